@@ -1,17 +1,28 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"crypto/ed25519"
+	"database/sql"
 	"embed"
+	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"io/fs"
 	"log"
+	"maps"
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"runtime"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -21,34 +32,85 @@ import (
 	"github.com/gordonklaus/portaudio"
 
 	"github.com/sjawhar/ghost-wispr/internal/audio"
+	"github.com/sjawhar/ghost-wispr/internal/briefing"
+	"github.com/sjawhar/ghost-wispr/internal/bundle"
 	"github.com/sjawhar/ghost-wispr/internal/config"
+	"github.com/sjawhar/ghost-wispr/internal/diarize"
+	"github.com/sjawhar/ghost-wispr/internal/dictation"
+	"github.com/sjawhar/ghost-wispr/internal/export"
 	"github.com/sjawhar/ghost-wispr/internal/gdrive"
+	"github.com/sjawhar/ghost-wispr/internal/hooks"
+	"github.com/sjawhar/ghost-wispr/internal/i18n"
+	"github.com/sjawhar/ghost-wispr/internal/ingest"
+	"github.com/sjawhar/ghost-wispr/internal/jobs"
 	"github.com/sjawhar/ghost-wispr/internal/llm"
+	"github.com/sjawhar/ghost-wispr/internal/mqtt"
+	"github.com/sjawhar/ghost-wispr/internal/power"
+	"github.com/sjawhar/ghost-wispr/internal/redact"
+	"github.com/sjawhar/ghost-wispr/internal/report"
+	"github.com/sjawhar/ghost-wispr/internal/script"
 	"github.com/sjawhar/ghost-wispr/internal/server"
+	"github.com/sjawhar/ghost-wispr/internal/service"
 	"github.com/sjawhar/ghost-wispr/internal/session"
 	"github.com/sjawhar/ghost-wispr/internal/storage"
 	"github.com/sjawhar/ghost-wispr/internal/summary"
+	"github.com/sjawhar/ghost-wispr/internal/transcribe"
+	"github.com/sjawhar/ghost-wispr/internal/tts"
+	"github.com/sjawhar/ghost-wispr/internal/update"
+	"github.com/sjawhar/ghost-wispr/internal/voiceprint"
+	"github.com/sjawhar/ghost-wispr/internal/watchdog"
+	"golang.org/x/term"
 )
 
 //go:embed static/*
 var staticFiles embed.FS
 
+// version is the release tag, set at build time with
+// -ldflags "-X main.version=v1.2.3".
+var version = "dev"
+
 type recorderState struct {
-	mic    *audio.Mic
+	mic    audio.Capture
 	mu     sync.RWMutex
 	paused bool
+	// recorder, if set, leaves paused time silent in session recordings.
+	recorder *audio.Recorder
+	// mute, if set, stops audio from streaming for transcription.
+	mute *audio.Mute
+	// manager, if set, leaves paused time out of transcription costs.
+	manager *session.Manager
 }
 
 func (r *recorderState) Pause() {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	r.paused = true
+	if r.mute != nil {
+		r.mute.SetMuted(true)
+	}
+	if r.manager != nil {
+		r.manager.SetCapturePaused(true)
+	}
+	if r.recorder != nil {
+		r.recorder.Pause()
+	}
 }
 
 func (r *recorderState) Resume() {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	r.paused = false
+	if r.mute != nil {
+		r.mute.SetMuted(false)
+	}
+	if r.manager != nil {
+		r.manager.SetCapturePaused(false)
+	}
+	if r.recorder != nil {
+		if err := r.recorder.Resume(); err != nil {
+			log.Printf("warning: %v", err)
+		}
+	}
 }
 
 func (r *recorderState) IsPaused() bool {
@@ -57,20 +119,52 @@ func (r *recorderState) IsPaused() bool {
 	return r.paused
 }
 
-func (r *recorderState) SetMic(mic *audio.Mic) {
+func (r *recorderState) SetMic(mic audio.Capture) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	r.mic = mic
 }
 
+// SetMute puts the transcription stream under pause control, muting it
+// straight away if recording is already paused.
+func (r *recorderState) SetMute(mute *audio.Mute) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.mute = mute
+	mute.SetMuted(r.paused)
+}
+
 type transcriptCallback struct {
 	manager session.LifecycleManager
+	// timeline, if set, maps Deepgram's timestamps, which only advance while
+	// audio is streamed, back onto the captured audio.
+	timeline func(float64) float64
+	// latency, if set, times final transcripts against when their audio was
+	// captured.
+	latency *transcribe.LatencyTracker
+	// onError, if set, is told of each error the stream reports.
+	onError func(error)
 }
 
 func (c transcriptCallback) Message(mr *api.MessageResponse) error {
 	if c.manager == nil {
 		return nil
 	}
+	if c.timeline != nil {
+		mr.Start = c.timeline(mr.Start)
+		for i := range mr.Channel.Alternatives {
+			words := mr.Channel.Alternatives[i].Words
+			for j := range words {
+				words[j].Start = c.timeline(words[j].Start)
+				words[j].End = c.timeline(words[j].End)
+			}
+		}
+	}
+	if c.latency != nil && mr.IsFinal && len(mr.Channel.Alternatives) > 0 {
+		if words := mr.Channel.Alternatives[0].Words; len(words) > 0 {
+			c.latency.ObserveFinal(words[len(words)-1].End)
+		}
+	}
 	return c.manager.Message(mr)
 }
 
@@ -87,6 +181,9 @@ func (c transcriptCallback) UtteranceEnd(ur *api.UtteranceEndResponse) error {
 	if c.manager == nil {
 		return nil
 	}
+	if c.timeline != nil {
+		ur.LastWordEnd = c.timeline(ur.LastWordEnd)
+	}
 	return c.manager.UtteranceEnd(ur)
 }
 
@@ -96,13 +193,26 @@ func (c transcriptCallback) Close(*api.CloseResponse) error {
 }
 
 func (c transcriptCallback) Error(er *api.ErrorResponse) error {
-	log.Printf("deepgram error %s: %s", er.ErrCode, er.Description)
+	log.Printf("transcription error %s: %s", er.ErrCode, er.Description)
+	if c.onError != nil {
+		c.onError(fmt.Errorf("%s: %s", er.ErrCode, er.Description))
+	}
 	return nil
 }
 
 func (c transcriptCallback) UnhandledEvent([]byte) error { return nil }
 
+// lateWriter forwards to a writer chosen after it is handed out.
+type lateWriter struct{ w io.Writer }
+
+func (l *lateWriter) Write(p []byte) (int, error) { return l.w.Write(p) }
+
 func main() {
+	if len(os.Args) > 1 {
+		runCommand(os.Args[1:])
+		return
+	}
+
 	log.Println("ghost-wispr: starting")
 
 	configPath := os.Getenv(config.EnvPrefix + "CONFIG")
@@ -118,10 +228,17 @@ func main() {
 		log.Printf("config: %s", w)
 	}
 
-	store, err := storage.NewSQLiteStore(cfg.DBPath)
+	locale, _ := i18n.New(cfg.Locale)
+
+	store, err := storage.Open(cfg.DBPath)
 	if err != nil {
 		log.Fatalf("storage init failed: %v", err)
 	}
+	if sqliteStore, ok := store.(*storage.SQLiteStore); ok && cfg.Appliance.Enabled {
+		if err := sqliteStore.SetCacheSize(cfg.Appliance.DBCacheKB); err != nil {
+			log.Printf("warning: %v", err)
+		}
+	}
 
 	assets, err := fs.Sub(staticFiles, "static")
 	if err != nil {
@@ -129,17 +246,25 @@ func main() {
 	}
 
 	hub := server.NewHub()
+	hub.SetInterimInterval(cfg.ParsedInterimInterval())
 	detector := session.NewDetector(cfg.ParsedSilenceTimeout())
 	audioRecorder := audio.NewRecorder(cfg.AudioDir)
+	audioRecorder.SetWAVOnly(cfg.Appliance.Enabled)
+	audioRecorder.SetEncoder(audio.EncoderOptions{Encoder: cfg.AudioEncoder, Bitrate: cfg.AudioBitrate, Quality: cfg.AudioQuality})
+	audioRecorder.SetPreRoll(cfg.ParsedAudioPreRoll())
 
-	apiKeys := map[string]string{
+	llmProviders := []string{"openai", "anthropic", "gemini"}
+	apiKeys := llm.NewKeys(map[string]string{
 		"openai":    cfg.OpenAIAPIKey,
 		"anthropic": cfg.AnthropicAPIKey,
 		"gemini":    cfg.GeminiAPIKey,
-	}
+		// Not LLMs, but rotated the same way.
+		"deepgram":   cfg.DeepgramAPIKey,
+		"assemblyai": cfg.AssemblyAIAPIKey,
+	})
 
 	clientFactory := func(provider, model string) (llm.Client, error) {
-		key := apiKeys[provider]
+		key := apiKeys.Get(provider)
 		if key == "" {
 			return nil, fmt.Errorf("no API key for provider %q", provider)
 		}
@@ -152,7 +277,7 @@ func main() {
 
 	var summarizer *summary.Summarizer
 	canSummarize := false
-	if provider, _, err := llm.ParseModel(cfg.Summarization.Model); err == nil && apiKeys[provider] != "" {
+	if provider, _, err := llm.ParseModel(cfg.Summarization.Model); err == nil && apiKeys.Get(provider) != "" {
 		canSummarize = true
 	}
 	if !canSummarize {
@@ -160,7 +285,7 @@ func main() {
 			if preset.Model == "" {
 				continue
 			}
-			if provider, _, err := llm.ParseModel(preset.Model); err == nil && apiKeys[provider] != "" {
+			if provider, _, err := llm.ParseModel(preset.Model); err == nil && apiKeys.Get(provider) != "" {
 				canSummarize = true
 				break
 			}
@@ -175,68 +300,513 @@ func main() {
 		sessionSummarizer = summarizer
 	}
 
-	manager := session.NewManager(store, audioRecorder, sessionSummarizer, hub, detector)
+	summaryPool := jobs.NewPool("summaries", cfg.Workers.Summaries)
+	exportPool := jobs.NewPool("exports", cfg.Workers.Exports)
+	backupPool := jobs.NewPool("backups", cfg.Workers.Backups)
+	// One ffmpeg at a time keeps encoding from starving live capture.
+	encodingPool := jobs.NewPool("encoding", 1)
+	var exports *export.Pipeline
+	if len(cfg.Exports) > 0 {
+		exports = export.NewPipeline(exportRules(cfg, locale), store, exportPool)
+	}
+
+	budget := session.NewBudgetGuard(cfg.Budget, store)
+	budget.Localizer = locale
+	managerOpts := []session.Option{
+		session.WithPricing(cfg.Summarization.Pricing, cfg.Transcription.CostPerMinute),
+		session.WithBudget(budget),
+		session.WithSummaryPool(summaryPool),
+		session.WithEncodingPool(encodingPool),
+		session.WithKeepWAV(cfg.AudioKeepWAV),
+		session.WithNotes(store),
+		session.WithStoredSpeakerNames(store),
+		session.WithTranscriptionModel(deepgramModel, cfg.Transcription.Language),
+		session.WithRedaction(redact.New(cfg.Redaction.Kinds)),
+		session.WithConfidenceFloor(cfg.Transcription.MinConfidence),
+	}
+	if cfg.Transcription.StableSpeakers {
+		managerOpts = append(managerOpts, session.WithSpeakerTracking())
+	}
+	if exports != nil {
+		managerOpts = append(managerOpts, session.WithExports(exports))
+	}
+	var voices *voiceprint.Identifier
+	if vc := cfg.VoiceProfiles; vc.Model != "" {
+		if model, err := voiceprint.LoadModel(vc.Model, vc.Runtime, cfg.ParsedVoiceTimeout()); err != nil {
+			log.Printf("warning: voice profiles disabled: %v", err)
+		} else {
+			defer func() { _ = model.Close() }()
+			voices = voiceprint.NewIdentifier(store, model, vc.Threshold)
+			managerOpts = append(managerOpts, session.WithVoiceIdentification(voices))
+		}
+	}
+	deepgramBatch := transcribe.NewDeepgramBatch(func() string { return apiKeys.Get("deepgram") }, deepgramModel, cfg.Transcription.Language)
+	assemblyAI := transcribe.AssemblyAI{URL: transcribe.AssemblyAIStreamingURL, APIKey: func() string { return apiKeys.Get("assemblyai") }, SampleRate: audio.TargetSampleRate}
+	// Rotating a transcription key reconnects the provider if it is live,
+	// and otherwise checks the key with one of these.
+	liveKeyChecks := map[string]func(context.Context) error{
+		config.TranscriptionProviderDeepgram:   deepgramBatch.Check,
+		config.TranscriptionProviderAssemblyAI: assemblyAI.Check,
+	}
+	keywords, err := transcribe.NormalizeKeywords(cfg.Transcription.Keywords)
+	if err != nil {
+		log.Printf("warning: ignoring transcription.keywords: %v", err)
+	}
+	// Batch transcription goes through the key pool, so it is available
+	// when the pool holds a Deepgram key.
+	hasDeepgram := apiKeys.Get("deepgram") != ""
+	deepgramBatch.SetKeywords(keywords)
+	deepgramBatch.SetRedaction(cfg.Transcription.Redact, cfg.Transcription.ProfanityFilter)
+	if hasDeepgram && cfg.Transcription.OfflineFallback {
+		managerOpts = append(managerOpts,
+			session.WithGapTranscriber(deepgramBatch),
+			session.WithGapQueue(filepath.Join(cfg.AudioDir, offlineQueueDir), cfg.ParsedOfflineRetryInterval(), func(sessionID string, gap transcribe.Gap) (string, error) {
+				return saveGapAudio(cfg.AudioDir, sessionID, gap)
+			}),
+		)
+	}
+	// Recordings from the watched folder and uploads are transcribed whole.
+	if hasDeepgram {
+		managerOpts = append(managerOpts, session.WithImportTranscriber(deepgramBatch))
+	}
+	if probe := cfg.ParsedOfflineProbe(); probe != "" {
+		managerOpts = append(managerOpts, session.WithOfflineQueue(session.TCPProbe(probe), cfg.ParsedOfflineRetryInterval()))
+	}
+	if summarizer != nil && cfg.Summarization.ExtractDecisions {
+		managerOpts = append(managerOpts, session.WithDecisionExtractor(summarizer))
+	}
+	if summarizer != nil {
+		renderer := summary.NewDocumentRenderer(cfg.Summarization)
+		renderer.Localizer = locale
+		managerOpts = append(managerOpts,
+			session.WithMemoPreset(cfg.Summarization.MemoPreset),
+			session.WithSummaryRenderer(renderer),
+		)
+	}
+	if summarizer != nil && cfg.Summarization.ExtractActionItems {
+		managerOpts = append(managerOpts, session.WithActionItems(summarizer, store))
+	}
 
-	recState := &recorderState{}
+	switch cfg.TTS.Provider {
+	case config.TTSProviderOpenAI:
+		managerOpts = append(managerOpts, session.WithSummaryAudio(tts.NewOpenAI(func() string { return apiKeys.Get("openai") }, cfg.TTS.BaseURL, cfg.TTS.Model, cfg.TTS.Voice), cfg.AudioDir))
+	case config.TTSProviderPiper:
+		managerOpts = append(managerOpts, session.WithSummaryAudio(tts.Piper{Command: cfg.TTS.PiperCommand, Model: cfg.TTS.PiperModel}, cfg.AudioDir))
+	}
+	if sc := cfg.Scripts.PresetRouter; sc.Module != "" && summarizer != nil {
+		if router, err := loadScript(sc, script.HookRoutePreset); err != nil {
+			log.Printf("warning: preset router script disabled: %v", err)
+		} else {
+			summarizer.SetPresetSelector(router)
+		}
+	}
+	if cfg.Series.LookbackWeeks > 0 {
+		managerOpts = append(managerOpts, session.WithSeriesDetection(&session.SeriesDetector{
+			Store:         store,
+			Tolerance:     cfg.ParsedSeriesSlotTolerance(),
+			LookbackWeeks: cfg.Series.LookbackWeeks,
+			MinDuration:   cfg.ParsedSeriesMinDuration(),
+		}))
+	}
+	purger := &session.Purger{
+		Store:        store,
+		DriveBackups: cfg.GDriveFolderID != "" && cfg.DBPath != storage.MemoryPath,
+	}
+	if usesDriveDocs(cfg) {
+		// Any Docs can delete what the service account created, whichever
+		// folder it was put in.
+		if docs, err := gdrive.NewDocs(context.Background(), cfg.GoogleCredentialsFile, cfg.GDriveFolderID); err != nil {
+			log.Printf("warning: Drive copies of deleted sessions will be kept: %v", err)
+		} else {
+			purger.DeleteDriveFile = docs.DeleteFile
+		}
+	}
+	var classifier *session.Classifier
+	if cc := cfg.Classification; cc.Enabled {
+		classifier = &session.Classifier{
+			Store:         store,
+			MinDuration:   cfg.ParsedClassificationMinDuration(),
+			MinSpeakers:   cc.MinSpeakers,
+			CalendarSlack: cfg.ParsedCalendarSlack(),
+			Policies: map[string]session.ClassPolicy{
+				storage.SessionKindMeeting: {Summarize: cc.Meeting.Summarize, Notify: cc.Meeting.Notify, Retention: cc.Meeting.ParsedRetention()},
+				storage.SessionKindAmbient: {Summarize: cc.Ambient.Summarize, Notify: cc.Ambient.Notify, Retention: cc.Ambient.ParsedRetention()},
+			},
+			Purger: purger,
+		}
+		managerOpts = append(managerOpts, session.WithClassifier(classifier))
+	}
+	if sc := cfg.Scripts.SegmentProcessor; sc.Module != "" {
+		if processor, err := loadScript(sc, script.HookProcessSegment); err != nil {
+			log.Printf("warning: segment processor script disabled: %v", err)
+		} else {
+			managerOpts = append(managerOpts, session.WithSegmentProcessor(processor))
+		}
+	}
+
+	dictator := dictation.New(dictation.Typer{
+		Command: cfg.Dictation.TypeCommand,
+		Args:    cfg.Dictation.TypeArgs,
+		Timeout: cfg.ParsedDictationTypeTimeout(),
+	})
+	dictator.SetActive(cfg.Dictation.Active)
+	managerOpts = append(managerOpts, session.WithDictation(dictator))
+
+	manager := session.NewManager(store, audioRecorder, sessionSummarizer, hub, detector, managerOpts...)
+
+	recState := &recorderState{recorder: audioRecorder, manager: manager}
 	warnings := append([]string{}, cfgWarnings...)
 
-	handler, err := server.Handler(assets, hub, store, server.ControlHooks{
+	var updates *update.Checker
+	switch {
+	case !cfg.Updates.Enabled:
+	case !update.IsRelease(version):
+		log.Printf("update checks skipped: %q is a development build", version)
+	default:
+		exe := ""
+		var releaseKey ed25519.PublicKey
+		if cfg.Updates.AutoStage {
+			key := cfg.Updates.PublicKey
+			if key == "" {
+				key = update.ReleaseKey
+			}
+			if key == "" {
+				log.Printf("warning: update staging disabled: no release key to verify updates with")
+			} else if releaseKey, err = update.ParsePublicKey(key); err != nil {
+				log.Printf("warning: update staging disabled: %v", err)
+			} else if exe, err = os.Executable(); err != nil {
+				log.Printf("warning: update staging disabled: %v", err)
+				exe = ""
+			}
+		}
+		updates = update.NewChecker(version, cfg.Updates.Channel, exe)
+		updates.PublicKey = releaseKey
+	}
+
+	var resources *watchdog.Watchdog
+	if cfg.Appliance.Enabled {
+		resources = watchdog.New(watchdog.Limits{MemoryMB: cfg.Appliance.MemoryLimitMB, CPUPercent: cfg.Appliance.CPULimitPercent})
+		resources.Localizer = locale
+		resources.IndexSizes = store.IndexSizes
+		// Recording and transcription always run; summaries, exports and
+		// backups can wait until the device has room again.
+		resources.Shed = func(shed bool) {
+			for _, pool := range []*jobs.Pool{summaryPool, exportPool, backupPool} {
+				pool.Hold(shed)
+			}
+			if shed {
+				log.Println("resources: holding back summaries, exports and backups until usage drops")
+			} else {
+				log.Println("resources: usage back under limits, resuming deferred jobs")
+			}
+		}
+	}
+
+	var briefWriter briefing.Writer
+	if summarizer != nil {
+		briefWriter = summarizer
+	}
+	briefings := briefing.New(store, briefWriter, hub, briefing.Options{
+		Lead:        cfg.ParsedBriefingLead(),
+		Lookback:    time.Duration(cfg.Briefings.LookbackDays) * 24 * time.Hour,
+		MaxSessions: cfg.Briefings.MaxSessions,
+	})
+	if err := briefings.Resume(); err != nil {
+		log.Printf("warning: rescheduling briefings failed: %v", err)
+	}
+	defer briefings.Stop()
+
+	// Runtime warnings about the input are announced as they start and stop,
+	// so broken capture is noticed before a day's recordings are lost.
+	var inputMonitor *audio.InputMonitor
+	// liveMu serializes reconnecting the live stream with a new key or
+	// keywords, which it reads from apiKeys and deepgramBatch whenever it
+	// connects.
+	var liveMu sync.Mutex
+	// Set once the live stream is up; reconnects provider if it is the one
+	// transcribing, reporting whether it was.
+	var refreshLive func(provider string) (bool, error)
+	controls := server.ControlHooks{
 		Pause:    recState.Pause,
 		Resume:   recState.Resume,
 		IsPaused: recState.IsPaused,
 		OnStatusChanged: func(paused bool) {
 			hub.BroadcastStatusChanged(paused)
 		},
-		Warnings: func() []string { return warnings },
+		Warnings: func() []string {
+			all := append(append([]string{}, warnings...), budget.Warnings()...)
+			if resources != nil {
+				all = append(all, resources.Warnings()...)
+			}
+			return append(all, inputMonitor.Warnings()...)
+		},
 		Presets: func() map[string]config.Preset {
 			if summarizer == nil {
 				return nil
 			}
 			return summarizer.Presets()
 		},
-		Resummarize: func(ctx context.Context, sessionID, preset string) error {
-			if summarizer == nil {
-				return fmt.Errorf("summarization not configured")
+		EndSession: func(ctx context.Context) error {
+			return manager.ForceEndSession(ctx)
+		},
+		KeepWAV: manager.KeepWAV,
+		AddNote: manager.AddNote,
+		PurgeSession: func(ctx context.Context, sessionID string) (session.PurgeReport, error) {
+			if sessionID == manager.CurrentSessionID() {
+				return session.PurgeReport{}, session.ErrSessionRecording
 			}
-
-			segments, err := store.GetSegments(sessionID)
+			return purger.Purge(ctx, sessionID)
+		},
+		Bookmark: func(_ context.Context, note string) (storage.Bookmark, error) {
+			at := time.Now().UTC()
+			sessionID, offset, err := manager.Timeline(at)
 			if err != nil {
-				return err
+				return storage.Bookmark{}, err
 			}
-
-			var b strings.Builder
-			for _, seg := range segments {
-				if strings.TrimSpace(seg.Text) != "" {
-					b.WriteString(seg.Text)
-					b.WriteString("\n")
+			return store.AddBookmark(sessionID, at, offset, note)
+		},
+		CreateBriefing: func(ctx context.Context, title string, attendees []string, startsAt, endsAt time.Time) (storage.Briefing, error) {
+			return briefings.Create(ctx, briefing.Event{Title: title, Attendees: attendees, StartsAt: startsAt, EndsAt: endsAt})
+		},
+		Briefings: store.ListBriefings,
+		StartMemo: manager.StartMemo,
+		StopMemo:  manager.StopMemo,
+		Dictation: dictator,
+		Jobs: func() []jobs.Stats {
+			return []jobs.Stats{summaryPool.Stats(), exportPool.Stats(), backupPool.Stats(), encodingPool.Stats()}
+		},
+		Exports:   exportPool,
+		GraphQL:   cfg.GraphQL.Enabled,
+		Localizer: locale,
+		RotateKeys: func(ctx context.Context, keys map[string]string) ([]server.KeyHealth, error) {
+			for provider := range keys {
+				if provider != "deepgram" && provider != "assemblyai" && !slices.Contains(llmProviders, provider) {
+					return nil, fmt.Errorf("%w %q: keys can be rotated for deepgram, assemblyai, %s", server.ErrUnknownProvider, provider, strings.Join(llmProviders, ", "))
 				}
 			}
-			transcript := b.String()
-
-			_ = store.UpdateSummary(sessionID, "", storage.SummaryRunning, "")
-			hub.BroadcastSummaryReady(sessionID, "", storage.SummaryRunning, "")
-
-			var summaryText string
-			var presetUsed string
-			if preset != "" {
-				presetUsed = preset
-				summaryText, err = summarizer.SummarizeWithPreset(ctx, sessionID, transcript, preset)
-			} else {
-				summaryText, presetUsed, err = summarizer.Summarize(ctx, sessionID, transcript)
+			liveMu.Lock()
+			defer liveMu.Unlock()
+			for provider, key := range keys {
+				apiKeys.Set(provider, key)
 			}
-
-			status := storage.SummaryCompleted
-			if err != nil {
-				status = storage.SummaryFailed
+			var health []server.KeyHealth
+			for _, provider := range slices.Sorted(maps.Keys(liveKeyChecks)) {
+				if _, ok := keys[provider]; !ok {
+					continue
+				}
+				// A live provider reconnects with the new key, holding audio
+				// meanwhile, and whether it connects is its health check.
+				var refreshed bool
+				var err error
+				if refreshLive != nil {
+					refreshed, err = refreshLive(provider)
+				}
+				if !refreshed {
+					err = liveKeyChecks[provider](ctx)
+				}
+				h := server.KeyHealth{Provider: provider, Healthy: err == nil}
+				if err != nil {
+					h.Error = err.Error()
+				}
+				health = append(health, h)
 			}
-			_ = store.UpdateSummary(sessionID, summaryText, status, presetUsed)
-			hub.BroadcastSummaryReady(sessionID, summaryText, status, presetUsed)
-			return err
+			log.Printf("API keys rotated for %d provider(s)", len(keys))
+			return append(health, checkProviderKeys(ctx, apiKeys, clientFactory, cfg.Summarization)...), nil
 		},
-		EndSession: func(ctx context.Context) error {
-			return manager.ForceEndSession(ctx)
+		Keywords: deepgramBatch.Keywords,
+		SetKeywords: func(next []string) ([]string, error) {
+			next, err := transcribe.NormalizeKeywords(next)
+			if err != nil {
+				return nil, err
+			}
+			liveMu.Lock()
+			defer liveMu.Unlock()
+			deepgramBatch.SetKeywords(next)
+			log.Printf("transcription keywords set to %d term(s)", len(next))
+			if refreshLive != nil {
+				if _, err := refreshLive(config.TranscriptionProviderDeepgram); err != nil {
+					return next, fmt.Errorf("reconnect deepgram: %w", err)
+				}
+			}
+			return next, nil
 		},
+	}
+	inputMonitor = audio.NewInputMonitor(inputClippingWarnAfter, inputSilentWarnAfter, recState.IsPaused, func() {
+		hub.BroadcastWarnings(controls.Warnings())
 	})
+	inputMonitor.Localizer = locale
+	var uploader *ingest.Uploader
+	if hasDeepgram {
+		uploader = ingest.NewUploader(cfg.AudioDir, manager)
+		controls.ImportAudio = uploader.Import
+	}
+	if voices != nil {
+		controls.EnrollVoice = voices.Enroll
+		controls.EnrollSpeakerVoice = voices.EnrollSpeaker
+	}
+	if updates != nil {
+		controls.Update = updates.Available
+	}
+	if summarizer != nil {
+		controls.Resummarize = manager.Resummarize
+		controls.EstimateSummary = manager.EstimateSummary
+		controls.RewriteSummary = manager.RewriteSummary
+		controls.SummaryQueue = manager.SummaryQueue
+		controls.CancelSummary = manager.CancelSummary
+	}
+	if resources != nil {
+		controls.Resources = resources.Stats
+	}
+	controls.AllowedOrigins = cfg.Auth.AllowedOrigins
+	controls.TrustedProxies = cfg.Auth.ParsedTrustedProxies()
+	if len(cfg.Auth.Tokens) > 0 {
+		tokens := make([]server.Token, 0, len(cfg.Auth.Tokens))
+		for _, t := range cfg.Auth.Tokens {
+			scope, _ := server.ParseScope(t.Scope)
+			tokens = append(tokens, server.Token{Name: t.Name, Scope: scope, Secret: t.Secret})
+		}
+		var accessLog io.Writer
+		if cfg.Auth.AccessLog != "" {
+			f, err := os.OpenFile(cfg.Auth.AccessLog, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+			if err != nil {
+				log.Fatalf("open access log failed: %v", err)
+			}
+			defer func() { _ = f.Close() }()
+			accessLog = f
+		}
+		controls.Auth = server.NewAuth(tokens, accessLog)
+	}
+
+	// Set once the microphone is open; device switching needs a running capture.
+	var switchable *audio.SwitchableCapture
+	micState := &micHealth{hub: hub}
+	controls.MicHealth = micState.get
+	transcriptionState := &transcriptionHealth{hub: hub}
+	controls.TranscriptionStatus = transcriptionState.get
+	// Set once audio streams; calibration listens to it and tunes the gate.
+	var calibrator *audio.Calibrator
+	var gate *audio.VADGate
+	// Set once live transcription connects.
+	var latency *transcribe.LatencyTracker
+	controls.TranscriptionLatency = func() []transcribe.LatencyStats {
+		if latency == nil {
+			return nil
+		}
+		return []transcribe.LatencyStats{latency.Stats()}
+	}
+	captureDevice := func() string {
+		if switchable != nil {
+			return switchable.Device()
+		}
+		return cfg.MicDevice
+	}
+	// applyCalibration sets the gate's threshold from the device's stored
+	// calibration, if it has one; otherwise the configured threshold stands.
+	applyCalibration := func(device string) {
+		if gate == nil {
+			return
+		}
+		c, err := store.GetAudioCalibration(device)
+		if errors.Is(err, sql.ErrNoRows) {
+			gate.SetThreshold(cfg.Transcription.VAD.ThresholdDB)
+			return
+		}
+		if err != nil {
+			log.Printf("warning: loading audio calibration failed: %v", err)
+			return
+		}
+		gate.SetThreshold(c.ThresholdDB)
+		log.Printf("voice-activity threshold %.1f dBFS from calibration of %q", c.ThresholdDB, device)
+	}
+	if exports != nil {
+		controls.SessionExports = exports.Exports
+		controls.RetryExport = exports.Retry
+	}
+	controls.Calibrate = func(ctx context.Context, d time.Duration) (storage.AudioCalibration, error) {
+		if calibrator == nil {
+			return storage.AudioCalibration{}, errors.New("microphone is not streaming")
+		}
+		floor, err := calibrator.Measure(ctx, d)
+		if errors.Is(err, audio.ErrCalibrationBusy) {
+			return storage.AudioCalibration{}, server.ErrCalibrationInProgress
+		}
+		if err != nil {
+			return storage.AudioCalibration{}, err
+		}
+		c := storage.AudioCalibration{
+			Device:       captureDevice(),
+			NoiseFloorDB: floor.FloorDB,
+			NoisePeakDB:  floor.PeakDB,
+			ThresholdDB:  floor.ThresholdDB,
+			Seconds:      floor.Duration.Seconds(),
+			CalibratedAt: time.Now().UTC(),
+		}
+		if err := store.SaveAudioCalibration(c); err != nil {
+			return storage.AudioCalibration{}, err
+		}
+		if gate != nil {
+			gate.SetThreshold(c.ThresholdDB)
+		}
+		log.Printf("calibrated %q: noise floor %.1f dBFS, voice-activity threshold %.1f dBFS", c.Device, c.NoiseFloorDB, c.ThresholdDB)
+		return c, nil
+	}
+	if cfg.CaptureBackend == config.CaptureBackendPortAudio && len(cfg.MicDevices) == 0 {
+		controls.AudioDevices = func() ([]server.AudioDevice, string, error) {
+			devices, err := audio.ListDevices()
+			if err != nil {
+				return nil, "", err
+			}
+			result := make([]server.AudioDevice, 0, len(devices))
+			for _, d := range devices {
+				result = append(result, server.AudioDevice(d))
+			}
+			current := cfg.MicDevice
+			if switchable != nil {
+				current = switchable.Device()
+			}
+			return result, current, nil
+		}
+		controls.SetAudioDevice = func(name string) error {
+			if switchable == nil {
+				return errors.New("microphone is not running")
+			}
+			mic, err := openMicDevice(name, cfg.MicSampleRate)
+			if errors.Is(err, audio.ErrDeviceNotFound) {
+				return fmt.Errorf("%w: %q", server.ErrAudioDeviceNotFound, name)
+			}
+			if err != nil {
+				return err
+			}
+			if err := switchable.Switch(mic, name); err != nil {
+				return err
+			}
+			log.Printf("capture switched to %q at %d Hz", name, mic.SampleRate())
+			applyCalibration(name)
+			return nil
+		}
+	}
+
+	// Set when audio comes from a web UI's microphone rather than a device
+	// on the server: always with the browser backend, or as a fallback
+	// when the configured capture can't open.
+	var browserMic atomic.Pointer[audio.BrowserCapture]
+	controls.BrowserMic = func(sampleRate int) (io.WriteCloser, error) {
+		b := browserMic.Load()
+		if b == nil {
+			return nil, server.ErrBrowserMicUnavailable
+		}
+		feed, err := b.Feed(sampleRate)
+		if errors.Is(err, audio.ErrBrowserCaptureBusy) {
+			return nil, server.ErrBrowserMicBusy
+		}
+		return feed, err
+	}
+	controls.BrowserMicStatus = func() server.BrowserMicStatus {
+		b := browserMic.Load()
+		return server.BrowserMicStatus{Enabled: b != nil, Feeding: b != nil && b.Feeding()}
+	}
+
+	handler, err := server.Handler(assets, hub, store, controls)
 	if err != nil {
 		log.Fatalf("build http handler failed: %v", err)
 	}
@@ -245,11 +815,85 @@ func main() {
 	defer cancel()
 	defer func() { _ = store.Close() }()
 
-	if cfg.GDriveFolderID != "" {
+	go manager.RunOfflineQueue(ctx)
+	go manager.RunGapQueue(ctx)
+	go dictator.Run(ctx)
+	if cfg.Watch.Dir != "" {
+		log.Printf("importing audio dropped into %s", cfg.Watch.Dir)
+		go ingest.NewWatcher(cfg.Watch.Dir, cfg.Watch.ArchiveDir, cfg.AudioDir, manager).Run(ctx, cfg.ParsedWatchInterval())
+	}
+	if classifier != nil {
+		go classifier.RunRetention(ctx, time.Hour)
+	}
+	if resources != nil {
+		go resources.Run(ctx, cfg.ParsedApplianceCheckInterval())
+	}
+	go budget.Watch(ctx, time.Minute, manager.AccrueTranscriptionUsage, func() {
+		if !recState.IsPaused() {
+			recState.Pause()
+			hub.BroadcastStatusChanged(true)
+		}
+	})
+
+	if len(cfg.Hooks) > 0 {
+		hookList := make([]hooks.Hook, 0, len(cfg.Hooks))
+		for _, h := range cfg.Hooks {
+			hookList = append(hookList, hooks.Hook{Event: h.Event, Command: h.Command, Args: h.Args, Timeout: h.ParsedTimeout()})
+		}
+		events := hub.Subscribe()
+		defer hub.Unsubscribe(events)
+		go hooks.NewRunner(hookList).Run(ctx, events)
+	}
+
+	if exports != nil {
+		exports.Start(ctx)
+	}
+	// After the export pipeline starts, so a report written at once is
+	// delivered too.
+	if cfg.Reports.Weekly {
+		reporter := report.New(store, hub)
+		reporter.Localizer = locale
+		if exports != nil {
+			reporter.Exports = exports
+		}
+		go reporter.Run(ctx)
+	}
+
+	if rc := cfg.SpeakerRefinement; rc.Command != "" {
+		refiner := diarize.NewRefiner(store, diarize.Command{Command: rc.Command, Args: rc.Args, Timeout: cfg.ParsedRefinementTimeout()})
+		go refiner.Run(ctx, cfg.ParsedRefinementInterval())
+	}
+
+	if cfg.MQTT.Broker != "" {
+		publisher := mqtt.NewPublisher(mqtt.Options{
+			Broker:   cfg.MQTT.Broker,
+			ClientID: cfg.MQTT.ClientID,
+			Username: cfg.MQTT.Username,
+			Password: cfg.MQTTPassword,
+		}, cfg.MQTT.TopicPrefix, cfg.MQTT.DiscoveryPrefix, recState.IsPaused())
+		events := hub.Subscribe()
+		defer hub.Unsubscribe(events)
+		go publisher.Run(ctx, events)
+	}
+
+	if updates != nil {
+		go updates.Run(ctx, cfg.ParsedUpdateInterval(), cfg.Updates.AutoStage, func(r update.Release) {
+			if r.Staged {
+				log.Printf("update %s staged — restart to apply", r.Version)
+			} else {
+				log.Printf("update %s available: %s", r.Version, r.URL)
+			}
+			hub.BroadcastUpdateAvailable(r.Version, r.URL, r.Staged)
+		})
+	}
+
+	if cfg.GDriveFolderID != "" && cfg.DBPath == storage.MemoryPath {
+		warnings = append(warnings, locale.T(i18n.WarnGDriveMemoryStore))
+	} else if cfg.GDriveFolderID != "" {
 		syncer, syncErr := gdrive.NewSyncer(ctx, cfg.GoogleCredentialsFile, cfg.GDriveFolderID)
 		if syncErr != nil {
 			log.Printf("warning: gdrive sync disabled: %v", syncErr)
-			warnings = append(warnings, "Google Drive sync failed to initialize \u2014 backups are disabled")
+			warnings = append(warnings, locale.T(i18n.WarnGDriveInitFailed))
 		} else {
 			go func() {
 				ticker := time.NewTicker(5 * time.Minute)
@@ -260,90 +904,387 @@ func main() {
 						return
 					case <-ticker.C:
 						date := time.Now().UTC().Format("2006-01-02")
-						if err := syncer.Sync(cfg.DBPath, date); err != nil {
+						err := backupPool.Do(ctx, func() error {
+							return syncer.Sync(cfg.DBPath, date)
+						})
+						if err != nil && ctx.Err() == nil {
 							log.Printf("gdrive sync error: %v", err)
 						}
 					}
 				}
 			}()
 		}
+		if cfg.GDriveSummaryDocs || cfg.GDriveJournal {
+			docs, docsErr := gdrive.NewDocs(ctx, cfg.GoogleCredentialsFile, cfg.GDriveFolderID)
+			if docsErr != nil {
+				log.Printf("warning: gdrive summary docs and journal disabled: %v", docsErr)
+				warnings = append(warnings, locale.T(i18n.WarnGDriveInitFailed))
+			} else {
+				var reviews *gdrive.Reviews
+				if cfg.GDriveSummaryDocs {
+					reviews = gdrive.NewReviews(docs, store)
+				}
+				var journal *gdrive.Journal
+				if cfg.GDriveJournal {
+					journal = gdrive.NewJournal(docs, store, locale)
+				}
+				go func() {
+					ticker := time.NewTicker(5 * time.Minute)
+					defer ticker.Stop()
+					for {
+						select {
+						case <-ctx.Done():
+							return
+						case <-ticker.C:
+							if reviews != nil {
+								err := backupPool.Do(ctx, func() error {
+									return reviews.Sync(ctx)
+								})
+								if err != nil && ctx.Err() == nil {
+									log.Printf("gdrive summary docs error: %v", err)
+								}
+							}
+							if journal != nil {
+								err := backupPool.Do(ctx, func() error {
+									return journal.Sync(ctx)
+								})
+								if err != nil && ctx.Err() == nil {
+									log.Printf("gdrive journal error: %v", err)
+								}
+							}
+						}
+					}
+				}()
+			}
+		}
 	}
 
-	var mic *audio.Mic
+	var mic audio.Capture
 	var dgWriter io.Writer
 	var dgStop func()
-	selectedSampleRate := cfg.MicSampleRate
+	var dgReconnect func()
 
-	paErr := portaudio.Initialize()
-	//nolint:errcheck // Terminate is best-effort cleanup
-	defer portaudio.Terminate()
-	if paErr != nil {
-		log.Fatalf("portaudio init failed: %v", paErr) //nolint:gocritic // Terminate is no-op if Initialize failed
+	if cfg.CaptureBackend == config.CaptureBackendPortAudio {
+		paErr := portaudio.Initialize()
+		//nolint:errcheck // Terminate is best-effort cleanup
+		defer portaudio.Terminate()
+		if paErr != nil {
+			log.Fatalf("portaudio init failed: %v", paErr) //nolint:gocritic // Terminate is no-op if Initialize failed
+		}
 	}
 
 	client.Init(client.InitLib{LogLevel: client.LogLevelDefault})
 
-	for _, rate := range cfg.SampleRateCandidates() {
-		mic, err = audio.NewMic(rate, rate/4) // 250ms buffer
-		if err != nil {
-			log.Printf("warning: microphone open failed at %d Hz: %v", rate, err)
-			continue
-		}
-		selectedSampleRate = rate
-		break
+	// useBrowserMic switches capture to the web UI's microphone.
+	useBrowserMic := func() {
+		b := audio.NewBrowserCapture()
+		mic = b
+		browserMic.Store(b)
 	}
-
-	if mic == nil {
-		log.Printf("warning: microphone unavailable, running API/UI only")
-		warnings = append(warnings, "Microphone unavailable \u2014 recording and live transcription are disabled")
-	} else {
-		audioRecorder.SetSampleRate(selectedSampleRate)
+	if cfg.CaptureBackend == config.CaptureBackendBrowser {
+		useBrowserMic()
+	} else if mic, err = openCapture(cfg); err != nil {
+		log.Printf("warning: microphone unavailable, waiting for a browser to send its microphone: %v", err)
+		warnings = append(warnings, locale.T(i18n.WarnMicUnavailable))
+		useBrowserMic()
+	}
+	audioRecorder.SetSampleRate(audio.TargetSampleRate)
+	audioRecorder.SetChannels(audio.Channels(mic))
+	recState.SetMic(mic)
+	if err := mic.Start(); err != nil {
+		log.Printf("warning: microphone start failed at %d Hz, waiting for a browser to send its microphone: %v", mic.SampleRate(), err)
+		warnings = append(warnings, locale.T(i18n.WarnMicStartFailed))
+		useBrowserMic()
+		audioRecorder.SetChannels(audio.Channels(mic))
 		recState.SetMic(mic)
-		if err := mic.Start(); err != nil {
-			log.Printf("warning: microphone start failed at %d Hz, running API/UI only: %v", selectedSampleRate, err)
-			mic = nil
-			recState.SetMic(nil)
-			warnings = append(warnings, "Microphone failed to start \u2014 recording and live transcription are disabled")
-		} else {
-			log.Printf("microphone started at %d Hz", selectedSampleRate)
+		_ = mic.Start()
+	}
+	if browserMic.Load() != nil {
+		log.Printf("capturing from the web UI's microphone, resampled to %d Hz", audio.TargetSampleRate)
+		micState.set(browserMicDevice, nil)
+	} else {
+		log.Printf("microphone started at %d Hz, resampling to %d Hz", mic.SampleRate(), audio.TargetSampleRate)
+		input := mic
+		if mix, ok := mic.(*audio.MixCapture); ok {
+			input = mix.Primary()
 		}
+		switchable, _ = input.(*audio.SwitchableCapture)
+		micState.set(captureDevice(), nil)
 	}
 
-	if mic != nil && cfg.DeepgramAPIKey != "" {
+	// Recordings a crashed run left unencoded are recovered once capture
+	// has set the recorder's format, which they're assumed to share.
+	go func() {
+		if n, err := manager.RecoverOrphanedAudio(); err != nil {
+			log.Printf("warning: recovering orphaned recordings failed: %v", err)
+		} else if n > 0 {
+			log.Printf("recovered %d recording(s) left unencoded by an earlier run", n)
+		}
+	}()
+
+	if mic != nil && len(cfg.Transcription.Providers) > 0 {
 		cOptions := &interfaces.ClientOptions{EnableKeepAlive: true}
 		tOptions := &interfaces.LiveTranscriptionOptions{
-			Model:          "nova-2",
-			Language:       "en-US",
-			Diarize:        true,
-			Punctuate:      true,
-			SmartFormat:    true,
-			Encoding:       "linear16",
-			SampleRate:     selectedSampleRate,
-			Channels:       1,
-			Endpointing:    cfg.Transcription.Endpointing,
-			InterimResults: true,
-			UtteranceEndMs: cfg.Transcription.UtteranceEndMs,
-			VadEvents:      true,
-		}
-
-		dgClient, err := client.NewWSUsingCallback(ctx, cfg.DeepgramAPIKey, cOptions, tOptions, transcriptCallback{manager: manager})
-		if err != nil {
-			log.Printf("warning: deepgram client unavailable, running API/UI only: %v", err)
-			warnings = append(warnings, "Deepgram initialization failed \u2014 live transcription is disabled")
-		} else if ok := dgClient.Connect(); !ok {
-			log.Printf("warning: deepgram connect failed, running API/UI only")
-			warnings = append(warnings, "Deepgram connection failed \u2014 live transcription is disabled")
+			Model:           deepgramModel,
+			Language:        transcribe.DeepgramLanguage(cfg.Transcription.Language),
+			ProfanityFilter: cfg.Transcription.ProfanityFilter,
+			Redact:          cfg.Transcription.Redact,
+			Diarize:         true,
+			Punctuate:       true,
+			SmartFormat:     true,
+			Encoding:        "linear16",
+			SampleRate:      audio.TargetSampleRate,
+			Channels:        audio.Channels(mic),
+			Multichannel:    audio.Channels(mic) > 1,
+			Endpointing:     cfg.Transcription.Endpointing,
+			InterimResults:  true,
+			UtteranceEndMs:  cfg.Transcription.UtteranceEndMs,
+			VadEvents:       true,
+			Keywords:        keywords,
+		}
+
+		// Audio is stamped with its capture time where it enters the
+		// transcription path, ahead of the gate and offline buffering, so
+		// final transcripts can be timed against it.
+		clockTo := &lateWriter{}
+		clock := transcribe.NewCaptureClock(clockTo, audio.TargetSampleRate, audio.Channels(mic))
+		callback := transcriptCallback{manager: manager, latency: transcribe.NewLatencyTracker("deepgram", clock)}
+		// Pausing mutes the stream after the clock, so nothing is sent to
+		// Deepgram while paused; like the gate, the mute keeps track of what
+		// it dropped so transcripts stay on the capture timeline.
+		muteTo := &lateWriter{}
+		mute := audio.NewMute(muteTo, audio.TargetSampleRate, audio.Channels(mic))
+		callback.timeline = mute.CaptureTime
+		// The VAD gate sits between the recorder and Deepgram, so recordings
+		// keep the silence that is never streamed. The SDK's keepalives hold
+		// the connection open while the gate is shut.
+		var gateTo *lateWriter
+		if vad := cfg.Transcription.VAD; vad.Enabled {
+			gateTo = &lateWriter{}
+			gate = audio.NewVADGate(gateTo, audio.TargetSampleRate, audio.Channels(mic), audio.VADOptions{
+				ThresholdDB: vad.ThresholdDB,
+				PreRoll:     cfg.ParsedVADPreRoll(),
+				Hangover:    cfg.ParsedVADHangover(),
+			})
+			callback.timeline = func(t float64) float64 { return mute.CaptureTime(gate.CaptureTime(t)) }
+		}
+
+		// Speakers of separate channels are told apart by channel, which
+		// diarizing the mixed recording afterwards would undo.
+		manager.SetChannelSpeakers(audio.Channels(mic) > 1)
+		// Gap recovery transcribes buffered audio as mono.
+		offlineCapture := cfg.Transcription.OfflineFallback && audio.Channels(mic) == 1
+		// Each connection gets its own callback: one connected partway into
+		// the stream has its timestamps moved on by the audio before it.
+		// Errors it reports count towards failing over.
+		var chain atomic.Pointer[transcribe.Failover]
+		callback.onError = func(err error) {
+			if f := chain.Load(); f != nil {
+				f.ReportError(err)
+			}
+		}
+		handler := func(offset float64) transcriptCallback {
+			next := callback
+			if offset > 0 {
+				next.timeline = func(t float64) float64 {
+					if callback.timeline == nil {
+						return t + offset
+					}
+					return callback.timeline(t + offset)
+				}
+			}
+			return next
+		}
+		var providers []transcribe.Provider
+		for _, name := range cfg.Transcription.Providers {
+			switch name {
+			case config.TranscriptionProviderDeepgram:
+				providers = append(providers, transcribe.Provider{Name: name, Model: deepgramModel, Connect: func(offset float64) (transcribe.Stream, error) {
+					opts := *tOptions
+					opts.Keywords = deepgramBatch.Keywords()
+					dgClient, err := client.NewWSUsingCallback(ctx, apiKeys.Get("deepgram"), cOptions, &opts, handler(offset))
+					if err != nil {
+						return nil, err
+					}
+					if !dgClient.Connect() {
+						return nil, errors.New("deepgram connect failed")
+					}
+					return dgClient, nil
+				}})
+			case config.TranscriptionProviderAssemblyAI, config.TranscriptionProviderWhisper:
+				if audio.Channels(mic) > 1 {
+					log.Printf("warning: %s transcription needs mono audio, skipping it with separate loopback channels", name)
+					continue
+				}
+				if name == config.TranscriptionProviderAssemblyAI {
+					providers = append(providers, transcribe.Provider{Name: name, Model: "universal-streaming", Connect: func(offset float64) (transcribe.Stream, error) {
+						return assemblyAI.Connect(ctx, handler(offset))
+					}})
+					continue
+				}
+				whisper := transcribe.NewWhisper(func() string { return apiKeys.Get("openai") }, cfg.Transcription.Whisper.BaseURL, cfg.Transcription.Whisper.Model, cfg.Transcription.Language)
+				providers = append(providers, transcribe.Provider{Name: name, Model: cfg.Transcription.Whisper.Model, Connect: func(offset float64) (transcribe.Stream, error) {
+					return transcribe.NewChunkedStream(name, whisper, audio.TargetSampleRate, handler(offset)), nil
+				}})
+			}
+		}
+
+		failover := transcribe.NewFailover(providers, audio.TargetSampleRate, audio.Channels(mic), func(p transcribe.Provider) {
+			manager.SetTranscriptionProvider(p.Name, p.Model)
+		})
+		chain.Store(failover)
+		failover.OnStatus = transcriptionState.set
+		_, connected := failover.Active()
+		if len(providers) == 0 {
+			log.Printf("warning: no live transcription provider usable, running API/UI only")
+			warnings = append(warnings, locale.T(i18n.WarnDeepgramInitFailed))
+		} else if !connected && !offlineCapture {
+			log.Printf("warning: no transcription provider connected, running API/UI only")
+			warnings = append(warnings, locale.T(i18n.WarnDeepgramConnectFailed))
 		} else {
-			dgWriter = dgClient
+			// With the offline fallback, capture starts anyway: audio is
+			// buffered and transcribed in batches until a provider connects.
+			if !connected {
+				log.Printf("warning: no transcription provider reachable, recording offline until one connects")
+				warnings = append(warnings, locale.T(i18n.WarnDeepgramOffline))
+			}
+			// Run reconnects a dropped stream with backoff, broadcasting
+			// transcription_status as it goes.
+			go failover.Run(ctx)
+			// Rotating a key or changing keywords reconnects the provider if
+			// it is transcribing, holding audio while it connects.
+			refreshLive = func(provider string) (bool, error) {
+				if p, ok := failover.Active(); !ok || p.Name != provider {
+					return false, nil
+				}
+				return true, failover.Refresh(provider)
+			}
+			dgWriter = failover
+			if cfg.Transcription.OfflineFallback && !offlineCapture {
+				log.Printf("offline fallback is not available with separate loopback channels")
+			} else if offlineCapture {
+				fallback := transcribe.NewFallbackWriter(
+					failover,
+					audio.TargetSampleRate,
+					time.Duration(cfg.Transcription.OfflineFallbackMaxMinutes)*time.Minute,
+					manager.CurrentSessionID,
+					func(gap transcribe.Gap) {
+						go func() {
+							if err := manager.RecoverGap(ctx, gap); err != nil {
+								log.Printf("warning: offline transcription recovery failed: %v", err)
+							}
+						}()
+					},
+				)
+				// The chain reconnects itself; its writes fail until it
+				// has, so the fallback buffers the outage and tries it
+				// again with every write.
+				dgWriter = fallback
+			}
+			if gate != nil {
+				gateTo.w = dgWriter
+				dgWriter = gate
+				log.Printf("voice-activity gate on: streaming only speech to Deepgram")
+				applyCalibration(captureDevice())
+			}
+			muteTo.w = dgWriter
+			recState.SetMute(mute)
+			clockTo.w = mute
+			dgWriter = clock
+			latency = callback.latency
+			calibrator = audio.NewCalibrator(audioRecorder.Writer(dgWriter), audio.TargetSampleRate, audio.Channels(mic))
 			dgStop = func() {
-				dgClient.Stop()
+				failover.Current().Stop()
+			}
+			// A stream open across a suspend is dead on waking, though it
+			// may take a while to say so.
+			dgReconnect = func() {
+				if !failover.Reconnect() {
+					log.Printf("warning: transcription not reconnected after resume, retrying")
+				}
 			}
 			go func() {
-				streamMicWithRetry(ctx, mic, audioRecorder.Writer(dgWriter), time.Sleep, log.Printf)
+				writer := audio.NewLevelMeter(
+					audio.NewResamplingWriter(
+						audio.NewDSPWriter(calibrator, audio.TargetSampleRate, audio.Channels(mic), dspOptions(cfg.DSP)),
+						mic.SampleRate(), audio.TargetSampleRate,
+					),
+					mic.SampleRate(), audio.Channels(mic), audioLevelInterval,
+					func(l audio.Level) {
+						hub.BroadcastAudioLevel(l.RMS, l.Peak, l.DBFS(), l.ClippedSamples)
+						inputMonitor.Observe(l)
+					},
+				)
+				// Only a switchable input can be reopened in place; other
+				// captures stay down once their device is lost.
+				var reopen func() error
+				if switchable != nil {
+					// Outside Linux the loopback is a PortAudio stream too,
+					// which re-enumerating devices would close.
+					refresh := cfg.Loopback.Mode == config.LoopbackModeOff || runtime.GOOS == "linux"
+					reopen = func() error {
+						before := switchable.Device()
+						if err := reopenMic(switchable, cfg.MicSampleRate, refresh, cfg.MicDevicePreferences); err != nil {
+							return err
+						}
+						if device := switchable.Device(); device != before {
+							log.Printf("capture moved to preferred device %q", device)
+							applyCalibration(device)
+						}
+						return nil
+					}
+				}
+				report := func(err error) { micState.set(captureDevice(), err) }
+				streamMicWithRetry(ctx, mic, writer, sleepCtx(ctx), log.Printf, reopen, report)
 			}()
 		}
 	}
 
+	// A preferred microphone plugged in while another one works is picked
+	// up by giving up the current input: the capture loop reopens it,
+	// re-enumerating devices and choosing by preference.
+	if switchable != nil && len(cfg.MicDevicePreferences) > 0 {
+		go audio.WatchDevices(ctx, deviceWatchInterval, func(cards []string) {
+			current := audio.PreferenceRank(switchable.Device(), cfg.MicDevicePreferences)
+			for _, card := range cards {
+				if audio.PreferenceRank(card, cfg.MicDevicePreferences) < current {
+					log.Printf("preferred microphone %q connected, switching capture to it", card)
+					switchable.Release()
+					return
+				}
+			}
+		})
+	}
+
+	if cfg.Power.SuspendAware {
+		onSuspend := func(at time.Time) {
+			// The note goes in while the session is still recording, so it
+			// is placed on its timeline.
+			if note, err := manager.AddNote(at, locale.T(i18n.NoteSuspended)); err == nil {
+				hub.BroadcastNoteAdded(note)
+			} else if !errors.Is(err, session.ErrNoActiveSession) {
+				log.Printf("warning: noting suspend failed: %v", err)
+			}
+			if err := manager.ForceEndSession(ctx); err != nil {
+				log.Printf("warning: ending session on suspend failed: %v", err)
+			}
+			// The capture loop reopens the input, reinitializing PortAudio,
+			// once the machine is awake.
+			if switchable != nil {
+				switchable.Release()
+			}
+		}
+		onResume := func() {
+			if switchable != nil {
+				switchable.Release()
+			}
+			if dgReconnect != nil {
+				dgReconnect()
+			}
+		}
+		go power.New(onSuspend, onResume).Run(ctx)
+	}
+
 	httpServer := &http.Server{Addr: ":8080", Handler: handler}
 	go func() {
 		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -366,12 +1307,27 @@ func main() {
 	if err := manager.ForceEndSession(shutdownCtx); err != nil {
 		log.Printf("warning: force end session failed: %v", err)
 	}
+	if err := manager.WaitForEncoding(shutdownCtx); err != nil {
+		log.Printf("warning: audio still encoding at shutdown, it will be recovered on restart: %v", err)
+	}
+	if uploader != nil {
+		// Imports are transcribed and summarized before they are stored,
+		// which takes longer than the rest of shutdown.
+		uploadCtx, uploadCancel := context.WithTimeout(context.Background(), uploadShutdownTimeout)
+		if err := uploader.Close(uploadCtx); err != nil {
+			log.Printf("warning: uploaded recordings still importing at shutdown were abandoned: %v", err)
+		}
+		uploadCancel()
+	}
 
 	if dgStop != nil {
 		dgStop()
 	}
 	if mic != nil {
 		_ = mic.Stop()
+		if closer, ok := mic.(io.Closer); ok {
+			_ = closer.Close()
+		}
 	}
 
 	if err := httpServer.Shutdown(shutdownCtx); err != nil {
@@ -379,16 +1335,285 @@ func main() {
 	}
 }
 
+// uploadShutdownTimeout is how long shutdown waits for uploaded recordings
+// to finish importing, within systemd's default stop timeout.
+const uploadShutdownTimeout = time.Minute
+
+// deepgramModel is the Deepgram model used for live and batch
+// transcription; nova-2 handles both single languages and "multi".
+const deepgramModel = "nova-2"
+
+// offlineQueueDir is where, under audio_dir, audio recorded while Deepgram
+// was unreachable waits to be transcribed.
+const offlineQueueDir = "offline-queue"
+
+// saveGapAudio keeps the audio of a session recovered from the offline
+// queue as FLAC in dir, with its waveform.
+func saveGapAudio(dir, sessionID string, gap transcribe.Gap) (string, error) {
+	path := filepath.Join(dir, sessionID+".flac")
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	if err := audio.EncodeFLAC(f, bytes.NewReader(gap.PCM), int64(len(gap.PCM)), gap.SampleRate, 1); err != nil {
+		_ = f.Close()
+		return "", errors.Join(err, os.Remove(path))
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+	audio.KeepWaveform(path, gap.PCM, gap.SampleRate)
+	return path, nil
+}
+
+// browserMicDevice names the web UI's microphone in mic_status.
+const browserMicDevice = "browser"
+
+// captureBuffer is the duration of audio read from the capture device per chunk.
+const captureBuffer = 250 * time.Millisecond
+
+// audioLevelInterval is how much audio each audio_level event covers. Matching
+// the capture chunk keeps the events evenly spaced rather than in bursts.
+const audioLevelInterval = captureBuffer
+
+// How long input must clip, or stay silent while recording, before it is
+// reported as a warning rather than a loud or quiet moment.
+const (
+	inputClippingWarnAfter = 30 * time.Second
+	inputSilentWarnAfter   = 10 * time.Minute
+)
+
+// dspOptions turns the dsp config into the audio cleanup to apply, none
+// unless it is enabled.
+func dspOptions(dsp config.DSP) audio.DSPOptions {
+	if !dsp.Enabled {
+		return audio.DSPOptions{}
+	}
+	opts := audio.DSPOptions{HighPassHz: dsp.HighPassHz, NoiseGateDB: dsp.NoiseGateDB}
+	if dsp.AGC {
+		opts.AGCTargetDB, opts.AGCMaxGainDB = dsp.AGCTargetDB, dsp.AGCMaxGainDB
+	}
+	return opts
+}
+
+// openCapture opens the configured capture: the microphone input, system
+// audio loopback, or the two mixed or on separate channels. A loopback that fails to open is skipped
+// in mix mode so the microphone keeps recording.
+func openCapture(cfg config.Config) (audio.Capture, error) {
+	if cfg.Loopback.Mode == config.LoopbackModeOnly {
+		return audio.NewLoopback(cfg.Loopback.Source, captureBuffer)
+	}
+
+	input, err := openInputCapture(cfg)
+	if err != nil || (cfg.Loopback.Mode != config.LoopbackModeMix && cfg.Loopback.Mode != config.LoopbackModeSeparate) {
+		return input, err
+	}
+	loopback, err := audio.NewLoopback(cfg.Loopback.Source, captureBuffer)
+	if err != nil {
+		log.Printf("warning: loopback capture unavailable, recording the microphone only: %v", err)
+		return input, nil
+	}
+	if cfg.Loopback.Mode == config.LoopbackModeSeparate {
+		channels := audio.NewChannelCapture(input, loopback)
+		channels.OnSourceError = func(_ int, err error) {
+			log.Printf("warning: loopback capture stopped, its channel is now silent: %v", err)
+		}
+		return channels, nil
+	}
+	mix := audio.NewMixCapture(audio.MixSource{Capture: input, Gain: 1}, audio.MixSource{Capture: loopback, Gain: cfg.Loopback.Gain})
+	mix.OnSourceError = func(_ int, err error) {
+		log.Printf("warning: loopback capture stopped, recording the microphone only: %v", err)
+	}
+	return mix, nil
+}
+
+// openInputCapture opens the configured input backend. For PortAudio that is
+// the first device matching mic_device_preferences, else mic_device or the
+// default microphone, wrapped so the device can be switched
+// at runtime, or every configured device, mixed or on separate channels per
+// mic_channels, when mic_devices is set; a zero mic_sample_rate captures at
+// the device's native rate.
+func openInputCapture(cfg config.Config) (audio.Capture, error) {
+	if cfg.CaptureBackend == config.CaptureBackendPipeWire {
+		pw, err := audio.NewPipeWireCapture(cfg.PipeWireTarget, captureBuffer)
+		if err != nil {
+			return nil, err
+		}
+		return pw, nil
+	}
+
+	if len(cfg.MicDevices) == 0 {
+		device := preferredMicDevice(cfg.MicDevicePreferences, cfg.MicDevice)
+		mic, err := openMicDevice(device, cfg.MicSampleRate)
+		if err != nil {
+			return nil, err
+		}
+		return audio.NewSwitchableCapture(mic, device), nil
+	}
+
+	mics := make([]audio.MicDevice, 0, len(cfg.MicDevices))
+	for _, d := range cfg.MicDevices {
+		mics = append(mics, audio.MicDevice{Name: d.Name, Gain: d.Gain})
+	}
+	newMulti := audio.NewMultiMic
+	if cfg.MicChannels == config.MicChannelsSeparate {
+		newMulti = audio.NewMultiMicChannels
+	}
+	multi, err := newMulti(mics, captureBuffer)
+	if err != nil {
+		return nil, err
+	}
+	return multi, nil
+}
+
+// preferredMicDevice is the first connected input matching preferences, or
+// fallback when none does.
+func preferredMicDevice(preferences []string, fallback string) string {
+	if len(preferences) == 0 {
+		return fallback
+	}
+	devices, err := audio.ListDevices()
+	if err != nil {
+		log.Printf("warning: choosing a preferred microphone failed: %v", err)
+		return fallback
+	}
+	if name, ok := audio.PreferredDevice(devices, preferences); ok {
+		return name
+	}
+	return fallback
+}
+
+// checkProviderKeys looks up the first configured summarization model of
+// each LLM provider that has a key, confirming the key is accepted.
+func checkProviderKeys(ctx context.Context, keys *llm.Keys, factory summary.ClientFactory, cfg config.Summarization) []server.KeyHealth {
+	models := []string{cfg.Model}
+	for _, name := range slices.Sorted(maps.Keys(cfg.Presets)) {
+		models = append(models, cfg.Presets[name].Model)
+	}
+
+	var health []server.KeyHealth
+	checked := map[string]bool{}
+	for _, m := range models {
+		provider, model, err := llm.ParseModel(m)
+		if err != nil || checked[provider] || keys.Get(provider) == "" {
+			continue
+		}
+		checked[provider] = true
+		h := server.KeyHealth{Provider: provider}
+		client, err := factory(provider, model)
+		if err == nil {
+			if checker, ok := client.(llm.Checker); ok {
+				err = checker.Check(ctx)
+			}
+		}
+		if err != nil {
+			h.Error = err.Error()
+		} else {
+			h.Healthy = true
+		}
+		health = append(health, h)
+	}
+	return health
+}
+
+// loadScript compiles a configured script, which must implement hook.
+func loadScript(sc config.Script, hook string) (*script.Script, error) {
+	s, err := script.Load(context.Background(), sc.Module, sc.ParsedTimeout(), sc.MemoryLimit())
+	if err != nil {
+		return nil, err
+	}
+	if !s.Exports(hook) {
+		_ = s.Close(context.Background())
+		return nil, fmt.Errorf("%s does not export %s", sc.Module, hook)
+	}
+	return s, nil
+}
+
+// usesDriveDocs reports whether summaries are put in Google Docs, for
+// review or by an export rule.
+func usesDriveDocs(cfg config.Config) bool {
+	if cfg.GDriveFolderID != "" && cfg.GDriveSummaryDocs {
+		return true
+	}
+	for _, rc := range cfg.Exports {
+		if rc.Target == config.ExportTargetGDrive {
+			return true
+		}
+	}
+	return false
+}
+
+// exportRules builds the configured export rules. Rules whose target can't
+// be reached are left out.
+func exportRules(cfg config.Config, locale *i18n.Localizer) []export.Rule {
+	rules := make([]export.Rule, 0, len(cfg.Exports))
+	for _, rc := range cfg.Exports {
+		r := export.Rule{
+			Name:        rc.Name,
+			On:          rc.On,
+			Kind:        rc.Target,
+			Presets:     rc.Presets,
+			Tags:        rc.Tags,
+			MinDuration: rc.ParsedMinDuration(),
+		}
+		summarization := cfg.Summarization
+		summarization.DocumentTemplate, summarization.Presets = rc.Template, nil
+		r.Renderer = summary.NewDocumentRenderer(summarization)
+		r.Renderer.Localizer = locale
+
+		switch rc.Target {
+		case config.ExportTargetMarkdown:
+			r.Target = export.Markdown{Dir: rc.Dir}
+		case config.ExportTargetSlack:
+			r.Target = export.Slack{WebhookURL: rc.WebhookURL, Channel: rc.Channel}
+		case config.ExportTargetGDrive:
+			docs, err := gdrive.NewDocs(context.Background(), cfg.GoogleCredentialsFile, rc.FolderID)
+			if err != nil {
+				log.Printf("warning: export rule %q disabled: %v", rc.Name, err)
+				continue
+			}
+			r.Target = export.Drive{Docs: docs}
+		}
+		rules = append(rules, r)
+	}
+	return rules
+}
+
+// openMicDevice opens the named PortAudio input, or the default one for "".
+func openMicDevice(name string, sampleRate int) (*audio.Mic, error) {
+	if name == "" {
+		return audio.NewMic(sampleRate, captureBuffer)
+	}
+	return audio.NewMicDevice(name, sampleRate, captureBuffer)
+}
+
 type micStreamer interface {
 	Stream(writer io.Writer) error
 }
 
+// Reopen attempts after losing the microphone back off from the first delay
+// to the last.
+const (
+	micReopenFirst = time.Second
+	micReopenMax   = 30 * time.Second
+)
+
+// deviceWatchInterval is how often sound cards are checked for a preferred
+// microphone being plugged in.
+const deviceWatchInterval = 2 * time.Second
+
+// streamMicWithRetry streams until ctx ends. Overflows restart the stream
+// at once. Any other error means the device is gone: report is told, and
+// reopen is retried with backoff until it succeeds and streaming resumes,
+// or, without reopen, streaming stops for good.
 func streamMicWithRetry(
 	ctx context.Context,
 	streamer micStreamer,
 	writer io.Writer,
 	wait func(time.Duration),
 	logf func(string, ...any),
+	reopen func() error,
+	report func(error),
 ) {
 	for {
 		if ctx.Err() != nil {
@@ -407,6 +1632,313 @@ func streamMicWithRetry(
 		}
 
 		logf("mic stream error: %v", err)
-		return
+		if report != nil {
+			report(err)
+		}
+		if reopen == nil {
+			return
+		}
+		for delay := micReopenFirst; ; delay = min(delay*2, micReopenMax) {
+			wait(delay)
+			if ctx.Err() != nil {
+				return
+			}
+			if err := reopen(); err != nil {
+				logf("microphone still unavailable, retrying in %v: %v", min(delay*2, micReopenMax), err)
+				continue
+			}
+			break
+		}
+		logf("microphone recovered")
+		if report != nil {
+			report(nil)
+		}
+	}
+}
+
+// micSampleRates are the capture rates tried, in order, when reopening a
+// lost microphone: the configured rate, if any, then the device's native
+// rate, then common rates.
+func micSampleRates(configured int) []int {
+	rates := []int{0, 48000, 44100, audio.TargetSampleRate}
+	if configured > 0 {
+		rates = append([]int{configured}, rates...)
+	}
+	return rates
+}
+
+// reopenMic reopens a lost microphone on the same device. With refresh it
+// re-enumerates devices first, since PortAudio only notices a device that
+// was plugged back in that way, and moves to the first device matching
+// preferences if one is connected.
+func reopenMic(switchable *audio.SwitchableCapture, configuredRate int, refresh bool, preferences []string) error {
+	return switchable.ReopenDevice(func(device string) (audio.Capture, string, error) {
+		if refresh {
+			if err := audio.RefreshDevices(); err != nil {
+				return nil, "", err
+			}
+			device = preferredMicDevice(preferences, device)
+		}
+		var errs []error
+		for _, rate := range micSampleRates(configuredRate) {
+			mic, err := openMicDevice(device, rate)
+			if errors.Is(err, audio.ErrDeviceNotFound) {
+				return nil, "", err
+			}
+			if err == nil {
+				log.Printf("microphone %q reopened at %d Hz", device, mic.SampleRate())
+				return mic, device, nil
+			}
+			errs = append(errs, err)
+		}
+		return nil, "", errors.Join(errs...)
+	})
+}
+
+// sleepCtx waits for d or until ctx ends, whichever comes first.
+func sleepCtx(ctx context.Context) func(time.Duration) {
+	return func(d time.Duration) {
+		t := time.NewTimer(d)
+		defer t.Stop()
+		select {
+		case <-ctx.Done():
+		case <-t.C:
+		}
+	}
+}
+
+// micHealth tracks whether capture is running, for /api/status, and
+// announces changes as mic_status events.
+type micHealth struct {
+	hub *server.Hub
+
+	mu    sync.Mutex
+	state server.MicHealth
+}
+
+func (m *micHealth) get() server.MicHealth {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.state
+}
+
+// set records capture on device as running, or lost with err.
+func (m *micHealth) set(device string, err error) {
+	state := server.MicHealth{Healthy: err == nil, Device: device, Since: time.Now().UTC()}
+	if err != nil {
+		state.Error = err.Error()
+	}
+	m.mu.Lock()
+	changed := m.state.Healthy != state.Healthy || m.state.Since.IsZero()
+	m.state = state
+	m.mu.Unlock()
+	if changed {
+		m.hub.BroadcastMicStatus(state)
+	}
+}
+
+type transcriptionHealth struct {
+	hub *server.Hub
+
+	mu    sync.Mutex
+	state server.TranscriptionStatus
+}
+
+func (t *transcriptionHealth) get() server.TranscriptionStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.state
+}
+
+// set records and broadcasts the live connection's status. Every failed
+// reconnect attempt is broadcast, so the UI can count them down.
+func (t *transcriptionHealth) set(s transcribe.Status) {
+	state := server.TranscriptionStatus{
+		State:          s.State,
+		Provider:       s.Provider,
+		Attempt:        s.Attempt,
+		RetryInSeconds: s.RetryIn.Seconds(),
+		Since:          time.Now().UTC(),
+	}
+	if s.Err != nil {
+		state.Error = s.Err.Error()
+	}
+	t.mu.Lock()
+	if t.state.State == state.State && !t.state.Since.IsZero() {
+		state.Since = t.state.Since
+	}
+	t.state = state
+	t.mu.Unlock()
+	t.hub.BroadcastTranscriptionStatus(state)
+}
+
+func runCommand(args []string) {
+	switch name := args[0]; name {
+	case "install-service":
+		spec, err := service.CurrentSpec(config.EnvPrefix)
+		if err != nil {
+			log.Fatalf("install-service: %v", err)
+		}
+		if err := service.Install(spec); err != nil {
+			log.Fatalf("install-service: %v", err)
+		}
+		fmt.Printf("installed %s service running %s from %s\n", service.Name, spec.Executable, spec.WorkingDir)
+	case "uninstall-service":
+		if err := service.Uninstall(); err != nil {
+			log.Fatalf("uninstall-service: %v", err)
+		}
+		fmt.Printf("removed %s service\n", service.Name)
+	case "decrypt":
+		if len(args) < 2 || len(args) > 3 {
+			fmt.Fprintln(os.Stderr, "usage: ghost-wispr decrypt <bundle"+bundle.Extension+"> [output.zip]")
+			os.Exit(2)
+		}
+		out := strings.TrimSuffix(args[1], bundle.Extension) + ".zip"
+		if len(args) == 3 {
+			out = args[2]
+		}
+		if err := decryptBundle(args[1], out); err != nil {
+			log.Fatalf("decrypt: %v", err)
+		}
+		fmt.Printf("decrypted %s to %s\n", args[1], out)
+	case "import":
+		if err := importTranscripts(args[1:]); err != nil {
+			log.Fatalf("import: %v", err)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q (expected install-service, uninstall-service, decrypt or import)\n", name)
+		os.Exit(2)
+	}
+}
+
+// importTranscripts stores transcripts exported from other tools as
+// finished sessions in the configured database.
+func importTranscripts(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	format := flags.String("format", "", "transcript format: "+strings.Join(ingest.Formats, ", "))
+	audioPath := flags.String("audio", "", "recording to keep with a single transcript (default: one with the same name next to it)")
+	startedAt := flags.String("started-at", "", "when a single conversation began, as RFC 3339 (default: the file's modification time)")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: ghost-wispr import --format <"+strings.Join(ingest.Formats, "|")+"> [--audio file] [--started-at time] <file>...")
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(args)
+	if *format == "" || flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	var start time.Time
+	if *startedAt != "" {
+		t, err := time.Parse(time.RFC3339, *startedAt)
+		if err != nil {
+			return fmt.Errorf("invalid --started-at: %w", err)
+		}
+		start = t
+	}
+
+	var transcripts []ingest.Transcript
+	var sources []string
+	for _, path := range flags.Args() {
+		parsed, err := ingest.ParseTranscripts(*format, path)
+		if err != nil {
+			return err
+		}
+		transcripts = append(transcripts, parsed...)
+		for range parsed {
+			sources = append(sources, path)
+		}
+	}
+	if len(transcripts) != 1 && (*audioPath != "" || !start.IsZero()) {
+		return fmt.Errorf("--audio and --started-at apply to a single transcript, found %d", len(transcripts))
+	}
+
+	configPath := os.Getenv(config.EnvPrefix + "CONFIG")
+	if configPath == "" {
+		configPath = "ghost-wispr.yaml"
+	}
+	cfg, _, err := config.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	store, err := storage.Open(cfg.DBPath)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	importer := ingest.NewTranscriptImporter(store, cfg.AudioDir, redact.New(cfg.Redaction.Kinds))
+	imported, skipped := 0, 0
+	for i, t := range transcripts {
+		if !start.IsZero() {
+			t.StartedAt = start
+		}
+		recording := *audioPath
+		if recording == "" && *format != ingest.FormatOtter {
+			recording = ingest.MatchingAudio(sources[i])
+		}
+		sessionID, err := importer.Import(t, recording)
+		switch {
+		case errors.Is(err, ingest.ErrAlreadyImported):
+			fmt.Printf("skipped %s: already imported as session %s\n", t.Name, sessionID)
+			skipped++
+		case err != nil:
+			return fmt.Errorf("%s: %w", t.Name, err)
+		default:
+			fmt.Printf("imported %s as session %s (%d segments)\n", t.Name, sessionID, len(t.Segments))
+			imported++
+		}
+	}
+	fmt.Printf("imported %d transcript(s), skipped %d\n", imported, skipped)
+	return nil
+}
+
+// decryptBundle writes the zip inside an encrypted session bundle to out. The
+// password comes from GHOST_WISPR_BUNDLE_PASSWORD or is read from stdin.
+func decryptBundle(in, out string) error {
+	password := os.Getenv(config.EnvPrefix + "BUNDLE_PASSWORD")
+	if password == "" {
+		fmt.Fprint(os.Stderr, "bundle password: ")
+		// Typed passwords aren't echoed; piped ones are read as a line.
+		if fd := int(os.Stdin.Fd()); term.IsTerminal(fd) {
+			typed, err := term.ReadPassword(fd)
+			fmt.Fprintln(os.Stderr)
+			if err != nil {
+				return fmt.Errorf("read password: %w", err)
+			}
+			password = string(typed)
+		} else {
+			line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+			if err != nil && line == "" {
+				return fmt.Errorf("read password: %w", err)
+			}
+			password = strings.TrimRight(line, "\r\n")
+		}
+	}
+
+	src, err := os.Open(in)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = src.Close() }()
+	plain, err := bundle.NewReader(src, password)
+	if err != nil {
+		return err
+	}
+
+	// Decrypt next to the output and rename, so a wrong password or a
+	// truncated bundle leaves no partial zip behind.
+	tmp, err := os.CreateTemp(filepath.Dir(out), ".ghost-wispr-decrypt-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := io.Copy(tmp, plain); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
 	}
+	return os.Rename(tmp.Name(), out)
 }
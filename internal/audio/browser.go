@@ -0,0 +1,156 @@
+package audio
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+)
+
+// ErrBrowserCaptureBusy is returned by Feed while another browser is
+// feeding the capture.
+var ErrBrowserCaptureBusy = errors.New("another browser is already sending audio")
+
+// browserQueue is how many written chunks are buffered between a browser
+// and the stream; beyond that audio is dropped rather than stalling the
+// connection it arrives on.
+const browserQueue = 64
+
+// BrowserCapture is a Capture fed by a web UI that captures the microphone
+// in the browser, for machines where the server has no audio device of its
+// own. It streams mono PCM at TargetSampleRate; while no browser is feeding
+// it, the stream simply waits.
+type BrowserCapture struct {
+	chunks chan []byte
+
+	mu      sync.Mutex
+	feeding bool
+	stopped bool
+	done    chan struct{}
+}
+
+// NewBrowserCapture returns a capture with no browser feeding it yet.
+func NewBrowserCapture() *BrowserCapture {
+	return &BrowserCapture{chunks: make(chan []byte, browserQueue), done: make(chan struct{})}
+}
+
+func (b *BrowserCapture) Start() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.stopped {
+		b.stopped = false
+		b.done = make(chan struct{})
+	}
+	return nil
+}
+
+func (b *BrowserCapture) Stop() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.stopped {
+		b.stopped = true
+		close(b.done)
+	}
+	return nil
+}
+
+func (b *BrowserCapture) SampleRate() int { return TargetSampleRate }
+
+// Stream writes audio from the browser to w until Stop.
+func (b *BrowserCapture) Stream(w io.Writer) error {
+	b.mu.Lock()
+	done := b.done
+	b.mu.Unlock()
+	for {
+		select {
+		case <-done:
+			return nil
+		case chunk := <-b.chunks:
+			if _, err := w.Write(chunk); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Feeding reports whether a browser is sending audio.
+func (b *BrowserCapture) Feeding() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.feeding
+}
+
+// Feed claims the capture for one browser sending mono PCM16-LE at
+// sampleRate. Closing the writer frees it for the next one.
+func (b *BrowserCapture) Feed(sampleRate int) (io.WriteCloser, error) {
+	if sampleRate <= 0 {
+		return nil, errors.New("sample rate must be positive")
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.feeding {
+		return nil, ErrBrowserCaptureBusy
+	}
+	b.feeding = true
+	f := &browserFeed{capture: b}
+	f.w = NewResamplingWriter(queueWriter{f}, sampleRate, TargetSampleRate)
+	return f, nil
+}
+
+type browserFeed struct {
+	capture *BrowserCapture
+	w       io.Writer
+	odd     []byte
+	dropped bool
+	closed  bool
+}
+
+// Write accepts frames of any length; a trailing odd byte is kept for the
+// next frame so samples are never split.
+func (f *browserFeed) Write(p []byte) (int, error) {
+	if f.closed {
+		return 0, io.ErrClosedPipe
+	}
+	n := len(p)
+	if len(f.odd) > 0 {
+		p = append(f.odd, p...)
+		f.odd = nil
+	}
+	if len(p)%2 == 1 {
+		f.odd = []byte{p[len(p)-1]}
+		p = p[:len(p)-1]
+	}
+	if len(p) > 0 {
+		if _, err := f.w.Write(p); err != nil {
+			return 0, err
+		}
+	}
+	return n, nil
+}
+
+func (f *browserFeed) Close() error {
+	if f.closed {
+		return nil
+	}
+	f.closed = true
+	f.capture.mu.Lock()
+	f.capture.feeding = false
+	f.capture.mu.Unlock()
+	return nil
+}
+
+// queueWriter hands resampled audio to the stream without blocking.
+type queueWriter struct{ f *browserFeed }
+
+func (q queueWriter) Write(p []byte) (int, error) {
+	select {
+	case q.f.capture.chunks <- append([]byte(nil), p...):
+		q.f.dropped = false
+	default:
+		if !q.f.dropped {
+			slog.Warn("browser audio queue full, dropping audio")
+			q.f.dropped = true
+		}
+	}
+	return len(p), nil
+}
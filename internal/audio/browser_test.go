@@ -0,0 +1,64 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBrowserCaptureStreamsFedAudio(t *testing.T) {
+	b := NewBrowserCapture()
+	if err := b.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	var out syncBuffer
+	done := make(chan error, 1)
+	go func() { done <- b.Stream(&out) }()
+
+	feed, err := b.Feed(TargetSampleRate)
+	if err != nil {
+		t.Fatalf("Feed failed: %v", err)
+	}
+	if _, err := b.Feed(TargetSampleRate); !errors.Is(err, ErrBrowserCaptureBusy) {
+		t.Fatalf("expected a second browser turned away, got %v", err)
+	}
+	if !b.Feeding() {
+		t.Fatal("expected the capture to report a browser feeding it")
+	}
+
+	// A sample split across frames still arrives whole.
+	quiet := binary.LittleEndian.AppendUint16(nil, 100)
+	split := binary.LittleEndian.AppendUint16(nil, 300)
+	if _, err := feed.Write(append(bytes.Repeat(quiet, 100), split[0])); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := feed.Write(append([]byte{split[1]}, bytes.Repeat(quiet, 99)...)); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !out.contains(300) {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for fed samples")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if err := feed.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if b.Feeding() {
+		t.Fatal("expected closing the feed to free the capture")
+	}
+	if _, err := b.Feed(48000); err != nil {
+		t.Fatalf("expected the next browser let in, got %v", err)
+	}
+
+	_ = b.Stop()
+	if err := <-done; err != nil {
+		t.Fatalf("expected the stream to end cleanly on Stop, got %v", err)
+	}
+}
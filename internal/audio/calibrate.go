@@ -0,0 +1,129 @@
+package audio
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// calibrationMargin is how far above the loudest room tone the speech
+// threshold is set, in dB.
+const calibrationMargin = 6
+
+// maxCalibratedThresholdDB caps a calibrated speech threshold, so a noisy
+// room can't produce one that ordinary speech never reaches.
+const maxCalibratedThresholdDB = -20
+
+// ErrCalibrationBusy is returned by Calibrator.Measure while another
+// measurement is running.
+var ErrCalibrationBusy = errors.New("calibration already in progress")
+
+// NoiseFloor describes the room tone heard during a calibration. Levels are
+// in dBFS over the frames a VADGate judges.
+type NoiseFloor struct {
+	// FloorDB is the median frame level.
+	FloorDB float64
+	// PeakDB is the 95th-percentile frame level, the loudest the room gets
+	// short of the odd click.
+	PeakDB float64
+	// ThresholdDB is a VAD threshold that the room tone stays under.
+	ThresholdDB float64
+	// Duration is how much audio was measured.
+	Duration time.Duration
+}
+
+// Calibrator passes PCM16-LE through to its destination unchanged and, on
+// request, measures the noise floor of what passes. Writes are expected to
+// hold whole samples.
+type Calibrator struct {
+	dst        io.Writer
+	frameBytes int
+
+	mu      sync.Mutex
+	pending []byte
+	levels  []float64
+	want    int
+	done    chan struct{}
+}
+
+// NewCalibrator measures audio at sampleRate with the given number of
+// interleaved channels.
+func NewCalibrator(dst io.Writer, sampleRate, channels int) *Calibrator {
+	frameSamples := max(int(int64(sampleRate)*int64(max(channels, 1))*int64(vadFrame)/int64(time.Second)), 1)
+	return &Calibrator{dst: dst, frameBytes: frameSamples * 2}
+}
+
+func (c *Calibrator) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	if c.done != nil {
+		c.pending = append(c.pending, p...)
+		for len(c.pending) >= c.frameBytes && len(c.levels) < c.want {
+			c.levels = append(c.levels, Level{RMS: frameRMS(c.pending[:c.frameBytes])}.DBFS())
+			c.pending = c.pending[c.frameBytes:]
+		}
+		if len(c.levels) >= c.want {
+			close(c.done)
+			c.done = nil
+		}
+	}
+	c.mu.Unlock()
+	return c.dst.Write(p)
+}
+
+// Measure listens to d of audio, which should be room tone with nobody
+// speaking, and reports its noise floor. It returns early with ctx's error
+// if the audio stops flowing.
+func (c *Calibrator) Measure(ctx context.Context, d time.Duration) (NoiseFloor, error) {
+	want := max(int(d/vadFrame), 1)
+	c.mu.Lock()
+	if c.done != nil {
+		c.mu.Unlock()
+		return NoiseFloor{}, ErrCalibrationBusy
+	}
+	done := make(chan struct{})
+	c.done, c.want, c.levels, c.pending = done, want, make([]float64, 0, want), nil
+	c.mu.Unlock()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		c.mu.Lock()
+		if c.done == done {
+			c.done = nil
+		}
+		c.mu.Unlock()
+		return NoiseFloor{}, ctx.Err()
+	}
+
+	c.mu.Lock()
+	levels := c.levels
+	c.levels = nil
+	c.mu.Unlock()
+	return measureNoiseFloor(levels), nil
+}
+
+func measureNoiseFloor(levels []float64) NoiseFloor {
+	sorted := append([]float64(nil), levels...)
+	sort.Float64s(sorted)
+	floor := percentile(sorted, 0.5)
+	peak := percentile(sorted, 0.95)
+	return NoiseFloor{
+		FloorDB:     floor,
+		PeakDB:      peak,
+		ThresholdDB: math.Min(peak+calibrationMargin, maxCalibratedThresholdDB),
+		Duration:    time.Duration(len(levels)) * vadFrame,
+	}
+}
+
+// percentile returns the p-th quantile of sorted, nearest-rank.
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return -96
+	}
+	i := int(math.Ceil(p*float64(len(sorted)))) - 1
+	return sorted[min(max(i, 0), len(sorted)-1)]
+}
@@ -0,0 +1,90 @@
+package audio
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestCalibratorMeasuresRoomTone(t *testing.T) {
+	var out bytes.Buffer
+	c := NewCalibrator(&out, 16000, 1)
+
+	// 320 samples per 20ms frame: a steady hum at about -60 dBFS, with one
+	// louder click that the percentiles shouldn't chase.
+	frame := func(amplitude float64) []byte {
+		samples := make([]int16, 320)
+		for i := range samples {
+			samples[i] = int16(amplitude * math.Sqrt2 * math.Sin(float64(i)/3))
+		}
+		return pcmBytes(samples)
+	}
+	hum := frame(math.MaxInt16 * math.Pow(10, -60.0/20))
+	click := frame(math.MaxInt16 * math.Pow(10, -20.0/20))
+
+	result := make(chan NoiseFloor, 1)
+	go func() {
+		floor, err := c.Measure(context.Background(), time.Second)
+		if err != nil {
+			t.Errorf("Measure failed: %v", err)
+		}
+		result <- floor
+	}()
+	waitFor(t, func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return c.done != nil
+	})
+
+	written := 0
+	for i := range 60 {
+		p := hum
+		if i == 10 {
+			p = click
+		}
+		n, err := c.Write(p)
+		if err != nil || n != len(p) {
+			t.Fatalf("Write returned %d, %v", n, err)
+		}
+		written += n
+	}
+	if out.Len() != written {
+		t.Fatalf("expected audio passed through unchanged, got %d of %d bytes", out.Len(), written)
+	}
+
+	floor := <-result
+	if math.Abs(floor.FloorDB+60) > 1 || math.Abs(floor.PeakDB+60) > 1 {
+		t.Fatalf("expected a -60 dBFS floor, got %+v", floor)
+	}
+	if math.Abs(floor.ThresholdDB-(floor.PeakDB+calibrationMargin)) > 1e-9 {
+		t.Fatalf("expected the threshold %d dB above the peak, got %+v", calibrationMargin, floor)
+	}
+	if floor.Duration != time.Second {
+		t.Fatalf("expected a second of audio measured, got %v", floor.Duration)
+	}
+}
+
+func TestCalibratorWithoutAudio(t *testing.T) {
+	c := NewCalibrator(&bytes.Buffer{}, 16000, 1)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := c.Measure(ctx, time.Second); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the deadline error, got %v", err)
+	}
+	// A cancelled measurement frees the calibrator for the next one.
+	ctx2, cancel2 := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel2()
+	if _, err := c.Measure(ctx2, time.Second); errors.Is(err, ErrCalibrationBusy) {
+		t.Fatal("calibrator still busy after a cancelled measurement")
+	}
+}
+
+func TestCalibratedThresholdIsCapped(t *testing.T) {
+	floor := measureNoiseFloor([]float64{-10, -10, -10})
+	if floor.ThresholdDB != maxCalibratedThresholdDB {
+		t.Fatalf("expected the threshold capped at %d dBFS, got %v", maxCalibratedThresholdDB, floor.ThresholdDB)
+	}
+}
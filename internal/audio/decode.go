@@ -0,0 +1,143 @@
+package audio
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+)
+
+// ErrUndecodable is returned by DecodeFile for audio it has no way to read:
+// anything but 16-bit PCM WAV when ffmpeg isn't installed.
+var ErrUndecodable = errors.New("cannot decode audio file")
+
+// DecodeFile reads an audio file as mono PCM16-LE at sampleRate. 16-bit PCM
+// WAV is streamed through directly; everything else is decoded with ffmpeg.
+func DecodeFile(path string, sampleRate int) ([]byte, error) {
+	var out bytes.Buffer
+	if err := DecodeTo(&out, path, sampleRate); err != nil {
+		return nil, err
+	}
+	return out.Bytes()[:out.Len()/2*2], nil
+}
+
+// DecodeTo decodes an audio file as DecodeFile does, writing the PCM to dst
+// as it goes so a long recording is never held in memory.
+func DecodeTo(dst io.Writer, path string, sampleRate int) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("read audio file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	in := bufio.NewReader(f)
+	if rate, channels, size, ok := readWAVHeader(in); ok {
+		if err := downmix(NewResamplingWriter(dst, rate, sampleRate), io.LimitReader(in, size), channels); err != nil {
+			return fmt.Errorf("read audio file: %w", err)
+		}
+		return nil
+	}
+
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		return fmt.Errorf("%w %s: not 16-bit WAV and ffmpeg is not installed", ErrUndecodable, path)
+	}
+	var stderr bytes.Buffer
+	cmd := exec.Command("ffmpeg", ffmpegDecodeArgs(path, sampleRate)...)
+	cmd.Stderr = &stderr
+	cmd.Stdout = dst
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%w %s: ffmpeg: %v: %s", ErrUndecodable, path, err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	return nil
+}
+
+func ffmpegDecodeArgs(path string, sampleRate int) []string {
+	return []string{
+		"-nostdin", "-v", "error",
+		"-i", path,
+		"-f", "s16le", "-acodec", "pcm_s16le",
+		"-ac", "1", "-ar", strconv.Itoa(sampleRate),
+		"-",
+	}
+}
+
+// readWAVHeader reads a 16-bit PCM WAV file up to its samples, returning
+// their format and how many bytes of them follow.
+func readWAVHeader(r io.Reader) (sampleRate, channels int, size int64, ok bool) {
+	var riff [12]byte
+	if _, err := io.ReadFull(r, riff[:]); err != nil || string(riff[:4]) != "RIFF" || string(riff[8:]) != "WAVE" {
+		return 0, 0, 0, false
+	}
+	for {
+		var chunk [8]byte
+		if _, err := io.ReadFull(r, chunk[:]); err != nil {
+			return 0, 0, 0, false
+		}
+		id, size := string(chunk[:4]), int64(binary.LittleEndian.Uint32(chunk[4:]))
+		switch id {
+		case "fmt ":
+			var body [16]byte
+			if size < 16 {
+				return 0, 0, 0, false
+			}
+			if _, err := io.ReadFull(r, body[:]); err != nil {
+				return 0, 0, 0, false
+			}
+			format := binary.LittleEndian.Uint16(body[0:])
+			channels = int(binary.LittleEndian.Uint16(body[2:]))
+			sampleRate = int(binary.LittleEndian.Uint32(body[4:]))
+			bits := binary.LittleEndian.Uint16(body[14:])
+			// 0xFFFE is WAVE_FORMAT_EXTENSIBLE, which ordinary recorders use
+			// for plain PCM too.
+			if (format != 1 && format != 0xFFFE) || bits != pcmBitDepth || channels < 1 || sampleRate < 1 {
+				return 0, 0, 0, false
+			}
+			size -= 16
+		case "data":
+			if channels == 0 {
+				return 0, 0, 0, false
+			}
+			return sampleRate, channels, size, true
+		}
+		// Chunks are padded to an even length.
+		if _, err := io.CopyN(io.Discard, r, size+size%2); err != nil {
+			return 0, 0, 0, false
+		}
+	}
+}
+
+// downmix averages the interleaved PCM16-LE read from src down to one
+// channel, writing it to dst a block at a time. A trailing partial frame is
+// dropped.
+func downmix(dst io.Writer, src io.Reader, channels int) error {
+	frame := 2 * channels
+	buf := make([]byte, 4096*frame)
+	out := make([]byte, 0, 4096*2)
+	for {
+		n, err := io.ReadFull(src, buf)
+		n -= n % frame
+		out = out[:0]
+		for i := 0; i < n; i += frame {
+			sum := 0
+			for c := range channels {
+				sum += int(int16(binary.LittleEndian.Uint16(buf[i+2*c:])))
+			}
+			out = binary.LittleEndian.AppendUint16(out, uint16(int16(sum/channels)))
+		}
+		if len(out) > 0 {
+			if _, err := dst.Write(out); err != nil {
+				return err
+			}
+		}
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package audio
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestDecodeFileReadsWAV(t *testing.T) {
+	// Half a second of stereo at 32 kHz, left and right at different levels
+	// so the downmix is visible, with an extra chunk before the data.
+	var pcm []int16
+	for range 16000 {
+		pcm = append(pcm, 1000, 3000)
+	}
+	data := pcmBytes(pcm)
+	header, err := wavHeader(len(data), 32000, 2, pcmBitDepth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := []byte("LIST\x03\x00\x00\x00abc\x00")
+	wav := append(append(append(header[:36:36], list...), header[36:]...), data...)
+	binary.LittleEndian.PutUint32(wav[4:], uint32(len(wav)-8))
+
+	path := filepath.Join(t.TempDir(), "memo.wav")
+	if err := os.WriteFile(path, wav, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out, err := DecodeFile(path, TargetSampleRate)
+	if err != nil {
+		t.Fatalf("DecodeFile failed: %v", err)
+	}
+	if n := len(out) / 2; n < 7990 || n > 8000 {
+		t.Fatalf("expected about 8000 samples at 16 kHz, got %d", len(out)/2)
+	}
+	if got := int16(binary.LittleEndian.Uint16(out[200:])); got != 2000 {
+		t.Fatalf("expected the channels averaged to 2000, got %d", got)
+	}
+}
+
+func TestDecodeFileReadsUnsizedWAV(t *testing.T) {
+	// Recorders streaming to disk leave the data size at its maximum.
+	data := pcmBytes(make([]int16, 1601))
+	header, err := wavHeader(len(data), TargetSampleRate, 1, pcmBitDepth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	binary.LittleEndian.PutUint32(header[40:], 0xFFFFFFFF)
+
+	path := filepath.Join(t.TempDir(), "memo.wav")
+	if err := os.WriteFile(path, append(header, data[:len(data)-1]...), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out, err := DecodeFile(path, TargetSampleRate)
+	if err != nil {
+		t.Fatalf("DecodeFile failed: %v", err)
+	}
+	if len(out) != 1600*2 {
+		t.Fatalf("expected the 1600 whole samples present, got %d bytes", len(out))
+	}
+}
+
+func TestDecodeFileWithoutFFmpeg(t *testing.T) {
+	if _, err := exec.LookPath("ffmpeg"); err == nil {
+		t.Skip("ffmpeg is installed")
+	}
+	path := filepath.Join(t.TempDir(), "memo.m4a")
+	if err := os.WriteFile(path, []byte("not a wav"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DecodeFile(path, TargetSampleRate); !errors.Is(err, ErrUndecodable) {
+		t.Fatalf("expected ErrUndecodable, got %v", err)
+	}
+}
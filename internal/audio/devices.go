@@ -0,0 +1,99 @@
+package audio
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gordonklaus/portaudio"
+)
+
+// ErrDeviceNotFound means no input device has the requested name.
+var ErrDeviceNotFound = errors.New("input device not found")
+
+// Device describes a PortAudio input device.
+type Device struct {
+	Name              string  `json:"name"`
+	HostAPI           string  `json:"host_api"`
+	MaxInputChannels  int     `json:"max_input_channels"`
+	DefaultSampleRate float64 `json:"default_sample_rate"`
+	// Default is set on the system default input device.
+	Default bool `json:"default"`
+}
+
+// RefreshDevices re-enumerates PortAudio's devices, which it otherwise only
+// does at startup, so an input plugged in since can be opened. It closes
+// every PortAudio stream, so call it only when none are in use.
+func RefreshDevices() error {
+	if err := portaudio.Terminate(); err != nil {
+		return fmt.Errorf("terminate portaudio: %w", err)
+	}
+	if err := portaudio.Initialize(); err != nil {
+		return fmt.Errorf("initialize portaudio: %w", err)
+	}
+	return nil
+}
+
+// ListDevices returns every PortAudio device with at least one input
+// channel. PortAudio must already be initialized.
+func ListDevices() ([]Device, error) {
+	devices, err := portaudio.Devices()
+	if err != nil {
+		return nil, fmt.Errorf("list audio devices: %w", err)
+	}
+	var defaultName string
+	if d, err := portaudio.DefaultInputDevice(); err == nil {
+		defaultName = d.Name
+	}
+
+	inputs := []Device{}
+	for _, d := range devices {
+		if d.MaxInputChannels <= 0 {
+			continue
+		}
+		hostAPI := ""
+		if d.HostApi != nil {
+			hostAPI = d.HostApi.Name
+		}
+		inputs = append(inputs, Device{
+			Name:              d.Name,
+			HostAPI:           hostAPI,
+			MaxInputChannels:  d.MaxInputChannels,
+			DefaultSampleRate: d.DefaultSampleRate,
+			Default:           d.Name == defaultName,
+		})
+	}
+	return inputs, nil
+}
+
+// PreferredDevice picks the input to capture from given device name patterns
+// in order of preference, such as a headset before the laptop's built-in
+// microphone. A pattern matches any device whose name contains it, ignoring
+// case. It returns the first device matching the earliest pattern, and false
+// when none match.
+func PreferredDevice(devices []Device, patterns []string) (string, bool) {
+	for _, pattern := range patterns {
+		for _, d := range devices {
+			if matchesPattern(d.Name, pattern) {
+				return d.Name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// PreferenceRank is the position in patterns of the first one name matches,
+// as PreferredDevice ranks devices, or len(patterns) when none does.
+func PreferenceRank(name string, patterns []string) int {
+	for i, pattern := range patterns {
+		if matchesPattern(name, pattern) {
+			return i
+		}
+	}
+	return len(patterns)
+}
+
+func matchesPattern(name, pattern string) bool {
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	return pattern != "" && strings.Contains(strings.ToLower(name), pattern)
+}
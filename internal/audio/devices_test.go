@@ -0,0 +1,42 @@
+package audio
+
+import "testing"
+
+func TestPreferredDevice(t *testing.T) {
+	devices := []Device{
+		{Name: "MacBook Pro Microphone"},
+		{Name: "Jabra Evolve2 65"},
+		{Name: "ZoomAudioDevice"},
+	}
+
+	for _, tc := range []struct {
+		patterns []string
+		want     string
+		ok       bool
+	}{
+		{[]string{"jabra", "MacBook Pro Microphone"}, "Jabra Evolve2 65", true},
+		{[]string{"Yeti", "MacBook"}, "MacBook Pro Microphone", true},
+		{[]string{"", "  zoom "}, "ZoomAudioDevice", true},
+		{[]string{"Yeti"}, "", false},
+		{nil, "", false},
+	} {
+		got, ok := PreferredDevice(devices, tc.patterns)
+		if got != tc.want || ok != tc.ok {
+			t.Errorf("PreferredDevice(%q) = %q, %v; want %q, %v", tc.patterns, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestPreferenceRank(t *testing.T) {
+	patterns := []string{"jabra", "", "MacBook"}
+	for name, want := range map[string]int{
+		"Jabra Link 380: USB Audio (hw:1,0)": 0,
+		"MacBook Pro Microphone":             2,
+		"ZoomAudioDevice":                    3,
+		"":                                   3,
+	} {
+		if got := PreferenceRank(name, patterns); got != want {
+			t.Errorf("PreferenceRank(%q) = %d, want %d", name, got, want)
+		}
+	}
+}
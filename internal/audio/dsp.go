@@ -0,0 +1,207 @@
+package audio
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"time"
+)
+
+// Time constants of the noise gate and AGC. The gate opens almost at once
+// so onsets aren't clipped and holds a moment before fading out, so word
+// endings aren't either. The AGC cuts quickly when speech gets loud but
+// only raises its gain slowly, so it doesn't pump up the pauses.
+const (
+	gateRelease   = 50 * time.Millisecond
+	gateHold      = 250 * time.Millisecond
+	gateOpenTime  = 2 * time.Millisecond
+	gateCloseTime = 100 * time.Millisecond
+	agcWindow     = 400 * time.Millisecond
+	agcAttack     = 10 * time.Millisecond
+	agcRelease    = time.Second
+)
+
+// agcFloorDB is the level below which audio is taken for silence and the
+// AGC holds its gain instead of boosting it.
+const agcFloorDB = -55
+
+// DSPOptions configure the cleanup NewDSPWriter applies. A stage left at
+// zero is skipped.
+type DSPOptions struct {
+	// HighPassHz filters out rumble below this frequency: air handling,
+	// traffic, knocks on the desk.
+	HighPassHz float64
+	// NoiseGateDB silences audio while it stays quieter than this level, in
+	// dBFS.
+	NoiseGateDB float64
+	// AGCTargetDB steers the level of speech toward this loudness, in dBFS.
+	AGCTargetDB float64
+	// AGCMaxGainDB caps how far the AGC boosts or cuts, in dB.
+	AGCMaxGainDB float64
+}
+
+// NewDSPWriter runs PCM16-LE at sampleRate, with the given number of
+// interleaved channels, through a high-pass filter, a noise gate and
+// automatic gain control before passing it on to dst. The stages run in
+// that order so the gate and AGC judge loudness without the rumble. Writes
+// are expected to hold whole samples.
+func NewDSPWriter(dst io.Writer, sampleRate, channels int, opts DSPOptions) io.Writer {
+	if opts.HighPassHz <= 0 && opts.NoiseGateDB == 0 && opts.AGCTargetDB == 0 {
+		return dst
+	}
+	channels = max(channels, 1)
+	rate := float64(sampleRate)
+	d := &dspWriter{dst: dst, channels: channels, gateGain: 1, agcGain: 1}
+	if opts.HighPassHz > 0 && opts.HighPassHz < rate/2 {
+		d.highPass = make([]biquad, channels)
+		for i := range d.highPass {
+			d.highPass[i] = newHighPass(opts.HighPassHz, rate)
+		}
+	}
+	if opts.NoiseGateDB != 0 {
+		d.gate = true
+		d.gateThreshold = dbToAmplitude(opts.NoiseGateDB)
+		d.gateRelease = decay(gateRelease, rate)
+		d.gateHold = int(gateHold.Seconds() * rate)
+		d.gateOpen = 1 - decay(gateOpenTime, rate)
+		d.gateClose = 1 - decay(gateCloseTime, rate)
+	}
+	if opts.AGCTargetDB != 0 {
+		d.agc = true
+		d.agcTarget = dbToAmplitude(opts.AGCTargetDB)
+		d.agcMaxGain = dbToAmplitude(max(opts.AGCMaxGainDB, 0))
+		d.agcFloor = dbToAmplitude(agcFloorDB)
+		d.agcWindow = 1 - decay(agcWindow, rate)
+		d.agcAttack = 1 - decay(agcAttack, rate)
+		d.agcRelease = 1 - decay(agcRelease, rate)
+	}
+	return d
+}
+
+type dspWriter struct {
+	dst      io.Writer
+	channels int
+	out      []byte
+
+	// ch is the channel of the next sample; peak and sumSq accumulate the
+	// frame of samples in progress.
+	ch    int
+	peak  float64
+	sumSq float64
+
+	highPass []biquad
+
+	gate          bool
+	gateThreshold float64
+	gateRelease   float64
+	gateHold      int
+	gateOpen      float64
+	gateClose     float64
+	gateEnv       float64
+	gateHeld      int
+	gateGain      float64
+
+	agc        bool
+	agcTarget  float64
+	agcMaxGain float64
+	agcFloor   float64
+	agcWindow  float64
+	agcAttack  float64
+	agcRelease float64
+	agcMeanSq  float64
+	agcGain    float64
+}
+
+func (d *dspWriter) Write(p []byte) (int, error) {
+	d.out = append(d.out[:0], p...)
+	for i := 0; i+1 < len(d.out); i += 2 {
+		s := float64(int16(binary.LittleEndian.Uint16(d.out[i:])))
+		binary.LittleEndian.PutUint16(d.out[i:], uint16(clip16(d.process(s))))
+	}
+	if _, err := d.dst.Write(d.out); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// process filters one sample. Gains are worked out once per frame, from the
+// frame's loudest channel, so every channel fades together.
+func (d *dspWriter) process(s float64) float64 {
+	if d.highPass != nil {
+		s = d.highPass[d.ch].process(s)
+	}
+	d.peak = max(d.peak, math.Abs(s)/math.MaxInt16)
+	s *= d.gateGain
+	d.sumSq += s * s
+	s *= d.agcGain
+
+	d.ch++
+	if d.ch == d.channels {
+		d.endFrame()
+	}
+	return s
+}
+
+func (d *dspWriter) endFrame() {
+	if d.gate {
+		d.gateEnv = max(d.peak, d.gateEnv*d.gateRelease)
+		if d.gateEnv >= d.gateThreshold {
+			d.gateHeld = d.gateHold
+		} else if d.gateHeld > 0 {
+			d.gateHeld--
+		}
+		if d.gateHeld > 0 {
+			d.gateGain += (1 - d.gateGain) * d.gateOpen
+		} else {
+			d.gateGain -= d.gateGain * d.gateClose
+		}
+	}
+	if d.agc {
+		ms := d.sumSq / float64(d.channels) / (math.MaxInt16 * math.MaxInt16)
+		d.agcMeanSq += (ms - d.agcMeanSq) * d.agcWindow
+		if level := math.Sqrt(d.agcMeanSq); level >= d.agcFloor {
+			want := min(max(d.agcTarget/level, 1/d.agcMaxGain), d.agcMaxGain)
+			if want < d.agcGain {
+				d.agcGain += (want - d.agcGain) * d.agcAttack
+			} else {
+				d.agcGain += (want - d.agcGain) * d.agcRelease
+			}
+		}
+	}
+	d.ch, d.peak, d.sumSq = 0, 0, 0
+}
+
+// biquad is a second-order IIR filter section.
+type biquad struct {
+	b0, b1, b2, a1, a2 float64
+	x1, x2, y1, y2     float64
+}
+
+// newHighPass is a Butterworth high-pass filter with its corner at cutoff
+// Hz.
+func newHighPass(cutoff, sampleRate float64) biquad {
+	w0 := 2 * math.Pi * cutoff / sampleRate
+	cos, alpha := math.Cos(w0), math.Sin(w0)/math.Sqrt2
+	a0 := 1 + alpha
+	return biquad{
+		b0: (1 + cos) / 2 / a0,
+		b1: -(1 + cos) / a0,
+		b2: (1 + cos) / 2 / a0,
+		a1: -2 * cos / a0,
+		a2: (1 - alpha) / a0,
+	}
+}
+
+func (f *biquad) process(x float64) float64 {
+	y := f.b0*x + f.b1*f.x1 + f.b2*f.x2 - f.a1*f.y1 - f.a2*f.y2
+	f.x2, f.x1 = f.x1, x
+	f.y2, f.y1 = f.y1, y
+	return y
+}
+
+// decay is the per-frame factor that shrinks a value to 1/e over d.
+func decay(d time.Duration, frameRate float64) float64 {
+	return math.Exp(-1 / (d.Seconds() * frameRate))
+}
+
+func dbToAmplitude(db float64) float64 { return math.Pow(10, db/20) }
@@ -0,0 +1,109 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+// tone is seconds of a sine at hz and dbfs RMS, sampled at 16 kHz.
+func tone(hz, dbfs, seconds float64) []int16 {
+	amplitude := math.MaxInt16 * math.Sqrt2 * math.Pow(10, dbfs/20)
+	samples := make([]int16, int(seconds*TargetSampleRate))
+	for i := range samples {
+		samples[i] = int16(amplitude * math.Sin(2*math.Pi*hz*float64(i)/TargetSampleRate))
+	}
+	return samples
+}
+
+// runDSP passes samples through a DSP writer in 250ms writes and returns the
+// level, in dBFS, of the last tenth of what came out.
+func runDSP(t *testing.T, opts DSPOptions, samples []int16) float64 {
+	t.Helper()
+	var out bytes.Buffer
+	w := NewDSPWriter(&out, TargetSampleRate, 1, opts)
+	in := pcmBytes(samples)
+	for len(in) > 0 {
+		n := min(len(in), TargetSampleRate/2)
+		if written, err := w.Write(in[:n]); err != nil || written != n {
+			t.Fatalf("Write returned %d, %v", written, err)
+		}
+		in = in[n:]
+	}
+	if out.Len() != len(samples)*2 {
+		t.Fatalf("expected %d bytes out, got %d", len(samples)*2, out.Len())
+	}
+	tail := out.Bytes()[out.Len()*9/10:]
+	return Level{RMS: frameRMS(tail)}.DBFS()
+}
+
+func TestDSPWriterDisabledPassesThrough(t *testing.T) {
+	var out bytes.Buffer
+	if w := NewDSPWriter(&out, TargetSampleRate, 1, DSPOptions{}); w != &out {
+		t.Fatal("expected no stage to leave the destination unwrapped")
+	}
+}
+
+func TestDSPHighPassRemovesRumble(t *testing.T) {
+	opts := DSPOptions{HighPassHz: 100}
+	if got := runDSP(t, opts, tone(30, -20, 1)); got > -38 {
+		t.Fatalf("expected a 30 Hz hum cut by 18 dB or more, got %.1f dBFS", got)
+	}
+	if got := runDSP(t, opts, tone(1000, -20, 1)); math.Abs(got+20) > 0.5 {
+		t.Fatalf("expected a 1 kHz tone untouched, got %.1f dBFS", got)
+	}
+}
+
+func TestDSPNoiseGate(t *testing.T) {
+	opts := DSPOptions{NoiseGateDB: -50}
+	if got := runDSP(t, opts, tone(440, -65, 1)); got > -90 {
+		t.Fatalf("expected room tone under the gate silenced, got %.1f dBFS", got)
+	}
+	if got := runDSP(t, opts, tone(440, -30, 1)); math.Abs(got+30) > 0.5 {
+		t.Fatalf("expected speech over the gate untouched, got %.1f dBFS", got)
+	}
+
+	// After speech stops, the gate closes once its hold runs out.
+	speech := append(tone(440, -30, 1), tone(440, -65, 1)...)
+	if got := runDSP(t, opts, speech); got > -90 {
+		t.Fatalf("expected the gate closed after speech, got %.1f dBFS", got)
+	}
+}
+
+func TestDSPAutomaticGainControl(t *testing.T) {
+	opts := DSPOptions{AGCTargetDB: -20, AGCMaxGainDB: 12}
+	if got := runDSP(t, opts, tone(440, -26, 5)); math.Abs(got+20) > 1 {
+		t.Fatalf("expected quiet speech raised to -20 dBFS, got %.1f dBFS", got)
+	}
+	if got := runDSP(t, opts, tone(440, -10, 2)); math.Abs(got+20) > 1 {
+		t.Fatalf("expected loud speech lowered to -20 dBFS, got %.1f dBFS", got)
+	}
+	if got := runDSP(t, opts, tone(440, -45, 5)); math.Abs(got+33) > 1 {
+		t.Fatalf("expected the boost capped at 12 dB, got %.1f dBFS", got)
+	}
+	// Silence isn't boosted at all.
+	if got := runDSP(t, opts, tone(440, -70, 5)); math.Abs(got+70) > 1 {
+		t.Fatalf("expected silence left alone, got %.1f dBFS", got)
+	}
+}
+
+func TestDSPStereoFadesChannelsTogether(t *testing.T) {
+	var out bytes.Buffer
+	w := NewDSPWriter(&out, TargetSampleRate, 2, DSPOptions{NoiseGateDB: -50})
+	loud, quiet := tone(440, -30, 1), tone(440, -65, 1)
+	var interleaved []int16
+	for i := range loud {
+		interleaved = append(interleaved, loud[i], quiet[i])
+	}
+	if _, err := w.Write(pcmBytes(interleaved)); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	var right []byte
+	for i := out.Len() / 2; i+3 < out.Len(); i += 4 {
+		right = binary.LittleEndian.AppendUint16(right, binary.LittleEndian.Uint16(out.Bytes()[i+2:]))
+	}
+	if got := (Level{RMS: frameRMS(right)}).DBFS(); math.Abs(got+65) > 0.5 {
+		t.Fatalf("expected the quiet channel kept open by the loud one, got %.1f dBFS", got)
+	}
+}
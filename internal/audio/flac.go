@@ -0,0 +1,411 @@
+package audio
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+)
+
+// flacBlockSize is the number of samples per channel in each FLAC frame.
+const flacBlockSize = 4096
+
+// pcmToFLAC compresses raw PCM16-LE into a FLAC file with the built-in
+// encoder, so sessions are compressed losslessly even without ffmpeg or lame.
+func pcmToFLAC(rawPath, flacPath string, sampleRate, channels int) error {
+	in, err := os.Open(rawPath)
+	if err != nil {
+		return fmt.Errorf("open raw pcm data: %w", err)
+	}
+	defer func() { _ = in.Close() }()
+	info, err := in.Stat()
+	if err != nil {
+		return fmt.Errorf("stat raw pcm data: %w", err)
+	}
+
+	out, err := os.OpenFile(flacPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("open flac output: %w", err)
+	}
+	w := bufio.NewWriter(out)
+	if err := EncodeFLAC(w, bufio.NewReader(in), info.Size(), sampleRate, channels); err != nil {
+		_ = out.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		_ = out.Close()
+		return fmt.Errorf("write flac output: %w", err)
+	}
+	return out.Close()
+}
+
+// EncodeFLAC writes size bytes of interleaved PCM16-LE from r to w as a
+// FLAC stream. Each channel is coded independently with the best fixed
+// linear predictor per frame and partitioned Rice residuals, which is close
+// to what reference encoders reach for speech at their fast settings.
+func EncodeFLAC(w io.Writer, r io.Reader, size int64, sampleRate, channels int) error {
+	if channels < 1 || channels > 8 {
+		return fmt.Errorf("flac supports 1 to 8 channels, got %d", channels)
+	}
+	if sampleRate <= 0 || sampleRate >= 1<<20 {
+		return fmt.Errorf("unsupported flac sample rate %d", sampleRate)
+	}
+	frameBytes := int64(channels * 2)
+	totalSamples := size / frameBytes
+
+	if err := writeFLACHeader(w, sampleRate, channels, totalSamples); err != nil {
+		return fmt.Errorf("write flac header: %w", err)
+	}
+
+	buf := make([]byte, flacBlockSize*int(frameBytes))
+	samples := make([][]int32, channels)
+	for ch := range samples {
+		samples[ch] = make([]int32, flacBlockSize)
+	}
+	var frame bytes.Buffer
+	for n, remaining := uint64(0), totalSamples; remaining > 0; n++ {
+		block := int(min(remaining, flacBlockSize))
+		if _, err := io.ReadFull(r, buf[:block*int(frameBytes)]); err != nil {
+			return fmt.Errorf("read pcm block: %w", err)
+		}
+		for i := range block {
+			for ch := range channels {
+				off := (i*channels + ch) * 2
+				samples[ch][i] = int32(int16(binary.LittleEndian.Uint16(buf[off:])))
+			}
+		}
+
+		frame.Reset()
+		encodeFLACFrame(&frame, n, sampleRate, channels, block, samples)
+		if _, err := w.Write(frame.Bytes()); err != nil {
+			return fmt.Errorf("write flac frame: %w", err)
+		}
+		remaining -= int64(block)
+	}
+	return nil
+}
+
+func writeFLACHeader(w io.Writer, sampleRate, channels int, totalSamples int64) error {
+	var b bitWriter
+	b.bytes = append(b.bytes, "fLaC"...)
+	// Last metadata block, type STREAMINFO, 34 bytes.
+	b.write(1, 1)
+	b.write(0, 7)
+	b.write(34, 24)
+	b.write(flacBlockSize, 16)
+	b.write(flacBlockSize, 16)
+	b.write(0, 24) // minimum frame size unknown
+	b.write(0, 24) // maximum frame size unknown
+	b.write(uint64(sampleRate), 20)
+	b.write(uint64(channels-1), 3)
+	b.write(15, 5) // 16 bits per sample
+	b.write(uint64(totalSamples), 36)
+	b.bytes = append(b.bytes, make([]byte, 16)...) // MD5 not computed
+	_, err := w.Write(b.bytes)
+	return err
+}
+
+func encodeFLACFrame(dst *bytes.Buffer, number uint64, sampleRate, channels, block int, samples [][]int32) {
+	var b bitWriter
+	b.write(0x3ffe, 14) // sync
+	b.write(0, 1)
+	b.write(0, 1) // fixed block size
+	b.write(0x7, 4)
+	rateCode, rateBits, rateValue := flacRateCode(sampleRate)
+	b.write(rateCode, 4)
+	b.write(uint64(channels-1), 4) // independent channels
+	b.write(0x4, 3)                // 16 bits per sample
+	b.write(0, 1)
+	b.writeUTF8(number)
+	b.write(uint64(block-1), 16)
+	if rateBits > 0 {
+		b.write(rateValue, rateBits)
+	}
+	b.write(uint64(crc8(b.bytes)), 8)
+
+	for ch := range channels {
+		encodeFLACSubframe(&b, samples[ch][:block])
+	}
+	b.align()
+	b.write(uint64(crc16(b.bytes)), 16)
+	dst.Write(b.bytes)
+}
+
+// flacRateCode returns the frame header sample rate code and any value
+// that follows the header for rates without a code of their own.
+func flacRateCode(rate int) (code uint64, bits uint, value uint64) {
+	switch rate {
+	case 88200:
+		return 0x1, 0, 0
+	case 176400:
+		return 0x2, 0, 0
+	case 192000:
+		return 0x3, 0, 0
+	case 8000:
+		return 0x4, 0, 0
+	case 16000:
+		return 0x5, 0, 0
+	case 22050:
+		return 0x6, 0, 0
+	case 24000:
+		return 0x7, 0, 0
+	case 32000:
+		return 0x8, 0, 0
+	case 44100:
+		return 0x9, 0, 0
+	case 48000:
+		return 0xa, 0, 0
+	case 96000:
+		return 0xb, 0, 0
+	}
+	switch {
+	case rate%1000 == 0 && rate/1000 < 256:
+		return 0xc, 8, uint64(rate / 1000)
+	case rate < 1<<16:
+		return 0xd, 16, uint64(rate)
+	case rate%10 == 0 && rate/10 < 1<<16:
+		return 0xe, 16, uint64(rate / 10)
+	}
+	return 0x0, 0, 0 // from STREAMINFO
+}
+
+func encodeFLACSubframe(b *bitWriter, samples []int32) {
+	constant := true
+	for _, s := range samples[1:] {
+		if s != samples[0] {
+			constant = false
+			break
+		}
+	}
+	if constant {
+		b.write(0, 1)
+		b.write(0, 6)
+		b.write(0, 1)
+		b.writeSigned(samples[0], 16)
+		return
+	}
+
+	order, residual := bestFixedPredictor(samples)
+	partitionOrder, params, bits := bestRicePartitions(residual, order, len(samples))
+	if verbatim := len(samples) * 16; bits+order*16 >= verbatim {
+		b.write(0, 1)
+		b.write(1, 6)
+		b.write(0, 1)
+		for _, s := range samples {
+			b.writeSigned(s, 16)
+		}
+		return
+	}
+
+	b.write(0, 1)
+	b.write(uint64(0x8|order), 6)
+	b.write(0, 1)
+	for _, s := range samples[:order] {
+		b.writeSigned(s, 16)
+	}
+	b.write(0, 2) // Rice coding with 4-bit parameters
+	b.write(uint64(partitionOrder), 4)
+	partitions := 1 << partitionOrder
+	size := len(samples) >> partitionOrder
+	start := 0
+	for p := range partitions {
+		n := size
+		if p == 0 {
+			n -= order
+		}
+		k := params[p]
+		b.write(uint64(k), 4)
+		for _, r := range residual[start : start+n] {
+			b.writeRice(zigzag(r), k)
+		}
+		start += n
+	}
+}
+
+// bestFixedPredictor returns the fixed predictor order (0-4) whose residual
+// has the smallest magnitude, and that residual.
+func bestFixedPredictor(samples []int32) (int, []int32) {
+	bestOrder, bestSum := 0, uint64(1<<63)
+	for order := 0; order <= 4 && order < len(samples); order++ {
+		var sum uint64
+		for i := order; i < len(samples); i++ {
+			r := fixedResidual(samples, i, order)
+			if r < 0 {
+				r = -r
+			}
+			sum += uint64(r)
+		}
+		if sum < bestSum {
+			bestOrder, bestSum = order, sum
+		}
+	}
+	residual := make([]int32, 0, len(samples)-bestOrder)
+	for i := bestOrder; i < len(samples); i++ {
+		residual = append(residual, fixedResidual(samples, i, bestOrder))
+	}
+	return bestOrder, residual
+}
+
+func fixedResidual(s []int32, i, order int) int32 {
+	switch order {
+	case 0:
+		return s[i]
+	case 1:
+		return s[i] - s[i-1]
+	case 2:
+		return s[i] - 2*s[i-1] + s[i-2]
+	case 3:
+		return s[i] - 3*s[i-1] + 3*s[i-2] - s[i-3]
+	default:
+		return s[i] - 4*s[i-1] + 6*s[i-2] - 4*s[i-3] + s[i-4]
+	}
+}
+
+// bestRicePartitions picks the partition order and per-partition Rice
+// parameters that code residual in the fewest bits.
+func bestRicePartitions(residual []int32, order, block int) (int, []int, int) {
+	bestOrder, bestBits := 0, -1
+	var bestParams []int
+	for p := 0; p <= 8; p++ {
+		if block%(1<<p) != 0 || block>>p <= order {
+			break
+		}
+		size := block >> p
+		params := make([]int, 1<<p)
+		bits := 6
+		start := 0
+		for i := range params {
+			n := size
+			if i == 0 {
+				n -= order
+			}
+			k, kBits := bestRiceParam(residual[start : start+n])
+			params[i] = k
+			bits += 4 + kBits
+			start += n
+		}
+		if bestBits < 0 || bits < bestBits {
+			bestOrder, bestBits, bestParams = p, bits, params
+		}
+	}
+	return bestOrder, bestParams, bestBits
+}
+
+func bestRiceParam(residual []int32) (int, int) {
+	var sum uint64
+	for _, r := range residual {
+		sum += uint64(zigzag(r))
+	}
+	guess := 0
+	if n := uint64(len(residual)); n > 0 {
+		for mean := sum / n; mean > 1 && guess < 14; mean >>= 1 {
+			guess++
+		}
+	}
+	bestK, bestBits := 0, -1
+	for k := max(guess-1, 0); k <= min(guess+1, 14); k++ {
+		bits := 0
+		for _, r := range residual {
+			bits += int(zigzag(r)>>k) + 1 + k
+		}
+		if bestBits < 0 || bits < bestBits {
+			bestK, bestBits = k, bits
+		}
+	}
+	return bestK, bestBits
+}
+
+func zigzag(r int32) uint32 {
+	return uint32(r<<1) ^ uint32(r>>31)
+}
+
+// bitWriter packs big-endian bit fields.
+type bitWriter struct {
+	bytes []byte
+	acc   uint64
+	n     uint
+}
+
+func (b *bitWriter) write(v uint64, bits uint) {
+	for bits > 0 {
+		take := min(bits, 32)
+		bits -= take
+		b.acc = b.acc<<take | (v>>bits)&(1<<take-1)
+		b.n += take
+		for b.n >= 8 {
+			b.n -= 8
+			b.bytes = append(b.bytes, byte(b.acc>>b.n))
+		}
+	}
+}
+
+func (b *bitWriter) writeSigned(v int32, bits uint) {
+	b.write(uint64(uint32(v))&(1<<bits-1), bits)
+}
+
+func (b *bitWriter) writeRice(u uint32, k int) {
+	for q := u >> k; q > 0; {
+		step := min(q, 32)
+		b.write(0, uint(step))
+		q -= step
+	}
+	b.write(1, 1)
+	if k > 0 {
+		b.write(uint64(u)&(1<<k-1), uint(k))
+	}
+}
+
+// writeUTF8 writes v with FLAC's UTF-8-like variable-length coding.
+func (b *bitWriter) writeUTF8(v uint64) {
+	if v < 0x80 {
+		b.write(v, 8)
+		return
+	}
+	n := 2
+	for v >= 1<<(5*n+1) {
+		n++
+	}
+	lead := uint64(0xff00>>n) & 0xff
+	b.write(lead|v>>(6*(n-1)), 8)
+	for i := n - 2; i >= 0; i-- {
+		b.write(0x80|(v>>(6*i))&0x3f, 8)
+	}
+}
+
+func (b *bitWriter) align() {
+	if b.n > 0 {
+		b.write(0, 8-b.n)
+	}
+}
+
+func crc8(data []byte) byte {
+	var crc byte
+	for _, d := range data {
+		crc ^= d
+		for range 8 {
+			if crc&0x80 != 0 {
+				crc = crc<<1 ^ 0x07
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+func crc16(data []byte) uint16 {
+	var crc uint16
+	for _, d := range data {
+		crc ^= uint16(d) << 8
+		for range 8 {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x8005
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
@@ -0,0 +1,129 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mewkiz/flac"
+)
+
+// decodeFLAC decodes data with an independent FLAC decoder, which checks
+// frame CRCs, and returns interleaved samples.
+func decodeFLAC(t *testing.T, data []byte, channels int) []int16 {
+	t.Helper()
+	stream, err := flac.New(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("parse flac stream: %v", err)
+	}
+	if int(stream.Info.NChannels) != channels || stream.Info.BitsPerSample != 16 {
+		t.Fatalf("expected %d channels of 16 bits, got %+v", channels, stream.Info)
+	}
+	var out []int16
+	for {
+		f, err := stream.ParseNext()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("decode flac frame: %v", err)
+		}
+		for i := range int(f.BlockSize) {
+			for ch := range channels {
+				out = append(out, int16(f.Subframes[ch].Samples[i]))
+			}
+		}
+	}
+	if uint64(len(out)/channels) != stream.Info.NSamples {
+		t.Fatalf("decoded %d samples, the header says %d", len(out)/channels, stream.Info.NSamples)
+	}
+	return out
+}
+
+func TestEncodeFLACRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	for _, tc := range []struct {
+		name       string
+		channels   int
+		sampleRate int
+		samples    int
+	}{
+		{"mono", 1, 16000, flacBlockSize*3 + 123},
+		{"stereo", 2, 44100, flacBlockSize + 1},
+		{"odd rate", 1, 11025, 500},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			in := make([]int16, tc.samples*tc.channels)
+			for i := range tc.samples {
+				for ch := range tc.channels {
+					var v float64
+					switch {
+					case i < 1000:
+						v = 0 // silence codes as a constant subframe
+					case i < 2000:
+						v = rng.Float64()*65535 - 32768 // noise falls back to verbatim
+					default:
+						v = 12000*math.Sin(float64(i)/float64(7+ch)) + rng.Float64()*200
+					}
+					in[i*tc.channels+ch] = int16(max(min(v, math.MaxInt16), math.MinInt16))
+				}
+			}
+			pcm := pcmBytes(in)
+
+			var out bytes.Buffer
+			if err := EncodeFLAC(&out, bytes.NewReader(pcm), int64(len(pcm)), tc.sampleRate, tc.channels); err != nil {
+				t.Fatalf("EncodeFLAC failed: %v", err)
+			}
+			if out.Len() >= len(pcm) {
+				t.Fatalf("expected compression, got %d bytes from %d", out.Len(), len(pcm))
+			}
+
+			got := decodeFLAC(t, out.Bytes(), tc.channels)
+			if len(got) != len(in) {
+				t.Fatalf("decoded %d samples, want %d", len(got), len(in))
+			}
+			for i := range in {
+				if got[i] != in[i] {
+					t.Fatalf("sample %d: got %d, want %d", i, got[i], in[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRecorderFLACWithoutExternalEncoders(t *testing.T) {
+	t.Setenv("PATH", "")
+	dir := t.TempDir()
+	recorder := NewRecorder(dir)
+
+	if err := recorder.StartSession("builtin"); err != nil {
+		t.Fatalf("StartSession failed: %v", err)
+	}
+	pcm := make([]byte, 3200)
+	for i := 0; i < len(pcm); i += 2 {
+		binary.LittleEndian.PutUint16(pcm[i:], uint16(int16(1000*math.Sin(float64(i)/20))))
+	}
+	if _, err := recorder.Writer(bytes.NewBuffer(nil)).Write(pcm); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	path, err := recorder.EndSession()
+	if err != nil {
+		t.Fatalf("EndSession failed: %v", err)
+	}
+	if path != filepath.Join(dir, "builtin.flac") {
+		t.Fatalf("expected the built-in flac encoder's output, got %q", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := decodeFLAC(t, data, 1); len(got) != len(pcm)/2 {
+		t.Fatalf("decoded %d samples, want %d", len(got), len(pcm)/2)
+	}
+}
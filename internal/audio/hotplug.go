@@ -0,0 +1,57 @@
+package audio
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"slices"
+	"time"
+)
+
+// errHotplugUnsupported means the OS's sound cards can't be listed without
+// reinitializing PortAudio.
+var errHotplugUnsupported = errors.New("listing sound cards is not supported on this platform")
+
+// listCards returns the names of the connected sound cards; a variable so
+// tests can replace it.
+var listCards = systemCards
+
+// WatchDevices calls changed with the names of the connected sound cards
+// each time a card is plugged in or removed, checking every interval until
+// ctx ends. PortAudio can't be asked without closing every stream, so the
+// cards are read from the OS instead; where that isn't supported it returns
+// at once.
+func WatchDevices(ctx context.Context, interval time.Duration, changed func(cards []string)) {
+	last, err := listCards()
+	if errors.Is(err, errHotplugUnsupported) {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		cards, err := listCards()
+		if err != nil || slices.Equal(cards, last) {
+			continue
+		}
+		last = cards
+		changed(cards)
+	}
+}
+
+// cardLine matches a card's first line in /proc/asound/cards, such as
+// " 1 [Link380        ]: USB-Audio - Jabra Link 380".
+var cardLine = regexp.MustCompile(`(?m)^\s*\d+\s+\[[^\]]*\]:\s+\S+\s+-\s+(.+?)\s*$`)
+
+// parseCards returns the card names listed in /proc/asound/cards.
+func parseCards(data string) []string {
+	var cards []string
+	for _, m := range cardLine.FindAllStringSubmatch(data, -1) {
+		cards = append(cards, m[1])
+	}
+	return cards
+}
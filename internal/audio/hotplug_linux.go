@@ -0,0 +1,13 @@
+//go:build linux
+
+package audio
+
+import "os"
+
+func systemCards() ([]string, error) {
+	data, err := os.ReadFile("/proc/asound/cards")
+	if err != nil {
+		return nil, err
+	}
+	return parseCards(string(data)), nil
+}
@@ -0,0 +1,7 @@
+//go:build !linux
+
+package audio
+
+func systemCards() ([]string, error) {
+	return nil, errHotplugUnsupported
+}
@@ -0,0 +1,73 @@
+package audio
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestParseCards(t *testing.T) {
+	data := ` 0 [PCH            ]: HDA-Intel - HDA Intel PCH
+                      HDA Intel PCH at 0xf7f10000 irq 32
+ 1 [Link380        ]: USB-Audio - Jabra Link 380
+                      GN Audio A/S Jabra Link 380 at usb-0000:00:14.0-2, full speed
+`
+	if got, want := parseCards(data), []string{"HDA Intel PCH", "Jabra Link 380"}; !slices.Equal(got, want) {
+		t.Fatalf("parseCards() = %q, want %q", got, want)
+	}
+	if got := parseCards("--- no soundcards ---\n"); got != nil {
+		t.Fatalf("expected no cards, got %q", got)
+	}
+}
+
+func TestWatchDevicesReportsChanges(t *testing.T) {
+	var mu sync.Mutex
+	cards := []string{"HDA Intel PCH"}
+	old := listCards
+	listCards = func() ([]string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Clone(cards), nil
+	}
+	defer func() { listCards = old }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	changes := make(chan []string, 4)
+	done := make(chan struct{})
+	go func() {
+		WatchDevices(ctx, time.Millisecond, func(c []string) { changes <- c })
+		close(done)
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	select {
+	case c := <-changes:
+		t.Fatalf("expected no change before a card is plugged in, got %q", c)
+	default:
+	}
+
+	mu.Lock()
+	cards = append(cards, "Jabra Link 380")
+	mu.Unlock()
+	select {
+	case c := <-changes:
+		if !slices.Equal(c, []string{"HDA Intel PCH", "Jabra Link 380"}) {
+			t.Fatalf("unexpected cards %q", c)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the new card to be reported")
+	}
+
+	cancel()
+	<-done
+}
+
+func TestWatchDevicesUnsupported(t *testing.T) {
+	old := listCards
+	listCards = func() ([]string, error) { return nil, errHotplugUnsupported }
+	defer func() { listCards = old }()
+
+	WatchDevices(context.Background(), time.Millisecond, func([]string) { t.Fatal("unexpected change") })
+}
@@ -0,0 +1,81 @@
+package audio
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"time"
+)
+
+// Level is the loudness of a stretch of PCM16 audio. RMS and Peak are
+// fractions of full scale; ClippedSamples counts samples at the int16 limit.
+type Level struct {
+	RMS            float64
+	Peak           float64
+	ClippedSamples int
+}
+
+// Clipping reports whether any sample hit full scale.
+func (l Level) Clipping() bool { return l.ClippedSamples > 0 }
+
+// DBFS returns RMS in decibels relative to full scale, floored at -96 dB for
+// silence.
+func (l Level) DBFS() float64 {
+	if l.RMS <= 0 {
+		return -96
+	}
+	return max(20*math.Log10(l.RMS), -96)
+}
+
+// LevelMeter passes PCM16-LE through to its destination unchanged, measuring
+// it along the way and reporting a Level for every interval of audio. The
+// interval is counted in samples, not wall-clock time, so it stays steady
+// however the capture batches its writes. Writes are expected to hold whole
+// samples.
+type LevelMeter struct {
+	dst    io.Writer
+	report func(Level)
+	window int
+
+	count   int
+	sumSq   float64
+	peak    int
+	clipped int
+}
+
+// NewLevelMeter meters audio at sampleRate with the given number of
+// interleaved channels, calling report once per interval.
+func NewLevelMeter(dst io.Writer, sampleRate, channels int, interval time.Duration, report func(Level)) *LevelMeter {
+	window := int(int64(sampleRate) * int64(max(channels, 1)) * int64(interval) / int64(time.Second))
+	return &LevelMeter{dst: dst, report: report, window: max(window, 1)}
+}
+
+func (m *LevelMeter) Write(p []byte) (int, error) {
+	n, err := m.dst.Write(p)
+	for i := 0; i+1 < len(p); i += 2 {
+		m.add(int(int16(binary.LittleEndian.Uint16(p[i:]))))
+	}
+	return n, err
+}
+
+func (m *LevelMeter) add(s int) {
+	if s < 0 {
+		s = -s
+	}
+	m.sumSq += float64(s) * float64(s)
+	m.peak = max(m.peak, s)
+	if s >= math.MaxInt16 {
+		m.clipped++
+	}
+	m.count++
+	if m.count < m.window {
+		return
+	}
+
+	m.report(Level{
+		RMS:            min(math.Sqrt(m.sumSq/float64(m.count))/math.MaxInt16, 1),
+		Peak:           min(float64(m.peak)/math.MaxInt16, 1),
+		ClippedSamples: m.clipped,
+	})
+	m.count, m.sumSq, m.peak, m.clipped = 0, 0, 0, 0
+}
@@ -0,0 +1,61 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+)
+
+func pcmBytes(samples []int16) []byte {
+	var b bytes.Buffer
+	_ = binary.Write(&b, binary.LittleEndian, samples)
+	return b.Bytes()
+}
+
+func TestLevelMeterReportsPerInterval(t *testing.T) {
+	var dst bytes.Buffer
+	var levels []Level
+	// 10 samples per report at 100 Hz and 100ms.
+	m := NewLevelMeter(&dst, 100, 1, 100*time.Millisecond, func(l Level) { levels = append(levels, l) })
+
+	quiet := make([]int16, 10)
+	for i := range quiet {
+		quiet[i] = 3277 // ~0.1 of full scale
+	}
+	loud := make([]int16, 10)
+	for i := range loud {
+		loud[i] = -16384
+	}
+	loud[3] = math.MinInt16
+	loud[4] = math.MaxInt16
+
+	in := pcmBytes(append(append(quiet, loud...), 1, 2, 3))
+	// Split writes across the report boundary.
+	if _, err := m.Write(in[:8]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Write(in[8:]); err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(dst.Bytes(), in) {
+		t.Fatal("expected audio passed through unchanged")
+	}
+	if len(levels) != 2 {
+		t.Fatalf("expected 2 reports, got %d", len(levels))
+	}
+	if math.Abs(levels[0].RMS-0.1) > 0.001 || levels[0].Clipping() {
+		t.Fatalf("unexpected quiet level %+v", levels[0])
+	}
+	if levels[1].Peak != 1 || levels[1].ClippedSamples != 2 || !levels[1].Clipping() {
+		t.Fatalf("unexpected loud level %+v", levels[1])
+	}
+	if db := levels[0].DBFS(); math.Abs(db+20) > 0.1 {
+		t.Fatalf("expected about -20 dBFS, got %.2f", db)
+	}
+	if db := (Level{}).DBFS(); db != -96 {
+		t.Fatalf("expected silence floored at -96 dBFS, got %.2f", db)
+	}
+}
@@ -0,0 +1,20 @@
+package audio
+
+import "time"
+
+// NewLoopback opens a capture of the system's audio output, so the remote
+// side of a call is recorded along with (or instead of) the microphone.
+// source names the output to capture; empty picks the platform default:
+//
+//   - Linux: the monitor of the default PipeWire or PulseAudio sink, via
+//     pw-record or parec. source is a sink name.
+//   - macOS: the "BlackHole 2ch" virtual device, which must be installed and
+//     included in a multi-output device with the speakers. source is any
+//     input device name.
+//   - Windows: the "Stereo Mix" recording device, which must be enabled in
+//     the sound settings. source is any input device name.
+//
+// PortAudio must already be initialized outside Linux.
+func NewLoopback(source string, buffer time.Duration) (Capture, error) {
+	return newLoopback(source, buffer)
+}
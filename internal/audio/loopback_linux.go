@@ -0,0 +1,46 @@
+//go:build linux
+
+package audio
+
+import (
+	"errors"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func newLoopback(source string, buffer time.Duration) (Capture, error) {
+	if bin, err := exec.LookPath("pw-record"); err == nil {
+		return newCommandCapture(bin, pwLoopbackArgs(source), buffer), nil
+	}
+	if bin, err := exec.LookPath("parec"); err == nil {
+		return newCommandCapture(bin, parecLoopbackArgs(source), buffer), nil
+	}
+	return nil, errors.New("loopback capture requires pw-record or parec")
+}
+
+// pwLoopbackArgs records a sink's output instead of a source; with no sink
+// PipeWire picks the default one.
+func pwLoopbackArgs(sink string) []string {
+	args := pwRecordArgs(sink)
+	return append(args[:len(args)-1], "--properties", "{ stream.capture.sink = true }", "-")
+}
+
+// parecLoopbackArgs records the monitor source PulseAudio creates for sink.
+func parecLoopbackArgs(sink string) []string {
+	device := "@DEFAULT_MONITOR@"
+	if sink != "" {
+		device = sink
+		if !strings.HasSuffix(device, ".monitor") {
+			device += ".monitor"
+		}
+	}
+	return []string{
+		"--raw",
+		"--format=s16le",
+		"--rate=" + strconv.Itoa(TargetSampleRate),
+		"--channels=1",
+		"--device=" + device,
+	}
+}
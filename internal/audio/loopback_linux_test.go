@@ -0,0 +1,23 @@
+package audio
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLoopbackArgs(t *testing.T) {
+	got := pwLoopbackArgs("")
+	want := []string{"--rate", "16000", "--channels", "1", "--format", "s16", "--properties", "{ stream.capture.sink = true }", "-"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected pw-record args %v", got)
+	}
+
+	if got := parecLoopbackArgs("")[4]; got != "--device=@DEFAULT_MONITOR@" {
+		t.Fatalf("expected the default monitor, got %q", got)
+	}
+	for _, sink := range []string{"alsa_output.usb", "alsa_output.usb.monitor"} {
+		if got := parecLoopbackArgs(sink)[4]; got != "--device=alsa_output.usb.monitor" {
+			t.Fatalf("expected the sink's monitor for %q, got %q", sink, got)
+		}
+	}
+}
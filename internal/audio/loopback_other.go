@@ -0,0 +1,30 @@
+//go:build !linux
+
+package audio
+
+import (
+	"fmt"
+	"runtime"
+	"time"
+)
+
+// defaultLoopbackDevices are the usual loopback inputs where the OS has no
+// monitor sources of its own.
+var defaultLoopbackDevices = map[string]string{
+	"darwin":  "BlackHole 2ch",
+	"windows": "Stereo Mix",
+}
+
+func newLoopback(source string, buffer time.Duration) (Capture, error) {
+	if source == "" {
+		source = defaultLoopbackDevices[runtime.GOOS]
+	}
+	if source == "" {
+		return nil, fmt.Errorf("no default loopback device on %s: set loopback.source", runtime.GOOS)
+	}
+	mic, err := NewMicDevice(source, 0, buffer)
+	if err != nil {
+		return nil, fmt.Errorf("open loopback device: %w", err)
+	}
+	return mic, nil
+}
@@ -3,29 +3,105 @@ package audio
 import (
 	"bytes"
 	"encoding/binary"
+	"errors"
+	"fmt"
 	"io"
+	"time"
 
 	"github.com/gordonklaus/portaudio"
 )
 
+// Capture is an audio input that streams 16-bit little-endian PCM at
+// SampleRate: mono, or interleaved channels for captures that report more
+// through Channels.
+type Capture interface {
+	Start() error
+	Stop() error
+	Stream(w io.Writer) error
+	SampleRate() int
+}
+
+// Channels reports how many interleaved channels c streams. Captures are
+// mono unless they implement Channels() int.
+func Channels(c Capture) int {
+	if mc, ok := c.(interface{ Channels() int }); ok {
+		return mc.Channels()
+	}
+	return 1
+}
+
 // Mic wraps PortAudio with a configurable buffer size.
 type Mic struct {
-	stream *portaudio.Stream
-	buf    []int16
+	stream     *portaudio.Stream
+	buf        []int16
+	sampleRate int
 }
 
-// NewMic opens a PortAudio capture stream with the given sample rate and buffer size (in frames).
-func NewMic(sampleRate, framesPerBuffer int) (*Mic, error) {
+// NewMic opens a PortAudio capture stream on the default input device. A zero
+// sampleRate captures at the device's native rate; buffer is the duration of
+// audio read per chunk.
+func NewMic(sampleRate int, buffer time.Duration) (*Mic, error) {
+	device, err := portaudio.DefaultInputDevice()
+	if err != nil {
+		return nil, fmt.Errorf("find default input device: %w", err)
+	}
+	return openMic(device, sampleRate, buffer)
+}
+
+// NewMicDevice opens a capture stream on the input device with the given name.
+func NewMicDevice(name string, sampleRate int, buffer time.Duration) (*Mic, error) {
+	device, err := findInputDevice(name)
+	if err != nil {
+		return nil, err
+	}
+	mic, err := openMic(device, sampleRate, buffer)
+	if err != nil {
+		return nil, fmt.Errorf("open device %q: %w", name, err)
+	}
+	return mic, nil
+}
+
+func openMic(device *portaudio.DeviceInfo, sampleRate int, buffer time.Duration) (*Mic, error) {
+	if sampleRate <= 0 {
+		sampleRate = int(device.DefaultSampleRate)
+	}
+	framesPerBuffer := int(buffer.Seconds() * float64(sampleRate))
+
 	buf := make([]int16, framesPerBuffer)
-	stream, err := portaudio.OpenDefaultStream(1, 0, float64(sampleRate), framesPerBuffer, buf)
+	stream, err := portaudio.OpenStream(portaudio.StreamParameters{
+		Input: portaudio.StreamDeviceParameters{
+			Device:   device,
+			Channels: 1,
+			Latency:  device.DefaultHighInputLatency,
+		},
+		SampleRate:      float64(sampleRate),
+		FramesPerBuffer: framesPerBuffer,
+	}, buf)
 	if err != nil {
 		return nil, err
 	}
-	return &Mic{stream: stream, buf: buf}, nil
+	return &Mic{stream: stream, buf: buf, sampleRate: sampleRate}, nil
+}
+
+func findInputDevice(name string) (*portaudio.DeviceInfo, error) {
+	devices, err := portaudio.Devices()
+	if err != nil {
+		return nil, fmt.Errorf("list audio devices: %w", err)
+	}
+	for _, d := range devices {
+		if d.Name == name && d.MaxInputChannels > 0 {
+			return d, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %q", ErrDeviceNotFound, name)
 }
 
-func (m *Mic) Start() error { return m.stream.Start() }
-func (m *Mic) Stop() error  { return m.stream.Stop() }
+func (m *Mic) Start() error    { return m.stream.Start() }
+func (m *Mic) Stop() error     { return m.stream.Stop() }
+func (m *Mic) SampleRate() int { return m.sampleRate }
+
+// Close releases the PortAudio stream. The mic cannot be restarted after.
+func (m *Mic) Close() error { return m.stream.Close() }
 
 // Stream reads from the mic and writes PCM16-LE to w until an error or stop.
 func (m *Mic) Stream(w io.Writer) error {
@@ -44,3 +120,143 @@ func (m *Mic) Stream(w io.Writer) error {
 		}
 	}
 }
+
+// MultiMic captures from several input devices at once and mixes them into a
+// single mono stream at TargetSampleRate, scaling each device by its gain,
+// or, opened with NewMultiMicChannels, streams each device on its own
+// channel. Each device captures at its own native rate and is resampled
+// first.
+type MultiMic struct {
+	mics       []*Mic
+	resamplers []*Resampler
+	gains      []float64
+	interleave bool
+	mixed      []int16
+}
+
+// MicDevice names an input device and the gain applied to it when mixing.
+type MicDevice struct {
+	Name string
+	Gain float64
+}
+
+// NewMultiMic opens every device with the given buffer duration.
+func NewMultiMic(devices []MicDevice, buffer time.Duration) (*MultiMic, error) {
+	if len(devices) == 0 {
+		return nil, errors.New("no microphone devices configured")
+	}
+
+	mm := &MultiMic{}
+	for _, d := range devices {
+		mic, err := NewMicDevice(d.Name, 0, buffer)
+		if err != nil {
+			_ = mm.Close()
+			return nil, err
+		}
+		mm.mics = append(mm.mics, mic)
+		mm.resamplers = append(mm.resamplers, NewResampler(mic.sampleRate, TargetSampleRate))
+		mm.gains = append(mm.gains, d.Gain)
+	}
+	return mm, nil
+}
+
+// NewMultiMicChannels opens every device like NewMultiMic but streams them
+// as interleaved PCM, one channel per device in order, so a transcriber can
+// tell them apart without diarization.
+func NewMultiMicChannels(devices []MicDevice, buffer time.Duration) (*MultiMic, error) {
+	mm, err := NewMultiMic(devices, buffer)
+	if err != nil {
+		return nil, err
+	}
+	mm.interleave = true
+	return mm, nil
+}
+
+func (mm *MultiMic) Start() error {
+	for i, mic := range mm.mics {
+		if err := mic.Start(); err != nil {
+			for _, started := range mm.mics[:i] {
+				_ = started.Stop()
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (mm *MultiMic) Stop() error {
+	var errs []error
+	for _, mic := range mm.mics {
+		errs = append(errs, mic.Stop())
+	}
+	return errors.Join(errs...)
+}
+
+func (mm *MultiMic) SampleRate() int { return TargetSampleRate }
+
+// Channels is the number of devices when they stream on separate channels,
+// and 1 when they are mixed.
+func (mm *MultiMic) Channels() int {
+	if mm.interleave {
+		return len(mm.mics)
+	}
+	return 1
+}
+
+// Stream reads one buffer from every device in turn, mixes or interleaves
+// them and writes PCM16-LE to w until an error or stop.
+func (mm *MultiMic) Stream(w io.Writer) error {
+	var out bytes.Buffer
+	sources := make([][]int16, len(mm.mics))
+	for {
+		for i, mic := range mm.mics {
+			if err := mic.stream.Read(); err != nil {
+				return err
+			}
+			sources[i] = mm.resamplers[i].Process(mic.buf)
+		}
+
+		out.Reset()
+		if err := binary.Write(&out, binary.LittleEndian, mm.combine(sources)); err != nil {
+			return err
+		}
+		if _, err := w.Write(out.Bytes()); err != nil {
+			return err
+		}
+	}
+}
+
+// combine mixes one buffer from each device, or interleaves them with each
+// device's gain applied, padding devices that ran short with silence.
+func (mm *MultiMic) combine(sources [][]int16) []int16 {
+	frames := 0
+	for _, src := range sources {
+		frames = max(frames, len(src))
+	}
+	n := frames * mm.Channels()
+	if cap(mm.mixed) < n {
+		mm.mixed = make([]int16, n)
+	}
+	mixed := mm.mixed[:n]
+	if !mm.interleave {
+		MixPCM(mixed, sources, mm.gains)
+		return mixed
+	}
+	interleavePCM(mixed, sources)
+	for i := range mixed {
+		if gain := mm.gains[i%len(sources)]; gain != 1 {
+			mixed[i] = clip16(float64(mixed[i]) * gain)
+		}
+	}
+	return mixed
+}
+
+// Close releases every device's PortAudio stream. The mics cannot be
+// restarted after.
+func (mm *MultiMic) Close() error {
+	var errs []error
+	for _, mic := range mm.mics {
+		errs = append(errs, mic.stream.Close())
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,240 @@
+package audio
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"sync"
+)
+
+// mixQueueLimit bounds how far a secondary source may run ahead of the
+// primary, so clock drift between devices can't build up unbounded latency.
+const mixQueueLimit = TargetSampleRate
+
+// MixSource is a capture mixed into a MixCapture, scaled by Gain.
+type MixSource struct {
+	Capture Capture
+	Gain    float64
+}
+
+// MixCapture mixes secondary captures, such as system audio loopback, into a
+// primary one and streams the mix as mono PCM at TargetSampleRate. The
+// primary paces the output: each chunk it produces is mixed with whatever the
+// secondaries have buffered since, and silence stands in for a secondary that
+// falls behind or stops. A secondary that fails is reported to OnSourceError
+// and left silent rather than interrupting the primary.
+type MixCapture struct {
+	primary     MixSource
+	secondaries []MixSource
+	queues      []*sampleQueue
+	interleave  bool
+
+	// OnSourceError, if set, is called when a secondary's stream ends before
+	// Stop, with the secondary's index.
+	OnSourceError func(i int, err error)
+
+	mu      sync.Mutex
+	pumping sync.WaitGroup
+	stopped bool
+
+	sources [][]int16
+	gains   []float64
+	mixed   []int16
+	out     []byte
+}
+
+// NewChannelCapture streams primary and secondary side by side as
+// interleaved two-channel PCM, primary on the first channel, so a
+// transcriber can tell them apart without diarization. Gains are unity.
+func NewChannelCapture(primary, secondary Capture) *MixCapture {
+	m := NewMixCapture(MixSource{Capture: primary, Gain: 1}, MixSource{Capture: secondary, Gain: 1})
+	m.interleave = true
+	return m
+}
+
+// NewMixCapture mixes secondaries into primary.
+func NewMixCapture(primary MixSource, secondaries ...MixSource) *MixCapture {
+	m := &MixCapture{primary: primary, secondaries: secondaries}
+	m.gains = append(m.gains, primary.Gain)
+	for _, s := range secondaries {
+		m.queues = append(m.queues, &sampleQueue{})
+		m.gains = append(m.gains, s.Gain)
+	}
+	m.sources = make([][]int16, len(m.gains))
+	return m
+}
+
+// Primary returns the capture that paces the mix.
+func (m *MixCapture) Primary() Capture { return m.primary.Capture }
+
+// Start starts every source and begins buffering the secondaries. If any
+// source fails to start, the ones already started are stopped again.
+func (m *MixCapture) Start() error {
+	if err := m.primary.Capture.Start(); err != nil {
+		return err
+	}
+	for i, s := range m.secondaries {
+		if err := s.Capture.Start(); err != nil {
+			for _, started := range m.secondaries[:i] {
+				_ = started.Capture.Stop()
+			}
+			_ = m.primary.Capture.Stop()
+			return err
+		}
+	}
+
+	m.mu.Lock()
+	m.stopped = false
+	m.mu.Unlock()
+	for i, s := range m.secondaries {
+		m.queues[i].reset()
+		m.pumping.Add(1)
+		go m.pump(i, s.Capture)
+	}
+	return nil
+}
+
+func (m *MixCapture) pump(i int, c Capture) {
+	defer m.pumping.Done()
+	err := c.Stream(NewResamplingWriter(m.queues[i], c.SampleRate(), TargetSampleRate))
+
+	m.mu.Lock()
+	stopped := m.stopped
+	m.mu.Unlock()
+	if !stopped && m.OnSourceError != nil {
+		m.OnSourceError(i, err)
+	}
+}
+
+// Stop stops every source and waits for the secondaries to stop buffering.
+func (m *MixCapture) Stop() error {
+	m.mu.Lock()
+	m.stopped = true
+	m.mu.Unlock()
+
+	errs := []error{m.primary.Capture.Stop()}
+	for _, s := range m.secondaries {
+		errs = append(errs, s.Capture.Stop())
+	}
+	m.pumping.Wait()
+	return errors.Join(errs...)
+}
+
+// Close releases sources that hold a device handle beyond Stop.
+func (m *MixCapture) Close() error {
+	closeCapture(m.primary.Capture)
+	for _, s := range m.secondaries {
+		closeCapture(s.Capture)
+	}
+	return nil
+}
+
+func (m *MixCapture) SampleRate() int { return TargetSampleRate }
+
+// Channels is the number of interleaved channels Stream writes: one per
+// source for a channel capture, otherwise one.
+func (m *MixCapture) Channels() int {
+	if m.interleave {
+		return len(m.sources)
+	}
+	return 1
+}
+
+// Stream writes the mix to w until the primary's stream ends.
+func (m *MixCapture) Stream(w io.Writer) error {
+	c := m.primary.Capture
+	return c.Stream(NewResamplingWriter(mixWriterFunc(func(p []byte) error {
+		return m.mix(w, p)
+	}), c.SampleRate(), TargetSampleRate))
+}
+
+// mix combines one chunk of primary PCM with the buffered secondaries.
+func (m *MixCapture) mix(w io.Writer, p []byte) error {
+	n := len(p) / 2
+	primary := decodePCM(m.sources[0][:0], p)
+	m.sources[0] = primary
+	for i, q := range m.queues {
+		m.sources[i+1] = q.take(m.sources[i+1][:0], n)
+	}
+
+	frames := n * m.Channels()
+	if cap(m.mixed) < frames {
+		m.mixed = make([]int16, frames)
+	}
+	mixed := m.mixed[:frames]
+	if m.interleave {
+		interleavePCM(mixed, m.sources)
+	} else {
+		MixPCM(mixed, m.sources, m.gains)
+	}
+
+	m.out = m.out[:0]
+	for _, s := range mixed {
+		m.out = binary.LittleEndian.AppendUint16(m.out, uint16(s))
+	}
+	_, err := w.Write(m.out)
+	return err
+}
+
+// interleavePCM writes one sample from each source per frame into dst,
+// padding sources that run short with silence.
+func interleavePCM(dst []int16, sources [][]int16) {
+	channels := len(sources)
+	for i := range dst {
+		frame, ch := i/channels, i%channels
+		if frame < len(sources[ch]) {
+			dst[i] = sources[ch][frame]
+		} else {
+			dst[i] = 0
+		}
+	}
+}
+
+type mixWriterFunc func(p []byte) error
+
+func (f mixWriterFunc) Write(p []byte) (int, error) {
+	if err := f(p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func decodePCM(dst []int16, p []byte) []int16 {
+	for i := 0; i+1 < len(p); i += 2 {
+		dst = append(dst, int16(binary.LittleEndian.Uint16(p[i:])))
+	}
+	return dst
+}
+
+// sampleQueue buffers PCM16-LE from a secondary source until the mix takes
+// it, dropping the oldest samples beyond mixQueueLimit.
+type sampleQueue struct {
+	mu      sync.Mutex
+	samples []int16
+}
+
+func (q *sampleQueue) Write(p []byte) (int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.samples = decodePCM(q.samples, p)
+	if over := len(q.samples) - mixQueueLimit; over > 0 {
+		q.samples = append(q.samples[:0], q.samples[over:]...)
+	}
+	return len(p), nil
+}
+
+// take appends up to n buffered samples to dst.
+func (q *sampleQueue) take(dst []int16, n int) []int16 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	n = min(n, len(q.samples))
+	dst = append(dst, q.samples[:n]...)
+	q.samples = append(q.samples[:0], q.samples[n:]...)
+	return dst
+}
+
+func (q *sampleQueue) reset() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.samples = q.samples[:0]
+}
@@ -0,0 +1,115 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+func TestMixCaptureMixesSecondaryIntoPrimary(t *testing.T) {
+	mic, loopback := newFakeCapture(100), newFakeCapture(50)
+	mix := NewMixCapture(MixSource{Capture: mic, Gain: 1}, MixSource{Capture: loopback, Gain: 2})
+	if err := mix.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if !mic.started || !loopback.started {
+		t.Fatal("expected both sources started")
+	}
+
+	var out syncBuffer
+	done := make(chan error, 1)
+	go func() { done <- mix.Stream(&out) }()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !out.contains(200) {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for mixed samples")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if err := mix.Stop(); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+	if err := <-done; err == nil {
+		t.Fatal("expected the stream to end with the primary's error")
+	}
+}
+
+func TestMixCaptureReportsSecondaryFailure(t *testing.T) {
+	mic, loopback := newFakeCapture(100), newFakeCapture(50)
+	mix := NewMixCapture(MixSource{Capture: mic, Gain: 1}, MixSource{Capture: loopback, Gain: 1})
+	failed := make(chan int, 1)
+	mix.OnSourceError = func(i int, _ error) { failed <- i }
+	if err := mix.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = mix.Stop() }()
+
+	_ = loopback.Stop()
+	select {
+	case i := <-failed:
+		if i != 0 {
+			t.Fatalf("expected secondary 0 reported, got %d", i)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the secondary failure to be reported")
+	}
+}
+
+func TestSampleQueueDropsOldest(t *testing.T) {
+	var q sampleQueue
+	chunk := make([]byte, 2*mixQueueLimit)
+	if _, err := q.Write(chunk); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := q.Write(binary.LittleEndian.AppendUint16(nil, 7)); err != nil {
+		t.Fatal(err)
+	}
+	got := q.take(nil, mixQueueLimit+10)
+	if len(got) != mixQueueLimit || got[len(got)-1] != 7 {
+		t.Fatalf("expected the newest %d samples ending in 7, got %d ending in %d", mixQueueLimit, len(got), got[len(got)-1])
+	}
+	if rest := q.take(nil, 1); len(rest) != 0 {
+		t.Fatalf("expected an empty queue, got %v", rest)
+	}
+}
+
+func TestChannelCaptureInterleaves(t *testing.T) {
+	mic, loopback := newFakeCapture(100), newFakeCapture(50)
+	capture := NewChannelCapture(mic, loopback)
+	if Channels(capture) != 2 || Channels(mic) != 1 {
+		t.Fatalf("unexpected channel counts %d and %d", Channels(capture), Channels(mic))
+	}
+	if err := capture.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	var out syncBuffer
+	done := make(chan error, 1)
+	go func() { done <- capture.Stream(&out) }()
+
+	frame := binary.LittleEndian.AppendUint16(binary.LittleEndian.AppendUint16(nil, 100), 50)
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		out.mu.Lock()
+		found := bytes.Contains(out.buf.Bytes(), frame)
+		out.mu.Unlock()
+		if found {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for an interleaved frame")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	_ = capture.Stop()
+	<-done
+	out.mu.Lock()
+	defer out.mu.Unlock()
+	if out.buf.Len()%4 != 0 {
+		t.Fatalf("expected whole stereo frames, got %d bytes", out.buf.Len())
+	}
+}
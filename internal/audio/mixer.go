@@ -0,0 +1,32 @@
+package audio
+
+import "math"
+
+// MixPCM sums equally long 16-bit sample buffers into dst, scaling each source
+// by its gain and clipping the result to the int16 range.
+func MixPCM(dst []int16, sources [][]int16, gains []float64) {
+	for i := range dst {
+		var sum float64
+		for s, src := range sources {
+			if i >= len(src) {
+				continue
+			}
+			gain := 1.0
+			if s < len(gains) {
+				gain = gains[s]
+			}
+			sum += float64(src[i]) * gain
+		}
+		dst[i] = clip16(sum)
+	}
+}
+
+func clip16(v float64) int16 {
+	if v > math.MaxInt16 {
+		return math.MaxInt16
+	}
+	if v < math.MinInt16 {
+		return math.MinInt16
+	}
+	return int16(math.Round(v))
+}
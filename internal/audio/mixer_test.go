@@ -0,0 +1,48 @@
+package audio
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestMixPCM(t *testing.T) {
+	a := []int16{1000, -1000, 30000, -30000}
+	b := []int16{500, 500, 10000, -10000}
+	dst := make([]int16, len(a))
+
+	MixPCM(dst, [][]int16{a, b}, []float64{1, 2})
+
+	want := []int16{2000, 0, math.MaxInt16, math.MinInt16}
+	for i := range want {
+		if dst[i] != want[i] {
+			t.Fatalf("sample %d: expected %d, got %d", i, want[i], dst[i])
+		}
+	}
+}
+
+func TestMixPCMDefaultsMissingGainToUnity(t *testing.T) {
+	dst := make([]int16, 2)
+	MixPCM(dst, [][]int16{{100, 200}, {1, 2}}, []float64{0.5})
+
+	if dst[0] != 51 || dst[1] != 102 {
+		t.Fatalf("unexpected mix %v", dst)
+	}
+}
+
+func TestMultiMicCombine(t *testing.T) {
+	sources := [][]int16{{100, 200, 300}, {10, 20}}
+
+	mixed := (&MultiMic{gains: []float64{1, 2}}).combine(sources)
+	if want := []int16{120, 240, 300}; !slices.Equal(mixed, want) {
+		t.Fatalf("expected mix %v, got %v", want, mixed)
+	}
+
+	mm := &MultiMic{mics: make([]*Mic, 2), gains: []float64{1, 2}, interleave: true}
+	if mm.Channels() != 2 {
+		t.Fatalf("expected a channel per device, got %d", mm.Channels())
+	}
+	if got, want := mm.combine(sources), []int16{100, 20, 200, 40, 300, 0}; !slices.Equal(got, want) {
+		t.Fatalf("expected interleaved %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,104 @@
+package audio
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/i18n"
+)
+
+const (
+	// Clipping windows this close together count as one stretch of
+	// clipping, since speech only peaks now and then.
+	clipGap = 5 * time.Second
+	// Input quieter than this is treated as a dead or muted microphone;
+	// even a quiet room sits well above it.
+	silentDBFS = -75
+)
+
+// InputMonitor watches input levels over minutes rather than a meter's
+// fraction of a second, to catch capture that is broken rather than quiet:
+// input clipping for a sustained stretch, or a microphone gone silent while
+// recording. Feed it every Level from a LevelMeter.
+type InputMonitor struct {
+	clipAfter   time.Duration
+	silentAfter time.Duration
+	paused      func() bool
+	onChange    func()
+	now         func() time.Time
+
+	// Localizer renders Warnings; nil renders English.
+	Localizer *i18n.Localizer
+
+	mu          sync.Mutex
+	clipStart   time.Time
+	lastClip    time.Time
+	silentSince time.Time
+	clipping    bool
+	silent      bool
+}
+
+// NewInputMonitor warns once input has clipped for clipAfter, or been
+// silent for silentAfter while paused reports false. onChange, if set, is
+// called whenever either warning starts or stops.
+func NewInputMonitor(clipAfter, silentAfter time.Duration, paused func() bool, onChange func()) *InputMonitor {
+	return &InputMonitor{
+		clipAfter:   clipAfter,
+		silentAfter: silentAfter,
+		paused:      paused,
+		onChange:    onChange,
+		now:         time.Now,
+	}
+}
+
+// Observe records one metering interval.
+func (m *InputMonitor) Observe(l Level) {
+	m.mu.Lock()
+	now := m.now()
+	clipping, silent := m.clipping, m.silent
+
+	if l.ClippedSamples > 0 {
+		if m.clipStart.IsZero() || now.Sub(m.lastClip) > clipGap {
+			m.clipStart = now
+		}
+		m.lastClip = now
+		if now.Sub(m.clipStart) >= m.clipAfter {
+			m.clipping = true
+		}
+	} else if m.clipping && now.Sub(m.lastClip) >= m.clipAfter {
+		m.clipping = false
+		m.clipStart = time.Time{}
+	}
+
+	switch {
+	case (m.paused != nil && m.paused()) || l.DBFS() > silentDBFS:
+		m.silentSince = time.Time{}
+		m.silent = false
+	case m.silentSince.IsZero():
+		m.silentSince = now
+	case now.Sub(m.silentSince) >= m.silentAfter:
+		m.silent = true
+	}
+
+	changed := clipping != m.clipping || silent != m.silent
+	m.mu.Unlock()
+	if changed && m.onChange != nil {
+		m.onChange()
+	}
+}
+
+// Warnings describes any input problem that is ongoing.
+func (m *InputMonitor) Warnings() []string {
+	m.mu.Lock()
+	clipping, silent := m.clipping, m.silent
+	m.mu.Unlock()
+
+	var warnings []string
+	if clipping {
+		warnings = append(warnings, m.Localizer.T(i18n.InputClipping, m.clipAfter.Seconds()))
+	}
+	if silent {
+		warnings = append(warnings, m.Localizer.T(i18n.InputSilent, m.silentAfter.Minutes()))
+	}
+	return warnings
+}
@@ -0,0 +1,74 @@
+package audio
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInputMonitorClipping(t *testing.T) {
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	changes := 0
+	m := NewInputMonitor(30*time.Second, 10*time.Minute, nil, func() { changes++ })
+	m.now = func() time.Time { return now }
+
+	loud := Level{RMS: 0.5, Peak: 1, ClippedSamples: 3}
+	// Clipping every couple of seconds for 30s is one stretch.
+	for range 16 {
+		m.Observe(loud)
+		now = now.Add(2 * time.Second)
+		m.Observe(Level{RMS: 0.3, Peak: 0.6})
+	}
+	if w := m.Warnings(); len(w) != 1 || !strings.Contains(w[0], "clipping for 30s") {
+		t.Fatalf("expected a clipping warning, got %v", w)
+	}
+
+	// It clears once the input has stayed clean as long again.
+	now = now.Add(31 * time.Second)
+	m.Observe(Level{RMS: 0.3, Peak: 0.6})
+	if w := m.Warnings(); len(w) != 0 {
+		t.Fatalf("expected the clipping warning cleared, got %v", w)
+	}
+	if changes != 2 {
+		t.Fatalf("expected two changes, got %d", changes)
+	}
+
+	// Clipping now and then never adds up to a stretch.
+	for range 10 {
+		m.Observe(loud)
+		now = now.Add(10 * time.Second)
+	}
+	if w := m.Warnings(); len(w) != 0 {
+		t.Fatalf("expected no warning for occasional clipping, got %v", w)
+	}
+}
+
+func TestInputMonitorSilentWhileUnpaused(t *testing.T) {
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	paused := true
+	m := NewInputMonitor(30*time.Second, 10*time.Minute, func() bool { return paused }, nil)
+	m.now = func() time.Time { return now }
+
+	silence := Level{}
+	for range 20 {
+		m.Observe(silence)
+		now = now.Add(time.Minute)
+	}
+	if w := m.Warnings(); len(w) != 0 {
+		t.Fatalf("expected no warning while paused, got %v", w)
+	}
+
+	paused = false
+	for range 11 {
+		m.Observe(silence)
+		now = now.Add(time.Minute)
+	}
+	if w := m.Warnings(); len(w) != 1 || !strings.Contains(w[0], "silent for 10 minutes") {
+		t.Fatalf("expected a silent mic warning, got %v", w)
+	}
+
+	m.Observe(Level{RMS: 0.001})
+	if w := m.Warnings(); len(w) != 0 {
+		t.Fatalf("expected room tone to clear the warning, got %v", w)
+	}
+}
@@ -0,0 +1,77 @@
+package audio
+
+import (
+	"io"
+	"sort"
+	"sync"
+)
+
+// Mute passes PCM16-LE on to its destination except while muted, when the
+// audio is dropped before it leaves the machine. Like VADGate, it records
+// where it dropped audio, so CaptureTime can map the destination's
+// timestamps back onto the audio it was given.
+type Mute struct {
+	dst        io.Writer
+	frameBytes int64
+	frameRate  float64
+
+	mu    sync.Mutex
+	muted bool
+	// in and out count the bytes written to the mute and passed on.
+	in, out int64
+	offset  int64
+	skips   []vadSkip
+}
+
+// NewMute passes audio at sampleRate with the given number of interleaved
+// channels on to dst.
+func NewMute(dst io.Writer, sampleRate, channels int) *Mute {
+	return &Mute{dst: dst, frameBytes: int64(max(channels, 1)) * 2, frameRate: float64(sampleRate)}
+}
+
+// SetMuted starts or stops dropping audio.
+func (m *Mute) SetMuted(muted bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.muted = muted
+}
+
+// Muted reports whether audio is being dropped.
+func (m *Mute) Muted() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.muted
+}
+
+func (m *Mute) Write(p []byte) (int, error) {
+	m.mu.Lock()
+	m.in += int64(len(p))
+	if m.muted {
+		m.mu.Unlock()
+		return len(p), nil
+	}
+	if offset := (m.in - m.out - int64(len(p))) / m.frameBytes; offset != m.offset {
+		m.offset = offset
+		m.skips = append(m.skips, vadSkip{at: m.out / m.frameBytes, offset: offset})
+	}
+	m.out += int64(len(p))
+	m.mu.Unlock()
+
+	if _, err := m.dst.Write(p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// CaptureTime maps a time in seconds on the passed-on stream, as reported by
+// the transcriber, to seconds of audio written to the mute.
+func (m *Mute) CaptureTime(t float64) float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	frame := int64(t * m.frameRate)
+	i := sort.Search(len(m.skips), func(i int) bool { return m.skips[i].at > frame })
+	if i == 0 {
+		return t
+	}
+	return t + float64(m.skips[i-1].offset)/m.frameRate
+}
@@ -0,0 +1,39 @@
+package audio
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestMuteDropsAudioAndMapsTimeline(t *testing.T) {
+	var out bytes.Buffer
+	m := NewMute(&out, TargetSampleRate, 1)
+	second := make([]byte, TargetSampleRate*2)
+
+	write := func() {
+		t.Helper()
+		if n, err := m.Write(second); err != nil || n != len(second) {
+			t.Fatalf("Write returned %d, %v", n, err)
+		}
+	}
+	write()
+	m.SetMuted(true)
+	write()
+	write()
+	if !m.Muted() || out.Len() != len(second) {
+		t.Fatalf("expected muted audio dropped, %d bytes passed on", out.Len())
+	}
+	m.SetMuted(false)
+	write()
+	if out.Len() != 2*len(second) {
+		t.Fatalf("expected audio passed on again, %d bytes", out.Len())
+	}
+
+	// The second second streamed was captured after two muted ones.
+	for _, tc := range []struct{ stream, want float64 }{{0.5, 0.5}, {1.5, 3.5}} {
+		if got := m.CaptureTime(tc.stream); math.Abs(got-tc.want) > 1e-9 {
+			t.Fatalf("CaptureTime(%v) = %v, want %v", tc.stream, got, tc.want)
+		}
+	}
+}
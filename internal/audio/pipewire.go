@@ -0,0 +1,125 @@
+package audio
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// PipeWireCapture records through PipeWire's pw-record tool instead of
+// PortAudio, for Linux systems where PortAudio device handling is unreliable.
+// JACK applications are reachable too when PipeWire provides the JACK graph.
+// PipeWire resamples to TargetSampleRate itself.
+type PipeWireCapture struct {
+	tool  string
+	args  []string
+	chunk int
+
+	command func(args ...string) *exec.Cmd
+
+	mu     sync.Mutex
+	cmd    *exec.Cmd
+	stdout io.ReadCloser
+}
+
+// NewPipeWireCapture records from the PipeWire node named target, or the
+// default source when target is empty, reading buffer worth of audio at a time.
+func NewPipeWireCapture(target string, buffer time.Duration) (*PipeWireCapture, error) {
+	bin, err := exec.LookPath("pw-record")
+	if err != nil {
+		return nil, fmt.Errorf("pipewire capture requires pw-record: %w", err)
+	}
+	return newCommandCapture(bin, pwRecordArgs(target), buffer), nil
+}
+
+// newCommandCapture records PCM16-LE mono at TargetSampleRate from the
+// stdout of bin run with args.
+func newCommandCapture(bin string, args []string, buffer time.Duration) *PipeWireCapture {
+	return &PipeWireCapture{
+		tool:    filepath.Base(bin),
+		args:    args,
+		chunk:   int(buffer.Seconds()*TargetSampleRate) * 2,
+		command: func(args ...string) *exec.Cmd { return exec.Command(bin, args...) },
+	}
+}
+
+func pwRecordArgs(target string) []string {
+	args := []string{
+		"--rate", strconv.Itoa(TargetSampleRate),
+		"--channels", "1",
+		"--format", "s16",
+	}
+	if target != "" {
+		args = append(args, "--target", target)
+	}
+	return append(args, "-")
+}
+
+func (p *PipeWireCapture) Start() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.cmd != nil {
+		return nil
+	}
+	cmd := p.command(p.args...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("%s stdout: %w", p.tool, err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("start %s: %w", p.tool, err)
+	}
+	p.cmd = cmd
+	p.stdout = stdout
+	return nil
+}
+
+func (p *PipeWireCapture) Stop() error {
+	p.mu.Lock()
+	cmd := p.cmd
+	p.cmd = nil
+	p.stdout = nil
+	p.mu.Unlock()
+
+	if cmd == nil {
+		return nil
+	}
+	if err := cmd.Process.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
+		return fmt.Errorf("stop %s: %w", p.tool, err)
+	}
+	_ = cmd.Wait()
+	return nil
+}
+
+func (p *PipeWireCapture) SampleRate() int { return TargetSampleRate }
+
+// Stream copies PCM16-LE from the recording process to w until it exits or
+// Stop is called.
+func (p *PipeWireCapture) Stream(w io.Writer) error {
+	p.mu.Lock()
+	stdout := p.stdout
+	p.mu.Unlock()
+	if stdout == nil {
+		return errors.New("pipewire capture not started")
+	}
+
+	buf := make([]byte, p.chunk)
+	for {
+		n, err := io.ReadFull(stdout, buf)
+		if n > 0 {
+			if _, werr := w.Write(buf[:n-n%2]); werr != nil {
+				return werr
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("%s stream ended: %w", p.tool, err)
+		}
+	}
+}
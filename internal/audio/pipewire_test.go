@@ -0,0 +1,41 @@
+package audio
+
+import (
+	"bytes"
+	"os/exec"
+	"reflect"
+	"testing"
+)
+
+func TestPwRecordArgs(t *testing.T) {
+	got := pwRecordArgs("alsa_input.usb-mic")
+	want := []string{"--rate", "16000", "--channels", "1", "--format", "s16", "--target", "alsa_input.usb-mic", "-"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected args %v", got)
+	}
+	if args := pwRecordArgs(""); args[len(args)-2] == "--target" {
+		t.Fatalf("expected no target for default source, got %v", args)
+	}
+}
+
+func TestPipeWireCaptureStreamsProcessOutput(t *testing.T) {
+	if _, err := exec.LookPath("head"); err != nil {
+		t.Skip("head not available")
+	}
+	p := &PipeWireCapture{
+		chunk:   3200,
+		command: func(...string) *exec.Cmd { return exec.Command("head", "-c", "8000", "/dev/zero") },
+	}
+	if err := p.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer func() { _ = p.Stop() }()
+
+	var out bytes.Buffer
+	if err := p.Stream(&out); err == nil {
+		t.Fatal("expected stream to end with an error when the process exits")
+	}
+	if out.Len() != 8000 {
+		t.Fatalf("expected 8000 bytes streamed, got %d", out.Len())
+	}
+}
@@ -9,25 +9,93 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"sync"
+	"time"
 )
 
 const (
 	defaultSampleRate = 16000
-	pcmChannels       = 1
 	pcmBitDepth       = 16
 )
 
+// pcmChunkDuration is how much audio each raw chunk of a recording holds.
+// Finished chunks are synced to disk and the one being written is synced
+// every pcmSyncInterval of audio, in the background, so a crash loses
+// seconds of a recording rather than all of it.
+const (
+	pcmChunkDuration = 5 * time.Minute
+	pcmSyncInterval  = 10 * time.Second
+)
+
+// Encoders a Recorder can compress finished sessions with.
+const (
+	// EncoderAuto tries ffmpeg, then lame, then the built-in FLAC encoder.
+	EncoderAuto   = "auto"
+	EncoderFFmpeg = "ffmpeg"
+	EncoderLame   = "lame"
+	// EncoderFLAC is the built-in lossless encoder, which needs no external
+	// tools.
+	EncoderFLAC = "flac"
+	EncoderWAV  = "wav"
+)
+
+// EncoderOptions choose how finished sessions are compressed. A chosen
+// encoder that fails still falls back to FLAC, then WAV, so no recording is
+// lost. Bitrate and Quality only apply to the MP3 encoders.
+type EncoderOptions struct {
+	Encoder string
+	// Bitrate is a constant MP3 bitrate in kbps. Zero encodes with variable
+	// bitrate at Quality instead.
+	Bitrate int
+	// Quality is the variable-bitrate quality, from 0 (best) to 9 (smallest).
+	Quality int
+}
+
+// defaultEncoderOptions match what ffmpeg and lame do without flags.
+var defaultEncoderOptions = EncoderOptions{Encoder: EncoderAuto, Bitrate: 128}
+
 type Recorder struct {
 	audioDir string
 
-	mu         sync.Mutex
-	sessionID  string
-	rawPath    string
-	rawFile    *os.File
-	sampleRate int
+	mu        sync.Mutex
+	sessionID string
+	// The session's audio is written to raw chunk files of chunkDuration
+	// each, joined when it ends; rawFile is the chunk being written.
+	chunks        []string
+	rawFile       *os.File
+	syncer        fileSyncer
+	chunkBytes    int
+	unsynced      int
+	chunkDuration time.Duration
+	sampleRate    int
+	channels      int
+	wavOnly       bool
+	encoder       EncoderOptions
+
+	// preRoll holds the most recent audio while no session is recording, so
+	// a session's recording starts before the words that started it.
+	preRoll         pcmRing
+	preRollDuration time.Duration
+
+	// While paused nothing is recorded. dropped is how many bytes of the
+	// session's audio the pause has skipped; resuming writes that much
+	// silence so the recording stays in step with transcript timecodes.
+	paused  bool
+	dropped int
+
+	// captured is how many seconds of audio the recorder has been given,
+	// recorded or not; it runs on the same capture timeline as transcript
+	// timecodes. audioStart is where on it the session's recording begins.
+	captured   float64
+	audioStart float64
 
 	encode func(rawPath, sessionID string) (string, error)
+	// encoding holds the stopped sessions whose audio is being encoded.
+	encoding map[string]bool
+	// keepWAV holds the sessions whose lossless audio is kept as WAV next
+	// to their encoded recording.
+	keepWAV map[string]bool
 }
 
 func NewRecorder(audioDir string) *Recorder {
@@ -35,7 +103,15 @@ func NewRecorder(audioDir string) *Recorder {
 		audioDir = filepath.Join("data", "audio")
 	}
 
-	r := &Recorder{audioDir: audioDir, sampleRate: defaultSampleRate}
+	r := &Recorder{
+		audioDir:      audioDir,
+		sampleRate:    defaultSampleRate,
+		channels:      1,
+		encoder:       defaultEncoderOptions,
+		chunkDuration: pcmChunkDuration,
+		encoding:      make(map[string]bool),
+		keepWAV:       make(map[string]bool),
+	}
 	r.encode = r.defaultEncode
 	return r
 }
@@ -46,6 +122,67 @@ func (r *Recorder) SetSampleRate(sampleRate int) {
 
 	if sampleRate > 0 {
 		r.sampleRate = sampleRate
+		r.resizePreRoll()
+	}
+}
+
+// SetChannels sets how many interleaved channels the recorded PCM holds.
+func (r *Recorder) SetChannels(channels int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if channels > 0 {
+		r.channels = channels
+		r.resizePreRoll()
+	}
+}
+
+// SetPreRoll keeps the last d of audio heard between sessions and writes it
+// at the start of the next session's recording. Zero disables it.
+func (r *Recorder) SetPreRoll(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.preRollDuration = max(d, 0)
+	r.resizePreRoll()
+}
+
+// resizePreRoll sizes the pre-roll buffer for the current format, in whole
+// sample frames so it always starts on one. Buffered audio is dropped.
+func (r *Recorder) resizePreRoll() {
+	frame := int64(r.channels) * pcmBitDepth / 8
+	frames := int64(r.sampleRate) * int64(r.preRollDuration) / int64(time.Second)
+	r.preRoll = pcmRing{buf: make([]byte, frames*frame)}
+}
+
+// SetWAVOnly skips ffmpeg and lame and always writes WAV, so ending a
+// session never spawns an encoder on a constrained device.
+func (r *Recorder) SetWAVOnly(wavOnly bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.wavOnly = wavOnly
+}
+
+// SetEncoder sets how finished sessions are compressed. SetWAVOnly takes
+// precedence.
+func (r *Recorder) SetEncoder(opts EncoderOptions) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if opts.Encoder == "" {
+		opts.Encoder = EncoderAuto
+	}
+	r.encoder = opts
+}
+
+// KeepWAV sets whether a session's lossless audio is kept as WAV next to
+// its encoded recording, with the same name and a .wav extension. It must
+// be set before the session's audio is encoded.
+func (r *Recorder) KeepWAV(sessionID string, keep bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if keep {
+		r.keepWAV[sessionID] = true
+	} else {
+		delete(r.keepWAV, sessionID)
 	}
 }
 
@@ -62,126 +199,393 @@ func (r *Recorder) StartSession(sessionID string) error {
 	}
 
 	if r.rawFile != nil {
-		_ = r.rawFile.Close()
+		r.syncer.push(r.rawFile, true)
+		r.rawFile = nil
 	}
 
-	rawPath := filepath.Join(r.audioDir, sessionID+".pcm")
-	rawFile, err := os.OpenFile(rawPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
-	if err != nil {
-		return fmt.Errorf("open raw pcm file: %w", err)
+	r.sessionID = sessionID
+	r.chunks = nil
+	r.dropped = 0
+	if err := r.openChunk(); err != nil {
+		r.sessionID = ""
+		return err
 	}
 
-	r.sessionID = sessionID
-	r.rawPath = rawPath
-	r.rawFile = rawFile
+	preRoll := r.preRoll.bytes()
+	r.audioStart = max(r.captured-r.seconds(len(preRoll)), 0)
+	if len(preRoll) > 0 {
+		if _, err := r.rawFile.Write(preRoll); err != nil {
+			_ = r.rawFile.Close()
+			r.sessionID, r.rawFile = "", nil
+			return fmt.Errorf("write pre-roll audio: %w", err)
+		}
+		r.chunkBytes += len(preRoll)
+	}
+	r.preRoll.reset()
+
+	return nil
+}
 
+// ChunkPath is where the recorder keeps the index-th raw chunk of a
+// session's audio while it records.
+func ChunkPath(audioDir, sessionID string, index int) string {
+	return filepath.Join(audioDir, fmt.Sprintf("%s.%04d.pcm", sessionID, index))
+}
+
+// openChunk starts the session's next raw chunk file.
+func (r *Recorder) openChunk() error {
+	path := ChunkPath(r.audioDir, r.sessionID, len(r.chunks))
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("open raw pcm chunk: %w", err)
+	}
+	r.chunks = append(r.chunks, path)
+	r.rawFile = f
+	r.chunkBytes, r.unsynced = 0, 0
 	return nil
 }
 
+// closeChunk hands the chunk being written to the syncer to be synced and
+// closed.
+func (r *Recorder) closeChunk() {
+	r.syncer.push(r.rawFile, true)
+	r.rawFile = nil
+}
+
+// seconds is how long n bytes of audio last.
+func (r *Recorder) seconds(n int) float64 {
+	return float64(n) / float64(r.audioBytes(time.Second))
+}
+
+// AudioStart is where, in seconds on the capture timeline, the recording of
+// the session last started begins.
+func (r *Recorder) AudioStart() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.audioStart
+}
+
+// Captured is where, in seconds on the capture timeline, the audio given to
+// the recorder so far ends.
+func (r *Recorder) Captured() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.captured
+}
+
+// audioBytes is how many bytes of PCM make up d of audio in whole frames.
+func (r *Recorder) audioBytes(d time.Duration) int {
+	frame := r.channels * pcmBitDepth / 8
+	return max(int(int64(r.sampleRate)*int64(d)/int64(time.Second)), 1) * frame
+}
+
+// EndSession stops recording the session and encodes its audio, returning
+// the encoded file.
 func (r *Recorder) EndSession() (string, error) {
+	encode, err := r.StopSession()
+	if encode == nil || err != nil {
+		return "", err
+	}
+	return encode()
+}
+
+// StopSession stops recording the session and returns a function that
+// encodes its audio, so a slow encoder can run in the background while the
+// next session records. It returns a nil function when no session was
+// recording.
+func (r *Recorder) StopSession() (func() (string, error), error) {
 	r.mu.Lock()
 	if r.sessionID == "" || r.rawFile == nil {
 		r.mu.Unlock()
-		return "", nil
+		return nil, nil
 	}
 
 	sessionID := r.sessionID
-	rawPath := r.rawPath
-	rawFile := r.rawFile
-
+	chunks := r.chunks
+	sampleRate, channels := r.sampleRate, r.channels
+	r.closeChunk()
 	r.sessionID = ""
-	r.rawPath = ""
-	r.rawFile = nil
+	r.chunks = nil
+	// Marked before unlocking, so the session is never neither recording
+	// nor encoding.
+	r.encoding[sessionID] = true
 	r.mu.Unlock()
 
-	if err := rawFile.Close(); err != nil {
-		return "", fmt.Errorf("close raw pcm file: %w", err)
+	// The chunks are left for recovery when they can't be made durable.
+	if err := r.syncer.flush(); err != nil {
+		r.mu.Lock()
+		delete(r.encoding, sessionID)
+		r.mu.Unlock()
+		return nil, err
 	}
 
-	audioPath, err := r.encode(rawPath, sessionID)
+	return func() (string, error) {
+		defer func() {
+			r.mu.Lock()
+			delete(r.encoding, sessionID)
+			r.mu.Unlock()
+		}()
+		audioPath, _, err := r.finish(sessionID, chunks, sampleRate, channels)
+		return audioPath, err
+	}, nil
+}
+
+// finish joins a session's raw chunks, draws its waveform and encodes it,
+// returning the encoded file and how many bytes of raw audio it holds. Raw
+// audio that can't be finished is set aside, so it isn't recovered again at
+// every startup.
+func (r *Recorder) finish(sessionID string, chunks []string, sampleRate, channels int) (audioPath string, size int64, err error) {
+	defer func() {
+		if err != nil {
+			r.setAside(sessionID)
+		}
+	}()
+	rawPath := filepath.Join(r.audioDir, sessionID+".pcm")
+	if len(chunks) > 0 {
+		if err := concatFiles(rawPath, chunks); err != nil {
+			return "", 0, fmt.Errorf("join raw pcm chunks: %w", err)
+		}
+		for _, chunk := range chunks {
+			_ = os.Remove(chunk)
+		}
+	}
+	info, err := os.Stat(rawPath)
 	if err != nil {
-		return "", err
+		return "", 0, fmt.Errorf("stat raw pcm: %w", err)
+	}
+
+	audioPath, err = r.encode(rawPath, sessionID)
+	if err != nil {
+		return "", 0, err
+	}
+	// The waveform is only for display; a session without one still plays.
+	if err := writeWaveform(rawPath, WaveformPath(audioPath), sampleRate, channels); err != nil {
+		_ = os.Remove(WaveformPath(audioPath))
+	}
+	r.mu.Lock()
+	keepWAV := r.keepWAV[sessionID]
+	delete(r.keepWAV, sessionID)
+	r.mu.Unlock()
+	// The encoded recording is what matters; a WAV that couldn't be
+	// written is simply missing, which the caller can see.
+	if wavPath := strings.TrimSuffix(audioPath, filepath.Ext(audioPath)) + ".wav"; keepWAV && wavPath != audioPath {
+		if err := pcmToWav(rawPath, wavPath, sampleRate, channels); err != nil {
+			_ = os.Remove(wavPath)
+		}
 	}
 
 	_ = os.Remove(rawPath)
-	return audioPath, nil
+	return audioPath, info.Size(), nil
+}
+
+// writeWaveform stores the envelope of the raw PCM at rawPath in path.
+func writeWaveform(rawPath, path string, sampleRate, channels int) error {
+	in, err := os.Open(rawPath)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+	return WriteWaveform(path, in, sampleRate, channels)
+}
+
+// concatFiles writes the contents of srcs, in order, to dst.
+func concatFiles(dst string, srcs []string) error {
+	if len(srcs) == 1 {
+		return os.Rename(srcs[0], dst)
+	}
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	for _, src := range srcs {
+		in, err := os.Open(src)
+		if err != nil {
+			_ = out.Close()
+			return err
+		}
+		_, err = io.Copy(out, in)
+		_ = in.Close()
+		if err != nil {
+			_ = out.Close()
+			return err
+		}
+	}
+	return out.Close()
+}
+
+// Pause stops recording until Resume. Audio written meanwhile is dropped,
+// pre-roll included.
+func (r *Recorder) Pause() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.paused {
+		return
+	}
+	r.paused = true
+	r.dropped = 0
+	r.preRoll.reset()
+}
+
+// Resume starts recording again. A session recording through the pause gets
+// the audio it skipped as silence, so it keeps lining up with the
+// transcript.
+func (r *Recorder) Resume() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.paused {
+		return nil
+	}
+	r.paused = false
+	pad := r.dropped
+	r.dropped = 0
+	if r.rawFile == nil || pad == 0 {
+		return nil
+	}
+	silence := make([]byte, r.audioBytes(time.Second))
+	for ; pad > 0; pad -= len(silence) {
+		if err := r.appendPCM(silence[:min(pad, len(silence))]); err != nil {
+			return fmt.Errorf("pad paused audio: %w", err)
+		}
+	}
+	return nil
 }
 
 func (r *Recorder) writePCM(data []byte) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	r.captured += r.seconds(len(data))
+	if r.paused {
+		if r.rawFile != nil {
+			r.dropped += len(data)
+		}
+		return nil
+	}
 	if r.rawFile == nil {
+		r.preRoll.write(data)
 		return nil
 	}
+	return r.appendPCM(data)
+}
 
+// appendPCM writes to the session's raw chunks, starting a new chunk when
+// the current one is full. A chunk that failed to sync in the background is
+// reported by the next write.
+func (r *Recorder) appendPCM(data []byte) error {
 	if _, err := r.rawFile.Write(data); err != nil {
 		return fmt.Errorf("write raw pcm bytes: %w", err)
 	}
-	return nil
+	r.chunkBytes += len(data)
+	r.unsynced += len(data)
+
+	if r.chunkBytes >= r.audioBytes(r.chunkDuration) {
+		r.closeChunk()
+		if err := r.openChunk(); err != nil {
+			return err
+		}
+	} else if r.unsynced >= r.audioBytes(pcmSyncInterval) {
+		r.unsynced = 0
+		r.syncer.push(r.rawFile, false)
+	}
+	return r.syncer.takeErr()
 }
 
 func (r *Recorder) defaultEncode(rawPath, sessionID string) (string, error) {
 	r.mu.Lock()
 	sampleRate := r.sampleRate
+	channels := r.channels
+	wavOnly := r.wavOnly
+	opts := r.encoder
 	r.mu.Unlock()
 	if sampleRate <= 0 {
 		sampleRate = defaultSampleRate
 	}
 
-	mp3Path := filepath.Join(r.audioDir, sessionID+".mp3")
+	if !wavOnly && opts.Encoder != EncoderWAV {
+		mp3Path := filepath.Join(r.audioDir, sessionID+".mp3")
 
-	if err := encodeWithFFmpeg(rawPath, mp3Path, sampleRate); err == nil {
-		return mp3Path, nil
-	}
+		if opts.Encoder == EncoderAuto || opts.Encoder == EncoderFFmpeg {
+			if err := encodeWithFFmpeg(rawPath, mp3Path, sampleRate, channels, opts); err == nil {
+				return mp3Path, nil
+			}
+		}
+
+		if opts.Encoder == EncoderAuto || opts.Encoder == EncoderLame {
+			if err := encodeWithLame(rawPath, mp3Path, sampleRate, channels, opts); err == nil {
+				return mp3Path, nil
+			}
+		}
 
-	if err := encodeWithLame(rawPath, mp3Path, sampleRate); err == nil {
-		return mp3Path, nil
+		flacPath := filepath.Join(r.audioDir, sessionID+".flac")
+		if err := pcmToFLAC(rawPath, flacPath, sampleRate, channels); err == nil {
+			return flacPath, nil
+		}
+		_ = os.Remove(flacPath)
 	}
 
 	wavPath := filepath.Join(r.audioDir, sessionID+".wav")
-	if err := pcmToWav(rawPath, wavPath, sampleRate); err != nil {
+	if err := pcmToWav(rawPath, wavPath, sampleRate, channels); err != nil {
 		return "", fmt.Errorf("encode wav fallback: %w", err)
 	}
 
 	return wavPath, nil
 }
 
-func encodeWithFFmpeg(rawPath, outputPath string, sampleRate int) error {
-	cmd := exec.Command(
-		"ffmpeg",
+func encodeWithFFmpeg(rawPath, outputPath string, sampleRate, channels int, opts EncoderOptions) error {
+	cmd := exec.Command("ffmpeg", ffmpegArgs(rawPath, outputPath, sampleRate, channels, opts)...)
+	if err := cmd.Run(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func ffmpegArgs(rawPath, outputPath string, sampleRate, channels int, opts EncoderOptions) []string {
+	args := []string{
 		"-y",
 		"-f", "s16le",
 		"-ar", strconv.Itoa(sampleRate),
-		"-ac", "1",
+		"-ac", strconv.Itoa(channels),
 		"-i", rawPath,
-		outputPath,
-	)
+		"-codec:a", "libmp3lame",
+	}
+	if opts.Bitrate > 0 {
+		args = append(args, "-b:a", strconv.Itoa(opts.Bitrate)+"k")
+	} else {
+		args = append(args, "-q:a", strconv.Itoa(opts.Quality))
+	}
+	return append(args, outputPath)
+}
+
+func encodeWithLame(rawPath, outputPath string, sampleRate, channels int, opts EncoderOptions) error {
+	cmd := exec.Command("lame", lameArgs(rawPath, outputPath, sampleRate, channels, opts)...)
 	if err := cmd.Run(); err != nil {
 		return err
 	}
 	return nil
 }
 
-func encodeWithLame(rawPath, outputPath string, sampleRate int) error {
+func lameArgs(rawPath, outputPath string, sampleRate, channels int, opts EncoderOptions) []string {
 	khz := float64(sampleRate) / 1000.0
 	formatted := strconv.FormatFloat(khz, 'f', -1, 64)
-	cmd := exec.Command(
-		"lame",
+	mode := "m"
+	if channels == 2 {
+		mode = "s"
+	}
+	args := []string{
 		"-r",
 		"-s", formatted,
 		"--bitwidth", "16",
-		"-m", "m",
-		rawPath,
-		outputPath,
-	)
-	if err := cmd.Run(); err != nil {
-		return err
+		"-m", mode,
 	}
-	return nil
+	if opts.Bitrate > 0 {
+		args = append(args, "--cbr", "-b", strconv.Itoa(opts.Bitrate))
+	} else {
+		args = append(args, "-V", strconv.Itoa(opts.Quality))
+	}
+	return append(args, rawPath, outputPath)
 }
 
-func pcmToWav(rawPath, wavPath string, sampleRate int) error {
+func pcmToWav(rawPath, wavPath string, sampleRate, channels int) error {
 	pcmData, err := os.ReadFile(rawPath)
 	if err != nil {
 		return fmt.Errorf("read raw pcm data: %w", err)
@@ -193,7 +597,7 @@ func pcmToWav(rawPath, wavPath string, sampleRate int) error {
 	}
 	defer func() { _ = out.Close() }()
 
-	header, err := wavHeader(len(pcmData), sampleRate, pcmChannels, pcmBitDepth)
+	header, err := wavHeader(len(pcmData), sampleRate, channels, pcmBitDepth)
 	if err != nil {
 		return fmt.Errorf("build wav header: %w", err)
 	}
@@ -274,3 +678,41 @@ func (w *teeWriter) Write(p []byte) (int, error) {
 
 	return n, nil
 }
+
+// pcmRing keeps the most recent len(buf) bytes written to it.
+type pcmRing struct {
+	buf   []byte
+	start int
+	n     int
+}
+
+func (b *pcmRing) write(p []byte) {
+	size := len(b.buf)
+	if size == 0 {
+		return
+	}
+	if len(p) >= size {
+		copy(b.buf, p[len(p)-size:])
+		b.start, b.n = 0, size
+		return
+	}
+	end := (b.start + b.n) % size
+	copied := copy(b.buf[end:], p)
+	copy(b.buf, p[copied:])
+	if b.n += len(p); b.n > size {
+		b.start = (b.start + b.n - size) % size
+		b.n = size
+	}
+}
+
+// bytes returns the buffered audio, oldest first.
+func (b *pcmRing) bytes() []byte {
+	out := make([]byte, 0, b.n)
+	end := min(b.start+b.n, len(b.buf))
+	out = append(out, b.buf[b.start:end]...)
+	return append(out, b.buf[:b.n-(end-b.start)]...)
+}
+
+func (b *pcmRing) reset() {
+	b.start, b.n = 0, 0
+}
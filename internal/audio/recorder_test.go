@@ -2,9 +2,13 @@ package audio
 
 import (
 	"bytes"
+	"encoding/binary"
+	"math"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestRecorderProducesOutputFile(t *testing.T) {
@@ -81,3 +85,301 @@ func TestTeeWriterWritesToBothDestinations(t *testing.T) {
 		t.Fatalf("expected raw pcm temp file cleanup, file still exists with %d bytes", len(rawBytes))
 	}
 }
+
+func TestRecorderWAVOnlySkipsEncoders(t *testing.T) {
+	t.Setenv("PATH", "")
+	dir := t.TempDir()
+	recorder := NewRecorder(dir)
+	recorder.SetWAVOnly(true)
+
+	if err := recorder.StartSession("pi"); err != nil {
+		t.Fatalf("StartSession failed: %v", err)
+	}
+	if _, err := recorder.Writer(bytes.NewBuffer(nil)).Write([]byte{1, 2, 3, 4}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	path, err := recorder.EndSession()
+	if err != nil {
+		t.Fatalf("EndSession failed: %v", err)
+	}
+	if path != filepath.Join(dir, "pi.wav") {
+		t.Fatalf("expected wav output, got %q", path)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat output file failed: %v", err)
+	}
+	if info.Size() != 44+4 {
+		t.Fatalf("expected header plus payload, got %d bytes", info.Size())
+	}
+}
+
+func TestRecorderWAVStereoHeader(t *testing.T) {
+	dir := t.TempDir()
+	recorder := NewRecorder(dir)
+	recorder.SetWAVOnly(true)
+	recorder.SetChannels(2)
+
+	if err := recorder.StartSession("stereo"); err != nil {
+		t.Fatalf("StartSession failed: %v", err)
+	}
+	if _, err := recorder.Writer(bytes.NewBuffer(nil)).Write([]byte{1, 2, 3, 4}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	path, err := recorder.EndSession()
+	if err != nil {
+		t.Fatalf("EndSession failed: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if channels := binary.LittleEndian.Uint16(data[22:]); channels != 2 {
+		t.Fatalf("expected 2 channels in the wav header, got %d", channels)
+	}
+	if blockAlign := binary.LittleEndian.Uint16(data[32:]); blockAlign != 4 {
+		t.Fatalf("expected 4-byte frames, got %d", blockAlign)
+	}
+}
+
+func TestEncoderArgs(t *testing.T) {
+	cbr := EncoderOptions{Encoder: EncoderAuto, Bitrate: 64}
+	vbr := EncoderOptions{Encoder: EncoderAuto, Quality: 7}
+
+	ffmpeg := strings.Join(ffmpegArgs("in.pcm", "out.mp3", 16000, 1, cbr), " ")
+	if !strings.Contains(ffmpeg, "-ar 16000 -ac 1 -i in.pcm -codec:a libmp3lame -b:a 64k out.mp3") {
+		t.Fatalf("unexpected ffmpeg args %q", ffmpeg)
+	}
+	if ffmpeg := strings.Join(ffmpegArgs("in.pcm", "out.mp3", 16000, 1, vbr), " "); !strings.HasSuffix(ffmpeg, "-q:a 7 out.mp3") {
+		t.Fatalf("unexpected ffmpeg vbr args %q", ffmpeg)
+	}
+
+	lame := strings.Join(lameArgs("in.pcm", "out.mp3", 16000, 2, cbr), " ")
+	if lame != "-r -s 16 --bitwidth 16 -m s --cbr -b 64 in.pcm out.mp3" {
+		t.Fatalf("unexpected lame args %q", lame)
+	}
+	if lame := strings.Join(lameArgs("in.pcm", "out.mp3", 16000, 1, vbr), " "); !strings.Contains(lame, "-m m -V 7 in.pcm") {
+		t.Fatalf("unexpected lame vbr args %q", lame)
+	}
+}
+
+func TestRecorderWAVEncoder(t *testing.T) {
+	dir := t.TempDir()
+	recorder := NewRecorder(dir)
+	recorder.SetEncoder(EncoderOptions{Encoder: EncoderWAV})
+
+	if err := recorder.StartSession("wav"); err != nil {
+		t.Fatalf("StartSession failed: %v", err)
+	}
+	if _, err := recorder.Writer(bytes.NewBuffer(nil)).Write([]byte{1, 2, 3, 4}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	path, err := recorder.EndSession()
+	if err != nil {
+		t.Fatalf("EndSession failed: %v", err)
+	}
+	if path != filepath.Join(dir, "wav.wav") {
+		t.Fatalf("expected wav output, got %q", path)
+	}
+}
+
+func TestRecorderKeepWAV(t *testing.T) {
+	dir := t.TempDir()
+	recorder := NewRecorder(dir)
+	recorder.encode = func(rawPath, sessionID string) (string, error) {
+		path := filepath.Join(dir, sessionID+".mp3")
+		return path, os.WriteFile(path, []byte("mp3"), 0o644)
+	}
+
+	for _, id := range []string{"kept", "dropped"} {
+		if err := recorder.StartSession(id); err != nil {
+			t.Fatalf("StartSession failed: %v", err)
+		}
+		recorder.KeepWAV(id, id == "kept")
+		if _, err := recorder.Writer(bytes.NewBuffer(nil)).Write([]byte{1, 2, 3, 4}); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+		if _, err := recorder.EndSession(); err != nil {
+			t.Fatalf("EndSession failed: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "kept.wav"))
+	if err != nil {
+		t.Fatalf("expected the flagged session's wav to be kept: %v", err)
+	}
+	if len(data) != 44+4 || !bytes.Equal(data[44:], []byte{1, 2, 3, 4}) {
+		t.Fatalf("unexpected kept wav of %d bytes", len(data))
+	}
+	if _, err := os.Stat(filepath.Join(dir, "dropped.wav")); !os.IsNotExist(err) {
+		t.Fatalf("expected no wav for an unflagged session, got %v", err)
+	}
+}
+
+func TestRecorderPreRoll(t *testing.T) {
+	dir := t.TempDir()
+	recorder := NewRecorder(dir)
+	recorder.SetSampleRate(1000)
+	recorder.SetPreRoll(5 * time.Millisecond) // five samples, ten bytes
+	var raw []byte
+	recorder.encode = func(rawPath, sessionID string) (string, error) {
+		var err error
+		raw, err = os.ReadFile(rawPath)
+		return filepath.Join(dir, sessionID+".wav"), err
+	}
+	writer := recorder.Writer(bytes.NewBuffer(nil))
+
+	// Before the session: only the last five samples are kept, across
+	// writes of odd sizes that wrap the buffer.
+	for _, p := range [][]byte{{1, 1, 2, 2, 3, 3}, {4, 4, 5, 5, 6, 6, 7, 7}, {8, 8}} {
+		if _, err := writer.Write(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := recorder.StartSession("s1"); err != nil {
+		t.Fatalf("StartSession failed: %v", err)
+	}
+	// Eight samples were captured, the last five of them kept.
+	if got := recorder.AudioStart(); math.Abs(got-0.003) > 1e-9 {
+		t.Fatalf("expected the recording to start 3ms into capture, got %v", got)
+	}
+	if _, err := writer.Write([]byte{9, 9}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := recorder.EndSession(); err != nil {
+		t.Fatalf("EndSession failed: %v", err)
+	}
+	if want := []byte{4, 4, 5, 5, 6, 6, 7, 7, 8, 8, 9, 9}; !bytes.Equal(raw, want) {
+		t.Fatalf("expected the pre-roll ahead of the session audio, got %v", raw)
+	}
+
+	// Audio recorded into one session is not replayed into the next.
+	if err := recorder.StartSession("s2"); err != nil {
+		t.Fatalf("StartSession failed: %v", err)
+	}
+	if got := recorder.AudioStart(); math.Abs(got-0.009) > 1e-9 {
+		t.Fatalf("expected the recording to start 9ms into capture, got %v", got)
+	}
+	if _, err := recorder.EndSession(); err != nil {
+		t.Fatalf("EndSession failed: %v", err)
+	}
+	if len(raw) != 0 {
+		t.Fatalf("expected an empty recording, got %v", raw)
+	}
+
+	// A write larger than the buffer keeps only its tail.
+	if _, err := writer.Write([]byte{1, 1, 2, 2, 3, 3, 4, 4, 5, 5, 6, 6, 7, 7}); err != nil {
+		t.Fatal(err)
+	}
+	if err := recorder.StartSession("s3"); err != nil {
+		t.Fatalf("StartSession failed: %v", err)
+	}
+	if _, err := recorder.EndSession(); err != nil {
+		t.Fatalf("EndSession failed: %v", err)
+	}
+	if want := []byte{3, 3, 4, 4, 5, 5, 6, 6, 7, 7}; !bytes.Equal(raw, want) {
+		t.Fatalf("expected the tail of a large write, got %v", raw)
+	}
+}
+
+func TestRecorderChunksRawAudio(t *testing.T) {
+	dir := t.TempDir()
+	recorder := NewRecorder(dir)
+	recorder.SetSampleRate(1000)
+	recorder.chunkDuration = 3 * time.Millisecond // three samples, six bytes
+	var raw []byte
+	recorder.encode = func(rawPath, sessionID string) (string, error) {
+		var err error
+		raw, err = os.ReadFile(rawPath)
+		return filepath.Join(dir, sessionID+".wav"), err
+	}
+	writer := recorder.Writer(bytes.NewBuffer(nil))
+
+	if err := recorder.StartSession("s1"); err != nil {
+		t.Fatalf("StartSession failed: %v", err)
+	}
+	for _, p := range [][]byte{{1, 1, 2, 2}, {3, 3, 4, 4}, {5, 5}, {6, 6, 7, 7}} {
+		if _, err := writer.Write(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Mid-session the audio so far is on disk, split across chunk files.
+	var onDisk []byte
+	for i := range 3 {
+		data, err := os.ReadFile(ChunkPath(dir, "s1", i))
+		if err != nil {
+			t.Fatalf("expected chunk %d on disk: %v", i, err)
+		}
+		onDisk = append(onDisk, data...)
+	}
+	if want := []byte{1, 1, 2, 2, 3, 3, 4, 4, 5, 5, 6, 6, 7, 7}; !bytes.Equal(onDisk, want) {
+		t.Fatalf("expected the chunks to hold the audio so far, got %v", onDisk)
+	}
+
+	if _, err := recorder.EndSession(); err != nil {
+		t.Fatalf("EndSession failed: %v", err)
+	}
+	if !bytes.Equal(raw, onDisk) {
+		t.Fatalf("expected the chunks joined for encoding, got %v", raw)
+	}
+	if leftover, _ := filepath.Glob(filepath.Join(dir, "*.pcm")); len(leftover) != 0 {
+		t.Fatalf("expected raw files cleaned up, got %v", leftover)
+	}
+}
+
+func TestFileSyncerReportsFailures(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "chunk.pcm"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = f.Close()
+
+	var s fileSyncer
+	s.push(f, true)
+	if err := s.flush(); err == nil || !strings.Contains(err.Error(), "sync raw pcm chunk") {
+		t.Fatalf("expected the failed sync reported, got %v", err)
+	}
+	if err := s.flush(); err != nil {
+		t.Fatalf("expected the failure reported once, got %v", err)
+	}
+}
+
+func TestRecorderPauseKeepsTimeline(t *testing.T) {
+	dir := t.TempDir()
+	recorder := NewRecorder(dir)
+	recorder.SetSampleRate(1000)
+	var raw []byte
+	recorder.encode = func(rawPath, sessionID string) (string, error) {
+		var err error
+		raw, err = os.ReadFile(rawPath)
+		return filepath.Join(dir, sessionID+".wav"), err
+	}
+	writer := recorder.Writer(bytes.NewBuffer(nil))
+
+	if err := recorder.StartSession("s1"); err != nil {
+		t.Fatalf("StartSession failed: %v", err)
+	}
+	if _, err := writer.Write([]byte{1, 1, 2, 2}); err != nil {
+		t.Fatal(err)
+	}
+	recorder.Pause()
+	// Audio that still arrives while paused isn't kept.
+	if _, err := writer.Write([]byte{9, 9, 9, 9, 9, 9}); err != nil {
+		t.Fatal(err)
+	}
+	if err := recorder.Resume(); err != nil {
+		t.Fatalf("Resume failed: %v", err)
+	}
+	if _, err := writer.Write([]byte{3, 3}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := recorder.EndSession(); err != nil {
+		t.Fatalf("EndSession failed: %v", err)
+	}
+	if want := []byte{1, 1, 2, 2, 0, 0, 0, 0, 0, 0, 3, 3}; !bytes.Equal(raw, want) {
+		t.Fatalf("expected the pause recorded as three samples of silence, got %v", raw)
+	}
+}
@@ -0,0 +1,96 @@
+package audio
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"time"
+)
+
+// rawName matches the raw audio a recorder leaves in its audio folder: a
+// session's chunks while it records, and the joined file while it encodes.
+var rawName = regexp.MustCompile(`^(.+?)(\.\d{4})?\.pcm$`)
+
+// failedSuffix is added to the raw audio of a session that couldn't be
+// encoded. It is kept for recovering by hand but no longer orphaned.
+const failedSuffix = ".failed"
+
+// OrphanedSessions lists the sessions whose raw audio was left in the audio
+// folder by a run that stopped before encoding it, such as after a crash.
+// The sessions being recorded or encoded aren't included.
+func (r *Recorder) OrphanedSessions() ([]string, error) {
+	r.mu.Lock()
+	busy := maps.Clone(r.encoding)
+	busy[r.sessionID] = true
+	r.mu.Unlock()
+
+	entries, err := os.ReadDir(r.audioDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read audio directory: %w", err)
+	}
+	var ids []string
+	for _, entry := range entries {
+		m := rawName.FindStringSubmatch(entry.Name())
+		if m == nil || !entry.Type().IsRegular() || busy[m[1]] || slices.Contains(ids, m[1]) {
+			continue
+		}
+		ids = append(ids, m[1])
+	}
+	return ids, nil
+}
+
+// RecoverSession encodes the raw audio a previous run left for sessionID,
+// as EndSession would have, returning the encoded file and how long the
+// recording is. The audio is assumed to be in the recorder's current sample
+// rate and channel layout.
+func (r *Recorder) RecoverSession(sessionID string) (string, time.Duration, error) {
+	r.mu.Lock()
+	if sessionID == r.sessionID || r.encoding[sessionID] {
+		r.mu.Unlock()
+		return "", 0, fmt.Errorf("session %s is still recording", sessionID)
+	}
+	sampleRate, channels := r.sampleRate, r.channels
+	r.mu.Unlock()
+
+	entries, err := os.ReadDir(r.audioDir)
+	if err != nil {
+		return "", 0, fmt.Errorf("read audio directory: %w", err)
+	}
+	// Chunks are only deleted once joined, so when any are left they are
+	// the whole recording, even if a run stopped partway through joining
+	// them. Their zero-padded names sort in recording order.
+	var chunks []string
+	for _, entry := range entries {
+		if m := rawName.FindStringSubmatch(entry.Name()); m != nil && m[1] == sessionID && m[2] != "" {
+			chunks = append(chunks, filepath.Join(r.audioDir, entry.Name()))
+		}
+	}
+	slices.Sort(chunks)
+
+	audioPath, size, err := r.finish(sessionID, chunks, sampleRate, channels)
+	if err != nil {
+		return "", 0, err
+	}
+	frame := int64(channels * pcmBitDepth / 8)
+	return audioPath, time.Duration(size / frame * int64(time.Second) / int64(sampleRate)), nil
+}
+
+// setAside renames the raw audio left for sessionID with failedSuffix.
+func (r *Recorder) setAside(sessionID string) {
+	entries, err := os.ReadDir(r.audioDir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if m := rawName.FindStringSubmatch(entry.Name()); m != nil && m[1] == sessionID {
+			path := filepath.Join(r.audioDir, entry.Name())
+			_ = os.Rename(path, path+failedSuffix)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package audio
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRecorderRecoversOrphanedAudio(t *testing.T) {
+	dir := t.TempDir()
+	second := bytes.Repeat([]byte{1, 0}, defaultSampleRate)
+	// A run that crashed mid-session left two chunks, and one that crashed
+	// while encoding left the joined file.
+	for path, data := range map[string][]byte{
+		ChunkPath(dir, "crashed", 0):            second,
+		ChunkPath(dir, "crashed", 1):            append(second, second...),
+		filepath.Join(dir, "encoding.pcm"):      second,
+		filepath.Join(dir, "done.wav"):          []byte("RIFF"),
+		filepath.Join(dir, "done.peaks.json"):   []byte("{}"),
+		filepath.Join(dir, "notes.pcm.partial"): []byte("x"),
+	} {
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	recorder := NewRecorder(dir)
+	recorder.SetWAVOnly(true)
+	if err := recorder.StartSession("live"); err != nil {
+		t.Fatalf("StartSession failed: %v", err)
+	}
+
+	ids, err := recorder.OrphanedSessions()
+	if err != nil {
+		t.Fatalf("OrphanedSessions failed: %v", err)
+	}
+	slices.Sort(ids)
+	if !slices.Equal(ids, []string{"crashed", "encoding"}) {
+		t.Fatalf("expected the crashed and encoding sessions, got %v", ids)
+	}
+	if _, _, err := recorder.RecoverSession("live"); err == nil {
+		t.Fatal("expected the session being recorded to be left alone")
+	}
+
+	for id, want := range map[string]time.Duration{"crashed": 3 * time.Second, "encoding": time.Second} {
+		path, d, err := recorder.RecoverSession(id)
+		if err != nil {
+			t.Fatalf("RecoverSession(%s) failed: %v", id, err)
+		}
+		if d != want || path != filepath.Join(dir, id+".wav") {
+			t.Fatalf("RecoverSession(%s): expected %s of audio in %s.wav, got %s in %s", id, want, id, d, path)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil || len(data) != 44+int(want/time.Second)*len(second) {
+			t.Fatalf("expected %s encoded as WAV, got %d bytes (%v)", id, len(data), err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, id+".peaks.json")); err != nil {
+			t.Fatalf("expected a waveform for %s: %v", id, err)
+		}
+	}
+
+	ids, err = recorder.OrphanedSessions()
+	if err != nil || len(ids) != 0 {
+		t.Fatalf("expected no raw audio left, got %v (%v)", ids, err)
+	}
+}
+
+func TestRecorderSetsAsideAudioThatFailsToEncode(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(ChunkPath(dir, "broken", 0), []byte{1, 0}, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	recorder := NewRecorder(dir)
+	recorder.encode = func(string, string) (string, error) { return "", errors.New("disk full") }
+
+	if _, _, err := recorder.RecoverSession("broken"); err == nil {
+		t.Fatal("expected the failed encode reported")
+	}
+	ids, err := recorder.OrphanedSessions()
+	if err != nil || len(ids) != 0 {
+		t.Fatalf("expected the audio no longer orphaned, got %v (%v)", ids, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "broken.pcm"+failedSuffix)); err != nil {
+		t.Fatalf("expected the raw audio kept aside: %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package audio
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// TargetSampleRate is the rate all captured audio is converted to before it
+// is recorded or streamed for transcription.
+const TargetSampleRate = 16000
+
+// Resampler converts a stream of 16-bit mono samples between sample rates
+// using linear interpolation. It keeps state across calls so consecutive
+// chunks resample seamlessly.
+type Resampler struct {
+	inRate  int
+	outRate int
+	step    float64
+
+	pos      float64
+	prev     int16
+	havePrev bool
+}
+
+// NewResampler converts from inRate to outRate, so a mic opened at whatever
+// rate its hardware prefers can still be streamed at TargetSampleRate.
+func NewResampler(inRate, outRate int) *Resampler {
+	return &Resampler{inRate: inRate, outRate: outRate, step: float64(inRate) / float64(outRate)}
+}
+
+// Process resamples the next chunk of input samples.
+func (r *Resampler) Process(in []int16) []int16 {
+	if r.inRate == r.outRate || len(in) == 0 {
+		return append([]int16(nil), in...)
+	}
+
+	src := in
+	if r.havePrev {
+		src = make([]int16, 0, len(in)+1)
+		src = append(src, r.prev)
+		src = append(src, in...)
+	}
+
+	out := make([]int16, 0, int(float64(len(src))/r.step)+1)
+	last := float64(len(src) - 1)
+	for ; r.pos < last; r.pos += r.step {
+		i := int(r.pos)
+		frac := r.pos - float64(i)
+		v := float64(src[i]) + (float64(src[i+1])-float64(src[i]))*frac
+		out = append(out, clip16(v))
+	}
+
+	r.prev = src[len(src)-1]
+	r.havePrev = true
+	r.pos -= last
+	return out
+}
+
+// NewResamplingWriter converts PCM16-LE written at inRate to outRate before
+// passing it on to dst. Writes are expected to hold whole samples.
+func NewResamplingWriter(dst io.Writer, inRate, outRate int) io.Writer {
+	if inRate == outRate {
+		return dst
+	}
+	return &resamplingWriter{dst: dst, resampler: NewResampler(inRate, outRate)}
+}
+
+type resamplingWriter struct {
+	dst       io.Writer
+	resampler *Resampler
+	samples   []int16
+	out       []byte
+}
+
+func (w *resamplingWriter) Write(p []byte) (int, error) {
+	n := len(p) / 2
+	w.samples = w.samples[:0]
+	for i := range n {
+		w.samples = append(w.samples, int16(binary.LittleEndian.Uint16(p[2*i:])))
+	}
+
+	resampled := w.resampler.Process(w.samples)
+	w.out = w.out[:0]
+	for _, s := range resampled {
+		w.out = binary.LittleEndian.AppendUint16(w.out, uint16(s))
+	}
+	if _, err := w.dst.Write(w.out); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,70 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestResamplerDownsamplesInChunks(t *testing.T) {
+	in := make([]int16, 4800) // 100ms at 48kHz
+	for i := range in {
+		in[i] = int16(i)
+	}
+
+	r := NewResampler(48000, 16000)
+	var out []int16
+	for start := 0; start < len(in); start += 480 {
+		out = append(out, r.Process(in[start:start+480])...)
+	}
+
+	if len(out) < 1599 || len(out) > 1600 {
+		t.Fatalf("expected ~1600 samples, got %d", len(out))
+	}
+	for i, s := range out {
+		if int(s) != i*3 {
+			t.Fatalf("sample %d: expected %d, got %d", i, i*3, s)
+		}
+	}
+}
+
+func TestResamplerUpsamplesInterpolating(t *testing.T) {
+	r := NewResampler(8000, 16000)
+	out := r.Process([]int16{0, 100, 200})
+	out = append(out, r.Process([]int16{300})...)
+
+	want := []int16{0, 50, 100, 150, 200, 250}
+	if len(out) != len(want) {
+		t.Fatalf("expected %v, got %v", want, out)
+	}
+	for i := range want {
+		if out[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, out)
+		}
+	}
+}
+
+func TestResamplingWriter(t *testing.T) {
+	var dst bytes.Buffer
+	w := NewResamplingWriter(&dst, 32000, 16000)
+
+	samples := []int16{10, 20, 30, 40, 50, 60}
+	var in bytes.Buffer
+	_ = binary.Write(&in, binary.LittleEndian, samples)
+
+	n, err := w.Write(in.Bytes())
+	if err != nil || n != in.Len() {
+		t.Fatalf("Write returned n=%d err=%v", n, err)
+	}
+
+	got := make([]int16, dst.Len()/2)
+	_ = binary.Read(&dst, binary.LittleEndian, got)
+	want := []int16{10, 30, 50}
+	if len(got) != len(want) || got[0] != 10 || got[1] != 30 || got[2] != 50 {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+
+	if NewResamplingWriter(&dst, 16000, 16000) != &dst {
+		t.Fatal("expected passthrough writer for equal rates")
+	}
+}
@@ -0,0 +1,204 @@
+package audio
+
+import (
+	"errors"
+	"io"
+	"sync"
+)
+
+// ErrCaptureLost is returned by SwitchableCapture while a failed input is
+// waiting to be reopened.
+var ErrCaptureLost = errors.New("capture device lost")
+
+// errStillStreaming is returned by Reopen while the input is still running.
+var errStillStreaming = errors.New("capture is still streaming")
+
+// SwitchableCapture is a Capture whose underlying input can be replaced while
+// it streams, so the capture device can change without restarting the
+// transcription pipeline. Output is always at TargetSampleRate, whatever the
+// rate of the current input.
+type SwitchableCapture struct {
+	mu        sync.Mutex
+	current   Capture
+	device    string
+	gen       int
+	streaming bool
+	stopped   bool
+}
+
+// NewSwitchableCapture wraps c, captured from the named device ("" for the
+// system default).
+func NewSwitchableCapture(c Capture, device string) *SwitchableCapture {
+	return &SwitchableCapture{current: c, device: device}
+}
+
+// Device returns the name of the current input device, "" for the default.
+func (s *SwitchableCapture) Device() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.device
+}
+
+func (s *SwitchableCapture) Start() error {
+	s.mu.Lock()
+	s.stopped = false
+	c := s.current
+	s.mu.Unlock()
+	return c.Start()
+}
+
+func (s *SwitchableCapture) Stop() error {
+	s.mu.Lock()
+	s.stopped = true
+	c := s.current
+	s.mu.Unlock()
+	return c.Stop()
+}
+
+func (s *SwitchableCapture) SampleRate() int { return TargetSampleRate }
+
+// Switch starts c and makes it the current input, then stops the previous
+// one. If c fails to start, the previous input keeps running.
+func (s *SwitchableCapture) Switch(c Capture, device string) error {
+	if err := c.Start(); err != nil {
+		closeCapture(c)
+		return err
+	}
+
+	s.mu.Lock()
+	old := s.current
+	s.current = c
+	s.device = device
+	s.gen++
+	streaming := s.streaming
+	s.mu.Unlock()
+
+	_ = old.Stop()
+	if !streaming {
+		closeCapture(old)
+	}
+	return nil
+}
+
+// Stream writes PCM16-LE from the current input to w, moving on to the next
+// input each time Switch replaces it, until the input fails or Stop is called.
+func (s *SwitchableCapture) Stream(w io.Writer) error {
+	for {
+		s.mu.Lock()
+		c, gen := s.current, s.gen
+		s.streaming = true
+		s.mu.Unlock()
+
+		err := c.Stream(NewResamplingWriter(w, c.SampleRate(), TargetSampleRate))
+
+		s.mu.Lock()
+		switched := s.gen != gen
+		stopped := s.stopped
+		s.streaming = false
+		s.mu.Unlock()
+		if !switched {
+			return err
+		}
+		// The stream ended because Switch stopped c; it is no longer in use.
+		closeCapture(c)
+		if stopped {
+			return err
+		}
+	}
+}
+
+// Reopen replaces an input that failed on its own, such as a USB headset
+// that was unplugged. The failed input is released first so its device can
+// be acquired again, then open is called for a fresh input on the same
+// device. Until that succeeds Stream returns ErrCaptureLost. Reopen must not
+// be called while Stream is running; after Stop it does nothing.
+func (s *SwitchableCapture) Reopen(open func(device string) (Capture, error)) error {
+	return s.ReopenDevice(func(device string) (Capture, string, error) {
+		c, err := open(device)
+		return c, device, err
+	})
+}
+
+// ReopenDevice is Reopen for an open that may move to another device, such
+// as a preferred headset plugged in while the old one was gone. open returns
+// the name of the device it opened alongside the input.
+func (s *SwitchableCapture) ReopenDevice(open func(device string) (Capture, string, error)) error {
+	s.mu.Lock()
+	if s.streaming {
+		s.mu.Unlock()
+		return errStillStreaming
+	}
+	if s.stopped {
+		s.mu.Unlock()
+		return nil
+	}
+	old, device := s.current, s.device
+	lost := &lostCapture{}
+	s.current = lost
+	s.gen++
+	s.mu.Unlock()
+
+	_ = old.Stop()
+	closeCapture(old)
+
+	c, device, err := open(device)
+	if err != nil {
+		return err
+	}
+	if err := c.Start(); err != nil {
+		closeCapture(c)
+		return err
+	}
+
+	s.mu.Lock()
+	// A Switch while the device was being reopened wins.
+	replaced := s.current != Capture(lost)
+	if !replaced {
+		s.current = c
+		s.device = device
+		s.gen++
+	}
+	s.mu.Unlock()
+	if replaced {
+		_ = c.Stop()
+		closeCapture(c)
+	}
+	return nil
+}
+
+// Release gives up the current input as though its device had been lost:
+// Stream returns ErrCaptureLost once it has finished with it, so that it is
+// reopened as a lost input would be. It is for inputs that will not survive
+// anyway, as over a system suspend. After Stop it does nothing.
+func (s *SwitchableCapture) Release() {
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		return
+	}
+	old := s.current
+	s.current = &lostCapture{}
+	s.gen++
+	streaming := s.streaming
+	s.mu.Unlock()
+
+	_ = old.Stop()
+	if !streaming {
+		closeCapture(old)
+	}
+}
+
+// lostCapture stands in for a released input until it is reopened.
+type lostCapture struct{}
+
+func (*lostCapture) Start() error           { return ErrCaptureLost }
+func (*lostCapture) Stop() error            { return nil }
+func (*lostCapture) Stream(io.Writer) error { return ErrCaptureLost }
+func (*lostCapture) SampleRate() int        { return TargetSampleRate }
+
+// closeCapture releases inputs that hold a device handle beyond Stop.
+func closeCapture(c Capture) {
+	if closer, ok := c.(io.Closer); ok {
+		_ = closer.Close()
+	}
+}
@@ -0,0 +1,230 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeCapture streams a constant sample at TargetSampleRate until stopped.
+type fakeCapture struct {
+	sample  int16
+	stop    chan struct{}
+	once    sync.Once
+	closed  chan struct{}
+	started bool
+}
+
+func newFakeCapture(sample int16) *fakeCapture {
+	return &fakeCapture{sample: sample, stop: make(chan struct{}), closed: make(chan struct{})}
+}
+
+func (f *fakeCapture) Start() error    { f.started = true; return nil }
+func (f *fakeCapture) Stop() error     { f.once.Do(func() { close(f.stop) }); return nil }
+func (f *fakeCapture) SampleRate() int { return TargetSampleRate }
+func (f *fakeCapture) Close() error    { close(f.closed); return nil }
+
+func (f *fakeCapture) Stream(w io.Writer) error {
+	buf := binary.LittleEndian.AppendUint16(nil, uint16(f.sample))
+	for {
+		select {
+		case <-f.stop:
+			return errors.New("stream stopped")
+		case <-time.After(time.Millisecond):
+		}
+		if _, err := w.Write(buf); err != nil {
+			return err
+		}
+	}
+}
+
+// syncBuffer collects samples written from the streaming goroutine.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) contains(sample int16) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return bytes.Contains(b.buf.Bytes(), binary.LittleEndian.AppendUint16(nil, uint16(sample)))
+}
+
+func TestSwitchableCaptureSwitchesWhileStreaming(t *testing.T) {
+	first, second := newFakeCapture(1), newFakeCapture(2)
+	sc := NewSwitchableCapture(first, "")
+	if err := sc.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	var out syncBuffer
+	done := make(chan error, 1)
+	go func() { done <- sc.Stream(&out) }()
+
+	waitFor(t, func() bool { return out.contains(1) })
+	if err := sc.Switch(second, "USB Mic"); err != nil {
+		t.Fatalf("Switch failed: %v", err)
+	}
+	if !second.started || sc.Device() != "USB Mic" {
+		t.Fatalf("expected the new device to be started and current, got %q", sc.Device())
+	}
+	waitFor(t, func() bool { return out.contains(2) })
+	select {
+	case <-first.closed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the replaced capture to be closed")
+	}
+
+	_ = sc.Stop()
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Fatal("expected Stream to return the stop error")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Stream did not return after Stop")
+	}
+}
+
+func TestSwitchableCaptureClosesIdleCapture(t *testing.T) {
+	first := newFakeCapture(1)
+	sc := NewSwitchableCapture(first, "")
+	if err := sc.Switch(newFakeCapture(2), "Other"); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-first.closed:
+	default:
+		t.Fatal("expected the replaced capture to be closed when nothing streams it")
+	}
+}
+
+func TestSwitchableCaptureReopensLostInput(t *testing.T) {
+	first := newFakeCapture(1)
+	sc := NewSwitchableCapture(first, "USB Mic")
+	if err := sc.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The device disappears: its stream fails without Stop or Switch.
+	_ = first.Stop()
+	if err := sc.Stream(io.Discard); err == nil {
+		t.Fatal("expected the failed input's error")
+	}
+
+	unplugged := errors.New("device not found")
+	var opened []string
+	if err := sc.Reopen(func(device string) (Capture, error) {
+		opened = append(opened, device)
+		return nil, unplugged
+	}); !errors.Is(err, unplugged) {
+		t.Fatalf("expected the open error, got %v", err)
+	}
+	select {
+	case <-first.closed:
+	default:
+		t.Fatal("expected the failed input released before reopening")
+	}
+	if err := sc.Stream(io.Discard); !errors.Is(err, ErrCaptureLost) {
+		t.Fatalf("expected ErrCaptureLost while the device is missing, got %v", err)
+	}
+
+	second := newFakeCapture(2)
+	if err := sc.Reopen(func(device string) (Capture, error) {
+		opened = append(opened, device)
+		return second, nil
+	}); err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	if !second.started || len(opened) != 2 || opened[1] != "USB Mic" {
+		t.Fatalf("expected the same device reopened and started, got %v", opened)
+	}
+
+	var out syncBuffer
+	done := make(chan error, 1)
+	go func() { done <- sc.Stream(&out) }()
+	waitFor(t, func() bool { return out.contains(2) })
+	if err := sc.Reopen(func(string) (Capture, error) { return newFakeCapture(3), nil }); err == nil {
+		t.Fatal("expected Reopen to refuse while streaming")
+	}
+	_ = sc.Stop()
+	<-done
+}
+
+func TestSwitchableCaptureReopenOnAnotherDevice(t *testing.T) {
+	first := newFakeCapture(1)
+	sc := NewSwitchableCapture(first, "MacBook Pro Microphone")
+	if err := sc.Start(); err != nil {
+		t.Fatal(err)
+	}
+	_ = first.Stop()
+
+	headset := newFakeCapture(2)
+	if err := sc.ReopenDevice(func(string) (Capture, string, error) {
+		return headset, "Jabra Evolve2 65", nil
+	}); err != nil {
+		t.Fatalf("ReopenDevice failed: %v", err)
+	}
+	if !headset.started || sc.Device() != "Jabra Evolve2 65" {
+		t.Fatalf("expected capture moved to the headset, got %q", sc.Device())
+	}
+	_ = sc.Stop()
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestSwitchableCaptureReleaseWhileStreaming(t *testing.T) {
+	first := newFakeCapture(1)
+	sc := NewSwitchableCapture(first, "USB Mic")
+	if err := sc.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	var out syncBuffer
+	done := make(chan error, 1)
+	go func() { done <- sc.Stream(&out) }()
+	waitFor(t, func() bool { return out.contains(1) })
+
+	sc.Release()
+	if err := <-done; !errors.Is(err, ErrCaptureLost) {
+		t.Fatalf("expected ErrCaptureLost after Release, got %v", err)
+	}
+	select {
+	case <-first.closed:
+	default:
+		t.Fatal("expected the released input closed")
+	}
+
+	second := newFakeCapture(2)
+	if err := sc.Reopen(func(device string) (Capture, error) {
+		if device != "USB Mic" {
+			t.Errorf("expected the same device reopened, got %q", device)
+		}
+		return second, nil
+	}); err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	if !second.started {
+		t.Fatal("expected the reopened input started")
+	}
+	_ = sc.Stop()
+}
@@ -0,0 +1,91 @@
+package audio
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// fileSyncer syncs raw chunks to disk, and closes finished ones, on its own
+// goroutine, so a slow disk stalls neither the capture callback nor anyone
+// waiting on the recorder's lock.
+type fileSyncer struct {
+	mu      sync.Mutex
+	jobs    []syncJob
+	running bool
+	// idle is closed once the queue empties.
+	idle chan struct{}
+	// err is the first failure not yet reported.
+	err error
+}
+
+type syncJob struct {
+	f     *os.File
+	close bool
+}
+
+// push queues f to be synced and, when close is set, closed.
+func (s *fileSyncer) push(f *os.File, close bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs = append(s.jobs, syncJob{f: f, close: close})
+	if s.running {
+		return
+	}
+	s.running = true
+	s.idle = make(chan struct{})
+	go s.run()
+}
+
+func (s *fileSyncer) run() {
+	for {
+		s.mu.Lock()
+		if len(s.jobs) == 0 {
+			s.running = false
+			close(s.idle)
+			s.mu.Unlock()
+			return
+		}
+		job := s.jobs[0]
+		s.jobs = s.jobs[1:]
+		s.mu.Unlock()
+
+		err := job.f.Sync()
+		if err != nil {
+			err = fmt.Errorf("sync raw pcm chunk: %w", err)
+		}
+		if job.close {
+			if cerr := job.f.Close(); cerr != nil && err == nil {
+				err = fmt.Errorf("close raw pcm chunk: %w", cerr)
+			}
+		}
+		if err != nil {
+			s.mu.Lock()
+			if s.err == nil {
+				s.err = err
+			}
+			s.mu.Unlock()
+		}
+	}
+}
+
+// takeErr returns and clears the first failure since it was last called.
+func (s *fileSyncer) takeErr() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := s.err
+	s.err = nil
+	return err
+}
+
+// flush waits for the queued files to be synced and returns any failure
+// not yet reported.
+func (s *fileSyncer) flush() error {
+	s.mu.Lock()
+	idle, running := s.idle, s.running
+	s.mu.Unlock()
+	if running {
+		<-idle
+	}
+	return s.takeErr()
+}
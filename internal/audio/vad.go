@@ -0,0 +1,173 @@
+package audio
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// vadFrame is the stretch of audio each speech decision covers.
+const vadFrame = 20 * time.Millisecond
+
+// VADOptions tune a VADGate.
+type VADOptions struct {
+	// ThresholdDB is the frame loudness, in dBFS, above which a frame counts
+	// as speech.
+	ThresholdDB float64
+	// PreRoll is how much audio from before the first loud frame is sent
+	// when the gate opens, so the onset of speech isn't clipped.
+	PreRoll time.Duration
+	// Hangover keeps the gate open this long after the last loud frame, so
+	// the transcriber hears the trailing silence it needs to finalize.
+	Hangover time.Duration
+}
+
+// VADGate is an energy-based voice-activity gate: it passes PCM16-LE on to
+// its destination only around speech and drops silence. The transcriber's
+// clock then only advances while audio flows, so the gate records where it
+// skipped audio and CaptureTime maps the transcriber's timestamps back onto
+// the capture timeline. Keeping an idle connection alive is up to the
+// destination. Writes are expected to hold whole samples.
+type VADGate struct {
+	dst        io.Writer
+	frameBytes int
+	frameRate  float64
+	threshold  atomic.Uint64 // math.Float64bits of the RMS threshold
+	preFrames  int
+	hangFrames int
+
+	pending  []byte
+	preRoll  []gatedFrame
+	open     bool
+	quiet    int
+	captured int64
+
+	mu       sync.Mutex
+	streamed int64
+	offset   int64
+	skips    []vadSkip
+}
+
+type gatedFrame struct {
+	index int64
+	data  []byte
+}
+
+// vadSkip records that from stream frame at onward, stream frames lag the
+// capture by offset frames.
+type vadSkip struct {
+	at, offset int64
+}
+
+// NewVADGate gates audio at sampleRate with the given number of interleaved
+// channels.
+func NewVADGate(dst io.Writer, sampleRate, channels int, opts VADOptions) *VADGate {
+	frameSamples := max(int(int64(sampleRate)*int64(max(channels, 1))*int64(vadFrame)/int64(time.Second)), 1)
+	g := &VADGate{
+		dst:        dst,
+		frameBytes: frameSamples * 2,
+		frameRate:  float64(time.Second) / float64(vadFrame),
+		preFrames:  int(opts.PreRoll / vadFrame),
+		hangFrames: int(opts.Hangover / vadFrame),
+	}
+	g.SetThreshold(opts.ThresholdDB)
+	return g
+}
+
+// SetThreshold changes the speech threshold, in dBFS, while audio flows.
+func (g *VADGate) SetThreshold(db float64) {
+	g.threshold.Store(math.Float64bits(math.Pow(10, db/20)))
+}
+
+func (g *VADGate) Write(p []byte) (int, error) {
+	g.pending = append(g.pending, p...)
+	for len(g.pending) >= g.frameBytes {
+		frame := g.pending[:g.frameBytes]
+		if err := g.process(frame); err != nil {
+			return 0, err
+		}
+		g.pending = g.pending[g.frameBytes:]
+	}
+	g.pending = append(g.pending[:0:0], g.pending...)
+	return len(p), nil
+}
+
+func (g *VADGate) process(frame []byte) error {
+	index := g.captured
+	g.captured++
+
+	if frameRMS(frame) >= math.Float64frombits(g.threshold.Load()) {
+		g.quiet = 0
+		if !g.open {
+			g.open = true
+			for _, f := range g.preRoll {
+				if err := g.send(f.index, f.data); err != nil {
+					return err
+				}
+			}
+			g.preRoll = g.preRoll[:0]
+		}
+		return g.send(index, frame)
+	}
+
+	if g.open {
+		g.quiet++
+		if g.quiet <= g.hangFrames {
+			return g.send(index, frame)
+		}
+		g.open = false
+	}
+
+	if g.preFrames > 0 {
+		if len(g.preRoll) == g.preFrames {
+			g.preRoll = append(g.preRoll[:0], g.preRoll[1:]...)
+		}
+		g.preRoll = append(g.preRoll, gatedFrame{index: index, data: append([]byte(nil), frame...)})
+	}
+	return nil
+}
+
+// send streams one captured frame, noting where the stream jumps ahead of
+// the capture.
+func (g *VADGate) send(index int64, frame []byte) error {
+	g.mu.Lock()
+	if offset := index - g.streamed; offset != g.offset {
+		g.offset = offset
+		g.skips = append(g.skips, vadSkip{at: g.streamed, offset: offset})
+	}
+	g.streamed++
+	g.mu.Unlock()
+
+	_, err := g.dst.Write(frame)
+	return err
+}
+
+// CaptureTime maps a time in seconds on the gated stream, as reported by the
+// transcriber, to seconds of captured audio.
+func (g *VADGate) CaptureTime(t float64) float64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	frame := int64(t * g.frameRate)
+	i := sort.Search(len(g.skips), func(i int) bool { return g.skips[i].at > frame })
+	if i == 0 {
+		return t
+	}
+	return t + float64(g.skips[i-1].offset)/g.frameRate
+}
+
+func frameRMS(frame []byte) float64 {
+	n := len(frame) / 2
+	if n == 0 {
+		return 0
+	}
+	var sumSq float64
+	for i := 0; i+1 < len(frame); i += 2 {
+		s := float64(int16(binary.LittleEndian.Uint16(frame[i:])))
+		sumSq += s * s
+	}
+	return math.Sqrt(sumSq/float64(n)) / math.MaxInt16
+}
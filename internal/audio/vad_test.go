@@ -0,0 +1,59 @@
+package audio
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestVADGateStreamsSpeechWithPreRollAndHangover(t *testing.T) {
+	// 20 samples per frame at 1 kHz mono.
+	frame := func(v int16) []int16 {
+		f := make([]int16, 20)
+		for i := range f {
+			f[i] = v
+		}
+		return f
+	}
+	const quiet, loud = 10, 8000
+	pattern := []int16{quiet, quiet, quiet, quiet, quiet, loud, loud, quiet, quiet, quiet, quiet, quiet, quiet, loud}
+	var samples []int16
+	for _, v := range pattern {
+		samples = append(samples, frame(v)...)
+	}
+
+	var dst bytes.Buffer
+	g := NewVADGate(&dst, 1000, 1, VADOptions{ThresholdDB: -30, PreRoll: 40 * time.Millisecond, Hangover: 40 * time.Millisecond})
+
+	in := pcmBytes(samples)
+	// Writes that don't line up with frames are buffered.
+	for len(in) > 0 {
+		n := min(len(in), 30)
+		if _, err := g.Write(in[:n]); err != nil {
+			t.Fatal(err)
+		}
+		in = in[n:]
+	}
+
+	// Frames 3-4 as pre-roll, 5-6 speech, 7-8 hangover, then 11-12 as pre-roll
+	// before the speech in frame 13.
+	var want []int16
+	for _, i := range []int{3, 4, 5, 6, 7, 8, 11, 12, 13} {
+		want = append(want, frame(pattern[i])...)
+	}
+	if !bytes.Equal(dst.Bytes(), pcmBytes(want)) {
+		t.Fatalf("unexpected gated audio: got %d bytes, want %d", dst.Len(), len(want)*2)
+	}
+
+	for _, tc := range []struct{ stream, capture float64 }{
+		{0, 0.06},    // first streamed frame is captured frame 3
+		{0.11, 0.17}, // still in the first stretch
+		{0.12, 0.22}, // stream frame 6 is captured frame 11
+		{0.17, 0.27},
+	} {
+		if got := g.CaptureTime(tc.stream); math.Abs(got-tc.capture) > 1e-9 {
+			t.Errorf("CaptureTime(%v) = %v, want %v", tc.stream, got, tc.capture)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package audio
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// waveformPeaksPerSecond is the resolution of a session's waveform: fine
+// enough to see words, coarse enough that an hour is a few hundred KB.
+const waveformPeaksPerSecond = 10
+
+// Waveform is the amplitude envelope of a recording, for drawing it. Each
+// peak is the loudest sample of its slice of the recording, as a fraction of
+// full scale.
+type Waveform struct {
+	// SecondsPerPeak is how much audio each peak covers.
+	SecondsPerPeak float64 `json:"seconds_per_peak"`
+	// Duration is the length of the recording in seconds.
+	Duration float64   `json:"duration"`
+	Peaks    []float64 `json:"peaks"`
+}
+
+// WaveformPath is where the waveform of the audio file at audioPath is kept:
+// next to it, as <name>.peaks.json.
+func WaveformPath(audioPath string) string {
+	return strings.TrimSuffix(audioPath, filepath.Ext(audioPath)) + ".peaks.json"
+}
+
+// ComputeWaveform reads PCM16-LE at sampleRate, with the given number of
+// interleaved channels, and returns its envelope.
+func ComputeWaveform(pcm io.Reader, sampleRate, channels int) (Waveform, error) {
+	channels = max(channels, 1)
+	perPeak := max(sampleRate/waveformPeaksPerSecond, 1) * channels
+	wf := Waveform{SecondsPerPeak: float64(perPeak/channels) / float64(sampleRate), Peaks: []float64{}}
+
+	in := bufio.NewReader(pcm)
+	var sample [2]byte
+	samples, inPeak := 0, 0
+	peak := 0.0
+	for {
+		if _, err := io.ReadFull(in, sample[:]); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				break
+			}
+			return Waveform{}, fmt.Errorf("read pcm: %w", err)
+		}
+		s := math.Abs(float64(int16(binary.LittleEndian.Uint16(sample[:])))) / math.MaxInt16
+		peak = max(peak, s)
+		samples++
+		inPeak++
+		if inPeak == perPeak {
+			wf.Peaks = append(wf.Peaks, roundPeak(peak))
+			inPeak, peak = 0, 0
+		}
+	}
+	if inPeak > 0 {
+		wf.Peaks = append(wf.Peaks, roundPeak(peak))
+	}
+	wf.Duration = float64(samples/channels) / float64(sampleRate)
+	return wf, nil
+}
+
+// roundPeak keeps three decimals, which is all a drawing needs and keeps
+// the JSON small.
+func roundPeak(p float64) float64 {
+	return math.Round(min(p, 1)*1000) / 1000
+}
+
+// WriteWaveform computes the envelope of pcm and writes it as JSON to path.
+func WriteWaveform(path string, pcm io.Reader, sampleRate, channels int) error {
+	wf, err := ComputeWaveform(pcm, sampleRate, channels)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(wf)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("write waveform: %w", err)
+	}
+	return nil
+}
+
+// KeepWaveform writes the waveform of mono pcm at sampleRate next to the
+// audio file at audioPath. A failure is only logged: without a waveform the
+// recording still plays, just undrawn.
+func KeepWaveform(audioPath string, pcm []byte, sampleRate int) {
+	if err := WriteWaveform(WaveformPath(audioPath), bytes.NewReader(pcm), sampleRate, 1); err != nil {
+		slog.Warn("writing waveform failed", "file", audioPath, "error", err)
+	}
+}
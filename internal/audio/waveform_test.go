@@ -0,0 +1,67 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestComputeWaveform(t *testing.T) {
+	// Half a second of silence, then a second of a -6 dBFS peak tone and a
+	// trailing partial slice.
+	samples := append(make([]int16, TargetSampleRate/2), tone(440, -9, 1.05)...)
+	wf, err := ComputeWaveform(bytes.NewReader(pcmBytes(samples)), TargetSampleRate, 1)
+	if err != nil {
+		t.Fatalf("ComputeWaveform failed: %v", err)
+	}
+	if wf.SecondsPerPeak != 0.1 || math.Abs(wf.Duration-1.55) > 1e-9 {
+		t.Fatalf("unexpected resolution %v or duration %v", wf.SecondsPerPeak, wf.Duration)
+	}
+	if len(wf.Peaks) != 16 {
+		t.Fatalf("expected 16 peaks, got %d", len(wf.Peaks))
+	}
+	for i, p := range wf.Peaks {
+		if i < 5 && p != 0 {
+			t.Fatalf("expected silence at peak %d, got %v", i, p)
+		}
+		if i >= 5 && math.Abs(p-0.5) > 0.01 {
+			t.Fatalf("expected the tone's peak at %d, got %v", i, p)
+		}
+	}
+}
+
+func TestRecorderWritesWaveform(t *testing.T) {
+	t.Setenv("PATH", "")
+	dir := t.TempDir()
+	recorder := NewRecorder(dir)
+	recorder.SetWAVOnly(true)
+
+	if err := recorder.StartSession("s1"); err != nil {
+		t.Fatalf("StartSession failed: %v", err)
+	}
+	if _, err := recorder.Writer(bytes.NewBuffer(nil)).Write(pcmBytes(tone(440, -9, 2))); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	path, err := recorder.EndSession()
+	if err != nil {
+		t.Fatalf("EndSession failed: %v", err)
+	}
+	if got := WaveformPath(path); got != filepath.Join(dir, "s1.peaks.json") {
+		t.Fatalf("unexpected waveform path %q", got)
+	}
+
+	data, err := os.ReadFile(WaveformPath(path))
+	if err != nil {
+		t.Fatalf("read waveform: %v", err)
+	}
+	var wf Waveform
+	if err := json.Unmarshal(data, &wf); err != nil {
+		t.Fatalf("decode waveform: %v", err)
+	}
+	if wf.Duration != 2 || len(wf.Peaks) != 20 {
+		t.Fatalf("expected 20 peaks over 2s, got %d over %v", len(wf.Peaks), wf.Duration)
+	}
+}
@@ -0,0 +1,400 @@
+// Package briefing prepares pre-meeting briefs for upcoming calendar events
+// from related past sessions, and announces each one shortly before its
+// meeting starts.
+package briefing
+
+import (
+	"cmp"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+	"github.com/sjawhar/ghost-wispr/internal/transcribe"
+)
+
+// Store is the storage a Service reads past sessions from and keeps
+// briefings in.
+type Store interface {
+	GetDates() ([]string, error)
+	GetSessionsByDate(date string) ([]storage.Session, error)
+	GetSegments(sessionID string) ([]transcribe.Segment, error)
+	GetActionItems(sessionID string) ([]storage.ActionItem, error)
+	CreateBriefing(b storage.Briefing) error
+	ListBriefings() ([]storage.Briefing, error)
+	MarkBriefingDelivered(id string, at time.Time) error
+}
+
+// Writer turns notes from related sessions into a brief.
+type Writer interface {
+	WriteBrief(ctx context.Context, meeting, history string) (string, error)
+}
+
+// Notifier announces a brief that is due.
+type Notifier interface {
+	BroadcastBriefingReady(b storage.Briefing)
+}
+
+// Event is an upcoming calendar event to brief. EndsAt is zero when the
+// event's end isn't known.
+type Event struct {
+	Title     string
+	Attendees []string
+	StartsAt  time.Time
+	EndsAt    time.Time
+}
+
+// Options configure a Service. Zero values take the defaults below.
+type Options struct {
+	// Lead is how long before the meeting the brief is announced.
+	Lead time.Duration
+	// Lookback limits related sessions to those started this recently.
+	Lookback time.Duration
+	// MaxSessions caps how many related sessions feed a brief.
+	MaxSessions int
+}
+
+const (
+	DefaultLead        = 10 * time.Minute
+	DefaultLookback    = 30 * 24 * time.Hour
+	DefaultMaxSessions = 5
+)
+
+// Service generates, stores and schedules briefings.
+type Service struct {
+	store    Store
+	writer   Writer
+	notifier Notifier
+	opts     Options
+
+	// Swappable for tests.
+	now       func() time.Time
+	afterFunc func(d time.Duration, f func()) *time.Timer
+
+	mu      sync.Mutex
+	timers  map[string]*time.Timer
+	stopped bool
+}
+
+// New returns a Service. writer may be nil, in which case a brief is the
+// plain notes of the related sessions.
+func New(store Store, writer Writer, notifier Notifier, opts Options) *Service {
+	if opts.Lead <= 0 {
+		opts.Lead = DefaultLead
+	}
+	if opts.Lookback <= 0 {
+		opts.Lookback = DefaultLookback
+	}
+	if opts.MaxSessions <= 0 {
+		opts.MaxSessions = DefaultMaxSessions
+	}
+	return &Service{
+		store:     store,
+		writer:    writer,
+		notifier:  notifier,
+		opts:      opts,
+		now:       time.Now,
+		afterFunc: time.AfterFunc,
+		timers:    make(map[string]*time.Timer),
+	}
+}
+
+// Create prepares a brief for ev, stores it and schedules its announcement.
+func (s *Service) Create(ctx context.Context, ev Event) (storage.Briefing, error) {
+	ev.Title = strings.TrimSpace(ev.Title)
+	if ev.Title == "" && len(ev.Attendees) == 0 {
+		return storage.Briefing{}, errors.New("briefing needs a title or attendees")
+	}
+	if ev.StartsAt.IsZero() {
+		return storage.Briefing{}, errors.New("briefing needs a start time")
+	}
+	if !ev.EndsAt.IsZero() && !ev.EndsAt.After(ev.StartsAt) {
+		return storage.Briefing{}, errors.New("briefing must end after it starts")
+	}
+
+	related, err := s.Related(ev)
+	if err != nil {
+		return storage.Briefing{}, err
+	}
+
+	brief := "No related past sessions found."
+	ids := make([]string, 0, len(related))
+	if len(related) > 0 {
+		history, err := s.history(related)
+		if err != nil {
+			return storage.Briefing{}, err
+		}
+		brief = history
+		if s.writer != nil {
+			if brief, err = s.writer.WriteBrief(ctx, describe(ev), history); err != nil {
+				return storage.Briefing{}, err
+			}
+		}
+		for _, sess := range related {
+			ids = append(ids, sess.ID)
+		}
+	}
+
+	b := storage.Briefing{
+		ID:         newID(),
+		Title:      ev.Title,
+		Attendees:  ev.Attendees,
+		StartsAt:   ev.StartsAt.UTC(),
+		Brief:      brief,
+		SessionIDs: ids,
+		CreatedAt:  s.now().UTC(),
+	}
+	if !ev.EndsAt.IsZero() {
+		ends := ev.EndsAt.UTC()
+		b.EndsAt = &ends
+	}
+	if err := s.store.CreateBriefing(b); err != nil {
+		return storage.Briefing{}, err
+	}
+	s.schedule(b)
+	return b, nil
+}
+
+// Resume schedules the stored briefings that have not been announced yet and
+// whose meeting is still ahead, e.g. after a restart.
+func (s *Service) Resume() error {
+	briefings, err := s.store.ListBriefings()
+	if err != nil {
+		return err
+	}
+	now := s.now()
+	for _, b := range briefings {
+		if b.DeliveredAt == nil && b.StartsAt.After(now) {
+			s.schedule(b)
+		}
+	}
+	return nil
+}
+
+// Stop cancels every pending announcement.
+func (s *Service) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stopped = true
+	for id, t := range s.timers {
+		t.Stop()
+		delete(s.timers, id)
+	}
+}
+
+func (s *Service) schedule(b storage.Briefing) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped {
+		return
+	}
+	delay := max(b.StartsAt.Add(-s.opts.Lead).Sub(s.now()), 0)
+	s.timers[b.ID] = s.afterFunc(delay, func() { s.deliver(b) })
+}
+
+func (s *Service) deliver(b storage.Briefing) {
+	s.mu.Lock()
+	delete(s.timers, b.ID)
+	stopped := s.stopped
+	s.mu.Unlock()
+	if stopped {
+		return
+	}
+
+	at := s.now().UTC()
+	if err := s.store.MarkBriefingDelivered(b.ID, at); err != nil {
+		log.Printf("mark briefing %s delivered: %v", b.ID, err)
+	}
+	b.DeliveredAt = &at
+	if s.notifier != nil {
+		s.notifier.BroadcastBriefingReady(b)
+	}
+}
+
+// Related returns the finished sessions within the lookback window that
+// mention ev's attendees or title keywords, best match first.
+func (s *Service) Related(ev Event) ([]storage.Session, error) {
+	terms := eventTerms(ev)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	dates, err := s.store.GetDates()
+	if err != nil {
+		return nil, err
+	}
+	cutoff := s.now().Add(-s.opts.Lookback)
+	// Dates are only a coarse filter; a day's slack covers stores that bucket
+	// by local date.
+	lastDate := cutoff.UTC().AddDate(0, 0, -1).Format("2006-01-02")
+
+	type match struct {
+		sess  storage.Session
+		score int
+	}
+	var matches []match
+	for _, date := range dates {
+		if date < lastDate {
+			break
+		}
+		sessions, err := s.store.GetSessionsByDate(date)
+		if err != nil {
+			return nil, err
+		}
+		for _, sess := range sessions {
+			if sess.EndedAt == nil || sess.StartedAt.Before(cutoff) {
+				continue
+			}
+			segments, err := s.store.GetSegments(sess.ID)
+			if err != nil {
+				return nil, fmt.Errorf("load segments for session %s: %w", sess.ID, err)
+			}
+			if score := terms.score(words(sess.Summary, segments)); score > 0 {
+				matches = append(matches, match{sess: sess, score: score})
+			}
+		}
+	}
+
+	slices.SortStableFunc(matches, func(a, b match) int {
+		if c := cmp.Compare(b.score, a.score); c != 0 {
+			return c
+		}
+		return b.sess.StartedAt.Compare(a.sess.StartedAt)
+	})
+	related := make([]storage.Session, 0, min(len(matches), s.opts.MaxSessions))
+	for _, m := range matches[:min(len(matches), s.opts.MaxSessions)] {
+		related = append(related, m.sess)
+	}
+	return related, nil
+}
+
+// history formats the notes of related sessions for the brief writer.
+func (s *Service) history(sessions []storage.Session) (string, error) {
+	var b strings.Builder
+	for _, sess := range sessions {
+		fmt.Fprintf(&b, "## %s (session %s)\n\n", sess.StartedAt.Local().Format("Mon Jan 2 2006 15:04"), sess.ID)
+		if summary := strings.TrimSpace(sess.Summary); summary != "" {
+			b.WriteString(summary)
+			b.WriteString("\n\n")
+		}
+		items, err := s.store.GetActionItems(sess.ID)
+		if err != nil {
+			return "", fmt.Errorf("load action items for session %s: %w", sess.ID, err)
+		}
+		open := false
+		for _, item := range items {
+			if item.Done {
+				continue
+			}
+			if !open {
+				b.WriteString("Open action items:\n")
+				open = true
+			}
+			if item.Owner != "" {
+				fmt.Fprintf(&b, "- %s (%s)\n", item.Text, item.Owner)
+			} else {
+				fmt.Fprintf(&b, "- %s\n", item.Text)
+			}
+		}
+		if open {
+			b.WriteString("\n")
+		}
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+func describe(ev Event) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Title: %s\n", ev.Title)
+	if len(ev.Attendees) > 0 {
+		fmt.Fprintf(&b, "Attendees: %s\n", strings.Join(ev.Attendees, ", "))
+	}
+	fmt.Fprintf(&b, "Starts: %s", ev.StartsAt.Local().Format("Mon Jan 2 2006 15:04"))
+	return b.String()
+}
+
+// terms are the entities a past session is matched on. Each attendee counts
+// double a title keyword, and matches on any part of their name, so "Alice"
+// in a transcript finds "Alice Smith" or alice.smith@example.com.
+type terms []term
+
+type term struct {
+	words  []string
+	weight int
+}
+
+func (t terms) score(text map[string]bool) int {
+	score := 0
+	for _, term := range t {
+		for _, w := range term.words {
+			if text[w] {
+				score += term.weight
+				break
+			}
+		}
+	}
+	return score
+}
+
+func eventTerms(ev Event) terms {
+	var t terms
+	for _, attendee := range ev.Attendees {
+		name, _, _ := strings.Cut(attendee, "@")
+		var parts []string
+		for _, w := range splitWords(name) {
+			if len([]rune(w)) >= 3 {
+				parts = append(parts, w)
+			}
+		}
+		if len(parts) > 0 {
+			t = append(t, term{words: parts, weight: 2})
+		}
+	}
+	for _, w := range splitWords(ev.Title) {
+		if len([]rune(w)) >= 4 && !stopwords[w] {
+			t = append(t, term{words: []string{w}, weight: 1})
+		}
+	}
+	return t
+}
+
+func words(summary string, segments []transcribe.Segment) map[string]bool {
+	set := make(map[string]bool)
+	for _, w := range splitWords(summary) {
+		set[w] = true
+	}
+	for _, seg := range segments {
+		for _, w := range splitWords(seg.Text) {
+			set[w] = true
+		}
+	}
+	return set
+}
+
+func splitWords(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// stopwords are common title words that say nothing about a meeting's topic.
+var stopwords = map[string]bool{
+	"meeting": true, "sync": true, "call": true, "weekly": true, "daily": true,
+	"monthly": true, "chat": true, "with": true, "and": true, "the": true,
+	"review": true, "update": true, "updates": true, "check": true, "catch": true,
+	"session": true, "discussion": true, "about": true, "from": true, "team": true,
+}
+
+func newID() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return "brief-" + hex.EncodeToString(b[:])
+}
@@ -0,0 +1,202 @@
+package briefing
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+	"github.com/sjawhar/ghost-wispr/internal/transcribe"
+)
+
+var now = time.Date(2026, 3, 20, 9, 0, 0, 0, time.UTC)
+
+func addSession(t *testing.T, store *storage.MemoryStore, id string, start time.Time, summary, transcript string) {
+	t.Helper()
+	if err := store.CreateSession(id, start); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.AppendSegment(id, transcribe.Segment{Text: transcript}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.EndSession(id, start.Add(30*time.Minute), ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.UpdateSummary(id, summary, "completed", "default"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type writerMock struct {
+	meeting, history string
+}
+
+func (w *writerMock) WriteBrief(_ context.Context, meeting, history string) (string, error) {
+	w.meeting, w.history = meeting, history
+	return "the brief", nil
+}
+
+type notifierMock struct {
+	mu        sync.Mutex
+	delivered []storage.Briefing
+}
+
+func (n *notifierMock) BroadcastBriefingReady(b storage.Briefing) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.delivered = append(n.delivered, b)
+}
+
+// fakeTimers records scheduled announcements instead of waiting for them.
+type fakeTimers struct {
+	delays []time.Duration
+	funcs  []func()
+}
+
+func (f *fakeTimers) afterFunc(d time.Duration, fn func()) *time.Timer {
+	f.delays = append(f.delays, d)
+	f.funcs = append(f.funcs, fn)
+	return time.NewTimer(time.Hour)
+}
+
+func newTestService(store Store, writer Writer, notifier Notifier) (*Service, *fakeTimers) {
+	timers := &fakeTimers{}
+	s := New(store, writer, notifier, Options{})
+	s.now = func() time.Time { return now }
+	s.afterFunc = timers.afterFunc
+	return s, timers
+}
+
+func TestCreateBriefsFromRelatedSessions(t *testing.T) {
+	store := storage.NewMemoryStore()
+	addSession(t, store, "pricing", now.AddDate(0, 0, -7), "Agreed to raise enterprise pricing.", "Priya wants the pricing page updated")
+	addSession(t, store, "standup", now.AddDate(0, 0, -2), "Standup notes.", "nothing about that topic")
+	addSession(t, store, "priya", now.AddDate(0, 0, -1), "Hiring plan.", "Priya asked about the new hire")
+	addSession(t, store, "old", now.AddDate(0, 0, -60), "Pricing history.", "Priya on pricing")
+	if err := store.ReplaceActionItems("pricing", []storage.ActionItem{
+		{Text: "Update the pricing page", Owner: "Priya"},
+		{Text: "Email finance", Done: true},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	writer := &writerMock{}
+	s, timers := newTestService(store, writer, nil)
+	b, err := s.Create(context.Background(), Event{
+		Title:     "Pricing sync",
+		Attendees: []string{"priya.patel@example.com"},
+		StartsAt:  now.Add(2 * time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	// Both the attendee and the topic match the pricing session, so it ranks
+	// above the one that only mentions Priya; the standup and the session
+	// outside the lookback window are left out.
+	if len(b.SessionIDs) != 2 || b.SessionIDs[0] != "pricing" || b.SessionIDs[1] != "priya" {
+		t.Fatalf("unexpected related sessions %v", b.SessionIDs)
+	}
+	if b.Brief != "the brief" {
+		t.Fatalf("expected the writer's brief, got %q", b.Brief)
+	}
+	if !strings.Contains(writer.meeting, "Pricing sync") {
+		t.Fatalf("meeting description missing title: %q", writer.meeting)
+	}
+	if !strings.Contains(writer.history, "Update the pricing page (Priya)") || strings.Contains(writer.history, "Email finance") {
+		t.Fatalf("history should list only open action items: %q", writer.history)
+	}
+
+	stored, err := store.GetBriefing(b.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Brief != "the brief" {
+		t.Fatalf("unexpected stored briefing %#v", stored)
+	}
+	if len(timers.delays) != 1 || timers.delays[0] != 2*time.Hour-DefaultLead {
+		t.Fatalf("expected announcement %v before the meeting, got %v", DefaultLead, timers.delays)
+	}
+}
+
+func TestCreateWithoutWriterUsesNotes(t *testing.T) {
+	store := storage.NewMemoryStore()
+	addSession(t, store, "launch", now.AddDate(0, 0, -3), "Launch slipped a week.", "the launch date moved")
+
+	s, _ := newTestService(store, nil, nil)
+	b, err := s.Create(context.Background(), Event{Title: "Launch planning", StartsAt: now.Add(time.Hour)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.Brief, "Launch slipped a week.") {
+		t.Fatalf("expected the session notes as the brief, got %q", b.Brief)
+	}
+
+	b, err = s.Create(context.Background(), Event{Title: "Unrelated topic", StartsAt: now.Add(time.Hour)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b.SessionIDs) != 0 || !strings.Contains(b.Brief, "No related") {
+		t.Fatalf("expected an empty brief, got %#v", b)
+	}
+}
+
+func TestCreateValidatesEvent(t *testing.T) {
+	s, _ := newTestService(storage.NewMemoryStore(), nil, nil)
+	if _, err := s.Create(context.Background(), Event{StartsAt: now}); err == nil {
+		t.Fatal("expected an error without title or attendees")
+	}
+	if _, err := s.Create(context.Background(), Event{Title: "Sync"}); err == nil {
+		t.Fatal("expected an error without a start time")
+	}
+}
+
+func TestDeliverAndResume(t *testing.T) {
+	store := storage.NewMemoryStore()
+	notifier := &notifierMock{}
+	s, timers := newTestService(store, nil, notifier)
+
+	b, err := s.Create(context.Background(), Event{Title: "Roadmap", StartsAt: now.Add(5 * time.Minute)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(timers.delays) != 1 || timers.delays[0] != 0 {
+		t.Fatalf("a meeting inside the lead time should be announced now, got %v", timers.delays)
+	}
+	timers.funcs[0]()
+
+	if len(notifier.delivered) != 1 || notifier.delivered[0].ID != b.ID || notifier.delivered[0].DeliveredAt == nil {
+		t.Fatalf("unexpected deliveries %#v", notifier.delivered)
+	}
+	stored, err := store.GetBriefing(b.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.DeliveredAt == nil {
+		t.Fatal("expected the briefing to be marked delivered")
+	}
+
+	pending := storage.Briefing{ID: "pending", Title: "Later", StartsAt: now.Add(time.Hour), CreatedAt: now}
+	past := storage.Briefing{ID: "past", Title: "Earlier", StartsAt: now.Add(-time.Hour), CreatedAt: now}
+	for _, b := range []storage.Briefing{pending, past} {
+		if err := store.CreateBriefing(b); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	resumed, timers := newTestService(store, nil, notifier)
+	if err := resumed.Resume(); err != nil {
+		t.Fatal(err)
+	}
+	if len(timers.delays) != 1 || timers.delays[0] != time.Hour-DefaultLead {
+		t.Fatalf("expected only the pending briefing rescheduled, got %v", timers.delays)
+	}
+
+	resumed.Stop()
+	timers.funcs[0]()
+	if len(notifier.delivered) != 1 {
+		t.Fatalf("expected no delivery after Stop, got %d", len(notifier.delivered))
+	}
+}
@@ -0,0 +1,224 @@
+// Package bundle encrypts session exports with a password so they can be
+// shared over untrusted channels.
+//
+// A bundle is a header followed by the plaintext split into 64 KiB chunks,
+// each sealed with AES-256-GCM. The key is derived from the password with
+// PBKDF2-SHA256 over a random salt. Chunk nonces are a counter plus a flag
+// marking the last chunk, so reordered, dropped or truncated chunks fail to
+// decrypt, and the header is authenticated with every chunk.
+package bundle
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	magic      = "ghost-wispr-bundle v1\n"
+	saltSize   = 16
+	headerSize = len(magic) + saltSize + 4
+	chunkSize  = 64 << 10
+	tagSize    = 16
+	// maxIterations bounds the work factor accepted from a bundle header.
+	maxIterations = 10_000_000
+)
+
+// iterations is the PBKDF2 work factor for new bundles.
+var iterations uint32 = 600_000
+
+// Extension is the file extension for encrypted bundles.
+const Extension = ".gwb"
+
+var (
+	// ErrNotBundle means the input does not start with a bundle header.
+	ErrNotBundle = errors.New("not a ghost-wispr bundle")
+	// ErrDecrypt means a chunk failed authentication: the password is wrong
+	// or the bundle was modified or truncated.
+	ErrDecrypt = errors.New("wrong password or corrupted bundle")
+)
+
+// NewWriter returns a writer that encrypts everything written to it into w.
+// Close must be called to write the final chunk; it does not close w.
+func NewWriter(w io.Writer, password string) (io.WriteCloser, error) {
+	if password == "" {
+		return nil, errors.New("bundle password is empty")
+	}
+	header := make([]byte, headerSize)
+	copy(header, magic)
+	if _, err := rand.Read(header[len(magic) : len(magic)+saltSize]); err != nil {
+		return nil, fmt.Errorf("generate salt: %w", err)
+	}
+	binary.BigEndian.PutUint32(header[len(magic)+saltSize:], iterations)
+
+	aead, err := newAEAD(password, header)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(header); err != nil {
+		return nil, fmt.Errorf("write bundle header: %w", err)
+	}
+	return &writer{w: w, aead: aead, header: header, buf: make([]byte, 0, chunkSize)}, nil
+}
+
+type writer struct {
+	w      io.Writer
+	aead   cipher.AEAD
+	header []byte
+	buf    []byte
+	nonce  chunkNonce
+	closed bool
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, errors.New("write to closed bundle")
+	}
+	n := 0
+	for len(p) > 0 {
+		// A full buffer is only sealed once more data arrives, so the last
+		// chunk can always be marked final on Close.
+		if len(w.buf) == chunkSize {
+			if err := w.seal(false); err != nil {
+				return n, err
+			}
+		}
+		c := copy(w.buf[len(w.buf):chunkSize], p)
+		w.buf = w.buf[:len(w.buf)+c]
+		p = p[c:]
+		n += c
+	}
+	return n, nil
+}
+
+func (w *writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	return w.seal(true)
+}
+
+func (w *writer) seal(final bool) error {
+	out := w.aead.Seal(nil, w.nonce.bytes(final), w.buf, w.header)
+	w.buf = w.buf[:0]
+	w.nonce.next()
+	if _, err := w.w.Write(out); err != nil {
+		return fmt.Errorf("write bundle chunk: %w", err)
+	}
+	return nil
+}
+
+// NewReader reads a bundle header from r and returns a reader of the
+// decrypted contents. Read returns ErrDecrypt if a chunk fails to
+// authenticate, including when the bundle ends before its final chunk.
+func NewReader(r io.Reader, password string) (io.Reader, error) {
+	header := make([]byte, headerSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, ErrNotBundle
+		}
+		return nil, fmt.Errorf("read bundle header: %w", err)
+	}
+	if !bytes.Equal(header[:len(magic)], []byte(magic)) {
+		return nil, ErrNotBundle
+	}
+	aead, err := newAEAD(password, header)
+	if err != nil {
+		return nil, err
+	}
+	return &reader{r: bufio.NewReaderSize(r, chunkSize+tagSize+1), aead: aead, header: header}, nil
+}
+
+type reader struct {
+	r      *bufio.Reader
+	aead   cipher.AEAD
+	header []byte
+	nonce  chunkNonce
+	plain  []byte
+	done   bool
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	for len(r.plain) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.open(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.plain)
+	r.plain = r.plain[n:]
+	return n, nil
+}
+
+func (r *reader) open() error {
+	sealed := make([]byte, chunkSize+r.aead.Overhead())
+	n, err := io.ReadFull(r.r, sealed)
+	switch {
+	case errors.Is(err, io.EOF):
+		return ErrDecrypt
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		r.done = true
+	case err != nil:
+		return fmt.Errorf("read bundle chunk: %w", err)
+	default:
+		if _, err := r.r.Peek(1); errors.Is(err, io.EOF) {
+			r.done = true
+		}
+	}
+
+	plain, err := r.aead.Open(nil, r.nonce.bytes(r.done), sealed[:n], r.header)
+	if err != nil {
+		return ErrDecrypt
+	}
+	r.nonce.next()
+	r.plain = plain
+	return nil
+}
+
+func newAEAD(password string, header []byte) (cipher.AEAD, error) {
+	work := binary.BigEndian.Uint32(header[len(magic)+saltSize:])
+	if work == 0 || work > maxIterations {
+		return nil, fmt.Errorf("unsupported bundle work factor %d", work)
+	}
+	key, err := pbkdf2.Key(sha256.New, password, header[len(magic):len(magic)+saltSize], int(work), 32)
+	if err != nil {
+		return nil, fmt.Errorf("derive bundle key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// chunkNonce is an 11-byte big-endian chunk counter followed by a byte that
+// is 1 for the final chunk.
+type chunkNonce [12]byte
+
+func (n *chunkNonce) bytes(final bool) []byte {
+	out := *n
+	if final {
+		out[11] = 1
+	}
+	return out[:]
+}
+
+func (n *chunkNonce) next() {
+	for i := 10; i >= 0; i-- {
+		n[i]++
+		if n[i] != 0 {
+			return
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package bundle
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io"
+	"testing"
+)
+
+func init() {
+	// Keep key derivation fast in tests.
+	iterations = 1000
+}
+
+func seal(t *testing.T, plain []byte, password string) []byte {
+	t.Helper()
+	var out bytes.Buffer
+	w, err := NewWriter(&out, password)
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+	if _, err := w.Write(plain); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	return out.Bytes()
+}
+
+func open(sealed []byte, password string) ([]byte, error) {
+	r, err := NewReader(bytes.NewReader(sealed), password)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, size := range []int{0, 1, chunkSize - 1, chunkSize, chunkSize + 1, 3*chunkSize + 17} {
+		plain := make([]byte, size)
+		_, _ = rand.Read(plain)
+
+		sealed := seal(t, plain, "correct horse")
+		if bytes.Contains(sealed, plain) && size > 0 {
+			t.Fatalf("size %d: plaintext visible in bundle", size)
+		}
+		got, err := open(sealed, "correct horse")
+		if err != nil {
+			t.Fatalf("size %d: open failed: %v", size, err)
+		}
+		if !bytes.Equal(got, plain) {
+			t.Fatalf("size %d: round trip mismatch", size)
+		}
+	}
+}
+
+func TestWrongPasswordAndTampering(t *testing.T) {
+	plain := bytes.Repeat([]byte("transcript "), chunkSize/5)
+	sealed := seal(t, plain, "secret")
+
+	if _, err := open(sealed, "guess"); !errors.Is(err, ErrDecrypt) {
+		t.Fatalf("expected ErrDecrypt for wrong password, got %v", err)
+	}
+
+	flipped := bytes.Clone(sealed)
+	flipped[len(flipped)-1] ^= 1
+	if _, err := open(flipped, "secret"); !errors.Is(err, ErrDecrypt) {
+		t.Fatalf("expected ErrDecrypt for modified bundle, got %v", err)
+	}
+
+	// Cutting the bundle at a chunk boundary must not pass as a shorter file.
+	truncated := sealed[:headerSize+chunkSize+tagSize]
+	if _, err := open(truncated, "secret"); !errors.Is(err, ErrDecrypt) {
+		t.Fatalf("expected ErrDecrypt for truncated bundle, got %v", err)
+	}
+
+	if _, err := open([]byte("PK\x03\x04 plain zip"), "secret"); !errors.Is(err, ErrNotBundle) {
+		t.Fatalf("expected ErrNotBundle, got %v", err)
+	}
+}
+
+func TestEmptyPasswordRejected(t *testing.T) {
+	if _, err := NewWriter(io.Discard, ""); err == nil {
+		t.Fatal("expected an empty password to be rejected")
+	}
+}
@@ -2,12 +2,17 @@ package config
 
 import (
 	"fmt"
+	"net/netip"
+	"net/url"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/sjawhar/ghost-wispr/internal/i18n"
 	"github.com/sjawhar/ghost-wispr/internal/llm"
+	"github.com/sjawhar/ghost-wispr/internal/redact"
 
 	"gopkg.in/yaml.v3"
 )
@@ -22,58 +27,781 @@ type Preset struct {
 	SystemPrompt string `yaml:"system_prompt"`
 	UserTemplate string `yaml:"user_template"`
 	Model        string `yaml:"model"`
+	// DocumentTemplate overrides Summarization.DocumentTemplate for sessions
+	// summarized with this preset.
+	DocumentTemplate string `yaml:"document_template"`
+	// Language pins the language summaries are written in, as a name or
+	// ISO 639-1 code. Empty or SummaryLanguageAuto matches the transcript.
+	Language string `yaml:"language"`
+	// ReadingLevel is who the summary is written for, one of ReadingLevels;
+	// empty leaves it to the prompt.
+	ReadingLevel string `yaml:"reading_level"`
+}
+
+// SummaryLanguageAuto summarizes in the transcript's own language.
+const SummaryLanguageAuto = "auto"
+
+// OfflineProbeAuto probes the summarization provider's own API host.
+const OfflineProbeAuto = "auto"
+
+// Summary reading levels: plain language for anyone, a brief for
+// executives, or full detail for specialists.
+const (
+	ReadingLevelPlain     = "plain"
+	ReadingLevelExecutive = "executive"
+	ReadingLevelTechnical = "technical"
+)
+
+// ReadingLevels are the reading levels summaries can be written at.
+var ReadingLevels = []string{ReadingLevelPlain, ReadingLevelExecutive, ReadingLevelTechnical}
+
+// ModelPricing is the USD price per million tokens for an LLM model.
+type ModelPricing struct {
+	InputPerMillion  float64 `yaml:"input_per_million"`
+	OutputPerMillion float64 `yaml:"output_per_million"`
+}
+
+// Cost returns the USD cost of the given token counts.
+func (p ModelPricing) Cost(inputTokens, outputTokens int) float64 {
+	return (float64(inputTokens)*p.InputPerMillion + float64(outputTokens)*p.OutputPerMillion) / 1e6
 }
 
 type Summarization struct {
-	Model   string            `yaml:"model"`
-	BaseURL string            `yaml:"base_url"`
-	Presets map[string]Preset `yaml:"presets"`
+	Model            string            `yaml:"model" env:"SUMMARIZATION_MODEL"`
+	BaseURL          string            `yaml:"base_url"`
+	Presets          map[string]Preset `yaml:"presets"`
+	ExtractDecisions bool              `yaml:"extract_decisions"`
+	// ExtractActionItems records each session's action items and, for
+	// recurring meetings, carries the open ones into the next summary.
+	ExtractActionItems bool                    `yaml:"extract_action_items"`
+	Pricing            map[string]ModelPricing `yaml:"pricing"`
+	// OfflineProbe is a host:port dialed to detect that the network is back
+	// before retrying summaries queued while offline. OfflineProbeAuto dials
+	// the summarization provider's API; empty disables queueing.
+	OfflineProbe         string `yaml:"offline_probe"`
+	OfflineRetryInterval string `yaml:"offline_retry_interval"`
+	// MemoPreset is the preset voice memos are summarized with.
+	MemoPreset string `yaml:"memo_preset"`
+	// DocumentTemplate wraps every summary before it is stored, e.g. with a
+	// header and footer. Empty stores the summary as written.
+	DocumentTemplate string `yaml:"document_template"`
+	// LinkBaseURL is where the web UI is reachable from, for the audio and
+	// transcript links in document templates.
+	LinkBaseURL string `yaml:"link_base_url"`
+	// PrimaryLanguage is the ISO 639-1 code of the language mixed-language
+	// meetings are summarized in, when it was spoken in them.
+	PrimaryLanguage string `yaml:"primary_language"`
 }
 
 type Transcription struct {
-	Endpointing    string `yaml:"endpointing"`
-	UtteranceEndMs string `yaml:"utterance_end_ms"`
+	Endpointing    string  `yaml:"endpointing" env:"TRANSCRIPTION_ENDPOINTING"`
+	UtteranceEndMs string  `yaml:"utterance_end_ms" env:"TRANSCRIPTION_UTTERANCE_END_MS"`
+	CostPerMinute  float64 `yaml:"cost_per_minute"`
+	// Language is the BCP-47 code of the language spoken, such as "en-US" or
+	// "fr", or "auto" to detect it word by word in multilingual meetings.
+	Language string `yaml:"language" env:"TRANSCRIPTION_LANGUAGE"`
+	// Keywords are names, product terms and acronyms Deepgram should favour,
+	// each optionally suffixed with ":intensifier".
+	Keywords []string `yaml:"keywords" env:"TRANSCRIPTION_KEYWORDS" live:"true"`
+	// ProfanityFilter has Deepgram mask profanity, and Redact names the
+	// entity classes it should redact, such as "pci", "pii" or "numbers".
+	ProfanityFilter bool     `yaml:"profanity_filter" env:"TRANSCRIPTION_PROFANITY_FILTER"`
+	Redact          []string `yaml:"redact" env:"TRANSCRIPTION_REDACT"`
+	// MinConfidence drops words the provider was less sure of, from 0 to 1;
+	// 0 keeps every word.
+	MinConfidence float64 `yaml:"min_confidence"`
+	// StableSpeakers keeps speaker numbers meaning the same person across
+	// reconnects and smooths over brief diarization slips.
+	StableSpeakers bool `yaml:"stable_speakers"`
+	// Providers is the live transcription failover chain, most preferred
+	// first: when one can't connect or keeps failing, the next takes over.
+	Providers []string        `yaml:"providers" env:"TRANSCRIPTION_PROVIDERS"`
+	Whisper   WhisperProvider `yaml:"whisper"`
+	// OfflineFallback buffers audio while the live stream is down and
+	// transcribes it with the pre-recorded API once it reconnects.
+	OfflineFallback           bool `yaml:"offline_fallback"`
+	OfflineFallbackMaxMinutes int  `yaml:"offline_fallback_max_minutes"`
+	VAD                       VAD  `yaml:"vad"`
+	// InterimInterval is the least time between interim transcripts sent
+	// to clients; those arriving sooner are coalesced, the latest winning.
+	// 0 sends every one.
+	InterimInterval string `yaml:"interim_interval"`
+}
+
+// maxInterimInterval bounds transcription.interim_interval; held any
+// longer, interim transcripts stop looking live.
+const maxInterimInterval = 10 * time.Second
+
+// Live transcription providers.
+const (
+	TranscriptionProviderDeepgram   = "deepgram"
+	TranscriptionProviderAssemblyAI = "assemblyai"
+	TranscriptionProviderWhisper    = "whisper"
+)
+
+// TranscriptionProviders lists the live transcription providers.
+var TranscriptionProviders = []string{TranscriptionProviderDeepgram, TranscriptionProviderAssemblyAI, TranscriptionProviderWhisper}
+
+// WhisperProvider transcribes with an OpenAI-compatible Whisper API, such as
+// a local faster-whisper-server. An empty BaseURL uses OpenAI's.
+type WhisperProvider struct {
+	BaseURL string `yaml:"base_url"`
+	Model   string `yaml:"model"`
+}
+
+// VAD gates the live stream on local voice-activity detection so silence is
+// never sent to Deepgram.
+type VAD struct {
+	Enabled bool `yaml:"enabled"`
+	// ThresholdDB is the loudness, in dBFS, above which audio counts as speech.
+	ThresholdDB float64 `yaml:"threshold_db"`
+	// PreRoll is how much audio from just before speech is sent when
+	// streaming resumes.
+	PreRoll string `yaml:"pre_roll"`
+	// Hangover keeps streaming this long after speech stops. It should exceed
+	// utterance_end_ms so Deepgram can finalize utterances.
+	Hangover string `yaml:"hangover"`
+}
+
+// Budget actions applied once a monthly budget is exhausted.
+const (
+	BudgetActionWarn             = "warn"
+	BudgetActionPauseCapture     = "pause_capture"
+	BudgetActionCheaperModel     = "cheaper_model"
+	BudgetActionDisableSummaries = "disable_summaries"
+)
+
+// Budget caps monthly spend in USD. A zero budget is unlimited. When a budget
+// is exceeded the matching action degrades behavior until the month rolls over.
+type Budget struct {
+	MonthlyTranscriptionUSD float64 `yaml:"monthly_transcription_usd"`
+	MonthlyLLMUSD           float64 `yaml:"monthly_llm_usd"`
+	TranscriptionAction     string  `yaml:"transcription_action"`
+	LLMAction               string  `yaml:"llm_action"`
+	FallbackModel           string  `yaml:"fallback_model"`
+}
+
+// MicDevice selects an input device by name for multi-mic capture. Gain
+// scales the device's samples before mixing; 0 means unity gain.
+type MicDevice struct {
+	Name string  `yaml:"name"`
+	Gain float64 `yaml:"gain"`
+}
+
+// How the devices of mic_devices are combined: mixed into one channel, or
+// each on its own channel.
+const (
+	MicChannelsMix      = "mix"
+	MicChannelsSeparate = "separate"
+)
+
+// Loopback modes for capturing system audio output.
+const (
+	LoopbackModeOff      = "off"
+	LoopbackModeMix      = "mix"
+	LoopbackModeOnly     = "only"
+	LoopbackModeSeparate = "separate"
+)
+
+// Loopback records what the computer is playing, so the remote side of calls
+// is transcribed. Mode "mix" adds it to the microphone, "only" replaces the
+// microphone with it, and "separate" sends the two as the channels of a
+// stereo stream so the microphone is always told apart from the remote
+// participants. Source names the output to capture; empty picks the
+// platform default. Gain scales the loopback when mixing; 0 means unity.
+type Loopback struct {
+	Mode   string  `yaml:"mode" env:"LOOPBACK_MODE"`
+	Source string  `yaml:"source"`
+	Gain   float64 `yaml:"gain"`
+}
+
+// DSP cleans up captured audio before it is recorded and transcribed, so
+// steady background noise doesn't turn into junk segments or start
+// sessions. HighPassHz filters out rumble below it and audio quieter than
+// NoiseGateDB is silenced; either is off at 0. AGC steers speech toward
+// AGCTargetDB, boosting or cutting it by at most AGCMaxGainDB.
+type DSP struct {
+	Enabled      bool    `yaml:"enabled"`
+	HighPassHz   float64 `yaml:"high_pass_hz"`
+	NoiseGateDB  float64 `yaml:"noise_gate_db"`
+	AGC          bool    `yaml:"agc"`
+	AGCTargetDB  float64 `yaml:"agc_target_db"`
+	AGCMaxGainDB float64 `yaml:"agc_max_gain_db"`
+}
+
+// Watch imports audio files dropped into Dir, such as a phone's recordings
+// synced in with Syncthing: each is transcribed, summarized and then moved
+// to ArchiveDir, or "imported" inside Dir when that is empty. Dir is scanned
+// every Interval. An empty Dir disables it.
+type Watch struct {
+	Dir        string `yaml:"dir"`
+	ArchiveDir string `yaml:"archive_dir"`
+	Interval   string `yaml:"interval"`
+}
+
+// Text-to-speech providers for summary audio.
+const (
+	TTSProviderOpenAI = "openai"
+	TTSProviderPiper  = "piper"
+)
+
+// TTS renders completed summaries to audio. An empty Provider disables it.
+// BaseURL points the openai provider at a compatible speech API, such as a
+// local openedai-speech; empty uses OpenAI's.
+type TTS struct {
+	Provider     string `yaml:"provider" env:"TTS_PROVIDER"`
+	BaseURL      string `yaml:"base_url"`
+	Model        string `yaml:"model"`
+	Voice        string `yaml:"voice"`
+	PiperCommand string `yaml:"piper_command"`
+	PiperModel   string `yaml:"piper_model"`
+}
+
+// MQTT publishes recording state to a broker for Home Assistant. An empty
+// Broker disables it; the password comes from GHOST_WISPR_MQTT_PASSWORD.
+type MQTT struct {
+	Broker          string `yaml:"broker" env:"MQTT_BROKER"`
+	ClientID        string `yaml:"client_id"`
+	Username        string `yaml:"username"`
+	TopicPrefix     string `yaml:"topic_prefix"`
+	DiscoveryPrefix string `yaml:"discovery_prefix"`
+}
+
+// SpeakerRefinement re-diarizes archived session audio with a local program
+// to fix speaker flips. An empty Command disables it.
+type SpeakerRefinement struct {
+	Command  string   `yaml:"command"`
+	Args     []string `yaml:"args"`
+	Timeout  string   `yaml:"timeout"`
+	Interval string   `yaml:"interval"`
+}
+
+// VoiceProfiles recognizes enrolled voices in finished sessions with a
+// speaker-embedding model run in process, and names their speakers. Model is
+// the model in ONNX format, and Runtime the ONNX Runtime shared library to
+// run it with, found by the system when empty. An empty Model disables it. A
+// voice is recognized when its embedding is at least Threshold similar
+// (cosine) to an enrolled one.
+type VoiceProfiles struct {
+	Model     string  `yaml:"model"`
+	Runtime   string  `yaml:"onnxruntime"`
+	Timeout   string  `yaml:"timeout"`
+	Threshold float64 `yaml:"threshold"`
+}
+
+// Series threads sessions held in the same weekly slot (weekday and local
+// start time within SlotTolerance) into recurring meeting series. Sessions
+// shorter than MinDuration are left out. LookbackWeeks 0 disables detection.
+type Series struct {
+	LookbackWeeks int    `yaml:"lookback_weeks"`
+	SlotTolerance string `yaml:"slot_tolerance"`
+	MinDuration   string `yaml:"min_duration"`
+}
+
+// Classification sorts finished sessions into meetings and ambient chatter,
+// such as hallway conversations, and treats each class by its own policy. A
+// session overlapping a briefing's start, give or take CalendarSlack, is a
+// meeting; otherwise one shorter than MinDuration or with fewer than
+// MinSpeakers speakers is ambient.
+type Classification struct {
+	Enabled       bool          `yaml:"enabled"`
+	MinDuration   string        `yaml:"min_duration"`
+	MinSpeakers   int           `yaml:"min_speakers"`
+	CalendarSlack string        `yaml:"calendar_slack"`
+	Meeting       SessionPolicy `yaml:"meeting"`
+	Ambient       SessionPolicy `yaml:"ambient"`
+}
+
+// SessionPolicy is how sessions of one class are treated: whether they are
+// summarized, whether their summary_ready event is broadcast, and how long
+// they are kept after ending. An empty Retention keeps them forever.
+type SessionPolicy struct {
+	Summarize bool   `yaml:"summarize"`
+	Notify    bool   `yaml:"notify"`
+	Retention string `yaml:"retention"`
+}
+
+// ParsedRetention returns Retention as a time.Duration, or 0 (keep forever)
+// if it is empty or invalid.
+func (p SessionPolicy) ParsedRetention() time.Duration {
+	d, err := time.ParseDuration(p.Retention)
+	if err != nil || d < 0 {
+		return 0
+	}
+	return d
+}
+
+// Briefings tunes pre-meeting briefs: each is announced Lead before its
+// meeting and drawn from up to MaxSessions related sessions of the last
+// LookbackDays days.
+type Briefings struct {
+	Lead         string `yaml:"lead"`
+	LookbackDays int    `yaml:"lookback_days"`
+	MaxSessions  int    `yaml:"max_sessions"`
+}
+
+// Reports controls the weekly operational report, written each Monday
+// for the week before and announced as a report_ready event.
+type Reports struct {
+	Weekly bool `yaml:"weekly"`
+}
+
+// Redaction masks details locally, on top of any redaction Deepgram does,
+// before segments are stored or sent to a model. Kinds are any of "email",
+// "phone" and "credit_card".
+type Redaction struct {
+	Kinds []string `yaml:"kinds" env:"REDACTION_KINDS"`
+}
+
+// Dictation configures dictation mode, which streams each finalized segment
+// to /api/dictation/stream. TypeCommand, when set, is an input-injection
+// helper such as wtype or xdotool run with each segment on stdin to type it
+// into the focused window. Active starts with dictation on.
+type Dictation struct {
+	Active      bool     `yaml:"active"`
+	TypeCommand string   `yaml:"type_command"`
+	TypeArgs    []string `yaml:"type_args"`
+	TypeTimeout string   `yaml:"type_timeout"`
+}
+
+// Workers caps how many background jobs of each kind run concurrently.
+type Workers struct {
+	Summaries int `yaml:"summaries" env:"SUMMARY_WORKERS"`
+	Exports   int `yaml:"exports"`
+	Backups   int `yaml:"backups"`
+}
+
+// Appliance tunes Ghost Wispr for an always-on, low-memory ARM board such as
+// a Raspberry Pi: buffers and worker pools are capped, audio is kept as WAV
+// so no encoder is spawned, and the SQLite cache is shrunk. The watchdog
+// limits are checked every CheckInterval and reported in /api/status.
+type Appliance struct {
+	Enabled         bool    `yaml:"enabled" env:"APPLIANCE"`
+	MemoryLimitMB   int     `yaml:"memory_limit_mb"`
+	CPULimitPercent float64 `yaml:"cpu_limit_percent"`
+	CheckInterval   string  `yaml:"check_interval"`
+	DBCacheKB       int     `yaml:"db_cache_kb"`
+	// BufferMinutes caps the offline-fallback audio held in memory.
+	BufferMinutes int `yaml:"buffer_minutes"`
+}
+
+// Power controls what happens when the machine sleeps. With SuspendAware
+// the active session ends as it suspends, noted as suspended, and capture
+// and live transcription are reopened when it wakes.
+type Power struct {
+	SuspendAware bool `yaml:"suspend_aware"`
+}
+
+// GraphQL enables the read-only /graphql endpoint.
+type GraphQL struct {
+	Enabled bool `yaml:"enabled"`
+}
+
+// API token scopes, from least to most privileged.
+const (
+	TokenScopeRead    = "read"
+	TokenScopeControl = "control"
+	TokenScopeAdmin   = "admin"
+)
+
+// APIToken grants a bearer token a scope. The secret is read from the
+// environment variable named by TokenEnv so it never appears in the file.
+type APIToken struct {
+	Name     string `yaml:"name"`
+	Scope    string `yaml:"scope"`
+	TokenEnv string `yaml:"token_env"`
+	Secret   string `yaml:"-"`
+}
+
+// Auth requires one of Tokens on every API and WebSocket request once any
+// token is configured. AccessLog, if set, is a file that records which token
+// hit which endpoint. AllowedOrigins lists the pages, as scheme://host[:port],
+// besides the server's own that may open WebSockets to it. TrustedProxies
+// lists the addresses or CIDR ranges of authenticating reverse proxies whose
+// X-Forwarded-User and Remote-User headers name the user.
+type Auth struct {
+	Tokens         []APIToken `yaml:"tokens"`
+	AccessLog      string     `yaml:"access_log"`
+	AllowedOrigins []string   `yaml:"allowed_origins"`
+	TrustedProxies []string   `yaml:"trusted_proxies"`
+}
+
+// ParsedTrustedProxies returns TrustedProxies as prefixes, a bare address
+// becoming a single-address prefix. Invalid entries are skipped.
+func (a Auth) ParsedTrustedProxies() []netip.Prefix {
+	prefixes := make([]netip.Prefix, 0, len(a.TrustedProxies))
+	for _, p := range a.TrustedProxies {
+		if prefix, ok := parseProxy(p); ok {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	return prefixes
+}
+
+func parseProxy(s string) (netip.Prefix, bool) {
+	s = strings.TrimSpace(s)
+	if prefix, err := netip.ParsePrefix(s); err == nil {
+		return prefix.Masked(), true
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, false
+	}
+	return netip.PrefixFrom(addr, addr.BitLen()), true
+}
+
+// Update channels.
+const (
+	UpdateChannelStable     = "stable"
+	UpdateChannelPrerelease = "prerelease"
+)
+
+// Updates controls the GitHub release checker. AutoStage downloads a newer
+// binary over the installed one so it runs after the next restart, once it
+// matches the release's checksums signed by PublicKey (base64 Ed25519),
+// which defaults to the key the build was released with.
+type Updates struct {
+	Enabled   bool   `yaml:"enabled"`
+	Channel   string `yaml:"channel" env:"UPDATE_CHANNEL"`
+	Interval  string `yaml:"interval"`
+	AutoStage bool   `yaml:"auto_stage"`
+	PublicKey string `yaml:"public_key"`
+}
+
+// Hook runs an external command when an event is broadcast. The event JSON
+// is written to the command's stdin; Timeout defaults to 30s.
+type Hook struct {
+	Event   string   `yaml:"event"`
+	Command string   `yaml:"command"`
+	Args    []string `yaml:"args"`
+	Timeout string   `yaml:"timeout"`
+}
+
+// ParsedTimeout returns Timeout as a time.Duration, falling back to 30s if
+// the value is empty or invalid.
+func (h Hook) ParsedTimeout() time.Duration {
+	d, err := time.ParseDuration(h.Timeout)
+	if err != nil || d <= 0 {
+		return 30 * time.Second
+	}
+	return d
+}
+
+// Export targets.
+const (
+	ExportTargetMarkdown = "markdown"
+	ExportTargetSlack    = "slack"
+	ExportTargetGDrive   = "gdrive"
+)
+
+// ExportTargets lists the targets an export rule can deliver to.
+var ExportTargets = []string{ExportTargetMarkdown, ExportTargetSlack, ExportTargetGDrive}
+
+// ExportRule delivers a session to one target when event On is broadcast,
+// if it passes the rule's filters: a markdown file in Dir, a Slack message
+// through the incoming webhook named by WebhookURLEnv, or a Google Doc in
+// FolderID. Template wraps the summary with the document template
+// placeholders; empty sends the summary as it is. Presets and Tags, when
+// set, each need one match; a session's tags are its kind and "recurring"
+// if it belongs to a series.
+type ExportRule struct {
+	Name          string   `yaml:"name"`
+	On            string   `yaml:"on"`
+	Target        string   `yaml:"target"`
+	Dir           string   `yaml:"dir"`
+	Channel       string   `yaml:"channel"`
+	WebhookURLEnv string   `yaml:"webhook_url_env"`
+	WebhookURL    string   `yaml:"-"`
+	FolderID      string   `yaml:"folder_id"`
+	Template      string   `yaml:"template"`
+	Presets       []string `yaml:"presets"`
+	Tags          []string `yaml:"tags"`
+	MinDuration   string   `yaml:"min_duration"`
+}
+
+// Export events. ExportEventSummaryReady, the default, triggers a rule once
+// a session's summary is written; ExportEventWeeklyReport once the weekly
+// report is, delivering the report instead of a session.
+const (
+	ExportEventSummaryReady = "summary_ready"
+	ExportEventWeeklyReport = "weekly_report"
+)
+
+// ExportEvents lists the events an export rule can fire on.
+var ExportEvents = []string{ExportEventSummaryReady, ExportEventWeeklyReport}
+
+// ParsedMinDuration returns MinDuration as a time.Duration, or 0 (no
+// minimum) if it is empty or invalid.
+func (r ExportRule) ParsedMinDuration() time.Duration {
+	d, err := time.ParseDuration(r.MinDuration)
+	if err != nil || d < 0 {
+		return 0
+	}
+	return d
+}
+
+// Script is a WebAssembly module consulted over the protocol described in
+// the script package, run sandboxed in-process. Timeout defaults to 5s and
+// MemoryLimitMB, the most memory the module may use, to 16.
+type Script struct {
+	Module        string `yaml:"module"`
+	Timeout       string `yaml:"timeout"`
+	MemoryLimitMB int    `yaml:"memory_limit_mb"`
 }
 
+// ParsedTimeout returns Timeout as a time.Duration, falling back to 5s if
+// the value is empty or invalid.
+func (s Script) ParsedTimeout() time.Duration {
+	d, err := time.ParseDuration(s.Timeout)
+	if err != nil || d <= 0 {
+		return 5 * time.Second
+	}
+	return d
+}
+
+// MemoryLimit returns MemoryLimitMB in bytes, falling back to 16MB if it is
+// not positive.
+func (s Script) MemoryLimit() int {
+	if s.MemoryLimitMB <= 0 {
+		return 16 << 20
+	}
+	return s.MemoryLimitMB << 20
+}
+
+// Scripts replace built-in decisions with user programs. An empty Module
+// leaves the built-in behavior in place.
+type Scripts struct {
+	PresetRouter     Script `yaml:"preset_router"`
+	SegmentProcessor Script `yaml:"segment_processor"`
+}
+
+// Capture backends.
+const (
+	CaptureBackendPortAudio = "portaudio"
+	CaptureBackendPipeWire  = "pipewire"
+	// CaptureBackendBrowser takes audio from the web UI, captured by the
+	// browser, for machines where the server has no audio device.
+	CaptureBackendBrowser = "browser"
+)
+
+// Audio encoders for finished session recordings.
+const (
+	AudioEncoderAuto   = "auto"
+	AudioEncoderFFmpeg = "ffmpeg"
+	AudioEncoderLame   = "lame"
+	AudioEncoderFLAC   = "flac"
+	AudioEncoderWAV    = "wav"
+)
+
+// Config is Ghost Wispr's configuration. Besides its yaml key, a field's
+// env tag names the variable, after EnvPrefix, that overrides it, and live
+// marks one that can be changed through the API without a restart; Schema
+// reads both.
 type Config struct {
-	DBPath                string        `yaml:"db_path"`
-	AudioDir              string        `yaml:"audio_dir"`
-	SilenceTimeout        string        `yaml:"silence_timeout"`
-	MicSampleRate         int           `yaml:"mic_sample_rate"`
-	MicSampleRates        []int         `yaml:"mic_sample_rates"`
-	GDriveFolderID        string        `yaml:"gdrive_folder_id"`
-	GoogleCredentialsFile string        `yaml:"google_credentials_file"`
-	Summarization         Summarization `yaml:"summarization"`
-	Transcription         Transcription `yaml:"transcription"`
-
-	// Secrets — env vars only, never serialized to YAML.
-	DeepgramAPIKey  string `yaml:"-"`
-	OpenAIAPIKey    string `yaml:"-"`
-	AnthropicAPIKey string `yaml:"-"`
-	GeminiAPIKey    string `yaml:"-"`
+	DBPath   string `yaml:"db_path" env:"DB_PATH"`
+	AudioDir string `yaml:"audio_dir" env:"AUDIO_DIR"`
+	// AudioEncoder compresses finished recordings: auto tries ffmpeg, then
+	// lame, then the built-in FLAC encoder. AudioBitrate is a constant MP3 bitrate in kbps;
+	// 0 encodes variable bitrate at AudioQuality, 0 (best) to 9 (smallest).
+	// AudioPreRoll is how much audio from before a session starts is kept at
+	// the head of its recording, since sessions start on their first words.
+	// AudioKeepWAV keeps every session's lossless audio as WAV next to its
+	// compressed recording; sessions can also opt in one at a time.
+	AudioEncoder          string            `yaml:"audio_encoder"`
+	AudioBitrate          int               `yaml:"audio_bitrate"`
+	AudioQuality          int               `yaml:"audio_quality"`
+	AudioPreRoll          string            `yaml:"audio_pre_roll"`
+	AudioKeepWAV          bool              `yaml:"audio_keep_wav"`
+	SilenceTimeout        string            `yaml:"silence_timeout" env:"SILENCE_TIMEOUT"`
+	MicSampleRate         int               `yaml:"mic_sample_rate" env:"MIC_SAMPLE_RATE"`
+	MicDevice             string            `yaml:"mic_device" env:"MIC_DEVICE" live:"true"`
+	MicDevicePreferences  []string          `yaml:"mic_device_preferences"`
+	MicDevices            []MicDevice       `yaml:"mic_devices"`
+	MicChannels           string            `yaml:"mic_channels"`
+	CaptureBackend        string            `yaml:"capture_backend" env:"CAPTURE_BACKEND"`
+	PipeWireTarget        string            `yaml:"pipewire_target"`
+	Loopback              Loopback          `yaml:"loopback"`
+	DSP                   DSP               `yaml:"dsp"`
+	Watch                 Watch             `yaml:"watch"`
+	GDriveFolderID        string            `yaml:"gdrive_folder_id" env:"GDRIVE_FOLDER_ID"`
+	GDriveSummaryDocs     bool              `yaml:"gdrive_summary_docs" env:"GDRIVE_SUMMARY_DOCS"`
+	GDriveJournal         bool              `yaml:"gdrive_journal" env:"GDRIVE_JOURNAL"`
+	GoogleCredentialsFile string            `yaml:"google_credentials_file" env:"GOOGLE_CREDENTIALS_FILE"`
+	Summarization         Summarization     `yaml:"summarization"`
+	Transcription         Transcription     `yaml:"transcription"`
+	Budget                Budget            `yaml:"budget"`
+	TTS                   TTS               `yaml:"tts"`
+	MQTT                  MQTT              `yaml:"mqtt"`
+	SpeakerRefinement     SpeakerRefinement `yaml:"speaker_refinement"`
+	VoiceProfiles         VoiceProfiles     `yaml:"voice_profiles"`
+	Series                Series            `yaml:"series"`
+	Classification        Classification    `yaml:"classification"`
+	Briefings             Briefings         `yaml:"briefings"`
+	Reports               Reports           `yaml:"reports"`
+	Dictation             Dictation         `yaml:"dictation"`
+	Redaction             Redaction         `yaml:"redaction"`
+	Workers               Workers           `yaml:"workers"`
+	Appliance             Appliance         `yaml:"appliance"`
+	Power                 Power             `yaml:"power"`
+	GraphQL               GraphQL           `yaml:"graphql"`
+	Auth                  Auth              `yaml:"auth"`
+	Updates               Updates           `yaml:"updates"`
+	Hooks                 []Hook            `yaml:"hooks"`
+	Exports               []ExportRule      `yaml:"exports"`
+	Scripts               Scripts           `yaml:"scripts"`
+	// Locale is the language of status and config warnings and generated
+	// documents, such as "de" or "es-MX". Logs stay in English.
+	Locale string `yaml:"locale" env:"LOCALE"`
+
+	// Secrets — env vars only, never serialized to YAML. The live ones are
+	// the provider keys POST /api/admin/keys rotates.
+	DeepgramAPIKey   string `yaml:"-" env:"DEEPGRAM_API_KEY" live:"true"`
+	AssemblyAIAPIKey string `yaml:"-" env:"ASSEMBLYAI_API_KEY" live:"true"`
+	OpenAIAPIKey     string `yaml:"-" env:"OPENAI_API_KEY" live:"true"`
+	AnthropicAPIKey  string `yaml:"-" env:"ANTHROPIC_API_KEY" live:"true"`
+	GeminiAPIKey     string `yaml:"-" env:"GEMINI_API_KEY" live:"true"`
+	MQTTPassword     string `yaml:"-" env:"MQTT_PASSWORD"`
 }
 
 func defaults() Config {
 	return Config{
 		DBPath:                "data/ghost-wispr.db",
 		AudioDir:              "data/audio",
+		AudioEncoder:          AudioEncoderAuto,
+		AudioBitrate:          128,
+		AudioQuality:          4,
+		AudioPreRoll:          "5s",
 		SilenceTimeout:        "30s",
-		MicSampleRate:         16000,
-		MicSampleRates:        []int{48000, 44100, 32000, 24000},
+		Locale:                i18n.DefaultLocale,
 		GoogleCredentialsFile: "./service-account.json",
+		CaptureBackend:        CaptureBackendPortAudio,
+		MicChannels:           MicChannelsMix,
+		Loopback:              Loopback{Mode: LoopbackModeOff},
+		DSP: DSP{
+			HighPassHz:   100,
+			NoiseGateDB:  -55,
+			AGC:          true,
+			AGCTargetDB:  -20,
+			AGCMaxGainDB: 15,
+		},
+		Watch: Watch{Interval: "10s"},
 		Summarization: Summarization{
-			Model: "openai/gpt-4o-mini",
+			Model:                "openai/gpt-4o-mini",
+			ExtractDecisions:     true,
+			ExtractActionItems:   true,
+			OfflineProbe:         OfflineProbeAuto,
+			OfflineRetryInterval: "1m",
+			MemoPreset:           "memo",
+			LinkBaseURL:          "http://127.0.0.1:8080",
 			Presets: map[string]Preset{
 				"default": {
 					Description:  "General-purpose meeting summary with key topics, decisions, and action items",
 					SystemPrompt: "Summarize the following office conversation transcript concisely in markdown. Include key topics, decisions made, and action items if any.",
 					UserTemplate: "{{transcript}}",
 				},
+				"memo": {
+					Description:  "Personal voice memo: a short note to self, not a meeting",
+					SystemPrompt: "This is a voice memo the speaker recorded for themselves. Rewrite it as a short markdown note: a one-line title, the key points as bullets, and any to-dos or reminders as a checklist. Keep the speaker's own wording where possible.",
+					UserTemplate: "{{transcript}}",
+				},
+			},
+			// List prices of the models the providers offer for
+			// summaries; configured prices override them.
+			Pricing: map[string]ModelPricing{
+				"openai/gpt-4o-mini":                 {InputPerMillion: 0.15, OutputPerMillion: 0.60},
+				"openai/gpt-4o":                      {InputPerMillion: 2.50, OutputPerMillion: 10.00},
+				"openai/gpt-4.1":                     {InputPerMillion: 2.00, OutputPerMillion: 8.00},
+				"openai/gpt-4.1-mini":                {InputPerMillion: 0.40, OutputPerMillion: 1.60},
+				"openai/gpt-4.1-nano":                {InputPerMillion: 0.10, OutputPerMillion: 0.40},
+				"anthropic/claude-3-5-haiku-latest":  {InputPerMillion: 0.80, OutputPerMillion: 4.00},
+				"anthropic/claude-3-5-sonnet-latest": {InputPerMillion: 3.00, OutputPerMillion: 15.00},
+				"anthropic/claude-sonnet-4-5":        {InputPerMillion: 3.00, OutputPerMillion: 15.00},
+				"anthropic/claude-3-opus-latest":     {InputPerMillion: 15.00, OutputPerMillion: 75.00},
+				"gemini/gemini-2.5-flash":            {InputPerMillion: 0.30, OutputPerMillion: 2.50},
+				"gemini/gemini-2.5-pro":              {InputPerMillion: 1.25, OutputPerMillion: 10.00},
 			},
 		},
 		Transcription: Transcription{
-			Endpointing:    "400",
-			UtteranceEndMs: "1000",
+			Endpointing:               "400",
+			UtteranceEndMs:            "1000",
+			CostPerMinute:             0.0058,
+			Language:                  "en-US",
+			Providers:                 []string{TranscriptionProviderDeepgram},
+			Whisper:                   WhisperProvider{Model: "whisper-1"},
+			StableSpeakers:            true,
+			OfflineFallback:           true,
+			OfflineFallbackMaxMinutes: 30,
+			InterimInterval:           "200ms",
+			VAD: VAD{
+				ThresholdDB: -45,
+				PreRoll:     "500ms",
+				Hangover:    "2s",
+			},
+		},
+		Budget: Budget{
+			TranscriptionAction: BudgetActionPauseCapture,
+			LLMAction:           BudgetActionCheaperModel,
+			FallbackModel:       "openai/gpt-4o-mini",
+		},
+		TTS: TTS{
+			Model:        "gpt-4o-mini-tts",
+			Voice:        "alloy",
+			PiperCommand: "piper",
+		},
+		MQTT: MQTT{
+			ClientID:        "ghost-wispr",
+			TopicPrefix:     "ghost-wispr",
+			DiscoveryPrefix: "homeassistant",
+		},
+		SpeakerRefinement: SpeakerRefinement{
+			Timeout:  "30m",
+			Interval: "1h",
+		},
+		VoiceProfiles: VoiceProfiles{
+			Timeout:   "5m",
+			Threshold: 0.75,
+		},
+		Series: Series{
+			LookbackWeeks: 4,
+			SlotTolerance: "20m",
+			MinDuration:   "5m",
+		},
+		Classification: Classification{
+			MinDuration:   "3m",
+			MinSpeakers:   2,
+			CalendarSlack: "10m",
+			Meeting:       SessionPolicy{Summarize: true, Notify: true},
+			Ambient:       SessionPolicy{Retention: "168h"},
+		},
+		Briefings: Briefings{
+			Lead:         "10m",
+			LookbackDays: 30,
+			MaxSessions:  5,
+		},
+		Reports: Reports{
+			Weekly: true,
+		},
+		Dictation: Dictation{
+			TypeTimeout: "10s",
+		},
+		Workers: Workers{
+			Summaries: 2,
+			Exports:   2,
+			Backups:   1,
+		},
+		Appliance: Appliance{
+			MemoryLimitMB:   256,
+			CPULimitPercent: 80,
+			CheckInterval:   "30s",
+			DBCacheKB:       2048,
+			BufferMinutes:   5,
+		},
+		Power: Power{
+			SuspendAware: true,
+		},
+		Updates: Updates{
+			Channel:  UpdateChannelStable,
+			Interval: "24h",
 		},
 	}
 }
@@ -102,6 +830,7 @@ func Load(path string) (Config, []string, error) {
 	loadSecrets(&cfg)
 
 	warnings := validate(&cfg)
+	applyApplianceProfile(&cfg)
 	return cfg, warnings, nil
 }
 
@@ -115,29 +844,193 @@ func (c *Config) ParsedSilenceTimeout() time.Duration {
 	return d
 }
 
-// SampleRateCandidates returns a deduplicated ordered list of sample rates
-// to try: preferred rate first, then configured alternatives, then defaults.
-func (c *Config) SampleRateCandidates() []int {
-	hardcoded := []int{16000, 48000, 44100, 32000, 24000}
+// ParsedAudioPreRoll returns AudioPreRoll as a time.Duration, falling back
+// to 5s if the value is invalid.
+func (c *Config) ParsedAudioPreRoll() time.Duration {
+	d, err := time.ParseDuration(c.AudioPreRoll)
+	if err != nil || d < 0 {
+		return 5 * time.Second
+	}
+	return d
+}
 
-	combined := make([]int, 0, 1+len(c.MicSampleRates)+len(hardcoded))
-	combined = append(combined, c.MicSampleRate)
-	combined = append(combined, c.MicSampleRates...)
-	combined = append(combined, hardcoded...)
+// ParsedOfflineRetryInterval returns Summarization.OfflineRetryInterval as a
+// time.Duration, falling back to 1m if the value is invalid.
+// ParsedOfflineProbe is the host:port dialed to detect that summaries can
+// be retried, resolving OfflineProbeAuto through the summarization model's
+// provider and base_url. It is empty when queueing is disabled or the
+// provider's API can't be worked out.
+func (c *Config) ParsedOfflineProbe() string {
+	if c.Summarization.OfflineProbe != OfflineProbeAuto {
+		return c.Summarization.OfflineProbe
+	}
+	provider, _, err := llm.ParseModel(c.Summarization.Model)
+	if err != nil {
+		return ""
+	}
+	addr, err := llm.Endpoint(provider, c.Summarization.BaseURL)
+	if err != nil {
+		return ""
+	}
+	return addr
+}
 
-	seen := make(map[int]struct{}, len(combined))
-	result := make([]int, 0, len(combined))
-	for _, rate := range combined {
-		if rate <= 0 {
-			continue
-		}
-		if _, ok := seen[rate]; ok {
-			continue
-		}
-		seen[rate] = struct{}{}
-		result = append(result, rate)
+func (c *Config) ParsedOfflineRetryInterval() time.Duration {
+	d, err := time.ParseDuration(c.Summarization.OfflineRetryInterval)
+	if err != nil || d <= 0 {
+		return time.Minute
+	}
+	return d
+}
+
+// ParsedRefinementTimeout returns SpeakerRefinement.Timeout as a
+// time.Duration, falling back to 30m if the value is invalid.
+func (c *Config) ParsedRefinementTimeout() time.Duration {
+	d, err := time.ParseDuration(c.SpeakerRefinement.Timeout)
+	if err != nil || d <= 0 {
+		return 30 * time.Minute
+	}
+	return d
+}
+
+// ParsedRefinementInterval returns SpeakerRefinement.Interval as a
+// time.Duration, falling back to 1h if the value is invalid.
+func (c *Config) ParsedRefinementInterval() time.Duration {
+	d, err := time.ParseDuration(c.SpeakerRefinement.Interval)
+	if err != nil || d <= 0 {
+		return time.Hour
+	}
+	return d
+}
+
+// ParsedVoiceTimeout returns VoiceProfiles.Timeout as a time.Duration,
+// falling back to 5m if the value is invalid.
+func (c *Config) ParsedVoiceTimeout() time.Duration {
+	d, err := time.ParseDuration(c.VoiceProfiles.Timeout)
+	if err != nil || d <= 0 {
+		return 5 * time.Minute
+	}
+	return d
+}
+
+// ParsedSeriesSlotTolerance returns Series.SlotTolerance as a time.Duration,
+// falling back to 20m if the value is invalid.
+func (c *Config) ParsedSeriesSlotTolerance() time.Duration {
+	d, err := time.ParseDuration(c.Series.SlotTolerance)
+	if err != nil || d <= 0 {
+		return 20 * time.Minute
+	}
+	return d
+}
+
+// ParsedSeriesMinDuration returns Series.MinDuration as a time.Duration,
+// falling back to 5m if the value is invalid.
+func (c *Config) ParsedSeriesMinDuration() time.Duration {
+	d, err := time.ParseDuration(c.Series.MinDuration)
+	if err != nil || d < 0 {
+		return 5 * time.Minute
+	}
+	return d
+}
+
+// ParsedClassificationMinDuration returns Classification.MinDuration as a
+// time.Duration, falling back to 3m if the value is invalid.
+func (c *Config) ParsedClassificationMinDuration() time.Duration {
+	d, err := time.ParseDuration(c.Classification.MinDuration)
+	if err != nil || d < 0 {
+		return 3 * time.Minute
+	}
+	return d
+}
+
+// ParsedCalendarSlack returns Classification.CalendarSlack as a
+// time.Duration, falling back to 10m if the value is invalid.
+func (c *Config) ParsedCalendarSlack() time.Duration {
+	d, err := time.ParseDuration(c.Classification.CalendarSlack)
+	if err != nil || d < 0 {
+		return 10 * time.Minute
+	}
+	return d
+}
+
+// ParsedBriefingLead returns Briefings.Lead as a time.Duration, falling back
+// to 10m if the value is invalid.
+func (c *Config) ParsedBriefingLead() time.Duration {
+	d, err := time.ParseDuration(c.Briefings.Lead)
+	if err != nil || d < 0 {
+		return 10 * time.Minute
 	}
-	return result
+	return d
+}
+
+// ParsedDictationTypeTimeout returns Dictation.TypeTimeout as a
+// time.Duration, falling back to 10s if the value is invalid.
+func (c *Config) ParsedDictationTypeTimeout() time.Duration {
+	d, err := time.ParseDuration(c.Dictation.TypeTimeout)
+	if err != nil || d <= 0 {
+		return 10 * time.Second
+	}
+	return d
+}
+
+// ParsedWatchInterval returns Watch.Interval as a time.Duration, falling
+// back to 10s if it is invalid.
+func (c *Config) ParsedWatchInterval() time.Duration {
+	d, err := time.ParseDuration(c.Watch.Interval)
+	if err != nil || d <= 0 {
+		return 10 * time.Second
+	}
+	return d
+}
+
+// ParsedVADPreRoll returns Transcription.VAD.PreRoll as a time.Duration,
+// falling back to 500ms if the value is invalid.
+func (c *Config) ParsedVADPreRoll() time.Duration {
+	d, err := time.ParseDuration(c.Transcription.VAD.PreRoll)
+	if err != nil || d < 0 {
+		return 500 * time.Millisecond
+	}
+	return d
+}
+
+// ParsedVADHangover returns Transcription.VAD.Hangover as a time.Duration,
+// falling back to 2s if the value is invalid.
+func (c *Config) ParsedVADHangover() time.Duration {
+	d, err := time.ParseDuration(c.Transcription.VAD.Hangover)
+	if err != nil || d <= 0 {
+		return 2 * time.Second
+	}
+	return d
+}
+
+// ParsedInterimInterval returns Transcription.InterimInterval as a
+// time.Duration, falling back to 200ms if the value is invalid.
+func (c *Config) ParsedInterimInterval() time.Duration {
+	d, err := time.ParseDuration(c.Transcription.InterimInterval)
+	if err != nil || d < 0 || d > maxInterimInterval {
+		return 200 * time.Millisecond
+	}
+	return d
+}
+
+// ParsedApplianceCheckInterval returns Appliance.CheckInterval as a
+// time.Duration, falling back to 30s if the value is invalid.
+func (c *Config) ParsedApplianceCheckInterval() time.Duration {
+	d, err := time.ParseDuration(c.Appliance.CheckInterval)
+	if err != nil || d <= 0 {
+		return 30 * time.Second
+	}
+	return d
+}
+
+// ParsedUpdateInterval returns Updates.Interval as a time.Duration, falling
+// back to 24h if the value is invalid.
+func (c *Config) ParsedUpdateInterval() time.Duration {
+	d, err := time.ParseDuration(c.Updates.Interval)
+	if err != nil || d <= 0 {
+		return 24 * time.Hour
+	}
+	return d
 }
 
 func applyEnvOverrides(cfg *Config) {
@@ -155,8 +1048,14 @@ func applyEnvOverrides(cfg *Config) {
 			cfg.MicSampleRate = rate
 		}
 	}
-	if v := os.Getenv(EnvPrefix + "MIC_SAMPLE_RATES"); v != "" {
-		cfg.MicSampleRates = parseSampleRates(v)
+	if v := os.Getenv(EnvPrefix + "MIC_DEVICE"); v != "" {
+		cfg.MicDevice = v
+	}
+	if v := os.Getenv(EnvPrefix + "CAPTURE_BACKEND"); v != "" {
+		cfg.CaptureBackend = v
+	}
+	if v := os.Getenv(EnvPrefix + "LOOPBACK_MODE"); v != "" {
+		cfg.Loopback.Mode = v
 	}
 	if v := os.Getenv(EnvPrefix + "SUMMARIZATION_MODEL"); v != "" {
 		cfg.Summarization.Model = v
@@ -164,6 +1063,16 @@ func applyEnvOverrides(cfg *Config) {
 	if v := os.Getenv(EnvPrefix + "GDRIVE_FOLDER_ID"); v != "" {
 		cfg.GDriveFolderID = v
 	}
+	if v := os.Getenv(EnvPrefix + "GDRIVE_SUMMARY_DOCS"); v != "" {
+		if enabled, err := strconv.ParseBool(strings.TrimSpace(v)); err == nil {
+			cfg.GDriveSummaryDocs = enabled
+		}
+	}
+	if v := os.Getenv(EnvPrefix + "GDRIVE_JOURNAL"); v != "" {
+		if enabled, err := strconv.ParseBool(strings.TrimSpace(v)); err == nil {
+			cfg.GDriveJournal = enabled
+		}
+	}
 	if v := os.Getenv(EnvPrefix + "GOOGLE_CREDENTIALS_FILE"); v != "" {
 		cfg.GoogleCredentialsFile = v
 	}
@@ -173,39 +1082,213 @@ func applyEnvOverrides(cfg *Config) {
 	if v := os.Getenv(EnvPrefix + "TRANSCRIPTION_UTTERANCE_END_MS"); v != "" {
 		cfg.Transcription.UtteranceEndMs = v
 	}
+	if v := os.Getenv(EnvPrefix + "TRANSCRIPTION_LANGUAGE"); v != "" {
+		cfg.Transcription.Language = v
+	}
+	if v := os.Getenv(EnvPrefix + "TRANSCRIPTION_KEYWORDS"); v != "" {
+		cfg.Transcription.Keywords = strings.Split(v, ",")
+	}
+	if v := os.Getenv(EnvPrefix + "TRANSCRIPTION_PROFANITY_FILTER"); v != "" {
+		if enabled, err := strconv.ParseBool(strings.TrimSpace(v)); err == nil {
+			cfg.Transcription.ProfanityFilter = enabled
+		}
+	}
+	if v := os.Getenv(EnvPrefix + "TRANSCRIPTION_REDACT"); v != "" {
+		cfg.Transcription.Redact = strings.Split(v, ",")
+	}
+	if v := os.Getenv(EnvPrefix + "TRANSCRIPTION_PROVIDERS"); v != "" {
+		cfg.Transcription.Providers = strings.Split(v, ",")
+	}
+	if v := os.Getenv(EnvPrefix + "REDACTION_KINDS"); v != "" {
+		cfg.Redaction.Kinds = strings.Split(v, ",")
+	}
+	if v := os.Getenv(EnvPrefix + "SUMMARY_WORKERS"); v != "" {
+		if n, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
+			cfg.Workers.Summaries = n
+		}
+	}
+	if v := os.Getenv(EnvPrefix + "APPLIANCE"); v != "" {
+		if enabled, err := strconv.ParseBool(strings.TrimSpace(v)); err == nil {
+			cfg.Appliance.Enabled = enabled
+		}
+	}
+	if v := os.Getenv(EnvPrefix + "MQTT_BROKER"); v != "" {
+		cfg.MQTT.Broker = v
+	}
+	if v := os.Getenv(EnvPrefix + "TTS_PROVIDER"); v != "" {
+		cfg.TTS.Provider = v
+	}
+	if v := os.Getenv(EnvPrefix + "UPDATE_CHANNEL"); v != "" {
+		cfg.Updates.Channel = v
+	}
+	if v := os.Getenv(EnvPrefix + "LOCALE"); v != "" {
+		cfg.Locale = v
+	}
+}
+
+// applyApplianceProfile tightens resource-hungry settings when appliance mode
+// is on. It runs after validation so the caps apply to already-sane values.
+func applyApplianceProfile(cfg *Config) {
+	a := cfg.Appliance
+	if !a.Enabled {
+		return
+	}
+	if a.BufferMinutes > 0 && cfg.Transcription.OfflineFallbackMaxMinutes > a.BufferMinutes {
+		cfg.Transcription.OfflineFallbackMaxMinutes = a.BufferMinutes
+	}
+	cfg.Workers = Workers{Summaries: 1, Exports: 1, Backups: 1}
 }
 
 func loadSecrets(cfg *Config) {
 	cfg.DeepgramAPIKey = os.Getenv(EnvPrefix + "DEEPGRAM_API_KEY")
+	cfg.AssemblyAIAPIKey = os.Getenv(EnvPrefix + "ASSEMBLYAI_API_KEY")
 	cfg.OpenAIAPIKey = os.Getenv(EnvPrefix + "OPENAI_API_KEY")
 	cfg.AnthropicAPIKey = os.Getenv(EnvPrefix + "ANTHROPIC_API_KEY")
 	cfg.GeminiAPIKey = os.Getenv(EnvPrefix + "GEMINI_API_KEY")
+	cfg.MQTTPassword = os.Getenv(EnvPrefix + "MQTT_PASSWORD")
+	for i := range cfg.Auth.Tokens {
+		if env := cfg.Auth.Tokens[i].TokenEnv; env != "" {
+			cfg.Auth.Tokens[i].Secret = os.Getenv(env)
+		}
+	}
+	for i := range cfg.Exports {
+		if env := cfg.Exports[i].WebhookURLEnv; env != "" {
+			cfg.Exports[i].WebhookURL = os.Getenv(env)
+		}
+	}
+}
+
+// validateExports drops export rules that can't run: unnamed or duplicate
+// ones, unknown triggers or targets, and targets missing where to deliver.
+func validateExports(cfg *Config, l *i18n.Localizer) []string {
+	var warnings []string
+	seen := make(map[string]bool)
+	rules := cfg.Exports[:0]
+	for i, r := range cfg.Exports {
+		r.Name = strings.TrimSpace(r.Name)
+		if r.On == "" {
+			r.On = ExportEventSummaryReady
+		}
+		skip := ""
+		switch {
+		case r.Name == "":
+			skip = l.T(i18n.ConfigExportUnnamed, i)
+		case seen[r.Name]:
+			skip = l.T(i18n.ConfigExportDuplicate, r.Name)
+		case !slices.Contains(ExportEvents, r.On):
+			skip = l.T(i18n.ConfigExportEvent, r.On, r.Name, strings.Join(ExportEvents, ", "))
+		case !slices.Contains(ExportTargets, r.Target):
+			skip = l.T(i18n.ConfigExportTarget, r.Target, r.Name, strings.Join(ExportTargets, ", "))
+		case r.Target == ExportTargetMarkdown && strings.TrimSpace(r.Dir) == "":
+			skip = l.T(i18n.ConfigExportNeedsDir, r.Name)
+		case r.Target == ExportTargetSlack && r.WebhookURLEnv == "":
+			skip = l.T(i18n.ConfigExportNeedsWebhook, r.Name)
+		case r.Target == ExportTargetSlack && r.WebhookURL == "":
+			skip = l.T(i18n.ConfigExportWebhookUnset, r.Name, r.WebhookURLEnv)
+		case r.Target == ExportTargetGDrive && r.FolderID == "":
+			skip = l.T(i18n.ConfigExportNeedsFolder, r.Name)
+		}
+		if skip != "" {
+			warnings = append(warnings, skip)
+			continue
+		}
+		if r.MinDuration != "" {
+			if d, err := time.ParseDuration(r.MinDuration); err != nil || d < 0 {
+				warnings = append(warnings, l.T(i18n.ConfigExportMinDuration, r.MinDuration, r.Name))
+				r.MinDuration = ""
+			}
+		}
+		seen[r.Name] = true
+		rules = append(rules, r)
+	}
+	cfg.Exports = rules
+	return warnings
+}
+
+// validateTranscriptionProviders normalizes the failover chain, dropping
+// providers that are unknown or lack what they need to connect.
+func validateTranscriptionProviders(cfg *Config, l *i18n.Localizer) []string {
+	var warnings []string
+	var providers []string
+	noDeepgramKey := false
+	for _, p := range cfg.Transcription.Providers {
+		p = strings.ToLower(strings.TrimSpace(p))
+		switch {
+		case p == "" || slices.Contains(providers, p):
+		case !slices.Contains(TranscriptionProviders, p):
+			warnings = append(warnings, l.T(i18n.ConfigUnknownEntry, "transcription.providers", p, strings.Join(TranscriptionProviders, ", ")))
+		case p == TranscriptionProviderDeepgram && cfg.DeepgramAPIKey == "":
+			noDeepgramKey = true
+		case p == TranscriptionProviderAssemblyAI && cfg.AssemblyAIAPIKey == "":
+			warnings = append(warnings, l.T(i18n.ConfigProviderKeyMissing, "AssemblyAI", EnvPrefix+"ASSEMBLYAI_API_KEY", p))
+		case p == TranscriptionProviderWhisper && cfg.OpenAIAPIKey == "" && strings.TrimSpace(cfg.Transcription.Whisper.BaseURL) == "":
+			warnings = append(warnings, l.T(i18n.ConfigWhisperKeyMissing))
+		default:
+			providers = append(providers, p)
+		}
+	}
+	cfg.Transcription.Providers = providers
+	switch {
+	case len(providers) == 0:
+		warnings = append(warnings, l.T(i18n.ConfigNoTranscription, EnvPrefix+"DEEPGRAM_API_KEY"))
+	case noDeepgramKey:
+		warnings = append(warnings, l.T(i18n.ConfigProviderKeyMissing, "Deepgram", EnvPrefix+"DEEPGRAM_API_KEY", TranscriptionProviderDeepgram))
+	}
+	cfg.Transcription.Whisper.BaseURL = strings.TrimSpace(cfg.Transcription.Whisper.BaseURL)
+	if cfg.Transcription.Whisper.Model = strings.TrimSpace(cfg.Transcription.Whisper.Model); cfg.Transcription.Whisper.Model == "" {
+		cfg.Transcription.Whisper.Model = "whisper-1"
+	}
+	return warnings
 }
 
 func validate(cfg *Config) []string {
 	var warnings []string
 
-	if cfg.DeepgramAPIKey == "" {
-		warnings = append(warnings, "Deepgram API key not configured — live transcription is disabled. Set "+EnvPrefix+"DEEPGRAM_API_KEY.")
+	// The locale comes first so the rest of the warnings are in it; this
+	// one can only be in English.
+	if _, ok := i18n.Match(cfg.Locale); !ok {
+		warnings = append(warnings, fmt.Sprintf("Unsupported locale %q — must be one of %s. Using %s.", cfg.Locale, strings.Join(i18n.Supported(), ", "), i18n.DefaultLocale))
+		cfg.Locale = i18n.DefaultLocale
 	}
+	l, _ := i18n.New(cfg.Locale)
+
+	warnings = append(warnings, validateTranscriptionProviders(cfg, l)...)
 
 	providers := make(map[string]struct{})
 	addModelProvider := func(scope, model string) {
 		provider, _, err := llm.ParseModel(model)
 		if err != nil {
-			warnings = append(warnings, fmt.Sprintf("Invalid %s model %q — %v.", scope, model, err))
+			warnings = append(warnings, l.T(i18n.ConfigInvalidModel, scope, model, err))
 			return
 		}
 		providers[provider] = struct{}{}
 	}
 
 	addModelProvider("summarization", cfg.Summarization.Model)
+	warnings = append(warnings, validateBudget(&cfg.Budget, l)...)
+	if cfg.Budget.MonthlyLLMUSD > 0 && cfg.Budget.LLMAction == BudgetActionCheaperModel {
+		addModelProvider("budget fallback", cfg.Budget.FallbackModel)
+	}
 
 	if _, ok := cfg.Summarization.Presets["default"]; !ok {
-		warnings = append(warnings, "No default summarization preset configured — set summarization.presets.default.")
+		warnings = append(warnings, l.T(i18n.ConfigNoDefaultPreset))
+	}
+
+	if _, ok := cfg.Summarization.Presets[cfg.Summarization.MemoPreset]; !ok {
+		warnings = append(warnings, l.T(i18n.ConfigUnknownMemoPreset, cfg.Summarization.MemoPreset))
+		cfg.Summarization.MemoPreset = "default"
+	}
+	if u, err := url.Parse(cfg.Summarization.LinkBaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		warnings = append(warnings, l.T(i18n.ConfigInvalidDefault, "summarization.link_base_url", cfg.Summarization.LinkBaseURL, "http://127.0.0.1:8080"))
+		cfg.Summarization.LinkBaseURL = "http://127.0.0.1:8080"
 	}
 
 	for name, preset := range cfg.Summarization.Presets {
+		if preset.ReadingLevel != "" && !slices.Contains(ReadingLevels, preset.ReadingLevel) {
+			warnings = append(warnings, l.T(i18n.ConfigReadingLevel, "summarization.presets."+name+".reading_level", preset.ReadingLevel, strings.Join(ReadingLevels, ", ")))
+			preset.ReadingLevel = ""
+			cfg.Summarization.Presets[name] = preset
+		}
 		if strings.TrimSpace(preset.Model) == "" {
 			continue
 		}
@@ -216,57 +1299,403 @@ func validate(cfg *Config) []string {
 		switch provider {
 		case "openai":
 			if cfg.OpenAIAPIKey == "" {
-				warnings = append(warnings, "OpenAI API key not configured — set "+EnvPrefix+"OPENAI_API_KEY.")
+				warnings = append(warnings, l.T(i18n.ConfigAPIKeyMissing, "OpenAI", EnvPrefix+"OPENAI_API_KEY"))
 			}
 		case "anthropic":
 			if cfg.AnthropicAPIKey == "" {
-				warnings = append(warnings, "Anthropic API key not configured — set "+EnvPrefix+"ANTHROPIC_API_KEY.")
+				warnings = append(warnings, l.T(i18n.ConfigAPIKeyMissing, "Anthropic", EnvPrefix+"ANTHROPIC_API_KEY"))
 			}
 		case "gemini":
 			if cfg.GeminiAPIKey == "" {
-				warnings = append(warnings, "Gemini API key not configured — set "+EnvPrefix+"GEMINI_API_KEY.")
+				warnings = append(warnings, l.T(i18n.ConfigAPIKeyMissing, "Gemini", EnvPrefix+"GEMINI_API_KEY"))
 			}
 		}
 	}
 
+	switch cfg.AudioEncoder {
+	case AudioEncoderAuto, AudioEncoderFFmpeg, AudioEncoderLame, AudioEncoderFLAC, AudioEncoderWAV:
+	default:
+		warnings = append(warnings, l.T(i18n.ConfigInvalidChoice, "audio_encoder", cfg.AudioEncoder, strings.Join([]string{AudioEncoderAuto, AudioEncoderFFmpeg, AudioEncoderLame, AudioEncoderFLAC, AudioEncoderWAV}, ", "), AudioEncoderAuto))
+		cfg.AudioEncoder = AudioEncoderAuto
+	}
+	if cfg.AudioBitrate != 0 && (cfg.AudioBitrate < 8 || cfg.AudioBitrate > 320) {
+		warnings = append(warnings, l.T(i18n.ConfigAudioBitrate, cfg.AudioBitrate))
+		cfg.AudioBitrate = 128
+	}
+	if cfg.AudioQuality < 0 || cfg.AudioQuality > 9 {
+		warnings = append(warnings, l.T(i18n.ConfigAudioQuality, cfg.AudioQuality))
+		cfg.AudioQuality = 4
+	}
+
+	switch cfg.CaptureBackend {
+	case CaptureBackendPortAudio:
+	case CaptureBackendPipeWire:
+		if len(cfg.MicDevices) > 0 {
+			warnings = append(warnings, l.T(i18n.ConfigPipeWireIgnores, "mic_devices"))
+		}
+		if cfg.MicDevice != "" {
+			warnings = append(warnings, l.T(i18n.ConfigPipeWireIgnores, "mic_device"))
+		}
+		if len(cfg.MicDevicePreferences) > 0 {
+			warnings = append(warnings, l.T(i18n.ConfigPipeWireIgnores, "mic_device_preferences"))
+		}
+	case CaptureBackendBrowser:
+	default:
+		warnings = append(warnings, l.T(i18n.ConfigInvalidChoice, "capture_backend", cfg.CaptureBackend, strings.Join([]string{CaptureBackendPortAudio, CaptureBackendPipeWire, CaptureBackendBrowser}, ", "), CaptureBackendPortAudio))
+		cfg.CaptureBackend = CaptureBackendPortAudio
+	}
+
+	switch cfg.Loopback.Mode {
+	case LoopbackModeOff, LoopbackModeMix, LoopbackModeOnly, LoopbackModeSeparate:
+	case "":
+		cfg.Loopback.Mode = LoopbackModeOff
+	default:
+		warnings = append(warnings, l.T(i18n.ConfigInvalidChoice, "loopback.mode", cfg.Loopback.Mode, strings.Join([]string{LoopbackModeOff, LoopbackModeMix, LoopbackModeOnly, LoopbackModeSeparate}, ", "), LoopbackModeOff))
+		cfg.Loopback.Mode = LoopbackModeOff
+	}
+	if cfg.Loopback.Gain < 0 {
+		warnings = append(warnings, l.T(i18n.ConfigNegative, "loopback.gain", cfg.Loopback.Gain, "1.0"))
+		cfg.Loopback.Gain = 0
+	}
+	if cfg.Loopback.Gain == 0 {
+		cfg.Loopback.Gain = 1
+	}
+
+	if cfg.MicDevice != "" && len(cfg.MicDevices) > 0 {
+		warnings = append(warnings, l.T(i18n.ConfigMicDevicesOverride, "mic_device"))
+	}
+	if len(cfg.MicDevicePreferences) > 0 && len(cfg.MicDevices) > 0 {
+		warnings = append(warnings, l.T(i18n.ConfigMicDevicesOverride, "mic_device_preferences"))
+	}
+	cfg.MicDevicePreferences = slices.DeleteFunc(cfg.MicDevicePreferences, func(p string) bool { return strings.TrimSpace(p) == "" })
+
+	switch cfg.MicChannels {
+	case MicChannelsMix:
+	case MicChannelsSeparate:
+		if cfg.Loopback.Mode == LoopbackModeMix || cfg.Loopback.Mode == LoopbackModeSeparate {
+			warnings = append(warnings, l.T(i18n.ConfigMicChannelsLoopback, MicChannelsSeparate, cfg.Loopback.Mode))
+			cfg.MicChannels = MicChannelsMix
+		}
+	default:
+		warnings = append(warnings, l.T(i18n.ConfigInvalidChoice, "mic_channels", cfg.MicChannels, MicChannelsMix+", "+MicChannelsSeparate, MicChannelsMix))
+		cfg.MicChannels = MicChannelsMix
+	}
+
+	for i := range cfg.MicDevices {
+		d := &cfg.MicDevices[i]
+		if strings.TrimSpace(d.Name) == "" {
+			warnings = append(warnings, l.T(i18n.ConfigMicDeviceUnnamed, i))
+		}
+		if d.Gain < 0 {
+			warnings = append(warnings, l.T(i18n.ConfigMicDeviceGain, d.Gain, d.Name))
+			d.Gain = 0
+		}
+		if d.Gain == 0 {
+			d.Gain = 1
+		}
+	}
+
+	if d, err := time.ParseDuration(cfg.AudioPreRoll); err != nil || d < 0 {
+		warnings = append(warnings, l.T(i18n.ConfigInvalidDefault, "audio_pre_roll", cfg.AudioPreRoll, "5s"))
+	}
+
 	if _, err := time.ParseDuration(cfg.SilenceTimeout); err != nil {
-		warnings = append(warnings, fmt.Sprintf("Invalid silence_timeout %q — using default 30s.", cfg.SilenceTimeout))
+		warnings = append(warnings, l.T(i18n.ConfigInvalidDefault, "silence_timeout", cfg.SilenceTimeout, "30s"))
+	}
+
+	if d, err := time.ParseDuration(cfg.Summarization.OfflineRetryInterval); err != nil || d <= 0 {
+		warnings = append(warnings, l.T(i18n.ConfigInvalidDefault, "summarization.offline_retry_interval", cfg.Summarization.OfflineRetryInterval, "1m"))
 	}
 
 	if v := cfg.Transcription.Endpointing; v != "" {
 		if n, err := strconv.Atoi(v); err != nil || n < 0 {
-			warnings = append(warnings, fmt.Sprintf("Invalid transcription.endpointing %q — must be a non-negative integer (ms). Using Deepgram default.", v))
+			warnings = append(warnings, l.T(i18n.ConfigInvalidMs, "transcription.endpointing", v))
 		}
 	}
-	if v := cfg.Transcription.UtteranceEndMs; v != "" {
-		if n, err := strconv.Atoi(v); err != nil || n < 0 {
-			warnings = append(warnings, fmt.Sprintf("Invalid transcription.utterance_end_ms %q — must be a non-negative integer (ms). Using Deepgram default.", v))
+	if cfg.Transcription.Language = strings.TrimSpace(cfg.Transcription.Language); cfg.Transcription.Language == "" {
+		warnings = append(warnings, l.T(i18n.ConfigEmptyLanguage))
+		cfg.Transcription.Language = "en-US"
+	}
+	if cfg.Transcription.CostPerMinute < 0 {
+		warnings = append(warnings, l.T(i18n.ConfigNegative, "transcription.cost_per_minute", cfg.Transcription.CostPerMinute, "0"))
+		cfg.Transcription.CostPerMinute = 0
+	}
+	if c := cfg.Transcription.MinConfidence; c < 0 || c >= 1 {
+		warnings = append(warnings, l.T(i18n.ConfigMinConfidence, c))
+		cfg.Transcription.MinConfidence = 0
+	}
+	if cfg.Transcription.OfflineFallbackMaxMinutes <= 0 {
+		warnings = append(warnings, l.T(i18n.ConfigPositive, "transcription.offline_fallback_max_minutes", cfg.Transcription.OfflineFallbackMaxMinutes, 30))
+		cfg.Transcription.OfflineFallbackMaxMinutes = 30
+	}
+	if d, err := time.ParseDuration(cfg.Transcription.InterimInterval); err != nil || d < 0 || d > maxInterimInterval {
+		warnings = append(warnings, l.T(i18n.ConfigInterimInterval, cfg.Transcription.InterimInterval, maxInterimInterval))
+	}
+	if vad := &cfg.Transcription.VAD; vad.Enabled {
+		if vad.ThresholdDB >= 0 {
+			warnings = append(warnings, l.T(i18n.ConfigBelowZeroDB, "transcription.vad.threshold_db", vad.ThresholdDB, -45))
+			vad.ThresholdDB = -45
+		}
+		if d, err := time.ParseDuration(vad.PreRoll); err != nil || d < 0 {
+			warnings = append(warnings, l.T(i18n.ConfigInvalidDefault, "transcription.vad.pre_roll", vad.PreRoll, "500ms"))
+		}
+		if d, err := time.ParseDuration(vad.Hangover); err != nil || d <= 0 {
+			warnings = append(warnings, l.T(i18n.ConfigInvalidDefault, "transcription.vad.hangover", vad.Hangover, "2s"))
+		} else if ms, err := strconv.Atoi(cfg.Transcription.UtteranceEndMs); err == nil && d <= time.Duration(ms)*time.Millisecond {
+			warnings = append(warnings, l.T(i18n.ConfigVADHangover, d, ms))
+		}
+	}
+	if dsp := &cfg.DSP; dsp.Enabled {
+		if dsp.HighPassHz < 0 || dsp.HighPassHz >= 1000 {
+			warnings = append(warnings, l.T(i18n.ConfigHighPass, dsp.HighPassHz))
+			dsp.HighPassHz = 100
+		}
+		if dsp.NoiseGateDB > 0 {
+			warnings = append(warnings, l.T(i18n.ConfigBelowZeroDB, "dsp.noise_gate_db", dsp.NoiseGateDB, -55))
+			dsp.NoiseGateDB = -55
+		}
+		if dsp.AGCTargetDB >= 0 {
+			warnings = append(warnings, l.T(i18n.ConfigBelowZeroDB, "dsp.agc_target_db", dsp.AGCTargetDB, -20))
+			dsp.AGCTargetDB = -20
+		}
+		if dsp.AGCMaxGainDB < 0 {
+			warnings = append(warnings, l.T(i18n.ConfigNegative, "dsp.agc_max_gain_db", dsp.AGCMaxGainDB, "15"))
+			dsp.AGCMaxGainDB = 15
+		}
+	}
+	if cfg.Watch.Dir != "" {
+		if d, err := time.ParseDuration(cfg.Watch.Interval); err != nil || d <= 0 {
+			warnings = append(warnings, l.T(i18n.ConfigInvalidDefault, "watch.interval", cfg.Watch.Interval, "10s"))
+		}
+		if cfg.DeepgramAPIKey == "" {
+			warnings = append(warnings, l.T(i18n.ConfigWatchNeedsDeepgram, EnvPrefix+"DEEPGRAM_API_KEY"))
+			cfg.Watch.Dir = ""
+		}
+	}
+	switch cfg.TTS.Provider {
+	case "":
+	case TTSProviderOpenAI:
+		cfg.TTS.BaseURL = strings.TrimSpace(cfg.TTS.BaseURL)
+		if cfg.OpenAIAPIKey == "" && cfg.TTS.BaseURL == "" {
+			warnings = append(warnings, l.T(i18n.ConfigTTSNeedsOpenAI, EnvPrefix+"OPENAI_API_KEY"))
+			cfg.TTS.Provider = ""
+		}
+	case TTSProviderPiper:
+		if strings.TrimSpace(cfg.TTS.PiperModel) == "" {
+			warnings = append(warnings, l.T(i18n.ConfigTTSNeedsPiperModel))
+			cfg.TTS.Provider = ""
 		}
+	default:
+		warnings = append(warnings, l.T(i18n.ConfigTTSProvider, cfg.TTS.Provider, TTSProviderOpenAI+", "+TTSProviderPiper))
+		cfg.TTS.Provider = ""
 	}
 
-	return warnings
-}
+	if cfg.MQTT.Broker != "" && strings.TrimSpace(cfg.MQTT.TopicPrefix) == "" {
+		warnings = append(warnings, l.T(i18n.ConfigMQTTPrefix, "ghost-wispr"))
+		cfg.MQTT.TopicPrefix = "ghost-wispr"
+	}
+
+	if cfg.SpeakerRefinement.Command != "" {
+		if d, err := time.ParseDuration(cfg.SpeakerRefinement.Timeout); err != nil || d <= 0 {
+			warnings = append(warnings, l.T(i18n.ConfigInvalidDefault, "speaker_refinement.timeout", cfg.SpeakerRefinement.Timeout, "30m"))
+		}
+		if d, err := time.ParseDuration(cfg.SpeakerRefinement.Interval); err != nil || d <= 0 {
+			warnings = append(warnings, l.T(i18n.ConfigInvalidDefault, "speaker_refinement.interval", cfg.SpeakerRefinement.Interval, "1h"))
+		}
+	}
+
+	if cfg.VoiceProfiles.Model != "" {
+		if d, err := time.ParseDuration(cfg.VoiceProfiles.Timeout); err != nil || d <= 0 {
+			warnings = append(warnings, l.T(i18n.ConfigInvalidDefault, "voice_profiles.timeout", cfg.VoiceProfiles.Timeout, "5m"))
+		}
+		if t := cfg.VoiceProfiles.Threshold; t <= 0 || t >= 1 {
+			warnings = append(warnings, l.T(i18n.ConfigVoiceThreshold, t))
+			cfg.VoiceProfiles.Threshold = 0.75
+		}
+	}
+
+	if cfg.Series.LookbackWeeks < 0 {
+		warnings = append(warnings, l.T(i18n.ConfigSeriesNegative, cfg.Series.LookbackWeeks))
+		cfg.Series.LookbackWeeks = 0
+	}
+	if cfg.Series.LookbackWeeks > 0 {
+		if d, err := time.ParseDuration(cfg.Series.SlotTolerance); err != nil || d <= 0 {
+			warnings = append(warnings, l.T(i18n.ConfigInvalidDefault, "series.slot_tolerance", cfg.Series.SlotTolerance, "20m"))
+		}
+		if d, err := time.ParseDuration(cfg.Series.MinDuration); err != nil || d < 0 {
+			warnings = append(warnings, l.T(i18n.ConfigInvalidDefault, "series.min_duration", cfg.Series.MinDuration, "5m"))
+		}
+	}
+
+	if cfg.Classification.Enabled {
+		if d, err := time.ParseDuration(cfg.Classification.MinDuration); err != nil || d < 0 {
+			warnings = append(warnings, l.T(i18n.ConfigInvalidDefault, "classification.min_duration", cfg.Classification.MinDuration, "3m"))
+		}
+		if d, err := time.ParseDuration(cfg.Classification.CalendarSlack); err != nil || d < 0 {
+			warnings = append(warnings, l.T(i18n.ConfigInvalidDefault, "classification.calendar_slack", cfg.Classification.CalendarSlack, "10m"))
+		}
+		if cfg.Classification.MinSpeakers < 1 {
+			warnings = append(warnings, l.T(i18n.ConfigInvalidCountDefault, "classification.min_speakers", cfg.Classification.MinSpeakers, 2))
+			cfg.Classification.MinSpeakers = 2
+		}
+		for _, class := range []struct {
+			name   string
+			policy SessionPolicy
+		}{{"meeting", cfg.Classification.Meeting}, {"ambient", cfg.Classification.Ambient}} {
+			if d, err := time.ParseDuration(class.policy.Retention); class.policy.Retention != "" && (err != nil || d < 0) {
+				warnings = append(warnings, l.T(i18n.ConfigRetention, "classification."+class.name+".retention", class.policy.Retention))
+			}
+		}
+	}
+
+	if d, err := time.ParseDuration(cfg.Briefings.Lead); err != nil || d < 0 {
+		warnings = append(warnings, l.T(i18n.ConfigInvalidDefault, "briefings.lead", cfg.Briefings.Lead, "10m"))
+	}
+	if cfg.Briefings.LookbackDays <= 0 {
+		warnings = append(warnings, l.T(i18n.ConfigInvalidCountDefault, "briefings.lookback_days", cfg.Briefings.LookbackDays, 30))
+		cfg.Briefings.LookbackDays = 30
+	}
+	if cfg.Briefings.MaxSessions <= 0 {
+		warnings = append(warnings, l.T(i18n.ConfigInvalidCountDefault, "briefings.max_sessions", cfg.Briefings.MaxSessions, 5))
+		cfg.Briefings.MaxSessions = 5
+	}
+
+	var redactKinds []string
+	for _, kind := range cfg.Redaction.Kinds {
+		kind = strings.ToLower(strings.TrimSpace(kind))
+		switch {
+		case kind == "" || slices.Contains(redactKinds, kind):
+		case slices.Contains(redact.Kinds, kind):
+			redactKinds = append(redactKinds, kind)
+		default:
+			warnings = append(warnings, l.T(i18n.ConfigUnknownEntry, "redaction.kinds", kind, strings.Join(redact.Kinds, ", ")))
+		}
+	}
+	cfg.Redaction.Kinds = redactKinds
+	var deepgramRedact []string
+	for _, class := range cfg.Transcription.Redact {
+		if class = strings.ToLower(strings.TrimSpace(class)); class != "" && !slices.Contains(deepgramRedact, class) {
+			deepgramRedact = append(deepgramRedact, class)
+		}
+	}
+	cfg.Transcription.Redact = deepgramRedact
+
+	if cfg.Dictation.TypeCommand != "" {
+		if d, err := time.ParseDuration(cfg.Dictation.TypeTimeout); err != nil || d <= 0 {
+			warnings = append(warnings, l.T(i18n.ConfigInvalidDefault, "dictation.type_timeout", cfg.Dictation.TypeTimeout, "10s"))
+		}
+	}
+
+	for _, w := range []struct {
+		name string
+		n    *int
+	}{
+		{"summaries", &cfg.Workers.Summaries},
+		{"exports", &cfg.Workers.Exports},
+		{"backups", &cfg.Workers.Backups},
+	} {
+		if *w.n < 1 {
+			warnings = append(warnings, l.T(i18n.ConfigAtLeastOne, "workers."+w.name, *w.n))
+			*w.n = 1
+		}
+	}
 
-func parseSampleRates(raw string) []int {
-	parts := strings.Split(raw, ",")
-	seen := make(map[int]struct{}, len(parts))
-	result := make([]int, 0, len(parts))
+	hooks := cfg.Hooks[:0]
+	for i, h := range cfg.Hooks {
+		if strings.TrimSpace(h.Event) == "" || strings.TrimSpace(h.Command) == "" {
+			warnings = append(warnings, l.T(i18n.ConfigHookIncomplete, i))
+			continue
+		}
+		if h.Timeout != "" {
+			if d, err := time.ParseDuration(h.Timeout); err != nil || d <= 0 {
+				warnings = append(warnings, l.T(i18n.ConfigHookTimeout, h.Timeout, h.Event, h.Command))
+			}
+		}
+		hooks = append(hooks, h)
+	}
+	cfg.Hooks = hooks
+	warnings = append(warnings, validateExports(cfg, l)...)
 
-	for _, part := range parts {
-		trimmed := strings.TrimSpace(part)
-		if trimmed == "" {
+	configuredTokens := len(cfg.Auth.Tokens)
+	tokens := cfg.Auth.Tokens[:0]
+	for i, t := range cfg.Auth.Tokens {
+		switch {
+		case strings.TrimSpace(t.Name) == "" || t.TokenEnv == "":
+			warnings = append(warnings, l.T(i18n.ConfigTokenIncomplete, i))
+			continue
+		case t.Scope != TokenScopeRead && t.Scope != TokenScopeControl && t.Scope != TokenScopeAdmin:
+			warnings = append(warnings, l.T(i18n.ConfigTokenScope, t.Scope, t.Name, strings.Join([]string{TokenScopeRead, TokenScopeControl, TokenScopeAdmin}, ", ")))
+			continue
+		case t.Secret == "":
+			warnings = append(warnings, l.T(i18n.ConfigTokenUnset, t.Name, t.TokenEnv))
 			continue
 		}
-		rate, err := strconv.Atoi(trimmed)
-		if err != nil || rate <= 0 {
+		tokens = append(tokens, t)
+	}
+	cfg.Auth.Tokens = tokens
+	if configuredTokens > 0 && len(tokens) == 0 {
+		warnings = append(warnings, l.T(i18n.ConfigNoTokens))
+	}
+	proxies := cfg.Auth.TrustedProxies[:0]
+	for _, p := range cfg.Auth.TrustedProxies {
+		if _, ok := parseProxy(p); !ok {
+			warnings = append(warnings, l.T(i18n.ConfigTrustedProxy, p))
 			continue
 		}
-		if _, ok := seen[rate]; ok {
+		proxies = append(proxies, p)
+	}
+	cfg.Auth.TrustedProxies = proxies
+
+	for name, sc := range map[string]Script{"preset_router": cfg.Scripts.PresetRouter, "segment_processor": cfg.Scripts.SegmentProcessor} {
+		if sc.Module == "" || sc.Timeout == "" {
 			continue
 		}
-		seen[rate] = struct{}{}
-		result = append(result, rate)
+		if d, err := time.ParseDuration(sc.Timeout); err != nil || d <= 0 {
+			warnings = append(warnings, l.T(i18n.ConfigInvalidDefault, "scripts."+name+".timeout", sc.Timeout, "5s"))
+		}
+	}
+
+	if cfg.Updates.Enabled {
+		switch cfg.Updates.Channel {
+		case UpdateChannelStable, UpdateChannelPrerelease:
+		default:
+			warnings = append(warnings, l.T(i18n.ConfigInvalidChoice, "updates.channel", cfg.Updates.Channel, UpdateChannelStable+", "+UpdateChannelPrerelease, UpdateChannelStable))
+			cfg.Updates.Channel = UpdateChannelStable
+		}
+		if d, err := time.ParseDuration(cfg.Updates.Interval); err != nil || d <= 0 {
+			warnings = append(warnings, l.T(i18n.ConfigInvalidDefault, "updates.interval", cfg.Updates.Interval, "24h"))
+		}
+	}
+	if v := cfg.Transcription.UtteranceEndMs; v != "" {
+		if n, err := strconv.Atoi(v); err != nil || n < 0 {
+			warnings = append(warnings, l.T(i18n.ConfigInvalidMs, "transcription.utterance_end_ms", v))
+		}
 	}
 
-	return result
+	return warnings
+}
+
+func validateBudget(b *Budget, l *i18n.Localizer) []string {
+	var warnings []string
+	if b.MonthlyTranscriptionUSD < 0 {
+		warnings = append(warnings, l.T(i18n.ConfigBudgetNegative, "budget.monthly_transcription_usd", b.MonthlyTranscriptionUSD))
+		b.MonthlyTranscriptionUSD = 0
+	}
+	if b.MonthlyLLMUSD < 0 {
+		warnings = append(warnings, l.T(i18n.ConfigBudgetNegative, "budget.monthly_llm_usd", b.MonthlyLLMUSD))
+		b.MonthlyLLMUSD = 0
+	}
+	switch b.TranscriptionAction {
+	case BudgetActionWarn, BudgetActionPauseCapture:
+	default:
+		warnings = append(warnings, l.T(i18n.ConfigInvalidChoice, "budget.transcription_action", b.TranscriptionAction, BudgetActionPauseCapture+", "+BudgetActionWarn, BudgetActionPauseCapture))
+		b.TranscriptionAction = BudgetActionPauseCapture
+	}
+	switch b.LLMAction {
+	case BudgetActionWarn, BudgetActionCheaperModel, BudgetActionDisableSummaries:
+	default:
+		warnings = append(warnings, l.T(i18n.ConfigInvalidChoice, "budget.llm_action", b.LLMAction, strings.Join([]string{BudgetActionCheaperModel, BudgetActionDisableSummaries, BudgetActionWarn}, ", "), BudgetActionCheaperModel))
+		b.LLMAction = BudgetActionCheaperModel
+	}
+	return warnings
 }
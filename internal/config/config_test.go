@@ -1,9 +1,15 @@
 package config
 
 import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/netip"
 	"os"
 	"path/filepath"
 	"reflect"
+	"slices"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -13,7 +19,8 @@ func clearEnv(t *testing.T) {
 	t.Helper()
 	for _, key := range []string{
 		"DB_PATH", "AUDIO_DIR", "SILENCE_TIMEOUT",
-		"MIC_SAMPLE_RATE", "MIC_SAMPLE_RATES",
+		"MIC_SAMPLE_RATE", "MIC_DEVICE", "LOOPBACK_MODE", "CAPTURE_BACKEND", "UPDATE_CHANNEL", "TTS_PROVIDER", "MQTT_BROKER", "MQTT_PASSWORD", "SUMMARY_WORKERS", "APPLIANCE", "LOCALE",
+		"REDACTION_KINDS", "TRANSCRIPTION_REDACT", "TRANSCRIPTION_PROVIDERS", "ASSEMBLYAI_API_KEY",
 		"SUMMARIZATION_MODEL", "GDRIVE_FOLDER_ID", "GOOGLE_CREDENTIALS_FILE",
 		"DEEPGRAM_API_KEY", "OPENAI_API_KEY", "ANTHROPIC_API_KEY", "GEMINI_API_KEY", "CONFIG",
 	} {
@@ -38,8 +45,8 @@ func TestDefaults(t *testing.T) {
 	if cfg.SilenceTimeout != "30s" {
 		t.Fatalf("expected default silence_timeout, got %q", cfg.SilenceTimeout)
 	}
-	if cfg.MicSampleRate != 16000 {
-		t.Fatalf("expected default mic_sample_rate 16000, got %d", cfg.MicSampleRate)
+	if cfg.MicSampleRate != 0 {
+		t.Fatalf("expected default mic_sample_rate 0 (device native), got %d", cfg.MicSampleRate)
 	}
 	if cfg.Summarization.Model != "openai/gpt-4o-mini" {
 		t.Fatalf("expected default summarization.model, got %q", cfg.Summarization.Model)
@@ -51,12 +58,29 @@ func TestDefaults(t *testing.T) {
 	if preset.UserTemplate != "{{transcript}}" {
 		t.Fatalf("expected default preset user_template, got %q", preset.UserTemplate)
 	}
+	if !cfg.Summarization.ExtractDecisions {
+		t.Fatal("expected decision extraction enabled by default")
+	}
+	for _, model := range []string{cfg.Summarization.Model, "anthropic/claude-3-5-haiku-latest", "gemini/gemini-2.5-flash"} {
+		if cfg.Summarization.Pricing[model].InputPerMillion == 0 {
+			t.Fatalf("expected a built-in price for %s", model)
+		}
+	}
 	if cfg.Transcription.Endpointing != "400" {
 		t.Fatalf("expected default transcription.endpointing '400', got %q", cfg.Transcription.Endpointing)
 	}
 	if cfg.Transcription.UtteranceEndMs != "1000" {
 		t.Fatalf("expected default transcription.utterance_end_ms '1000', got %q", cfg.Transcription.UtteranceEndMs)
 	}
+	if !cfg.Transcription.StableSpeakers {
+		t.Fatal("expected stable speaker tracking enabled by default")
+	}
+	if !cfg.Reports.Weekly {
+		t.Fatal("expected weekly reports enabled by default")
+	}
+	if !cfg.Power.SuspendAware {
+		t.Fatal("expected sessions to end on suspend by default")
+	}
 }
 
 func TestYAMLLoading(t *testing.T) {
@@ -69,7 +93,6 @@ db_path: /custom/db.sqlite
 audio_dir: /custom/audio
 silence_timeout: 45s
 mic_sample_rate: 48000
-mic_sample_rates: [44100, 32000]
 summarization:
   model: anthropic/claude-3-5-sonnet-latest
   presets:
@@ -106,13 +129,11 @@ google_credentials_file: /path/to/creds.json
 	if cfg.MicSampleRate != 48000 {
 		t.Fatalf("expected yaml mic_sample_rate, got %d", cfg.MicSampleRate)
 	}
-	if !reflect.DeepEqual(cfg.MicSampleRates, []int{44100, 32000}) {
-		t.Fatalf("expected yaml mic_sample_rates, got %v", cfg.MicSampleRates)
-	}
 	if cfg.Summarization.Model != "anthropic/claude-3-5-sonnet-latest" {
 		t.Fatalf("expected yaml summarization.model, got %q", cfg.Summarization.Model)
 	}
-	if len(cfg.Summarization.Presets) != 2 {
+	// The two configured presets plus the built-in memo preset.
+	if len(cfg.Summarization.Presets) != 3 {
 		t.Fatalf("expected yaml summarization presets, got %#v", cfg.Summarization.Presets)
 	}
 	if cfg.Summarization.Presets["concise"].Model != "gemini/gemini-2.5-flash" {
@@ -317,94 +338,1347 @@ func TestInvalidSilenceTimeoutWarning(t *testing.T) {
 	}
 }
 
-func TestMissingConfigFileUsesDefaults(t *testing.T) {
+func TestInvalidBudgetWarnings(t *testing.T) {
 	clearEnv(t)
+	t.Setenv(EnvPrefix+"DEEPGRAM_API_KEY", "key")
+	t.Setenv(EnvPrefix+"OPENAI_API_KEY", "key")
 
-	cfg, _, err := Load("/nonexistent/path/config.yaml")
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+	yamlContent := `
+budget:
+  monthly_transcription_usd: -5
+  monthly_llm_usd: 10
+  llm_action: downgrade
+`
+	if err := os.WriteFile(configPath, []byte(yamlContent), 0o644); err != nil {
+		t.Fatalf("write config failed: %v", err)
+	}
+
+	cfg, warnings, err := Load(configPath)
 	if err != nil {
-		t.Fatalf("Load should not fail for missing config file, got: %v", err)
+		t.Fatalf("Load failed: %v", err)
 	}
 
-	if cfg.DBPath != "data/ghost-wispr.db" {
-		t.Fatalf("expected defaults when config file missing, got db_path=%q", cfg.DBPath)
+	if len(warnings) != 2 {
+		t.Fatalf("expected 2 budget warnings, got: %v", warnings)
+	}
+	if cfg.Budget.MonthlyTranscriptionUSD != 0 {
+		t.Fatalf("expected negative transcription budget to be disabled, got %v", cfg.Budget.MonthlyTranscriptionUSD)
+	}
+	if cfg.Budget.LLMAction != BudgetActionCheaperModel {
+		t.Fatalf("expected llm_action fallback to %q, got %q", BudgetActionCheaperModel, cfg.Budget.LLMAction)
+	}
+	if cfg.Budget.TranscriptionAction != BudgetActionPauseCapture {
+		t.Fatalf("expected default transcription_action, got %q", cfg.Budget.TranscriptionAction)
 	}
 }
 
-func TestInvalidConfigFileReturnsError(t *testing.T) {
+func TestRedactionKindsNormalized(t *testing.T) {
+	clearEnv(t)
+	t.Setenv(EnvPrefix+"DEEPGRAM_API_KEY", "key")
+	t.Setenv(EnvPrefix+"OPENAI_API_KEY", "key")
+	t.Setenv(EnvPrefix+"REDACTION_KINDS", " Email,phone,email,passport")
+	t.Setenv(EnvPrefix+"TRANSCRIPTION_REDACT", "PCI, ssn")
+
+	cfg, warnings, err := Load("")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "passport") {
+		t.Fatalf("expected a warning about the unknown kind, got: %v", warnings)
+	}
+	if !slices.Equal(cfg.Redaction.Kinds, []string{"email", "phone"}) {
+		t.Fatalf("expected normalized redaction kinds, got %q", cfg.Redaction.Kinds)
+	}
+	if !slices.Equal(cfg.Transcription.Redact, []string{"pci", "ssn"}) {
+		t.Fatalf("expected normalized Deepgram redaction, got %q", cfg.Transcription.Redact)
+	}
+}
+
+func TestTranscriptionProvidersNormalized(t *testing.T) {
+	clearEnv(t)
+	t.Setenv(EnvPrefix+"OPENAI_API_KEY", "key")
+	t.Setenv(EnvPrefix+"TRANSCRIPTION_PROVIDERS", "Deepgram, assemblyai,whisper,whisper,vosk")
+
+	cfg, warnings, err := Load("")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if !slices.Equal(cfg.Transcription.Providers, []string{"whisper"}) {
+		t.Fatalf("expected only whisper usable, got %q", cfg.Transcription.Providers)
+	}
+	for _, want := range []string{"vosk", "ASSEMBLYAI_API_KEY", "Skipping deepgram"} {
+		if !slices.ContainsFunc(warnings, func(w string) bool { return strings.Contains(w, want) }) {
+			t.Fatalf("expected a warning mentioning %q, got: %v", want, warnings)
+		}
+	}
+	if cfg.Transcription.Whisper.Model != "whisper-1" {
+		t.Fatalf("expected the default whisper model, got %q", cfg.Transcription.Whisper.Model)
+	}
+}
+
+func TestPresetReadingLevelValidation(t *testing.T) {
+	clearEnv(t)
+
+	path := filepath.Join(t.TempDir(), "ghost-wispr.yaml")
+	yamlContent := `
+summarization:
+  presets:
+    default:
+      user_template: "{{transcript}}"
+      reading_level: executive
+    kids:
+      user_template: "{{transcript}}"
+      reading_level: toddler
+`
+	if err := os.WriteFile(path, []byte(yamlContent), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, warnings, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if got := cfg.Summarization.Presets["default"].ReadingLevel; got != ReadingLevelExecutive {
+		t.Fatalf("expected executive reading level kept, got %q", got)
+	}
+	if got := cfg.Summarization.Presets["kids"].ReadingLevel; got != "" {
+		t.Fatalf("expected invalid reading level cleared, got %q", got)
+	}
+	if !slices.ContainsFunc(warnings, func(w string) bool { return strings.Contains(w, "presets.kids.reading_level") }) {
+		t.Fatalf("expected a reading_level warning, got %v", warnings)
+	}
+}
+
+func TestTranscriptionMinConfidence(t *testing.T) {
+	clearEnv(t)
+
+	for _, tc := range []struct {
+		value string
+		want  float64
+		warn  bool
+	}{
+		{"0.6", 0.6, false},
+		{"1", 0, true},
+		{"-0.2", 0, true},
+	} {
+		path := filepath.Join(t.TempDir(), "ghost-wispr.yaml")
+		if err := os.WriteFile(path, []byte("transcription:\n  min_confidence: "+tc.value+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		cfg, warnings, err := Load(path)
+		if err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+		if cfg.Transcription.MinConfidence != tc.want {
+			t.Fatalf("%s: expected %v, got %v", tc.value, tc.want, cfg.Transcription.MinConfidence)
+		}
+		warned := slices.ContainsFunc(warnings, func(w string) bool { return strings.Contains(w, "min_confidence") })
+		if warned != tc.warn {
+			t.Fatalf("%s: expected warning %v, got %v", tc.value, tc.warn, warnings)
+		}
+	}
+}
+
+func TestMicDevicesGainDefaults(t *testing.T) {
+	clearEnv(t)
+
 	dir := t.TempDir()
-	configPath := filepath.Join(dir, "bad.yaml")
-	if err := os.WriteFile(configPath, []byte(":::invalid yaml"), 0o644); err != nil {
-		t.Fatalf("write config: %v", err)
+	configPath := filepath.Join(dir, "config.yaml")
+	yamlContent := `
+mic_devices:
+  - name: Left
+  - name: Right
+    gain: 1.5
+  - name: Broken
+    gain: -2
+`
+	if err := os.WriteFile(configPath, []byte(yamlContent), 0o644); err != nil {
+		t.Fatalf("write config failed: %v", err)
+	}
+
+	cfg, warnings, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	gains := []float64{cfg.MicDevices[0].Gain, cfg.MicDevices[1].Gain, cfg.MicDevices[2].Gain}
+	if !reflect.DeepEqual(gains, []float64{1, 1.5, 1}) {
+		t.Fatalf("unexpected mic gains %v", gains)
+	}
+	found := false
+	for _, w := range warnings {
+		if strings.Contains(w, `"Broken"`) {
+			found = true
+		}
 	}
+	if !found {
+		t.Fatalf("expected warning for negative gain, got %v", warnings)
+	}
+}
 
+func TestLoopbackValidation(t *testing.T) {
 	clearEnv(t)
+	t.Setenv(EnvPrefix+"LOOPBACK_MODE", "both")
 
-	_, _, err := Load(configPath)
-	if err == nil {
-		t.Fatal("expected error for invalid yaml, got nil")
+	cfg, warnings, err := Load("")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Loopback.Mode != LoopbackModeOff || cfg.Loopback.Gain != 1 {
+		t.Fatalf("expected loopback off at unity gain, got %+v", cfg.Loopback)
+	}
+	found := false
+	for _, w := range warnings {
+		if strings.Contains(w, "loopback.mode") {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected loopback.mode warning, got %v", warnings)
+	}
+
+	t.Setenv(EnvPrefix+"LOOPBACK_MODE", LoopbackModeMix)
+	cfg, _, err = Load("")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Loopback.Mode != LoopbackModeMix {
+		t.Fatalf("expected loopback mode from env, got %q", cfg.Loopback.Mode)
 	}
 }
 
-func TestSampleRateCandidatesDefault(t *testing.T) {
-	cfg := defaults()
-	got := cfg.SampleRateCandidates()
-	want := []int{16000, 48000, 44100, 32000, 24000}
-	if !reflect.DeepEqual(got, want) {
-		t.Fatalf("unexpected default sample rates: got=%v want=%v", got, want)
+func TestMicDeviceFromEnv(t *testing.T) {
+	clearEnv(t)
+	t.Setenv(EnvPrefix+"MIC_DEVICE", "Blue Yeti")
+
+	cfg, warnings, err := Load("")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.MicDevice != "Blue Yeti" {
+		t.Fatalf("expected mic_device from env, got %q", cfg.MicDevice)
+	}
+	for _, w := range warnings {
+		if strings.Contains(w, "mic_device") {
+			t.Fatalf("unexpected mic_device warning: %s", w)
+		}
 	}
 }
 
-func TestSampleRateCandidatesCustom(t *testing.T) {
-	cfg := defaults()
-	cfg.MicSampleRate = 48000
-	cfg.MicSampleRates = []int{44100, 16000, 48000, 32000}
+func TestMicDevicePreferences(t *testing.T) {
+	clearEnv(t)
 
-	got := cfg.SampleRateCandidates()
-	want := []int{48000, 44100, 16000, 32000, 24000}
-	if !reflect.DeepEqual(got, want) {
-		t.Fatalf("unexpected custom sample rates: got=%v want=%v", got, want)
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+	yamlContent := `
+mic_device_preferences: ["Jabra", "", "MacBook Pro Microphone"]
+`
+	if err := os.WriteFile(configPath, []byte(yamlContent), 0o644); err != nil {
+		t.Fatalf("write config failed: %v", err)
+	}
+
+	cfg, warnings, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if !reflect.DeepEqual(cfg.MicDevicePreferences, []string{"Jabra", "MacBook Pro Microphone"}) {
+		t.Fatalf("unexpected mic_device_preferences %q", cfg.MicDevicePreferences)
+	}
+	for _, w := range warnings {
+		if strings.Contains(w, "mic_device_preferences") {
+			t.Fatalf("unexpected warning: %s", w)
+		}
+	}
+
+	t.Setenv(EnvPrefix+"CAPTURE_BACKEND", CaptureBackendPipeWire)
+	_, warnings, err = Load(configPath)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if !slices.ContainsFunc(warnings, func(w string) bool { return strings.Contains(w, "mic_device_preferences") }) {
+		t.Fatalf("expected mic_device_preferences ignored by pipewire, got %v", warnings)
 	}
 }
 
-func TestSampleRateCandidatesEnvOverride(t *testing.T) {
+func TestInvalidCaptureBackendFallsBack(t *testing.T) {
 	clearEnv(t)
-	t.Setenv(EnvPrefix+"MIC_SAMPLE_RATE", "48000")
-	t.Setenv(EnvPrefix+"MIC_SAMPLE_RATES", "44100,16000,48000,abc,32000")
+	t.Setenv(EnvPrefix+"CAPTURE_BACKEND", "jack")
 
-	cfg, _, err := Load("")
+	cfg, warnings, err := Load("")
 	if err != nil {
 		t.Fatalf("Load failed: %v", err)
 	}
+	if cfg.CaptureBackend != CaptureBackendPortAudio {
+		t.Fatalf("expected fallback to portaudio, got %q", cfg.CaptureBackend)
+	}
+	found := false
+	for _, w := range warnings {
+		if strings.Contains(w, "capture_backend") {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected capture_backend warning, got %v", warnings)
+	}
+}
+
+func TestInvalidUpdateChannelFallsBack(t *testing.T) {
+	clearEnv(t)
+	t.Setenv(EnvPrefix+"UPDATE_CHANNEL", "nightly")
+
+	path := filepath.Join(t.TempDir(), "ghost-wispr.yaml")
+	if err := os.WriteFile(path, []byte("updates:\n  enabled: true\n  interval: soon\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
 
-	got := cfg.SampleRateCandidates()
-	want := []int{48000, 44100, 16000, 32000, 24000}
-	if !reflect.DeepEqual(got, want) {
-		t.Fatalf("unexpected env sample rates: got=%v want=%v", got, want)
+	cfg, warnings, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Updates.Channel != UpdateChannelStable {
+		t.Fatalf("expected fallback to stable, got %q", cfg.Updates.Channel)
+	}
+	if got := cfg.ParsedUpdateInterval(); got != 24*time.Hour {
+		t.Fatalf("expected 24h fallback interval, got %v", got)
+	}
+	var channel, interval bool
+	for _, w := range warnings {
+		channel = channel || strings.Contains(w, "updates.channel")
+		interval = interval || strings.Contains(w, "updates.interval")
+	}
+	if !channel || !interval {
+		t.Fatalf("expected updates warnings, got %v", warnings)
 	}
 }
 
-func TestParseSampleRates(t *testing.T) {
-	got := parseSampleRates(" 16000,  ,invalid,0,-1,44100,16000 ")
-	want := []int{16000, 44100}
-	if !reflect.DeepEqual(got, want) {
-		t.Fatalf("unexpected parsed sample rates: got=%v want=%v", got, want)
+func TestHooksValidation(t *testing.T) {
+	clearEnv(t)
+
+	path := filepath.Join(t.TempDir(), "ghost-wispr.yaml")
+	yml := `hooks:
+  - event: summary_ready
+    command: /usr/local/bin/print-summary
+    args: ["--printer", "office"]
+    timeout: 2m
+  - event: session_ended
+  - event: session_ended
+    command: notify-send
+    timeout: never
+`
+	if err := os.WriteFile(path, []byte(yml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, warnings, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(cfg.Hooks) != 2 {
+		t.Fatalf("expected incomplete hook to be dropped, got %+v", cfg.Hooks)
+	}
+	if got := cfg.Hooks[0].ParsedTimeout(); got != 2*time.Minute {
+		t.Fatalf("expected 2m timeout, got %v", got)
+	}
+	if got := cfg.Hooks[1].ParsedTimeout(); got != 30*time.Second {
+		t.Fatalf("expected 30s fallback timeout, got %v", got)
+	}
+	var missing, timeout bool
+	for _, w := range warnings {
+		missing = missing || strings.Contains(w, "hooks[1]")
+		timeout = timeout || strings.Contains(w, `Invalid timeout "never"`)
+	}
+	if !missing || !timeout {
+		t.Fatalf("expected hook warnings, got %v", warnings)
 	}
 }
 
-func TestEnvOverrideSampleRate(t *testing.T) {
+func TestExportRulesValidation(t *testing.T) {
 	clearEnv(t)
-	t.Setenv(EnvPrefix+"MIC_SAMPLE_RATE", "48000")
-	t.Setenv(EnvPrefix+"MIC_SAMPLE_RATES", "44100,32000")
+	t.Setenv("TEAM_SLACK_WEBHOOK", "https://hooks.slack.com/services/T/B/x")
 
-	cfg, _, err := Load("")
+	path := filepath.Join(t.TempDir(), "ghost-wispr.yaml")
+	yml := `exports:
+  - name: notes
+    target: markdown
+    dir: /srv/notes
+    min_duration: 5m
+  - name: standups
+    target: slack
+    channel: "#standups"
+    webhook_url_env: TEAM_SLACK_WEBHOOK
+    presets: [standup]
+    tags: [meeting]
+    min_duration: soon
+  - name: notes
+    target: markdown
+    dir: /srv/other
+  - name: unset
+    target: slack
+    webhook_url_env: MISSING_SLACK_WEBHOOK
+  - name: drive
+    target: gdrive
+  - name: early
+    on: session_ended
+    target: markdown
+    dir: /srv/notes
+  - name: reports
+    on: weekly_report
+    target: markdown
+    dir: /srv/reports
+`
+	if err := os.WriteFile(path, []byte(yml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, warnings, err := Load(path)
 	if err != nil {
 		t.Fatalf("Load failed: %v", err)
 	}
+	if len(cfg.Exports) != 3 || cfg.Exports[2].On != ExportEventWeeklyReport {
+		t.Fatalf("expected three usable export rules, the last for reports, got %+v", cfg.Exports)
+	}
+	notes, standups := cfg.Exports[0], cfg.Exports[1]
+	if notes.On != ExportEventSummaryReady || notes.ParsedMinDuration() != 5*time.Minute {
+		t.Fatalf("unexpected notes rule %+v", notes)
+	}
+	if standups.WebhookURL != "https://hooks.slack.com/services/T/B/x" || standups.MinDuration != "" {
+		t.Fatalf("unexpected standups rule %+v", standups)
+	}
+	for _, want := range []string{`Duplicate export rule "notes"`, "set MISSING_SLACK_WEBHOOK", `"drive" needs a folder_id`, `Invalid event "session_ended"`, `Invalid min_duration "soon"`} {
+		if !slices.ContainsFunc(warnings, func(w string) bool { return strings.Contains(w, want) }) {
+			t.Errorf("expected warning containing %q, got %v", want, warnings)
+		}
+	}
+}
 
-	if cfg.MicSampleRate != 48000 {
-		t.Fatalf("expected env mic_sample_rate 48000, got %d", cfg.MicSampleRate)
+func TestAuthTokensValidation(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("WALL_TOKEN", "wall-secret")
+	t.Setenv("ADMIN_TOKEN", "admin-secret")
+
+	path := filepath.Join(t.TempDir(), "ghost-wispr.yaml")
+	yml := `auth:
+  access_log: data/access.log
+  tokens:
+    - name: wall-display
+      scope: read
+      token_env: WALL_TOKEN
+    - name: laptop
+      scope: admin
+      token_env: ADMIN_TOKEN
+    - name: unset
+      scope: control
+      token_env: UNSET_TOKEN
+    - name: typo
+      scope: root
+      token_env: ADMIN_TOKEN
+    - scope: read
+`
+	if err := os.WriteFile(path, []byte(yml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, warnings, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(cfg.Auth.Tokens) != 2 {
+		t.Fatalf("expected two usable tokens, got %+v", cfg.Auth.Tokens)
+	}
+	if got := cfg.Auth.Tokens[0]; got.Name != "wall-display" || got.Scope != TokenScopeRead || got.Secret != "wall-secret" {
+		t.Fatalf("unexpected first token: %+v", got)
+	}
+	if cfg.Auth.AccessLog != "data/access.log" {
+		t.Fatalf("expected access log path, got %q", cfg.Auth.AccessLog)
+	}
+	var unset, scope, incomplete bool
+	for _, w := range warnings {
+		unset = unset || strings.Contains(w, `API token "unset" is not set`)
+		scope = scope || strings.Contains(w, `Invalid scope "root"`)
+		incomplete = incomplete || strings.Contains(w, "auth.tokens[4]")
+	}
+	if !unset || !scope || !incomplete {
+		t.Fatalf("expected token warnings, got %v", warnings)
+	}
+}
+
+func TestParsedOfflineProbe(t *testing.T) {
+	cfg := Config{Summarization: Summarization{Model: "anthropic/claude-haiku", OfflineProbe: OfflineProbeAuto}}
+	if got := cfg.ParsedOfflineProbe(); got != "api.anthropic.com:443" {
+		t.Fatalf("expected the provider's API host, got %q", got)
+	}
+	cfg.Summarization.BaseURL = "http://127.0.0.1:11434/v1"
+	if got := cfg.ParsedOfflineProbe(); got != "127.0.0.1:11434" {
+		t.Fatalf("expected the base_url host, got %q", got)
+	}
+	cfg.Summarization.OfflineProbe = "gateway.lan:443"
+	if got := cfg.ParsedOfflineProbe(); got != "gateway.lan:443" {
+		t.Fatalf("expected an explicit probe kept, got %q", got)
+	}
+	cfg.Summarization.OfflineProbe = ""
+	if got := cfg.ParsedOfflineProbe(); got != "" {
+		t.Fatalf("expected queueing disabled, got %q", got)
+	}
+}
+
+func TestTrustedProxiesValidation(t *testing.T) {
+	clearEnv(t)
+
+	path := filepath.Join(t.TempDir(), "ghost-wispr.yaml")
+	yml := `auth:
+  trusted_proxies: ["10.1.2.3", "192.168.0.0/16", "proxy.lan"]
+`
+	if err := os.WriteFile(path, []byte(yml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, warnings, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	got := cfg.Auth.ParsedTrustedProxies()
+	want := []netip.Prefix{netip.MustParsePrefix("10.1.2.3/32"), netip.MustParsePrefix("192.168.0.0/16")}
+	if !slices.Equal(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	if !slices.ContainsFunc(warnings, func(w string) bool { return strings.Contains(w, `"proxy.lan"`) }) {
+		t.Fatalf("expected a warning for the host name, got %v", warnings)
 	}
-	if !reflect.DeepEqual(cfg.MicSampleRates, []int{44100, 32000}) {
-		t.Fatalf("expected env mic_sample_rates, got %v", cfg.MicSampleRates)
+}
+
+func TestScriptsConfig(t *testing.T) {
+	clearEnv(t)
+
+	path := filepath.Join(t.TempDir(), "ghost-wispr.yaml")
+	yml := `scripts:
+  preset_router:
+    module: route.wasm
+  segment_processor:
+    module: filter.wasm
+    timeout: slow
+    memory_limit_mb: 4
+`
+	if err := os.WriteFile(path, []byte(yml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, warnings, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Scripts.PresetRouter.Module != "route.wasm" || cfg.Scripts.PresetRouter.MemoryLimit() != 16<<20 {
+		t.Fatalf("unexpected preset router %+v", cfg.Scripts.PresetRouter)
+	}
+	if cfg.Scripts.SegmentProcessor.Module != "filter.wasm" || cfg.Scripts.SegmentProcessor.MemoryLimit() != 4<<20 {
+		t.Fatalf("unexpected segment processor %+v", cfg.Scripts.SegmentProcessor)
+	}
+	if got := cfg.Scripts.SegmentProcessor.ParsedTimeout(); got != 5*time.Second {
+		t.Fatalf("expected 5s fallback timeout, got %v", got)
+	}
+	found := false
+	for _, w := range warnings {
+		found = found || strings.Contains(w, "scripts.segment_processor.timeout")
+	}
+	if !found {
+		t.Fatalf("expected timeout warning, got %v", warnings)
+	}
+}
+
+func TestTTSProviderValidation(t *testing.T) {
+	cases := []struct {
+		provider string
+		openAI   string
+		want     string
+		warning  string
+	}{
+		{"openai", "sk-test", TTSProviderOpenAI, ""},
+		{"openai", "", "", "Summary audio needs an OpenAI API key"},
+		{"piper", "", "", "tts.piper_model"},
+		{"espeak", "", "", "Invalid tts.provider"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.provider, func(t *testing.T) {
+			clearEnv(t)
+			t.Setenv(EnvPrefix+"TTS_PROVIDER", tc.provider)
+			t.Setenv(EnvPrefix+"OPENAI_API_KEY", tc.openAI)
+
+			cfg, warnings, err := Load("")
+			if err != nil {
+				t.Fatalf("Load failed: %v", err)
+			}
+			if cfg.TTS.Provider != tc.want {
+				t.Fatalf("expected provider %q, got %q", tc.want, cfg.TTS.Provider)
+			}
+			if tc.warning == "" {
+				return
+			}
+			found := false
+			for _, w := range warnings {
+				found = found || strings.Contains(w, tc.warning)
+			}
+			if !found {
+				t.Fatalf("expected warning containing %q, got %v", tc.warning, warnings)
+			}
+		})
+	}
+}
+
+func TestMQTTFromEnv(t *testing.T) {
+	clearEnv(t)
+	t.Setenv(EnvPrefix+"MQTT_BROKER", "tcp://homeassistant.local:1883")
+	t.Setenv(EnvPrefix+"MQTT_PASSWORD", "hunter2")
+
+	cfg, _, err := Load("")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.MQTT.Broker != "tcp://homeassistant.local:1883" || cfg.MQTTPassword != "hunter2" {
+		t.Fatalf("unexpected mqtt config %+v (password %q)", cfg.MQTT, cfg.MQTTPassword)
+	}
+	if cfg.MQTT.TopicPrefix != "ghost-wispr" || cfg.MQTT.DiscoveryPrefix != "homeassistant" {
+		t.Fatalf("expected default prefixes, got %+v", cfg.MQTT)
+	}
+}
+
+func TestSpeakerRefinementDurations(t *testing.T) {
+	clearEnv(t)
+
+	path := filepath.Join(t.TempDir(), "ghost-wispr.yaml")
+	yml := "speaker_refinement:\n  command: diarize.py\n  interval: never\n"
+	if err := os.WriteFile(path, []byte(yml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, warnings, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if got := cfg.ParsedRefinementTimeout(); got != 30*time.Minute {
+		t.Fatalf("expected default 30m timeout, got %v", got)
+	}
+	if got := cfg.ParsedRefinementInterval(); got != time.Hour {
+		t.Fatalf("expected fallback 1h interval, got %v", got)
+	}
+	found := false
+	for _, w := range warnings {
+		found = found || strings.Contains(w, "speaker_refinement.interval")
+	}
+	if !found {
+		t.Fatalf("expected interval warning, got %v", warnings)
+	}
+}
+
+func TestVoiceProfilesValidation(t *testing.T) {
+	clearEnv(t)
+
+	path := filepath.Join(t.TempDir(), "ghost-wispr.yaml")
+	yml := "voice_profiles:\n  model: voices.onnx\n  threshold: 1.5\n"
+	if err := os.WriteFile(path, []byte(yml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, warnings, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.VoiceProfiles.Threshold != 0.75 {
+		t.Fatalf("expected threshold reset to 0.75, got %v", cfg.VoiceProfiles.Threshold)
+	}
+	if got := cfg.ParsedVoiceTimeout(); got != 5*time.Minute {
+		t.Fatalf("expected default 5m timeout, got %v", got)
+	}
+	found := false
+	for _, w := range warnings {
+		found = found || strings.Contains(w, "voice_profiles.threshold")
+	}
+	if !found {
+		t.Fatalf("expected threshold warning, got %v", warnings)
+	}
+}
+
+func TestSeriesDurations(t *testing.T) {
+	clearEnv(t)
+
+	path := filepath.Join(t.TempDir(), "ghost-wispr.yaml")
+	yml := "series:\n  slot_tolerance: soon\n  min_duration: 10m\n"
+	if err := os.WriteFile(path, []byte(yml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, warnings, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Series.LookbackWeeks != 4 {
+		t.Fatalf("expected default lookback of 4 weeks, got %d", cfg.Series.LookbackWeeks)
+	}
+	if got := cfg.ParsedSeriesSlotTolerance(); got != 20*time.Minute {
+		t.Fatalf("expected fallback 20m tolerance, got %v", got)
+	}
+	if got := cfg.ParsedSeriesMinDuration(); got != 10*time.Minute {
+		t.Fatalf("expected 10m min duration, got %v", got)
+	}
+	found := false
+	for _, w := range warnings {
+		found = found || strings.Contains(w, "series.slot_tolerance")
+	}
+	if !found {
+		t.Fatalf("expected slot tolerance warning, got %v", warnings)
+	}
+}
+
+func TestWorkersConfig(t *testing.T) {
+	clearEnv(t)
+	t.Setenv(EnvPrefix+"SUMMARY_WORKERS", "1")
+
+	path := filepath.Join(t.TempDir(), "ghost-wispr.yaml")
+	if err := os.WriteFile(path, []byte("workers:\n  exports: 0\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, warnings, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	want := Workers{Summaries: 1, Exports: 1, Backups: 1}
+	if cfg.Workers != want {
+		t.Fatalf("expected %+v, got %+v", want, cfg.Workers)
+	}
+	found := false
+	for _, w := range warnings {
+		found = found || strings.Contains(w, "workers.exports")
+	}
+	if !found {
+		t.Fatalf("expected workers.exports warning, got %v", warnings)
+	}
+}
+
+func TestApplianceProfileCapsResources(t *testing.T) {
+	clearEnv(t)
+	t.Setenv(EnvPrefix+"APPLIANCE", "true")
+
+	path := filepath.Join(t.TempDir(), "ghost-wispr.yaml")
+	if err := os.WriteFile(path, []byte("workers:\n  summaries: 4\nappliance:\n  buffer_minutes: 3\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, _, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if !cfg.Appliance.Enabled {
+		t.Fatal("expected appliance mode enabled from env")
+	}
+	if cfg.Transcription.OfflineFallbackMaxMinutes != 3 {
+		t.Fatalf("expected offline buffer capped to 3 minutes, got %d", cfg.Transcription.OfflineFallbackMaxMinutes)
+	}
+	if cfg.Workers != (Workers{Summaries: 1, Exports: 1, Backups: 1}) {
+		t.Fatalf("expected single workers, got %+v", cfg.Workers)
+	}
+	if cfg.ParsedApplianceCheckInterval() != 30*time.Second {
+		t.Fatalf("expected default check interval, got %v", cfg.ParsedApplianceCheckInterval())
+	}
+}
+
+func TestMissingConfigFileUsesDefaults(t *testing.T) {
+	clearEnv(t)
+
+	cfg, _, err := Load("/nonexistent/path/config.yaml")
+	if err != nil {
+		t.Fatalf("Load should not fail for missing config file, got: %v", err)
+	}
+
+	if cfg.DBPath != "data/ghost-wispr.db" {
+		t.Fatalf("expected defaults when config file missing, got db_path=%q", cfg.DBPath)
+	}
+}
+
+func TestInvalidConfigFileReturnsError(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "bad.yaml")
+	if err := os.WriteFile(configPath, []byte(":::invalid yaml"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	clearEnv(t)
+
+	_, _, err := Load(configPath)
+	if err == nil {
+		t.Fatal("expected error for invalid yaml, got nil")
+	}
+}
+
+func TestEnvOverrideSampleRate(t *testing.T) {
+	clearEnv(t)
+	t.Setenv(EnvPrefix+"MIC_SAMPLE_RATE", "48000")
+
+	cfg, _, err := Load("")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if cfg.MicSampleRate != 48000 {
+		t.Fatalf("expected env mic_sample_rate 48000, got %d", cfg.MicSampleRate)
+	}
+}
+
+func TestInterimInterval(t *testing.T) {
+	clearEnv(t)
+
+	cfg, _, err := Load("")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if got := cfg.ParsedInterimInterval(); got != 200*time.Millisecond {
+		t.Fatalf("expected a 200ms default, got %v", got)
+	}
+
+	path := filepath.Join(t.TempDir(), "ghost-wispr.yaml")
+	for yml, want := range map[string]time.Duration{
+		"transcription:\n  interim_interval: 0s\n":   0,
+		"transcription:\n  interim_interval: 1m\n":   200 * time.Millisecond,
+		"transcription:\n  interim_interval: fast\n": 200 * time.Millisecond,
+	} {
+		if err := os.WriteFile(path, []byte(yml), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		cfg, warnings, err := Load(path)
+		if err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+		if got := cfg.ParsedInterimInterval(); got != want {
+			t.Fatalf("%q: expected %v, got %v", yml, want, got)
+		}
+		warned := slices.ContainsFunc(warnings, func(w string) bool { return strings.Contains(w, "transcription.interim_interval") })
+		if warned != (want != 0) {
+			t.Fatalf("%q: unexpected warnings %v", yml, warnings)
+		}
+	}
+}
+
+func TestBriefingsValidation(t *testing.T) {
+	clearEnv(t)
+
+	path := filepath.Join(t.TempDir(), "ghost-wispr.yaml")
+	yml := "briefings:\n  lead: soon\n  lookback_days: -1\n  max_sessions: 3\n"
+	if err := os.WriteFile(path, []byte(yml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, warnings, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if got := cfg.ParsedBriefingLead(); got != 10*time.Minute {
+		t.Fatalf("expected fallback 10m lead, got %v", got)
+	}
+	if cfg.Briefings.LookbackDays != 30 || cfg.Briefings.MaxSessions != 3 {
+		t.Fatalf("unexpected briefings config %+v", cfg.Briefings)
+	}
+	leadWarned, lookbackWarned := false, false
+	for _, w := range warnings {
+		if strings.Contains(w, "briefings.lead") {
+			leadWarned = true
+		}
+		if strings.Contains(w, "briefings.lookback_days") {
+			lookbackWarned = true
+		}
+	}
+	if !leadWarned || !lookbackWarned {
+		t.Fatalf("expected briefings warnings, got %v", warnings)
+	}
+}
+
+func TestMemoPreset(t *testing.T) {
+	clearEnv(t)
+
+	path := filepath.Join(t.TempDir(), "ghost-wispr.yaml")
+	yml := "summarization:\n  presets:\n    standup:\n      system_prompt: Summarize the standup.\n      user_template: \"{{transcript}}\"\n"
+	if err := os.WriteFile(path, []byte(yml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, _, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if _, ok := cfg.Summarization.Presets["memo"]; !ok || cfg.Summarization.MemoPreset != "memo" {
+		t.Fatalf("expected the built-in memo preset alongside configured ones, got %v", cfg.Summarization.Presets)
+	}
+
+	yml = "summarization:\n  memo_preset: journal\n"
+	if err := os.WriteFile(path, []byte(yml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, warnings, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Summarization.MemoPreset != "default" {
+		t.Fatalf("expected fallback to the default preset, got %q", cfg.Summarization.MemoPreset)
+	}
+	found := false
+	for _, w := range warnings {
+		if strings.Contains(w, "memo_preset") {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected memo_preset warning, got %v", warnings)
+	}
+}
+
+func TestDictationConfig(t *testing.T) {
+	clearEnv(t)
+
+	path := filepath.Join(t.TempDir(), "ghost-wispr.yaml")
+	yml := "dictation:\n  active: true\n  type_command: wtype\n  type_args: [\"-\"]\n  type_timeout: never\n"
+	if err := os.WriteFile(path, []byte(yml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, warnings, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if !cfg.Dictation.Active || cfg.Dictation.TypeCommand != "wtype" || len(cfg.Dictation.TypeArgs) != 1 {
+		t.Fatalf("unexpected dictation config %+v", cfg.Dictation)
+	}
+	if got := cfg.ParsedDictationTypeTimeout(); got != 10*time.Second {
+		t.Fatalf("expected fallback 10s timeout, got %v", got)
+	}
+	found := false
+	for _, w := range warnings {
+		if strings.Contains(w, "dictation.type_timeout") {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected dictation.type_timeout warning, got %v", warnings)
+	}
+}
+
+func TestVADConfig(t *testing.T) {
+	clearEnv(t)
+
+	path := filepath.Join(t.TempDir(), "ghost-wispr.yaml")
+	yml := "transcription:\n  vad:\n    enabled: true\n    threshold_db: 3\n    pre_roll: 250ms\n    hangover: 500ms\n"
+	if err := os.WriteFile(path, []byte(yml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, warnings, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if !cfg.Transcription.VAD.Enabled || cfg.Transcription.VAD.ThresholdDB != -45 {
+		t.Fatalf("unexpected vad config %+v", cfg.Transcription.VAD)
+	}
+	if got := cfg.ParsedVADPreRoll(); got != 250*time.Millisecond {
+		t.Fatalf("expected 250ms pre-roll, got %v", got)
+	}
+	if got := cfg.ParsedVADHangover(); got != 500*time.Millisecond {
+		t.Fatalf("expected 500ms hangover, got %v", got)
+	}
+	var threshold, hangover bool
+	for _, w := range warnings {
+		threshold = threshold || strings.Contains(w, "vad.threshold_db")
+		hangover = hangover || strings.Contains(w, "vad.hangover")
+	}
+	if !threshold || !hangover {
+		t.Fatalf("expected threshold and hangover warnings, got %v", warnings)
+	}
+}
+
+func TestDSPConfig(t *testing.T) {
+	clearEnv(t)
+
+	path := filepath.Join(t.TempDir(), "ghost-wispr.yaml")
+	yml := "dsp:\n  enabled: true\n  high_pass_hz: 80\n  noise_gate_db: 6\n  agc: false\n"
+	if err := os.WriteFile(path, []byte(yml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, warnings, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	want := DSP{Enabled: true, HighPassHz: 80, NoiseGateDB: -55, AGCTargetDB: -20, AGCMaxGainDB: 15}
+	if cfg.DSP != want {
+		t.Fatalf("expected %+v, got %+v", want, cfg.DSP)
+	}
+	found := false
+	for _, w := range warnings {
+		found = found || strings.Contains(w, "dsp.noise_gate_db")
+	}
+	if !found {
+		t.Fatalf("expected a noise_gate_db warning, got %v", warnings)
+	}
+}
+
+func TestWatchConfig(t *testing.T) {
+	clearEnv(t)
+
+	path := filepath.Join(t.TempDir(), "ghost-wispr.yaml")
+	yml := "watch:\n  dir: /srv/sync/recordings\n  interval: soon\n"
+	if err := os.WriteFile(path, []byte(yml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, warnings, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Watch.Dir != "" {
+		t.Fatalf("expected the watch folder disabled without a Deepgram key, got %q", cfg.Watch.Dir)
+	}
+	var interval, key bool
+	for _, w := range warnings {
+		interval = interval || strings.Contains(w, "watch.interval")
+		key = key || strings.Contains(w, "DEEPGRAM_API_KEY")
+	}
+	if !interval || !key {
+		t.Fatalf("expected interval and API key warnings, got %v", warnings)
+	}
+
+	t.Setenv(EnvPrefix+"DEEPGRAM_API_KEY", "dg-test")
+	cfg, _, err = Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Watch.Dir != "/srv/sync/recordings" || cfg.ParsedWatchInterval() != 10*time.Second {
+		t.Fatalf("unexpected watch config %+v", cfg.Watch)
+	}
+}
+
+func TestSummaryDocumentConfig(t *testing.T) {
+	clearEnv(t)
+
+	path := filepath.Join(t.TempDir(), "ghost-wispr.yaml")
+	yml := "summarization:\n  document_template: \"# {{title}}\\n\\n{{summary}}\"\n  link_base_url: wispr.local\n  presets:\n    default:\n      system_prompt: s\n      user_template: \"{{transcript}}\"\n      document_template: \"{{summary}}\"\n"
+	if err := os.WriteFile(path, []byte(yml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, warnings, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Summarization.DocumentTemplate != "# {{title}}\n\n{{summary}}" || cfg.Summarization.Presets["default"].DocumentTemplate != "{{summary}}" {
+		t.Fatalf("unexpected document templates %+v", cfg.Summarization)
+	}
+	if cfg.Summarization.LinkBaseURL != "http://127.0.0.1:8080" {
+		t.Fatalf("expected the default link base URL, got %q", cfg.Summarization.LinkBaseURL)
+	}
+	found := false
+	for _, w := range warnings {
+		if strings.Contains(w, "summarization.link_base_url") {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected summarization.link_base_url warning, got %v", warnings)
+	}
+}
+
+func TestAudioEncoderConfig(t *testing.T) {
+	clearEnv(t)
+
+	path := filepath.Join(t.TempDir(), "ghost-wispr.yaml")
+	yml := "audio_encoder: opus\naudio_bitrate: 0\naudio_quality: 12\n"
+	if err := os.WriteFile(path, []byte(yml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, warnings, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.AudioEncoder != AudioEncoderAuto || cfg.AudioBitrate != 0 || cfg.AudioQuality != 4 {
+		t.Fatalf("unexpected encoder config %q %d %d", cfg.AudioEncoder, cfg.AudioBitrate, cfg.AudioQuality)
+	}
+	var encoder, quality bool
+	for _, w := range warnings {
+		encoder = encoder || strings.Contains(w, "audio_encoder")
+		quality = quality || strings.Contains(w, "audio_quality")
+	}
+	if !encoder || !quality {
+		t.Fatalf("expected audio_encoder and audio_quality warnings, got %v", warnings)
+	}
+}
+
+func TestAudioPreRollConfig(t *testing.T) {
+	clearEnv(t)
+
+	cfg, _, err := Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if got := cfg.ParsedAudioPreRoll(); got != 5*time.Second {
+		t.Fatalf("expected a 5s default pre-roll, got %v", got)
+	}
+
+	path := filepath.Join(t.TempDir(), "ghost-wispr.yaml")
+	if err := os.WriteFile(path, []byte("audio_pre_roll: -2s\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, warnings, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if got := cfg.ParsedAudioPreRoll(); got != 5*time.Second {
+		t.Fatalf("expected the default for a negative pre-roll, got %v", got)
+	}
+	found := false
+	for _, w := range warnings {
+		found = found || strings.Contains(w, "audio_pre_roll")
+	}
+	if !found {
+		t.Fatalf("expected an audio_pre_roll warning, got %v", warnings)
+	}
+}
+
+func TestLocaleConfig(t *testing.T) {
+	clearEnv(t)
+
+	path := filepath.Join(t.TempDir(), "ghost-wispr.yaml")
+	if err := os.WriteFile(path, []byte("locale: tlh\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, warnings, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Locale != "en" {
+		t.Fatalf("expected an unsupported locale replaced by en, got %q", cfg.Locale)
+	}
+	found := false
+	for _, w := range warnings {
+		found = found || strings.Contains(w, `Unsupported locale "tlh"`)
+	}
+	if !found {
+		t.Fatalf("expected a locale warning, got %v", warnings)
+	}
+
+	t.Setenv(EnvPrefix+"LOCALE", "de-AT")
+	cfg, _, err = Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Locale != "de-AT" {
+		t.Fatalf("expected the environment to override the locale, got %q", cfg.Locale)
+	}
+}
+
+func TestClassificationConfig(t *testing.T) {
+	clearEnv(t)
+
+	path := filepath.Join(t.TempDir(), "ghost-wispr.yaml")
+	yaml := "classification:\n  enabled: true\n  min_speakers: 0\n  ambient:\n    summarize: true\n    retention: soon\n"
+	if err := os.WriteFile(path, []byte(yaml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, warnings, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	cc := cfg.Classification
+	if !cc.Enabled || cc.MinSpeakers != 2 || cfg.ParsedClassificationMinDuration() != 3*time.Minute {
+		t.Fatalf("unexpected classification %+v", cc)
+	}
+	// Fields left out keep their defaults.
+	if !cc.Meeting.Summarize || !cc.Meeting.Notify || !cc.Ambient.Summarize || cc.Ambient.Notify {
+		t.Fatalf("unexpected policies %+v %+v", cc.Meeting, cc.Ambient)
+	}
+	if cc.Ambient.ParsedRetention() != 0 || cc.Meeting.ParsedRetention() != 0 {
+		t.Fatalf("expected invalid and empty retention to keep sessions forever")
+	}
+	for _, want := range []string{"classification.min_speakers 0", `classification.ambient.retention "soon"`} {
+		found := false
+		for _, w := range warnings {
+			found = found || strings.Contains(w, want)
+		}
+		if !found {
+			t.Fatalf("expected a warning about %s, got %v", want, warnings)
+		}
+	}
+}
+
+func TestMicChannelsConfig(t *testing.T) {
+	clearEnv(t)
+
+	path := filepath.Join(t.TempDir(), "ghost-wispr.yaml")
+	for _, tc := range []struct {
+		yaml, want, warning string
+	}{
+		{"mic_channels: separate\n", MicChannelsSeparate, ""},
+		{"mic_channels: stereo\n", MicChannelsMix, `Invalid mic_channels "stereo"`},
+		{"mic_channels: separate\nloopback:\n  mode: mix\n", MicChannelsMix, "can't be combined with loopback.mode"},
+	} {
+		if err := os.WriteFile(path, []byte(tc.yaml), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		cfg, warnings, err := Load(path)
+		if err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+		if cfg.MicChannels != tc.want {
+			t.Fatalf("%q: expected mic_channels %q, got %q", tc.yaml, tc.want, cfg.MicChannels)
+		}
+		found := tc.warning == ""
+		for _, w := range warnings {
+			found = found || strings.Contains(w, tc.warning)
+		}
+		if !found {
+			t.Fatalf("%q: expected a warning containing %q, got %v", tc.yaml, tc.warning, warnings)
+		}
+	}
+}
+
+func TestSchema(t *testing.T) {
+	schema := Schema()
+	props := schema["properties"].(map[string]any)
+
+	db := props["db_path"].(map[string]any)
+	if db["type"] != "string" || db["default"] != defaults().DBPath || db["x-env"] != EnvPrefix+"DB_PATH" || db["x-restart-required"] != true {
+		t.Fatalf("unexpected db_path schema: %v", db)
+	}
+	transcription := props["transcription"].(map[string]any)["properties"].(map[string]any)
+	if keywords := transcription["keywords"].(map[string]any); keywords["type"] != "array" || keywords["x-restart-required"] != false {
+		t.Fatalf("expected keywords changeable live, got %v", keywords)
+	}
+	hooks := props["hooks"].(map[string]any)
+	if items := hooks["items"].(map[string]any); items["type"] != "object" || items["properties"].(map[string]any)["command"] == nil {
+		t.Fatalf("expected hooks described as a list of objects, got %v", hooks)
+	}
+	presets := props["summarization"].(map[string]any)["properties"].(map[string]any)["presets"].(map[string]any)
+	if _, ok := presets["default"].(map[string]any)["memo"].(map[string]any)["system_prompt"]; !ok {
+		t.Fatalf("expected preset defaults keyed by yaml names, got %v", presets["default"])
+	}
+
+	secrets := schema["x-secrets"].([]Secret)
+	for _, want := range []Secret{
+		{Name: "deepgram_api_key", Env: EnvPrefix + "DEEPGRAM_API_KEY"},
+		{Name: "assemblyai_api_key", Env: EnvPrefix + "ASSEMBLYAI_API_KEY"},
+		{Name: "mqtt_password", Env: EnvPrefix + "MQTT_PASSWORD", RestartRequired: true},
+	} {
+		if !slices.Contains(secrets, want) {
+			t.Fatalf("expected %+v listed, got %+v", want, secrets)
+		}
+	}
+	for _, s := range secrets {
+		if _, ok := props[s.Name]; ok {
+			t.Fatalf("secret %s listed as a config file option", s.Name)
+		}
+	}
+}
+
+// TestEnvTagsAreApplied keeps the env tags the schema reports in step with
+// the variables Load actually reads.
+func TestEnvTagsAreApplied(t *testing.T) {
+	clearEnv(t)
+	cfg := defaults()
+	type tagged struct {
+		env   string
+		field reflect.Value
+		want  any
+	}
+	var fields []tagged
+	var walk func(v reflect.Value)
+	walk = func(v reflect.Value) {
+		for i := range v.NumField() {
+			f, sf := v.Field(i), v.Type().Field(i)
+			if f.Kind() == reflect.Struct {
+				walk(f)
+				continue
+			}
+			env := sf.Tag.Get("env")
+			if env == "" {
+				continue
+			}
+			tf := tagged{env: env, field: f}
+			switch f.Kind() {
+			case reflect.String:
+				tf.want = "from-env"
+				t.Setenv(EnvPrefix+env, "from-env")
+			case reflect.Bool:
+				tf.want = !f.Bool()
+				t.Setenv(EnvPrefix+env, strconv.FormatBool(!f.Bool()))
+			case reflect.Int:
+				tf.want = 7
+				t.Setenv(EnvPrefix+env, "7")
+			case reflect.Slice:
+				tf.want = []string{"a", "b"}
+				t.Setenv(EnvPrefix+env, "a,b")
+			default:
+				t.Fatalf("%s: unexpected kind %s", env, f.Kind())
+			}
+			fields = append(fields, tf)
+		}
+	}
+	walk(reflect.ValueOf(&cfg).Elem())
+
+	applyEnvOverrides(&cfg)
+	loadSecrets(&cfg)
+	for _, tf := range fields {
+		if got := tf.field.Interface(); !reflect.DeepEqual(got, tf.want) {
+			t.Errorf("%s%s: expected %v, got %v", EnvPrefix, tf.env, tf.want, got)
+		}
+	}
+}
+
+// TestEnvVariablesAreTagged checks the other direction: every variable Load
+// reads by name is an env tag, so the schema lists it.
+func TestEnvVariablesAreTagged(t *testing.T) {
+	tags := map[string]bool{}
+	var walk func(t reflect.Type)
+	walk = func(t reflect.Type) {
+		for i := range t.NumField() {
+			f := t.Field(i)
+			if f.Type.Kind() == reflect.Struct {
+				walk(f.Type)
+			} else if env := f.Tag.Get("env"); env != "" {
+				tags[env] = true
+			}
+		}
+	}
+	walk(reflect.TypeFor[Config]())
+
+	file, err := parser.ParseFile(token.NewFileSet(), "config.go", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	read := 0
+	ast.Inspect(file, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || len(call.Args) != 1 {
+			return true
+		}
+		if fn, ok := call.Fun.(*ast.SelectorExpr); !ok || fn.Sel.Name != "Getenv" && fn.Sel.Name != "LookupEnv" {
+			return true
+		}
+		// Variables named in the config, like tokens' secret_env, aren't
+		// fixed names and have no tag.
+		name, ok := call.Args[0].(*ast.BinaryExpr)
+		if !ok {
+			return true
+		}
+		if prefix, ok := name.X.(*ast.Ident); !ok || prefix.Name != "EnvPrefix" {
+			return true
+		}
+		lit, ok := name.Y.(*ast.BasicLit)
+		if !ok {
+			t.Error("Load reads a variable after EnvPrefix whose name isn't a literal")
+			return true
+		}
+		env, _ := strconv.Unquote(lit.Value)
+		read++
+		if !tags[env] {
+			t.Errorf("%s%s is read by Load but no field has env:%q", EnvPrefix, env, env)
+		}
+		return true
+	})
+	if read < len(tags)/2 {
+		t.Fatalf("found only %d variables read in config.go; has Load moved?", read)
 	}
 }
@@ -0,0 +1,130 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+)
+
+// SchemaDialect is the JSON Schema draft Schema follows.
+const SchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// Secret is a setting read only from the environment, never from the
+// config file.
+type Secret struct {
+	Name            string `json:"name"`
+	Env             string `json:"env"`
+	RestartRequired bool   `json:"restart_required"`
+}
+
+// Schema describes every config file option as a JSON Schema, generated
+// from Config and its defaults: each option's type and default, the
+// environment variable overriding it as x-env, and whether changing it
+// needs a restart as x-restart-required. Secrets, which only come from the
+// environment, are listed under x-secrets.
+func Schema() map[string]any {
+	schema := objectSchema(reflect.ValueOf(defaults()), true)
+	schema["$schema"] = SchemaDialect
+	schema["title"] = "Ghost Wispr configuration"
+
+	var secrets []Secret
+	t := reflect.TypeFor[Config]()
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if env := f.Tag.Get("env"); f.Tag.Get("yaml") == "-" && env != "" {
+			secrets = append(secrets, Secret{
+				Name:            strings.ToLower(env),
+				Env:             EnvPrefix + env,
+				RestartRequired: f.Tag.Get("live") != "true",
+			})
+		}
+	}
+	schema["x-secrets"] = secrets
+	return schema
+}
+
+// objectSchema describes the yaml fields of the struct v, each with its
+// value in v as its default when withDefaults is set.
+func objectSchema(v reflect.Value, withDefaults bool) map[string]any {
+	properties := make(map[string]any)
+	t := v.Type()
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name := f.Tag.Get("yaml")
+		if name == "" || name == "-" {
+			continue
+		}
+		var prop map[string]any
+		if f.Type.Kind() == reflect.Struct {
+			prop = objectSchema(v.Field(i), withDefaults)
+		} else {
+			prop = valueSchema(f.Type)
+			if d := plain(v.Field(i)); withDefaults && d != nil {
+				prop["default"] = d
+			}
+			prop["x-restart-required"] = f.Tag.Get("live") != "true"
+		}
+		if env := f.Tag.Get("env"); env != "" {
+			prop["x-env"] = EnvPrefix + env
+		}
+		properties[name] = prop
+	}
+	return map[string]any{"type": "object", "properties": properties, "additionalProperties": false}
+}
+
+// valueSchema describes a value of type t, without defaults.
+func valueSchema(t reflect.Type) map[string]any {
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice:
+		return map[string]any{"type": "array", "items": valueSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": valueSchema(t.Elem())}
+	case reflect.Struct:
+		return objectSchema(reflect.Zero(t), false)
+	}
+	return map[string]any{}
+}
+
+// plain converts v to what it looks like in the config file, keyed by yaml
+// names, for JSON. Empty lists and maps are nil, and given no default.
+func plain(v reflect.Value) any {
+	switch v.Kind() {
+	case reflect.Slice:
+		if v.Len() == 0 {
+			return nil
+		}
+		out := make([]any, v.Len())
+		for i := range out {
+			out[i] = plain(v.Index(i))
+		}
+		return out
+	case reflect.Map:
+		if v.Len() == 0 {
+			return nil
+		}
+		out := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out[iter.Key().String()] = plain(iter.Value())
+		}
+		return out
+	case reflect.Struct:
+		out := make(map[string]any)
+		t := v.Type()
+		for i := range t.NumField() {
+			if name := t.Field(i).Tag.Get("yaml"); name != "" && name != "-" {
+				out[name] = plain(v.Field(i))
+			}
+		}
+		return out
+	}
+	return v.Interface()
+}
@@ -0,0 +1,147 @@
+// Package diarize re-runs speaker diarization over archived session audio
+// with a local model and corrects the speaker labels Deepgram assigned live,
+// without re-transcribing anything.
+package diarize
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/transcribe"
+)
+
+// Turn is a span of audio attributed to one speaker, in seconds from the
+// start of the session audio.
+type Turn struct {
+	Start   float64 `json:"start"`
+	End     float64 `json:"end"`
+	Speaker string  `json:"speaker"`
+}
+
+// Diarizer finds speaker turns in an audio file.
+type Diarizer interface {
+	Diarize(ctx context.Context, audioPath string) ([]Turn, error)
+}
+
+// Command runs a local diarization program (for example a pyannote wrapper)
+// with the audio path appended to Args. It must print a JSON array of turns.
+type Command struct {
+	Command string
+	Args    []string
+	Timeout time.Duration
+}
+
+func (c Command) Diarize(ctx context.Context, audioPath string) ([]Turn, error) {
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+
+	args := append(append([]string{}, c.Args...), audioPath)
+	cmd := exec.CommandContext(ctx, c.Command, args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("diarizer timed out after %s", c.Timeout)
+		}
+		return nil, fmt.Errorf("diarizer: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	var turns []Turn
+	if err := json.Unmarshal(stdout.Bytes(), &turns); err != nil {
+		return nil, fmt.Errorf("decode diarizer output: %w", err)
+	}
+	return turns, nil
+}
+
+// Relabel assigns each segment the speaker of the turn it overlaps most.
+// Diarizer labels are mapped onto the existing numeric speakers by greatest
+// total overlap, so labels users already see stay put where the two agree;
+// labels with no counterpart get fresh numbers. Segments that overlap no turn
+// keep their speaker. It returns the new speakers and how many changed.
+func Relabel(segments []transcribe.Segment, turns []Turn) ([]int, int) {
+	best := make([]string, len(segments))
+	overlapByPair := map[string]map[int]float64{}
+	maxSpeaker := -1
+	for i, seg := range segments {
+		maxSpeaker = max(maxSpeaker, seg.Speaker)
+		var bestOverlap float64
+		for _, t := range turns {
+			o := min(seg.EndTime, t.End) - max(seg.StartTime, t.Start)
+			if o <= 0 {
+				continue
+			}
+			if overlapByPair[t.Speaker] == nil {
+				overlapByPair[t.Speaker] = map[int]float64{}
+			}
+			overlapByPair[t.Speaker][seg.Speaker] += o
+			if o > bestOverlap {
+				bestOverlap, best[i] = o, t.Speaker
+			}
+		}
+	}
+
+	type pair struct {
+		label   string
+		speaker int
+		overlap float64
+	}
+	var pairs []pair
+	for label, bySpeaker := range overlapByPair {
+		for speaker, o := range bySpeaker {
+			pairs = append(pairs, pair{label, speaker, o})
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].overlap != pairs[j].overlap {
+			return pairs[i].overlap > pairs[j].overlap
+		}
+		if pairs[i].label != pairs[j].label {
+			return pairs[i].label < pairs[j].label
+		}
+		return pairs[i].speaker < pairs[j].speaker
+	})
+
+	mapping := map[string]int{}
+	taken := map[int]bool{}
+	for _, p := range pairs {
+		if _, ok := mapping[p.label]; ok || taken[p.speaker] {
+			continue
+		}
+		mapping[p.label] = p.speaker
+		taken[p.speaker] = true
+	}
+	labels := make([]string, 0, len(overlapByPair))
+	for label := range overlapByPair {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	for _, label := range labels {
+		if _, ok := mapping[label]; !ok {
+			maxSpeaker++
+			mapping[label] = maxSpeaker
+		}
+	}
+
+	speakers := make([]int, len(segments))
+	changed := 0
+	for i, seg := range segments {
+		speakers[i] = seg.Speaker
+		if best[i] != "" {
+			speakers[i] = mapping[best[i]]
+		}
+		if speakers[i] != seg.Speaker {
+			changed++
+		}
+	}
+	return speakers, changed
+}
@@ -0,0 +1,134 @@
+package diarize
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+	"github.com/sjawhar/ghost-wispr/internal/transcribe"
+)
+
+func TestRelabelCorrectsFlipsAndKeepsLabels(t *testing.T) {
+	segments := []transcribe.Segment{
+		{Speaker: 0, StartTime: 0, EndTime: 4},
+		{Speaker: 1, StartTime: 4, EndTime: 8},
+		{Speaker: 1, StartTime: 8, EndTime: 10}, // flip: actually SPEAKER_A
+		{Speaker: 0, StartTime: 10, EndTime: 14},
+		{Speaker: 1, StartTime: 20, EndTime: 22}, // no turn covers it
+	}
+	turns := []Turn{
+		{Start: 0, End: 4, Speaker: "SPEAKER_A"},
+		{Start: 4, End: 8, Speaker: "SPEAKER_B"},
+		{Start: 8, End: 14, Speaker: "SPEAKER_A"},
+	}
+
+	speakers, changed := Relabel(segments, turns)
+	if want := []int{0, 1, 0, 0, 1}; !reflect.DeepEqual(speakers, want) {
+		t.Fatalf("expected %v, got %v", want, speakers)
+	}
+	if changed != 1 {
+		t.Fatalf("expected 1 change, got %d", changed)
+	}
+}
+
+func TestRelabelSplitsMergedSpeakers(t *testing.T) {
+	segments := []transcribe.Segment{
+		{Speaker: 0, StartTime: 0, EndTime: 5},
+		{Speaker: 0, StartTime: 5, EndTime: 7},
+	}
+	turns := []Turn{
+		{Start: 0, End: 5, Speaker: "A"},
+		{Start: 5, End: 7, Speaker: "B"},
+	}
+
+	speakers, changed := Relabel(segments, turns)
+	if want := []int{0, 1}; !reflect.DeepEqual(speakers, want) || changed != 1 {
+		t.Fatalf("expected %v with 1 change, got %v with %d", want, speakers, changed)
+	}
+}
+
+func TestCommandDiarize(t *testing.T) {
+	c := Command{Command: "sh", Args: []string{"-c", `echo "[{\"start\":0,\"end\":1.5,\"speaker\":\"$0\"}]"`}, Timeout: 5 * time.Second}
+	turns, err := c.Diarize(context.Background(), "s1.mp3")
+	if err != nil {
+		t.Fatalf("diarize: %v", err)
+	}
+	if len(turns) != 1 || turns[0].Speaker != "s1.mp3" || turns[0].End != 1.5 {
+		t.Fatalf("expected audio path passed as last arg, got %+v", turns)
+	}
+
+	failing := Command{Command: "sh", Args: []string{"-c", "echo no model >&2; exit 1"}}
+	if _, err := failing.Diarize(context.Background(), "s1.mp3"); err == nil || !strings.Contains(err.Error(), "no model") {
+		t.Fatalf("expected stderr in error, got %v", err)
+	}
+}
+
+type refineStoreStub struct {
+	segments []transcribe.Segment
+	relabels [][]int
+}
+
+func (s *refineStoreStub) GetSessionsForRefinement(int) ([]storage.Session, error) {
+	return nil, nil
+}
+
+func (s *refineStoreStub) AddRefineFailure(string) error {
+	return nil
+}
+
+func (s *refineStoreStub) GetSegments(string) ([]transcribe.Segment, error) {
+	return s.segments, nil
+}
+
+func (s *refineStoreStub) RelabelSegmentSpeakers(_ string, speakers []int) (int, error) {
+	s.relabels = append(s.relabels, speakers)
+	return 2, nil
+}
+
+type diarizerFunc func(string) ([]Turn, error)
+
+func (f diarizerFunc) Diarize(_ context.Context, path string) ([]Turn, error) { return f(path) }
+
+func TestRefineSession(t *testing.T) {
+	store := &refineStoreStub{segments: []transcribe.Segment{
+		{Speaker: 0, StartTime: 0, EndTime: 2},
+		{Speaker: 0, StartTime: 2, EndTime: 4},
+	}}
+	var gotPath string
+	refiner := NewRefiner(store, diarizerFunc(func(path string) ([]Turn, error) {
+		gotPath = path
+		return []Turn{{Start: 0, End: 2, Speaker: "A"}, {Start: 2, End: 4, Speaker: "B"}}, nil
+	}))
+
+	if err := refiner.RefineSession(context.Background(), storage.Session{ID: "s1", AudioPath: "data/audio/s1.mp3"}); err != nil {
+		t.Fatalf("RefineSession failed: %v", err)
+	}
+	if gotPath != "data/audio/s1.mp3" {
+		t.Fatalf("expected session audio to be diarized, got %q", gotPath)
+	}
+	if len(store.relabels) != 1 || !reflect.DeepEqual(store.relabels[0], []int{0, 1}) {
+		t.Fatalf("unexpected relabels %v", store.relabels)
+	}
+}
+
+func TestRefineSessionAlignsTurnsWithAudioOffset(t *testing.T) {
+	// A later session in a run: its recording starts 100s into the capture
+	// timeline its segments are timed on.
+	store := &refineStoreStub{segments: []transcribe.Segment{
+		{Speaker: 0, StartTime: 100, EndTime: 103},
+		{Speaker: 0, StartTime: 103, EndTime: 104},
+	}}
+	refiner := NewRefiner(store, diarizerFunc(func(string) ([]Turn, error) {
+		return []Turn{{Start: 0, End: 3, Speaker: "A"}, {Start: 3, End: 4, Speaker: "B"}}, nil
+	}))
+
+	if err := refiner.RefineSession(context.Background(), storage.Session{ID: "s2", AudioPath: "s2.mp3", AudioOffset: 100}); err != nil {
+		t.Fatalf("RefineSession failed: %v", err)
+	}
+	if len(store.relabels) != 1 || !reflect.DeepEqual(store.relabels[0], []int{0, 1}) {
+		t.Fatalf("expected turns shifted onto the segment timeline, got %v", store.relabels)
+	}
+}
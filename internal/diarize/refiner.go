@@ -0,0 +1,85 @@
+package diarize
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+	"github.com/sjawhar/ghost-wispr/internal/transcribe"
+)
+
+// Store is the storage the refiner reads and rewrites.
+type Store interface {
+	GetSessionsForRefinement(limit int) ([]storage.Session, error)
+	AddRefineFailure(sessionID string) error
+	GetSegments(sessionID string) ([]transcribe.Segment, error)
+	RelabelSegmentSpeakers(sessionID string, speakers []int) (int, error)
+}
+
+// Refiner works through archived sessions that have not been refined yet.
+type Refiner struct {
+	store    Store
+	diarizer Diarizer
+}
+
+func NewRefiner(store Store, diarizer Diarizer) *Refiner {
+	return &Refiner{store: store, diarizer: diarizer}
+}
+
+// RefineSession diarizes a session's audio and relabels its segments.
+func (r *Refiner) RefineSession(ctx context.Context, sess storage.Session) error {
+	segments, err := r.store.GetSegments(sess.ID)
+	if err != nil {
+		return fmt.Errorf("get segments: %w", err)
+	}
+	turns, err := r.diarizer.Diarize(ctx, sess.AudioPath)
+	if err != nil {
+		return err
+	}
+	// Turns are timed from the start of the recording, which is AudioOffset
+	// into the capture timeline segments are timed on.
+	for i := range turns {
+		turns[i].Start += sess.AudioOffset
+		turns[i].End += sess.AudioOffset
+	}
+
+	speakers, changed := Relabel(segments, turns)
+	version, err := r.store.RelabelSegmentSpeakers(sess.ID, speakers)
+	if err != nil {
+		return err
+	}
+	slog.Info("refined session speakers", "session", sess.ID, "changed", changed, "segments", len(segments), "version", version)
+	return nil
+}
+
+// Run refines pending sessions one at a time, then waits interval before
+// looking again, until ctx is cancelled. A session that fails is retried on
+// later passes, after those not yet tried, until it has failed
+// storage.MaxRefineFailures times.
+func (r *Refiner) Run(ctx context.Context, interval time.Duration) {
+	for {
+		sessions, err := r.store.GetSessionsForRefinement(20)
+		if err != nil {
+			slog.Warn("list sessions for refinement failed", "error", err)
+		}
+		for _, sess := range sessions {
+			if ctx.Err() != nil {
+				return
+			}
+			if err := r.RefineSession(ctx, sess); err != nil {
+				slog.Warn("speaker refinement failed", "session", sess.ID, "attempt", sess.RefineFailures+1, "error", err)
+				if err := r.store.AddRefineFailure(sess.ID); err != nil {
+					slog.Warn("recording refinement failure failed", "session", sess.ID, "error", err)
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
@@ -0,0 +1,145 @@
+// Package dictation turns ghost-wispr into a dictation tool: while it is on,
+// each finalized transcript segment is streamed to listeners, such as a
+// clipboard bridge reading the live text endpoint, and optionally typed into
+// the focused window by a local input-injection helper (wtype, xdotool,
+// ydotool) that receives the text on stdin.
+package dictation
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Typer is an input-injection helper run once per dictated segment with the
+// text on stdin. An empty Command disables typing.
+type Typer struct {
+	Command string
+	Args    []string
+	Timeout time.Duration
+}
+
+// typeQueueSize bounds how much text may wait for a slow helper before new
+// segments are dropped rather than typed late.
+const typeQueueSize = 32
+
+// Dictation fans finalized text out to subscribers and the typer while on.
+type Dictation struct {
+	typer Typer
+	queue chan string
+
+	mu          sync.Mutex
+	active      bool
+	subscribers map[chan string]struct{}
+}
+
+func New(typer Typer) *Dictation {
+	return &Dictation{
+		typer:       typer,
+		queue:       make(chan string, typeQueueSize),
+		subscribers: make(map[chan string]struct{}),
+	}
+}
+
+// SetActive turns dictation on or off.
+func (d *Dictation) SetActive(active bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.active = active
+}
+
+// Active reports whether dictation is on.
+func (d *Dictation) Active() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.active
+}
+
+// Dictate emits one finalized segment's text. It is a no-op while dictation
+// is off, and never blocks: slow subscribers and a backed-up typer miss
+// text rather than stalling transcription.
+func (d *Dictation) Dictate(text string) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.active {
+		return
+	}
+	for ch := range d.subscribers {
+		select {
+		case ch <- text:
+		default:
+		}
+	}
+	if d.typer.Command != "" {
+		select {
+		case d.queue <- text:
+		default:
+			slog.Warn("dictation typer backed up, dropping text")
+		}
+	}
+}
+
+// Subscribe returns a channel receiving each dictated segment and a function
+// to stop receiving.
+func (d *Dictation) Subscribe() (<-chan string, func()) {
+	ch := make(chan string, 64)
+	d.mu.Lock()
+	d.subscribers[ch] = struct{}{}
+	d.mu.Unlock()
+	return ch, func() {
+		d.mu.Lock()
+		delete(d.subscribers, ch)
+		d.mu.Unlock()
+	}
+}
+
+// Run types queued text with the helper, one segment at a time so words land
+// in order, until ctx is cancelled. Each segment after the first is preceded
+// by a space so consecutive segments don't run together.
+func (d *Dictation) Run(ctx context.Context) {
+	first := true
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case text := <-d.queue:
+			if !first {
+				text = " " + text
+			}
+			first = false
+			if err := d.typeText(ctx, text); err != nil {
+				slog.Warn("dictation typer failed", "command", d.typer.Command, "error", err)
+			}
+		}
+	}
+}
+
+func (d *Dictation) typeText(ctx context.Context, text string) error {
+	if d.typer.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d.typer.Timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, d.typer.Command, d.typer.Args...)
+	cmd.Stdin = strings.NewReader(text)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package dictation
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDictateOnlyWhileActive(t *testing.T) {
+	d := New(Typer{})
+	ch, unsubscribe := d.Subscribe()
+	defer unsubscribe()
+
+	d.Dictate("before")
+	d.SetActive(true)
+	d.Dictate("  hello world  ")
+	d.Dictate(" ")
+	d.SetActive(false)
+	d.Dictate("after")
+
+	select {
+	case got := <-ch:
+		if got != "hello world" {
+			t.Fatalf("unexpected text %q", got)
+		}
+	default:
+		t.Fatal("expected dictated text")
+	}
+	select {
+	case got := <-ch:
+		t.Fatalf("expected nothing else, got %q", got)
+	default:
+	}
+
+	unsubscribe()
+	d.SetActive(true)
+	d.Dictate("unheard")
+	if len(ch) != 0 {
+		t.Fatal("expected no text after unsubscribing")
+	}
+}
+
+func TestRunTypesSegmentsInOrder(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "typed")
+	d := New(Typer{Command: "sh", Args: []string{"-c", `cat >> "$0"`, out}, Timeout: 5 * time.Second})
+	d.SetActive(true)
+	d.Dictate("Dear team,")
+	d.Dictate("the launch moved.")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go d.Run(ctx)
+
+	want := "Dear team, the launch moved."
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		got, _ := os.ReadFile(out)
+		if string(got) == want {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %q typed, got %q", want, got)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
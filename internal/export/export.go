@@ -0,0 +1,299 @@
+// Package export delivers sessions to where configured export rules send
+// them — a notes folder, a Slack channel, a Drive folder — once their
+// summaries are ready, recording how each delivery went per session and
+// rule so failures can be seen and retried.
+package export
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/config"
+	"github.com/sjawhar/ghost-wispr/internal/jobs"
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+	"github.com/sjawhar/ghost-wispr/internal/summary"
+)
+
+// ErrUnknownRule is returned by Retry for a rule that isn't configured.
+var ErrUnknownRule = errors.New("unknown export rule")
+
+// Document is what a rule delivers: the session's summary, wrapped in the
+// rule's template.
+type Document struct {
+	Title string
+	Body  string
+}
+
+// Target delivers documents somewhere. previous is where the last
+// delivery of the same session landed, or "" if there was none, so a
+// target can update it in place. Export returns where this one landed.
+type Target interface {
+	Export(ctx context.Context, sess storage.Session, doc Document, previous string) (string, error)
+}
+
+// Rule sends sessions that pass its filters to Target on event On. Empty
+// filters let every session through.
+type Rule struct {
+	Name string
+	On   string
+	// Kind names the target in export statuses, e.g. "slack".
+	Kind     string
+	Target   Target
+	Renderer *summary.DocumentRenderer
+	Presets  []string
+	Tags     []string
+	// MinDuration skips sessions shorter than it, and unfinished ones.
+	MinDuration time.Duration
+}
+
+// Matches reports whether sess passes the rule's filters.
+func (r Rule) Matches(sess storage.Session) bool {
+	if len(r.Presets) > 0 && !slices.Contains(r.Presets, sess.SummaryPreset) {
+		return false
+	}
+	if len(r.Tags) > 0 && !slices.ContainsFunc(Tags(sess), func(tag string) bool { return slices.Contains(r.Tags, tag) }) {
+		return false
+	}
+	if r.MinDuration > 0 && (sess.EndedAt == nil || sess.EndedAt.Sub(sess.StartedAt) < r.MinDuration) {
+		return false
+	}
+	return true
+}
+
+// Tags are what rules filter sessions on: their kind (meeting, ambient or
+// memo) and "recurring" for those in a series.
+func Tags(sess storage.Session) []string {
+	var tags []string
+	if sess.Kind != "" {
+		tags = append(tags, sess.Kind)
+	}
+	if sess.SeriesID != "" {
+		tags = append(tags, "recurring")
+	}
+	return tags
+}
+
+// Store is the session storage Pipeline reads from and records export
+// statuses in.
+type Store interface {
+	GetSession(id string) (storage.Session, error)
+	SetExport(e storage.Export) error
+	GetExports(sessionID string) ([]storage.Export, error)
+	GetExportsByStatus(status string) ([]storage.Export, error)
+}
+
+// Pipeline runs export rules as summaries are written, on the export
+// worker pool.
+type Pipeline struct {
+	rules []Rule
+	store Store
+	pool  *jobs.Pool
+	now   func() time.Time
+	wg    sync.WaitGroup
+
+	mu sync.Mutex
+	// ctx is Start's, which exports run under; until Start, exports are
+	// only recorded as pending.
+	ctx context.Context
+}
+
+// NewPipeline runs rules against sessions in store, at most as many at a
+// time as pool admits; a nil pool doesn't limit them.
+func NewPipeline(rules []Rule, store Store, pool *jobs.Pool) *Pipeline {
+	return &Pipeline{rules: rules, store: store, pool: pool, now: time.Now}
+}
+
+// Start runs exports from now on under ctx, beginning with those a
+// previous run left pending or running and those queued before Start.
+func (p *Pipeline) Start(ctx context.Context) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.ctx = ctx
+	p.resume(ctx)
+}
+
+// SummaryReady queues the exports of the summary_ready rules whose filters
+// the session passes. The session manager calls it once the session's
+// summary is stored, so exports don't hang on an event being delivered.
+func (p *Pipeline) SummaryReady(sessionID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var sess *storage.Session
+	for _, r := range p.rules {
+		if r.On != config.ExportEventSummaryReady {
+			continue
+		}
+		if sess == nil {
+			s, err := p.store.GetSession(sessionID)
+			if err != nil {
+				slog.Warn("loading session to export failed", "session", sessionID, "error", err)
+				return
+			}
+			sess = &s
+		}
+		if r.Matches(*sess) {
+			p.queue(p.ctx, r, sess.ID)
+		}
+	}
+}
+
+// ExportReport delivers a weekly report through each weekly_report rule,
+// on the export worker pool. A report isn't a session, so the rules'
+// filters and templates don't apply, and how each delivery went is only
+// logged.
+func (p *Pipeline) ExportReport(rep storage.Report) {
+	p.mu.Lock()
+	ctx := p.ctx
+	p.mu.Unlock()
+	// Its first line is the report's title.
+	title, _, _ := strings.Cut(rep.Text, "\n")
+	doc := Document{Title: title, Body: rep.Text}
+	// Targets name what they write after the session ID.
+	sess := storage.Session{ID: "report-" + rep.ID, StartedAt: rep.PeriodStart}
+	for _, r := range p.rules {
+		if r.On != config.ExportEventWeeklyReport {
+			continue
+		}
+		if ctx == nil {
+			slog.Warn("report exported before the export pipeline started", "rule", r.Name, "report", rep.ID)
+			continue
+		}
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			_ = p.pool.Do(ctx, func() error {
+				location, err := r.Target.Export(ctx, sess, doc, "")
+				if err != nil {
+					slog.Warn("exporting report failed", "rule", r.Name, "report", rep.ID, "error", err)
+				} else {
+					slog.Info("exported report", "rule", r.Name, "report", rep.ID, "location", location)
+				}
+				return nil
+			})
+		}()
+	}
+}
+
+// Retry exports a session with the named rule again, whether or not it
+// passes the rule's filters.
+func (p *Pipeline) Retry(sessionID, rule string) error {
+	i := slices.IndexFunc(p.rules, func(r Rule) bool { return r.Name == rule })
+	if i < 0 {
+		return fmt.Errorf("%w: %q", ErrUnknownRule, rule)
+	}
+	if _, err := p.store.GetSession(sessionID); err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.queue(p.ctx, p.rules[i], sessionID)
+	return nil
+}
+
+// Exports returns how a session's exports went, by rule name.
+func (p *Pipeline) Exports(sessionID string) ([]storage.Export, error) {
+	return p.store.GetExports(sessionID)
+}
+
+// Wait blocks until all queued exports have finished.
+func (p *Pipeline) Wait() {
+	p.wg.Wait()
+}
+
+// resume queues the exports that were waiting or running when the last
+// run stopped, for the rules still configured.
+func (p *Pipeline) resume(ctx context.Context) {
+	var unfinished []storage.Export
+	for _, status := range []string{storage.ExportRunning, storage.ExportPending} {
+		exports, err := p.store.GetExportsByStatus(status)
+		if err != nil {
+			slog.Warn("listing unfinished exports failed", "status", status, "error", err)
+			continue
+		}
+		unfinished = append(unfinished, exports...)
+	}
+	for _, e := range unfinished {
+		if i := slices.IndexFunc(p.rules, func(r Rule) bool { return r.Name == e.Rule }); i >= 0 {
+			p.queue(ctx, p.rules[i], e.SessionID)
+		}
+	}
+}
+
+// queue records the export as pending and runs it once a worker is free.
+// With no ctx yet, or once ctx is cancelled, it is left pending for Start
+// to pick up.
+func (p *Pipeline) queue(ctx context.Context, r Rule, sessionID string) {
+	last := p.last(sessionID, r.Name)
+	p.record(storage.Export{SessionID: sessionID, Rule: r.Name, Target: r.Kind, Status: storage.ExportPending, Location: last.Location, Attempts: last.Attempts})
+	if ctx == nil {
+		return
+	}
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		_ = p.pool.Do(ctx, func() error {
+			p.run(ctx, r, sessionID)
+			return nil
+		})
+	}()
+}
+
+func (p *Pipeline) run(ctx context.Context, r Rule, sessionID string) {
+	last := p.last(sessionID, r.Name)
+	e := storage.Export{SessionID: sessionID, Rule: r.Name, Target: r.Kind, Status: storage.ExportRunning, Location: last.Location, Attempts: last.Attempts + 1}
+	p.record(e)
+
+	location, err := p.export(ctx, r, sessionID, last.Location)
+	if err != nil {
+		e.Status, e.Error = storage.ExportFailed, err.Error()
+		slog.Warn("export failed", "rule", r.Name, "session", sessionID, "error", err)
+	} else {
+		e.Status, e.Location = storage.ExportCompleted, location
+		slog.Info("exported session", "rule", r.Name, "session", sessionID, "location", location)
+	}
+	p.record(e)
+}
+
+func (p *Pipeline) export(ctx context.Context, r Rule, sessionID, previous string) (string, error) {
+	sess, err := p.store.GetSession(sessionID)
+	if err != nil {
+		return "", fmt.Errorf("load session: %w", err)
+	}
+	body := r.Renderer.Render(sess.Summary, summary.Document{
+		SessionID: sess.ID,
+		Preset:    sess.SummaryPreset,
+		StartedAt: sess.StartedAt,
+		EndedAt:   sess.EndedAt,
+	})
+	if body == "" {
+		return "", errors.New("session has no summary")
+	}
+	doc := Document{Title: summary.Title(sess.Summary, sess.StartedAt.Local(), r.Renderer.Localizer), Body: body}
+	return r.Target.Export(ctx, sess, doc, previous)
+}
+
+// last returns what was recorded for the session's last export with rule.
+func (p *Pipeline) last(sessionID, rule string) storage.Export {
+	exports, err := p.store.GetExports(sessionID)
+	if err != nil {
+		return storage.Export{}
+	}
+	if i := slices.IndexFunc(exports, func(e storage.Export) bool { return e.Rule == rule }); i >= 0 {
+		return exports[i]
+	}
+	return storage.Export{}
+}
+
+func (p *Pipeline) record(e storage.Export) {
+	e.UpdatedAt = p.now().UTC()
+	if err := p.store.SetExport(e); err != nil {
+		slog.Warn("recording export status failed", "rule", e.Rule, "session", e.SessionID, "status", e.Status, "error", err)
+	}
+}
@@ -0,0 +1,224 @@
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/config"
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+	"github.com/sjawhar/ghost-wispr/internal/summary"
+)
+
+type docsStub struct {
+	mu       sync.Mutex
+	created  []string
+	replaced []string
+	fail     error
+}
+
+func (d *docsStub) CreateDoc(_ context.Context, title, _ string) (string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.fail != nil {
+		return "", d.fail
+	}
+	d.created = append(d.created, title)
+	return "doc-1", nil
+}
+
+func (d *docsStub) ReplaceDoc(_ context.Context, docID, _ string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.replaced = append(d.replaced, docID)
+	return nil
+}
+
+func newSession(t *testing.T, store storage.Store, id, preset, kind string, length time.Duration) {
+	t.Helper()
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	if err := store.CreateSession(id, start); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.EndSession(id, start.Add(length), ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.UpdateSummary(id, "# Weekly sync\n\nShipped the export pipeline.", storage.SummaryCompleted, preset); err != nil {
+		t.Fatal(err)
+	}
+	if kind != "" {
+		if err := store.SetSessionKind(id, kind); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func exportStatus(t *testing.T, store storage.Store, sessionID string) map[string]storage.Export {
+	t.Helper()
+	exports, err := store.GetExports(sessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	byRule := make(map[string]storage.Export)
+	for _, e := range exports {
+		byRule[e.Rule] = e
+	}
+	return byRule
+}
+
+func TestPipelineExportsMatchingSessions(t *testing.T) {
+	store := storage.NewMemoryStore()
+	newSession(t, store, "long", "standup", storage.SessionKindMeeting, 20*time.Minute)
+	newSession(t, store, "short", "standup", storage.SessionKindMeeting, time.Minute)
+	newSession(t, store, "memo", "default", storage.SessionKindMemo, 20*time.Minute)
+
+	dir := t.TempDir()
+	docs := &docsStub{}
+	renderer := summary.NewDocumentRenderer(config.Summarization{})
+	templated := summary.NewDocumentRenderer(config.Summarization{DocumentTemplate: "{{title}} ({{duration}})\n\n{{summary}}"})
+	rules := []Rule{
+		{Name: "notes", On: "summary_ready", Kind: "markdown", Target: Markdown{Dir: dir}, Renderer: templated, MinDuration: 5 * time.Minute},
+		{Name: "drive", On: "summary_ready", Kind: "gdrive", Target: Drive{Docs: docs}, Renderer: renderer, Presets: []string{"standup"}, Tags: []string{storage.SessionKindMeeting}},
+	}
+	p := NewPipeline(rules, store, nil)
+
+	// Summaries written before Start wait for it.
+	p.SummaryReady("long")
+	if notes := exportStatus(t, store, "long")["notes"]; notes.Status != storage.ExportPending {
+		t.Fatalf("expected the export pending until the pipeline starts, got %+v", notes)
+	}
+	p.Start(context.Background())
+	for _, id := range []string{"short", "memo"} {
+		p.SummaryReady(id)
+	}
+	p.Wait()
+
+	long := exportStatus(t, store, "long")
+	notes, drive := long["notes"], long["drive"]
+	if notes.Status != storage.ExportCompleted || notes.Target != "markdown" || notes.Attempts != 1 {
+		t.Fatalf("unexpected notes export %+v", notes)
+	}
+	content, err := os.ReadFile(filepath.Join(dir, "ghost-wispr-long.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if notes.Location != filepath.Join(dir, "ghost-wispr-long.md") || !strings.HasPrefix(string(content), "Weekly sync (20 min)") {
+		t.Fatalf("unexpected markdown export at %s: %q", notes.Location, content)
+	}
+	if drive.Status != storage.ExportCompleted || drive.Location != "doc-1" || len(docs.created) != 2 || docs.created[0] != "Weekly sync" {
+		t.Fatalf("unexpected drive export %+v, created %v", drive, docs.created)
+	}
+
+	short := exportStatus(t, store, "short")
+	if _, ok := short["notes"]; ok || short["drive"].Status != storage.ExportCompleted {
+		t.Fatalf("expected short session to skip only the notes rule, got %+v", short)
+	}
+	if memo := exportStatus(t, store, "memo"); len(memo) != 1 || memo["notes"].Status != storage.ExportCompleted {
+		t.Fatalf("expected memo to skip the drive rule, got %+v", memo)
+	}
+
+	// Exporting again updates the doc the first export made.
+	if err := p.Retry("long", "drive"); err != nil {
+		t.Fatal(err)
+	}
+	p.Wait()
+	if drive := exportStatus(t, store, "long")["drive"]; drive.Attempts != 2 || len(docs.created) != 2 || len(docs.replaced) != 1 || docs.replaced[0] != "doc-1" {
+		t.Fatalf("expected retry to replace doc-1, got %+v, created %v, replaced %v", drive, docs.created, docs.replaced)
+	}
+
+	if err := p.Retry("long", "missing"); !errors.Is(err, ErrUnknownRule) {
+		t.Fatalf("expected ErrUnknownRule, got %v", err)
+	}
+}
+
+func TestPipelineExportsWeeklyReports(t *testing.T) {
+	dir := t.TempDir()
+	docs := &docsStub{}
+	renderer := summary.NewDocumentRenderer(config.Summarization{})
+	rules := []Rule{
+		{Name: "reports", On: "weekly_report", Kind: "markdown", Target: Markdown{Dir: dir}, Renderer: renderer},
+		{Name: "drive", On: "summary_ready", Kind: "gdrive", Target: Drive{Docs: docs}, Renderer: renderer},
+	}
+	p := NewPipeline(rules, storage.NewMemoryStore(), nil)
+	p.Start(context.Background())
+
+	text := "Ghost Wispr weekly report, 2026-W42\nFailures: none\n"
+	p.ExportReport(storage.Report{ID: "2026-W42", Text: text})
+	p.Wait()
+
+	content, err := os.ReadFile(filepath.Join(dir, "ghost-wispr-report-2026-W42.md"))
+	if err != nil || !strings.HasPrefix(string(content), text) {
+		t.Fatalf("expected the report written by the weekly_report rule, got %q, %v", content, err)
+	}
+	if len(docs.created) != 0 {
+		t.Fatalf("expected summary_ready rules to skip reports, created %v", docs.created)
+	}
+}
+
+func TestPipelineRecordsFailuresAndResumesUnfinished(t *testing.T) {
+	store := storage.NewMemoryStore()
+	newSession(t, store, "s1", "default", "", 10*time.Minute)
+	newSession(t, store, "s2", "default", "", 10*time.Minute)
+	if err := store.SetExport(storage.Export{SessionID: "s2", Rule: "drive", Target: "gdrive", Status: storage.ExportRunning, Attempts: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	docs := &docsStub{fail: errors.New("quota exceeded")}
+	rules := []Rule{{Name: "drive", On: "summary_ready", Kind: "gdrive", Target: Drive{Docs: docs}, Renderer: summary.NewDocumentRenderer(config.Summarization{})}}
+	p := NewPipeline(rules, store, nil)
+
+	p.Start(context.Background())
+	p.SummaryReady("s1")
+	p.Wait()
+
+	failed := exportStatus(t, store, "s1")["drive"]
+	if failed.Status != storage.ExportFailed || failed.Error != "quota exceeded" || failed.Attempts != 1 {
+		t.Fatalf("unexpected failed export %+v", failed)
+	}
+	resumed := exportStatus(t, store, "s2")["drive"]
+	if resumed.Status != storage.ExportFailed || resumed.Attempts != 2 {
+		t.Fatalf("expected interrupted export to be run again, got %+v", resumed)
+	}
+}
+
+func TestSlackPostsMrkdwn(t *testing.T) {
+	var got struct {
+		Text    string `json:"text"`
+		Channel string `json:"channel"`
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode slack payload: %v", err)
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	doc := Document{Title: "Sync", Body: "## Decisions\n\n- **Ship** it, see [the plan](https://example.com/plan)"}
+	location, err := Slack{WebhookURL: srv.URL, Channel: "#team"}.Export(context.Background(), storage.Session{}, doc, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if location != "#team" || got.Channel != "#team" {
+		t.Fatalf("unexpected location %q, channel %q", location, got.Channel)
+	}
+	if want := "*Decisions*\n\n• *Ship* it, see <https://example.com/plan|the plan>"; got.Text != want {
+		t.Fatalf("expected %q, got %q", want, got.Text)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "no_service", http.StatusNotFound)
+	}))
+	defer failing.Close()
+	if _, err := (Slack{WebhookURL: failing.URL}).Export(context.Background(), storage.Session{}, doc, ""); err == nil || !strings.Contains(err.Error(), "no_service") {
+		t.Fatalf("expected slack's error to be reported, got %v", err)
+	}
+}
@@ -0,0 +1,134 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+)
+
+// Markdown writes each session to a markdown file in Dir, replacing the
+// file when the session is exported again.
+type Markdown struct {
+	Dir string
+}
+
+func (m Markdown) Export(_ context.Context, sess storage.Session, doc Document, _ string) (string, error) {
+	if err := os.MkdirAll(m.Dir, 0o755); err != nil {
+		return "", fmt.Errorf("create export folder: %w", err)
+	}
+	path := filepath.Join(m.Dir, "ghost-wispr-"+sess.ID+".md")
+	// Write beside the file and rename, so a notes app syncing the folder
+	// never picks up half a document.
+	tmp, err := os.CreateTemp(m.Dir, ".ghost-wispr-*.md")
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.WriteString(doc.Body + "\n"); err != nil {
+		_ = tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// slackTimeout bounds a post to a Slack webhook.
+const slackTimeout = 15 * time.Second
+
+// Slack posts each session to a Slack incoming webhook. Channel overrides
+// the webhook's own channel where Slack still allows it.
+type Slack struct {
+	WebhookURL string
+	Channel    string
+	Client     *http.Client
+}
+
+func (s Slack) Export(ctx context.Context, _ storage.Session, doc Document, _ string) (string, error) {
+	payload, err := json.Marshal(struct {
+		Text    string `json:"text"`
+		Channel string `json:"channel,omitempty"`
+	}{slackText(doc.Body), s.Channel})
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := context.WithTimeout(ctx, slackTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.WebhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return "", fmt.Errorf("build slack request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		// The error quotes the URL, which holds the webhook's secret.
+		return "", fmt.Errorf("post to slack: %w", errors.Unwrap(err))
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", fmt.Errorf("post to slack: HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if s.Channel != "" {
+		return s.Channel, nil
+	}
+	return "slack", nil
+}
+
+var (
+	markdownHeading = regexp.MustCompile(`(?m)^#{1,6}[ \t]+(.+?)[ \t#]*$`)
+	markdownBold    = regexp.MustCompile(`\*\*(.+?)\*\*`)
+	markdownLink    = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	markdownBullet  = regexp.MustCompile(`(?m)^([ \t]*)[-*][ \t]+`)
+)
+
+// slackText rewrites markdown into Slack's mrkdwn, which has no headings
+// and marks bold and links differently.
+func slackText(md string) string {
+	md = markdownBullet.ReplaceAllString(md, "$1• ")
+	md = markdownBold.ReplaceAllString(md, "*$1*")
+	md = markdownHeading.ReplaceAllString(md, "*$1*")
+	return markdownLink.ReplaceAllString(md, "<$2|$1>")
+}
+
+// DocWriter creates and updates Google Docs in a Drive folder.
+type DocWriter interface {
+	CreateDoc(ctx context.Context, title, markdown string) (string, error)
+	ReplaceDoc(ctx context.Context, docID, markdown string) error
+}
+
+// Drive uploads each session as a Google Doc, updating the doc made by an
+// earlier export of the session rather than adding another.
+type Drive struct {
+	Docs DocWriter
+}
+
+func (d Drive) Export(ctx context.Context, _ storage.Session, doc Document, previous string) (string, error) {
+	if previous != "" {
+		if err := d.Docs.ReplaceDoc(ctx, previous, doc.Body); err != nil {
+			return "", err
+		}
+		return previous, nil
+	}
+	return d.Docs.CreateDoc(ctx, doc.Title, doc.Body)
+}
@@ -0,0 +1,282 @@
+package gdrive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/docs/v1"
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
+
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+)
+
+// googleDocMimeType makes Drive convert an upload into a Google Doc.
+const googleDocMimeType = "application/vnd.google-apps.document"
+
+// commentFields are the parts of a comment thread that become annotations.
+const commentFields = "nextPageToken,comments(id,author/displayName,content,quotedFileContent/value,createdTime,resolved,deleted,replies(id,author/displayName,content,createdTime,deleted))"
+
+// Docs exports summaries as Google Docs in a Drive folder and reads back
+// what collaborators commented on or suggested in them.
+type Docs struct {
+	drive    *drive.Service
+	docs     *docs.Service
+	folderID string
+}
+
+// NewDocs connects to Drive and Docs with a service account. Only files it
+// creates are reachable, so collaborators review the copies it shares.
+func NewDocs(ctx context.Context, credPath, folderID string) (*Docs, error) {
+	creds, err := os.ReadFile(credPath)
+	if err != nil {
+		return nil, fmt.Errorf("read credentials: %w", err)
+	}
+	config, err := google.CredentialsFromJSONWithTypeAndParams(ctx, creds, google.ServiceAccount, google.CredentialsParams{Scopes: []string{drive.DriveFileScope}})
+	if err != nil {
+		return nil, fmt.Errorf("parse credentials: %w", err)
+	}
+
+	driveSvc, err := drive.NewService(ctx, option.WithCredentials(config))
+	if err != nil {
+		return nil, fmt.Errorf("create drive service: %w", err)
+	}
+	docsSvc, err := docs.NewService(ctx, option.WithCredentials(config))
+	if err != nil {
+		return nil, fmt.Errorf("create docs service: %w", err)
+	}
+	return &Docs{drive: driveSvc, docs: docsSvc, folderID: folderID}, nil
+}
+
+// CreateDoc uploads a markdown summary as a new Google Doc and returns its
+// file ID.
+func (d *Docs) CreateDoc(ctx context.Context, title, markdown string) (string, error) {
+	doc, err := d.drive.Files.Create(&drive.File{
+		Name:     title,
+		MimeType: googleDocMimeType,
+		Parents:  []string{d.folderID},
+	}).Media(strings.NewReader(markdown), googleapi.ContentType("text/markdown")).Context(ctx).Do()
+	if err != nil {
+		return "", fmt.Errorf("drive create: %w", err)
+	}
+	return doc.Id, nil
+}
+
+// FindDoc returns the ID of the Google Doc titled title in the folder, or
+// "" if there is none.
+func (d *Docs) FindDoc(ctx context.Context, title string) (string, error) {
+	q := fmt.Sprintf("name = '%s' and '%s' in parents and mimeType = '%s' and trashed = false",
+		escapeQuery(title), escapeQuery(d.folderID), googleDocMimeType)
+	list, err := d.drive.Files.List().Q(q).Fields("files(id)").PageSize(1).Context(ctx).Do()
+	if err != nil {
+		return "", fmt.Errorf("drive list: %w", err)
+	}
+	if len(list.Files) == 0 {
+		return "", nil
+	}
+	return list.Files[0].Id, nil
+}
+
+// ReplaceDoc replaces a Google Doc's content with a markdown document.
+func (d *Docs) ReplaceDoc(ctx context.Context, docID, markdown string) error {
+	_, err := d.drive.Files.Update(docID, &drive.File{}).Media(strings.NewReader(markdown), googleapi.ContentType("text/markdown")).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("drive update: %w", err)
+	}
+	return nil
+}
+
+// AppendSection adds text at the end of a Google Doc under a heading. The
+// text goes in as it is: markdown is only converted when a whole doc is
+// uploaded.
+func (d *Docs) AppendSection(ctx context.Context, docID, heading, text string) error {
+	doc, err := d.docs.Documents.Get(docID).Fields("body/content/endIndex").Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("get document: %w", err)
+	}
+	// Insert before the body's final newline, starting a new paragraph.
+	// Indexes count UTF-16 code units.
+	at := int64(1)
+	if doc.Body != nil && len(doc.Body.Content) > 0 {
+		at = doc.Body.Content[len(doc.Body.Content)-1].EndIndex - 1
+	}
+	headingStart := at + 1
+	headingEnd := headingStart + utf16Len(heading) + 1
+	textEnd := headingEnd + utf16Len(text)
+	style := func(start, end int64, named string) *docs.Request {
+		return &docs.Request{UpdateParagraphStyle: &docs.UpdateParagraphStyleRequest{
+			Range:          &docs.Range{StartIndex: start, EndIndex: end},
+			ParagraphStyle: &docs.ParagraphStyle{NamedStyleType: named},
+			Fields:         "namedStyleType",
+		}}
+	}
+	_, err = d.docs.Documents.BatchUpdate(docID, &docs.BatchUpdateDocumentRequest{Requests: []*docs.Request{
+		{InsertText: &docs.InsertTextRequest{Location: &docs.Location{Index: at}, Text: "\n" + heading + "\n" + text}},
+		style(headingStart, headingEnd, "HEADING_2"),
+		style(headingEnd, textEnd, "NORMAL_TEXT"),
+	}}).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("docs append: %w", err)
+	}
+	return nil
+}
+
+func utf16Len(s string) int64 {
+	return int64(len(utf16.Encode([]rune(s))))
+}
+
+// DeleteFile deletes a Drive file this account created, such as an exported
+// summary doc, returning an error wrapping os.ErrNotExist if it is gone.
+func (d *Docs) DeleteFile(ctx context.Context, fileID string) error {
+	err := d.drive.Files.Delete(fileID).Context(ctx).Do()
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound {
+		return fmt.Errorf("drive delete %s: %w", fileID, os.ErrNotExist)
+	}
+	if err != nil {
+		return fmt.Errorf("drive delete: %w", err)
+	}
+	return nil
+}
+
+// escapeQuery quotes s for a string literal in a Drive search query.
+func escapeQuery(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s)
+}
+
+// Annotations returns the comments, replies and open suggestions on a doc.
+func (d *Docs) Annotations(ctx context.Context, docID string) ([]storage.Annotation, error) {
+	var annotations []storage.Annotation
+	err := d.drive.Comments.List(docID).Fields(commentFields).Pages(ctx, func(page *drive.CommentList) error {
+		for _, c := range page.Comments {
+			annotations = append(annotations, commentAnnotations(c)...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list comments: %w", err)
+	}
+
+	doc, err := d.docs.Documents.Get(docID).SuggestionsViewMode("SUGGESTIONS_INLINE").Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("get document: %w", err)
+	}
+	if doc.Body != nil {
+		annotations = append(annotations, suggestionAnnotations(doc.Body.Content)...)
+	}
+	return annotations, nil
+}
+
+// commentAnnotations turns a comment thread into one annotation per
+// comment and reply, skipping deleted ones.
+func commentAnnotations(c *drive.Comment) []storage.Annotation {
+	var out []storage.Annotation
+	quote := ""
+	if c.QuotedFileContent != nil {
+		quote = c.QuotedFileContent.Value
+	}
+	if !c.Deleted {
+		out = append(out, storage.Annotation{
+			ExternalID: c.Id,
+			Kind:       storage.AnnotationComment,
+			Author:     authorName(c.Author),
+			Text:       c.Content,
+			Quote:      quote,
+			Resolved:   c.Resolved,
+			CreatedAt:  parseTime(c.CreatedTime),
+		})
+	}
+	for _, r := range c.Replies {
+		if r.Deleted || r.Content == "" {
+			continue
+		}
+		out = append(out, storage.Annotation{
+			ExternalID: c.Id + "/" + r.Id,
+			Kind:       storage.AnnotationReply,
+			Author:     authorName(r.Author),
+			Text:       r.Content,
+			Quote:      quote,
+			Resolved:   c.Resolved,
+			CreatedAt:  parseTime(r.CreatedTime),
+		})
+	}
+	return out
+}
+
+// suggestionAnnotations gathers the text each open suggestion inserts and
+// deletes. The Docs API doesn't say who made a suggestion or when.
+func suggestionAnnotations(content []*docs.StructuralElement) []storage.Annotation {
+	type edit struct{ inserted, deleted strings.Builder }
+	edits := map[string]*edit{}
+	var order []string
+	get := func(id string) *edit {
+		e, ok := edits[id]
+		if !ok {
+			e = &edit{}
+			edits[id] = e
+			order = append(order, id)
+		}
+		return e
+	}
+	var walk func([]*docs.StructuralElement)
+	walk = func(elements []*docs.StructuralElement) {
+		for _, el := range elements {
+			if el.Table != nil {
+				for _, row := range el.Table.TableRows {
+					for _, cell := range row.TableCells {
+						walk(cell.Content)
+					}
+				}
+			}
+			if el.Paragraph == nil {
+				continue
+			}
+			for _, pe := range el.Paragraph.Elements {
+				if pe.TextRun == nil {
+					continue
+				}
+				for _, id := range pe.TextRun.SuggestedInsertionIds {
+					get(id).inserted.WriteString(pe.TextRun.Content)
+				}
+				for _, id := range pe.TextRun.SuggestedDeletionIds {
+					get(id).deleted.WriteString(pe.TextRun.Content)
+				}
+			}
+		}
+	}
+	walk(content)
+
+	sort.Strings(order)
+	out := make([]storage.Annotation, 0, len(order))
+	for _, id := range order {
+		e := edits[id]
+		out = append(out, storage.Annotation{
+			ExternalID: id,
+			Kind:       storage.AnnotationSuggestion,
+			Text:       strings.TrimSpace(e.inserted.String()),
+			Quote:      strings.TrimSpace(e.deleted.String()),
+		})
+	}
+	return out
+}
+
+func authorName(u *drive.User) string {
+	if u == nil {
+		return ""
+	}
+	return u.DisplayName
+}
+
+func parseTime(s string) time.Time {
+	t, _ := time.Parse(time.RFC3339, s)
+	return t
+}
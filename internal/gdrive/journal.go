@@ -0,0 +1,150 @@
+package gdrive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/i18n"
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+	"github.com/sjawhar/ghost-wispr/internal/summary"
+)
+
+// JournalDocService keeps one Google Doc per day.
+type JournalDocService interface {
+	// FindDoc returns the ID of the doc titled title, or "" if there is none.
+	FindDoc(ctx context.Context, title string) (string, error)
+	CreateDoc(ctx context.Context, title, markdown string) (string, error)
+	// AppendSection adds text under a heading at the end of a doc.
+	AppendSection(ctx context.Context, docID, heading, text string) error
+}
+
+// JournalStore is the session storage Journal needs.
+type JournalStore interface {
+	GetSessionsByDate(date string) ([]storage.Session, error)
+	SetJournalDocID(sessionID, docID string) error
+}
+
+// Journal keeps a daily journal in Google Docs: one doc per local day to
+// which each of that day's sessions' title and summary is appended once
+// its summary completes. Each session is appended once, so edits made in
+// the doc are kept, and a summary rewritten afterwards isn't.
+type Journal struct {
+	docs   JournalDocService
+	store  JournalStore
+	locale *i18n.Localizer
+	// now sets the days as well as the time: they are local to its zone.
+	now func() time.Time
+	// docIDs caches the doc of each day, so a pass with nothing new makes
+	// no API calls.
+	docIDs map[string]string
+}
+
+// NewJournal writes journal docs through docs from the sessions in store,
+// titled in locale's language.
+func NewJournal(docs JournalDocService, store JournalStore, locale *i18n.Localizer) *Journal {
+	return &Journal{docs: docs, store: store, locale: locale, now: time.Now, docIDs: make(map[string]string)}
+}
+
+// Sync appends today's newly summarized sessions to today's journal, and
+// yesterday's to yesterday's, as its last summaries may complete after
+// midnight. Days without a summary get no doc.
+func (j *Journal) Sync(ctx context.Context) error {
+	today := j.now()
+	days := []time.Time{today.AddDate(0, 0, -1), today}
+	for date := range j.docIDs {
+		if date != days[0].Format("2006-01-02") && date != days[1].Format("2006-01-02") {
+			delete(j.docIDs, date)
+		}
+	}
+	var errs []error
+	for _, day := range days {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		errs = append(errs, j.syncDay(ctx, day))
+	}
+	return errors.Join(errs...)
+}
+
+func (j *Journal) syncDay(ctx context.Context, day time.Time) error {
+	date := day.Format("2006-01-02")
+	sessions, err := j.unwrittenOn(day)
+	if err != nil {
+		return fmt.Errorf("list sessions of %s: %w", date, err)
+	}
+	if len(sessions) == 0 {
+		return nil
+	}
+	docID, err := j.dayDoc(ctx, date)
+	if err != nil {
+		return err
+	}
+	for _, sess := range sessions {
+		start := sess.StartedAt.In(day.Location())
+		heading := start.Format("15:04") + " " + summary.Title(sess.Summary, start, j.locale)
+		if err := j.docs.AppendSection(ctx, docID, heading, strings.TrimSpace(sess.Summary)); err != nil {
+			return fmt.Errorf("append session %s to journal of %s: %w", sess.ID, date, err)
+		}
+		if err := j.store.SetJournalDocID(sess.ID, docID); err != nil {
+			return fmt.Errorf("record journal of session %s: %w", sess.ID, err)
+		}
+	}
+	slog.Info("updated daily journal", "date", date, "sessions", len(sessions))
+	return nil
+}
+
+// dayDoc returns the journal doc of date, creating it if need be. A doc
+// from before a restart is found rather than duplicated.
+func (j *Journal) dayDoc(ctx context.Context, date string) (string, error) {
+	if id, ok := j.docIDs[date]; ok {
+		return id, nil
+	}
+	title := j.locale.T(i18n.DocJournalTitle, date)
+	id, err := j.docs.FindDoc(ctx, title)
+	if err != nil {
+		return "", fmt.Errorf("find journal of %s: %w", date, err)
+	}
+	if id == "" {
+		if id, err = j.docs.CreateDoc(ctx, title, "# "+title+"\n"); err != nil {
+			return "", fmt.Errorf("create journal of %s: %w", date, err)
+		}
+	}
+	j.docIDs[date] = id
+	return id, nil
+}
+
+// unwrittenOn returns the sessions with a completed summary not yet in a
+// journal that started on day in local time, oldest first. Sessions are
+// stored by UTC date, so a local day can span two of them.
+func (j *Journal) unwrittenOn(day time.Time) ([]storage.Session, error) {
+	start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	end := start.AddDate(0, 0, 1)
+	dates := []string{start.UTC().Format("2006-01-02")}
+	if last := end.Add(-time.Nanosecond).UTC().Format("2006-01-02"); last != dates[0] {
+		dates = append(dates, last)
+	}
+
+	var sessions []storage.Session
+	for _, date := range dates {
+		stored, err := j.store.GetSessionsByDate(date)
+		if err != nil {
+			return nil, err
+		}
+		for _, sess := range stored {
+			if sess.JournalDocID != "" || sess.SummaryStatus != storage.SummaryCompleted || strings.TrimSpace(sess.Summary) == "" {
+				continue
+			}
+			if sess.StartedAt.Before(start) || !sess.StartedAt.Before(end) {
+				continue
+			}
+			sessions = append(sessions, sess)
+		}
+	}
+	sort.Slice(sessions, func(a, b int) bool { return sessions[a].StartedAt.Before(sessions[b].StartedAt) })
+	return sessions, nil
+}
@@ -0,0 +1,129 @@
+package gdrive
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/i18n"
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+)
+
+type fakeJournalDocs struct {
+	docs    map[string]string
+	titles  map[string]string
+	writes  int
+	lookups int
+}
+
+func (f *fakeJournalDocs) FindDoc(_ context.Context, title string) (string, error) {
+	f.lookups++
+	return f.titles[title], nil
+}
+
+func (f *fakeJournalDocs) CreateDoc(_ context.Context, title, markdown string) (string, error) {
+	id := "doc-" + title
+	f.titles[title] = id
+	f.docs[id] = markdown
+	f.writes++
+	return id, nil
+}
+
+func (f *fakeJournalDocs) AppendSection(_ context.Context, docID, heading, text string) error {
+	f.docs[docID] += "\n## " + heading + "\n\n" + text + "\n"
+	f.writes++
+	return nil
+}
+
+func TestJournalAppendsSessionsAsTheyComplete(t *testing.T) {
+	// 23:30 in New York is the next day in UTC; the journal goes by the
+	// local day.
+	zone := time.FixedZone("EST", -5*60*60)
+	now := time.Date(2026, 3, 2, 23, 45, 0, 0, zone)
+	store := storage.NewMemoryStore()
+	add := func(id string, at time.Time, summary string) {
+		t.Helper()
+		if err := store.CreateSession(id, at); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.UpdateSummary(id, summary, storage.SummaryCompleted, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	add("standup", time.Date(2026, 3, 2, 9, 0, 0, 0, zone), "## Standup\n- shipped the beta")
+	add("late", time.Date(2026, 3, 2, 23, 30, 0, 0, zone), "- ordered pizza")
+	add("running", time.Date(2026, 3, 2, 12, 0, 0, 0, zone), "")
+
+	docs := &fakeJournalDocs{docs: map[string]string{}, titles: map[string]string{}}
+	journal := NewJournal(docs, store, nil)
+	journal.now = func() time.Time { return now }
+
+	if err := journal.Sync(context.Background()); err != nil {
+		t.Fatalf("first sync: %v", err)
+	}
+	id := docs.titles["Ghost Wispr journal 2026-03-02"]
+	if len(docs.docs) != 1 || id == "" {
+		t.Fatalf("expected one journal for the day with summaries, got %v", docs.titles)
+	}
+	got := docs.docs[id]
+	standup, late := strings.Index(got, "## 09:00 Standup"), strings.Index(got, "## 23:30 Session 2026-03-02 23:30")
+	if !strings.HasPrefix(got, "# Ghost Wispr journal 2026-03-02\n") || standup < 0 || late < standup || !strings.Contains(got, "- ordered pizza") {
+		t.Fatalf("expected both sessions in order under their titles, got:\n%s", got)
+	}
+
+	writes := docs.writes
+	if err := journal.Sync(context.Background()); err != nil {
+		t.Fatalf("idle sync: %v", err)
+	}
+	if docs.writes != writes {
+		t.Fatal("expected nothing rewritten without new summaries")
+	}
+
+	if err := store.UpdateSummary("running", "## Lunch\n- tacos", storage.SummaryCompleted, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := journal.Sync(context.Background()); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	got = docs.docs[id]
+	if len(docs.docs) != 1 || !strings.HasSuffix(got, "## 12:00 Lunch\n\n## Lunch\n- tacos\n") || strings.Count(got, "## 09:00 Standup") != 1 {
+		t.Fatalf("expected only the new summary appended to the same doc, got:\n%s", got)
+	}
+
+	// After a restart the existing doc is found rather than duplicated, and
+	// sessions already in it aren't appended again.
+	if err := store.UpdateSummary("standup", "## Standup\n- shipped the beta, really", storage.SummaryCompleted, ""); err != nil {
+		t.Fatal(err)
+	}
+	add("evening", time.Date(2026, 3, 2, 18, 0, 0, 0, zone), "## Dinner\n- pasta")
+	restarted := NewJournal(docs, store, nil)
+	restarted.now = journal.now
+	if err := restarted.Sync(context.Background()); err != nil {
+		t.Fatalf("sync after restart: %v", err)
+	}
+	if len(docs.docs) != 1 || docs.lookups == 0 || !strings.Contains(docs.docs[id], "## 18:00 Dinner") || strings.Contains(docs.docs[id], "really") {
+		t.Fatalf("expected the journal reused after a restart and only the new session appended, got %v", docs.docs)
+	}
+}
+
+func TestJournalTitlesInLocale(t *testing.T) {
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	store := storage.NewMemoryStore()
+	if err := store.CreateSession("s", now.Add(-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.UpdateSummary("s", "- Kaffee", storage.SummaryCompleted, ""); err != nil {
+		t.Fatal(err)
+	}
+	docs := &fakeJournalDocs{docs: map[string]string{}, titles: map[string]string{}}
+	locale, _ := i18n.New("de")
+	journal := NewJournal(docs, store, locale)
+	journal.now = func() time.Time { return now }
+	if err := journal.Sync(context.Background()); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if _, ok := docs.titles["Ghost-Wispr-Tagebuch 2026-03-02"]; !ok {
+		t.Fatalf("expected a German journal title, got %v", docs.titles)
+	}
+}
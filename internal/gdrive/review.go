@@ -0,0 +1,138 @@
+package gdrive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+)
+
+// ReviewWindow is how long after a session ends its summary doc is watched
+// for comments. Older sessions are neither exported nor polled, which keeps
+// each pass to a bounded number of API calls.
+const ReviewWindow = 30 * 24 * time.Hour
+
+// DocService creates summary docs and reads collaborators' annotations.
+type DocService interface {
+	CreateDoc(ctx context.Context, title, markdown string) (string, error)
+	Annotations(ctx context.Context, docID string) ([]storage.Annotation, error)
+}
+
+// ReviewStore is the session storage Reviews needs.
+type ReviewStore interface {
+	GetSessionsBySummaryStatus(status string) ([]storage.Session, error)
+	SetGDocID(sessionID, docID string) error
+	GetAnnotations(sessionID string) ([]storage.Annotation, error)
+	ReplaceAnnotations(sessionID string, annotations []storage.Annotation) error
+}
+
+// Reviews runs a lightweight review loop on meeting minutes: completed
+// summaries are exported as Google Docs, and comments and suggestions
+// collaborators make there are pulled back as session annotations.
+type Reviews struct {
+	docs  DocService
+	store ReviewStore
+	now   func() time.Time
+}
+
+// NewReviews exports summaries through docs and keeps annotations in store.
+func NewReviews(docs DocService, store ReviewStore) *Reviews {
+	return &Reviews{docs: docs, store: store, now: time.Now}
+}
+
+// Sync exports summaries that have no doc yet and refreshes the
+// annotations of those that do. A failing session doesn't hold up the
+// others; their errors are returned together.
+func (r *Reviews) Sync(ctx context.Context) error {
+	sessions, err := r.store.GetSessionsBySummaryStatus(storage.SummaryCompleted)
+	if err != nil {
+		return fmt.Errorf("list summarized sessions: %w", err)
+	}
+	now := r.now()
+	var errs []error
+	for _, sess := range sessions {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if sess.EndedAt == nil || now.Sub(*sess.EndedAt) > ReviewWindow {
+			continue
+		}
+		if sess.GDocID == "" {
+			errs = append(errs, r.export(ctx, sess))
+			continue
+		}
+		errs = append(errs, r.pull(ctx, sess, now))
+	}
+	return errors.Join(errs...)
+}
+
+func (r *Reviews) export(ctx context.Context, sess storage.Session) error {
+	title := "Ghost Wispr " + sess.StartedAt.Local().Format("2006-01-02 15:04")
+	docID, err := r.docs.CreateDoc(ctx, title, sess.Summary)
+	if err != nil {
+		return fmt.Errorf("export summary of session %s: %w", sess.ID, err)
+	}
+	if err := r.store.SetGDocID(sess.ID, docID); err != nil {
+		return fmt.Errorf("record doc of session %s: %w", sess.ID, err)
+	}
+	return nil
+}
+
+func (r *Reviews) pull(ctx context.Context, sess storage.Session, now time.Time) error {
+	pulled, err := r.docs.Annotations(ctx, sess.GDocID)
+	if err != nil {
+		return fmt.Errorf("pull annotations of session %s: %w", sess.ID, err)
+	}
+	stored, err := r.store.GetAnnotations(sess.ID)
+	if err != nil {
+		return fmt.Errorf("load annotations of session %s: %w", sess.ID, err)
+	}
+
+	// Suggestions carry no timestamp, so they keep the time they were
+	// first seen.
+	seen := make(map[string]time.Time, len(stored))
+	for _, a := range stored {
+		seen[a.ExternalID] = a.CreatedAt
+	}
+	for i := range pulled {
+		pulled[i].SessionID = sess.ID
+		if !pulled[i].CreatedAt.IsZero() {
+			continue
+		}
+		if at, ok := seen[pulled[i].ExternalID]; ok {
+			pulled[i].CreatedAt = at
+		} else {
+			pulled[i].CreatedAt = now.UTC()
+		}
+	}
+	if sameAnnotations(stored, pulled) {
+		return nil
+	}
+	if err := r.store.ReplaceAnnotations(sess.ID, pulled); err != nil {
+		return fmt.Errorf("store annotations of session %s: %w", sess.ID, err)
+	}
+	slog.Info("pulled summary annotations", "session", sess.ID, "annotations", len(pulled))
+	return nil
+}
+
+// sameAnnotations reports whether a pull found nothing new, regardless of
+// order.
+func sameAnnotations(a, b []storage.Annotation) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	key := func(x storage.Annotation) string { return x.ExternalID }
+	byID := make(map[string]storage.Annotation, len(a))
+	for _, x := range a {
+		byID[key(x)] = x
+	}
+	return !slices.ContainsFunc(b, func(y storage.Annotation) bool {
+		x, ok := byID[key(y)]
+		return !ok || x.Kind != y.Kind || x.Author != y.Author || x.Text != y.Text ||
+			x.Quote != y.Quote || x.Resolved != y.Resolved || !x.CreatedAt.Equal(y.CreatedAt)
+	})
+}
@@ -0,0 +1,99 @@
+package gdrive
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+)
+
+type fakeDocs struct {
+	created     map[string]string
+	annotations map[string][]storage.Annotation
+}
+
+func (f *fakeDocs) CreateDoc(_ context.Context, title, markdown string) (string, error) {
+	id := "doc-" + title
+	f.created[id] = markdown
+	return id, nil
+}
+
+func (f *fakeDocs) Annotations(_ context.Context, docID string) ([]storage.Annotation, error) {
+	return append([]storage.Annotation(nil), f.annotations[docID]...), nil
+}
+
+func TestReviewsExportThenPullAnnotations(t *testing.T) {
+	store := storage.NewMemoryStore()
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	started := now.Add(-2 * time.Hour)
+	stale := now.Add(-2 * ReviewWindow)
+	for id, at := range map[string]time.Time{"recent": started, "stale": stale} {
+		if err := store.CreateSession(id, at); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.EndSession(id, at.Add(time.Hour), ""); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.UpdateSummary(id, "## Minutes\n- shipped", storage.SummaryCompleted, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	docs := &fakeDocs{created: map[string]string{}, annotations: map[string][]storage.Annotation{}}
+	reviews := NewReviews(docs, store)
+	reviews.now = func() time.Time { return now }
+
+	if err := reviews.Sync(context.Background()); err != nil {
+		t.Fatalf("first sync: %v", err)
+	}
+	if len(docs.created) != 1 {
+		t.Fatalf("expected only the recent summary exported, got %v", docs.created)
+	}
+	sess, err := store.GetSession("recent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sess.GDocID == "" || docs.created[sess.GDocID] != "## Minutes\n- shipped" {
+		t.Fatalf("expected the doc recorded on the session, got %q", sess.GDocID)
+	}
+
+	docs.annotations[sess.GDocID] = []storage.Annotation{
+		{ExternalID: "c1", Kind: storage.AnnotationComment, Author: "Ada", Text: "Who owns this?", Quote: "shipped", CreatedAt: now.Add(-time.Minute)},
+		{ExternalID: "s1", Kind: storage.AnnotationSuggestion, Text: "launched", Quote: "shipped"},
+	}
+	if err := reviews.Sync(context.Background()); err != nil {
+		t.Fatalf("second sync: %v", err)
+	}
+	got, err := store.GetAnnotations("recent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected both annotations imported, got %+v", got)
+	}
+	var suggestedAt time.Time
+	for _, a := range got {
+		if a.SessionID != "recent" {
+			t.Fatalf("expected annotations attached to the session, got %+v", a)
+		}
+		if a.ExternalID == "s1" {
+			suggestedAt = a.CreatedAt
+		}
+	}
+	if !suggestedAt.Equal(now) {
+		t.Fatalf("expected the suggestion stamped when first seen, got %v", suggestedAt)
+	}
+
+	// A later pull keeps the suggestion's first-seen time.
+	reviews.now = func() time.Time { return now.Add(time.Hour) }
+	if err := reviews.Sync(context.Background()); err != nil {
+		t.Fatalf("third sync: %v", err)
+	}
+	got, _ = store.GetAnnotations("recent")
+	for _, a := range got {
+		if a.ExternalID == "s1" && !a.CreatedAt.Equal(now) {
+			t.Fatalf("expected the first-seen time kept, got %v", a.CreatedAt)
+		}
+	}
+}
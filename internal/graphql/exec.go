@@ -0,0 +1,281 @@
+// Package graphql is a small GraphQL query executor over hand-written
+// resolvers. It covers what read-only API clients use — nested selections,
+// aliases, arguments, variables and __typename — and leaves out fragments,
+// directives, mutations and introspection.
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// Object is an object type: a name and a resolver per field.
+type Object struct {
+	Name   string
+	Fields map[string]Resolver
+}
+
+// Resolver computes one field of src. It returns a JSON-encodable scalar,
+// a Node, or a []Node.
+type Resolver func(ctx context.Context, src any, args Args) (any, error)
+
+// Node is a value whose fields are resolved by Type.
+type Node struct {
+	Type  *Object
+	Value any
+}
+
+// Nodes wraps each item as a Node of typ.
+func Nodes[T any](typ *Object, items []T) []Node {
+	nodes := make([]Node, len(items))
+	for i, item := range items {
+		nodes[i] = Node{Type: typ, Value: item}
+	}
+	return nodes
+}
+
+// Args holds a field's arguments with variables substituted.
+type Args map[string]any
+
+// String returns a string argument; ok is false when it is absent or null.
+func (a Args) String(name string) (string, bool, error) {
+	v, ok := a[name]
+	if !ok || v == nil {
+		return "", false, nil
+	}
+	s, isString := v.(string)
+	if !isString {
+		return "", false, fmt.Errorf("argument %s must be a string", name)
+	}
+	return s, true, nil
+}
+
+// Int returns an integer argument; ok is false when it is absent or null.
+func (a Args) Int(name string) (int, bool, error) {
+	v, ok := a[name]
+	if !ok || v == nil {
+		return 0, false, nil
+	}
+	switch n := v.(type) {
+	case int:
+		return n, true, nil
+	case float64:
+		if n == math.Trunc(n) {
+			return int(n), true, nil
+		}
+	}
+	return 0, false, fmt.Errorf("argument %s must be an integer", name)
+}
+
+// Request is a GraphQL request as POSTed by clients.
+type Request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+type Response struct {
+	Data   any     `json:"data,omitempty"`
+	Errors []Error `json:"errors,omitempty"`
+}
+
+type Error struct {
+	Message string `json:"message"`
+	Path    []any  `json:"path,omitempty"`
+}
+
+// Schema executes queries against its Query root type.
+type Schema struct {
+	Query *Object
+}
+
+// Execute runs the requested operation. Field errors null the field and are
+// reported alongside the data; parse errors yield no data at all.
+func (s *Schema) Execute(ctx context.Context, req Request) Response {
+	ops, err := Parse(req.Query)
+	if err != nil {
+		return Response{Errors: []Error{{Message: err.Error()}}}
+	}
+
+	op, err := selectOperation(ops, req.OperationName)
+	if err != nil {
+		return Response{Errors: []Error{{Message: err.Error()}}}
+	}
+
+	e := &executor{ctx: ctx, vars: req.Variables, defaults: op.Defaults}
+	data := e.selections(s.Query, nil, op.Selections, nil)
+	return Response{Data: data, Errors: e.errors}
+}
+
+func selectOperation(ops []Operation, name string) (Operation, error) {
+	if name == "" {
+		if len(ops) > 1 {
+			return Operation{}, fmt.Errorf("operationName is required when the document has several operations")
+		}
+		return ops[0], nil
+	}
+	for _, op := range ops {
+		if op.Name == name {
+			return op, nil
+		}
+	}
+	return Operation{}, fmt.Errorf("unknown operation %q", name)
+}
+
+type executor struct {
+	ctx      context.Context
+	vars     map[string]any
+	defaults map[string]Value
+	errors   []Error
+}
+
+func (e *executor) selections(typ *Object, src any, fields []Field, path []any) orderedMap {
+	out := orderedMap{}
+	for _, f := range fields {
+		fieldPath := append(append([]any{}, path...), f.Alias)
+		out = out.set(f.Alias, e.field(typ, src, f, fieldPath))
+	}
+	return out
+}
+
+func (e *executor) field(typ *Object, src any, f Field, path []any) any {
+	if f.Name == "__typename" {
+		return typ.Name
+	}
+	resolve, ok := typ.Fields[f.Name]
+	if !ok {
+		e.fail(path, fmt.Errorf("cannot query field %q on type %s", f.Name, typ.Name))
+		return nil
+	}
+
+	args := Args{}
+	for name, v := range f.Args {
+		args[name] = e.value(v)
+	}
+	v, err := resolve(e.ctx, src, args)
+	if err != nil {
+		e.fail(path, err)
+		return nil
+	}
+	return e.complete(f, v, path)
+}
+
+func (e *executor) complete(f Field, v any, path []any) any {
+	if v == nil {
+		return nil
+	}
+	switch n := v.(type) {
+	case Node:
+		if len(f.Selections) == 0 {
+			e.fail(path, fmt.Errorf("field %q of type %s must have a selection of subfields", f.Name, n.Type.Name))
+			return nil
+		}
+		return e.selections(n.Type, n.Value, f.Selections, path)
+	case *Node:
+		if n == nil {
+			return nil
+		}
+		return e.complete(f, *n, path)
+	case []Node:
+		items := make([]any, len(n))
+		for i, item := range n {
+			items[i] = e.complete(f, item, append(append([]any{}, path...), i))
+		}
+		return items
+	}
+	if len(f.Selections) > 0 {
+		e.fail(path, fmt.Errorf("field %q is a scalar and has no subfields", f.Name))
+		return nil
+	}
+	return v
+}
+
+func (e *executor) value(v Value) any {
+	switch v.Kind {
+	case ValueInt:
+		n, err := strconv.Atoi(v.Raw)
+		if err != nil {
+			f, _ := strconv.ParseFloat(v.Raw, 64)
+			return f
+		}
+		return n
+	case ValueFloat:
+		f, _ := strconv.ParseFloat(v.Raw, 64)
+		return f
+	case ValueString, ValueEnum:
+		return v.Raw
+	case ValueBoolean:
+		return v.Raw == "true"
+	case ValueList:
+		items := make([]any, len(v.List))
+		for i, item := range v.List {
+			items[i] = e.value(item)
+		}
+		return items
+	case ValueObject:
+		obj := make(map[string]any, len(v.Object))
+		for k, item := range v.Object {
+			obj[k] = e.value(item)
+		}
+		return obj
+	case ValueVariable:
+		if val, ok := e.vars[v.Variable]; ok {
+			return val
+		}
+		if def, ok := e.defaults[v.Variable]; ok {
+			return e.value(def)
+		}
+	}
+	return nil
+}
+
+func (e *executor) fail(path []any, err error) {
+	e.errors = append(e.errors, Error{Message: err.Error(), Path: path})
+}
+
+// orderedMap marshals to a JSON object whose keys follow the query's
+// selection order, as the spec requires.
+type orderedMap []orderedEntry
+
+type orderedEntry struct {
+	key   string
+	value any
+}
+
+// set adds key, or replaces it when an alias was selected twice.
+func (m orderedMap) set(key string, value any) orderedMap {
+	for i := range m {
+		if m[i].key == key {
+			m[i].value = value
+			return m
+		}
+	}
+	return append(m, orderedEntry{key, value})
+}
+
+func (m orderedMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, entry := range m {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(entry.key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(entry.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,113 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+type book struct {
+	Title  string
+	Author string
+}
+
+func testSchema() *Schema {
+	books := []book{{"Dune", "Herbert"}, {"Emma", "Austen"}}
+	bookType := &Object{Name: "Book", Fields: map[string]Resolver{
+		"title":  func(_ context.Context, src any, _ Args) (any, error) { return src.(book).Title, nil },
+		"author": func(_ context.Context, src any, _ Args) (any, error) { return src.(book).Author, nil },
+		"broken": func(context.Context, any, Args) (any, error) { return nil, errors.New("boom") },
+	}}
+	return &Schema{Query: &Object{Name: "Query", Fields: map[string]Resolver{
+		"books": func(_ context.Context, _ any, args Args) (any, error) {
+			limit, ok, err := args.Int("limit")
+			if err != nil {
+				return nil, err
+			}
+			if ok && limit < len(books) {
+				return Nodes(bookType, books[:limit]), nil
+			}
+			return Nodes(bookType, books), nil
+		},
+		"book": func(_ context.Context, _ any, args Args) (any, error) {
+			title, _, err := args.String("title")
+			for _, b := range books {
+				if b.Title == title {
+					return Node{Type: bookType, Value: b}, err
+				}
+			}
+			return nil, err
+		},
+	}}}
+}
+
+func execute(t *testing.T, req Request) string {
+	t.Helper()
+	out, err := json.Marshal(testSchema().Execute(context.Background(), req))
+	if err != nil {
+		t.Fatalf("marshal response: %v", err)
+	}
+	return string(out)
+}
+
+func TestExecuteNestedSelectionsInOrder(t *testing.T) {
+	got := execute(t, Request{Query: `
+		# comments and commas are ignored
+		query Shelf($n: Int = 5) {
+			first: books(limit: 1) { author, title __typename }
+			all: books(limit: $n) { title }
+			book(title: "Emma") { author }
+			missing: book(title: "Nope") { author }
+		}`, Variables: map[string]any{"n": float64(2)}})
+
+	want := `{"data":{"first":[{"author":"Herbert","title":"Dune","__typename":"Book"}],` +
+		`"all":[{"title":"Dune"},{"title":"Emma"}],"book":{"author":"Austen"},"missing":null}}`
+	if got != want {
+		t.Fatalf("got  %s\nwant %s", got, want)
+	}
+}
+
+func TestExecuteReportsFieldErrorsWithPath(t *testing.T) {
+	got := execute(t, Request{Query: `{ books(limit: 1) { title broken } nope }`})
+
+	want := `{"data":{"books":[{"title":"Dune","broken":null}],"nope":null},"errors":[` +
+		`{"message":"boom","path":["books",0,"broken"]},` +
+		`{"message":"cannot query field \"nope\" on type Query","path":["nope"]}]}`
+	if got != want {
+		t.Fatalf("got  %s\nwant %s", got, want)
+	}
+}
+
+func TestExecuteRejectsUnsupportedSyntax(t *testing.T) {
+	for _, query := range []string{
+		`mutation { books { title } }`,
+		`{ books { ...BookFields } }`,
+		`{ books @include(if: true) { title } }`,
+		`{ books { title }`,
+		`{ books { title } } { books { author } }`,
+	} {
+		resp := testSchema().Execute(context.Background(), Request{Query: query})
+		if resp.Data != nil || len(resp.Errors) != 1 {
+			t.Fatalf("%s: expected a single request error, got %+v", query, resp)
+		}
+	}
+}
+
+func TestParseValues(t *testing.T) {
+	ops, err := Parse(`{ f(a: -1.5e2, b: [1 "xA\n"], c: {d: null, e: ENUM, g: true}) }`)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	args := ops[0].Selections[0].Args
+	if args["a"].Kind != ValueFloat || args["a"].Raw != "-1.5e2" {
+		t.Fatalf("unexpected float: %+v", args["a"])
+	}
+	if list := args["b"].List; len(list) != 2 || list[1].Raw != "xA\n" {
+		t.Fatalf("unexpected list: %+v", args["b"])
+	}
+	obj := args["c"].Object
+	if obj["d"].Kind != ValueNull || obj["e"].Kind != ValueEnum || obj["g"].Kind != ValueBoolean {
+		t.Fatalf("unexpected object: %+v", obj)
+	}
+}
@@ -0,0 +1,484 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Field is one selected field. Alias equals Name when no alias was given.
+type Field struct {
+	Alias      string
+	Name       string
+	Args       map[string]Value
+	Selections []Field
+}
+
+// Value is an argument value as written in the query.
+type Value struct {
+	Kind     ValueKind
+	Raw      string // name, number or decoded string
+	List     []Value
+	Object   map[string]Value
+	Variable string
+}
+
+type ValueKind int
+
+const (
+	ValueNull ValueKind = iota
+	ValueInt
+	ValueFloat
+	ValueString
+	ValueBoolean
+	ValueEnum
+	ValueList
+	ValueObject
+	ValueVariable
+)
+
+// Operation is a parsed query operation.
+type Operation struct {
+	Name       string
+	Defaults   map[string]Value // variable defaults
+	Selections []Field
+}
+
+// Parse parses a query document. Only query operations are supported;
+// fragments, directives, mutations and subscriptions are rejected.
+func Parse(source string) ([]Operation, error) {
+	p := &parser{lex: lexer{src: source}}
+	p.next()
+
+	var ops []Operation
+	for p.tok.kind != tokEOF {
+		op, err := p.operation()
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, op)
+	}
+	if len(ops) == 0 {
+		return nil, fmt.Errorf("document has no operations")
+	}
+	return ops, nil
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokPunct
+	tokName
+	tokInt
+	tokFloat
+	tokString
+)
+
+type token struct {
+	kind tokenKind
+	val  string
+	pos  int
+}
+
+type lexer struct {
+	src string
+	pos int
+}
+
+func (l *lexer) next() (token, error) {
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			l.pos++
+		case c == '#':
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' {
+				l.pos++
+			}
+		default:
+			return l.token()
+		}
+	}
+	return token{kind: tokEOF, pos: l.pos}, nil
+}
+
+func (l *lexer) token() (token, error) {
+	start := l.pos
+	c := l.src[l.pos]
+	switch {
+	case strings.IndexByte("!$():=@[]{}|", c) >= 0:
+		l.pos++
+		return token{kind: tokPunct, val: string(c), pos: start}, nil
+	case c == '.':
+		if strings.HasPrefix(l.src[l.pos:], "...") {
+			l.pos += 3
+			return token{kind: tokPunct, val: "...", pos: start}, nil
+		}
+	case c == '_' || isLetter(c):
+		for l.pos < len(l.src) && (l.src[l.pos] == '_' || isLetter(l.src[l.pos]) || isDigit(l.src[l.pos])) {
+			l.pos++
+		}
+		return token{kind: tokName, val: l.src[start:l.pos], pos: start}, nil
+	case c == '-' || isDigit(c):
+		return l.number()
+	case c == '"':
+		return l.string()
+	}
+	return token{}, fmt.Errorf("unexpected character %q at offset %d", c, start)
+}
+
+func (l *lexer) number() (token, error) {
+	start := l.pos
+	kind := tokInt
+	if l.src[l.pos] == '-' {
+		l.pos++
+	}
+	digits := func() {
+		for l.pos < len(l.src) && isDigit(l.src[l.pos]) {
+			l.pos++
+		}
+	}
+	digits()
+	if l.pos < len(l.src) && l.src[l.pos] == '.' {
+		kind = tokFloat
+		l.pos++
+		digits()
+	}
+	if l.pos < len(l.src) && (l.src[l.pos] == 'e' || l.src[l.pos] == 'E') {
+		kind = tokFloat
+		l.pos++
+		if l.pos < len(l.src) && (l.src[l.pos] == '+' || l.src[l.pos] == '-') {
+			l.pos++
+		}
+		digits()
+	}
+	raw := l.src[start:l.pos]
+	if _, err := strconv.ParseFloat(raw, 64); err != nil {
+		return token{}, fmt.Errorf("invalid number %q at offset %d", raw, start)
+	}
+	return token{kind: kind, val: raw, pos: start}, nil
+}
+
+func (l *lexer) string() (token, error) {
+	start := l.pos
+	l.pos++
+	var b strings.Builder
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case c == '"':
+			l.pos++
+			return token{kind: tokString, val: b.String(), pos: start}, nil
+		case c == '\n' || c == '\r':
+			return token{}, fmt.Errorf("unterminated string at offset %d", start)
+		case c == '\\' && l.pos+1 < len(l.src):
+			esc := l.src[l.pos+1]
+			l.pos += 2
+			switch esc {
+			case '"', '\\', '/':
+				b.WriteByte(esc)
+			case 'b':
+				b.WriteByte('\b')
+			case 'f':
+				b.WriteByte('\f')
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case 'u':
+				if l.pos+4 > len(l.src) {
+					return token{}, fmt.Errorf("invalid unicode escape at offset %d", l.pos)
+				}
+				r, err := strconv.ParseUint(l.src[l.pos:l.pos+4], 16, 32)
+				if err != nil {
+					return token{}, fmt.Errorf("invalid unicode escape at offset %d", l.pos)
+				}
+				b.WriteRune(rune(r))
+				l.pos += 4
+			default:
+				return token{}, fmt.Errorf("invalid escape \\%c at offset %d", esc, l.pos-2)
+			}
+		default:
+			r, size := utf8.DecodeRuneInString(l.src[l.pos:])
+			b.WriteRune(r)
+			l.pos += size
+		}
+	}
+	return token{}, fmt.Errorf("unterminated string at offset %d", start)
+}
+
+func isLetter(c byte) bool { return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') }
+func isDigit(c byte) bool  { return c >= '0' && c <= '9' }
+
+type parser struct {
+	lex lexer
+	tok token
+	err error
+}
+
+func (p *parser) next() {
+	if p.err != nil {
+		return
+	}
+	p.tok, p.err = p.lex.next()
+	if p.err != nil {
+		p.tok = token{kind: tokEOF}
+	}
+}
+
+func (p *parser) peek(punct string) bool {
+	return p.tok.kind == tokPunct && p.tok.val == punct
+}
+
+func (p *parser) expect(punct string) error {
+	if p.err != nil {
+		return p.err
+	}
+	if !p.peek(punct) {
+		return p.unexpected("\"" + punct + "\"")
+	}
+	p.next()
+	return p.err
+}
+
+func (p *parser) name() (string, error) {
+	if p.err != nil {
+		return "", p.err
+	}
+	if p.tok.kind != tokName {
+		return "", p.unexpected("a name")
+	}
+	n := p.tok.val
+	p.next()
+	return n, p.err
+}
+
+func (p *parser) unexpected(want string) error {
+	if p.err != nil {
+		return p.err
+	}
+	if p.tok.kind == tokEOF {
+		return fmt.Errorf("expected %s, got end of query", want)
+	}
+	return fmt.Errorf("expected %s, got %q at offset %d", want, p.tok.val, p.tok.pos)
+}
+
+func (p *parser) operation() (Operation, error) {
+	var op Operation
+	if p.peek("{") {
+		sels, err := p.selectionSet()
+		op.Selections = sels
+		return op, err
+	}
+	if p.tok.kind != tokName {
+		return op, p.unexpected("an operation")
+	}
+	switch p.tok.val {
+	case "query":
+	case "fragment":
+		return op, fmt.Errorf("fragments are not supported")
+	default:
+		return op, fmt.Errorf("%s operations are not supported", p.tok.val)
+	}
+	p.next()
+
+	if p.tok.kind == tokName {
+		op.Name = p.tok.val
+		p.next()
+	}
+	if p.peek("(") {
+		defaults, err := p.variableDefinitions()
+		if err != nil {
+			return op, err
+		}
+		op.Defaults = defaults
+	}
+	if p.peek("@") {
+		return op, fmt.Errorf("directives are not supported")
+	}
+	sels, err := p.selectionSet()
+	op.Selections = sels
+	return op, err
+}
+
+func (p *parser) variableDefinitions() (map[string]Value, error) {
+	defaults := map[string]Value{}
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	for !p.peek(")") {
+		if err := p.expect("$"); err != nil {
+			return nil, err
+		}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		if err := p.typeRef(); err != nil {
+			return nil, err
+		}
+		if p.peek("=") {
+			p.next()
+			v, err := p.value(true)
+			if err != nil {
+				return nil, err
+			}
+			defaults[name] = v
+		}
+	}
+	return defaults, p.expect(")")
+}
+
+// typeRef skips a variable's type; values are checked by the resolvers.
+func (p *parser) typeRef() error {
+	if p.peek("[") {
+		p.next()
+		if err := p.typeRef(); err != nil {
+			return err
+		}
+		if err := p.expect("]"); err != nil {
+			return err
+		}
+	} else if _, err := p.name(); err != nil {
+		return err
+	}
+	if p.peek("!") {
+		p.next()
+	}
+	return p.err
+}
+
+func (p *parser) selectionSet() ([]Field, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var fields []Field
+	for !p.peek("}") {
+		if p.peek("...") {
+			return nil, fmt.Errorf("fragments are not supported")
+		}
+		f, err := p.field()
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, f)
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty selection set")
+	}
+	return fields, p.expect("}")
+}
+
+func (p *parser) field() (Field, error) {
+	name, err := p.name()
+	if err != nil {
+		return Field{}, err
+	}
+	f := Field{Alias: name, Name: name}
+	if p.peek(":") {
+		p.next()
+		if f.Name, err = p.name(); err != nil {
+			return f, err
+		}
+	}
+	if p.peek("(") {
+		p.next()
+		f.Args = map[string]Value{}
+		for !p.peek(")") {
+			arg, err := p.name()
+			if err != nil {
+				return f, err
+			}
+			if err := p.expect(":"); err != nil {
+				return f, err
+			}
+			if f.Args[arg], err = p.value(false); err != nil {
+				return f, err
+			}
+		}
+		if err := p.expect(")"); err != nil {
+			return f, err
+		}
+	}
+	if p.peek("@") {
+		return f, fmt.Errorf("directives are not supported")
+	}
+	if p.peek("{") {
+		if f.Selections, err = p.selectionSet(); err != nil {
+			return f, err
+		}
+	}
+	return f, p.err
+}
+
+func (p *parser) value(constant bool) (Value, error) {
+	if p.err != nil {
+		return Value{}, p.err
+	}
+	tok := p.tok
+	switch tok.kind {
+	case tokInt:
+		p.next()
+		return Value{Kind: ValueInt, Raw: tok.val}, p.err
+	case tokFloat:
+		p.next()
+		return Value{Kind: ValueFloat, Raw: tok.val}, p.err
+	case tokString:
+		p.next()
+		return Value{Kind: ValueString, Raw: tok.val}, p.err
+	case tokName:
+		p.next()
+		switch tok.val {
+		case "true", "false":
+			return Value{Kind: ValueBoolean, Raw: tok.val}, p.err
+		case "null":
+			return Value{Kind: ValueNull}, p.err
+		}
+		return Value{Kind: ValueEnum, Raw: tok.val}, p.err
+	case tokPunct:
+		switch tok.val {
+		case "$":
+			if constant {
+				return Value{}, fmt.Errorf("variables are not allowed in default values")
+			}
+			p.next()
+			name, err := p.name()
+			return Value{Kind: ValueVariable, Variable: name}, err
+		case "[":
+			p.next()
+			v := Value{Kind: ValueList}
+			for !p.peek("]") {
+				item, err := p.value(constant)
+				if err != nil {
+					return v, err
+				}
+				v.List = append(v.List, item)
+			}
+			return v, p.expect("]")
+		case "{":
+			p.next()
+			v := Value{Kind: ValueObject, Object: map[string]Value{}}
+			for !p.peek("}") {
+				key, err := p.name()
+				if err != nil {
+					return v, err
+				}
+				if err := p.expect(":"); err != nil {
+					return v, err
+				}
+				if v.Object[key], err = p.value(constant); err != nil {
+					return v, err
+				}
+			}
+			return v, p.expect("}")
+		}
+	}
+	return Value{}, p.unexpected("a value")
+}
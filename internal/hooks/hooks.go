@@ -0,0 +1,108 @@
+// Package hooks runs user-configured commands when events are broadcast,
+// passing the event JSON (the same payload WebSocket clients receive) on
+// stdin. Hooks let local integrations react to sessions without a fork.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Hook runs Command with Args whenever an event of type Event is broadcast.
+type Hook struct {
+	Event   string
+	Command string
+	Args    []string
+	Timeout time.Duration
+}
+
+// Runner dispatches events to the hooks registered for their type.
+type Runner struct {
+	hooks map[string][]Hook
+	wg    sync.WaitGroup
+}
+
+func NewRunner(hooks []Hook) *Runner {
+	byEvent := make(map[string][]Hook)
+	for _, h := range hooks {
+		byEvent[h.Event] = append(byEvent[h.Event], h)
+	}
+	return &Runner{hooks: byEvent}
+}
+
+// Run dispatches every payload received on events until the channel closes
+// or ctx is cancelled, then waits for running hooks to finish.
+func (r *Runner) Run(ctx context.Context, events <-chan []byte) {
+	defer r.wg.Wait()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case payload, ok := <-events:
+			if !ok {
+				return
+			}
+			r.Dispatch(ctx, payload)
+		}
+	}
+}
+
+// Dispatch starts the hooks matching payload's event type in the background.
+func (r *Runner) Dispatch(ctx context.Context, payload []byte) {
+	var event struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return
+	}
+	for _, h := range r.hooks[event.Type] {
+		r.wg.Add(1)
+		go func() {
+			defer r.wg.Done()
+			if err := runHook(ctx, h, event.Type, payload); err != nil {
+				slog.Warn("hook failed", "event", event.Type, "command", h.Command, "error", err)
+			}
+		}()
+	}
+}
+
+// Wait blocks until all dispatched hooks have exited.
+func (r *Runner) Wait() {
+	r.wg.Wait()
+}
+
+func runHook(ctx context.Context, h Hook, eventType string, payload []byte) error {
+	if h.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.Timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, h.Command, h.Args...)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Env = append(os.Environ(), "GHOST_WISPR_EVENT="+eventType)
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	// Don't wait on grandchildren holding the output pipe open past the timeout.
+	cmd.WaitDelay = time.Second
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			err = fmt.Errorf("timed out after %s", h.Timeout)
+		}
+		if out := strings.TrimSpace(output.String()); out != "" {
+			return fmt.Errorf("%w: %s", err, out)
+		}
+		return err
+	}
+	return nil
+}
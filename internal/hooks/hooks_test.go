@@ -0,0 +1,60 @@
+package hooks
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDispatchRunsMatchingHooksWithPayload(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out")
+	runner := NewRunner([]Hook{
+		{Event: "session_ended", Command: "sh", Args: []string{"-c", `{ echo "$GHOST_WISPR_EVENT"; cat; } > "$0"`, out}, Timeout: 5 * time.Second},
+		{Event: "summary_ready", Command: "sh", Args: []string{"-c", `touch "$0"`, filepath.Join(dir, "unexpected")}},
+	})
+
+	payload := `{"type":"session_ended","session_id":"s1"}`
+	runner.Dispatch(context.Background(), []byte(payload))
+	runner.Wait()
+
+	got, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("hook did not run: %v", err)
+	}
+	if string(got) != "session_ended\n"+payload {
+		t.Fatalf("unexpected hook input %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "unexpected")); err == nil {
+		t.Fatal("summary_ready hook should not run for session_ended")
+	}
+}
+
+func TestRunHookTimeout(t *testing.T) {
+	start := time.Now()
+	err := runHook(context.Background(), Hook{Command: "sleep", Args: []string{"5"}, Timeout: 50 * time.Millisecond}, "session_ended", nil)
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("expected timeout error, got %v", err)
+	}
+	if time.Since(start) > 3*time.Second {
+		t.Fatal("hook was not killed at its timeout")
+	}
+}
+
+func TestRunStopsWhenChannelCloses(t *testing.T) {
+	events := make(chan []byte)
+	done := make(chan struct{})
+	go func() {
+		NewRunner(nil).Run(context.Background(), events)
+		close(done)
+	}()
+	close(events)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after channel closed")
+	}
+}
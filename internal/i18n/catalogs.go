@@ -0,0 +1,587 @@
+package i18n
+
+// Message keys. Each catalog holds a fmt format per key, taking the same
+// arguments in the same order as English.
+const (
+	WarnGDriveMemoryStore     = "warning.gdrive_memory_store"
+	WarnGDriveInitFailed      = "warning.gdrive_init_failed"
+	WarnMicUnavailable        = "warning.mic_unavailable"
+	WarnMicStartFailed        = "warning.mic_start_failed"
+	WarnDeepgramInitFailed    = "warning.deepgram_init_failed"
+	WarnDeepgramConnectFailed = "warning.deepgram_connect_failed"
+	WarnDeepgramOffline       = "warning.deepgram_offline"
+
+	BudgetTranscriptionExceeded = "budget.transcription_exceeded"
+	BudgetCaptureContinues      = "budget.capture_continues"
+	BudgetCapturePaused         = "budget.capture_paused"
+	BudgetLLMExceeded           = "budget.llm_exceeded"
+	BudgetSummariesContinue     = "budget.summaries_continue"
+	BudgetSummariesFallback     = "budget.summaries_fallback"
+	BudgetSummariesDisabled     = "budget.summaries_disabled"
+
+	WatchdogMemoryOver = "watchdog.memory_over"
+	WatchdogCPUOver    = "watchdog.cpu_over"
+
+	InputClipping = "input.clipping"
+	InputSilent   = "input.silent"
+
+	NoteSuspended = "note.suspended"
+
+	DocDecisionLog     = "doc.decision_log"
+	DocDecisionSession = "doc.decision_session"
+	DocMeetingsOn      = "doc.meetings_on"
+	DocNoMeetings      = "doc.no_meetings"
+	DocDecided         = "doc.decided"
+	DocNoSummary       = "doc.no_summary"
+	DocMore            = "doc.more"
+	DocVoiceMemo       = "doc.voice_memo"
+	DocMeeting         = "doc.meeting"
+	DocInProgress      = "doc.in_progress"
+	DocBriefTitle      = "doc.brief_title"
+	DocOpenActionItems = "doc.open_action_items"
+	DocNone            = "doc.none"
+	DocLastMeeting     = "doc.last_meeting"
+	DocSessionTitle    = "doc.session_title"
+	DocStarted         = "doc.started"
+	DocSpeaker         = "doc.speaker"
+	DocSummary         = "doc.summary"
+	DocTranscript      = "doc.transcript"
+	DocJournalTitle    = "doc.journal_title"
+
+	DocNoParticipants = "doc.no_participants"
+	DocMinutes        = "doc.minutes"
+	DocHoursMinutes   = "doc.hours_minutes"
+	DateLong          = "date.long"
+	DateWeekdays      = "date.weekdays"
+	DateMonths        = "date.months"
+
+	ReportTitle      = "report.title"
+	ReportDatabase   = "report.database"
+	ReportAudio      = "report.audio"
+	ReportSessions   = "report.sessions"
+	ReportLLM        = "report.llm"
+	ReportFailures   = "report.failures"
+	ReportNoFailures = "report.no_failures"
+
+	ConfigInvalidDefault      = "config.invalid_default"
+	ConfigInvalidCountDefault = "config.invalid_count_default"
+	ConfigInvalidChoice       = "config.invalid_choice"
+	ConfigNegative            = "config.negative"
+	ConfigBelowZeroDB         = "config.below_zero_db"
+	ConfigInvalidMs           = "config.invalid_ms"
+	ConfigPositive            = "config.positive"
+	ConfigAtLeastOne          = "config.at_least_one"
+	ConfigBudgetNegative      = "config.budget_negative"
+	ConfigUnknownEntry        = "config.unknown_entry"
+	ConfigInvalidModel        = "config.invalid_model"
+	ConfigAPIKeyMissing       = "config.api_key_missing"
+	ConfigProviderKeyMissing  = "config.provider_key_missing"
+	ConfigWhisperKeyMissing   = "config.whisper_key_missing"
+	ConfigNoTranscription     = "config.no_transcription"
+	ConfigNoDefaultPreset     = "config.no_default_preset"
+	ConfigUnknownMemoPreset   = "config.unknown_memo_preset"
+	ConfigReadingLevel        = "config.reading_level"
+	ConfigAudioBitrate        = "config.audio_bitrate"
+	ConfigAudioQuality        = "config.audio_quality"
+	ConfigPipeWireIgnores     = "config.pipewire_ignores"
+	ConfigMicDevicesOverride  = "config.mic_devices_override"
+	ConfigMicChannelsLoopback = "config.mic_channels_loopback"
+	ConfigMicDeviceUnnamed    = "config.mic_device_unnamed"
+	ConfigMicDeviceGain       = "config.mic_device_gain"
+	ConfigEmptyLanguage       = "config.empty_language"
+	ConfigMinConfidence       = "config.min_confidence"
+	ConfigInterimInterval     = "config.interim_interval"
+	ConfigVADHangover         = "config.vad_hangover"
+	ConfigHighPass            = "config.high_pass"
+	ConfigWatchNeedsDeepgram  = "config.watch_needs_deepgram"
+	ConfigTTSNeedsOpenAI      = "config.tts_needs_openai"
+	ConfigTTSNeedsPiperModel  = "config.tts_needs_piper_model"
+	ConfigTTSProvider         = "config.tts_provider"
+	ConfigMQTTPrefix          = "config.mqtt_prefix"
+	ConfigVoiceThreshold      = "config.voice_threshold"
+	ConfigSeriesNegative      = "config.series_negative"
+	ConfigRetention           = "config.retention"
+	ConfigHookIncomplete      = "config.hook_incomplete"
+	ConfigHookTimeout         = "config.hook_timeout"
+	ConfigTokenIncomplete     = "config.token_incomplete"
+	ConfigTokenScope          = "config.token_scope"
+	ConfigTokenUnset          = "config.token_unset"
+	ConfigNoTokens            = "config.no_tokens"
+	ConfigTrustedProxy        = "config.trusted_proxy"
+	ConfigExportUnnamed       = "config.export_unnamed"
+	ConfigExportDuplicate     = "config.export_duplicate"
+	ConfigExportEvent         = "config.export_event"
+	ConfigExportTarget        = "config.export_target"
+	ConfigExportNeedsDir      = "config.export_needs_dir"
+	ConfigExportNeedsWebhook  = "config.export_needs_webhook"
+	ConfigExportNeedsFolder   = "config.export_needs_folder"
+	ConfigExportWebhookUnset  = "config.export_webhook_unset"
+	ConfigExportMinDuration   = "config.export_min_duration"
+)
+
+var catalogs = map[string]map[string]string{
+	"en": {
+		WarnGDriveMemoryStore:     "Google Drive sync is disabled for the in-memory store",
+		WarnGDriveInitFailed:      "Google Drive sync failed to initialize — backups are disabled",
+		WarnMicUnavailable:        "Microphone unavailable — record from a browser with “Use this browser's mic” in the web UI",
+		WarnMicStartFailed:        "Microphone failed to start — record from a browser with “Use this browser's mic” in the web UI",
+		WarnDeepgramInitFailed:    "Deepgram initialization failed — live transcription is disabled",
+		WarnDeepgramConnectFailed: "Deepgram connection failed — live transcription is disabled",
+		WarnDeepgramOffline:       "Deepgram was unreachable at startup — audio is recorded and transcribed once it connects",
+
+		BudgetTranscriptionExceeded: "Monthly transcription budget exceeded ($%.2f of $%.2f) — %s",
+		BudgetCaptureContinues:      "capture continues",
+		BudgetCapturePaused:         "capture is paused until next month",
+		BudgetLLMExceeded:           "Monthly LLM budget exceeded ($%.2f of $%.2f) — %s",
+		BudgetSummariesContinue:     "summaries continue",
+		BudgetSummariesFallback:     "summaries use %s until next month",
+		BudgetSummariesDisabled:     "automatic summaries are disabled until next month",
+
+		WatchdogMemoryOver: "Memory use %.0f MB is over the appliance limit of %d MB",
+		WatchdogCPUOver:    "CPU use %.0f%% is over the appliance limit of %.0f%%",
+
+		InputClipping: "Input has been clipping for %.0fs — turn the microphone gain down",
+		InputSilent:   "Microphone silent for %.0f minutes while recording — check that it is connected and unmuted",
+
+		NoteSuspended: "Suspended — the computer went to sleep",
+
+		DocDecisionLog:     "Decision Log",
+		DocDecisionSession: "session %s",
+		DocMeetingsOn:      "Meetings on %s",
+		DocNoMeetings:      "No meetings recorded.",
+		DocDecided:         "Decided: %s",
+		DocNoSummary:       "No summary yet.",
+		DocMore:            "…%d more",
+		DocVoiceMemo:       "Voice memo",
+		DocMeeting:         "Meeting",
+		DocInProgress:      "%s (in progress)",
+		DocBriefTitle:      "Brief: follow-up to %s",
+		DocOpenActionItems: "Open action items",
+		DocNone:            "None.",
+		DocLastMeeting:     "Last meeting",
+		DocSessionTitle:    "Session %s",
+		DocStarted:         "Started %s",
+		DocSpeaker:         "Speaker %d",
+		DocSummary:         "Summary",
+		DocTranscript:      "Transcript",
+		DocJournalTitle:    "Ghost Wispr journal %s",
+
+		DocNoParticipants: "Unknown",
+		DocMinutes:        "%d min",
+		DocHoursMinutes:   "%dh %02dm",
+		DateLong:          "%[1]s, %[2]s %[3]d, %[4]d",
+		DateWeekdays:      "Sunday,Monday,Tuesday,Wednesday,Thursday,Friday,Saturday",
+		DateMonths:        "January,February,March,April,May,June,July,August,September,October,November,December",
+
+		ReportTitle:      "Ghost Wispr weekly report, %s (%s to %s)",
+		ReportDatabase:   "Database: %s (%s this week)",
+		ReportAudio:      "Audio added: %s",
+		ReportSessions:   "Sessions: %d, %.0f minutes transcribed ($%.2f)",
+		ReportLLM:        "LLM: %d input and %d output tokens ($%.2f)",
+		ReportFailures:   "Failures: %d summaries, %d audio encodes, %d exports",
+		ReportNoFailures: "Failures: none",
+
+		ConfigInvalidDefault:      "Invalid %s %q — using default %s.",
+		ConfigInvalidCountDefault: "Invalid %s %d — using default %d.",
+		ConfigInvalidChoice:       "Invalid %s %q — must be one of %s. Using %s.",
+		ConfigNegative:            "Invalid %s %v — must be non-negative. Using %s.",
+		ConfigBelowZeroDB:         "Invalid %s %v — must be below 0 dBFS. Using %d.",
+		ConfigInvalidMs:           "Invalid %s %q — must be a non-negative integer (ms). Using Deepgram default.",
+		ConfigPositive:            "Invalid %s %d — must be positive. Using %d.",
+		ConfigAtLeastOne:          "Invalid %s %d — must be at least 1. Using 1.",
+		ConfigBudgetNegative:      "Invalid %s %v — must be non-negative. Budget disabled.",
+		ConfigUnknownEntry:        "Unknown %s entry %q — must be one of %s. Ignoring it.",
+		ConfigInvalidModel:        "Invalid %s model %q — %v.",
+		ConfigAPIKeyMissing:       "%s API key not configured — set %s.",
+		ConfigProviderKeyMissing:  "%s API key not configured — set %s. Skipping %s transcription.",
+		ConfigWhisperKeyMissing:   "Whisper transcription needs an OpenAI API key or transcription.whisper.base_url. Skipping whisper transcription.",
+		ConfigNoTranscription:     "Deepgram API key not configured — live transcription is disabled. Set %s.",
+		ConfigNoDefaultPreset:     "No default summarization preset configured — set summarization.presets.default.",
+		ConfigUnknownMemoPreset:   "Unknown summarization.memo_preset %q — voice memos use the default preset.",
+		ConfigReadingLevel:        "Invalid %s %q — must be one of %s. Leaving it to the prompt.",
+		ConfigAudioBitrate:        "Invalid audio_bitrate %d — must be 0 (variable bitrate) or 8-320 kbps. Using 128.",
+		ConfigAudioQuality:        "Invalid audio_quality %d — must be 0 (best) to 9 (smallest). Using 4.",
+		ConfigPipeWireIgnores:     "%s is ignored by the pipewire capture backend — set pipewire_target instead.",
+		ConfigMicDevicesOverride:  "%s is ignored when mic_devices is set — capturing the mix instead.",
+		ConfigMicChannelsLoopback: "mic_channels %q can't be combined with loopback.mode %q — mixing the microphones instead.",
+		ConfigMicDeviceUnnamed:    "mic_devices[%d] has no name — it will fail to open.",
+		ConfigMicDeviceGain:       "Invalid gain %v for mic device %q — must be non-negative. Using 1.0.",
+		ConfigEmptyLanguage:       "Empty transcription.language — using en-US.",
+		ConfigMinConfidence:       "Invalid transcription.min_confidence %v — must be at least 0 and below 1. Keeping every word.",
+		ConfigInterimInterval:     "Invalid transcription.interim_interval %q — must be a duration from 0 to %s. Using 200ms.",
+		ConfigVADHangover:         "transcription.vad.hangover %s is not longer than utterance_end_ms %dms — Deepgram may not finalize utterances before streaming pauses.",
+		ConfigHighPass:            "Invalid dsp.high_pass_hz %v — must be between 0 and 1000. Using 100.",
+		ConfigWatchNeedsDeepgram:  "Importing the watch folder needs a Deepgram API key — set %s. Watch folder disabled.",
+		ConfigTTSNeedsOpenAI:      "Summary audio needs an OpenAI API key — set %s. Summary audio disabled.",
+		ConfigTTSNeedsPiperModel:  "tts.piper_model is not set — piper needs a voice model. Summary audio disabled.",
+		ConfigTTSProvider:         "Invalid tts.provider %q — must be one of %s. Summary audio disabled.",
+		ConfigMQTTPrefix:          "mqtt.topic_prefix is empty — using %q.",
+		ConfigVoiceThreshold:      "Invalid voice_profiles.threshold %g — must be between 0 and 1. Using 0.75.",
+		ConfigSeriesNegative:      "Invalid series.lookback_weeks %d — must be non-negative. Series detection disabled.",
+		ConfigRetention:           "Invalid %s %q — keeping these sessions forever.",
+		ConfigHookIncomplete:      "hooks[%d] needs both event and command — skipping it.",
+		ConfigHookTimeout:         "Invalid timeout %q for %s hook %q — using default 30s.",
+		ConfigTokenIncomplete:     "auth.tokens[%d] needs both name and token_env — skipping it.",
+		ConfigTokenScope:          "Invalid scope %q for API token %q — must be one of %s. Skipping it.",
+		ConfigTokenUnset:          "API token %q is not set — set %s. Skipping it.",
+		ConfigNoTokens:            "No usable API tokens — the API is open to anyone who can reach it.",
+		ConfigTrustedProxy:        "Invalid auth.trusted_proxies entry %q — use an IP address or CIDR range. Skipping it.",
+		ConfigExportUnnamed:       "exports[%d] needs a name — skipping it.",
+		ConfigExportDuplicate:     "Duplicate export rule %q — skipping it.",
+		ConfigExportEvent:         "Invalid event %q for export rule %q — must be one of %s. Skipping it.",
+		ConfigExportTarget:        "Invalid target %q for export rule %q — must be one of %s. Skipping it.",
+		ConfigExportNeedsDir:      "Export rule %q needs a dir — skipping it.",
+		ConfigExportNeedsWebhook:  "Export rule %q needs webhook_url_env — skipping it.",
+		ConfigExportNeedsFolder:   "Export rule %q needs a folder_id — skipping it.",
+		ConfigExportWebhookUnset:  "Slack webhook for export rule %q is not set — set %s. Skipping it.",
+		ConfigExportMinDuration:   "Invalid min_duration %q for export rule %q — exporting sessions of any length.",
+	},
+	"es": {
+		WarnGDriveMemoryStore:     "La sincronización con Google Drive está desactivada con el almacenamiento en memoria",
+		WarnGDriveInitFailed:      "No se pudo iniciar la sincronización con Google Drive — las copias de seguridad están desactivadas",
+		WarnMicUnavailable:        "Micrófono no disponible — graba desde un navegador con «Usar el micrófono de este navegador» en la interfaz web",
+		WarnMicStartFailed:        "No se pudo iniciar el micrófono — graba desde un navegador con «Usar el micrófono de este navegador» en la interfaz web",
+		WarnDeepgramInitFailed:    "No se pudo iniciar Deepgram — la transcripción en directo está desactivada",
+		WarnDeepgramConnectFailed: "No se pudo conectar con Deepgram — la transcripción en directo está desactivada",
+		WarnDeepgramOffline:       "Deepgram no estaba disponible al arrancar — el audio se graba y se transcribe cuando se conecte",
+
+		BudgetTranscriptionExceeded: "Presupuesto mensual de transcripción superado ($%.2f de $%.2f) — %s",
+		BudgetCaptureContinues:      "la captura continúa",
+		BudgetCapturePaused:         "la captura está en pausa hasta el mes que viene",
+		BudgetLLMExceeded:           "Presupuesto mensual de LLM superado ($%.2f de $%.2f) — %s",
+		BudgetSummariesContinue:     "los resúmenes continúan",
+		BudgetSummariesFallback:     "los resúmenes usan %s hasta el mes que viene",
+		BudgetSummariesDisabled:     "los resúmenes automáticos están desactivados hasta el mes que viene",
+
+		WatchdogMemoryOver: "El uso de memoria de %.0f MB supera el límite del equipo de %d MB",
+		WatchdogCPUOver:    "El uso de CPU del %.0f%% supera el límite del equipo del %.0f%%",
+
+		InputClipping: "La entrada lleva %.0fs saturada — baja la ganancia del micrófono",
+		InputSilent:   "Micrófono en silencio durante %.0f minutos mientras se graba — comprueba que esté conectado y sin silenciar",
+
+		NoteSuspended: "Suspendido — el equipo entró en reposo",
+
+		DocDecisionLog:     "Registro de decisiones",
+		DocDecisionSession: "sesión %s",
+		DocMeetingsOn:      "Reuniones del %s",
+		DocNoMeetings:      "No hay reuniones grabadas.",
+		DocDecided:         "Decidido: %s",
+		DocNoSummary:       "Aún no hay resumen.",
+		DocMore:            "…%d más",
+		DocVoiceMemo:       "Nota de voz",
+		DocMeeting:         "Reunión",
+		DocInProgress:      "%s (en curso)",
+		DocBriefTitle:      "Resumen previo: seguimiento de %s",
+		DocOpenActionItems: "Tareas pendientes",
+		DocNone:            "Ninguna.",
+		DocLastMeeting:     "Última reunión",
+		DocSessionTitle:    "Sesión %s",
+		DocStarted:         "Inicio: %s",
+		DocSpeaker:         "Hablante %d",
+		DocSummary:         "Resumen",
+		DocTranscript:      "Transcripción",
+		DocJournalTitle:    "Diario de Ghost Wispr %s",
+
+		DocNoParticipants: "Desconocidos",
+		DocMinutes:        "%d min",
+		DocHoursMinutes:   "%d h %02d min",
+		DateLong:          "%[1]s, %[3]d de %[2]s de %[4]d",
+		DateWeekdays:      "domingo,lunes,martes,miércoles,jueves,viernes,sábado",
+		DateMonths:        "enero,febrero,marzo,abril,mayo,junio,julio,agosto,septiembre,octubre,noviembre,diciembre",
+
+		ReportTitle:      "Informe semanal de Ghost Wispr, %s (del %s al %s)",
+		ReportDatabase:   "Base de datos: %s (%s esta semana)",
+		ReportAudio:      "Audio añadido: %s",
+		ReportSessions:   "Sesiones: %d, %.0f minutos transcritos ($%.2f)",
+		ReportLLM:        "LLM: %d tokens de entrada y %d de salida ($%.2f)",
+		ReportFailures:   "Fallos: %d resúmenes, %d codificaciones de audio, %d exportaciones",
+		ReportNoFailures: "Fallos: ninguno",
+
+		ConfigInvalidDefault:      "%s %q no es válido — se usa el valor predeterminado %s.",
+		ConfigInvalidCountDefault: "%s %d no es válido — se usa el valor predeterminado %d.",
+		ConfigInvalidChoice:       "%s %q no es válido — debe ser uno de %s. Se usa %s.",
+		ConfigNegative:            "%s %v no es válido — no puede ser negativo. Se usa %s.",
+		ConfigBelowZeroDB:         "%s %v no es válido — debe ser inferior a 0 dBFS. Se usa %d.",
+		ConfigInvalidMs:           "%s %q no es válido — debe ser un entero no negativo (ms). Se usa el valor predeterminado de Deepgram.",
+		ConfigPositive:            "%s %d no es válido — debe ser positivo. Se usa %d.",
+		ConfigAtLeastOne:          "%s %d no es válido — debe ser al menos 1. Se usa 1.",
+		ConfigBudgetNegative:      "%s %v no es válido — no puede ser negativo. Presupuesto desactivado.",
+		ConfigUnknownEntry:        "Entrada %[2]q desconocida en %[1]s — debe ser una de %[3]s. Se ignora.",
+		ConfigInvalidModel:        "Modelo %[2]q de %[1]s no válido — %[3]v.",
+		ConfigAPIKeyMissing:       "Clave de API de %s no configurada — define %s.",
+		ConfigProviderKeyMissing:  "Clave de API de %s no configurada — define %s. Se omite la transcripción con %s.",
+		ConfigWhisperKeyMissing:   "La transcripción con Whisper necesita una clave de API de OpenAI o transcription.whisper.base_url. Se omite la transcripción con whisper.",
+		ConfigNoTranscription:     "Clave de API de Deepgram no configurada — la transcripción en directo está desactivada. Define %s.",
+		ConfigNoDefaultPreset:     "No hay un preset de resumen predeterminado — define summarization.presets.default.",
+		ConfigUnknownMemoPreset:   "summarization.memo_preset %q desconocido — las notas de voz usan el preset predeterminado.",
+		ConfigReadingLevel:        "%s %q no es válido — debe ser uno de %s. Se deja en manos del prompt.",
+		ConfigAudioBitrate:        "audio_bitrate %d no es válido — debe ser 0 (tasa variable) o 8-320 kbps. Se usa 128.",
+		ConfigAudioQuality:        "audio_quality %d no es válido — debe ir de 0 (mejor) a 9 (más pequeño). Se usa 4.",
+		ConfigPipeWireIgnores:     "El backend de captura pipewire ignora %s — define pipewire_target en su lugar.",
+		ConfigMicDevicesOverride:  "%s se ignora cuando mic_devices está definido — se captura la mezcla.",
+		ConfigMicChannelsLoopback: "mic_channels %q no se puede combinar con loopback.mode %q — se mezclan los micrófonos.",
+		ConfigMicDeviceUnnamed:    "mic_devices[%d] no tiene nombre — no se podrá abrir.",
+		ConfigMicDeviceGain:       "Ganancia %v no válida para el micrófono %q — no puede ser negativa. Se usa 1.0.",
+		ConfigEmptyLanguage:       "transcription.language está vacío — se usa en-US.",
+		ConfigMinConfidence:       "transcription.min_confidence %v no es válido — debe ser al menos 0 e inferior a 1. Se conservan todas las palabras.",
+		ConfigInterimInterval:     "transcription.interim_interval %q no es válido — debe ser una duración de 0 a %s. Se usa 200ms.",
+		ConfigVADHangover:         "transcription.vad.hangover %s no es mayor que utterance_end_ms %dms — puede que Deepgram no cierre las frases antes de que se pause el envío.",
+		ConfigHighPass:            "dsp.high_pass_hz %v no es válido — debe estar entre 0 y 1000. Se usa 100.",
+		ConfigWatchNeedsDeepgram:  "Importar la carpeta vigilada necesita una clave de API de Deepgram — define %s. Carpeta vigilada desactivada.",
+		ConfigTTSNeedsOpenAI:      "El audio de los resúmenes necesita una clave de API de OpenAI — define %s. Audio de resúmenes desactivado.",
+		ConfigTTSNeedsPiperModel:  "tts.piper_model no está definido — piper necesita un modelo de voz. Audio de resúmenes desactivado.",
+		ConfigTTSProvider:         "tts.provider %q no es válido — debe ser uno de %s. Audio de resúmenes desactivado.",
+		ConfigMQTTPrefix:          "mqtt.topic_prefix está vacío — se usa %q.",
+		ConfigVoiceThreshold:      "voice_profiles.threshold %g no es válido — debe estar entre 0 y 1. Se usa 0.75.",
+		ConfigSeriesNegative:      "series.lookback_weeks %d no es válido — no puede ser negativo. Detección de series desactivada.",
+		ConfigRetention:           "%s %q no es válido — estas sesiones se conservan para siempre.",
+		ConfigHookIncomplete:      "hooks[%d] necesita event y command — se omite.",
+		ConfigHookTimeout:         "Timeout %q no válido para el hook %[2]s %[3]q — se usa el valor predeterminado 30s.",
+		ConfigTokenIncomplete:     "auth.tokens[%d] necesita name y token_env — se omite.",
+		ConfigTokenScope:          "Ámbito %q no válido para el token de API %q — debe ser uno de %s. Se omite.",
+		ConfigTokenUnset:          "El token de API %q no está definido — define %s. Se omite.",
+		ConfigNoTokens:            "No hay tokens de API utilizables — la API está abierta a cualquiera que pueda alcanzarla.",
+		ConfigTrustedProxy:        "Entrada %q de auth.trusted_proxies no válida — usa una dirección IP o un rango CIDR. Se omite.",
+		ConfigExportUnnamed:       "exports[%d] necesita un nombre — se omite.",
+		ConfigExportDuplicate:     "Regla de exportación %q duplicada — se omite.",
+		ConfigExportEvent:         "Evento %q no válido para la regla de exportación %q — debe ser uno de %s. Se omite.",
+		ConfigExportTarget:        "Destino %q no válido para la regla de exportación %q — debe ser uno de %s. Se omite.",
+		ConfigExportNeedsDir:      "La regla de exportación %q necesita un dir — se omite.",
+		ConfigExportNeedsWebhook:  "La regla de exportación %q necesita webhook_url_env — se omite.",
+		ConfigExportNeedsFolder:   "La regla de exportación %q necesita un folder_id — se omite.",
+		ConfigExportWebhookUnset:  "El webhook de Slack de la regla de exportación %q no está definido — define %s. Se omite.",
+		ConfigExportMinDuration:   "min_duration %q no válido para la regla de exportación %q — se exportan sesiones de cualquier duración.",
+	},
+	"fr": {
+		WarnGDriveMemoryStore:     "La synchronisation Google Drive est désactivée avec le stockage en mémoire",
+		WarnGDriveInitFailed:      "Échec de l'initialisation de la synchronisation Google Drive — les sauvegardes sont désactivées",
+		WarnMicUnavailable:        "Microphone indisponible — enregistrez depuis un navigateur avec « Utiliser le micro de ce navigateur » dans l'interface web",
+		WarnMicStartFailed:        "Le microphone n'a pas pu démarrer — enregistrez depuis un navigateur avec « Utiliser le micro de ce navigateur » dans l'interface web",
+		WarnDeepgramInitFailed:    "Échec de l'initialisation de Deepgram — la transcription en direct est désactivée",
+		WarnDeepgramConnectFailed: "Échec de la connexion à Deepgram — la transcription en direct est désactivée",
+		WarnDeepgramOffline:       "Deepgram était injoignable au démarrage — l'audio est enregistré et transcrit dès la connexion",
+
+		BudgetTranscriptionExceeded: "Budget mensuel de transcription dépassé (%.2f $ sur %.2f $) — %s",
+		BudgetCaptureContinues:      "la capture continue",
+		BudgetCapturePaused:         "la capture est en pause jusqu'au mois prochain",
+		BudgetLLMExceeded:           "Budget mensuel LLM dépassé (%.2f $ sur %.2f $) — %s",
+		BudgetSummariesContinue:     "les résumés continuent",
+		BudgetSummariesFallback:     "les résumés utilisent %s jusqu'au mois prochain",
+		BudgetSummariesDisabled:     "les résumés automatiques sont désactivés jusqu'au mois prochain",
+
+		WatchdogMemoryOver: "L'utilisation mémoire de %.0f Mo dépasse la limite de l'appareil de %d Mo",
+		WatchdogCPUOver:    "L'utilisation CPU de %.0f %% dépasse la limite de l'appareil de %.0f %%",
+
+		InputClipping: "L'entrée sature depuis %.0f s — baissez le gain du micro",
+		InputSilent:   "Micro silencieux depuis %.0f minutes pendant l'enregistrement — vérifiez qu'il est branché et non coupé",
+
+		NoteSuspended: "Suspendu — l'ordinateur s'est mis en veille",
+
+		DocDecisionLog:     "Journal des décisions",
+		DocDecisionSession: "session %s",
+		DocMeetingsOn:      "Réunions du %s",
+		DocNoMeetings:      "Aucune réunion enregistrée.",
+		DocDecided:         "Décidé : %s",
+		DocNoSummary:       "Pas encore de résumé.",
+		DocMore:            "…%d de plus",
+		DocVoiceMemo:       "Mémo vocal",
+		DocMeeting:         "Réunion",
+		DocInProgress:      "%s (en cours)",
+		DocBriefTitle:      "Note préparatoire : suite de %s",
+		DocOpenActionItems: "Actions en cours",
+		DocNone:            "Aucune.",
+		DocLastMeeting:     "Dernière réunion",
+		DocSessionTitle:    "Session %s",
+		DocStarted:         "Début : %s",
+		DocSpeaker:         "Intervenant %d",
+		DocSummary:         "Résumé",
+		DocTranscript:      "Transcription",
+		DocJournalTitle:    "Journal Ghost Wispr du %s",
+
+		DocNoParticipants: "Inconnus",
+		DocMinutes:        "%d min",
+		DocHoursMinutes:   "%d h %02d",
+		DateLong:          "%[1]s %[3]d %[2]s %[4]d",
+		DateWeekdays:      "dimanche,lundi,mardi,mercredi,jeudi,vendredi,samedi",
+		DateMonths:        "janvier,février,mars,avril,mai,juin,juillet,août,septembre,octobre,novembre,décembre",
+
+		ReportTitle:      "Rapport hebdomadaire Ghost Wispr, %s (du %s au %s)",
+		ReportDatabase:   "Base de données : %s (%s cette semaine)",
+		ReportAudio:      "Audio ajouté : %s",
+		ReportSessions:   "Sessions : %d, %.0f minutes transcrites (%.2f $)",
+		ReportLLM:        "LLM : %d jetons en entrée et %d en sortie (%.2f $)",
+		ReportFailures:   "Échecs : %d résumés, %d encodages audio, %d exports",
+		ReportNoFailures: "Échecs : aucun",
+
+		ConfigInvalidDefault:      "%s %q non valide — valeur par défaut %s utilisée.",
+		ConfigInvalidCountDefault: "%s %d non valide — valeur par défaut %d utilisée.",
+		ConfigInvalidChoice:       "%s %q non valide — doit être l'une des valeurs %s. %s est utilisé.",
+		ConfigNegative:            "%s %v non valide — ne doit pas être négatif. %s est utilisé.",
+		ConfigBelowZeroDB:         "%s %v non valide — doit être inférieur à 0 dBFS. %d est utilisé.",
+		ConfigInvalidMs:           "%s %q non valide — doit être un entier positif ou nul (ms). Valeur par défaut de Deepgram utilisée.",
+		ConfigPositive:            "%s %d non valide — doit être positif. %d est utilisé.",
+		ConfigAtLeastOne:          "%s %d non valide — doit valoir au moins 1. 1 est utilisé.",
+		ConfigBudgetNegative:      "%s %v non valide — ne doit pas être négatif. Budget désactivé.",
+		ConfigUnknownEntry:        "Entrée %[2]q inconnue dans %[1]s — doit être l'une des valeurs %[3]s. Elle est ignorée.",
+		ConfigInvalidModel:        "Modèle %[2]q de %[1]s non valide — %[3]v.",
+		ConfigAPIKeyMissing:       "Clé d'API %s non configurée — définissez %s.",
+		ConfigProviderKeyMissing:  "Clé d'API %s non configurée — définissez %s. Transcription %s ignorée.",
+		ConfigWhisperKeyMissing:   "La transcription Whisper nécessite une clé d'API OpenAI ou transcription.whisper.base_url. Transcription whisper ignorée.",
+		ConfigNoTranscription:     "Clé d'API Deepgram non configurée — la transcription en direct est désactivée. Définissez %s.",
+		ConfigNoDefaultPreset:     "Aucun préréglage de résumé par défaut — définissez summarization.presets.default.",
+		ConfigUnknownMemoPreset:   "summarization.memo_preset %q inconnu — les mémos vocaux utilisent le préréglage par défaut.",
+		ConfigReadingLevel:        "%s %q non valide — doit être l'une des valeurs %s. Laissé au prompt.",
+		ConfigAudioBitrate:        "audio_bitrate %d non valide — doit valoir 0 (débit variable) ou 8-320 kbit/s. 128 est utilisé.",
+		ConfigAudioQuality:        "audio_quality %d non valide — doit aller de 0 (meilleure) à 9 (plus petit). 4 est utilisé.",
+		ConfigPipeWireIgnores:     "Le backend de capture pipewire ignore %s — définissez plutôt pipewire_target.",
+		ConfigMicDevicesOverride:  "%s est ignoré quand mic_devices est défini — le mixage est capturé à la place.",
+		ConfigMicChannelsLoopback: "mic_channels %q ne peut pas être combiné avec loopback.mode %q — les micros sont mixés à la place.",
+		ConfigMicDeviceUnnamed:    "mic_devices[%d] n'a pas de nom — son ouverture échouera.",
+		ConfigMicDeviceGain:       "Gain %v non valide pour le micro %q — ne doit pas être négatif. 1.0 est utilisé.",
+		ConfigEmptyLanguage:       "transcription.language est vide — en-US est utilisé.",
+		ConfigMinConfidence:       "transcription.min_confidence %v non valide — doit être au moins 0 et inférieur à 1. Tous les mots sont conservés.",
+		ConfigInterimInterval:     "transcription.interim_interval %q non valide — doit être une durée de 0 à %s. 200ms est utilisé.",
+		ConfigVADHangover:         "transcription.vad.hangover %s n'est pas plus long que utterance_end_ms %dms — Deepgram risque de ne pas finaliser les phrases avant la pause du flux.",
+		ConfigHighPass:            "dsp.high_pass_hz %v non valide — doit être compris entre 0 et 1000. 100 est utilisé.",
+		ConfigWatchNeedsDeepgram:  "L'import du dossier surveillé nécessite une clé d'API Deepgram — définissez %s. Dossier surveillé désactivé.",
+		ConfigTTSNeedsOpenAI:      "L'audio des résumés nécessite une clé d'API OpenAI — définissez %s. Audio des résumés désactivé.",
+		ConfigTTSNeedsPiperModel:  "tts.piper_model n'est pas défini — piper a besoin d'un modèle de voix. Audio des résumés désactivé.",
+		ConfigTTSProvider:         "tts.provider %q non valide — doit être l'une des valeurs %s. Audio des résumés désactivé.",
+		ConfigMQTTPrefix:          "mqtt.topic_prefix est vide — %q est utilisé.",
+		ConfigVoiceThreshold:      "voice_profiles.threshold %g non valide — doit être compris entre 0 et 1. 0.75 est utilisé.",
+		ConfigSeriesNegative:      "series.lookback_weeks %d non valide — ne doit pas être négatif. Détection des séries désactivée.",
+		ConfigRetention:           "%s %q non valide — ces sessions sont conservées indéfiniment.",
+		ConfigHookIncomplete:      "hooks[%d] nécessite event et command — il est ignoré.",
+		ConfigHookTimeout:         "Délai %q non valide pour le hook %[2]s %[3]q — valeur par défaut 30s utilisée.",
+		ConfigTokenIncomplete:     "auth.tokens[%d] nécessite name et token_env — il est ignoré.",
+		ConfigTokenScope:          "Portée %q non valide pour le jeton d'API %q — doit être l'une des valeurs %s. Il est ignoré.",
+		ConfigTokenUnset:          "Le jeton d'API %q n'est pas défini — définissez %s. Il est ignoré.",
+		ConfigNoTokens:            "Aucun jeton d'API utilisable — l'API est ouverte à quiconque peut la joindre.",
+		ConfigTrustedProxy:        "Entrée %q de auth.trusted_proxies invalide — utilisez une adresse IP ou une plage CIDR. Elle est ignorée.",
+		ConfigExportUnnamed:       "exports[%d] nécessite un nom — il est ignoré.",
+		ConfigExportDuplicate:     "Règle d'export %q en double — elle est ignorée.",
+		ConfigExportEvent:         "Événement %q non valide pour la règle d'export %q — doit être l'une des valeurs %s. Elle est ignorée.",
+		ConfigExportTarget:        "Cible %q non valide pour la règle d'export %q — doit être l'une des valeurs %s. Elle est ignorée.",
+		ConfigExportNeedsDir:      "La règle d'export %q nécessite un dir — elle est ignorée.",
+		ConfigExportNeedsWebhook:  "La règle d'export %q nécessite webhook_url_env — elle est ignorée.",
+		ConfigExportNeedsFolder:   "La règle d'export %q nécessite un folder_id — elle est ignorée.",
+		ConfigExportWebhookUnset:  "Le webhook Slack de la règle d'export %q n'est pas défini — définissez %s. Elle est ignorée.",
+		ConfigExportMinDuration:   "min_duration %q non valide pour la règle d'export %q — les sessions de toute durée sont exportées.",
+	},
+	"de": {
+		WarnGDriveMemoryStore:     "Die Google-Drive-Synchronisierung ist beim In-Memory-Speicher deaktiviert",
+		WarnGDriveInitFailed:      "Google-Drive-Synchronisierung konnte nicht gestartet werden — Sicherungen sind deaktiviert",
+		WarnMicUnavailable:        "Mikrofon nicht verfügbar — nehmen Sie im Web-UI mit „Mikrofon dieses Browsers verwenden“ über einen Browser auf",
+		WarnMicStartFailed:        "Mikrofon konnte nicht gestartet werden — nehmen Sie im Web-UI mit „Mikrofon dieses Browsers verwenden“ über einen Browser auf",
+		WarnDeepgramInitFailed:    "Deepgram konnte nicht initialisiert werden — Live-Transkription ist deaktiviert",
+		WarnDeepgramConnectFailed: "Verbindung zu Deepgram fehlgeschlagen — Live-Transkription ist deaktiviert",
+		WarnDeepgramOffline:       "Deepgram war beim Start nicht erreichbar — Audio wird aufgenommen und nach dem Verbindungsaufbau transkribiert",
+
+		BudgetTranscriptionExceeded: "Monatliches Transkriptionsbudget überschritten (%.2f $ von %.2f $) — %s",
+		BudgetCaptureContinues:      "die Aufnahme läuft weiter",
+		BudgetCapturePaused:         "die Aufnahme ist bis nächsten Monat pausiert",
+		BudgetLLMExceeded:           "Monatliches LLM-Budget überschritten (%.2f $ von %.2f $) — %s",
+		BudgetSummariesContinue:     "Zusammenfassungen laufen weiter",
+		BudgetSummariesFallback:     "Zusammenfassungen nutzen bis nächsten Monat %s",
+		BudgetSummariesDisabled:     "automatische Zusammenfassungen sind bis nächsten Monat deaktiviert",
+
+		WatchdogMemoryOver: "Speichernutzung von %.0f MB liegt über dem Gerätelimit von %d MB",
+		WatchdogCPUOver:    "CPU-Auslastung von %.0f %% liegt über dem Gerätelimit von %.0f %%",
+
+		InputClipping: "Eingang übersteuert seit %.0f s — Mikrofonverstärkung verringern",
+		InputSilent:   "Mikrofon seit %.0f Minuten stumm, obwohl aufgenommen wird — prüfen, ob es angeschlossen und nicht stummgeschaltet ist",
+
+		NoteSuspended: "Unterbrochen — der Computer wechselte in den Ruhezustand",
+
+		DocDecisionLog:     "Entscheidungsprotokoll",
+		DocDecisionSession: "Sitzung %s",
+		DocMeetingsOn:      "Besprechungen am %s",
+		DocNoMeetings:      "Keine Besprechungen aufgezeichnet.",
+		DocDecided:         "Entschieden: %s",
+		DocNoSummary:       "Noch keine Zusammenfassung.",
+		DocMore:            "…%d weitere",
+		DocVoiceMemo:       "Sprachnotiz",
+		DocMeeting:         "Besprechung",
+		DocInProgress:      "%s (läuft)",
+		DocBriefTitle:      "Briefing: Fortsetzung von %s",
+		DocOpenActionItems: "Offene Aufgaben",
+		DocNone:            "Keine.",
+		DocLastMeeting:     "Letzte Besprechung",
+		DocSessionTitle:    "Sitzung %s",
+		DocStarted:         "Beginn: %s",
+		DocSpeaker:         "Sprecher %d",
+		DocSummary:         "Zusammenfassung",
+		DocTranscript:      "Transkript",
+		DocJournalTitle:    "Ghost-Wispr-Tagebuch %s",
+
+		DocNoParticipants: "Unbekannt",
+		DocMinutes:        "%d Min.",
+		DocHoursMinutes:   "%d Std. %02d Min.",
+		DateLong:          "%[1]s, %[3]d. %[2]s %[4]d",
+		DateWeekdays:      "Sonntag,Montag,Dienstag,Mittwoch,Donnerstag,Freitag,Samstag",
+		DateMonths:        "Januar,Februar,März,April,Mai,Juni,Juli,August,September,Oktober,November,Dezember",
+
+		ReportTitle:      "Ghost-Wispr-Wochenbericht, %s (%s bis %s)",
+		ReportDatabase:   "Datenbank: %s (%s diese Woche)",
+		ReportAudio:      "Audio hinzugefügt: %s",
+		ReportSessions:   "Sitzungen: %d, %.0f Minuten transkribiert (%.2f $)",
+		ReportLLM:        "LLM: %d Eingabe- und %d Ausgabe-Tokens (%.2f $)",
+		ReportFailures:   "Fehler: %d Zusammenfassungen, %d Audio-Kodierungen, %d Exporte",
+		ReportNoFailures: "Fehler: keine",
+
+		ConfigInvalidDefault:      "Ungültiger Wert %[2]q für %[1]s — Standardwert %[3]s wird verwendet.",
+		ConfigInvalidCountDefault: "Ungültiger Wert %[2]d für %[1]s — Standardwert %[3]d wird verwendet.",
+		ConfigInvalidChoice:       "Ungültiger Wert %[2]q für %[1]s — erlaubt sind %[3]s. %[4]s wird verwendet.",
+		ConfigNegative:            "Ungültiger Wert %[2]v für %[1]s — darf nicht negativ sein. %[3]s wird verwendet.",
+		ConfigBelowZeroDB:         "Ungültiger Wert %[2]v für %[1]s — muss unter 0 dBFS liegen. %[3]d wird verwendet.",
+		ConfigInvalidMs:           "Ungültiger Wert %[2]q für %[1]s — muss eine nicht negative Ganzzahl (ms) sein. Deepgram-Standard wird verwendet.",
+		ConfigPositive:            "Ungültiger Wert %[2]d für %[1]s — muss positiv sein. %[3]d wird verwendet.",
+		ConfigAtLeastOne:          "Ungültiger Wert %[2]d für %[1]s — muss mindestens 1 sein. 1 wird verwendet.",
+		ConfigBudgetNegative:      "Ungültiger Wert %[2]v für %[1]s — darf nicht negativ sein. Budget deaktiviert.",
+		ConfigUnknownEntry:        "Unbekannter Eintrag %[2]q in %[1]s — erlaubt sind %[3]s. Er wird ignoriert.",
+		ConfigInvalidModel:        "Ungültiges Modell %[2]q für %[1]s — %[3]v.",
+		ConfigAPIKeyMissing:       "%s-API-Schlüssel nicht konfiguriert — %s setzen.",
+		ConfigProviderKeyMissing:  "%s-API-Schlüssel nicht konfiguriert — %s setzen. %s-Transkription wird übersprungen.",
+		ConfigWhisperKeyMissing:   "Whisper-Transkription braucht einen OpenAI-API-Schlüssel oder transcription.whisper.base_url. whisper-Transkription wird übersprungen.",
+		ConfigNoTranscription:     "Deepgram-API-Schlüssel nicht konfiguriert — Live-Transkription ist deaktiviert. %s setzen.",
+		ConfigNoDefaultPreset:     "Kein Standard-Preset für Zusammenfassungen konfiguriert — summarization.presets.default setzen.",
+		ConfigUnknownMemoPreset:   "Unbekanntes summarization.memo_preset %q — Sprachnotizen nutzen das Standard-Preset.",
+		ConfigReadingLevel:        "Ungültiger Wert %[2]q für %[1]s — erlaubt sind %[3]s. Wird dem Prompt überlassen.",
+		ConfigAudioBitrate:        "Ungültige audio_bitrate %d — muss 0 (variable Bitrate) oder 8-320 kbit/s sein. 128 wird verwendet.",
+		ConfigAudioQuality:        "Ungültige audio_quality %d — muss zwischen 0 (beste) und 9 (kleinste) liegen. 4 wird verwendet.",
+		ConfigPipeWireIgnores:     "%s wird vom Aufnahme-Backend pipewire ignoriert — stattdessen pipewire_target setzen.",
+		ConfigMicDevicesOverride:  "%s wird ignoriert, wenn mic_devices gesetzt ist — stattdessen wird der Mix aufgenommen.",
+		ConfigMicChannelsLoopback: "mic_channels %q lässt sich nicht mit loopback.mode %q kombinieren — stattdessen werden die Mikrofone gemischt.",
+		ConfigMicDeviceUnnamed:    "mic_devices[%d] hat keinen Namen — das Öffnen wird fehlschlagen.",
+		ConfigMicDeviceGain:       "Ungültige Verstärkung %v für Mikrofon %q — darf nicht negativ sein. 1.0 wird verwendet.",
+		ConfigEmptyLanguage:       "transcription.language ist leer — en-US wird verwendet.",
+		ConfigMinConfidence:       "Ungültige transcription.min_confidence %v — muss mindestens 0 und kleiner als 1 sein. Alle Wörter bleiben erhalten.",
+		ConfigInterimInterval:     "Ungültiges transcription.interim_interval %q — muss eine Dauer von 0 bis %s sein. 200ms wird verwendet.",
+		ConfigVADHangover:         "transcription.vad.hangover %s ist nicht länger als utterance_end_ms %dms — Deepgram schließt Äußerungen womöglich nicht ab, bevor das Streaming pausiert.",
+		ConfigHighPass:            "Ungültiges dsp.high_pass_hz %v — muss zwischen 0 und 1000 liegen. 100 wird verwendet.",
+		ConfigWatchNeedsDeepgram:  "Der Import des überwachten Ordners braucht einen Deepgram-API-Schlüssel — %s setzen. Überwachter Ordner deaktiviert.",
+		ConfigTTSNeedsOpenAI:      "Audio-Zusammenfassungen brauchen einen OpenAI-API-Schlüssel — %s setzen. Audio-Zusammenfassungen deaktiviert.",
+		ConfigTTSNeedsPiperModel:  "tts.piper_model ist nicht gesetzt — piper braucht ein Stimmmodell. Audio-Zusammenfassungen deaktiviert.",
+		ConfigTTSProvider:         "Ungültiger tts.provider %q — erlaubt sind %s. Audio-Zusammenfassungen deaktiviert.",
+		ConfigMQTTPrefix:          "mqtt.topic_prefix ist leer — %q wird verwendet.",
+		ConfigVoiceThreshold:      "Ungültiger voice_profiles.threshold %g — muss zwischen 0 und 1 liegen. 0.75 wird verwendet.",
+		ConfigSeriesNegative:      "Ungültige series.lookback_weeks %d — darf nicht negativ sein. Serienerkennung deaktiviert.",
+		ConfigRetention:           "Ungültiger Wert %[2]q für %[1]s — diese Sitzungen werden dauerhaft behalten.",
+		ConfigHookIncomplete:      "hooks[%d] braucht event und command — wird übersprungen.",
+		ConfigHookTimeout:         "Ungültiges Timeout %q für %s-Hook %q — Standardwert 30s wird verwendet.",
+		ConfigTokenIncomplete:     "auth.tokens[%d] braucht name und token_env — wird übersprungen.",
+		ConfigTokenScope:          "Ungültiger Scope %q für API-Token %q — erlaubt sind %s. Wird übersprungen.",
+		ConfigTokenUnset:          "API-Token %q ist nicht gesetzt — %s setzen. Wird übersprungen.",
+		ConfigNoTokens:            "Keine nutzbaren API-Tokens — die API ist für alle offen, die sie erreichen.",
+		ConfigTrustedProxy:        "Ungültiger Eintrag %q in auth.trusted_proxies — verwende eine IP-Adresse oder einen CIDR-Bereich. Er wird übersprungen.",
+		ConfigExportUnnamed:       "exports[%d] braucht einen Namen — wird übersprungen.",
+		ConfigExportDuplicate:     "Doppelte Exportregel %q — wird übersprungen.",
+		ConfigExportEvent:         "Ungültiges Ereignis %q für Exportregel %q — erlaubt sind %s. Wird übersprungen.",
+		ConfigExportTarget:        "Ungültiges Ziel %q für Exportregel %q — erlaubt sind %s. Wird übersprungen.",
+		ConfigExportNeedsDir:      "Exportregel %q braucht ein dir — wird übersprungen.",
+		ConfigExportNeedsWebhook:  "Exportregel %q braucht webhook_url_env — wird übersprungen.",
+		ConfigExportNeedsFolder:   "Exportregel %q braucht eine folder_id — wird übersprungen.",
+		ConfigExportWebhookUnset:  "Slack-Webhook für Exportregel %q ist nicht gesetzt — %s setzen. Wird übersprungen.",
+		ConfigExportMinDuration:   "Ungültige min_duration %q für Exportregel %q — Sitzungen jeder Länge werden exportiert.",
+	},
+}
@@ -0,0 +1,88 @@
+// Package i18n translates the text Ghost Wispr writes for people rather
+// than for its logs: status warnings and the headings of generated
+// documents. Messages are looked up by key in built-in catalogs; anything
+// missing from a locale falls back to English.
+package i18n
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DefaultLocale is used when no locale, or one without a catalog, is
+// configured.
+const DefaultLocale = "en"
+
+// Localizer renders messages in one locale. A nil Localizer renders English.
+type Localizer struct {
+	locale   string
+	messages map[string]string
+}
+
+// New returns a Localizer for locale, such as "de" or "es-MX", matched to a
+// catalog by its language. ok is false when there is no catalog for it, in
+// which case the Localizer renders English.
+func New(locale string) (l *Localizer, ok bool) {
+	lang, ok := Match(locale)
+	return &Localizer{locale: lang, messages: catalogs[lang]}, ok
+}
+
+// Match returns the catalog locale for locale, ignoring case and any region
+// or encoding, and whether there is one.
+func Match(locale string) (string, bool) {
+	lang := strings.ToLower(strings.TrimSpace(locale))
+	if i := strings.IndexAny(lang, "-_."); i >= 0 {
+		lang = lang[:i]
+	}
+	if _, ok := catalogs[lang]; ok {
+		return lang, true
+	}
+	return DefaultLocale, false
+}
+
+// Supported lists the locales with catalogs.
+func Supported() []string {
+	locales := make([]string, 0, len(catalogs))
+	for locale := range catalogs {
+		locales = append(locales, locale)
+	}
+	slices.Sort(locales)
+	return locales
+}
+
+// Locale is the catalog in use.
+func (l *Localizer) Locale() string {
+	if l == nil {
+		return DefaultLocale
+	}
+	return l.locale
+}
+
+// T renders the message key with args substituted fmt-style. An unknown key
+// renders as itself, so a missing translation shows up rather than
+// vanishing.
+func (l *Localizer) T(key string, args ...any) string {
+	format, ok := "", false
+	if l != nil {
+		format, ok = l.messages[key]
+	}
+	if !ok {
+		if format, ok = catalogs[DefaultLocale][key]; !ok {
+			return key
+		}
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// Date renders t as a long date, such as "Monday, January 2, 2006", with the
+// weekday and month named in the locale's language.
+func (l *Localizer) Date(t time.Time) string {
+	weekdays := strings.Split(l.T(DateWeekdays), ",")
+	months := strings.Split(l.T(DateMonths), ",")
+	return l.T(DateLong, weekdays[t.Weekday()], months[t.Month()-1], t.Day(), t.Year())
+}
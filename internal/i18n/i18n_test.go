@@ -0,0 +1,108 @@
+package i18n
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// verbs matches fmt verbs, skipping escaped percent signs.
+var verbs = regexp.MustCompile(`%%|%[-+# 0]*(?:\[(\d+)\])?[0-9.]*([a-zA-Z])`)
+
+// formatVerbs lists the verb applied to each argument, following explicit
+// indexes such as %[2]s so translations can reorder arguments.
+func formatVerbs(format string) []string {
+	var out []string
+	next := 0
+	for _, m := range verbs.FindAllStringSubmatch(format, -1) {
+		if m[0] == "%%" {
+			continue
+		}
+		if m[1] != "" {
+			next, _ = strconv.Atoi(m[1])
+			next--
+		}
+		for len(out) <= next {
+			out = append(out, "")
+		}
+		out[next] = m[2]
+		next++
+	}
+	return out
+}
+
+func TestCatalogsMatchEnglish(t *testing.T) {
+	for locale, messages := range catalogs {
+		for key, english := range catalogs[DefaultLocale] {
+			format, ok := messages[key]
+			if !ok {
+				t.Errorf("%s: missing %s", locale, key)
+				continue
+			}
+			if got, want := fmt.Sprint(formatVerbs(format)), fmt.Sprint(formatVerbs(english)); got != want {
+				t.Errorf("%s: %s takes %s, English takes %s", locale, key, got, want)
+			}
+		}
+		for key := range messages {
+			if _, ok := catalogs[DefaultLocale][key]; !ok {
+				t.Errorf("%s: %s has no English message", locale, key)
+			}
+		}
+	}
+}
+
+func TestLocalizer(t *testing.T) {
+	for _, tc := range []struct {
+		locale, want string
+		ok           bool
+	}{
+		{"de", "Besprechungen am 2026-03-02", true},
+		{"es_MX.UTF-8", "Reuniones del 2026-03-02", true},
+		{"FR-ca", "Réunions du 2026-03-02", true},
+		{"", "Meetings on 2026-03-02", false},
+		{"tlh", "Meetings on 2026-03-02", false},
+	} {
+		l, ok := New(tc.locale)
+		if ok != tc.ok {
+			t.Errorf("New(%q) ok = %v, want %v", tc.locale, ok, tc.ok)
+		}
+		if got := l.T(DocMeetingsOn, "2026-03-02"); got != tc.want {
+			t.Errorf("%q: got %q, want %q", tc.locale, got, tc.want)
+		}
+	}
+
+	var l *Localizer
+	if got := l.T(DocSpeaker, 2); got != "Speaker 2" {
+		t.Fatalf("expected a nil Localizer to render English, got %q", got)
+	}
+	if got := l.T("doc.unknown"); got != "doc.unknown" {
+		t.Fatalf("expected an unknown key to render as itself, got %q", got)
+	}
+	if got := l.T(DocNone); got != "None." {
+		t.Fatalf("expected a message without arguments as written, got %q", got)
+	}
+}
+
+func TestDate(t *testing.T) {
+	day := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	for locale, want := range map[string]string{
+		"en": "Monday, March 2, 2026",
+		"es": "lunes, 2 de marzo de 2026",
+		"fr": "lundi 2 mars 2026",
+		"de": "Montag, 2. März 2026",
+	} {
+		l, _ := New(locale)
+		if got := l.Date(day); got != want {
+			t.Errorf("%s: got %q, want %q", locale, got, want)
+		}
+		if n := len(strings.Split(l.T(DateWeekdays), ",")); n != 7 {
+			t.Errorf("%s: %d weekdays", locale, n)
+		}
+		if n := len(strings.Split(l.T(DateMonths), ",")); n != 12 {
+			t.Errorf("%s: %d months", locale, n)
+		}
+	}
+}
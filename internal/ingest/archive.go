@@ -0,0 +1,136 @@
+package ingest
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/audio"
+	"github.com/sjawhar/ghost-wispr/internal/redact"
+	"github.com/sjawhar/ghost-wispr/internal/session"
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+	"github.com/sjawhar/ghost-wispr/internal/transcribe"
+)
+
+// ErrAlreadyImported is returned by TranscriptImporter.Import for a transcript that
+// an earlier import already stored.
+var ErrAlreadyImported = errors.New("transcript already imported")
+
+// TranscriptStore is the storage imported transcripts are written to.
+type TranscriptStore interface {
+	GetSession(id string) (storage.Session, error)
+	CreateSession(id string, startedAt time.Time) error
+	AppendSegment(sessionID string, seg transcribe.Segment) error
+	EndSession(id string, endedAt time.Time, audioPath string) error
+	UpdateSummary(sessionID, summary, status, preset string) error
+	SetTranscriptionModel(sessionID, model, language string) error
+	SetSpeakerName(sessionID string, speaker int, name string) error
+}
+
+// TranscriptImporter stores transcripts from other tools as finished
+// sessions, so an archive kept elsewhere is searchable alongside them.
+type TranscriptImporter struct {
+	store    TranscriptStore
+	audioDir string
+	redactor *redact.Redactor
+
+	// decode is swappable for tests.
+	decode func(path string, sampleRate int) ([]byte, error)
+}
+
+// NewTranscriptImporter writes to store, keeping audio in audioDir and
+// masking what redactor masks in live transcripts; redactor may be nil.
+func NewTranscriptImporter(store TranscriptStore, audioDir string, redactor *redact.Redactor) *TranscriptImporter {
+	return &TranscriptImporter{store: store, audioDir: audioDir, redactor: redactor, decode: audio.DecodeFile}
+}
+
+// Import stores t as a finished session, with the recording at audioPath
+// if it isn't empty, and returns its ID. Imported sessions aren't
+// summarized; resummarize one to get a summary. A transcript imported
+// before, from the same format and starting at the same time, returns
+// ErrAlreadyImported, so an export can be imported again after adding to it.
+func (i *TranscriptImporter) Import(t Transcript, audioPath string) (string, error) {
+	startedAt := t.StartedAt.UTC().Truncate(time.Second)
+	model := "import/" + t.Format
+	if sess, err := i.store.GetSession(startedAt.Format(session.IDLayout)); err == nil && sess.TranscriptionModel == model {
+		return sess.ID, ErrAlreadyImported
+	}
+	sessionID := session.FreeID(startedAt, func(id string) bool {
+		_, err := i.store.GetSession(id)
+		return err == nil
+	})
+
+	if err := i.store.CreateSession(sessionID, startedAt); err != nil {
+		return "", fmt.Errorf("create session: %w", err)
+	}
+	if err := i.store.SetTranscriptionModel(sessionID, model, t.Language); err != nil {
+		slog.Warn("recording imported transcript format failed", "session", sessionID, "error", err)
+	}
+	for _, seg := range t.Segments {
+		seg.Text = i.redactor.Redact(seg.Text)
+		seg.Timestamp = startedAt.Add(time.Duration(seg.StartTime * float64(time.Second)))
+		if err := i.store.AppendSegment(sessionID, seg); err != nil {
+			return sessionID, fmt.Errorf("append imported segment: %w", err)
+		}
+	}
+	// Speakers are numbered like diarized ones and named as if renamed.
+	for speaker, name := range t.Speakers {
+		if name == "" {
+			continue
+		}
+		if err := i.store.SetSpeakerName(sessionID, speaker, name); err != nil {
+			slog.Warn("naming imported speaker failed", "session", sessionID, "speaker", speaker, "error", err)
+		}
+	}
+
+	kept := ""
+	if audioPath != "" {
+		var err error
+		if kept, err = i.keepAudio(sessionID, audioPath); err != nil {
+			slog.Warn("keeping imported audio failed", "session", sessionID, "error", err)
+			kept = ""
+		}
+	}
+	if err := i.store.EndSession(sessionID, startedAt.Add(t.Duration()), kept); err != nil {
+		return sessionID, fmt.Errorf("end session: %w", err)
+	}
+	if err := i.store.UpdateSummary(sessionID, "", storage.SummaryCompleted, ""); err != nil {
+		return sessionID, fmt.Errorf("update summary: %w", err)
+	}
+	slog.Info("imported transcript", "file", t.Name, "format", t.Format, "session", sessionID, "segments", len(t.Segments))
+	return sessionID, nil
+}
+
+// keepAudio copies the recording into the audio folder for playback.
+func (i *TranscriptImporter) keepAudio(sessionID, path string) (string, error) {
+	dst := filepath.Join(i.audioDir, sessionID+strings.ToLower(filepath.Ext(path)))
+	if err := copyFile(path, dst); err != nil {
+		return "", err
+	}
+	if pcm, err := i.decode(dst, audio.TargetSampleRate); err != nil {
+		slog.Warn("decoding imported audio for its waveform failed", "file", path, "error", err)
+	} else {
+		audio.KeepWaveform(dst, pcm, audio.TargetSampleRate)
+	}
+	return dst, nil
+}
+
+// MatchingAudio returns the recording next to a transcript file, with the
+// same name and an audio extension, or "" if there is none.
+func MatchingAudio(transcriptPath string) string {
+	base := strings.TrimSuffix(transcriptPath, filepath.Ext(transcriptPath))
+	for _, ext := range slices.Sorted(maps.Keys(audioExts)) {
+		for _, candidate := range []string{base + ext, base + strings.ToUpper(ext)} {
+			if info, err := os.Stat(candidate); err == nil && info.Mode().IsRegular() {
+				return candidate
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,91 @@
+package ingest
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/redact"
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+	"github.com/sjawhar/ghost-wispr/internal/transcribe"
+)
+
+func TestTranscriptImporterStoresSession(t *testing.T) {
+	store := storage.NewMemoryStore()
+	audioDir := t.TempDir()
+	importer := NewTranscriptImporter(store, audioDir, redact.New([]string{redact.Email}))
+	importer.decode = func(string, int) ([]byte, error) { return make([]byte, 3200), nil }
+
+	recording := filepath.Join(t.TempDir(), "call.mp3")
+	if err := os.WriteFile(recording, []byte("audio"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	startedAt := time.Date(2025, 11, 4, 15, 30, 0, 0, time.UTC)
+	tr := Transcript{
+		Name:      "call.srt",
+		Format:    FormatSRT,
+		StartedAt: startedAt,
+		Segments: []transcribe.Segment{
+			{Speaker: 0, Text: "Mail me at jane@example.com.", StartTime: 1, EndTime: 3},
+			{Speaker: 1, Text: "Will do.", StartTime: 4, EndTime: 90},
+		},
+		Speakers: []string{"Jane", "Bob"},
+	}
+
+	id, err := importer.Import(tr, recording)
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	sess, err := store.GetSession(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != "20251104153000" || sess.Status != "ended" || sess.SummaryStatus != storage.SummaryCompleted || sess.TranscriptionModel != "import/srt" {
+		t.Fatalf("unexpected session %s: %+v", id, sess)
+	}
+	if sess.EndedAt == nil || !sess.EndedAt.Equal(startedAt.Add(90*time.Second)) {
+		t.Fatalf("expected the session to end with its last segment, got %v", sess.EndedAt)
+	}
+	if want := filepath.Join(audioDir, id+".mp3"); sess.AudioPath != want {
+		t.Fatalf("expected the recording kept at %s, got %q", want, sess.AudioPath)
+	}
+	segments, _ := store.GetSegments(id)
+	if len(segments) != 2 || segments[0].Text != "Mail me at [EMAIL]." || !segments[1].Timestamp.Equal(startedAt.Add(4*time.Second)) {
+		t.Fatalf("unexpected segments: %+v", segments)
+	}
+	if notes, _ := store.GetNotes(id); len(notes) != 0 {
+		t.Fatalf("expected the speakers named rather than noted, got %+v", notes)
+	}
+	names, _ := store.GetSpeakerNames(id)
+	if names[0] != "Jane" || names[1] != "Bob" {
+		t.Fatalf("expected the imported speakers named, got %+v", names)
+	}
+
+	// Importing the same export again leaves it be.
+	if again, err := importer.Import(tr, ""); !errors.Is(err, ErrAlreadyImported) || again != id {
+		t.Fatalf("expected ErrAlreadyImported for %s, got %s, %v", id, again, err)
+	}
+	// Another conversation starting the same second gets the next ID.
+	other := tr
+	other.Format = FormatVTT
+	if next, err := importer.Import(other, ""); err != nil || next != "20251104153001" {
+		t.Fatalf("expected the next free ID, got %s, %v", next, err)
+	}
+}
+
+func TestMatchingAudio(t *testing.T) {
+	dir := t.TempDir()
+	transcript := filepath.Join(dir, "meeting.srt")
+	if MatchingAudio(transcript) != "" {
+		t.Fatal("expected no recording")
+	}
+	recording := filepath.Join(dir, "meeting.m4a")
+	if err := os.WriteFile(recording, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := MatchingAudio(transcript); got != recording {
+		t.Fatalf("expected %s, got %q", recording, got)
+	}
+}
@@ -0,0 +1,349 @@
+package ingest
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/transcribe"
+)
+
+// Transcript formats imported from other tools.
+const (
+	FormatOtter   = "otter"
+	FormatWhisper = "whisper"
+	FormatSRT     = "srt"
+	FormatVTT     = "vtt"
+)
+
+// Formats lists the transcript formats ParseTranscripts reads.
+var Formats = []string{FormatOtter, FormatWhisper, FormatSRT, FormatVTT}
+
+// ErrUnsupportedFormat is returned for transcript formats that aren't one
+// of Formats.
+var ErrUnsupportedFormat = errors.New("unsupported transcript format")
+
+// Transcript is a conversation transcribed by another tool, to be imported
+// as a finished session.
+type Transcript struct {
+	// Name is the file it was read from.
+	Name   string
+	Format string
+	// StartedAt is when the conversation began: the file's modification
+	// time unless the caller knows better.
+	StartedAt time.Time
+	// Segments are timed from the start of the conversation.
+	Segments []transcribe.Segment
+	// Speakers names segments' speakers by number, where the tool knew them.
+	Speakers []string
+	Language string
+}
+
+// Duration is how long the conversation runs, to its last segment's end.
+func (t Transcript) Duration() time.Duration {
+	if len(t.Segments) == 0 {
+		return 0
+	}
+	return time.Duration(t.Segments[len(t.Segments)-1].EndTime * float64(time.Second))
+}
+
+// ParseTranscripts reads the transcripts in the file at path. An Otter.ai
+// export is a zip of transcripts, or a single one; every other format holds
+// one transcript per file.
+func ParseTranscripts(format, path string) ([]Transcript, error) {
+	if !slices.Contains(Formats, format) {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if format == FormatOtter && strings.EqualFold(filepath.Ext(path), ".zip") {
+		return parseOtterZip(path)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	t, err := parseTranscript(format, filepath.Base(path), f)
+	if err != nil {
+		return nil, err
+	}
+	t.StartedAt = info.ModTime().UTC()
+	return []Transcript{t}, nil
+}
+
+func parseTranscript(format, name string, r io.Reader) (Transcript, error) {
+	t := Transcript{Name: name, Format: format}
+	speakers := speakerNumbers{}
+	var err error
+	switch format {
+	case FormatOtter:
+		// Otter exports text, or subtitles when asked for them.
+		if strings.EqualFold(filepath.Ext(name), ".srt") {
+			t.Segments, err = parseCues(r, speakers)
+		} else {
+			t.Segments, err = parseOtterText(r, speakers)
+		}
+	case FormatWhisper:
+		t.Segments, t.Language, err = parseWhisperJSON(r, speakers)
+	case FormatSRT, FormatVTT:
+		t.Segments, err = parseCues(r, speakers)
+	}
+	if err != nil {
+		return Transcript{}, fmt.Errorf("parse %s: %w", name, err)
+	}
+	t.Speakers = speakers.names()
+	return t, nil
+}
+
+// parseOtterZip reads each text or subtitle transcript in an Otter.ai
+// export, dated by the time stored for it in the zip.
+func parseOtterZip(path string) ([]Transcript, error) {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = zr.Close() }()
+
+	var transcripts []Transcript
+	for _, f := range zr.File {
+		ext := strings.ToLower(filepath.Ext(f.Name))
+		if f.FileInfo().IsDir() || (ext != ".txt" && ext != ".srt") || strings.HasPrefix(filepath.Base(f.Name), ".") {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("open %s: %w", f.Name, err)
+		}
+		t, err := parseTranscript(FormatOtter, filepath.Base(f.Name), rc)
+		_ = rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		t.StartedAt = f.Modified.UTC()
+		transcripts = append(transcripts, t)
+	}
+	return transcripts, nil
+}
+
+// otterHeader starts a speaker's turn in an Otter.ai text export: their
+// name, then how far into the conversation they spoke, as in
+// "Jane Doe  12:04" or "Speaker 2  1:02:45".
+var otterHeader = regexp.MustCompile(`^(.+?)\s{2,}(\d{1,2}(?::\d{2}){1,2})\s*$`)
+
+// speechRate times the last turn of a transcript that only records when
+// each turn starts, at a typical words per second.
+const speechRate = 2.5
+
+func parseOtterText(r io.Reader, speakers speakerNumbers) ([]transcribe.Segment, error) {
+	var segments []transcribe.Segment
+	var text []string
+	flush := func() {
+		if n := len(segments); n > 0 {
+			segments[n-1].Text = strings.Join(text, " ")
+		}
+		text = nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+		if m := otterHeader.FindStringSubmatch(line); m != nil {
+			flush()
+			start, _ := parseClock(m[2])
+			segments = append(segments, transcribe.Segment{Speaker: speakers.number(m[1]), StartTime: start})
+			continue
+		}
+		// Otter ends an export with a line crediting itself.
+		if line == "" || len(segments) == 0 || strings.HasPrefix(line, "Transcribed by https://otter.ai") {
+			continue
+		}
+		text = append(text, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+
+	// A turn runs until the next one starts.
+	for i := range segments {
+		if i+1 < len(segments) {
+			segments[i].EndTime = segments[i+1].StartTime
+		} else {
+			segments[i].EndTime = segments[i].StartTime + float64(len(strings.Fields(segments[i].Text)))/speechRate
+		}
+	}
+	return slices.DeleteFunc(segments, func(s transcribe.Segment) bool { return s.Text == "" }), nil
+}
+
+// whisperOutput is the JSON the Whisper CLI writes; WhisperX adds a speaker
+// to each segment.
+type whisperOutput struct {
+	Language string `json:"language"`
+	Segments []struct {
+		Start   float64 `json:"start"`
+		End     float64 `json:"end"`
+		Text    string  `json:"text"`
+		Speaker string  `json:"speaker"`
+	} `json:"segments"`
+}
+
+func parseWhisperJSON(r io.Reader, speakers speakerNumbers) ([]transcribe.Segment, string, error) {
+	var out whisperOutput
+	if err := json.NewDecoder(r).Decode(&out); err != nil {
+		return nil, "", err
+	}
+	segments := make([]transcribe.Segment, 0, len(out.Segments))
+	for _, s := range out.Segments {
+		text := strings.TrimSpace(s.Text)
+		if text == "" {
+			continue
+		}
+		segments = append(segments, transcribe.Segment{Speaker: speakers.number(s.Speaker), Text: text, StartTime: s.Start, EndTime: s.End})
+	}
+	return mergeTurns(segments), out.Language, nil
+}
+
+// cueTiming is a subtitle cue's "start --> end" line. SRT separates
+// milliseconds with a comma, WebVTT with a dot and may leave out the hours.
+var cueTiming = regexp.MustCompile(`^((?:\d+:)?\d{1,2}:\d{2}[.,]\d{1,3})\s+-->\s+((?:\d+:)?\d{1,2}:\d{2}[.,]\d{1,3})`)
+
+// cueVoice is a WebVTT voice span, naming who speaks a cue.
+var cueVoice = regexp.MustCompile(`<v(?:\.[^ >]*)?\s+([^>]+)>`)
+
+var cueTag = regexp.MustCompile(`</?[^>]+>`)
+
+// parseCues reads SRT or WebVTT subtitles, one segment per cue, merged into
+// turns where the voice doesn't change.
+func parseCues(r io.Reader, speakers speakerNumbers) ([]transcribe.Segment, error) {
+	var segments []transcribe.Segment
+	var current *transcribe.Segment
+	var voice string
+	var text []string
+	flush := func() {
+		if current != nil && len(text) > 0 {
+			current.Speaker = speakers.number(voice)
+			current.Text = strings.Join(text, " ")
+			segments = append(segments, *current)
+		}
+		current, voice, text = nil, "", nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+		if line == "" {
+			flush()
+			continue
+		}
+		if m := cueTiming.FindStringSubmatch(line); m != nil {
+			flush()
+			start, err := parseClock(m[1])
+			if err != nil {
+				return nil, err
+			}
+			end, err := parseClock(m[2])
+			if err != nil {
+				return nil, err
+			}
+			current = &transcribe.Segment{StartTime: start, EndTime: end}
+			continue
+		}
+		// Cue numbers, the WEBVTT header, NOTE and STYLE blocks all come
+		// outside a cue's text.
+		if current == nil {
+			continue
+		}
+		if m := cueVoice.FindStringSubmatch(line); m != nil && voice == "" {
+			voice = m[1]
+		}
+		if plain := strings.TrimSpace(cueTag.ReplaceAllString(line, "")); plain != "" {
+			text = append(text, plain)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+	return mergeTurns(segments), nil
+}
+
+// maxTurnGap is the longest pause within a turn merged from subtitle cues.
+const maxTurnGap = 2.0
+
+// mergeTurns joins consecutive segments by the same speaker, as subtitles
+// split a turn into screen-sized cues.
+func mergeTurns(segments []transcribe.Segment) []transcribe.Segment {
+	var merged []transcribe.Segment
+	for _, s := range segments {
+		if n := len(merged); n > 0 && merged[n-1].Speaker == s.Speaker && s.StartTime-merged[n-1].EndTime <= maxTurnGap {
+			merged[n-1].Text += " " + s.Text
+			merged[n-1].EndTime = max(merged[n-1].EndTime, s.EndTime)
+			continue
+		}
+		merged = append(merged, s)
+	}
+	return merged
+}
+
+// parseClock parses an "h:mm:ss", "mm:ss" or "mm:ss.mmm" offset into
+// seconds; a comma may separate the milliseconds.
+func parseClock(s string) (float64, error) {
+	parts := strings.Split(strings.ReplaceAll(s, ",", "."), ":")
+	var seconds float64
+	for _, p := range parts {
+		v, err := strconv.ParseFloat(p, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid timestamp %q", s)
+		}
+		seconds = seconds*60 + v
+	}
+	return seconds, nil
+}
+
+// speakerNumbers numbers speakers by name in the order they first speak.
+// Unnamed speech is speaker 0.
+type speakerNumbers map[string]int
+
+func (s speakerNumbers) number(name string) int {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = "\x00"
+	}
+	if n, ok := s[name]; ok {
+		return n
+	}
+	s[name] = len(s)
+	return s[name]
+}
+
+// names lists the speakers' names by number, or nil when none were named.
+func (s speakerNumbers) names() []string {
+	names := make([]string, len(s))
+	named := false
+	for name, n := range s {
+		if name != "\x00" {
+			names[n] = name
+			named = true
+		}
+	}
+	if !named {
+		return nil
+	}
+	return names
+}
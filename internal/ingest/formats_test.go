@@ -0,0 +1,140 @@
+package ingest
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/transcribe"
+)
+
+// transcriptFile writes a transcript export last modified at exportedAt.
+func transcriptFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	writeFile(t, path, content, exportedAt)
+	return path
+}
+
+var exportedAt = time.Date(2025, 11, 4, 15, 30, 0, 0, time.UTC)
+
+func TestParseOtterZip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "otter.zip")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	modified := time.Date(2025, 11, 4, 15, 30, 0, 0, time.UTC)
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: "Weekly Sync.txt", Modified: modified, Method: zip.Deflate})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = w.Write([]byte("Jane Doe  0:02\nMorning, everyone.\nLet's start.\n\nSpeaker 2  1:05\nSounds good.\n\nTranscribed by https://otter.ai\n"))
+	if _, err := zw.Create("notes.docx"); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	_ = f.Close()
+
+	transcripts, err := ParseTranscripts(FormatOtter, path)
+	if err != nil {
+		t.Fatalf("ParseTranscripts failed: %v", err)
+	}
+	if len(transcripts) != 1 {
+		t.Fatalf("expected only the text transcript, got %d", len(transcripts))
+	}
+	tr := transcripts[0]
+	want := []transcribe.Segment{
+		{Speaker: 0, Text: "Morning, everyone. Let's start.", StartTime: 2, EndTime: 65},
+		{Speaker: 1, Text: "Sounds good.", StartTime: 65, EndTime: 65.8},
+	}
+	if !reflect.DeepEqual(tr.Segments, want) {
+		t.Fatalf("unexpected segments: %+v", tr.Segments)
+	}
+	if !slices.Equal(tr.Speakers, []string{"Jane Doe", "Speaker 2"}) || !tr.StartedAt.Equal(modified) {
+		t.Fatalf("unexpected speakers %q or start %v", tr.Speakers, tr.StartedAt)
+	}
+}
+
+func TestParseVTTMergesCuesIntoTurns(t *testing.T) {
+	path := transcriptFile(t, "standup.vtt", `WEBVTT
+
+NOTE exported from a video call
+
+1
+00:00.500 --> 00:02.000
+<v Alice>We shipped the importer.</v>
+
+2
+00:02.500 --> 00:04.000
+<v Alice>It reads subtitles too.
+
+00:01:04.000 --> 00:01:06.250
+<v.loud Bob>Nice!
+`)
+	transcripts, err := ParseTranscripts(FormatVTT, path)
+	if err != nil {
+		t.Fatalf("ParseTranscripts failed: %v", err)
+	}
+	want := []transcribe.Segment{
+		{Speaker: 0, Text: "We shipped the importer. It reads subtitles too.", StartTime: 0.5, EndTime: 4},
+		{Speaker: 1, Text: "Nice!", StartTime: 64, EndTime: 66.25},
+	}
+	if got := transcripts[0].Segments; !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected segments: %+v", got)
+	}
+	if !slices.Equal(transcripts[0].Speakers, []string{"Alice", "Bob"}) {
+		t.Fatalf("unexpected speakers: %q", transcripts[0].Speakers)
+	}
+}
+
+func TestParseSRT(t *testing.T) {
+	path := transcriptFile(t, "call.srt", "1\r\n00:00:01,000 --> 00:00:03,500\r\nHello there.\r\n\r\n2\r\n00:01:00,000 --> 00:01:02,000\r\n<i>Still here?</i>\r\n")
+	transcripts, err := ParseTranscripts(FormatSRT, path)
+	if err != nil {
+		t.Fatalf("ParseTranscripts failed: %v", err)
+	}
+	want := []transcribe.Segment{
+		{Text: "Hello there.", StartTime: 1, EndTime: 3.5},
+		{Text: "Still here?", StartTime: 60, EndTime: 62},
+	}
+	if got := transcripts[0].Segments; !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected segments: %+v", got)
+	}
+	if transcripts[0].Speakers != nil {
+		t.Fatalf("expected no speaker names, got %q", transcripts[0].Speakers)
+	}
+}
+
+func TestParseWhisperJSON(t *testing.T) {
+	path := transcriptFile(t, "interview.json", `{"text": "...", "language": "fr", "segments": [
+		{"id": 0, "start": 0.0, "end": 2.4, "text": " Bonjour.", "speaker": "SPEAKER_01"},
+		{"id": 1, "start": 2.4, "end": 5.0, "text": " Bienvenue.", "speaker": "SPEAKER_00"},
+		{"id": 2, "start": 5.0, "end": 6.0, "text": " ", "speaker": "SPEAKER_00"}
+	]}`)
+	transcripts, err := ParseTranscripts(FormatWhisper, path)
+	if err != nil {
+		t.Fatalf("ParseTranscripts failed: %v", err)
+	}
+	tr := transcripts[0]
+	want := []transcribe.Segment{
+		{Speaker: 0, Text: "Bonjour.", StartTime: 0, EndTime: 2.4},
+		{Speaker: 1, Text: "Bienvenue.", StartTime: 2.4, EndTime: 5},
+	}
+	if !reflect.DeepEqual(tr.Segments, want) || tr.Language != "fr" || !tr.StartedAt.Equal(exportedAt) {
+		t.Fatalf("unexpected transcript: %+v", tr)
+	}
+}
+
+func TestParseTranscriptsRejectsUnknownFormat(t *testing.T) {
+	if _, err := ParseTranscripts("rev", "transcript.txt"); err == nil {
+		t.Fatal("expected an unsupported format error")
+	}
+}
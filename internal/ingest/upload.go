@@ -0,0 +1,165 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/audio"
+	"github.com/sjawhar/ghost-wispr/internal/session"
+)
+
+// ErrUnsupportedType is returned for uploads whose extension isn't one of
+// the audio types imported.
+var ErrUnsupportedType = errors.New("unsupported audio file type")
+
+// ErrUploaderClosed is returned for uploads made once the uploader is
+// closing.
+var ErrUploaderClosed = errors.New("uploader closed")
+
+// IsAudioFile reports whether name has an extension of the audio types
+// imported from the watched folder and uploads.
+func IsAudioFile(name string) bool {
+	return audioExts[strings.ToLower(filepath.Ext(name))]
+}
+
+// Uploader imports recordings uploaded through the API, keeping a copy in
+// the audio folder for playback.
+type Uploader struct {
+	audioDir string
+	importer Importer
+
+	// decode is swappable for tests.
+	decode func(dst io.Writer, path string, sampleRate int) error
+
+	mu     sync.Mutex
+	closed bool
+	// imports tracks the imports running in the background.
+	imports sync.WaitGroup
+}
+
+// NewUploader keeps uploads in audioDir.
+func NewUploader(audioDir string, importer Importer) *Uploader {
+	return &Uploader{audioDir: audioDir, importer: importer, decode: audio.DecodeTo}
+}
+
+// Import stores and decodes the recording named name read from r, then
+// transcribes, stores and summarizes it as a finished session in the
+// background, which takes about as long as a live session's summary. Only
+// errors reading the upload are returned; the session shows up in the
+// session list once stored. The recording began at startedAt; when that is
+// zero it is taken to have just ended, as when a voice memo is shared
+// straight from a phone. The upload is decoded to disk rather than memory,
+// so a long recording can't exhaust it.
+func (u *Uploader) Import(ctx context.Context, name string, r io.Reader, startedAt time.Time) error {
+	if !IsAudioFile(name) {
+		return fmt.Errorf("%w: %q", ErrUnsupportedType, filepath.Ext(name))
+	}
+	if err := os.MkdirAll(u.audioDir, 0o755); err != nil {
+		return fmt.Errorf("create audio folder: %w", err)
+	}
+	ext := strings.ToLower(filepath.Ext(name))
+	// ffmpeg goes by the extension for some containers, so the upload keeps it.
+	tmp, err := os.CreateTemp(u.audioDir, ".upload-*"+ext)
+	if err != nil {
+		return fmt.Errorf("store upload: %w", err)
+	}
+	pcm, err := os.CreateTemp(u.audioDir, ".upload-*.pcm")
+	if err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("store upload: %w", err)
+	}
+	// Once the audio is kept for the session its file is gone and removing
+	// it fails.
+	cleanup := func() {
+		_ = pcm.Close()
+		_ = os.Remove(pcm.Name())
+		_ = os.Remove(tmp.Name())
+	}
+	if _, err := io.Copy(tmp, r); err != nil {
+		_ = tmp.Close()
+		cleanup()
+		return fmt.Errorf("store upload: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		cleanup()
+		return fmt.Errorf("store upload: %w", err)
+	}
+
+	if err := u.decode(pcm, tmp.Name(), audio.TargetSampleRate); err != nil {
+		cleanup()
+		return err
+	}
+	size, err := pcm.Seek(0, io.SeekCurrent)
+	if err == nil {
+		_, err = pcm.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		cleanup()
+		return fmt.Errorf("store upload: %w", err)
+	}
+	rec := session.Recording{StartedAt: startedAt, Audio: pcm, Size: size, SampleRate: audio.TargetSampleRate}
+	if rec.StartedAt.IsZero() {
+		rec.StartedAt = time.Now().Add(-rec.Duration())
+	}
+	rec.SaveAudio = func(sessionID string) (string, error) {
+		dst := filepath.Join(u.audioDir, sessionID+ext)
+		if err := os.Rename(tmp.Name(), dst); err != nil {
+			return "", err
+		}
+		if err := audio.WriteWaveform(audio.WaveformPath(dst), io.NewSectionReader(pcm, 0, size), audio.TargetSampleRate, 1); err != nil {
+			slog.Warn("writing waveform failed", "file", dst, "error", err)
+		}
+		return dst, nil
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.closed {
+		cleanup()
+		return ErrUploaderClosed
+	}
+	// Transcription is paid for once it starts, so the uploader giving up
+	// doesn't abandon it halfway.
+	ctx = context.WithoutCancel(ctx)
+	u.imports.Add(1)
+	go func() {
+		defer u.imports.Done()
+		defer cleanup()
+		sessionID, err := u.importer.ImportRecording(ctx, rec)
+		if err != nil {
+			slog.Warn("importing uploaded file failed", "file", name, "session", sessionID, "error", err)
+			return
+		}
+		slog.Info("imported uploaded file", "file", name, "session", sessionID)
+	}()
+	return nil
+}
+
+// Close stops taking uploads and waits until those being imported are
+// stored, or ctx is done.
+func (u *Uploader) Close(ctx context.Context) error {
+	u.mu.Lock()
+	u.closed = true
+	u.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		u.imports.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
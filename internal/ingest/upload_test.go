@@ -0,0 +1,130 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/audio"
+)
+
+func TestUploaderImportsRecording(t *testing.T) {
+	audioDir := t.TempDir()
+	importer := &importerStub{}
+	u := NewUploader(audioDir, importer)
+	u.decode = func(dst io.Writer, path string, _ int) error {
+		if filepath.Ext(path) != ".m4a" {
+			t.Errorf("expected the upload decoded with its extension, got %s", path)
+		}
+		_, err := dst.Write(make([]byte, 60*2*audio.TargetSampleRate))
+		return err
+	}
+
+	startedAt := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	if err := u.Import(context.Background(), "Voice Memo.M4A", strings.NewReader("audio"), startedAt); err != nil {
+		t.Fatal(err)
+	}
+	if err := u.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !importer.recordings[0].StartedAt.Equal(startedAt) {
+		t.Fatalf("expected the session to start at %v, got %v", startedAt, importer.recordings[0].StartedAt)
+	}
+	if got := importer.recordings[0].Duration(); got != time.Minute {
+		t.Fatalf("expected the decoded minute streamed to the import, got %v", got)
+	}
+	want := filepath.Join(audioDir, "20260302090000.m4a")
+	if data, err := os.ReadFile(want); err != nil || string(data) != "audio" {
+		t.Fatalf("expected the upload kept at %s, got %q, %v", want, data, err)
+	}
+	entries, _ := os.ReadDir(audioDir)
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), ".upload-") {
+			t.Fatalf("expected no temporary upload left behind, found %s", e.Name())
+		}
+	}
+}
+
+func TestUploaderDefaultsToEndingNow(t *testing.T) {
+	importer := &importerStub{}
+	u := NewUploader(t.TempDir(), importer)
+	u.decode = writePCM(60 * 2 * audio.TargetSampleRate)
+
+	before := time.Now()
+	if err := u.Import(context.Background(), "memo.mp3", strings.NewReader("audio"), time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	u.imports.Wait()
+	started := importer.recordings[0].StartedAt
+	if started.Before(before.Add(-time.Minute)) || started.After(time.Now().Add(-time.Minute)) {
+		t.Fatalf("expected the recording to have ended on upload, started %v", started)
+	}
+}
+
+func TestUploaderRejectsUnusableFiles(t *testing.T) {
+	audioDir := t.TempDir()
+	importer := &importerStub{}
+	u := NewUploader(audioDir, importer)
+	u.decode = func(io.Writer, string, int) error { return audio.ErrUndecodable }
+
+	if err := u.Import(context.Background(), "notes.txt", strings.NewReader("text"), time.Time{}); !errors.Is(err, ErrUnsupportedType) {
+		t.Fatalf("expected ErrUnsupportedType, got %v", err)
+	}
+	if err := u.Import(context.Background(), "broken.wav", strings.NewReader("???"), time.Time{}); !errors.Is(err, audio.ErrUndecodable) {
+		t.Fatalf("expected ErrUndecodable, got %v", err)
+	}
+	if len(importer.recordings) != 0 {
+		t.Fatal("expected nothing imported")
+	}
+	if entries, _ := os.ReadDir(audioDir); len(entries) != 0 {
+		t.Fatalf("expected failed uploads cleaned up, found %d files", len(entries))
+	}
+}
+
+func TestUploaderCleansUpAfterFailedImport(t *testing.T) {
+	audioDir := t.TempDir()
+	u := NewUploader(audioDir, &importerStub{err: errors.New("transcription failed")})
+	u.decode = writePCM(2 * audio.TargetSampleRate)
+
+	// The upload was read, so the failure comes later, in the background.
+	if err := u.Import(context.Background(), "memo.wav", strings.NewReader("audio"), time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	u.imports.Wait()
+	if entries, _ := os.ReadDir(audioDir); len(entries) != 0 {
+		t.Fatalf("expected the upload removed after the import failed, found %d files", len(entries))
+	}
+}
+
+func TestUploaderRefusesUploadsOnceClosed(t *testing.T) {
+	audioDir := t.TempDir()
+	importer := &importerStub{}
+	u := NewUploader(audioDir, importer)
+	u.decode = writePCM(2 * audio.TargetSampleRate)
+
+	if err := u.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := u.Import(context.Background(), "memo.wav", strings.NewReader("audio"), time.Time{}); !errors.Is(err, ErrUploaderClosed) {
+		t.Fatalf("expected ErrUploaderClosed, got %v", err)
+	}
+	if len(importer.recordings) != 0 {
+		t.Fatal("expected nothing imported")
+	}
+	if entries, _ := os.ReadDir(audioDir); len(entries) != 0 {
+		t.Fatalf("expected the refused upload cleaned up, found %d files", len(entries))
+	}
+}
+
+// writePCM decodes any upload as n bytes of silence.
+func writePCM(n int) func(io.Writer, string, int) error {
+	return func(dst io.Writer, _ string, _ int) error {
+		_, err := dst.Write(make([]byte, n))
+		return err
+	}
+}
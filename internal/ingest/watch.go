@@ -0,0 +1,259 @@
+// Package ingest imports audio files dropped into a watched folder, such as
+// one a phone's recordings are synced into with Syncthing, so they become
+// sessions without anyone touching the API.
+package ingest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/audio"
+	"github.com/sjawhar/ghost-wispr/internal/session"
+)
+
+// Importer turns a recording into a finished, summarized session.
+type Importer interface {
+	ImportRecording(ctx context.Context, rec session.Recording) (string, error)
+}
+
+// audioExts are the file types picked up from the watched folder.
+var audioExts = map[string]bool{
+	".wav": true, ".mp3": true, ".m4a": true, ".aac": true, ".flac": true,
+	".ogg": true, ".opus": true, ".webm": true, ".amr": true, ".3gp": true,
+}
+
+// failedDir is where files that can't be imported are moved, inside the
+// watched folder, so they aren't retried forever.
+const failedDir = "failed"
+
+// importRetries is how many times a file is tried when importing it fails in
+// a way that may pass, such as no transcriber being reachable, before it is
+// moved to the failed folder. Attempts are retryBackoff apart, doubling each
+// time.
+const (
+	importRetries = 5
+	retryBackoff  = time.Minute
+)
+
+// Watcher imports the audio files that appear in a folder. A file is only
+// imported once it has stopped changing between two scans, so recordings
+// still being synced in aren't read half-written. Imported files are moved
+// to the archive folder and a copy is kept in the audio folder for playback.
+type Watcher struct {
+	dir        string
+	archiveDir string
+	audioDir   string
+	importer   Importer
+
+	// decode and now are swappable for tests.
+	decode  func(path string, sampleRate int) ([]byte, error)
+	now     func() time.Time
+	seen    map[string]fileState
+	retries map[string]retryState
+}
+
+// retryState tracks a file whose import failed: how many times, and when to
+// try it again.
+type retryState struct {
+	failures int
+	next     time.Time
+}
+
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+// NewWatcher watches dir. Imported files go to archiveDir, or "imported"
+// inside dir when it is empty.
+func NewWatcher(dir, archiveDir, audioDir string, importer Importer) *Watcher {
+	if archiveDir == "" {
+		archiveDir = filepath.Join(dir, "imported")
+	}
+	return &Watcher{
+		dir:        dir,
+		archiveDir: archiveDir,
+		audioDir:   audioDir,
+		importer:   importer,
+		decode:     audio.DecodeFile,
+		now:        time.Now,
+		seen:       make(map[string]fileState),
+		retries:    make(map[string]retryState),
+	}
+}
+
+// Run scans the folder every interval until ctx is done.
+func (w *Watcher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		w.Scan(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Scan imports the files that have settled since the last scan, oldest
+// first.
+func (w *Watcher) Scan(ctx context.Context) {
+	entries, err := os.ReadDir(w.dir)
+	if err != nil {
+		slog.Warn("scanning watch folder failed", "dir", w.dir, "error", err)
+		return
+	}
+
+	seen := make(map[string]fileState, len(entries))
+	var ready []string
+	for _, entry := range entries {
+		name := entry.Name()
+		// Sync tools write to hidden or .tmp files and rename them when done.
+		if !entry.Type().IsRegular() || strings.HasPrefix(name, ".") || !audioExts[strings.ToLower(filepath.Ext(name))] {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		state := fileState{size: info.Size(), modTime: info.ModTime()}
+		seen[name] = state
+		if prev, ok := w.seen[name]; ok && prev == state && state.size > 0 {
+			if r, ok := w.retries[name]; ok && w.now().Before(r.next) {
+				continue
+			}
+			ready = append(ready, name)
+		}
+	}
+	w.seen = seen
+	for name := range w.retries {
+		if _, ok := seen[name]; !ok {
+			delete(w.retries, name)
+		}
+	}
+	sort.Slice(ready, func(i, j int) bool { return seen[ready[i]].modTime.Before(seen[ready[j]].modTime) })
+
+	for _, name := range ready {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := w.importFile(ctx, name); err != nil {
+			slog.Warn("importing watched file failed", "file", name, "error", err)
+		}
+		delete(w.seen, name)
+	}
+}
+
+func (w *Watcher) importFile(ctx context.Context, name string) error {
+	path := filepath.Join(w.dir, name)
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	pcm, err := w.decode(path, audio.TargetSampleRate)
+	if err != nil {
+		return errors.Join(err, w.moveAside(name))
+	}
+
+	rec := session.Recording{PCM: pcm, SampleRate: audio.TargetSampleRate}
+	// Phones stamp a recording when it is saved, at its end.
+	rec.StartedAt = info.ModTime().Add(-rec.Duration())
+	ext := strings.ToLower(filepath.Ext(name))
+	rec.SaveAudio = func(sessionID string) (string, error) {
+		dst := filepath.Join(w.audioDir, sessionID+ext)
+		if err := copyFile(path, dst); err != nil {
+			return "", err
+		}
+		audio.KeepWaveform(dst, pcm, audio.TargetSampleRate)
+		return dst, nil
+	}
+
+	sessionID, err := w.importer.ImportRecording(ctx, rec)
+	// A file stored as a session is archived even if the rest failed, so it
+	// isn't imported twice.
+	if err != nil && (sessionID == "" || errors.Is(err, session.ErrImportUnavailable)) {
+		return w.retryLater(name, err)
+	}
+	if err == nil {
+		slog.Info("imported watched file", "file", name, "session", sessionID)
+	}
+	delete(w.retries, name)
+	return errors.Join(err, w.archive(name, sessionID))
+}
+
+// retryLater schedules another attempt at a file whose import failed, or
+// moves it to the failed folder once it has failed importRetries times.
+func (w *Watcher) retryLater(name string, err error) error {
+	r := w.retries[name]
+	r.failures++
+	if r.failures >= importRetries {
+		delete(w.retries, name)
+		return errors.Join(fmt.Errorf("import %s failed %d times, giving up: %w", name, r.failures, err), w.moveAside(name))
+	}
+	r.next = w.now().Add(retryBackoff << (r.failures - 1))
+	w.retries[name] = r
+	return fmt.Errorf("import %s (will retry): %w", name, err)
+}
+
+// archive moves an imported file into the archive folder, prefixing it with
+// its session ID if the name is already taken.
+func (w *Watcher) archive(name, sessionID string) error {
+	if err := os.MkdirAll(w.archiveDir, 0o755); err != nil {
+		return fmt.Errorf("create archive folder: %w", err)
+	}
+	dst := filepath.Join(w.archiveDir, name)
+	if _, err := os.Stat(dst); err == nil {
+		dst = filepath.Join(w.archiveDir, sessionID+"-"+name)
+	}
+	return moveFile(filepath.Join(w.dir, name), dst)
+}
+
+// moveAside moves a file that can't be imported into the failed folder.
+func (w *Watcher) moveAside(name string) error {
+	dir := filepath.Join(w.dir, failedDir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("create failed folder: %w", err)
+	}
+	return moveFile(filepath.Join(w.dir, name), filepath.Join(dir, name))
+}
+
+// moveFile renames src to dst, copying it across filesystems.
+func moveFile(src, dst string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+	if err := copyFile(src, dst); err != nil {
+		return err
+	}
+	return os.Remove(src)
+}
+
+func copyFile(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return fmt.Errorf("create folder for %s: %w", dst, err)
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		_ = os.Remove(dst)
+		return fmt.Errorf("copy %s: %w", src, err)
+	}
+	return out.Close()
+}
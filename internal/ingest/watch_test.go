@@ -0,0 +1,176 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/audio"
+	"github.com/sjawhar/ghost-wispr/internal/session"
+)
+
+type importerStub struct {
+	recordings []session.Recording
+	audioPaths []string
+	err        error
+}
+
+func (s *importerStub) ImportRecording(_ context.Context, rec session.Recording) (string, error) {
+	if s.err != nil {
+		return "", s.err
+	}
+	s.recordings = append(s.recordings, rec)
+	id := rec.StartedAt.UTC().Format("20060102150405")
+	path, err := rec.SaveAudio(id)
+	if err != nil {
+		return "", err
+	}
+	s.audioPaths = append(s.audioPaths, path)
+	return id, nil
+}
+
+func writeFile(t *testing.T, path, content string, modTime time.Time) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWatcherImportsSettledFiles(t *testing.T) {
+	dir, audioDir := t.TempDir(), t.TempDir()
+	importer := &importerStub{}
+	w := NewWatcher(dir, "", audioDir, importer)
+	w.decode = func(string, int) ([]byte, error) {
+		return make([]byte, 60*2*audio.TargetSampleRate), nil
+	}
+
+	savedAt := time.Date(2026, 3, 2, 9, 1, 0, 0, time.UTC)
+	writeFile(t, filepath.Join(dir, "memo.m4a"), "audio", savedAt)
+	writeFile(t, filepath.Join(dir, ".syncthing.memo2.m4a.tmp"), "partial", savedAt)
+	writeFile(t, filepath.Join(dir, "notes.txt"), "not audio", savedAt)
+
+	ctx := context.Background()
+	w.Scan(ctx)
+	if len(importer.recordings) != 0 {
+		t.Fatal("expected nothing imported before the file settles")
+	}
+	w.Scan(ctx)
+	if len(importer.recordings) != 1 {
+		t.Fatalf("expected one import, got %d", len(importer.recordings))
+	}
+	if want := savedAt.Add(-time.Minute); !importer.recordings[0].StartedAt.Equal(want) {
+		t.Fatalf("expected the recording to start a minute before it was saved, got %v", importer.recordings[0].StartedAt)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "memo.m4a")); !os.IsNotExist(err) {
+		t.Fatalf("expected the file moved out of the watch folder, got %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "imported", "memo.m4a")); err != nil || string(data) != "audio" {
+		t.Fatalf("expected the file archived, got %q, %v", data, err)
+	}
+	want := filepath.Join(audioDir, "20260302090000.m4a")
+	if len(importer.audioPaths) != 1 || importer.audioPaths[0] != want {
+		t.Fatalf("expected the audio kept at %s, got %v", want, importer.audioPaths)
+	}
+	if _, err := os.Stat(want); err != nil {
+		t.Fatalf("expected a copy of the audio kept: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "notes.txt")); err != nil {
+		t.Fatalf("expected other files left alone: %v", err)
+	}
+}
+
+func TestWatcherWaitsForGrowingFiles(t *testing.T) {
+	dir := t.TempDir()
+	importer := &importerStub{}
+	w := NewWatcher(dir, "", t.TempDir(), importer)
+	w.decode = func(string, int) ([]byte, error) { return make([]byte, 320), nil }
+
+	path := filepath.Join(dir, "call.mp3")
+	at := time.Now().Add(-time.Minute)
+	writeFile(t, path, "a", at)
+	w.Scan(context.Background())
+	writeFile(t, path, "ab", at.Add(time.Second))
+	w.Scan(context.Background())
+	if len(importer.recordings) != 0 {
+		t.Fatal("expected a file still being written to be left alone")
+	}
+	w.Scan(context.Background())
+	if len(importer.recordings) != 1 {
+		t.Fatalf("expected the file imported once settled, got %d imports", len(importer.recordings))
+	}
+}
+
+func TestWatcherMovesUndecodableFilesAside(t *testing.T) {
+	dir := t.TempDir()
+	importer := &importerStub{}
+	w := NewWatcher(dir, "", t.TempDir(), importer)
+	w.decode = func(string, int) ([]byte, error) { return nil, audio.ErrUndecodable }
+
+	writeFile(t, filepath.Join(dir, "broken.ogg"), "???", time.Now())
+	w.Scan(context.Background())
+	w.Scan(context.Background())
+	if len(importer.recordings) != 0 {
+		t.Fatal("expected nothing imported")
+	}
+	if _, err := os.Stat(filepath.Join(dir, failedDir, "broken.ogg")); err != nil {
+		t.Fatalf("expected the file moved to the failed folder: %v", err)
+	}
+}
+
+func TestWatcherRetriesFailedImports(t *testing.T) {
+	dir := t.TempDir()
+	importer := &importerStub{err: errors.New("deepgram unreachable")}
+	w := NewWatcher(dir, "", t.TempDir(), importer)
+	w.decode = func(string, int) ([]byte, error) { return make([]byte, 320), nil }
+	now := time.Now()
+	w.now = func() time.Time { return now }
+
+	writeFile(t, filepath.Join(dir, "memo.wav"), "audio", time.Now())
+	w.Scan(context.Background())
+	w.Scan(context.Background())
+	if _, err := os.Stat(filepath.Join(dir, "memo.wav")); err != nil {
+		t.Fatalf("expected the file left in place to retry: %v", err)
+	}
+
+	importer.err = nil
+	w.Scan(context.Background())
+	w.Scan(context.Background())
+	if len(importer.recordings) != 0 {
+		t.Fatal("expected no retry before the backoff passes")
+	}
+	now = now.Add(retryBackoff)
+	w.Scan(context.Background())
+	if len(importer.recordings) != 1 {
+		t.Fatalf("expected the retry to import the file, got %d imports", len(importer.recordings))
+	}
+}
+
+func TestWatcherGivesUpOnUnavailableImports(t *testing.T) {
+	dir := t.TempDir()
+	importer := &importerStub{err: session.ErrImportUnavailable}
+	w := NewWatcher(dir, "", t.TempDir(), importer)
+	w.decode = func(string, int) ([]byte, error) { return make([]byte, 320), nil }
+	now := time.Now()
+	w.now = func() time.Time { return now }
+
+	writeFile(t, filepath.Join(dir, "memo.wav"), "audio", time.Now())
+	w.Scan(context.Background())
+	for i := range importRetries {
+		w.Scan(context.Background())
+		if _, err := os.Stat(filepath.Join(dir, "memo.wav")); (err == nil) != (i < importRetries-1) {
+			t.Fatalf("attempt %d: unexpected watch folder state: %v", i+1, err)
+		}
+		now = now.Add(retryBackoff << i)
+		w.Scan(context.Background())
+	}
+	if _, err := os.Stat(filepath.Join(dir, failedDir, "memo.wav")); err != nil {
+		t.Fatalf("expected the file moved to the failed folder after %d attempts: %v", importRetries, err)
+	}
+}
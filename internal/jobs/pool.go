@@ -0,0 +1,138 @@
+// Package jobs bounds how many background jobs of each kind run at once, so
+// a small device isn't swamped by parallel LLM calls, exports and syncs.
+package jobs
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// Stats is a snapshot of a pool's queue.
+type Stats struct {
+	Name      string `json:"name"`
+	Workers   int    `json:"workers"`
+	Queued    int64  `json:"queued"`
+	Running   int64  `json:"running"`
+	Completed int64  `json:"completed"`
+	// Held is whether new jobs are being held back; see Pool.Hold.
+	Held bool `json:"held"`
+}
+
+// Pool admits at most Workers jobs at a time; the rest wait in line. A nil
+// *Pool admits everything immediately.
+type Pool struct {
+	name    string
+	workers int
+	slots   chan struct{}
+
+	queued    atomic.Int64
+	running   atomic.Int64
+	completed atomic.Int64
+
+	mu sync.Mutex
+	// held is closed when Hold(false) lets waiting jobs start; nil when
+	// jobs aren't held.
+	held chan struct{}
+}
+
+func NewPool(name string, workers int) *Pool {
+	if workers < 1 {
+		workers = 1
+	}
+	return &Pool{name: name, workers: workers, slots: make(chan struct{}, workers)}
+}
+
+// Acquire waits for a free worker slot. The returned release must be called
+// when the job finishes.
+func (p *Pool) Acquire(ctx context.Context) (func(), error) {
+	if p == nil {
+		return func() {}, nil
+	}
+
+	p.queued.Add(1)
+	for held := p.holding(); held != nil; held = p.holding() {
+		select {
+		case <-held:
+		case <-ctx.Done():
+			p.queued.Add(-1)
+			return nil, ctx.Err()
+		}
+	}
+	select {
+	case p.slots <- struct{}{}:
+		p.queued.Add(-1)
+	case <-ctx.Done():
+		p.queued.Add(-1)
+		return nil, ctx.Err()
+	}
+
+	p.running.Add(1)
+	var released atomic.Bool
+	return func() {
+		if released.Swap(true) {
+			return
+		}
+		p.running.Add(-1)
+		p.completed.Add(1)
+		<-p.slots
+	}, nil
+}
+
+// Hold stops new jobs from starting, as while the device is short of
+// memory or CPU, until Hold(false). They wait in line rather than fail, and
+// jobs already running carry on.
+func (p *Pool) Hold(hold bool) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	switch {
+	case hold && p.held == nil:
+		p.held = make(chan struct{})
+	case !hold && p.held != nil:
+		close(p.held)
+		p.held = nil
+	}
+}
+
+func (p *Pool) holding() chan struct{} {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.held
+}
+
+// Do runs fn once a worker slot is free.
+func (p *Pool) Do(ctx context.Context, fn func() error) error {
+	release, err := p.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return fn()
+}
+
+// Go queues fn to run in the background once a worker slot is free.
+func (p *Pool) Go(fn func()) {
+	go func() {
+		_ = p.Do(context.Background(), func() error {
+			fn()
+			return nil
+		})
+	}()
+}
+
+func (p *Pool) Stats() Stats {
+	if p == nil {
+		return Stats{}
+	}
+	return Stats{
+		Name:      p.name,
+		Workers:   p.workers,
+		Queued:    p.queued.Load(),
+		Running:   p.running.Load(),
+		Completed: p.completed.Load(),
+		Held:      p.holding() != nil,
+	}
+}
@@ -0,0 +1,92 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestPoolLimitsConcurrency(t *testing.T) {
+	pool := NewPool("summaries", 2)
+	gate := make(chan struct{})
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		pool.Go(func() {
+			defer wg.Done()
+			<-gate
+		})
+	}
+
+	waitFor(t, func() bool { s := pool.Stats(); return s.Running == 2 && s.Queued == 3 })
+
+	close(gate)
+	wg.Wait()
+	waitFor(t, func() bool { return pool.Stats().Completed == 5 })
+	if s := pool.Stats(); s.Running != 0 || s.Queued != 0 || s.Workers != 2 || s.Name != "summaries" {
+		t.Fatalf("unexpected final stats %+v", s)
+	}
+}
+
+func TestAcquireHonorsContext(t *testing.T) {
+	pool := NewPool("exports", 1)
+	release, err := pool.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := pool.Acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if s := pool.Stats(); s.Queued != 0 {
+		t.Fatalf("expected abandoned waiter to leave the queue, got %+v", s)
+	}
+}
+
+func TestHoldKeepsJobsWaiting(t *testing.T) {
+	pool := NewPool("backups", 2)
+	pool.Hold(true)
+	pool.Hold(true)
+	done := make(chan struct{})
+	pool.Go(func() { close(done) })
+
+	waitFor(t, func() bool { s := pool.Stats(); return s.Queued == 1 && s.Held })
+	select {
+	case <-done:
+		t.Fatal("expected a held pool not to start the job")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	pool.Hold(false)
+	<-done
+	if s := pool.Stats(); s.Held {
+		t.Fatalf("expected the pool to be released, got %+v", s)
+	}
+
+	var nilPool *Pool
+	nilPool.Hold(true)
+}
+
+func TestNilPoolRunsImmediately(t *testing.T) {
+	var pool *Pool
+	ran := false
+	if err := pool.Do(context.Background(), func() error { ran = true; return nil }); err != nil || !ran {
+		t.Fatalf("expected nil pool to run job, ran=%v err=%v", ran, err)
+	}
+}
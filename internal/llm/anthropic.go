@@ -47,6 +47,7 @@ func (c *anthropicClient) Complete(ctx context.Context, messages []Message) (str
 	if err != nil {
 		return "", fmt.Errorf("anthropic completion: %w", err)
 	}
+	recordUsage(ctx, "anthropic/"+c.model, int(resp.Usage.InputTokens), int(resp.Usage.OutputTokens))
 
 	var b strings.Builder
 	for i := range resp.Content {
@@ -62,3 +63,10 @@ func (c *anthropicClient) Complete(ctx context.Context, messages []Message) (str
 	}
 	return result, nil
 }
+
+func (c *anthropicClient) Check(ctx context.Context) error {
+	if _, err := c.client.Models.Get(ctx, c.model, anthropic.ModelGetParams{}); err != nil {
+		return fmt.Errorf("anthropic model %s: %w", c.model, err)
+	}
+	return nil
+}
@@ -65,6 +65,9 @@ func (c *geminiClient) Complete(ctx context.Context, messages []Message) (string
 	if err != nil {
 		return "", fmt.Errorf("gemini completion: %w", err)
 	}
+	if result.UsageMetadata != nil {
+		recordUsage(ctx, "gemini/"+c.model, int(result.UsageMetadata.PromptTokenCount), int(result.UsageMetadata.CandidatesTokenCount))
+	}
 
 	text := strings.TrimSpace(result.Text())
 	if text == "" {
@@ -72,3 +75,10 @@ func (c *geminiClient) Complete(ctx context.Context, messages []Message) (string
 	}
 	return text, nil
 }
+
+func (c *geminiClient) Check(ctx context.Context) error {
+	if _, err := c.client.Models.Get(ctx, c.model, nil); err != nil {
+		return fmt.Errorf("gemini model %s: %w", c.model, err)
+	}
+	return nil
+}
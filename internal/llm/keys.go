@@ -0,0 +1,32 @@
+package llm
+
+import "sync"
+
+// Keys holds the API key of each provider. Clients are made with the key
+// current at the time, so keys can be rotated while the app runs.
+type Keys struct {
+	mu   sync.RWMutex
+	keys map[string]string
+}
+
+func NewKeys(keys map[string]string) *Keys {
+	k := &Keys{keys: make(map[string]string, len(keys))}
+	for provider, key := range keys {
+		k.keys[provider] = key
+	}
+	return k
+}
+
+// Get returns the provider's key, or "" if it has none.
+func (k *Keys) Get(provider string) string {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.keys[provider]
+}
+
+// Set replaces the provider's key.
+func (k *Keys) Set(provider, key string) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.keys[provider] = key
+}
@@ -3,6 +3,8 @@ package llm
 import (
 	"context"
 	"fmt"
+	"net"
+	"net/url"
 	"strings"
 )
 
@@ -15,6 +17,12 @@ type Client interface {
 	Complete(ctx context.Context, messages []Message) (string, error)
 }
 
+// Checker is a Client that can confirm its API key and model are accepted
+// without spending tokens, by looking the model up.
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
 type Option func(*clientOptions)
 
 type clientOptions struct {
@@ -35,6 +43,38 @@ func ParseModel(model string) (provider, modelName string, err error) {
 	return parts[0], parts[1], nil
 }
 
+// apiHosts are where each provider's API is served without a base URL.
+var apiHosts = map[string]string{
+	"openai":    "api.openai.com",
+	"anthropic": "api.anthropic.com",
+	"gemini":    "generativelanguage.googleapis.com",
+}
+
+// Endpoint returns the host:port a provider's API is reached at, through
+// baseURL when it is set, for checking that it is reachable.
+func Endpoint(provider, baseURL string) (string, error) {
+	if baseURL == "" {
+		host, ok := apiHosts[provider]
+		if !ok {
+			return "", fmt.Errorf("unknown LLM provider %q", provider)
+		}
+		return net.JoinHostPort(host, "443"), nil
+	}
+	u, err := url.Parse(baseURL)
+	if err != nil || u.Hostname() == "" {
+		return "", fmt.Errorf("invalid base URL %q", baseURL)
+	}
+	port := u.Port()
+	switch {
+	case port != "":
+	case u.Scheme == "http":
+		port = "80"
+	default:
+		port = "443"
+	}
+	return net.JoinHostPort(u.Hostname(), port), nil
+}
+
 func NewClient(provider, apiKey, model string, opts ...Option) (Client, error) {
 	o := &clientOptions{}
 	for _, opt := range opts {
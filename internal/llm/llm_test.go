@@ -5,6 +5,27 @@ import (
 	"testing"
 )
 
+func TestEndpoint(t *testing.T) {
+	tests := []struct {
+		provider, baseURL, want string
+	}{
+		{"openai", "", "api.openai.com:443"},
+		{"anthropic", "", "api.anthropic.com:443"},
+		{"gemini", "", "generativelanguage.googleapis.com:443"},
+		{"openai", "http://localhost:11434/v1", "localhost:11434"},
+		{"openai", "http://llm.lan/v1", "llm.lan:80"},
+		{"anthropic", "https://proxy.example.com", "proxy.example.com:443"},
+	}
+	for _, tt := range tests {
+		if got, err := Endpoint(tt.provider, tt.baseURL); err != nil || got != tt.want {
+			t.Errorf("Endpoint(%q, %q) = %q, %v; want %q", tt.provider, tt.baseURL, got, err, tt.want)
+		}
+	}
+	if _, err := Endpoint("mistral", ""); err == nil {
+		t.Error("expected an error for an unknown provider")
+	}
+}
+
 func TestParseModel(t *testing.T) {
 	tests := []struct {
 		name         string
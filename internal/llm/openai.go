@@ -31,9 +31,17 @@ func (c *openaiClient) Complete(ctx context.Context, messages []Message) (string
 	if err != nil {
 		return "", fmt.Errorf("openai completion: %w", err)
 	}
+	recordUsage(ctx, "openai/"+c.model, resp.Usage.PromptTokens, resp.Usage.CompletionTokens)
 	if len(resp.Choices) == 0 {
 		return "", fmt.Errorf("openai: no choices in response")
 	}
 
 	return strings.TrimSpace(resp.Choices[0].Message.Content), nil
 }
+
+func (c *openaiClient) Check(ctx context.Context) error {
+	if _, err := c.client.GetModel(ctx, c.model); err != nil {
+		return fmt.Errorf("openai model %s: %w", c.model, err)
+	}
+	return nil
+}
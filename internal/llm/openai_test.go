@@ -131,3 +131,62 @@ func TestOpenAI_Complete_EmptyChoices(t *testing.T) {
 		t.Fatalf("expected 'no choices' in error, got %q", err.Error())
 	}
 }
+
+func TestOpenAI_Complete_RecordsUsage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"id":      "chatcmpl-3",
+			"object":  "chat.completion",
+			"created": 123,
+			"model":   "gpt-4o-mini",
+			"choices": []map[string]any{{
+				"index":         0,
+				"message":       map[string]any{"role": "assistant", "content": "ok"},
+				"finish_reason": "stop",
+			}},
+			"usage": map[string]any{"prompt_tokens": 120, "completion_tokens": 30, "total_tokens": 150},
+		})
+	}))
+	defer server.Close()
+
+	client, err := newOpenAIClient("test-key", "gpt-4o-mini", &clientOptions{baseURL: server.URL + "/v1"})
+	if err != nil {
+		t.Fatalf("newOpenAIClient failed: %v", err)
+	}
+
+	recorder := NewUsageRecorder()
+	ctx := WithUsageRecorder(context.Background(), recorder)
+	if _, err := client.Complete(ctx, []Message{{Role: "user", Content: "hello"}}); err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+
+	got := recorder.ByModel()["openai/gpt-4o-mini"]
+	if got.InputTokens != 120 || got.OutputTokens != 30 {
+		t.Fatalf("expected 120/30 tokens, got %+v", got)
+	}
+}
+
+func TestOpenAICheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer good-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_ = json.NewEncoder(w).Encode(map[string]any{"error": map[string]any{"message": "invalid key"}})
+			return
+		}
+		if r.URL.Path != "/v1/models/gpt-4o-mini" {
+			t.Fatalf("unexpected path %q", r.URL.Path)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": "gpt-4o-mini", "object": "model"})
+	}))
+	defer server.Close()
+
+	for key, healthy := range map[string]bool{"good-key": true, "bad-key": false} {
+		client, err := newOpenAIClient(key, "gpt-4o-mini", &clientOptions{baseURL: server.URL + "/v1"})
+		if err != nil {
+			t.Fatalf("newOpenAIClient failed: %v", err)
+		}
+		if err := client.Check(context.Background()); (err == nil) != healthy {
+			t.Fatalf("%s: expected healthy=%v, got error %v", key, healthy, err)
+		}
+	}
+}
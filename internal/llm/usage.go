@@ -0,0 +1,70 @@
+package llm
+
+import (
+	"context"
+	"sync"
+)
+
+// TokenUsage counts the tokens consumed by one or more completions.
+type TokenUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// UsageRecorder accumulates token usage per "provider/model". Attach one to a
+// context with WithUsageRecorder and every Complete call made with that
+// context reports its usage into it.
+type UsageRecorder struct {
+	mu      sync.Mutex
+	byModel map[string]TokenUsage
+}
+
+func NewUsageRecorder() *UsageRecorder {
+	return &UsageRecorder{byModel: make(map[string]TokenUsage)}
+}
+
+// Add records tokens consumed by a completion against model.
+func (r *UsageRecorder) Add(model string, inputTokens, outputTokens int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u := r.byModel[model]
+	u.InputTokens += inputTokens
+	u.OutputTokens += outputTokens
+	r.byModel[model] = u
+}
+
+// ByModel returns a copy of the accumulated usage keyed by "provider/model".
+func (r *UsageRecorder) ByModel() map[string]TokenUsage {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make(map[string]TokenUsage, len(r.byModel))
+	for model, u := range r.byModel {
+		out[model] = u
+	}
+	return out
+}
+
+// Total returns the usage summed across all models.
+func (r *UsageRecorder) Total() TokenUsage {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var total TokenUsage
+	for _, u := range r.byModel {
+		total.InputTokens += u.InputTokens
+		total.OutputTokens += u.OutputTokens
+	}
+	return total
+}
+
+type usageRecorderKey struct{}
+
+// WithUsageRecorder returns a context whose completions report into r.
+func WithUsageRecorder(ctx context.Context, r *UsageRecorder) context.Context {
+	return context.WithValue(ctx, usageRecorderKey{}, r)
+}
+
+func recordUsage(ctx context.Context, model string, inputTokens, outputTokens int) {
+	if r, ok := ctx.Value(usageRecorderKey{}).(*UsageRecorder); ok && r != nil {
+		r.Add(model, inputTokens, outputTokens)
+	}
+}
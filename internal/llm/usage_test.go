@@ -0,0 +1,24 @@
+package llm
+
+import (
+	"context"
+	"testing"
+)
+
+func TestUsageRecorderAccumulates(t *testing.T) {
+	recorder := NewUsageRecorder()
+	ctx := WithUsageRecorder(context.Background(), recorder)
+
+	recordUsage(ctx, "openai/gpt-4o-mini", 100, 10)
+	recordUsage(ctx, "openai/gpt-4o-mini", 50, 5)
+	recordUsage(ctx, "anthropic/claude-3-5-haiku-latest", 20, 2)
+	recordUsage(context.Background(), "openai/gpt-4o-mini", 1000, 1000)
+
+	byModel := recorder.ByModel()
+	if got := byModel["openai/gpt-4o-mini"]; got.InputTokens != 150 || got.OutputTokens != 15 {
+		t.Fatalf("unexpected openai usage %+v", got)
+	}
+	if total := recorder.Total(); total.InputTokens != 170 || total.OutputTokens != 17 {
+		t.Fatalf("unexpected total usage %+v", total)
+	}
+}
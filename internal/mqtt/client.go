@@ -0,0 +1,279 @@
+// Package mqtt publishes Ghost Wispr state to an MQTT broker using Home
+// Assistant's discovery schema. It carries a minimal MQTT 3.1.1 client that
+// only publishes at QoS 0, which is all state publishing needs.
+package mqtt
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	packetConnect    = 0x10
+	packetConnack    = 0x20
+	packetPublish    = 0x30
+	packetPingreq    = 0xC0
+	packetDisconnect = 0xE0
+
+	flagRetain = 0x01
+)
+
+// Message is a retained or transient publish.
+type Message struct {
+	Topic   string
+	Payload []byte
+	Retain  bool
+}
+
+// Options configure a broker connection. Will, if set, is published by the
+// broker when the connection drops without a DISCONNECT.
+type Options struct {
+	Broker    string
+	ClientID  string
+	Username  string
+	Password  string
+	KeepAlive time.Duration
+	Will      *Message
+}
+
+// Client is a publish-only MQTT 3.1.1 connection.
+type Client struct {
+	conn net.Conn
+
+	mu     sync.Mutex
+	closed chan struct{}
+	err    error
+	once   sync.Once
+}
+
+// Dial connects and completes the MQTT handshake. Broker is host:port,
+// optionally prefixed with tcp:// or mqtt://.
+func Dial(ctx context.Context, opts Options) (*Client, error) {
+	addr := strings.TrimPrefix(strings.TrimPrefix(opts.Broker, "tcp://"), "mqtt://")
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, "1883")
+	}
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("dial mqtt broker: %w", err)
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	if _, err := conn.Write(connectPacket(opts)); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("send connect: %w", err)
+	}
+
+	r := bufio.NewReader(conn)
+	header, body, err := readPacket(r)
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("read connack: %w", err)
+	}
+	if header&0xF0 != packetConnack || len(body) != 2 {
+		_ = conn.Close()
+		return nil, fmt.Errorf("unexpected packet 0x%02x waiting for connack", header)
+	}
+	if code := body[1]; code != 0 {
+		_ = conn.Close()
+		return nil, fmt.Errorf("broker refused connection: %s", connackReason(code))
+	}
+	_ = conn.SetDeadline(time.Time{})
+
+	c := &Client{conn: conn, closed: make(chan struct{})}
+	go c.readLoop(r)
+	if opts.KeepAlive > 0 {
+		go c.pingLoop(opts.KeepAlive)
+	}
+	return c, nil
+}
+
+// Publish sends msg at QoS 0.
+func (c *Client) Publish(msg Message) error {
+	header := byte(packetPublish)
+	if msg.Retain {
+		header |= flagRetain
+	}
+	body := appendString(nil, msg.Topic)
+	body = append(body, msg.Payload...)
+	return c.write(packet(header, body))
+}
+
+// Done is closed once the connection is lost or closed.
+func (c *Client) Done() <-chan struct{} {
+	return c.closed
+}
+
+// Err reports why the connection ended.
+func (c *Client) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// Close sends DISCONNECT, so the broker discards the will, and closes.
+func (c *Client) Close() error {
+	_ = c.write([]byte{packetDisconnect, 0})
+	c.fail(errors.New("closed"))
+	return nil
+}
+
+func (c *Client) write(p []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return c.err
+	}
+	_ = c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	if _, err := c.conn.Write(p); err != nil {
+		return fmt.Errorf("mqtt write: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) fail(err error) {
+	c.once.Do(func() {
+		c.mu.Lock()
+		c.err = err
+		c.mu.Unlock()
+		_ = c.conn.Close()
+		close(c.closed)
+	})
+}
+
+// readLoop drains broker packets (PINGRESP is the only one expected) so a
+// dead connection is noticed.
+func (c *Client) readLoop(r *bufio.Reader) {
+	for {
+		if _, _, err := readPacket(r); err != nil {
+			c.fail(fmt.Errorf("mqtt connection lost: %w", err))
+			return
+		}
+	}
+}
+
+func (c *Client) pingLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.closed:
+			return
+		case <-ticker.C:
+			if err := c.write([]byte{packetPingreq, 0}); err != nil {
+				c.fail(err)
+				return
+			}
+		}
+	}
+}
+
+func connectPacket(opts Options) []byte {
+	flags := byte(0x02) // clean session
+	if opts.Will != nil {
+		flags |= 0x04
+		if opts.Will.Retain {
+			flags |= 0x20
+		}
+	}
+	if opts.Username != "" {
+		flags |= 0x80
+		if opts.Password != "" {
+			flags |= 0x40
+		}
+	}
+
+	body := appendString(nil, "MQTT")
+	body = append(body, 4, flags)
+	body = binary.BigEndian.AppendUint16(body, uint16(opts.KeepAlive/time.Second))
+	body = appendString(body, opts.ClientID)
+	if opts.Will != nil {
+		body = appendString(body, opts.Will.Topic)
+		body = appendString(body, string(opts.Will.Payload))
+	}
+	if opts.Username != "" {
+		body = appendString(body, opts.Username)
+		if opts.Password != "" {
+			body = appendString(body, opts.Password)
+		}
+	}
+	return packet(packetConnect, body)
+}
+
+func packet(header byte, body []byte) []byte {
+	out := []byte{header}
+	n := len(body)
+	for {
+		b := byte(n % 128)
+		n /= 128
+		if n > 0 {
+			b |= 0x80
+		}
+		out = append(out, b)
+		if n == 0 {
+			break
+		}
+	}
+	return append(out, body...)
+}
+
+func appendString(b []byte, s string) []byte {
+	b = binary.BigEndian.AppendUint16(b, uint16(len(s)))
+	return append(b, s...)
+}
+
+func readPacket(r *bufio.Reader) (byte, []byte, error) {
+	header, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	length, multiplier := 0, 1
+	for i := 0; ; i++ {
+		if i == 4 {
+			return 0, nil, errors.New("malformed remaining length")
+		}
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		length += int(b&0x7F) * multiplier
+		multiplier *= 128
+		if b&0x80 == 0 {
+			break
+		}
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, nil, err
+	}
+	return header, body, nil
+}
+
+func connackReason(code byte) string {
+	switch code {
+	case 1:
+		return "unacceptable protocol version"
+	case 2:
+		return "identifier rejected"
+	case 3:
+		return "server unavailable"
+	case 4:
+		return "bad username or password"
+	case 5:
+		return "not authorized"
+	default:
+		return fmt.Sprintf("code %d", code)
+	}
+}
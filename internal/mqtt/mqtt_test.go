@@ -0,0 +1,174 @@
+package mqtt
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+)
+
+type brokerStub struct {
+	ln       net.Listener
+	connects chan []byte
+	publish  chan Message
+}
+
+func newBrokerStub(t *testing.T) *brokerStub {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &brokerStub{ln: ln, connects: make(chan []byte, 4), publish: make(chan Message, 64)}
+	t.Cleanup(func() { _ = ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go b.serve(conn)
+		}
+	}()
+	return b
+}
+
+func (b *brokerStub) serve(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+	r := bufio.NewReader(conn)
+	for {
+		header, body, err := readPacket(r)
+		if err != nil {
+			return
+		}
+		switch header & 0xF0 {
+		case packetConnect:
+			b.connects <- body
+			_, _ = conn.Write([]byte{packetConnack, 2, 0, 0})
+		case packetPublish:
+			n := int(binary.BigEndian.Uint16(body))
+			b.publish <- Message{Topic: string(body[2 : 2+n]), Payload: body[2+n:], Retain: header&flagRetain != 0}
+		case packetDisconnect:
+			return
+		}
+	}
+}
+
+func (b *brokerStub) next(t *testing.T) Message {
+	t.Helper()
+	select {
+	case m := <-b.publish:
+		return m
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for publish")
+		return Message{}
+	}
+}
+
+func TestClientConnectAndPublish(t *testing.T) {
+	broker := newBrokerStub(t)
+
+	c, err := Dial(context.Background(), Options{
+		Broker:   "tcp://" + broker.ln.Addr().String(),
+		ClientID: "ghost-wispr",
+		Username: "user",
+		Password: "secret",
+		Will:     &Message{Topic: "gw/availability", Payload: []byte("offline"), Retain: true},
+	})
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer func() { _ = c.Close() }()
+
+	connect := <-broker.connects
+	if string(connect[2:6]) != "MQTT" || connect[6] != 4 {
+		t.Fatalf("unexpected protocol header %q", connect[:7])
+	}
+	if flags := connect[7]; flags != 0x02|0x04|0x20|0x80|0x40 {
+		t.Fatalf("unexpected connect flags %08b", flags)
+	}
+
+	if err := c.Publish(Message{Topic: "gw/recording", Payload: []byte("ON"), Retain: true}); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	got := broker.next(t)
+	if got.Topic != "gw/recording" || string(got.Payload) != "ON" || !got.Retain {
+		t.Fatalf("unexpected publish %+v", got)
+	}
+}
+
+func TestPacketRemainingLength(t *testing.T) {
+	p := packet(packetPublish, make([]byte, 321))
+	if p[1] != 0xC1 || p[2] != 0x02 || len(p) != 3+321 {
+		t.Fatalf("unexpected varint encoding % x", p[:3])
+	}
+}
+
+func TestPublisherAnnouncesAndTracksState(t *testing.T) {
+	broker := newBrokerStub(t)
+	pub := NewPublisher(Options{Broker: broker.ln.Addr().String(), ClientID: "Office Pi"}, "ghost-wispr", "homeassistant", false)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events := make(chan []byte, 8)
+	done := make(chan struct{})
+	go func() {
+		pub.Run(ctx, events)
+		close(done)
+	}()
+
+	announced := map[string]Message{}
+	for range len(pub.announce()) {
+		m := broker.next(t)
+		announced[m.Topic] = m
+	}
+	cfg, ok := announced["homeassistant/binary_sensor/office_pi/recording/config"]
+	if !ok || !cfg.Retain {
+		t.Fatalf("expected retained recording discovery config, got %v", announced)
+	}
+	var discovery map[string]any
+	if err := json.Unmarshal(cfg.Payload, &discovery); err != nil || discovery["state_topic"] != "ghost-wispr/recording" {
+		t.Fatalf("unexpected discovery payload %s", cfg.Payload)
+	}
+	if string(announced["ghost-wispr/availability"].Payload) != "online" {
+		t.Fatal("expected availability online")
+	}
+	if string(announced["ghost-wispr/recording"].Payload) != "OFF" {
+		t.Fatal("expected recording OFF before a session")
+	}
+
+	events <- []byte(`{"type":"session_started","timestamp":"2026-01-01T10:00:00Z","session_id":"s1"}`)
+	if m := broker.next(t); m.Topic != "ghost-wispr/recording" || string(m.Payload) != "ON" {
+		t.Fatalf("expected recording ON, got %+v", m)
+	}
+	if m := broker.next(t); m.Topic != "ghost-wispr/session" || string(m.Payload) != `{"session_id":"s1","started_at":"2026-01-01T10:00:00Z"}` {
+		t.Fatalf("unexpected session state %+v", m)
+	}
+
+	events <- []byte(`{"type":"status_changed","paused":true}`)
+	if m := broker.next(t); string(m.Payload) != "OFF" {
+		t.Fatalf("expected recording OFF while paused, got %s", m.Payload)
+	}
+	if m := broker.next(t); m.Topic != "ghost-wispr/paused" || string(m.Payload) != "ON" {
+		t.Fatalf("expected paused ON, got %+v", m)
+	}
+
+	events <- []byte(`{"type":"summary_ready","session_id":"s1","status":"running"}`)
+	events <- []byte(`{"type":"summary_ready","session_id":"s1","status":"completed","summary":"## Done"}`)
+	m := broker.next(t)
+	if m.Topic != "ghost-wispr/summary" || m.Retain {
+		t.Fatalf("expected transient summary event, got %+v", m)
+	}
+	var summary map[string]string
+	if err := json.Unmarshal(m.Payload, &summary); err != nil || summary["event_type"] != "summary_ready" || summary["summary"] != "## Done" {
+		t.Fatalf("unexpected summary event %s", m.Payload)
+	}
+
+	cancel()
+	<-done
+	if m := broker.next(t); m.Topic != "ghost-wispr/availability" || string(m.Payload) != "offline" {
+		t.Fatalf("expected offline on shutdown, got %+v", m)
+	}
+}
@@ -0,0 +1,251 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// Publisher mirrors hub events to MQTT topics under Prefix and announces
+// them to Home Assistant under DiscoveryPrefix:
+//
+//	<prefix>/availability  online | offline (broker will)
+//	<prefix>/recording     ON while a session is active and capture is not paused
+//	<prefix>/paused        ON while capture is paused
+//	<prefix>/session       {"session_id": "...", "started_at": "..."} or {} when idle
+//	<prefix>/summary       {"event_type": "summary_ready" | "summary_failed", ...}
+type Publisher struct {
+	opts      Options
+	prefix    string
+	discovery string
+	nodeID    string
+
+	dial  func(context.Context, Options) (*Client, error)
+	retry time.Duration
+
+	paused         bool
+	sessionID      string
+	sessionStarted string
+}
+
+// NewPublisher publishes under prefix; paused is the capture state at start.
+func NewPublisher(opts Options, prefix, discoveryPrefix string, paused bool) *Publisher {
+	prefix = strings.TrimSuffix(prefix, "/")
+	opts.Will = &Message{Topic: prefix + "/availability", Payload: []byte("offline"), Retain: true}
+	if opts.KeepAlive == 0 {
+		opts.KeepAlive = 30 * time.Second
+	}
+	return &Publisher{
+		opts:      opts,
+		prefix:    prefix,
+		discovery: strings.TrimSuffix(discoveryPrefix, "/"),
+		nodeID:    nodeID(opts.ClientID),
+		dial:      Dial,
+		retry:     30 * time.Second,
+		paused:    paused,
+	}
+}
+
+type hubEvent struct {
+	Type      string `json:"type"`
+	Timestamp string `json:"timestamp"`
+	Paused    bool   `json:"paused"`
+	SessionID string `json:"session_id"`
+	Summary   string `json:"summary"`
+	Status    string `json:"status"`
+	Preset    string `json:"summary_preset"`
+}
+
+// Run publishes events until ctx is cancelled or events closes, reconnecting
+// to the broker as needed. State is republished on every reconnect.
+func (p *Publisher) Run(ctx context.Context, events <-chan []byte) {
+	var client *Client
+	var lost <-chan struct{}
+	retry := time.NewTimer(0)
+	defer retry.Stop()
+	defer func() {
+		if client != nil {
+			_ = client.Publish(Message{Topic: p.prefix + "/availability", Payload: []byte("offline"), Retain: true})
+			_ = client.Close()
+		}
+	}()
+
+	disconnect := func(err error) {
+		slog.Warn("mqtt disconnected", "error", err)
+		if client != nil {
+			_ = client.Close()
+		}
+		client, lost = nil, nil
+		retry.Reset(p.retry)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-lost:
+			disconnect(client.Err())
+		case <-retry.C:
+			dialCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+			c, err := p.dial(dialCtx, p.opts)
+			cancel()
+			if err != nil {
+				slog.Warn("mqtt connect failed", "broker", p.opts.Broker, "error", err)
+				retry.Reset(p.retry)
+				continue
+			}
+			client, lost = c, c.Done()
+			if err := p.publishAll(client, p.announce()); err != nil {
+				disconnect(err)
+			}
+		case payload, ok := <-events:
+			if !ok {
+				return
+			}
+			msgs := p.apply(payload)
+			if client == nil || len(msgs) == 0 {
+				continue
+			}
+			if err := p.publishAll(client, msgs); err != nil {
+				disconnect(err)
+			}
+		}
+	}
+}
+
+func (p *Publisher) publishAll(c *Client, msgs []Message) error {
+	for _, m := range msgs {
+		if err := c.Publish(m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// apply folds an event into the tracked state and returns what to publish.
+func (p *Publisher) apply(payload []byte) []Message {
+	var ev hubEvent
+	if err := json.Unmarshal(payload, &ev); err != nil {
+		return nil
+	}
+
+	switch ev.Type {
+	case "status_changed":
+		p.paused = ev.Paused
+		return []Message{p.recordingState(), p.pausedState()}
+	case "session_started":
+		p.sessionID, p.sessionStarted = ev.SessionID, ev.Timestamp
+		return []Message{p.recordingState(), p.sessionState()}
+	case "session_ended":
+		p.sessionID, p.sessionStarted = "", ""
+		return []Message{p.recordingState(), p.sessionState()}
+	case "summary_ready":
+		eventType := ""
+		switch ev.Status {
+		case "completed":
+			eventType = "summary_ready"
+		case "failed":
+			eventType = "summary_failed"
+		default:
+			return nil
+		}
+		body, _ := json.Marshal(map[string]string{
+			"event_type": eventType,
+			"session_id": ev.SessionID,
+			"preset":     ev.Preset,
+			"summary":    ev.Summary,
+		})
+		return []Message{{Topic: p.prefix + "/summary", Payload: body}}
+	}
+	return nil
+}
+
+func onOff(v bool) []byte {
+	if v {
+		return []byte("ON")
+	}
+	return []byte("OFF")
+}
+
+func (p *Publisher) recordingState() Message {
+	return Message{Topic: p.prefix + "/recording", Payload: onOff(p.sessionID != "" && !p.paused), Retain: true}
+}
+
+func (p *Publisher) pausedState() Message {
+	return Message{Topic: p.prefix + "/paused", Payload: onOff(p.paused), Retain: true}
+}
+
+func (p *Publisher) sessionState() Message {
+	state := map[string]string{}
+	if p.sessionID != "" {
+		state["session_id"] = p.sessionID
+		state["started_at"] = p.sessionStarted
+	}
+	body, _ := json.Marshal(state)
+	return Message{Topic: p.prefix + "/session", Payload: body, Retain: true}
+}
+
+// announce returns the discovery configs followed by the current state.
+func (p *Publisher) announce() []Message {
+	device := map[string]any{
+		"identifiers":  []string{p.nodeID},
+		"name":         "Ghost Wispr",
+		"manufacturer": "Ghost Wispr",
+	}
+	entity := func(component, object string, cfg map[string]any) Message {
+		cfg["unique_id"] = p.nodeID + "_" + object
+		cfg["availability_topic"] = p.prefix + "/availability"
+		cfg["device"] = device
+		body, _ := json.Marshal(cfg)
+		return Message{Topic: p.discovery + "/" + component + "/" + p.nodeID + "/" + object + "/config", Payload: body, Retain: true}
+	}
+
+	return []Message{
+		entity("binary_sensor", "recording", map[string]any{
+			"name":        "Recording",
+			"state_topic": p.prefix + "/recording",
+			"icon":        "mdi:record-rec",
+		}),
+		entity("binary_sensor", "paused", map[string]any{
+			"name":        "Paused",
+			"state_topic": p.prefix + "/paused",
+			"icon":        "mdi:pause-circle",
+		}),
+		entity("sensor", "session", map[string]any{
+			"name":                  "Session",
+			"state_topic":           p.prefix + "/session",
+			"value_template":        "{{ value_json.session_id | default('idle') }}",
+			"json_attributes_topic": p.prefix + "/session",
+			"icon":                  "mdi:account-voice",
+		}),
+		entity("event", "summary", map[string]any{
+			"name":        "Summary",
+			"state_topic": p.prefix + "/summary",
+			"event_types": []string{"summary_ready", "summary_failed"},
+			"icon":        "mdi:text-box-check",
+		}),
+		{Topic: p.prefix + "/availability", Payload: []byte("online"), Retain: true},
+		p.recordingState(),
+		p.pausedState(),
+		p.sessionState(),
+	}
+}
+
+// nodeID turns a client ID into a Home Assistant object id.
+func nodeID(clientID string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(clientID) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '_':
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	if b.Len() == 0 {
+		return "ghost_wispr"
+	}
+	return b.String()
+}
@@ -0,0 +1,88 @@
+package parquet
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// Thrift compact protocol type ids, as used in field and list headers.
+const (
+	ctI32    = 5
+	ctI64    = 6
+	ctBinary = 8
+	ctList   = 9
+	ctStruct = 12
+)
+
+// compactWriter encodes the handful of Thrift compact-protocol constructs
+// Parquet metadata needs. Structs are written by calling field helpers in
+// increasing field-id order between beginStruct and endStruct.
+type compactWriter struct {
+	buf    []byte
+	fields []int16 // last field id written, per open struct
+}
+
+func (c *compactWriter) beginStruct() {
+	c.fields = append(c.fields, 0)
+}
+
+func (c *compactWriter) endStruct() {
+	c.buf = append(c.buf, 0)
+	c.fields = c.fields[:len(c.fields)-1]
+}
+
+func (c *compactWriter) fieldHeader(id int16, typ byte) {
+	last := &c.fields[len(c.fields)-1]
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		c.buf = append(c.buf, byte(delta)<<4|typ)
+	} else {
+		c.buf = append(c.buf, typ)
+		c.varint(int64(id))
+	}
+	*last = id
+}
+
+func (c *compactWriter) i32Field(id int16, v int32) {
+	c.fieldHeader(id, ctI32)
+	c.varint(int64(v))
+}
+
+func (c *compactWriter) i64Field(id int16, v int64) {
+	c.fieldHeader(id, ctI64)
+	c.varint(v)
+}
+
+func (c *compactWriter) stringField(id int16, v string) {
+	c.fieldHeader(id, ctBinary)
+	c.binary(v)
+}
+
+func (c *compactWriter) structField(id int16) {
+	c.fieldHeader(id, ctStruct)
+	c.beginStruct()
+}
+
+// listField writes a list header; the caller then writes n elements of elem.
+func (c *compactWriter) listField(id int16, elem byte, n int) {
+	c.fieldHeader(id, ctList)
+	if n < 15 {
+		c.buf = append(c.buf, byte(n)<<4|elem)
+		return
+	}
+	c.buf = append(c.buf, 0xF0|elem)
+	c.buf = binary.AppendUvarint(c.buf, uint64(n))
+}
+
+func (c *compactWriter) binary(v string) {
+	c.buf = binary.AppendUvarint(c.buf, uint64(len(v)))
+	c.buf = append(c.buf, v...)
+}
+
+// varint appends a zigzag-encoded varint.
+func (c *compactWriter) varint(v int64) {
+	c.buf = binary.AppendUvarint(c.buf, uint64(v<<1)^uint64(v>>63))
+}
+
+func appendDouble(b []byte, v float64) []byte {
+	return binary.LittleEndian.AppendUint64(b, math.Float64bits(v))
+}
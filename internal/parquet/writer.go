@@ -0,0 +1,345 @@
+// Package parquet writes flat, uncompressed Parquet files. It supports just
+// what analytical exports need: required or optional columns of strings,
+// integers, doubles and millisecond timestamps, PLAIN-encoded, one data page
+// per column per row group. Rows are buffered only until the row group
+// fills, so files of any size can be streamed with bounded memory.
+package parquet
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ColumnType is the logical type of a column.
+type ColumnType int
+
+const (
+	String ColumnType = iota
+	Int32
+	Int64
+	Double
+	TimestampMillis
+)
+
+// Column describes one column. Optional columns accept nil values.
+type Column struct {
+	Name     string
+	Type     ColumnType
+	Optional bool
+}
+
+// DefaultRowGroupSize is the number of rows buffered before a row group is
+// written out.
+const DefaultRowGroupSize = 8192
+
+const magic = "PAR1"
+
+// Parquet physical types, encodings and enums from parquet.thrift.
+const (
+	physInt32     = 1
+	physInt64     = 2
+	physDouble    = 5
+	physByteArray = 6
+
+	convertedUTF8            = 0
+	convertedTimestampMillis = 9
+
+	repetitionRequired = 0
+	repetitionOptional = 1
+
+	encodingPlain = 0
+	encodingRLE   = 3
+
+	codecUncompressed = 0
+	pageTypeData      = 0
+)
+
+type Writer struct {
+	w            io.Writer
+	offset       int64
+	columns      []Column
+	RowGroupSize int
+
+	values    [][]byte // PLAIN-encoded non-null values, per column
+	defLevels [][]bool // per optional column: true when the value is present
+	rows      int
+	total     int64
+	groups    []rowGroup
+	closed    bool
+}
+
+type rowGroup struct {
+	rows    int
+	size    int64
+	columns []columnChunk
+}
+
+type columnChunk struct {
+	offset int64
+	size   int64
+	values int
+}
+
+// NewWriter writes the file header to w and returns a Writer for columns.
+func NewWriter(w io.Writer, columns []Column) (*Writer, error) {
+	if len(columns) == 0 {
+		return nil, errors.New("parquet: at least one column is required")
+	}
+	pw := &Writer{
+		w:            w,
+		columns:      columns,
+		RowGroupSize: DefaultRowGroupSize,
+		values:       make([][]byte, len(columns)),
+		defLevels:    make([][]bool, len(columns)),
+	}
+	if err := pw.write([]byte(magic)); err != nil {
+		return nil, err
+	}
+	return pw, nil
+}
+
+// Write appends one row. Values must match the column types: string, int32,
+// int64, float64 and time.Time respectively, or nil for an optional column.
+func (pw *Writer) Write(row ...any) error {
+	if pw.closed {
+		return errors.New("parquet: write after close")
+	}
+	if len(row) != len(pw.columns) {
+		return fmt.Errorf("parquet: got %d values for %d columns", len(row), len(pw.columns))
+	}
+
+	encoded := make([][]byte, len(row))
+	for i, v := range row {
+		col := pw.columns[i]
+		if v == nil {
+			if !col.Optional {
+				return fmt.Errorf("parquet: column %s is required", col.Name)
+			}
+			continue
+		}
+		b, err := encodePlain(nil, col, v)
+		if err != nil {
+			return err
+		}
+		encoded[i] = b
+	}
+
+	for i, col := range pw.columns {
+		if col.Optional {
+			pw.defLevels[i] = append(pw.defLevels[i], row[i] != nil)
+		}
+		pw.values[i] = append(pw.values[i], encoded[i]...)
+	}
+	pw.rows++
+
+	if pw.rows >= pw.RowGroupSize {
+		return pw.Flush()
+	}
+	return nil
+}
+
+// Flush writes the buffered rows as a row group.
+func (pw *Writer) Flush() error {
+	if pw.rows == 0 {
+		return nil
+	}
+
+	group := rowGroup{rows: pw.rows, columns: make([]columnChunk, len(pw.columns))}
+	for i, col := range pw.columns {
+		var page []byte
+		if col.Optional {
+			levels := encodeDefinitionLevels(pw.defLevels[i])
+			page = binary.LittleEndian.AppendUint32(page, uint32(len(levels)))
+			page = append(page, levels...)
+		}
+		page = append(page, pw.values[i]...)
+
+		var header compactWriter
+		header.beginStruct()
+		header.i32Field(1, pageTypeData)
+		header.i32Field(2, int32(len(page)))
+		header.i32Field(3, int32(len(page)))
+		header.structField(5)
+		header.i32Field(1, int32(pw.rows))
+		header.i32Field(2, encodingPlain)
+		header.i32Field(3, encodingRLE)
+		header.i32Field(4, encodingRLE)
+		header.endStruct()
+		header.endStruct()
+
+		chunk := columnChunk{offset: pw.offset, size: int64(len(header.buf) + len(page)), values: pw.rows}
+		if err := pw.write(header.buf); err != nil {
+			return err
+		}
+		if err := pw.write(page); err != nil {
+			return err
+		}
+		group.columns[i] = chunk
+		group.size += chunk.size
+
+		pw.values[i] = pw.values[i][:0]
+		pw.defLevels[i] = pw.defLevels[i][:0]
+	}
+
+	pw.groups = append(pw.groups, group)
+	pw.total += int64(pw.rows)
+	pw.rows = 0
+	return nil
+}
+
+// Close flushes buffered rows and writes the footer. It does not close the
+// underlying writer.
+func (pw *Writer) Close() error {
+	if pw.closed {
+		return nil
+	}
+	if err := pw.Flush(); err != nil {
+		return err
+	}
+	pw.closed = true
+
+	footer := pw.fileMetadata()
+	if err := pw.write(footer); err != nil {
+		return err
+	}
+	trailer := binary.LittleEndian.AppendUint32(nil, uint32(len(footer)))
+	return pw.write(append(trailer, magic...))
+}
+
+func (pw *Writer) fileMetadata() []byte {
+	var c compactWriter
+	c.beginStruct()
+	c.i32Field(1, 1)
+
+	c.listField(2, ctStruct, len(pw.columns)+1)
+	c.beginStruct()
+	c.stringField(4, "schema")
+	c.i32Field(5, int32(len(pw.columns)))
+	c.endStruct()
+	for _, col := range pw.columns {
+		physical, converted := physicalType(col.Type)
+		repetition := int32(repetitionRequired)
+		if col.Optional {
+			repetition = repetitionOptional
+		}
+		c.beginStruct()
+		c.i32Field(1, physical)
+		c.i32Field(3, repetition)
+		c.stringField(4, col.Name)
+		if converted >= 0 {
+			c.i32Field(6, converted)
+		}
+		c.endStruct()
+	}
+
+	c.i64Field(3, pw.total)
+
+	c.listField(4, ctStruct, len(pw.groups))
+	for _, g := range pw.groups {
+		c.beginStruct()
+		c.listField(1, ctStruct, len(g.columns))
+		for i, chunk := range g.columns {
+			col := pw.columns[i]
+			physical, _ := physicalType(col.Type)
+
+			c.beginStruct()
+			c.i64Field(2, chunk.offset)
+			c.structField(3)
+			c.i32Field(1, physical)
+			c.listField(2, ctI32, 2)
+			c.varint(encodingPlain)
+			c.varint(encodingRLE)
+			c.listField(3, ctBinary, 1)
+			c.binary(col.Name)
+			c.i32Field(4, codecUncompressed)
+			c.i64Field(5, int64(chunk.values))
+			c.i64Field(6, chunk.size)
+			c.i64Field(7, chunk.size)
+			c.i64Field(9, chunk.offset)
+			c.endStruct()
+			c.endStruct()
+		}
+		c.i64Field(2, g.size)
+		c.i64Field(3, int64(g.rows))
+		c.endStruct()
+	}
+
+	c.stringField(6, "ghost-wispr")
+	c.endStruct()
+	return c.buf
+}
+
+func (pw *Writer) write(b []byte) error {
+	n, err := pw.w.Write(b)
+	pw.offset += int64(n)
+	if err != nil {
+		return fmt.Errorf("parquet: write: %w", err)
+	}
+	return nil
+}
+
+func physicalType(t ColumnType) (physical int32, converted int32) {
+	switch t {
+	case String:
+		return physByteArray, convertedUTF8
+	case Int32:
+		return physInt32, -1
+	case Int64:
+		return physInt64, -1
+	case Double:
+		return physDouble, -1
+	case TimestampMillis:
+		return physInt64, convertedTimestampMillis
+	}
+	return physByteArray, -1
+}
+
+func encodePlain(b []byte, col Column, v any) ([]byte, error) {
+	switch col.Type {
+	case String:
+		if s, ok := v.(string); ok {
+			b = binary.LittleEndian.AppendUint32(b, uint32(len(s)))
+			return append(b, s...), nil
+		}
+	case Int32:
+		if n, ok := v.(int32); ok {
+			return binary.LittleEndian.AppendUint32(b, uint32(n)), nil
+		}
+	case Int64:
+		if n, ok := v.(int64); ok {
+			return binary.LittleEndian.AppendUint64(b, uint64(n)), nil
+		}
+	case Double:
+		if f, ok := v.(float64); ok {
+			return appendDouble(b, f), nil
+		}
+	case TimestampMillis:
+		if t, ok := v.(time.Time); ok {
+			return binary.LittleEndian.AppendUint64(b, uint64(t.UnixMilli())), nil
+		}
+	}
+	return nil, fmt.Errorf("parquet: column %s: unexpected value of type %T", col.Name, v)
+}
+
+// encodeDefinitionLevels writes 1-bit definition levels in the RLE/bit-packed
+// hybrid encoding, using RLE runs only.
+func encodeDefinitionLevels(present []bool) []byte {
+	var out []byte
+	for i := 0; i < len(present); {
+		j := i
+		for j < len(present) && present[j] == present[i] {
+			j++
+		}
+		out = binary.AppendUvarint(out, uint64(j-i)<<1)
+		if present[i] {
+			out = append(out, 1)
+		} else {
+			out = append(out, 0)
+		}
+		i = j
+	}
+	return out
+}
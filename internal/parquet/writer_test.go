@@ -0,0 +1,147 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+)
+
+// readCompact decodes a Thrift compact struct generically, returning field
+// values keyed by id: int64, string, []any or map[int16]any.
+func readCompact(t *testing.T, b []byte) (map[int16]any, int) {
+	t.Helper()
+	fields := map[int16]any{}
+	pos := 0
+	var last int16
+	for {
+		header := b[pos]
+		pos++
+		if header == 0 {
+			return fields, pos
+		}
+		typ := header & 0x0F
+		if delta := header >> 4; delta != 0 {
+			last += int16(delta)
+		} else {
+			id, n := binary.Uvarint(b[pos:])
+			pos += n
+			last = int16(int64(id>>1) ^ -int64(id&1))
+		}
+		v, n := readCompactValue(t, b[pos:], typ)
+		pos += n
+		fields[last] = v
+	}
+}
+
+func readCompactValue(t *testing.T, b []byte, typ byte) (any, int) {
+	t.Helper()
+	switch typ {
+	case ctI32, ctI64:
+		u, n := binary.Uvarint(b)
+		return int64(u>>1) ^ -int64(u&1), n
+	case ctBinary:
+		l, n := binary.Uvarint(b)
+		return string(b[n : n+int(l)]), n + int(l)
+	case ctStruct:
+		return readCompact(t, b)
+	case ctList:
+		size, elem, pos := int(b[0]>>4), b[0]&0x0F, 1
+		if size == 15 {
+			s, n := binary.Uvarint(b[1:])
+			size, pos = int(s), 1+n
+		}
+		items := make([]any, 0, size)
+		for i := 0; i < size; i++ {
+			v, n := readCompactValue(t, b[pos:], elem)
+			pos += n
+			items = append(items, v)
+		}
+		return items, pos
+	}
+	t.Fatalf("unexpected compact type %d", typ)
+	return nil, 0
+}
+
+func TestWriterRoundTripsFooterAndPages(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, []Column{
+		{Name: "name", Type: String},
+		{Name: "n", Type: Int32},
+		{Name: "score", Type: Double, Optional: true},
+		{Name: "at", Type: TimestampMillis},
+	})
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+	w.RowGroupSize = 2
+
+	at := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	rows := [][]any{
+		{"a", int32(1), 0.5, at},
+		{"bb", int32(2), nil, at},
+		{"ccc", int32(3), 1.5, at},
+	}
+	for _, r := range rows {
+		if err := w.Write(r...); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if err := w.Write("x", int32(1), 0.1); err == nil {
+		t.Fatal("expected column count mismatch to fail")
+	}
+	if err := w.Write(nil, int32(1), 0.1, at); err == nil {
+		t.Fatal("expected nil in required column to fail")
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	data := buf.Bytes()
+	if string(data[:4]) != magic || string(data[len(data)-4:]) != magic {
+		t.Fatal("missing PAR1 magic")
+	}
+	footerLen := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	footer, n := readCompact(t, data[len(data)-8-footerLen:len(data)-8])
+	if n != footerLen {
+		t.Fatalf("footer decoded %d of %d bytes", n, footerLen)
+	}
+
+	if footer[3] != int64(3) {
+		t.Fatalf("expected 3 rows, got %v", footer[3])
+	}
+	schema := footer[2].([]any)
+	if len(schema) != 5 || schema[0].(map[int16]any)[5] != int64(4) {
+		t.Fatalf("unexpected schema: %v", schema)
+	}
+	score := schema[3].(map[int16]any)
+	if score[4] != "score" || score[1] != int64(physDouble) || score[3] != int64(repetitionOptional) {
+		t.Fatalf("unexpected score schema: %v", score)
+	}
+
+	groups := footer[4].([]any)
+	if len(groups) != 2 || groups[1].(map[int16]any)[3] != int64(1) {
+		t.Fatalf("expected row groups of 2 and 1 rows, got %v", groups)
+	}
+
+	// Read the optional score column of the first row group back.
+	chunk := groups[0].(map[int16]any)[1].([]any)[2].(map[int16]any)[3].(map[int16]any)
+	offset := int(chunk[9].(int64))
+	page, hn := readCompact(t, data[offset:])
+	if page[5].(map[int16]any)[1] != int64(2) {
+		t.Fatalf("expected 2 values in page header, got %v", page)
+	}
+	body := data[offset+hn : offset+hn+int(page[2].(int64))]
+	levelsLen := int(binary.LittleEndian.Uint32(body))
+	if !bytes.Equal(body[4:4+levelsLen], []byte{2, 1, 2, 0}) {
+		t.Fatalf("unexpected definition levels %v", body[4:4+levelsLen])
+	}
+	values := body[4+levelsLen:]
+	if len(values) != 8 || math.Float64frombits(binary.LittleEndian.Uint64(values)) != 0.5 {
+		t.Fatalf("unexpected score values %v", values)
+	}
+	if int64(offset+hn+len(body)) != chunk[9].(int64)+chunk[7].(int64) {
+		t.Fatalf("column chunk size does not cover header and page")
+	}
+}
@@ -0,0 +1,71 @@
+//go:build linux
+
+package power
+
+import (
+	"context"
+	"log/slog"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// watchLogind reports logind's sleep signals until ctx is cancelled, and
+// returns false if it cannot listen for them, so the clock is watched
+// instead.
+func (w *Watcher) watchLogind(ctx context.Context) bool {
+	bin, err := exec.LookPath("gdbus")
+	if err != nil {
+		return false
+	}
+	cmd := exec.CommandContext(ctx, bin, "monitor", "--system",
+		"--dest", "org.freedesktop.login1", "--object-path", "/org/freedesktop/login1")
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return false
+	}
+	if err := cmd.Start(); err != nil {
+		slog.Warn("listening for logind sleep signals failed", "error", err)
+		return false
+	}
+
+	release := inhibitSleep(ctx)
+	_ = readSleepSignals(out, func(sleeping bool) {
+		if sleeping {
+			slog.Info("system suspending")
+			w.OnSuspend(time.Now().UTC())
+			release()
+			return
+		}
+		slog.Info("system resumed from suspend")
+		w.OnResume()
+		release = inhibitSleep(ctx)
+	})
+	release()
+	err = cmd.Wait()
+	if ctx.Err() != nil {
+		return true
+	}
+	slog.Warn("logind sleep signals stopped, watching the clock instead", "error", err)
+	return false
+}
+
+// inhibitSleep takes a logind delay lock, which holds a suspend off until
+// it is released or logind's InhibitDelayMaxSec runs out, and returns the
+// func releasing it. Without systemd-inhibit, sleep is not held off.
+func inhibitSleep(ctx context.Context) (release func()) {
+	bin, err := exec.LookPath("systemd-inhibit")
+	if err != nil {
+		return func() {}
+	}
+	cmd := exec.CommandContext(ctx, bin, "--what=sleep", "--mode=delay",
+		"--who=ghost-wispr", "--why=Ending the recording session", "sleep", "infinity")
+	if err := cmd.Start(); err != nil {
+		slog.Warn("taking a sleep delay lock failed", "error", err)
+		return func() {}
+	}
+	return sync.OnceFunc(func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	})
+}
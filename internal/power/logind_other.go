@@ -0,0 +1,9 @@
+//go:build !linux
+
+package power
+
+import "context"
+
+// watchLogind always returns false here: without logind, suspends are
+// noticed from the clock.
+func (w *Watcher) watchLogind(context.Context) bool { return false }
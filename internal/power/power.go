@@ -0,0 +1,95 @@
+// Package power notices the machine suspending and resuming, so that a
+// laptop whose lid is closed ends its session cleanly instead of waking to
+// a dead microphone and a dropped transcription stream.
+package power
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+const (
+	// checkInterval is how often the clock is checked for a suspend where
+	// the system does not announce one.
+	checkInterval = 5 * time.Second
+	// minSleep is the smallest gap between the wall and monotonic clocks
+	// taken for a suspend rather than the wall clock being corrected.
+	minSleep = 30 * time.Second
+)
+
+// Watcher reports suspends and resumes. On Linux it listens for logind's
+// PrepareForSleep signal, holding a delay lock so that OnSuspend runs
+// before the machine sleeps. Elsewhere, or without gdbus, a suspend is
+// noticed on waking from the monotonic clock, which stops while the machine
+// sleeps, falling behind the wall clock; OnSuspend and OnResume then run
+// back to back.
+type Watcher struct {
+	// OnSuspend is called as the machine goes to sleep, with the time it
+	// did, and OnResume once it is awake again.
+	OnSuspend func(at time.Time)
+	OnResume  func()
+}
+
+func New(onSuspend func(at time.Time), onResume func()) *Watcher {
+	return &Watcher{OnSuspend: onSuspend, OnResume: onResume}
+}
+
+// Run watches for suspends until ctx is cancelled.
+func (w *Watcher) Run(ctx context.Context) {
+	if w.watchLogind(ctx) {
+		return
+	}
+	w.watchClock(ctx)
+}
+
+// watchClock checks the clocks every checkInterval for a suspend.
+func (w *Watcher) watchClock(ctx context.Context) {
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+	last := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		now := time.Now()
+		if slept := sleptBetween(last.Round(0), now.Round(0), now.Sub(last)); slept >= minSleep {
+			slog.Info("system resumed from suspend", "slept", slept.Round(time.Second))
+			w.OnSuspend(last.UTC())
+			w.OnResume()
+		}
+		last = now
+	}
+}
+
+// sleptBetween is how long the machine slept between two wall clock
+// readings, given the monotonic time that passed between them.
+func sleptBetween(from, to time.Time, elapsed time.Duration) time.Duration {
+	return to.Sub(from) - elapsed
+}
+
+// readSleepSignals calls fn for each PrepareForSleep signal in the output
+// of gdbus monitor, with true as the machine is about to sleep and false
+// once it has woken, until r ends.
+func readSleepSignals(r io.Reader, fn func(sleeping bool)) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		_, args, ok := strings.Cut(line, "org.freedesktop.login1.Manager.PrepareForSleep ")
+		if !ok {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(args, "(true"):
+			fn(true)
+		case strings.HasPrefix(args, "(false"):
+			fn(false)
+		}
+	}
+	return scanner.Err()
+}
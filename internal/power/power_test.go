@@ -0,0 +1,36 @@
+package power
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadSleepSignals(t *testing.T) {
+	out := strings.Join([]string{
+		"Monitoring signals on object /org/freedesktop/login1 owned by org.freedesktop.login1",
+		"The name org.freedesktop.login1 is owned by :1.4",
+		"/org/freedesktop/login1: org.freedesktop.login1.Manager.SessionNew ('3', objectpath '/org/freedesktop/login1/session/_33')",
+		"/org/freedesktop/login1: org.freedesktop.login1.Manager.PrepareForSleep (true,)",
+		"/org/freedesktop/login1: org.freedesktop.login1.Manager.PrepareForSleep (false,)",
+	}, "\n")
+	var got []bool
+	if err := readSleepSignals(strings.NewReader(out), func(sleeping bool) { got = append(got, sleeping) }); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || !got[0] || got[1] {
+		t.Fatalf("expected a suspend then a resume, got %v", got)
+	}
+}
+
+func TestSleptBetween(t *testing.T) {
+	from := time.Date(2026, 10, 16, 18, 0, 0, 0, time.UTC)
+	// Closed overnight: 14 hours passed on the wall clock, 5 seconds awake.
+	if got := sleptBetween(from, from.Add(14*time.Hour+5*time.Second), 5*time.Second); got != 14*time.Hour {
+		t.Fatalf("expected 14h asleep, got %v", got)
+	}
+	// An NTP correction moves the wall clock a little without a suspend.
+	if got := sleptBetween(from, from.Add(7*time.Second), 5*time.Second); got >= minSleep {
+		t.Fatalf("expected a small correction not taken for a suspend, got %v", got)
+	}
+}
@@ -0,0 +1,98 @@
+// Package redact masks emails, phone numbers and card numbers in transcript
+// text before it is stored or sent to a model.
+package redact
+
+import (
+	"regexp"
+	"strings"
+)
+
+// The kinds of detail a Redactor can mask.
+const (
+	Email      = "email"
+	Phone      = "phone"
+	CreditCard = "credit_card"
+)
+
+// Kinds lists every kind New accepts.
+var Kinds = []string{Email, Phone, CreditCard}
+
+type rule struct {
+	kind  string
+	re    *regexp.Regexp
+	label string
+	// valid, if set, rejects matches that only look like the detail.
+	valid func(match string) bool
+}
+
+// rules run in this order whatever order kinds are configured in: card
+// numbers first, so their digit groups aren't taken for phone numbers.
+var rules = []rule{
+	{kind: CreditCard, re: regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`), label: "[CREDIT_CARD]", valid: luhn},
+	{kind: Email, re: regexp.MustCompile(`(?i)\b[a-z0-9._%+-]+@[a-z0-9.-]+\.[a-z]{2,}\b`), label: "[EMAIL]"},
+	// International numbers need their + prefix; national ones the grouping
+	// smart formatting gives them, (555) 123-4567 or 555-123-4567, so that
+	// runs of spoken figures aren't masked.
+	{kind: Phone, re: regexp.MustCompile(`\+\d{1,3}(?:[ .-]?\d{2,4}){2,4}\b|(?:\(\d{3}\) ?|\b\d{3}[.-])\d{3}[.-]\d{4}\b`), label: "[PHONE]"},
+}
+
+// Redactor replaces each configured kind of detail with a label such as
+// [EMAIL]. The nil Redactor masks nothing.
+type Redactor struct {
+	rules []rule
+}
+
+// New masks the given kinds, ignoring any not in Kinds; config validation
+// reports those. It returns nil when there is nothing to mask.
+func New(kinds []string) *Redactor {
+	var r Redactor
+	for _, rule := range rules {
+		for _, kind := range kinds {
+			if strings.EqualFold(strings.TrimSpace(kind), rule.kind) {
+				r.rules = append(r.rules, rule)
+				break
+			}
+		}
+	}
+	if len(r.rules) == 0 {
+		return nil
+	}
+	return &r
+}
+
+// Redact returns text with the configured details masked.
+func (r *Redactor) Redact(text string) string {
+	if r == nil {
+		return text
+	}
+	for _, rule := range r.rules {
+		text = rule.re.ReplaceAllStringFunc(text, func(match string) string {
+			if rule.valid != nil && !rule.valid(match) {
+				return match
+			}
+			return rule.label
+		})
+	}
+	return text
+}
+
+// luhn reports whether the digits of number pass the Luhn checksum card
+// numbers carry.
+func luhn(number string) bool {
+	sum, n := 0, 0
+	for i := len(number) - 1; i >= 0; i-- {
+		c := number[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if n%2 == 1 {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n >= 13 && sum%10 == 0
+}
@@ -0,0 +1,33 @@
+package redact
+
+import "testing"
+
+func TestRedact(t *testing.T) {
+	r := New([]string{"phone", " Email ", CreditCard, "ssn"})
+	tests := []struct {
+		in, want string
+	}{
+		{"Mail jane.doe+ops@example.co.uk today.", "Mail [EMAIL] today."},
+		{"Call (555) 123-4567 or 555.123.4567.", "Call [PHONE] or [PHONE]."},
+		{"Our London line is +44 20 7946 0958.", "Our London line is [PHONE]."},
+		{"The card is 4111 1111 1111 1111, expiring soon.", "The card is [CREDIT_CARD], expiring soon."},
+		{"Order 4111 1111 1111 1112 failed.", "Order 4111 1111 1111 1112 failed."},
+		{"We hired 100 200 300 people between 2019 2020 2021.", "We hired 100 200 300 people between 2019 2020 2021."},
+	}
+	for _, tc := range tests {
+		if got := r.Redact(tc.in); got != tc.want {
+			t.Errorf("Redact(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestRedactOnlyConfiguredKinds(t *testing.T) {
+	r := New([]string{Email})
+	if got := r.Redact("a@b.io, 555-123-4567"); got != "[EMAIL], 555-123-4567" {
+		t.Fatalf("expected only the email masked, got %q", got)
+	}
+	none := New(nil)
+	if none != nil || none.Redact("a@b.io") != "a@b.io" {
+		t.Fatal("expected no kinds to mask nothing")
+	}
+}
@@ -0,0 +1,233 @@
+// Package report writes a weekly operational report, so that whoever runs
+// the appliance can see storage filling up, spend creeping and failures
+// piling up before they become a problem.
+package report
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/i18n"
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+)
+
+// Store is the storage a report is drawn from and kept in.
+type Store interface {
+	GetSessionsByDate(date string) ([]storage.Session, error)
+	GetSessionUsage(sessionID string) (storage.SessionUsage, error)
+	GetAudioFiles(sessionID string) ([]storage.AudioFile, error)
+	GetExports(sessionID string) ([]storage.Export, error)
+	DatabaseSize() (int64, error)
+	SaveReport(r storage.Report) error
+	GetReport(id string) (storage.Report, error)
+	ListReports() ([]storage.Report, error)
+}
+
+// Notifier announces a new report.
+type Notifier interface {
+	BroadcastReportReady(r storage.Report)
+}
+
+// Exporter delivers a new report wherever export rules send it.
+type Exporter interface {
+	ExportReport(r storage.Report)
+}
+
+// Reporter writes a report for each ISO week, Monday to Monday UTC, once
+// the week is over.
+type Reporter struct {
+	store    Store
+	notifier Notifier
+	// Exports, when set, delivers each report through the export rules
+	// firing on weekly_report.
+	Exports Exporter
+	// Localizer renders the report text; nil renders English.
+	Localizer *i18n.Localizer
+
+	// Swappable for tests.
+	now func() time.Time
+}
+
+func New(store Store, notifier Notifier) *Reporter {
+	return &Reporter{store: store, notifier: notifier, now: time.Now}
+}
+
+// Run writes the report for the last finished week if it is missing, then
+// each week's as it ends, until ctx is cancelled.
+func (r *Reporter) Run(ctx context.Context) {
+	for {
+		start := weekStart(r.now()).AddDate(0, 0, -7)
+		if _, err := r.store.GetReport(weekID(start)); errors.Is(err, sql.ErrNoRows) {
+			if _, err := r.Write(start); err != nil {
+				slog.Warn("writing weekly report failed", "week", weekID(start), "error", err)
+			}
+		} else if err != nil {
+			slog.Warn("checking weekly report failed", "week", weekID(start), "error", err)
+		}
+
+		next := weekStart(r.now()).AddDate(0, 0, 7)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(next.Sub(r.now())):
+		}
+	}
+}
+
+// Write reports on the week starting at start, stores the report,
+// announces it and exports it.
+func (r *Reporter) Write(start time.Time) (storage.Report, error) {
+	start = weekStart(start)
+	end := start.AddDate(0, 0, 7)
+	rep := storage.Report{ID: weekID(start), PeriodStart: start, PeriodEnd: end, CreatedAt: r.now().UTC()}
+
+	size, err := r.store.DatabaseSize()
+	if err != nil {
+		return storage.Report{}, err
+	}
+	rep.DatabaseBytes = size
+	if prev, err := r.previous(start); err != nil {
+		return storage.Report{}, err
+	} else if prev != nil {
+		rep.DatabaseGrowthBytes = size - prev.DatabaseBytes
+	}
+
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		sessions, err := r.store.GetSessionsByDate(day.Format("2006-01-02"))
+		if err != nil {
+			return storage.Report{}, err
+		}
+		for _, sess := range sessions {
+			if err := r.addSession(&rep, sess); err != nil {
+				return storage.Report{}, err
+			}
+		}
+	}
+	rep.Text = describe(rep, r.Localizer)
+
+	if err := r.store.SaveReport(rep); err != nil {
+		return storage.Report{}, err
+	}
+	slog.Info("weekly report written", "week", rep.ID, "sessions", rep.Sessions)
+	if r.notifier != nil {
+		r.notifier.BroadcastReportReady(rep)
+	}
+	if r.Exports != nil {
+		r.Exports.ExportReport(rep)
+	}
+	return rep, nil
+}
+
+// previous returns the latest report for a week before start, if any.
+func (r *Reporter) previous(start time.Time) (*storage.Report, error) {
+	reports, err := r.store.ListReports()
+	if err != nil {
+		return nil, err
+	}
+	for _, rep := range reports {
+		if rep.PeriodStart.Before(start) {
+			return &rep, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *Reporter) addSession(rep *storage.Report, sess storage.Session) error {
+	rep.Sessions++
+	usage, err := r.store.GetSessionUsage(sess.ID)
+	if err != nil {
+		return err
+	}
+	rep.TranscribedMinutes += usage.TranscriptionMinutes
+	rep.TranscriptionCost += usage.TranscriptionCost
+	rep.LLMInputTokens += usage.LLMInputTokens
+	rep.LLMOutputTokens += usage.LLMOutputTokens
+	rep.LLMCost += usage.LLMCost
+
+	if sess.SummaryStatus == storage.SummaryFailed {
+		rep.SummaryFailures++
+	}
+	if sess.AudioStatus == storage.AudioFailed {
+		rep.AudioFailures++
+	}
+	exports, err := r.store.GetExports(sess.ID)
+	if err != nil {
+		return err
+	}
+	for _, e := range exports {
+		if e.Status == storage.ExportFailed {
+			rep.ExportFailures++
+		}
+	}
+
+	files, err := r.store.GetAudioFiles(sess.ID)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if info, err := os.Stat(f.Path); err == nil {
+			rep.AudioBytesAdded += info.Size()
+		}
+	}
+	return nil
+}
+
+// describe writes a report out as a few lines of plain text, the first its
+// title.
+func describe(rep storage.Report, l *i18n.Localizer) string {
+	lines := []string{
+		l.T(i18n.ReportTitle, rep.ID, rep.PeriodStart.Format("2006-01-02"), rep.PeriodEnd.AddDate(0, 0, -1).Format("2006-01-02")),
+		l.T(i18n.ReportDatabase, formatBytes(rep.DatabaseBytes), signedBytes(rep.DatabaseGrowthBytes)),
+		l.T(i18n.ReportAudio, formatBytes(rep.AudioBytesAdded)),
+		l.T(i18n.ReportSessions, rep.Sessions, rep.TranscribedMinutes, rep.TranscriptionCost),
+		l.T(i18n.ReportLLM, rep.LLMInputTokens, rep.LLMOutputTokens, rep.LLMCost),
+	}
+	if failures := rep.SummaryFailures + rep.AudioFailures + rep.ExportFailures; failures > 0 {
+		lines = append(lines, l.T(i18n.ReportFailures, rep.SummaryFailures, rep.AudioFailures, rep.ExportFailures))
+	} else {
+		lines = append(lines, l.T(i18n.ReportNoFailures))
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit && n > -unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	value, suffix := float64(n), ""
+	for _, s := range []string{"KB", "MB", "GB", "TB"} {
+		value /= unit
+		suffix = s
+		if value < unit && value > -unit {
+			break
+		}
+	}
+	return fmt.Sprintf("%.1f %s", value, suffix)
+}
+
+func signedBytes(n int64) string {
+	if n >= 0 {
+		return "+" + formatBytes(n)
+	}
+	return "-" + formatBytes(-n)
+}
+
+// weekStart is the Monday, 00:00 UTC, starting t's ISO week.
+func weekStart(t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+}
+
+// weekID names the ISO week containing t, such as "2026-W42".
+func weekID(t time.Time) string {
+	year, week := t.UTC().ISOWeek()
+	return fmt.Sprintf("%d-W%02d", year, week)
+}
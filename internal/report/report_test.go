@@ -0,0 +1,170 @@
+package report
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/i18n"
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+)
+
+type notifierMock struct {
+	delivered []storage.Report
+	exported  []storage.Report
+}
+
+func (n *notifierMock) BroadcastReportReady(r storage.Report) {
+	n.delivered = append(n.delivered, r)
+}
+
+func (n *notifierMock) ExportReport(r storage.Report) {
+	n.exported = append(n.exported, r)
+}
+
+func newTestReporter(store Store, notifier Notifier, now time.Time) *Reporter {
+	r := New(store, notifier)
+	r.now = func() time.Time { return now }
+	return r
+}
+
+func addSession(t *testing.T, store *storage.MemoryStore, id string, start time.Time) {
+	t.Helper()
+	if err := store.CreateSession(id, start); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.EndSession(id, start.Add(30*time.Minute), ""); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWriteSumsTheWeek(t *testing.T) {
+	store := storage.NewMemoryStore()
+	monday := time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)
+
+	addSession(t, store, "tuesday", monday.AddDate(0, 0, 1).Add(9*time.Hour))
+	if err := store.RecordTranscriptionUsage("tuesday", 30*time.Minute, 0.13); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.AddLLMUsage("tuesday", 1000, 200, 0.02, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	audio := filepath.Join(t.TempDir(), "tuesday.mp3")
+	if err := os.WriteFile(audio, make([]byte, 2048), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetAudioStatus("tuesday", storage.AudioReady, audio); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetExport(storage.Export{SessionID: "tuesday", Rule: "slack", Status: storage.ExportFailed}); err != nil {
+		t.Fatal(err)
+	}
+
+	addSession(t, store, "sunday", monday.AddDate(0, 0, 6).Add(23*time.Hour))
+	if err := store.UpdateSummary("sunday", "", storage.SummaryFailed, "default"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.AddLLMUsage("sunday", 500, 0, 0.01, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	addSession(t, store, "next-monday", monday.AddDate(0, 0, 7))
+
+	if err := store.SaveReport(storage.Report{ID: "2026-W41", PeriodStart: monday.AddDate(0, 0, -7), DatabaseBytes: 4096}); err != nil {
+		t.Fatal(err)
+	}
+
+	notifier := &notifierMock{}
+	r := newTestReporter(store, notifier, monday.AddDate(0, 0, 7).Add(time.Minute))
+	r.Exports = notifier
+	rep, err := r.Write(monday.AddDate(0, 0, 3))
+	if err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	if rep.ID != "2026-W42" || !rep.PeriodStart.Equal(monday) || !rep.PeriodEnd.Equal(monday.AddDate(0, 0, 7)) {
+		t.Fatalf("expected week 2026-W42 from %s, got %s from %s to %s", monday, rep.ID, rep.PeriodStart, rep.PeriodEnd)
+	}
+	if rep.Sessions != 2 || rep.TranscribedMinutes != 30 || rep.TranscriptionCost != 0.13 {
+		t.Fatalf("expected two sessions with 30 transcribed minutes, got %+v", rep)
+	}
+	if rep.LLMInputTokens != 1500 || rep.LLMOutputTokens != 200 || rep.LLMCost < 0.0299 || rep.LLMCost > 0.0301 {
+		t.Fatalf("expected LLM usage summed, got %+v", rep)
+	}
+	if rep.AudioBytesAdded != 2048 {
+		t.Fatalf("expected 2048 audio bytes added, got %d", rep.AudioBytesAdded)
+	}
+	if rep.SummaryFailures != 1 || rep.AudioFailures != 0 || rep.ExportFailures != 1 {
+		t.Fatalf("expected one summary and one export failure, got %+v", rep)
+	}
+	if rep.DatabaseGrowthBytes != rep.DatabaseBytes-4096 {
+		t.Fatalf("expected growth against the W41 report, got %d", rep.DatabaseGrowthBytes)
+	}
+	for _, want := range []string{"2026-W42 (2026-10-12 to 2026-10-18)", "Audio added: 2.0 KB", "Sessions: 2, 30 minutes", "1 summaries, 0 audio encodes, 1 exports"} {
+		if !strings.Contains(rep.Text, want) {
+			t.Fatalf("expected report text to contain %q, got:\n%s", want, rep.Text)
+		}
+	}
+
+	if stored, err := store.GetReport("2026-W42"); err != nil || stored.Text != rep.Text {
+		t.Fatalf("expected the report stored, got %+v, %v", stored, err)
+	}
+	if len(notifier.delivered) != 1 || notifier.delivered[0].ID != "2026-W42" {
+		t.Fatalf("expected the report announced, got %+v", notifier.delivered)
+	}
+	if len(notifier.exported) != 1 || notifier.exported[0].Text != rep.Text {
+		t.Fatalf("expected the report exported, got %+v", notifier.exported)
+	}
+}
+
+func TestWriteInLocale(t *testing.T) {
+	store := storage.NewMemoryStore()
+	r := newTestReporter(store, nil, time.Date(2026, 10, 21, 8, 0, 0, 0, time.UTC))
+	r.Localizer, _ = i18n.New("de")
+	rep, err := r.Write(time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if !strings.HasPrefix(rep.Text, "Ghost-Wispr-Wochenbericht, 2026-W42") || !strings.Contains(rep.Text, "Fehler: keine") {
+		t.Fatalf("expected the report in German, got:\n%s", rep.Text)
+	}
+}
+
+func TestRunWritesTheLastWeekOnce(t *testing.T) {
+	store := storage.NewMemoryStore()
+	addSession(t, store, "s1", time.Date(2026, 10, 14, 10, 0, 0, 0, time.UTC))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	notifier := &notifierMock{}
+	r := newTestReporter(store, notifier, time.Date(2026, 10, 21, 8, 0, 0, 0, time.UTC))
+	r.Run(ctx)
+	r.Run(ctx)
+
+	if len(notifier.delivered) != 1 {
+		t.Fatalf("expected one report across restarts, got %d", len(notifier.delivered))
+	}
+	if rep := notifier.delivered[0]; rep.ID != "2026-W42" || rep.Sessions != 1 {
+		t.Fatalf("expected last week's report, got %+v", rep)
+	}
+}
+
+func TestWeekBoundaries(t *testing.T) {
+	tests := []struct {
+		at    time.Time
+		start string
+		id    string
+	}{
+		{time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC), "2026-10-12", "2026-W42"},
+		{time.Date(2026, 10, 18, 23, 59, 0, 0, time.UTC), "2026-10-12", "2026-W42"},
+		{time.Date(2027, 1, 2, 12, 0, 0, 0, time.UTC), "2026-12-28", "2026-W53"},
+	}
+	for _, tt := range tests {
+		start := weekStart(tt.at)
+		if start.Format("2006-01-02") != tt.start || weekID(start) != tt.id {
+			t.Fatalf("week of %s: expected %s starting %s, got %s starting %s", tt.at, tt.id, tt.start, weekID(start), start.Format("2006-01-02"))
+		}
+	}
+}
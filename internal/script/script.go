@@ -0,0 +1,212 @@
+// Package script lets user programs make preset routing and segment
+// post-processing decisions that static config can't express.
+//
+// A script is a WebAssembly module run in an embedded runtime. It is given
+// no imports at all, so it can't reach the filesystem, network, clock or
+// environment: it sees only what it is handed and can only answer. Each call
+// runs in a fresh instance, killed at its timeout, whose memory can't grow
+// past the configured limit.
+//
+// The module exports its memory, alloc(size i32) i32, returning where the
+// host may write size bytes, and either or both hooks, each taking a JSON
+// request at (ptr, len) and returning its JSON response packed as
+// ptr<<32 | len:
+//
+//	route_preset    {"transcript":"...","presets":{"name":"description"}}
+//	                → {"preset":"name"}
+//
+//	process_segment {"segment":{"speaker":0,"text":"...","start_time":1.2,"end_time":3.4}}
+//	                → {"segment":{...}} to replace it, {"drop":true} to discard it, or {} to keep it
+//
+// Any language compiling to wasm32 without WASI works, e.g. Rust, Zig,
+// AssemblyScript or TinyGo with -target=wasm-unknown.
+package script
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+
+	"github.com/sjawhar/ghost-wispr/internal/transcribe"
+)
+
+// Hook names, exported by the module.
+const (
+	HookRoutePreset    = "route_preset"
+	HookProcessSegment = "process_segment"
+)
+
+// pageSize is the size of a WebAssembly memory page.
+const pageSize = 64 << 10
+
+// Script is a compiled module speaking the protocol above.
+type Script struct {
+	name     string
+	runtime  wazero.Runtime
+	compiled wazero.CompiledModule
+	timeout  time.Duration
+}
+
+// Load compiles the module at path. Calls are cut off after timeout, and
+// the module's memory is capped at memoryLimit bytes, rounded down to whole
+// pages.
+func Load(ctx context.Context, path string, timeout time.Duration, memoryLimit int) (*Script, error) {
+	wasm, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read script: %w", err)
+	}
+	return New(ctx, path, wasm, timeout, memoryLimit)
+}
+
+// New compiles wasm, naming it name in errors.
+func New(ctx context.Context, name string, wasm []byte, timeout time.Duration, memoryLimit int) (*Script, error) {
+	pages := uint32(max(memoryLimit/pageSize, 1))
+	runtime := wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfig().
+		WithMemoryLimitPages(pages).
+		WithCloseOnContextDone(true))
+	compiled, err := runtime.CompileModule(ctx, wasm)
+	if err != nil {
+		_ = runtime.Close(ctx)
+		return nil, fmt.Errorf("compile script %s: %w", name, err)
+	}
+	if imports := compiled.ImportedFunctions(); len(imports) > 0 {
+		_ = runtime.Close(ctx)
+		module, fn, _ := imports[0].Import()
+		return nil, fmt.Errorf("script %s imports %s.%s: scripts are given no imports", name, module, fn)
+	}
+	if _, ok := compiled.ExportedFunctions()["alloc"]; !ok {
+		_ = runtime.Close(ctx)
+		return nil, fmt.Errorf("script %s does not export alloc", name)
+	}
+	return &Script{name: name, runtime: runtime, compiled: compiled, timeout: timeout}, nil
+}
+
+// Close releases the compiled module.
+func (s *Script) Close(ctx context.Context) error {
+	return s.runtime.Close(ctx)
+}
+
+// Exports reports whether the module implements hook.
+func (s *Script) Exports(hook string) bool {
+	_, ok := s.compiled.ExportedFunctions()[hook]
+	return ok
+}
+
+type routeRequest struct {
+	Transcript string            `json:"transcript"`
+	Presets    map[string]string `json:"presets"`
+}
+
+type routeResponse struct {
+	Preset string `json:"preset"`
+}
+
+type segmentRequest struct {
+	Segment transcribe.Segment `json:"segment"`
+}
+
+type segmentResponse struct {
+	Segment *transcribe.Segment `json:"segment"`
+	Drop    bool                `json:"drop"`
+}
+
+// SelectPreset asks the script which preset fits transcript. presets maps
+// preset names to their descriptions.
+func (s *Script) SelectPreset(ctx context.Context, transcript string, presets map[string]string) (string, error) {
+	var resp routeResponse
+	if err := s.call(ctx, HookRoutePreset, routeRequest{Transcript: transcript, Presets: presets}, &resp); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(resp.Preset), nil
+}
+
+// ProcessSegment lets the script rewrite or drop a final segment before it is
+// stored. It returns the segment to keep and whether to keep it at all.
+func (s *Script) ProcessSegment(ctx context.Context, seg transcribe.Segment) (transcribe.Segment, bool, error) {
+	var resp segmentResponse
+	if err := s.call(ctx, HookProcessSegment, segmentRequest{Segment: seg}, &resp); err != nil {
+		return seg, true, err
+	}
+	if resp.Drop {
+		return seg, false, nil
+	}
+	if resp.Segment != nil {
+		// The script may not move the segment in time.
+		resp.Segment.Timestamp = seg.Timestamp
+		return *resp.Segment, true, nil
+	}
+	return seg, true, nil
+}
+
+func (s *Script) call(ctx context.Context, hook string, request, response any) error {
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("encode script request: %w", err)
+	}
+
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+
+	// A fresh, anonymous instance per call: nothing carries over between
+	// calls, and a trapped or killed instance takes no state with it.
+	mod, err := s.runtime.InstantiateModule(ctx, s.compiled, wazero.NewModuleConfig().WithName(""))
+	if err != nil {
+		return fmt.Errorf("start script %s: %w", s.name, err)
+	}
+	defer mod.Close(context.Background())
+
+	out, err := s.invoke(ctx, mod, hook, payload)
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("script %s timed out after %s", s.name, s.timeout)
+		}
+		return fmt.Errorf("script %s: %w", s.name, err)
+	}
+	if err := json.Unmarshal(out, response); err != nil {
+		return fmt.Errorf("decode script %s response: %w", s.name, err)
+	}
+	return nil
+}
+
+// invoke writes payload into mod's memory, calls hook on it and returns a
+// copy of the response.
+func (s *Script) invoke(ctx context.Context, mod api.Module, hook string, payload []byte) ([]byte, error) {
+	fn := mod.ExportedFunction(hook)
+	if fn == nil {
+		return nil, fmt.Errorf("%s is not exported", hook)
+	}
+	mem := mod.Memory()
+	if mem == nil {
+		return nil, errors.New("no memory exported")
+	}
+
+	res, err := mod.ExportedFunction("alloc").Call(ctx, uint64(len(payload)))
+	if err != nil {
+		return nil, fmt.Errorf("alloc: %w", err)
+	}
+	ptr := uint32(res[0])
+	if !mem.Write(ptr, payload) {
+		return nil, fmt.Errorf("alloc returned %d, outside memory", ptr)
+	}
+
+	res, err = fn.Call(ctx, uint64(ptr), uint64(len(payload)))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", hook, err)
+	}
+	outPtr, outLen := uint32(res[0]>>32), uint32(res[0])
+	out, ok := mem.Read(outPtr, outLen)
+	if !ok {
+		return nil, fmt.Errorf("%s returned %d bytes at %d, outside memory", hook, outLen, outPtr)
+	}
+	return append([]byte(nil), out...), nil
+}
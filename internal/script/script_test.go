@@ -0,0 +1,214 @@
+package script
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/transcribe"
+)
+
+// The modules below are assembled by hand, so the tests need no toolchain
+// targeting wasm.
+
+const (
+	i32 = 0x7f
+	i64 = 0x7e
+)
+
+// leb appends v as signed LEB128, which also encodes the small unsigned
+// sizes and indices used here.
+func leb(b []byte, v int64) []byte {
+	for {
+		c := byte(v & 0x7f)
+		v >>= 7
+		if (v == 0 && c&0x40 == 0) || (v == -1 && c&0x40 != 0) {
+			return append(b, c)
+		}
+		b = append(b, c|0x80)
+	}
+}
+
+func vec(items ...[]byte) []byte {
+	b := leb(nil, int64(len(items)))
+	for _, item := range items {
+		b = append(b, item...)
+	}
+	return b
+}
+
+func name(s string) []byte { return append(leb(nil, int64(len(s))), s...) }
+
+func section(id byte, body []byte) []byte {
+	return append(leb([]byte{id}, int64(len(body))), body...)
+}
+
+func i64Const(v int64) []byte { return leb([]byte{0x42}, v) }
+
+type module struct {
+	imports []byte
+	pages   int64
+	data    map[int64]string
+	// hook is the body shared by both hooks, leaving an i64 on the stack.
+	hook []byte
+}
+
+// assemble builds a module exporting memory, alloc, which always returns
+// 1024, and both hooks.
+func (m module) assemble() []byte {
+	allocType := []byte{0x60, 1, i32, 1, i32}
+	hookType := []byte{0x60, 2, i32, i32, 1, i64}
+	types := [][]byte{allocType, hookType}
+	var imports [][]byte
+	if m.imports != nil {
+		types = append(types, []byte{0x60, 0, 0})
+		imports = append(imports, m.imports)
+	}
+	alloc := append(leb([]byte{0x00, 0x41}, 1024), 0x0b)
+	hook := append(append([]byte{0x00}, m.hook...), 0x0b)
+	n := int64(len(imports))
+
+	out := []byte{0x00, 'a', 's', 'm', 1, 0, 0, 0}
+	out = append(out, section(1, vec(types...))...)
+	if len(imports) > 0 {
+		out = append(out, section(2, vec(imports...))...)
+	}
+	out = append(out, section(3, vec([]byte{0}, []byte{1}, []byte{1}))...)
+	out = append(out, section(5, vec(leb([]byte{0x00}, m.pages)))...)
+	out = append(out, section(7, vec(
+		append(name("memory"), 0x02, 0),
+		leb(append(name("alloc"), 0x00), n),
+		leb(append(name(HookRoutePreset), 0x00), n+1),
+		leb(append(name(HookProcessSegment), 0x00), n+2),
+	))...)
+	out = append(out, section(10, vec(
+		append(leb(nil, int64(len(alloc))), alloc...),
+		append(leb(nil, int64(len(hook))), hook...),
+		append(leb(nil, int64(len(hook))), hook...),
+	))...)
+	var segments [][]byte
+	for offset, s := range m.data {
+		seg := append(leb([]byte{0x00, 0x41}, offset), 0x0b)
+		segments = append(segments, append(seg, name(s)...))
+	}
+	if len(segments) > 0 {
+		out = append(out, section(11, vec(segments...))...)
+	}
+	return out
+}
+
+// answering returns a module whose hooks answer response.
+func answering(response string) module {
+	return module{pages: 1, data: map[int64]string{0: response}, hook: i64Const(int64(len(response)))}
+}
+
+func load(t *testing.T, m module, memoryLimit int) *Script {
+	t.Helper()
+	s, err := New(context.Background(), "test.wasm", m.assemble(), time.Second, memoryLimit)
+	if err != nil {
+		t.Fatalf("load script: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close(context.Background()) })
+	return s
+}
+
+func TestSelectPreset(t *testing.T) {
+	s := load(t, answering(`{"preset":" standup "}`), 1<<20)
+
+	got, err := s.SelectPreset(context.Background(), "yesterday I worked on", map[string]string{"standup": "daily"})
+	if err != nil {
+		t.Fatalf("select preset: %v", err)
+	}
+	if got != "standup" {
+		t.Fatalf("expected standup, got %q", got)
+	}
+	if !s.Exports(HookRoutePreset) || s.Exports("other") {
+		t.Fatal("expected exported hooks reported")
+	}
+}
+
+func TestProcessSegment(t *testing.T) {
+	ts := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	seg := transcribe.Segment{Speaker: 1, Text: "um hello", StartTime: 1, EndTime: 2, Timestamp: ts}
+
+	rewrite := load(t, answering(`{"segment":{"speaker":2,"text":"hello","start_time":1,"end_time":2}}`), 1<<20)
+	got, keep, err := rewrite.ProcessSegment(context.Background(), seg)
+	if err != nil || !keep {
+		t.Fatalf("expected rewritten segment, got keep=%v err=%v", keep, err)
+	}
+	if got.Text != "hello" || got.Speaker != 2 || !got.Timestamp.Equal(ts) {
+		t.Fatalf("unexpected rewritten segment %+v", got)
+	}
+
+	drop := load(t, answering(`{"drop":true}`), 1<<20)
+	if _, keep, err := drop.ProcessSegment(context.Background(), seg); err != nil || keep {
+		t.Fatalf("expected segment to be dropped, got keep=%v err=%v", keep, err)
+	}
+
+	// Echoing the request back answers with the segment it was given.
+	echo := load(t, module{pages: 1, hook: []byte{
+		0x20, 0, 0xad, // local.get 0, i64.extend_i32_u
+		0x42, 32, 0x86, // i64.const 32, i64.shl
+		0x20, 1, 0xad, // local.get 1, i64.extend_i32_u
+		0x84, // i64.or
+	}}, 1<<20)
+	got, keep, err = echo.ProcessSegment(context.Background(), seg)
+	if err != nil || !keep || got.Text != seg.Text || got.Speaker != seg.Speaker || !got.Timestamp.Equal(ts) {
+		t.Fatalf("expected the segment back, got %+v keep=%v err=%v", got, keep, err)
+	}
+
+	passthrough := load(t, answering(`{}`), 1<<20)
+	if got, keep, err := passthrough.ProcessSegment(context.Background(), seg); err != nil || !keep || !reflect.DeepEqual(got, seg) {
+		t.Fatalf("expected unchanged segment, got %+v keep=%v err=%v", got, keep, err)
+	}
+}
+
+func TestScriptLimits(t *testing.T) {
+	seg := transcribe.Segment{Text: "hello"}
+
+	trap := load(t, module{pages: 1, hook: []byte{0x00}}, 1<<20) // unreachable
+	got, keep, err := trap.ProcessSegment(context.Background(), seg)
+	if err == nil || !keep || !reflect.DeepEqual(got, seg) {
+		t.Fatalf("expected a trap to keep the original segment with an error, got keep=%v err=%v", keep, err)
+	}
+
+	spin := load(t, module{pages: 1, hook: []byte{0x03, 0x40, 0x0c, 0, 0x0b, 0x00}}, 1<<20) // loop br 0 end unreachable
+	spin.timeout = 50 * time.Millisecond
+	if _, err := spin.SelectPreset(context.Background(), "", nil); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("expected timeout, got %v", err)
+	}
+
+	// Growing memory past the limit fails inside the script, which drops the
+	// segment when it does: memory.grow 1000; i32.const -1; i32.eq; if (result i64) ...
+	grow := module{
+		pages: 1,
+		data:  map[int64]string{0: `{"drop":true}`, 16: `{}`},
+		hook: append(append(append([]byte{0x41}, leb(nil, 1000)...), 0x40, 0, 0x41, 0x7f, 0x46, 0x04, i64),
+			append(append(i64Const(13), 0x05), append(i64Const(16<<32|2), 0x0b)...)...),
+	}
+	if _, keep, err := load(t, grow, 1<<20).ProcessSegment(context.Background(), seg); err != nil || keep {
+		t.Fatalf("expected memory growth past 1MiB refused, got keep=%v err=%v", keep, err)
+	}
+	if _, keep, err := load(t, grow, 128<<20).ProcessSegment(context.Background(), seg); err != nil || !keep {
+		t.Fatalf("expected memory growth within 128MiB allowed, got keep=%v err=%v", keep, err)
+	}
+
+	big := answering(`{}`)
+	big.pages = 64
+	if _, err := New(context.Background(), "big.wasm", big.assemble(), time.Second, 1<<20); err == nil {
+		t.Fatal("expected a module needing more memory than the limit refused")
+	}
+
+	importing := answering(`{}`)
+	importing.imports = append(append(name("env"), name("open")...), 0x00, 2)
+	if _, err := New(context.Background(), "importing.wasm", importing.assemble(), time.Second, 1<<20); err == nil || !strings.Contains(err.Error(), "env.open") {
+		t.Fatalf("expected imports refused, got %v", err)
+	}
+
+	garbage := answering(`not-json`)
+	if _, err := load(t, garbage, 1<<20).SelectPreset(context.Background(), "", nil); err == nil {
+		t.Fatal("expected decode error")
+	}
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+)
+
+// KeyHealth is the outcome of checking one provider's API key after
+// POST /api/admin/keys.
+type KeyHealth struct {
+	Provider string `json:"provider"`
+	Healthy  bool   `json:"healthy"`
+	Error    string `json:"error,omitempty"`
+}
+
+// ErrUnknownProvider is returned by ControlHooks.RotateKeys for a provider
+// whose key can't be changed at runtime.
+var ErrUnknownProvider = errors.New("unknown provider")
+
+// keyCheckTimeout bounds the provider health checks run after a rotation.
+const keyCheckTimeout = 20 * time.Second
+
+// registerAdminRoutes serves key rotation only behind admin tokens: an open
+// API would let anyone who can reach it swap in their own keys.
+func registerAdminRoutes(mux *http.ServeMux, controls ControlHooks) {
+	if controls.Auth == nil {
+		return
+	}
+	mux.HandleFunc("POST /api/admin/keys", func(w http.ResponseWriter, r *http.Request) {
+		if controls.RotateKeys == nil {
+			writeJSONError(w, http.StatusServiceUnavailable, "key rotation not available")
+			return
+		}
+		// A form on another site can post text/plain with the token cookie
+		// attached, but not JSON.
+		if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+			writeJSONError(w, http.StatusUnsupportedMediaType, "request body must be application/json")
+			return
+		}
+		var body struct {
+			Keys map[string]string `json:"keys"`
+		}
+		if err := json.NewDecoder(io.LimitReader(r.Body, 16<<10)).Decode(&body); err != nil || len(body.Keys) == 0 {
+			writeJSONError(w, http.StatusBadRequest, "request body must be {\"keys\": {\"<provider>\": \"...\"}}")
+			return
+		}
+		for provider, key := range body.Keys {
+			if key == "" {
+				writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("empty key for %s", provider))
+				return
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), keyCheckTimeout)
+		defer cancel()
+		health, err := controls.RotateKeys(ctx, body.Keys)
+		if err != nil {
+			if errors.Is(err, ErrUnknownProvider) {
+				writeJSONError(w, http.StatusBadRequest, err.Error())
+			} else {
+				writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("rotate keys: %v", err))
+			}
+			return
+		}
+		if health == nil {
+			health = []KeyHealth{}
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"providers": health})
+	})
+}
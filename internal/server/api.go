@@ -11,12 +11,20 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/sjawhar/ghost-wispr/internal/audio"
+	"github.com/sjawhar/ghost-wispr/internal/config"
+	"github.com/sjawhar/ghost-wispr/internal/i18n"
+	"github.com/sjawhar/ghost-wispr/internal/jobs"
 	"github.com/sjawhar/ghost-wispr/internal/session"
 	"github.com/sjawhar/ghost-wispr/internal/storage"
+	"github.com/sjawhar/ghost-wispr/internal/summary"
 	"github.com/sjawhar/ghost-wispr/internal/transcribe"
+	"github.com/sjawhar/ghost-wispr/internal/update"
 )
 
 var sessionIDPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
@@ -26,11 +34,41 @@ type SessionStore interface {
 	GetSession(id string) (storage.Session, error)
 	GetSegments(sessionID string) ([]transcribe.Segment, error)
 	GetDates() ([]string, error)
+	GetDecisions(filter storage.DecisionFilter) ([]storage.Decision, error)
+	GetSessionUsage(sessionID string) (storage.SessionUsage, error)
+	GetBookmarks(sessionID string) ([]storage.Bookmark, error)
+	GetNotes(sessionID string) ([]storage.Note, error)
+	GetAnnotations(sessionID string) ([]storage.Annotation, error)
+	ListSeries() ([]storage.Series, error)
+	GetSeriesSessions(seriesID string) ([]storage.Session, error)
+	GetActionItems(sessionID string) ([]storage.ActionItem, error)
+	GetCitations(sessionID string) ([]storage.Citation, error)
+	GetSessionsByKind(kind string) ([]storage.Session, error)
+	GetAudioFiles(sessionID string) ([]storage.AudioFile, error)
 }
 
-func registerAPIRoutes(mux *http.ServeMux, store SessionStore, controls ControlHooks) {
+func registerAPIRoutes(mux *http.ServeMux, store SessionStore, hub *Hub, controls ControlHooks) {
 	mux.HandleFunc("GET /api/sessions", func(w http.ResponseWriter, r *http.Request) {
 		date := r.URL.Query().Get("date")
+		if v := r.URL.Query().Get("unreviewed"); v != "" {
+			unreviewed, err := strconv.ParseBool(v)
+			if err != nil {
+				writeJSONError(w, http.StatusBadRequest, "unreviewed must be true or false")
+				return
+			}
+			if unreviewed {
+				sessions, err := unreviewedSessions(store, date)
+				switch {
+				case errors.Is(err, errors.ErrUnsupported):
+					writeJSONError(w, http.StatusNotImplemented, "view tracking is not supported by this store")
+				case err != nil:
+					writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("list unreviewed sessions: %v", err))
+				default:
+					writeJSON(w, http.StatusOK, sessions)
+				}
+				return
+			}
+		}
 		if date == "" {
 			date = time.Now().UTC().Format("2006-01-02")
 		}
@@ -67,9 +105,59 @@ func registerAPIRoutes(mux *http.ServeMux, store SessionStore, controls ControlH
 			return
 		}
 
+		usage, err := store.GetSessionUsage(sessionID)
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("get session usage: %v", err))
+			return
+		}
+
+		bookmarks, err := store.GetBookmarks(sessionID)
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("get session bookmarks: %v", err))
+			return
+		}
+
+		notes, err := store.GetNotes(sessionID)
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("get session notes: %v", err))
+			return
+		}
+
+		annotations, err := store.GetAnnotations(sessionID)
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("get session annotations: %v", err))
+			return
+		}
+
+		actionItems, err := store.GetActionItems(sessionID)
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("get session action items: %v", err))
+			return
+		}
+
+		citations, err := store.GetCitations(sessionID)
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("get session citations: %v", err))
+			return
+		}
+
+		audioFiles, err := store.GetAudioFiles(sessionID)
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("get session audio files: %v", err))
+			return
+		}
+
 		writeJSON(w, http.StatusOK, map[string]any{
-			"session":  sessionData,
-			"segments": segments,
+			"session":       sessionData,
+			"segments":      segments,
+			"usage":         usage,
+			"bookmarks":     bookmarks,
+			"notes":         notes,
+			"annotations":   annotations,
+			"action_items":  actionItems,
+			"citations":     citations,
+			"audio_files":   audioFiles,
+			"speaker_names": storedSpeakerNames(store, sessionID),
 		})
 	})
 
@@ -91,35 +179,113 @@ func registerAPIRoutes(mux *http.ServeMux, store SessionStore, controls ControlH
 			return
 		}
 
-		cleanPath := filepath.Clean(sessionData.AudioPath)
-		if cleanPath == "" || cleanPath == "." || cleanPath == ".." || strings.Contains(cleanPath, "..") {
-			writeJSONError(w, http.StatusForbidden, "invalid audio path")
+		// ?format=wav picks the lossless copy kept beside a compressed
+		// recording; naming the recording's own format changes nothing.
+		q := r.URL.Query()
+		audioPath := sessionData.AudioPath
+		if format := q.Get("format"); format != "" && !strings.EqualFold("."+format, filepath.Ext(audioPath)) {
+			if !strings.EqualFold(format, "wav") || sessionData.WAVPath == "" {
+				writeJSONError(w, http.StatusNotFound, fmt.Sprintf("audio not available as %s", format))
+				return
+			}
+			audioPath = sessionData.WAVPath
+		}
+
+		if q.Has("start") || q.Has("end") {
+			start, end, err := parseClipWindow(r)
+			if err != nil {
+				writeJSONError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			serveAudioClip(w, r, audioPath, start, end)
 			return
 		}
-		if filepath.IsAbs(cleanPath) {
-			writeJSONError(w, http.StatusForbidden, "invalid audio path")
+
+		serveAudioFile(w, r, audioPath, "public, max-age=31536000, immutable")
+	})
+
+	mux.HandleFunc("GET /api/sessions/{id}/audio/{index}", func(w http.ResponseWriter, r *http.Request) {
+		sessionID := r.PathValue("id")
+		if !validSessionID(sessionID) {
+			writeJSONError(w, http.StatusForbidden, "invalid session id")
+			return
+		}
+		index, err := strconv.Atoi(r.PathValue("index"))
+		if err != nil || index < 0 {
+			writeJSONError(w, http.StatusBadRequest, "index must be a non-negative integer")
 			return
 		}
 
+		files, err := store.GetAudioFiles(sessionID)
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("get session audio files: %v", err))
+			return
+		}
+		i := slices.IndexFunc(files, func(f storage.AudioFile) bool { return f.Index == index })
+		if i < 0 {
+			writeJSONError(w, http.StatusNotFound, "audio not available")
+			return
+		}
+
+		serveAudioFile(w, r, files[i].Path, "public, max-age=31536000, immutable")
+	})
+
+	mux.HandleFunc("GET /api/sessions/{id}/waveform", func(w http.ResponseWriter, r *http.Request) {
+		sessionID := r.PathValue("id")
+		if !validSessionID(sessionID) {
+			writeJSONError(w, http.StatusForbidden, "invalid session id")
+			return
+		}
+
+		sessionData, err := store.GetSession(sessionID)
+		if err != nil {
+			writeJSONError(w, http.StatusNotFound, "session not found")
+			return
+		}
+		cleanPath, ok := cleanAudioPath(audio.WaveformPath(sessionData.AudioPath))
+		if sessionData.AudioPath == "" || !ok {
+			writeJSONError(w, http.StatusNotFound, "waveform not available")
+			return
+		}
 		f, err := os.Open(cleanPath)
 		if err != nil {
-			writeJSONError(w, http.StatusNotFound, "audio file not found")
+			writeJSONError(w, http.StatusNotFound, "waveform not available")
 			return
 		}
 		defer func() { _ = f.Close() }()
-
 		info, err := f.Stat()
 		if err != nil {
-			writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("stat audio: %v", err))
+			writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("stat waveform: %v", err))
 			return
 		}
 
-		w.Header().Set("Accept-Ranges", "bytes")
 		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
-		w.Header().Set("Content-Type", contentTypeForAudio(cleanPath))
+		w.Header().Set("Content-Type", "application/json")
 		http.ServeContent(w, r, filepath.Base(cleanPath), info.ModTime(), f)
 	})
 
+	mux.HandleFunc("GET /api/sessions/{id}/summary/audio", func(w http.ResponseWriter, r *http.Request) {
+		sessionID := r.PathValue("id")
+		if !validSessionID(sessionID) {
+			writeJSONError(w, http.StatusForbidden, "invalid session id")
+			return
+		}
+
+		sessionData, err := store.GetSession(sessionID)
+		if err != nil {
+			writeJSONError(w, http.StatusNotFound, "session not found")
+			return
+		}
+
+		if sessionData.SummaryAudioPath == "" {
+			writeJSONError(w, http.StatusNotFound, "summary audio not available")
+			return
+		}
+
+		// Re-summarizing overwrites the file in place, so it must be revalidated.
+		serveAudioFile(w, r, sessionData.SummaryAudioPath, "no-cache")
+	})
+
 	mux.HandleFunc("GET /api/dates", func(w http.ResponseWriter, r *http.Request) {
 		dates, err := store.GetDates()
 		if err != nil {
@@ -132,23 +298,39 @@ func registerAPIRoutes(mux *http.ServeMux, store SessionStore, controls ControlH
 		writeJSON(w, http.StatusOK, dates)
 	})
 
-	mux.HandleFunc("POST /api/pause", func(w http.ResponseWriter, r *http.Request) {
-		if controls.Pause != nil {
-			controls.Pause()
+	mux.HandleFunc("GET /api/decisions", func(w http.ResponseWriter, r *http.Request) {
+		decisions, err := store.GetDecisions(decisionFilterFromQuery(r))
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("get decisions: %v", err))
+			return
 		}
-		if controls.OnStatusChanged != nil {
-			controls.OnStatusChanged(true)
+		writeJSON(w, http.StatusOK, decisions)
+	})
+
+	mux.HandleFunc("GET /api/decisions/export", func(w http.ResponseWriter, r *http.Request) {
+		release, err := controls.Exports.Acquire(r.Context())
+		if err != nil {
+			return
 		}
+		defer release()
+
+		decisions, err := store.GetDecisions(decisionFilterFromQuery(r))
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("get decisions: %v", err))
+			return
+		}
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="decisions.md"`)
+		_, _ = io.WriteString(w, formatDecisionLog(decisions, controls.Localizer))
+	})
+
+	mux.HandleFunc("POST /api/pause", func(w http.ResponseWriter, r *http.Request) {
+		controls.setPaused(true)
 		w.WriteHeader(http.StatusNoContent)
 	})
 
 	mux.HandleFunc("POST /api/resume", func(w http.ResponseWriter, r *http.Request) {
-		if controls.Resume != nil {
-			controls.Resume()
-		}
-		if controls.OnStatusChanged != nil {
-			controls.OnStatusChanged(false)
-		}
+		controls.setPaused(false)
 		w.WriteHeader(http.StatusNoContent)
 	})
 
@@ -170,6 +352,39 @@ func registerAPIRoutes(mux *http.ServeMux, store SessionStore, controls ControlH
 		w.WriteHeader(http.StatusNoContent)
 	})
 
+	mux.HandleFunc("POST /api/session/current/notes", func(w http.ResponseWriter, r *http.Request) {
+		if controls.AddNote == nil {
+			writeJSONError(w, http.StatusServiceUnavailable, "notes not available")
+			return
+		}
+		var body struct {
+			Text string     `json:"text"`
+			At   *time.Time `json:"at"`
+		}
+		if err := json.NewDecoder(io.LimitReader(r.Body, 16<<10)).Decode(&body); err != nil {
+			writeJSONError(w, http.StatusBadRequest, "request body must be {\"text\": \"...\"}")
+			return
+		}
+		at := time.Now().UTC()
+		if body.At != nil {
+			at = *body.At
+		}
+		note, err := controls.AddNote(at, body.Text)
+		switch {
+		case errors.Is(err, session.ErrEmptyNote):
+			writeJSONError(w, http.StatusBadRequest, "note text is required")
+			return
+		case errors.Is(err, session.ErrNoActiveSession):
+			writeJSONError(w, http.StatusConflict, "no active session")
+			return
+		case err != nil:
+			writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("add note: %v", err))
+			return
+		}
+		hub.BroadcastNoteAdded(note)
+		writeJSON(w, http.StatusCreated, note)
+	})
+
 	mux.HandleFunc("GET /api/status", func(w http.ResponseWriter, r *http.Request) {
 		paused := false
 		if controls.IsPaused != nil {
@@ -182,7 +397,30 @@ func registerAPIRoutes(mux *http.ServeMux, store SessionStore, controls ControlH
 		if warnings == nil {
 			warnings = []string{}
 		}
-		writeJSON(w, http.StatusOK, map[string]any{"paused": paused, "warnings": warnings})
+		var available *update.Release
+		if controls.Update != nil {
+			available = controls.Update()
+		}
+		queues := []jobs.Stats{}
+		if controls.Jobs != nil {
+			queues = controls.Jobs()
+		}
+		status := map[string]any{"paused": paused, "warnings": warnings, "update": available, "jobs": queues, "viewers": len(hub.Viewers())}
+		if controls.Resources != nil {
+			status["resources"] = controls.Resources()
+		}
+		if controls.MicHealth != nil {
+			status["mic"] = controls.MicHealth()
+		}
+		if controls.BrowserMicStatus != nil {
+			status["browser_mic"] = controls.BrowserMicStatus()
+		}
+		if controls.TranscriptionStatus != nil {
+			if ts := controls.TranscriptionStatus(); ts.State != "" {
+				status["transcription"] = ts
+			}
+		}
+		writeJSON(w, http.StatusOK, status)
 	})
 
 	mux.HandleFunc("GET /api/presets", func(w http.ResponseWriter, r *http.Request) {
@@ -198,6 +436,12 @@ func registerAPIRoutes(mux *http.ServeMux, store SessionStore, controls ControlH
 		writeJSON(w, http.StatusOK, result)
 	})
 
+	// The schema describes the options and their defaults, not the running
+	// config, so it holds nothing secret.
+	mux.HandleFunc("GET /api/config/schema", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, config.Schema())
+	})
+
 	mux.HandleFunc("POST /api/sessions/{id}/resummarize", func(w http.ResponseWriter, r *http.Request) {
 		sessionID := r.PathValue("id")
 		if !validSessionID(sessionID) {
@@ -206,7 +450,8 @@ func registerAPIRoutes(mux *http.ServeMux, store SessionStore, controls ControlH
 		}
 
 		var body struct {
-			Preset string `json:"preset"`
+			Preset       string `json:"preset"`
+			ReadingLevel string `json:"reading_level"`
 		}
 		if r.Body != nil {
 			if err := json.NewDecoder(r.Body).Decode(&body); err != nil && err != io.EOF {
@@ -214,24 +459,127 @@ func registerAPIRoutes(mux *http.ServeMux, store SessionStore, controls ControlH
 				return
 			}
 		}
+		if !summary.ValidReadingLevel(body.ReadingLevel) {
+			writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("reading_level must be one of %s", strings.Join(config.ReadingLevels, ", ")))
+			return
+		}
 
 		if controls.Resummarize == nil {
 			writeJSONError(w, http.StatusServiceUnavailable, "summarization not configured")
 			return
 		}
+		if sess, err := store.GetSession(sessionID); err == nil && sess.Locked {
+			writeJSONError(w, http.StatusConflict, "session is locked")
+			return
+		}
 
 		go func() {
-			_ = controls.Resummarize(context.Background(), sessionID, body.Preset)
+			_ = controls.Resummarize(summary.WithReadingLevel(context.Background(), body.ReadingLevel), sessionID, body.Preset)
 		}()
 
 		w.WriteHeader(http.StatusAccepted)
 	})
+
+	mux.HandleFunc("POST /api/sessions/{id}/keep-wav", func(w http.ResponseWriter, r *http.Request) {
+		sessionID := r.PathValue("id")
+		if !validSessionID(sessionID) {
+			writeJSONError(w, http.StatusForbidden, "invalid session id")
+			return
+		}
+		if controls.KeepWAV == nil {
+			writeJSONError(w, http.StatusServiceUnavailable, "lossless audio not available")
+			return
+		}
+
+		body := struct {
+			Keep bool `json:"keep"`
+		}{Keep: true}
+		if err := json.NewDecoder(io.LimitReader(r.Body, 4<<10)).Decode(&body); err != nil && err != io.EOF {
+			writeJSONError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		if err := controls.KeepWAV(sessionID, body.Keep); err != nil {
+			if errors.Is(err, session.ErrSessionNotRecording) {
+				writeJSONError(w, http.StatusConflict, "session is not recording")
+			} else {
+				writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("keep lossless audio: %v", err))
+			}
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func decisionFilterFromQuery(r *http.Request) storage.DecisionFilter {
+	q := r.URL.Query()
+	return storage.DecisionFilter{From: q.Get("from"), To: q.Get("to"), Query: q.Get("q")}
+}
+
+// formatDecisionLog renders decisions as a markdown log grouped by date.
+func formatDecisionLog(decisions []storage.Decision, l *i18n.Localizer) string {
+	var b strings.Builder
+	b.WriteString("# " + l.T(i18n.DocDecisionLog) + "\n")
+	currentDate := ""
+	for _, d := range decisions {
+		date := d.Timestamp.UTC().Format("2006-01-02")
+		if date != currentDate {
+			currentDate = date
+			fmt.Fprintf(&b, "\n## %s\n\n", date)
+		}
+		fmt.Fprintf(&b, "- %s", d.Text)
+		if len(d.Participants) > 0 {
+			fmt.Fprintf(&b, " (%s)", strings.Join(d.Participants, ", "))
+		}
+		fmt.Fprintf(&b, " — %s\n", l.T(i18n.DocDecisionSession, d.SessionID))
+	}
+	return b.String()
 }
 
 func validSessionID(id string) bool {
 	return sessionIDPattern.MatchString(id)
 }
 
+// serveAudioFile streams a relative audio path with range support, refusing
+// absolute paths and parent-directory escapes.
+func serveAudioFile(w http.ResponseWriter, r *http.Request, path, cacheControl string) {
+	cleanPath, ok := cleanAudioPath(path)
+	if !ok {
+		writeJSONError(w, http.StatusForbidden, "invalid audio path")
+		return
+	}
+
+	f, err := os.Open(cleanPath)
+	if err != nil {
+		writeJSONError(w, http.StatusNotFound, "audio file not found")
+		return
+	}
+	defer func() { _ = f.Close() }()
+
+	info, err := f.Stat()
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("stat audio: %v", err))
+		return
+	}
+
+	w.Header().Set("Accept-Ranges", "bytes")
+	w.Header().Set("Cache-Control", cacheControl)
+	w.Header().Set("Content-Type", contentTypeForAudio(cleanPath))
+	http.ServeContent(w, r, filepath.Base(cleanPath), info.ModTime(), f)
+}
+
+// cleanAudioPath cleans a stored audio path, rejecting absolute paths and
+// parent-directory escapes.
+func cleanAudioPath(path string) (string, bool) {
+	cleanPath := filepath.Clean(path)
+	if cleanPath == "" || cleanPath == "." || cleanPath == ".." || strings.Contains(cleanPath, "..") {
+		return "", false
+	}
+	if filepath.IsAbs(cleanPath) {
+		return "", false
+	}
+	return cleanPath, true
+}
+
 func contentTypeForAudio(path string) string {
 	ext := filepath.Ext(path)
 	switch ext {
@@ -239,6 +587,16 @@ func contentTypeForAudio(path string) string {
 		return "audio/mpeg"
 	case ".wav":
 		return "audio/wav"
+	case ".flac":
+		return "audio/flac"
+	case ".m4a":
+		return "audio/mp4"
+	case ".aac":
+		return "audio/aac"
+	case ".ogg", ".opus":
+		return "audio/ogg"
+	case ".webm":
+		return "audio/webm"
 	default:
 		return "application/octet-stream"
 	}
@@ -1,23 +1,40 @@
 package server
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"database/sql"
+	"encoding/binary"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"io/fs"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/sjawhar/ghost-wispr/internal/audio"
 	"github.com/sjawhar/ghost-wispr/internal/config"
+	"github.com/sjawhar/ghost-wispr/internal/dictation"
+	"github.com/sjawhar/ghost-wispr/internal/export"
+	"github.com/sjawhar/ghost-wispr/internal/i18n"
+	"github.com/sjawhar/ghost-wispr/internal/jobs"
 	"github.com/sjawhar/ghost-wispr/internal/session"
 	"github.com/sjawhar/ghost-wispr/internal/storage"
+	"github.com/sjawhar/ghost-wispr/internal/summary"
 	"github.com/sjawhar/ghost-wispr/internal/transcribe"
+	"github.com/sjawhar/ghost-wispr/internal/update"
+	"github.com/sjawhar/ghost-wispr/internal/voiceprint"
+	"github.com/sjawhar/ghost-wispr/internal/watchdog"
 )
 
 type apiStoreStub struct {
@@ -25,6 +42,15 @@ type apiStoreStub struct {
 	sessions       map[string]storage.Session
 	segments       map[string][]transcribe.Segment
 	dates          []string
+	decisions      []storage.Decision
+	usage          map[string]storage.SessionUsage
+	bookmarks      map[string][]storage.Bookmark
+	notes          map[string][]storage.Note
+	annotations    map[string][]storage.Annotation
+	actionItems    map[string][]storage.ActionItem
+	citations      map[string][]storage.Citation
+	audioFiles     map[string][]storage.AudioFile
+	viewed         map[string]time.Time
 }
 
 func (s apiStoreStub) GetSessionsByDate(date string) ([]storage.Session, error) {
@@ -46,6 +72,95 @@ func (s apiStoreStub) GetDates() ([]string, error) {
 	return s.dates, nil
 }
 
+func (s apiStoreStub) GetDecisions(filter storage.DecisionFilter) ([]storage.Decision, error) {
+	var result []storage.Decision
+	for _, d := range s.decisions {
+		if filter.Query == "" || strings.Contains(strings.ToLower(d.Text), strings.ToLower(filter.Query)) {
+			result = append(result, d)
+		}
+	}
+	return result, nil
+}
+
+func (s apiStoreStub) GetBookmarks(sessionID string) ([]storage.Bookmark, error) {
+	return s.bookmarks[sessionID], nil
+}
+
+func (s apiStoreStub) GetNotes(sessionID string) ([]storage.Note, error) {
+	return s.notes[sessionID], nil
+}
+
+func (s apiStoreStub) GetAnnotations(sessionID string) ([]storage.Annotation, error) {
+	return s.annotations[sessionID], nil
+}
+
+func (s apiStoreStub) GetSessionUsage(sessionID string) (storage.SessionUsage, error) {
+	return s.usage[sessionID], nil
+}
+
+func (s apiStoreStub) ListSeries() ([]storage.Series, error) {
+	return []storage.Series{}, nil
+}
+
+func (s apiStoreStub) GetSeriesSessions(seriesID string) ([]storage.Session, error) {
+	var sessions []storage.Session
+	for _, sess := range s.sessions {
+		if sess.SeriesID == seriesID {
+			sessions = append(sessions, sess)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].StartedAt.Before(sessions[j].StartedAt) })
+	return sessions, nil
+}
+
+func (s apiStoreStub) GetActionItems(sessionID string) ([]storage.ActionItem, error) {
+	return s.actionItems[sessionID], nil
+}
+
+func (s apiStoreStub) GetCitations(sessionID string) ([]storage.Citation, error) {
+	return s.citations[sessionID], nil
+}
+
+func (s apiStoreStub) AddAudioFile(sessionID, kind, path string) (storage.AudioFile, error) {
+	file := storage.AudioFile{Index: len(s.audioFiles[sessionID]), Kind: kind, Path: path}
+	s.audioFiles[sessionID] = append(s.audioFiles[sessionID], file)
+	return file, nil
+}
+
+func (s apiStoreStub) GetAudioFiles(sessionID string) ([]storage.AudioFile, error) {
+	return s.audioFiles[sessionID], nil
+}
+
+func (s apiStoreStub) MarkSessionViewed(sessionID string, at time.Time) error {
+	if _, ok := s.sessions[sessionID]; !ok {
+		return os.ErrNotExist
+	}
+	s.viewed[sessionID] = at
+	return nil
+}
+
+func (s apiStoreStub) GetUnreviewedSessions() ([]storage.Session, error) {
+	sessions := []storage.Session{}
+	for _, sess := range s.sessions {
+		if _, ok := s.viewed[sess.ID]; !ok && sess.SummaryStatus == storage.SummaryCompleted {
+			sessions = append(sessions, sess)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].StartedAt.After(sessions[j].StartedAt) })
+	return sessions, nil
+}
+
+func (s apiStoreStub) GetSessionsByKind(kind string) ([]storage.Session, error) {
+	var sessions []storage.Session
+	for _, sess := range s.sessions {
+		if sess.Kind == kind {
+			sessions = append(sessions, sess)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].StartedAt.After(sessions[j].StartedAt) })
+	return sessions, nil
+}
+
 func testStaticFS(t *testing.T) fs.FS {
 	t.Helper()
 	dir := t.TempDir()
@@ -98,6 +213,12 @@ func TestAPISessionDetail(t *testing.T) {
 			"s1": {{Speaker: 0, Text: "line", StartTime: 0, EndTime: 1, Timestamp: started}},
 		},
 		dates: []string{"2026-02-26"},
+		usage: map[string]storage.SessionUsage{
+			"s1": {TranscriptionMinutes: 2, TranscriptionCost: 0.0116, TotalCost: 0.0116},
+		},
+		citations: map[string][]storage.Citation{
+			"s1": {{Bullet: 0, Line: 0, Text: "hello", FirstSegmentID: 1, LastSegmentID: 1, StartTime: 0, EndTime: 1, Score: 1}},
+		},
 	}
 
 	h, err := Handler(testStaticFS(t), NewHub(), store, ControlHooks{})
@@ -115,6 +236,254 @@ func TestAPISessionDetail(t *testing.T) {
 	if !strings.Contains(rr.Body.String(), "segments") {
 		t.Fatalf("expected detail response to contain segments, got %s", rr.Body.String())
 	}
+	if !strings.Contains(rr.Body.String(), `"total_cost_usd":0.0116`) {
+		t.Fatalf("expected detail response to contain usage, got %s", rr.Body.String())
+	}
+	if !strings.Contains(rr.Body.String(), `"citations":[{"bullet":0,"line":0,"text":"hello","first_segment_id":1`) {
+		t.Fatalf("expected detail response to contain citations, got %s", rr.Body.String())
+	}
+}
+
+func TestAPIAudioClip(t *testing.T) {
+	root := t.TempDir()
+	oldWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd failed: %v", err)
+	}
+	if err := os.Chdir(root); err != nil {
+		t.Fatalf("chdir failed: %v", err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(oldWd) })
+
+	// Ten seconds of 16 kHz mono WAV, each second's samples set to its
+	// number.
+	var wav bytes.Buffer
+	wav.WriteString("RIFF")
+	_ = binary.Write(&wav, binary.LittleEndian, uint32(36+320000))
+	wav.WriteString("WAVEfmt ")
+	for _, v := range []any{uint32(16), uint16(1), uint16(1), uint32(16000), uint32(32000), uint16(2), uint16(16)} {
+		_ = binary.Write(&wav, binary.LittleEndian, v)
+	}
+	wav.WriteString("data")
+	_ = binary.Write(&wav, binary.LittleEndian, uint32(320000))
+	for sec := range 10 {
+		for range 16000 {
+			_ = binary.Write(&wav, binary.LittleEndian, int16(sec))
+		}
+	}
+	if err := os.WriteFile("s1.wav", wav.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Ten seconds of 128 kbps CBR MP3: silent 417-byte frames behind an
+	// ID3 tag.
+	mp3 := []byte("ID3\x04\x00\x00\x00\x00\x00\x05tag..")
+	for len(mp3) < 10*16000 {
+		frame := make([]byte, 417)
+		copy(frame, []byte{0xFF, 0xFB, 0x90, 0xC4})
+		mp3 = append(mp3, frame...)
+	}
+	if err := os.WriteFile("s2.mp3", mp3, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	store := apiStoreStub{
+		sessions: map[string]storage.Session{
+			"s1": {ID: "s1", AudioPath: "s1.wav"},
+			"s2": {ID: "s2", AudioPath: "s2.mp3"},
+		},
+		audioFiles: map[string][]storage.AudioFile{"s1": {{Kind: storage.AudioRecording, Path: "s1.wav"}}},
+	}
+	h, err := Handler(testStaticFS(t), NewHub(), store, ControlHooks{})
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/sessions/s1/audio?start=2&end=3.5", nil))
+	if rr.Code != http.StatusOK || rr.Header().Get("Content-Type") != "audio/wav" {
+		t.Fatalf("expected a WAV clip, got %d %q", rr.Code, rr.Header().Get("Content-Type"))
+	}
+	body := rr.Body.Bytes()
+	if len(body) != 44+48000 || binary.LittleEndian.Uint32(body[40:]) != 48000 {
+		t.Fatalf("expected a second and a half of audio behind a fresh header, got %d bytes", len(body))
+	}
+	if first, last := int16(binary.LittleEndian.Uint16(body[44:])), int16(binary.LittleEndian.Uint16(body[len(body)-2:])); first != 2 || last != 3 {
+		t.Fatalf("expected samples from 2s to 3.5s, got %d..%d", first, last)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/sessions/s1/audio?start=2&end=3.5", nil)
+	req.Header.Set("Range", "bytes=44-")
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	if rr.Code != http.StatusPartialContent || rr.Body.Len() != 48000 {
+		t.Fatalf("expected a ranged read of the clip, got %d with %d bytes", rr.Code, rr.Body.Len())
+	}
+
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/sessions/s2/audio?start=2&end=4", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected an MP3 clip, got %d: %s", rr.Code, rr.Body.String())
+	}
+	body = rr.Body.Bytes()
+	if !bytes.HasPrefix(body, []byte{0xFF, 0xFB}) || len(body) < 31000 || len(body) > 32000 {
+		t.Fatalf("expected two seconds starting on a frame, got %d bytes starting %x", len(body), body[:min(len(body), 4)])
+	}
+
+	// A saved clip is kept next to the recording as another audio file.
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/sessions/s1/clips?start=2&end=3.5", nil))
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected the clip saved, got %d: %s", rr.Code, rr.Body.String())
+	}
+	files := store.audioFiles["s1"]
+	if len(files) != 2 || files[1].Kind != storage.AudioClip || files[1].Path != "s1.clip-2.000-3.500.wav" {
+		t.Fatalf("expected the clip added as audio file 1, got %+v", files)
+	}
+	if saved, err := os.ReadFile(files[1].Path); err != nil || len(saved) != 44+48000 {
+		t.Fatalf("expected a second and a half saved, got %d bytes (%v)", len(saved), err)
+	}
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/sessions/s1/clips?start=2&end=3.5", nil))
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected the same clip refused twice, got %d", rr.Code)
+	}
+
+	for target, want := range map[string]int{
+		"/api/sessions/s1/audio?start=-1":        http.StatusBadRequest,
+		"/api/sessions/s1/audio?start=5&end=4":   http.StatusBadRequest,
+		"/api/sessions/s1/audio?start=soon":      http.StatusBadRequest,
+		"/api/sessions/s1/audio?start=60":        http.StatusRequestedRangeNotSatisfiable,
+		"/api/sessions/s2/audio?start=60&end=61": http.StatusRequestedRangeNotSatisfiable,
+	} {
+		rr = httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, target, nil))
+		if rr.Code != want {
+			t.Fatalf("%s: expected %d, got %d", target, want, rr.Code)
+		}
+	}
+}
+
+func TestAPIWaveform(t *testing.T) {
+	root := t.TempDir()
+	oldWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd failed: %v", err)
+	}
+	if err := os.Chdir(root); err != nil {
+		t.Fatalf("chdir failed: %v", err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(oldWd) })
+
+	peaks := `{"seconds_per_peak":0.1,"duration":0.2,"peaks":[0.5,0.25]}`
+	if err := os.WriteFile("s1.peaks.json", []byte(peaks), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	store := apiStoreStub{
+		sessions: map[string]storage.Session{
+			"s1": {ID: "s1", AudioPath: "s1.mp3"},
+			"s2": {ID: "s2", AudioPath: "s2.mp3"},
+			"s3": {ID: "s3"},
+		},
+	}
+	h, err := Handler(testStaticFS(t), NewHub(), store, ControlHooks{})
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/sessions/s1/waveform", nil))
+	if rr.Code != http.StatusOK || rr.Header().Get("Content-Type") != "application/json" || rr.Body.String() != peaks {
+		t.Fatalf("expected the stored peaks, got %d %q: %s", rr.Code, rr.Header().Get("Content-Type"), rr.Body.String())
+	}
+	for _, id := range []string{"s2", "s3", "missing"} {
+		rr = httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/sessions/"+id+"/waveform", nil))
+		if rr.Code != http.StatusNotFound {
+			t.Fatalf("%s: expected 404, got %d", id, rr.Code)
+		}
+	}
+}
+
+func TestAPIPauseAnnouncesActualState(t *testing.T) {
+	var announced []bool
+	controls := ControlHooks{
+		// Recording that can't be paused stays live, and says so.
+		IsPaused:        func() bool { return false },
+		OnStatusChanged: func(paused bool) { announced = append(announced, paused) },
+	}
+	h, err := Handler(testStaticFS(t), NewHub(), apiStoreStub{}, controls)
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/pause", nil))
+	if rr.Code != http.StatusNoContent || len(announced) != 1 || announced[0] {
+		t.Fatalf("expected the live state announced, got %d %v", rr.Code, announced)
+	}
+}
+
+func TestAPISessionSearch(t *testing.T) {
+	store := apiStoreStub{
+		sessions: map[string]storage.Session{"s1": {ID: "s1"}},
+		segments: map[string][]transcribe.Segment{"s1": {
+			{Speaker: 0, Text: "Let's start with the budget.", StartTime: 0, EndTime: 2},
+			{Speaker: 1, Text: "Café budget is over; the Budget review is Friday.", StartTime: 2, EndTime: 6},
+			{Speaker: 0, Text: "Okay, thanks.", StartTime: 6, EndTime: 7},
+		}},
+	}
+	h, err := Handler(testStaticFS(t), NewHub(), store, ControlHooks{})
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/sessions/s1/search?q=budget+REVIEW", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var body struct {
+		Matches []SearchMatch `json:"matches"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if len(body.Matches) != 1 {
+		t.Fatalf("expected only the segment with both terms, got %+v", body.Matches)
+	}
+	m := body.Matches[0]
+	// Offsets count UTF-16 units, so the é before them counts once.
+	want := []TextRange{{Start: 5, End: 11}, {Start: 25, End: 31}, {Start: 32, End: 38}}
+	if m.Segment != 1 || !reflect.DeepEqual(m.Highlights, want) {
+		t.Fatalf("expected segment 1 highlighted at %v, got %+v", want, m)
+	}
+	if m.Before == nil || m.Before.Text != "Let's start with the budget." || m.After == nil || m.After.Text != "Okay, thanks." {
+		t.Fatalf("expected the neighbouring segments as context, got %+v %+v", m.Before, m.After)
+	}
+
+	// Characters outside the BMP take two UTF-16 units in the browser.
+	matches, _ := searchSegments([]transcribe.Segment{{Text: "🎉 Budget 😀 budget"}}, searchTerms("budget"))
+	if want := []TextRange{{Start: 3, End: 9}, {Start: 13, End: 19}}; len(matches) != 1 || !reflect.DeepEqual(matches[0].Highlights, want) {
+		t.Fatalf("expected UTF-16 highlights %v, got %+v", want, matches)
+	}
+
+	for _, tc := range []struct {
+		path string
+		code int
+	}{
+		{"/api/sessions/s1/search?q=+", http.StatusBadRequest},
+		{"/api/sessions/nope/search?q=x", http.StatusNotFound},
+		{"/api/sessions/s1/search?q=missing", http.StatusOK},
+	} {
+		rr = httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tc.path, nil))
+		if rr.Code != tc.code {
+			t.Fatalf("%s: expected %d, got %d", tc.path, tc.code, rr.Code)
+		}
+	}
+	if !strings.Contains(rr.Body.String(), `"matches":[]`) {
+		t.Fatalf("expected an empty match list, got %s", rr.Body.String())
+	}
 }
 
 func TestAPIAudioRange(t *testing.T) {
@@ -163,6 +532,268 @@ func TestAPIAudioRange(t *testing.T) {
 	}
 }
 
+func TestAPIAudioByIndex(t *testing.T) {
+	t.Chdir(t.TempDir())
+	for name, body := range map[string]string{"main.mp3": "main", "gap.mp3": "gap"} {
+		if err := os.WriteFile(name, []byte(body), 0o644); err != nil {
+			t.Fatalf("write audio file failed: %v", err)
+		}
+	}
+
+	store := apiStoreStub{
+		sessions: map[string]storage.Session{"s1": {ID: "s1", AudioPath: "main.mp3"}},
+		audioFiles: map[string][]storage.AudioFile{"s1": {
+			{Index: 0, Kind: storage.AudioRecording, Path: "main.mp3"},
+			{Index: 2, Kind: storage.AudioGap, Path: "gap.mp3"},
+		}},
+	}
+	h, err := Handler(testStaticFS(t), NewHub(), store, ControlHooks{})
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+
+	for path, want := range map[string]struct {
+		code int
+		body string
+	}{
+		"/api/sessions/s1/audio/0":  {http.StatusOK, "main"},
+		"/api/sessions/s1/audio/2":  {http.StatusOK, "gap"},
+		"/api/sessions/s1/audio/1":  {http.StatusNotFound, ""},
+		"/api/sessions/s1/audio/-1": {http.StatusBadRequest, ""},
+	} {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+		if rr.Code != want.code || (want.body != "" && rr.Body.String() != want.body) {
+			t.Fatalf("%s: expected %d %q, got %d %q", path, want.code, want.body, rr.Code, rr.Body.String())
+		}
+	}
+}
+
+func TestAPIAudioFormat(t *testing.T) {
+	t.Chdir(t.TempDir())
+	for name, body := range map[string]string{"s1.mp3": "mp3", "s1.wav": "wav", "s2.mp3": "only"} {
+		if err := os.WriteFile(name, []byte(body), 0o644); err != nil {
+			t.Fatalf("write audio file failed: %v", err)
+		}
+	}
+	store := apiStoreStub{sessions: map[string]storage.Session{
+		"s1": {ID: "s1", AudioPath: "s1.mp3", KeepWAV: true, WAVPath: "s1.wav"},
+		"s2": {ID: "s2", AudioPath: "s2.mp3"},
+	}}
+	h, err := Handler(testStaticFS(t), NewHub(), store, ControlHooks{})
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+
+	for path, want := range map[string]struct {
+		code int
+		body string
+	}{
+		"/api/sessions/s1/audio":             {http.StatusOK, "mp3"},
+		"/api/sessions/s1/audio?format=mp3":  {http.StatusOK, "mp3"},
+		"/api/sessions/s1/audio?format=wav":  {http.StatusOK, "wav"},
+		"/api/sessions/s1/audio?format=flac": {http.StatusNotFound, ""},
+		"/api/sessions/s2/audio?format=wav":  {http.StatusNotFound, ""},
+	} {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+		if rr.Code != want.code || (want.body != "" && rr.Body.String() != want.body) {
+			t.Fatalf("%s: expected %d %q, got %d %q", path, want.code, want.body, rr.Code, rr.Body.String())
+		}
+	}
+}
+
+func TestAPIKeepWAV(t *testing.T) {
+	kept := map[string]bool{}
+	controls := ControlHooks{
+		KeepWAV: func(sessionID string, keep bool) error {
+			if sessionID != "20260301-090000" {
+				return session.ErrSessionNotRecording
+			}
+			kept[sessionID] = keep
+			return nil
+		},
+	}
+	h, err := Handler(testStaticFS(t), NewHub(), apiStoreStub{}, controls)
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+
+	for _, tc := range []struct {
+		id, body string
+		code     int
+		keep     bool
+	}{
+		{"20260301-090000", "", http.StatusNoContent, true},
+		{"20260301-090000", `{"keep": false}`, http.StatusNoContent, false},
+		{"20260301-080000", "", http.StatusConflict, false},
+	} {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/sessions/"+tc.id+"/keep-wav", strings.NewReader(tc.body)))
+		if rr.Code != tc.code {
+			t.Fatalf("%s %q: expected %d, got %d: %s", tc.id, tc.body, tc.code, rr.Code, rr.Body.String())
+		}
+		if tc.code == http.StatusNoContent && kept[tc.id] != tc.keep {
+			t.Fatalf("%s %q: expected keep=%v", tc.id, tc.body, tc.keep)
+		}
+	}
+}
+
+func TestAPISessionExports(t *testing.T) {
+	var retried []string
+	controls := ControlHooks{
+		SessionExports: func(sessionID string) ([]storage.Export, error) {
+			return []storage.Export{{SessionID: sessionID, Rule: "notes", Target: "markdown", Status: storage.ExportFailed, Error: "disk full", Attempts: 1}}, nil
+		},
+		RetryExport: func(sessionID, rule string) error {
+			if rule != "notes" {
+				return fmt.Errorf("%w: %q", export.ErrUnknownRule, rule)
+			}
+			retried = append(retried, sessionID)
+			return nil
+		},
+	}
+	h, err := Handler(testStaticFS(t), NewHub(), apiStoreStub{}, controls)
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/sessions/20260301-090000/exports", nil))
+	var exports []storage.Export
+	if err := json.Unmarshal(rr.Body.Bytes(), &exports); err != nil || rr.Code != http.StatusOK {
+		t.Fatalf("expected exports, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if len(exports) != 1 || exports[0].Status != storage.ExportFailed || exports[0].Error != "disk full" {
+		t.Fatalf("unexpected exports %+v", exports)
+	}
+
+	for _, tc := range []struct {
+		rule string
+		code int
+	}{
+		{"notes", http.StatusAccepted},
+		{"slack", http.StatusNotFound},
+	} {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/sessions/20260301-090000/exports/"+tc.rule, nil))
+		if rr.Code != tc.code {
+			t.Fatalf("%s: expected %d, got %d: %s", tc.rule, tc.code, rr.Code, rr.Body.String())
+		}
+	}
+	if len(retried) != 1 || retried[0] != "20260301-090000" {
+		t.Fatalf("expected one retry, got %v", retried)
+	}
+
+	h, err = Handler(testStaticFS(t), NewHub(), apiStoreStub{}, ControlHooks{})
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/sessions/20260301-090000/exports", nil))
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 without export rules, got %d", rr.Code)
+	}
+}
+
+func TestAPIAddNote(t *testing.T) {
+	active := true
+	var gotAt time.Time
+	controls := ControlHooks{
+		AddNote: func(at time.Time, text string) (storage.Note, error) {
+			if !active {
+				return storage.Note{}, session.ErrNoActiveSession
+			}
+			if strings.TrimSpace(text) == "" {
+				return storage.Note{}, session.ErrEmptyNote
+			}
+			gotAt = at
+			return storage.Note{ID: 1, SessionID: "20260301-090000", Text: text, CreatedAt: at}, nil
+		},
+	}
+	hub := NewHub()
+	events := hub.Subscribe()
+	defer hub.Unsubscribe(events)
+	h, err := Handler(testStaticFS(t), hub, apiStoreStub{}, controls)
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+	post := func(body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/session/current/notes", strings.NewReader(body)))
+		return rr
+	}
+
+	rr := post(`{"text": "ship date is firm", "at": "2026-03-01T09:05:00Z"}`)
+	if rr.Code != http.StatusCreated || !strings.Contains(rr.Body.String(), `"text":"ship date is firm"`) {
+		t.Fatalf("expected the note created, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if !gotAt.Equal(time.Date(2026, 3, 1, 9, 5, 0, 0, time.UTC)) {
+		t.Fatalf("expected the note's own timestamp kept, got %v", gotAt)
+	}
+	select {
+	case msg := <-events:
+		if !strings.Contains(string(msg), `"type":"note_added"`) {
+			t.Fatalf("expected a note_added event, got %s", msg)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the note broadcast")
+	}
+
+	if rr := post(`{"text": " "}`); rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an empty note, got %d", rr.Code)
+	}
+	active = false
+	if rr := post(`{"text": "late"}`); rr.Code != http.StatusConflict {
+		t.Fatalf("expected 409 without an active session, got %d", rr.Code)
+	}
+}
+
+func TestAPISummaryAudio(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "s1-summary.wav"), []byte("RIFF"), 0o644); err != nil {
+		t.Fatalf("write summary audio failed: %v", err)
+	}
+
+	oldWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd failed: %v", err)
+	}
+	if err := os.Chdir(root); err != nil {
+		t.Fatalf("chdir failed: %v", err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(oldWd) })
+
+	store := apiStoreStub{
+		sessionsByDate: map[string][]storage.Session{},
+		sessions: map[string]storage.Session{
+			"s1": {ID: "s1", SummaryAudioPath: "s1-summary.wav"},
+			"s2": {ID: "s2"},
+		},
+		segments: map[string][]transcribe.Segment{},
+	}
+
+	h, err := Handler(testStaticFS(t), NewHub(), store, ControlHooks{})
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/sessions/s1/summary/audio", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	if rr.Header().Get("Content-Type") != "audio/wav" || rr.Body.String() != "RIFF" {
+		t.Fatalf("unexpected summary audio response %q %q", rr.Header().Get("Content-Type"), rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/sessions/s2/summary/audio", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 without summary audio, got %d", rr.Code)
+	}
+}
+
 func TestAPIDates(t *testing.T) {
 	store := apiStoreStub{
 		sessionsByDate: map[string][]storage.Session{},
@@ -276,7 +907,76 @@ func TestAPIStatusNoWarnings(t *testing.T) {
 	}
 }
 
-func TestGetPresets(t *testing.T) {
+func TestAPIStatusReportsUpdate(t *testing.T) {
+	store := apiStoreStub{
+		sessionsByDate: map[string][]storage.Session{},
+		sessions:       map[string]storage.Session{},
+		segments:       map[string][]transcribe.Segment{},
+	}
+
+	h, err := Handler(testStaticFS(t), NewHub(), store, ControlHooks{
+		Update: func() *update.Release {
+			return &update.Release{Version: "v1.2.0", URL: "https://example.test/v1.2.0", Staged: true}
+		},
+	})
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/status", nil)
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	body := rr.Body.String()
+	if !strings.Contains(body, `"version":"v1.2.0"`) || !strings.Contains(body, `"staged":true`) {
+		t.Fatalf("expected update in response, got %s", body)
+	}
+}
+
+func TestAPIStatusReportsJobQueues(t *testing.T) {
+	store := apiStoreStub{
+		sessionsByDate: map[string][]storage.Session{},
+		sessions:       map[string]storage.Session{},
+		segments:       map[string][]transcribe.Segment{},
+	}
+
+	h, err := Handler(testStaticFS(t), NewHub(), store, ControlHooks{
+		Jobs: func() []jobs.Stats {
+			return []jobs.Stats{{Name: "summaries", Workers: 1, Queued: 3, Running: 1}}
+		},
+		Resources: func() watchdog.Stats {
+			return watchdog.Stats{MemoryMB: 120, MemoryLimitMB: 256}
+		},
+		MicHealth: func() MicHealth {
+			return MicHealth{Device: "USB Mic", Error: "device lost"}
+		},
+		TranscriptionStatus: func() TranscriptionStatus {
+			return TranscriptionStatus{State: "reconnecting", Provider: "deepgram", Attempt: 3, RetryInSeconds: 8}
+		},
+	})
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/status", nil))
+
+	body := rr.Body.String()
+	if !strings.Contains(body, `"jobs":[{"name":"summaries","workers":1,"queued":3,"running":1,"completed":0,"held":false}]`) {
+		t.Fatalf("expected job queue stats in response, got %s", body)
+	}
+	if !strings.Contains(body, `"resources":{"memory_mb":120,"memory_limit_mb":256`) {
+		t.Fatalf("expected resource stats in response, got %s", body)
+	}
+	if !strings.Contains(body, `"mic":{"healthy":false,"device":"USB Mic","error":"device lost"`) {
+		t.Fatalf("expected mic health in response, got %s", body)
+	}
+	if !strings.Contains(body, `"transcription":{"state":"reconnecting","provider":"deepgram","attempt":3,"retry_in_seconds":8`) {
+		t.Fatalf("expected transcription status in response, got %s", body)
+	}
+}
+
+func TestGetPresets(t *testing.T) {
 	store := apiStoreStub{
 		sessionsByDate: map[string][]storage.Session{},
 		sessions:       map[string]storage.Session{},
@@ -624,3 +1324,1531 @@ func TestEndSession_NotConfigured(t *testing.T) {
 		t.Fatalf("expected 503, got %d body=%s", rr.Code, rr.Body.String())
 	}
 }
+
+func TestAPIDecisions(t *testing.T) {
+	ts := time.Date(2026, 2, 26, 10, 0, 0, 0, time.UTC)
+	store := apiStoreStub{
+		decisions: []storage.Decision{
+			{ID: 1, SessionID: "s1", Text: "Adopt SQLite", Participants: []string{"Alice", "Bob"}, Timestamp: ts},
+			{ID: 2, SessionID: "s1", Text: "Postpone launch", Participants: []string{}, Timestamp: ts},
+		},
+	}
+
+	h, err := Handler(testStaticFS(t), NewHub(), store, ControlHooks{})
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/decisions?q=sqlite", nil)
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	var got []storage.Decision
+	if err := json.NewDecoder(rr.Body).Decode(&got); err != nil {
+		t.Fatalf("decode response failed: %v", err)
+	}
+	if len(got) != 1 || got[0].Text != "Adopt SQLite" {
+		t.Fatalf("unexpected decisions %#v", got)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/decisions/export", nil)
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	if got := rr.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/markdown") {
+		t.Fatalf("expected markdown content-type, got %q", got)
+	}
+	body := rr.Body.String()
+	if !strings.Contains(body, "## 2026-02-26") || !strings.Contains(body, "- Adopt SQLite (Alice, Bob) — session s1") {
+		t.Fatalf("unexpected decision log:\n%s", body)
+	}
+
+	german, _ := i18n.New("de")
+	h, err = Handler(testStaticFS(t), NewHub(), store, ControlHooks{Localizer: german})
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/decisions/export", nil))
+	body = rr.Body.String()
+	if !strings.HasPrefix(body, "# Entscheidungsprotokoll\n") || !strings.Contains(body, "- Postpone launch — Sitzung s1") {
+		t.Fatalf("expected a German decision log, got:\n%s", body)
+	}
+}
+
+func TestAPIAudioDevices(t *testing.T) {
+	var selected string
+	controls := ControlHooks{
+		AudioDevices: func() ([]AudioDevice, string, error) {
+			return []AudioDevice{{Name: "Built-in", Default: true}, {Name: "USB Mic"}}, selected, nil
+		},
+		SetAudioDevice: func(name string) error {
+			if name != "" && name != "Built-in" && name != "USB Mic" {
+				return ErrAudioDeviceNotFound
+			}
+			selected = name
+			return nil
+		},
+	}
+	h, err := Handler(testStaticFS(t), NewHub(), apiStoreStub{}, controls)
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/audio/device", strings.NewReader(`{"name": "USB Mic"}`)))
+	if rr.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/audio/device", strings.NewReader(`{"name": "Nope"}`)))
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown device, got %d", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/audio/devices", nil))
+	var body struct {
+		Current string        `json:"current"`
+		Devices []AudioDevice `json:"devices"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if body.Current != "USB Mic" || len(body.Devices) != 2 || !body.Devices[0].Default {
+		t.Fatalf("unexpected devices response: %+v", body)
+	}
+}
+
+func TestAPIAudioCalibrate(t *testing.T) {
+	var requested time.Duration
+	controls := ControlHooks{
+		Calibrate: func(ctx context.Context, d time.Duration) (storage.AudioCalibration, error) {
+			if requested != 0 {
+				return storage.AudioCalibration{}, ErrCalibrationInProgress
+			}
+			requested = d
+			return storage.AudioCalibration{Device: "USB Mic", NoiseFloorDB: -62, ThresholdDB: -50, Seconds: d.Seconds()}, nil
+		},
+	}
+	h, err := Handler(testStaticFS(t), NewHub(), apiStoreStub{}, controls)
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/audio/calibrate", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if requested != defaultCalibrationSeconds*time.Second {
+		t.Fatalf("expected the default calibration length, got %v", requested)
+	}
+	var got storage.AudioCalibration
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got.Device != "USB Mic" || got.ThresholdDB != -50 {
+		t.Fatalf("unexpected calibration %+v", got)
+	}
+
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/audio/calibrate", strings.NewReader(`{"seconds": 2}`)))
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected 409 while calibrating, got %d", rr.Code)
+	}
+
+	for _, body := range []string{`{"seconds": 0}`, `{"seconds": 61}`} {
+		rr = httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/audio/calibrate", strings.NewReader(body)))
+		if rr.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", body, rr.Code)
+		}
+	}
+}
+
+func TestAPIAudioDevicesUnavailable(t *testing.T) {
+	h, err := Handler(testStaticFS(t), NewHub(), apiStoreStub{}, ControlHooks{})
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/audio/devices", nil))
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d", rr.Code)
+	}
+}
+
+func TestAPIRotateKeys(t *testing.T) {
+	var rotated map[string]string
+	controls := ControlHooks{
+		Auth: NewAuth([]Token{{Name: "laptop", Scope: ScopeAdmin, Secret: "admin-secret"}}, nil),
+		RotateKeys: func(ctx context.Context, keys map[string]string) ([]KeyHealth, error) {
+			if _, ok := keys["deepgram"]; ok {
+				return nil, fmt.Errorf("%w %q", ErrUnknownProvider, "deepgram")
+			}
+			rotated = keys
+			return []KeyHealth{{Provider: "openai", Healthy: true}}, nil
+		},
+	}
+	h, err := Handler(testStaticFS(t), NewHub(), apiStoreStub{}, controls)
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+	post := func(contentType, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/admin/keys", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer admin-secret")
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := post("application/json; charset=utf-8", `{"keys": {"openai": "sk-new"}}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if rotated["openai"] != "sk-new" {
+		t.Fatalf("expected the openai key to be rotated, got %v", rotated)
+	}
+	var body struct {
+		Providers []KeyHealth `json:"providers"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(body.Providers) != 1 || !body.Providers[0].Healthy {
+		t.Fatalf("unexpected health response: %+v", body)
+	}
+
+	for _, req := range []string{`{"keys": {"deepgram": "dg"}}`, `{"keys": {"openai": ""}}`, `{"keys": {}}`} {
+		if rr := post("application/json", req); rr.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", req, rr.Code)
+		}
+	}
+
+	// What a cross-site form can send is refused.
+	rotated = nil
+	for _, contentType := range []string{"", "text/plain", "application/x-www-form-urlencoded"} {
+		if rr := post(contentType, `{"keys": {"openai": "sk-evil"}}`); rr.Code != http.StatusUnsupportedMediaType || rotated != nil {
+			t.Fatalf("%q: expected 415 and no rotation, got %d", contentType, rr.Code)
+		}
+	}
+}
+
+func TestAPIRotateKeysNeedsAuth(t *testing.T) {
+	controls := ControlHooks{
+		RotateKeys: func(ctx context.Context, keys map[string]string) ([]KeyHealth, error) {
+			t.Fatal("expected keys not to be rotated without auth")
+			return nil, nil
+		},
+	}
+	h, err := Handler(testStaticFS(t), NewHub(), apiStoreStub{}, controls)
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/keys", strings.NewReader(`{"keys": {"openai": "sk-evil"}}`))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	if rr.Code == http.StatusOK {
+		t.Fatalf("expected key rotation unavailable without auth, got %d", rr.Code)
+	}
+}
+
+func TestAPIUnreviewedSessions(t *testing.T) {
+	day := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	store := apiStoreStub{
+		sessions: map[string]storage.Session{
+			"monday":  {ID: "monday", StartedAt: day, Summary: "- a", SummaryStatus: storage.SummaryCompleted},
+			"tuesday": {ID: "tuesday", StartedAt: day.AddDate(0, 0, 1), Summary: "- b", SummaryStatus: storage.SummaryCompleted},
+			"running": {ID: "running", StartedAt: day.Add(time.Hour), SummaryStatus: storage.SummaryRunning},
+		},
+		viewed: map[string]time.Time{},
+	}
+	h, err := Handler(testStaticFS(t), NewHub(), store, ControlHooks{})
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+	list := func(query string) []string {
+		t.Helper()
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/sessions"+query, nil))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("GET %s: expected 200, got %d: %s", query, rr.Code, rr.Body.String())
+		}
+		var sessions []storage.Session
+		if err := json.Unmarshal(rr.Body.Bytes(), &sessions); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		ids := []string{}
+		for _, sess := range sessions {
+			ids = append(ids, sess.ID)
+		}
+		return ids
+	}
+
+	if got := list("?unreviewed=true"); !reflect.DeepEqual(got, []string{"tuesday", "monday"}) {
+		t.Fatalf("expected both summaries unreviewed, newest first, got %v", got)
+	}
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/sessions/tuesday/viewed", nil))
+	if rr.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if got := list("?unreviewed=true"); !reflect.DeepEqual(got, []string{"monday"}) {
+		t.Fatalf("expected the viewed session to leave the inbox, got %v", got)
+	}
+	if got := list("?unreviewed=true&date=2026-03-03"); len(got) != 0 {
+		t.Fatalf("expected the date to narrow the inbox, got %v", got)
+	}
+
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/sessions/missing/viewed", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for a missing session, got %d", rr.Code)
+	}
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/sessions?unreviewed=maybe", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a malformed filter, got %d", rr.Code)
+	}
+}
+
+func TestAPIKeywords(t *testing.T) {
+	current := []string{"Kubernetes"}
+	controls := ControlHooks{
+		Keywords: func() []string { return current },
+		SetKeywords: func(keywords []string) ([]string, error) {
+			next, err := transcribe.NormalizeKeywords(keywords)
+			if err != nil {
+				return nil, err
+			}
+			current = next
+			return next, nil
+		},
+	}
+	h, err := Handler(testStaticFS(t), NewHub(), apiStoreStub{}, controls)
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/transcription/keywords", nil))
+	if rr.Code != http.StatusOK || strings.TrimSpace(rr.Body.String()) != `{"keywords":["Kubernetes"]}` {
+		t.Fatalf("expected the current keywords, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodPut, "/api/transcription/keywords", strings.NewReader(`{"keywords": [" OKR:2 ", "okr", "Sjawhar"]}`)))
+	if rr.Code != http.StatusOK || strings.TrimSpace(rr.Body.String()) != `{"keywords":["OKR:2","Sjawhar"]}` {
+		t.Fatalf("expected the normalized keywords, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	for _, req := range []string{`{"keywords": ["OKR:lots"]}`, `{}`, `not json`} {
+		rr = httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodPut, "/api/transcription/keywords", strings.NewReader(req)))
+		if rr.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", req, rr.Code)
+		}
+	}
+	if len(current) != 2 {
+		t.Fatalf("expected rejected updates to change nothing, got %q", current)
+	}
+
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodPut, "/api/transcription/keywords", strings.NewReader(`{"keywords": []}`)))
+	if rr.Code != http.StatusOK || strings.TrimSpace(rr.Body.String()) != `{"keywords":[]}` {
+		t.Fatalf("expected keywords cleared, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestAPISeriesDetail(t *testing.T) {
+	week1 := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	week2 := week1.AddDate(0, 0, 7)
+	store := apiStoreStub{
+		sessions: map[string]storage.Session{
+			"w2":  {ID: "w2", StartedAt: week2, SeriesID: "series-w1", Summary: "Shipped the beta."},
+			"w1":  {ID: "w1", StartedAt: week1, SeriesID: "series-w1", Summary: "Planned the beta."},
+			"one": {ID: "one", StartedAt: week1.Add(time.Hour)},
+		},
+		decisions: []storage.Decision{
+			{SessionID: "w1", Text: "Ship beta next week", Timestamp: week1},
+			{SessionID: "one", Text: "Unrelated", Timestamp: week1},
+		},
+	}
+	h, err := Handler(testStaticFS(t), NewHub(), store, ControlHooks{})
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/series/series-w1", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var body struct {
+		Series    storage.Series     `json:"series"`
+		Sessions  []storage.Session  `json:"sessions"`
+		Summary   string             `json:"summary"`
+		Decisions []storage.Decision `json:"decisions"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if body.Series.SessionCount != 2 || len(body.Sessions) != 2 || body.Sessions[0].ID != "w1" {
+		t.Fatalf("unexpected series %+v", body)
+	}
+	if strings.Index(body.Summary, "Planned") > strings.Index(body.Summary, "Shipped") {
+		t.Fatalf("expected summaries oldest first, got %q", body.Summary)
+	}
+	if len(body.Decisions) != 1 || body.Decisions[0].SessionID != "w1" {
+		t.Fatalf("expected only the series' decisions, got %+v", body.Decisions)
+	}
+
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/series/series-none", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown series, got %d", rr.Code)
+	}
+}
+
+func TestAPISeriesBrief(t *testing.T) {
+	week1 := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	store := apiStoreStub{
+		sessions: map[string]storage.Session{
+			"w1": {ID: "w1", StartedAt: week1, SeriesID: "series-w1"},
+			"w2": {ID: "w2", StartedAt: week1.AddDate(0, 0, 7), SeriesID: "series-w1", Summary: "Shipped the beta."},
+		},
+		actionItems: map[string][]storage.ActionItem{
+			"w1": {{SessionID: "w1", Text: "Stale"}},
+			"w2": {
+				{SessionID: "w2", Text: "Send the deck", Owner: "Alice"},
+				{SessionID: "w2", Text: "Book the room", Done: true},
+			},
+		},
+	}
+	h, err := Handler(testStaticFS(t), NewHub(), store, ControlHooks{})
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/series/series-w1/brief", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var body struct {
+		LastSession storage.Session      `json:"last_session"`
+		Open        []storage.ActionItem `json:"open_action_items"`
+		Brief       string               `json:"brief"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if body.LastSession.ID != "w2" || len(body.Open) != 1 || body.Open[0].Text != "Send the deck" {
+		t.Fatalf("unexpected brief %+v", body)
+	}
+	if !strings.Contains(body.Brief, "- [ ] Send the deck — Alice") || !strings.Contains(body.Brief, "Shipped the beta.") {
+		t.Fatalf("unexpected brief markdown %q", body.Brief)
+	}
+
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/series/series-none/brief", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown series, got %d", rr.Code)
+	}
+}
+
+func TestAPIBriefings(t *testing.T) {
+	var created []storage.Briefing
+	controls := ControlHooks{
+		CreateBriefing: func(_ context.Context, title string, attendees []string, startsAt, endsAt time.Time) (storage.Briefing, error) {
+			b := storage.Briefing{ID: "brief-1", Title: title, Attendees: attendees, StartsAt: startsAt, EndsAt: &endsAt, Brief: "Talk about pricing."}
+			created = append(created, b)
+			return b, nil
+		},
+		Briefings: func() ([]storage.Briefing, error) { return created, nil },
+	}
+	h, err := Handler(testStaticFS(t), NewHub(), apiStoreStub{}, controls)
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/briefings", strings.NewReader(
+		`{"title": "Pricing sync", "attendees": ["priya@example.com"], "starts_at": "2026-03-20T15:00:00Z", "ends_at": "2026-03-20T15:30:00Z"}`)))
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if len(created) != 1 || created[0].Title != "Pricing sync" || !created[0].StartsAt.Equal(time.Date(2026, 3, 20, 15, 0, 0, 0, time.UTC)) || !created[0].EndsAt.Equal(time.Date(2026, 3, 20, 15, 30, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected briefing request %#v", created)
+	}
+
+	for _, body := range []string{
+		`{"starts_at": "2026-03-20T15:00:00Z"}`,
+		`{"title": "Pricing sync"}`,
+		`{"title": "Pricing sync", "starts_at": "2026-03-20T15:00:00Z", "ends_at": "2026-03-20T14:00:00Z"}`,
+		`not json`,
+	} {
+		rr = httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/briefings", strings.NewReader(body)))
+		if rr.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400 for %s, got %d", body, rr.Code)
+		}
+	}
+
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/briefings", nil))
+	var listed []storage.Briefing
+	if err := json.Unmarshal(rr.Body.Bytes(), &listed); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(listed) != 1 || listed[0].Brief != "Talk about pricing." {
+		t.Fatalf("unexpected briefings %+v", listed)
+	}
+
+	h, err = Handler(testStaticFS(t), NewHub(), apiStoreStub{}, ControlHooks{})
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/briefings", nil))
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d", rr.Code)
+	}
+}
+
+func TestAPIMemos(t *testing.T) {
+	start := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	store := apiStoreStub{
+		sessions: map[string]storage.Session{
+			"m1":      {ID: "m1", StartedAt: start, Kind: storage.SessionKindMemo},
+			"m2":      {ID: "m2", StartedAt: start.Add(time.Hour), Kind: storage.SessionKindMemo},
+			"meeting": {ID: "meeting", StartedAt: start.Add(30 * time.Minute)},
+		},
+	}
+	recording := false
+	controls := ControlHooks{
+		StartMemo: func(context.Context) (string, error) {
+			if recording {
+				return "", session.ErrMemoInProgress
+			}
+			recording = true
+			return "m3", nil
+		},
+		StopMemo: func(context.Context) (string, error) {
+			if !recording {
+				return "", session.ErrNoMemoInProgress
+			}
+			recording = false
+			return "m3", nil
+		},
+	}
+	h, err := Handler(testStaticFS(t), NewHub(), store, controls)
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+
+	for _, tc := range []struct {
+		path string
+		code int
+	}{
+		{"/api/memo/stop", http.StatusConflict},
+		{"/api/memo/start", http.StatusCreated},
+		{"/api/memo/start", http.StatusConflict},
+		{"/api/memo/stop", http.StatusOK},
+	} {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, tc.path, nil))
+		if rr.Code != tc.code {
+			t.Fatalf("POST %s: expected %d, got %d: %s", tc.path, tc.code, rr.Code, rr.Body.String())
+		}
+		if rr.Code < 300 && !strings.Contains(rr.Body.String(), `"session_id":"m3"`) {
+			t.Fatalf("POST %s: expected the memo's session id, got %s", tc.path, rr.Body.String())
+		}
+	}
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/memos", nil))
+	var memos []storage.Session
+	if err := json.Unmarshal(rr.Body.Bytes(), &memos); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(memos) != 2 || memos[0].ID != "m2" || memos[1].ID != "m1" {
+		t.Fatalf("expected memos newest first, got %+v", memos)
+	}
+}
+
+func TestAPIImportAudio(t *testing.T) {
+	var gotName, gotBody string
+	var gotStart time.Time
+	controls := ControlHooks{
+		ImportAudio: func(_ context.Context, name string, r io.Reader, startedAt time.Time) error {
+			data, err := io.ReadAll(r)
+			if err != nil {
+				return err
+			}
+			gotName, gotBody, gotStart = name, string(data), startedAt
+			if string(data) == "garbled" {
+				return audio.ErrUndecodable
+			}
+			return nil
+		},
+	}
+	h, err := Handler(testStaticFS(t), NewHub(), apiStoreStub{}, controls)
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+	upload := func(query, filename, content string) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		_ = form.WriteField("note", "ignored")
+		part, err := form.CreateFormFile("file", filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = part.Write([]byte(content))
+		_ = form.Close()
+		req := httptest.NewRequest(http.MethodPost, "/api/sessions/import-audio"+query, &body)
+		req.Header.Set("Content-Type", form.FormDataContentType())
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := upload("?started_at=2026-03-02T09:00:00Z", "memo.m4a", "audio")
+	if rr.Code != http.StatusAccepted || !strings.Contains(rr.Body.String(), `"status":"importing"`) {
+		t.Fatalf("expected the import accepted, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if gotName != "memo.m4a" || gotBody != "audio" || !gotStart.Equal(time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected the upload passed on, got %q %q %v", gotName, gotBody, gotStart)
+	}
+
+	for _, tc := range []struct {
+		query, filename, content string
+		code                     int
+	}{
+		{"", "notes.txt", "text", http.StatusUnsupportedMediaType},
+		{"", "memo.wav", "garbled", http.StatusUnsupportedMediaType},
+		{"?started_at=yesterday", "memo.wav", "audio", http.StatusBadRequest},
+	} {
+		if rr := upload(tc.query, tc.filename, tc.content); rr.Code != tc.code {
+			t.Fatalf("upload %s%s: expected %d, got %d: %s", tc.filename, tc.query, tc.code, rr.Code, rr.Body.String())
+		}
+	}
+
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/sessions/import-audio", strings.NewReader("audio")))
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected a non-multipart body rejected, got %d", rr.Code)
+	}
+
+	h, err = Handler(testStaticFS(t), NewHub(), apiStoreStub{}, ControlHooks{})
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+	rr = upload("", "memo.m4a", "audio")
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 without a transcriber configured, got %d", rr.Code)
+	}
+}
+
+func TestAPISummaryEstimate(t *testing.T) {
+	controls := ControlHooks{
+		EstimateSummary: func(_ context.Context, sessionID, preset string) (string, []summary.Estimate, error) {
+			switch {
+			case sessionID != "s1":
+				return "", nil, fmt.Errorf("get session: %w", os.ErrNotExist)
+			case preset == "":
+				return "default", []summary.Estimate{{Model: "openai/gpt-4o-mini", InputTokens: 1000, OutputTokens: 150, CostUSD: 0.0002, Priced: true, Selected: true}}, nil
+			default:
+				return "", nil, fmt.Errorf("%w %q", summary.ErrUnknownPreset, preset)
+			}
+		},
+	}
+	h, err := Handler(testStaticFS(t), NewHub(), apiStoreStub{}, controls)
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/sessions/s1/summarize/estimate", nil))
+	var body struct {
+		Preset    string             `json:"preset"`
+		Estimates []summary.Estimate `json:"estimates"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if rr.Code != http.StatusOK || body.Preset != "default" || len(body.Estimates) != 1 || body.Estimates[0].InputTokens != 1000 {
+		t.Fatalf("unexpected estimate %d: %s", rr.Code, rr.Body.String())
+	}
+
+	for _, tc := range []struct {
+		path string
+		code int
+	}{
+		{"/api/sessions/s1/summarize/estimate?preset=retro", http.StatusBadRequest},
+		{"/api/sessions/s2/summarize/estimate", http.StatusNotFound},
+		{"/api/sessions/..%2Fetc/summarize/estimate", http.StatusForbidden},
+	} {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tc.path, nil))
+		if rr.Code != tc.code {
+			t.Fatalf("GET %s: expected %d, got %d: %s", tc.path, tc.code, rr.Code, rr.Body.String())
+		}
+	}
+
+	h, err = Handler(testStaticFS(t), NewHub(), apiStoreStub{}, ControlHooks{})
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/sessions/s1/summarize/estimate", nil))
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 without a summarizer, got %d", rr.Code)
+	}
+}
+
+func TestAPISummaryRewrite(t *testing.T) {
+	controls := ControlHooks{
+		RewriteSummary: func(_ context.Context, sessionID, level string) (string, error) {
+			switch sessionID {
+			case "s1":
+				return "Rewritten for " + level, nil
+			case "live":
+				return "", session.ErrNoSummary
+			default:
+				return "", fmt.Errorf("get session: %w", os.ErrNotExist)
+			}
+		},
+	}
+	h, err := Handler(testStaticFS(t), NewHub(), apiStoreStub{}, controls)
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/sessions/s1/summary/rewrite", strings.NewReader(`{"reading_level":"plain"}`)))
+	var body struct {
+		ReadingLevel string `json:"reading_level"`
+		Summary      string `json:"summary"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if rr.Code != http.StatusOK || body.ReadingLevel != "plain" || body.Summary != "Rewritten for plain" {
+		t.Fatalf("unexpected rewrite %d: %s", rr.Code, rr.Body.String())
+	}
+
+	for _, tc := range []struct {
+		path, body string
+		code       int
+	}{
+		{"/api/sessions/s1/summary/rewrite", `{"reading_level":"legalese"}`, http.StatusBadRequest},
+		{"/api/sessions/s1/summary/rewrite", `{}`, http.StatusBadRequest},
+		{"/api/sessions/live/summary/rewrite", `{"reading_level":"executive"}`, http.StatusConflict},
+		{"/api/sessions/s2/summary/rewrite", `{"reading_level":"technical"}`, http.StatusNotFound},
+		{"/api/sessions/s1/resummarize", `{"reading_level":"legalese"}`, http.StatusBadRequest},
+	} {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, tc.path, strings.NewReader(tc.body)))
+		if rr.Code != tc.code {
+			t.Fatalf("POST %s %s: expected %d, got %d: %s", tc.path, tc.body, tc.code, rr.Code, rr.Body.String())
+		}
+	}
+}
+
+func TestAPISummaryQueue(t *testing.T) {
+	queued := map[string]bool{"s1": true}
+	controls := ControlHooks{
+		SummaryQueue: func() []session.SummaryJob {
+			if !queued["s1"] {
+				return nil
+			}
+			return []session.SummaryJob{{SessionID: "s1", Status: storage.SummaryPending, Attempt: 2}}
+		},
+		CancelSummary: func(sessionID string) error {
+			if !queued[sessionID] {
+				return session.ErrNoSummaryJob
+			}
+			delete(queued, sessionID)
+			return nil
+		},
+	}
+	h, err := Handler(testStaticFS(t), NewHub(), apiStoreStub{}, controls)
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/summaries/queue", nil))
+	var body struct {
+		Jobs []session.SummaryJob `json:"jobs"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if rr.Code != http.StatusOK || len(body.Jobs) != 1 || body.Jobs[0].SessionID != "s1" || body.Jobs[0].Attempt != 2 {
+		t.Fatalf("unexpected queue %d: %s", rr.Code, rr.Body.String())
+	}
+
+	for _, tc := range []struct {
+		path string
+		code int
+	}{
+		{"/api/summaries/s1/cancel", http.StatusOK},
+		{"/api/summaries/s1/cancel", http.StatusNotFound},
+		{"/api/summaries/..%2Fetc/cancel", http.StatusForbidden},
+	} {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, tc.path, nil))
+		if rr.Code != tc.code {
+			t.Fatalf("POST %s: expected %d, got %d: %s", tc.path, tc.code, rr.Code, rr.Body.String())
+		}
+	}
+
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/summaries/queue", nil))
+	if got := strings.TrimSpace(rr.Body.String()); got != `{"jobs":[]}` {
+		t.Fatalf("expected an empty queue, got %s", got)
+	}
+
+	h, err = Handler(testStaticFS(t), NewHub(), apiStoreStub{}, ControlHooks{})
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/summaries/queue", nil))
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 without a summarizer, got %d", rr.Code)
+	}
+}
+
+func TestAPIStatsAndMetrics(t *testing.T) {
+	controls := ControlHooks{
+		TranscriptionLatency: func() []transcribe.LatencyStats {
+			return []transcribe.LatencyStats{{Provider: "deepgram", Count: 3, Sum: 2.25, P50: 700, P90: 900, P99: 950, Max: 950}}
+		},
+	}
+	h, err := Handler(testStaticFS(t), NewHub(), apiStoreStub{}, controls)
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/stats", nil))
+	var stats struct {
+		TranscriptionLatency []transcribe.LatencyStats `json:"transcription_latency"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &stats); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(stats.TranscriptionLatency) != 1 || stats.TranscriptionLatency[0].P90 != 900 {
+		t.Fatalf("unexpected stats: %s", rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	for _, want := range []string{
+		"# TYPE ghost_wispr_transcription_latency_seconds summary\n",
+		`ghost_wispr_transcription_latency_seconds{provider="deepgram",quantile="0.9"} 0.9` + "\n",
+		`ghost_wispr_transcription_latency_seconds_sum{provider="deepgram"} 2.25` + "\n",
+		`ghost_wispr_transcription_latency_seconds_count{provider="deepgram"} 3` + "\n",
+	} {
+		if !strings.Contains(rr.Body.String(), want) {
+			t.Fatalf("expected %q in metrics, got:\n%s", want, rr.Body.String())
+		}
+	}
+
+	// Without live transcription both report no providers.
+	h, err = Handler(testStaticFS(t), NewHub(), apiStoreStub{}, ControlHooks{})
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/stats", nil))
+	if got := strings.TrimSpace(rr.Body.String()); got != `{"transcription_latency":[]}` {
+		t.Fatalf("unexpected stats without transcription: %s", got)
+	}
+}
+
+func TestAPIDictation(t *testing.T) {
+	d := dictation.New(dictation.Typer{})
+	h, err := Handler(testStaticFS(t), NewHub(), apiStoreStub{}, ControlHooks{Dictation: d})
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"/api/dictation/start", "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !d.Active() {
+		t.Fatalf("expected dictation on, got %d (active %v)", resp.StatusCode, d.Active())
+	}
+
+	for _, tc := range []struct {
+		query, want string
+	}{
+		{"?format=text", "Dear team,\n"},
+		{"", "data: Dear team,\n"},
+	} {
+		stream, err := http.Get(srv.URL + "/api/dictation/stream" + tc.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The handler may subscribe just after the headers arrive, so keep
+		// dictating until the stream picks a segment up.
+		done := make(chan struct{})
+		go func() {
+			for {
+				select {
+				case <-done:
+					return
+				case <-time.After(10 * time.Millisecond):
+					d.Dictate("Dear team,")
+				}
+			}
+		}()
+		line, err := bufio.NewReader(stream.Body).ReadString('\n')
+		close(done)
+		_ = stream.Body.Close()
+		if err != nil || line != tc.want {
+			t.Fatalf("stream%s: expected %q, got %q (%v)", tc.query, tc.want, line, err)
+		}
+	}
+
+	resp, err = http.Post(srv.URL+"/api/dictation/stop", "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if d.Active() {
+		t.Fatal("expected dictation off")
+	}
+}
+
+func TestOverlay(t *testing.T) {
+	hub := NewHub()
+	h, err := Handler(testStaticFS(t), hub, apiStoreStub{}, ControlHooks{})
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	hub.BroadcastLiveTranscript(transcribe.Segment{Speaker: 0, Text: "stale"})
+	hub.BroadcastSessionStarted("s1")
+	for _, text := range []string{"one", "two", "three", "four"} {
+		hub.BroadcastLiveTranscript(transcribe.Segment{Speaker: 1, Text: text})
+	}
+
+	get := func(query string) (int, string) {
+		t.Helper()
+		resp, err := http.Get(srv.URL + "/overlay" + query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+	for _, tc := range []struct {
+		query, want string
+	}{
+		{"?format=text", "two\nthree\nfour\n"},
+		{"?format=text&lines=1&speakers=true", "Speaker 1: four\n"},
+	} {
+		if code, body := get(tc.query); code != http.StatusOK || body != tc.want {
+			t.Fatalf("GET /overlay%s: expected %q, got %d %q", tc.query, tc.want, code, body)
+		}
+	}
+	de, _ := i18n.New("de")
+	if got := overlayText(hub.RecentTranscript(1), true, de); got != "Sprecher 1: four\n" {
+		t.Fatalf("expected a localized speaker label, got %q", got)
+	}
+	code, page := get("?color=ff8800&background=black&font=Open+Sans&size=48&align=center")
+	if code != http.StatusOK || !strings.Contains(page, "color: #ff8800") || !strings.Contains(page, "font-size: 48px") || !strings.Contains(page, "font-family: Open Sans") {
+		t.Fatalf("expected a styled overlay page, got %d %s", code, page)
+	}
+	for _, query := range []string{"?lines=0", "?size=1000", "?color=red%3Bx", "?font=a%7Bb", "?align=top", "?format=xml"} {
+		if code, _ := get(query); code != http.StatusBadRequest {
+			t.Fatalf("GET /overlay%s: expected 400, got %d", query, code)
+		}
+	}
+
+	stream, err := http.Get(srv.URL + "/overlay?format=sse&lines=2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = stream.Body.Close() }()
+	events := bufio.NewReader(stream.Body)
+	readEvent := func() string {
+		t.Helper()
+		var event string
+		for {
+			line, err := events.ReadString('\n')
+			if err != nil {
+				t.Fatalf("read overlay stream: %v", err)
+			}
+			if line == "\n" {
+				return event
+			}
+			event += line
+		}
+	}
+	if got := readEvent(); got != "data: three\ndata: four\n" {
+		t.Fatalf("expected the current lines first, got %q", got)
+	}
+	hub.BroadcastLiveTranscript(transcribe.Segment{Speaker: 1, Text: "five"})
+	if got := readEvent(); got != "data: four\ndata: five\n" {
+		t.Fatalf("expected the lines to roll on, got %q", got)
+	}
+}
+
+func speakerTestStore(t *testing.T) *storage.MemoryStore {
+	t.Helper()
+	store := storage.NewMemoryStore()
+	if err := store.CreateSession("s1", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	base := time.Date(2026, 3, 20, 9, 0, 0, 0, time.UTC)
+	for i, speaker := range []int{0, 1, 0, 2, 1} {
+		seg := transcribe.Segment{
+			Speaker:   speaker,
+			Text:      fmt.Sprintf("line %d", i),
+			StartTime: float64(i * 2),
+			EndTime:   float64(i*2 + 1),
+			Timestamp: base.Add(time.Duration(i) * time.Second),
+		}
+		if err := store.AppendSegment("s1", seg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return store
+}
+
+func segmentSpeakers(t *testing.T, store *storage.MemoryStore) []int {
+	t.Helper()
+	segments, err := store.GetSegments("s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	speakers := make([]int, len(segments))
+	for i, seg := range segments {
+		speakers[i] = seg.Speaker
+	}
+	return speakers
+}
+
+func TestSpeakerReassign(t *testing.T) {
+	store := speakerTestStore(t)
+	h, err := Handler(testStaticFS(t), NewHub(), store, ControlHooks{})
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/sessions/s1/speakers/reassign",
+		strings.NewReader(`{"first_segment":1,"last_segment":3,"speaker":0}`))
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	var resp struct {
+		SegmentsVersion int            `json:"segments_version"`
+		Speakers        []SpeakerStats `json:"speakers"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.SegmentsVersion != 2 {
+		t.Fatalf("expected segments_version 2, got %d", resp.SegmentsVersion)
+	}
+	want := []SpeakerStats{{Speaker: 0, Segments: 4, Seconds: 4}, {Speaker: 1, Segments: 1, Seconds: 1}}
+	if !reflect.DeepEqual(resp.Speakers, want) {
+		t.Fatalf("expected stats %+v, got %+v", want, resp.Speakers)
+	}
+	if got := segmentSpeakers(t, store); !reflect.DeepEqual(got, []int{0, 0, 0, 0, 1}) {
+		t.Fatalf("unexpected speakers after reassign: %v", got)
+	}
+
+	for _, body := range []string{
+		`{"first_segment":3,"last_segment":5,"speaker":0}`,
+		`{"first_segment":2,"last_segment":1,"speaker":0}`,
+		`{"first_segment":0,"last_segment":1}`,
+	} {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/sessions/s1/speakers/reassign", strings.NewReader(body)))
+		if rr.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected status 400, got %d", body, rr.Code)
+		}
+	}
+
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/sessions/missing/speakers/reassign",
+		strings.NewReader(`{"first_segment":0,"last_segment":0,"speaker":0}`)))
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404 for a missing session, got %d", rr.Code)
+	}
+}
+
+func TestSpeakerNames(t *testing.T) {
+	store := speakerTestStore(t)
+	h, err := Handler(testStaticFS(t), NewHub(), store, ControlHooks{})
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+	patch := func(path, body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodPatch, path, strings.NewReader(body)))
+		return rr
+	}
+
+	if rr := patch("/api/sessions/s1/speakers/1", `{"name":" Alice "}`); rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"name":"Alice"`) {
+		t.Fatalf("expected speaker named, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/sessions/s1/speakers", nil))
+	var resp struct {
+		Speakers []SpeakerStats `json:"speakers"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Speakers) != 3 || resp.Speakers[0].Name != "" || resp.Speakers[1].Name != "Alice" {
+		t.Fatalf("expected speaker 1 named in stats, got %+v", resp.Speakers)
+	}
+
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/sessions/s1/export?format=html", nil))
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "Alice") || !strings.Contains(rr.Body.String(), "Speaker 0") {
+		t.Fatalf("expected the viewer to label speaker 1 by name, got %d", rr.Code)
+	}
+
+	if rr := patch("/api/sessions/s1/speakers/1", `{"name":""}`); rr.Code != http.StatusOK {
+		t.Fatalf("expected name cleared, got %d", rr.Code)
+	}
+	if names, _ := store.GetSpeakerNames("s1"); len(names) != 0 {
+		t.Fatalf("expected no names left, got %v", names)
+	}
+
+	for path, want := range map[string]int{
+		"/api/sessions/s1/speakers/x":      http.StatusBadRequest,
+		"/api/sessions/s1/speakers/-1":     http.StatusBadRequest,
+		"/api/sessions/missing/speakers/0": http.StatusNotFound,
+	} {
+		if rr := patch(path, `{"name":"Bob"}`); rr.Code != want {
+			t.Fatalf("%s: expected status %d, got %d", path, want, rr.Code)
+		}
+	}
+	if rr := patch("/api/sessions/s1/speakers/0", `{}`); rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 without a name, got %d", rr.Code)
+	}
+
+	if err := store.SetSessionLocked("s1", true); err != nil {
+		t.Fatal(err)
+	}
+	if rr := patch("/api/sessions/s1/speakers/0", `{"name":"Bob"}`); rr.Code != http.StatusConflict {
+		t.Fatalf("expected naming a speaker of a locked session to conflict, got %d", rr.Code)
+	}
+}
+
+func TestVoiceProfiles(t *testing.T) {
+	store := speakerTestStore(t)
+	type enrollment struct {
+		name, filename, audio string
+	}
+	enrolled := make(chan enrollment, 1)
+	h, err := Handler(testStaticFS(t), NewHub(), store, ControlHooks{
+		EnrollVoice: func(_ context.Context, name, filename string, r io.Reader) (storage.VoiceProfile, error) {
+			audio, _ := io.ReadAll(r)
+			enrolled <- enrollment{name, filename, string(audio)}
+			return store.AddVoiceProfile(storage.VoiceProfile{Name: name, Embedding: []float64{1}})
+		},
+		EnrollSpeakerVoice: func(_ context.Context, sessionID string, speaker int, name string) (storage.VoiceProfile, error) {
+			if sessionID != "s1" {
+				return storage.VoiceProfile{}, os.ErrNotExist
+			}
+			if name == "" {
+				return storage.VoiceProfile{}, voiceprint.ErrNoName
+			}
+			return store.AddVoiceProfile(storage.VoiceProfile{Name: name, Source: fmt.Sprintf("speaker %d", speaker), Embedding: []float64{1}})
+		},
+	})
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", "sample.webm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = part.Write([]byte("voice"))
+	_ = form.Close()
+	req := httptest.NewRequest(http.MethodPost, "/api/voices?name=Alice", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if got := <-enrolled; got != (enrollment{"Alice", "sample.webm", "voice"}) {
+		t.Fatalf("unexpected enrollment %+v", got)
+	}
+
+	post := func(path, body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+		return rr
+	}
+	if rr := post("/api/sessions/s1/speakers/1/voice", `{"name":"Bob"}`); rr.Code != http.StatusCreated || !strings.Contains(rr.Body.String(), `"source":"speaker 1"`) {
+		t.Fatalf("expected speaker 1 enrolled, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if rr := post("/api/sessions/s1/speakers/2/voice", ""); rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 without a name, got %d", rr.Code)
+	}
+	if rr := post("/api/sessions/missing/speakers/0/voice", `{"name":"Bob"}`); rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for a missing session, got %d", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/voices", nil))
+	var resp struct {
+		Voices []storage.VoiceProfile `json:"voices"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Voices) != 2 || resp.Voices[0].Name != "Alice" || strings.Contains(rr.Body.String(), "embedding") {
+		t.Fatalf("expected Alice and Bob listed without embeddings, got %s", rr.Body.String())
+	}
+
+	del := func(path string) int {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, path, nil))
+		return rr.Code
+	}
+	if code := del(fmt.Sprintf("/api/voices/%d", resp.Voices[0].ID)); code != http.StatusNoContent {
+		t.Fatalf("expected 204 deleting a voice, got %d", code)
+	}
+	if code := del(fmt.Sprintf("/api/voices/%d", resp.Voices[0].ID)); code != http.StatusNotFound {
+		t.Fatalf("expected 404 deleting it again, got %d", code)
+	}
+
+	h, err = Handler(testStaticFS(t), NewHub(), store, ControlHooks{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/sessions/s1/speakers/1/voice", nil))
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 without voice profiles configured, got %d", rr.Code)
+	}
+}
+
+func TestReports(t *testing.T) {
+	store := storage.NewMemoryStore()
+	h, err := Handler(testStaticFS(t), NewHub(), store, ControlHooks{})
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+	get := func(path string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+		return rr
+	}
+
+	if rr := get("/api/reports"); rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"reports":[]`) {
+		t.Fatalf("expected an empty list, got %d: %s", rr.Code, rr.Body.String())
+	}
+	for _, r := range []storage.Report{
+		{ID: "2026-W41", PeriodStart: time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC)},
+		{ID: "2026-W42", PeriodStart: time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC), Sessions: 4, Text: "four sessions"},
+	} {
+		if err := store.SaveReport(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rr := get("/api/reports")
+	var resp struct {
+		Reports []storage.Report `json:"reports"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Reports) != 2 || resp.Reports[0].ID != "2026-W42" {
+		t.Fatalf("expected the latest report first, got %s", rr.Body.String())
+	}
+
+	rr = get("/api/reports/2026-W42")
+	var report storage.Report
+	if err := json.Unmarshal(rr.Body.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	if rr.Code != http.StatusOK || report.Sessions != 4 || report.Text != "four sessions" {
+		t.Fatalf("expected the W42 report, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if rr := get("/api/reports/2025-W01"); rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for a missing report, got %d", rr.Code)
+	}
+}
+
+func TestSpeakerMergeResummarizesWithNames(t *testing.T) {
+	store := speakerTestStore(t)
+	type call struct {
+		names  map[int]string
+		preset string
+	}
+	called := make(chan call, 1)
+	h, err := Handler(testStaticFS(t), NewHub(), store, ControlHooks{
+		Resummarize: func(ctx context.Context, sessionID, preset string) error {
+			called <- call{names: session.SpeakerNames(ctx), preset: preset}
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/sessions/s1/speakers/merge",
+		strings.NewReader(`{"from":2,"into":0,"resummarize":true,"preset":"detailed","names":{"0":"Alice","1":"Bob"}}`))
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if got := segmentSpeakers(t, store); !reflect.DeepEqual(got, []int{0, 1, 0, 0, 1}) {
+		t.Fatalf("unexpected speakers after merge: %v", got)
+	}
+
+	select {
+	case got := <-called:
+		if got.preset != "detailed" || got.names[0] != "Alice" || got.names[1] != "Bob" {
+			t.Fatalf("unexpected resummarize call %+v", got)
+		}
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("expected resummarize to be called")
+	}
+
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/sessions/s1/speakers/merge", strings.NewReader(`{"from":2,"into":0}`)))
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 merging a speaker with no segments, got %d", rr.Code)
+	}
+}
+
+func TestContextToday(t *testing.T) {
+	start := time.Date(2026, 3, 20, 9, 0, 0, 0, time.UTC)
+	end := start.Add(45 * time.Minute)
+	store := apiStoreStub{
+		sessionsByDate: map[string][]storage.Session{"2026-03-20": {
+			{ID: "s2", StartedAt: start.Add(2 * time.Hour), Status: "active"},
+			{ID: "s1", StartedAt: start, EndedAt: &end, Summary: "# Roadmap sync\n\n## Key points\n- Launch moved to May\n1. Hiring paused\n"},
+		}},
+		decisions: []storage.Decision{{SessionID: "s1", Text: "Adopt SQLite"}},
+		actionItems: map[string][]storage.ActionItem{"s1": {
+			{Text: "Send the deck", Owner: "Alice"},
+			{Text: "Book the room", Done: true},
+		}},
+	}
+	h, err := Handler(testStaticFS(t), NewHub(), store, ControlHooks{})
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/context/today.md?date=2026-03-20", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if got := rr.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/markdown") {
+		t.Fatalf("expected markdown content-type, got %q", got)
+	}
+	want := "# Meetings on 2026-03-20\n\n" +
+		"## 09:00–09:45 Roadmap sync\n" +
+		"- [ ] Send the deck (Alice)\n" +
+		"- Decided: Adopt SQLite\n" +
+		"- Launch moved to May\n" +
+		"- Hiring paused\n\n" +
+		"## 11:00 Meeting (in progress)\n" +
+		"No summary yet.\n"
+	if body := rr.Body.String(); body != want {
+		t.Fatalf("unexpected context:\n%s", body)
+	}
+}
+
+func TestContextTodayBudget(t *testing.T) {
+	var points strings.Builder
+	for i := range 200 {
+		fmt.Fprintf(&points, "- Point %d about something that was discussed at length\n", i)
+	}
+	start := time.Date(2026, 3, 20, 9, 0, 0, 0, time.UTC)
+	store := apiStoreStub{sessionsByDate: map[string][]storage.Session{"2026-03-20": {
+		{ID: "s1", StartedAt: start, Summary: points.String()},
+		{ID: "s2", StartedAt: start.Add(time.Hour), Summary: points.String()},
+	}}}
+	h, err := Handler(testStaticFS(t), NewHub(), store, ControlHooks{})
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/context/today.md?date=2026-03-20&max_tokens=500", nil))
+	body := rr.Body.String()
+	if len(body) > 500*summary.CharsPerToken {
+		t.Fatalf("expected at most %d bytes, got %d", 500*summary.CharsPerToken, len(body))
+	}
+	if !strings.Contains(body, "## 10:00 Meeting") || strings.Count(body, "more\n") != 2 {
+		t.Fatalf("expected both meetings, each truncated:\n%s", body)
+	}
+
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/context/today.md?max_tokens=10", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a tiny budget, got %d", rr.Code)
+	}
+}
+
+func TestSessionLock(t *testing.T) {
+	store := speakerTestStore(t)
+	resummarized := make(chan string, 1)
+	h, err := Handler(testStaticFS(t), NewHub(), store, ControlHooks{
+		Resummarize: func(_ context.Context, sessionID, _ string) error {
+			resummarized <- sessionID
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+	post := func(path, body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+		return rr
+	}
+
+	if rr := post("/api/sessions/s1/lock", ""); rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"locked":true`) {
+		t.Fatalf("expected lock to succeed, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if sess, _ := store.GetSession("s1"); !sess.Locked {
+		t.Fatal("expected session locked in the store")
+	}
+	if rr := post("/api/sessions/s1/resummarize", ""); rr.Code != http.StatusConflict {
+		t.Fatalf("expected resummarize of a locked session to conflict, got %d", rr.Code)
+	}
+	if rr := post("/api/sessions/s1/speakers/merge", `{"from":2,"into":0}`); rr.Code != http.StatusConflict || !strings.Contains(rr.Body.String(), "locked") {
+		t.Fatalf("expected speaker edit of a locked session to conflict, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	if rr := post("/api/sessions/s1/lock", `{"locked":false}`); rr.Code != http.StatusOK {
+		t.Fatalf("expected unlock to succeed, got %d", rr.Code)
+	}
+	if rr := post("/api/sessions/s1/resummarize", ""); rr.Code != http.StatusAccepted {
+		t.Fatalf("expected resummarize after unlocking, got %d", rr.Code)
+	}
+	if got := <-resummarized; got != "s1" {
+		t.Fatalf("expected s1 resummarized, got %q", got)
+	}
+
+	if rr := post("/api/sessions/missing/lock", ""); rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for a missing session, got %d", rr.Code)
+	}
+}
+
+func TestDeleteSessionPurges(t *testing.T) {
+	var purged []string
+	controls := ControlHooks{PurgeSession: func(_ context.Context, sessionID string) (session.PurgeReport, error) {
+		switch sessionID {
+		case "missing":
+			return session.PurgeReport{}, sql.ErrNoRows
+		case "locked":
+			return session.PurgeReport{}, fmt.Errorf("delete session locked: %w", storage.ErrSessionLocked)
+		case "live":
+			return session.PurgeReport{}, session.ErrSessionRecording
+		}
+		purged = append(purged, sessionID)
+		return session.PurgeReport{SessionID: sessionID, Files: []string{"/audio/s1.mp3"}, Kept: []string{"Slack message posted to #team by export rule \"team\""}}, nil
+	}}
+	h, err := Handler(testStaticFS(t), NewHub(), apiStoreStub{}, controls)
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+	del := func(id string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, "/api/sessions/"+id, nil))
+		return rr
+	}
+
+	rr := del("s1")
+	var report session.PurgeReport
+	if err := json.Unmarshal(rr.Body.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	if rr.Code != http.StatusOK || len(purged) != 1 || len(report.Files) != 1 || len(report.Kept) != 1 {
+		t.Fatalf("expected the purge report, got %d: %s", rr.Code, rr.Body.String())
+	}
+	for id, want := range map[string]int{"missing": http.StatusNotFound, "locked": http.StatusConflict, "live": http.StatusConflict} {
+		if rr := del(id); rr.Code != want {
+			t.Errorf("%s: expected %d, got %d: %s", id, want, rr.Code, rr.Body.String())
+		}
+	}
+
+	h, err = Handler(testStaticFS(t), NewHub(), apiStoreStub{}, ControlHooks{})
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, "/api/sessions/s1", nil))
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 without purging configured, got %d", rr.Code)
+	}
+}
+
+func TestConfigSchema(t *testing.T) {
+	h, err := Handler(testStaticFS(t), NewHub(), apiStoreStub{}, ControlHooks{})
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/config/schema", nil))
+	var schema struct {
+		Schema     string `json:"$schema"`
+		Properties map[string]struct {
+			Type            string `json:"type"`
+			Env             string `json:"x-env"`
+			RestartRequired *bool  `json:"x-restart-required"`
+		} `json:"properties"`
+		Secrets []config.Secret `json:"x-secrets"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &schema); err != nil {
+		t.Fatal(err)
+	}
+	locale := schema.Properties["locale"]
+	if rr.Code != http.StatusOK || schema.Schema != config.SchemaDialect || locale.Type != "string" || locale.Env != config.EnvPrefix+"LOCALE" || locale.RestartRequired == nil || !*locale.RestartRequired {
+		t.Fatalf("unexpected schema, %d: %s", rr.Code, rr.Body.String())
+	}
+	if len(schema.Secrets) == 0 {
+		t.Fatal("expected the environment-only secrets listed")
+	}
+}
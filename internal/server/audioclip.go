@@ -0,0 +1,383 @@
+package server
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// errClipPastEnd is returned when a clip starts after the audio ends.
+var errClipPastEnd = errors.New("start is past the end of the audio")
+
+// errNeedsFFmpeg is returned when a clip can only be cut by re-encoding and
+// ffmpeg isn't installed.
+var errNeedsFFmpeg = errors.New("clipping this audio format needs ffmpeg")
+
+// parseClipWindow reads the start and end query parameters, in seconds.
+// A missing end runs to the end of the audio, returned as +Inf.
+func parseClipWindow(r *http.Request) (start, end float64, err error) {
+	q := r.URL.Query()
+	end = math.Inf(1)
+	if v := q.Get("start"); v != "" {
+		if start, err = strconv.ParseFloat(v, 64); err != nil || start < 0 || math.IsInf(start, 0) || math.IsNaN(start) {
+			return 0, 0, errors.New("start must be a non-negative number of seconds")
+		}
+	}
+	if v := q.Get("end"); v != "" {
+		if end, err = strconv.ParseFloat(v, 64); err != nil || end <= start || math.IsNaN(end) {
+			return 0, 0, errors.New("end must be a number of seconds after start")
+		}
+	}
+	return start, end, nil
+}
+
+// serveAudioClip serves the stretch of a session's audio between start and
+// end seconds. WAV is sliced exactly and constant-bitrate MP3 by mapping
+// the times to frame-aligned byte offsets, so both still support range
+// requests; anything else is trimmed on the fly with ffmpeg.
+func serveAudioClip(w http.ResponseWriter, r *http.Request, path string, start, end float64) {
+	cleanPath, ok := cleanAudioPath(path)
+	if !ok {
+		writeJSONError(w, http.StatusForbidden, "invalid audio path")
+		return
+	}
+	f, err := os.Open(cleanPath)
+	if err != nil {
+		writeJSONError(w, http.StatusNotFound, "audio file not found")
+		return
+	}
+	defer func() { _ = f.Close() }()
+	info, err := f.Stat()
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("stat audio: %v", err))
+		return
+	}
+
+	clip, err := cutClip(r, f, info.Size(), start, end)
+	if err != nil {
+		writeJSONError(w, clipErrorStatus(err), fmt.Sprintf("clip audio: %v", err))
+		return
+	}
+
+	contentType := contentTypeForAudio(cleanPath)
+	if ext := filepath.Ext(cleanPath); ext == ".m4a" || ext == ".aac" {
+		contentType = "audio/aac"
+	}
+	w.Header().Set("Accept-Ranges", "bytes")
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.Header().Set("Content-Type", contentType)
+	http.ServeContent(w, r, filepath.Base(cleanPath), info.ModTime(), clip)
+}
+
+// cutClip is the stretch of the audio in f, of size bytes, between start
+// and end seconds.
+func cutClip(r *http.Request, f *os.File, size int64, start, end float64) (io.ReadSeeker, error) {
+	var clip io.ReadSeeker
+	var err error
+	switch filepath.Ext(f.Name()) {
+	case ".wav":
+		clip, err = wavClip(f, size, start, end)
+	case ".mp3":
+		clip, err = mp3Clip(f, size, start, end)
+	}
+	if clip == nil && err == nil {
+		clip, err = ffmpegClip(r, f.Name(), start, end)
+	}
+	return clip, err
+}
+
+// clipErrorStatus is the HTTP status for an error cutting a clip.
+func clipErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, errClipPastEnd):
+		return http.StatusRequestedRangeNotSatisfiable
+	case errors.Is(err, errNeedsFFmpeg):
+		return http.StatusNotImplemented
+	case errors.Is(err, os.ErrNotExist):
+		return http.StatusNotFound
+	case errors.Is(err, os.ErrExist):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// wavClip is a WAV file holding the samples of f between start and end.
+func wavClip(f *os.File, size int64, start, end float64) (io.ReadSeeker, error) {
+	head := make([]byte, min(size, 4096))
+	if _, err := f.ReadAt(head, 0); err != nil {
+		return nil, fmt.Errorf("read wav header: %w", err)
+	}
+	if len(head) < 12 || string(head[:4]) != "RIFF" || string(head[8:12]) != "WAVE" {
+		return nil, nil
+	}
+
+	var format []byte
+	for off := 12; off+8 <= len(head); {
+		id, chunk := string(head[off:off+4]), int(binary.LittleEndian.Uint32(head[off+4:]))
+		body := off + 8
+		switch id {
+		case "fmt ":
+			if chunk < 16 || body+16 > len(head) {
+				return nil, nil
+			}
+			format = head[body : body+16]
+		case "data":
+			if format == nil {
+				return nil, nil
+			}
+			byteRate := int64(binary.LittleEndian.Uint32(format[8:]))
+			block := int64(binary.LittleEndian.Uint16(format[12:]))
+			if byteRate == 0 || block == 0 {
+				return nil, nil
+			}
+			dataEnd := min(int64(body)+int64(chunk), size)
+			from := int64(body) + int64(start*float64(byteRate))/block*block
+			if from >= dataEnd {
+				return nil, errClipPastEnd
+			}
+			to := dataEnd
+			if !math.IsInf(end, 1) {
+				to = min(int64(body)+int64(end*float64(byteRate))/block*block, dataEnd)
+			}
+
+			var header bytes.Buffer
+			header.WriteString("RIFF")
+			_ = binary.Write(&header, binary.LittleEndian, uint32(36+to-from))
+			header.WriteString("WAVEfmt ")
+			_ = binary.Write(&header, binary.LittleEndian, uint32(16))
+			header.Write(format)
+			header.WriteString("data")
+			_ = binary.Write(&header, binary.LittleEndian, uint32(to-from))
+			return &prefixedReader{head: header.Bytes(), body: io.NewSectionReader(f, from, to-from)}, nil
+		}
+		off = body + chunk + chunk%2
+	}
+	return nil, nil
+}
+
+// mp3Frame is what a clip needs from an MP3 frame header.
+type mp3Frame struct {
+	version    int // 1, 2, or 25 for MPEG 2.5
+	bitrate    int // bits per second
+	sampleRate int
+	length     int
+	mono       bool
+}
+
+var (
+	mp3Bitrates1 = [16]int{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320}
+	mp3Bitrates2 = [16]int{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160}
+	mp3Rates     = [3]int{44100, 48000, 32000}
+)
+
+// parseMP3Frame parses a Layer III frame header.
+func parseMP3Frame(h []byte) (mp3Frame, bool) {
+	if len(h) < 4 || h[0] != 0xFF || h[1]&0xE0 != 0xE0 || (h[1]>>1)&3 != 1 {
+		return mp3Frame{}, false
+	}
+	var fr mp3Frame
+	switch (h[1] >> 3) & 3 {
+	case 0:
+		fr.version = 25
+	case 2:
+		fr.version = 2
+	case 3:
+		fr.version = 1
+	default:
+		return mp3Frame{}, false
+	}
+	bitrateIndex, rateIndex := h[2]>>4, (h[2]>>2)&3
+	if bitrateIndex == 0 || bitrateIndex == 15 || rateIndex == 3 {
+		return mp3Frame{}, false
+	}
+	fr.sampleRate = mp3Rates[rateIndex]
+	perFrame := 144
+	if fr.version == 1 {
+		fr.bitrate = mp3Bitrates1[bitrateIndex] * 1000
+	} else {
+		fr.bitrate = mp3Bitrates2[bitrateIndex] * 1000
+		fr.sampleRate /= 2
+		perFrame = 72
+	}
+	if fr.version == 25 {
+		fr.sampleRate /= 2
+	}
+	fr.length = perFrame*fr.bitrate/fr.sampleRate + int((h[2]>>1)&1)
+	fr.mono = h[3]>>6 == 3
+	return fr, true
+}
+
+// mp3Clip maps start and end onto byte offsets of a constant-bitrate MP3.
+// It returns nil for variable-bitrate files, whose offsets can't be
+// computed without an index.
+func mp3Clip(f *os.File, size int64, start, end float64) (io.ReadSeeker, error) {
+	head := make([]byte, min(size, 64*1024))
+	if _, err := f.ReadAt(head, 0); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("read mp3 header: %w", err)
+	}
+	off := 0
+	if len(head) >= 10 && string(head[:3]) == "ID3" {
+		off = 10 + (int(head[6])<<21 | int(head[7])<<14 | int(head[8])<<7 | int(head[9]))
+	}
+	first, ok := findMP3Frame(head, off, nil)
+	if !ok {
+		return nil, nil
+	}
+	fr, _ := parseMP3Frame(head[first:])
+
+	// A Xing tag marks a variable-bitrate file; LAME writes an Info tag in
+	// the same place for constant bitrate, in a frame with no audio.
+	side := 32
+	switch {
+	case fr.version == 1 && fr.mono, fr.version != 1 && !fr.mono:
+		side = 17
+	case fr.version != 1 && fr.mono:
+		side = 9
+	}
+	if tag := first + 4 + side; tag+4 <= len(head) {
+		switch string(head[tag : tag+4]) {
+		case "Xing":
+			return nil, nil
+		case "Info":
+			first += fr.length
+		}
+	}
+
+	bytesPerSecond := float64(fr.bitrate) / 8
+	from := int64(first) + int64(start*bytesPerSecond)
+	if from >= size {
+		return nil, errClipPastEnd
+	}
+	// Start on a frame boundary so the first frame decodes.
+	window := make([]byte, min(8*1024, size-from))
+	if _, err := f.ReadAt(window, from); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("read mp3 frames: %w", err)
+	}
+	skip, ok := findMP3Frame(window, 0, &fr)
+	if !ok {
+		return nil, errClipPastEnd
+	}
+	from += int64(skip)
+	to := size
+	if !math.IsInf(end, 1) {
+		to = min(int64(first)+int64(end*bytesPerSecond), size)
+	}
+	if to <= from {
+		return nil, errClipPastEnd
+	}
+	return io.NewSectionReader(f, from, to-from), nil
+}
+
+// findMP3Frame returns the offset of the first frame header in b at or
+// after off. With like set, it only matches frames of the same stream, and
+// a match must be followed by another frame where the buffer allows, so
+// stray sync bits in audio data aren't taken for a header.
+func findMP3Frame(b []byte, off int, like *mp3Frame) (int, bool) {
+	for i := max(off, 0); i+4 <= len(b); i++ {
+		fr, ok := parseMP3Frame(b[i:])
+		if !ok {
+			continue
+		}
+		if like != nil && (fr.version != like.version || fr.sampleRate != like.sampleRate) {
+			continue
+		}
+		if next := i + fr.length; next+4 <= len(b) {
+			if _, ok := parseMP3Frame(b[next:]); !ok {
+				continue
+			}
+		}
+		return i, true
+	}
+	return 0, false
+}
+
+// ffmpegClip trims audio with ffmpeg, copying the codec where the format
+// allows.
+func ffmpegClip(r *http.Request, path string, start, end float64) (io.ReadSeeker, error) {
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		return nil, errNeedsFFmpeg
+	}
+	args := []string{"-nostdin", "-v", "error", "-ss", formatSeconds(start)}
+	if !math.IsInf(end, 1) {
+		args = append(args, "-t", formatSeconds(end-start))
+	}
+	args = append(args, "-i", path)
+	switch filepath.Ext(path) {
+	case ".flac":
+		args = append(args, "-f", "flac")
+	case ".m4a", ".aac":
+		// MP4 needs a seekable output, so clips are re-wrapped as ADTS.
+		args = append(args, "-c:a", "copy", "-f", "adts")
+	case ".ogg", ".opus":
+		args = append(args, "-c:a", "copy", "-f", "ogg")
+	case ".webm":
+		args = append(args, "-c:a", "copy", "-f", "webm")
+	default:
+		args = append(args, "-c:a", "copy", "-f", strings.TrimPrefix(filepath.Ext(path), "."))
+	}
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(r.Context(), "ffmpeg", append(args, "-")...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("ffmpeg: %v: %s", err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	if len(out) == 0 {
+		return nil, errClipPastEnd
+	}
+	return bytes.NewReader(out), nil
+}
+
+func formatSeconds(s float64) string {
+	return strconv.FormatFloat(s, 'f', 3, 64)
+}
+
+// prefixedReader reads head and then body as one seekable stream.
+type prefixedReader struct {
+	head []byte
+	body *io.SectionReader
+	pos  int64
+}
+
+func (p *prefixedReader) size() int64 { return int64(len(p.head)) + p.body.Size() }
+
+func (p *prefixedReader) Read(b []byte) (int, error) {
+	if p.pos < int64(len(p.head)) {
+		n := copy(b, p.head[p.pos:])
+		p.pos += int64(n)
+		return n, nil
+	}
+	n, err := p.body.ReadAt(b, p.pos-int64(len(p.head)))
+	p.pos += int64(n)
+	if errors.Is(err, io.EOF) && n > 0 {
+		err = nil
+	}
+	return n, err
+}
+
+func (p *prefixedReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += p.pos
+	case io.SeekEnd:
+		offset += p.size()
+	default:
+		return 0, errors.New("invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("negative position")
+	}
+	p.pos = offset
+	return offset, nil
+}
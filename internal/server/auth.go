@@ -0,0 +1,241 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Scope is what an API token may do. Each scope includes the ones below it.
+type Scope int
+
+const (
+	ScopeNone Scope = iota
+	// ScopeRead views sessions, transcripts, status and the live stream.
+	ScopeRead
+	// ScopeControl also pauses, resumes, ends sessions and adds bookmarks.
+	ScopeControl
+	// ScopeAdmin also downloads exports and deletes data.
+	ScopeAdmin
+)
+
+// ParseScope maps a config scope name to a Scope.
+func ParseScope(name string) (Scope, bool) {
+	switch name {
+	case "read":
+		return ScopeRead, true
+	case "control":
+		return ScopeControl, true
+	case "admin":
+		return ScopeAdmin, true
+	}
+	return ScopeNone, false
+}
+
+func (s Scope) String() string {
+	switch s {
+	case ScopeRead:
+		return "read"
+	case ScopeControl:
+		return "control"
+	case ScopeAdmin:
+		return "admin"
+	}
+	return "none"
+}
+
+// Token is a bearer token granted a scope. Name identifies it in the access
+// log so the secret itself is never written out.
+type Token struct {
+	Name   string
+	Scope  Scope
+	Secret string
+}
+
+// tokenCookie carries the token for browsers, which cannot set headers on
+// <audio> or WebSocket requests.
+const tokenCookie = "ghost_wispr_token"
+
+// Auth requires a token on every API, GraphQL and WebSocket request and
+// records each one in an access log.
+type Auth struct {
+	tokens []Token
+
+	mu  sync.Mutex
+	log io.Writer
+}
+
+// NewAuth returns an Auth accepting tokens and writing JSON lines to
+// accessLog, which may be nil.
+func NewAuth(tokens []Token, accessLog io.Writer) *Auth {
+	return &Auth{tokens: tokens, log: accessLog}
+}
+
+// accessEntry is one line of the access log.
+type accessEntry struct {
+	Time   time.Time `json:"time"`
+	Token  string    `json:"token"`
+	Method string    `json:"method"`
+	Path   string    `json:"path"`
+	Status int       `json:"status"`
+	Remote string    `json:"remote"`
+}
+
+type tokenContextKey struct{}
+
+// requestToken returns the token that authenticated the request, if any.
+func requestToken(ctx context.Context) (Token, bool) {
+	t, ok := ctx.Value(tokenContextKey{}).(Token)
+	return t, ok
+}
+
+// requestScope returns the scope of the request's token. Without auth every
+// request may do anything.
+func requestScope(ctx context.Context) Scope {
+	if t, ok := requestToken(ctx); ok {
+		return t.Scope
+	}
+	return ScopeAdmin
+}
+
+// Middleware authenticates requests to protected paths and logs them. A
+// valid ?token= also sets a cookie so the web UI keeps working once the
+// parameter is gone.
+func (a *Auth) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		need := requiredScope(r)
+		token, ok := a.authenticate(r)
+		if ok && r.URL.Query().Get("token") != "" {
+			http.SetCookie(w, &http.Cookie{
+				Name:     tokenCookie,
+				Value:    token.Secret,
+				Path:     "/",
+				HttpOnly: true,
+				SameSite: http.SameSiteStrictMode,
+			})
+		}
+		if need == ScopeNone {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		defer func() { a.record(token.Name, r.Method, r.URL.Path, rec.status, r.RemoteAddr) }()
+
+		switch {
+		case !ok:
+			rec.Header().Set("WWW-Authenticate", `Bearer realm="ghost-wispr"`)
+			writeJSONError(rec, http.StatusUnauthorized, "missing or invalid token")
+			return
+		case token.Scope < need:
+			writeJSONError(rec, http.StatusForbidden, fmt.Sprintf("token %q lacks %s scope", token.Name, need))
+			return
+		}
+		next.ServeHTTP(rec, r.WithContext(context.WithValue(r.Context(), tokenContextKey{}, token)))
+	})
+}
+
+// authenticate finds the token presented in the Authorization header, the
+// token query parameter or the token cookie.
+func (a *Auth) authenticate(r *http.Request) (Token, bool) {
+	secret := ""
+	if h := r.Header.Get("Authorization"); strings.HasPrefix(h, "Bearer ") {
+		secret = strings.TrimSpace(strings.TrimPrefix(h, "Bearer "))
+	} else if q := r.URL.Query().Get("token"); q != "" {
+		secret = q
+	} else if c, err := r.Cookie(tokenCookie); err == nil {
+		secret = c.Value
+	}
+	if secret == "" {
+		return Token{}, false
+	}
+	for _, t := range a.tokens {
+		if subtle.ConstantTimeCompare([]byte(t.Secret), []byte(secret)) == 1 {
+			return t, true
+		}
+	}
+	return Token{}, false
+}
+
+// requiredScope is the scope a request needs. The SPA's static assets are
+// public; the data behind them is not.
+func requiredScope(r *http.Request) Scope {
+	p := r.URL.Path
+	switch {
+	case p == "/ws/mic":
+		return ScopeControl
+	case p == "/ws", p == "/graphql", p == "/metrics", p == "/overlay":
+		return ScopeRead
+	case !strings.HasPrefix(p, "/api/"):
+		return ScopeNone
+	case r.Method == http.MethodDelete,
+		strings.HasPrefix(p, "/api/export/"),
+		strings.HasPrefix(p, "/api/admin/"),
+		p == "/api/decisions/export":
+		return ScopeAdmin
+	case r.Method == http.MethodGet, r.Method == http.MethodHead:
+		return ScopeRead
+	case r.Method == http.MethodPost && strings.HasPrefix(p, "/api/sessions/") && strings.HasSuffix(p, "/viewed"):
+		// Reading a session is all marking it viewed records.
+		return ScopeRead
+	}
+	return ScopeControl
+}
+
+// record appends one access log line. Unauthenticated requests are logged
+// with token "-". A nil Auth records nothing.
+func (a *Auth) record(token, method, path string, status int, remote string) {
+	if a == nil || a.log == nil {
+		return
+	}
+	if token == "" {
+		token = "-"
+	}
+	line, err := json.Marshal(accessEntry{
+		Time:   time.Now().UTC(),
+		Token:  token,
+		Method: method,
+		Path:   path,
+		Status: status,
+		Remote: remote,
+	})
+	if err != nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	_, _ = a.log.Write(append(line, '\n'))
+}
+
+// statusRecorder captures the response status for the access log.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	s.status = status
+	s.ResponseWriter.WriteHeader(status)
+}
+
+func (s *statusRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
+
+// Hijack lets /ws upgrade through the recorder.
+func (s *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := s.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer does not support hijacking")
+	}
+	s.status = http.StatusSwitchingProtocols
+	return h.Hijack()
+}
@@ -0,0 +1,140 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+func authTestServer(t *testing.T, accessLog io.Writer) *httptest.Server {
+	t.Helper()
+	controls := ControlHooks{
+		Auth: NewAuth([]Token{
+			{Name: "wall", Scope: ScopeRead, Secret: "read-secret"},
+			{Name: "desk", Scope: ScopeControl, Secret: "control-secret"},
+			{Name: "laptop", Scope: ScopeAdmin, Secret: "admin-secret"},
+		}, accessLog),
+	}
+	h, err := Handler(testStaticFS(t), NewHub(), exportStoreStub(), controls)
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestAuthEnforcesTokenScopes(t *testing.T) {
+	var accessLog bytes.Buffer
+	srv := authTestServer(t, &accessLog)
+
+	tests := []struct {
+		method, path, token string
+		want                int
+	}{
+		{http.MethodGet, "/", "", http.StatusOK},
+		{http.MethodGet, "/api/dates", "", http.StatusUnauthorized},
+		{http.MethodGet, "/api/dates", "wrong", http.StatusUnauthorized},
+		{http.MethodGet, "/api/dates", "read-secret", http.StatusOK},
+		{http.MethodPost, "/api/pause", "read-secret", http.StatusForbidden},
+		{http.MethodPost, "/api/pause", "control-secret", http.StatusNoContent},
+		{http.MethodGet, "/api/export/segments", "control-secret", http.StatusForbidden},
+		{http.MethodGet, "/api/decisions/export", "read-secret", http.StatusForbidden},
+		{http.MethodGet, "/api/decisions/export", "admin-secret", http.StatusOK},
+		{http.MethodPost, "/api/admin/keys", "control-secret", http.StatusForbidden},
+		{http.MethodGet, "/ws/mic", "read-secret", http.StatusForbidden},
+		{http.MethodGet, "/ws/mic", "control-secret", http.StatusServiceUnavailable},
+		{http.MethodPost, "/api/sessions/early/viewed", "read-secret", http.StatusNotFound},
+	}
+	for _, tc := range tests {
+		req, _ := http.NewRequest(tc.method, srv.URL+tc.path, nil)
+		if tc.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s failed: %v", tc.method, tc.path, err)
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != tc.want {
+			t.Errorf("%s %s with %q: expected %d, got %d", tc.method, tc.path, tc.token, tc.want, resp.StatusCode)
+		}
+	}
+
+	lines := strings.Split(strings.TrimSpace(accessLog.String()), "\n")
+	if len(lines) != len(tests)-1 {
+		t.Fatalf("expected one access log line per API request, got %d:\n%s", len(lines), accessLog.String())
+	}
+	var entry accessEntry
+	if err := json.Unmarshal([]byte(lines[4]), &entry); err != nil {
+		t.Fatalf("decode access log: %v", err)
+	}
+	if entry.Token != "desk" || entry.Method != http.MethodPost || entry.Path != "/api/pause" || entry.Status != http.StatusNoContent {
+		t.Fatalf("unexpected access log entry: %+v", entry)
+	}
+	if strings.Contains(accessLog.String(), "secret") {
+		t.Fatalf("access log leaked a token secret:\n%s", accessLog.String())
+	}
+}
+
+func TestAuthQueryTokenSetsCookie(t *testing.T) {
+	srv := authTestServer(t, nil)
+
+	resp, err := http.Get(srv.URL + "/?token=read-secret")
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	_ = resp.Body.Close()
+	cookies := resp.Cookies()
+	if len(cookies) != 1 || cookies[0].Name != tokenCookie || !cookies[0].HttpOnly {
+		t.Fatalf("expected an HttpOnly token cookie, got %+v", cookies)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/api/status", nil)
+	req.AddCookie(cookies[0])
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET status failed: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected cookie to authenticate, got %d", resp.StatusCode)
+	}
+}
+
+func TestAuthReadTokenCannotSendWSCommands(t *testing.T) {
+	var accessLog bytes.Buffer
+	srv := authTestServer(t, &accessLog)
+
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws"
+	if _, _, err := websocket.DefaultDialer.Dial(url, nil); err == nil {
+		t.Fatal("expected /ws without a token to be refused")
+	}
+
+	conn, _, err := websocket.DefaultDialer.Dial(url+"?token=read-secret", nil)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+	readEvent(t, conn) // connection
+	if presence := readEvent(t, conn); presence["viewers"].([]any)[0].(map[string]any)["user"] != "wall" {
+		t.Fatalf("expected the token name as viewer, got %v", presence)
+	}
+
+	if err := conn.WriteJSON(map[string]any{"id": "1", "command": "pause"}); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	ack := readEvent(t, conn)
+	if ack["type"] != "ack" || ack["ok"] != false || ack["error"] != "token lacks control scope" {
+		t.Fatalf("expected a refused ack, got %v", ack)
+	}
+	if !strings.Contains(accessLog.String(), `"path":"/ws#pause","status":403`) {
+		t.Fatalf("expected the refused command in the access log:\n%s", accessLog.String())
+	}
+}
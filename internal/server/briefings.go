@@ -0,0 +1,66 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+)
+
+func registerBriefingRoutes(mux *http.ServeMux, controls ControlHooks) {
+	mux.HandleFunc("POST /api/briefings", func(w http.ResponseWriter, r *http.Request) {
+		if controls.CreateBriefing == nil {
+			writeJSONError(w, http.StatusServiceUnavailable, "briefings not available")
+			return
+		}
+		var body struct {
+			Title     string    `json:"title"`
+			Attendees []string  `json:"attendees"`
+			StartsAt  time.Time `json:"starts_at"`
+			EndsAt    time.Time `json:"ends_at"`
+		}
+		if err := json.NewDecoder(io.LimitReader(r.Body, 64<<10)).Decode(&body); err != nil {
+			writeJSONError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		if strings.TrimSpace(body.Title) == "" && len(body.Attendees) == 0 {
+			writeJSONError(w, http.StatusBadRequest, "title or attendees required")
+			return
+		}
+		if body.StartsAt.IsZero() {
+			writeJSONError(w, http.StatusBadRequest, "starts_at required")
+			return
+		}
+		if !body.EndsAt.IsZero() && !body.EndsAt.After(body.StartsAt) {
+			writeJSONError(w, http.StatusBadRequest, "ends_at must be after starts_at")
+			return
+		}
+
+		b, err := controls.CreateBriefing(r.Context(), body.Title, body.Attendees, body.StartsAt, body.EndsAt)
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("create briefing: %v", err))
+			return
+		}
+		writeJSON(w, http.StatusCreated, b)
+	})
+
+	mux.HandleFunc("GET /api/briefings", func(w http.ResponseWriter, r *http.Request) {
+		if controls.Briefings == nil {
+			writeJSONError(w, http.StatusServiceUnavailable, "briefings not available")
+			return
+		}
+		briefings, err := controls.Briefings()
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("list briefings: %v", err))
+			return
+		}
+		if briefings == nil {
+			briefings = []storage.Briefing{}
+		}
+		writeJSON(w, http.StatusOK, briefings)
+	})
+}
@@ -0,0 +1,111 @@
+package server
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/websocket"
+)
+
+// Errors returned by ControlHooks.BrowserMic.
+var (
+	ErrBrowserMicBusy        = errors.New("another browser is already sending audio")
+	ErrBrowserMicUnavailable = errors.New("the server is capturing audio itself")
+)
+
+// BrowserMicStatus is reported under browser_mic in /api/status.
+type BrowserMicStatus struct {
+	// Enabled is set when audio is captured from a browser rather than a
+	// device on the server.
+	Enabled bool `json:"enabled"`
+	// Feeding is set while a browser is sending its microphone.
+	Feeding bool `json:"feeding"`
+}
+
+// Sample rates a browser may send at, covering every rate an AudioContext
+// supports.
+const (
+	browserMicDefaultRate = 48000
+	browserMicMinRate     = 8000
+	browserMicMaxRate     = 192000
+)
+
+// registerBrowserMicRoute accepts microphone audio captured by the web UI
+// as binary websocket frames of mono PCM16-LE at ?sample_rate=, and feeds
+// it to the recorder and live transcription like a local microphone.
+func registerBrowserMicRoute(mux *http.ServeMux, controls ControlHooks) {
+	upgrader := newUpgrader(controls.AllowedOrigins)
+	mux.HandleFunc("GET /ws/mic", func(w http.ResponseWriter, r *http.Request) {
+		if controls.BrowserMic == nil {
+			writeJSONError(w, http.StatusServiceUnavailable, "browser microphone not available")
+			return
+		}
+		// Checked before claiming the microphone, which another site's
+		// page must not even hold.
+		if !upgrader.CheckOrigin(r) {
+			writeJSONError(w, http.StatusForbidden, "origin not allowed")
+			return
+		}
+		if format := r.URL.Query().Get("format"); format != "" && format != "pcm16" {
+			writeJSONError(w, http.StatusUnsupportedMediaType, "format must be pcm16")
+			return
+		}
+		rate := browserMicDefaultRate
+		if v := r.URL.Query().Get("sample_rate"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < browserMicMinRate || n > browserMicMaxRate {
+				writeJSONError(w, http.StatusBadRequest, "sample_rate must be 8000-192000")
+				return
+			}
+			rate = n
+		}
+
+		feed, err := controls.BrowserMic(rate)
+		switch {
+		case errors.Is(err, ErrBrowserMicBusy):
+			writeJSONError(w, http.StatusConflict, err.Error())
+			return
+		case errors.Is(err, ErrBrowserMicUnavailable):
+			writeJSONError(w, http.StatusServiceUnavailable, err.Error())
+			return
+		case err != nil:
+			writeJSONError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		defer func() { _ = feed.Close() }()
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			log.Printf("ws/mic upgrade error: %v", err)
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		conn.SetReadLimit(1 << 20)
+
+		if err := readBrowserMic(conn, feed); err != nil {
+			log.Printf("browser microphone stopped: %v", err)
+		}
+	})
+}
+
+// readBrowserMic copies binary frames to feed until the browser hangs up.
+func readBrowserMic(conn *websocket.Conn, feed io.Writer) error {
+	for {
+		kind, data, err := conn.ReadMessage()
+		if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if kind != websocket.BinaryMessage {
+			continue
+		}
+		if _, err := feed.Write(data); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package server
+
+import (
+	"archive/zip"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sjawhar/ghost-wispr/internal/bundle"
+	"github.com/sjawhar/ghost-wispr/internal/i18n"
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+	"github.com/sjawhar/ghost-wispr/internal/transcribe"
+)
+
+// registerBundleRoute serves POST /api/export/sessions/{id}/bundle: a zip of
+// the session's transcript, summary and audio, encrypted with the password
+// from the request body. `ghost-wispr decrypt` turns it back into the zip.
+func registerBundleRoute(mux *http.ServeMux, store SessionStore, controls ControlHooks) {
+	mux.HandleFunc("POST /api/export/sessions/{id}/bundle", func(w http.ResponseWriter, r *http.Request) {
+		sessionID := r.PathValue("id")
+		if !validSessionID(sessionID) {
+			writeJSONError(w, http.StatusForbidden, "invalid session id")
+			return
+		}
+
+		var body struct {
+			Password string `json:"password"`
+		}
+		if err := json.NewDecoder(io.LimitReader(r.Body, 4<<10)).Decode(&body); err != nil || body.Password == "" {
+			writeJSONError(w, http.StatusBadRequest, "request body must be {\"password\": \"...\"}")
+			return
+		}
+
+		sessionData, err := store.GetSession(sessionID)
+		if err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, os.ErrNotExist) || errors.Is(err, sql.ErrNoRows) {
+				status = http.StatusNotFound
+			}
+			writeJSONError(w, status, fmt.Sprintf("get session: %v", err))
+			return
+		}
+		segments, err := store.GetSegments(sessionID)
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("get session segments: %v", err))
+			return
+		}
+		audioFiles, err := store.GetAudioFiles(sessionID)
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("get session audio files: %v", err))
+			return
+		}
+
+		release, err := controls.Exports.Acquire(r.Context())
+		if err != nil {
+			return
+		}
+		defer release()
+
+		name := "session-" + sessionID + bundle.Extension
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+		w.Header().Set("Cache-Control", "no-store")
+		if err := writeSessionBundle(w, body.Password, sessionData, segments, storedSpeakerNames(store, sessionID), audioFiles, controls.Localizer); err != nil {
+			// Headers are already sent; truncating the body is all that's left.
+			log.Printf("bundle export for session %s failed: %v", sessionID, err)
+		}
+	})
+}
+
+// writeSessionBundle streams the encrypted zip for one session to w. The
+// primary recording is stored as audio, its kept lossless copy as
+// audio-lossless, and any other audio files as audio-<index>-<kind>.
+func writeSessionBundle(w io.Writer, password string, sess storage.Session, segments []transcribe.Segment, names map[int]string, audioFiles []storage.AudioFile, l *i18n.Localizer) error {
+	enc, err := bundle.NewWriter(w, password)
+	if err != nil {
+		return err
+	}
+	zw := zip.NewWriter(enc)
+
+	meta, err := json.MarshalIndent(sess, "", "  ")
+	if err != nil {
+		return err
+	}
+	files := [][2]string{
+		{"session.json", string(meta) + "\n"},
+		{"transcript.md", formatBundleTranscript(sess, segments, names, l)},
+	}
+	if sess.Summary != "" {
+		files = append(files, [2]string{"summary.md", sess.Summary + "\n"})
+	}
+	for _, f := range files {
+		fw, err := zw.Create(f[0])
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(fw, f[1]); err != nil {
+			return err
+		}
+	}
+
+	type bundleAudio struct{ prefix, path string }
+	audio := []bundleAudio{{"audio", sess.AudioPath}}
+	for _, f := range audioFiles {
+		if f.Index > 0 {
+			audio = append(audio, bundleAudio{fmt.Sprintf("audio-%d-%s", f.Index, f.Kind), f.Path})
+		}
+	}
+	if sess.WAVPath != sess.AudioPath {
+		audio = append(audio, bundleAudio{"audio-lossless", sess.WAVPath})
+	}
+	audio = append(audio, bundleAudio{"summary-audio", sess.SummaryAudioPath})
+	for _, a := range audio {
+		if err := addBundleAudio(zw, a.prefix, a.path); err != nil {
+			return err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// addBundleAudio stores an audio file in the zip as prefix plus its
+// extension. Missing files are skipped; audio is already compressed.
+func addBundleAudio(zw *zip.Writer, prefix, path string) error {
+	if path == "" {
+		return nil
+	}
+	cleanPath, ok := cleanAudioPath(path)
+	if !ok {
+		return nil
+	}
+	f, err := os.Open(cleanPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	fw, err := zw.CreateHeader(&zip.FileHeader{Name: prefix + filepath.Ext(cleanPath), Method: zip.Store})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(fw, f)
+	return err
+}
+
+// formatBundleTranscript renders segments as markdown with speaker labels,
+// by name where known, and offsets from the start of the session.
+func formatBundleTranscript(sess storage.Session, segments []transcribe.Segment, names map[int]string, l *i18n.Localizer) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n%s\n\n", l.T(i18n.DocSessionTitle, sess.ID), l.T(i18n.DocStarted, sess.StartedAt.UTC().Format("2006-01-02 15:04 MST")))
+	for _, seg := range segments {
+		if strings.TrimSpace(seg.Text) == "" {
+			continue
+		}
+		offset := int(seg.StartTime)
+		fmt.Fprintf(&b, "**%s** [%02d:%02d:%02d] %s\n\n", speakerLabel(l, names, seg.Speaker), offset/3600, offset/60%60, offset%60, seg.Text)
+	}
+	return b.String()
+}
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/sjawhar/ghost-wispr/internal/i18n"
+	"github.com/sjawhar/ghost-wispr/internal/transcribe"
+)
+
+const (
+	// A caption stays up no longer than maxCueSeconds and holds no more
+	// than maxCueChars, two lines' worth, so it can be read as it goes by.
+	maxCueSeconds = 6.0
+	maxCueChars   = 84
+)
+
+// cue is one caption.
+type cue struct {
+	start, end float64
+	speaker    string
+	text       string
+}
+
+// captionCues splits segments into captions. Segments with word timings
+// break between words, each caption timed by its own; those without are
+// captioned whole. Captions are timed against the recording, which starts
+// audioOffset seconds into the timeline segments are timed on.
+func captionCues(segments []transcribe.Segment, audioOffset float64, names map[int]string, l *i18n.Localizer) []cue {
+	at := func(t float64) float64 { return max(t-audioOffset, 0) }
+	var cues []cue
+	for _, seg := range segments {
+		text := strings.TrimSpace(seg.Text)
+		if text == "" {
+			continue
+		}
+		speaker := speakerLabel(l, names, seg.Speaker)
+		if len(seg.Words) == 0 {
+			cues = append(cues, cue{start: at(seg.StartTime), end: at(seg.EndTime), speaker: speaker, text: text})
+			continue
+		}
+		var words []transcribe.SegmentWord
+		flush := func() {
+			if len(words) > 0 {
+				cues = append(cues, cue{start: at(words[0].Start), end: at(words[len(words)-1].End), speaker: speaker, text: transcribe.WordsText(words)})
+				words = nil
+			}
+		}
+		for _, w := range seg.Words {
+			if len(words) > 0 && (w.End-words[0].Start > maxCueSeconds || len(transcribe.WordsText(words))+1+len(w.Word) > maxCueChars) {
+				flush()
+			}
+			words = append(words, w)
+		}
+		flush()
+	}
+	return cues
+}
+
+// writeSRT writes segments as SubRip captions, each led by its speaker.
+func writeSRT(w io.Writer, segments []transcribe.Segment, audioOffset float64, names map[int]string, l *i18n.Localizer) error {
+	for i, c := range captionCues(segments, audioOffset, names, l) {
+		if _, err := fmt.Fprintf(w, "%d\n%s --> %s\n%s: %s\n\n", i+1, captionTime(c.start, ','), captionTime(c.end, ','), c.speaker, c.text); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeVTT writes segments as WebVTT captions, with speakers as voice
+// spans.
+func writeVTT(w io.Writer, segments []transcribe.Segment, audioOffset float64, names map[int]string, l *i18n.Localizer) error {
+	if _, err := io.WriteString(w, "WEBVTT\n\n"); err != nil {
+		return err
+	}
+	for _, c := range captionCues(segments, audioOffset, names, l) {
+		speaker := strings.NewReplacer(">", "", "&", "&amp;").Replace(c.speaker)
+		text := strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(c.text)
+		if _, err := fmt.Fprintf(w, "%s --> %s\n<v %s>%s\n\n", captionTime(c.start, '.'), captionTime(c.end, '.'), speaker, text); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// captionTime formats seconds as hh:mm:ss followed by sep and milliseconds.
+func captionTime(seconds float64, sep rune) string {
+	ms := max(int64(seconds*1000+0.5), 0)
+	return fmt.Sprintf("%02d:%02d:%02d%c%03d", ms/3600000, ms/60000%60, ms/1000%60, sep, ms%1000)
+}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+)
+
+// AudioFileAdder is implemented by stores that can attach extra audio files
+// to a session.
+type AudioFileAdder interface {
+	AddAudioFile(sessionID, kind, path string) (storage.AudioFile, error)
+}
+
+func registerClipRoutes(mux *http.ServeMux, store SessionStore) {
+	mux.HandleFunc("POST /api/sessions/{id}/clips", func(w http.ResponseWriter, r *http.Request) {
+		sessionID := r.PathValue("id")
+		if !validSessionID(sessionID) {
+			writeJSONError(w, http.StatusForbidden, "invalid session id")
+			return
+		}
+		adder, ok := store.(AudioFileAdder)
+		if !ok {
+			writeJSONError(w, http.StatusNotImplemented, "saving clips is not supported by this store")
+			return
+		}
+		start, end, err := parseClipWindow(r)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		sess, err := store.GetSession(sessionID)
+		if errors.Is(err, sql.ErrNoRows) {
+			writeJSONError(w, http.StatusNotFound, "session not found")
+			return
+		}
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("get session: %v", err))
+			return
+		}
+		path, err := saveClip(r, sess.AudioPath, start, end)
+		if err != nil {
+			writeJSONError(w, clipErrorStatus(err), fmt.Sprintf("save clip: %v", err))
+			return
+		}
+		file, err := adder.AddAudioFile(sessionID, storage.AudioClip, path)
+		if err != nil {
+			_ = os.Remove(path)
+			writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("add clip: %v", err))
+			return
+		}
+		writeJSON(w, http.StatusCreated, file)
+	})
+}
+
+// saveClip writes the stretch of the recording at audioPath between start
+// and end seconds next to it, returning where.
+func saveClip(r *http.Request, audioPath string, start, end float64) (string, error) {
+	cleanPath, ok := cleanAudioPath(audioPath)
+	if !ok {
+		return "", os.ErrNotExist
+	}
+	f, err := os.Open(cleanPath)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+	info, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	clip, err := cutClip(r, f, info.Size(), start, end)
+	if err != nil {
+		return "", err
+	}
+
+	ext := filepath.Ext(cleanPath)
+	if ext == ".m4a" {
+		// ffmpeg re-wraps MP4 clips as ADTS.
+		ext = ".aac"
+	}
+	to := "end"
+	if !math.IsInf(end, 1) {
+		to = formatSeconds(end)
+	}
+	path := fmt.Sprintf("%s.clip-%s-%s%s", strings.TrimSuffix(cleanPath, filepath.Ext(cleanPath)), formatSeconds(start), to, ext)
+	out, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(out, clip); err != nil {
+		_ = out.Close()
+		_ = os.Remove(path)
+		return "", err
+	}
+	if err := out.Close(); err != nil {
+		_ = os.Remove(path)
+		return "", err
+	}
+	return path, nil
+}
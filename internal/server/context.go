@@ -0,0 +1,188 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/i18n"
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+	"github.com/sjawhar/ghost-wispr/internal/summary"
+)
+
+// Token budgets accepted by GET /api/context/today.md. Tokens are estimated
+// at summary.CharsPerToken characters each, as for cost estimates.
+const (
+	defaultContextTokens = 2000
+	minContextTokens     = 200
+	maxContextTokens     = 32000
+)
+
+func registerContextRoutes(mux *http.ServeMux, store SessionStore, l *i18n.Localizer) {
+	// today.md is working context for coding and assistant agents: a compact
+	// markdown digest of the day's meetings that fits a token budget.
+	mux.HandleFunc("GET /api/context/today.md", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		date := q.Get("date")
+		if date == "" {
+			date = time.Now().UTC().Format("2006-01-02")
+		} else if _, err := time.Parse("2006-01-02", date); err != nil {
+			writeJSONError(w, http.StatusBadRequest, "date must be YYYY-MM-DD")
+			return
+		}
+		budget := defaultContextTokens
+		if v := q.Get("max_tokens"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < minContextTokens || n > maxContextTokens {
+				writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("max_tokens must be between %d and %d", minContextTokens, maxContextTokens))
+				return
+			}
+			budget = n
+		}
+
+		sessions, err := store.GetSessionsByDate(date)
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("list sessions: %v", err))
+			return
+		}
+		sort.Slice(sessions, func(i, j int) bool { return sessions[i].StartedAt.Before(sessions[j].StartedAt) })
+		decisions, err := store.GetDecisions(storage.DecisionFilter{From: date, To: date})
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("get decisions: %v", err))
+			return
+		}
+		items := make(map[string][]storage.ActionItem, len(sessions))
+		for _, sess := range sessions {
+			if items[sess.ID], err = store.GetActionItems(sess.ID); err != nil {
+				writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("get action items: %v", err))
+				return
+			}
+		}
+
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		_, _ = io.WriteString(w, formatDayContext(date, sessions, decisions, items, budget, l))
+	})
+}
+
+// formatDayContext renders a day's meetings as markdown in roughly budget
+// tokens. Every meeting gets its heading; the rest of the budget is shared
+// out in meeting order, open items first, then decisions, then the
+// summary's key points, and whatever a meeting doesn't use passes to the
+// next. Lines that don't fit are counted rather than dropped silently.
+func formatDayContext(date string, sessions []storage.Session, decisions []storage.Decision, items map[string][]storage.ActionItem, budget int, l *i18n.Localizer) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", l.T(i18n.DocMeetingsOn, date))
+	if len(sessions) == 0 {
+		b.WriteString(l.T(i18n.DocNoMeetings) + "\n")
+		return b.String()
+	}
+
+	bySession := make(map[string][]storage.Decision)
+	for _, d := range decisions {
+		bySession[d.SessionID] = append(bySession[d.SessionID], d)
+	}
+
+	headings := make([]string, len(sessions))
+	remaining := budget*summary.CharsPerToken - b.Len()
+	for i, sess := range sessions {
+		headings[i] = contextHeading(sess, l)
+		remaining -= len(headings[i])
+	}
+
+	for i, sess := range sessions {
+		b.WriteString(headings[i])
+		share := max(remaining/(len(sessions)-i), 0)
+
+		var lines []string
+		for _, item := range items[sess.ID] {
+			if item.Done {
+				continue
+			}
+			line := "- [ ] " + item.Text
+			if item.Owner != "" {
+				line += " (" + item.Owner + ")"
+			}
+			lines = append(lines, line)
+		}
+		for _, d := range bySession[sess.ID] {
+			lines = append(lines, "- "+l.T(i18n.DocDecided, d.Text))
+		}
+		lines = append(lines, keyPoints(sess.Summary)...)
+
+		used, omitted := 0, 0
+		for _, line := range lines {
+			if omitted == 0 && used+len(line)+1 <= share {
+				b.WriteString(line)
+				b.WriteString("\n")
+				used += len(line) + 1
+				continue
+			}
+			omitted++
+		}
+		if omitted > 0 {
+			note := "- " + l.T(i18n.DocMore, omitted) + "\n"
+			b.WriteString(note)
+			used += len(note)
+		}
+		if len(lines) == 0 {
+			b.WriteString(l.T(i18n.DocNoSummary) + "\n")
+		}
+		b.WriteString("\n")
+		remaining -= used + 1
+	}
+	return strings.TrimRight(b.String(), "\n") + "\n"
+}
+
+// contextHeading is a meeting's "## 09:00–09:45 Title" line.
+func contextHeading(sess storage.Session, l *i18n.Localizer) string {
+	when := sess.StartedAt.UTC().Format("15:04")
+	if sess.EndedAt != nil {
+		when += "–" + sess.EndedAt.UTC().Format("15:04")
+	}
+	title := ""
+	for _, line := range strings.Split(sess.Summary, "\n") {
+		if strings.HasPrefix(line, "#") {
+			if title = strings.TrimSpace(strings.TrimLeft(line, "#")); title != "" {
+				break
+			}
+		}
+	}
+	switch {
+	case title != "":
+	case sess.Kind == storage.SessionKindMemo:
+		title = l.T(i18n.DocVoiceMemo)
+	default:
+		title = l.T(i18n.DocMeeting)
+	}
+	if sess.EndedAt == nil && sess.Status == "active" {
+		title = l.T(i18n.DocInProgress, title)
+	}
+	return fmt.Sprintf("## %s %s\n", when, title)
+}
+
+// keyPoints returns a summary's list items as "- " bullets, one per line.
+func keyPoints(summary string) []string {
+	var points []string
+	for _, line := range strings.Split(summary, "\n") {
+		line = strings.TrimSpace(line)
+		text := ""
+		switch {
+		case strings.HasPrefix(line, "- "), strings.HasPrefix(line, "* "):
+			text = line[2:]
+		default:
+			if dot := strings.Index(line, ". "); dot > 0 && dot <= 3 {
+				if _, err := strconv.Atoi(line[:dot]); err == nil {
+					text = line[dot+2:]
+				}
+			}
+		}
+		if text = strings.TrimSpace(text); text != "" {
+			points = append(points, "- "+text)
+		}
+	}
+	return points
+}
@@ -0,0 +1,129 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// AudioDevice is a capture device offered by GET /api/audio/devices.
+type AudioDevice struct {
+	Name              string  `json:"name"`
+	HostAPI           string  `json:"host_api"`
+	MaxInputChannels  int     `json:"max_input_channels"`
+	DefaultSampleRate float64 `json:"default_sample_rate"`
+	Default           bool    `json:"default"`
+}
+
+// MicHealth is whether audio capture is running. Since is when it was lost
+// or last came back.
+type MicHealth struct {
+	Healthy bool      `json:"healthy"`
+	Device  string    `json:"device"`
+	Error   string    `json:"error,omitempty"`
+	Since   time.Time `json:"since"`
+}
+
+// TranscriptionStatus is the state of the live transcription connection:
+// "connected", or "reconnecting" after it dropped. While reconnecting,
+// Attempt counts the failed attempts, the next coming RetryInSeconds later.
+// Since is when the state last changed.
+type TranscriptionStatus struct {
+	State          string    `json:"state"`
+	Provider       string    `json:"provider,omitempty"`
+	Attempt        int       `json:"attempt,omitempty"`
+	RetryInSeconds float64   `json:"retry_in_seconds,omitempty"`
+	Error          string    `json:"error,omitempty"`
+	Since          time.Time `json:"since"`
+}
+
+// ErrAudioDeviceNotFound is returned by ControlHooks.SetAudioDevice for an
+// unknown device name.
+var ErrAudioDeviceNotFound = errors.New("audio device not found")
+
+// ErrCalibrationInProgress is returned by ControlHooks.Calibrate while an
+// earlier calibration is still listening.
+var ErrCalibrationInProgress = errors.New("calibration already in progress")
+
+// Calibration lengths accepted by POST /api/audio/calibrate, in seconds.
+const (
+	defaultCalibrationSeconds = 5
+	maxCalibrationSeconds     = 60
+)
+
+func registerDeviceRoutes(mux *http.ServeMux, controls ControlHooks) {
+	mux.HandleFunc("GET /api/audio/devices", func(w http.ResponseWriter, r *http.Request) {
+		if controls.AudioDevices == nil {
+			writeJSONError(w, http.StatusServiceUnavailable, "device selection not available")
+			return
+		}
+		devices, current, err := controls.AudioDevices()
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("list audio devices: %v", err))
+			return
+		}
+		if devices == nil {
+			devices = []AudioDevice{}
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"current": current, "devices": devices})
+	})
+
+	mux.HandleFunc("POST /api/audio/device", func(w http.ResponseWriter, r *http.Request) {
+		if controls.SetAudioDevice == nil {
+			writeJSONError(w, http.StatusServiceUnavailable, "device selection not available")
+			return
+		}
+		var body struct {
+			Name string `json:"name"`
+		}
+		if err := json.NewDecoder(io.LimitReader(r.Body, 4<<10)).Decode(&body); err != nil {
+			writeJSONError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		if err := controls.SetAudioDevice(body.Name); err != nil {
+			if errors.Is(err, ErrAudioDeviceNotFound) {
+				writeJSONError(w, http.StatusNotFound, err.Error())
+			} else {
+				writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("switch audio device: %v", err))
+			}
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	// Calibration listens to the room for a few seconds, so it is answered
+	// once the measurement is done.
+	mux.HandleFunc("POST /api/audio/calibrate", func(w http.ResponseWriter, r *http.Request) {
+		if controls.Calibrate == nil {
+			writeJSONError(w, http.StatusServiceUnavailable, "calibration not available")
+			return
+		}
+		body := struct {
+			Seconds float64 `json:"seconds"`
+		}{Seconds: defaultCalibrationSeconds}
+		if err := json.NewDecoder(io.LimitReader(r.Body, 4<<10)).Decode(&body); err != nil && err != io.EOF {
+			writeJSONError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		if body.Seconds <= 0 || body.Seconds > maxCalibrationSeconds {
+			writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("seconds must be between 0 and %d", maxCalibrationSeconds))
+			return
+		}
+		d := time.Duration(body.Seconds * float64(time.Second))
+		ctx, cancel := context.WithTimeout(r.Context(), d+5*time.Second)
+		defer cancel()
+		calibration, err := controls.Calibrate(ctx, d)
+		if err != nil {
+			if errors.Is(err, ErrCalibrationInProgress) {
+				writeJSONError(w, http.StatusConflict, err.Error())
+			} else {
+				writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("calibrate: %v", err))
+			}
+			return
+		}
+		writeJSON(w, http.StatusOK, calibration)
+	})
+}
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+func registerDictationRoutes(mux *http.ServeMux, controls ControlHooks) {
+	d := controls.Dictation
+
+	mux.HandleFunc("GET /api/dictation", func(w http.ResponseWriter, r *http.Request) {
+		if d == nil {
+			writeJSONError(w, http.StatusServiceUnavailable, "dictation not available")
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]bool{"active": d.Active()})
+	})
+
+	for _, action := range []string{"start", "stop"} {
+		mux.HandleFunc("POST /api/dictation/"+action, func(w http.ResponseWriter, r *http.Request) {
+			if d == nil {
+				writeJSONError(w, http.StatusServiceUnavailable, "dictation not available")
+				return
+			}
+			d.SetActive(action == "start")
+			writeJSON(w, http.StatusOK, map[string]bool{"active": d.Active()})
+		})
+	}
+
+	// The stream sends each dictated segment as a server-sent event, or as a
+	// plain line with ?format=text for shell clipboard bridges.
+	mux.HandleFunc("GET /api/dictation/stream", func(w http.ResponseWriter, r *http.Request) {
+		if d == nil {
+			writeJSONError(w, http.StatusServiceUnavailable, "dictation not available")
+			return
+		}
+		plain := r.URL.Query().Get("format") == "text"
+		if plain {
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		} else {
+			w.Header().Set("Content-Type", "text/event-stream")
+		}
+		w.Header().Set("Cache-Control", "no-cache")
+		w.WriteHeader(http.StatusOK)
+
+		rc := http.NewResponseController(w)
+		_ = rc.Flush()
+
+		texts, unsubscribe := d.Subscribe()
+		defer unsubscribe()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case text := <-texts:
+				var err error
+				if plain {
+					_, err = fmt.Fprintln(w, text)
+				} else {
+					_, err = fmt.Fprintf(w, "data: %s\n\n", strings.ReplaceAll(text, "\n", "\ndata: "))
+				}
+				if err != nil {
+					return
+				}
+				if err := rc.Flush(); err != nil {
+					return
+				}
+			}
+		}
+	})
+}
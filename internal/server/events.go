@@ -1,6 +1,10 @@
 package server
 
-import "time"
+import (
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+)
 
 const EventVersion = 1
 
@@ -44,11 +48,110 @@ type SummaryReadyEvent struct {
 	Preset    string `json:"summary_preset"`
 }
 
+// AudioReadyEvent announces that an ended session's audio has been
+// encoded and can be played, or that encoding it failed.
+type AudioReadyEvent struct {
+	Event
+	SessionID string `json:"session_id"`
+	Status    string `json:"status"`
+	AudioPath string `json:"audio_path,omitempty"`
+}
+
 type StatusChangedEvent struct {
 	Event
 	Paused bool `json:"paused"`
 }
 
+type UpdateAvailableEvent struct {
+	Event
+	NewVersion string `json:"new_version"`
+	URL        string `json:"url"`
+	Staged     bool   `json:"staged"`
+}
+
+type BookmarkAddedEvent struct {
+	Event
+	Bookmark storage.Bookmark `json:"bookmark"`
+}
+
+// NoteAddedEvent announces a note typed during the active session.
+type NoteAddedEvent struct {
+	Event
+	Note storage.Note `json:"note"`
+}
+
+// AudioLevelEvent reports the input level a few times a second for a VU
+// meter. RMS and Peak are fractions of full scale; Clipping is set when any
+// sample in the interval hit full scale.
+type AudioLevelEvent struct {
+	Event
+	RMS            float64 `json:"rms"`
+	Peak           float64 `json:"peak"`
+	DBFS           float64 `json:"dbfs"`
+	Clipping       bool    `json:"clipping"`
+	ClippedSamples int     `json:"clipped_samples"`
+}
+
+// MicStatusEvent is broadcast when audio capture is lost and when it
+// recovers.
+type MicStatusEvent struct {
+	Event
+	MicHealth
+}
+
+// TranscriptionStatusEvent is broadcast when the live transcription
+// connection drops, as each reconnect attempt fails, and when it connects.
+type TranscriptionStatusEvent struct {
+	Event
+	TranscriptionStatus
+}
+
+// WarningsEvent carries the status warnings, as /api/status lists them,
+// whenever a runtime warning such as a silent microphone starts or stops.
+type WarningsEvent struct {
+	Event
+	Warnings []string `json:"warnings"`
+}
+
+// BriefingReadyEvent announces a pre-meeting brief shortly before its
+// meeting starts.
+type BriefingReadyEvent struct {
+	Event
+	Briefing storage.Briefing `json:"briefing"`
+}
+
+// ReportReadyEvent announces the weekly operational report.
+type ReportReadyEvent struct {
+	Event
+	Report storage.Report `json:"report"`
+}
+
+// SessionDeletedEvent announces that a session was purged.
+type SessionDeletedEvent struct {
+	Event
+	SessionID string `json:"session_id"`
+}
+
+// CommandAckEvent answers a command sent by a WebSocket client. It is only
+// sent to that client; ID echoes the command's id.
+type CommandAckEvent struct {
+	Event
+	ID      string `json:"id,omitempty"`
+	Command string `json:"command"`
+	OK      bool   `json:"ok"`
+	Error   string `json:"error,omitempty"`
+	Data    any    `json:"data,omitempty"`
+}
+
+// PresenceEvent is broadcast whenever a /ws client joins or leaves.
+type PresenceEvent struct {
+	Event
+	Change      string   `json:"change"`
+	Viewer      Viewer   `json:"viewer"`
+	ViewerCount int      `json:"viewer_count"`
+	Viewers     []Viewer `json:"viewers"`
+}
+
 type ConnectionEvent struct {
 	Event
 	Connected bool `json:"connected"`
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"testing"
 	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/storage"
 )
 
 func TestEventSerialization(t *testing.T) {
@@ -13,6 +15,10 @@ func TestEventSerialization(t *testing.T) {
 		SessionEndedEvent{Event: newEvent("session_ended", time.Unix(1, 0)), SessionID: "abc", Duration: 30},
 		SummaryReadyEvent{Event: newEvent("summary_ready", time.Unix(1, 0)), SessionID: "abc", Summary: "ok", Status: "completed"},
 		StatusChangedEvent{Event: newEvent("status_changed", time.Unix(1, 0)), Paused: true},
+		MicStatusEvent{Event: newEvent("mic_status", time.Unix(1, 0)), MicHealth: MicHealth{Device: "USB Mic", Error: "device lost"}},
+		TranscriptionStatusEvent{Event: newEvent("transcription_status", time.Unix(1, 0)), TranscriptionStatus: TranscriptionStatus{State: "reconnecting", Provider: "deepgram", Attempt: 2, RetryInSeconds: 4}},
+		WarningsEvent{Event: newEvent("warnings", time.Unix(1, 0)), Warnings: []string{"Microphone silent"}},
+		NoteAddedEvent{Event: newEvent("note_added", time.Unix(1, 0)), Note: storage.Note{SessionID: "abc", Text: "ship date is firm"}},
 	}
 
 	for _, event := range events {
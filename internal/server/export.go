@@ -0,0 +1,205 @@
+package server
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/parquet"
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+	"github.com/sjawhar/ghost-wispr/internal/transcribe"
+)
+
+// segmentExportColumns are the columns of GET /api/export/segments, in order.
+var segmentExportColumns = []parquet.Column{
+	{Name: "session_id", Type: parquet.String},
+	{Name: "session_started_at", Type: parquet.TimestampMillis},
+	{Name: "session_ended_at", Type: parquet.TimestampMillis, Optional: true},
+	{Name: "summary_preset", Type: parquet.String},
+	{Name: "speaker", Type: parquet.Int32},
+	{Name: "start_time", Type: parquet.Double},
+	{Name: "end_time", Type: parquet.Double},
+	{Name: "timestamp", Type: parquet.TimestampMillis},
+	{Name: "text", Type: parquet.String},
+}
+
+// Snapshotter is implemented by stores that can write a consistent copy of
+// their database to a new file while still accepting writes.
+type Snapshotter interface {
+	Snapshot(path string) error
+}
+
+func registerExportRoutes(mux *http.ServeMux, store SessionStore, controls ControlHooks) {
+	mux.HandleFunc("GET /api/export/sqlite", func(w http.ResponseWriter, r *http.Request) {
+		snapshotter, ok := store.(Snapshotter)
+		if !ok {
+			writeJSONError(w, http.StatusNotImplemented, "database snapshots are not supported by this store")
+			return
+		}
+
+		release, err := controls.Exports.Acquire(r.Context())
+		if err != nil {
+			return
+		}
+		defer release()
+
+		dir, err := os.MkdirTemp("", "ghost-wispr-snapshot-")
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("create snapshot dir: %v", err))
+			return
+		}
+		defer func() { _ = os.RemoveAll(dir) }()
+
+		path := filepath.Join(dir, "snapshot.db")
+		if err := snapshotter.Snapshot(path); err != nil {
+			writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("snapshot database: %v", err))
+			return
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("open snapshot: %v", err))
+			return
+		}
+		defer func() { _ = f.Close() }()
+
+		now := time.Now().UTC()
+		name := "ghost-wispr-" + now.Format("20060102-150405") + ".db"
+		w.Header().Set("Content-Type", "application/vnd.sqlite3")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+		w.Header().Set("Cache-Control", "no-store")
+		http.ServeContent(w, r, name, now, f)
+	})
+	mux.HandleFunc("GET /api/export/segments", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		from, to := q.Get("from"), q.Get("to")
+		for _, d := range []string{from, to} {
+			if _, err := time.Parse("2006-01-02", d); d != "" && err != nil {
+				writeJSONError(w, http.StatusBadRequest, "from and to must be YYYY-MM-DD dates")
+				return
+			}
+		}
+		format := q.Get("format")
+		if format == "" {
+			format = "csv"
+		}
+		if format != "csv" && format != "parquet" {
+			writeJSONError(w, http.StatusBadRequest, "format must be csv or parquet")
+			return
+		}
+
+		release, err := controls.Exports.Acquire(r.Context())
+		if err != nil {
+			return
+		}
+		defer release()
+
+		dates, err := store.GetDates()
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("list dates: %v", err))
+			return
+		}
+		dates = slices.DeleteFunc(dates, func(d string) bool {
+			return (from != "" && d < from) || (to != "" && d > to)
+		})
+		slices.Sort(dates)
+
+		name := "segments." + format
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+		w.Header().Set("Cache-Control", "no-store")
+		if format == "parquet" {
+			w.Header().Set("Content-Type", "application/vnd.apache.parquet")
+			err = exportSegmentsParquet(w, store, dates)
+		} else {
+			w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+			err = exportSegmentsCSV(w, store, dates)
+		}
+		if err != nil {
+			// Headers are already sent; truncating the body is all that's left.
+			log.Printf("segment export (%s) failed: %v", format, err)
+		}
+	})
+}
+
+// eachExportSegment calls fn for every segment of the sessions started on
+// dates, oldest session first. Only one session's segments are held at a
+// time, so the export streams in bounded memory however large the range.
+func eachExportSegment(store SessionStore, dates []string, fn func(storage.Session, transcribe.Segment) error) error {
+	for _, date := range dates {
+		sessions, err := store.GetSessionsByDate(date)
+		if err != nil {
+			return fmt.Errorf("list sessions for %s: %w", date, err)
+		}
+		slices.Reverse(sessions)
+		for _, sess := range sessions {
+			segments, err := store.GetSegments(sess.ID)
+			if err != nil {
+				return fmt.Errorf("get segments for session %s: %w", sess.ID, err)
+			}
+			for _, seg := range segments {
+				if err := fn(sess, seg); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func exportSegmentsCSV(w http.ResponseWriter, store SessionStore, dates []string) error {
+	cw := csv.NewWriter(w)
+	header := make([]string, len(segmentExportColumns))
+	for i, col := range segmentExportColumns {
+		header[i] = col.Name
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	err := eachExportSegment(store, dates, func(sess storage.Session, seg transcribe.Segment) error {
+		endedAt := ""
+		if sess.EndedAt != nil {
+			endedAt = sess.EndedAt.UTC().Format(time.RFC3339Nano)
+		}
+		return cw.Write([]string{
+			sess.ID,
+			sess.StartedAt.UTC().Format(time.RFC3339Nano),
+			endedAt,
+			sess.SummaryPreset,
+			strconv.Itoa(seg.Speaker),
+			strconv.FormatFloat(seg.StartTime, 'f', -1, 64),
+			strconv.FormatFloat(seg.EndTime, 'f', -1, 64),
+			seg.Timestamp.UTC().Format(time.RFC3339Nano),
+			seg.Text,
+		})
+	})
+	cw.Flush()
+	if err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+func exportSegmentsParquet(w http.ResponseWriter, store SessionStore, dates []string) error {
+	pw, err := parquet.NewWriter(w, segmentExportColumns)
+	if err != nil {
+		return err
+	}
+	err = eachExportSegment(store, dates, func(sess storage.Session, seg transcribe.Segment) error {
+		var endedAt any
+		if sess.EndedAt != nil {
+			endedAt = *sess.EndedAt
+		}
+		return pw.Write(sess.ID, sess.StartedAt, endedAt, sess.SummaryPreset, int32(seg.Speaker), seg.StartTime, seg.EndTime, seg.Timestamp, seg.Text)
+	})
+	if err != nil {
+		return err
+	}
+	return pw.Close()
+}
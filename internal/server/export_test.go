@@ -0,0 +1,327 @@
+package server
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/bundle"
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+	"github.com/sjawhar/ghost-wispr/internal/transcribe"
+)
+
+type snapshotStoreStub struct {
+	apiStoreStub
+	contents string
+}
+
+func (s snapshotStoreStub) Snapshot(path string) error {
+	return os.WriteFile(path, []byte(s.contents), 0o644)
+}
+
+func TestExportSQLiteStreamsSnapshot(t *testing.T) {
+	store := snapshotStoreStub{contents: "SQLite format 3\x00"}
+	h, err := Handler(testStaticFS(t), NewHub(), store, ControlHooks{})
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/export/sqlite", nil))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if rr.Body.String() != store.contents {
+		t.Fatalf("unexpected body %q", rr.Body.String())
+	}
+	if got := rr.Header().Get("Content-Disposition"); !strings.HasPrefix(got, `attachment; filename="ghost-wispr-`) {
+		t.Fatalf("unexpected Content-Disposition %q", got)
+	}
+}
+
+func TestExportSQLiteUnsupportedStore(t *testing.T) {
+	h, err := Handler(testStaticFS(t), NewHub(), apiStoreStub{}, ControlHooks{})
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/export/sqlite", nil))
+
+	if rr.Code != http.StatusNotImplemented {
+		t.Fatalf("expected 501, got %d", rr.Code)
+	}
+}
+
+func exportStoreStub() apiStoreStub {
+	day1 := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	day2 := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	ended := day1.Add(time.Hour)
+	return apiStoreStub{
+		dates: []string{"2026-03-02", "2026-03-01"},
+		sessionsByDate: map[string][]storage.Session{
+			"2026-03-01": {
+				{ID: "late", StartedAt: day1.Add(2 * time.Hour)},
+				{ID: "early", StartedAt: day1, EndedAt: &ended, SummaryPreset: "default"},
+			},
+			"2026-03-02": {{ID: "next", StartedAt: day2}},
+		},
+		segments: map[string][]transcribe.Segment{
+			"early": {{Speaker: 1, Text: "hello, world", StartTime: 0, EndTime: 1.5, Timestamp: day1}},
+			"late":  {{Speaker: 0, Text: "later", Timestamp: day1.Add(2 * time.Hour)}},
+			"next":  {{Speaker: 0, Text: "next day", Timestamp: day2}},
+		},
+	}
+}
+
+func TestExportSegmentsCSV(t *testing.T) {
+	h, err := Handler(testStaticFS(t), NewHub(), exportStoreStub(), ControlHooks{})
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/export/segments?to=2026-03-01", nil))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	want := "session_id,session_started_at,session_ended_at,summary_preset,speaker,start_time,end_time,timestamp,text\n" +
+		"early,2026-03-01T09:00:00Z,2026-03-01T10:00:00Z,default,1,0,1.5,2026-03-01T09:00:00Z,\"hello, world\"\n" +
+		"late,2026-03-01T11:00:00Z,,,0,0,0,2026-03-01T11:00:00Z,later\n"
+	if rr.Body.String() != want {
+		t.Fatalf("unexpected csv:\n%s", rr.Body.String())
+	}
+}
+
+func TestExportSegmentsParquet(t *testing.T) {
+	h, err := Handler(testStaticFS(t), NewHub(), exportStoreStub(), ControlHooks{})
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/export/segments?format=parquet", nil))
+
+	body := rr.Body.Bytes()
+	if rr.Code != http.StatusOK || !bytes.HasPrefix(body, []byte("PAR1")) || !bytes.HasSuffix(body, []byte("PAR1")) {
+		t.Fatalf("expected parquet file, got %d: %q", rr.Code, body)
+	}
+	if !bytes.Contains(body, []byte("next day")) {
+		t.Fatal("expected all sessions in export")
+	}
+}
+
+func TestExportSegmentsRejectsBadParams(t *testing.T) {
+	h, err := Handler(testStaticFS(t), NewHub(), exportStoreStub(), ControlHooks{})
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+
+	for _, query := range []string{"format=xlsx", "from=March"} {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/export/segments?"+query, nil))
+		if rr.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", query, rr.Code)
+		}
+	}
+}
+
+func TestExportSessionBundle(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.MkdirAll("audio", 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join("audio", "early.mp3"), []byte("ID3 fake mp3"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join("audio", "phone.m4a"), []byte("phone audio"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	store := exportStoreStub()
+	early := store.sessionsByDate["2026-03-01"][1]
+	early.Summary = "## Summary\n\nShipped it."
+	early.AudioPath = filepath.Join("audio", "early.mp3")
+	store.sessions = map[string]storage.Session{"early": early}
+	store.audioFiles = map[string][]storage.AudioFile{"early": {
+		{Index: 0, Kind: storage.AudioRecording, Path: early.AudioPath},
+		{Index: 1, Kind: storage.AudioImported, Path: filepath.Join("audio", "phone.m4a")},
+	}}
+	h, err := Handler(testStaticFS(t), NewHub(), store, ControlHooks{})
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/export/sessions/early/bundle", strings.NewReader(`{}`)))
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 without a password, got %d", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/export/sessions/early/bundle", strings.NewReader(`{"password": "s3cret"}`)))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if got := rr.Header().Get("Content-Disposition"); !strings.Contains(got, "session-early.gwb") {
+		t.Fatalf("unexpected Content-Disposition %q", got)
+	}
+	if bytes.Contains(rr.Body.Bytes(), []byte("hello, world")) {
+		t.Fatal("transcript visible in encrypted bundle")
+	}
+
+	plain, err := bundle.NewReader(rr.Body, "s3cret")
+	if err != nil {
+		t.Fatalf("NewReader failed: %v", err)
+	}
+	data, err := io.ReadAll(plain)
+	if err != nil {
+		t.Fatalf("decrypt failed: %v", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("open zip: %v", err)
+	}
+	contents := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := io.ReadAll(rc)
+		_ = rc.Close()
+		contents[f.Name] = string(b)
+	}
+	if !strings.Contains(contents["transcript.md"], "**Speaker 1** [00:00:00] hello, world") {
+		t.Fatalf("unexpected transcript:\n%s", contents["transcript.md"])
+	}
+	if contents["summary.md"] != early.Summary+"\n" || contents["audio.mp3"] != "ID3 fake mp3" || contents["audio-1-imported.m4a"] != "phone audio" || contents["session.json"] == "" {
+		t.Fatalf("unexpected bundle contents: %v", contents)
+	}
+}
+
+func TestExportSessionHTML(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.MkdirAll("audio", 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join("audio", "early.mp3"), []byte("ID3 fake mp3"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	store := exportStoreStub()
+	early := store.sessionsByDate["2026-03-01"][1]
+	early.Summary = "## Summary\n\n<b>Shipped</b> it."
+	early.AudioPath = filepath.Join("audio", "early.mp3")
+	store.sessions = map[string]storage.Session{"early": early}
+	h, err := Handler(testStaticFS(t), NewHub(), store, ControlHooks{})
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/sessions/early/export?format=html", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if got := rr.Header().Get("Content-Disposition"); !strings.Contains(got, "session-early.html") {
+		t.Fatalf("unexpected Content-Disposition %q", got)
+	}
+	page := rr.Body.String()
+	for _, want := range []string{"hello, world", "Speaker 1", "[00:00:00]", "&lt;b&gt;Shipped&lt;/b&gt; it."} {
+		if !strings.Contains(page, want) {
+			t.Fatalf("expected %q in page:\n%s", want, page)
+		}
+	}
+	if strings.Contains(page, "<audio") {
+		t.Fatal("expected no audio unless asked for")
+	}
+
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/sessions/early/export?format=html&audio=true", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if want := "data:audio/mpeg;base64," + base64.StdEncoding.EncodeToString([]byte("ID3 fake mp3")) + `"`; !strings.Contains(rr.Body.String(), want) {
+		t.Fatalf("expected the recording embedded, got:\n%s", rr.Body.String())
+	}
+
+	for query, code := range map[string]int{
+		"format=pdf":            http.StatusBadRequest,
+		"audio=maybe":           http.StatusBadRequest,
+		"format=html&audio=yes": http.StatusBadRequest,
+	} {
+		rr = httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/sessions/early/export?"+query, nil))
+		if rr.Code != code {
+			t.Fatalf("%s: expected %d, got %d", query, code, rr.Code)
+		}
+	}
+}
+
+func TestExportSessionCaptions(t *testing.T) {
+	store := exportStoreStub()
+	early := store.sessionsByDate["2026-03-01"][1]
+	store.sessions = map[string]storage.Session{"early": early}
+	words := []transcribe.SegmentWord{{Word: "hello,", Start: 0.25, End: 0.75}, {Word: "world", Start: 0.8, End: 1.5}}
+	for i := 0; i < 10; i++ {
+		words = append(words, transcribe.SegmentWord{Word: "again", Start: 2 + float64(i), End: 2.5 + float64(i)})
+	}
+	store.segments["early"] = []transcribe.Segment{
+		{Speaker: 1, Text: transcribe.WordsText(words), StartTime: 0.25, EndTime: 11.5, Words: words},
+		{Speaker: 0, Text: "no <timings>", StartTime: 3723.5, EndTime: 3725},
+	}
+	h, err := Handler(testStaticFS(t), NewHub(), store, ControlHooks{})
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/sessions/early/export?format=srt", nil))
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Header().Get("Content-Disposition"), "session-early.srt") {
+		t.Fatalf("expected an srt attachment, got %d %v", rr.Code, rr.Header())
+	}
+	srt := rr.Body.String()
+	for _, want := range []string{
+		"1\n00:00:00,250 --> 00:00:05,500\nSpeaker 1: hello, world again again again again\n\n",
+		"2\n00:00:06,000 --> 00:00:11,500\nSpeaker 1: again again again again again again\n\n",
+		"3\n01:02:03,500 --> 01:02:05,000\nSpeaker 0: no <timings>\n\n",
+	} {
+		if !strings.Contains(srt, want) {
+			t.Fatalf("expected %q in captions:\n%s", want, srt)
+		}
+	}
+
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/sessions/early/export?format=vtt", nil))
+	vtt := rr.Body.String()
+	if rr.Code != http.StatusOK || !strings.HasPrefix(vtt, "WEBVTT\n\n00:00:00.250 --> 00:00:05.500\n<v Speaker 1>hello, world") || !strings.Contains(vtt, "<v Speaker 0>no &lt;timings&gt;") {
+		t.Fatalf("unexpected vtt captions %d:\n%s", rr.Code, vtt)
+	}
+
+	// A later session's recording starts partway into the capture timeline,
+	// and its captions are timed against the recording.
+	early.AudioOffset = 3600
+	store.sessions["early"] = early
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/sessions/early/export?format=srt", nil))
+	srt = rr.Body.String()
+	for _, want := range []string{
+		"1\n00:00:00,000 --> 00:00:00,000\nSpeaker 1: hello, world",
+		"3\n00:02:03,500 --> 00:02:05,000\nSpeaker 0: no <timings>\n\n",
+	} {
+		if !strings.Contains(srt, want) {
+			t.Fatalf("expected %q in offset captions:\n%s", want, srt)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package server
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/sjawhar/ghost-wispr/internal/export"
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+)
+
+func registerExportRuleRoutes(mux *http.ServeMux, controls ControlHooks) {
+	mux.HandleFunc("GET /api/sessions/{id}/exports", func(w http.ResponseWriter, r *http.Request) {
+		if controls.SessionExports == nil {
+			writeJSONError(w, http.StatusServiceUnavailable, "no export rules configured")
+			return
+		}
+		sessionID := r.PathValue("id")
+		if !validSessionID(sessionID) {
+			writeJSONError(w, http.StatusForbidden, "invalid session id")
+			return
+		}
+		exports, err := controls.SessionExports(sessionID)
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("get session exports: %v", err))
+			return
+		}
+		if exports == nil {
+			exports = []storage.Export{}
+		}
+		writeJSON(w, http.StatusOK, exports)
+	})
+
+	mux.HandleFunc("POST /api/sessions/{id}/exports/{rule}", func(w http.ResponseWriter, r *http.Request) {
+		if controls.RetryExport == nil {
+			writeJSONError(w, http.StatusServiceUnavailable, "no export rules configured")
+			return
+		}
+		sessionID := r.PathValue("id")
+		if !validSessionID(sessionID) {
+			writeJSONError(w, http.StatusForbidden, "invalid session id")
+			return
+		}
+		err := controls.RetryExport(sessionID, r.PathValue("rule"))
+		switch {
+		case errors.Is(err, export.ErrUnknownRule), errors.Is(err, os.ErrNotExist), errors.Is(err, sql.ErrNoRows):
+			writeJSONError(w, http.StatusNotFound, err.Error())
+		case err != nil:
+			writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("retry export: %v", err))
+		default:
+			writeJSON(w, http.StatusAccepted, map[string]string{"status": storage.ExportPending})
+		}
+	})
+}
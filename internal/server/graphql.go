@@ -0,0 +1,300 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/sjawhar/ghost-wispr/internal/graphql"
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+	"github.com/sjawhar/ghost-wispr/internal/transcribe"
+)
+
+// graphqlStats aggregates sessions over a date range for the stats query.
+type graphqlStats struct {
+	Sessions           int
+	Segments           int
+	DurationSeconds    float64
+	SummariesCompleted int
+	TotalCost          float64
+}
+
+func registerGraphQLRoute(mux *http.ServeMux, store SessionStore) {
+	schema := newGraphQLSchema(store)
+
+	handle := func(w http.ResponseWriter, r *http.Request, req graphql.Request) {
+		if strings.TrimSpace(req.Query) == "" {
+			writeJSONError(w, http.StatusBadRequest, "query is required")
+			return
+		}
+		writeJSON(w, http.StatusOK, schema.Execute(r.Context(), req))
+	}
+
+	mux.HandleFunc("GET /graphql", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		req := graphql.Request{Query: q.Get("query"), OperationName: q.Get("operationName")}
+		if vars := q.Get("variables"); vars != "" {
+			if err := json.Unmarshal([]byte(vars), &req.Variables); err != nil {
+				writeJSONError(w, http.StatusBadRequest, "variables must be a JSON object")
+				return
+			}
+		}
+		handle(w, r, req)
+	})
+
+	mux.HandleFunc("POST /graphql", func(w http.ResponseWriter, r *http.Request) {
+		var req graphql.Request
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil {
+			writeJSONError(w, http.StatusBadRequest, "invalid JSON body")
+			return
+		}
+		handle(w, r, req)
+	})
+}
+
+// prop resolves a field from the source value alone.
+func prop[T any](get func(T) any) graphql.Resolver {
+	return func(_ context.Context, src any, _ graphql.Args) (any, error) {
+		return get(src.(T)), nil
+	}
+}
+
+func newGraphQLSchema(store SessionStore) *graphql.Schema {
+	segmentType := &graphql.Object{Name: "Segment", Fields: map[string]graphql.Resolver{
+		"speaker":   prop(func(s transcribe.Segment) any { return s.Speaker }),
+		"text":      prop(func(s transcribe.Segment) any { return s.Text }),
+		"startTime": prop(func(s transcribe.Segment) any { return s.StartTime }),
+		"endTime":   prop(func(s transcribe.Segment) any { return s.EndTime }),
+		"timestamp": prop(func(s transcribe.Segment) any { return s.Timestamp }),
+	}}
+
+	summaryType := &graphql.Object{Name: "Summary", Fields: map[string]graphql.Resolver{
+		"text":      prop(func(s storage.Session) any { return s.Summary }),
+		"status":    prop(func(s storage.Session) any { return s.SummaryStatus }),
+		"preset":    prop(func(s storage.Session) any { return s.SummaryPreset }),
+		"model":     prop(func(s storage.Session) any { return s.SummaryModel }),
+		"audioPath": prop(func(s storage.Session) any { return s.SummaryAudioPath }),
+	}}
+
+	usageType := &graphql.Object{Name: "Usage", Fields: map[string]graphql.Resolver{
+		"transcriptionMinutes": prop(func(u storage.SessionUsage) any { return u.TranscriptionMinutes }),
+		"transcriptionCostUsd": prop(func(u storage.SessionUsage) any { return u.TranscriptionCost }),
+		"llmInputTokens":       prop(func(u storage.SessionUsage) any { return u.LLMInputTokens }),
+		"llmOutputTokens":      prop(func(u storage.SessionUsage) any { return u.LLMOutputTokens }),
+		"llmCostUsd":           prop(func(u storage.SessionUsage) any { return u.LLMCost }),
+		"summaryLatencyMs":     prop(func(u storage.SessionUsage) any { return u.SummaryLatencyMs }),
+		"totalCostUsd":         prop(func(u storage.SessionUsage) any { return u.TotalCost }),
+	}}
+
+	statsType := &graphql.Object{Name: "Stats", Fields: map[string]graphql.Resolver{
+		"sessions":           prop(func(s graphqlStats) any { return s.Sessions }),
+		"segments":           prop(func(s graphqlStats) any { return s.Segments }),
+		"durationSeconds":    prop(func(s graphqlStats) any { return s.DurationSeconds }),
+		"summariesCompleted": prop(func(s graphqlStats) any { return s.SummariesCompleted }),
+		"totalCostUsd":       prop(func(s graphqlStats) any { return s.TotalCost }),
+	}}
+
+	sessionType := &graphql.Object{Name: "Session"}
+	decisionType := &graphql.Object{Name: "Decision"}
+
+	sessionType.Fields = map[string]graphql.Resolver{
+		"id":                    prop(func(s storage.Session) any { return s.ID }),
+		"startedAt":             prop(func(s storage.Session) any { return s.StartedAt }),
+		"endedAt":               prop(func(s storage.Session) any { return s.EndedAt }),
+		"status":                prop(func(s storage.Session) any { return s.Status }),
+		"audioPath":             prop(func(s storage.Session) any { return s.AudioPath }),
+		"segmentsVersion":       prop(func(s storage.Session) any { return s.SegmentsVersion }),
+		"transcriptionModel":    prop(func(s storage.Session) any { return s.TranscriptionModel }),
+		"transcriptionLanguage": prop(func(s storage.Session) any { return s.TranscriptionLanguage }),
+		"durationSeconds":       prop(func(s storage.Session) any { return sessionDuration(s) }),
+		"summary":               prop(func(s storage.Session) any { return graphql.Node{Type: summaryType, Value: s} }),
+		"segments": func(_ context.Context, src any, args graphql.Args) (any, error) {
+			segments, err := store.GetSegments(src.(storage.Session).ID)
+			if err != nil {
+				return nil, err
+			}
+			speaker, bySpeaker, err := args.Int("speaker")
+			if err != nil {
+				return nil, err
+			}
+			query, _, err := args.String("query")
+			if err != nil {
+				return nil, err
+			}
+			query = strings.ToLower(query)
+			segments = slices.DeleteFunc(segments, func(seg transcribe.Segment) bool {
+				return (bySpeaker && seg.Speaker != speaker) || !strings.Contains(strings.ToLower(seg.Text), query)
+			})
+			segments, err = limitArg(args, segments)
+			return graphql.Nodes(segmentType, segments), err
+		},
+		"usage": func(_ context.Context, src any, _ graphql.Args) (any, error) {
+			usage, err := store.GetSessionUsage(src.(storage.Session).ID)
+			if err != nil {
+				return nil, err
+			}
+			return graphql.Node{Type: usageType, Value: usage}, nil
+		},
+		"decisions": func(_ context.Context, src any, _ graphql.Args) (any, error) {
+			sess := src.(storage.Session)
+			date := sess.StartedAt.UTC().Format("2006-01-02")
+			decisions, err := store.GetDecisions(storage.DecisionFilter{From: date, To: date})
+			if err != nil {
+				return nil, err
+			}
+			decisions = slices.DeleteFunc(decisions, func(d storage.Decision) bool { return d.SessionID != sess.ID })
+			return graphql.Nodes(decisionType, decisions), nil
+		},
+	}
+
+	decisionType.Fields = map[string]graphql.Resolver{
+		"id":           prop(func(d storage.Decision) any { return d.ID }),
+		"sessionId":    prop(func(d storage.Decision) any { return d.SessionID }),
+		"text":         prop(func(d storage.Decision) any { return d.Text }),
+		"participants": prop(func(d storage.Decision) any { return d.Participants }),
+		"timestamp":    prop(func(d storage.Decision) any { return d.Timestamp }),
+		"session": func(_ context.Context, src any, _ graphql.Args) (any, error) {
+			return lookupSession(store, sessionType, src.(storage.Decision).SessionID)
+		},
+	}
+
+	return &graphql.Schema{Query: &graphql.Object{Name: "Query", Fields: map[string]graphql.Resolver{
+		"dates": func(context.Context, any, graphql.Args) (any, error) {
+			return store.GetDates()
+		},
+		"session": func(_ context.Context, _ any, args graphql.Args) (any, error) {
+			id, _, err := args.String("id")
+			if err != nil {
+				return nil, err
+			}
+			return lookupSession(store, sessionType, id)
+		},
+		"sessions": func(_ context.Context, _ any, args graphql.Args) (any, error) {
+			sessions, err := sessionsInRange(store, args)
+			if err != nil {
+				return nil, err
+			}
+			status, byStatus, err := args.String("summaryStatus")
+			if err != nil {
+				return nil, err
+			}
+			if byStatus {
+				sessions = slices.DeleteFunc(sessions, func(s storage.Session) bool { return s.SummaryStatus != status })
+			}
+			sessions, err = limitArg(args, sessions)
+			return graphql.Nodes(sessionType, sessions), err
+		},
+		"decisions": func(_ context.Context, _ any, args graphql.Args) (any, error) {
+			var filter storage.DecisionFilter
+			var err error
+			for name, dst := range map[string]*string{"from": &filter.From, "to": &filter.To, "query": &filter.Query} {
+				if *dst, _, err = args.String(name); err != nil {
+					return nil, err
+				}
+			}
+			decisions, err := store.GetDecisions(filter)
+			if err != nil {
+				return nil, err
+			}
+			return graphql.Nodes(decisionType, decisions), nil
+		},
+		"stats": func(_ context.Context, _ any, args graphql.Args) (any, error) {
+			sessions, err := sessionsInRange(store, args)
+			if err != nil {
+				return nil, err
+			}
+			var stats graphqlStats
+			for _, sess := range sessions {
+				segments, err := store.GetSegments(sess.ID)
+				if err != nil {
+					return nil, err
+				}
+				usage, err := store.GetSessionUsage(sess.ID)
+				if err != nil {
+					return nil, err
+				}
+				stats.Sessions++
+				stats.Segments += len(segments)
+				stats.DurationSeconds += sessionDuration(sess)
+				stats.TotalCost += usage.TotalCost
+				if sess.SummaryStatus == storage.SummaryCompleted {
+					stats.SummariesCompleted++
+				}
+			}
+			return graphql.Node{Type: statsType, Value: stats}, nil
+		},
+	}}}
+}
+
+// sessionsInRange returns sessions for the date, or from/to, arguments,
+// oldest first. With no dates given, every session is returned.
+func sessionsInRange(store SessionStore, args graphql.Args) ([]storage.Session, error) {
+	date, byDate, err := args.String("date")
+	if err != nil {
+		return nil, err
+	}
+	from, _, err := args.String("from")
+	if err != nil {
+		return nil, err
+	}
+	to, _, err := args.String("to")
+	if err != nil {
+		return nil, err
+	}
+
+	dates := []string{date}
+	if !byDate {
+		if dates, err = store.GetDates(); err != nil {
+			return nil, err
+		}
+		dates = slices.DeleteFunc(dates, func(d string) bool {
+			return (from != "" && d < from) || (to != "" && d > to)
+		})
+		slices.Sort(dates)
+	}
+
+	var sessions []storage.Session
+	for _, d := range dates {
+		daySessions, err := store.GetSessionsByDate(d)
+		if err != nil {
+			return nil, err
+		}
+		slices.Reverse(daySessions)
+		sessions = append(sessions, daySessions...)
+	}
+	return sessions, nil
+}
+
+func lookupSession(store SessionStore, sessionType *graphql.Object, id string) (any, error) {
+	sess, err := store.GetSession(id)
+	if errors.Is(err, os.ErrNotExist) || errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return graphql.Node{Type: sessionType, Value: sess}, nil
+}
+
+func limitArg[T any](args graphql.Args, items []T) ([]T, error) {
+	limit, ok, err := args.Int("limit")
+	if err != nil {
+		return nil, err
+	}
+	if ok && limit >= 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	return items, nil
+}
+
+func sessionDuration(s storage.Session) float64 {
+	if s.EndedAt == nil {
+		return 0
+	}
+	return s.EndedAt.Sub(s.StartedAt).Seconds()
+}
@@ -0,0 +1,79 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+)
+
+func TestGraphQLNestedQuery(t *testing.T) {
+	store := exportStoreStub()
+	store.sessions = map[string]storage.Session{}
+	for _, day := range store.sessionsByDate {
+		for _, sess := range day {
+			store.sessions[sess.ID] = sess
+		}
+	}
+	store.decisions = []storage.Decision{{ID: 1, SessionID: "early", Text: "Ship it", Timestamp: time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)}}
+	store.usage = map[string]storage.SessionUsage{"early": {TotalCost: 0.5}}
+
+	h, err := Handler(testStaticFS(t), NewHub(), store, ControlHooks{GraphQL: true})
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+
+	body := `{"query":"query($d: String) { sessions(date: $d, limit: 1) { id summary { preset } segments(speaker: 1) { text } usage { totalCostUsd } } decisions(query: \"ship\") { text session { id durationSeconds } } stats(to: \"2026-03-01\") { sessions segments } }","variables":{"d":"2026-03-01"}}`
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body)))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	want := `{"data":{"sessions":[{"id":"early","summary":{"preset":"default"},"segments":[{"text":"hello, world"}],"usage":{"totalCostUsd":0.5}}],` +
+		`"decisions":[{"text":"Ship it","session":{"id":"early","durationSeconds":3600}}],"stats":{"sessions":2,"segments":2}}}`
+	if got := strings.TrimSpace(rr.Body.String()); got != want {
+		t.Fatalf("got  %s\nwant %s", got, want)
+	}
+}
+
+func TestGraphQLGetAndErrors(t *testing.T) {
+	h, err := Handler(testStaticFS(t), NewHub(), exportStoreStub(), ControlHooks{GraphQL: true})
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/graphql?query="+url.QueryEscape("{ session(id: \"nope\") { id } bogus }"), nil))
+
+	var resp struct {
+		Data   map[string]any `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Data["session"] != nil || len(resp.Errors) != 1 || !strings.Contains(resp.Errors[0].Message, "bogus") {
+		t.Fatalf("unexpected response: %s", rr.Body.String())
+	}
+}
+
+func TestGraphQLDisabledByDefault(t *testing.T) {
+	h, err := Handler(testStaticFS(t), NewHub(), exportStoreStub(), ControlHooks{})
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{"query":"{ dates }"}`)))
+	if rr.Header().Get("Content-Type") == "application/json" {
+		t.Fatalf("expected /graphql to fall through to the SPA, got %s", rr.Body.String())
+	}
+}
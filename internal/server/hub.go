@@ -3,19 +3,51 @@ package server
 import (
 	"encoding/json"
 	"log"
+	"slices"
 	"sync"
 	"time"
 
+	"github.com/sjawhar/ghost-wispr/internal/storage"
 	"github.com/sjawhar/ghost-wispr/internal/transcribe"
 )
 
 type Hub struct {
 	mu      sync.RWMutex
 	clients map[chan []byte]struct{}
+
+	presenceMu sync.Mutex
+	viewers    map[string]Viewer
+	nextViewer int
+
+	// recent holds the current session's last finalized lines, for
+	// overlays that connect mid-session.
+	recentMu sync.Mutex
+	recent   []LiveTranscriptEvent
+
+	// interimEvery is the least time between interim transcripts sent to
+	// each WebSocket connection, which coalesces them; see registerWSRoute.
+	interimMu    sync.Mutex
+	interimEvery time.Duration
 }
 
 func NewHub() *Hub {
-	return &Hub{clients: make(map[chan []byte]struct{})}
+	return &Hub{clients: make(map[chan []byte]struct{}), viewers: make(map[string]Viewer)}
+}
+
+// SetInterimInterval sets the least time between interim transcripts sent
+// to each WebSocket connection; 0 sends every one. Connections can ask for
+// a longer interval, never a shorter one.
+func (h *Hub) SetInterimInterval(d time.Duration) {
+	h.interimMu.Lock()
+	defer h.interimMu.Unlock()
+	h.interimEvery = max(d, 0)
+}
+
+// InterimInterval returns the interval set by SetInterimInterval.
+func (h *Hub) InterimInterval() time.Duration {
+	h.interimMu.Lock()
+	defer h.interimMu.Unlock()
+	return h.interimEvery
 }
 
 func (h *Hub) Subscribe() chan []byte {
@@ -46,25 +78,44 @@ func (h *Hub) Broadcast(msg []byte) {
 }
 
 func (h *Hub) BroadcastLiveTranscript(seg transcribe.Segment) {
-	h.broadcastEvent(LiveTranscriptEvent{
+	event := LiveTranscriptEvent{
 		Event:     newEvent("live_transcript", seg.Timestamp),
 		Speaker:   seg.Speaker,
 		Text:      seg.Text,
 		StartTime: seg.StartTime,
 		EndTime:   seg.EndTime,
-	})
+	}
+	h.recentMu.Lock()
+	h.recent = append(h.recent, event)
+	if n := len(h.recent); n > maxOverlayLines {
+		h.recent = slices.Clone(h.recent[n-maxOverlayLines:])
+	}
+	h.recentMu.Unlock()
+	h.broadcastEvent(event)
+}
+
+// RecentTranscript returns up to n of the current session's last finalized
+// lines, oldest first.
+func (h *Hub) RecentTranscript(n int) []LiveTranscriptEvent {
+	h.recentMu.Lock()
+	defer h.recentMu.Unlock()
+	return slices.Clone(h.recent[max(len(h.recent)-n, 0):])
 }
 
 func (h *Hub) BroadcastLiveTranscriptInterim(speaker int, text string, startTime float64) {
-	h.broadcastEvent(LiveTranscriptInterimEvent{
+	event := LiveTranscriptInterimEvent{
 		Event:     newEvent("live_transcript_interim", time.Now().UTC()),
 		Speaker:   speaker,
 		Text:      text,
 		StartTime: startTime,
-	})
+	}
+	h.broadcastEvent(event)
 }
 
 func (h *Hub) BroadcastSessionStarted(sessionID string) {
+	h.recentMu.Lock()
+	h.recent = nil
+	h.recentMu.Unlock()
 	h.broadcastEvent(SessionStartedEvent{
 		Event:     newEvent("session_started", time.Now().UTC()),
 		SessionID: sessionID,
@@ -89,6 +140,15 @@ func (h *Hub) BroadcastSummaryReady(sessionID, summary, status, preset string) {
 	})
 }
 
+func (h *Hub) BroadcastAudioReady(sessionID, status, audioPath string) {
+	h.broadcastEvent(AudioReadyEvent{
+		Event:     newEvent("audio_ready", time.Now().UTC()),
+		SessionID: sessionID,
+		Status:    status,
+		AudioPath: audioPath,
+	})
+}
+
 func (h *Hub) BroadcastStatusChanged(paused bool) {
 	h.broadcastEvent(StatusChangedEvent{
 		Event:  newEvent("status_changed", time.Now().UTC()),
@@ -96,6 +156,85 @@ func (h *Hub) BroadcastStatusChanged(paused bool) {
 	})
 }
 
+func (h *Hub) BroadcastUpdateAvailable(version, url string, staged bool) {
+	h.broadcastEvent(UpdateAvailableEvent{
+		Event:      newEvent("update_available", time.Now().UTC()),
+		NewVersion: version,
+		URL:        url,
+		Staged:     staged,
+	})
+}
+
+func (h *Hub) BroadcastBookmarkAdded(b storage.Bookmark) {
+	h.broadcastEvent(BookmarkAddedEvent{
+		Event:    newEvent("bookmark_added", time.Now().UTC()),
+		Bookmark: b,
+	})
+}
+
+func (h *Hub) BroadcastNoteAdded(n storage.Note) {
+	h.broadcastEvent(NoteAddedEvent{
+		Event: newEvent("note_added", time.Now().UTC()),
+		Note:  n,
+	})
+}
+
+func (h *Hub) BroadcastAudioLevel(rms, peak, dbfs float64, clippedSamples int) {
+	h.broadcastEvent(AudioLevelEvent{
+		Event:          newEvent("audio_level", time.Now().UTC()),
+		RMS:            rms,
+		Peak:           peak,
+		DBFS:           dbfs,
+		Clipping:       clippedSamples > 0,
+		ClippedSamples: clippedSamples,
+	})
+}
+
+func (h *Hub) BroadcastMicStatus(health MicHealth) {
+	h.broadcastEvent(MicStatusEvent{
+		Event:     newEvent("mic_status", time.Now().UTC()),
+		MicHealth: health,
+	})
+}
+
+func (h *Hub) BroadcastTranscriptionStatus(status TranscriptionStatus) {
+	h.broadcastEvent(TranscriptionStatusEvent{
+		Event:               newEvent("transcription_status", time.Now().UTC()),
+		TranscriptionStatus: status,
+	})
+}
+
+func (h *Hub) BroadcastWarnings(warnings []string) {
+	if warnings == nil {
+		warnings = []string{}
+	}
+	h.broadcastEvent(WarningsEvent{
+		Event:    newEvent("warnings", time.Now().UTC()),
+		Warnings: warnings,
+	})
+}
+
+func (h *Hub) BroadcastBriefingReady(b storage.Briefing) {
+	h.broadcastEvent(BriefingReadyEvent{
+		Event:    newEvent("briefing_ready", time.Now().UTC()),
+		Briefing: b,
+	})
+}
+
+func (h *Hub) BroadcastReportReady(r storage.Report) {
+	h.broadcastEvent(ReportReadyEvent{
+		Event:  newEvent("report_ready", time.Now().UTC()),
+		Report: r,
+	})
+}
+
+func (h *Hub) BroadcastSessionDeleted(sessionID string) {
+	h.broadcastEvent(SessionDeletedEvent{
+		Event:     newEvent("session_deleted", time.Now().UTC()),
+		SessionID: sessionID,
+	})
+}
+
 func (h *Hub) broadcastEvent(event any) {
 	payload, err := json.Marshal(event)
 	if err != nil {
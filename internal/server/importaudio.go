@@ -0,0 +1,84 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/audio"
+	"github.com/sjawhar/ghost-wispr/internal/ingest"
+)
+
+// maxImportUpload caps an uploaded recording; a few hours of WAV fits.
+const maxImportUpload = 1 << 30
+
+// registerImportAudioRoute serves POST /api/sessions/import-audio, which
+// takes a recording made elsewhere, such as a phone voice memo or an old
+// meeting, as the multipart field "file" and accepts it to be stored as a
+// transcribed, summarized session in the background.
+func registerImportAudioRoute(mux *http.ServeMux, controls ControlHooks) {
+	mux.HandleFunc("POST /api/sessions/import-audio", func(w http.ResponseWriter, r *http.Request) {
+		if controls.ImportAudio == nil {
+			writeJSONError(w, http.StatusServiceUnavailable, "audio import not available")
+			return
+		}
+		var startedAt time.Time
+		if v := r.URL.Query().Get("started_at"); v != "" {
+			var err error
+			if startedAt, err = time.Parse(time.RFC3339, v); err != nil {
+				writeJSONError(w, http.StatusBadRequest, "started_at must be RFC 3339")
+				return
+			}
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, maxImportUpload)
+		parts, err := r.MultipartReader()
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "expected a multipart/form-data upload")
+			return
+		}
+		// The file is streamed rather than parsed into a form, so it's read
+		// straight from the first part named file.
+		for {
+			part, err := parts.NextPart()
+			if errors.Is(err, io.EOF) {
+				writeJSONError(w, http.StatusBadRequest, "missing file")
+				return
+			}
+			if err != nil {
+				writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("read upload: %v", err))
+				return
+			}
+			if part.FormName() != "file" {
+				continue
+			}
+			name := part.FileName()
+			if !ingest.IsAudioFile(name) {
+				writeJSONError(w, http.StatusUnsupportedMediaType, "file must be an audio recording such as MP3, WAV or M4A")
+				return
+			}
+			if err := controls.ImportAudio(r.Context(), name, part, startedAt); err != nil {
+				writeImportError(w, err)
+				return
+			}
+			writeJSON(w, http.StatusAccepted, map[string]string{"status": "importing"})
+			return
+		}
+	})
+}
+
+func writeImportError(w http.ResponseWriter, err error) {
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		writeJSONError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("upload is larger than %d MB", maxImportUpload>>20))
+	case errors.Is(err, ingest.ErrUnsupportedType), errors.Is(err, audio.ErrUndecodable):
+		writeJSONError(w, http.StatusUnsupportedMediaType, err.Error())
+	case errors.Is(err, ingest.ErrUploaderClosed):
+		writeJSONError(w, http.StatusServiceUnavailable, "shutting down")
+	default:
+		writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("import audio: %v", err))
+	}
+}
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/sjawhar/ghost-wispr/internal/transcribe"
+)
+
+// registerKeywordRoutes serves the keywords Deepgram is told to favour, and
+// replaces them without a restart.
+func registerKeywordRoutes(mux *http.ServeMux, controls ControlHooks) {
+	mux.HandleFunc("GET /api/transcription/keywords", func(w http.ResponseWriter, r *http.Request) {
+		if controls.Keywords == nil {
+			writeJSONError(w, http.StatusServiceUnavailable, "keywords not available")
+			return
+		}
+		writeKeywords(w, http.StatusOK, controls.Keywords())
+	})
+
+	mux.HandleFunc("PUT /api/transcription/keywords", func(w http.ResponseWriter, r *http.Request) {
+		if controls.SetKeywords == nil {
+			writeJSONError(w, http.StatusServiceUnavailable, "keywords not available")
+			return
+		}
+		var body struct {
+			Keywords []string `json:"keywords"`
+		}
+		if err := json.NewDecoder(io.LimitReader(r.Body, 64<<10)).Decode(&body); err != nil || body.Keywords == nil {
+			writeJSONError(w, http.StatusBadRequest, "request body must be {\"keywords\": [\"...\"]}")
+			return
+		}
+		keywords, err := controls.SetKeywords(body.Keywords)
+		switch {
+		case errors.Is(err, transcribe.ErrInvalidKeyword):
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+		case err != nil && keywords != nil:
+			// Stored and used for batch transcription, but the live stream
+			// carries on with the old ones.
+			writeJSONError(w, http.StatusBadGateway, err.Error())
+		case err != nil:
+			writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("set keywords: %v", err))
+		default:
+			writeKeywords(w, http.StatusOK, keywords)
+		}
+	})
+}
+
+func writeKeywords(w http.ResponseWriter, status int, keywords []string) {
+	if keywords == nil {
+		keywords = []string{}
+	}
+	writeJSON(w, status, map[string][]string{"keywords": keywords})
+}
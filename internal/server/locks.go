@@ -0,0 +1,49 @@
+package server
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+)
+
+// SessionLocker is implemented by stores that can lock sessions against
+// edits, resummarization and deletion.
+type SessionLocker interface {
+	SetSessionLocked(sessionID string, locked bool) error
+}
+
+func registerLockRoutes(mux *http.ServeMux, store SessionStore) {
+	mux.HandleFunc("POST /api/sessions/{id}/lock", func(w http.ResponseWriter, r *http.Request) {
+		sessionID := r.PathValue("id")
+		if !validSessionID(sessionID) {
+			writeJSONError(w, http.StatusForbidden, "invalid session id")
+			return
+		}
+		locker, ok := store.(SessionLocker)
+		if !ok {
+			writeJSONError(w, http.StatusNotImplemented, "session locking is not supported by this store")
+			return
+		}
+
+		body := struct {
+			Locked bool `json:"locked"`
+		}{Locked: true}
+		if err := json.NewDecoder(io.LimitReader(r.Body, 4<<10)).Decode(&body); err != nil && err != io.EOF {
+			writeJSONError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		if err := locker.SetSessionLocked(sessionID, body.Locked); err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, os.ErrNotExist) || errors.Is(err, sql.ErrNoRows) {
+				status = http.StatusNotFound
+			}
+			writeJSONError(w, status, fmt.Sprintf("lock session: %v", err))
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"locked": body.Locked})
+	})
+}
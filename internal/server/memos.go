@@ -0,0 +1,64 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/session"
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+)
+
+func registerMemoRoutes(mux *http.ServeMux, store SessionStore, controls ControlHooks) {
+	mux.HandleFunc("POST /api/memo/start", func(w http.ResponseWriter, r *http.Request) {
+		if controls.StartMemo == nil {
+			writeJSONError(w, http.StatusServiceUnavailable, "voice memos not available")
+			return
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+		sessionID, err := controls.StartMemo(ctx)
+		if err != nil {
+			if errors.Is(err, session.ErrMemoInProgress) {
+				writeJSONError(w, http.StatusConflict, err.Error())
+			} else {
+				writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("start memo: %v", err))
+			}
+			return
+		}
+		writeJSON(w, http.StatusCreated, map[string]string{"session_id": sessionID})
+	})
+
+	mux.HandleFunc("POST /api/memo/stop", func(w http.ResponseWriter, r *http.Request) {
+		if controls.StopMemo == nil {
+			writeJSONError(w, http.StatusServiceUnavailable, "voice memos not available")
+			return
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+		sessionID, err := controls.StopMemo(ctx)
+		if err != nil {
+			if errors.Is(err, session.ErrNoMemoInProgress) {
+				writeJSONError(w, http.StatusConflict, err.Error())
+			} else {
+				writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("stop memo: %v", err))
+			}
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"session_id": sessionID})
+	})
+
+	mux.HandleFunc("GET /api/memos", func(w http.ResponseWriter, r *http.Request) {
+		memos, err := store.GetSessionsByKind(storage.SessionKindMemo)
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("list memos: %v", err))
+			return
+		}
+		if memos == nil {
+			memos = []storage.Session{}
+		}
+		writeJSON(w, http.StatusOK, memos)
+	})
+}
@@ -0,0 +1,38 @@
+package server
+
+import (
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/gorilla/websocket"
+)
+
+// newUpgrader returns a WebSocket upgrader that accepts connections opened
+// by the server's own pages or by pages on one of origins
+// ("https://example.com"). WebSockets aren't covered by the same-origin
+// policy, so without this any site the user visits could drive the
+// recorder. Clients sending no Origin are not browsers and are let through.
+func newUpgrader(origins []string) *websocket.Upgrader {
+	allowed := make([]string, 0, len(origins))
+	for _, o := range origins {
+		allowed = append(allowed, strings.ToLower(strings.TrimSuffix(o, "/")))
+	}
+	return &websocket.Upgrader{
+		CheckOrigin: func(r *http.Request) bool {
+			origin := r.Header.Get("Origin")
+			if origin == "" {
+				return true
+			}
+			u, err := url.Parse(origin)
+			if err != nil {
+				return false
+			}
+			if strings.EqualFold(u.Host, r.Host) {
+				return true
+			}
+			return slices.Contains(allowed, strings.ToLower(u.Scheme+"://"+u.Host))
+		},
+	}
+}
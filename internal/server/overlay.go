@@ -0,0 +1,228 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/sjawhar/ghost-wispr/internal/i18n"
+)
+
+const (
+	defaultOverlayLines = 3
+	// maxOverlayLines is also how many lines the hub keeps for overlays.
+	maxOverlayLines = 50
+)
+
+var (
+	hexColor   = regexp.MustCompile(`^#?([0-9a-fA-F]{3,4}|[0-9a-fA-F]{6}|[0-9a-fA-F]{8})$`)
+	colorName  = regexp.MustCompile(`^[a-zA-Z]+$`)
+	fontFamily = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9 -]*$`)
+)
+
+// overlayOptions are the query parameters of /overlay.
+type overlayOptions struct {
+	Lines    int
+	Speakers bool
+	// Styling, for the HTML page only.
+	Font       string
+	Size       int
+	Color      string
+	Background string
+	Align      string
+	Shadow     bool
+}
+
+// parseOverlayOptions reads the overlay's query string. Colors are CSS
+// names or hex with or without the "#", which OBS URLs tend to drop.
+func parseOverlayOptions(r *http.Request) (overlayOptions, error) {
+	q := r.URL.Query()
+	opts := overlayOptions{
+		Lines:      defaultOverlayLines,
+		Font:       "sans-serif",
+		Size:       32,
+		Color:      "#ffffff",
+		Background: "transparent",
+		Align:      "left",
+		Shadow:     true,
+	}
+	var err error
+	if v := q.Get("lines"); v != "" {
+		if opts.Lines, err = strconv.Atoi(v); err != nil || opts.Lines < 1 || opts.Lines > maxOverlayLines {
+			return opts, fmt.Errorf("lines must be between 1 and %d", maxOverlayLines)
+		}
+	}
+	if v := q.Get("size"); v != "" {
+		if opts.Size, err = strconv.Atoi(v); err != nil || opts.Size < 8 || opts.Size > 200 {
+			return opts, errors.New("size must be between 8 and 200 pixels")
+		}
+	}
+	for _, flag := range []struct {
+		name string
+		dst  *bool
+	}{{"speakers", &opts.Speakers}, {"shadow", &opts.Shadow}} {
+		if v := q.Get(flag.name); v != "" {
+			if *flag.dst, err = strconv.ParseBool(v); err != nil {
+				return opts, fmt.Errorf("%s must be true or false", flag.name)
+			}
+		}
+	}
+	for _, color := range []struct {
+		name string
+		dst  *string
+	}{{"color", &opts.Color}, {"background", &opts.Background}} {
+		v := q.Get(color.name)
+		switch {
+		case v == "":
+		case hexColor.MatchString(v):
+			*color.dst = "#" + strings.TrimPrefix(v, "#")
+		case colorName.MatchString(v):
+			*color.dst = v
+		default:
+			return opts, fmt.Errorf("%s must be a CSS color name or hex value", color.name)
+		}
+	}
+	if v := q.Get("font"); v != "" {
+		if !fontFamily.MatchString(v) {
+			return opts, errors.New("font must be a font family name")
+		}
+		opts.Font = v
+	}
+	if v := q.Get("align"); v != "" {
+		if v != "left" && v != "center" && v != "right" {
+			return opts, errors.New("align must be left, center or right")
+		}
+		opts.Align = v
+	}
+	return opts, nil
+}
+
+// overlayText renders lines one per line, optionally prefixed with their
+// speaker in l's language.
+func overlayText(lines []LiveTranscriptEvent, speakers bool, l *i18n.Localizer) string {
+	var b strings.Builder
+	for _, line := range lines {
+		if speakers {
+			fmt.Fprintf(&b, "%s: ", l.T(i18n.DocSpeaker, line.Speaker))
+		}
+		b.WriteString(strings.ReplaceAll(line.Text, "\n", " "))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func registerOverlayRoutes(mux *http.ServeMux, hub *Hub, l *i18n.Localizer) {
+	// /overlay is for OBS browser sources and other stream overlay tools:
+	// an HTML page styled by the query string by default, the lines
+	// themselves with ?format=text, or a stream of them with ?format=sse.
+	mux.HandleFunc("GET /overlay", func(w http.ResponseWriter, r *http.Request) {
+		opts, err := parseOverlayOptions(r)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		format := r.URL.Query().Get("format")
+		if format == "" && strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+			format = "sse"
+		}
+
+		switch format {
+		case "", "html":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Header().Set("Cache-Control", "no-cache")
+			if err := overlayPage.Execute(w, opts); err != nil {
+				writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("render overlay: %v", err))
+			}
+		case "text":
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			w.Header().Set("Cache-Control", "no-cache")
+			_, _ = w.Write([]byte(overlayText(hub.RecentTranscript(opts.Lines), opts.Speakers, l)))
+		case "sse":
+			streamOverlay(w, r, hub, opts, l)
+		default:
+			writeJSONError(w, http.StatusBadRequest, "format must be html, text or sse")
+		}
+	})
+}
+
+// streamOverlay sends the last lines as one server-sent event whenever they
+// change, starting with the current ones, so a client only ever replaces
+// what it shows.
+func streamOverlay(w http.ResponseWriter, r *http.Request, hub *Hub, opts overlayOptions, l *i18n.Localizer) {
+	ch := hub.Subscribe()
+	defer hub.Unsubscribe(ch)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	rc := http.NewResponseController(w)
+
+	send := func() bool {
+		text := strings.TrimSuffix(overlayText(hub.RecentTranscript(opts.Lines), opts.Speakers, l), "\n")
+		if _, err := fmt.Fprintf(w, "data: %s\n\n", strings.ReplaceAll(text, "\n", "\ndata: ")); err != nil {
+			return false
+		}
+		return rc.Flush() == nil
+	}
+	if !send() {
+		return
+	}
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+			var event Event
+			if json.Unmarshal(msg, &event) != nil {
+				continue
+			}
+			if event.Type != "live_transcript" && event.Type != "session_started" {
+				continue
+			}
+			if !send() {
+				return
+			}
+		}
+	}
+}
+
+var overlayPage = template.Must(template.New("overlay").Parse(`<!doctype html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Ghost Wispr overlay</title>
+<style>
+html, body { margin: 0; background: {{.Background}}; overflow: hidden; }
+#lines {
+  position: fixed; left: 0; right: 0; bottom: 0; padding: 0.5em;
+  font-family: {{.Font}}; font-size: {{.Size}}px; color: {{.Color}}; text-align: {{.Align}};
+  {{if .Shadow}}text-shadow: 0 0 4px #000, 0 0 2px #000;{{end}}
+}
+#lines div { white-space: pre-wrap; }
+</style>
+</head>
+<body>
+<div id="lines"></div>
+<script>
+const params = new URLSearchParams(location.search);
+params.set("format", "sse");
+const lines = document.getElementById("lines");
+new EventSource("/overlay?" + params).onmessage = (e) => {
+  lines.replaceChildren(...e.data.split("\n").filter(Boolean).map((text) => {
+    const div = document.createElement("div");
+    div.textContent = text;
+    return div;
+  }));
+};
+</script>
+</body>
+</html>
+`))
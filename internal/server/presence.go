@@ -0,0 +1,127 @@
+package server
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Viewer is a connected /ws client.
+type Viewer struct {
+	ID          string    `json:"id"`
+	User        string    `json:"user,omitempty"`
+	UserAgent   string    `json:"user_agent"`
+	ConnectedAt time.Time `json:"connected_at"`
+}
+
+// viewerFromRequest describes the client behind a /ws upgrade. The user is
+// taken from the X-Forwarded-User or Remote-User header when the request
+// comes from one of the trusted authenticating proxies, falling back to the
+// API token's name.
+func viewerFromRequest(r *http.Request, trusted []netip.Prefix) Viewer {
+	var user string
+	if fromTrustedProxy(r, trusted) {
+		for _, header := range []string{"X-Forwarded-User", "Remote-User"} {
+			if user == "" {
+				user = r.Header.Get(header)
+			}
+		}
+	}
+	if token, ok := requestToken(r.Context()); ok && user == "" {
+		user = token.Name
+	}
+	return Viewer{
+		User:      truncate(strings.TrimSpace(user), 64),
+		UserAgent: truncate(r.UserAgent(), 256),
+	}
+}
+
+// fromTrustedProxy reports whether the request's peer is within trusted.
+func fromTrustedProxy(r *http.Request, trusted []netip.Prefix) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, p := range trusted {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// AddViewer registers a connected client, assigns its ID and broadcasts the
+// new presence.
+func (h *Hub) AddViewer(v Viewer) Viewer {
+	h.presenceMu.Lock()
+	h.nextViewer++
+	v.ID = strconv.Itoa(h.nextViewer)
+	v.ConnectedAt = time.Now().UTC()
+	h.viewers[v.ID] = v
+	viewers := h.viewerList()
+	h.presenceMu.Unlock()
+
+	h.broadcastPresence("joined", v, viewers)
+	return v
+}
+
+// RemoveViewer unregisters a client and broadcasts the new presence.
+func (h *Hub) RemoveViewer(id string) {
+	h.presenceMu.Lock()
+	v, ok := h.viewers[id]
+	delete(h.viewers, id)
+	viewers := h.viewerList()
+	h.presenceMu.Unlock()
+
+	if ok {
+		h.broadcastPresence("left", v, viewers)
+	}
+}
+
+// Viewers returns the connected clients, oldest connection first.
+func (h *Hub) Viewers() []Viewer {
+	h.presenceMu.Lock()
+	defer h.presenceMu.Unlock()
+	return h.viewerList()
+}
+
+// viewerList snapshots the viewers; callers hold presenceMu.
+func (h *Hub) viewerList() []Viewer {
+	viewers := make([]Viewer, 0, len(h.viewers))
+	for _, v := range h.viewers {
+		viewers = append(viewers, v)
+	}
+	slices.SortFunc(viewers, func(a, b Viewer) int {
+		if c := a.ConnectedAt.Compare(b.ConnectedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return viewers
+}
+
+func (h *Hub) broadcastPresence(change string, v Viewer, viewers []Viewer) {
+	h.broadcastEvent(PresenceEvent{
+		Event:       newEvent("presence", time.Now().UTC()),
+		Change:      change,
+		Viewer:      v,
+		ViewerCount: len(viewers),
+		Viewers:     viewers,
+	})
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
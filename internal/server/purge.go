@@ -0,0 +1,44 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/sjawhar/ghost-wispr/internal/session"
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+)
+
+// Deleting a session purges it: its recordings, clips, exports, Drive
+// copies and the voices learned from it go too, and the report says what
+// was deleted and what could not be.
+func registerPurgeRoute(mux *http.ServeMux, hub *Hub, controls ControlHooks) {
+	mux.HandleFunc("DELETE /api/sessions/{id}", func(w http.ResponseWriter, r *http.Request) {
+		sessionID := r.PathValue("id")
+		if !validSessionID(sessionID) {
+			writeJSONError(w, http.StatusForbidden, "invalid session id")
+			return
+		}
+		if controls.PurgeSession == nil {
+			writeJSONError(w, http.StatusServiceUnavailable, "session deletion not available")
+			return
+		}
+		report, err := controls.PurgeSession(context.WithoutCancel(r.Context()), sessionID)
+		if err != nil {
+			status := http.StatusInternalServerError
+			switch {
+			case errors.Is(err, os.ErrNotExist) || errors.Is(err, sql.ErrNoRows):
+				status = http.StatusNotFound
+			case errors.Is(err, storage.ErrSessionLocked), errors.Is(err, session.ErrSessionRecording):
+				status = http.StatusConflict
+			}
+			writeJSONError(w, status, fmt.Sprintf("delete session: %v", err))
+			return
+		}
+		hub.BroadcastSessionDeleted(sessionID)
+		writeJSON(w, http.StatusOK, report)
+	})
+}
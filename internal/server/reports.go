@@ -0,0 +1,53 @@
+package server
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+)
+
+// ReportStore is implemented by stores that keep weekly reports.
+type ReportStore interface {
+	GetReport(id string) (storage.Report, error)
+	ListReports() ([]storage.Report, error)
+}
+
+func registerReportRoutes(mux *http.ServeMux, store SessionStore) {
+	mux.HandleFunc("GET /api/reports", func(w http.ResponseWriter, r *http.Request) {
+		reports, ok := store.(ReportStore)
+		if !ok {
+			writeJSONError(w, http.StatusNotImplemented, "reports are not supported by this store")
+			return
+		}
+		list, err := reports.ListReports()
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("list reports: %v", err))
+			return
+		}
+		if list == nil {
+			list = []storage.Report{}
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"reports": list})
+	})
+
+	mux.HandleFunc("GET /api/reports/{id}", func(w http.ResponseWriter, r *http.Request) {
+		reports, ok := store.(ReportStore)
+		if !ok {
+			writeJSONError(w, http.StatusNotImplemented, "reports are not supported by this store")
+			return
+		}
+		report, err := reports.GetReport(r.PathValue("id"))
+		if errors.Is(err, sql.ErrNoRows) {
+			writeJSONError(w, http.StatusNotFound, "report not found")
+			return
+		}
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("get report: %v", err))
+			return
+		}
+		writeJSON(w, http.StatusOK, report)
+	})
+}
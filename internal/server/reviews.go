@@ -0,0 +1,64 @@
+package server
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+)
+
+// ReviewTracker is implemented by stores that remember which sessions were
+// opened, so those whose summaries haven't been read can be listed like an
+// inbox.
+type ReviewTracker interface {
+	MarkSessionViewed(sessionID string, at time.Time) error
+	GetUnreviewedSessions() ([]storage.Session, error)
+}
+
+func registerReviewRoutes(mux *http.ServeMux, store SessionStore) {
+	mux.HandleFunc("POST /api/sessions/{id}/viewed", func(w http.ResponseWriter, r *http.Request) {
+		sessionID := r.PathValue("id")
+		if !validSessionID(sessionID) {
+			writeJSONError(w, http.StatusForbidden, "invalid session id")
+			return
+		}
+		tracker, ok := store.(ReviewTracker)
+		if !ok {
+			writeJSONError(w, http.StatusNotImplemented, "view tracking is not supported by this store")
+			return
+		}
+		if err := tracker.MarkSessionViewed(sessionID, time.Now()); err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, os.ErrNotExist) || errors.Is(err, sql.ErrNoRows) {
+				status = http.StatusNotFound
+			}
+			writeJSONError(w, status, fmt.Sprintf("mark session viewed: %v", err))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// unreviewedSessions lists the sessions with unread summaries, newest
+// first, limited to those started on date unless it is empty.
+func unreviewedSessions(store SessionStore, date string) ([]storage.Session, error) {
+	tracker, ok := store.(ReviewTracker)
+	if !ok {
+		return nil, errors.ErrUnsupported
+	}
+	sessions, err := tracker.GetUnreviewedSessions()
+	if err != nil || date == "" {
+		return sessions, err
+	}
+	onDate := sessions[:0]
+	for _, sess := range sessions {
+		if sess.StartedAt.UTC().Format("2006-01-02") == date {
+			onDate = append(onDate, sess)
+		}
+	}
+	return onDate, nil
+}
@@ -0,0 +1,176 @@
+package server
+
+import (
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+
+	"github.com/sjawhar/ghost-wispr/internal/transcribe"
+)
+
+// maxSearchMatches caps how many segments one in-session search returns.
+const maxSearchMatches = 200
+
+// SearchMatch is a segment of a session's transcript containing every term
+// of a search, with the neighbouring segments' text for context.
+type SearchMatch struct {
+	// Segment is the segment's position in the session's transcript, as
+	// returned by GET /api/sessions/{id}.
+	Segment    int          `json:"segment"`
+	Speaker    int          `json:"speaker"`
+	StartTime  float64      `json:"start_time"`
+	EndTime    float64      `json:"end_time"`
+	Text       string       `json:"text"`
+	Highlights []TextRange  `json:"highlights"`
+	Before     *SearchBlurb `json:"before,omitempty"`
+	After      *SearchBlurb `json:"after,omitempty"`
+}
+
+// TextRange is a half-open range of a segment's text in UTF-16 code units,
+// the offsets JavaScript strings use.
+type TextRange struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// SearchBlurb is a neighbouring segment shown around a match.
+type SearchBlurb struct {
+	Speaker int    `json:"speaker"`
+	Text    string `json:"text"`
+}
+
+func registerSearchRoutes(mux *http.ServeMux, store SessionStore) {
+	// Searching one session scans its segments in memory, which is quick
+	// enough for any single meeting and needs nothing from the store.
+	mux.HandleFunc("GET /api/sessions/{id}/search", func(w http.ResponseWriter, r *http.Request) {
+		sessionID := r.PathValue("id")
+		if !validSessionID(sessionID) {
+			writeJSONError(w, http.StatusForbidden, "invalid session id")
+			return
+		}
+		query := r.URL.Query().Get("q")
+		terms := searchTerms(query)
+		if len(terms) == 0 {
+			writeJSONError(w, http.StatusBadRequest, "q is required")
+			return
+		}
+		segments, ok := loadSpeakerSegments(w, store, sessionID)
+		if !ok {
+			return
+		}
+
+		matches, truncated := searchSegments(segments, terms)
+		writeJSON(w, http.StatusOK, map[string]any{
+			"query":     query,
+			"matches":   matches,
+			"truncated": truncated,
+		})
+	})
+}
+
+// searchTerms splits a query into the lowercase words every match must
+// contain.
+func searchTerms(query string) [][]rune {
+	var terms [][]rune
+	for _, field := range strings.Fields(query) {
+		terms = append(terms, foldRunes(field))
+	}
+	return terms
+}
+
+// foldRunes lowercases s one code point at a time, so positions in the
+// result are positions in s.
+func foldRunes(s string) []rune {
+	runes := []rune(s)
+	for i, r := range runes {
+		runes[i] = unicode.ToLower(r)
+	}
+	return runes
+}
+
+// searchSegments finds the segments containing every term, case-insensitively,
+// reporting at most maxSearchMatches of them.
+func searchSegments(segments []transcribe.Segment, terms [][]rune) ([]SearchMatch, bool) {
+	matches := []SearchMatch{}
+	for i, seg := range segments {
+		text := foldRunes(seg.Text)
+		var highlights []TextRange
+		found := true
+		for _, term := range terms {
+			ranges := findAll(text, term)
+			if len(ranges) == 0 {
+				found = false
+				break
+			}
+			highlights = append(highlights, ranges...)
+		}
+		if !found {
+			continue
+		}
+		if len(matches) == maxSearchMatches {
+			return matches, true
+		}
+
+		match := SearchMatch{
+			Segment:    i,
+			Speaker:    seg.Speaker,
+			StartTime:  seg.StartTime,
+			EndTime:    seg.EndTime,
+			Text:       seg.Text,
+			Highlights: utf16Ranges(seg.Text, mergeRanges(highlights)),
+		}
+		if i > 0 {
+			match.Before = &SearchBlurb{Speaker: segments[i-1].Speaker, Text: segments[i-1].Text}
+		}
+		if i+1 < len(segments) {
+			match.After = &SearchBlurb{Speaker: segments[i+1].Speaker, Text: segments[i+1].Text}
+		}
+		matches = append(matches, match)
+	}
+	return matches, false
+}
+
+// findAll returns every non-overlapping occurrence of term in text.
+func findAll(text, term []rune) []TextRange {
+	var ranges []TextRange
+	for i := 0; i+len(term) <= len(text); {
+		if slices.Equal(text[i:i+len(term)], term) {
+			ranges = append(ranges, TextRange{Start: i, End: i + len(term)})
+			i += len(term)
+			continue
+		}
+		i++
+	}
+	return ranges
+}
+
+// utf16Ranges converts ranges of code points in s to ranges of UTF-16 code
+// units, where characters outside the Basic Multilingual Plane take two.
+func utf16Ranges(s string, ranges []TextRange) []TextRange {
+	offsets := []int{0}
+	for _, r := range s {
+		offsets = append(offsets, offsets[len(offsets)-1]+utf16.RuneLen(r))
+	}
+	for i, r := range ranges {
+		ranges[i] = TextRange{Start: offsets[r.Start], End: offsets[r.End]}
+	}
+	return ranges
+}
+
+// mergeRanges sorts ranges and joins the ones that overlap or touch, so
+// terms like "news" and "newsletter" don't produce nested highlights.
+func mergeRanges(ranges []TextRange) []TextRange {
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].Start < ranges[j].Start })
+	merged := ranges[:0]
+	for _, r := range ranges {
+		if n := len(merged); n > 0 && r.Start <= merged[n-1].End {
+			merged[n-1].End = max(merged[n-1].End, r.End)
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
@@ -0,0 +1,150 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/sjawhar/ghost-wispr/internal/i18n"
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+)
+
+func registerSeriesRoutes(mux *http.ServeMux, store SessionStore, l *i18n.Localizer) {
+	mux.HandleFunc("GET /api/series", func(w http.ResponseWriter, r *http.Request) {
+		series, err := store.ListSeries()
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("list series: %v", err))
+			return
+		}
+		writeJSON(w, http.StatusOK, series)
+	})
+
+	mux.HandleFunc("GET /api/series/{id}", func(w http.ResponseWriter, r *http.Request) {
+		seriesID := r.PathValue("id")
+		if !validSessionID(seriesID) {
+			writeJSONError(w, http.StatusForbidden, "invalid series id")
+			return
+		}
+
+		sessions, err := store.GetSeriesSessions(seriesID)
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("get series sessions: %v", err))
+			return
+		}
+		if len(sessions) == 0 {
+			writeJSONError(w, http.StatusNotFound, "series not found")
+			return
+		}
+
+		first, last := sessions[0].StartedAt, sessions[len(sessions)-1].StartedAt
+		decisions, err := store.GetDecisions(storage.DecisionFilter{
+			From: first.UTC().Format("2006-01-02"),
+			To:   last.UTC().Format("2006-01-02"),
+		})
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("get series decisions: %v", err))
+			return
+		}
+		inSeries := make(map[string]bool, len(sessions))
+		for _, sess := range sessions {
+			inSeries[sess.ID] = true
+		}
+		seriesDecisions := make([]storage.Decision, 0, len(decisions))
+		for _, d := range decisions {
+			if inSeries[d.SessionID] {
+				seriesDecisions = append(seriesDecisions, d)
+			}
+		}
+
+		writeJSON(w, http.StatusOK, map[string]any{
+			"series": storage.Series{
+				ID:             seriesID,
+				SessionCount:   len(sessions),
+				FirstStartedAt: first,
+				LastStartedAt:  last,
+			},
+			"sessions":  sessions,
+			"summary":   formatSeriesSummary(sessions),
+			"decisions": seriesDecisions,
+		})
+	})
+
+	// The brief prepares for the next meeting: what was promised last time
+	// and is not done yet. Carried-over items accumulate on the latest
+	// session, so its open items are everything outstanding.
+	mux.HandleFunc("GET /api/series/{id}/brief", func(w http.ResponseWriter, r *http.Request) {
+		seriesID := r.PathValue("id")
+		if !validSessionID(seriesID) {
+			writeJSONError(w, http.StatusForbidden, "invalid series id")
+			return
+		}
+
+		sessions, err := store.GetSeriesSessions(seriesID)
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("get series sessions: %v", err))
+			return
+		}
+		if len(sessions) == 0 {
+			writeJSONError(w, http.StatusNotFound, "series not found")
+			return
+		}
+		last := sessions[len(sessions)-1]
+
+		items, err := store.GetActionItems(last.ID)
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("get action items: %v", err))
+			return
+		}
+		open := make([]storage.ActionItem, 0, len(items))
+		for _, item := range items {
+			if !item.Done {
+				open = append(open, item)
+			}
+		}
+
+		writeJSON(w, http.StatusOK, map[string]any{
+			"series_id":         seriesID,
+			"last_session":      last,
+			"open_action_items": open,
+			"brief":             formatSeriesBrief(last, open, l),
+		})
+	})
+}
+
+// formatSeriesBrief renders the pre-meeting brief as markdown: the open
+// action items followed by the last meeting's summary.
+func formatSeriesBrief(last storage.Session, open []storage.ActionItem, l *i18n.Localizer) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n## %s\n\n", l.T(i18n.DocBriefTitle, last.StartedAt.Local().Format("Mon 2006-01-02 15:04")), l.T(i18n.DocOpenActionItems))
+	if len(open) == 0 {
+		b.WriteString(l.T(i18n.DocNone) + "\n")
+	}
+	for _, item := range open {
+		b.WriteString("- [ ] " + item.Text)
+		if item.Owner != "" {
+			b.WriteString(" — " + item.Owner)
+		}
+		b.WriteString("\n")
+	}
+	if text := strings.TrimSpace(last.Summary); text != "" {
+		fmt.Fprintf(&b, "\n## %s\n\n%s\n", l.T(i18n.DocLastMeeting), text)
+	}
+	return b.String()
+}
+
+// formatSeriesSummary stitches the summaries of a series' sessions into one
+// markdown document, oldest meeting first.
+func formatSeriesSummary(sessions []storage.Session) string {
+	var b strings.Builder
+	for _, sess := range sessions {
+		text := strings.TrimSpace(sess.Summary)
+		if text == "" {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "## %s\n\n%s\n", sess.StartedAt.Local().Format("Mon 2006-01-02 15:04"), text)
+	}
+	return b.String()
+}
@@ -2,13 +2,25 @@ package server
 
 import (
 	"context"
+	"io"
 	"io/fs"
 	"log"
 	"net/http"
+	"net/netip"
 	"path"
 	"strings"
+	"time"
 
 	"github.com/sjawhar/ghost-wispr/internal/config"
+	"github.com/sjawhar/ghost-wispr/internal/dictation"
+	"github.com/sjawhar/ghost-wispr/internal/i18n"
+	"github.com/sjawhar/ghost-wispr/internal/jobs"
+	"github.com/sjawhar/ghost-wispr/internal/session"
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+	"github.com/sjawhar/ghost-wispr/internal/summary"
+	"github.com/sjawhar/ghost-wispr/internal/transcribe"
+	"github.com/sjawhar/ghost-wispr/internal/update"
+	"github.com/sjawhar/ghost-wispr/internal/watchdog"
 )
 
 type ControlHooks struct {
@@ -19,18 +31,169 @@ type ControlHooks struct {
 	Warnings        func() []string
 	Presets         func() map[string]config.Preset
 	Resummarize     func(ctx context.Context, sessionID, preset string) error
+	// EstimateSummary projects the tokens and cost of resummarizing a
+	// session with a preset, per configured model, returning the preset it
+	// resolved to.
+	EstimateSummary func(ctx context.Context, sessionID, preset string) (string, []summary.Estimate, error)
 	EndSession      func(ctx context.Context) error
+	Update          func() *update.Release
+	Jobs            func() []jobs.Stats
+	Resources       func() watchdog.Stats
+	// KeepWAV sets whether the session being recorded keeps its lossless
+	// audio alongside the compressed recording.
+	KeepWAV func(sessionID string, keep bool) error
+	// Bookmark marks the current moment of the active session.
+	Bookmark func(ctx context.Context, note string) (storage.Bookmark, error)
+	// AddNote records a note typed at the given time in the active session.
+	AddNote func(at time.Time, text string) (storage.Note, error)
+	// AudioDevices lists capture devices and the name of the one in use
+	// ("" for the system default).
+	AudioDevices func() ([]AudioDevice, string, error)
+	// SetAudioDevice reopens capture on the named device; "" selects the
+	// system default.
+	SetAudioDevice func(name string) error
+	// MicHealth reports whether capture is running or waiting for its
+	// device to come back; nil leaves it out of /api/status.
+	MicHealth func() MicHealth
+	// TranscriptionStatus reports the live transcription connection; nil, or
+	// an empty State before it first connects, leaves it out of /api/status.
+	TranscriptionStatus func() TranscriptionStatus
+	// BrowserMic claims the browser microphone capture for one web UI
+	// sending mono PCM16-LE at sampleRate. It returns ErrBrowserMicBusy
+	// while another browser is sending, and ErrBrowserMicUnavailable while
+	// the server captures audio itself; nil disables /ws/mic.
+	BrowserMic func(sampleRate int) (io.WriteCloser, error)
+	// BrowserMicStatus reports whether capture comes from a browser; nil
+	// leaves browser_mic out of /api/status.
+	BrowserMicStatus func() BrowserMicStatus
+	// Calibrate measures d of room tone on the capture device in use, then
+	// stores and applies the speech threshold derived from it.
+	Calibrate func(ctx context.Context, d time.Duration) (storage.AudioCalibration, error)
+	// CreateBriefing prepares a brief for an upcoming meeting from related
+	// past sessions and schedules its announcement.
+	CreateBriefing func(ctx context.Context, title string, attendees []string, startsAt, endsAt time.Time) (storage.Briefing, error)
+	// Briefings lists the prepared briefings.
+	Briefings func() ([]storage.Briefing, error)
+	// StartMemo and StopMemo record a one-shot voice memo outside the
+	// silence-timeout session flow, returning its session ID.
+	StartMemo func(ctx context.Context) (string, error)
+	StopMemo  func(ctx context.Context) (string, error)
+	// SummaryQueue lists the summaries waiting for or running on a worker.
+	SummaryQueue func() []session.SummaryJob
+	// CancelSummary stops a session's queued or running summary.
+	CancelSummary func(sessionID string) error
+	// RewriteSummary returns a session's summary rewritten at a reading
+	// level, without summarizing the transcript again.
+	RewriteSummary func(ctx context.Context, sessionID, level string) (string, error)
+	// Localizer renders generated documents; nil renders English.
+	Localizer *i18n.Localizer
+	// TranscriptionLatency reports how far live transcripts lag behind the
+	// audio, per streaming provider, for /api/stats and /metrics.
+	TranscriptionLatency func() []transcribe.LatencyStats
+	// Dictation streams finalized text to /api/dictation/stream while on;
+	// nil disables the dictation endpoints.
+	Dictation *dictation.Dictation
+	// GraphQL serves the read-only /graphql endpoint when set.
+	GraphQL bool
+	// Exports bounds concurrent export generation; nil means unbounded.
+	Exports *jobs.Pool
+	// SessionExports reports how a session fared under each export rule;
+	// nil, with no rules configured, disables the export status endpoints.
+	SessionExports func(sessionID string) ([]storage.Export, error)
+	// RetryExport queues a session's export with the named rule again,
+	// returning an error wrapping export.ErrUnknownRule if there is none.
+	RetryExport func(sessionID, rule string) error
+	// Auth requires scoped API tokens when set; nil leaves the API open.
+	Auth *Auth
+	// AllowedOrigins are the pages, besides the server's own, that may
+	// open WebSockets to it.
+	AllowedOrigins []string
+	// TrustedProxies are the authenticating reverse proxies whose user
+	// headers name a /ws viewer; headers from anyone else are ignored.
+	TrustedProxies []netip.Prefix
+	// RotateKeys replaces provider API keys at runtime, then checks every
+	// provider that has a key and reports how each fared.
+	RotateKeys func(ctx context.Context, keys map[string]string) ([]KeyHealth, error)
+	// ImportAudio reads an uploaded recording named name and starts
+	// transcribing and summarizing it as a finished session that began at
+	// startedAt (zero when unknown); nil, without a batch transcriber,
+	// disables /api/sessions/import-audio.
+	ImportAudio func(ctx context.Context, name string, r io.Reader, startedAt time.Time) error
+	// EnrollVoice learns a voice under name from a recording named
+	// filename; nil, without voice profiles configured, disables enrolling.
+	EnrollVoice func(ctx context.Context, name, filename string, r io.Reader) (storage.VoiceProfile, error)
+	// EnrollSpeakerVoice learns the voice of a speaker in a session under
+	// name, or the name the speaker was given there when it is empty.
+	EnrollSpeakerVoice func(ctx context.Context, sessionID string, speaker int, name string) (storage.VoiceProfile, error)
+	// PurgeSession deletes a session with everything derived from it and
+	// reports what went; nil disables DELETE /api/sessions/{id}.
+	PurgeSession func(ctx context.Context, sessionID string) (session.PurgeReport, error)
+	// Keywords lists the terms boosted in transcription.
+	Keywords func() []string
+	// SetKeywords replaces the boosted terms and reconnects the live stream
+	// with them, returning them as normalized. An error wrapping
+	// transcribe.ErrInvalidKeyword changes nothing; any other error with
+	// keywords returned means only the reconnect failed.
+	SetKeywords func(keywords []string) ([]string, error)
+}
+
+// setPaused pauses or resumes recording and announces the resulting state,
+// which is what IsPaused reports when it is set.
+func (c ControlHooks) setPaused(paused bool) {
+	if paused && c.Pause != nil {
+		c.Pause()
+	}
+	if !paused && c.Resume != nil {
+		c.Resume()
+	}
+	if c.IsPaused != nil {
+		paused = c.IsPaused()
+	}
+	if c.OnStatusChanged != nil {
+		c.OnStatusChanged(paused)
+	}
 }
 
 func Handler(staticFS fs.FS, hub *Hub, store SessionStore, controls ControlHooks) (http.Handler, error) {
 	mux := http.NewServeMux()
 
-	registerWSRoute(mux, hub)
-	registerAPIRoutes(mux, store, controls)
+	registerWSRoute(mux, hub, controls)
+	registerBrowserMicRoute(mux, controls)
+	registerAPIRoutes(mux, store, hub, controls)
+	registerExportRoutes(mux, store, controls)
+	registerExportRuleRoutes(mux, controls)
+	registerBundleRoute(mux, store, controls)
+	registerViewerRoute(mux, store, controls)
+	registerImportAudioRoute(mux, controls)
+	registerVoiceRoutes(mux, store, controls)
+	registerReviewRoutes(mux, store)
+	registerLockRoutes(mux, store)
+	registerClipRoutes(mux, store)
+	registerPurgeRoute(mux, hub, controls)
+	registerDeviceRoutes(mux, controls)
+	registerSeriesRoutes(mux, store, controls.Localizer)
+	registerContextRoutes(mux, store, controls.Localizer)
+	registerBriefingRoutes(mux, controls)
+	registerReportRoutes(mux, store)
+	registerMemoRoutes(mux, store, controls)
+	registerSpeakerRoutes(mux, store, controls)
+	registerSearchRoutes(mux, store)
+	registerOverlayRoutes(mux, hub, controls.Localizer)
+	registerDictationRoutes(mux, controls)
+	registerSummaryRoutes(mux, controls)
+	registerStatsRoutes(mux, controls)
+	registerAdminRoutes(mux, controls)
+	registerKeywordRoutes(mux, controls)
+	if controls.GraphQL {
+		registerGraphQLRoute(mux, store)
+	}
 
 	fileServer := http.FileServer(http.FS(staticFS))
 	mux.HandleFunc("/", serveSPA(staticFS, fileServer))
 
+	if controls.Auth != nil {
+		return controls.Auth.Middleware(mux), nil
+	}
 	return mux, nil
 }
 
@@ -0,0 +1,306 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sjawhar/ghost-wispr/internal/i18n"
+	"github.com/sjawhar/ghost-wispr/internal/session"
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+	"github.com/sjawhar/ghost-wispr/internal/transcribe"
+)
+
+// SpeakerRelabeler is implemented by stores that can rewrite who said each
+// segment of a session.
+type SpeakerRelabeler interface {
+	RelabelSegmentSpeakers(sessionID string, speakers []int) (int, error)
+}
+
+// SpeakerNamer is implemented by stores that keep names for sessions'
+// speakers.
+type SpeakerNamer interface {
+	SetSpeakerName(sessionID string, speaker int, name string) error
+	GetSpeakerNames(sessionID string) (map[int]string, error)
+}
+
+// SpeakerStats is how much of a session one speaker accounts for.
+type SpeakerStats struct {
+	Speaker  int     `json:"speaker"`
+	Name     string  `json:"name,omitempty"`
+	Segments int     `json:"segments"`
+	Seconds  float64 `json:"seconds"`
+}
+
+// speakerEdit is the part of a reassign or merge request that says what to
+// do afterwards: optionally resummarize with the speakers' real names.
+type speakerEdit struct {
+	Resummarize bool           `json:"resummarize"`
+	Preset      string         `json:"preset"`
+	Names       map[int]string `json:"names"`
+}
+
+func registerSpeakerRoutes(mux *http.ServeMux, store SessionStore, controls ControlHooks) {
+	mux.HandleFunc("GET /api/sessions/{id}/speakers", func(w http.ResponseWriter, r *http.Request) {
+		sessionID := r.PathValue("id")
+		if !validSessionID(sessionID) {
+			writeJSONError(w, http.StatusForbidden, "invalid session id")
+			return
+		}
+		segments, ok := loadSpeakerSegments(w, store, sessionID)
+		if !ok {
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"speakers": speakerStats(segments, storedSpeakerNames(store, sessionID))})
+	})
+
+	// Naming a speaker applies to the whole session: the transcript, its
+	// exports and later summaries. An empty name clears it.
+	mux.HandleFunc("PATCH /api/sessions/{id}/speakers/{n}", func(w http.ResponseWriter, r *http.Request) {
+		sessionID := r.PathValue("id")
+		if !validSessionID(sessionID) {
+			writeJSONError(w, http.StatusForbidden, "invalid session id")
+			return
+		}
+		speaker, err := strconv.Atoi(r.PathValue("n"))
+		if err != nil || speaker < 0 {
+			writeJSONError(w, http.StatusBadRequest, "speaker must be a non-negative integer")
+			return
+		}
+		namer, ok := store.(SpeakerNamer)
+		if !ok {
+			writeJSONError(w, http.StatusNotImplemented, "speaker names are not supported by this store")
+			return
+		}
+		var body struct {
+			Name *string `json:"name"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Name == nil {
+			writeJSONError(w, http.StatusBadRequest, "name is required")
+			return
+		}
+		name := strings.TrimSpace(*body.Name)
+		if len(name) > maxSpeakerName {
+			writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("name must be at most %d bytes", maxSpeakerName))
+			return
+		}
+
+		if err := namer.SetSpeakerName(sessionID, speaker, name); err != nil {
+			status := http.StatusInternalServerError
+			switch {
+			case errors.Is(err, os.ErrNotExist) || errors.Is(err, sql.ErrNoRows):
+				status = http.StatusNotFound
+			case errors.Is(err, storage.ErrSessionLocked):
+				status = http.StatusConflict
+			}
+			writeJSONError(w, status, fmt.Sprintf("name speaker: %v", err))
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"speaker": speaker, "name": name})
+	})
+
+	// Segments are addressed by their position in the session's transcript,
+	// as returned by GET /api/sessions/{id}; the range is inclusive.
+	mux.HandleFunc("POST /api/sessions/{id}/speakers/reassign", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			speakerEdit
+			FirstSegment *int `json:"first_segment"`
+			LastSegment  *int `json:"last_segment"`
+			Speaker      *int `json:"speaker"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeJSONError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		if body.FirstSegment == nil || body.LastSegment == nil || body.Speaker == nil {
+			writeJSONError(w, http.StatusBadRequest, "first_segment, last_segment and speaker are required")
+			return
+		}
+		if *body.Speaker < 0 {
+			writeJSONError(w, http.StatusBadRequest, "speaker must not be negative")
+			return
+		}
+		relabelSpeakers(w, r, store, controls, body.speakerEdit, func(segments []transcribe.Segment) ([]int, error) {
+			first, last := *body.FirstSegment, *body.LastSegment
+			if first < 0 || last < first || last >= len(segments) {
+				return nil, fmt.Errorf("segment range %d-%d is outside the session's %d segments", first, last, len(segments))
+			}
+			speakers := currentSpeakers(segments)
+			for i := first; i <= last; i++ {
+				speakers[i] = *body.Speaker
+			}
+			return speakers, nil
+		})
+	})
+
+	mux.HandleFunc("POST /api/sessions/{id}/speakers/merge", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			speakerEdit
+			From *int `json:"from"`
+			Into *int `json:"into"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeJSONError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		if body.From == nil || body.Into == nil {
+			writeJSONError(w, http.StatusBadRequest, "from and into are required")
+			return
+		}
+		if *body.From == *body.Into || *body.Into < 0 {
+			writeJSONError(w, http.StatusBadRequest, "from and into must be different speakers")
+			return
+		}
+		relabelSpeakers(w, r, store, controls, body.speakerEdit, func(segments []transcribe.Segment) ([]int, error) {
+			speakers := currentSpeakers(segments)
+			found := false
+			for i, speaker := range speakers {
+				if speaker == *body.From {
+					speakers[i] = *body.Into
+					found = true
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf("speaker %d has no segments in this session", *body.From)
+			}
+			return speakers, nil
+		})
+	})
+}
+
+// relabelSpeakers applies the speakers computed by edit from the session's
+// current segments and reports the resulting per-speaker stats. Invalid
+// edits are the client's fault and answered with 400.
+func relabelSpeakers(w http.ResponseWriter, r *http.Request, store SessionStore, controls ControlHooks, opts speakerEdit, edit func([]transcribe.Segment) ([]int, error)) {
+	sessionID := r.PathValue("id")
+	if !validSessionID(sessionID) {
+		writeJSONError(w, http.StatusForbidden, "invalid session id")
+		return
+	}
+	relabeler, ok := store.(SpeakerRelabeler)
+	if !ok {
+		writeJSONError(w, http.StatusNotImplemented, "speaker edits are not supported by this store")
+		return
+	}
+	if opts.Resummarize && controls.Resummarize == nil {
+		writeJSONError(w, http.StatusServiceUnavailable, "summarization not configured")
+		return
+	}
+
+	segments, ok := loadSpeakerSegments(w, store, sessionID)
+	if !ok {
+		return
+	}
+	speakers, err := edit(segments)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	version, err := relabeler.RelabelSegmentSpeakers(sessionID, speakers)
+	if err != nil {
+		writeJSONError(w, http.StatusConflict, fmt.Sprintf("relabel speakers: %v", err))
+		return
+	}
+	for i := range segments {
+		segments[i].Speaker = speakers[i]
+	}
+
+	if opts.Resummarize {
+		ctx := session.WithSpeakerNames(context.Background(), opts.Names)
+		go func() {
+			_ = controls.Resummarize(ctx, sessionID, opts.Preset)
+		}()
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"segments_version": version,
+		"speakers":         speakerStats(segments, storedSpeakerNames(store, sessionID)),
+		"resummarizing":    opts.Resummarize,
+	})
+}
+
+// loadSpeakerSegments fetches a session's segments, answering 404 if the
+// session doesn't exist.
+func loadSpeakerSegments(w http.ResponseWriter, store SessionStore, sessionID string) ([]transcribe.Segment, bool) {
+	if _, err := store.GetSession(sessionID); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, os.ErrNotExist) || errors.Is(err, sql.ErrNoRows) {
+			status = http.StatusNotFound
+		}
+		writeJSONError(w, status, fmt.Sprintf("get session: %v", err))
+		return nil, false
+	}
+	segments, err := store.GetSegments(sessionID)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("get session segments: %v", err))
+		return nil, false
+	}
+	return segments, true
+}
+
+func currentSpeakers(segments []transcribe.Segment) []int {
+	speakers := make([]int, len(segments))
+	for i, seg := range segments {
+		speakers[i] = seg.Speaker
+	}
+	return speakers
+}
+
+// speakerStats counts each speaker's segments and speaking time, ordered by
+// speaker index.
+func speakerStats(segments []transcribe.Segment, names map[int]string) []SpeakerStats {
+	byIndex := make(map[int]*SpeakerStats)
+	for _, seg := range segments {
+		stats, ok := byIndex[seg.Speaker]
+		if !ok {
+			stats = &SpeakerStats{Speaker: seg.Speaker, Name: names[seg.Speaker]}
+			byIndex[seg.Speaker] = stats
+		}
+		stats.Segments++
+		stats.Seconds += max(seg.EndTime-seg.StartTime, 0)
+	}
+	out := make([]SpeakerStats, 0, len(byIndex))
+	for _, stats := range byIndex {
+		out = append(out, *stats)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Speaker < out[j].Speaker })
+	return out
+}
+
+// maxSpeakerName bounds a speaker's name, which is printed before every
+// line they said.
+const maxSpeakerName = 100
+
+// storedSpeakerNames returns the names given to a session's speakers, or
+// none if the store doesn't keep them or they can't be read; the speakers
+// are then shown by number.
+func storedSpeakerNames(store SessionStore, sessionID string) map[int]string {
+	namer, ok := store.(SpeakerNamer)
+	if !ok {
+		return nil
+	}
+	names, err := namer.GetSpeakerNames(sessionID)
+	if err != nil {
+		log.Printf("load speaker names for session %s: %v", sessionID, err)
+		return nil
+	}
+	return names
+}
+
+// speakerLabel is a speaker's name, or "Speaker N" in the reader's
+// language when they have none.
+func speakerLabel(l *i18n.Localizer, names map[int]string, speaker int) string {
+	if name := names[speaker]; name != "" {
+		return name
+	}
+	return l.T(i18n.DocSpeaker, speaker)
+}
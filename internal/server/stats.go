@@ -0,0 +1,57 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/sjawhar/ghost-wispr/internal/transcribe"
+)
+
+func registerStatsRoutes(mux *http.ServeMux, controls ControlHooks) {
+	mux.HandleFunc("GET /api/stats", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{"transcription_latency": transcriptionLatency(controls)})
+	})
+
+	// /metrics is in the Prometheus text format, for scraping.
+	mux.HandleFunc("GET /metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_, _ = w.Write([]byte(latencyMetrics(transcriptionLatency(controls))))
+	})
+}
+
+func transcriptionLatency(controls ControlHooks) []transcribe.LatencyStats {
+	if controls.TranscriptionLatency == nil {
+		return []transcribe.LatencyStats{}
+	}
+	stats := controls.TranscriptionLatency()
+	if stats == nil {
+		stats = []transcribe.LatencyStats{}
+	}
+	return stats
+}
+
+// latencyMetrics renders transcription latency as a Prometheus summary.
+func latencyMetrics(stats []transcribe.LatencyStats) string {
+	const name = "ghost_wispr_transcription_latency_seconds"
+	var b strings.Builder
+	fmt.Fprintf(&b, "# HELP %s Delay from capturing audio to receiving its final transcript.\n", name)
+	fmt.Fprintf(&b, "# TYPE %s summary\n", name)
+	for _, s := range stats {
+		provider := strconv.Quote(s.Provider)
+		for _, q := range []struct {
+			quantile string
+			ms       float64
+		}{{"0.5", s.P50}, {"0.9", s.P90}, {"0.99", s.P99}} {
+			fmt.Fprintf(&b, "%s{provider=%s,quantile=%q} %s\n", name, provider, q.quantile, formatMetric(q.ms/1000))
+		}
+		fmt.Fprintf(&b, "%s_sum{provider=%s} %s\n", name, provider, formatMetric(s.Sum))
+		fmt.Fprintf(&b, "%s_count{provider=%s} %d\n", name, provider, s.Count)
+	}
+	return b.String()
+}
+
+func formatMetric(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
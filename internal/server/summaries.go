@@ -0,0 +1,118 @@
+package server
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/sjawhar/ghost-wispr/internal/config"
+	"github.com/sjawhar/ghost-wispr/internal/session"
+	"github.com/sjawhar/ghost-wispr/internal/summary"
+)
+
+func registerSummaryRoutes(mux *http.ServeMux, controls ControlHooks) {
+	mux.HandleFunc("GET /api/summaries/queue", func(w http.ResponseWriter, r *http.Request) {
+		if controls.SummaryQueue == nil {
+			writeJSONError(w, http.StatusServiceUnavailable, "summary queue not available")
+			return
+		}
+		jobs := controls.SummaryQueue()
+		if jobs == nil {
+			jobs = []session.SummaryJob{}
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"jobs": jobs})
+	})
+
+	mux.HandleFunc("POST /api/summaries/{id}/cancel", func(w http.ResponseWriter, r *http.Request) {
+		if controls.CancelSummary == nil {
+			writeJSONError(w, http.StatusServiceUnavailable, "summary queue not available")
+			return
+		}
+		sessionID := r.PathValue("id")
+		if !validSessionID(sessionID) {
+			writeJSONError(w, http.StatusForbidden, "invalid session id")
+			return
+		}
+		if err := controls.CancelSummary(sessionID); err != nil {
+			if errors.Is(err, session.ErrNoSummaryJob) {
+				writeJSONError(w, http.StatusNotFound, err.Error())
+			} else {
+				writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("cancel summary: %v", err))
+			}
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"status": "cancelled"})
+	})
+
+	mux.HandleFunc("GET /api/sessions/{id}/summarize/estimate", func(w http.ResponseWriter, r *http.Request) {
+		if controls.EstimateSummary == nil {
+			writeJSONError(w, http.StatusServiceUnavailable, "summarization not configured")
+			return
+		}
+		sessionID := r.PathValue("id")
+		if !validSessionID(sessionID) {
+			writeJSONError(w, http.StatusForbidden, "invalid session id")
+			return
+		}
+		preset, estimates, err := controls.EstimateSummary(r.Context(), sessionID, r.URL.Query().Get("preset"))
+		switch {
+		case errors.Is(err, os.ErrNotExist), errors.Is(err, sql.ErrNoRows):
+			writeJSONError(w, http.StatusNotFound, "session not found")
+			return
+		case errors.Is(err, summary.ErrUnknownPreset), errors.Is(err, summary.ErrPresetRequired):
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		case errors.Is(err, session.ErrSummarizationUnavailable):
+			writeJSONError(w, http.StatusServiceUnavailable, err.Error())
+			return
+		case err != nil:
+			writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("estimate summary: %v", err))
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"preset": preset, "estimates": estimates})
+	})
+
+	mux.HandleFunc("POST /api/sessions/{id}/summary/rewrite", func(w http.ResponseWriter, r *http.Request) {
+		if controls.RewriteSummary == nil {
+			writeJSONError(w, http.StatusServiceUnavailable, "summarization not configured")
+			return
+		}
+		sessionID := r.PathValue("id")
+		if !validSessionID(sessionID) {
+			writeJSONError(w, http.StatusForbidden, "invalid session id")
+			return
+		}
+		var body struct {
+			ReadingLevel string `json:"reading_level"`
+		}
+		if err := json.NewDecoder(io.LimitReader(r.Body, 4<<10)).Decode(&body); err != nil && err != io.EOF {
+			writeJSONError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		if body.ReadingLevel == "" || !summary.ValidReadingLevel(body.ReadingLevel) {
+			writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("reading_level must be one of %s", strings.Join(config.ReadingLevels, ", ")))
+			return
+		}
+		rewritten, err := controls.RewriteSummary(r.Context(), sessionID, body.ReadingLevel)
+		switch {
+		case errors.Is(err, os.ErrNotExist), errors.Is(err, sql.ErrNoRows):
+			writeJSONError(w, http.StatusNotFound, "session not found")
+			return
+		case errors.Is(err, session.ErrNoSummary):
+			writeJSONError(w, http.StatusConflict, "session has no summary to rewrite; resummarize it with reading_level instead")
+			return
+		case errors.Is(err, session.ErrSummarizationUnavailable):
+			writeJSONError(w, http.StatusServiceUnavailable, err.Error())
+			return
+		case err != nil:
+			writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("rewrite summary: %v", err))
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"reading_level": body.ReadingLevel, "summary": rewritten})
+	})
+}
@@ -0,0 +1,238 @@
+package server
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/sjawhar/ghost-wispr/internal/i18n"
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+	"github.com/sjawhar/ghost-wispr/internal/transcribe"
+)
+
+// maxViewerAudio caps the recording embedded in a viewer export; base64
+// makes the page a third larger again, and browsers and mail clients choke
+// well before files reach gigabytes.
+const maxViewerAudio = 64 << 20
+
+// registerViewerRoute serves GET /api/sessions/{id}/export?format=html: one
+// self-contained HTML file with the summary and transcript, and with
+// ?audio=true the recording as a data URI, that can be archived or mailed
+// and opened without the app running. format=srt and format=vtt give the
+// transcript as captions instead.
+func registerViewerRoute(mux *http.ServeMux, store SessionStore, controls ControlHooks) {
+	mux.HandleFunc("GET /api/sessions/{id}/export", func(w http.ResponseWriter, r *http.Request) {
+		sessionID := r.PathValue("id")
+		if !validSessionID(sessionID) {
+			writeJSONError(w, http.StatusForbidden, "invalid session id")
+			return
+		}
+		q := r.URL.Query()
+		format := q.Get("format")
+		if format != "" && format != "html" && format != "srt" && format != "vtt" {
+			writeJSONError(w, http.StatusBadRequest, "format must be html, srt or vtt")
+			return
+		}
+		withAudio := false
+		if v := q.Get("audio"); v != "" {
+			var err error
+			if withAudio, err = strconv.ParseBool(v); err != nil {
+				writeJSONError(w, http.StatusBadRequest, "audio must be true or false")
+				return
+			}
+		}
+
+		sessionData, err := store.GetSession(sessionID)
+		if err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, os.ErrNotExist) || errors.Is(err, sql.ErrNoRows) {
+				status = http.StatusNotFound
+			}
+			writeJSONError(w, status, fmt.Sprintf("get session: %v", err))
+			return
+		}
+		segments, err := store.GetSegments(sessionID)
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("get session segments: %v", err))
+			return
+		}
+		if format == "srt" || format == "vtt" {
+			write, contentType := writeSRT, "application/x-subrip; charset=utf-8"
+			if format == "vtt" {
+				write, contentType = writeVTT, "text/vtt; charset=utf-8"
+			}
+			w.Header().Set("Content-Type", contentType)
+			w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "session-"+sessionID+"."+format))
+			if err := write(w, segments, sessionData.AudioOffset, storedSpeakerNames(store, sessionID), controls.Localizer); err != nil {
+				log.Printf("%s export for session %s failed: %v", format, sessionID, err)
+			}
+			return
+		}
+
+		var audio *os.File
+		if withAudio {
+			cleanPath, ok := cleanAudioPath(sessionData.AudioPath)
+			if sessionData.AudioPath == "" || !ok {
+				writeJSONError(w, http.StatusNotFound, "session has no audio")
+				return
+			}
+			audio, err = os.Open(cleanPath)
+			if errors.Is(err, os.ErrNotExist) {
+				writeJSONError(w, http.StatusNotFound, "audio file not found")
+				return
+			}
+			if err != nil {
+				writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("open audio: %v", err))
+				return
+			}
+			defer func() { _ = audio.Close() }()
+			info, err := audio.Stat()
+			if err != nil {
+				writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("stat audio: %v", err))
+				return
+			}
+			if info.Size() > maxViewerAudio {
+				writeJSONError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("audio is larger than %d MB; export without it", maxViewerAudio>>20))
+				return
+			}
+		}
+
+		release, err := controls.Exports.Acquire(r.Context())
+		if err != nil {
+			return
+		}
+		defer release()
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "session-"+sessionID+".html"))
+		w.Header().Set("Cache-Control", "no-store")
+		if err := writeViewer(w, sessionData, segments, storedSpeakerNames(store, sessionID), audio, controls.Localizer); err != nil {
+			// Headers are already sent; truncating the body is all that's left.
+			log.Printf("html export for session %s failed: %v", sessionID, err)
+		}
+	})
+}
+
+// viewerLine is one transcript segment as the viewer shows it.
+type viewerLine struct {
+	Speaker string
+	Offset  string
+	Start   float64
+	Text    string
+}
+
+type viewerPage struct {
+	Lang            string
+	Title           string
+	Started         string
+	SummaryHeading  string
+	Summary         string
+	NoSummary       string
+	TranscriptTitle string
+	Lines           []viewerLine
+	// AudioType is set when the recording follows the header.
+	AudioType string
+}
+
+// writeViewer renders the page, streaming the audio's base64 between the
+// header and the transcript so it is never held in memory whole.
+func writeViewer(w io.Writer, sess storage.Session, segments []transcribe.Segment, names map[int]string, audio *os.File, l *i18n.Localizer) error {
+	page := viewerPage{
+		Lang:            l.Locale(),
+		Title:           l.T(i18n.DocSessionTitle, sess.ID),
+		Started:         l.T(i18n.DocStarted, sess.StartedAt.UTC().Format("2006-01-02 15:04 MST")),
+		SummaryHeading:  l.T(i18n.DocSummary),
+		Summary:         strings.TrimSpace(sess.Summary),
+		NoSummary:       l.T(i18n.DocNoSummary),
+		TranscriptTitle: l.T(i18n.DocTranscript),
+	}
+	for _, seg := range segments {
+		if strings.TrimSpace(seg.Text) == "" {
+			continue
+		}
+		offset := int(seg.StartTime)
+		page.Lines = append(page.Lines, viewerLine{
+			Speaker: speakerLabel(l, names, seg.Speaker),
+			Offset:  fmt.Sprintf("%02d:%02d:%02d", offset/3600, offset/60%60, offset%60),
+			Start:   seg.StartTime,
+			Text:    seg.Text,
+		})
+	}
+	if audio != nil {
+		page.AudioType = contentTypeForAudio(audio.Name())
+	}
+
+	if err := viewerTemplate.ExecuteTemplate(w, "head", page); err != nil {
+		return err
+	}
+	if audio != nil {
+		if _, err := fmt.Fprintf(w, `<audio id="audio" controls preload="metadata" src="data:%s;base64,`, page.AudioType); err != nil {
+			return err
+		}
+		enc := base64.NewEncoder(base64.StdEncoding, w)
+		if _, err := io.Copy(enc, audio); err != nil {
+			return err
+		}
+		if err := enc.Close(); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, "\"></audio>\n"); err != nil {
+			return err
+		}
+	}
+	return viewerTemplate.ExecuteTemplate(w, "body", page)
+}
+
+var viewerTemplate = template.Must(template.New("viewer").Parse(`{{define "head"}}<!doctype html>
+<html lang="{{.Lang}}">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="generator" content="Ghost Wispr">
+<title>{{.Title}}</title>
+<style>
+body { font-family: system-ui, sans-serif; line-height: 1.5; max-width: 48rem; margin: 2rem auto; padding: 0 1rem; color: #1f2328; }
+header p { color: #59636e; margin-top: 0; }
+audio { width: 100%; position: sticky; top: 0; background: #fff; }
+.summary { white-space: pre-wrap; background: #f6f8fa; border-radius: 6px; padding: 1rem; }
+.line { margin: 0 0 0.75rem; }
+.line .meta { color: #59636e; font-size: 0.85em; margin-right: 0.5rem; }
+.line a { color: inherit; text-decoration: none; }
+.line a:hover { text-decoration: underline; }
+@media (prefers-color-scheme: dark) {
+  body, audio { background: #0d1117; color: #e6edf3; }
+  header p, .line .meta { color: #9198a1; }
+  .summary { background: #161b22; }
+}
+</style>
+</head>
+<body>
+<header>
+<h1>{{.Title}}</h1>
+<p>{{.Started}}</p>
+</header>
+{{end}}{{define "body"}}<h2>{{.SummaryHeading}}</h2>
+{{if .Summary}}<div class="summary">{{.Summary}}</div>{{else}}<p>{{.NoSummary}}</p>{{end}}
+<h2>{{.TranscriptTitle}}</h2>
+{{range .Lines}}<p class="line"><span class="meta">{{.Speaker}} {{if $.AudioType}}<a href="#" data-start="{{.Start}}">[{{.Offset}}]</a>{{else}}[{{.Offset}}]{{end}}</span>{{.Text}}</p>
+{{end}}{{if .AudioType}}<script>
+document.addEventListener('click', (event) => {
+  const link = event.target.closest('a[data-start]')
+  if (!link) return
+  event.preventDefault()
+  const audio = document.getElementById('audio')
+  audio.currentTime = Number(link.dataset.start)
+  audio.play()
+})
+</script>
+{{end}}</body>
+</html>
+{{end}}`))
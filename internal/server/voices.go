@@ -0,0 +1,157 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/sjawhar/ghost-wispr/internal/ingest"
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+	"github.com/sjawhar/ghost-wispr/internal/voiceprint"
+)
+
+// maxVoiceUpload caps an enrollment recording; a minute or two of speech
+// is plenty.
+const maxVoiceUpload = 64 << 20
+
+// VoiceProfileStore is implemented by stores that keep enrolled voices.
+type VoiceProfileStore interface {
+	ListVoiceProfiles() ([]storage.VoiceProfile, error)
+	DeleteVoiceProfile(id int64) error
+}
+
+func registerVoiceRoutes(mux *http.ServeMux, store SessionStore, controls ControlHooks) {
+	mux.HandleFunc("GET /api/voices", func(w http.ResponseWriter, r *http.Request) {
+		voices, ok := store.(VoiceProfileStore)
+		if !ok {
+			writeJSONError(w, http.StatusNotImplemented, "voice profiles are not supported by this store")
+			return
+		}
+		profiles, err := voices.ListVoiceProfiles()
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, fmt.Sprintf("list voice profiles: %v", err))
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"voices": profiles})
+	})
+
+	// Enrolling takes a short recording of one person talking as the
+	// multipart field "file", named by ?name=. The recording is not kept.
+	mux.HandleFunc("POST /api/voices", func(w http.ResponseWriter, r *http.Request) {
+		if controls.EnrollVoice == nil {
+			writeJSONError(w, http.StatusServiceUnavailable, "voice profiles not configured")
+			return
+		}
+		name := r.URL.Query().Get("name")
+
+		r.Body = http.MaxBytesReader(w, r.Body, maxVoiceUpload)
+		parts, err := r.MultipartReader()
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "expected a multipart/form-data upload")
+			return
+		}
+		for {
+			part, err := parts.NextPart()
+			if errors.Is(err, io.EOF) {
+				writeJSONError(w, http.StatusBadRequest, "missing file")
+				return
+			}
+			if err != nil {
+				writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("read upload: %v", err))
+				return
+			}
+			if part.FormName() != "file" {
+				continue
+			}
+			if !ingest.IsAudioFile(part.FileName()) {
+				writeJSONError(w, http.StatusUnsupportedMediaType, "file must be an audio recording such as MP3, WAV or WebM")
+				return
+			}
+			profile, err := controls.EnrollVoice(context.WithoutCancel(r.Context()), name, part.FileName(), part)
+			if err != nil {
+				writeVoiceError(w, err)
+				return
+			}
+			writeJSON(w, http.StatusCreated, profile)
+			return
+		}
+	})
+
+	mux.HandleFunc("DELETE /api/voices/{id}", func(w http.ResponseWriter, r *http.Request) {
+		voices, ok := store.(VoiceProfileStore)
+		if !ok {
+			writeJSONError(w, http.StatusNotImplemented, "voice profiles are not supported by this store")
+			return
+		}
+		id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "invalid voice id")
+			return
+		}
+		if err := voices.DeleteVoiceProfile(id); err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, sql.ErrNoRows) {
+				status = http.StatusNotFound
+			}
+			writeJSONError(w, status, fmt.Sprintf("delete voice profile: %v", err))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	// A speaker heard in a session can be enrolled from its audio, under
+	// the name in the body or the one they were given in the session.
+	mux.HandleFunc("POST /api/sessions/{id}/speakers/{n}/voice", func(w http.ResponseWriter, r *http.Request) {
+		sessionID := r.PathValue("id")
+		if !validSessionID(sessionID) {
+			writeJSONError(w, http.StatusForbidden, "invalid session id")
+			return
+		}
+		speaker, err := strconv.Atoi(r.PathValue("n"))
+		if err != nil || speaker < 0 {
+			writeJSONError(w, http.StatusBadRequest, "speaker must be a non-negative integer")
+			return
+		}
+		if controls.EnrollSpeakerVoice == nil {
+			writeJSONError(w, http.StatusServiceUnavailable, "voice profiles not configured")
+			return
+		}
+		var body struct {
+			Name string `json:"name"`
+		}
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				writeJSONError(w, http.StatusBadRequest, "invalid request body")
+				return
+			}
+		}
+		profile, err := controls.EnrollSpeakerVoice(context.WithoutCancel(r.Context()), sessionID, speaker, body.Name)
+		if err != nil {
+			writeVoiceError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, profile)
+	})
+}
+
+func writeVoiceError(w http.ResponseWriter, err error) {
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		writeJSONError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("upload is larger than %d MB", maxVoiceUpload>>20))
+	case errors.Is(err, voiceprint.ErrNoName):
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, voiceprint.ErrNoSpeech):
+		writeJSONError(w, http.StatusUnprocessableEntity, err.Error())
+	case errors.Is(err, os.ErrNotExist) || errors.Is(err, sql.ErrNoRows):
+		writeJSONError(w, http.StatusNotFound, err.Error())
+	default:
+		writeJSONError(w, http.StatusBadGateway, fmt.Sprintf("enroll voice: %v", err))
+	}
+}
@@ -1,26 +1,110 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"slices"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
+
+	"github.com/sjawhar/ghost-wispr/internal/session"
 )
 
-var upgrader = websocket.Upgrader{
-	CheckOrigin: func(r *http.Request) bool { return true },
+// wsCommand is a command sent by a client over /ws. Each one is answered
+// with an ack event carrying the same ID.
+type wsCommand struct {
+	ID      string `json:"id"`
+	Command string `json:"command"`
+	// Note is the bookmark text for "bookmark".
+	Note string `json:"note"`
+	// Events lists the event types to receive for "subscribe"; empty means all.
+	Events []string `json:"events"`
+	// InterimMs, if set on "subscribe", changes how often interim
+	// transcripts are sent; see parseInterimInterval.
+	InterimMs *int `json:"interim_ms"`
+}
+
+// maxInterimInterval bounds interim coalescing; past a few seconds interim
+// transcripts stop being live.
+const maxInterimInterval = 10 * time.Second
+
+// parseInterimInterval validates an interim_ms setting: interim transcripts
+// are sent at most once per that many milliseconds, 0 sending each one.
+func parseInterimInterval(ms int) (time.Duration, error) {
+	d := time.Duration(ms) * time.Millisecond
+	if d < 0 || d > maxInterimInterval {
+		return 0, fmt.Errorf("interim_ms must be between 0 and %d", maxInterimInterval.Milliseconds())
+	}
+	return d, nil
+}
+
+// wsClient is the per-connection state of a /ws client.
+type wsClient struct {
+	// scope is what the connection's token allows; commands other than
+	// subscribe need ScopeControl.
+	scope Scope
+
+	mu     sync.Mutex
+	events []string
+	// interimEvery coalesces interim transcripts to one per interval, the
+	// latest, for clients such as phones that would rather not wake for
+	// each one. Zero sends them all.
+	interimEvery time.Duration
+}
+
+// wants reports whether an event type passes the client's subscribe filter.
+// Payloads whose type can't be read have an empty one and always pass.
+func (c *wsClient) wants(eventType string) bool {
+	c.mu.Lock()
+	events := c.events
+	c.mu.Unlock()
+	return len(events) == 0 || eventType == "" || slices.Contains(events, eventType)
+}
+
+func (c *wsClient) interimInterval() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.interimEvery
+}
+
+// eventType reads the type of a broadcast payload.
+func eventType(msg []byte) string {
+	var head struct {
+		Type string `json:"type"`
+	}
+	_ = json.Unmarshal(msg, &head)
+	return head.Type
 }
 
-func registerWSRoute(mux *http.ServeMux, hub *Hub) {
+func registerWSRoute(mux *http.ServeMux, hub *Hub, controls ControlHooks) {
+	upgrader := newUpgrader(controls.AllowedOrigins)
 	mux.HandleFunc("GET /ws", func(w http.ResponseWriter, r *http.Request) {
+		var interimEvery time.Duration
+		if v := r.URL.Query().Get("interim_ms"); v != "" {
+			ms, err := strconv.Atoi(v)
+			if err == nil {
+				interimEvery, err = parseInterimInterval(ms)
+			}
+			if err != nil {
+				writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("interim_ms must be between 0 and %d", maxInterimInterval.Milliseconds()))
+				return
+			}
+		}
+
 		conn, err := upgrader.Upgrade(w, r, nil)
 		if err != nil {
 			log.Printf("ws upgrade error: %v", err)
 			return
 		}
 		defer func() { _ = conn.Close() }()
+		conn.SetReadLimit(64 << 10)
 
 		connectionEvent := ConnectionEvent{
 			Event:     newEvent("connection", time.Now().UTC()),
@@ -34,10 +118,196 @@ func registerWSRoute(mux *http.ServeMux, hub *Hub) {
 		ch := hub.Subscribe()
 		defer hub.Unsubscribe(ch)
 
-		for msg := range ch {
+		viewer := hub.AddViewer(viewerFromRequest(r, controls.TrustedProxies))
+		defer hub.RemoveViewer(viewer.ID)
+
+		// Only this goroutine writes to conn; acks come back through replies.
+		client := &wsClient{scope: requestScope(r.Context()), interimEvery: interimEvery}
+		token, _ := requestToken(r.Context())
+		replies := make(chan []byte, 16)
+		stop := make(chan struct{})
+		done := make(chan struct{})
+		defer close(stop)
+		go func() {
+			defer close(done)
+			for {
+				_, data, err := conn.ReadMessage()
+				if err != nil {
+					return
+				}
+				ack := client.handle(data, hub, controls)
+				controls.Auth.record(token.Name, "WS", "/ws#"+ack.Command, ackStatus(ack), r.RemoteAddr)
+				payload, err := json.Marshal(ack)
+				if err != nil {
+					continue
+				}
+				select {
+				case replies <- payload:
+				case <-stop:
+					return
+				}
+			}
+		}()
+
+		// An interim transcript arriving too soon after the last one is held,
+		// replacing any held before it, until flush fires. Finals are never
+		// held and supersede a held interim; both come through ch, in order,
+		// so an interim is never sent after its final.
+		var (
+			held        []byte
+			lastInterim time.Time
+			flushTimer  *time.Timer
+			flush       <-chan time.Time
+		)
+		defer func() {
+			if flushTimer != nil {
+				flushTimer.Stop()
+			}
+		}()
+		for {
+			var msg []byte
+			select {
+			case m, ok := <-ch:
+				if !ok {
+					return
+				}
+				kind := eventType(m)
+				if !client.wants(kind) {
+					continue
+				}
+				switch every := max(hub.InterimInterval(), client.interimInterval()); {
+				case kind == "live_transcript_interim" && every > 0:
+					if wait := every - time.Since(lastInterim); wait > 0 {
+						held = m
+						if flush == nil {
+							flushTimer = time.NewTimer(wait)
+							flush = flushTimer.C
+						}
+						continue
+					}
+					lastInterim = time.Now()
+				case kind == "live_transcript" && held != nil:
+					held = nil
+					flushTimer.Stop()
+					flush = nil
+				}
+				msg = m
+			case <-flush:
+				flush = nil
+				if held == nil {
+					continue
+				}
+				msg, held = held, nil
+				lastInterim = time.Now()
+			case msg = <-replies:
+			case <-done:
+				return
+			}
 			if err := conn.WriteMessage(websocket.TextMessage, msg); err != nil {
 				return
 			}
 		}
 	})
 }
+
+// handle runs one client command with the same effect as its REST
+// equivalent and returns the ack to send back.
+func (c *wsClient) handle(data []byte, hub *Hub, controls ControlHooks) CommandAckEvent {
+	var cmd wsCommand
+	if err := json.Unmarshal(data, &cmd); err != nil {
+		return commandAck(cmd, errors.New("invalid command: expected a JSON object"), nil)
+	}
+
+	if cmd.Command != "subscribe" && c.scope < ScopeControl {
+		return commandAck(cmd, errCommandForbidden, nil)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	switch cmd.Command {
+	case "pause", "resume":
+		controls.setPaused(cmd.Command == "pause")
+		return commandAck(cmd, nil, nil)
+	case "end_session":
+		if controls.EndSession == nil {
+			return commandAck(cmd, errors.New("session management not available"), nil)
+		}
+		err := controls.EndSession(ctx)
+		if errors.Is(err, session.ErrNoActiveSession) {
+			err = errors.New("no active session")
+		}
+		return commandAck(cmd, err, nil)
+	case "bookmark":
+		if controls.Bookmark == nil {
+			return commandAck(cmd, errors.New("bookmarks not available"), nil)
+		}
+		b, err := controls.Bookmark(ctx, cmd.Note)
+		if errors.Is(err, session.ErrNoActiveSession) {
+			return commandAck(cmd, errors.New("no active session"), nil)
+		}
+		if err != nil {
+			return commandAck(cmd, err, nil)
+		}
+		hub.BroadcastBookmarkAdded(b)
+		return commandAck(cmd, nil, b)
+	case "memo_start", "memo_stop":
+		memo := controls.StartMemo
+		if cmd.Command == "memo_stop" {
+			memo = controls.StopMemo
+		}
+		if memo == nil {
+			return commandAck(cmd, errors.New("voice memos not available"), nil)
+		}
+		sessionID, err := memo(ctx)
+		if err != nil {
+			return commandAck(cmd, err, nil)
+		}
+		return commandAck(cmd, nil, map[string]string{"session_id": sessionID})
+	case "subscribe":
+		var interimEvery time.Duration
+		if cmd.InterimMs != nil {
+			d, err := parseInterimInterval(*cmd.InterimMs)
+			if err != nil {
+				return commandAck(cmd, err, nil)
+			}
+			interimEvery = d
+		}
+		c.mu.Lock()
+		c.events = slices.Clone(cmd.Events)
+		if cmd.InterimMs != nil {
+			c.interimEvery = interimEvery
+		}
+		interimEvery = c.interimEvery
+		c.mu.Unlock()
+		return commandAck(cmd, nil, map[string]any{"events": cmd.Events, "interim_ms": interimEvery.Milliseconds()})
+	}
+	return commandAck(cmd, errors.New("unknown command"), nil)
+}
+
+var errCommandForbidden = errors.New("token lacks control scope")
+
+// ackStatus is the HTTP-style status recorded in the access log for a command.
+func ackStatus(ack CommandAckEvent) int {
+	switch {
+	case ack.OK:
+		return http.StatusOK
+	case ack.Error == errCommandForbidden.Error():
+		return http.StatusForbidden
+	}
+	return http.StatusBadRequest
+}
+
+func commandAck(cmd wsCommand, err error, data any) CommandAckEvent {
+	ack := CommandAckEvent{
+		Event:   newEvent("ack", time.Now().UTC()),
+		ID:      cmd.ID,
+		Command: cmd.Command,
+		OK:      err == nil,
+		Data:    data,
+	}
+	if err != nil {
+		ack.Error = err.Error()
+	}
+	return ack
+}
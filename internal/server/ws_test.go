@@ -1,10 +1,22 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/gorilla/websocket"
+
+	"github.com/sjawhar/ghost-wispr/internal/session"
+	"github.com/sjawhar/ghost-wispr/internal/storage"
 	"github.com/sjawhar/ghost-wispr/internal/transcribe"
 )
 
@@ -40,3 +52,386 @@ func TestWSBroadcastEventShape(t *testing.T) {
 		t.Fatal("timeout waiting for websocket broadcast")
 	}
 }
+
+func dialWS(t *testing.T, controls ControlHooks, hub *Hub) *websocket.Conn {
+	t.Helper()
+	h, err := Handler(testStaticFS(t), hub, apiStoreStub{}, controls)
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/ws", nil)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	var connected map[string]any
+	if err := conn.ReadJSON(&connected); err != nil || connected["type"] != "connection" {
+		t.Fatalf("expected connection event, got %v (%v)", connected, err)
+	}
+	if presence := readEvent(t, conn); presence["type"] != "presence" {
+		t.Fatalf("expected presence event, got %v", presence)
+	}
+	return conn
+}
+
+func readEvent(t *testing.T, conn *websocket.Conn) map[string]any {
+	t.Helper()
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var event map[string]any
+	if err := conn.ReadJSON(&event); err != nil {
+		t.Fatalf("read event failed: %v", err)
+	}
+	return event
+}
+
+func TestWSCommandsAreAcknowledged(t *testing.T) {
+	hub := NewHub()
+	var paused atomic.Bool
+	conn := dialWS(t, ControlHooks{
+		Pause:           func() { paused.Store(true) },
+		OnStatusChanged: hub.BroadcastStatusChanged,
+		Bookmark: func(_ context.Context, note string) (storage.Bookmark, error) {
+			return storage.Bookmark{ID: 7, SessionID: "s1", Offset: 12, Note: note}, nil
+		},
+		EndSession: func(context.Context) error { return session.ErrNoActiveSession },
+		StartMemo:  func(context.Context) (string, error) { return "memo1", nil },
+	}, hub)
+
+	if err := conn.WriteJSON(map[string]any{"id": "1", "command": "pause"}); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	// The status broadcast and the ack may arrive in either order.
+	seen := map[string]map[string]any{}
+	for len(seen) < 2 {
+		event := readEvent(t, conn)
+		seen[event["type"].(string)] = event
+	}
+	if ack := seen["ack"]; ack["id"] != "1" || ack["ok"] != true || !paused.Load() {
+		t.Fatalf("unexpected pause ack %v (paused %v)", ack, paused.Load())
+	}
+	if seen["status_changed"]["paused"] != true {
+		t.Fatalf("expected status_changed broadcast, got %v", seen)
+	}
+
+	_ = conn.WriteJSON(map[string]any{"id": "2", "command": "subscribe", "events": []string{"ack", "bookmark_added"}})
+	if ack := readEvent(t, conn); ack["id"] != "2" || ack["ok"] != true {
+		t.Fatalf("unexpected subscribe ack %v", ack)
+	}
+
+	_ = conn.WriteJSON(map[string]any{"id": "3", "command": "end_session"})
+	if ack := readEvent(t, conn); ack["id"] != "3" || ack["ok"] != false || ack["error"] != "no active session" {
+		t.Fatalf("unexpected end_session ack %v", ack)
+	}
+
+	hub.BroadcastStatusChanged(false) // filtered out by the subscription
+	_ = conn.WriteJSON(map[string]any{"id": "4", "command": "bookmark", "note": "decision"})
+	seen = map[string]map[string]any{}
+	for len(seen) < 2 {
+		event := readEvent(t, conn)
+		if event["type"] == "status_changed" {
+			t.Fatalf("expected status_changed to be filtered, got %v", event)
+		}
+		seen[event["type"].(string)] = event
+	}
+	if data, _ := seen["ack"]["data"].(map[string]any); data["note"] != "decision" {
+		t.Fatalf("unexpected bookmark ack %v", seen["ack"])
+	}
+
+	_ = conn.WriteJSON(map[string]any{"id": "5", "command": "dance"})
+	if ack := readEvent(t, conn); ack["ok"] != false || ack["error"] != "unknown command" {
+		t.Fatalf("unexpected unknown-command ack %v", ack)
+	}
+
+	_ = conn.WriteJSON(map[string]any{"id": "6", "command": "memo_start"})
+	if ack := readEvent(t, conn); ack["ok"] != true || ack["data"].(map[string]any)["session_id"] != "memo1" {
+		t.Fatalf("unexpected memo_start ack %v", ack)
+	}
+	_ = conn.WriteJSON(map[string]any{"id": "7", "command": "memo_stop"})
+	if ack := readEvent(t, conn); ack["ok"] != false || ack["error"] != "voice memos not available" {
+		t.Fatalf("unexpected memo_stop ack %v", ack)
+	}
+}
+
+func TestWSCoalescesInterimTranscripts(t *testing.T) {
+	hub := NewHub()
+	conn := dialWS(t, ControlHooks{}, hub)
+
+	subscribe := func(ms int) {
+		t.Helper()
+		_ = conn.WriteJSON(map[string]any{"id": "s", "command": "subscribe", "interim_ms": ms,
+			"events": []string{"ack", "live_transcript", "live_transcript_interim"}})
+		if ack := readEvent(t, conn); ack["ok"] != true || ack["data"].(map[string]any)["interim_ms"] != float64(ms) {
+			t.Fatalf("unexpected subscribe ack %v", ack)
+		}
+	}
+	expect := func(kind, text string) {
+		t.Helper()
+		if event := readEvent(t, conn); event["type"] != kind || event["text"] != text {
+			t.Fatalf("expected %s %q, got %v", kind, text, event)
+		}
+	}
+
+	// Interims held back are superseded by the final.
+	subscribe(10000)
+	for _, text := range []string{"a", "ab", "abc"} {
+		hub.BroadcastLiveTranscriptInterim(0, text, 0)
+	}
+	hub.BroadcastLiveTranscript(transcribe.Segment{Text: "abcd", Timestamp: time.Now()})
+	expect("live_transcript_interim", "a")
+	expect("live_transcript", "abcd")
+
+	// The latest interim held back is sent once the interval is up.
+	subscribe(50)
+	time.Sleep(60 * time.Millisecond)
+	for _, text := range []string{"x", "xy", "xyz"} {
+		hub.BroadcastLiveTranscriptInterim(0, text, 0)
+	}
+	expect("live_transcript_interim", "x")
+	expect("live_transcript_interim", "xyz")
+
+	_ = conn.WriteJSON(map[string]any{"id": "bad", "command": "subscribe", "interim_ms": -1})
+	if ack := readEvent(t, conn); ack["ok"] != false {
+		t.Fatalf("expected a negative interval rejected, got %v", ack)
+	}
+
+	h, err := Handler(testStaticFS(t), hub, apiStoreStub{}, ControlHooks{})
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/ws?interim_ms=soon", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a bad interim_ms, got %d", rr.Code)
+	}
+}
+
+func TestWSCoalescesInterimsAtTheServerInterval(t *testing.T) {
+	hub := NewHub()
+	hub.SetInterimInterval(50 * time.Millisecond)
+	conn := dialWS(t, ControlHooks{}, hub)
+	expect := func(kind, text string) {
+		t.Helper()
+		if event := readEvent(t, conn); event["type"] != kind || event["text"] != text {
+			t.Fatalf("expected %s %q, got %v", kind, text, event)
+		}
+	}
+
+	// Without interim_ms the connection gets the configured interval: the
+	// first interim goes straight out, the rest are coalesced into the
+	// latest.
+	for _, text := range []string{"a", "ab", "abc"} {
+		hub.BroadcastLiveTranscriptInterim(0, text, 0)
+	}
+	expect("live_transcript_interim", "a")
+	expect("live_transcript_interim", "abc")
+
+	// A final line supersedes an interim held back, which never follows it.
+	hub.BroadcastLiveTranscriptInterim(0, "x", 0)
+	hub.BroadcastLiveTranscript(transcribe.Segment{Text: "xyz", Timestamp: time.Now()})
+	expect("live_transcript", "xyz")
+	_ = conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	if _, msg, err := conn.ReadMessage(); err == nil {
+		t.Fatalf("expected the held interim dropped, got %s", msg)
+	}
+}
+
+func TestWSPresenceTracksViewers(t *testing.T) {
+	hub := NewHub()
+	h, err := Handler(testStaticFS(t), hub, apiStoreStub{}, ControlHooks{TrustedProxies: []netip.Prefix{netip.MustParsePrefix("127.0.0.0/8")}})
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws"
+
+	first, _, err := websocket.DefaultDialer.Dial(wsURL, http.Header{"Remote-User": {"ana"}})
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	defer func() { _ = first.Close() }()
+	readEvent(t, first) // connection
+	if joined := readEvent(t, first); joined["type"] != "presence" || joined["viewer_count"] != float64(1) {
+		t.Fatalf("expected own presence event, got %v", joined)
+	}
+
+	second, _, err := websocket.DefaultDialer.Dial(wsURL, http.Header{"X-Forwarded-User": {"ben"}, "User-Agent": {"kiosk"}})
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	joined := readEvent(t, first)
+	viewer, _ := joined["viewer"].(map[string]any)
+	if joined["change"] != "joined" || joined["viewer_count"] != float64(2) || viewer["user"] != "ben" || viewer["user_agent"] != "kiosk" {
+		t.Fatalf("unexpected join event %v", joined)
+	}
+
+	resp, err := http.Get(srv.URL + "/api/status")
+	if err != nil {
+		t.Fatalf("status request failed: %v", err)
+	}
+	var status map[string]any
+	_ = json.NewDecoder(resp.Body).Decode(&status)
+	_ = resp.Body.Close()
+	if status["viewers"] != float64(2) {
+		t.Fatalf("expected 2 viewers in status, got %v", status["viewers"])
+	}
+
+	_ = second.Close()
+	left := readEvent(t, first)
+	if left["change"] != "left" || left["viewer_count"] != float64(1) {
+		t.Fatalf("unexpected leave event %v", left)
+	}
+	if viewers := hub.Viewers(); len(viewers) != 1 || viewers[0].User != "ana" {
+		t.Fatalf("unexpected viewers %+v", viewers)
+	}
+}
+
+func TestWSPresenceIgnoresUserHeadersFromUntrustedPeers(t *testing.T) {
+	hub := NewHub()
+	h, err := Handler(testStaticFS(t), hub, apiStoreStub{}, ControlHooks{TrustedProxies: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}})
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws"
+
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL+"?user=mallory", http.Header{"X-Forwarded-User": {"admin"}, "Remote-User": {"admin"}})
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+	readEvent(t, conn) // connection
+	readEvent(t, conn) // presence
+	if viewers := hub.Viewers(); len(viewers) != 1 || viewers[0].User != "" {
+		t.Fatalf("expected spoofed user names ignored, got %+v", viewers)
+	}
+}
+
+type micFeedStub struct {
+	mu     sync.Mutex
+	data   []byte
+	closed bool
+}
+
+func (f *micFeedStub) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.data = append(f.data, p...)
+	return len(p), nil
+}
+
+func (f *micFeedStub) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closed = true
+	return nil
+}
+
+func (f *micFeedStub) state() (int, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.data), f.closed
+}
+
+func TestWSBrowserMic(t *testing.T) {
+	feed := &micFeedStub{}
+	var gotRate int
+	controls := ControlHooks{BrowserMic: func(rate int) (io.WriteCloser, error) {
+		if gotRate != 0 {
+			return nil, ErrBrowserMicBusy
+		}
+		gotRate = rate
+		return feed, nil
+	}}
+	h, err := Handler(testStaticFS(t), NewHub(), apiStoreStub{}, controls)
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	micURL := "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws/mic"
+
+	if _, resp, err := websocket.DefaultDialer.Dial(micURL, http.Header{"Origin": {"https://evil.example.com"}}); err == nil || resp == nil || resp.StatusCode != http.StatusForbidden || gotRate != 0 {
+		t.Fatalf("expected another site's page refused before claiming the microphone, got %v", resp)
+	}
+	for query, want := range map[string]int{"?format=opus": http.StatusUnsupportedMediaType, "?sample_rate=100": http.StatusBadRequest} {
+		_, resp, err := websocket.DefaultDialer.Dial(micURL+query, nil)
+		if err == nil || resp == nil || resp.StatusCode != want {
+			t.Fatalf("expected %d for %s, got %v", want, query, resp)
+		}
+	}
+
+	conn, _, err := websocket.DefaultDialer.Dial(micURL+"?sample_rate=44100", nil)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	if gotRate != 44100 {
+		t.Fatalf("expected the browser's sample rate passed on, got %d", gotRate)
+	}
+	_, resp, err := websocket.DefaultDialer.Dial(micURL, nil)
+	if err == nil || resp == nil || resp.StatusCode != http.StatusConflict {
+		t.Fatalf("expected a second browser turned away with 409, got %v", resp)
+	}
+
+	if err := conn.WriteMessage(websocket.BinaryMessage, make([]byte, 640)); err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.WriteMessage(websocket.TextMessage, []byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	_ = conn.Close()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		n, closed := feed.state()
+		if closed {
+			if n != 640 {
+				t.Fatalf("expected only the binary frame fed, got %d bytes", n)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected the feed closed when the browser hung up")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestWebSocketRejectsOtherOrigins(t *testing.T) {
+	h, err := Handler(testStaticFS(t), NewHub(), apiStoreStub{}, ControlHooks{AllowedOrigins: []string{"https://dash.example.com/"}})
+	if err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws"
+
+	for origin, ok := range map[string]bool{
+		"https://evil.example.com": false,
+		"null":                     false,
+		srv.URL:                    true,
+		"https://Dash.example.com": true,
+		"":                         true,
+	} {
+		header := http.Header{}
+		if origin != "" {
+			header.Set("Origin", origin)
+		}
+		conn, resp, err := websocket.DefaultDialer.Dial(url, header)
+		if ok && err != nil {
+			t.Fatalf("expected origin %q accepted, got %v", origin, err)
+		}
+		if !ok && (err == nil || resp == nil || resp.StatusCode != http.StatusForbidden) {
+			t.Fatalf("expected origin %q refused with 403, got %v", origin, err)
+		}
+		if conn != nil {
+			_ = conn.Close()
+		}
+	}
+}
@@ -0,0 +1,264 @@
+// Package service registers Ghost Wispr to start automatically at login: as a
+// systemd user unit on Linux, a LaunchAgent on macOS and a logon task on
+// Windows. All three run in the user's session so the microphone is reachable,
+// which a system-wide service would not guarantee.
+package service
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// Name identifies the installed unit, agent or task.
+const Name = "ghost-wispr"
+
+const launchAgentLabel = "com.sjawhar.ghost-wispr"
+
+// Spec describes how the service runs.
+type Spec struct {
+	Executable string
+	WorkingDir string
+	// Env is written alongside the service definition with owner-only
+	// permissions, since it usually holds API keys.
+	Env map[string]string
+}
+
+// CurrentSpec runs the current executable from the current directory with
+// every variable carrying envPrefix from the current environment.
+func CurrentSpec(envPrefix string) (Spec, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return Spec{}, fmt.Errorf("locate executable: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return Spec{}, fmt.Errorf("get working directory: %w", err)
+	}
+
+	env := make(map[string]string)
+	for _, kv := range os.Environ() {
+		key, value, ok := strings.Cut(kv, "=")
+		if ok && strings.HasPrefix(key, envPrefix) {
+			env[key] = value
+		}
+	}
+	return Spec{Executable: exe, WorkingDir: wd, Env: env}, nil
+}
+
+// run executes a service manager command; replaced in tests.
+var run = func(name string, args ...string) error {
+	out, err := exec.Command(name, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s %s: %w: %s", name, strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// Install writes the service definition for this platform and starts it.
+func Install(spec Spec) error {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("find home directory: %w", err)
+	}
+
+	switch runtime.GOOS {
+	case "linux":
+		dir := filepath.Join(home, ".config", "systemd", "user")
+		envPath := filepath.Join(dir, Name+".env")
+		unit, err := renderSystemdUnit(spec, envPath)
+		if err != nil {
+			return err
+		}
+		if err := writeFile(envPath, renderEnvFile(spec.Env)); err != nil {
+			return err
+		}
+		if err := writeFile(filepath.Join(dir, Name+".service"), unit); err != nil {
+			return err
+		}
+		if err := run("systemctl", "--user", "daemon-reload"); err != nil {
+			return err
+		}
+		return run("systemctl", "--user", "enable", "--now", Name+".service")
+	case "darwin":
+		path := filepath.Join(home, "Library", "LaunchAgents", launchAgentLabel+".plist")
+		if err := writeFile(path, renderLaunchAgent(spec, filepath.Join(home, "Library", "Logs", Name+".log"))); err != nil {
+			return err
+		}
+		return run("launchctl", "load", "-w", path)
+	case "windows":
+		script := filepath.Join(windowsDir(home), "run.cmd")
+		if err := writeFile(script, renderWindowsScript(spec)); err != nil {
+			return err
+		}
+		return run("schtasks", "/Create", "/F", "/TN", Name, "/SC", "ONLOGON", "/RL", "LIMITED", "/TR", `"`+script+`"`)
+	default:
+		return fmt.Errorf("install-service is not supported on %s", runtime.GOOS)
+	}
+}
+
+// Uninstall stops the service and removes everything Install wrote.
+func Uninstall() error {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("find home directory: %w", err)
+	}
+
+	switch runtime.GOOS {
+	case "linux":
+		dir := filepath.Join(home, ".config", "systemd", "user")
+		stopErr := run("systemctl", "--user", "disable", "--now", Name+".service")
+		removeErr := removeFiles(filepath.Join(dir, Name+".service"), filepath.Join(dir, Name+".env"))
+		if removeErr == nil {
+			removeErr = run("systemctl", "--user", "daemon-reload")
+		}
+		return errors.Join(stopErr, removeErr)
+	case "darwin":
+		path := filepath.Join(home, "Library", "LaunchAgents", launchAgentLabel+".plist")
+		return errors.Join(run("launchctl", "unload", "-w", path), removeFiles(path))
+	case "windows":
+		dir := windowsDir(home)
+		return errors.Join(run("schtasks", "/Delete", "/F", "/TN", Name), os.RemoveAll(dir))
+	default:
+		return fmt.Errorf("uninstall-service is not supported on %s", runtime.GOOS)
+	}
+}
+
+func windowsDir(home string) string {
+	if dir := os.Getenv("LOCALAPPDATA"); dir != "" {
+		return filepath.Join(dir, Name)
+	}
+	return filepath.Join(home, "AppData", "Local", Name)
+}
+
+func writeFile(path, content string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	return nil
+}
+
+func removeFiles(paths ...string) error {
+	var errs []error
+	for _, p := range paths {
+		if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func sortedKeys(env map[string]string) []string {
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// renderEnvFile writes env in systemd's EnvironmentFile format, each value
+// double-quoted so spaces, quotes and $ survive as written.
+func renderEnvFile(env map[string]string) string {
+	quote := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "`", "\\`")
+	var b strings.Builder
+	for _, k := range sortedKeys(env) {
+		fmt.Fprintf(&b, "%s=\"%s\"\n", k, quote.Replace(env[k]))
+	}
+	return b.String()
+}
+
+// systemdExecutable quotes an executable's path for ExecStart, escaping
+// specifiers (%). systemd won't run a path holding quotes, backslashes or
+// control characters however they are escaped.
+func systemdExecutable(path string) (string, error) {
+	if strings.ContainsFunc(path, func(r rune) bool { return r < ' ' || r == 0x7f || strings.ContainsRune(`\"'`, r) }) {
+		return "", fmt.Errorf("systemd can't run %q: move it to a path without quotes or backslashes", path)
+	}
+	return `"` + systemdPath(path) + `"`, nil
+}
+
+// systemdPath escapes specifiers in a path setting, which otherwise takes
+// the rest of its line as written.
+func systemdPath(s string) string {
+	return strings.ReplaceAll(s, "%", "%%")
+}
+
+func renderSystemdUnit(spec Spec, envPath string) (string, error) {
+	execStart, err := systemdExecutable(spec.Executable)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(`[Unit]
+Description=Ghost Wispr continuous transcription
+After=network-online.target
+
+[Service]
+Type=simple
+ExecStart=%s
+WorkingDirectory=%s
+Restart=always
+RestartSec=10
+EnvironmentFile=-%s
+
+[Install]
+WantedBy=default.target
+`, execStart, systemdPath(spec.WorkingDir), systemdPath(envPath)), nil
+}
+
+func renderLaunchAgent(spec Spec, logPath string) string {
+	var env strings.Builder
+	for _, k := range sortedKeys(spec.Env) {
+		fmt.Fprintf(&env, "\t\t<key>%s</key>\n\t\t<string>%s</string>\n", html.EscapeString(k), html.EscapeString(spec.Env[k]))
+	}
+
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>%s</string>
+	<key>ProgramArguments</key>
+	<array>
+		<string>%s</string>
+	</array>
+	<key>WorkingDirectory</key>
+	<string>%s</string>
+	<key>EnvironmentVariables</key>
+	<dict>
+%s	</dict>
+	<key>RunAtLoad</key>
+	<true/>
+	<key>KeepAlive</key>
+	<true/>
+	<key>StandardOutPath</key>
+	<string>%s</string>
+	<key>StandardErrorPath</key>
+	<string>%s</string>
+</dict>
+</plist>
+`, launchAgentLabel, html.EscapeString(spec.Executable), html.EscapeString(spec.WorkingDir), env.String(), html.EscapeString(logPath), html.EscapeString(logPath))
+}
+
+func renderWindowsScript(spec Spec) string {
+	var b strings.Builder
+	b.WriteString("@echo off\r\n")
+	for _, k := range sortedKeys(spec.Env) {
+		fmt.Fprintf(&b, "set \"%s=%s\"\r\n", k, spec.Env[k])
+	}
+	fmt.Fprintf(&b, "cd /d \"%s\"\r\n", spec.WorkingDir)
+	fmt.Fprintf(&b, "\"%s\"\r\n", spec.Executable)
+	return b.String()
+}
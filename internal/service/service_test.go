@@ -0,0 +1,95 @@
+package service
+
+import (
+	"strings"
+	"testing"
+)
+
+var testSpec = Spec{
+	Executable: "/opt/ghost wispr/ghost-wispr",
+	WorkingDir: "/home/me/ghost-wispr",
+	Env: map[string]string{
+		"GHOST_WISPR_OPENAI_API_KEY":   "sk-<secret>&",
+		"GHOST_WISPR_DEEPGRAM_API_KEY": "dg",
+	},
+}
+
+func TestRenderSystemdUnit(t *testing.T) {
+	unit, err := renderSystemdUnit(testSpec, "/home/me/.config/systemd/user/ghost-wispr.env")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		`ExecStart="/opt/ghost wispr/ghost-wispr"`,
+		"WorkingDirectory=/home/me/ghost-wispr",
+		"EnvironmentFile=-/home/me/.config/systemd/user/ghost-wispr.env",
+		"WantedBy=default.target",
+	} {
+		if !strings.Contains(unit, want) {
+			t.Fatalf("expected unit to contain %q, got:\n%s", want, unit)
+		}
+	}
+}
+
+func TestRenderSystemdUnitEscapes(t *testing.T) {
+	spec := Spec{Executable: "/opt/100% $HOME/ghost-wispr", WorkingDir: "/srv/50%"}
+	unit, err := renderSystemdUnit(spec, "/home/me/.config/systemd/user/ghost-wispr.env")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		`ExecStart="/opt/100%% $HOME/ghost-wispr"`,
+		"WorkingDirectory=/srv/50%%\n",
+	} {
+		if !strings.Contains(unit, want) {
+			t.Fatalf("expected unit to contain %q, got:\n%s", want, unit)
+		}
+	}
+
+	for _, exe := range []string{`/opt/"real"/ghost-wispr`, `/opt/ghost\wispr`} {
+		if _, err := renderSystemdUnit(Spec{Executable: exe}, ""); err == nil {
+			t.Errorf("expected an error for %q, which systemd won't run", exe)
+		}
+	}
+}
+
+func TestRenderEnvFileSorted(t *testing.T) {
+	got := renderEnvFile(testSpec.Env)
+	want := "GHOST_WISPR_DEEPGRAM_API_KEY=\"dg\"\nGHOST_WISPR_OPENAI_API_KEY=\"sk-<secret>&\"\n"
+	if got != want {
+		t.Fatalf("unexpected env file %q", got)
+	}
+
+	got = renderEnvFile(map[string]string{"GHOST_WISPR_KEY": "a b \"c\" $d `e` \\f"})
+	want = "GHOST_WISPR_KEY=\"a b \\\"c\\\" \\$d \\`e\\` \\\\f\"\n"
+	if got != want {
+		t.Fatalf("expected the value quoted for systemd, got %q", got)
+	}
+}
+
+func TestRenderLaunchAgentEscapesValues(t *testing.T) {
+	plist := renderLaunchAgent(testSpec, "/Users/me/Library/Logs/ghost-wispr.log")
+
+	if !strings.Contains(plist, "<string>sk-&lt;secret&gt;&amp;</string>") {
+		t.Fatalf("expected escaped env value, got:\n%s", plist)
+	}
+	if !strings.Contains(plist, "<string>"+launchAgentLabel+"</string>") {
+		t.Fatalf("expected label in plist, got:\n%s", plist)
+	}
+}
+
+func TestRenderWindowsScript(t *testing.T) {
+	script := renderWindowsScript(testSpec)
+
+	for _, want := range []string{
+		"set \"GHOST_WISPR_DEEPGRAM_API_KEY=dg\"\r\n",
+		"cd /d \"/home/me/ghost-wispr\"\r\n",
+		"\"/opt/ghost wispr/ghost-wispr\"\r\n",
+	} {
+		if !strings.Contains(script, want) {
+			t.Fatalf("expected script to contain %q, got %q", want, script)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package session
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+	"github.com/sjawhar/ghost-wispr/internal/summary"
+)
+
+// ActionItemExtractor lists the action items in a transcript, carrying over
+// prior items with their updated status.
+type ActionItemExtractor interface {
+	ExtractActionItems(ctx context.Context, transcript string, prior []summary.ActionItem) ([]summary.ActionItem, error)
+}
+
+// ActionItemStore is the storage needed to carry action items from one
+// meeting in a series to the next.
+type ActionItemStore interface {
+	GetSession(id string) (storage.Session, error)
+	GetSeriesSessions(seriesID string) ([]storage.Session, error)
+	ReplaceActionItems(sessionID string, items []storage.ActionItem) error
+	GetActionItems(sessionID string) ([]storage.ActionItem, error)
+}
+
+// WithActionItems records the action items of each summarized session. When
+// the session belongs to a series, the items still open after the previous
+// meeting are handed to the summary prompt so it reports on them, and carried
+// into this session's list until they are done.
+func WithActionItems(extractor ActionItemExtractor, store ActionItemStore) Option {
+	return func(m *Manager) {
+		m.actionItems = extractor
+		m.actionItemStore = store
+	}
+}
+
+// PreviousOpenActionItems returns the open action items of the series session
+// held before sessionID, along with that session. ok is false when sessionID
+// is not part of a series or is its first meeting.
+func PreviousOpenActionItems(store ActionItemStore, sessionID string) (prev storage.Session, items []storage.ActionItem, ok bool, err error) {
+	sess, err := store.GetSession(sessionID)
+	if err != nil || sess.SeriesID == "" {
+		return storage.Session{}, nil, false, err
+	}
+	sessions, err := store.GetSeriesSessions(sess.SeriesID)
+	if err != nil {
+		return storage.Session{}, nil, false, err
+	}
+	for _, candidate := range sessions {
+		if candidate.ID != sess.ID && candidate.StartedAt.Before(sess.StartedAt) {
+			prev, ok = candidate, true
+		}
+	}
+	if !ok {
+		return storage.Session{}, nil, false, nil
+	}
+	items, err = OpenActionItems(store, prev.ID)
+	return prev, items, true, err
+}
+
+// OpenActionItems returns the action items of a session that are not done.
+func OpenActionItems(store ActionItemStore, sessionID string) ([]storage.ActionItem, error) {
+	all, err := store.GetActionItems(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	open := make([]storage.ActionItem, 0, len(all))
+	for _, item := range all {
+		if !item.Done {
+			open = append(open, item)
+		}
+	}
+	return open, nil
+}
+
+// priorActionItems loads the items to carry into sessionID's summary. Failures
+// are logged and treated as nothing to carry over.
+func (m *Manager) priorActionItems(sessionID string) []summary.ActionItem {
+	if m.actionItems == nil {
+		return nil
+	}
+	_, open, ok, err := PreviousOpenActionItems(m.actionItemStore, sessionID)
+	if err != nil {
+		slog.Warn("loading carried-over action items failed", "session", sessionID, "error", err)
+		return nil
+	}
+	if !ok {
+		return nil
+	}
+	prior := make([]summary.ActionItem, 0, len(open))
+	for _, item := range open {
+		prior = append(prior, summary.ActionItem{Text: item.Text, Owner: item.Owner})
+	}
+	return prior
+}
+
+func (m *Manager) extractActionItems(ctx context.Context, sessionID, transcript string, prior []summary.ActionItem) {
+	if m.actionItems == nil {
+		return
+	}
+
+	extracted, err := m.actionItems.ExtractActionItems(ctx, transcript, prior)
+	if err != nil {
+		slog.Warn("action item extraction failed", "session", sessionID, "error", err)
+		return
+	}
+
+	items := make([]storage.ActionItem, 0, len(extracted))
+	for _, item := range extracted {
+		items = append(items, storage.ActionItem{Text: item.Text, Owner: item.Owner, Done: item.Done})
+	}
+	if err := m.actionItemStore.ReplaceActionItems(sessionID, items); err != nil {
+		slog.Warn("storing action items failed", "session", sessionID, "error", err)
+	}
+}
@@ -0,0 +1,68 @@
+package session
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+	"github.com/sjawhar/ghost-wispr/internal/summary"
+	"github.com/sjawhar/ghost-wispr/internal/transcribe"
+)
+
+type actionItemExtractorMock struct {
+	prior []summary.ActionItem
+	added []summary.ActionItem
+}
+
+func (a *actionItemExtractorMock) ExtractActionItems(_ context.Context, _ string, prior []summary.ActionItem) ([]summary.ActionItem, error) {
+	a.prior = prior
+	return append(append([]summary.ActionItem{}, prior...), a.added...), nil
+}
+
+func TestManager_CarriesOverOpenActionItems(t *testing.T) {
+	store := storage.NewMemoryStore()
+	monday := time.Date(2026, 3, 2, 10, 0, 0, 0, time.Local)
+	addEndedSession(t, store, "w1", monday, 30*time.Minute)
+	addEndedSession(t, store, "w2", monday.AddDate(0, 0, 7), 30*time.Minute)
+	addEndedSession(t, store, "w3", monday.AddDate(0, 0, 14), 30*time.Minute)
+	for _, id := range []string{"w1", "w2", "w3"} {
+		if err := store.SetSessionSeries(id, "series-w1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.ReplaceActionItems("w2", []storage.ActionItem{
+		{Text: "Send the deck", Owner: "Alice"},
+		{Text: "Book the room", Done: true},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.AppendSegment("w3", transcribe.Segment{Text: "the deck is still pending"}); err != nil {
+		t.Fatal(err)
+	}
+
+	extractor := &actionItemExtractorMock{added: []summary.ActionItem{{Text: "Draft the agenda", Owner: "Bob"}}}
+	manager := NewManager(store, nil, summarizerMock{}, nil, NewDetector(time.Hour), WithActionItems(extractor, store))
+	if err := manager.Resummarize(context.Background(), "w3", "default"); err != nil {
+		t.Fatalf("Resummarize failed: %v", err)
+	}
+
+	if len(extractor.prior) != 1 || extractor.prior[0].Text != "Send the deck" || extractor.prior[0].Owner != "Alice" {
+		t.Fatalf("expected only the open item from w2 carried over, got %#v", extractor.prior)
+	}
+	items, err := store.GetActionItems("w3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || items[0].Text != "Send the deck" || items[1].Text != "Draft the agenda" {
+		t.Fatalf("unexpected stored items %#v", items)
+	}
+
+	// The first meeting of a series has nothing to carry over.
+	if err := manager.Resummarize(context.Background(), "w1", "default"); err != nil {
+		t.Fatal(err)
+	}
+	if extractor.prior != nil {
+		t.Fatalf("expected no prior items for the first meeting, got %#v", extractor.prior)
+	}
+}
@@ -0,0 +1,169 @@
+package session
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/config"
+	"github.com/sjawhar/ghost-wispr/internal/i18n"
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+	"github.com/sjawhar/ghost-wispr/internal/summary"
+)
+
+// SpendStore reports how much has been spent in a calendar month.
+type SpendStore interface {
+	GetMonthlySpend(month time.Time) (storage.MonthlySpend, error)
+}
+
+// BudgetStatus is the current month's spend against the configured budgets.
+type BudgetStatus struct {
+	Spend                 storage.MonthlySpend
+	TranscriptionExceeded bool
+	LLMExceeded           bool
+}
+
+// BudgetGuard checks monthly spend against the configured budgets and decides
+// how to degrade once one is exhausted.
+type BudgetGuard struct {
+	cfg   config.Budget
+	store SpendStore
+	now   func() time.Time
+
+	// Localizer renders Warnings; nil renders English.
+	Localizer *i18n.Localizer
+}
+
+func NewBudgetGuard(cfg config.Budget, store SpendStore) *BudgetGuard {
+	return &BudgetGuard{cfg: cfg, store: store, now: time.Now}
+}
+
+// Enabled reports whether any budget is configured.
+func (g *BudgetGuard) Enabled() bool {
+	return g != nil && (g.cfg.MonthlyTranscriptionUSD > 0 || g.cfg.MonthlyLLMUSD > 0)
+}
+
+// Status returns this month's spend and which budgets it exceeds.
+func (g *BudgetGuard) Status() (BudgetStatus, error) {
+	if !g.Enabled() {
+		return BudgetStatus{}, nil
+	}
+	spend, err := g.store.GetMonthlySpend(g.now())
+	if err != nil {
+		return BudgetStatus{}, err
+	}
+	return BudgetStatus{
+		Spend:                 spend,
+		TranscriptionExceeded: g.cfg.MonthlyTranscriptionUSD > 0 && spend.TranscriptionCost >= g.cfg.MonthlyTranscriptionUSD,
+		LLMExceeded:           g.cfg.MonthlyLLMUSD > 0 && spend.LLMCost >= g.cfg.MonthlyLLMUSD,
+	}, nil
+}
+
+// Warnings describes every exceeded budget and the degradation in effect.
+func (g *BudgetGuard) Warnings() []string {
+	status, err := g.Status()
+	if err != nil {
+		slog.Warn("checking budget failed", "error", err)
+		return nil
+	}
+
+	l := g.Localizer
+	var warnings []string
+	if status.TranscriptionExceeded {
+		effect := l.T(i18n.BudgetCaptureContinues)
+		if g.cfg.TranscriptionAction == config.BudgetActionPauseCapture {
+			effect = l.T(i18n.BudgetCapturePaused)
+		}
+		warnings = append(warnings, l.T(i18n.BudgetTranscriptionExceeded, status.Spend.TranscriptionCost, g.cfg.MonthlyTranscriptionUSD, effect))
+	}
+	if status.LLMExceeded {
+		effect := l.T(i18n.BudgetSummariesContinue)
+		switch g.cfg.LLMAction {
+		case config.BudgetActionCheaperModel:
+			effect = l.T(i18n.BudgetSummariesFallback, g.cfg.FallbackModel)
+		case config.BudgetActionDisableSummaries:
+			effect = l.T(i18n.BudgetSummariesDisabled)
+		}
+		warnings = append(warnings, l.T(i18n.BudgetLLMExceeded, status.Spend.LLMCost, g.cfg.MonthlyLLMUSD, effect))
+	}
+	return warnings
+}
+
+// summaryPolicy reports whether automatic summaries may run and returns ctx
+// with any cheaper-model override applied.
+func (g *BudgetGuard) summaryPolicy(ctx context.Context, auto bool) (context.Context, bool) {
+	status, err := g.Status()
+	if err != nil {
+		slog.Warn("checking budget failed", "error", err)
+		return ctx, true
+	}
+	if !status.LLMExceeded {
+		return ctx, true
+	}
+
+	switch g.cfg.LLMAction {
+	case config.BudgetActionCheaperModel:
+		slog.Warn("monthly LLM budget exceeded, using fallback model", "model", g.cfg.FallbackModel)
+		return summary.WithModelOverride(ctx, g.cfg.FallbackModel), true
+	case config.BudgetActionDisableSummaries:
+		if auto {
+			slog.Warn("monthly LLM budget exceeded, skipping automatic summary")
+			return ctx, false
+		}
+	default:
+		slog.Warn("monthly LLM budget exceeded", "spent", status.Spend.LLMCost, "budget", g.cfg.MonthlyLLMUSD)
+	}
+	return ctx, true
+}
+
+// Watch checks the budgets every interval, first calling accrue so the
+// session being recorded counts towards this month's spend. While the
+// transcription budget is exceeded and the pause_capture action is set, it
+// calls pause on every check, so capture resumed by hand is paused again.
+func (g *BudgetGuard) Watch(ctx context.Context, interval time.Duration, accrue, pause func()) {
+	if !g.Enabled() {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	exceeded := false
+	for {
+		accrue()
+		status, err := g.Status()
+		if err != nil {
+			slog.Warn("checking budget failed", "error", err)
+		} else {
+			if status.TranscriptionExceeded && g.cfg.TranscriptionAction == config.BudgetActionPauseCapture {
+				if !exceeded {
+					slog.Warn("monthly transcription budget exceeded, pausing capture",
+						"spent", status.Spend.TranscriptionCost, "budget", g.cfg.MonthlyTranscriptionUSD)
+				}
+				pause()
+			}
+			exceeded = status.TranscriptionExceeded
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// AccrueTranscriptionUsage records the transcription time and cost of the
+// session being recorded so far; ending it records the final figures.
+func (m *Manager) AccrueTranscriptionUsage() {
+	m.mu.Lock()
+	sessionID, clock := m.currentSessionID, m.currentClock
+	paused := m.capture.pausedFor(clock)
+	m.mu.Unlock()
+	if sessionID == "" {
+		return
+	}
+	duration := max(clock.elapsed()-paused, 0)
+	if err := m.store.RecordTranscriptionUsage(sessionID, duration, duration.Minutes()*m.transcriptionPerMinute); err != nil {
+		slog.Warn("recording transcription usage failed", "session", sessionID, "error", err)
+	}
+}
@@ -0,0 +1,131 @@
+package session
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/config"
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+)
+
+type spendStub struct {
+	spend storage.MonthlySpend
+}
+
+func (s *spendStub) GetMonthlySpend(time.Time) (storage.MonthlySpend, error) {
+	return s.spend, nil
+}
+
+func TestBudgetGuard_Warnings(t *testing.T) {
+	spend := &spendStub{spend: storage.MonthlySpend{TranscriptionCost: 4, LLMCost: 12}}
+	guard := NewBudgetGuard(config.Budget{
+		MonthlyTranscriptionUSD: 5,
+		MonthlyLLMUSD:           10,
+		TranscriptionAction:     config.BudgetActionPauseCapture,
+		LLMAction:               config.BudgetActionCheaperModel,
+		FallbackModel:           "openai/gpt-4o-mini",
+	}, spend)
+
+	warnings := guard.Warnings()
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "LLM budget exceeded ($12.00 of $10.00)") {
+		t.Fatalf("expected one LLM budget warning, got %v", warnings)
+	}
+	if !strings.Contains(warnings[0], "openai/gpt-4o-mini") {
+		t.Fatalf("expected warning to name the fallback model, got %q", warnings[0])
+	}
+
+	if NewBudgetGuard(config.Budget{}, spend).Enabled() {
+		t.Fatal("expected guard without budgets to be disabled")
+	}
+}
+
+func TestManager_BudgetDisablesAutoSummaries(t *testing.T) {
+	store := newStoreMock()
+	hub := &hubMock{}
+	called := make(chan string, 1)
+	guard := NewBudgetGuard(config.Budget{
+		MonthlyLLMUSD: 1,
+		LLMAction:     config.BudgetActionDisableSummaries,
+	}, &spendStub{spend: storage.MonthlySpend{LLMCost: 2}})
+	manager := NewManager(store, nil, summarizerMock{called: called}, hub, NewDetector(time.Hour), WithBudget(guard))
+
+	if err := store.CreateSession("s1", time.Now().UTC()); err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+	manager.generateSummary(context.Background(), "s1", "")
+
+	select {
+	case <-called:
+		t.Fatal("expected automatic summary to be skipped")
+	default:
+	}
+	store.mu.Lock()
+	status := store.status["s1"]
+	store.mu.Unlock()
+	if status != storage.SummarySkippedBudget {
+		t.Fatalf("expected summary status skipped_budget, got %q", status)
+	}
+
+	if err := manager.Resummarize(context.Background(), "s1", "default"); err != nil {
+		t.Fatalf("expected manual resummarize to run, got %v", err)
+	}
+	select {
+	case <-called:
+	default:
+		t.Fatal("expected manual resummarize to call the summarizer")
+	}
+}
+
+func TestBudgetGuard_WatchKeepsCapturePaused(t *testing.T) {
+	spend := &spendStub{spend: storage.MonthlySpend{TranscriptionCost: 6}}
+	guard := NewBudgetGuard(config.Budget{
+		MonthlyTranscriptionUSD: 5,
+		TranscriptionAction:     config.BudgetActionPauseCapture,
+	}, spend)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var accrued, pauses atomic.Int32
+	done := make(chan struct{})
+	go func() {
+		guard.Watch(ctx, 5*time.Millisecond, func() { accrued.Add(1) }, func() { pauses.Add(1) })
+		close(done)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	<-done
+
+	// Capture resumed by hand after the first pause is paused again.
+	if pauses.Load() < 2 {
+		t.Fatalf("expected capture paused on every check, got %d pauses", pauses.Load())
+	}
+	if accrued.Load() != pauses.Load() {
+		t.Fatalf("expected usage accrued before each check, got %d accruals for %d checks", accrued.Load(), pauses.Load())
+	}
+}
+
+func TestManager_AccruesTranscriptionUsage(t *testing.T) {
+	store := newStoreMock()
+	manager := NewManager(store, nil, nil, &hubMock{}, NewDetector(time.Hour), WithPricing(nil, 0.6))
+
+	manager.AccrueTranscriptionUsage()
+	if len(store.usage) != 0 {
+		t.Fatal("expected nothing accrued without a session")
+	}
+
+	manager.mu.Lock()
+	manager.currentSessionID = "s1"
+	manager.currentClock = startClock(time.Now().Add(-10 * time.Minute))
+	manager.mu.Unlock()
+	manager.AccrueTranscriptionUsage()
+
+	store.mu.Lock()
+	cost := store.usage["s1"].TranscriptionCost
+	store.mu.Unlock()
+	if cost < 6 || cost > 6.1 {
+		t.Fatalf("expected about $6 accrued for 10 minutes, got %v", cost)
+	}
+}
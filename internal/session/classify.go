@@ -0,0 +1,194 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+	"github.com/sjawhar/ghost-wispr/internal/transcribe"
+)
+
+// ClassifierStore is the storage needed to classify finished sessions and
+// expire them once their class's retention runs out.
+type ClassifierStore interface {
+	GetSegments(sessionID string) ([]transcribe.Segment, error)
+	ListBriefings() ([]storage.Briefing, error)
+	GetSessionsByKind(kind string) ([]storage.Session, error)
+	DeleteSession(id string) error
+}
+
+// ClassPolicy is how sessions of one class are treated. A zero Retention
+// keeps them forever.
+type ClassPolicy struct {
+	Summarize bool
+	Notify    bool
+	Retention time.Duration
+}
+
+// Classifier sorts finished sessions into meetings and ambient chatter. A
+// session that overlaps a calendar event (a briefing whose time, widened by
+// CalendarSlack, meets the session's) is a meeting; otherwise one shorter than MinDuration
+// or with fewer than MinSpeakers speakers is ambient, like a hallway
+// conversation or the office radio.
+type Classifier struct {
+	Store         ClassifierStore
+	MinDuration   time.Duration
+	MinSpeakers   int
+	CalendarSlack time.Duration
+	// Policies are keyed by storage.SessionKindMeeting and
+	// storage.SessionKindAmbient. A class without one is summarized and
+	// announced as usual and kept forever.
+	Policies map[string]ClassPolicy
+	// Purger, when set, expires sessions along with everything derived
+	// from them; without one just the session and its audio are deleted.
+	Purger *Purger
+}
+
+// Classify returns the class of a finished session and why it was chosen.
+func (c *Classifier) Classify(sess storage.Session) (class, reason string, err error) {
+	end := sess.StartedAt
+	if sess.EndedAt != nil {
+		end = *sess.EndedAt
+	}
+
+	briefings, err := c.Store.ListBriefings()
+	if err != nil {
+		return "", "", fmt.Errorf("list calendar events: %w", err)
+	}
+	for _, b := range briefings {
+		// An event without an end is taken to be the instant it starts.
+		eventEnd := b.StartsAt
+		if b.EndsAt != nil {
+			eventEnd = *b.EndsAt
+		}
+		if !b.StartsAt.After(end.Add(c.CalendarSlack)) && !eventEnd.Before(sess.StartedAt.Add(-c.CalendarSlack)) {
+			return storage.SessionKindMeeting, fmt.Sprintf("overlaps calendar event %q", b.Title), nil
+		}
+	}
+
+	if d := end.Sub(sess.StartedAt); d < c.MinDuration {
+		return storage.SessionKindAmbient, fmt.Sprintf("shorter than %s", c.MinDuration), nil
+	}
+	segments, err := c.Store.GetSegments(sess.ID)
+	if err != nil {
+		return "", "", fmt.Errorf("get segments: %w", err)
+	}
+	if n := countSpeakers(segments); n < c.MinSpeakers {
+		return storage.SessionKindAmbient, fmt.Sprintf("%d speaker(s), fewer than %d", n, c.MinSpeakers), nil
+	}
+	return storage.SessionKindMeeting, "long enough with several speakers", nil
+}
+
+// Policy returns how sessions of class are treated.
+func (c *Classifier) Policy(class string) ClassPolicy {
+	if p, ok := c.Policies[class]; ok {
+		return p
+	}
+	return ClassPolicy{Summarize: true, Notify: true}
+}
+
+// RunRetention deletes expired sessions every interval until ctx is done.
+func (c *Classifier) RunRetention(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if n, err := c.Expire(time.Now()); err != nil {
+			slog.Warn("expiring sessions failed", "error", err)
+		} else if n > 0 {
+			slog.Info("expired sessions past their retention", "count", n)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Expire deletes the sessions, and their audio, that ended longer ago than
+// their class's retention, returning how many were deleted. Locked sessions
+// are kept.
+func (c *Classifier) Expire(now time.Time) (int, error) {
+	deleted := 0
+	var errs []error
+	for class, policy := range c.Policies {
+		if policy.Retention <= 0 {
+			continue
+		}
+		sessions, err := c.Store.GetSessionsByKind(class)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("list %s sessions: %w", class, err))
+			continue
+		}
+		cutoff := now.Add(-policy.Retention)
+		for _, sess := range sessions {
+			if sess.Locked || sess.EndedAt == nil || sess.EndedAt.After(cutoff) {
+				continue
+			}
+			if c.Purger != nil {
+				if _, err := c.Purger.Purge(context.Background(), sess.ID); err != nil {
+					errs = append(errs, err)
+					continue
+				}
+				deleted++
+				continue
+			}
+			if err := c.Store.DeleteSession(sess.ID); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			removeSessionFiles(sess)
+			deleted++
+		}
+	}
+	return deleted, errors.Join(errs...)
+}
+
+// removeSessionFiles deletes a session's recording, its waveform peaks and
+// its summary audio.
+func removeSessionFiles(sess storage.Session) {
+	var paths []string
+	if sess.AudioPath != "" {
+		paths = append(paths, sess.AudioPath, strings.TrimSuffix(sess.AudioPath, filepath.Ext(sess.AudioPath))+".peaks.json")
+	}
+	if sess.SummaryAudioPath != "" {
+		paths = append(paths, sess.SummaryAudioPath)
+	}
+	if sess.WAVPath != "" && sess.WAVPath != sess.AudioPath {
+		paths = append(paths, sess.WAVPath)
+	}
+	for _, path := range paths {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			slog.Warn("removing session file failed", "session", sess.ID, "path", path, "error", err)
+		}
+	}
+}
+
+type quietKey struct{}
+
+// withQuiet returns a context in which a finished summary is stored without
+// announcing it.
+func withQuiet(ctx context.Context) context.Context {
+	return context.WithValue(ctx, quietKey{}, true)
+}
+
+func isQuiet(ctx context.Context) bool {
+	quiet, _ := ctx.Value(quietKey{}).(bool)
+	return quiet
+}
+
+func countSpeakers(segments []transcribe.Segment) int {
+	seen := make(map[int]bool)
+	for _, seg := range segments {
+		if strings.TrimSpace(seg.Text) != "" {
+			seen[seg.Speaker] = true
+		}
+	}
+	return len(seen)
+}
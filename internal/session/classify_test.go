@@ -0,0 +1,162 @@
+package session
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+	"github.com/sjawhar/ghost-wispr/internal/transcribe"
+)
+
+func TestClassifierClassify(t *testing.T) {
+	store := storage.NewMemoryStore()
+	classifier := &Classifier{Store: store, MinDuration: 3 * time.Minute, MinSpeakers: 2, CalendarSlack: 10 * time.Minute}
+
+	start := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	talk := func(id string, speakers ...int) {
+		for _, sp := range speakers {
+			if err := store.AppendSegment(id, transcribe.Segment{Speaker: sp, Text: "hello", Timestamp: start}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	sessions := map[string]storage.Session{
+		"chat":     addEndedSession(t, store, "chat", start, time.Minute),
+		"solo":     addEndedSession(t, store, "solo", start.Add(time.Hour), 20*time.Minute),
+		"meeting":  addEndedSession(t, store, "meeting", start.Add(2*time.Hour), 20*time.Minute),
+		"standup":  addEndedSession(t, store, "standup", start.Add(5*time.Hour), 2*time.Minute),
+		"workshop": addEndedSession(t, store, "workshop", start.Add(8*time.Hour), 2*time.Minute),
+		"after":    addEndedSession(t, store, "after", start.Add(11*time.Hour), 2*time.Minute),
+	}
+	talk("chat", 0, 1)
+	talk("solo", 0, 0)
+	talk("meeting", 0, 1, 0)
+	talk("standup", 0)
+	talk("workshop", 0)
+	talk("after", 0)
+	// A short call is still a meeting when the calendar says so, including
+	// partway through an event that started long before it.
+	workshopEnds := start.Add(10 * time.Hour)
+	for _, b := range []storage.Briefing{
+		{ID: "b1", Title: "Standup", StartsAt: start.Add(5*time.Hour - 5*time.Minute)},
+		{ID: "b2", Title: "Workshop", StartsAt: start.Add(7 * time.Hour), EndsAt: &workshopEnds},
+	} {
+		if err := store.CreateBriefing(b); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for id, want := range map[string]string{
+		"chat":     storage.SessionKindAmbient,
+		"solo":     storage.SessionKindAmbient,
+		"meeting":  storage.SessionKindMeeting,
+		"standup":  storage.SessionKindMeeting,
+		"workshop": storage.SessionKindMeeting,
+		"after":    storage.SessionKindAmbient,
+	} {
+		class, reason, err := classifier.Classify(sessions[id])
+		if err != nil {
+			t.Fatalf("%s: Classify failed: %v", id, err)
+		}
+		if class != want || reason == "" {
+			t.Fatalf("%s: expected %s, got %s (%s)", id, want, class, reason)
+		}
+	}
+}
+
+func TestClassifierExpire(t *testing.T) {
+	store := storage.NewMemoryStore()
+	classifier := &Classifier{Store: store, Policies: map[string]ClassPolicy{
+		storage.SessionKindAmbient: {Retention: 24 * time.Hour},
+		storage.SessionKindMeeting: {Summarize: true, Notify: true},
+	}}
+
+	dir := t.TempDir()
+	audioPath := filepath.Join(dir, "old.mp3")
+	peaksPath := filepath.Join(dir, "old.peaks.json")
+	for _, path := range []string{audioPath, peaksPath} {
+		if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	for _, s := range []struct {
+		id, kind string
+		age      time.Duration
+	}{
+		{"old", storage.SessionKindAmbient, 48 * time.Hour},
+		{"recent", storage.SessionKindAmbient, time.Hour},
+		{"old-meeting", storage.SessionKindMeeting, 48 * time.Hour},
+		{"old-locked", storage.SessionKindAmbient, 48 * time.Hour},
+	} {
+		if err := store.CreateSession(s.id, now.Add(-s.age)); err != nil {
+			t.Fatal(err)
+		}
+		path := ""
+		if s.id == "old" {
+			path = audioPath
+		}
+		if err := store.EndSession(s.id, now.Add(-s.age+time.Minute), path); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.SetSessionKind(s.id, s.kind); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := store.SetSessionLocked("old-locked", true); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := classifier.Expire(now)
+	if err != nil || n != 1 {
+		t.Fatalf("expected one session expired, got %d (%v)", n, err)
+	}
+	if _, err := store.GetSession("old"); err == nil {
+		t.Fatal("expected the old ambient session deleted")
+	}
+	for _, id := range []string{"recent", "old-meeting", "old-locked"} {
+		if _, err := store.GetSession(id); err != nil {
+			t.Fatalf("expected %s kept: %v", id, err)
+		}
+	}
+	for _, path := range []string{audioPath, peaksPath} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Fatalf("expected %s removed, got %v", path, err)
+		}
+	}
+}
+
+func TestManager_AmbientSessionSkipsSummary(t *testing.T) {
+	store := newStoreMock()
+	hub := &hubMock{}
+	summaryCalled := make(chan string, 1)
+	classifier := &Classifier{Store: storage.NewMemoryStore(), MinDuration: time.Hour, MinSpeakers: 2, Policies: map[string]ClassPolicy{
+		storage.SessionKindAmbient: {},
+	}}
+	manager := NewManager(store, &recorderMock{}, summarizerMock{called: summaryCalled}, hub, NewDetector(time.Hour), WithClassifier(classifier))
+
+	msg := buildMsg(t, `{"is_final": true, "speech_final": true, "channel": {"alternatives": [{"transcript": "coffee?", "words": [{"speaker": 0, "punctuated_word": "coffee?", "start": 0, "end": 0.5}]}]}}`)
+	if err := manager.Message(msg); err != nil {
+		t.Fatal(err)
+	}
+	sessionID := manager.CurrentSessionID()
+	if err := manager.ForceEndSession(context.Background()); err != nil {
+		t.Fatalf("ForceEndSession failed: %v", err)
+	}
+
+	select {
+	case id := <-summaryCalled:
+		t.Fatalf("expected no summary for ambient chatter, got one for %s", id)
+	case <-time.After(50 * time.Millisecond):
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if store.kind[sessionID] != storage.SessionKindAmbient || store.status[sessionID] != storage.SummaryCompleted {
+		t.Fatalf("expected an ambient session with its summary settled, got kind %q status %q", store.kind[sessionID], store.status[sessionID])
+	}
+}
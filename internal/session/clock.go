@@ -0,0 +1,115 @@
+package session
+
+import "time"
+
+// clockJumpTolerance is how far the wall clock may drift from the session's
+// own clock over a session before it is taken to have been changed, by NTP,
+// by hand or by a laptop resuming with a stale clock.
+const clockJumpTolerance = 2 * time.Second
+
+// processStart anchors the boot clock where the system offers none.
+var processStart = time.Now()
+
+// sessionClock anchors a session's start on two clocks: the wall clock,
+// which says when it started, and the boot clock, which says how long it
+// has run and is never set back or forward.
+type sessionClock struct {
+	wall time.Time
+	boot time.Duration
+	// countsSleep is whether the boot clock kept counting while the machine
+	// was suspended.
+	countsSleep bool
+}
+
+// startClock anchors a session that started at wall, which may be a little
+// in the past, as for speech recovered after an outage.
+func startClock(wall time.Time) sessionClock {
+	return sessionClock{wall: wall.UTC(), boot: bootClock() - time.Since(wall), countsSleep: bootClockCountsSleep}
+}
+
+// elapsed is how long the session has run, on the boot clock.
+func (c sessionClock) elapsed() time.Duration {
+	if c.wall.IsZero() {
+		return 0
+	}
+	return max(bootClock()-c.boot, 0)
+}
+
+// now is the wall time now, as reconciled by reconcile.
+func (c sessionClock) now() time.Time {
+	at, _ := c.reconcile(time.Now().UTC(), c.elapsed())
+	return at
+}
+
+// reconcile checks the wall clock, read as wall, against the elapsed time.
+// While they agree it returns wall; once the wall clock has been changed
+// since the session started it returns the start plus elapsed instead, so
+// that times within the session stay in order and its duration is how long
+// it actually ran. Where the boot clock stops while suspended, the wall
+// clock running ahead of it is the machine having slept, not a change, and
+// the wall clock is kept.
+func (c sessionClock) reconcile(wall time.Time, elapsed time.Duration) (time.Time, bool) {
+	skew := wall.Sub(c.wall) - elapsed
+	if skew.Abs() <= clockJumpTolerance || (!c.countsSleep && skew > 0) {
+		return wall, false
+	}
+	return c.wall.Add(elapsed), true
+}
+
+// clockNow is the time now as the active session keeps it, or the wall
+// clock between sessions.
+func (m *Manager) clockNow() time.Time {
+	m.mu.Lock()
+	clock, active := m.currentClock, m.currentSessionID != ""
+	m.mu.Unlock()
+	if !active {
+		return time.Now().UTC()
+	}
+	return clock.now()
+}
+
+// pauseTracker adds up how long capture was paused during a session, on the
+// boot clock.
+type pauseTracker struct {
+	paused bool
+	since  time.Duration
+	// total is the session's paused time before since.
+	total time.Duration
+}
+
+// startSession starts counting for a session anchored on clock, which
+// capture may already be paused at.
+func (p *pauseTracker) startSession(clock sessionClock) {
+	p.total = 0
+	if p.paused {
+		p.since = max(p.since, clock.boot)
+	}
+}
+
+func (p *pauseTracker) set(paused bool) {
+	switch {
+	case paused && !p.paused:
+		p.since = bootClock()
+	case !paused && p.paused:
+		p.total += bootClock() - p.since
+	}
+	p.paused = paused
+}
+
+// pausedFor is how long capture has been paused during the session anchored
+// on clock.
+func (p *pauseTracker) pausedFor(clock sessionClock) time.Duration {
+	total := p.total
+	if p.paused {
+		total += bootClock() - max(p.since, clock.boot)
+	}
+	return total
+}
+
+// SetCapturePaused tells the manager that capture was paused or resumed, so
+// the time spent paused isn't counted as transcribed.
+func (m *Manager) SetCapturePaused(paused bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.capture.set(paused)
+}
@@ -0,0 +1,32 @@
+package session
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// bootClockCountsSleep: CLOCK_BOOTTIME, unlike the CLOCK_MONOTONIC behind
+// Go's monotonic readings, keeps counting through suspend.
+const bootClockCountsSleep = true
+
+// bootClock reads CLOCK_BOOTTIME, the time since the machine booted.
+func bootClock() time.Duration {
+	var ts unix.Timespec
+	if err := unix.ClockGettime(unix.CLOCK_BOOTTIME, &ts); err != nil {
+		return time.Since(processStart)
+	}
+	return time.Duration(ts.Nano())
+}
+
+// bootID identifies this boot of the machine, whose boot clock readings
+// can be compared across runs.
+func bootID() string {
+	id, err := os.ReadFile("/proc/sys/kernel/random/boot_id")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(id))
+}
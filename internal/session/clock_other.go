@@ -0,0 +1,19 @@
+//go:build !linux
+
+package session
+
+import "time"
+
+// bootClockCountsSleep: elsewhere the boot clock is Go's monotonic clock,
+// which may stop while the machine is suspended.
+const bootClockCountsSleep = false
+
+// bootClock is the time since the process started on Go's monotonic clock.
+func bootClock() time.Duration {
+	return time.Since(processStart)
+}
+
+// bootID is empty: the boot clock only holds within one run.
+func bootID() string {
+	return ""
+}
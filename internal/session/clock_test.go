@@ -0,0 +1,71 @@
+package session
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSessionClockReconcile(t *testing.T) {
+	start := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		countsSleep bool
+		wall        time.Time
+		elapsed     time.Duration
+		want        time.Time
+		jumped      bool
+	}{
+		{"clocks agree", true, start.Add(10 * time.Minute), 10 * time.Minute, start.Add(10 * time.Minute), false},
+		{"slight drift", true, start.Add(10*time.Minute + time.Second), 10 * time.Minute, start.Add(10*time.Minute + time.Second), false},
+		{"set back", true, start.Add(-time.Hour), 10 * time.Minute, start.Add(10 * time.Minute), true},
+		{"set forward", true, start.Add(26 * time.Hour), 10 * time.Minute, start.Add(10 * time.Minute), true},
+		// A boot clock that stops in suspend can't tell sleeping from the
+		// clock being set forward, and takes it for sleep.
+		{"slept", false, start.Add(8 * time.Hour), 10 * time.Minute, start.Add(8 * time.Hour), false},
+		{"set back without sleep counted", false, start.Add(-time.Hour), 10 * time.Minute, start.Add(10 * time.Minute), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := sessionClock{wall: start, countsSleep: tt.countsSleep}
+			got, jumped := clock.reconcile(tt.wall, tt.elapsed)
+			if !got.Equal(tt.want) || jumped != tt.jumped {
+				t.Fatalf("reconcile(%s, %s) = %s, %v; want %s, %v", tt.wall, tt.elapsed, got, jumped, tt.want, tt.jumped)
+			}
+		})
+	}
+}
+
+func TestStartClockBackdated(t *testing.T) {
+	clock := startClock(time.Now().Add(-time.Minute))
+	if elapsed := clock.elapsed(); elapsed < time.Minute || elapsed > time.Minute+clockJumpTolerance {
+		t.Fatalf("expected about a minute elapsed since a backdated start, got %s", elapsed)
+	}
+	if clock.wall.Location() != time.UTC {
+		t.Fatalf("expected the wall anchor in UTC, got %s", clock.wall.Location())
+	}
+	if now := clock.now(); now.Sub(time.Now()).Abs() > clockJumpTolerance {
+		t.Fatalf("expected now to follow the wall clock while it agrees, got %s", now)
+	}
+	if (sessionClock{}).elapsed() != 0 {
+		t.Fatal("expected no elapsed time without a session")
+	}
+}
+
+func TestPauseTrackerCountsPausesWithinSession(t *testing.T) {
+	clock := startClock(time.Now().Add(-10 * time.Minute))
+	// Paused since before the session started, and once before that.
+	p := pauseTracker{paused: true, since: clock.boot - time.Hour, total: time.Hour}
+	p.startSession(clock)
+	if got := p.pausedFor(clock); got < 10*time.Minute || got > 10*time.Minute+clockJumpTolerance {
+		t.Fatalf("expected only the paused part of the session counted, got %s", got)
+	}
+
+	p.set(false)
+	paused := p.pausedFor(clock)
+	p.set(true)
+	p.since -= 2 * time.Minute
+	if got := p.pausedFor(clock) - paused; got < 2*time.Minute || got > 2*time.Minute+clockJumpTolerance {
+		t.Fatalf("expected a second pause added, got %s", got)
+	}
+}
@@ -0,0 +1,18 @@
+package session
+
+import "github.com/sjawhar/ghost-wispr/internal/transcribe"
+
+// WithConfidenceFloor drops words transcribed with less than floor
+// confidence from every transcript, live, recovered or imported, before
+// they become segments. Words the provider gave no confidence for are kept.
+func WithConfidenceFloor(floor float64) Option {
+	return func(m *Manager) {
+		m.confidenceFloor = floor
+	}
+}
+
+// groupWords turns words into segments by speaker, less the words below
+// the confidence floor.
+func (m *Manager) groupWords(words []transcribe.Word) []transcribe.Segment {
+	return transcribe.GroupWordsBySpeaker(transcribe.DropLowConfidence(words, m.confidenceFloor))
+}
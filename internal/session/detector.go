@@ -5,6 +5,9 @@ import (
 	"time"
 )
 
+// Detector ends a session once no speech has been heard for the timeout,
+// measured on the monotonic clock so that clock changes neither cut a
+// session short nor keep it open.
 type Detector struct {
 	timeout      time.Duration
 	mu           sync.Mutex
@@ -0,0 +1,89 @@
+package session
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/sjawhar/ghost-wispr/internal/jobs"
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+)
+
+// AsyncRecorder is a Recorder that can stop a session without waiting for
+// its audio to be encoded.
+type AsyncRecorder interface {
+	// StopSession stops recording and returns a function that encodes the
+	// session's audio, or nil if no session was recording.
+	StopSession() (func() (string, error), error)
+}
+
+// WithEncodingPool caps how many sessions' audio is encoded at once; the
+// rest wait their turn in the encoding state.
+func WithEncodingPool(pool *jobs.Pool) Option {
+	return func(m *Manager) {
+		m.encodingPool = pool
+	}
+}
+
+// stopRecording stops recording the current session. When the recorder
+// can defer encoding, its audio path is empty and encode is returned for
+// queueEncoding; otherwise the audio is encoded before it returns.
+func (m *Manager) stopRecording() (audioPath string, encode func() (string, error), err error) {
+	if m.recorder == nil {
+		return "", nil, nil
+	}
+	if async, ok := m.recorder.(AsyncRecorder); ok {
+		encode, err = async.StopSession()
+		return "", encode, err
+	}
+	audioPath, err = m.recorder.EndSession()
+	return audioPath, nil, err
+}
+
+// queueEncoding encodes an ended session's audio in the background,
+// tracking it on the session as storage.AudioEncoding until it is ready or
+// has failed.
+func (m *Manager) queueEncoding(sessionID string, encode func() (string, error)) {
+	if err := m.store.SetAudioStatus(sessionID, storage.AudioEncoding, ""); err != nil {
+		slog.Warn("recording audio status failed", "session", sessionID, "error", err)
+	}
+	m.trackPendingAudio(sessionID)
+	m.encoding.Add(1)
+	m.encodingPool.Go(func() {
+		defer m.encoding.Done()
+		m.encodeAudio(sessionID, encode)
+	})
+}
+
+// encodeAudio encodes a stopped session's audio and announces the outcome.
+func (m *Manager) encodeAudio(sessionID string, encode func() (string, error)) {
+	status := storage.AudioReady
+	audioPath, err := encode()
+	if err != nil {
+		slog.Warn("encoding session audio failed", "session", sessionID, "error", err)
+		status, audioPath = storage.AudioFailed, ""
+	}
+	if err := m.store.SetAudioStatus(sessionID, status, audioPath); err != nil {
+		slog.Warn("recording audio status failed", "session", sessionID, "error", err)
+	}
+	m.recordWAV(sessionID, audioPath)
+	m.audioDone(sessionID)
+	if m.hub != nil {
+		m.hub.BroadcastAudioReady(sessionID, status, audioPath)
+	}
+}
+
+// WaitForEncoding blocks until the audio of every ended session is encoded,
+// or ctx is done. Audio left unencoded is recovered at the next startup.
+func (m *Manager) WaitForEncoding(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		m.encoding.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,83 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+)
+
+// asyncRecorderStub hands back encoders that wait for release.
+type asyncRecorderStub struct {
+	recorderMock
+	release chan struct{}
+	err     error
+}
+
+func (r *asyncRecorderStub) StopSession() (func() (string, error), error) {
+	r.mu.Lock()
+	id := r.started[len(r.started)-1]
+	r.mu.Unlock()
+	return func() (string, error) {
+		<-r.release
+		if r.err != nil {
+			return "", r.err
+		}
+		return "data/audio/" + id + ".mp3", nil
+	}, nil
+}
+
+func TestManager_EncodesAudioInBackground(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		err    error
+		status string
+	}{
+		{"ready", nil, storage.AudioReady},
+		{"failed", errors.New("ffmpeg crashed"), storage.AudioFailed},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			store := newStoreMock()
+			hub := &hubMock{}
+			rec := &asyncRecorderStub{release: make(chan struct{}), err: tc.err}
+			manager := NewManager(store, rec, nil, hub, NewDetector(time.Hour))
+
+			if err := manager.ensureSessionStarted(time.Now()); err != nil {
+				t.Fatalf("ensureSessionStarted failed: %v", err)
+			}
+			sessionID := manager.currentSession()
+			if err := manager.ForceEndSession(context.Background()); err != nil {
+				t.Fatalf("ForceEndSession failed: %v", err)
+			}
+
+			// The session has ended while its audio is still encoding.
+			store.mu.Lock()
+			status, ended := store.audioStatus[sessionID], store.status[sessionID]
+			store.mu.Unlock()
+			if status != storage.AudioEncoding || ended != "ended" {
+				t.Fatalf("expected an ended session with encoding audio, got %q %q", ended, status)
+			}
+
+			close(rec.release)
+			if err := manager.WaitForEncoding(context.Background()); err != nil {
+				t.Fatalf("WaitForEncoding failed: %v", err)
+			}
+			want := ""
+			if tc.err == nil {
+				want = "data/audio/" + sessionID + ".mp3"
+			}
+			store.mu.Lock()
+			defer store.mu.Unlock()
+			if store.audioStatus[sessionID] != tc.status || store.audio[sessionID] != want {
+				t.Fatalf("expected audio %q at %q, got %q at %q", tc.status, want, store.audioStatus[sessionID], store.audio[sessionID])
+			}
+			hub.mu.Lock()
+			defer hub.mu.Unlock()
+			if len(hub.audioReady) != 1 || hub.audioReady[0] != sessionID+" "+tc.status {
+				t.Fatalf("expected one audio_ready event, got %v", hub.audioReady)
+			}
+		})
+	}
+}
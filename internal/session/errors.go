@@ -4,3 +4,20 @@ import "errors"
 
 // ErrNoActiveSession is returned by ForceEndSession when no session is active.
 var ErrNoActiveSession = errors.New("no active session")
+
+// ErrSummarizationUnavailable is returned by Resummarize when no summarizer is configured.
+var ErrSummarizationUnavailable = errors.New("summarization not configured")
+
+// ErrMemoInProgress is returned by StartMemo while a voice memo is recording.
+var ErrMemoInProgress = errors.New("voice memo already recording")
+
+// ErrNoMemoInProgress is returned by StopMemo when no voice memo is recording.
+var ErrNoMemoInProgress = errors.New("no voice memo recording")
+
+// ErrNoSummary is returned by RewriteSummary for a session without a
+// finished summary.
+var ErrNoSummary = errors.New("session has no summary")
+
+// ErrSessionRecording is returned when purging the session still being
+// recorded.
+var ErrSessionRecording = errors.New("session is still recording")
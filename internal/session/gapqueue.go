@@ -0,0 +1,242 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+	"github.com/sjawhar/ghost-wispr/internal/transcribe"
+)
+
+// gapJoinTolerance is how far apart the end of one gap and the start of the
+// next may be for them to be taken as one outage, which the fallback
+// writer hands on in pieces once its buffer fills.
+const gapJoinTolerance = 2 * time.Second
+
+// gapQueue holds offline audio that couldn't be transcribed yet, one PCM
+// file per gap with a JSON sidecar describing it. The sidecar is written
+// last, so a gap without one was never completely queued.
+type gapQueue struct {
+	dir       string
+	interval  time.Duration
+	saveAudio func(sessionID string, gap transcribe.Gap) (string, error)
+}
+
+type queuedGap struct {
+	SessionID  string    `json:"session_id,omitempty"`
+	Start      time.Time `json:"start"`
+	SampleRate int       `json:"sample_rate"`
+}
+
+// WithGapQueue keeps gaps that can't be transcribed when live transcription
+// returns, such as during a longer outage or when the batch API is down too,
+// as files in dir until RunGapQueue manages to, checking every interval.
+// They survive restarts. Gaps from outside any session become sessions of
+// their own; saveAudio, if set, keeps their audio and returns the path it
+// is served from.
+func WithGapQueue(dir string, interval time.Duration, saveAudio func(sessionID string, gap transcribe.Gap) (string, error)) Option {
+	return func(m *Manager) {
+		m.gapQueue = &gapQueue{dir: dir, interval: interval, saveAudio: saveAudio}
+	}
+}
+
+func (q *gapQueue) add(gap transcribe.Gap) error {
+	if err := os.MkdirAll(q.dir, 0o755); err != nil {
+		return fmt.Errorf("create gap queue: %w", err)
+	}
+	name := filepath.Join(q.dir, fmt.Sprintf("%d", gap.Start.UnixNano()))
+	if err := os.WriteFile(name+".pcm", gap.PCM, 0o600); err != nil {
+		return fmt.Errorf("queue gap audio: %w", err)
+	}
+	meta, err := json.Marshal(queuedGap{SessionID: gap.SessionID, Start: gap.Start.UTC(), SampleRate: gap.SampleRate})
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(name+".json", meta, 0o600); err != nil {
+		return errors.Join(fmt.Errorf("queue gap: %w", err), os.Remove(name+".pcm"))
+	}
+	return nil
+}
+
+// pending lists the queued gaps' names, oldest first.
+func (q *gapQueue) pending() ([]string, error) {
+	entries, err := os.ReadDir(q.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if name, ok := strings.CutSuffix(e.Name(), ".json"); ok && !e.IsDir() {
+			names = append(names, name)
+		}
+	}
+	// Names are start times in nanoseconds, all the same length.
+	slices.Sort(names)
+	return names, nil
+}
+
+func (q *gapQueue) load(name string) (transcribe.Gap, error) {
+	path := filepath.Join(q.dir, name)
+	data, err := os.ReadFile(path + ".json")
+	if err != nil {
+		return transcribe.Gap{}, err
+	}
+	var meta queuedGap
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return transcribe.Gap{}, fmt.Errorf("read queued gap %s: %w", name, err)
+	}
+	pcm, err := os.ReadFile(path + ".pcm")
+	if err != nil {
+		return transcribe.Gap{}, err
+	}
+	return transcribe.Gap{SessionID: meta.SessionID, Start: meta.Start, PCM: pcm, SampleRate: meta.SampleRate}, nil
+}
+
+func (q *gapQueue) remove(name string) error {
+	path := filepath.Join(q.dir, name)
+	return errors.Join(os.Remove(path+".json"), os.Remove(path+".pcm"))
+}
+
+// RunGapQueue transcribes queued gaps, oldest first, until ctx is
+// cancelled. A pass stops at the first gap that still can't be transcribed
+// and is tried again after the queue's interval. Adjacent gaps from outside
+// any session, the pieces of one long outage, become one session.
+func (m *Manager) RunGapQueue(ctx context.Context) {
+	if m.gapQueue == nil || m.gapTranscriber == nil {
+		return
+	}
+
+	ticker := time.NewTicker(m.gapQueue.interval)
+	defer ticker.Stop()
+	for {
+		m.retryQueuedGaps(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *Manager) retryQueuedGaps(ctx context.Context) {
+	names, err := m.gapQueue.pending()
+	if err != nil {
+		slog.Warn("listing queued gaps failed", "error", err)
+		return
+	}
+	for len(names) > 0 {
+		if ctx.Err() != nil {
+			return
+		}
+		gap, err := m.gapQueue.load(names[0])
+		if err != nil {
+			slog.Warn("dropping unreadable queued gap", "gap", names[0], "error", err)
+			_ = m.gapQueue.remove(names[0])
+			names = names[1:]
+			continue
+		}
+		run, gaps := m.gapQueue.adjacent(names, gap)
+		words, err := m.transcribeGaps(ctx, gaps)
+		if err != nil {
+			slog.Debug("queued gap still can't be transcribed", "gap", names[0], "error", err)
+			return
+		}
+		err = m.spliceGap(ctx, joinGaps(gaps), words, true)
+		switch {
+		case errors.Is(err, storage.ErrSessionLocked):
+			// Its session was signed off while the gap waited; the
+			// transcript stays as it was locked.
+			slog.Warn("dropping queued gap of a locked session", "gap", names[0], "session", gap.SessionID)
+		case err != nil:
+			slog.Warn("storing queued gap failed", "gap", names[0], "error", err)
+			return
+		}
+		for _, name := range run {
+			if err := m.gapQueue.remove(name); err != nil {
+				slog.Warn("removing transcribed gap failed", "gap", name, "error", err)
+			}
+		}
+		names = names[len(run):]
+	}
+}
+
+// adjacent returns the queued gaps, named in order from names[0], whose
+// audio runs on from first's: pieces of one outage outside any session. A
+// gap within a session stands alone.
+func (q *gapQueue) adjacent(names []string, first transcribe.Gap) ([]string, []transcribe.Gap) {
+	gaps := []transcribe.Gap{first}
+	if first.SessionID != "" {
+		return names[:1], gaps
+	}
+	for _, name := range names[1:] {
+		last := gaps[len(gaps)-1]
+		next, err := q.load(name)
+		if err != nil || next.SessionID != "" || next.SampleRate != last.SampleRate ||
+			next.Start.Sub(last.Start.Add(last.Duration())).Abs() > gapJoinTolerance {
+			break
+		}
+		gaps = append(gaps, next)
+	}
+	return names[:len(gaps)], gaps
+}
+
+// transcribeGaps transcribes consecutive gaps, timing the words from the
+// start of the first as if their audio were joined.
+func (m *Manager) transcribeGaps(ctx context.Context, gaps []transcribe.Gap) ([]transcribe.Word, error) {
+	var words []transcribe.Word
+	var offset float64
+	for _, gap := range gaps {
+		transcribed, err := m.gapTranscriber.TranscribePCM(ctx, gap.PCM, gap.SampleRate)
+		if err != nil {
+			return nil, err
+		}
+		for _, w := range transcribed {
+			w.Start += offset
+			w.End += offset
+			words = append(words, w)
+		}
+		offset += gap.Duration().Seconds()
+	}
+	return words, nil
+}
+
+// joinGaps joins the audio of consecutive gaps into one.
+func joinGaps(gaps []transcribe.Gap) transcribe.Gap {
+	if len(gaps) == 1 {
+		return gaps[0]
+	}
+	joined := gaps[0]
+	joined.PCM = nil
+	for _, gap := range gaps {
+		joined.PCM = append(joined.PCM, gap.PCM...)
+	}
+	return joined
+}
+
+// importGap stores a queued gap recorded outside any session as a finished
+// session spanning it, then summarizes it.
+func (m *Manager) importGap(ctx context.Context, gap transcribe.Gap, segments []transcribe.Segment) error {
+	var saveAudio func(string) (string, error)
+	if m.gapQueue.saveAudio != nil {
+		saveAudio = func(sessionID string) (string, error) { return m.gapQueue.saveAudio(sessionID, gap) }
+	}
+	sessionID, err := m.storeFinishedSession(gap.Start, gap.Duration(), segments, storage.AudioGap, saveAudio)
+	if err != nil {
+		return err
+	}
+	slog.Info("recovered offline session", "session", sessionID, "segments", len(segments), "gap", gap.Duration())
+	m.generateSummary(ctx, sessionID, "")
+	return nil
+}
@@ -0,0 +1,152 @@
+package session
+
+import (
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/sjawhar/ghost-wispr/internal/transcribe"
+)
+
+const (
+	// A run of words no longer than speakerBlipSeconds, given to another
+	// speaker in the middle of someone's continuous speech, is taken for a
+	// diarization slip and handed back to them.
+	speakerBlipSeconds = 0.8
+	// speakerContinuityGap is the longest pause, in seconds, that still
+	// counts as continuous speech.
+	speakerContinuityGap = 0.3
+)
+
+// WithSpeakerTracking keeps speaker numbers meaning the same person for a
+// whole session. Deepgram numbers speakers per connection, so after a
+// reconnect or failover its speaker 0 may be someone else; and within a
+// connection it sometimes gives a word or two of one person's sentence to
+// another. Multichannel streams, whose speakers follow their channels,
+// are left alone.
+func WithSpeakerTracking() Option {
+	return func(m *Manager) {
+		m.speakers = &speakerTracker{}
+	}
+}
+
+// speakerTracker maps the speaker numbers of each transcription stream
+// onto the session's. The first stream's numbers are the session's; later
+// streams' speakers are matched up in the order they are heard, the first
+// with whoever spoke last before the switch, the next with the speaker
+// heard before them, and so on, since a reconnect usually lands mid
+// conversation.
+type speakerTracker struct {
+	mu sync.Mutex
+	// stream identifies the connection being mapped; mapped is nil while
+	// it is the session's first.
+	stream string
+	mapped map[int]int
+	// recent lists the session's speakers, most recently heard first.
+	recent []int
+	next   int
+}
+
+// track renumbers the speakers of words, final results from stream, as
+// the session's and smooths over short slips.
+func (t *speakerTracker) track(stream string, words []transcribe.Word) []transcribe.Word {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if stream != "" && stream != t.stream {
+		if t.stream != "" {
+			t.mapped = make(map[int]int)
+		}
+		t.stream = stream
+	}
+	for i, w := range words {
+		if w.Speaker != nil {
+			speaker := t.sessionSpeaker(*w.Speaker)
+			words[i].Speaker = &speaker
+		}
+	}
+	words = smoothSpeakerBlips(words)
+	for _, w := range words {
+		if w.Speaker != nil {
+			t.heard(*w.Speaker)
+		}
+	}
+	return words
+}
+
+// reset forgets the session's speakers, for the next session to number
+// its own.
+func (t *speakerTracker) reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	*t = speakerTracker{}
+}
+
+func (t *speakerTracker) sessionSpeaker(raw int) int {
+	if t.mapped == nil {
+		t.next = max(t.next, raw+1)
+		return raw
+	}
+	if speaker, ok := t.mapped[raw]; ok {
+		return speaker
+	}
+	speaker := -1
+	for _, candidate := range t.recent {
+		if !slices.Contains(slices.Collect(maps.Values(t.mapped)), candidate) {
+			speaker = candidate
+			break
+		}
+	}
+	if speaker < 0 {
+		speaker = t.next
+		t.next++
+	}
+	t.mapped[raw] = speaker
+	return speaker
+}
+
+func (t *speakerTracker) heard(speaker int) {
+	if len(t.recent) > 0 && t.recent[0] == speaker {
+		return
+	}
+	t.recent = slices.DeleteFunc(t.recent, func(s int) bool { return s == speaker })
+	t.recent = slices.Insert(t.recent, 0, speaker)
+}
+
+// smoothSpeakerBlips gives a brief run of another speaker's words back to
+// the speaker talking without a pause either side of it.
+func smoothSpeakerBlips(words []transcribe.Word) []transcribe.Word {
+	type run struct{ start, end int }
+	var runs []run
+	for i := range words {
+		if len(runs) > 0 && sameSpeaker(words[i].Speaker, words[runs[len(runs)-1].start].Speaker) {
+			runs[len(runs)-1].end = i
+			continue
+		}
+		runs = append(runs, run{i, i})
+	}
+
+	for i := 1; i+1 < len(runs); i++ {
+		prev, blip, next := runs[i-1], runs[i], runs[i+1]
+		around := words[prev.end].Speaker
+		if around == nil || !sameSpeaker(around, words[next.start].Speaker) {
+			continue
+		}
+		if words[blip.end].End-words[blip.start].Start > speakerBlipSeconds ||
+			words[blip.start].Start-words[prev.end].End > speakerContinuityGap ||
+			words[next.start].Start-words[blip.end].End > speakerContinuityGap {
+			continue
+		}
+		for j := blip.start; j <= blip.end; j++ {
+			words[j].Speaker = around
+		}
+	}
+	return words
+}
+
+func sameSpeaker(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,62 @@
+package session
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestManager_SpeakerTrackingAcrossStreams(t *testing.T) {
+	store := newStoreMock()
+	hub := &hubMock{}
+	manager := NewManager(store, nil, nil, hub, NewDetector(time.Hour), WithSpeakerTracking())
+
+	for _, raw := range []string{
+		// Speaker 1's "the" is a slip in the middle of speaker 0's sentence.
+		`{"is_final": true, "speech_final": true, "metadata": {"request_id": "a"}, "channel": {"alternatives": [{
+			"transcript": "Let's start the review.",
+			"words": [{"speaker": 0, "punctuated_word": "Let's", "start": 0.0, "end": 0.3},
+			           {"speaker": 0, "punctuated_word": "start", "start": 0.3, "end": 0.6},
+			           {"speaker": 1, "punctuated_word": "the", "start": 0.65, "end": 0.8},
+			           {"speaker": 0, "punctuated_word": "review.", "start": 0.85, "end": 1.2}]
+		}]}}`,
+		`{"is_final": true, "speech_final": true, "metadata": {"request_id": "a"}, "channel": {"alternatives": [{
+			"transcript": "Sounds good.",
+			"words": [{"speaker": 1, "punctuated_word": "Sounds", "start": 2.0, "end": 2.3},
+			           {"speaker": 1, "punctuated_word": "good.", "start": 2.3, "end": 2.6}]
+		}]}}`,
+		// After a reconnect the new stream numbers its speakers from 0 again:
+		// its first is whoever spoke last, its second the one before them.
+		`{"is_final": true, "speech_final": true, "metadata": {"request_id": "b"}, "channel": {"alternatives": [{
+			"transcript": "I'll begin.",
+			"words": [{"speaker": 0, "punctuated_word": "I'll", "start": 10.0, "end": 10.2},
+			           {"speaker": 0, "punctuated_word": "begin.", "start": 10.2, "end": 10.6}]
+		}]}}`,
+		`{"is_final": true, "speech_final": true, "metadata": {"request_id": "b"}, "channel": {"alternatives": [{
+			"transcript": "Thanks.",
+			"words": [{"speaker": 1, "punctuated_word": "Thanks.", "start": 12.0, "end": 12.4}]
+		}]}}`,
+		`{"is_final": true, "speech_final": true, "metadata": {"request_id": "b"}, "channel": {"alternatives": [{
+			"transcript": "Sorry I'm late.",
+			"words": [{"speaker": 2, "punctuated_word": "Sorry", "start": 14.0, "end": 14.3},
+			           {"speaker": 2, "punctuated_word": "I'm", "start": 14.3, "end": 14.5},
+			           {"speaker": 2, "punctuated_word": "late.", "start": 14.5, "end": 14.9}]
+		}]}}`,
+	} {
+		if err := manager.Message(buildMsg(t, raw)); err != nil {
+			t.Fatalf("Message failed: %v", err)
+		}
+	}
+
+	segs := store.segments[hub.latestSession]
+	var speakers []int
+	for _, seg := range segs {
+		speakers = append(speakers, seg.Speaker)
+	}
+	if !slices.Equal(speakers, []int{0, 1, 1, 0, 2}) {
+		t.Fatalf("expected speakers 0, 1, 1, 0, 2, got %v", speakers)
+	}
+	if segs[0].Text != "Let's start the review." {
+		t.Fatalf("expected the slip folded into speaker 0's segment, got %q", segs[0].Text)
+	}
+}
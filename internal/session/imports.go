@@ -0,0 +1,168 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+	"github.com/sjawhar/ghost-wispr/internal/transcribe"
+)
+
+// ErrImportUnavailable is returned when no batch transcriber is configured
+// for imported recordings.
+var ErrImportUnavailable = errors.New("audio import not configured")
+
+// WithImportTranscriber transcribes recordings made elsewhere, such as on a
+// phone, so they can be imported as sessions.
+func WithImportTranscriber(t transcribe.BatchTranscriber) Option {
+	return func(m *Manager) {
+		m.importTranscriber = t
+	}
+}
+
+// Recording is audio recorded elsewhere, to be imported as a session.
+type Recording struct {
+	// StartedAt is when the recording began.
+	StartedAt time.Time
+	// PCM is the recording as mono PCM16-LE at SampleRate. A long one is
+	// read from Audio instead, Size bytes of it, a chunk at a time so it is
+	// never held in memory whole.
+	PCM        []byte
+	Audio      io.Reader
+	Size       int64
+	SampleRate int
+	// SaveAudio, if set, keeps the recording's audio for the session and
+	// returns the path it is served from.
+	SaveAudio func(sessionID string) (string, error)
+}
+
+// Duration is how long the recording runs.
+func (r Recording) Duration() time.Duration {
+	if r.SampleRate <= 0 {
+		return 0
+	}
+	size := int64(len(r.PCM))
+	if r.Audio != nil {
+		size = r.Size
+	}
+	return time.Duration(size/2) * time.Second / time.Duration(r.SampleRate)
+}
+
+// importChunk is how much of a recording read from Audio is transcribed at
+// a time. A word cut at a chunk's end may be misheard, so chunks are long.
+const importChunk = 10 * time.Minute
+
+// ImportRecording transcribes a recording and stores it as a finished
+// session starting at rec.StartedAt, then summarizes it. Unlike live
+// sessions it returns only once the summary is done, so the caller can tidy
+// the source away knowing nothing else will read it.
+func (m *Manager) ImportRecording(ctx context.Context, rec Recording) (string, error) {
+	if m.importTranscriber == nil {
+		return "", ErrImportUnavailable
+	}
+
+	words, err := m.transcribeRecording(ctx, rec)
+	if err != nil {
+		return "", fmt.Errorf("transcribe recording: %w", err)
+	}
+	segments := m.groupWords(words)
+	m.redactSegments(segments)
+
+	sessionID, err := m.storeFinishedSession(rec.StartedAt, rec.Duration(), segments, storage.AudioImported, rec.SaveAudio)
+	if err != nil {
+		return sessionID, err
+	}
+	slog.Info("imported recording", "session", sessionID, "segments", len(segments), "duration", rec.Duration())
+
+	if len(segments) == 0 {
+		_ = m.store.UpdateSummary(sessionID, "", storage.SummaryCompleted, "")
+		return sessionID, nil
+	}
+	m.generateSummary(ctx, sessionID, "")
+	return sessionID, nil
+}
+
+// transcribeRecording transcribes rec, a chunk at a time when it is read
+// from Audio, with word times from the start of the recording.
+func (m *Manager) transcribeRecording(ctx context.Context, rec Recording) ([]transcribe.Word, error) {
+	if rec.Audio == nil {
+		return m.importTranscriber.TranscribePCM(ctx, rec.PCM, rec.SampleRate)
+	}
+	if rec.SampleRate <= 0 {
+		return nil, fmt.Errorf("invalid sample rate %d", rec.SampleRate)
+	}
+	var words []transcribe.Word
+	var start float64
+	buf := make([]byte, int(importChunk.Seconds())*rec.SampleRate*2)
+	for {
+		n, err := io.ReadFull(rec.Audio, buf)
+		if n -= n % 2; n > 0 {
+			chunk, err := m.importTranscriber.TranscribePCM(ctx, buf[:n], rec.SampleRate)
+			if err != nil {
+				return nil, err
+			}
+			for _, w := range chunk {
+				w.Start += start
+				w.End += start
+				words = append(words, w)
+			}
+			start += float64(n/2) / float64(rec.SampleRate)
+		}
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return words, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read recording: %w", err)
+		}
+	}
+}
+
+// storeFinishedSession stores segments transcribed from audio recorded
+// away from the live stream as a finished session that started at
+// startedAt and ran for duration, keeping the audio with saveAudio, if set,
+// as a file of kind, and records what transcribing it cost. It returns the
+// session's ID, once the session is created even if storing the rest fails.
+func (m *Manager) storeFinishedSession(startedAt time.Time, duration time.Duration, segments []transcribe.Segment, kind string, saveAudio func(sessionID string) (string, error)) (string, error) {
+	startedAt = startedAt.UTC()
+	sessionID := m.importSessionID(startedAt)
+	if err := m.store.CreateSession(sessionID, startedAt); err != nil {
+		return "", fmt.Errorf("create session: %w", err)
+	}
+	m.recordTranscriptionModel(sessionID)
+	for _, seg := range segments {
+		seg.Timestamp = startedAt.Add(time.Duration(seg.StartTime * float64(time.Second)))
+		if err := m.store.AppendSegment(sessionID, seg); err != nil {
+			return sessionID, fmt.Errorf("append segment: %w", err)
+		}
+	}
+
+	if err := m.store.EndSession(sessionID, startedAt.Add(duration), ""); err != nil {
+		return sessionID, fmt.Errorf("end session: %w", err)
+	}
+	if saveAudio != nil {
+		path, err := saveAudio(sessionID)
+		if err == nil {
+			_, err = m.store.AddAudioFile(sessionID, kind, path)
+		}
+		if err != nil {
+			slog.Warn("keeping session audio failed", "session", sessionID, "kind", kind, "error", err)
+		}
+	}
+	if err := m.store.RecordTranscriptionUsage(sessionID, duration, duration.Minutes()*m.transcriptionPerMinute); err != nil {
+		slog.Warn("recording transcription usage failed", "session", sessionID, "error", err)
+	}
+	return sessionID, nil
+}
+
+// importSessionID is the ID for a session starting at startedAt, past any
+// stored session and the one being recorded.
+func (m *Manager) importSessionID(startedAt time.Time) string {
+	return FreeID(startedAt, func(id string) bool {
+		_, err := m.store.GetSession(id)
+		return err == nil || id == m.CurrentSessionID()
+	})
+}
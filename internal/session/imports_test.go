@@ -0,0 +1,101 @@
+package session
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+	"github.com/sjawhar/ghost-wispr/internal/transcribe"
+)
+
+func TestManager_ImportRecording(t *testing.T) {
+	store := newStoreMock()
+	speaker := 0
+	summarized := make(chan string, 1)
+	manager := NewManager(store, nil, summarizerMock{called: summarized}, nil, NewDetector(time.Hour), WithImportTranscriber(batchTranscriberStub{
+		words: []transcribe.Word{
+			{Speaker: &speaker, PunctuatedWord: "Call", Start: 4, End: 4.5},
+			{Speaker: &speaker, PunctuatedWord: "Alice.", Start: 4.5, End: 5},
+		},
+	}))
+
+	start := time.Date(2026, 3, 2, 8, 30, 0, 0, time.UTC)
+	// A session already starting that second pushes the import along.
+	if err := store.CreateSession("20260302083000", start); err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+
+	var savedFor string
+	sessionID, err := manager.ImportRecording(context.Background(), Recording{
+		StartedAt:  start,
+		PCM:        make([]byte, 10*32000),
+		SampleRate: 16000,
+		SaveAudio: func(id string) (string, error) {
+			savedFor = id
+			return "data/audio/" + id + ".m4a", nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("ImportRecording failed: %v", err)
+	}
+	if sessionID != "20260302083001" || savedFor != sessionID {
+		t.Fatalf("expected the import as 20260302083001 with its audio saved, got %q (saved for %q)", sessionID, savedFor)
+	}
+	if got := <-summarized; got != sessionID {
+		t.Fatalf("expected %s summarized, got %s", sessionID, got)
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	segments := store.segments[sessionID]
+	if len(segments) != 1 || segments[0].Text != "Call Alice." {
+		t.Fatalf("unexpected imported segments %#v", segments)
+	}
+	if want := start.Add(4 * time.Second); !segments[0].Timestamp.Equal(want) {
+		t.Fatalf("expected the segment at %v, got %v", want, segments[0].Timestamp)
+	}
+	if store.audio[sessionID] != "data/audio/"+sessionID+".m4a" || store.audioKind[sessionID] != storage.AudioImported {
+		t.Fatalf("expected the saved audio recorded as imported, got %q (%s)", store.audio[sessionID], store.audioKind[sessionID])
+	}
+	if store.status[sessionID] != storage.SummaryCompleted || !strings.Contains(store.summary[sessionID], "Call Alice.") {
+		t.Fatalf("expected a completed summary, got %q (%s)", store.summary[sessionID], store.status[sessionID])
+	}
+	if got := store.usage[sessionID].TranscriptionMinutes; got != 10.0/60 {
+		t.Fatalf("expected 10s of transcription recorded, got %v minutes", got)
+	}
+}
+
+func TestManager_ImportRecordingWithoutTranscriber(t *testing.T) {
+	manager := NewManager(newStoreMock(), nil, nil, nil, NewDetector(time.Hour))
+	if _, err := manager.ImportRecording(context.Background(), Recording{}); !errors.Is(err, ErrImportUnavailable) {
+		t.Fatalf("expected ErrImportUnavailable, got %v", err)
+	}
+}
+
+func TestManager_ImportRecordingReadsAudioInChunks(t *testing.T) {
+	manager := NewManager(newStoreMock(), nil, nil, nil, NewDetector(time.Hour), WithImportTranscriber(batchTranscriberStub{
+		words: []transcribe.Word{{PunctuatedWord: "hello", Start: 4, End: 4.5}},
+	}))
+
+	// Twelve minutes at 100 Hz make a whole chunk and a partial one.
+	const sampleRate = 100
+	size := int64(12 * 60 * sampleRate * 2)
+	words, err := manager.transcribeRecording(context.Background(), Recording{
+		Audio:      bytes.NewReader(make([]byte, size)),
+		Size:       size,
+		SampleRate: sampleRate,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(words) != 2 || words[0].Start != 4 || words[1].Start != 604 || words[1].End != 604.5 {
+		t.Fatalf("expected a word from each chunk, timed from the recording's start, got %+v", words)
+	}
+	if got := (Recording{Audio: bytes.NewReader(nil), Size: size, SampleRate: sampleRate}).Duration(); got != 12*time.Minute {
+		t.Fatalf("expected the duration taken from Size, got %v", got)
+	}
+}
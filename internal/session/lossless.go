@@ -0,0 +1,73 @@
+package session
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrSessionNotRecording is returned by KeepWAV for a session other than
+// the one being recorded.
+var ErrSessionNotRecording = errors.New("session is not recording")
+
+// ErrKeepWAVUnavailable is returned by KeepWAV when the recorder can't keep
+// lossless audio.
+var ErrKeepWAVUnavailable = errors.New("keeping lossless audio not supported")
+
+// WAVKeeper is a Recorder that can keep a session's lossless audio as WAV
+// next to its encoded recording, e.g. for interviews that need archival
+// quality.
+type WAVKeeper interface {
+	KeepWAV(sessionID string, keep bool)
+}
+
+// WithKeepWAV keeps every session's lossless audio unless KeepWAV turns it
+// off for one.
+func WithKeepWAV(keep bool) Option {
+	return func(m *Manager) {
+		m.keepWAV = keep
+	}
+}
+
+// KeepWAV sets whether the session being recorded keeps its lossless audio
+// once it ends.
+func (m *Manager) KeepWAV(sessionID string, keep bool) error {
+	if sessionID == "" || sessionID != m.CurrentSessionID() {
+		return ErrSessionNotRecording
+	}
+	return m.setKeepWAV(sessionID, keep)
+}
+
+func (m *Manager) setKeepWAV(sessionID string, keep bool) error {
+	keeper, ok := m.recorder.(WAVKeeper)
+	if !ok {
+		return ErrKeepWAVUnavailable
+	}
+	if err := m.store.SetKeepWAV(sessionID, keep); err != nil {
+		return err
+	}
+	keeper.KeepWAV(sessionID, keep)
+	return nil
+}
+
+// recordWAV records where a session flagged with KeepWAV had its lossless
+// audio kept, once its recording is encoded to audioPath.
+func (m *Manager) recordWAV(sessionID, audioPath string) {
+	if audioPath == "" {
+		return
+	}
+	sess, err := m.store.GetSession(sessionID)
+	if err != nil || !sess.KeepWAV {
+		return
+	}
+	wavPath := strings.TrimSuffix(audioPath, filepath.Ext(audioPath)) + ".wav"
+	if _, err := os.Stat(wavPath); err != nil {
+		slog.Warn("lossless audio was not kept", "session", sessionID, "error", err)
+		return
+	}
+	if err := m.store.SetWAVPath(sessionID, wavPath); err != nil {
+		slog.Warn("recording lossless audio path failed", "session", sessionID, "error", err)
+	}
+}
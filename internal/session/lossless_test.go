@@ -0,0 +1,68 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// wavRecorderStub writes a WAV next to the MP3 of sessions flagged to keep one.
+type wavRecorderStub struct {
+	recorderMock
+	dir  string
+	keep map[string]bool
+}
+
+func (r *wavRecorderStub) KeepWAV(sessionID string, keep bool) {
+	r.keep[sessionID] = keep
+}
+
+func (r *wavRecorderStub) EndSession() (string, error) {
+	path, _ := r.recorderMock.EndSession()
+	id := r.started[len(r.started)-1]
+	if r.keep[id] {
+		if err := os.WriteFile(filepath.Join(r.dir, id+".wav"), nil, 0o644); err != nil {
+			return "", err
+		}
+	}
+	return filepath.Join(r.dir, filepath.Base(path)), nil
+}
+
+func TestManager_KeepWAV(t *testing.T) {
+	store := newStoreMock()
+	rec := &wavRecorderStub{dir: t.TempDir(), keep: map[string]bool{}}
+	manager := NewManager(store, rec, nil, nil, NewDetector(time.Hour), WithKeepWAV(true))
+
+	if err := manager.KeepWAV("20260301-090000", true); !errors.Is(err, ErrSessionNotRecording) {
+		t.Fatalf("expected ErrSessionNotRecording without a session, got %v", err)
+	}
+
+	var ids []string
+	for _, keep := range []bool{true, false} {
+		if err := manager.ensureSessionStarted(time.Now().Add(time.Duration(len(ids)) * time.Minute)); err != nil {
+			t.Fatalf("ensureSessionStarted failed: %v", err)
+		}
+		sessionID := manager.currentSession()
+		ids = append(ids, sessionID)
+		if !keep {
+			if err := manager.KeepWAV(sessionID, false); err != nil {
+				t.Fatalf("KeepWAV failed: %v", err)
+			}
+		}
+		if err := manager.ForceEndSession(context.Background()); err != nil {
+			t.Fatalf("ForceEndSession failed: %v", err)
+		}
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if want := filepath.Join(rec.dir, ids[0]+".wav"); !store.keepWAV[ids[0]] || store.wavPath[ids[0]] != want {
+		t.Fatalf("expected the default to keep %s, got keep=%v at %q", want, store.keepWAV[ids[0]], store.wavPath[ids[0]])
+	}
+	if store.keepWAV[ids[1]] || store.wavPath[ids[1]] != "" {
+		t.Fatalf("expected the opted-out session to keep no wav, got %q", store.wavPath[ids[1]])
+	}
+}
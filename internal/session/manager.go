@@ -1,16 +1,26 @@
 package session
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
+	"maps"
+	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"time"
 
 	api "github.com/deepgram/deepgram-go-sdk/v3/pkg/api/listen/v1/websocket/interfaces"
 
+	"github.com/sjawhar/ghost-wispr/internal/config"
+	"github.com/sjawhar/ghost-wispr/internal/jobs"
+	"github.com/sjawhar/ghost-wispr/internal/llm"
+	"github.com/sjawhar/ghost-wispr/internal/redact"
 	"github.com/sjawhar/ghost-wispr/internal/storage"
+	"github.com/sjawhar/ghost-wispr/internal/summary"
 	"github.com/sjawhar/ghost-wispr/internal/transcribe"
 )
 
@@ -21,13 +31,185 @@ type Manager struct {
 	hub        EventBroadcaster
 	detector   *Detector
 	buffer     *UtteranceBuffer
+	decisions  DecisionExtractor
+	budget     *BudgetGuard
+	processor  SegmentProcessor
+	segments   *segmentQueue
+	renderer   SummaryRenderer
+	redactor   *redact.Redactor
+	// confidenceFloor is the least confidence a word is kept with.
+	confidenceFloor float64
+	speakers        *speakerTracker
+
+	speech    SpeechSynthesizer
+	speechDir string
+
+	summaryPool *jobs.Pool
+	// Ended sessions' audio is encoded on encodingPool; encoding counts the
+	// ones not yet done.
+	encodingPool *jobs.Pool
+	encoding     sync.WaitGroup
+	keepWAV      bool
+	series       *SeriesDetector
+	classifier   *Classifier
+
+	// Summaries queued or running, for the queue endpoint and cancelling.
+	jobsMu          sync.Mutex
+	jobSeq          int64
+	summaryJobs     map[int64]*summaryJob
+	summaryAttempts map[string]int
+	summaryDuration time.Duration
+
+	actionItems     ActionItemExtractor
+	actionItemStore ActionItemStore
+
+	notes NoteStore
+
+	speakerNames SpeakerNameStore
+
+	// With voices set, summaries wait for the session's audio, pending
+	// while it encodes, so that recognized voices are named in them.
+	voices       VoiceIdentifier
+	audioPending map[string]chan struct{}
+
+	memoPreset string
+	dictation  Dictator
+	exports    SummaryExporter
+
+	offlineProbe    NetworkProbe
+	offlineInterval time.Duration
+	gapTranscriber  transcribe.BatchTranscriber
+	gapQueue        *gapQueue
+
+	importTranscriber transcribe.BatchTranscriber
+
+	modelPricing           map[string]config.ModelPricing
+	transcriptionPerMinute float64
+
+	transcriptionModel    string
+	transcriptionLanguage string
 
 	mu               sync.Mutex
 	currentSessionID string
-	currentStartedAt time.Time
+	currentClock     sessionClock
+	lastSessionID    string
+	// capture tracks pauses, so paused time isn't billed as transcription.
+	capture pauseTracker
+	// recordingMemo is set while the current session is a voice memo, which
+	// ignores the silence timeout.
+	recordingMemo bool
+	// liveProvider and liveModel are the live transcription service in use
+	// and its model, once SetTranscriptionProvider has named one.
+	liveProvider string
+	liveModel    string
+	// channelSpeakers is set while speakers follow the capture's channels.
+	channelSpeakers bool
 }
 
-func NewManager(store Store, recorder Recorder, summarizer Summarizer, hub EventBroadcaster, detector *Detector) *Manager {
+// Option configures optional Manager behavior.
+type Option func(*Manager)
+
+// WithDecisionExtractor records the decisions made in each session after it
+// has been summarized successfully.
+func WithDecisionExtractor(extractor DecisionExtractor) Option {
+	return func(m *Manager) {
+		m.decisions = extractor
+	}
+}
+
+// WithPricing sets the rates used to cost each session's transcription time
+// and summarization tokens. Models without pricing are metered at zero cost.
+func WithPricing(models map[string]config.ModelPricing, transcriptionPerMinute float64) Option {
+	return func(m *Manager) {
+		m.modelPricing = models
+		m.transcriptionPerMinute = transcriptionPerMinute
+	}
+}
+
+// WithTranscriptionModel names the model and language sessions are
+// transcribed with, recorded on each new session.
+func WithTranscriptionModel(model, language string) Option {
+	return func(m *Manager) {
+		m.transcriptionModel = model
+		m.transcriptionLanguage = language
+	}
+}
+
+// WithBudget degrades summarization once the monthly LLM budget is spent.
+func WithBudget(guard *BudgetGuard) Option {
+	return func(m *Manager) {
+		m.budget = guard
+	}
+}
+
+// WithSegmentProcessor passes each final segment through processor before it
+// is stored and broadcast. Processing runs on its own goroutine, in order, so
+// a slow processor delays its segments rather than the transcription stream.
+// Segments are kept unchanged if processing fails.
+func WithSegmentProcessor(processor SegmentProcessor) Option {
+	return func(m *Manager) {
+		m.processor = processor
+	}
+}
+
+// WithSummaryPool caps how many summaries run at once; the rest wait their
+// turn in the pending state.
+func WithSummaryPool(pool *jobs.Pool) Option {
+	return func(m *Manager) {
+		m.summaryPool = pool
+	}
+}
+
+// WithSummaryAudio renders each completed summary to speech in dir, served
+// alongside the session for listening back.
+func WithSummaryAudio(synth SpeechSynthesizer, dir string) Option {
+	return func(m *Manager) {
+		m.speech = synth
+		m.speechDir = dir
+	}
+}
+
+// WithSummaryRenderer stores each generated summary as rendered by renderer,
+// e.g. tidied up and wrapped in a document template.
+func WithSummaryRenderer(renderer SummaryRenderer) Option {
+	return func(m *Manager) {
+		m.renderer = renderer
+	}
+}
+
+// WithSeriesDetection threads each finished session into a recurring
+// meeting series when it matches an earlier session's weekly slot.
+func WithSeriesDetection(detector *SeriesDetector) Option {
+	return func(m *Manager) {
+		m.series = detector
+	}
+}
+
+// WithClassifier sorts each finished session into a meeting or ambient
+// chatter and applies that class's summarization and notification policy.
+func WithClassifier(classifier *Classifier) Option {
+	return func(m *Manager) {
+		m.classifier = classifier
+	}
+}
+
+// WithDictation hands the text of each final segment to dictator once it has
+// been stored, for dictation output.
+func WithDictation(dictator Dictator) Option {
+	return func(m *Manager) {
+		m.dictation = dictator
+	}
+}
+
+// WithExports hands each session to exporter once its summary is stored,
+// unless its class is kept quiet.
+func WithExports(exporter SummaryExporter) Option {
+	return func(m *Manager) {
+		m.exports = exporter
+	}
+}
+
+func NewManager(store Store, recorder Recorder, summarizer Summarizer, hub EventBroadcaster, detector *Detector, opts ...Option) *Manager {
 	if detector == nil {
 		detector = NewDetector(30 * time.Second)
 	}
@@ -40,8 +222,17 @@ func NewManager(store Store, recorder Recorder, summarizer Summarizer, hub Event
 		detector:   detector,
 		buffer:     NewUtteranceBuffer(),
 	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	if m.processor != nil {
+		m.segments = newSegmentQueue(m.processSegment, m.storeSegment)
+	}
 
 	detector.OnSessionEnd(func() {
+		if m.RecordingMemo() {
+			return
+		}
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
 		_ = m.endCurrentSession(ctx)
@@ -61,13 +252,20 @@ func (m *Manager) Message(mr *api.MessageResponse) error {
 	}
 
 	// Extract words from the Deepgram response.
+	channel, channels, multichannel := audioChannel(mr)
 	words := make([]transcribe.Word, 0, len(mr.Channel.Alternatives[0].Words))
 	for _, word := range mr.Channel.Alternatives[0].Words {
+		speaker := word.Speaker
+		if multichannel {
+			speaker = channelSpeaker(channel, channels, speaker)
+		}
 		words = append(words, transcribe.Word{
-			Speaker:        word.Speaker,
+			Speaker:        speaker,
 			PunctuatedWord: word.PunctuatedWord,
 			Start:          word.Start,
 			End:            word.End,
+			Language:       word.Language,
+			Confidence:     word.Confidence,
 		})
 	}
 
@@ -102,7 +300,7 @@ func (m *Manager) Message(mr *api.MessageResponse) error {
 				}
 				startTime = words[0].Start
 			}
-			m.hub.BroadcastLiveTranscriptInterim(speaker, broadcastText, startTime)
+			m.hub.BroadcastLiveTranscriptInterim(speaker, m.redactor.Redact(broadcastText), startTime)
 		}
 		return nil
 	}
@@ -110,6 +308,13 @@ func (m *Manager) Message(mr *api.MessageResponse) error {
 	// If is_final but no word timings provided, create a fallback word (Fix #7).
 	if len(words) == 0 {
 		words = []transcribe.Word{{PunctuatedWord: sentence, Start: 0, End: 0}}
+		if multichannel {
+			words[0].Speaker = channelSpeaker(channel, channels, nil)
+		}
+	}
+
+	if m.speakers != nil && !multichannel {
+		words = m.speakers.track(mr.Metadata.RequestID, words)
 	}
 
 	// Final result — buffer words until speech_final.
@@ -133,7 +338,7 @@ func (m *Manager) Message(mr *api.MessageResponse) error {
 					startTime = w.Start
 				}
 			}
-			m.hub.BroadcastLiveTranscriptInterim(speaker, b.String(), startTime)
+			m.hub.BroadcastLiveTranscriptInterim(speaker, m.redactor.Redact(b.String()), startTime)
 		}
 	}
 
@@ -145,6 +350,29 @@ func (m *Manager) Message(mr *api.MessageResponse) error {
 	return nil
 }
 
+// audioChannel reports which channel of a multichannel stream mr transcribes,
+// and how many channels the stream has.
+func audioChannel(mr *api.MessageResponse) (channel, channels int, ok bool) {
+	if len(mr.ChannelIndex) != 2 || mr.ChannelIndex[1] < 2 {
+		return 0, 0, false
+	}
+	return mr.ChannelIndex[0], mr.ChannelIndex[1], true
+}
+
+// channelSpeaker numbers speakers across the channels of a multichannel
+// stream. In a two-channel microphone + loopback stream everyone on the
+// microphone channel is speaker 0 ("me"), and the loopback channel's
+// diarized speakers ("them") follow from 1, so the two sides never share a
+// label. With more channels, one microphone per person, each channel is its
+// own speaker.
+func channelSpeaker(channel, channels int, speaker *int) *int {
+	mapped := channel
+	if channels == 2 && channel > 0 && speaker != nil {
+		mapped += *speaker
+	}
+	return &mapped
+}
+
 func (m *Manager) UtteranceEnd(_ *api.UtteranceEndResponse) error {
 	if err := m.flushBuffer(); err != nil {
 		return err
@@ -159,25 +387,57 @@ func (m *Manager) flushBuffer() error {
 		return nil
 	}
 
-	segments := transcribe.GroupWordsBySpeaker(words)
+	segments := m.groupWords(words)
 	if len(segments) == 0 {
 		return nil
 	}
+	m.redactSegments(segments)
 
-	for i := range segments {
-		segments[i].Timestamp = time.Now().UTC()
-		if err := m.ensureSessionStarted(segments[i].Timestamp); err != nil {
+	for _, seg := range segments {
+		seg.Timestamp = m.clockNow()
+		if m.segments != nil {
+			m.segments.push(seg)
+			continue
+		}
+		if err := m.storeSegment(seg); err != nil {
 			return err
 		}
+	}
+	return nil
+}
 
-		sessionID := m.currentSession()
-		if err := m.store.AppendSegment(sessionID, segments[i]); err != nil {
-			return fmt.Errorf("append segment: %w", err)
-		}
+// processSegment runs seg through the segment processor, keeping it
+// unchanged if processing fails.
+func (m *Manager) processSegment(ctx context.Context, seg transcribe.Segment) (transcribe.Segment, bool) {
+	processed, keep, err := m.processor.ProcessSegment(ctx, seg)
+	switch {
+	case err != nil:
+		slog.Warn("segment processor failed, keeping segment unchanged", "error", err)
+		return seg, true
+	case !keep:
+		return seg, false
+	}
+	processed.DropStaleWords()
+	return processed, true
+}
 
-		if m.hub != nil {
-			m.hub.BroadcastLiveTranscript(segments[i])
-		}
+// storeSegment stores a final segment in the current session, starting one
+// if none is open, and passes it on to listeners.
+func (m *Manager) storeSegment(seg transcribe.Segment) error {
+	if err := m.ensureSessionStarted(seg.Timestamp); err != nil {
+		return err
+	}
+
+	sessionID := m.currentSession()
+	if err := m.store.AppendSegment(sessionID, seg); err != nil {
+		return fmt.Errorf("append segment: %w", err)
+	}
+
+	if m.hub != nil {
+		m.hub.BroadcastLiveTranscript(seg)
+	}
+	if m.dictation != nil {
+		m.dictation.Dictate(seg.Text)
 	}
 	return nil
 }
@@ -191,6 +451,20 @@ func (m *Manager) ForceEndSession(ctx context.Context) error {
 	return m.endCurrentSession(ctx)
 }
 
+// IDLayout formats a session's UTC start time as its ID, so IDs sort by
+// when sessions started.
+const IDLayout = "20060102150405"
+
+// FreeID is the ID for a session starting at startedAt, moved on a second at
+// a time past any ID taken reports in use.
+func FreeID(startedAt time.Time, taken func(id string) bool) string {
+	for at := startedAt.UTC(); ; at = at.Add(time.Second) {
+		if id := at.Format(IDLayout); !taken(id) {
+			return id
+		}
+	}
+}
+
 func (m *Manager) ensureSessionStarted(now time.Time) error {
 	m.mu.Lock()
 	if m.currentSessionID != "" {
@@ -198,32 +472,52 @@ func (m *Manager) ensureSessionStarted(now time.Time) error {
 		return nil
 	}
 
-	sessionID := now.UTC().Format("20060102150405")
-	if m.currentStartedAt.Format("20060102150405") == sessionID {
-		sessionID = now.UTC().Add(time.Second).Format("20060102150405")
+	sessionID := now.UTC().Format(IDLayout)
+	// Sessions started back to back, e.g. a memo right after a meeting, may
+	// fall in the same second; IDs must stay unique and ordered.
+	if sessionID <= m.lastSessionID {
+		last, _ := time.Parse(IDLayout, m.lastSessionID)
+		sessionID = last.Add(time.Second).Format(IDLayout)
 	}
 	startedAt := now.UTC()
+	clock := startClock(startedAt)
 	m.currentSessionID = sessionID
-	m.currentStartedAt = startedAt
+	m.lastSessionID = sessionID
+	m.currentClock = clock
+	m.capture.startSession(clock)
 	m.mu.Unlock()
 
 	if err := m.store.CreateSession(sessionID, startedAt); err != nil {
 		m.mu.Lock()
 		m.currentSessionID = ""
-		m.currentStartedAt = time.Time{}
+		m.currentClock = sessionClock{}
 		m.mu.Unlock()
 		return fmt.Errorf("create session: %w", err)
 	}
+	if err := m.store.SetSessionClock(sessionID, bootID(), clock.boot); err != nil {
+		slog.Warn("recording session clock failed", "session", sessionID, "error", err)
+	}
+	m.recordLiveTranscription(sessionID)
 
 	if m.recorder != nil {
 		if err := m.recorder.StartSession(sessionID); err != nil {
 			m.mu.Lock()
 			m.currentSessionID = ""
-			m.currentStartedAt = time.Time{}
+			m.currentClock = sessionClock{}
 			m.mu.Unlock()
 			_ = m.store.EndSession(sessionID, time.Now().UTC(), "")
 			return fmt.Errorf("start audio recorder session: %w", err)
 		}
+		if m.keepWAV {
+			if err := m.setKeepWAV(sessionID, true); err != nil {
+				slog.Warn("keeping lossless audio failed", "session", sessionID, "error", err)
+			}
+		}
+		if aligner, ok := m.recorder.(AudioAligner); ok {
+			if err := m.store.SetAudioOffset(sessionID, aligner.AudioStart()); err != nil {
+				slog.Warn("recording audio offset failed", "session", sessionID, "error", err)
+			}
+		}
 	}
 
 	if m.hub != nil {
@@ -234,81 +528,410 @@ func (m *Manager) ensureSessionStarted(now time.Time) error {
 }
 
 func (m *Manager) endCurrentSession(ctx context.Context) error {
+	// Segments still with the processor belong to this session.
+	if m.segments != nil {
+		m.segments.drain(ctx)
+	}
 	m.mu.Lock()
 	sessionID := m.currentSessionID
-	startedAt := m.currentStartedAt
+	clock := m.currentClock
+	paused := m.capture.pausedFor(clock)
+	memo := m.recordingMemo
 	if sessionID == "" {
 		m.mu.Unlock()
 		return ErrNoActiveSession
 	}
 
 	m.mu.Unlock()
+	if m.speakers != nil {
+		m.speakers.reset()
+	}
 
-	endedAt := time.Now().UTC()
-	audioPath := ""
-	if m.recorder != nil {
-		path, err := m.recorder.EndSession()
-		if err != nil {
-			return fmt.Errorf("end audio recorder session: %w", err)
-		}
-		audioPath = path
+	// Durations come from the boot clock: a session the wall clock was
+	// changed during ends as long after it started as it actually ran.
+	startedAt := clock.wall
+	elapsed := clock.elapsed()
+	endedAt, jumped := clock.reconcile(time.Now().UTC(), elapsed)
+	if jumped {
+		slog.Warn("system clock changed during session, timing it by elapsed time", "session", sessionID, "wall_clock", time.Now().UTC(), "elapsed", elapsed)
+	}
+	audioPath, encode, err := m.stopRecording()
+	if err != nil {
+		return fmt.Errorf("end audio recorder session: %w", err)
 	}
 
 	if err := m.store.EndSession(sessionID, endedAt, audioPath); err != nil {
 		return fmt.Errorf("end session: %w", err)
 	}
+	if encode != nil {
+		m.queueEncoding(sessionID, encode)
+	} else {
+		m.recordWAV(sessionID, audioPath)
+	}
+
+	duration := endedAt.Sub(startedAt)
+	transcribed := max(duration-paused, 0)
+	if err := m.store.RecordTranscriptionUsage(sessionID, transcribed, transcribed.Minutes()*m.transcriptionPerMinute); err != nil {
+		slog.Warn("recording transcription usage failed", "session", sessionID, "error", err)
+	}
+
+	ended := storage.Session{ID: sessionID, StartedAt: startedAt, EndedAt: &endedAt}
+	class, policy := m.classify(ended, memo)
+
+	if m.series != nil && !memo && class != storage.SessionKindAmbient {
+		if seriesID, err := m.series.Assign(ended); err != nil {
+			slog.Warn("series detection failed", "session", sessionID, "error", err)
+		} else if seriesID != "" {
+			slog.Info("session joined recurring series", "session", sessionID, "series", seriesID)
+		}
+	}
 
 	m.mu.Lock()
 	m.currentSessionID = ""
-	m.currentStartedAt = time.Time{}
+	m.currentClock = sessionClock{}
+	m.recordingMemo = false
 	m.mu.Unlock()
 
 	if m.hub != nil {
-		m.hub.BroadcastSessionEnded(sessionID, endedAt.Sub(startedAt))
+		m.hub.BroadcastSessionEnded(sessionID, duration)
 	}
 
-	go m.generateSummary(context.Background(), sessionID)
+	if !policy.Summarize {
+		_ = m.store.UpdateSummary(sessionID, "", storage.SummaryCompleted, "")
+		return nil
+	}
+	summaryCtx := context.Background()
+	if !policy.Notify {
+		summaryCtx = withQuiet(summaryCtx)
+	}
+	preset := ""
+	if memo {
+		preset = m.memoPreset
+	}
+	go m.generateSummary(summaryCtx, sessionID, preset)
 	return nil
 }
 
-func (m *Manager) generateSummary(ctx context.Context, sessionID string) {
+// classify records the class of a finished session and returns it with its
+// policy. Memos, and sessions that can't be classified, are summarized and
+// announced as usual.
+func (m *Manager) classify(sess storage.Session, memo bool) (string, ClassPolicy) {
+	if m.classifier == nil || memo {
+		return "", ClassPolicy{Summarize: true, Notify: true}
+	}
+	class, reason, err := m.classifier.Classify(sess)
+	if err != nil {
+		slog.Warn("classifying session failed", "session", sess.ID, "error", err)
+		return "", ClassPolicy{Summarize: true, Notify: true}
+	}
+	if err := m.store.SetSessionKind(sess.ID, class); err != nil {
+		slog.Warn("recording session class failed", "session", sess.ID, "error", err)
+	}
+	slog.Info("session classified", "session", sess.ID, "class", class, "reason", reason)
+	return class, m.classifier.Policy(class)
+}
+
+// generateSummary summarizes a session that just ended, with preset or, when
+// it is empty, whichever preset the summarizer picks.
+func (m *Manager) generateSummary(ctx context.Context, sessionID, preset string) {
 	if m.summarizer == nil {
 		_ = m.store.UpdateSummary(sessionID, "", storage.SummaryCompleted, "")
 		return
 	}
 
-	_ = m.store.UpdateSummary(sessionID, "", storage.SummaryRunning, "")
+	ctx, allowed := m.applyBudget(ctx, true)
+	if !allowed {
+		_ = m.store.UpdateSummary(sessionID, "", storage.SummarySkippedBudget, "")
+		m.broadcastSummaryStatus(sessionID, "", storage.SummarySkippedBudget, "")
+		return
+	}
+
+	m.identifyVoices(ctx, sessionID)
+	_ = m.summarize(ctx, sessionID, preset)
+}
 
+// Resummarize regenerates the summary of a stored session, using the given
+// preset or letting the summarizer pick one when preset is empty. Locked
+// sessions keep their summary: it returns storage.ErrSessionLocked.
+func (m *Manager) Resummarize(ctx context.Context, sessionID, preset string) error {
+	if m.summarizer == nil {
+		return ErrSummarizationUnavailable
+	}
+	if sess, err := m.store.GetSession(sessionID); err == nil && sess.Locked {
+		return storage.ErrSessionLocked
+	}
+
+	ctx, _ = m.applyBudget(ctx, false)
+	m.broadcastSummaryStatus(sessionID, "", storage.SummaryRunning, "")
+	return m.summarize(ctx, sessionID, preset)
+}
+
+// EstimateSummary projects the tokens and cost of resummarizing a session
+// with preset, per configured model, returning the preset it resolved to.
+func (m *Manager) EstimateSummary(ctx context.Context, sessionID, preset string) (string, []summary.Estimate, error) {
+	estimator, ok := m.summarizer.(SummaryEstimator)
+	if !ok {
+		return "", nil, ErrSummarizationUnavailable
+	}
+	if _, err := m.store.GetSession(sessionID); err != nil {
+		return "", nil, fmt.Errorf("get session: %w", err)
+	}
 	segments, err := m.store.GetSegments(sessionID)
 	if err != nil {
-		_ = m.store.UpdateSummary(sessionID, "", storage.SummaryFailed, "")
-		m.broadcastSummaryStatus(sessionID, "", storage.SummaryFailed, "")
-		return
+		return "", nil, fmt.Errorf("get segments: %w", err)
 	}
+	ctx = summary.WithTranscriptLanguages(ctx, segmentLanguages(segments))
+	return estimator.EstimateSummary(ctx, buildTranscript(segments), preset)
+}
 
-	var b strings.Builder
-	for _, segment := range segments {
-		if strings.TrimSpace(segment.Text) == "" {
-			continue
+func (m *Manager) applyBudget(ctx context.Context, auto bool) (context.Context, bool) {
+	if !m.budget.Enabled() {
+		return ctx, true
+	}
+	return m.budget.summaryPolicy(ctx, auto)
+}
+
+func (m *Manager) summarize(ctx context.Context, sessionID, preset string) (err error) {
+	ctx, job := m.queueSummary(ctx, sessionID, preset)
+	defer func() { m.finishSummary(job, err) }()
+
+	release, err := m.summaryPool.Acquire(ctx)
+	if err != nil {
+		if m.summaryCancelled(job) {
+			return m.markSummaryCancelled(sessionID, preset)
 		}
-		b.WriteString(segment.Text)
-		b.WriteString("\n")
+		return fmt.Errorf("wait for summary worker: %w", err)
 	}
+	defer release()
+	m.startSummary(job)
+
+	usage := llm.NewUsageRecorder()
+	ctx = llm.WithUsageRecorder(ctx, usage)
+	var latency time.Duration
+	defer func() { m.recordLLMUsage(sessionID, usage, latency) }()
 
-	summaryText, preset, err := m.summarizer.Summarize(ctx, sessionID, b.String())
+	if err := m.store.UpdateSummary(sessionID, "", storage.SummaryRunning, ""); errors.Is(err, storage.ErrSessionLocked) {
+		return err
+	}
+
+	segments, err := m.store.GetSegments(sessionID)
 	if err != nil {
 		_ = m.store.UpdateSummary(sessionID, "", storage.SummaryFailed, preset)
 		m.broadcastSummaryStatus(sessionID, "", storage.SummaryFailed, preset)
-		return
+		return fmt.Errorf("get segments: %w", err)
+	}
+	names := m.summarySpeakerNames(ctx, sessionID)
+	transcript := buildTranscript(segments)
+	if names != nil {
+		transcript = buildNamedTranscript(segments, names)
 	}
+	if notes := m.sessionNotes(sessionID); len(notes) > 0 {
+		transcript = buildTranscriptWithNotes(segments, notes, names)
+		ctx = summary.WithNotes(ctx)
+	}
+	prior := m.priorActionItems(sessionID)
+	ctx = summary.WithPriorActionItems(ctx, prior)
+	ctx = summary.WithTranscriptLanguages(ctx, segmentLanguages(segments))
+
+	var summaryText string
+	started := time.Now()
+	if preset != "" {
+		summaryText, err = m.summarizer.SummarizeWithPreset(ctx, sessionID, transcript, preset)
+	} else {
+		summaryText, preset, err = m.summarizer.Summarize(ctx, sessionID, transcript)
+	}
+	if err == nil {
+		latency = time.Since(started)
+	}
+	// Later extraction calls share the recorder, so the summary's models are
+	// taken before they run.
+	summaryModels := slices.Sorted(maps.Keys(usage.ByModel()))
+	if err != nil && m.summaryCancelled(job) {
+		return m.markSummaryCancelled(sessionID, preset)
+	}
+	if err != nil && m.offlineProbe != nil && isConnectivityError(err) {
+		slog.Warn("summary queued until network returns", "session", sessionID, "error", err)
+		_ = m.store.UpdateSummary(sessionID, "", storage.SummaryQueuedOffline, preset)
+		m.broadcastSummaryStatus(sessionID, "", storage.SummaryQueuedOffline, preset)
+		return err
+	}
+	if err != nil {
+		_ = m.store.UpdateSummary(sessionID, "", storage.SummaryFailed, preset)
+		m.broadcastSummaryStatus(sessionID, "", storage.SummaryFailed, preset)
+		return err
+	}
+
+	spoken := summaryText
+	summaryText = m.renderSummary(sessionID, summaryText, preset, segments, names)
 
 	if err := m.store.UpdateSummary(sessionID, summaryText, storage.SummaryCompleted, preset); err != nil {
 		_ = m.store.UpdateSummary(sessionID, "", storage.SummaryFailed, preset)
 		m.broadcastSummaryStatus(sessionID, "", storage.SummaryFailed, preset)
+		return fmt.Errorf("store summary: %w", err)
+	}
+	if err := m.store.SetSummaryModel(sessionID, strings.Join(summaryModels, ", ")); err != nil {
+		slog.Warn("recording summary model failed", "session", sessionID, "error", err)
+	}
+
+	if !isQuiet(ctx) {
+		m.broadcastSummaryStatus(sessionID, summaryText, storage.SummaryCompleted, preset)
+		if m.exports != nil {
+			m.exports.SummaryReady(sessionID)
+		}
+	}
+	m.storeCitations(sessionID, summaryText, spoken, segments)
+	m.extractDecisions(ctx, sessionID, transcript)
+	m.extractActionItems(ctx, sessionID, transcript, prior)
+	m.renderSummaryAudio(ctx, sessionID, spoken)
+	return nil
+}
+
+// renderSummary applies the summary renderer, if any, describing the session
+// from its stored record and who spoke in it, by name where known.
+func (m *Manager) renderSummary(sessionID, summaryText, preset string, segments []transcribe.Segment, names map[int]string) string {
+	if m.renderer == nil {
+		return summaryText
+	}
+	doc := summary.Document{SessionID: sessionID, Preset: preset}
+	if sess, err := m.store.GetSession(sessionID); err != nil {
+		slog.Warn("loading session for summary document failed", "session", sessionID, "error", err)
+	} else {
+		doc.StartedAt = sess.StartedAt
+		doc.EndedAt = sess.EndedAt
+	}
+	seen := make(map[int]bool)
+	for _, seg := range segments {
+		if !seen[seg.Speaker] && strings.TrimSpace(seg.Text) != "" {
+			seen[seg.Speaker] = true
+			doc.Participants = append(doc.Participants, speakerLabel(names, seg.Speaker))
+		}
+	}
+	return m.renderer.Render(summaryText, doc)
+}
+
+func (m *Manager) renderSummaryAudio(ctx context.Context, sessionID, summaryText string) {
+	if m.speech == nil || strings.TrimSpace(summaryText) == "" {
 		return
 	}
 
-	m.broadcastSummaryStatus(sessionID, summaryText, storage.SummaryCompleted, preset)
+	path := filepath.Join(m.speechDir, sessionID+"-summary"+m.speech.Ext())
+	if err := m.speech.Synthesize(ctx, summaryText, path); err != nil {
+		slog.Warn("summary audio failed", "session", sessionID, "error", err)
+		return
+	}
+	if err := m.store.SetSummaryAudioPath(sessionID, path); err != nil {
+		slog.Warn("storing summary audio path failed", "session", sessionID, "error", err)
+	}
+}
+
+// storeCitations links the bullets of the model's summary to the transcript
+// segments that support them. Bullets a document template adds around the
+// summary aren't cited, but lines are counted in the stored document.
+func (m *Manager) storeCitations(sessionID, document, summaryText string, segments []transcribe.Segment) {
+	body, offset := document, 0
+	if normalized := summary.Normalize(summaryText); normalized != "" {
+		if i := strings.Index(document, normalized); i >= 0 {
+			body, offset = normalized, strings.Count(document[:i], "\n")
+		}
+	}
+	cited := summary.Cite(body, segments)
+	citations := make([]storage.Citation, 0, len(cited))
+	for _, c := range cited {
+		citations = append(citations, storage.Citation{
+			Bullet:         c.Bullet,
+			Line:           offset + c.Line,
+			Text:           c.Text,
+			FirstSegmentID: c.FirstSegmentID,
+			LastSegmentID:  c.LastSegmentID,
+			StartTime:      c.StartTime,
+			EndTime:        c.EndTime,
+			Score:          c.Score,
+		})
+	}
+	if err := m.store.ReplaceCitations(sessionID, citations); err != nil {
+		slog.Warn("storing summary citations failed", "session", sessionID, "error", err)
+	}
+}
+
+func (m *Manager) extractDecisions(ctx context.Context, sessionID, transcript string) {
+	if m.decisions == nil {
+		return
+	}
+
+	extracted, err := m.decisions.ExtractDecisions(ctx, transcript)
+	if err != nil {
+		slog.Warn("decision extraction failed", "session", sessionID, "error", err)
+		return
+	}
+
+	decisions := make([]storage.Decision, 0, len(extracted))
+	for _, d := range extracted {
+		decisions = append(decisions, storage.Decision{Text: d.Text, Participants: d.Participants})
+	}
+	if err := m.store.ReplaceDecisions(sessionID, decisions); err != nil {
+		slog.Warn("storing decisions failed", "session", sessionID, "error", err)
+	}
+}
+
+// recordTranscriptionModel notes on a new session what it is transcribed
+// with.
+func (m *Manager) recordTranscriptionModel(sessionID string) {
+	if m.transcriptionModel == "" && m.transcriptionLanguage == "" {
+		return
+	}
+	if err := m.store.SetTranscriptionModel(sessionID, m.transcriptionModel, m.transcriptionLanguage); err != nil {
+		slog.Warn("recording transcription model failed", "session", sessionID, "error", err)
+	}
+}
+
+func (m *Manager) recordLLMUsage(sessionID string, usage *llm.UsageRecorder, latency time.Duration) {
+	var cost float64
+	for model, tokens := range usage.ByModel() {
+		cost += m.modelPricing[model].Cost(tokens.InputTokens, tokens.OutputTokens)
+	}
+	total := usage.Total()
+	if err := m.store.AddLLMUsage(sessionID, total.InputTokens, total.OutputTokens, cost, latency); err != nil {
+		slog.Warn("recording llm usage failed", "session", sessionID, "error", err)
+	}
+}
+
+func buildTranscript(segments []transcribe.Segment) string {
+	var b strings.Builder
+	for _, segment := range segments {
+		if strings.TrimSpace(segment.Text) == "" {
+			continue
+		}
+		b.WriteString(segment.Text)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// segmentLanguages returns each language's share of the words in segments
+// whose language was detected during transcription, most spoken first, or
+// nil when none was.
+func segmentLanguages(segments []transcribe.Segment) []summary.LanguageShare {
+	counts := map[string]int{}
+	total := 0
+	for _, segment := range segments {
+		if segment.Language == "" {
+			continue
+		}
+		n := len(strings.Fields(segment.Text))
+		counts[segment.Language] += n
+		total += n
+	}
+	if total == 0 {
+		return nil
+	}
+	shares := make([]summary.LanguageShare, 0, len(counts))
+	for language, n := range counts {
+		shares = append(shares, summary.LanguageShare{Language: language, Share: float64(n) / float64(total)})
+	}
+	slices.SortFunc(shares, func(a, b summary.LanguageShare) int {
+		return cmp.Or(cmp.Compare(b.Share, a.Share), cmp.Compare(a.Language, b.Language))
+	})
+	return shares
 }
 
 func (m *Manager) broadcastSummaryStatus(sessionID, summary, status, preset string) {
@@ -322,3 +945,8 @@ func (m *Manager) currentSession() string {
 	defer m.mu.Unlock()
 	return m.currentSessionID
 }
+
+// CurrentSessionID returns the active session, or "" between sessions.
+func (m *Manager) CurrentSessionID() string {
+	return m.currentSession()
+}
@@ -2,8 +2,13 @@ package session
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 	"sync"
 	"testing"
@@ -11,7 +16,12 @@ import (
 
 	api "github.com/deepgram/deepgram-go-sdk/v3/pkg/api/listen/v1/websocket/interfaces"
 
+	"github.com/sjawhar/ghost-wispr/internal/config"
+	"github.com/sjawhar/ghost-wispr/internal/jobs"
+	"github.com/sjawhar/ghost-wispr/internal/llm"
+	"github.com/sjawhar/ghost-wispr/internal/redact"
 	"github.com/sjawhar/ghost-wispr/internal/storage"
+	"github.com/sjawhar/ghost-wispr/internal/summary"
 	"github.com/sjawhar/ghost-wispr/internal/transcribe"
 )
 
@@ -24,6 +34,22 @@ type storeMock struct {
 	preset   map[string]string
 	audio    map[string]string
 
+	decisions    map[string][]storage.Decision
+	usage        map[string]storage.SessionUsage
+	summaryAudio map[string]string
+	kind         map[string]string
+	citations    map[string][]storage.Citation
+	audioStatus  map[string]string
+	models       map[string]string
+	providers    map[string][]string
+	summaryModel map[string]string
+	keepWAV      map[string]bool
+	channels     map[string]bool
+	audioOffset  map[string]float64
+	clockBoot    map[string]time.Duration
+	wavPath      map[string]string
+	audioKind    map[string]string
+
 	endSessionErr   error
 	endSessionCalls int
 }
@@ -36,6 +62,22 @@ func newStoreMock() *storeMock {
 		status:   map[string]string{},
 		preset:   map[string]string{},
 		audio:    map[string]string{},
+
+		decisions:    map[string][]storage.Decision{},
+		usage:        map[string]storage.SessionUsage{},
+		summaryAudio: map[string]string{},
+		kind:         map[string]string{},
+		citations:    map[string][]storage.Citation{},
+		audioStatus:  map[string]string{},
+		models:       map[string]string{},
+		providers:    map[string][]string{},
+		summaryModel: map[string]string{},
+		keepWAV:      map[string]bool{},
+		channels:     map[string]bool{},
+		audioOffset:  map[string]float64{},
+		clockBoot:    map[string]time.Duration{},
+		wavPath:      map[string]string{},
+		audioKind:    map[string]string{},
 	}
 }
 
@@ -47,6 +89,16 @@ func (s *storeMock) CreateSession(id string, startedAt time.Time) error {
 	return nil
 }
 
+func (s *storeMock) GetSession(id string) (storage.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	startedAt, ok := s.sessions[id]
+	if !ok {
+		return storage.Session{}, sql.ErrNoRows
+	}
+	return storage.Session{ID: id, StartedAt: startedAt, Status: s.status[id], AudioPath: s.audio[id], Kind: s.kind[id], KeepWAV: s.keepWAV[id], WAVPath: s.wavPath[id], AudioOffset: s.audioOffset[id]}, nil
+}
+
 func (s *storeMock) EndSession(id string, _ time.Time, audioPath string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -59,6 +111,39 @@ func (s *storeMock) EndSession(id string, _ time.Time, audioPath string) error {
 	return nil
 }
 
+func (s *storeMock) AddAudioFile(sessionID, kind, path string) (storage.AudioFile, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.sessions[sessionID]; !ok {
+		return storage.AudioFile{}, sql.ErrNoRows
+	}
+	s.audio[sessionID] = path
+	s.audioKind[sessionID] = kind
+	return storage.AudioFile{Kind: kind, Path: path}, nil
+}
+
+func (s *storeMock) SetAudioStatus(id, status, audioPath string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.sessions[id]; !ok {
+		return sql.ErrNoRows
+	}
+	s.audioStatus[id] = status
+	s.audio[id] = audioPath
+	return nil
+}
+
+func (s *storeMock) MarkSessionRecovered(id string, _ time.Time, audioPath string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.sessions[id]; !ok {
+		return sql.ErrNoRows
+	}
+	s.status[id] = "recovered"
+	s.audio[id] = audioPath
+	return nil
+}
+
 func (s *storeMock) AppendSegment(sessionID string, seg transcribe.Segment) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -82,6 +167,127 @@ func (s *storeMock) UpdateSummary(sessionID, summary, status, preset string) err
 	return nil
 }
 
+func (s *storeMock) GetSessionsBySummaryStatus(status string) ([]storage.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var sessions []storage.Session
+	for id, st := range s.status {
+		if st == status {
+			sessions = append(sessions, storage.Session{ID: id, StartedAt: s.sessions[id], SummaryStatus: st, SummaryPreset: s.preset[id]})
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].StartedAt.Before(sessions[j].StartedAt) })
+	return sessions, nil
+}
+
+func (s *storeMock) ReplaceDecisions(sessionID string, decisions []storage.Decision) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.decisions[sessionID] = decisions
+	return nil
+}
+
+func (s *storeMock) ReplaceCitations(sessionID string, citations []storage.Citation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.citations[sessionID] = citations
+	return nil
+}
+
+func (s *storeMock) RecordTranscriptionUsage(sessionID string, duration time.Duration, cost float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u := s.usage[sessionID]
+	u.TranscriptionMinutes = duration.Minutes()
+	u.TranscriptionCost = cost
+	s.usage[sessionID] = u
+	return nil
+}
+
+func (s *storeMock) AddLLMUsage(sessionID string, inputTokens, outputTokens int, cost float64, latency time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u := s.usage[sessionID]
+	u.LLMInputTokens += inputTokens
+	u.LLMOutputTokens += outputTokens
+	u.LLMCost += cost
+	u.SummaryLatencyMs = latency.Milliseconds()
+	s.usage[sessionID] = u
+	return nil
+}
+
+func (s *storeMock) SetSummaryAudioPath(sessionID, path string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.summaryAudio[sessionID] = path
+	return nil
+}
+
+func (s *storeMock) SetTranscriptionModel(sessionID, model, language string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.models[sessionID] = model + " " + language
+	return nil
+}
+
+func (s *storeMock) AddTranscriptionProvider(sessionID, provider string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !slices.Contains(s.providers[sessionID], provider) {
+		s.providers[sessionID] = append(s.providers[sessionID], provider)
+	}
+	return nil
+}
+
+func (s *storeMock) SetSummaryModel(sessionID, model string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.summaryModel[sessionID] = model
+	return nil
+}
+
+func (s *storeMock) SetKeepWAV(sessionID string, keep bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keepWAV[sessionID] = keep
+	return nil
+}
+
+func (s *storeMock) SetChannelSpeakers(sessionID string, channels bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.channels[sessionID] = channels
+	return nil
+}
+
+func (s *storeMock) SetAudioOffset(sessionID string, offset float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.audioOffset[sessionID] = offset
+	return nil
+}
+
+func (s *storeMock) SetSessionClock(sessionID, _ string, boot time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clockBoot[sessionID] = boot
+	return nil
+}
+
+func (s *storeMock) SetWAVPath(sessionID, path string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.wavPath[sessionID] = path
+	return nil
+}
+
+func (s *storeMock) SetSessionKind(sessionID, kind string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.kind[sessionID] = kind
+	return nil
+}
+
 type recorderMock struct {
 	mu      sync.Mutex
 	started []string
@@ -90,6 +296,17 @@ type recorderMock struct {
 	startErr error
 }
 
+// alignedRecorderMock is a recorder whose recordings start audioStart
+// seconds into a capture timeline that has reached captured.
+type alignedRecorderMock struct {
+	recorderMock
+	audioStart, captured float64
+}
+
+func (r *alignedRecorderMock) AudioStart() float64 { return r.audioStart }
+
+func (r *alignedRecorderMock) Captured() float64 { return r.captured }
+
 func (r *recorderMock) StartSession(id string) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -121,6 +338,21 @@ func (s summarizerMock) Summarize(_ context.Context, sessionID, transcript strin
 	return "## Summary\n- " + transcript, "default", nil
 }
 
+func (s summarizerMock) SummarizeWithPreset(_ context.Context, sessionID, transcript, preset string) (string, error) {
+	if s.called != nil {
+		s.called <- sessionID
+	}
+	return "## " + preset + "\n- " + transcript, nil
+}
+
+type decisionExtractorMock struct {
+	decisions []summary.Decision
+}
+
+func (d decisionExtractorMock) ExtractDecisions(context.Context, string) ([]summary.Decision, error) {
+	return d.decisions, nil
+}
+
 type contextProbeSummarizer struct {
 	delay  time.Duration
 	stateC chan error
@@ -142,6 +374,11 @@ func (s contextProbeSummarizer) Summarize(ctx context.Context, _ string, transcr
 	}
 }
 
+func (s contextProbeSummarizer) SummarizeWithPreset(ctx context.Context, sessionID, transcript, _ string) (string, error) {
+	summaryText, _, err := s.Summarize(ctx, sessionID, transcript)
+	return summaryText, err
+}
+
 type hubMock struct {
 	mu            sync.Mutex
 	liveCount     int
@@ -153,6 +390,8 @@ type hubMock struct {
 	latestStatus  string
 	latestPreset  string
 	interimCount  int
+	latestInterim string
+	audioReady    []string
 }
 
 func (h *hubMock) BroadcastLiveTranscript(_ transcribe.Segment) {
@@ -161,9 +400,10 @@ func (h *hubMock) BroadcastLiveTranscript(_ transcribe.Segment) {
 	h.mu.Unlock()
 }
 
-func (h *hubMock) BroadcastLiveTranscriptInterim(_ int, _ string, _ float64) {
+func (h *hubMock) BroadcastLiveTranscriptInterim(_ int, text string, _ float64) {
 	h.mu.Lock()
 	h.interimCount++
+	h.latestInterim = text
 	h.mu.Unlock()
 }
 
@@ -191,6 +431,12 @@ func (h *hubMock) BroadcastSummaryReady(sessionID, summary, status, preset strin
 	h.mu.Unlock()
 }
 
+func (h *hubMock) BroadcastAudioReady(sessionID, status, _ string) {
+	h.mu.Lock()
+	h.audioReady = append(h.audioReady, sessionID+" "+status)
+	h.mu.Unlock()
+}
+
 func TestManagerLifecycle(t *testing.T) {
 	store := newStoreMock()
 	recorder := &recorderMock{}
@@ -456,6 +702,232 @@ func TestManager_BuffersUntilSpeechFinal(t *testing.T) {
 	}
 }
 
+func TestManager_MultichannelSpeakers(t *testing.T) {
+	store := newStoreMock()
+	hub := &hubMock{}
+	manager := NewManager(store, nil, nil, hub, NewDetector(time.Hour))
+
+	for _, raw := range []string{
+		`{"is_final": true, "channel_index": [0, 2], "channel": {"alternatives": [{
+			"transcript": "can you hear me",
+			"words": [{"speaker": 1, "punctuated_word": "can", "start": 0, "end": 0.2},
+			           {"speaker": 1, "punctuated_word": "you", "start": 0.2, "end": 0.4},
+			           {"speaker": 1, "punctuated_word": "hear", "start": 0.4, "end": 0.6},
+			           {"speaker": 1, "punctuated_word": "me", "start": 0.6, "end": 0.8}]
+		}]}}`,
+		`{"is_final": true, "speech_final": true, "channel_index": [1, 2], "channel": {"alternatives": [{
+			"transcript": "yes loud and clear",
+			"words": [{"speaker": 0, "punctuated_word": "yes", "start": 1.0, "end": 1.2},
+			           {"speaker": 1, "punctuated_word": "loud", "start": 1.2, "end": 1.4}]
+		}]}}`,
+	} {
+		if err := manager.Message(buildMsg(t, raw)); err != nil {
+			t.Fatalf("Message failed: %v", err)
+		}
+	}
+
+	segs := store.segments[hub.latestSession]
+	var speakers []int
+	for _, seg := range segs {
+		speakers = append(speakers, seg.Speaker)
+	}
+	if !slices.Equal(speakers, []int{0, 1, 2}) {
+		t.Fatalf("expected the mic as speaker 0 and loopback speakers from 1, got %v", speakers)
+	}
+}
+
+func TestManager_ChannelPerMicSpeakers(t *testing.T) {
+	store := newStoreMock()
+	hub := &hubMock{}
+	manager := NewManager(store, nil, nil, hub, NewDetector(time.Hour))
+
+	for i, raw := range []string{
+		`{"is_final": true, "channel_index": [0, 3], "channel": {"alternatives": [{"transcript": "morning",
+			"words": [{"speaker": 0, "punctuated_word": "morning", "start": 0, "end": 0.4}]}]}}`,
+		`{"is_final": true, "channel_index": [2, 3], "channel": {"alternatives": [{"transcript": "hi",
+			"words": [{"speaker": 1, "punctuated_word": "hi", "start": 0.5, "end": 0.7}]}]}}`,
+		`{"is_final": true, "speech_final": true, "channel_index": [1, 3], "channel": {"alternatives": [{"transcript": "hello",
+			"words": [{"speaker": 1, "punctuated_word": "hello", "start": 0.8, "end": 1.0}]}]}}`,
+	} {
+		if err := manager.Message(buildMsg(t, raw)); err != nil {
+			t.Fatalf("message %d failed: %v", i, err)
+		}
+	}
+
+	var speakers []int
+	for _, seg := range store.segments[hub.latestSession] {
+		speakers = append(speakers, seg.Speaker)
+	}
+	if !slices.Equal(speakers, []int{0, 2, 1}) {
+		t.Fatalf("expected each microphone's channel as its speaker, got %v", speakers)
+	}
+}
+
+type processorFunc func(seg transcribe.Segment) (transcribe.Segment, bool, error)
+
+func (f processorFunc) ProcessSegment(_ context.Context, seg transcribe.Segment) (transcribe.Segment, bool, error) {
+	return f(seg)
+}
+
+func TestManager_SegmentProcessor(t *testing.T) {
+	store := newStoreMock()
+	hub := &hubMock{}
+	processor := processorFunc(func(seg transcribe.Segment) (transcribe.Segment, bool, error) {
+		switch seg.Speaker {
+		case 0:
+			seg.Text = strings.ToUpper(seg.Text)
+			return seg, true, nil
+		case 1:
+			return seg, false, nil
+		default:
+			return transcribe.Segment{}, false, errors.New("script crashed")
+		}
+	})
+	manager := NewManager(store, nil, nil, hub, NewDetector(time.Hour), WithSegmentProcessor(processor))
+
+	msg := buildMsg(t, `{
+		"is_final": true,
+		"speech_final": true,
+		"channel": {"alternatives": [{
+			"transcript": "hello um bye",
+			"words": [{"speaker": 0, "punctuated_word": "hello", "start": 0, "end": 0.5},
+			           {"speaker": 1, "punctuated_word": "um", "start": 0.5, "end": 0.8},
+			           {"speaker": 2, "punctuated_word": "bye", "start": 0.8, "end": 1.0}]
+		}]}}`)
+	if err := manager.Message(msg); err != nil {
+		t.Fatalf("Message failed: %v", err)
+	}
+	manager.segments.drain(context.Background())
+
+	segs := store.segments[hub.latestSession]
+	if len(segs) != 2 {
+		t.Fatalf("expected dropped segment to be skipped, got %+v", segs)
+	}
+	if segs[0].Text != "HELLO" {
+		t.Fatalf("expected rewritten text, got %q", segs[0].Text)
+	}
+	if segs[1].Text != "bye" {
+		t.Fatalf("expected failed processing to keep the segment, got %q", segs[1].Text)
+	}
+}
+
+func TestManager_SegmentProcessorRunsOffCallback(t *testing.T) {
+	store := newStoreMock()
+	hub := &hubMock{}
+	release := make(chan struct{})
+	processor := processorFunc(func(seg transcribe.Segment) (transcribe.Segment, bool, error) {
+		<-release
+		return seg, true, nil
+	})
+	manager := NewManager(store, nil, nil, hub, NewDetector(time.Hour), WithSegmentProcessor(processor))
+
+	for i, text := range []string{"first", "second"} {
+		msg := buildMsg(t, fmt.Sprintf(`{
+			"is_final": true,
+			"speech_final": true,
+			"channel": {"alternatives": [{
+				"transcript": %[1]q,
+				"words": [{"speaker": 0, "punctuated_word": %[1]q, "start": %[2]d, "end": %[2]d.5}]
+			}]}}`, text, i))
+		done := make(chan error, 1)
+		go func() { done <- manager.Message(msg) }()
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Fatalf("Message failed: %v", err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("expected the transcription callback not to wait for the processor")
+		}
+	}
+
+	close(release)
+	if err := manager.ForceEndSession(context.Background()); err != nil {
+		t.Fatalf("end session: %v", err)
+	}
+	segs := store.segments[hub.latestSession]
+	if len(segs) != 2 || segs[0].Text != "first" || segs[1].Text != "second" {
+		t.Fatalf("expected both segments stored in order before the session ended, got %+v", segs)
+	}
+}
+
+func TestManager_RedactsBeforeProcessingAndStoring(t *testing.T) {
+	store := newStoreMock()
+	hub := &hubMock{}
+	var processed []string
+	processor := processorFunc(func(seg transcribe.Segment) (transcribe.Segment, bool, error) {
+		processed = append(processed, seg.Text)
+		return seg, true, nil
+	})
+	manager := NewManager(store, nil, nil, hub, NewDetector(time.Hour),
+		WithSegmentProcessor(processor), WithRedaction(redact.New([]string{redact.Email})))
+
+	interim := buildMsg(t, `{
+		"channel": {"alternatives": [{
+			"transcript": "mail ops@example.com",
+			"words": [{"speaker": 0, "punctuated_word": "mail", "start": 0, "end": 0.2},
+			           {"speaker": 0, "punctuated_word": "ops@example.com", "start": 0.2, "end": 0.8}]
+		}]}}`)
+	if err := manager.Message(interim); err != nil {
+		t.Fatalf("Message failed: %v", err)
+	}
+	if hub.latestInterim != "mail [EMAIL]" {
+		t.Fatalf("expected the interim transcript redacted, got %q", hub.latestInterim)
+	}
+
+	final := buildMsg(t, `{
+		"is_final": true,
+		"speech_final": true,
+		"channel": {"alternatives": [{
+			"transcript": "mail ops@example.com.",
+			"words": [{"speaker": 0, "punctuated_word": "mail", "start": 0, "end": 0.2},
+			           {"speaker": 0, "punctuated_word": "ops@example.com.", "start": 0.2, "end": 0.8}]
+		}]}}`)
+	if err := manager.Message(final); err != nil {
+		t.Fatalf("Message failed: %v", err)
+	}
+	manager.segments.drain(context.Background())
+	segs := store.segments[hub.latestSession]
+	if len(segs) != 1 || segs[0].Text != "mail [EMAIL]." || !slices.Equal(processed, []string{"mail [EMAIL]."}) {
+		t.Fatalf("expected the segment redacted before processing and storage, got %+v, processed %q", segs, processed)
+	}
+	if words := segs[0].Words; len(words) != 2 || words[1].Word != "[EMAIL]." || words[1].Start != 0.2 {
+		t.Fatalf("expected the word timings kept and redacted, got %+v", words)
+	}
+}
+
+type dictatorFunc func(text string)
+
+func (f dictatorFunc) Dictate(text string) { f(text) }
+
+func TestManager_DictatesProcessedSegments(t *testing.T) {
+	var dictated []string
+	processor := processorFunc(func(seg transcribe.Segment) (transcribe.Segment, bool, error) {
+		return seg, seg.Speaker == 0, nil
+	})
+	manager := NewManager(newStoreMock(), nil, nil, nil, NewDetector(time.Hour),
+		WithSegmentProcessor(processor),
+		WithDictation(dictatorFunc(func(text string) { dictated = append(dictated, text) })))
+
+	msg := buildMsg(t, `{
+		"is_final": true,
+		"speech_final": true,
+		"channel": {"alternatives": [{
+			"transcript": "take a note um",
+			"words": [{"speaker": 0, "punctuated_word": "take", "start": 0, "end": 0.2},
+			           {"speaker": 0, "punctuated_word": "a", "start": 0.2, "end": 0.3},
+			           {"speaker": 0, "punctuated_word": "note.", "start": 0.3, "end": 0.6},
+			           {"speaker": 1, "punctuated_word": "um", "start": 0.6, "end": 0.8}]
+		}]}}`)
+	if err := manager.Message(msg); err != nil {
+		t.Fatalf("Message failed: %v", err)
+	}
+	manager.segments.drain(context.Background())
+	if len(dictated) != 1 || dictated[0] != "take a note." {
+		t.Fatalf("expected only the kept segment dictated, got %q", dictated)
+	}
+}
+
 func TestManager_InterimBroadcast(t *testing.T) {
 	store := newStoreMock()
 	hub := &hubMock{}
@@ -556,3 +1028,404 @@ func TestManager_ForceEndFlushesBuffer(t *testing.T) {
 		t.Fatal("expected buffered words to be flushed by ForceEndSession")
 	}
 }
+
+func TestManager_ResummarizeWithPresetStoresDecisions(t *testing.T) {
+	store := newStoreMock()
+	hub := &hubMock{}
+	extractor := decisionExtractorMock{decisions: []summary.Decision{{Text: "Ship it", Participants: []string{"Alice"}}}}
+	manager := NewManager(store, nil, summarizerMock{}, hub, NewDetector(time.Hour), WithDecisionExtractor(extractor))
+
+	if err := store.AppendSegment("s1", transcribe.Segment{ID: 7, Text: "we will ship it"}); err != nil {
+		t.Fatalf("AppendSegment failed: %v", err)
+	}
+
+	if err := manager.Resummarize(context.Background(), "s1", "detailed"); err != nil {
+		t.Fatalf("Resummarize failed: %v", err)
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if store.preset["s1"] != "detailed" {
+		t.Fatalf("expected preset detailed, got %q", store.preset["s1"])
+	}
+	if !strings.Contains(store.summary["s1"], "we will ship it") {
+		t.Fatalf("expected transcript in summary, got %q", store.summary["s1"])
+	}
+	if got := store.decisions["s1"]; len(got) != 1 || got[0].Text != "Ship it" || got[0].Participants[0] != "Alice" {
+		t.Fatalf("expected stored decision, got %#v", got)
+	}
+	if got := store.citations["s1"]; len(got) != 1 || got[0].FirstSegmentID != 7 || got[0].Text != "we will ship it" {
+		t.Fatalf("expected the summary bullet cited to the segment, got %#v", got)
+	}
+
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+	if hub.summaryReady != 2 || hub.latestStatus != storage.SummaryCompleted {
+		t.Fatalf("expected running then completed broadcasts, got %d ending in %q", hub.summaryReady, hub.latestStatus)
+	}
+}
+
+type speechMock struct {
+	texts []string
+}
+
+func (s *speechMock) Ext() string { return ".mp3" }
+
+func (s *speechMock) Synthesize(_ context.Context, text, _ string) error {
+	s.texts = append(s.texts, text)
+	return nil
+}
+
+func TestManager_RendersSummaryAudio(t *testing.T) {
+	store := newStoreMock()
+	speech := &speechMock{}
+	manager := NewManager(store, nil, summarizerMock{}, nil, NewDetector(time.Hour), WithSummaryAudio(speech, "data/audio"))
+
+	if err := store.AppendSegment("s1", transcribe.Segment{Text: "we will ship it"}); err != nil {
+		t.Fatalf("AppendSegment failed: %v", err)
+	}
+	if err := manager.Resummarize(context.Background(), "s1", "detailed"); err != nil {
+		t.Fatalf("Resummarize failed: %v", err)
+	}
+
+	if len(speech.texts) != 1 || !strings.Contains(speech.texts[0], "we will ship it") {
+		t.Fatalf("expected summary to be synthesized, got %q", speech.texts)
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if got := store.summaryAudio["s1"]; got != filepath.Join("data/audio", "s1-summary.mp3") {
+		t.Fatalf("unexpected summary audio path %q", got)
+	}
+}
+
+type exporterMock struct {
+	mu       sync.Mutex
+	sessions []string
+}
+
+func (e *exporterMock) SummaryReady(sessionID string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.sessions = append(e.sessions, sessionID)
+}
+
+func TestManager_ExportsStoredSummaries(t *testing.T) {
+	store := newStoreMock()
+	exporter := &exporterMock{}
+	manager := NewManager(store, nil, summarizerMock{}, nil, NewDetector(time.Hour), WithExports(exporter))
+
+	if err := store.AppendSegment("s1", transcribe.Segment{Text: "we will ship it"}); err != nil {
+		t.Fatalf("AppendSegment failed: %v", err)
+	}
+	if err := manager.Resummarize(context.Background(), "s1", "detailed"); err != nil {
+		t.Fatalf("Resummarize failed: %v", err)
+	}
+
+	exporter.mu.Lock()
+	defer exporter.mu.Unlock()
+	if len(exporter.sessions) != 1 || exporter.sessions[0] != "s1" {
+		t.Fatalf("expected s1 handed to the exporter once its summary was stored, got %v", exporter.sessions)
+	}
+}
+
+func TestManager_SummaryPoolQueuesSummaries(t *testing.T) {
+	store := newStoreMock()
+	pool := jobs.NewPool("summaries", 1)
+	manager := NewManager(store, nil, summarizerMock{}, nil, NewDetector(time.Hour), WithSummaryPool(pool))
+	if err := store.AppendSegment("s1", transcribe.Segment{Text: "queued work"}); err != nil {
+		t.Fatalf("AppendSegment failed: %v", err)
+	}
+
+	release, err := pool.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- manager.Resummarize(context.Background(), "s1", "detailed") }()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for pool.Stats().Queued != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("expected summary to wait for a worker")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	select {
+	case <-done:
+		t.Fatal("summary ran without a free worker")
+	default:
+	}
+
+	release()
+	if err := <-done; err != nil {
+		t.Fatalf("Resummarize failed: %v", err)
+	}
+	if got := pool.Stats().Completed; got != 2 {
+		t.Fatalf("expected 2 completed jobs, got %d", got)
+	}
+}
+
+type rendererFunc func(summary string, doc summary.Document) string
+
+func (f rendererFunc) Render(summary string, doc summary.Document) string { return f(summary, doc) }
+
+func TestManager_ResummarizeRendersSummaryDocument(t *testing.T) {
+	store := newStoreMock()
+	started := time.Date(2026, 3, 20, 9, 0, 0, 0, time.UTC)
+	if err := store.CreateSession("s1", started); err != nil {
+		t.Fatal(err)
+	}
+	for _, seg := range []transcribe.Segment{{Speaker: 1, Text: "hello"}, {Speaker: 0, Text: "hi"}, {Speaker: 1, Text: "bye"}} {
+		if err := store.AppendSegment("s1", seg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var got summary.Document
+	renderer := rendererFunc(func(text string, doc summary.Document) string {
+		got = doc
+		return "# Header\n\n" + text
+	})
+	manager := NewManager(store, nil, summarizerMock{}, nil, NewDetector(time.Hour), WithSummaryRenderer(renderer))
+	if err := manager.Resummarize(context.Background(), "s1", "detailed"); err != nil {
+		t.Fatalf("Resummarize failed: %v", err)
+	}
+
+	if got.SessionID != "s1" || got.Preset != "detailed" || !got.StartedAt.Equal(started) {
+		t.Fatalf("unexpected document %+v", got)
+	}
+	if len(got.Participants) != 2 || got.Participants[0] != "Speaker 1" || got.Participants[1] != "Speaker 0" {
+		t.Fatalf("expected participants in speaking order, got %v", got.Participants)
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if !strings.HasPrefix(store.summary["s1"], "# Header\n\n## detailed") {
+		t.Fatalf("expected the rendered summary stored, got %q", store.summary["s1"])
+	}
+}
+
+func TestManager_ResummarizeWithSpeakerNames(t *testing.T) {
+	store := newStoreMock()
+	if err := store.CreateSession("s1", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	for _, seg := range []transcribe.Segment{{Speaker: 0, Text: "hello"}, {Speaker: 2, Text: "hi"}} {
+		if err := store.AppendSegment("s1", seg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var got summary.Document
+	renderer := rendererFunc(func(text string, doc summary.Document) string {
+		got = doc
+		return text
+	})
+	manager := NewManager(store, nil, summarizerMock{}, nil, NewDetector(time.Hour), WithSummaryRenderer(renderer))
+	ctx := WithSpeakerNames(context.Background(), map[int]string{0: "Alice"})
+	if err := manager.Resummarize(ctx, "s1", "default"); err != nil {
+		t.Fatalf("Resummarize failed: %v", err)
+	}
+
+	if len(got.Participants) != 2 || got.Participants[0] != "Alice" || got.Participants[1] != "Speaker 2" {
+		t.Fatalf("expected named participants, got %v", got.Participants)
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if want := "## default\n- Alice: hello\nSpeaker 2: hi\n"; store.summary["s1"] != want {
+		t.Fatalf("expected a transcript labelled by speaker, got %q", store.summary["s1"])
+	}
+}
+
+type speakerNamesStub map[int]string
+
+func (s speakerNamesStub) GetSpeakerNames(string) (map[int]string, error) {
+	names := make(map[int]string, len(s))
+	for speaker, name := range s {
+		names[speaker] = name
+	}
+	return names, nil
+}
+
+func TestManager_ResummarizeWithStoredSpeakerNames(t *testing.T) {
+	store := newStoreMock()
+	if err := store.CreateSession("s1", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	for _, seg := range []transcribe.Segment{{Speaker: 0, Text: "hello"}, {Speaker: 2, Text: "hi"}} {
+		if err := store.AppendSegment("s1", seg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	manager := NewManager(store, nil, summarizerMock{}, nil, NewDetector(time.Hour),
+		WithStoredSpeakerNames(speakerNamesStub{0: "Alice", 2: "Bob"}))
+	if err := manager.Resummarize(context.Background(), "s1", "default"); err != nil {
+		t.Fatalf("Resummarize failed: %v", err)
+	}
+	store.mu.Lock()
+	if want := "## default\n- Alice: hello\nBob: hi\n"; store.summary["s1"] != want {
+		t.Fatalf("expected the stored names in the transcript, got %q", store.summary["s1"])
+	}
+	store.mu.Unlock()
+
+	ctx := WithSpeakerNames(context.Background(), map[int]string{2: "Carol"})
+	if err := manager.Resummarize(ctx, "s1", "default"); err != nil {
+		t.Fatalf("Resummarize failed: %v", err)
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if want := "## default\n- Alice: hello\nCarol: hi\n"; store.summary["s1"] != want {
+		t.Fatalf("expected names given with the request to win, got %q", store.summary["s1"])
+	}
+}
+
+func TestManager_ResummarizeWithoutSummarizer(t *testing.T) {
+	manager := NewManager(newStoreMock(), nil, nil, nil, NewDetector(time.Hour))
+
+	if err := manager.Resummarize(context.Background(), "s1", ""); !errors.Is(err, ErrSummarizationUnavailable) {
+		t.Fatalf("expected ErrSummarizationUnavailable, got %v", err)
+	}
+}
+
+func TestManager_EstimateSummary(t *testing.T) {
+	store := newStoreMock()
+	store.sessions["s1"] = time.Now().UTC()
+	store.segments["s1"] = []transcribe.Segment{{Text: strings.Repeat("budget review ", 20)}}
+	cfg := config.Summarization{
+		Model:   "openai/gpt-4o-mini",
+		Presets: map[string]config.Preset{"default": {UserTemplate: "{{transcript}}"}},
+	}
+	manager := NewManager(store, nil, summary.New(cfg, nil), nil, NewDetector(time.Hour))
+
+	preset, estimates, err := manager.EstimateSummary(context.Background(), "s1", "")
+	if err != nil {
+		t.Fatalf("EstimateSummary failed: %v", err)
+	}
+	if preset != "default" || len(estimates) != 1 || estimates[0].InputTokens == 0 {
+		t.Fatalf("expected an estimate of the stored transcript, got %q %+v", preset, estimates)
+	}
+	if _, _, err := manager.EstimateSummary(context.Background(), "missing", ""); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows for a missing session, got %v", err)
+	}
+
+	manager = NewManager(store, nil, summarizerMock{}, nil, NewDetector(time.Hour))
+	if _, _, err := manager.EstimateSummary(context.Background(), "s1", ""); !errors.Is(err, ErrSummarizationUnavailable) {
+		t.Fatalf("expected ErrSummarizationUnavailable without an estimator, got %v", err)
+	}
+}
+
+func TestManager_RecordsTranscriptionModel(t *testing.T) {
+	store := newStoreMock()
+	manager := NewManager(store, nil, nil, nil, NewDetector(time.Hour), WithTranscriptionModel("nova-2", "en-US"))
+
+	if err := manager.ensureSessionStarted(time.Now()); err != nil {
+		t.Fatalf("ensureSessionStarted failed: %v", err)
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if got := store.models[manager.currentSession()]; got != "nova-2 en-US" {
+		t.Fatalf("expected the session's transcription model recorded, got %q", got)
+	}
+}
+
+func TestManager_RecordsTranscriptionProviders(t *testing.T) {
+	store := newStoreMock()
+	manager := NewManager(store, nil, nil, nil, NewDetector(time.Hour), WithTranscriptionModel("nova-2", "en-US"))
+	manager.SetTranscriptionProvider("deepgram", "nova-2")
+
+	if err := manager.ensureSessionStarted(time.Now()); err != nil {
+		t.Fatalf("ensureSessionStarted failed: %v", err)
+	}
+	first := manager.currentSession()
+	manager.SetTranscriptionProvider("whisper", "whisper-1")
+	if err := manager.endCurrentSession(context.Background()); err != nil {
+		t.Fatalf("endCurrentSession failed: %v", err)
+	}
+	if err := manager.ensureSessionStarted(time.Now().Add(time.Minute)); err != nil {
+		t.Fatalf("ensureSessionStarted failed: %v", err)
+	}
+	second := manager.currentSession()
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if got := store.providers[first]; !slices.Equal(got, []string{"deepgram", "whisper"}) {
+		t.Fatalf("expected the failover recorded on the session in progress, got %q", got)
+	}
+	if got := store.providers[second]; !slices.Equal(got, []string{"whisper"}) || store.models[second] != "whisper-1 en-US" {
+		t.Fatalf("expected the next session on the new provider, got %q with %q", got, store.models[second])
+	}
+}
+
+func TestManager_RecordsChannelSpeakers(t *testing.T) {
+	store := newStoreMock()
+	manager := NewManager(store, nil, nil, nil, NewDetector(time.Hour))
+	manager.SetChannelSpeakers(true)
+
+	if err := manager.ensureSessionStarted(time.Now()); err != nil {
+		t.Fatalf("ensureSessionStarted failed: %v", err)
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if !store.channels[manager.currentSession()] {
+		t.Fatal("expected the session's speakers recorded as following channels")
+	}
+}
+
+func TestManager_RecordsSessionUsage(t *testing.T) {
+	store := newStoreMock()
+	manager := NewManager(store, nil, nil, nil, NewDetector(time.Hour),
+		WithPricing(map[string]config.ModelPricing{"openai/gpt-4o-mini": {InputPerMillion: 1, OutputPerMillion: 2}}, 0.01))
+
+	started := time.Now().UTC().Add(-2 * time.Minute)
+	if err := manager.ensureSessionStarted(started); err != nil {
+		t.Fatalf("ensureSessionStarted failed: %v", err)
+	}
+	sessionID := manager.currentSession()
+	if err := manager.endCurrentSession(context.Background()); err != nil {
+		t.Fatalf("endCurrentSession failed: %v", err)
+	}
+
+	store.mu.Lock()
+	usage := store.usage[sessionID]
+	store.mu.Unlock()
+	if usage.TranscriptionMinutes < 1.9 || usage.TranscriptionMinutes > 2.1 {
+		t.Fatalf("expected ~2 transcription minutes, got %v", usage.TranscriptionMinutes)
+	}
+	if usage.TranscriptionCost < 0.019 || usage.TranscriptionCost > 0.021 {
+		t.Fatalf("expected ~0.02 transcription cost, got %v", usage.TranscriptionCost)
+	}
+
+	recorder := llm.NewUsageRecorder()
+	recorder.Add("openai/gpt-4o-mini", 1_000_000, 500_000)
+	recorder.Add("unpriced/model", 10, 10)
+	manager.recordLLMUsage(sessionID, recorder, 1200*time.Millisecond)
+
+	store.mu.Lock()
+	usage = store.usage[sessionID]
+	store.mu.Unlock()
+	if usage.LLMInputTokens != 1_000_010 || usage.LLMOutputTokens != 500_010 {
+		t.Fatalf("unexpected token totals %d/%d", usage.LLMInputTokens, usage.LLMOutputTokens)
+	}
+	if usage.LLMCost != 2 {
+		t.Fatalf("expected llm cost 2, got %v", usage.LLMCost)
+	}
+	if usage.SummaryLatencyMs != 1200 {
+		t.Fatalf("expected latency 1200ms, got %d", usage.SummaryLatencyMs)
+	}
+}
+
+func TestSegmentLanguagesWeighsWords(t *testing.T) {
+	segments := []transcribe.Segment{
+		{Text: "Bonjour à tous, on commence", Language: "fr"},
+		{Text: "Sounds good", Language: "en"},
+		{Text: "undetected words here"},
+	}
+	got := segmentLanguages(segments)
+	want := []summary.LanguageShare{{Language: "fr", Share: 5.0 / 7}, {Language: "en", Share: 2.0 / 7}}
+	if !slices.Equal(got, want) {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+	if segmentLanguages(segments[2:]) != nil {
+		t.Fatal("expected no shares without detected languages")
+	}
+}
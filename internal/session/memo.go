@@ -0,0 +1,69 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+)
+
+// WithMemoPreset summarizes voice memos with preset instead of letting the
+// summarizer pick one.
+func WithMemoPreset(preset string) Option {
+	return func(m *Manager) {
+		m.memoPreset = preset
+	}
+}
+
+// StartMemo ends any session in progress and starts recording a voice memo.
+// A memo is a session of kind storage.SessionKindMemo that ignores the
+// silence timeout: it runs until StopMemo and is then summarized with the
+// memo preset. It returns the memo's session ID.
+func (m *Manager) StartMemo(ctx context.Context) (string, error) {
+	if m.RecordingMemo() {
+		return "", ErrMemoInProgress
+	}
+	if err := m.ForceEndSession(ctx); err != nil && !errors.Is(err, ErrNoActiveSession) {
+		return "", fmt.Errorf("end session before memo: %w", err)
+	}
+
+	m.mu.Lock()
+	m.recordingMemo = true
+	m.mu.Unlock()
+	if err := m.ensureSessionStarted(time.Now().UTC()); err != nil {
+		m.mu.Lock()
+		m.recordingMemo = false
+		m.mu.Unlock()
+		return "", err
+	}
+
+	sessionID := m.currentSession()
+	if err := m.store.SetSessionKind(sessionID, storage.SessionKindMemo); err != nil {
+		return "", fmt.Errorf("mark session %s as memo: %w", sessionID, err)
+	}
+	return sessionID, nil
+}
+
+// StopMemo ends the voice memo being recorded and returns its session ID.
+func (m *Manager) StopMemo(ctx context.Context) (string, error) {
+	m.mu.Lock()
+	sessionID := m.currentSessionID
+	recording := m.recordingMemo
+	m.mu.Unlock()
+	if !recording {
+		return "", ErrNoMemoInProgress
+	}
+	if err := m.ForceEndSession(ctx); err != nil {
+		return "", err
+	}
+	return sessionID, nil
+}
+
+// RecordingMemo reports whether a voice memo is being recorded.
+func (m *Manager) RecordingMemo() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.recordingMemo
+}
@@ -0,0 +1,87 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+)
+
+func TestManager_VoiceMemo(t *testing.T) {
+	store := newStoreMock()
+	hub := &hubMock{}
+	summaryCalled := make(chan string, 2)
+	detector := NewDetector(20 * time.Millisecond)
+	manager := NewManager(store, &recorderMock{}, summarizerMock{called: summaryCalled}, hub, detector, WithMemoPreset("memo"))
+
+	if _, err := manager.StopMemo(context.Background()); !errors.Is(err, ErrNoMemoInProgress) {
+		t.Fatalf("expected ErrNoMemoInProgress, got %v", err)
+	}
+
+	// A memo ends the regular session in progress first.
+	msg := buildMsg(t, `{"is_final": true, "speech_final": true, "channel": {"alternatives": [{"transcript": "meeting talk", "words": [{"speaker": 0, "punctuated_word": "meeting", "start": 0, "end": 0.5}]}]}}`)
+	if err := manager.Message(msg); err != nil {
+		t.Fatal(err)
+	}
+	meetingID := manager.CurrentSessionID()
+
+	memoID, err := manager.StartMemo(context.Background())
+	if err != nil {
+		t.Fatalf("StartMemo failed: %v", err)
+	}
+	if memoID == "" || memoID == meetingID {
+		t.Fatalf("expected a new memo session, got %q after %q", memoID, meetingID)
+	}
+	if got := <-summaryCalled; got != meetingID {
+		t.Fatalf("expected the meeting to be summarized, got %q", got)
+	}
+	if _, err := manager.StartMemo(context.Background()); !errors.Is(err, ErrMemoInProgress) {
+		t.Fatalf("expected ErrMemoInProgress, got %v", err)
+	}
+
+	msg = buildMsg(t, `{"is_final": true, "speech_final": true, "channel": {"alternatives": [{"transcript": "buy milk", "words": [{"speaker": 0, "punctuated_word": "buy", "start": 0, "end": 0.5}, {"speaker": 0, "punctuated_word": "milk", "start": 0.5, "end": 1}]}]}}`)
+	if err := manager.Message(msg); err != nil {
+		t.Fatal(err)
+	}
+
+	// The silence timeout doesn't end a memo.
+	time.Sleep(60 * time.Millisecond)
+	if manager.CurrentSessionID() != memoID || !manager.RecordingMemo() {
+		t.Fatalf("expected memo %q still recording, current %q", memoID, manager.CurrentSessionID())
+	}
+
+	stopped, err := manager.StopMemo(context.Background())
+	if err != nil || stopped != memoID {
+		t.Fatalf("StopMemo returned %q, %v", stopped, err)
+	}
+	if got := <-summaryCalled; got != memoID {
+		t.Fatalf("expected the memo to be summarized, got %q", got)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		store.mu.Lock()
+		done := store.status[memoID] == storage.SummaryCompleted && store.status[meetingID] == storage.SummaryCompleted
+		store.mu.Unlock()
+		if done {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for summaries")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if store.kind[memoID] != storage.SessionKindMemo || store.kind[meetingID] != "" {
+		t.Fatalf("unexpected session kinds %v", store.kind)
+	}
+	if store.preset[memoID] != "memo" || store.preset[meetingID] != "default" {
+		t.Fatalf("expected memo preset for the memo only, got %v", store.preset)
+	}
+	if manager.RecordingMemo() {
+		t.Fatal("expected memo recording to have stopped")
+	}
+}
@@ -0,0 +1,116 @@
+package session
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+	"github.com/sjawhar/ghost-wispr/internal/summary"
+	"github.com/sjawhar/ghost-wispr/internal/transcribe"
+)
+
+// ErrNotesUnavailable is returned by AddNote when no note store is
+// configured.
+var ErrNotesUnavailable = errors.New("notes not configured")
+
+// ErrEmptyNote is returned by AddNote for a note with no text.
+var ErrEmptyNote = errors.New("note is empty")
+
+// NoteStore keeps the notes typed during sessions.
+type NoteStore interface {
+	AddNote(sessionID string, at time.Time, offset float64, text string) (storage.Note, error)
+	GetNotes(sessionID string) ([]storage.Note, error)
+}
+
+// WithNotes lets notes be typed during sessions. Summaries get them
+// interleaved with the transcript at the moment they were taken, marked
+// summary.NoteMarker, so the model can weigh what the note taker thought
+// mattered.
+func WithNotes(store NoteStore) Option {
+	return func(m *Manager) {
+		m.notes = store
+	}
+}
+
+// AddNote records text typed at the given time in the active session.
+func (m *Manager) AddNote(at time.Time, text string) (storage.Note, error) {
+	if m.notes == nil {
+		return storage.Note{}, ErrNotesUnavailable
+	}
+	if strings.TrimSpace(text) == "" {
+		return storage.Note{}, ErrEmptyNote
+	}
+	sessionID, offset, err := m.Timeline(at)
+	if err != nil {
+		return storage.Note{}, err
+	}
+	return m.notes.AddNote(sessionID, at, offset, text)
+}
+
+// Timeline returns the active session and where the wall time at falls on
+// the capture timeline its segments are timed on: the recorder's capture
+// position now, less how long ago at was. Without a recorder that tracks
+// it, at is placed by its time since the session started.
+func (m *Manager) Timeline(at time.Time) (sessionID string, offset float64, err error) {
+	sessionID = m.CurrentSessionID()
+	if sessionID == "" {
+		return "", 0, ErrNoActiveSession
+	}
+	sess, err := m.store.GetSession(sessionID)
+	if err != nil {
+		return "", 0, fmt.Errorf("get session: %w", err)
+	}
+	if aligner, ok := m.recorder.(AudioAligner); ok {
+		return sessionID, max(aligner.Captured()-m.clockNow().Sub(at).Seconds(), sess.AudioOffset), nil
+	}
+	return sessionID, sess.AudioOffset + max(at.Sub(sess.StartedAt).Seconds(), 0), nil
+}
+
+// sessionNotes returns a session's notes, or none when they can't be read;
+// a summary without them is better than no summary.
+func (m *Manager) sessionNotes(sessionID string) []storage.Note {
+	if m.notes == nil {
+		return nil
+	}
+	notes, err := m.notes.GetNotes(sessionID)
+	if err != nil {
+		slog.Warn("loading session notes failed", "session", sessionID, "error", err)
+		return nil
+	}
+	return notes
+}
+
+// buildTranscriptWithNotes is buildTranscript, or buildNamedTranscript when
+// names are set, with each note on its own line before the first segment
+// that starts after it was taken.
+func buildTranscriptWithNotes(segments []transcribe.Segment, notes []storage.Note, names map[int]string) string {
+	var b strings.Builder
+	writeNote := func(n storage.Note) {
+		b.WriteString(summary.NoteMarker)
+		b.WriteString(" ")
+		b.WriteString(n.Text)
+		b.WriteString("\n")
+	}
+	for _, segment := range segments {
+		if strings.TrimSpace(segment.Text) == "" {
+			continue
+		}
+		for len(notes) > 0 && notes[0].Offset <= segment.StartTime {
+			writeNote(notes[0])
+			notes = notes[1:]
+		}
+		if names != nil {
+			b.WriteString(speakerLabel(names, segment.Speaker))
+			b.WriteString(": ")
+		}
+		b.WriteString(segment.Text)
+		b.WriteString("\n")
+	}
+	for _, n := range notes {
+		writeNote(n)
+	}
+	return b.String()
+}
@@ -0,0 +1,98 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+	"github.com/sjawhar/ghost-wispr/internal/transcribe"
+)
+
+func TestManager_NotesInterleavedIntoSummary(t *testing.T) {
+	store := newStoreMock()
+	notes := storage.NewMemoryStore()
+	manager := NewManager(store, nil, summarizerMock{}, &hubMock{}, NewDetector(time.Hour), WithNotes(notes))
+
+	if _, err := manager.AddNote(time.Now(), "ship date is firm"); !errors.Is(err, ErrNoActiveSession) {
+		t.Fatalf("expected ErrNoActiveSession without a session, got %v", err)
+	}
+
+	start := time.Now()
+	if err := manager.ensureSessionStarted(start); err != nil {
+		t.Fatalf("ensureSessionStarted failed: %v", err)
+	}
+	sessionID := manager.currentSession()
+	_ = notes.CreateSession(sessionID, start)
+
+	if _, err := manager.AddNote(start.Add(time.Minute), "  "); !errors.Is(err, ErrEmptyNote) {
+		t.Fatalf("expected ErrEmptyNote, got %v", err)
+	}
+	for _, n := range []struct {
+		at   time.Duration
+		text string
+	}{{45 * time.Second, "ship date is firm"}, {10 * time.Minute, "follow up with legal"}} {
+		if _, err := manager.AddNote(start.Add(n.at), n.text); err != nil {
+			t.Fatalf("AddNote failed: %v", err)
+		}
+	}
+	for _, seg := range []transcribe.Segment{
+		{Text: "we ship in may", StartTime: 30},
+		{Text: "unless qa slips", StartTime: 60},
+	} {
+		_ = store.AppendSegment(sessionID, seg)
+	}
+
+	if err := manager.Resummarize(context.Background(), sessionID, "default"); err != nil {
+		t.Fatalf("Resummarize failed: %v", err)
+	}
+	store.mu.Lock()
+	got := store.summary[sessionID]
+	store.mu.Unlock()
+	want := "## default\n- we ship in may\n[note] ship date is firm\nunless qa slips\n[note] follow up with legal\n"
+	if got != want {
+		t.Fatalf("expected notes interleaved by time\nwant %q\ngot  %q", want, got)
+	}
+}
+
+func TestManager_NotesArePlacedOnTheCaptureTimeline(t *testing.T) {
+	// A later session in a run: its recording starts 100s into the capture
+	// timeline, which has reached 200s, and its segments are timed on it.
+	store := newStoreMock()
+	notes := storage.NewMemoryStore()
+	recorder := &alignedRecorderMock{audioStart: 100, captured: 200}
+	manager := NewManager(store, recorder, summarizerMock{}, &hubMock{}, NewDetector(time.Hour), WithNotes(notes))
+
+	start := time.Now()
+	if err := manager.ensureSessionStarted(start); err != nil {
+		t.Fatalf("ensureSessionStarted failed: %v", err)
+	}
+	sessionID := manager.currentSession()
+	_ = notes.CreateSession(sessionID, start)
+
+	note, err := manager.AddNote(time.Now().Add(-30*time.Second), "ship date is firm")
+	if err != nil {
+		t.Fatalf("AddNote failed: %v", err)
+	}
+	if note.Offset < 169 || note.Offset > 170 {
+		t.Fatalf("expected the note 30s before the capture position, got %v", note.Offset)
+	}
+	for _, seg := range []transcribe.Segment{
+		{Text: "we ship in may", StartTime: 160},
+		{Text: "unless qa slips", StartTime: 180},
+	} {
+		_ = store.AppendSegment(sessionID, seg)
+	}
+
+	if err := manager.Resummarize(context.Background(), sessionID, "default"); err != nil {
+		t.Fatalf("Resummarize failed: %v", err)
+	}
+	store.mu.Lock()
+	got := store.summary[sessionID]
+	store.mu.Unlock()
+	want := "## default\n- we ship in may\n[note] ship date is firm\nunless qa slips\n"
+	if got != want {
+		t.Fatalf("expected the note between the segments around it\nwant %q\ngot  %q", want, got)
+	}
+}
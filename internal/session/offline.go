@@ -0,0 +1,116 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+)
+
+// NetworkProbe reports whether the network is reachable.
+type NetworkProbe func(ctx context.Context) error
+
+// TCPProbe returns a probe that succeeds when a TCP connection to addr can be
+// established.
+func TCPProbe(addr string) NetworkProbe {
+	return func(ctx context.Context) error {
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+}
+
+// WithOfflineQueue queues summaries that fail for lack of connectivity as
+// "queued_offline" instead of failing them. RunOfflineQueue retries them once
+// probe succeeds, checking every interval.
+func WithOfflineQueue(probe NetworkProbe, interval time.Duration) Option {
+	return func(m *Manager) {
+		m.offlineProbe = probe
+		m.offlineInterval = interval
+	}
+}
+
+// RunOfflineQueue retries queued offline summaries whenever the network probe
+// succeeds, until ctx is cancelled. Sessions queued before a restart are
+// picked up on the first pass.
+func (m *Manager) RunOfflineQueue(ctx context.Context) {
+	if m.offlineProbe == nil || m.summarizer == nil {
+		return
+	}
+
+	ticker := time.NewTicker(m.offlineInterval)
+	defer ticker.Stop()
+	for {
+		m.retryOfflineSummaries(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *Manager) retryOfflineSummaries(ctx context.Context) {
+	queued, err := m.store.GetSessionsBySummaryStatus(storage.SummaryQueuedOffline)
+	if err != nil {
+		slog.Warn("listing offline summaries failed", "error", err)
+		return
+	}
+	if len(queued) == 0 {
+		return
+	}
+
+	probeCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	err = m.offlineProbe(probeCtx)
+	cancel()
+	if err != nil {
+		return
+	}
+
+	slog.Info("network reachable, retrying offline summaries", "count", len(queued))
+	for _, sess := range queued {
+		if ctx.Err() != nil {
+			return
+		}
+		// Spend may have crossed a budget while the summary waited.
+		summaryCtx, allowed := m.applyBudget(ctx, true)
+		if !allowed {
+			_ = m.store.UpdateSummary(sess.ID, "", storage.SummarySkippedBudget, "")
+			m.broadcastSummaryStatus(sess.ID, "", storage.SummarySkippedBudget, "")
+			continue
+		}
+		m.broadcastSummaryStatus(sess.ID, "", storage.SummaryRunning, sess.SummaryPreset)
+		if err := m.summarize(summaryCtx, sess.ID, sess.SummaryPreset); err != nil {
+			slog.Warn("retrying offline summary failed", "session", sess.ID, "error", err)
+			if isConnectivityError(err) {
+				return
+			}
+		}
+	}
+}
+
+// isConnectivityError reports whether err stems from the network being
+// unreachable rather than from the provider rejecting the request.
+func isConnectivityError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	for _, errno := range []syscall.Errno{syscall.ECONNREFUSED, syscall.ECONNRESET, syscall.ENETUNREACH, syscall.EHOSTUNREACH, syscall.ENETDOWN} {
+		if errors.Is(err, errno) {
+			return true
+		}
+	}
+	return false
+}
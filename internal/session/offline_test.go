@@ -0,0 +1,123 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/config"
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+)
+
+type flakySummarizer struct {
+	summarizerMock
+	err error
+}
+
+func (s *flakySummarizer) Summarize(ctx context.Context, sessionID, transcript string) (string, string, error) {
+	if s.err != nil {
+		return "", "default", s.err
+	}
+	return s.summarizerMock.Summarize(ctx, sessionID, transcript)
+}
+
+func (s *flakySummarizer) SummarizeWithPreset(ctx context.Context, sessionID, transcript, preset string) (string, error) {
+	if s.err != nil {
+		return "", s.err
+	}
+	return s.summarizerMock.SummarizeWithPreset(ctx, sessionID, transcript, preset)
+}
+
+func TestManager_OfflineSummaryQueuedAndRetried(t *testing.T) {
+	store := newStoreMock()
+	hub := &hubMock{}
+	offline := fmt.Errorf("openai completion: %w", &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("no route to host")})
+	summarizer := &flakySummarizer{err: offline}
+	probeErr := errors.New("offline")
+	probe := func(context.Context) error { return probeErr }
+	manager := NewManager(store, nil, summarizer, hub, NewDetector(time.Hour), WithOfflineQueue(probe, time.Hour))
+
+	if err := store.CreateSession("s1", time.Now().UTC()); err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+	manager.generateSummary(context.Background(), "s1", "")
+
+	store.mu.Lock()
+	status := store.status["s1"]
+	store.mu.Unlock()
+	if status != storage.SummaryQueuedOffline {
+		t.Fatalf("expected summary status %q, got %q", storage.SummaryQueuedOffline, status)
+	}
+
+	summarizer.err = nil
+	manager.retryOfflineSummaries(context.Background())
+	store.mu.Lock()
+	status = store.status["s1"]
+	store.mu.Unlock()
+	if status != storage.SummaryQueuedOffline {
+		t.Fatalf("expected summary to stay queued while probe fails, got %q", status)
+	}
+
+	probeErr = nil
+	manager.retryOfflineSummaries(context.Background())
+	store.mu.Lock()
+	status = store.status["s1"]
+	store.mu.Unlock()
+	if status != storage.SummaryCompleted {
+		t.Fatalf("expected summary completed after reconnect, got %q", status)
+	}
+}
+
+func TestManager_NonNetworkErrorFailsSummary(t *testing.T) {
+	store := newStoreMock()
+	summarizer := &flakySummarizer{err: errors.New("openai completion: 401 unauthorized")}
+	probe := func(context.Context) error { return nil }
+	manager := NewManager(store, nil, summarizer, &hubMock{}, NewDetector(time.Hour), WithOfflineQueue(probe, time.Hour))
+
+	if err := store.CreateSession("s1", time.Now().UTC()); err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+	manager.generateSummary(context.Background(), "s1", "")
+
+	store.mu.Lock()
+	status := store.status["s1"]
+	store.mu.Unlock()
+	if status != storage.SummaryFailed {
+		t.Fatalf("expected summary status failed, got %q", status)
+	}
+}
+
+func TestManager_OfflineRetryRespectsBudget(t *testing.T) {
+	store := newStoreMock()
+	called := make(chan string, 1)
+	guard := NewBudgetGuard(config.Budget{
+		MonthlyLLMUSD: 1,
+		LLMAction:     config.BudgetActionDisableSummaries,
+	}, &spendStub{spend: storage.MonthlySpend{LLMCost: 2}})
+	probe := func(context.Context) error { return nil }
+	manager := NewManager(store, nil, summarizerMock{called: called}, &hubMock{}, NewDetector(time.Hour),
+		WithOfflineQueue(probe, time.Hour), WithBudget(guard))
+
+	if err := store.CreateSession("s1", time.Now().UTC()); err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+	if err := store.UpdateSummary("s1", "", storage.SummaryQueuedOffline, ""); err != nil {
+		t.Fatal(err)
+	}
+	manager.retryOfflineSummaries(context.Background())
+
+	select {
+	case <-called:
+		t.Fatal("expected the queued summary skipped over budget")
+	default:
+	}
+	store.mu.Lock()
+	status := store.status["s1"]
+	store.mu.Unlock()
+	if status != storage.SummarySkippedBudget {
+		t.Fatalf("expected summary status skipped_budget, got %q", status)
+	}
+}
@@ -0,0 +1,56 @@
+package session
+
+import "log/slog"
+
+// SetTranscriptionProvider records that live transcription has moved to
+// provider, transcribing with model: on the session in progress, which may
+// then list several providers, and on every session started from now on.
+func (m *Manager) SetTranscriptionProvider(provider, model string) {
+	m.mu.Lock()
+	m.liveProvider, m.liveModel = provider, model
+	sessionID := m.currentSessionID
+	m.mu.Unlock()
+
+	if sessionID != "" {
+		m.recordTranscriptionProvider(sessionID, provider)
+	}
+}
+
+// SetChannelSpeakers records whether the speakers of sessions started from
+// now on follow the capture's channels, as with separate microphone and
+// loopback channels, rather than diarization.
+func (m *Manager) SetChannelSpeakers(channels bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.channelSpeakers = channels
+}
+
+// recordLiveTranscription notes on a new live session the provider and
+// model transcribing it, falling back to the configured model until a
+// provider has been named, and whether its speakers follow channels.
+func (m *Manager) recordLiveTranscription(sessionID string) {
+	m.mu.Lock()
+	provider, model, channels := m.liveProvider, m.liveModel, m.channelSpeakers
+	m.mu.Unlock()
+
+	if channels {
+		if err := m.store.SetChannelSpeakers(sessionID, true); err != nil {
+			slog.Warn("recording channel speakers failed", "session", sessionID, "error", err)
+		}
+	}
+
+	if provider == "" {
+		m.recordTranscriptionModel(sessionID)
+		return
+	}
+	if err := m.store.SetTranscriptionModel(sessionID, model, m.transcriptionLanguage); err != nil {
+		slog.Warn("recording transcription model failed", "session", sessionID, "error", err)
+	}
+	m.recordTranscriptionProvider(sessionID, provider)
+}
+
+func (m *Manager) recordTranscriptionProvider(sessionID, provider string) {
+	if err := m.store.AddTranscriptionProvider(sessionID, provider); err != nil {
+		slog.Warn("recording transcription provider failed", "session", sessionID, "error", err)
+	}
+}
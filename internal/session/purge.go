@@ -0,0 +1,199 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sjawhar/ghost-wispr/internal/config"
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+	"github.com/sjawhar/ghost-wispr/internal/transcribe"
+)
+
+// PurgeStore is the storage a session is purged from.
+type PurgeStore interface {
+	GetSession(id string) (storage.Session, error)
+	GetSegments(sessionID string) ([]transcribe.Segment, error)
+	GetActionItems(sessionID string) ([]storage.ActionItem, error)
+	GetNotes(sessionID string) ([]storage.Note, error)
+	GetBookmarks(sessionID string) ([]storage.Bookmark, error)
+	GetAnnotations(sessionID string) ([]storage.Annotation, error)
+	GetAudioFiles(sessionID string) ([]storage.AudioFile, error)
+	GetExports(sessionID string) ([]storage.Export, error)
+	ListVoiceProfiles() ([]storage.VoiceProfile, error)
+	DeleteVoiceProfile(id int64) error
+	DeleteSession(id string) error
+}
+
+// PurgeReport says what purging a session deleted. Kept lists what it
+// could not reach, such as a Slack message already posted, and Errors what
+// it tried to delete and failed to.
+type PurgeReport struct {
+	SessionID string `json:"session_id"`
+	// Records counts the rows deleted with the session, by kind.
+	Records       map[string]int `json:"records"`
+	Files         []string       `json:"files"`
+	DriveFiles    []string       `json:"drive_files"`
+	VoiceProfiles []string       `json:"voice_profiles"`
+	Kept          []string       `json:"kept,omitempty"`
+	Errors        []string       `json:"errors,omitempty"`
+}
+
+// Purger deletes a session along with everything derived from it: its
+// recordings and clips, summary audio, exported files and Google Docs, and
+// voices enrolled from it. Its summary in a daily journal is kept.
+type Purger struct {
+	Store PurgeStore
+	// DeleteDriveFile deletes a Google Drive file by ID, returning an error
+	// wrapping os.ErrNotExist if it is already gone; nil leaves Drive copies
+	// in place, listed as kept.
+	DeleteDriveFile func(ctx context.Context, fileID string) error
+	// DriveBackups notes that database backups already synced to Drive
+	// still hold the session.
+	DriveBackups bool
+}
+
+// Purge deletes a session and what was derived from it. The session's rows
+// go first, in one transaction, so a locked or missing session leaves
+// everything in place; what fails to be deleted after that is listed in the
+// report's Errors.
+func (p *Purger) Purge(ctx context.Context, sessionID string) (PurgeReport, error) {
+	sess, err := p.Store.GetSession(sessionID)
+	if err != nil {
+		return PurgeReport{}, err
+	}
+	report := PurgeReport{
+		SessionID:     sessionID,
+		Records:       make(map[string]int),
+		Files:         []string{},
+		DriveFiles:    []string{},
+		VoiceProfiles: []string{},
+	}
+	if err := p.countRecords(sessionID, report.Records); err != nil {
+		return PurgeReport{}, err
+	}
+	files, err := p.Store.GetAudioFiles(sessionID)
+	if err != nil {
+		return PurgeReport{}, fmt.Errorf("get audio files: %w", err)
+	}
+	exports, err := p.Store.GetExports(sessionID)
+	if err != nil {
+		return PurgeReport{}, fmt.Errorf("get exports: %w", err)
+	}
+	profiles, err := p.Store.ListVoiceProfiles()
+	if err != nil {
+		return PurgeReport{}, fmt.Errorf("list voice profiles: %w", err)
+	}
+
+	if err := p.Store.DeleteSession(sessionID); err != nil {
+		return PurgeReport{}, err
+	}
+
+	var paths, driveIDs []string
+	for _, f := range files {
+		paths = append(paths, f.Path, strings.TrimSuffix(f.Path, filepath.Ext(f.Path))+".peaks.json")
+	}
+	paths = append(paths, sess.AudioPath, sess.SummaryAudioPath, sess.WAVPath)
+	if sess.GDocID != "" {
+		driveIDs = append(driveIDs, sess.GDocID)
+	}
+	// The journal doc holds the day's other sessions too, and edits made
+	// in it, so the session's section is left for the user to remove.
+	if sess.JournalDocID != "" {
+		report.Kept = append(report.Kept, fmt.Sprintf("summary in the daily journal Google Doc %s", sess.JournalDocID))
+	}
+	for _, e := range exports {
+		if e.Location == "" {
+			continue
+		}
+		switch e.Target {
+		case config.ExportTargetMarkdown:
+			paths = append(paths, e.Location)
+		case config.ExportTargetGDrive:
+			driveIDs = append(driveIDs, e.Location)
+		case config.ExportTargetSlack:
+			report.Kept = append(report.Kept, fmt.Sprintf("Slack message posted to %s by export rule %q", e.Location, e.Rule))
+		}
+	}
+
+	removed := make(map[string]bool)
+	for _, path := range paths {
+		if path == "" || removed[path] {
+			continue
+		}
+		removed[path] = true
+		if err := os.Remove(path); err == nil {
+			report.Files = append(report.Files, path)
+		} else if !errors.Is(err, os.ErrNotExist) {
+			report.Errors = append(report.Errors, err.Error())
+		}
+	}
+
+	for _, id := range driveIDs {
+		if p.DeleteDriveFile == nil {
+			report.Kept = append(report.Kept, fmt.Sprintf("Google Doc %s, with Drive not configured", id))
+			continue
+		}
+		if err := p.DeleteDriveFile(ctx, id); err == nil {
+			report.DriveFiles = append(report.DriveFiles, id)
+		} else if !errors.Is(err, os.ErrNotExist) {
+			report.Errors = append(report.Errors, fmt.Sprintf("delete Google Doc %s: %v", id, err))
+		}
+	}
+	if p.DriveBackups {
+		report.Kept = append(report.Kept, "database backups synced to Google Drive before the purge")
+	}
+
+	for _, v := range profiles {
+		if v.SessionID != sessionID {
+			continue
+		}
+		if err := p.Store.DeleteVoiceProfile(v.ID); err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("delete voice profile %q: %v", v.Name, err))
+			continue
+		}
+		report.VoiceProfiles = append(report.VoiceProfiles, v.Name)
+	}
+
+	slog.Info("purged session", "session", sessionID, "files", len(report.Files), "drive_files", len(report.DriveFiles), "voice_profiles", len(report.VoiceProfiles), "errors", len(report.Errors))
+	return report, nil
+}
+
+// countRecords counts, by kind, the rows that go with the session.
+func (p *Purger) countRecords(sessionID string, records map[string]int) error {
+	segments, err := p.Store.GetSegments(sessionID)
+	if err != nil {
+		return fmt.Errorf("get segments: %w", err)
+	}
+	records["segments"] = len(segments)
+	items, err := p.Store.GetActionItems(sessionID)
+	if err != nil {
+		return fmt.Errorf("get action items: %w", err)
+	}
+	records["action_items"] = len(items)
+	notes, err := p.Store.GetNotes(sessionID)
+	if err != nil {
+		return fmt.Errorf("get notes: %w", err)
+	}
+	records["notes"] = len(notes)
+	bookmarks, err := p.Store.GetBookmarks(sessionID)
+	if err != nil {
+		return fmt.Errorf("get bookmarks: %w", err)
+	}
+	records["bookmarks"] = len(bookmarks)
+	annotations, err := p.Store.GetAnnotations(sessionID)
+	if err != nil {
+		return fmt.Errorf("get annotations: %w", err)
+	}
+	records["annotations"] = len(annotations)
+	exports, err := p.Store.GetExports(sessionID)
+	if err != nil {
+		return fmt.Errorf("get exports: %w", err)
+	}
+	records["exports"] = len(exports)
+	return nil
+}
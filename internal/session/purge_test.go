@@ -0,0 +1,131 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+	"github.com/sjawhar/ghost-wispr/internal/transcribe"
+)
+
+func TestPurgeRemovesDerivedArtifacts(t *testing.T) {
+	store := storage.NewMemoryStore()
+	dir := t.TempDir()
+	file := func(name string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	start := time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC)
+	recording, peaks, clip, summaryAudio, notes := file("s1.mp3"), file("s1.peaks.json"), file("s1-clip.mp3"), file("s1-summary.mp3"), file("ghost-wispr-s1.md")
+
+	if err := store.CreateSession("s1", start); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.AppendSegment("s1", transcribe.Segment{Speaker: 0, Text: "ship it", Timestamp: start}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.EndSession("s1", start.Add(time.Hour), recording); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.AddAudioFile("s1", storage.AudioClip, clip); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetSummaryAudioPath("s1", summaryAudio); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetGDocID("s1", "review-doc"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetJournalDocID("s1", "journal-doc"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.ReplaceActionItems("s1", []storage.ActionItem{{SessionID: "s1", Text: "ship it"}}); err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range []storage.Export{
+		{SessionID: "s1", Rule: "notes", Target: "markdown", Status: storage.ExportCompleted, Location: notes},
+		{SessionID: "s1", Rule: "drive", Target: "gdrive", Status: storage.ExportCompleted, Location: "export-doc"},
+		{SessionID: "s1", Rule: "team", Target: "slack", Status: storage.ExportCompleted, Location: "#standup"},
+	} {
+		if err := store.SetExport(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, v := range []storage.VoiceProfile{
+		{Name: "Ada", Source: "session s1, speaker 0", SessionID: "s1", Embedding: []float64{1}},
+		{Name: "Grace", Source: "recording", Embedding: []float64{1}},
+	} {
+		if _, err := store.AddVoiceProfile(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var deleted []string
+	purger := &Purger{Store: store, DeleteDriveFile: func(_ context.Context, id string) error {
+		deleted = append(deleted, id)
+		return nil
+	}}
+	report, err := purger.Purge(context.Background(), "s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.GetSession("s1"); err == nil {
+		t.Fatal("expected the session deleted")
+	}
+	for _, path := range []string{recording, peaks, clip, summaryAudio, notes} {
+		if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("expected %s removed", path)
+		}
+		if !slices.Contains(report.Files, path) {
+			t.Errorf("expected %s in the report, got %v", path, report.Files)
+		}
+	}
+	if !slices.Equal(deleted, []string{"review-doc", "export-doc"}) || !slices.Equal(report.DriveFiles, deleted) {
+		t.Fatalf("expected both Google Docs deleted, got %v (reported %v)", deleted, report.DriveFiles)
+	}
+	if !slices.Equal(report.VoiceProfiles, []string{"Ada"}) {
+		t.Fatalf("expected only the voice learned from the session deleted, got %v", report.VoiceProfiles)
+	}
+	profiles, _ := store.ListVoiceProfiles()
+	if len(profiles) != 1 || profiles[0].Name != "Grace" {
+		t.Fatalf("expected Grace's voice kept, got %+v", profiles)
+	}
+	if report.Records["segments"] != 1 || report.Records["action_items"] != 1 || report.Records["exports"] != 3 {
+		t.Fatalf("unexpected record counts: %v", report.Records)
+	}
+	if len(report.Kept) != 2 || !strings.Contains(report.Kept[0], "journal-doc") || len(report.Errors) != 0 {
+		t.Fatalf("expected only the journal section and the Slack message kept, got kept %v, errors %v", report.Kept, report.Errors)
+	}
+}
+
+func TestPurgeLeavesLockedSession(t *testing.T) {
+	store := storage.NewMemoryStore()
+	recording := filepath.Join(t.TempDir(), "s1.mp3")
+	if err := os.WriteFile(recording, []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	addEndedSession(t, store, "s1", time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC), time.Hour)
+	if _, err := store.AddAudioFile("s1", storage.AudioClip, recording); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetSessionLocked("s1", true); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := (&Purger{Store: store}).Purge(context.Background(), "s1"); !errors.Is(err, storage.ErrSessionLocked) {
+		t.Fatalf("expected ErrSessionLocked, got %v", err)
+	}
+	if _, err := os.Stat(recording); err != nil {
+		t.Fatalf("expected a locked session's audio kept: %v", err)
+	}
+}
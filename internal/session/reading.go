@@ -0,0 +1,42 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/llm"
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+)
+
+// SummaryRewriter rewrites a finished summary at another reading level
+// without reading the transcript again.
+type SummaryRewriter interface {
+	RewriteSummary(ctx context.Context, summary, preset, level string) (string, error)
+}
+
+// RewriteSummary returns a session's summary rewritten at level, one of
+// config.ReadingLevels. The stored summary is left as it is; sessions
+// without a finished summary return ErrNoSummary and need resummarizing
+// at that level instead. Like a resummary it is asked for by hand, so an
+// exhausted LLM budget switches it to the fallback model but never skips it.
+func (m *Manager) RewriteSummary(ctx context.Context, sessionID, level string) (string, error) {
+	rewriter, ok := m.summarizer.(SummaryRewriter)
+	if !ok {
+		return "", ErrSummarizationUnavailable
+	}
+	sess, err := m.store.GetSession(sessionID)
+	if err != nil {
+		return "", fmt.Errorf("get session: %w", err)
+	}
+	if sess.SummaryStatus != storage.SummaryCompleted || sess.Summary == "" {
+		return "", ErrNoSummary
+	}
+
+	ctx, _ = m.applyBudget(ctx, false)
+	usage := llm.NewUsageRecorder()
+	started := time.Now()
+	rewritten, err := rewriter.RewriteSummary(llm.WithUsageRecorder(ctx, usage), sess.Summary, sess.SummaryPreset, level)
+	m.recordLLMUsage(sessionID, usage, time.Since(started))
+	return rewritten, err
+}
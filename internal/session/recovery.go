@@ -0,0 +1,158 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/transcribe"
+)
+
+// ErrGapRecoveryUnavailable is returned when no batch transcriber is configured.
+var ErrGapRecoveryUnavailable = errors.New("gap recovery not configured")
+
+// WithGapTranscriber transcribes audio buffered while live transcription was
+// down so it can be spliced back into its session.
+func WithGapTranscriber(t transcribe.BatchTranscriber) Option {
+	return func(m *Manager) {
+		m.gapTranscriber = t
+	}
+}
+
+// RecoverGap transcribes a span missed by live transcription and stores the
+// resulting segments in the session that was active when the gap began,
+// timestamped at their position in the gap and timed on the session's
+// timeline like its live segments. If no session was active, one is
+// started for the recovered speech. When the gap can't be transcribed yet and
+// a gap queue is configured, it is queued for RunGapQueue instead.
+func (m *Manager) RecoverGap(ctx context.Context, gap transcribe.Gap) error {
+	if m.gapTranscriber == nil {
+		return ErrGapRecoveryUnavailable
+	}
+
+	words, err := m.gapTranscriber.TranscribePCM(ctx, gap.PCM, gap.SampleRate)
+	if err != nil && m.gapQueue != nil {
+		if qerr := m.gapQueue.add(gap); qerr != nil {
+			return errors.Join(fmt.Errorf("transcribe gap: %w", err), qerr)
+		}
+		slog.Warn("offline audio queued for transcription", "session", gap.SessionID, "gap", gap.Duration(), "error", err)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("transcribe gap: %w", err)
+	}
+	return m.spliceGap(ctx, gap, words, false)
+}
+
+// spliceGap stores the transcript of a gap. A queued gap is transcribed
+// long after it was recorded, so one from outside any session becomes a
+// finished session of its own rather than starting one now, and a session
+// that has ended since is summarized again.
+func (m *Manager) spliceGap(ctx context.Context, gap transcribe.Gap, words []transcribe.Word, queued bool) error {
+	segments := m.groupWords(words)
+	if len(segments) == 0 {
+		return nil
+	}
+	m.redactSegments(segments)
+	if queued && gap.SessionID == "" {
+		return m.importGap(ctx, gap, segments)
+	}
+
+	sessionID := gap.SessionID
+	if sessionID == "" {
+		if err := m.ensureSessionStarted(gap.Start); err != nil {
+			return err
+		}
+		sessionID = m.currentSession()
+		defer m.detector.OnUtteranceEnd()
+	}
+
+	offset := m.gapOffset(sessionID, gap.Start)
+	for _, seg := range segments {
+		seg.Timestamp = gap.Start.Add(time.Duration(seg.StartTime * float64(time.Second))).UTC()
+		seg.StartTime += offset
+		seg.EndTime += offset
+		for i := range seg.Words {
+			seg.Words[i].Start += offset
+			seg.Words[i].End += offset
+		}
+		if err := m.store.AppendSegment(sessionID, seg); err != nil {
+			return fmt.Errorf("append recovered segment: %w", err)
+		}
+	}
+
+	slog.Info("recovered offline transcription", "session", sessionID, "segments", len(segments), "gap", gap.Duration())
+	if queued && sessionID != m.currentSession() {
+		sess, err := m.store.GetSession(sessionID)
+		if err == nil && sess.Status == "ended" {
+			m.generateSummary(ctx, sessionID, sess.SummaryPreset)
+		}
+	}
+	return nil
+}
+
+// gapOffset is where a gap that began at start falls on the timeline the
+// session's segments are timed on, which starts at its audio offset when
+// the session did. The transcript of a gap is timed from the gap's start.
+func (m *Manager) gapOffset(sessionID string, start time.Time) float64 {
+	sess, err := m.store.GetSession(sessionID)
+	if err != nil {
+		slog.Warn("timing recovered segments from the gap", "session", sessionID, "error", err)
+		return 0
+	}
+	return sess.AudioOffset + max(start.Sub(sess.StartedAt).Seconds(), 0)
+}
+
+// OrphanRecoverer finishes the raw recordings a run that stopped
+// mid-session, such as after a crash, left unencoded.
+type OrphanRecoverer interface {
+	OrphanedSessions() ([]string, error)
+	RecoverSession(sessionID string) (audioPath string, duration time.Duration, err error)
+}
+
+// RecoverOrphanedAudio encodes the recordings an earlier run left behind,
+// if the recorder can, attaching each to its session, which is ended and
+// marked recovered. Raw audio for sessions that aren't stored is left in
+// place. It returns how many sessions were recovered.
+func (m *Manager) RecoverOrphanedAudio() (int, error) {
+	rec, ok := m.recorder.(OrphanRecoverer)
+	if !ok {
+		return 0, nil
+	}
+	ids, err := rec.OrphanedSessions()
+	if err != nil {
+		return 0, err
+	}
+
+	recovered := 0
+	var errs []error
+	for _, id := range ids {
+		if id == m.currentSession() {
+			continue
+		}
+		sess, err := m.store.GetSession(id)
+		if err != nil {
+			slog.Warn("leaving raw audio without a stored session", "session", id, "error", err)
+			continue
+		}
+		audioPath, duration, err := rec.RecoverSession(id)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("recover audio of %s: %w", id, err))
+			continue
+		}
+		// In the boot it started in, a session can't have run longer than
+		// the boot clock has since.
+		if sess.ClockBootID != "" && sess.ClockBootID == bootID() {
+			duration = min(duration, max(bootClock()-sess.ClockBoot, 0))
+		}
+		if err := m.store.MarkSessionRecovered(id, sess.StartedAt.Add(duration), audioPath); err != nil {
+			errs = append(errs, fmt.Errorf("mark %s recovered: %w", id, err))
+			continue
+		}
+		slog.Info("recovered orphaned recording", "session", id, "audio", audioPath, "duration", duration)
+		recovered++
+	}
+	return recovered, errors.Join(errs...)
+}
@@ -0,0 +1,272 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+	"github.com/sjawhar/ghost-wispr/internal/transcribe"
+)
+
+type batchTranscriberStub struct {
+	words []transcribe.Word
+}
+
+func (b batchTranscriberStub) TranscribePCM(context.Context, []byte, int) ([]transcribe.Word, error) {
+	return b.words, nil
+}
+
+func TestManager_RecoverGapSplicesIntoSession(t *testing.T) {
+	store := newStoreMock()
+	speaker := 1
+	manager := NewManager(store, nil, nil, nil, NewDetector(time.Hour), WithGapTranscriber(batchTranscriberStub{
+		words: []transcribe.Word{
+			{Speaker: &speaker, PunctuatedWord: "missed", Start: 2, End: 2.5},
+			{Speaker: &speaker, PunctuatedWord: "words", Start: 2.5, End: 3},
+		},
+	}))
+
+	start := time.Date(2026, 2, 26, 10, 0, 0, 0, time.UTC)
+	if err := store.CreateSession("s1", start); err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+	if err := store.SetAudioOffset("s1", 30); err != nil {
+		t.Fatalf("SetAudioOffset failed: %v", err)
+	}
+
+	gap := transcribe.Gap{SessionID: "s1", Start: start.Add(time.Minute), PCM: make([]byte, 32000), SampleRate: 16000}
+	if err := manager.RecoverGap(context.Background(), gap); err != nil {
+		t.Fatalf("RecoverGap failed: %v", err)
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	segments := store.segments["s1"]
+	if len(segments) != 1 || segments[0].Text != "missed words" {
+		t.Fatalf("unexpected recovered segments %#v", segments)
+	}
+	if want := start.Add(time.Minute + 2*time.Second); !segments[0].Timestamp.Equal(want) {
+		t.Fatalf("expected segment at %v, got %v", want, segments[0].Timestamp)
+	}
+	// The session's timeline starts 30s into the capture, and the gap a
+	// minute into the session.
+	if segments[0].StartTime != 92 || segments[0].EndTime != 93 {
+		t.Fatalf("expected the segment timed on the session's timeline at 92-93s, got %v-%v", segments[0].StartTime, segments[0].EndTime)
+	}
+}
+
+func TestManager_RecoverGapWithoutTranscriber(t *testing.T) {
+	manager := NewManager(newStoreMock(), nil, nil, nil, NewDetector(time.Hour))
+	if err := manager.RecoverGap(context.Background(), transcribe.Gap{}); err != ErrGapRecoveryUnavailable {
+		t.Fatalf("expected ErrGapRecoveryUnavailable, got %v", err)
+	}
+}
+
+type orphanRecorderStub struct {
+	recorderMock
+	orphans   []string
+	recovered []string
+}
+
+func (r *orphanRecorderStub) OrphanedSessions() ([]string, error) {
+	return r.orphans, nil
+}
+
+func (r *orphanRecorderStub) RecoverSession(sessionID string) (string, time.Duration, error) {
+	r.recovered = append(r.recovered, sessionID)
+	return "data/audio/" + sessionID + ".mp3", 90 * time.Second, nil
+}
+
+func TestManager_RecoverOrphanedAudio(t *testing.T) {
+	store := newStoreMock()
+	store.sessions["crashed"] = time.Now().UTC()
+	rec := &orphanRecorderStub{orphans: []string{"crashed", "unknown"}}
+	manager := NewManager(store, rec, nil, nil, NewDetector(time.Hour))
+
+	n, err := manager.RecoverOrphanedAudio()
+	if err != nil {
+		t.Fatalf("RecoverOrphanedAudio failed: %v", err)
+	}
+	if n != 1 || len(rec.recovered) != 1 || rec.recovered[0] != "crashed" {
+		t.Fatalf("expected only the stored session recovered, got %d %v", n, rec.recovered)
+	}
+	if store.status["crashed"] != "recovered" || store.audio["crashed"] != "data/audio/crashed.mp3" {
+		t.Fatalf("expected the session marked recovered with its audio, got %q %q", store.status["crashed"], store.audio["crashed"])
+	}
+}
+
+// flakyTranscriber fails until it is brought back online.
+type flakyTranscriber struct {
+	mu     sync.Mutex
+	online bool
+	words  []transcribe.Word
+}
+
+func (f *flakyTranscriber) TranscribePCM(context.Context, []byte, int) ([]transcribe.Word, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.online {
+		return nil, errors.New("dial tcp: no route to host")
+	}
+	return f.words, nil
+}
+
+func TestManager_QueuedGapsTranscribedOnceOnline(t *testing.T) {
+	store := newStoreMock()
+	speaker := 0
+	transcriber := &flakyTranscriber{words: []transcribe.Word{
+		{Speaker: &speaker, PunctuatedWord: "offline", Start: 1, End: 1.5},
+		{Speaker: &speaker, PunctuatedWord: "words", Start: 1.5, End: 2},
+	}}
+	dir := t.TempDir()
+	var saved []string
+	manager := NewManager(store, nil, summarizerMock{}, nil, NewDetector(time.Hour),
+		WithGapTranscriber(transcriber),
+		WithGapQueue(dir, time.Minute, func(sessionID string, gap transcribe.Gap) (string, error) {
+			saved = append(saved, sessionID)
+			return "data/audio/" + sessionID + ".flac", nil
+		}),
+	)
+
+	start := time.Date(2026, 2, 26, 10, 0, 0, 0, time.UTC)
+	if err := store.CreateSession("s1", start); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.EndSession("s1", start.Add(time.Hour), "data/audio/s1.mp3"); err != nil {
+		t.Fatal(err)
+	}
+
+	inSession := transcribe.Gap{SessionID: "s1", Start: start.Add(time.Minute), PCM: make([]byte, 32000), SampleRate: 16000}
+	outside := transcribe.Gap{Start: start.Add(2 * time.Hour), PCM: make([]byte, 64000), SampleRate: 16000}
+	for _, gap := range []transcribe.Gap{inSession, outside} {
+		if err := manager.RecoverGap(context.Background(), gap); err != nil {
+			t.Fatalf("expected the gap queued, got %v", err)
+		}
+	}
+	if names, _ := manager.gapQueue.pending(); len(names) != 2 {
+		t.Fatalf("expected two queued gaps, got %v", names)
+	}
+
+	// Still offline: nothing is lost.
+	manager.retryQueuedGaps(context.Background())
+	if names, _ := manager.gapQueue.pending(); len(names) != 2 {
+		t.Fatalf("expected gaps kept while offline, got %v", names)
+	}
+
+	transcriber.mu.Lock()
+	transcriber.online = true
+	transcriber.mu.Unlock()
+	manager.retryQueuedGaps(context.Background())
+
+	if names, _ := manager.gapQueue.pending(); len(names) != 0 {
+		t.Fatalf("expected the queue drained, got %v", names)
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if segs := store.segments["s1"]; len(segs) != 1 || !segs[0].Timestamp.Equal(start.Add(time.Minute+time.Second)) {
+		t.Fatalf("expected the gap spliced into s1, got %#v", segs)
+	}
+	if !strings.Contains(store.summary["s1"], "offline words") {
+		t.Fatalf("expected s1 summarized again with the recovered words, got %q", store.summary["s1"])
+	}
+	id := start.Add(2 * time.Hour).Format(IDLayout)
+	if _, ok := store.sessions[id]; !ok || store.audio[id] != "data/audio/"+id+".flac" || store.audioKind[id] != storage.AudioGap {
+		t.Fatalf("expected a finished session for the gap outside any session, got audio %q (%s)", store.audio[id], store.audioKind[id])
+	}
+	if len(saved) != 1 || saved[0] != id {
+		t.Fatalf("expected its audio kept, got %v", saved)
+	}
+	if store.summary[id] == "" {
+		t.Fatal("expected the recovered session summarized")
+	}
+}
+
+func TestManager_QueuedGapsOfLockedSessionsAreDropped(t *testing.T) {
+	store := storage.NewMemoryStore()
+	speaker := 0
+	transcriber := &flakyTranscriber{words: []transcribe.Word{{Speaker: &speaker, PunctuatedWord: "late", Start: 1, End: 1.5}}}
+	manager := NewManager(store, nil, summarizerMock{}, nil, NewDetector(time.Hour),
+		WithGapTranscriber(transcriber),
+		WithGapQueue(t.TempDir(), time.Minute, nil),
+	)
+
+	start := time.Date(2026, 2, 26, 10, 0, 0, 0, time.UTC)
+	if err := store.CreateSession("s1", start); err != nil {
+		t.Fatal(err)
+	}
+	gap := transcribe.Gap{SessionID: "s1", Start: start.Add(time.Minute), PCM: make([]byte, 32000), SampleRate: 16000}
+	if err := manager.RecoverGap(context.Background(), gap); err != nil {
+		t.Fatalf("expected the gap queued, got %v", err)
+	}
+	if err := store.SetSessionLocked("s1", true); err != nil {
+		t.Fatal(err)
+	}
+
+	transcriber.mu.Lock()
+	transcriber.online = true
+	transcriber.mu.Unlock()
+	manager.retryQueuedGaps(context.Background())
+
+	if names, _ := manager.gapQueue.pending(); len(names) != 0 {
+		t.Fatalf("expected the gap dropped rather than retried forever, got %v", names)
+	}
+	if segs, _ := store.GetSegments("s1"); len(segs) != 0 {
+		t.Fatalf("expected the locked transcript unchanged, got %+v", segs)
+	}
+}
+
+func TestManager_QueuedGapsOfOneOutageBecomeOneSession(t *testing.T) {
+	store := newStoreMock()
+	speaker := 0
+	transcriber := &flakyTranscriber{words: []transcribe.Word{{Speaker: &speaker, PunctuatedWord: "still", Start: 0.5, End: 1}}}
+	var saved []int
+	manager := NewManager(store, nil, summarizerMock{}, nil, NewDetector(time.Hour),
+		WithGapTranscriber(transcriber),
+		WithGapQueue(t.TempDir(), time.Minute, func(sessionID string, gap transcribe.Gap) (string, error) {
+			saved = append(saved, len(gap.PCM))
+			return "data/audio/" + sessionID + ".flac", nil
+		}),
+	)
+
+	// A long outage handed on in two pieces, then another an hour later.
+	start := time.Date(2026, 2, 26, 10, 0, 0, 0, time.UTC)
+	gaps := []transcribe.Gap{
+		{Start: start, PCM: make([]byte, 64000), SampleRate: 16000},
+		{Start: start.Add(2 * time.Second), PCM: make([]byte, 32000), SampleRate: 16000},
+		{Start: start.Add(time.Hour), PCM: make([]byte, 32000), SampleRate: 16000},
+	}
+	for _, gap := range gaps {
+		if err := manager.RecoverGap(context.Background(), gap); err != nil {
+			t.Fatalf("expected the gap queued, got %v", err)
+		}
+	}
+	transcriber.mu.Lock()
+	transcriber.online = true
+	transcriber.mu.Unlock()
+	manager.retryQueuedGaps(context.Background())
+
+	if names, _ := manager.gapQueue.pending(); len(names) != 0 {
+		t.Fatalf("expected the queue drained, got %v", names)
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	first, later := start.Format(IDLayout), start.Add(time.Hour).Format(IDLayout)
+	if len(store.sessions) != 2 {
+		t.Fatalf("expected one session per outage, got %v", store.sessions)
+	}
+	segs := store.segments[first]
+	if len(segs) != 1 || segs[0].Text != "still still" || len(segs[0].Words) != 2 || segs[0].Words[1].Start != 2.5 {
+		t.Fatalf("expected both pieces in the first session, the second timed after the first, got %#v", segs)
+	}
+	if len(store.segments[later]) != 1 {
+		t.Fatalf("expected the later outage in a session of its own, got %#v", store.segments[later])
+	}
+	if !slices.Equal(saved, []int{96000, 32000}) {
+		t.Fatalf("expected the pieces' audio kept joined, got %v", saved)
+	}
+}
@@ -0,0 +1,28 @@
+package session
+
+import (
+	"github.com/sjawhar/ghost-wispr/internal/redact"
+	"github.com/sjawhar/ghost-wispr/internal/transcribe"
+)
+
+// WithRedaction masks details such as emails and phone numbers in every
+// transcript, live, recovered or imported, before it is broadcast, stored,
+// handed to the segment processor or summarized.
+func WithRedaction(redactor *redact.Redactor) Option {
+	return func(m *Manager) {
+		m.redactor = redactor
+	}
+}
+
+// redactSegments masks segments' text, and their words, in place. A
+// segment with a detail spread over several words, such as a phone number,
+// loses its word timings rather than keep the detail in them.
+func (m *Manager) redactSegments(segments []transcribe.Segment) {
+	for i := range segments {
+		segments[i].Text = m.redactor.Redact(segments[i].Text)
+		for j := range segments[i].Words {
+			segments[i].Words[j].Word = m.redactor.Redact(segments[i].Words[j].Word)
+		}
+		segments[i].DropStaleWords()
+	}
+}
@@ -0,0 +1,108 @@
+package session
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/sjawhar/ghost-wispr/internal/transcribe"
+)
+
+// segmentQueueLimit is how many segments may wait for the segment processor.
+// Segments arriving behind a longer backlog are stored as they are, so a
+// slow script costs its own segments, not the live transcript.
+const segmentQueueLimit = 32
+
+// segmentQueue runs final segments through the segment processor on its own
+// goroutine, off the transcription callback, and stores them in the order
+// they were heard.
+type segmentQueue struct {
+	process func(ctx context.Context, seg transcribe.Segment) (transcribe.Segment, bool)
+	store   func(seg transcribe.Segment) error
+
+	mu      sync.Mutex
+	items   []transcribe.Segment
+	running bool
+	// idle is closed once the queue empties.
+	idle chan struct{}
+	// hurry skips processing for the rest of the backlog while draining
+	// against a deadline; cancel stops the call in progress.
+	hurry  bool
+	cancel context.CancelFunc
+}
+
+func newSegmentQueue(process func(context.Context, transcribe.Segment) (transcribe.Segment, bool), store func(transcribe.Segment) error) *segmentQueue {
+	return &segmentQueue{process: process, store: store}
+}
+
+// push queues seg to be processed and stored.
+func (q *segmentQueue) push(seg transcribe.Segment) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.items = append(q.items, seg)
+	if q.running {
+		return
+	}
+	q.running = true
+	q.idle = make(chan struct{})
+	go q.run()
+}
+
+func (q *segmentQueue) run() {
+	for {
+		q.mu.Lock()
+		if len(q.items) == 0 {
+			q.running = false
+			q.hurry = false
+			q.cancel = nil
+			close(q.idle)
+			q.mu.Unlock()
+			return
+		}
+		seg := q.items[0]
+		q.items = q.items[1:]
+		skip := q.hurry || len(q.items) >= segmentQueueLimit
+		ctx, cancel := context.WithCancel(context.Background())
+		q.cancel = cancel
+		q.mu.Unlock()
+
+		keep := true
+		if skip {
+			slog.Warn("segment processor is behind, storing segment unprocessed")
+		} else {
+			seg, keep = q.process(ctx, seg)
+		}
+		cancel()
+		if !keep {
+			continue
+		}
+		if err := q.store(seg); err != nil {
+			slog.Warn("storing processed segment failed", "error", err)
+		}
+	}
+}
+
+// drain waits for the queued segments to be stored. Once ctx is done, the
+// call in progress is cancelled and the rest are stored unprocessed.
+func (q *segmentQueue) drain(ctx context.Context) {
+	q.mu.Lock()
+	if !q.running {
+		q.mu.Unlock()
+		return
+	}
+	idle := q.idle
+	q.mu.Unlock()
+
+	select {
+	case <-idle:
+		return
+	case <-ctx.Done():
+	}
+	q.mu.Lock()
+	q.hurry = true
+	if q.cancel != nil {
+		q.cancel()
+	}
+	q.mu.Unlock()
+	<-idle
+}
@@ -0,0 +1,95 @@
+package session
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+)
+
+// SeriesStore is the storage needed to thread recurring sessions together.
+type SeriesStore interface {
+	GetSessionsByDate(date string) ([]storage.Session, error)
+	SetSessionSeries(sessionID, seriesID string) error
+}
+
+// SeriesDetector threads a finished session into a series when an earlier
+// session was held in the same weekly slot: the same weekday and local start
+// time, give or take Tolerance, within the last LookbackWeeks weeks. Sessions
+// shorter than MinDuration are never threaded, so a stray cough at 10:00 on
+// a Monday doesn't start a series.
+type SeriesDetector struct {
+	Store         SeriesStore
+	Tolerance     time.Duration
+	LookbackWeeks int
+	MinDuration   time.Duration
+}
+
+// Assign finds the series for a finished session and records it on both the
+// session and the earlier one it matched. It returns the series ID, or ""
+// when the session is not part of a series.
+func (d *SeriesDetector) Assign(sess storage.Session) (string, error) {
+	if !d.longEnough(sess) {
+		return "", nil
+	}
+
+	for week := 1; week <= d.LookbackWeeks; week++ {
+		// AddDate on local time keeps the wall-clock slot across DST changes.
+		target := sess.StartedAt.Local().AddDate(0, 0, -7*week)
+		match, ok, err := d.closestInSlot(sess.ID, target)
+		if err != nil {
+			return "", err
+		}
+		if !ok {
+			continue
+		}
+
+		seriesID := match.SeriesID
+		if seriesID == "" {
+			seriesID = "series-" + match.ID
+			if err := d.Store.SetSessionSeries(match.ID, seriesID); err != nil {
+				return "", fmt.Errorf("thread session %s into series: %w", match.ID, err)
+			}
+		}
+		if err := d.Store.SetSessionSeries(sess.ID, seriesID); err != nil {
+			return "", fmt.Errorf("thread session %s into series: %w", sess.ID, err)
+		}
+		return seriesID, nil
+	}
+	return "", nil
+}
+
+// closestInSlot returns the qualifying session that started nearest target.
+func (d *SeriesDetector) closestInSlot(excludeID string, target time.Time) (storage.Session, bool, error) {
+	var best storage.Session
+	var bestDiff time.Duration
+	found := false
+
+	seen := map[string]bool{}
+	for _, t := range []time.Time{target.Add(-d.Tolerance), target.Add(d.Tolerance)} {
+		date := t.UTC().Format("2006-01-02")
+		if seen[date] {
+			continue
+		}
+		seen[date] = true
+
+		sessions, err := d.Store.GetSessionsByDate(date)
+		if err != nil {
+			return storage.Session{}, false, fmt.Errorf("list sessions for %s: %w", date, err)
+		}
+		for _, candidate := range sessions {
+			diff := candidate.StartedAt.Sub(target).Abs()
+			if candidate.ID == excludeID || diff > d.Tolerance || !d.longEnough(candidate) {
+				continue
+			}
+			if !found || diff < bestDiff {
+				best, bestDiff, found = candidate, diff, true
+			}
+		}
+	}
+	return best, found, nil
+}
+
+func (d *SeriesDetector) longEnough(sess storage.Session) bool {
+	return sess.EndedAt != nil && sess.EndedAt.Sub(sess.StartedAt) >= d.MinDuration
+}
@@ -0,0 +1,78 @@
+package session
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+)
+
+func addEndedSession(t *testing.T, store *storage.MemoryStore, id string, start time.Time, length time.Duration) storage.Session {
+	t.Helper()
+	if err := store.CreateSession(id, start); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.EndSession(id, start.Add(length), ""); err != nil {
+		t.Fatal(err)
+	}
+	sess, err := store.GetSession(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sess
+}
+
+func TestSeriesDetectorThreadsWeeklySlot(t *testing.T) {
+	store := storage.NewMemoryStore()
+	detector := &SeriesDetector{Store: store, Tolerance: 20 * time.Minute, LookbackWeeks: 4, MinDuration: 5 * time.Minute}
+
+	monday := time.Date(2026, 3, 2, 10, 0, 0, 0, time.Local)
+	addEndedSession(t, store, "w1", monday, 30*time.Minute)
+	addEndedSession(t, store, "w1-cough", monday.Add(5*time.Minute), time.Minute)
+	addEndedSession(t, store, "tuesday", monday.AddDate(0, 0, 8), 30*time.Minute)
+
+	// Skipping a week still threads into the series.
+	w3 := addEndedSession(t, store, "w3", monday.AddDate(0, 0, 14).Add(10*time.Minute), 25*time.Minute)
+	seriesID, err := detector.Assign(w3)
+	if err != nil {
+		t.Fatalf("Assign failed: %v", err)
+	}
+	if seriesID != "series-w1" {
+		t.Fatalf("expected series-w1, got %q", seriesID)
+	}
+
+	w4 := addEndedSession(t, store, "w4", monday.AddDate(0, 0, 21).Add(-5*time.Minute), 40*time.Minute)
+	if seriesID, err = detector.Assign(w4); err != nil || seriesID != "series-w1" {
+		t.Fatalf("expected w4 to join series-w1, got %q (%v)", seriesID, err)
+	}
+
+	sessions, err := store.GetSeriesSessions("series-w1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, s := range sessions {
+		ids = append(ids, s.ID)
+	}
+	if len(ids) != 3 || ids[0] != "w1" || ids[1] != "w3" || ids[2] != "w4" {
+		t.Fatalf("unexpected series sessions %v", ids)
+	}
+}
+
+func TestSeriesDetectorIgnoresOtherSlotsAndShortSessions(t *testing.T) {
+	store := storage.NewMemoryStore()
+	detector := &SeriesDetector{Store: store, Tolerance: 20 * time.Minute, LookbackWeeks: 4, MinDuration: 5 * time.Minute}
+
+	monday := time.Date(2026, 3, 2, 10, 0, 0, 0, time.Local)
+	addEndedSession(t, store, "early", monday.Add(-time.Hour), 30*time.Minute)
+
+	next := addEndedSession(t, store, "next", monday.AddDate(0, 0, 7), 30*time.Minute)
+	if seriesID, err := detector.Assign(next); err != nil || seriesID != "" {
+		t.Fatalf("expected no series for a different slot, got %q (%v)", seriesID, err)
+	}
+
+	short := addEndedSession(t, store, "short", monday.AddDate(0, 0, 14).Add(-time.Hour), time.Minute)
+	if seriesID, err := detector.Assign(short); err != nil || seriesID != "" {
+		t.Fatalf("expected no series for a short session, got %q (%v)", seriesID, err)
+	}
+}
@@ -0,0 +1,88 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/sjawhar/ghost-wispr/internal/transcribe"
+)
+
+type speakerNamesKey struct{}
+
+// WithSpeakerNames returns a context in which summaries label transcript
+// lines with these names, keyed by speaker index, and list them as the
+// document's participants. Speakers without a name keep "Speaker N".
+func WithSpeakerNames(ctx context.Context, names map[int]string) context.Context {
+	if len(names) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, speakerNamesKey{}, names)
+}
+
+// SpeakerNames returns the names set by WithSpeakerNames, if any.
+func SpeakerNames(ctx context.Context) map[int]string {
+	names, _ := ctx.Value(speakerNamesKey{}).(map[int]string)
+	return names
+}
+
+// SpeakerNameStore keeps the names given to sessions' speakers.
+type SpeakerNameStore interface {
+	GetSpeakerNames(sessionID string) (map[int]string, error)
+}
+
+// WithStoredSpeakerNames has summaries use the names saved for a session's
+// speakers, unless a resummarize asks for others with WithSpeakerNames.
+func WithStoredSpeakerNames(store SpeakerNameStore) Option {
+	return func(m *Manager) {
+		m.speakerNames = store
+	}
+}
+
+// summarySpeakerNames returns the names to summarize a session with: those
+// saved for it, overridden speaker by speaker by any in ctx.
+func (m *Manager) summarySpeakerNames(ctx context.Context, sessionID string) map[int]string {
+	override := SpeakerNames(ctx)
+	if m.speakerNames == nil {
+		return override
+	}
+	names, err := m.speakerNames.GetSpeakerNames(sessionID)
+	if err != nil {
+		slog.Warn("loading speaker names failed", "session", sessionID, "error", err)
+		return override
+	}
+	if names == nil {
+		names = make(map[int]string, len(override))
+	}
+	for speaker, name := range override {
+		names[speaker] = name
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	return names
+}
+
+func speakerLabel(names map[int]string, speaker int) string {
+	if name := strings.TrimSpace(names[speaker]); name != "" {
+		return name
+	}
+	return fmt.Sprintf("Speaker %d", speaker)
+}
+
+// buildNamedTranscript is buildTranscript with each line prefixed by who
+// said it.
+func buildNamedTranscript(segments []transcribe.Segment, names map[int]string) string {
+	var b strings.Builder
+	for _, segment := range segments {
+		if strings.TrimSpace(segment.Text) == "" {
+			continue
+		}
+		b.WriteString(speakerLabel(names, segment.Speaker))
+		b.WriteString(": ")
+		b.WriteString(segment.Text)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,192 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+)
+
+// ErrSummaryCancelled is returned by a summary that was cancelled while it
+// waited or ran.
+var ErrSummaryCancelled = errors.New("summary cancelled")
+
+// ErrNoSummaryJob is returned by CancelSummary when the session has no
+// summary queued or running.
+var ErrNoSummaryJob = errors.New("no summary queued or running for session")
+
+// defaultSummaryDuration is how long a summary is assumed to take until one
+// has been timed.
+const defaultSummaryDuration = 30 * time.Second
+
+// SummaryJob is a summary waiting for a summary worker or running on one.
+type SummaryJob struct {
+	SessionID string `json:"session_id"`
+	Preset    string `json:"preset,omitempty"`
+	// Status is storage.SummaryPending while the job waits for a worker and
+	// storage.SummaryRunning once it has one.
+	Status string `json:"status"`
+	// Attempt counts the tries at this session's summary, including
+	// retries after the network came back.
+	Attempt   int        `json:"attempt"`
+	QueuedAt  time.Time  `json:"queued_at"`
+	StartedAt *time.Time `json:"started_at,omitempty"`
+	// ETA is when the job is expected to finish, from how long recent
+	// summaries took and the jobs ahead of it.
+	ETA time.Time `json:"eta"`
+}
+
+type summaryJob struct {
+	SummaryJob
+	seq       int64
+	cancel    context.CancelFunc
+	cancelled bool
+	// started is when a worker took the job, on the monotonic clock.
+	started time.Time
+}
+
+// queueSummary registers a summary of sessionID as waiting for a worker,
+// returning the context it runs under, which CancelSummary cancels.
+func (m *Manager) queueSummary(ctx context.Context, sessionID, preset string) (context.Context, *summaryJob) {
+	ctx, cancel := context.WithCancel(ctx)
+	m.jobsMu.Lock()
+	defer m.jobsMu.Unlock()
+	if m.summaryJobs == nil {
+		m.summaryJobs = make(map[int64]*summaryJob)
+		m.summaryAttempts = make(map[string]int)
+	}
+	m.summaryAttempts[sessionID]++
+	m.jobSeq++
+	job := &summaryJob{
+		SummaryJob: SummaryJob{
+			SessionID: sessionID,
+			Preset:    preset,
+			Status:    storage.SummaryPending,
+			Attempt:   m.summaryAttempts[sessionID],
+			QueuedAt:  time.Now().UTC(),
+		},
+		seq:    m.jobSeq,
+		cancel: cancel,
+	}
+	m.summaryJobs[job.seq] = job
+	return ctx, job
+}
+
+// startSummary marks a job as having a worker.
+func (m *Manager) startSummary(job *summaryJob) {
+	m.jobsMu.Lock()
+	defer m.jobsMu.Unlock()
+	job.started = time.Now()
+	now := job.started.UTC()
+	job.Status = storage.SummaryRunning
+	job.StartedAt = &now
+}
+
+// finishSummary drops a job from the queue. Successful runs refine the
+// duration estimate; the attempt count is kept only for a summary that
+// will be retried once the network returns.
+func (m *Manager) finishSummary(job *summaryJob, err error) {
+	job.cancel()
+	m.jobsMu.Lock()
+	defer m.jobsMu.Unlock()
+	delete(m.summaryJobs, job.seq)
+	if err == nil && job.StartedAt != nil {
+		took := time.Since(job.started)
+		if m.summaryDuration == 0 {
+			m.summaryDuration = took
+		} else {
+			m.summaryDuration = (3*m.summaryDuration + took) / 4
+		}
+	}
+	if err == nil || !(m.offlineProbe != nil && isConnectivityError(err)) {
+		delete(m.summaryAttempts, job.SessionID)
+	}
+}
+
+// summaryCancelled reports whether CancelSummary was called on job.
+func (m *Manager) summaryCancelled(job *summaryJob) bool {
+	m.jobsMu.Lock()
+	defer m.jobsMu.Unlock()
+	return job.cancelled
+}
+
+// markSummaryCancelled records that a session's summary was cancelled.
+func (m *Manager) markSummaryCancelled(sessionID, preset string) error {
+	_ = m.store.UpdateSummary(sessionID, "", storage.SummaryCancelled, preset)
+	m.broadcastSummaryStatus(sessionID, "", storage.SummaryCancelled, preset)
+	return ErrSummaryCancelled
+}
+
+// CancelSummary stops the session's queued or running summaries. A running
+// summary stops at its next provider call; its status becomes cancelled.
+func (m *Manager) CancelSummary(sessionID string) error {
+	m.jobsMu.Lock()
+	defer m.jobsMu.Unlock()
+	found := false
+	for _, job := range m.summaryJobs {
+		if job.SessionID == sessionID {
+			job.cancelled = true
+			job.cancel()
+			found = true
+		}
+	}
+	if !found {
+		return ErrNoSummaryJob
+	}
+	return nil
+}
+
+// SummaryQueue lists the running summaries, then the queued ones in the
+// order they will run, each with an estimated finish time.
+func (m *Manager) SummaryQueue() []SummaryJob {
+	m.jobsMu.Lock()
+	defer m.jobsMu.Unlock()
+
+	jobs := make([]*summaryJob, 0, len(m.summaryJobs))
+	for _, job := range m.summaryJobs {
+		jobs = append(jobs, job)
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		if ri, rj := jobs[i].StartedAt != nil, jobs[j].StartedAt != nil; ri != rj {
+			return ri
+		}
+		return jobs[i].seq < jobs[j].seq
+	})
+
+	took := m.summaryDuration
+	if took == 0 {
+		took = defaultSummaryDuration
+	}
+	workers := m.summaryPool.Stats().Workers
+	now := time.Now().UTC()
+
+	// free holds when each busy worker is expected to be free again.
+	var free []time.Time
+	out := make([]SummaryJob, 0, len(jobs))
+	for _, job := range jobs {
+		j := job.SummaryJob
+		if j.StartedAt != nil {
+			j.ETA = j.StartedAt.Add(took)
+			if j.ETA.Before(now) {
+				j.ETA = now
+			}
+			free = append(free, j.ETA)
+		} else if workers == 0 || len(free) < workers {
+			j.ETA = now.Add(took)
+			free = append(free, j.ETA)
+		} else {
+			next := 0
+			for i := range free {
+				if free[i].Before(free[next]) {
+					next = i
+				}
+			}
+			j.ETA = free[next].Add(took)
+			free[next] = j.ETA
+		}
+		out = append(out, j)
+	}
+	return out
+}
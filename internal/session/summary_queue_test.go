@@ -0,0 +1,80 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/jobs"
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+	"github.com/sjawhar/ghost-wispr/internal/transcribe"
+)
+
+func TestManager_SummaryQueueAndCancel(t *testing.T) {
+	store := newStoreMock()
+	pool := jobs.NewPool("summaries", 1)
+	manager := NewManager(store, nil, summarizerMock{}, nil, NewDetector(time.Hour), WithSummaryPool(pool))
+	for _, id := range []string{"s1", "s2"} {
+		if err := store.AppendSegment(id, transcribe.Segment{Text: "queued work"}); err != nil {
+			t.Fatalf("AppendSegment failed: %v", err)
+		}
+	}
+
+	release, err := pool.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+	done := map[string]chan error{"s1": make(chan error, 1), "s2": make(chan error, 1)}
+	go func() { done["s1"] <- manager.Resummarize(context.Background(), "s1", "detailed") }()
+	deadline := time.Now().Add(2 * time.Second)
+	for pool.Stats().Queued != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("expected s1 to wait for a worker")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	go func() { done["s2"] <- manager.Resummarize(context.Background(), "s2", "") }()
+	for pool.Stats().Queued != 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("expected s2 to wait for a worker")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	queue := manager.SummaryQueue()
+	if len(queue) != 2 || queue[0].SessionID != "s1" || queue[1].SessionID != "s2" {
+		t.Fatalf("expected s1 then s2 queued, got %+v", queue)
+	}
+	if queue[0].Status != storage.SummaryPending || queue[0].Preset != "detailed" || queue[0].Attempt != 1 {
+		t.Fatalf("unexpected queued job %+v", queue[0])
+	}
+	// One worker: s2 waits for s1 to finish.
+	if got := queue[1].ETA.Sub(queue[0].ETA); got != defaultSummaryDuration {
+		t.Fatalf("expected s2 due one summary after s1, got %s", got)
+	}
+
+	if err := manager.CancelSummary("s2"); err != nil {
+		t.Fatalf("CancelSummary failed: %v", err)
+	}
+	if err := <-done["s2"]; !errors.Is(err, ErrSummaryCancelled) {
+		t.Fatalf("expected ErrSummaryCancelled, got %v", err)
+	}
+	store.mu.Lock()
+	status := store.status["s2"]
+	store.mu.Unlock()
+	if status != storage.SummaryCancelled {
+		t.Fatalf("expected s2 cancelled, got %q", status)
+	}
+	if err := manager.CancelSummary("s2"); !errors.Is(err, ErrNoSummaryJob) {
+		t.Fatalf("expected ErrNoSummaryJob, got %v", err)
+	}
+
+	release()
+	if err := <-done["s1"]; err != nil {
+		t.Fatalf("Resummarize failed: %v", err)
+	}
+	if queue := manager.SummaryQueue(); len(queue) != 0 {
+		t.Fatalf("expected an empty queue, got %+v", queue)
+	}
+}
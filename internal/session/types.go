@@ -6,15 +6,36 @@ import (
 
 	api "github.com/deepgram/deepgram-go-sdk/v3/pkg/api/listen/v1/websocket/interfaces"
 
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+	"github.com/sjawhar/ghost-wispr/internal/summary"
 	"github.com/sjawhar/ghost-wispr/internal/transcribe"
 )
 
 type Store interface {
 	CreateSession(id string, startedAt time.Time) error
 	EndSession(id string, endedAt time.Time, audioPath string) error
+	MarkSessionRecovered(id string, endedAt time.Time, audioPath string) error
+	SetAudioStatus(id, status, audioPath string) error
+	AddAudioFile(sessionID, kind, path string) (storage.AudioFile, error)
 	AppendSegment(sessionID string, seg transcribe.Segment) error
 	GetSegments(sessionID string) ([]transcribe.Segment, error)
+	GetSession(id string) (storage.Session, error)
 	UpdateSummary(sessionID, summary, status, preset string) error
+	GetSessionsBySummaryStatus(status string) ([]storage.Session, error)
+	ReplaceDecisions(sessionID string, decisions []storage.Decision) error
+	ReplaceCitations(sessionID string, citations []storage.Citation) error
+	RecordTranscriptionUsage(sessionID string, duration time.Duration, cost float64) error
+	AddLLMUsage(sessionID string, inputTokens, outputTokens int, cost float64, latency time.Duration) error
+	SetSummaryAudioPath(sessionID, path string) error
+	SetTranscriptionModel(sessionID, model, language string) error
+	AddTranscriptionProvider(sessionID, provider string) error
+	SetSummaryModel(sessionID, model string) error
+	SetKeepWAV(sessionID string, keep bool) error
+	SetChannelSpeakers(sessionID string, channels bool) error
+	SetAudioOffset(sessionID string, offset float64) error
+	SetSessionClock(sessionID, bootID string, boot time.Duration) error
+	SetWAVPath(sessionID, path string) error
+	SetSessionKind(sessionID, kind string) error
 }
 
 type Recorder interface {
@@ -22,8 +43,49 @@ type Recorder interface {
 	EndSession() (string, error)
 }
 
+// AudioAligner is a Recorder that knows where, on the capture timeline
+// segment times are measured on, the recording it last started begins, and
+// where the audio captured so far ends.
+type AudioAligner interface {
+	AudioStart() float64
+	Captured() float64
+}
+
 type Summarizer interface {
 	Summarize(ctx context.Context, sessionID, transcript string) (summary, preset string, err error)
+	SummarizeWithPreset(ctx context.Context, sessionID, transcript, preset string) (string, error)
+}
+
+// SummaryEstimator projects what summarizing a transcript would cost
+// without calling a model.
+type SummaryEstimator interface {
+	EstimateSummary(ctx context.Context, transcript, preset string) (string, []summary.Estimate, error)
+}
+
+type DecisionExtractor interface {
+	ExtractDecisions(ctx context.Context, transcript string) ([]summary.Decision, error)
+}
+
+// SpeechSynthesizer renders a summary to an audio file at path.
+type SpeechSynthesizer interface {
+	Synthesize(ctx context.Context, text, path string) error
+	Ext() string
+}
+
+// SummaryRenderer turns a generated summary into the document that is
+// stored for the session.
+type SummaryRenderer interface {
+	Render(summary string, doc summary.Document) string
+}
+
+// SegmentProcessor rewrites or drops final segments before they are stored.
+type SegmentProcessor interface {
+	ProcessSegment(ctx context.Context, seg transcribe.Segment) (transcribe.Segment, bool, error)
+}
+
+// Dictator receives the text of final segments while dictating.
+type Dictator interface {
+	Dictate(text string)
 }
 
 type EventBroadcaster interface {
@@ -31,11 +93,19 @@ type EventBroadcaster interface {
 	BroadcastSessionStarted(sessionID string)
 	BroadcastSessionEnded(sessionID string, duration time.Duration)
 	BroadcastSummaryReady(sessionID, summary, status, preset string)
+	BroadcastAudioReady(sessionID, status, audioPath string)
 	BroadcastLiveTranscriptInterim(speaker int, text string, startTime float64)
 }
 
+// SummaryExporter delivers sessions whose summaries are ready to where
+// export rules send them.
+type SummaryExporter interface {
+	SummaryReady(sessionID string)
+}
+
 type LifecycleManager interface {
 	Message(mr *api.MessageResponse) error
 	UtteranceEnd(ur *api.UtteranceEndResponse) error
 	ForceEndSession(ctx context.Context) error
+	Resummarize(ctx context.Context, sessionID, preset string) error
 }
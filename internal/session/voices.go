@@ -0,0 +1,78 @@
+package session
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+)
+
+// VoiceIdentifier names the speakers of a session whose voices it
+// recognizes.
+type VoiceIdentifier interface {
+	IdentifySession(ctx context.Context, sessionID, audioPath string) (map[int]string, error)
+}
+
+// WithVoiceIdentification names the speakers of each finished session
+// whose voices are enrolled, before it is summarized, so the summary says
+// who said what. Summaries then wait for the session's audio to be encoded.
+func WithVoiceIdentification(identifier VoiceIdentifier) Option {
+	return func(m *Manager) {
+		m.voices = identifier
+		m.audioPending = make(map[string]chan struct{})
+	}
+}
+
+// identifyVoices names the recognized speakers of a session once its audio
+// is ready. A session that can't be identified is summarized all the same.
+func (m *Manager) identifyVoices(ctx context.Context, sessionID string) {
+	if m.voices == nil {
+		return
+	}
+	m.mu.Lock()
+	pending := m.audioPending[sessionID]
+	m.mu.Unlock()
+	if pending != nil {
+		select {
+		case <-pending:
+		case <-ctx.Done():
+			return
+		}
+	}
+
+	sess, err := m.store.GetSession(sessionID)
+	if err != nil {
+		slog.Warn("loading session for voice identification failed", "session", sessionID, "error", err)
+		return
+	}
+	if sess.AudioPath == "" || sess.AudioStatus == storage.AudioFailed {
+		return
+	}
+	if _, err := m.voices.IdentifySession(ctx, sessionID, sess.AudioPath); err != nil {
+		slog.Warn("voice identification failed", "session", sessionID, "error", err)
+	}
+}
+
+// trackPendingAudio notes that a session's audio is being encoded, for
+// identifyVoices to wait on.
+func (m *Manager) trackPendingAudio(sessionID string) {
+	if m.voices == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.audioPending[sessionID] = make(chan struct{})
+}
+
+// audioDone releases whoever waits on a session's audio.
+func (m *Manager) audioDone(sessionID string) {
+	if m.voices == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if pending, ok := m.audioPending[sessionID]; ok {
+		close(pending)
+		delete(m.audioPending, sessionID)
+	}
+}
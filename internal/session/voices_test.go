@@ -0,0 +1,60 @@
+package session
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type voiceIdentifierStub struct {
+	called chan string
+}
+
+func (v voiceIdentifierStub) IdentifySession(_ context.Context, sessionID, audioPath string) (map[int]string, error) {
+	v.called <- sessionID + " " + audioPath
+	return nil, nil
+}
+
+func TestManager_IdentifiesVoicesBeforeSummarizing(t *testing.T) {
+	store := newStoreMock()
+	rec := &asyncRecorderStub{release: make(chan struct{})}
+	summarized := make(chan string, 1)
+	identified := make(chan string, 1)
+	manager := NewManager(store, rec, summarizerMock{called: summarized}, nil, NewDetector(time.Hour),
+		WithVoiceIdentification(voiceIdentifierStub{called: identified}))
+
+	if err := manager.ensureSessionStarted(time.Now()); err != nil {
+		t.Fatalf("ensureSessionStarted failed: %v", err)
+	}
+	sessionID := manager.currentSession()
+	if err := manager.ForceEndSession(context.Background()); err != nil {
+		t.Fatalf("ForceEndSession failed: %v", err)
+	}
+
+	// Nothing happens until the audio is encoded.
+	select {
+	case got := <-identified:
+		t.Fatalf("expected identification to wait for the audio, got %q", got)
+	case got := <-summarized:
+		t.Fatalf("expected the summary to wait for identification, got %q", got)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(rec.release)
+	select {
+	case got := <-identified:
+		if want := sessionID + " data/audio/" + sessionID + ".mp3"; got != want {
+			t.Fatalf("expected %q identified, got %q", want, got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected voices identified once the audio was ready")
+	}
+	select {
+	case got := <-summarized:
+		if got != sessionID {
+			t.Fatalf("expected %s summarized, got %q", sessionID, got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the session summarized after identification")
+	}
+}
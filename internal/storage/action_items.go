@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ActionItem is a commitment recorded during a session, as extracted at
+// summarization time. Items still open at the end of a series meeting are
+// carried into the next one. Timestamp is the start time of the owning
+// session.
+type ActionItem struct {
+	ID        int64     `json:"id"`
+	SessionID string    `json:"session_id"`
+	Text      string    `json:"item"`
+	Owner     string    `json:"owner"`
+	Done      bool      `json:"done"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// ReplaceActionItems swaps the stored action items for a session, so that
+// resummarizing a session refreshes its items instead of duplicating them.
+func (s *SQLiteStore) ReplaceActionItems(sessionID string, items []ActionItem) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin replace action items for session %s: %w", sessionID, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec(`DELETE FROM action_items WHERE session_id = ?`, sessionID); err != nil {
+		return fmt.Errorf("delete action items for session %s: %w", sessionID, err)
+	}
+
+	var startedAt string
+	if err := tx.QueryRow(`SELECT started_at FROM sessions WHERE id = ?`, sessionID).Scan(&startedAt); err != nil {
+		return fmt.Errorf("query session %s: %w", sessionID, err)
+	}
+
+	for _, item := range items {
+		if _, err := tx.Exec(
+			`INSERT INTO action_items(session_id, text, owner, done, timestamp) VALUES(?, ?, ?, ?, ?)`,
+			sessionID,
+			strings.TrimSpace(item.Text),
+			strings.TrimSpace(item.Owner),
+			item.Done,
+			startedAt,
+		); err != nil {
+			return fmt.Errorf("insert action item for session %s: %w", sessionID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit action items for session %s: %w", sessionID, err)
+	}
+	return nil
+}
+
+// GetActionItems returns a session's action items in extraction order.
+func (s *SQLiteStore) GetActionItems(sessionID string) ([]ActionItem, error) {
+	rows, err := s.db.Query(
+		`SELECT id, session_id, text, owner, done, timestamp FROM action_items WHERE session_id = ? ORDER BY id ASC`,
+		sessionID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query action items for session %s: %w", sessionID, err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	items := make([]ActionItem, 0, 8)
+	for rows.Next() {
+		var item ActionItem
+		var ts string
+		if err := rows.Scan(&item.ID, &item.SessionID, &item.Text, &item.Owner, &item.Done, &ts); err != nil {
+			return nil, fmt.Errorf("scan action item: %w", err)
+		}
+		parsedTS, err := time.Parse(time.RFC3339Nano, ts)
+		if err != nil {
+			return nil, fmt.Errorf("parse action item timestamp: %w", err)
+		}
+		item.Timestamp = parsedTS
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate action item rows: %w", err)
+	}
+	return items, nil
+}
@@ -0,0 +1,47 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestActionItemStorage(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		start := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+		for _, id := range []string{"a", "b"} {
+			if err := store.CreateSession(id, start); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if err := store.ReplaceActionItems("a", []ActionItem{{Text: "stale"}}); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.ReplaceActionItems("a", []ActionItem{
+			{Text: " Send the deck ", Owner: "Alice"},
+			{Text: "Book the room", Done: true},
+		}); err != nil {
+			t.Fatalf("ReplaceActionItems failed: %v", err)
+		}
+		if err := store.ReplaceActionItems("b", []ActionItem{{Text: "Other"}}); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.ReplaceActionItems("missing", nil); err == nil {
+			t.Fatal("expected an error for a missing session")
+		}
+
+		items, err := store.GetActionItems("a")
+		if err != nil {
+			t.Fatalf("GetActionItems failed: %v", err)
+		}
+		if len(items) != 2 {
+			t.Fatalf("expected 2 items, got %+v", items)
+		}
+		if items[0].Text != "Send the deck" || items[0].Owner != "Alice" || items[0].Done || !items[0].Timestamp.Equal(start) {
+			t.Fatalf("unexpected first item %+v", items[0])
+		}
+		if items[1].Text != "Book the room" || !items[1].Done {
+			t.Fatalf("unexpected second item %+v", items[1])
+		}
+	})
+}
@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+)
+
+// Annotation kinds.
+const (
+	AnnotationComment    = "comment"
+	AnnotationReply      = "reply"
+	AnnotationSuggestion = "suggestion"
+)
+
+// Annotation is a collaborator's comment, reply or suggested edit on a
+// session's summary document, pulled back from where it was shared.
+type Annotation struct {
+	SessionID string `json:"session_id"`
+	// ExternalID identifies the annotation where it was made.
+	ExternalID string `json:"external_id"`
+	Kind       string `json:"kind"`
+	Author     string `json:"author,omitempty"`
+	// Text is the comment, or the text a suggestion inserts; Quote is the
+	// summary text it is anchored to, or that a suggestion deletes.
+	Text      string    `json:"text"`
+	Quote     string    `json:"quote,omitempty"`
+	Resolved  bool      `json:"resolved,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ReplaceAnnotations swaps the stored annotations for a session.
+func (s *SQLiteStore) ReplaceAnnotations(sessionID string, annotations []Annotation) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin replace annotations for session %s: %w", sessionID, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var exists int
+	if err := tx.QueryRow(`SELECT 1 FROM sessions WHERE id = ?`, sessionID).Scan(&exists); err != nil {
+		return fmt.Errorf("query session %s: %w", sessionID, err)
+	}
+	if _, err := tx.Exec(`DELETE FROM annotations WHERE session_id = ?`, sessionID); err != nil {
+		return fmt.Errorf("delete annotations for session %s: %w", sessionID, err)
+	}
+
+	for _, a := range annotations {
+		if _, err := tx.Exec(
+			`INSERT INTO annotations(session_id, external_id, kind, author, text, quote, resolved, created_at) VALUES(?, ?, ?, ?, ?, ?, ?, ?)`,
+			sessionID, a.ExternalID, a.Kind, a.Author, a.Text, a.Quote, a.Resolved, a.CreatedAt.UTC().Format(time.RFC3339Nano),
+		); err != nil {
+			return fmt.Errorf("insert annotation for session %s: %w", sessionID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit annotations for session %s: %w", sessionID, err)
+	}
+	return nil
+}
+
+// GetAnnotations returns a session's annotations, oldest first.
+func (s *SQLiteStore) GetAnnotations(sessionID string) ([]Annotation, error) {
+	rows, err := s.db.Query(
+		`SELECT session_id, external_id, kind, author, text, quote, resolved, created_at FROM annotations WHERE session_id = ? ORDER BY created_at ASC, id ASC`,
+		sessionID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query annotations for session %s: %w", sessionID, err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	annotations := make([]Annotation, 0, 4)
+	for rows.Next() {
+		var a Annotation
+		var createdAt string
+		if err := rows.Scan(&a.SessionID, &a.ExternalID, &a.Kind, &a.Author, &a.Text, &a.Quote, &a.Resolved, &createdAt); err != nil {
+			return nil, fmt.Errorf("scan annotation for session %s: %w", sessionID, err)
+		}
+		if a.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAt); err != nil {
+			return nil, fmt.Errorf("parse annotation timestamp for session %s: %w", sessionID, err)
+		}
+		annotations = append(annotations, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate annotation rows for session %s: %w", sessionID, err)
+	}
+	return annotations, nil
+}
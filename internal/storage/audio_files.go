@@ -0,0 +1,152 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Kinds of audio a session can have.
+const (
+	// AudioRecording is the session's own recording.
+	AudioRecording = "recording"
+	// AudioRecovered is a recording encoded after a crash interrupted it.
+	AudioRecovered = "recovered"
+	// AudioGap is audio captured while live transcription was down.
+	AudioGap = "gap"
+	// AudioImported is a recording made elsewhere, such as on a phone.
+	AudioImported = "imported"
+	// AudioClip is an excerpt cut from another recording.
+	AudioClip = "clip"
+)
+
+// AudioFile is one piece of audio belonging to a session. Index 0 is the
+// session's primary recording, which is also its AudioPath; other files are
+// numbered from 1 in the order they were added.
+type AudioFile struct {
+	Index     int       `json:"index"`
+	Kind      string    `json:"kind"`
+	Path      string    `json:"path"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// updateAudio runs query, an UPDATE of session id setting its audio_path
+// to path, and makes path its primary audio file of kind in the same
+// transaction, so the two never disagree. It returns sql.ErrNoRows when
+// the session doesn't exist.
+func (s *SQLiteStore) updateAudio(id, kind, path, query string, args ...any) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+	if rows, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	} else if rows == 0 {
+		return sql.ErrNoRows
+	}
+	if err := setPrimaryAudio(tx, id, kind, path); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// setPrimaryAudio makes path the session's primary audio file, or removes
+// it when path is empty. The session must exist.
+func setPrimaryAudio(tx *sql.Tx, sessionID, kind, path string) error {
+	if path == "" {
+		if _, err := tx.Exec(`DELETE FROM audio_files WHERE session_id = ? AND position = 0`, sessionID); err != nil {
+			return fmt.Errorf("clear primary audio: %w", err)
+		}
+		return nil
+	}
+	if _, err := tx.Exec(
+		`INSERT INTO audio_files(session_id, position, kind, path, created_at) VALUES(?, 0, ?, ?, ?)
+		 ON CONFLICT(session_id, position) DO UPDATE SET kind = excluded.kind, path = excluded.path, created_at = excluded.created_at`,
+		sessionID, kind, path, time.Now().UTC().Format(time.RFC3339Nano),
+	); err != nil {
+		return fmt.Errorf("set primary audio: %w", err)
+	}
+	return nil
+}
+
+// AddAudioFile attaches another audio file to a session after those it
+// already has, returning it with its index. Audio added while a session
+// records comes after the recording it will end with, but an ended session
+// without audio, such as one imported from elsewhere, gets it as its
+// primary recording at index 0, which also becomes its AudioPath.
+func (s *SQLiteStore) AddAudioFile(sessionID, kind, path string) (AudioFile, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return AudioFile{}, fmt.Errorf("begin add audio file for session %s: %w", sessionID, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var status string
+	if err := tx.QueryRow(`SELECT status FROM sessions WHERE id = ?`, sessionID).Scan(&status); err != nil {
+		return AudioFile{}, fmt.Errorf("query session %s: %w", sessionID, err)
+	}
+	file := AudioFile{Kind: kind, Path: path, CreatedAt: time.Now().UTC()}
+	var last sql.NullInt64
+	if err := tx.QueryRow(`SELECT MAX(position) FROM audio_files WHERE session_id = ?`, sessionID).Scan(&last); err != nil {
+		return AudioFile{}, fmt.Errorf("query audio files of session %s: %w", sessionID, err)
+	}
+	switch {
+	case last.Valid:
+		file.Index = int(last.Int64) + 1
+	case status == "ended":
+		file.Index = 0
+	default:
+		file.Index = 1
+	}
+	if file.Index == 0 {
+		if _, err := tx.Exec(`UPDATE sessions SET audio_path = ? WHERE id = ?`, path, sessionID); err != nil {
+			return AudioFile{}, fmt.Errorf("set audio path of session %s: %w", sessionID, err)
+		}
+	}
+	if _, err := tx.Exec(
+		`INSERT INTO audio_files(session_id, position, kind, path, created_at) VALUES(?, ?, ?, ?, ?)`,
+		sessionID, file.Index, kind, path, file.CreatedAt.Format(time.RFC3339Nano),
+	); err != nil {
+		return AudioFile{}, fmt.Errorf("insert audio file for session %s: %w", sessionID, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return AudioFile{}, fmt.Errorf("commit audio file for session %s: %w", sessionID, err)
+	}
+	return file, nil
+}
+
+// GetAudioFiles returns a session's audio files in index order.
+func (s *SQLiteStore) GetAudioFiles(sessionID string) ([]AudioFile, error) {
+	rows, err := s.db.Query(
+		`SELECT position, kind, path, created_at FROM audio_files WHERE session_id = ? ORDER BY position ASC`,
+		sessionID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query audio files for session %s: %w", sessionID, err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	files := make([]AudioFile, 0, 1)
+	for rows.Next() {
+		var f AudioFile
+		var createdAt string
+		if err := rows.Scan(&f.Index, &f.Kind, &f.Path, &createdAt); err != nil {
+			return nil, fmt.Errorf("scan audio file: %w", err)
+		}
+		if f.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAt); err != nil {
+			return nil, fmt.Errorf("parse audio file created_at: %w", err)
+		}
+		files = append(files, f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate audio file rows: %w", err)
+	}
+	return files, nil
+}
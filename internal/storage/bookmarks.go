@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Bookmark marks a moment in a session. Offset is where it was made on the
+// capture timeline the session's segments are timed on; it is
+// Offset-AudioOffset seconds into the session's recording.
+type Bookmark struct {
+	ID        int64     `json:"id"`
+	SessionID string    `json:"session_id"`
+	Offset    float64   `json:"offset"`
+	Note      string    `json:"note"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AddBookmark records a bookmark in a session at the given time, offset
+// seconds into the capture timeline.
+func (s *SQLiteStore) AddBookmark(sessionID string, at time.Time, offset float64, note string) (Bookmark, error) {
+	if _, err := s.GetSession(sessionID); err != nil {
+		return Bookmark{}, err
+	}
+
+	b := Bookmark{
+		SessionID: sessionID,
+		Offset:    max(offset, 0),
+		Note:      strings.TrimSpace(note),
+		CreatedAt: at.UTC(),
+	}
+	res, err := s.db.Exec(
+		`INSERT INTO bookmarks(session_id, offset_seconds, note, created_at, on_timeline) VALUES(?, ?, ?, ?, 1)`,
+		b.SessionID,
+		b.Offset,
+		b.Note,
+		b.CreatedAt.Format(time.RFC3339Nano),
+	)
+	if err != nil {
+		return Bookmark{}, fmt.Errorf("add bookmark for session %s: %w", sessionID, err)
+	}
+	if b.ID, err = res.LastInsertId(); err != nil {
+		return Bookmark{}, fmt.Errorf("add bookmark id: %w", err)
+	}
+	return b, nil
+}
+
+// GetBookmarks returns a session's bookmarks in the order they were made.
+func (s *SQLiteStore) GetBookmarks(sessionID string) ([]Bookmark, error) {
+	rows, err := s.db.Query(
+		`SELECT id, session_id, offset_seconds, note, created_at FROM bookmarks WHERE session_id = ? ORDER BY offset_seconds ASC, id ASC`,
+		sessionID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query bookmarks for session %s: %w", sessionID, err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	bookmarks := make([]Bookmark, 0, 4)
+	for rows.Next() {
+		var b Bookmark
+		var createdAt string
+		if err := rows.Scan(&b.ID, &b.SessionID, &b.Offset, &b.Note, &createdAt); err != nil {
+			return nil, fmt.Errorf("scan bookmark for session %s: %w", sessionID, err)
+		}
+		if b.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAt); err != nil {
+			return nil, fmt.Errorf("parse bookmark timestamp for session %s: %w", sessionID, err)
+		}
+		bookmarks = append(bookmarks, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate bookmark rows for session %s: %w", sessionID, err)
+	}
+	return bookmarks, nil
+}
@@ -0,0 +1,150 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Briefing is a pre-meeting brief generated for an upcoming calendar event
+// from related past sessions. EndsAt is nil when the event's end isn't
+// known. DeliveredAt is set once the brief has been announced ahead of the
+// meeting.
+type Briefing struct {
+	ID          string     `json:"id"`
+	Title       string     `json:"title"`
+	Attendees   []string   `json:"attendees"`
+	StartsAt    time.Time  `json:"starts_at"`
+	EndsAt      *time.Time `json:"ends_at,omitempty"`
+	Brief       string     `json:"brief"`
+	SessionIDs  []string   `json:"session_ids"`
+	CreatedAt   time.Time  `json:"created_at"`
+	DeliveredAt *time.Time `json:"delivered_at,omitempty"`
+}
+
+// CreateBriefing stores a new briefing.
+func (s *SQLiteStore) CreateBriefing(b Briefing) error {
+	attendees, err := json.Marshal(nonNil(b.Attendees))
+	if err != nil {
+		return fmt.Errorf("encode briefing attendees: %w", err)
+	}
+	sessionIDs, err := json.Marshal(nonNil(b.SessionIDs))
+	if err != nil {
+		return fmt.Errorf("encode briefing sessions: %w", err)
+	}
+	var endsAt any
+	if b.EndsAt != nil {
+		endsAt = b.EndsAt.UTC().Format(time.RFC3339Nano)
+	}
+	if _, err := s.db.Exec(
+		`INSERT INTO briefings(id, title, attendees, starts_at, ends_at, brief, session_ids, created_at) VALUES(?, ?, ?, ?, ?, ?, ?, ?)`,
+		b.ID,
+		b.Title,
+		string(attendees),
+		b.StartsAt.UTC().Format(time.RFC3339Nano),
+		endsAt,
+		b.Brief,
+		string(sessionIDs),
+		b.CreatedAt.UTC().Format(time.RFC3339Nano),
+	); err != nil {
+		return fmt.Errorf("insert briefing %s: %w", b.ID, err)
+	}
+	return nil
+}
+
+// GetBriefing returns a briefing by ID, or sql.ErrNoRows.
+func (s *SQLiteStore) GetBriefing(id string) (Briefing, error) {
+	rows, err := s.db.Query(`SELECT `+briefingColumns+` FROM briefings WHERE id = ?`, id)
+	if err != nil {
+		return Briefing{}, fmt.Errorf("query briefing %s: %w", id, err)
+	}
+	briefings, err := scanBriefings(rows)
+	if err != nil {
+		return Briefing{}, err
+	}
+	if len(briefings) == 0 {
+		return Briefing{}, fmt.Errorf("query briefing %s: %w", id, sql.ErrNoRows)
+	}
+	return briefings[0], nil
+}
+
+// ListBriefings returns every briefing, soonest meeting last.
+func (s *SQLiteStore) ListBriefings() ([]Briefing, error) {
+	rows, err := s.db.Query(`SELECT ` + briefingColumns + ` FROM briefings ORDER BY starts_at DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("query briefings: %w", err)
+	}
+	return scanBriefings(rows)
+}
+
+// MarkBriefingDelivered records when a briefing was announced.
+func (s *SQLiteStore) MarkBriefingDelivered(id string, at time.Time) error {
+	res, err := s.db.Exec(`UPDATE briefings SET delivered_at = ? WHERE id = ?`, at.UTC().Format(time.RFC3339Nano), id)
+	if err != nil {
+		return fmt.Errorf("mark briefing %s delivered: %w", id, err)
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("mark briefing rows affected: %w", err)
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+const briefingColumns = `id, title, attendees, starts_at, ends_at, brief, session_ids, created_at, delivered_at`
+
+func scanBriefings(rows *sql.Rows) ([]Briefing, error) {
+	defer func() { _ = rows.Close() }()
+
+	briefings := make([]Briefing, 0, 8)
+	for rows.Next() {
+		var b Briefing
+		var attendees, sessionIDs, startsAt, createdAt string
+		var endsAt, deliveredAt sql.NullString
+		if err := rows.Scan(&b.ID, &b.Title, &attendees, &startsAt, &endsAt, &b.Brief, &sessionIDs, &createdAt, &deliveredAt); err != nil {
+			return nil, fmt.Errorf("scan briefing: %w", err)
+		}
+		if err := json.Unmarshal([]byte(attendees), &b.Attendees); err != nil {
+			return nil, fmt.Errorf("decode briefing attendees: %w", err)
+		}
+		if err := json.Unmarshal([]byte(sessionIDs), &b.SessionIDs); err != nil {
+			return nil, fmt.Errorf("decode briefing sessions: %w", err)
+		}
+		var err error
+		if b.StartsAt, err = time.Parse(time.RFC3339Nano, startsAt); err != nil {
+			return nil, fmt.Errorf("parse briefing start: %w", err)
+		}
+		if endsAt.Valid {
+			at, err := time.Parse(time.RFC3339Nano, endsAt.String)
+			if err != nil {
+				return nil, fmt.Errorf("parse briefing end: %w", err)
+			}
+			b.EndsAt = &at
+		}
+		if b.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAt); err != nil {
+			return nil, fmt.Errorf("parse briefing creation: %w", err)
+		}
+		if deliveredAt.Valid {
+			at, err := time.Parse(time.RFC3339Nano, deliveredAt.String)
+			if err != nil {
+				return nil, fmt.Errorf("parse briefing delivery: %w", err)
+			}
+			b.DeliveredAt = &at
+		}
+		briefings = append(briefings, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate briefing rows: %w", err)
+	}
+	return briefings, nil
+}
+
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBriefingStorage(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		created := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+		ends := created.Add(90 * time.Minute)
+		for i, id := range []string{"b1", "b2"} {
+			b := Briefing{
+				ID:         id,
+				Title:      "Planning " + id,
+				Attendees:  []string{"Alice"},
+				StartsAt:   created.Add(time.Duration(i+1) * time.Hour),
+				Brief:      "brief " + id,
+				SessionIDs: []string{"s1"},
+				CreatedAt:  created,
+			}
+			if id == "b1" {
+				b.EndsAt = &ends
+			}
+			if err := store.CreateBriefing(b); err != nil {
+				t.Fatalf("CreateBriefing failed: %v", err)
+			}
+		}
+
+		briefings, err := store.ListBriefings()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(briefings) != 2 || briefings[0].ID != "b2" || briefings[0].EndsAt != nil || briefings[1].DeliveredAt != nil {
+			t.Fatalf("unexpected briefings %+v", briefings)
+		}
+
+		delivered := created.Add(30 * time.Minute)
+		if err := store.MarkBriefingDelivered("b1", delivered); err != nil {
+			t.Fatalf("MarkBriefingDelivered failed: %v", err)
+		}
+		b, err := store.GetBriefing("b1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b.DeliveredAt == nil || !b.DeliveredAt.Equal(delivered) || b.EndsAt == nil || !b.EndsAt.Equal(ends) || b.Attendees[0] != "Alice" || b.SessionIDs[0] != "s1" {
+			t.Fatalf("unexpected briefing %+v", b)
+		}
+
+		if _, err := store.GetBriefing("missing"); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("expected sql.ErrNoRows, got %v", err)
+		}
+		if err := store.MarkBriefingDelivered("missing", delivered); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("expected sql.ErrNoRows, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// AudioCalibration is the room tone measured on a capture device and the
+// speech threshold derived from it. Device is the capture device's name, ""
+// for the system default.
+type AudioCalibration struct {
+	Device       string    `json:"device"`
+	NoiseFloorDB float64   `json:"noise_floor_db"`
+	NoisePeakDB  float64   `json:"noise_peak_db"`
+	ThresholdDB  float64   `json:"threshold_db"`
+	Seconds      float64   `json:"seconds"`
+	CalibratedAt time.Time `json:"calibrated_at"`
+}
+
+// SaveAudioCalibration stores a device's calibration, replacing any earlier
+// one.
+func (s *SQLiteStore) SaveAudioCalibration(c AudioCalibration) error {
+	if _, err := s.db.Exec(
+		`INSERT INTO audio_calibrations(device, noise_floor_db, noise_peak_db, threshold_db, seconds, calibrated_at)
+		 VALUES(?, ?, ?, ?, ?, ?)
+		 ON CONFLICT(device) DO UPDATE SET
+			noise_floor_db = excluded.noise_floor_db,
+			noise_peak_db = excluded.noise_peak_db,
+			threshold_db = excluded.threshold_db,
+			seconds = excluded.seconds,
+			calibrated_at = excluded.calibrated_at`,
+		c.Device,
+		c.NoiseFloorDB,
+		c.NoisePeakDB,
+		c.ThresholdDB,
+		c.Seconds,
+		c.CalibratedAt.UTC().Format(time.RFC3339Nano),
+	); err != nil {
+		return fmt.Errorf("save calibration for device %q: %w", c.Device, err)
+	}
+	return nil
+}
+
+// GetAudioCalibration returns a device's calibration, or sql.ErrNoRows if it
+// has never been calibrated.
+func (s *SQLiteStore) GetAudioCalibration(device string) (AudioCalibration, error) {
+	c := AudioCalibration{Device: device}
+	var calibratedAt string
+	err := s.db.QueryRow(
+		`SELECT noise_floor_db, noise_peak_db, threshold_db, seconds, calibrated_at FROM audio_calibrations WHERE device = ?`,
+		device,
+	).Scan(&c.NoiseFloorDB, &c.NoisePeakDB, &c.ThresholdDB, &c.Seconds, &calibratedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return AudioCalibration{}, fmt.Errorf("query calibration for device %q: %w", device, sql.ErrNoRows)
+	}
+	if err != nil {
+		return AudioCalibration{}, fmt.Errorf("query calibration for device %q: %w", device, err)
+	}
+	if c.CalibratedAt, err = time.Parse(time.RFC3339Nano, calibratedAt); err != nil {
+		return AudioCalibration{}, fmt.Errorf("parse calibration time for device %q: %w", device, err)
+	}
+	return c, nil
+}
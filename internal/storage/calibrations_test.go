@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAudioCalibrationStorage(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		if _, err := store.GetAudioCalibration("USB Mic"); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("expected sql.ErrNoRows before calibrating, got %v", err)
+		}
+
+		at := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+		first := AudioCalibration{Device: "USB Mic", NoiseFloorDB: -62, NoisePeakDB: -55, ThresholdDB: -49, Seconds: 5, CalibratedAt: at}
+		if err := store.SaveAudioCalibration(first); err != nil {
+			t.Fatalf("SaveAudioCalibration failed: %v", err)
+		}
+		if err := store.SaveAudioCalibration(AudioCalibration{Device: "", NoiseFloorDB: -70, NoisePeakDB: -66, ThresholdDB: -60, CalibratedAt: at}); err != nil {
+			t.Fatalf("SaveAudioCalibration failed: %v", err)
+		}
+		second := first
+		second.ThresholdDB = -45
+		second.CalibratedAt = at.Add(time.Hour)
+		if err := store.SaveAudioCalibration(second); err != nil {
+			t.Fatalf("SaveAudioCalibration failed: %v", err)
+		}
+
+		got, err := store.GetAudioCalibration("USB Mic")
+		if err != nil {
+			t.Fatalf("GetAudioCalibration failed: %v", err)
+		}
+		if got.ThresholdDB != -45 || got.NoiseFloorDB != -62 || !got.CalibratedAt.Equal(second.CalibratedAt) {
+			t.Fatalf("expected the latest calibration, got %+v", got)
+		}
+		if got, err := store.GetAudioCalibration(""); err != nil || got.ThresholdDB != -60 {
+			t.Fatalf("expected the default device's own calibration, got %+v, %v", got, err)
+		}
+	})
+}
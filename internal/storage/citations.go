@@ -0,0 +1,75 @@
+package storage
+
+import "fmt"
+
+// Citation links a bullet of a session's summary to the transcript segments
+// supporting it, for jumping from the summary to the transcript. Segments
+// are identified by ID, so citations stay put when segments recovered from
+// a gap are inserted among them later.
+type Citation struct {
+	Bullet         int     `json:"bullet"`
+	Line           int     `json:"line"`
+	Text           string  `json:"text"`
+	FirstSegmentID int64   `json:"first_segment_id"`
+	LastSegmentID  int64   `json:"last_segment_id"`
+	StartTime      float64 `json:"start_time"`
+	EndTime        float64 `json:"end_time"`
+	Score          float64 `json:"score"`
+}
+
+// ReplaceCitations swaps the stored citations for a session, so that
+// resummarizing a session refreshes them instead of duplicating them.
+func (s *SQLiteStore) ReplaceCitations(sessionID string, citations []Citation) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin replace citations for session %s: %w", sessionID, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var exists int
+	if err := tx.QueryRow(`SELECT 1 FROM sessions WHERE id = ?`, sessionID).Scan(&exists); err != nil {
+		return fmt.Errorf("query session %s: %w", sessionID, err)
+	}
+	if _, err := tx.Exec(`DELETE FROM summary_citations WHERE session_id = ?`, sessionID); err != nil {
+		return fmt.Errorf("delete citations for session %s: %w", sessionID, err)
+	}
+
+	for _, c := range citations {
+		if _, err := tx.Exec(
+			`INSERT INTO summary_citations(session_id, bullet, line, text, first_segment_id, last_segment_id, start_time, end_time, score) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			sessionID, c.Bullet, c.Line, c.Text, c.FirstSegmentID, c.LastSegmentID, c.StartTime, c.EndTime, c.Score,
+		); err != nil {
+			return fmt.Errorf("insert citation for session %s: %w", sessionID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit citations for session %s: %w", sessionID, err)
+	}
+	return nil
+}
+
+// GetCitations returns a session's summary citations in bullet order.
+func (s *SQLiteStore) GetCitations(sessionID string) ([]Citation, error) {
+	rows, err := s.db.Query(
+		`SELECT bullet, line, text, first_segment_id, last_segment_id, start_time, end_time, score FROM summary_citations WHERE session_id = ? ORDER BY bullet ASC`,
+		sessionID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query citations for session %s: %w", sessionID, err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	citations := make([]Citation, 0, 8)
+	for rows.Next() {
+		var c Citation
+		if err := rows.Scan(&c.Bullet, &c.Line, &c.Text, &c.FirstSegmentID, &c.LastSegmentID, &c.StartTime, &c.EndTime, &c.Score); err != nil {
+			return nil, fmt.Errorf("scan citation: %w", err)
+		}
+		citations = append(citations, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate citation rows: %w", err)
+	}
+	return citations, nil
+}
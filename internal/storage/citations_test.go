@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/transcribe"
+)
+
+func TestCitations(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		if err := store.CreateSession("s1", time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.ReplaceCitations("s1", []Citation{
+			{Bullet: 2, Line: 4, Text: "Launch moves to March", FirstSegmentID: 3, LastSegmentID: 4, StartTime: 12, EndTime: 20, Score: 0.8},
+			{Bullet: 0, Line: 2, Text: "Billing slipped", FirstSegmentID: 1, LastSegmentID: 1, StartTime: 3, EndTime: 9, Score: 0.5},
+		}); err != nil {
+			t.Fatalf("ReplaceCitations failed: %v", err)
+		}
+
+		got, err := store.GetCitations("s1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 2 || got[0].Bullet != 0 || got[1].Text != "Launch moves to March" || got[1].LastSegmentID != 4 || got[1].EndTime != 20 {
+			t.Fatalf("unexpected citations %+v", got)
+		}
+
+		if err := store.ReplaceCitations("s1", nil); err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := store.GetCitations("s1"); len(got) != 0 {
+			t.Fatalf("expected citations replaced, got %+v", got)
+		}
+		if err := store.ReplaceCitations("missing", nil); err == nil {
+			t.Fatal("expected an error for a missing session")
+		}
+	})
+}
+
+func TestSegmentIDsSurviveGapSegments(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		start := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+		if err := store.CreateSession("s1", start); err != nil {
+			t.Fatal(err)
+		}
+		for i, text := range []string{"before the outage", "after the outage"} {
+			if err := store.AppendSegment("s1", transcribe.Segment{Text: text, Timestamp: start.Add(time.Duration(i*10) * time.Second)}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		before, _ := store.GetSegments("s1")
+		cited := before[1].ID
+		if cited == 0 || cited == before[0].ID {
+			t.Fatalf("expected distinct segment IDs, got %+v", before)
+		}
+
+		// A span transcribed after the outage lands between the two.
+		if err := store.AppendSegment("s1", transcribe.Segment{Text: "during the outage", Timestamp: start.Add(5 * time.Second)}); err != nil {
+			t.Fatal(err)
+		}
+		after, _ := store.GetSegments("s1")
+		if len(after) != 3 || after[2].Text != "after the outage" || after[2].ID != cited || after[1].ID == cited {
+			t.Fatalf("expected the cited segment to keep its ID after a gap, got %+v", after)
+		}
+	})
+}
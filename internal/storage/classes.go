@@ -0,0 +1,39 @@
+package storage
+
+import (
+	"fmt"
+)
+
+// Session kinds set when a finished session is classified: a meeting, or
+// ambient chatter picked up in passing, like a hallway conversation.
+const (
+	SessionKindMeeting = "meeting"
+	SessionKindAmbient = "ambient"
+)
+
+// DeleteSession removes a session and everything recorded about it. Its
+// audio files are left for the caller.
+func (s *SQLiteStore) DeleteSession(id string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin delete session %s: %w", id, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Everything else references sessions and goes with it.
+	if _, err := tx.Exec(`DELETE FROM summary_requests WHERE session_id = ?`, id); err != nil {
+		return fmt.Errorf("delete summary requests for session %s: %w", id, err)
+	}
+	res, err := tx.Exec(`DELETE FROM sessions WHERE id = ? AND locked = 0`, id)
+	if err != nil {
+		return fmt.Errorf("delete session %s: %w", id, err)
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("delete session rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("delete session %s: %w", id, lockedOrMissing(tx, id))
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/transcribe"
+)
+
+func TestDeleteSession(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		start := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+		for _, id := range []string{"chatter", "meeting"} {
+			if err := store.CreateSession(id, start); err != nil {
+				t.Fatal(err)
+			}
+			if err := store.AppendSegment(id, transcribe.Segment{Text: "hi", Timestamp: start}); err != nil {
+				t.Fatal(err)
+			}
+			if err := store.ReplaceDecisions(id, []Decision{{Text: "decided in " + id}}); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := store.AddBookmark(id, start, 0, ""); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := store.ClaimSummaryRequest("chatter", "hash"); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := store.DeleteSession("chatter"); err != nil {
+			t.Fatalf("DeleteSession failed: %v", err)
+		}
+		if _, err := store.GetSession("chatter"); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("expected the session gone, got %v", err)
+		}
+		if segs, _ := store.GetSegments("chatter"); len(segs) != 0 {
+			t.Fatalf("expected its segments gone, got %d", len(segs))
+		}
+		if marks, _ := store.GetBookmarks("chatter"); len(marks) != 0 {
+			t.Fatalf("expected its bookmarks gone, got %d", len(marks))
+		}
+		decisions, err := store.GetDecisions(DecisionFilter{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(decisions) != 1 || decisions[0].SessionID != "meeting" {
+			t.Fatalf("expected only the other session's decision left, got %+v", decisions)
+		}
+		// A session recreated under the same ID can be summarized afresh.
+		if err := store.CreateSession("chatter", start); err != nil {
+			t.Fatal(err)
+		}
+		if claimed, _ := store.ClaimSummaryRequest("chatter", "hash"); !claimed {
+			t.Fatal("expected the summary claim released")
+		}
+
+		if err := store.DeleteSession("missing"); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("expected sql.ErrNoRows for a missing session, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Decision is a decision recorded during a session, as extracted at
+// summarization time. Timestamp is the start time of the owning session.
+type Decision struct {
+	ID           int64     `json:"id"`
+	SessionID    string    `json:"session_id"`
+	Text         string    `json:"decision"`
+	Participants []string  `json:"participants"`
+	Timestamp    time.Time `json:"timestamp"`
+}
+
+// DecisionFilter narrows a decisions query. From and To are inclusive
+// YYYY-MM-DD dates; Query is a case-insensitive substring match on the text.
+type DecisionFilter struct {
+	From  string
+	To    string
+	Query string
+}
+
+// ReplaceDecisions swaps the stored decisions for a session, so that
+// resummarizing a session refreshes its decisions instead of duplicating them.
+func (s *SQLiteStore) ReplaceDecisions(sessionID string, decisions []Decision) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin replace decisions for session %s: %w", sessionID, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec(`DELETE FROM decisions WHERE session_id = ?`, sessionID); err != nil {
+		return fmt.Errorf("delete decisions for session %s: %w", sessionID, err)
+	}
+
+	var startedAt string
+	if err := tx.QueryRow(`SELECT started_at FROM sessions WHERE id = ?`, sessionID).Scan(&startedAt); err != nil {
+		return fmt.Errorf("query session %s: %w", sessionID, err)
+	}
+
+	for _, d := range decisions {
+		participants := d.Participants
+		if participants == nil {
+			participants = []string{}
+		}
+		encoded, err := json.Marshal(participants)
+		if err != nil {
+			return fmt.Errorf("encode decision participants: %w", err)
+		}
+		if _, err := tx.Exec(
+			`INSERT INTO decisions(session_id, text, participants, timestamp) VALUES(?, ?, ?, ?)`,
+			sessionID,
+			strings.TrimSpace(d.Text),
+			string(encoded),
+			startedAt,
+		); err != nil {
+			return fmt.Errorf("insert decision for session %s: %w", sessionID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit decisions for session %s: %w", sessionID, err)
+	}
+	return nil
+}
+
+// GetDecisions returns decisions matching the filter, newest session first.
+func (s *SQLiteStore) GetDecisions(filter DecisionFilter) ([]Decision, error) {
+	query := `SELECT id, session_id, text, participants, timestamp FROM decisions WHERE 1 = 1`
+	var args []any
+	if filter.From != "" {
+		query += ` AND substr(timestamp, 1, 10) >= ?`
+		args = append(args, filter.From)
+	}
+	if filter.To != "" {
+		query += ` AND substr(timestamp, 1, 10) <= ?`
+		args = append(args, filter.To)
+	}
+	if q := strings.TrimSpace(filter.Query); q != "" {
+		query += ` AND text LIKE ? ESCAPE '\'`
+		args = append(args, "%"+escapeLike(q)+"%")
+	}
+	query += ` ORDER BY timestamp DESC, id ASC`
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query decisions: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	decisions := make([]Decision, 0, 16)
+	for rows.Next() {
+		var d Decision
+		var participants, ts string
+		if err := rows.Scan(&d.ID, &d.SessionID, &d.Text, &participants, &ts); err != nil {
+			return nil, fmt.Errorf("scan decision: %w", err)
+		}
+		if err := json.Unmarshal([]byte(participants), &d.Participants); err != nil {
+			return nil, fmt.Errorf("decode decision participants: %w", err)
+		}
+		parsedTS, err := time.Parse(time.RFC3339Nano, ts)
+		if err != nil {
+			return nil, fmt.Errorf("parse decision timestamp: %w", err)
+		}
+		d.Timestamp = parsedTS
+		decisions = append(decisions, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate decision rows: %w", err)
+	}
+
+	return decisions, nil
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
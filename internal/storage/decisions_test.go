@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReplaceAndGetDecisions(t *testing.T) {
+	store := newTestSQLiteStore(t)
+
+	day1 := time.Date(2026, 2, 26, 10, 0, 0, 0, time.UTC)
+	day2 := time.Date(2026, 2, 27, 10, 0, 0, 0, time.UTC)
+	for _, started := range []time.Time{day1, day2} {
+		if err := store.CreateSession(started.Format("20060102150405"), started); err != nil {
+			t.Fatalf("CreateSession failed: %v", err)
+		}
+	}
+
+	if err := store.ReplaceDecisions("20260226100000", []Decision{
+		{Text: "Use SQLite", Participants: []string{"Alice"}},
+		{Text: "Defer the redesign"},
+	}); err != nil {
+		t.Fatalf("ReplaceDecisions failed: %v", err)
+	}
+	if err := store.ReplaceDecisions("20260227100000", []Decision{{Text: "Ship 100% of the release"}}); err != nil {
+		t.Fatalf("ReplaceDecisions failed: %v", err)
+	}
+
+	all, err := store.GetDecisions(DecisionFilter{})
+	if err != nil {
+		t.Fatalf("GetDecisions failed: %v", err)
+	}
+	if len(all) != 3 {
+		t.Fatalf("expected 3 decisions, got %d", len(all))
+	}
+	if all[0].SessionID != "20260227100000" {
+		t.Fatalf("expected newest session first, got %q", all[0].SessionID)
+	}
+	if !all[1].Timestamp.Equal(day1) {
+		t.Fatalf("expected decision timestamp to match session start, got %v", all[1].Timestamp)
+	}
+	if all[2].Participants == nil {
+		t.Fatal("expected empty participants slice, got nil")
+	}
+
+	filtered, err := store.GetDecisions(DecisionFilter{From: "2026-02-26", To: "2026-02-26", Query: "sqlite"})
+	if err != nil {
+		t.Fatalf("GetDecisions filtered failed: %v", err)
+	}
+	if len(filtered) != 1 || filtered[0].Text != "Use SQLite" {
+		t.Fatalf("unexpected filtered decisions %#v", filtered)
+	}
+
+	literal, err := store.GetDecisions(DecisionFilter{Query: "100%"})
+	if err != nil {
+		t.Fatalf("GetDecisions literal failed: %v", err)
+	}
+	if len(literal) != 1 {
+		t.Fatalf("expected %% to match literally, got %d decisions", len(literal))
+	}
+
+	if err := store.ReplaceDecisions("20260226100000", []Decision{{Text: "Use Postgres"}}); err != nil {
+		t.Fatalf("ReplaceDecisions failed: %v", err)
+	}
+	replaced, err := store.GetDecisions(DecisionFilter{From: "2026-02-26", To: "2026-02-26"})
+	if err != nil {
+		t.Fatalf("GetDecisions failed: %v", err)
+	}
+	if len(replaced) != 1 || replaced[0].Text != "Use Postgres" {
+		t.Fatalf("expected replaced decisions, got %#v", replaced)
+	}
+}
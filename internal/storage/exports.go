@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Export statuses, tracked per session and export rule.
+const (
+	ExportPending   = "pending"
+	ExportRunning   = "running"
+	ExportCompleted = "completed"
+	ExportFailed    = "failed"
+)
+
+// Export is where a configured export rule stands for one session.
+type Export struct {
+	SessionID string `json:"session_id"`
+	Rule      string `json:"rule"`
+	Target    string `json:"target"`
+	Status    string `json:"status"`
+	// Location is where the export landed: a file path, a Slack channel or
+	// a Google Doc ID.
+	Location  string    `json:"location,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	Attempts  int       `json:"attempts"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+const exportColumns = `session_id, rule, target, status, location, error, attempts, updated_at`
+
+// SetExport records where an export stands, replacing what was recorded
+// for the same session and rule.
+func (s *SQLiteStore) SetExport(e Export) error {
+	_, err := s.db.Exec(
+		`INSERT INTO exports(`+exportColumns+`) VALUES(?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(session_id, rule) DO UPDATE SET
+			target = excluded.target, status = excluded.status, location = excluded.location,
+			error = excluded.error, attempts = excluded.attempts, updated_at = excluded.updated_at`,
+		e.SessionID, e.Rule, e.Target, e.Status, e.Location, e.Error, e.Attempts, e.UpdatedAt.UTC().Format(time.RFC3339Nano),
+	)
+	if err != nil {
+		return fmt.Errorf("set %s export for session %s: %w", e.Rule, e.SessionID, err)
+	}
+	return nil
+}
+
+// GetExports returns a session's exports by rule name.
+func (s *SQLiteStore) GetExports(sessionID string) ([]Export, error) {
+	rows, err := s.db.Query(`SELECT `+exportColumns+` FROM exports WHERE session_id = ? ORDER BY rule`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("query exports for session %s: %w", sessionID, err)
+	}
+	return scanExports(rows)
+}
+
+// GetExportsByStatus returns the exports in status, oldest first.
+func (s *SQLiteStore) GetExportsByStatus(status string) ([]Export, error) {
+	rows, err := s.db.Query(`SELECT `+exportColumns+` FROM exports WHERE status = ? ORDER BY updated_at, session_id, rule`, status)
+	if err != nil {
+		return nil, fmt.Errorf("query %s exports: %w", status, err)
+	}
+	return scanExports(rows)
+}
+
+func scanExports(rows *sql.Rows) ([]Export, error) {
+	defer func() { _ = rows.Close() }()
+	exports := make([]Export, 0, 4)
+	for rows.Next() {
+		var e Export
+		var updatedAt string
+		if err := rows.Scan(&e.SessionID, &e.Rule, &e.Target, &e.Status, &e.Location, &e.Error, &e.Attempts, &updatedAt); err != nil {
+			return nil, fmt.Errorf("scan export: %w", err)
+		}
+		t, err := time.Parse(time.RFC3339Nano, updatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("parse export updated_at: %w", err)
+		}
+		e.UpdatedAt = t
+		exports = append(exports, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate exports: %w", err)
+	}
+	return exports, nil
+}
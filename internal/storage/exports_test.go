@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExportStorage(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+		for _, id := range []string{"s1", "s2"} {
+			if err := store.CreateSession(id, start); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		for _, e := range []Export{
+			{SessionID: "s1", Rule: "slack", Target: "slack", Status: ExportPending, UpdatedAt: start.Add(2 * time.Minute)},
+			{SessionID: "s1", Rule: "notes", Target: "markdown", Status: ExportPending, UpdatedAt: start.Add(time.Minute)},
+			{SessionID: "s2", Rule: "notes", Target: "markdown", Status: ExportPending, UpdatedAt: start.Add(3 * time.Minute)},
+		} {
+			if err := store.SetExport(e); err != nil {
+				t.Fatalf("SetExport failed: %v", err)
+			}
+		}
+		done := Export{SessionID: "s1", Rule: "notes", Target: "markdown", Status: ExportCompleted, Location: "/notes/s1.md", Attempts: 1, UpdatedAt: start.Add(4 * time.Minute)}
+		if err := store.SetExport(done); err != nil {
+			t.Fatalf("SetExport failed: %v", err)
+		}
+
+		exports, err := store.GetExports("s1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(exports) != 2 || exports[0] != done || exports[1].Rule != "slack" || exports[1].Status != ExportPending {
+			t.Fatalf("unexpected exports %+v", exports)
+		}
+
+		pending, err := store.GetExportsByStatus(ExportPending)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(pending) != 2 || pending[0].SessionID != "s1" || pending[1].SessionID != "s2" {
+			t.Fatalf("unexpected pending exports %+v", pending)
+		}
+
+		if err := store.DeleteSession("s1"); err != nil {
+			t.Fatal(err)
+		}
+		if exports, err := store.GetExports("s1"); err != nil || len(exports) != 0 {
+			t.Fatalf("expected exports deleted with session, got %+v, %v", exports, err)
+		}
+	})
+}
@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// ErrSessionLocked is returned when a locked session would be edited,
+// resummarized or deleted. Unlock it first.
+var ErrSessionLocked = errors.New("session is locked")
+
+// SetSessionLocked locks or unlocks a session. A locked session's
+// transcript, speakers and summary can't be changed, and it can't be
+// deleted, by hand or by retention.
+func (s *SQLiteStore) SetSessionLocked(sessionID string, locked bool) error {
+	res, err := s.db.Exec(`UPDATE sessions SET locked = ? WHERE id = ?`, locked, sessionID)
+	if err != nil {
+		return fmt.Errorf("update locked for session %s: %w", sessionID, err)
+	}
+	if rows, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("update locked rows affected: %w", err)
+	} else if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// rowQuerier is a *sql.DB or *sql.Tx.
+type rowQuerier interface {
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// checkUnlocked returns ErrSessionLocked if the session is locked and
+// sql.ErrNoRows if it doesn't exist.
+func checkUnlocked(q rowQuerier, sessionID string) error {
+	var locked bool
+	if err := q.QueryRow(`SELECT locked FROM sessions WHERE id = ?`, sessionID).Scan(&locked); err != nil {
+		return err
+	}
+	if locked {
+		return ErrSessionLocked
+	}
+	return nil
+}
+
+// lockedOrMissing explains why a write guarded by "locked = 0" changed
+// nothing: the session is locked, or doesn't exist.
+func lockedOrMissing(q rowQuerier, sessionID string) error {
+	if err := checkUnlocked(q, sessionID); err != nil {
+		return err
+	}
+	return sql.ErrNoRows
+}
+
+// SetSessionLocked locks or unlocks a session.
+func (s *MemoryStore) SetSessionLocked(sessionID string, locked bool) error {
+	return s.updateSession(sessionID, func(sess *Session) { sess.Locked = locked })
+}
+
+// unlockedSession returns the session to be changed, unless it is missing
+// or locked. Callers hold mu.
+func (s *MemoryStore) unlockedSession(id string) (*Session, error) {
+	sess, ok := s.sessions[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	if sess.Locked {
+		return nil, ErrSessionLocked
+	}
+	return sess, nil
+}
@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/transcribe"
+)
+
+func TestLockedSessionsRefuseChanges(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		start := time.Date(2026, 3, 4, 9, 0, 0, 0, time.UTC)
+		if err := store.CreateSession("s1", start); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.AppendSegment("s1", transcribe.Segment{Speaker: 0, Text: "Final numbers.", Timestamp: start}); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.EndSession("s1", start.Add(time.Minute), "s1.mp3"); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.UpdateSummary("s1", "Signed off.", SummaryCompleted, "default"); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.SetSessionLocked("s1", true); err != nil {
+			t.Fatalf("SetSessionLocked failed: %v", err)
+		}
+
+		if sess, err := store.GetSession("s1"); err != nil || !sess.Locked {
+			t.Fatalf("expected session locked, got %+v (%v)", sess, err)
+		}
+		if err := store.UpdateSummary("s1", "Rewritten.", SummaryCompleted, "default"); !errors.Is(err, ErrSessionLocked) {
+			t.Fatalf("expected UpdateSummary to fail with ErrSessionLocked, got %v", err)
+		}
+		if _, err := store.RelabelSegmentSpeakers("s1", []int{1}); !errors.Is(err, ErrSessionLocked) {
+			t.Fatalf("expected RelabelSegmentSpeakers to fail with ErrSessionLocked, got %v", err)
+		}
+		if err := store.DeleteSession("s1"); !errors.Is(err, ErrSessionLocked) {
+			t.Fatalf("expected DeleteSession to fail with ErrSessionLocked, got %v", err)
+		}
+		if err := store.AppendSegment("s1", transcribe.Segment{Text: "Late gap.", Timestamp: start}); !errors.Is(err, ErrSessionLocked) {
+			t.Fatalf("expected AppendSegment to fail with ErrSessionLocked, got %v", err)
+		}
+		if segments, _ := store.GetSegments("s1"); len(segments) != 1 {
+			t.Fatalf("expected the transcript unchanged, got %+v", segments)
+		}
+		if sessions, err := store.GetSessionsForRefinement(10); err != nil || len(sessions) != 0 {
+			t.Fatalf("expected locked session skipped by refinement, got %+v (%v)", sessions, err)
+		}
+		if sess, _ := store.GetSession("s1"); sess.Summary != "Signed off." {
+			t.Fatalf("expected summary unchanged, got %q", sess.Summary)
+		}
+
+		if err := store.SetSessionLocked("s1", false); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.UpdateSummary("s1", "Rewritten.", SummaryCompleted, "default"); err != nil {
+			t.Fatalf("expected unlocked session to be updated, got %v", err)
+		}
+		if err := store.DeleteSession("s1"); err != nil {
+			t.Fatalf("expected unlocked session to be deleted, got %v", err)
+		}
+
+		if err := store.SetSessionLocked("missing", true); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("expected sql.ErrNoRows for a missing session, got %v", err)
+		}
+		if err := store.UpdateSummary("missing", "", SummaryCompleted, ""); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("expected sql.ErrNoRows updating a missing session, got %v", err)
+		}
+	})
+}
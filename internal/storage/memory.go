@@ -0,0 +1,1014 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/transcribe"
+)
+
+// MemoryStore keeps everything in process memory with the same semantics as
+// SQLiteStore, including sql.ErrNoRows for missing sessions. Nothing
+// survives Close or a restart.
+type MemoryStore struct {
+	mu             sync.RWMutex
+	sessions       map[string]*Session
+	segments       map[string][]transcribe.Segment
+	lastSegmentID  int64
+	decisions      []Decision
+	nextDecisionID int64
+	actionItems    []ActionItem
+	nextActionID   int64
+	briefings      map[string]Briefing
+	citations      map[string][]Citation
+	annotations    map[string][]Annotation
+	audioFiles     map[string][]AudioFile
+	calibrations   map[string]AudioCalibration
+	speakerNames   map[string]map[int]string
+	voices         []VoiceProfile
+	reports        map[string]Report
+	nextVoiceID    int64
+	bookmarks      []Bookmark
+	nextBookmarkID int64
+	notes          []Note
+	nextNoteID     int64
+	exports        []Export
+	usage          map[string]*memoryUsage
+	claims         map[string]struct{}
+}
+
+type memoryUsage struct {
+	transcriptionSeconds float64
+	transcriptionCost    float64
+	llmInputTokens       int
+	llmOutputTokens      int
+	llmCost              float64
+	summaryLatencyMs     int64
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		sessions:     make(map[string]*Session),
+		segments:     make(map[string][]transcribe.Segment),
+		usage:        make(map[string]*memoryUsage),
+		claims:       make(map[string]struct{}),
+		briefings:    make(map[string]Briefing),
+		citations:    make(map[string][]Citation),
+		annotations:  make(map[string][]Annotation),
+		audioFiles:   make(map[string][]AudioFile),
+		calibrations: make(map[string]AudioCalibration),
+		speakerNames: make(map[string]map[int]string),
+		reports:      make(map[string]Report),
+	}
+}
+
+// Close discards all stored data.
+func (s *MemoryStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sessions = make(map[string]*Session)
+	s.segments = make(map[string][]transcribe.Segment)
+	s.decisions = nil
+	s.actionItems = nil
+	s.bookmarks = nil
+	s.notes = nil
+	s.exports = nil
+	s.voices = nil
+	s.usage = make(map[string]*memoryUsage)
+	s.claims = make(map[string]struct{})
+	s.briefings = make(map[string]Briefing)
+	s.citations = make(map[string][]Citation)
+	s.annotations = make(map[string][]Annotation)
+	s.audioFiles = make(map[string][]AudioFile)
+	s.calibrations = make(map[string]AudioCalibration)
+	s.speakerNames = make(map[string]map[int]string)
+	s.reports = make(map[string]Report)
+	return nil
+}
+
+func (s *MemoryStore) CreateSession(id string, startedAt time.Time) error {
+	if strings.TrimSpace(id) == "" {
+		return errors.New("session id is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.sessions[id]; ok {
+		return fmt.Errorf("create session %s: already exists", id)
+	}
+	s.sessions[id] = &Session{
+		ID:              id,
+		StartedAt:       startedAt.UTC(),
+		Status:          "active",
+		SummaryStatus:   SummaryPending,
+		SegmentsVersion: 1,
+	}
+	return nil
+}
+
+func (s *MemoryStore) EndSession(id string, endedAt time.Time, audioPath string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sess, ok := s.sessions[id]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	ended := endedAt.UTC()
+	sess.EndedAt = &ended
+	sess.Status = "ended"
+	sess.AudioPath = audioPath
+	s.setPrimaryAudio(id, AudioRecording, audioPath)
+	return nil
+}
+
+func (s *MemoryStore) SetAudioStatus(id, status, audioPath string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sess, ok := s.sessions[id]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	sess.AudioStatus = status
+	sess.AudioPath = audioPath
+	s.setPrimaryAudio(id, AudioRecording, audioPath)
+	return nil
+}
+
+func (s *MemoryStore) MarkSessionRecovered(id string, endedAt time.Time, audioPath string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sess, ok := s.sessions[id]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	if sess.EndedAt == nil {
+		ended := endedAt.UTC()
+		sess.EndedAt = &ended
+	}
+	sess.Status = "ended"
+	sess.AudioPath = audioPath
+	sess.AudioStatus = AudioReady
+	sess.Recovered = true
+	s.setPrimaryAudio(id, AudioRecovered, audioPath)
+	return nil
+}
+
+func (s *MemoryStore) AppendSegment(sessionID string, seg transcribe.Segment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.unlockedSession(sessionID); err != nil {
+		return fmt.Errorf("append segment for session %s: %w", sessionID, err)
+	}
+	seg.Text = strings.TrimSpace(seg.Text)
+	seg.Timestamp = seg.Timestamp.UTC()
+	seg.Words = slices.Clone(seg.Words)
+	s.lastSegmentID++
+	seg.ID = s.lastSegmentID
+	s.segments[sessionID] = append(s.segments[sessionID], seg)
+	return nil
+}
+
+func (s *MemoryStore) GetSessionsByDate(date string) ([]Session, error) {
+	sessions := s.filterSessions(func(sess *Session) bool {
+		return sess.StartedAt.Format("2006-01-02") == date
+	})
+	sort.SliceStable(sessions, func(i, j int) bool { return sessions[i].StartedAt.After(sessions[j].StartedAt) })
+	return sessions, nil
+}
+
+// GetSessionsBySummaryStatus returns sessions whose summary is in the given
+// status, oldest first.
+func (s *MemoryStore) GetSessionsBySummaryStatus(status string) ([]Session, error) {
+	sessions := s.filterSessions(func(sess *Session) bool { return sess.SummaryStatus == status })
+	sortOldestFirst(sessions)
+	return sessions, nil
+}
+
+// GetUnreviewedSessions returns sessions with a completed summary that
+// have never been viewed, newest first.
+func (s *MemoryStore) GetUnreviewedSessions() ([]Session, error) {
+	sessions := s.filterSessions(func(sess *Session) bool {
+		return sess.SummaryStatus == SummaryCompleted && sess.Summary != "" && sess.LastViewedAt == nil
+	})
+	sort.SliceStable(sessions, func(i, j int) bool { return sessions[i].StartedAt.After(sessions[j].StartedAt) })
+	return sessions, nil
+}
+
+func (s *MemoryStore) GetDates() ([]string, error) {
+	s.mu.RLock()
+	seen := make(map[string]struct{})
+	for _, sess := range s.sessions {
+		seen[sess.StartedAt.Format("2006-01-02")] = struct{}{}
+	}
+	s.mu.RUnlock()
+
+	var dates []string
+	for d := range seen {
+		dates = append(dates, d)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(dates)))
+	return dates, nil
+}
+
+func (s *MemoryStore) GetSession(id string) (Session, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sess, ok := s.sessions[id]
+	if !ok {
+		return Session{}, fmt.Errorf("query session %s: %w", id, sql.ErrNoRows)
+	}
+	return copySession(sess), nil
+}
+
+// GetSegments returns a session's segments in chronological order, so spans
+// recovered after a transcription outage interleave with live segments.
+func (s *MemoryStore) GetSegments(sessionID string) ([]transcribe.Segment, error) {
+	s.mu.RLock()
+	segments := append(make([]transcribe.Segment, 0, len(s.segments[sessionID])), s.segments[sessionID]...)
+	s.mu.RUnlock()
+
+	sort.SliceStable(segments, func(i, j int) bool { return segments[i].Timestamp.Before(segments[j].Timestamp) })
+	return segments, nil
+}
+
+func (s *MemoryStore) UpdateSummary(sessionID, summary, status, preset string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sess, err := s.unlockedSession(sessionID)
+	if err != nil {
+		return err
+	}
+	sess.Summary = summary
+	sess.SummaryStatus = status
+	sess.SummaryPreset = preset
+	return nil
+}
+
+// SetSummaryAudioPath records where the spoken summary for a session lives.
+func (s *MemoryStore) SetSummaryAudioPath(sessionID, path string) error {
+	return s.updateSession(sessionID, func(sess *Session) { sess.SummaryAudioPath = path })
+}
+
+// SetTranscriptionModel records the model and language a session's
+// transcript was made with.
+func (s *MemoryStore) SetTranscriptionModel(sessionID, model, language string) error {
+	return s.updateSession(sessionID, func(sess *Session) {
+		sess.TranscriptionModel, sess.TranscriptionLanguage = model, language
+	})
+}
+
+// AddTranscriptionProvider records that provider transcribed part of a
+// session, unless it already has.
+func (s *MemoryStore) AddTranscriptionProvider(sessionID, provider string) error {
+	return s.updateSession(sessionID, func(sess *Session) {
+		if !slices.Contains(sess.TranscriptionProviders, provider) {
+			sess.TranscriptionProviders = append(sess.TranscriptionProviders, provider)
+		}
+	})
+}
+
+// SetSummaryModel records the model that wrote a session's summary.
+func (s *MemoryStore) SetSummaryModel(sessionID, model string) error {
+	return s.updateSession(sessionID, func(sess *Session) { sess.SummaryModel = model })
+}
+
+// SetAudioOffset records where on the capture timeline a session's
+// recording starts.
+func (s *MemoryStore) SetAudioOffset(sessionID string, offset float64) error {
+	return s.updateSession(sessionID, func(sess *Session) { sess.AudioOffset = offset })
+}
+
+// SetSessionClock records where a session started on the boot clock of the
+// boot identified by bootID.
+func (s *MemoryStore) SetSessionClock(sessionID, bootID string, boot time.Duration) error {
+	return s.updateSession(sessionID, func(sess *Session) { sess.ClockBootID, sess.ClockBoot = bootID, boot })
+}
+
+// SetChannelSpeakers flags whether a session's speakers follow the
+// capture's channels.
+func (s *MemoryStore) SetChannelSpeakers(sessionID string, channels bool) error {
+	return s.updateSession(sessionID, func(sess *Session) { sess.ChannelSpeakers = channels })
+}
+
+// SetKeepWAV flags whether a session's lossless audio is to be kept.
+func (s *MemoryStore) SetKeepWAV(sessionID string, keep bool) error {
+	return s.updateSession(sessionID, func(sess *Session) { sess.KeepWAV = keep })
+}
+
+// SetWAVPath records where a session's lossless audio was kept.
+func (s *MemoryStore) SetWAVPath(sessionID, path string) error {
+	return s.updateSession(sessionID, func(sess *Session) { sess.WAVPath = path })
+}
+
+// SetGDocID records the Google Doc a session's summary was exported to.
+func (s *MemoryStore) SetGDocID(sessionID, docID string) error {
+	return s.updateSession(sessionID, func(sess *Session) { sess.GDocID = docID })
+}
+
+// SetJournalDocID records the daily journal doc a session's summary was
+// appended to.
+func (s *MemoryStore) SetJournalDocID(sessionID, docID string) error {
+	return s.updateSession(sessionID, func(sess *Session) { sess.JournalDocID = docID })
+}
+
+// MarkSessionViewed records that a session was opened at at.
+func (s *MemoryStore) MarkSessionViewed(sessionID string, at time.Time) error {
+	at = at.UTC()
+	return s.updateSession(sessionID, func(sess *Session) { sess.LastViewedAt = &at })
+}
+
+func (s *MemoryStore) ClaimSummaryRequest(sessionID, promptHash string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := sessionID + "\x00" + promptHash
+	if _, ok := s.claims[key]; ok {
+		return false, nil
+	}
+	s.claims[key] = struct{}{}
+	return true, nil
+}
+
+// ReplaceDecisions swaps the stored decisions for a session.
+func (s *MemoryStore) ReplaceDecisions(sessionID string, decisions []Decision) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sess, ok := s.sessions[sessionID]
+	if !ok {
+		return fmt.Errorf("query session %s: %w", sessionID, sql.ErrNoRows)
+	}
+
+	kept := s.decisions[:0]
+	for _, d := range s.decisions {
+		if d.SessionID != sessionID {
+			kept = append(kept, d)
+		}
+	}
+	s.decisions = kept
+
+	for _, d := range decisions {
+		participants := append([]string{}, d.Participants...)
+		s.nextDecisionID++
+		s.decisions = append(s.decisions, Decision{
+			ID:           s.nextDecisionID,
+			SessionID:    sessionID,
+			Text:         strings.TrimSpace(d.Text),
+			Participants: participants,
+			Timestamp:    sess.StartedAt,
+		})
+	}
+	return nil
+}
+
+// GetDecisions returns decisions matching the filter, newest session first.
+func (s *MemoryStore) GetDecisions(filter DecisionFilter) ([]Decision, error) {
+	query := strings.ToLower(strings.TrimSpace(filter.Query))
+
+	s.mu.RLock()
+	decisions := make([]Decision, 0, 16)
+	for _, d := range s.decisions {
+		date := d.Timestamp.Format("2006-01-02")
+		if filter.From != "" && date < filter.From {
+			continue
+		}
+		if filter.To != "" && date > filter.To {
+			continue
+		}
+		if query != "" && !strings.Contains(strings.ToLower(d.Text), query) {
+			continue
+		}
+		d.Participants = append([]string{}, d.Participants...)
+		decisions = append(decisions, d)
+	}
+	s.mu.RUnlock()
+
+	sort.SliceStable(decisions, func(i, j int) bool {
+		if !decisions[i].Timestamp.Equal(decisions[j].Timestamp) {
+			return decisions[i].Timestamp.After(decisions[j].Timestamp)
+		}
+		return decisions[i].ID < decisions[j].ID
+	})
+	return decisions, nil
+}
+
+// ReplaceActionItems swaps the stored action items for a session.
+func (s *MemoryStore) ReplaceActionItems(sessionID string, items []ActionItem) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sess, ok := s.sessions[sessionID]
+	if !ok {
+		return fmt.Errorf("query session %s: %w", sessionID, sql.ErrNoRows)
+	}
+
+	kept := s.actionItems[:0]
+	for _, item := range s.actionItems {
+		if item.SessionID != sessionID {
+			kept = append(kept, item)
+		}
+	}
+	s.actionItems = kept
+
+	for _, item := range items {
+		s.nextActionID++
+		s.actionItems = append(s.actionItems, ActionItem{
+			ID:        s.nextActionID,
+			SessionID: sessionID,
+			Text:      strings.TrimSpace(item.Text),
+			Owner:     strings.TrimSpace(item.Owner),
+			Done:      item.Done,
+			Timestamp: sess.StartedAt,
+		})
+	}
+	return nil
+}
+
+// GetActionItems returns a session's action items in extraction order.
+func (s *MemoryStore) GetActionItems(sessionID string) ([]ActionItem, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	items := make([]ActionItem, 0, 8)
+	for _, item := range s.actionItems {
+		if item.SessionID == sessionID {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+// ReplaceCitations swaps the stored citations for a session.
+func (s *MemoryStore) ReplaceCitations(sessionID string, citations []Citation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.sessions[sessionID]; !ok {
+		return fmt.Errorf("query session %s: %w", sessionID, sql.ErrNoRows)
+	}
+	s.citations[sessionID] = append([]Citation(nil), citations...)
+	sort.SliceStable(s.citations[sessionID], func(i, j int) bool {
+		return s.citations[sessionID][i].Bullet < s.citations[sessionID][j].Bullet
+	})
+	return nil
+}
+
+// GetCitations returns a session's summary citations in bullet order.
+func (s *MemoryStore) GetCitations(sessionID string) ([]Citation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append(make([]Citation, 0, len(s.citations[sessionID])), s.citations[sessionID]...), nil
+}
+
+// ReplaceAnnotations swaps the stored annotations for a session.
+func (s *MemoryStore) ReplaceAnnotations(sessionID string, annotations []Annotation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.sessions[sessionID]; !ok {
+		return fmt.Errorf("query session %s: %w", sessionID, sql.ErrNoRows)
+	}
+	stored := make([]Annotation, 0, len(annotations))
+	for _, a := range annotations {
+		a.SessionID = sessionID
+		stored = append(stored, a)
+	}
+	sort.SliceStable(stored, func(i, j int) bool { return stored[i].CreatedAt.Before(stored[j].CreatedAt) })
+	s.annotations[sessionID] = stored
+	return nil
+}
+
+// GetAnnotations returns a session's annotations, oldest first.
+func (s *MemoryStore) GetAnnotations(sessionID string) ([]Annotation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append(make([]Annotation, 0, len(s.annotations[sessionID])), s.annotations[sessionID]...), nil
+}
+
+// SetExport records where an export stands, replacing what was recorded
+// for the same session and rule.
+func (s *MemoryStore) SetExport(e Export) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.sessions[e.SessionID]; !ok {
+		return fmt.Errorf("set %s export for session %s: %w", e.Rule, e.SessionID, sql.ErrNoRows)
+	}
+	e.UpdatedAt = e.UpdatedAt.UTC()
+	for i, existing := range s.exports {
+		if existing.SessionID == e.SessionID && existing.Rule == e.Rule {
+			s.exports[i] = e
+			return nil
+		}
+	}
+	s.exports = append(s.exports, e)
+	return nil
+}
+
+// GetExports returns a session's exports by rule name.
+func (s *MemoryStore) GetExports(sessionID string) ([]Export, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	exports := make([]Export, 0, 4)
+	for _, e := range s.exports {
+		if e.SessionID == sessionID {
+			exports = append(exports, e)
+		}
+	}
+	sort.Slice(exports, func(i, j int) bool { return exports[i].Rule < exports[j].Rule })
+	return exports, nil
+}
+
+// GetExportsByStatus returns the exports in status, oldest first.
+func (s *MemoryStore) GetExportsByStatus(status string) ([]Export, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	exports := make([]Export, 0, 4)
+	for _, e := range s.exports {
+		if e.Status == status {
+			exports = append(exports, e)
+		}
+	}
+	sort.SliceStable(exports, func(i, j int) bool {
+		a, b := exports[i], exports[j]
+		if !a.UpdatedAt.Equal(b.UpdatedAt) {
+			return a.UpdatedAt.Before(b.UpdatedAt)
+		}
+		if a.SessionID != b.SessionID {
+			return a.SessionID < b.SessionID
+		}
+		return a.Rule < b.Rule
+	})
+	return exports, nil
+}
+
+// setPrimaryAudio makes path the session's audio file at index 0, or
+// removes it when path is empty. s.mu must be held.
+func (s *MemoryStore) setPrimaryAudio(sessionID, kind, path string) {
+	files := s.audioFiles[sessionID]
+	if len(files) > 0 && files[0].Index == 0 {
+		files = files[1:]
+	}
+	if path != "" {
+		files = append([]AudioFile{{Kind: kind, Path: path, CreatedAt: time.Now().UTC()}}, files...)
+	}
+	s.audioFiles[sessionID] = files
+}
+
+// AddAudioFile attaches another audio file to a session after those it
+// already has; an ended session without audio gets it as its primary
+// recording.
+func (s *MemoryStore) AddAudioFile(sessionID, kind, path string) (AudioFile, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sess, ok := s.sessions[sessionID]
+	if !ok {
+		return AudioFile{}, fmt.Errorf("query session %s: %w", sessionID, sql.ErrNoRows)
+	}
+	file := AudioFile{Index: 1, Kind: kind, Path: path, CreatedAt: time.Now().UTC()}
+	if files := s.audioFiles[sessionID]; len(files) > 0 {
+		file.Index = files[len(files)-1].Index + 1
+	} else if sess.Status == "ended" {
+		file.Index = 0
+		sess.AudioPath = path
+	}
+	s.audioFiles[sessionID] = append(s.audioFiles[sessionID], file)
+	return file, nil
+}
+
+// GetAudioFiles returns a session's audio files in index order.
+func (s *MemoryStore) GetAudioFiles(sessionID string) ([]AudioFile, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append(make([]AudioFile, 0, len(s.audioFiles[sessionID])), s.audioFiles[sessionID]...), nil
+}
+
+// CreateBriefing stores a new briefing.
+func (s *MemoryStore) CreateBriefing(b Briefing) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.briefings[b.ID]; ok {
+		return fmt.Errorf("insert briefing %s: already exists", b.ID)
+	}
+	s.briefings[b.ID] = copyBriefing(b)
+	return nil
+}
+
+// GetBriefing returns a briefing by ID, or sql.ErrNoRows.
+func (s *MemoryStore) GetBriefing(id string) (Briefing, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	b, ok := s.briefings[id]
+	if !ok {
+		return Briefing{}, fmt.Errorf("query briefing %s: %w", id, sql.ErrNoRows)
+	}
+	return copyBriefing(b), nil
+}
+
+// ListBriefings returns every briefing, soonest meeting last.
+func (s *MemoryStore) ListBriefings() ([]Briefing, error) {
+	s.mu.RLock()
+	briefings := make([]Briefing, 0, len(s.briefings))
+	for _, b := range s.briefings {
+		briefings = append(briefings, copyBriefing(b))
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(briefings, func(i, j int) bool { return briefings[i].StartsAt.After(briefings[j].StartsAt) })
+	return briefings, nil
+}
+
+// MarkBriefingDelivered records when a briefing was announced.
+func (s *MemoryStore) MarkBriefingDelivered(id string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.briefings[id]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	at = at.UTC()
+	b.DeliveredAt = &at
+	s.briefings[id] = b
+	return nil
+}
+
+func copyBriefing(b Briefing) Briefing {
+	b.Attendees = append([]string{}, b.Attendees...)
+	b.SessionIDs = append([]string{}, b.SessionIDs...)
+	if b.EndsAt != nil {
+		at := b.EndsAt.UTC()
+		b.EndsAt = &at
+	}
+	if b.DeliveredAt != nil {
+		at := *b.DeliveredAt
+		b.DeliveredAt = &at
+	}
+	return b
+}
+
+// AddBookmark records a bookmark in a session at the given time, offset
+// seconds into the capture timeline.
+func (s *MemoryStore) AddBookmark(sessionID string, at time.Time, offset float64, note string) (Bookmark, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.sessions[sessionID]; !ok {
+		return Bookmark{}, fmt.Errorf("query session %s: %w", sessionID, sql.ErrNoRows)
+	}
+	s.nextBookmarkID++
+	b := Bookmark{
+		ID:        s.nextBookmarkID,
+		SessionID: sessionID,
+		Offset:    max(offset, 0),
+		Note:      strings.TrimSpace(note),
+		CreatedAt: at.UTC(),
+	}
+	s.bookmarks = append(s.bookmarks, b)
+	return b, nil
+}
+
+// GetBookmarks returns a session's bookmarks in the order they were made.
+func (s *MemoryStore) GetBookmarks(sessionID string) ([]Bookmark, error) {
+	s.mu.RLock()
+	bookmarks := make([]Bookmark, 0, 4)
+	for _, b := range s.bookmarks {
+		if b.SessionID == sessionID {
+			bookmarks = append(bookmarks, b)
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.SliceStable(bookmarks, func(i, j int) bool { return bookmarks[i].Offset < bookmarks[j].Offset })
+	return bookmarks, nil
+}
+
+// AddNote records a note taken in a session at the given time, offset
+// seconds into the capture timeline.
+func (s *MemoryStore) AddNote(sessionID string, at time.Time, offset float64, text string) (Note, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.sessions[sessionID]; !ok {
+		return Note{}, fmt.Errorf("query session %s: %w", sessionID, sql.ErrNoRows)
+	}
+	s.nextNoteID++
+	n := Note{
+		ID:        s.nextNoteID,
+		SessionID: sessionID,
+		Offset:    max(offset, 0),
+		Text:      strings.TrimSpace(text),
+		CreatedAt: at.UTC(),
+	}
+	s.notes = append(s.notes, n)
+	return n, nil
+}
+
+// GetNotes returns a session's notes in the order they were taken.
+func (s *MemoryStore) GetNotes(sessionID string) ([]Note, error) {
+	s.mu.RLock()
+	notes := make([]Note, 0, 4)
+	for _, n := range s.notes {
+		if n.SessionID == sessionID {
+			notes = append(notes, n)
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.SliceStable(notes, func(i, j int) bool { return notes[i].Offset < notes[j].Offset })
+	return notes, nil
+}
+
+// SetSessionSeries threads a session into a series.
+func (s *MemoryStore) SetSessionSeries(sessionID, seriesID string) error {
+	return s.updateSession(sessionID, func(sess *Session) { sess.SeriesID = seriesID })
+}
+
+// GetSeriesSessions returns the sessions of a series, oldest first.
+func (s *MemoryStore) GetSeriesSessions(seriesID string) ([]Session, error) {
+	sessions := s.filterSessions(func(sess *Session) bool { return seriesID != "" && sess.SeriesID == seriesID })
+	sortOldestFirst(sessions)
+	return sessions, nil
+}
+
+// SetSessionKind marks what kind of recording a session is.
+func (s *MemoryStore) SetSessionKind(sessionID, kind string) error {
+	return s.updateSession(sessionID, func(sess *Session) { sess.Kind = kind })
+}
+
+// GetSessionsByKind returns the sessions of a kind, newest first.
+func (s *MemoryStore) GetSessionsByKind(kind string) ([]Session, error) {
+	sessions := s.filterSessions(func(sess *Session) bool { return kind != "" && sess.Kind == kind })
+	sort.SliceStable(sessions, func(i, j int) bool { return sessions[i].StartedAt.After(sessions[j].StartedAt) })
+	return sessions, nil
+}
+
+// DeleteSession removes a session and everything recorded about it.
+func (s *MemoryStore) DeleteSession(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.unlockedSession(id); err != nil {
+		return fmt.Errorf("delete session %s: %w", id, err)
+	}
+	delete(s.sessions, id)
+	delete(s.segments, id)
+	delete(s.citations, id)
+	delete(s.annotations, id)
+	delete(s.audioFiles, id)
+	delete(s.usage, id)
+	delete(s.speakerNames, id)
+	s.decisions = slices.DeleteFunc(s.decisions, func(d Decision) bool { return d.SessionID == id })
+	s.actionItems = slices.DeleteFunc(s.actionItems, func(a ActionItem) bool { return a.SessionID == id })
+	s.bookmarks = slices.DeleteFunc(s.bookmarks, func(b Bookmark) bool { return b.SessionID == id })
+	s.notes = slices.DeleteFunc(s.notes, func(n Note) bool { return n.SessionID == id })
+	s.exports = slices.DeleteFunc(s.exports, func(e Export) bool { return e.SessionID == id })
+	for key := range s.claims {
+		if strings.HasPrefix(key, id+"\x00") {
+			delete(s.claims, key)
+		}
+	}
+	return nil
+}
+
+// ListSeries returns every series, most recently active first.
+func (s *MemoryStore) ListSeries() ([]Series, error) {
+	byID := make(map[string]*Series)
+	for _, sess := range s.filterSessions(func(sess *Session) bool { return sess.SeriesID != "" }) {
+		sr, ok := byID[sess.SeriesID]
+		if !ok {
+			sr = &Series{ID: sess.SeriesID, FirstStartedAt: sess.StartedAt, LastStartedAt: sess.StartedAt}
+			byID[sess.SeriesID] = sr
+		}
+		sr.SessionCount++
+		if sess.StartedAt.Before(sr.FirstStartedAt) {
+			sr.FirstStartedAt = sess.StartedAt
+		}
+		if sess.StartedAt.After(sr.LastStartedAt) {
+			sr.LastStartedAt = sess.StartedAt
+		}
+	}
+
+	series := make([]Series, 0, len(byID))
+	for _, sr := range byID {
+		series = append(series, *sr)
+	}
+	sort.Slice(series, func(i, j int) bool { return series[i].LastStartedAt.After(series[j].LastStartedAt) })
+	return series, nil
+}
+
+// RecordTranscriptionUsage sets the transcribed duration and its cost for a session.
+func (s *MemoryStore) RecordTranscriptionUsage(sessionID string, duration time.Duration, cost float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u := s.usageFor(sessionID)
+	u.transcriptionSeconds = duration.Seconds()
+	u.transcriptionCost = cost
+	return nil
+}
+
+// AddLLMUsage adds tokens and cost spent on a session's summaries. Tokens and
+// cost accumulate across resummarizations; latency reflects the latest run.
+func (s *MemoryStore) AddLLMUsage(sessionID string, inputTokens, outputTokens int, cost float64, latency time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u := s.usageFor(sessionID)
+	u.llmInputTokens += inputTokens
+	u.llmOutputTokens += outputTokens
+	u.llmCost += cost
+	if latency > 0 {
+		u.summaryLatencyMs = latency.Milliseconds()
+	}
+	return nil
+}
+
+// GetSessionUsage returns the recorded usage for a session, or zero usage if
+// nothing has been metered yet.
+func (s *MemoryStore) GetSessionUsage(sessionID string) (SessionUsage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	u, ok := s.usage[sessionID]
+	if !ok {
+		return SessionUsage{}, nil
+	}
+	return SessionUsage{
+		TranscriptionMinutes: u.transcriptionSeconds / 60,
+		TranscriptionCost:    u.transcriptionCost,
+		LLMInputTokens:       u.llmInputTokens,
+		LLMOutputTokens:      u.llmOutputTokens,
+		LLMCost:              u.llmCost,
+		SummaryLatencyMs:     u.summaryLatencyMs,
+		TotalCost:            u.transcriptionCost + u.llmCost,
+	}, nil
+}
+
+// GetMonthlySpend sums the usage of every session started in the UTC
+// calendar month containing month.
+func (s *MemoryStore) GetMonthlySpend(month time.Time) (MonthlySpend, error) {
+	want := month.UTC().Format("2006-01")
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var spend MonthlySpend
+	for id, u := range s.usage {
+		sess, ok := s.sessions[id]
+		if !ok || sess.StartedAt.Format("2006-01") != want {
+			continue
+		}
+		spend.TranscriptionCost += u.transcriptionCost
+		spend.LLMCost += u.llmCost
+	}
+	return spend, nil
+}
+
+// GetSessionsForRefinement returns unlocked ended sessions with audio whose
+// segments have not been revised since transcription, oldest first, and
+// those that failed before after the rest. Sessions whose speakers follow
+// the capture's channels are left alone.
+func (s *MemoryStore) GetSessionsForRefinement(limit int) ([]Session, error) {
+	sessions := s.filterSessions(func(sess *Session) bool {
+		return sess.Status == "ended" && sess.AudioPath != "" && sess.SegmentsVersion == 1 && !sess.Locked &&
+			!sess.ChannelSpeakers && sess.RefineFailures < MaxRefineFailures
+	})
+	sortOldestFirst(sessions)
+	sort.SliceStable(sessions, func(i, j int) bool { return sessions[i].RefineFailures < sessions[j].RefineFailures })
+	if limit >= 0 && len(sessions) > limit {
+		sessions = sessions[:limit]
+	}
+	return sessions, nil
+}
+
+// AddRefineFailure counts a failed refinement pass of a session.
+func (s *MemoryStore) AddRefineFailure(sessionID string) error {
+	return s.updateSession(sessionID, func(sess *Session) { sess.RefineFailures++ })
+}
+
+// RelabelSegmentSpeakers assigns speakers[i] to the i-th segment of a
+// session, in GetSegments order, and bumps the session's segments_version.
+func (s *MemoryStore) RelabelSegmentSpeakers(sessionID string, speakers []int) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sess, err := s.unlockedSession(sessionID)
+	if err != nil {
+		return 0, fmt.Errorf("relabel session %s: %w", sessionID, err)
+	}
+	segments := s.segments[sessionID]
+	if len(segments) != len(speakers) {
+		return 0, fmt.Errorf("relabel session %s: have %d segments, got %d speakers", sessionID, len(segments), len(speakers))
+	}
+
+	order := make([]int, len(segments))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return segments[order[i]].Timestamp.Before(segments[order[j]].Timestamp) })
+	for i, idx := range order {
+		segments[idx].Speaker = speakers[i]
+	}
+
+	sess.SegmentsVersion++
+	return sess.SegmentsVersion, nil
+}
+
+func (s *MemoryStore) filterSessions(keep func(*Session) bool) []Session {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sessions := make([]Session, 0, 16)
+	for _, sess := range s.sessions {
+		if keep(sess) {
+			sessions = append(sessions, copySession(sess))
+		}
+	}
+	return sessions
+}
+
+func (s *MemoryStore) updateSession(id string, update func(*Session)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sess, ok := s.sessions[id]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	update(sess)
+	return nil
+}
+
+// usageFor returns the usage row for a session, creating it. Callers hold mu.
+func (s *MemoryStore) usageFor(sessionID string) *memoryUsage {
+	u, ok := s.usage[sessionID]
+	if !ok {
+		u = &memoryUsage{}
+		s.usage[sessionID] = u
+	}
+	return u
+}
+
+func copySession(sess *Session) Session {
+	out := *sess
+	if sess.EndedAt != nil {
+		ended := *sess.EndedAt
+		out.EndedAt = &ended
+	}
+	if sess.LastViewedAt != nil {
+		viewed := *sess.LastViewedAt
+		out.LastViewedAt = &viewed
+	}
+	out.TranscriptionProviders = slices.Clone(sess.TranscriptionProviders)
+	return out
+}
+
+func sortOldestFirst(sessions []Session) {
+	sort.SliceStable(sessions, func(i, j int) bool { return sessions[i].StartedAt.Before(sessions[j].StartedAt) })
+}
+
+// SaveAudioCalibration stores a device's calibration, replacing any earlier
+// one.
+func (s *MemoryStore) SaveAudioCalibration(c AudioCalibration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.calibrations[c.Device] = c
+	return nil
+}
+
+// GetAudioCalibration returns a device's calibration, or sql.ErrNoRows if it
+// has never been calibrated.
+func (s *MemoryStore) GetAudioCalibration(device string) (AudioCalibration, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	c, ok := s.calibrations[device]
+	if !ok {
+		return AudioCalibration{}, fmt.Errorf("query calibration for device %q: %w", device, sql.ErrNoRows)
+	}
+	return c, nil
+}
@@ -0,0 +1,492 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/transcribe"
+)
+
+// forEachStore runs fn against a fresh SQLiteStore and MemoryStore, so the
+// in-memory store is held to the same behavior as the real one.
+func forEachStore(t *testing.T, fn func(t *testing.T, store Store)) {
+	t.Run("sqlite", func(t *testing.T) { fn(t, newTestSQLiteStore(t)) })
+	t.Run("memory", func(t *testing.T) { fn(t, NewMemoryStore()) })
+}
+
+func TestOpenMemoryPath(t *testing.T) {
+	store, err := Open(MemoryPath)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if _, ok := store.(*MemoryStore); !ok {
+		t.Fatalf("expected *MemoryStore, got %T", store)
+	}
+}
+
+func TestStoresAgreeOnSessionsAndSegments(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		day1 := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+		day2 := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+		for _, s := range []struct {
+			id string
+			at time.Time
+		}{{"a", day1}, {"b", day1.Add(time.Hour)}, {"c", day2}} {
+			if err := store.CreateSession(s.id, s.at); err != nil {
+				t.Fatalf("CreateSession %s failed: %v", s.id, err)
+			}
+		}
+		if err := store.CreateSession("a", day1); err == nil {
+			t.Fatal("expected duplicate session to fail")
+		}
+
+		dates, _ := store.GetDates()
+		if !reflect.DeepEqual(dates, []string{"2026-03-02", "2026-03-01"}) {
+			t.Fatalf("unexpected dates: %v", dates)
+		}
+		sessions, _ := store.GetSessionsByDate("2026-03-01")
+		if len(sessions) != 2 || sessions[0].ID != "b" || sessions[1].ID != "a" {
+			t.Fatalf("expected newest first, got %+v", sessions)
+		}
+
+		late := transcribe.Segment{Speaker: 0, Text: " later ", Timestamp: day1.Add(2 * time.Second)}
+		early := transcribe.Segment{Speaker: 1, Text: "earlier", Timestamp: day1.Add(time.Second), Language: "fr",
+			Words: []transcribe.SegmentWord{{Word: "earlier", Start: 0.5, End: 0.9, Confidence: 0.8}}}
+		for _, seg := range []transcribe.Segment{late, early} {
+			if err := store.AppendSegment("a", seg); err != nil {
+				t.Fatalf("AppendSegment failed: %v", err)
+			}
+		}
+		segments, _ := store.GetSegments("a")
+		if len(segments) != 2 || segments[0].Text != "earlier" || segments[1].Text != "later" || segments[0].Language != "fr" {
+			t.Fatalf("expected chronological trimmed segments, got %+v", segments)
+		}
+		if !reflect.DeepEqual(segments[0].Words, early.Words) || segments[1].Words != nil {
+			t.Fatalf("expected word timings round-tripped, got %+v and %+v", segments[0].Words, segments[1].Words)
+		}
+
+		if err := store.EndSession("a", day1.Add(time.Minute), "a.wav"); err != nil {
+			t.Fatalf("EndSession failed: %v", err)
+		}
+		if err := store.EndSession("missing", day1, ""); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("expected ErrNoRows ending missing session, got %v", err)
+		}
+		if err := store.UpdateSummary("a", "done", SummaryCompleted, "default"); err != nil {
+			t.Fatalf("UpdateSummary failed: %v", err)
+		}
+
+		sess, err := store.GetSession("a")
+		if err != nil {
+			t.Fatalf("GetSession failed: %v", err)
+		}
+		if sess.Status != "ended" || sess.EndedAt == nil || sess.AudioPath != "a.wav" || sess.Summary != "done" || sess.SegmentsVersion != 1 {
+			t.Fatalf("unexpected session: %+v", sess)
+		}
+		if _, err := store.GetSession("missing"); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("expected ErrNoRows for missing session, got %v", err)
+		}
+
+		pending, _ := store.GetSessionsBySummaryStatus(SummaryPending)
+		if len(pending) != 2 || pending[0].ID != "b" {
+			t.Fatalf("unexpected pending sessions: %+v", pending)
+		}
+
+		refinable, _ := store.GetSessionsForRefinement(10)
+		if len(refinable) != 1 || refinable[0].ID != "a" {
+			t.Fatalf("unexpected refinable sessions: %+v", refinable)
+		}
+		version, err := store.RelabelSegmentSpeakers("a", []int{5, 6})
+		if err != nil || version != 2 {
+			t.Fatalf("RelabelSegmentSpeakers = %d, %v", version, err)
+		}
+		segments, _ = store.GetSegments("a")
+		if segments[0].Speaker != 5 || segments[1].Speaker != 6 {
+			t.Fatalf("expected relabeled speakers in chronological order, got %+v", segments)
+		}
+
+		first, _ := store.ClaimSummaryRequest("a", "hash")
+		second, _ := store.ClaimSummaryRequest("a", "hash")
+		if !first || second {
+			t.Fatalf("expected claim once, got %v then %v", first, second)
+		}
+	})
+}
+
+func TestStoresAgreeOnDecisionsAndUsage(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		march := time.Date(2026, 3, 5, 9, 0, 0, 0, time.UTC)
+		april := time.Date(2026, 4, 5, 9, 0, 0, 0, time.UTC)
+		_ = store.CreateSession("m", march)
+		_ = store.CreateSession("p", april)
+
+		if err := store.ReplaceDecisions("m", []Decision{{Text: "Ship Friday", Participants: []string{"Ana"}}}); err != nil {
+			t.Fatalf("ReplaceDecisions failed: %v", err)
+		}
+		_ = store.ReplaceDecisions("p", []Decision{{Text: "Hire contractor"}})
+		_ = store.ReplaceDecisions("m", []Decision{{Text: "Ship Monday"}})
+
+		all, _ := store.GetDecisions(DecisionFilter{})
+		if len(all) != 2 || all[0].SessionID != "p" || all[1].Text != "Ship Monday" {
+			t.Fatalf("unexpected decisions: %+v", all)
+		}
+		if len(all[0].Participants) != 0 || all[0].Participants == nil {
+			t.Fatalf("expected empty participants, got %#v", all[0].Participants)
+		}
+		filtered, _ := store.GetDecisions(DecisionFilter{To: "2026-03-31", Query: "ship"})
+		if len(filtered) != 1 || filtered[0].SessionID != "m" {
+			t.Fatalf("unexpected filtered decisions: %+v", filtered)
+		}
+
+		_ = store.RecordTranscriptionUsage("m", 3*time.Minute, 0.03)
+		_ = store.AddLLMUsage("m", 100, 20, 0.01, time.Second)
+		_ = store.AddLLMUsage("m", 50, 10, 0.02, 2*time.Second)
+		_ = store.AddLLMUsage("p", 1, 1, 1, 0)
+
+		usage, _ := store.GetSessionUsage("m")
+		if usage.TranscriptionMinutes != 3 || usage.LLMInputTokens != 150 || usage.SummaryLatencyMs != 2000 || usage.TotalCost != 0.06 {
+			t.Fatalf("unexpected usage: %+v", usage)
+		}
+		if none, _ := store.GetSessionUsage("missing"); none != (SessionUsage{}) {
+			t.Fatalf("expected zero usage, got %+v", none)
+		}
+
+		spend, _ := store.GetMonthlySpend(march)
+		if spend.TranscriptionCost != 0.03 || spend.LLMCost != 0.03 {
+			t.Fatalf("unexpected march spend: %+v", spend)
+		}
+	})
+}
+
+func TestStoresAgreeOnBookmarks(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+		_ = store.CreateSession("s", start)
+
+		if _, err := store.AddBookmark("s", start.Add(90*time.Second), 90, " action item "); err != nil {
+			t.Fatalf("AddBookmark failed: %v", err)
+		}
+		first, err := store.AddBookmark("s", start.Add(30*time.Second), 30, "")
+		if err != nil {
+			t.Fatalf("AddBookmark failed: %v", err)
+		}
+		if first.Offset != 30 || first.ID == 0 {
+			t.Fatalf("unexpected bookmark: %+v", first)
+		}
+		if _, err := store.AddBookmark("missing", start, 0, ""); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("expected ErrNoRows for missing session, got %v", err)
+		}
+
+		bookmarks, err := store.GetBookmarks("s")
+		if err != nil {
+			t.Fatalf("GetBookmarks failed: %v", err)
+		}
+		if len(bookmarks) != 2 || bookmarks[0].Offset != 30 || bookmarks[1].Note != "action item" {
+			t.Fatalf("unexpected bookmarks: %+v", bookmarks)
+		}
+	})
+}
+
+func TestStoresAgreeOnNotes(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+		_ = store.CreateSession("s", start)
+
+		if _, err := store.AddNote("s", start.Add(2*time.Minute), 120, " budget is capped at 10k "); err != nil {
+			t.Fatalf("AddNote failed: %v", err)
+		}
+		early, err := store.AddNote("s", start.Add(-time.Minute), 0, "agenda: hiring")
+		if err != nil {
+			t.Fatalf("AddNote failed: %v", err)
+		}
+		if early.Offset != 0 || early.ID == 0 {
+			t.Fatalf("unexpected note: %+v", early)
+		}
+		if _, err := store.AddNote("missing", start, 0, "x"); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("expected ErrNoRows for missing session, got %v", err)
+		}
+
+		notes, err := store.GetNotes("s")
+		if err != nil {
+			t.Fatalf("GetNotes failed: %v", err)
+		}
+		if len(notes) != 2 || notes[0].Text != "agenda: hiring" || notes[1].Offset != 120 || notes[1].Text != "budget is capped at 10k" {
+			t.Fatalf("unexpected notes: %+v", notes)
+		}
+
+		if err := store.DeleteSession("s"); err != nil {
+			t.Fatal(err)
+		}
+		if notes, _ := store.GetNotes("s"); len(notes) != 0 {
+			t.Fatalf("expected notes deleted with the session, got %+v", notes)
+		}
+	})
+}
+
+func TestMarkSessionRecovered(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+		for _, id := range []string{"crashed", "ended"} {
+			if err := store.CreateSession(id, start); err != nil {
+				t.Fatalf("CreateSession %s failed: %v", id, err)
+			}
+		}
+		if err := store.EndSession("ended", start.Add(time.Hour), ""); err != nil {
+			t.Fatalf("EndSession failed: %v", err)
+		}
+
+		for _, id := range []string{"crashed", "ended"} {
+			if err := store.MarkSessionRecovered(id, start.Add(10*time.Minute), id+".mp3"); err != nil {
+				t.Fatalf("MarkSessionRecovered %s failed: %v", id, err)
+			}
+		}
+		if err := store.MarkSessionRecovered("missing", start, ""); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("expected ErrNoRows recovering a missing session, got %v", err)
+		}
+
+		for id, end := range map[string]time.Time{"crashed": start.Add(10 * time.Minute), "ended": start.Add(time.Hour)} {
+			sess, err := store.GetSession(id)
+			if err != nil {
+				t.Fatalf("GetSession %s failed: %v", id, err)
+			}
+			if !sess.Recovered || sess.Status != "ended" || sess.AudioPath != id+".mp3" || sess.EndedAt == nil || !sess.EndedAt.Equal(end) {
+				t.Fatalf("expected %s recovered, ended at %v with its audio, got %+v", id, end, sess)
+			}
+		}
+	})
+}
+
+func TestSetAudioStatus(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+		if err := store.CreateSession("s1", start); err != nil {
+			t.Fatalf("CreateSession failed: %v", err)
+		}
+		if err := store.EndSession("s1", start.Add(time.Hour), ""); err != nil {
+			t.Fatalf("EndSession failed: %v", err)
+		}
+		if err := store.SetAudioStatus("s1", AudioEncoding, ""); err != nil {
+			t.Fatalf("SetAudioStatus encoding failed: %v", err)
+		}
+		if err := store.SetAudioStatus("s1", AudioReady, "s1.mp3"); err != nil {
+			t.Fatalf("SetAudioStatus ready failed: %v", err)
+		}
+		if err := store.SetAudioStatus("missing", AudioReady, ""); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("expected ErrNoRows for a missing session, got %v", err)
+		}
+
+		sess, err := store.GetSession("s1")
+		if err != nil {
+			t.Fatalf("GetSession failed: %v", err)
+		}
+		if sess.AudioStatus != AudioReady || sess.AudioPath != "s1.mp3" {
+			t.Fatalf("expected ready audio at s1.mp3, got %q at %q", sess.AudioStatus, sess.AudioPath)
+		}
+	})
+}
+
+func TestAudioFiles(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+		if err := store.CreateSession("s1", start); err != nil {
+			t.Fatalf("CreateSession failed: %v", err)
+		}
+		// Audio added while the session records comes after its recording.
+		if _, err := store.AddAudioFile("s1", AudioGap, "s1-gap.mp3"); err != nil {
+			t.Fatalf("AddAudioFile gap failed: %v", err)
+		}
+		if err := store.EndSession("s1", start.Add(time.Hour), "s1.mp3"); err != nil {
+			t.Fatalf("EndSession failed: %v", err)
+		}
+		clip, err := store.AddAudioFile("s1", AudioClip, "s1-clip.mp3")
+		if err != nil {
+			t.Fatalf("AddAudioFile clip failed: %v", err)
+		}
+		if clip.Index != 2 {
+			t.Fatalf("expected the clip at index 2, got %d", clip.Index)
+		}
+		if _, err := store.AddAudioFile("missing", AudioClip, "x.mp3"); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("expected ErrNoRows for a missing session, got %v", err)
+		}
+
+		files, err := store.GetAudioFiles("s1")
+		if err != nil {
+			t.Fatalf("GetAudioFiles failed: %v", err)
+		}
+		var got []string
+		for _, f := range files {
+			got = append(got, fmt.Sprintf("%d %s %s", f.Index, f.Kind, f.Path))
+		}
+		want := []string{"0 recording s1.mp3", "1 gap s1-gap.mp3", "2 clip s1-clip.mp3"}
+		if !slices.Equal(got, want) {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+
+		// An ended session without audio takes an import as its recording.
+		if err := store.CreateSession("s2", start); err != nil {
+			t.Fatalf("CreateSession failed: %v", err)
+		}
+		if err := store.EndSession("s2", start.Add(time.Hour), ""); err != nil {
+			t.Fatalf("EndSession failed: %v", err)
+		}
+		if imported, err := store.AddAudioFile("s2", AudioImported, "s2.mp3"); err != nil || imported.Index != 0 {
+			t.Fatalf("expected the import at index 0, got %+v (%v)", imported, err)
+		}
+		if sess, err := store.GetSession("s2"); err != nil || sess.AudioPath != "s2.mp3" {
+			t.Fatalf("expected the import as the session's audio, got %q (%v)", sess.AudioPath, err)
+		}
+
+		// Failing to encode leaves the session without its recording.
+		if err := store.SetAudioStatus("s1", AudioFailed, ""); err != nil {
+			t.Fatalf("SetAudioStatus failed: %v", err)
+		}
+		if files, _ = store.GetAudioFiles("s1"); len(files) != 2 || files[0].Index != 1 {
+			t.Fatalf("expected only the added files, got %+v", files)
+		}
+	})
+}
+
+func TestSessionModels(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		if err := store.CreateSession("s1", time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)); err != nil {
+			t.Fatalf("CreateSession failed: %v", err)
+		}
+		if err := store.SetTranscriptionModel("s1", "nova-2", "en-US"); err != nil {
+			t.Fatalf("SetTranscriptionModel failed: %v", err)
+		}
+		for _, provider := range []string{"deepgram", "whisper", "deepgram"} {
+			if err := store.AddTranscriptionProvider("s1", provider); err != nil {
+				t.Fatalf("AddTranscriptionProvider failed: %v", err)
+			}
+		}
+		if err := store.SetSummaryModel("s1", "anthropic/claude-sonnet-4-5"); err != nil {
+			t.Fatalf("SetSummaryModel failed: %v", err)
+		}
+		if err := store.SetSummaryModel("missing", "x"); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("expected ErrNoRows for a missing session, got %v", err)
+		}
+
+		sess, err := store.GetSession("s1")
+		if err != nil {
+			t.Fatalf("GetSession failed: %v", err)
+		}
+		if sess.TranscriptionModel != "nova-2" || sess.TranscriptionLanguage != "en-US" || sess.SummaryModel != "anthropic/claude-sonnet-4-5" {
+			t.Fatalf("unexpected session models %+v", sess)
+		}
+		if !slices.Equal(sess.TranscriptionProviders, []string{"deepgram", "whisper"}) {
+			t.Fatalf("expected each provider recorded once, in order, got %q", sess.TranscriptionProviders)
+		}
+	})
+}
+
+func TestKeepWAV(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		if err := store.CreateSession("s1", time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)); err != nil {
+			t.Fatalf("CreateSession failed: %v", err)
+		}
+		if err := store.SetKeepWAV("s1", true); err != nil {
+			t.Fatalf("SetKeepWAV failed: %v", err)
+		}
+		if err := store.SetWAVPath("s1", "data/audio/s1.wav"); err != nil {
+			t.Fatalf("SetWAVPath failed: %v", err)
+		}
+		if err := store.SetKeepWAV("missing", true); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("expected ErrNoRows for a missing session, got %v", err)
+		}
+		if err := store.SetAudioOffset("s1", 12.5); err != nil {
+			t.Fatalf("SetAudioOffset failed: %v", err)
+		}
+		if err := store.SetSessionClock("s1", "boot-1", 90*time.Second); err != nil {
+			t.Fatalf("SetSessionClock failed: %v", err)
+		}
+
+		sess, err := store.GetSession("s1")
+		if err != nil {
+			t.Fatalf("GetSession failed: %v", err)
+		}
+		if !sess.KeepWAV || sess.WAVPath != "data/audio/s1.wav" || sess.AudioOffset != 12.5 || sess.ClockBootID != "boot-1" || sess.ClockBoot != 90*time.Second {
+			t.Fatalf("unexpected audio fields %+v", sess)
+		}
+	})
+}
+
+func TestStoresAgreeOnUnreviewedSessions(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+		for i, id := range []string{"read", "unread", "pending", "later"} {
+			_ = store.CreateSession(id, start.Add(time.Duration(i)*time.Hour))
+		}
+		_ = store.UpdateSummary("read", "- shipped", SummaryCompleted, "default")
+		_ = store.UpdateSummary("unread", "- planned", SummaryCompleted, "default")
+		_ = store.UpdateSummary("later", "- reviewed", SummaryCompleted, "default")
+		_ = store.UpdateSummary("pending", "", SummaryRunning, "default")
+
+		viewedAt := start.Add(5 * time.Hour)
+		if err := store.MarkSessionViewed("read", viewedAt); err != nil {
+			t.Fatalf("MarkSessionViewed failed: %v", err)
+		}
+		if err := store.MarkSessionViewed("missing", viewedAt); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("expected ErrNoRows for a missing session, got %v", err)
+		}
+		if sess, _ := store.GetSession("read"); sess.LastViewedAt == nil || !sess.LastViewedAt.Equal(viewedAt) {
+			t.Fatalf("expected the view recorded, got %v", sess.LastViewedAt)
+		}
+
+		sessions, err := store.GetUnreviewedSessions()
+		if err != nil {
+			t.Fatalf("GetUnreviewedSessions failed: %v", err)
+		}
+		if len(sessions) != 2 || sessions[0].ID != "later" || sessions[1].ID != "unread" {
+			t.Fatalf("expected the unread summaries newest first, got %+v", sessions)
+		}
+	})
+}
+
+func TestStoresAgreeOnAnnotations(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+		_ = store.CreateSession("s", start)
+		if err := store.SetGDocID("s", "doc-1"); err != nil {
+			t.Fatalf("SetGDocID failed: %v", err)
+		}
+		if sess, _ := store.GetSession("s"); sess.GDocID != "doc-1" {
+			t.Fatalf("expected the doc id stored, got %q", sess.GDocID)
+		}
+		if err := store.SetJournalDocID("s", "journal-1"); err != nil {
+			t.Fatalf("SetJournalDocID failed: %v", err)
+		}
+		if sess, _ := store.GetSession("s"); sess.JournalDocID != "journal-1" || sess.GDocID != "doc-1" {
+			t.Fatalf("expected the journal doc id stored beside the review doc, got %+v", sess)
+		}
+
+		first := []Annotation{
+			{ExternalID: "c2", Kind: AnnotationReply, Author: "Bob", Text: "agreed", CreatedAt: start.Add(2 * time.Hour)},
+			{ExternalID: "c1", Kind: AnnotationComment, Author: "Alice", Text: "it was May, not June", Quote: "ships in June", CreatedAt: start.Add(time.Hour)},
+		}
+		if err := store.ReplaceAnnotations("s", first); err != nil {
+			t.Fatalf("ReplaceAnnotations failed: %v", err)
+		}
+		got, err := store.GetAnnotations("s")
+		if err != nil {
+			t.Fatalf("GetAnnotations failed: %v", err)
+		}
+		if len(got) != 2 || got[0].ExternalID != "c1" || got[0].SessionID != "s" || got[0].Quote != "ships in June" || !got[0].CreatedAt.Equal(start.Add(time.Hour)) {
+			t.Fatalf("unexpected annotations: %+v", got)
+		}
+
+		// A later pull replaces what an earlier one found.
+		if err := store.ReplaceAnnotations("s", []Annotation{{ExternalID: "c1", Kind: AnnotationComment, Text: "fixed", Resolved: true, CreatedAt: start}}); err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := store.GetAnnotations("s"); len(got) != 1 || !got[0].Resolved {
+			t.Fatalf("expected the annotations replaced, got %+v", got)
+		}
+		if err := store.ReplaceAnnotations("missing", nil); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("expected ErrNoRows for a missing session, got %v", err)
+		}
+	})
+}
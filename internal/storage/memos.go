@@ -0,0 +1,40 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// SessionKindMemo marks a session recorded as a one-shot voice memo rather
+// than by the silence-timeout flow.
+const SessionKindMemo = "memo"
+
+// SetSessionKind marks what kind of recording a session is; an empty kind
+// makes it a regular session.
+func (s *SQLiteStore) SetSessionKind(sessionID, kind string) error {
+	res, err := s.db.Exec(`UPDATE sessions SET kind = ? WHERE id = ?`, kind, sessionID)
+	if err != nil {
+		return fmt.Errorf("set kind for session %s: %w", sessionID, err)
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("set kind rows affected: %w", err)
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// GetSessionsByKind returns the sessions of a kind, newest first.
+func (s *SQLiteStore) GetSessionsByKind(kind string) ([]Session, error) {
+	rows, err := s.db.Query(
+		`SELECT `+sessionColumns+` FROM sessions WHERE kind = ? AND kind != '' ORDER BY started_at DESC`,
+		kind,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query %s sessions: %w", kind, err)
+	}
+	defer func() { _ = rows.Close() }()
+	return scanSessions(rows)
+}
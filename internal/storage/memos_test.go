@@ -0,0 +1,44 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSessionKinds(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		start := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+		for i, id := range []string{"meeting", "memo1", "memo2"} {
+			if err := store.CreateSession(id, start.Add(time.Duration(i)*time.Hour)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		for _, id := range []string{"memo1", "memo2"} {
+			if err := store.SetSessionKind(id, SessionKindMemo); err != nil {
+				t.Fatalf("SetSessionKind failed: %v", err)
+			}
+		}
+		if err := store.SetSessionKind("missing", SessionKindMemo); err == nil {
+			t.Fatal("expected an error for a missing session")
+		}
+
+		memos, err := store.GetSessionsByKind(SessionKindMemo)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(memos) != 2 || memos[0].ID != "memo2" || memos[1].ID != "memo1" || memos[0].Kind != SessionKindMemo {
+			t.Fatalf("unexpected memos %+v", memos)
+		}
+		if none, _ := store.GetSessionsByKind(""); len(none) != 0 {
+			t.Fatalf("expected no sessions for an empty kind, got %d", len(none))
+		}
+
+		meeting, err := store.GetSession("meeting")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if meeting.Kind != "" {
+			t.Fatalf("expected a regular session, got kind %q", meeting.Kind)
+		}
+	})
+}
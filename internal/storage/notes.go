@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Note is text typed by hand during a session. Offset is where it was taken
+// on the capture timeline the session's segments are timed on, so it can be
+// placed among them.
+type Note struct {
+	ID        int64     `json:"id"`
+	SessionID string    `json:"session_id"`
+	Offset    float64   `json:"offset"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AddNote records a note taken in a session at the given time, offset
+// seconds into the capture timeline.
+func (s *SQLiteStore) AddNote(sessionID string, at time.Time, offset float64, text string) (Note, error) {
+	if _, err := s.GetSession(sessionID); err != nil {
+		return Note{}, err
+	}
+
+	n := Note{
+		SessionID: sessionID,
+		Offset:    max(offset, 0),
+		Text:      strings.TrimSpace(text),
+		CreatedAt: at.UTC(),
+	}
+	res, err := s.db.Exec(
+		`INSERT INTO notes(session_id, offset_seconds, text, created_at, on_timeline) VALUES(?, ?, ?, ?, 1)`,
+		n.SessionID,
+		n.Offset,
+		n.Text,
+		n.CreatedAt.Format(time.RFC3339Nano),
+	)
+	if err != nil {
+		return Note{}, fmt.Errorf("add note for session %s: %w", sessionID, err)
+	}
+	if n.ID, err = res.LastInsertId(); err != nil {
+		return Note{}, fmt.Errorf("add note id: %w", err)
+	}
+	return n, nil
+}
+
+// GetNotes returns a session's notes in the order they were taken.
+func (s *SQLiteStore) GetNotes(sessionID string) ([]Note, error) {
+	rows, err := s.db.Query(
+		`SELECT id, session_id, offset_seconds, text, created_at FROM notes WHERE session_id = ? ORDER BY offset_seconds ASC, id ASC`,
+		sessionID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query notes for session %s: %w", sessionID, err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	notes := make([]Note, 0, 4)
+	for rows.Next() {
+		var n Note
+		var createdAt string
+		if err := rows.Scan(&n.ID, &n.SessionID, &n.Offset, &n.Text, &createdAt); err != nil {
+			return nil, fmt.Errorf("scan note for session %s: %w", sessionID, err)
+		}
+		if n.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAt); err != nil {
+			return nil, fmt.Errorf("parse note timestamp for session %s: %w", sessionID, err)
+		}
+		notes = append(notes, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate note rows for session %s: %w", sessionID, err)
+	}
+	return notes, nil
+}
@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// MaxRefineFailures is how many failed refinement passes a session gets
+// before it is left as transcribed.
+const MaxRefineFailures = 3
+
+// GetSessionsForRefinement returns unlocked ended sessions with audio whose
+// segments have not been revised since transcription, oldest first, and
+// those that failed before after the rest. Sessions whose speakers follow
+// the capture's channels are left alone.
+func (s *SQLiteStore) GetSessionsForRefinement(limit int) ([]Session, error) {
+	rows, err := s.db.Query(
+		`SELECT `+sessionColumns+`
+		 FROM sessions
+		 WHERE status = 'ended' AND audio_path != '' AND segments_version = 1 AND locked = 0
+		   AND channel_speakers = 0 AND refine_failures < ?
+		 ORDER BY refine_failures ASC, started_at ASC
+		 LIMIT ?`,
+		MaxRefineFailures, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query sessions for refinement: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	return scanSessions(rows)
+}
+
+// AddRefineFailure counts a failed refinement pass of a session.
+func (s *SQLiteStore) AddRefineFailure(sessionID string) error {
+	res, err := s.db.Exec(`UPDATE sessions SET refine_failures = refine_failures + 1 WHERE id = ?`, sessionID)
+	if err != nil {
+		return fmt.Errorf("count refine failure for session %s: %w", sessionID, err)
+	}
+	if rows, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("count refine failure rows affected: %w", err)
+	} else if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// RelabelSegmentSpeakers assigns speakers[i] to the i-th segment of a
+// session, in GetSegments order, and bumps the session's segments_version.
+// It fails if the number of segments no longer matches, so a relabeling
+// computed against a stale read is never applied. It returns the new version.
+func (s *SQLiteStore) RelabelSegmentSpeakers(sessionID string, speakers []int) (int, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("begin relabel for session %s: %w", sessionID, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := checkUnlocked(tx, sessionID); err != nil {
+		return 0, fmt.Errorf("relabel session %s: %w", sessionID, err)
+	}
+
+	rows, err := tx.Query(
+		`SELECT id FROM segments WHERE session_id = ? ORDER BY julianday(timestamp) ASC, id ASC`,
+		sessionID,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("query segment ids for session %s: %w", sessionID, err)
+	}
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			_ = rows.Close()
+			return 0, fmt.Errorf("scan segment id for session %s: %w", sessionID, err)
+		}
+		ids = append(ids, id)
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("iterate segment ids for session %s: %w", sessionID, err)
+	}
+	if len(ids) != len(speakers) {
+		return 0, fmt.Errorf("relabel session %s: have %d segments, got %d speakers", sessionID, len(ids), len(speakers))
+	}
+
+	for i, id := range ids {
+		if _, err := tx.Exec(`UPDATE segments SET speaker = ? WHERE id = ?`, speakers[i], id); err != nil {
+			return 0, fmt.Errorf("update segment %d speaker: %w", id, err)
+		}
+	}
+
+	var version int
+	if err := tx.QueryRow(
+		`UPDATE sessions SET segments_version = segments_version + 1 WHERE id = ? RETURNING segments_version`,
+		sessionID,
+	).Scan(&version); err != nil {
+		return 0, fmt.Errorf("bump segments version for session %s: %w", sessionID, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit relabel for session %s: %w", sessionID, err)
+	}
+	return version, nil
+}
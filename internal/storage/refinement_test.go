@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/transcribe"
+)
+
+func TestRelabelSegmentSpeakers(t *testing.T) {
+	store := newTestSQLiteStore(t)
+
+	startedAt := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	if err := store.CreateSession("s1", startedAt); err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+	for i, text := range []string{"hello", "hi there", "bye"} {
+		seg := transcribe.Segment{Speaker: 0, Text: text, Timestamp: startedAt.Add(time.Duration(i) * time.Second)}
+		if err := store.AppendSegment("s1", seg); err != nil {
+			t.Fatalf("AppendSegment failed: %v", err)
+		}
+	}
+	if err := store.EndSession("s1", startedAt.Add(time.Minute), "data/audio/s1.mp3"); err != nil {
+		t.Fatalf("EndSession failed: %v", err)
+	}
+
+	pending, err := store.GetSessionsForRefinement(10)
+	if err != nil {
+		t.Fatalf("GetSessionsForRefinement failed: %v", err)
+	}
+	if len(pending) != 1 || pending[0].SegmentsVersion != 1 {
+		t.Fatalf("expected one unrefined session at version 1, got %+v", pending)
+	}
+
+	if _, err := store.RelabelSegmentSpeakers("s1", []int{0, 1}); err == nil {
+		t.Fatal("expected mismatched speaker count to fail")
+	}
+
+	version, err := store.RelabelSegmentSpeakers("s1", []int{0, 1, 0})
+	if err != nil {
+		t.Fatalf("RelabelSegmentSpeakers failed: %v", err)
+	}
+	if version != 2 {
+		t.Fatalf("expected version 2, got %d", version)
+	}
+
+	segments, err := store.GetSegments("s1")
+	if err != nil {
+		t.Fatalf("GetSegments failed: %v", err)
+	}
+	if segments[0].Speaker != 0 || segments[1].Speaker != 1 || segments[2].Speaker != 0 {
+		t.Fatalf("unexpected speakers after relabel: %+v", segments)
+	}
+
+	pending, err = store.GetSessionsForRefinement(10)
+	if err != nil {
+		t.Fatalf("GetSessionsForRefinement failed: %v", err)
+	}
+	if len(pending) != 0 {
+		t.Fatalf("expected refined session to be skipped, got %+v", pending)
+	}
+}
+
+func TestRefinementSkipsChannelSpeakersAndGivesUpOnFailures(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+		for i, id := range []string{"failing", "stereo", "fresh"} {
+			at := start.Add(time.Duration(i) * time.Hour)
+			if err := store.CreateSession(id, at); err != nil {
+				t.Fatalf("CreateSession failed: %v", err)
+			}
+			if err := store.EndSession(id, at.Add(time.Minute), "data/audio/"+id+".mp3"); err != nil {
+				t.Fatalf("EndSession failed: %v", err)
+			}
+		}
+		if err := store.SetChannelSpeakers("stereo", true); err != nil {
+			t.Fatalf("SetChannelSpeakers failed: %v", err)
+		}
+		if err := store.AddRefineFailure("failing"); err != nil {
+			t.Fatalf("AddRefineFailure failed: %v", err)
+		}
+
+		ids := func() []string {
+			t.Helper()
+			pending, err := store.GetSessionsForRefinement(10)
+			if err != nil {
+				t.Fatalf("GetSessionsForRefinement failed: %v", err)
+			}
+			var ids []string
+			for _, sess := range pending {
+				ids = append(ids, sess.ID)
+			}
+			return ids
+		}
+		if got := ids(); !slices.Equal(got, []string{"fresh", "failing"}) {
+			t.Fatalf("expected the failed session after the fresh one and the stereo one skipped, got %v", got)
+		}
+		for range MaxRefineFailures - 1 {
+			if err := store.AddRefineFailure("failing"); err != nil {
+				t.Fatalf("AddRefineFailure failed: %v", err)
+			}
+		}
+		if got := ids(); !slices.Equal(got, []string{"fresh"}) {
+			t.Fatalf("expected the session given up on after %d failures, got %v", MaxRefineFailures, got)
+		}
+	})
+}
@@ -0,0 +1,178 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Report is a weekly operational report: how storage grew over the week
+// and what was transcribed, spent and failed.
+type Report struct {
+	// ID is the ISO week covered, such as "2026-W42".
+	ID          string    `json:"id"`
+	PeriodStart time.Time `json:"period_start"`
+	PeriodEnd   time.Time `json:"period_end"`
+	CreatedAt   time.Time `json:"created_at"`
+
+	DatabaseBytes int64 `json:"database_bytes"`
+	// DatabaseGrowthBytes is the change since the previous report, zero
+	// for the first.
+	DatabaseGrowthBytes int64   `json:"database_growth_bytes"`
+	AudioBytesAdded     int64   `json:"audio_bytes_added"`
+	Sessions            int     `json:"sessions"`
+	TranscribedMinutes  float64 `json:"transcribed_minutes"`
+	TranscriptionCost   float64 `json:"transcription_cost_usd"`
+	LLMInputTokens      int     `json:"llm_input_tokens"`
+	LLMOutputTokens     int     `json:"llm_output_tokens"`
+	LLMCost             float64 `json:"llm_cost_usd"`
+	SummaryFailures     int     `json:"summary_failures"`
+	AudioFailures       int     `json:"audio_failures"`
+	ExportFailures      int     `json:"export_failures"`
+
+	// Text is the report written out for people, as notifications carry it.
+	Text string `json:"text"`
+}
+
+// DatabaseSize returns the size of the database in bytes.
+func (s *SQLiteStore) DatabaseSize() (int64, error) {
+	var pages, pageSize int64
+	if err := s.db.QueryRow(`PRAGMA page_count`).Scan(&pages); err != nil {
+		return 0, fmt.Errorf("query page count: %w", err)
+	}
+	if err := s.db.QueryRow(`PRAGMA page_size`).Scan(&pageSize); err != nil {
+		return 0, fmt.Errorf("query page size: %w", err)
+	}
+	return pages * pageSize, nil
+}
+
+// IndexSize is how much of the database one index takes.
+type IndexSize struct {
+	Name  string `json:"name"`
+	Bytes int64  `json:"bytes"`
+}
+
+// IndexSizes returns the size of each index in the database, largest first.
+func (s *SQLiteStore) IndexSizes() ([]IndexSize, error) {
+	rows, err := s.db.Query(
+		`SELECT d.name, SUM(d.pgsize) FROM dbstat d
+		JOIN sqlite_schema m ON m.name = d.name AND m.type = 'index'
+		GROUP BY d.name ORDER BY SUM(d.pgsize) DESC, d.name`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query index sizes: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	var sizes []IndexSize
+	for rows.Next() {
+		var size IndexSize
+		if err := rows.Scan(&size.Name, &size.Bytes); err != nil {
+			return nil, fmt.Errorf("scan index size: %w", err)
+		}
+		sizes = append(sizes, size)
+	}
+	return sizes, rows.Err()
+}
+
+// SaveReport stores a report, replacing any earlier one for the same week.
+func (s *SQLiteStore) SaveReport(r Report) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("encode report %s: %w", r.ID, err)
+	}
+	if _, err := s.db.Exec(
+		`INSERT INTO reports(id, period_start, data) VALUES(?, ?, ?)
+		 ON CONFLICT(id) DO UPDATE SET period_start = excluded.period_start, data = excluded.data`,
+		r.ID, r.PeriodStart.UTC().Format(time.RFC3339Nano), string(data),
+	); err != nil {
+		return fmt.Errorf("save report %s: %w", r.ID, err)
+	}
+	return nil
+}
+
+// GetReport returns the report with the given ID, or sql.ErrNoRows.
+func (s *SQLiteStore) GetReport(id string) (Report, error) {
+	var data string
+	err := s.db.QueryRow(`SELECT data FROM reports WHERE id = ?`, id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Report{}, fmt.Errorf("query report %s: %w", id, sql.ErrNoRows)
+	}
+	if err != nil {
+		return Report{}, fmt.Errorf("query report %s: %w", id, err)
+	}
+	var r Report
+	if err := json.Unmarshal([]byte(data), &r); err != nil {
+		return Report{}, fmt.Errorf("decode report %s: %w", id, err)
+	}
+	return r, nil
+}
+
+// ListReports returns every report, latest week first.
+func (s *SQLiteStore) ListReports() ([]Report, error) {
+	rows, err := s.db.Query(`SELECT data FROM reports ORDER BY period_start DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("query reports: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	reports := make([]Report, 0, 4)
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("scan report: %w", err)
+		}
+		var r Report
+		if err := json.Unmarshal([]byte(data), &r); err != nil {
+			return nil, fmt.Errorf("decode report: %w", err)
+		}
+		reports = append(reports, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate reports: %w", err)
+	}
+	return reports, nil
+}
+
+// DatabaseSize is always zero: nothing is stored on disk.
+func (s *MemoryStore) DatabaseSize() (int64, error) {
+	return 0, nil
+}
+
+// IndexSizes is always empty: there are no indexes.
+func (s *MemoryStore) IndexSizes() ([]IndexSize, error) {
+	return nil, nil
+}
+
+// SaveReport stores a report, replacing any earlier one for the same week.
+func (s *MemoryStore) SaveReport(r Report) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reports[r.ID] = r
+	return nil
+}
+
+// GetReport returns the report with the given ID, or sql.ErrNoRows.
+func (s *MemoryStore) GetReport(id string) (Report, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	r, ok := s.reports[id]
+	if !ok {
+		return Report{}, fmt.Errorf("query report %s: %w", id, sql.ErrNoRows)
+	}
+	return r, nil
+}
+
+// ListReports returns every report, latest week first.
+func (s *MemoryStore) ListReports() ([]Report, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	reports := make([]Report, 0, len(s.reports))
+	for _, r := range s.reports {
+		reports = append(reports, r)
+	}
+	sort.Slice(reports, func(i, j int) bool { return reports[i].PeriodStart.After(reports[j].PeriodStart) })
+	return reports, nil
+}
@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestReports(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		if _, err := store.GetReport("2026-W41"); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("expected sql.ErrNoRows before any report, got %v", err)
+		}
+		if size, err := store.DatabaseSize(); err != nil || size < 0 {
+			t.Fatalf("DatabaseSize = %d, %v", size, err)
+		}
+
+		w41 := Report{ID: "2026-W41", PeriodStart: time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC), PeriodEnd: time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC), Sessions: 3}
+		w42 := Report{ID: "2026-W42", PeriodStart: time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC), PeriodEnd: time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC), LLMCost: 1.25, Text: "first"}
+		for _, r := range []Report{w42, w41} {
+			if err := store.SaveReport(r); err != nil {
+				t.Fatalf("SaveReport failed: %v", err)
+			}
+		}
+		w42.Text = "rewritten"
+		if err := store.SaveReport(w42); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := store.GetReport("2026-W42")
+		if err != nil {
+			t.Fatalf("GetReport failed: %v", err)
+		}
+		if got.Text != "rewritten" || got.LLMCost != 1.25 || !got.PeriodEnd.Equal(w42.PeriodEnd) {
+			t.Fatalf("expected the rewritten W42 report, got %+v", got)
+		}
+		reports, err := store.ListReports()
+		if err != nil {
+			t.Fatalf("ListReports failed: %v", err)
+		}
+		if len(reports) != 2 || reports[0].ID != "2026-W42" || reports[1].ID != "2026-W41" || reports[1].Sessions != 3 {
+			t.Fatalf("expected W42 then W41, got %+v", reports)
+		}
+	})
+}
+
+func TestSQLiteIndexSizes(t *testing.T) {
+	store := newTestSQLiteStore(t)
+	sizes, err := store.IndexSizes()
+	if err != nil {
+		t.Fatalf("IndexSizes failed: %v", err)
+	}
+	if len(sizes) == 0 {
+		t.Fatal("expected the schema's indexes to be measured")
+	}
+	for i, size := range sizes {
+		if size.Name == "" || size.Bytes <= 0 {
+			t.Fatalf("unexpected index size %+v", size)
+		}
+		if i > 0 && size.Bytes > sizes[i-1].Bytes {
+			t.Fatalf("expected largest first, got %+v", sizes)
+		}
+	}
+}
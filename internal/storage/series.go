@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Series is a recurring meeting: sessions held in the same weekly time slot.
+type Series struct {
+	ID             string    `json:"id"`
+	SessionCount   int       `json:"session_count"`
+	FirstStartedAt time.Time `json:"first_started_at"`
+	LastStartedAt  time.Time `json:"last_started_at"`
+}
+
+// SetSessionSeries threads a session into a series; an empty seriesID
+// removes it from its series.
+func (s *SQLiteStore) SetSessionSeries(sessionID, seriesID string) error {
+	res, err := s.db.Exec(`UPDATE sessions SET series_id = ? WHERE id = ?`, seriesID, sessionID)
+	if err != nil {
+		return fmt.Errorf("set series for session %s: %w", sessionID, err)
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("set series rows affected: %w", err)
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// GetSeriesSessions returns the sessions of a series, oldest first.
+func (s *SQLiteStore) GetSeriesSessions(seriesID string) ([]Session, error) {
+	rows, err := s.db.Query(
+		`SELECT `+sessionColumns+` FROM sessions WHERE series_id = ? AND series_id != '' ORDER BY started_at ASC`,
+		seriesID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query sessions for series %s: %w", seriesID, err)
+	}
+	defer func() { _ = rows.Close() }()
+	return scanSessions(rows)
+}
+
+// ListSeries returns every series, most recently active first.
+func (s *SQLiteStore) ListSeries() ([]Series, error) {
+	rows, err := s.db.Query(`
+		SELECT series_id, COUNT(*), MIN(started_at), MAX(started_at)
+		FROM sessions
+		WHERE series_id != ''
+		GROUP BY series_id
+		ORDER BY MAX(started_at) DESC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("query series: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	series := make([]Series, 0, 8)
+	for rows.Next() {
+		var sr Series
+		var first, last string
+		if err := rows.Scan(&sr.ID, &sr.SessionCount, &first, &last); err != nil {
+			return nil, fmt.Errorf("scan series: %w", err)
+		}
+		if sr.FirstStartedAt, err = time.Parse(time.RFC3339Nano, first); err != nil {
+			return nil, fmt.Errorf("parse series %s start: %w", sr.ID, err)
+		}
+		if sr.LastStartedAt, err = time.Parse(time.RFC3339Nano, last); err != nil {
+			return nil, fmt.Errorf("parse series %s start: %w", sr.ID, err)
+		}
+		series = append(series, sr)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate series rows: %w", err)
+	}
+	return series, nil
+}
@@ -0,0 +1,44 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSeriesStorage(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		start := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+		for i, id := range []string{"a", "b", "c"} {
+			if err := store.CreateSession(id, start.AddDate(0, 0, 7*i)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		for _, id := range []string{"b", "a"} {
+			if err := store.SetSessionSeries(id, "series-a"); err != nil {
+				t.Fatalf("SetSessionSeries failed: %v", err)
+			}
+		}
+		if err := store.SetSessionSeries("missing", "series-a"); err == nil {
+			t.Fatal("expected an error for a missing session")
+		}
+
+		sessions, err := store.GetSeriesSessions("series-a")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(sessions) != 2 || sessions[0].ID != "a" || sessions[1].SeriesID != "series-a" {
+			t.Fatalf("unexpected series sessions %+v", sessions)
+		}
+		if none, _ := store.GetSeriesSessions(""); len(none) != 0 {
+			t.Fatalf("expected no sessions for an empty series id, got %d", len(none))
+		}
+
+		series, err := store.ListSeries()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(series) != 1 || series[0].SessionCount != 2 || !series[0].FirstStartedAt.Equal(start) || !series[0].LastStartedAt.Equal(start.AddDate(0, 0, 7)) {
+			t.Fatalf("unexpected series %+v", series)
+		}
+	})
+}
@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SetSpeakerName names a diarized speaker of a session, replacing any
+// earlier name. An empty name forgets it, so the speaker is "Speaker N"
+// again.
+func (s *SQLiteStore) SetSpeakerName(sessionID string, speaker int, name string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin speaker name tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := checkUnlocked(tx, sessionID); err != nil {
+		return fmt.Errorf("name speaker %d of session %s: %w", speaker, sessionID, err)
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		_, err = tx.Exec(`DELETE FROM speakers WHERE session_id = ? AND speaker = ?`, sessionID, speaker)
+	} else {
+		_, err = tx.Exec(
+			`INSERT INTO speakers(session_id, speaker, name) VALUES(?, ?, ?)
+			ON CONFLICT(session_id, speaker) DO UPDATE SET name = excluded.name`,
+			sessionID, speaker, name,
+		)
+	}
+	if err != nil {
+		return fmt.Errorf("name speaker %d of session %s: %w", speaker, sessionID, err)
+	}
+	return tx.Commit()
+}
+
+// GetSpeakerNames returns the names given to a session's speakers, keyed by
+// speaker index.
+func (s *SQLiteStore) GetSpeakerNames(sessionID string) (map[int]string, error) {
+	rows, err := s.db.Query(`SELECT speaker, name FROM speakers WHERE session_id = ?`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("query speaker names for session %s: %w", sessionID, err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	names := make(map[int]string)
+	for rows.Next() {
+		var speaker int
+		var name string
+		if err := rows.Scan(&speaker, &name); err != nil {
+			return nil, fmt.Errorf("scan speaker name for session %s: %w", sessionID, err)
+		}
+		names[speaker] = name
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate speaker names for session %s: %w", sessionID, err)
+	}
+	return names, nil
+}
+
+// SetSpeakerName names a diarized speaker of a session.
+func (s *MemoryStore) SetSpeakerName(sessionID string, speaker int, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.unlockedSession(sessionID); err != nil {
+		return fmt.Errorf("name speaker %d of session %s: %w", speaker, sessionID, err)
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		delete(s.speakerNames[sessionID], speaker)
+		return nil
+	}
+	if s.speakerNames[sessionID] == nil {
+		s.speakerNames[sessionID] = make(map[int]string)
+	}
+	s.speakerNames[sessionID][speaker] = name
+	return nil
+}
+
+// GetSpeakerNames returns the names given to a session's speakers.
+func (s *MemoryStore) GetSpeakerNames(sessionID string) (map[int]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	names := make(map[int]string, len(s.speakerNames[sessionID]))
+	for speaker, name := range s.speakerNames[sessionID] {
+		names[speaker] = name
+	}
+	return names, nil
+}
@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSpeakerNames(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		if err := store.CreateSession("s1", time.Date(2026, 3, 4, 9, 0, 0, 0, time.UTC)); err != nil {
+			t.Fatal(err)
+		}
+		for speaker, name := range map[int]string{0: "Alice", 1: "Bob"} {
+			if err := store.SetSpeakerName("s1", speaker, name); err != nil {
+				t.Fatalf("SetSpeakerName failed: %v", err)
+			}
+		}
+		if err := store.SetSpeakerName("s1", 1, " Robert "); err != nil {
+			t.Fatal(err)
+		}
+		names, err := store.GetSpeakerNames("s1")
+		if err != nil {
+			t.Fatalf("GetSpeakerNames failed: %v", err)
+		}
+		if want := map[int]string{0: "Alice", 1: "Robert"}; !reflect.DeepEqual(names, want) {
+			t.Fatalf("expected %v, got %v", want, names)
+		}
+
+		if err := store.SetSpeakerName("s1", 0, ""); err != nil {
+			t.Fatal(err)
+		}
+		if names, _ := store.GetSpeakerNames("s1"); !reflect.DeepEqual(names, map[int]string{1: "Robert"}) {
+			t.Fatalf("expected Alice forgotten, got %v", names)
+		}
+
+		if err := store.SetSpeakerName("missing", 0, "Alice"); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("expected sql.ErrNoRows for a missing session, got %v", err)
+		}
+		if err := store.SetSessionLocked("s1", true); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.SetSpeakerName("s1", 0, "Alice"); !errors.Is(err, ErrSessionLocked) {
+			t.Fatalf("expected ErrSessionLocked, got %v", err)
+		}
+		if err := store.SetSessionLocked("s1", false); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := store.DeleteSession("s1"); err != nil {
+			t.Fatal(err)
+		}
+		if names, err := store.GetSpeakerNames("s1"); err != nil || len(names) != 0 {
+			t.Fatalf("expected names deleted with the session, got %v (%v)", names, err)
+		}
+	})
+}
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
@@ -19,6 +20,22 @@ const (
 	SummaryRunning   = "running"
 	SummaryCompleted = "completed"
 	SummaryFailed    = "failed"
+	// SummaryQueuedOffline marks a summary that failed for lack of network
+	// connectivity and will be retried once the network is reachable again.
+	SummaryQueuedOffline = "queued_offline"
+	// SummaryCancelled marks a summary stopped from the summary queue.
+	SummaryCancelled = "cancelled"
+	// SummarySkippedBudget marks an automatic summary skipped because the
+	// monthly LLM budget was spent.
+	SummarySkippedBudget = "skipped_budget"
+)
+
+// Audio statuses of a session's recording once the session has ended. A
+// session recorded before they were tracked has none.
+const (
+	AudioEncoding = "encoding"
+	AudioReady    = "ready"
+	AudioFailed   = "failed"
 )
 
 type Session struct {
@@ -29,11 +46,71 @@ type Session struct {
 	Summary       string     `json:"summary"`
 	SummaryStatus string     `json:"summary_status"`
 	SummaryPreset string     `json:"summary_preset"`
-	AudioPath     string     `json:"audio_path"`
+	// AudioPath is the session's primary audio file; GetAudioFiles lists it
+	// with any others.
+	AudioPath string `json:"audio_path"`
+	// AudioStatus is AudioEncoding until AudioPath can be played.
+	AudioStatus string `json:"audio_status,omitempty"`
+	// AudioOffset is where AudioPath starts on the capture timeline that
+	// segment times are measured on, in seconds: a segment is heard
+	// StartTime-AudioOffset seconds into the recording.
+	AudioOffset float64 `json:"audio_offset,omitempty"`
+	// ClockBootID and ClockBoot anchor the session's start on the boot
+	// clock of the boot identified, so a later run in the same boot can
+	// still tell how long ago it started.
+	ClockBootID string        `json:"-"`
+	ClockBoot   time.Duration `json:"-"`
+	// SummaryAudioPath is the spoken rendition of Summary, if one was made.
+	SummaryAudioPath string `json:"summary_audio_path,omitempty"`
+	// SegmentsVersion increases each time stored segments are revised after
+	// transcription, e.g. by speaker refinement.
+	SegmentsVersion int `json:"segments_version"`
+	// SeriesID threads recurring meetings together; empty for one-offs.
+	SeriesID string `json:"series_id,omitempty"`
+	// Kind is SessionKindMemo for voice memos; empty for sessions recorded
+	// by the normal silence-timeout flow.
+	Kind string `json:"kind,omitempty"`
+	// Recovered marks a session whose audio was left unencoded by a run
+	// that stopped mid-session and was encoded at a later startup.
+	Recovered bool `json:"recovered,omitempty"`
+	// TranscriptionModel and TranscriptionLanguage are what the transcript
+	// was made with, and SummaryModel the "provider/model" that wrote the
+	// summary, so old sessions can be told apart from ones worth redoing.
+	// Sessions from before they were recorded leave them empty.
+	TranscriptionModel    string `json:"transcription_model,omitempty"`
+	TranscriptionLanguage string `json:"transcription_language,omitempty"`
+	SummaryModel          string `json:"summary_model,omitempty"`
+	// TranscriptionProviders are the live transcription services that
+	// transcribed the session, in the order they took over.
+	TranscriptionProviders []string `json:"transcription_providers,omitempty"`
+	// KeepWAV asks for the session's lossless audio to be kept alongside
+	// its compressed recording; WAVPath is where it was kept.
+	KeepWAV bool   `json:"keep_wav,omitempty"`
+	WAVPath string `json:"wav_path,omitempty"`
+	// GDocID is the Google Doc the summary was exported to for review.
+	GDocID string `json:"gdoc_id,omitempty"`
+	// JournalDocID is the daily journal doc the summary was appended to.
+	JournalDocID string `json:"journal_doc_id,omitempty"`
+	// ChannelSpeakers marks sessions whose speakers follow the capture's
+	// channels rather than diarization.
+	ChannelSpeakers bool `json:"channel_speakers,omitempty"`
+	// RefineFailures counts the speaker refinement passes that failed.
+	RefineFailures int `json:"refine_failures,omitempty"`
+	// LastViewedAt is when the session was last opened in the UI; summaries
+	// of sessions never opened are unreviewed.
+	LastViewedAt *time.Time `json:"last_viewed_at,omitempty"`
+	// Locked sessions can't be edited, resummarized or deleted until they
+	// are unlocked.
+	Locked bool `json:"locked"`
 }
 
+// sessionColumns lists the sessions columns read by scanSession, in order.
+const sessionColumns = "id, started_at, ended_at, status, summary, summary_status, summary_preset, audio_path, summary_audio_path, segments_version, series_id, kind, recovered, audio_status, transcription_model, transcription_language, summary_model, keep_wav, wav_path, gdoc_id, last_viewed_at, transcription_providers, locked, audio_offset, clock_boot_id, clock_boot, journal_doc_id, channel_speakers, refine_failures"
+
 type SQLiteStore struct {
 	db *sql.DB
+	// path is the database file, which Snapshot opens again.
+	path string
 }
 
 func NewSQLiteStore(dbPath string) (*SQLiteStore, error) {
@@ -53,7 +130,7 @@ func NewSQLiteStore(dbPath string) (*SQLiteStore, error) {
 	db.SetMaxOpenConns(1)
 	db.SetMaxIdleConns(1)
 
-	store := &SQLiteStore{db: db}
+	store := &SQLiteStore{db: db, path: dbPath}
 	if err := store.init(); err != nil {
 		_ = db.Close()
 		return nil, err
@@ -62,6 +139,42 @@ func NewSQLiteStore(dbPath string) (*SQLiteStore, error) {
 	return store, nil
 }
 
+// SetCacheSize caps SQLite's page cache at kib kibibytes per connection and
+// disables memory-mapped I/O, trading query speed for a smaller footprint.
+func (s *SQLiteStore) SetCacheSize(kib int) error {
+	if kib <= 0 {
+		return nil
+	}
+	for _, p := range []string{
+		fmt.Sprintf("PRAGMA cache_size = -%d", kib),
+		"PRAGMA mmap_size = 0",
+		"PRAGMA temp_store = FILE",
+	} {
+		if _, err := s.db.Exec(p); err != nil {
+			return fmt.Errorf("apply pragma %q: %w", p, err)
+		}
+	}
+	return nil
+}
+
+// Snapshot writes a consistent, compacted copy of the database to path with
+// VACUUM INTO, and path must not already exist. The store's pool holds a
+// single connection, so the copy runs on a read-only connection of its own:
+// under WAL it reads one snapshot of the database while the store keeps
+// reading and writing, instead of waiting for the copy to finish.
+func (s *SQLiteStore) Snapshot(path string) error {
+	escaped := strings.NewReplacer("%", "%25", "?", "%3f", "#", "%23").Replace(s.path)
+	db, err := sql.Open("sqlite", "file:"+escaped+"?mode=ro&_pragma=busy_timeout(5000)")
+	if err != nil {
+		return fmt.Errorf("open database to snapshot: %w", err)
+	}
+	defer func() { _ = db.Close() }()
+	if _, err := db.Exec(`VACUUM INTO ?`, path); err != nil {
+		return fmt.Errorf("snapshot database to %s: %w", path, err)
+	}
+	return nil
+}
+
 func (s *SQLiteStore) init() error {
 	pragmas := []string{
 		"PRAGMA journal_mode = WAL",
@@ -91,6 +204,30 @@ func (s *SQLiteStore) init() error {
 
 	// Migrate: add summary_preset column if it doesn't exist (for pre-existing DBs).
 	_, _ = s.db.Exec(`ALTER TABLE sessions ADD COLUMN summary_preset TEXT NOT NULL DEFAULT ''`)
+	_, _ = s.db.Exec(`ALTER TABLE sessions ADD COLUMN summary_audio_path TEXT NOT NULL DEFAULT ''`)
+	_, _ = s.db.Exec(`ALTER TABLE sessions ADD COLUMN segments_version INTEGER NOT NULL DEFAULT 1`)
+	_, _ = s.db.Exec(`ALTER TABLE sessions ADD COLUMN series_id TEXT NOT NULL DEFAULT ''`)
+	_, _ = s.db.Exec(`ALTER TABLE sessions ADD COLUMN kind TEXT NOT NULL DEFAULT ''`)
+	_, _ = s.db.Exec(`ALTER TABLE sessions ADD COLUMN recovered INTEGER NOT NULL DEFAULT 0`)
+	_, _ = s.db.Exec(`ALTER TABLE sessions ADD COLUMN audio_status TEXT NOT NULL DEFAULT ''`)
+	_, _ = s.db.Exec(`ALTER TABLE sessions ADD COLUMN transcription_model TEXT NOT NULL DEFAULT ''`)
+	_, _ = s.db.Exec(`ALTER TABLE sessions ADD COLUMN transcription_language TEXT NOT NULL DEFAULT ''`)
+	_, _ = s.db.Exec(`ALTER TABLE sessions ADD COLUMN summary_model TEXT NOT NULL DEFAULT ''`)
+	_, _ = s.db.Exec(`ALTER TABLE sessions ADD COLUMN keep_wav INTEGER NOT NULL DEFAULT 0`)
+	_, _ = s.db.Exec(`ALTER TABLE sessions ADD COLUMN wav_path TEXT NOT NULL DEFAULT ''`)
+	_, _ = s.db.Exec(`ALTER TABLE sessions ADD COLUMN gdoc_id TEXT NOT NULL DEFAULT ''`)
+	_, _ = s.db.Exec(`ALTER TABLE sessions ADD COLUMN last_viewed_at TEXT`)
+	_, _ = s.db.Exec(`ALTER TABLE sessions ADD COLUMN transcription_providers TEXT NOT NULL DEFAULT ''`)
+	_, _ = s.db.Exec(`ALTER TABLE sessions ADD COLUMN locked INTEGER NOT NULL DEFAULT 0`)
+	_, _ = s.db.Exec(`ALTER TABLE sessions ADD COLUMN audio_offset REAL NOT NULL DEFAULT 0`)
+	_, _ = s.db.Exec(`ALTER TABLE sessions ADD COLUMN clock_boot_id TEXT NOT NULL DEFAULT ''`)
+	_, _ = s.db.Exec(`ALTER TABLE sessions ADD COLUMN clock_boot INTEGER NOT NULL DEFAULT 0`)
+	_, _ = s.db.Exec(`ALTER TABLE sessions ADD COLUMN journal_doc_id TEXT NOT NULL DEFAULT ''`)
+	_, _ = s.db.Exec(`ALTER TABLE sessions ADD COLUMN channel_speakers INTEGER NOT NULL DEFAULT 0`)
+	_, _ = s.db.Exec(`ALTER TABLE sessions ADD COLUMN refine_failures INTEGER NOT NULL DEFAULT 0`)
+	if _, err := s.db.Exec(`CREATE INDEX IF NOT EXISTS idx_sessions_series ON sessions(series_id) WHERE series_id != ''`); err != nil {
+		return fmt.Errorf("create sessions series index: %w", err)
+	}
 
 	if _, err := s.db.Exec(`
 		CREATE TABLE IF NOT EXISTS segments (
@@ -106,6 +243,10 @@ func (s *SQLiteStore) init() error {
 	`); err != nil {
 		return fmt.Errorf("create segments table: %w", err)
 	}
+	_, _ = s.db.Exec(`ALTER TABLE segments ADD COLUMN language TEXT NOT NULL DEFAULT ''`)
+	_, _ = s.db.Exec(`ALTER TABLE segments ADD COLUMN confidence REAL NOT NULL DEFAULT 0`)
+	// words holds the segment's timed words as JSON, '' when it has none.
+	_, _ = s.db.Exec(`ALTER TABLE segments ADD COLUMN words TEXT NOT NULL DEFAULT ''`)
 
 	if _, err := s.db.Exec(`
 		CREATE TABLE IF NOT EXISTS summary_requests (
@@ -118,12 +259,256 @@ func (s *SQLiteStore) init() error {
 		return fmt.Errorf("create summary_requests table: %w", err)
 	}
 
+	if _, err := s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS decisions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			session_id TEXT NOT NULL,
+			text TEXT NOT NULL,
+			participants TEXT NOT NULL DEFAULT '[]',
+			timestamp TEXT NOT NULL,
+			FOREIGN KEY(session_id) REFERENCES sessions(id) ON DELETE CASCADE
+		);
+	`); err != nil {
+		return fmt.Errorf("create decisions table: %w", err)
+	}
+
+	if _, err := s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS action_items (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			session_id TEXT NOT NULL,
+			text TEXT NOT NULL,
+			owner TEXT NOT NULL DEFAULT '',
+			done INTEGER NOT NULL DEFAULT 0,
+			timestamp TEXT NOT NULL,
+			FOREIGN KEY(session_id) REFERENCES sessions(id) ON DELETE CASCADE
+		);
+	`); err != nil {
+		return fmt.Errorf("create action_items table: %w", err)
+	}
+
+	if _, err := s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS summary_citations (
+			session_id TEXT NOT NULL,
+			bullet INTEGER NOT NULL,
+			line INTEGER NOT NULL,
+			text TEXT NOT NULL,
+			first_segment_id INTEGER NOT NULL,
+			last_segment_id INTEGER NOT NULL,
+			start_time REAL NOT NULL,
+			end_time REAL NOT NULL,
+			score REAL NOT NULL,
+			PRIMARY KEY(session_id, bullet),
+			FOREIGN KEY(session_id) REFERENCES sessions(id) ON DELETE CASCADE
+		);
+	`); err != nil {
+		return fmt.Errorf("create summary_citations table: %w", err)
+	}
+
+	if _, err := s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS annotations (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			session_id TEXT NOT NULL,
+			external_id TEXT NOT NULL,
+			kind TEXT NOT NULL,
+			author TEXT NOT NULL DEFAULT '',
+			text TEXT NOT NULL,
+			quote TEXT NOT NULL DEFAULT '',
+			resolved INTEGER NOT NULL DEFAULT 0,
+			created_at TEXT NOT NULL,
+			FOREIGN KEY(session_id) REFERENCES sessions(id) ON DELETE CASCADE
+		);
+	`); err != nil {
+		return fmt.Errorf("create annotations table: %w", err)
+	}
+
+	if _, err := s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS audio_files (
+			session_id TEXT NOT NULL,
+			position INTEGER NOT NULL,
+			kind TEXT NOT NULL,
+			path TEXT NOT NULL,
+			created_at TEXT NOT NULL,
+			PRIMARY KEY(session_id, position),
+			FOREIGN KEY(session_id) REFERENCES sessions(id) ON DELETE CASCADE
+		);
+	`); err != nil {
+		return fmt.Errorf("create audio_files table: %w", err)
+	}
+	// Migrate: sessions from before audio_files only have audio_path.
+	if _, err := s.db.Exec(`
+		INSERT OR IGNORE INTO audio_files(session_id, position, kind, path, created_at)
+		SELECT id, 0, CASE WHEN recovered THEN 'recovered' ELSE 'recording' END, audio_path, COALESCE(ended_at, started_at)
+		FROM sessions WHERE audio_path != ''
+	`); err != nil {
+		return fmt.Errorf("backfill audio_files: %w", err)
+	}
+
+	if _, err := s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS briefings (
+			id TEXT PRIMARY KEY,
+			title TEXT NOT NULL,
+			attendees TEXT NOT NULL DEFAULT '[]',
+			starts_at TEXT NOT NULL,
+			ends_at TEXT,
+			brief TEXT NOT NULL DEFAULT '',
+			session_ids TEXT NOT NULL DEFAULT '[]',
+			created_at TEXT NOT NULL,
+			delivered_at TEXT
+		);
+	`); err != nil {
+		return fmt.Errorf("create briefings table: %w", err)
+	}
+	_, _ = s.db.Exec(`ALTER TABLE briefings ADD COLUMN ends_at TEXT`)
+
+	if _, err := s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS bookmarks (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			session_id TEXT NOT NULL,
+			offset_seconds REAL NOT NULL,
+			note TEXT NOT NULL DEFAULT '',
+			created_at TEXT NOT NULL,
+			on_timeline INTEGER NOT NULL DEFAULT 0,
+			FOREIGN KEY(session_id) REFERENCES sessions(id) ON DELETE CASCADE
+		);
+	`); err != nil {
+		return fmt.Errorf("create bookmarks table: %w", err)
+	}
+	_, _ = s.db.Exec(`ALTER TABLE bookmarks ADD COLUMN on_timeline INTEGER NOT NULL DEFAULT 0`)
+	// Migrate: bookmarks used to be timed from the session's start, like
+	// notes.
+	if _, err := s.db.Exec(`
+		UPDATE bookmarks SET on_timeline = 1,
+			offset_seconds = offset_seconds + COALESCE((SELECT audio_offset FROM sessions WHERE sessions.id = bookmarks.session_id), 0)
+		WHERE on_timeline = 0
+	`); err != nil {
+		return fmt.Errorf("move bookmarks onto the capture timeline: %w", err)
+	}
+
+	if _, err := s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS notes (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			session_id TEXT NOT NULL,
+			offset_seconds REAL NOT NULL,
+			text TEXT NOT NULL,
+			created_at TEXT NOT NULL,
+			on_timeline INTEGER NOT NULL DEFAULT 0,
+			FOREIGN KEY(session_id) REFERENCES sessions(id) ON DELETE CASCADE
+		);
+	`); err != nil {
+		return fmt.Errorf("create notes table: %w", err)
+	}
+	_, _ = s.db.Exec(`ALTER TABLE notes ADD COLUMN on_timeline INTEGER NOT NULL DEFAULT 0`)
+	// Migrate: notes used to be timed from the session's start; the timeline
+	// their segments are on starts at the session's audio offset.
+	if _, err := s.db.Exec(`
+		UPDATE notes SET on_timeline = 1,
+			offset_seconds = offset_seconds + COALESCE((SELECT audio_offset FROM sessions WHERE sessions.id = notes.session_id), 0)
+		WHERE on_timeline = 0
+	`); err != nil {
+		return fmt.Errorf("move notes onto the capture timeline: %w", err)
+	}
+
+	if _, err := s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS session_usage (
+			session_id TEXT PRIMARY KEY,
+			transcription_seconds REAL NOT NULL DEFAULT 0,
+			transcription_cost REAL NOT NULL DEFAULT 0,
+			llm_input_tokens INTEGER NOT NULL DEFAULT 0,
+			llm_output_tokens INTEGER NOT NULL DEFAULT 0,
+			llm_cost REAL NOT NULL DEFAULT 0,
+			summary_latency_ms INTEGER NOT NULL DEFAULT 0,
+			FOREIGN KEY(session_id) REFERENCES sessions(id) ON DELETE CASCADE
+		);
+	`); err != nil {
+		return fmt.Errorf("create session_usage table: %w", err)
+	}
+
+	if _, err := s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS audio_calibrations (
+			device TEXT PRIMARY KEY,
+			noise_floor_db REAL NOT NULL,
+			noise_peak_db REAL NOT NULL,
+			threshold_db REAL NOT NULL,
+			seconds REAL NOT NULL DEFAULT 0,
+			calibrated_at TEXT NOT NULL
+		);
+	`); err != nil {
+		return fmt.Errorf("create audio_calibrations table: %w", err)
+	}
+
+	if _, err := s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS exports (
+			session_id TEXT NOT NULL,
+			rule TEXT NOT NULL,
+			target TEXT NOT NULL,
+			status TEXT NOT NULL,
+			location TEXT NOT NULL DEFAULT '',
+			error TEXT NOT NULL DEFAULT '',
+			attempts INTEGER NOT NULL DEFAULT 0,
+			updated_at TEXT NOT NULL,
+			PRIMARY KEY(session_id, rule),
+			FOREIGN KEY(session_id) REFERENCES sessions(id) ON DELETE CASCADE
+		);
+	`); err != nil {
+		return fmt.Errorf("create exports table: %w", err)
+	}
+
+	if _, err := s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS speakers (
+			session_id TEXT NOT NULL,
+			speaker INTEGER NOT NULL,
+			name TEXT NOT NULL,
+			PRIMARY KEY(session_id, speaker),
+			FOREIGN KEY(session_id) REFERENCES sessions(id) ON DELETE CASCADE
+		);
+	`); err != nil {
+		return fmt.Errorf("create speakers table: %w", err)
+	}
+
+	if _, err := s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS voice_profiles (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL,
+			source TEXT NOT NULL DEFAULT '',
+			session_id TEXT NOT NULL DEFAULT '',
+			embedding TEXT NOT NULL,
+			created_at TEXT NOT NULL
+		);
+	`); err != nil {
+		return fmt.Errorf("create voice_profiles table: %w", err)
+	}
+	_, _ = s.db.Exec(`ALTER TABLE voice_profiles ADD COLUMN session_id TEXT NOT NULL DEFAULT ''`)
+	// Migrate: voices learned from a session before session_id only name it
+	// in their source, as "session <id>, speaker <n>".
+	if _, err := s.db.Exec(`
+		UPDATE voice_profiles SET session_id = substr(source, 9, instr(source, ', speaker ') - 9)
+		WHERE session_id = '' AND source LIKE 'session %, speaker %'
+	`); err != nil {
+		return fmt.Errorf("backfill voice profile sessions: %w", err)
+	}
+
+	if _, err := s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS reports (
+			id TEXT PRIMARY KEY,
+			period_start TEXT NOT NULL,
+			data TEXT NOT NULL
+		);
+	`); err != nil {
+		return fmt.Errorf("create reports table: %w", err)
+	}
+
 	if _, err := s.db.Exec("CREATE INDEX IF NOT EXISTS idx_sessions_started_at ON sessions(started_at)"); err != nil {
 		return fmt.Errorf("create sessions index: %w", err)
 	}
 	if _, err := s.db.Exec("CREATE INDEX IF NOT EXISTS idx_segments_session_id ON segments(session_id, timestamp)"); err != nil {
 		return fmt.Errorf("create segments index: %w", err)
 	}
+	if _, err := s.db.Exec("CREATE INDEX IF NOT EXISTS idx_decisions_timestamp ON decisions(timestamp)"); err != nil {
+		return fmt.Errorf("create decisions index: %w", err)
+	}
+	if _, err := s.db.Exec("CREATE INDEX IF NOT EXISTS idx_action_items_session_id ON action_items(session_id)"); err != nil {
+		return fmt.Errorf("create action items index: %w", err)
+	}
 
 	return nil
 }
@@ -157,45 +542,75 @@ func (s *SQLiteStore) CreateSession(id string, startedAt time.Time) error {
 }
 
 func (s *SQLiteStore) EndSession(id string, endedAt time.Time, audioPath string) error {
-	res, err := s.db.Exec(
+	if err := s.updateAudio(id, AudioRecording, audioPath,
 		`UPDATE sessions SET ended_at = ?, status = 'ended', audio_path = ? WHERE id = ?`,
-		endedAt.UTC().Format(time.RFC3339Nano),
-		audioPath,
-		id,
-	)
-	if err != nil {
+		endedAt.UTC().Format(time.RFC3339Nano), audioPath, id,
+	); err != nil {
 		return fmt.Errorf("end session %s: %w", id, err)
 	}
+	return nil
+}
 
-	rows, err := res.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("end session rows affected: %w", err)
+// SetAudioStatus records how encoding a session's audio is going, and the
+// encoded file once it is AudioReady.
+func (s *SQLiteStore) SetAudioStatus(id, status, audioPath string) error {
+	if err := s.updateAudio(id, AudioRecording, audioPath,
+		`UPDATE sessions SET audio_status = ?, audio_path = ? WHERE id = ?`, status, audioPath, id,
+	); err != nil {
+		return fmt.Errorf("set audio status of session %s: %w", id, err)
 	}
-	if rows == 0 {
-		return sql.ErrNoRows
+	return nil
+}
+
+// MarkSessionRecovered attaches audio recovered after a crash to a session
+// and ends it, keeping its end time if it had one.
+func (s *SQLiteStore) MarkSessionRecovered(id string, endedAt time.Time, audioPath string) error {
+	if err := s.updateAudio(id, AudioRecovered, audioPath,
+		`UPDATE sessions SET ended_at = COALESCE(ended_at, ?), status = 'ended', audio_path = ?, audio_status = 'ready', recovered = 1 WHERE id = ?`,
+		endedAt.UTC().Format(time.RFC3339Nano), audioPath, id,
+	); err != nil {
+		return fmt.Errorf("mark session %s recovered: %w", id, err)
 	}
 	return nil
 }
 
 func (s *SQLiteStore) AppendSegment(sessionID string, seg transcribe.Segment) error {
-	_, err := s.db.Exec(
-		`INSERT INTO segments(session_id, speaker, text, start_time, end_time, timestamp) VALUES(?, ?, ?, ?, ?, ?)`,
+	words := ""
+	if len(seg.Words) > 0 {
+		data, err := json.Marshal(seg.Words)
+		if err != nil {
+			return fmt.Errorf("encode segment words for session %s: %w", sessionID, err)
+		}
+		words = string(data)
+	}
+	res, err := s.db.Exec(
+		`INSERT INTO segments(session_id, speaker, text, start_time, end_time, timestamp, language, confidence, words)
+		 SELECT ?, ?, ?, ?, ?, ?, ?, ?, ? FROM sessions WHERE id = ? AND locked = 0`,
 		sessionID,
 		seg.Speaker,
 		strings.TrimSpace(seg.Text),
 		seg.StartTime,
 		seg.EndTime,
 		seg.Timestamp.UTC().Format(time.RFC3339Nano),
+		seg.Language,
+		seg.Confidence,
+		words,
+		sessionID,
 	)
 	if err != nil {
 		return fmt.Errorf("append segment for session %s: %w", sessionID, err)
 	}
+	if rows, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("append segment rows affected: %w", err)
+	} else if rows == 0 {
+		return fmt.Errorf("append segment for session %s: %w", sessionID, lockedOrMissing(s.db, sessionID))
+	}
 	return nil
 }
 
 func (s *SQLiteStore) GetSessionsByDate(date string) ([]Session, error) {
 	rows, err := s.db.Query(
-		`SELECT id, started_at, ended_at, status, summary, summary_status, summary_preset, audio_path
+		`SELECT `+sessionColumns+`
 		 FROM sessions
 		 WHERE substr(started_at, 1, 10) = ?
 		 ORDER BY started_at DESC`,
@@ -209,6 +624,42 @@ func (s *SQLiteStore) GetSessionsByDate(date string) ([]Session, error) {
 	return scanSessions(rows)
 }
 
+// GetSessionsBySummaryStatus returns sessions whose summary is in the given
+// status, oldest first.
+func (s *SQLiteStore) GetSessionsBySummaryStatus(status string) ([]Session, error) {
+	rows, err := s.db.Query(
+		`SELECT `+sessionColumns+`
+		 FROM sessions
+		 WHERE summary_status = ?
+		 ORDER BY started_at ASC`,
+		status,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query sessions by summary status %s: %w", status, err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	return scanSessions(rows)
+}
+
+// GetUnreviewedSessions returns sessions with a completed summary that
+// have never been viewed, newest first.
+func (s *SQLiteStore) GetUnreviewedSessions() ([]Session, error) {
+	rows, err := s.db.Query(
+		`SELECT `+sessionColumns+`
+		 FROM sessions
+		 WHERE summary_status = ? AND summary != '' AND last_viewed_at IS NULL
+		 ORDER BY started_at DESC`,
+		SummaryCompleted,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query unreviewed sessions: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	return scanSessions(rows)
+}
+
 func (s *SQLiteStore) GetDates() ([]string, error) {
 	rows, err := s.db.Query(
 		`SELECT DISTINCT substr(started_at, 1, 10) AS date FROM sessions ORDER BY date DESC`,
@@ -234,41 +685,23 @@ func (s *SQLiteStore) GetDates() ([]string, error) {
 }
 
 func (s *SQLiteStore) GetSession(id string) (Session, error) {
-	row := s.db.QueryRow(
-		`SELECT id, started_at, ended_at, status, summary, summary_status, summary_preset, audio_path FROM sessions WHERE id = ?`,
-		id,
-	)
-
-	var sess Session
-	var startedAt string
-	var endedAt sql.NullString
-	if err := row.Scan(&sess.ID, &startedAt, &endedAt, &sess.Status, &sess.Summary, &sess.SummaryStatus, &sess.SummaryPreset, &sess.AudioPath); err != nil {
-		return Session{}, fmt.Errorf("query session %s: %w", id, err)
-	}
+	row := s.db.QueryRow(`SELECT `+sessionColumns+` FROM sessions WHERE id = ?`, id)
 
-	parsedStart, err := time.Parse(time.RFC3339Nano, startedAt)
+	sess, err := scanSession(row)
 	if err != nil {
-		return Session{}, fmt.Errorf("parse session %s started_at: %w", id, err)
-	}
-	sess.StartedAt = parsedStart
-
-	if endedAt.Valid {
-		parsedEnd, err := time.Parse(time.RFC3339Nano, endedAt.String)
-		if err != nil {
-			return Session{}, fmt.Errorf("parse session %s ended_at: %w", id, err)
-		}
-		sess.EndedAt = &parsedEnd
+		return Session{}, fmt.Errorf("query session %s: %w", id, err)
 	}
-
 	return sess, nil
 }
 
+// GetSegments returns a session's segments in chronological order, so spans
+// recovered after a transcription outage interleave with live segments.
 func (s *SQLiteStore) GetSegments(sessionID string) ([]transcribe.Segment, error) {
 	rows, err := s.db.Query(
-		`SELECT speaker, text, start_time, end_time, timestamp
+		`SELECT id, speaker, text, start_time, end_time, timestamp, language, confidence, words
 		 FROM segments
 		 WHERE session_id = ?
-		 ORDER BY id ASC`,
+		 ORDER BY julianday(timestamp) ASC, id ASC`,
 		sessionID,
 	)
 	if err != nil {
@@ -279,10 +712,15 @@ func (s *SQLiteStore) GetSegments(sessionID string) ([]transcribe.Segment, error
 	segments := make([]transcribe.Segment, 0, 32)
 	for rows.Next() {
 		var seg transcribe.Segment
-		var ts string
-		if err := rows.Scan(&seg.Speaker, &seg.Text, &seg.StartTime, &seg.EndTime, &ts); err != nil {
+		var ts, words string
+		if err := rows.Scan(&seg.ID, &seg.Speaker, &seg.Text, &seg.StartTime, &seg.EndTime, &ts, &seg.Language, &seg.Confidence, &words); err != nil {
 			return nil, fmt.Errorf("scan segment for session %s: %w", sessionID, err)
 		}
+		if words != "" {
+			if err := json.Unmarshal([]byte(words), &seg.Words); err != nil {
+				return nil, fmt.Errorf("decode segment words for session %s: %w", sessionID, err)
+			}
+		}
 
 		parsedTS, err := time.Parse(time.RFC3339Nano, ts)
 		if err != nil {
@@ -302,7 +740,7 @@ func (s *SQLiteStore) GetSegments(sessionID string) ([]transcribe.Segment, error
 
 func (s *SQLiteStore) UpdateSummary(sessionID, summary, status, preset string) error {
 	res, err := s.db.Exec(
-		`UPDATE sessions SET summary = ?, summary_status = ?, summary_preset = ? WHERE id = ?`,
+		`UPDATE sessions SET summary = ?, summary_status = ?, summary_preset = ? WHERE id = ? AND locked = 0`,
 		summary,
 		status,
 		preset,
@@ -317,9 +755,193 @@ func (s *SQLiteStore) UpdateSummary(sessionID, summary, status, preset string) e
 		return fmt.Errorf("update summary rows affected: %w", err)
 	}
 	if rows == 0 {
+		return lockedOrMissing(s.db, sessionID)
+	}
+
+	return nil
+}
+
+// SetSummaryAudioPath records where the spoken summary for a session lives.
+func (s *SQLiteStore) SetSummaryAudioPath(sessionID, path string) error {
+	res, err := s.db.Exec(`UPDATE sessions SET summary_audio_path = ? WHERE id = ?`, path, sessionID)
+	if err != nil {
+		return fmt.Errorf("update summary audio for session %s: %w", sessionID, err)
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("update summary audio rows affected: %w", err)
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// SetTranscriptionModel records the model and language a session's
+// transcript was made with.
+func (s *SQLiteStore) SetTranscriptionModel(sessionID, model, language string) error {
+	res, err := s.db.Exec(`UPDATE sessions SET transcription_model = ?, transcription_language = ? WHERE id = ?`, model, language, sessionID)
+	if err != nil {
+		return fmt.Errorf("update transcription model for session %s: %w", sessionID, err)
+	}
+	if rows, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("update transcription model rows affected: %w", err)
+	} else if rows == 0 {
 		return sql.ErrNoRows
 	}
+	return nil
+}
+
+// AddTranscriptionProvider records that provider transcribed part of a
+// session, unless it already has.
+func (s *SQLiteStore) AddTranscriptionProvider(sessionID, provider string) error {
+	res, err := s.db.Exec(
+		`UPDATE sessions SET transcription_providers = CASE
+			WHEN transcription_providers = '' THEN ?
+			WHEN ',' || transcription_providers || ',' LIKE '%,' || ? || ',%' THEN transcription_providers
+			ELSE transcription_providers || ',' || ?
+		 END
+		 WHERE id = ?`,
+		provider, provider, provider, sessionID,
+	)
+	if err != nil {
+		return fmt.Errorf("add transcription provider for session %s: %w", sessionID, err)
+	}
+	if rows, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("add transcription provider rows affected: %w", err)
+	} else if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
 
+// SetSummaryModel records the model that wrote a session's summary.
+func (s *SQLiteStore) SetSummaryModel(sessionID, model string) error {
+	res, err := s.db.Exec(`UPDATE sessions SET summary_model = ? WHERE id = ?`, model, sessionID)
+	if err != nil {
+		return fmt.Errorf("update summary model for session %s: %w", sessionID, err)
+	}
+	if rows, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("update summary model rows affected: %w", err)
+	} else if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// SetKeepWAV flags whether a session's lossless audio is to be kept.
+func (s *SQLiteStore) SetKeepWAV(sessionID string, keep bool) error {
+	res, err := s.db.Exec(`UPDATE sessions SET keep_wav = ? WHERE id = ?`, keep, sessionID)
+	if err != nil {
+		return fmt.Errorf("update keep wav for session %s: %w", sessionID, err)
+	}
+	if rows, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("update keep wav rows affected: %w", err)
+	} else if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// SetChannelSpeakers flags whether a session's speakers follow the
+// capture's channels.
+func (s *SQLiteStore) SetChannelSpeakers(sessionID string, channels bool) error {
+	res, err := s.db.Exec(`UPDATE sessions SET channel_speakers = ? WHERE id = ?`, channels, sessionID)
+	if err != nil {
+		return fmt.Errorf("update channel speakers for session %s: %w", sessionID, err)
+	}
+	if rows, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("update channel speakers rows affected: %w", err)
+	} else if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// SetAudioOffset records where on the capture timeline a session's
+// recording starts.
+func (s *SQLiteStore) SetAudioOffset(sessionID string, offset float64) error {
+	res, err := s.db.Exec(`UPDATE sessions SET audio_offset = ? WHERE id = ?`, offset, sessionID)
+	if err != nil {
+		return fmt.Errorf("update audio offset for session %s: %w", sessionID, err)
+	}
+	if rows, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("update audio offset rows affected: %w", err)
+	} else if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// SetSessionClock records where a session started on the boot clock of the
+// boot identified by bootID.
+func (s *SQLiteStore) SetSessionClock(sessionID, bootID string, boot time.Duration) error {
+	res, err := s.db.Exec(`UPDATE sessions SET clock_boot_id = ?, clock_boot = ? WHERE id = ?`, bootID, int64(boot), sessionID)
+	if err != nil {
+		return fmt.Errorf("update clock anchors for session %s: %w", sessionID, err)
+	}
+	if rows, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("update clock anchors rows affected: %w", err)
+	} else if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// SetWAVPath records where a session's lossless audio was kept.
+func (s *SQLiteStore) SetWAVPath(sessionID, path string) error {
+	res, err := s.db.Exec(`UPDATE sessions SET wav_path = ? WHERE id = ?`, path, sessionID)
+	if err != nil {
+		return fmt.Errorf("update wav path for session %s: %w", sessionID, err)
+	}
+	if rows, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("update wav path rows affected: %w", err)
+	} else if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// SetJournalDocID records the daily journal doc a session's summary was
+// appended to.
+func (s *SQLiteStore) SetJournalDocID(sessionID, docID string) error {
+	res, err := s.db.Exec(`UPDATE sessions SET journal_doc_id = ? WHERE id = ?`, docID, sessionID)
+	if err != nil {
+		return fmt.Errorf("update journal doc id for session %s: %w", sessionID, err)
+	}
+	if rows, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("update journal doc id rows affected: %w", err)
+	} else if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// SetGDocID records the Google Doc a session's summary was exported to.
+func (s *SQLiteStore) SetGDocID(sessionID, docID string) error {
+	res, err := s.db.Exec(`UPDATE sessions SET gdoc_id = ? WHERE id = ?`, docID, sessionID)
+	if err != nil {
+		return fmt.Errorf("update gdoc id for session %s: %w", sessionID, err)
+	}
+	if rows, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("update gdoc id rows affected: %w", err)
+	} else if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// MarkSessionViewed records that a session was opened at at.
+func (s *SQLiteStore) MarkSessionViewed(sessionID string, at time.Time) error {
+	res, err := s.db.Exec(`UPDATE sessions SET last_viewed_at = ? WHERE id = ?`, at.UTC().Format(time.RFC3339Nano), sessionID)
+	if err != nil {
+		return fmt.Errorf("mark session %s viewed: %w", sessionID, err)
+	}
+	if rows, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("mark session viewed rows affected: %w", err)
+	} else if rows == 0 {
+		return sql.ErrNoRows
+	}
 	return nil
 }
 
@@ -344,27 +966,10 @@ func (s *SQLiteStore) ClaimSummaryRequest(sessionID, promptHash string) (bool, e
 func scanSessions(rows *sql.Rows) ([]Session, error) {
 	sessions := make([]Session, 0, 16)
 	for rows.Next() {
-		var sess Session
-		var startedAt string
-		var endedAt sql.NullString
-		if err := rows.Scan(&sess.ID, &startedAt, &endedAt, &sess.Status, &sess.Summary, &sess.SummaryStatus, &sess.SummaryPreset, &sess.AudioPath); err != nil {
-			return nil, fmt.Errorf("scan session: %w", err)
-		}
-
-		parsedStart, err := time.Parse(time.RFC3339Nano, startedAt)
+		sess, err := scanSession(rows)
 		if err != nil {
-			return nil, fmt.Errorf("parse started_at: %w", err)
-		}
-		sess.StartedAt = parsedStart
-
-		if endedAt.Valid {
-			parsedEnd, err := time.Parse(time.RFC3339Nano, endedAt.String)
-			if err != nil {
-				return nil, fmt.Errorf("parse ended_at: %w", err)
-			}
-			sess.EndedAt = &parsedEnd
+			return nil, err
 		}
-
 		sessions = append(sessions, sess)
 	}
 	if err := rows.Err(); err != nil {
@@ -373,3 +978,40 @@ func scanSessions(rows *sql.Rows) ([]Session, error) {
 
 	return sessions, nil
 }
+
+// scanSession reads one row selected with sessionColumns.
+func scanSession(row interface{ Scan(...any) error }) (Session, error) {
+	var sess Session
+	var startedAt string
+	var endedAt, viewedAt sql.NullString
+	var providers string
+	if err := row.Scan(&sess.ID, &startedAt, &endedAt, &sess.Status, &sess.Summary, &sess.SummaryStatus, &sess.SummaryPreset, &sess.AudioPath, &sess.SummaryAudioPath, &sess.SegmentsVersion, &sess.SeriesID, &sess.Kind, &sess.Recovered, &sess.AudioStatus, &sess.TranscriptionModel, &sess.TranscriptionLanguage, &sess.SummaryModel, &sess.KeepWAV, &sess.WAVPath, &sess.GDocID, &viewedAt, &providers, &sess.Locked, &sess.AudioOffset, &sess.ClockBootID, &sess.ClockBoot, &sess.JournalDocID, &sess.ChannelSpeakers, &sess.RefineFailures); err != nil {
+		return Session{}, fmt.Errorf("scan session: %w", err)
+	}
+	if providers != "" {
+		sess.TranscriptionProviders = strings.Split(providers, ",")
+	}
+
+	parsedStart, err := time.Parse(time.RFC3339Nano, startedAt)
+	if err != nil {
+		return Session{}, fmt.Errorf("parse started_at: %w", err)
+	}
+	sess.StartedAt = parsedStart
+
+	if endedAt.Valid {
+		parsedEnd, err := time.Parse(time.RFC3339Nano, endedAt.String)
+		if err != nil {
+			return Session{}, fmt.Errorf("parse ended_at: %w", err)
+		}
+		sess.EndedAt = &parsedEnd
+	}
+	if viewedAt.Valid {
+		parsed, err := time.Parse(time.RFC3339Nano, viewedAt.String)
+		if err != nil {
+			return Session{}, fmt.Errorf("parse last_viewed_at: %w", err)
+		}
+		sess.LastViewedAt = &parsed
+	}
+
+	return sess, nil
+}
@@ -1,8 +1,11 @@
 package storage
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
 	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -45,6 +48,79 @@ func TestSQLitePragmas(t *testing.T) {
 	}
 }
 
+func TestSQLiteSetCacheSize(t *testing.T) {
+	store := newTestSQLiteStore(t)
+
+	if err := store.SetCacheSize(1024); err != nil {
+		t.Fatalf("SetCacheSize failed: %v", err)
+	}
+
+	var size int
+	if err := store.DB().QueryRow("PRAGMA cache_size").Scan(&size); err != nil {
+		t.Fatalf("PRAGMA cache_size failed: %v", err)
+	}
+	if size != -1024 {
+		t.Fatalf("expected cache_size -1024, got %d", size)
+	}
+}
+
+func TestSQLiteSnapshot(t *testing.T) {
+	store := newTestSQLiteStore(t)
+	startedAt := time.Date(2026, 2, 26, 10, 0, 0, 0, time.UTC)
+	if err := store.CreateSession("snap", startedAt); err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "snapshot.db")
+	if err := store.Snapshot(path); err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+
+	copied, err := NewSQLiteStore(path)
+	if err != nil {
+		t.Fatalf("open snapshot failed: %v", err)
+	}
+	defer func() { _ = copied.Close() }()
+	if _, err := copied.GetSession("snap"); err != nil {
+		t.Fatalf("expected session in snapshot: %v", err)
+	}
+
+	if err := store.Snapshot(path); err == nil {
+		t.Fatal("expected snapshot over an existing file to fail")
+	}
+
+	// A write in progress on the store's only connection neither blocks the
+	// snapshot nor shows up in it.
+	tx, err := store.DB().Begin()
+	if err != nil {
+		t.Fatalf("begin failed: %v", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	if _, err := tx.Exec(`INSERT INTO sessions(id, started_at, status) VALUES('uncommitted', ?, 'active')`, startedAt.Format(time.RFC3339)); err != nil {
+		t.Fatalf("insert failed: %v", err)
+	}
+	during := filepath.Join(t.TempDir(), "during.db")
+	done := make(chan error, 1)
+	go func() { done <- store.Snapshot(during) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Snapshot during a write failed: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Snapshot blocked behind the store's connection")
+	}
+
+	copied, err = NewSQLiteStore(during)
+	if err != nil {
+		t.Fatalf("open snapshot failed: %v", err)
+	}
+	defer func() { _ = copied.Close() }()
+	if _, err := copied.GetSession("uncommitted"); err == nil {
+		t.Fatal("expected the uncommitted session left out of the snapshot")
+	}
+}
+
 func TestSQLiteCRUD(t *testing.T) {
 	store := newTestSQLiteStore(t)
 
@@ -55,11 +131,12 @@ func TestSQLiteCRUD(t *testing.T) {
 	}
 
 	seg := transcribe.Segment{
-		Speaker:   1,
-		Text:      "Ship the polished app.",
-		StartTime: 1.0,
-		EndTime:   2.5,
-		Timestamp: startedAt.Add(2 * time.Second),
+		Speaker:    1,
+		Text:       "Ship the polished app.",
+		StartTime:  1.0,
+		EndTime:    2.5,
+		Timestamp:  startedAt.Add(2 * time.Second),
+		Confidence: 0.875,
 	}
 	if err := store.AppendSegment(sessionID, seg); err != nil {
 		t.Fatalf("AppendSegment failed: %v", err)
@@ -97,6 +174,9 @@ func TestSQLiteCRUD(t *testing.T) {
 	if segments[0].Text != seg.Text {
 		t.Fatalf("expected segment text %q, got %q", seg.Text, segments[0].Text)
 	}
+	if segments[0].Confidence != seg.Confidence {
+		t.Fatalf("expected segment confidence %v, got %v", seg.Confidence, segments[0].Confidence)
+	}
 
 	sessionsByDate, err := store.GetSessionsByDate("2026-02-26")
 	if err != nil {
@@ -115,6 +195,38 @@ func TestSQLiteCRUD(t *testing.T) {
 	}
 }
 
+func TestGetSegmentsChronological(t *testing.T) {
+	store := newTestSQLiteStore(t)
+
+	startedAt := time.Date(2026, 2, 26, 10, 0, 0, 0, time.UTC)
+	sessionID := startedAt.Format("20060102150405")
+	if err := store.CreateSession(sessionID, startedAt); err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+
+	for _, seg := range []transcribe.Segment{
+		{Text: "before", Timestamp: startedAt.Add(time.Second)},
+		{Text: "after", Timestamp: startedAt.Add(10 * time.Second)},
+		{Text: "recovered", Timestamp: startedAt.Add(5*time.Second + 500*time.Millisecond)},
+	} {
+		if err := store.AppendSegment(sessionID, seg); err != nil {
+			t.Fatalf("AppendSegment failed: %v", err)
+		}
+	}
+
+	segments, err := store.GetSegments(sessionID)
+	if err != nil {
+		t.Fatalf("GetSegments failed: %v", err)
+	}
+	var texts []string
+	for _, seg := range segments {
+		texts = append(texts, seg.Text)
+	}
+	if strings.Join(texts, ",") != "before,recovered,after" {
+		t.Fatalf("expected chronological segments, got %v", texts)
+	}
+}
+
 func TestUpdateSummaryWithPreset(t *testing.T) {
 	store := newTestSQLiteStore(t)
 
@@ -138,6 +250,57 @@ func TestUpdateSummaryWithPreset(t *testing.T) {
 	}
 }
 
+func TestSetSummaryAudioPath(t *testing.T) {
+	store := newTestSQLiteStore(t)
+
+	startedAt := time.Date(2026, 2, 26, 10, 0, 0, 0, time.UTC)
+	sessionID := startedAt.Format("20060102150405")
+	if err := store.CreateSession(sessionID, startedAt); err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+
+	if err := store.SetSummaryAudioPath(sessionID, "data/audio/summary.mp3"); err != nil {
+		t.Fatalf("SetSummaryAudioPath failed: %v", err)
+	}
+	sessions, err := store.GetSessionsByDate("2026-02-26")
+	if err != nil {
+		t.Fatalf("GetSessionsByDate failed: %v", err)
+	}
+	if len(sessions) != 1 || sessions[0].SummaryAudioPath != "data/audio/summary.mp3" {
+		t.Fatalf("expected summary audio path, got %+v", sessions)
+	}
+
+	if err := store.SetSummaryAudioPath("missing", "x.mp3"); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows for missing session, got %v", err)
+	}
+}
+
+func TestGetSessionsBySummaryStatus(t *testing.T) {
+	store := newTestSQLiteStore(t)
+
+	for i, status := range []string{SummaryQueuedOffline, SummaryCompleted, SummaryQueuedOffline} {
+		startedAt := time.Date(2026, 2, 26, 10+i, 0, 0, 0, time.UTC)
+		sessionID := startedAt.Format("20060102150405")
+		if err := store.CreateSession(sessionID, startedAt); err != nil {
+			t.Fatalf("CreateSession failed: %v", err)
+		}
+		if err := store.UpdateSummary(sessionID, "", status, ""); err != nil {
+			t.Fatalf("UpdateSummary failed: %v", err)
+		}
+	}
+
+	queued, err := store.GetSessionsBySummaryStatus(SummaryQueuedOffline)
+	if err != nil {
+		t.Fatalf("GetSessionsBySummaryStatus failed: %v", err)
+	}
+	if len(queued) != 2 {
+		t.Fatalf("expected 2 queued sessions, got %d", len(queued))
+	}
+	if queued[0].ID != "20260226100000" || queued[1].ID != "20260226120000" {
+		t.Fatalf("expected queued sessions oldest first, got %s, %s", queued[0].ID, queued[1].ID)
+	}
+}
+
 func TestSQLiteSummaryClaimIsIdempotent(t *testing.T) {
 	store := newTestSQLiteStore(t)
 
@@ -192,3 +355,55 @@ func TestSQLiteConcurrentAccess(t *testing.T) {
 		t.Fatalf("expected 20 segments, got %d", len(segments))
 	}
 }
+
+func TestSQLiteMovesLegacyNotesAndBookmarksOntoTheTimeline(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	store, err := NewSQLiteStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	if err := store.CreateSession("s", start); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetAudioOffset("s", 100); err != nil {
+		t.Fatal(err)
+	}
+	// Notes and bookmarks from before the timeline were timed from the
+	// session's start.
+	if _, err := store.db.Exec(`INSERT INTO notes(session_id, offset_seconds, text, created_at, on_timeline) VALUES('s', 30, 'legacy', ?, 0)`, start.Format(time.RFC3339Nano)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.AddNote("s", start, 150, "current"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.db.Exec(`INSERT INTO bookmarks(session_id, offset_seconds, note, created_at, on_timeline) VALUES('s', 20, 'legacy', ?, 0)`, start.Format(time.RFC3339Nano)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.AddBookmark("s", start, 140, "current"); err != nil {
+		t.Fatal(err)
+	}
+	_ = store.Close()
+
+	for range 2 {
+		store, err = NewSQLiteStore(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		notes, err := store.GetNotes("s")
+		if err != nil {
+			t.Fatal(err)
+		}
+		bookmarks, err := store.GetBookmarks("s")
+		_ = store.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(notes) != 2 || notes[0].Text != "legacy" || notes[0].Offset != 130 || notes[1].Offset != 150 {
+			t.Fatalf("expected the legacy note moved once by the audio offset, got %+v", notes)
+		}
+		if len(bookmarks) != 2 || bookmarks[0].Note != "legacy" || bookmarks[0].Offset != 120 || bookmarks[1].Offset != 140 {
+			t.Fatalf("expected the legacy bookmark moved once by the audio offset, got %+v", bookmarks)
+		}
+	}
+}
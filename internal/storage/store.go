@@ -0,0 +1,115 @@
+package storage
+
+import (
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/transcribe"
+)
+
+// MemoryPath as a db_path selects MemoryStore instead of a database file, for
+// demos and ephemeral sessions that should leave nothing on disk.
+const MemoryPath = ":memory:"
+
+// Store is the full storage API. SQLiteStore and MemoryStore both implement it.
+type Store interface {
+	CreateSession(id string, startedAt time.Time) error
+	EndSession(id string, endedAt time.Time, audioPath string) error
+	MarkSessionRecovered(id string, endedAt time.Time, audioPath string) error
+	SetAudioStatus(id, status, audioPath string) error
+	AppendSegment(sessionID string, seg transcribe.Segment) error
+	GetSessionsByDate(date string) ([]Session, error)
+	GetSessionsBySummaryStatus(status string) ([]Session, error)
+	GetUnreviewedSessions() ([]Session, error)
+	GetDates() ([]string, error)
+	GetSession(id string) (Session, error)
+	GetSegments(sessionID string) ([]transcribe.Segment, error)
+	UpdateSummary(sessionID, summary, status, preset string) error
+	SetSummaryAudioPath(sessionID, path string) error
+	SetTranscriptionModel(sessionID, model, language string) error
+	AddTranscriptionProvider(sessionID, provider string) error
+	SetSummaryModel(sessionID, model string) error
+	SetKeepWAV(sessionID string, keep bool) error
+	SetChannelSpeakers(sessionID string, channels bool) error
+	SetAudioOffset(sessionID string, offset float64) error
+	SetSessionClock(sessionID, bootID string, boot time.Duration) error
+	SetWAVPath(sessionID, path string) error
+	SetGDocID(sessionID, docID string) error
+	SetJournalDocID(sessionID, docID string) error
+	MarkSessionViewed(sessionID string, at time.Time) error
+	ClaimSummaryRequest(sessionID, promptHash string) (bool, error)
+
+	ReplaceDecisions(sessionID string, decisions []Decision) error
+	GetDecisions(filter DecisionFilter) ([]Decision, error)
+
+	ReplaceActionItems(sessionID string, items []ActionItem) error
+	GetActionItems(sessionID string) ([]ActionItem, error)
+
+	ReplaceCitations(sessionID string, citations []Citation) error
+	GetCitations(sessionID string) ([]Citation, error)
+	AddAudioFile(sessionID, kind, path string) (AudioFile, error)
+	GetAudioFiles(sessionID string) ([]AudioFile, error)
+
+	SetSessionSeries(sessionID, seriesID string) error
+	GetSeriesSessions(seriesID string) ([]Session, error)
+	ListSeries() ([]Series, error)
+
+	SetSessionLocked(sessionID string, locked bool) error
+	SetSessionKind(sessionID, kind string) error
+	GetSessionsByKind(kind string) ([]Session, error)
+	DeleteSession(id string) error
+
+	CreateBriefing(b Briefing) error
+	GetBriefing(id string) (Briefing, error)
+	ListBriefings() ([]Briefing, error)
+	MarkBriefingDelivered(id string, at time.Time) error
+
+	AddBookmark(sessionID string, at time.Time, offset float64, note string) (Bookmark, error)
+	GetBookmarks(sessionID string) ([]Bookmark, error)
+	AddNote(sessionID string, at time.Time, offset float64, text string) (Note, error)
+	GetNotes(sessionID string) ([]Note, error)
+	ReplaceAnnotations(sessionID string, annotations []Annotation) error
+	GetAnnotations(sessionID string) ([]Annotation, error)
+
+	SetExport(e Export) error
+	GetExports(sessionID string) ([]Export, error)
+	GetExportsByStatus(status string) ([]Export, error)
+
+	RecordTranscriptionUsage(sessionID string, duration time.Duration, cost float64) error
+	AddLLMUsage(sessionID string, inputTokens, outputTokens int, cost float64, latency time.Duration) error
+	GetSessionUsage(sessionID string) (SessionUsage, error)
+	GetMonthlySpend(month time.Time) (MonthlySpend, error)
+
+	GetSessionsForRefinement(limit int) ([]Session, error)
+	AddRefineFailure(sessionID string) error
+	RelabelSegmentSpeakers(sessionID string, speakers []int) (int, error)
+	SetSpeakerName(sessionID string, speaker int, name string) error
+	GetSpeakerNames(sessionID string) (map[int]string, error)
+	AddVoiceProfile(p VoiceProfile) (VoiceProfile, error)
+	ListVoiceProfiles() ([]VoiceProfile, error)
+	DeleteVoiceProfile(id int64) error
+
+	DatabaseSize() (int64, error)
+	IndexSizes() ([]IndexSize, error)
+	SaveReport(r Report) error
+	GetReport(id string) (Report, error)
+	ListReports() ([]Report, error)
+
+	SaveAudioCalibration(c AudioCalibration) error
+	GetAudioCalibration(device string) (AudioCalibration, error)
+
+	Close() error
+}
+
+var (
+	_ Store = (*SQLiteStore)(nil)
+	_ Store = (*MemoryStore)(nil)
+)
+
+// Open returns a MemoryStore when dbPath is MemoryPath and a SQLiteStore
+// backed by the file at dbPath otherwise.
+func Open(dbPath string) (Store, error) {
+	if dbPath == MemoryPath {
+		return NewMemoryStore(), nil
+	}
+	return NewSQLiteStore(dbPath)
+}
@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+)
+
+// SessionUsage is the metered usage and cost of a session: the streamed
+// transcription time plus every LLM call made to summarize it.
+type SessionUsage struct {
+	TranscriptionMinutes float64 `json:"transcription_minutes"`
+	TranscriptionCost    float64 `json:"transcription_cost_usd"`
+	LLMInputTokens       int     `json:"llm_input_tokens"`
+	LLMOutputTokens      int     `json:"llm_output_tokens"`
+	LLMCost              float64 `json:"llm_cost_usd"`
+	SummaryLatencyMs     int64   `json:"summary_latency_ms"`
+	TotalCost            float64 `json:"total_cost_usd"`
+}
+
+// RecordTranscriptionUsage sets the transcribed duration and its cost for a session.
+func (s *SQLiteStore) RecordTranscriptionUsage(sessionID string, duration time.Duration, cost float64) error {
+	_, err := s.db.Exec(
+		`INSERT INTO session_usage(session_id, transcription_seconds, transcription_cost) VALUES(?, ?, ?)
+		 ON CONFLICT(session_id) DO UPDATE SET
+			transcription_seconds = excluded.transcription_seconds,
+			transcription_cost = excluded.transcription_cost`,
+		sessionID,
+		duration.Seconds(),
+		cost,
+	)
+	if err != nil {
+		return fmt.Errorf("record transcription usage for session %s: %w", sessionID, err)
+	}
+	return nil
+}
+
+// AddLLMUsage adds tokens and cost spent on a session's summaries. Tokens and
+// cost accumulate across resummarizations; latency reflects the latest
+// successful run, so a zero latency leaves it as it was.
+func (s *SQLiteStore) AddLLMUsage(sessionID string, inputTokens, outputTokens int, cost float64, latency time.Duration) error {
+	_, err := s.db.Exec(
+		`INSERT INTO session_usage(session_id, llm_input_tokens, llm_output_tokens, llm_cost, summary_latency_ms) VALUES(?, ?, ?, ?, ?)
+		 ON CONFLICT(session_id) DO UPDATE SET
+			llm_input_tokens = llm_input_tokens + excluded.llm_input_tokens,
+			llm_output_tokens = llm_output_tokens + excluded.llm_output_tokens,
+			llm_cost = llm_cost + excluded.llm_cost,
+			summary_latency_ms = CASE WHEN excluded.summary_latency_ms > 0 THEN excluded.summary_latency_ms ELSE summary_latency_ms END`,
+		sessionID,
+		inputTokens,
+		outputTokens,
+		cost,
+		latency.Milliseconds(),
+	)
+	if err != nil {
+		return fmt.Errorf("add llm usage for session %s: %w", sessionID, err)
+	}
+	return nil
+}
+
+// GetSessionUsage returns the recorded usage for a session, or zero usage if
+// nothing has been metered yet.
+func (s *SQLiteStore) GetSessionUsage(sessionID string) (SessionUsage, error) {
+	var usage SessionUsage
+	var seconds float64
+	err := s.db.QueryRow(
+		`SELECT COALESCE(SUM(transcription_seconds), 0), COALESCE(SUM(transcription_cost), 0),
+			COALESCE(SUM(llm_input_tokens), 0), COALESCE(SUM(llm_output_tokens), 0),
+			COALESCE(SUM(llm_cost), 0), COALESCE(SUM(summary_latency_ms), 0)
+		 FROM session_usage WHERE session_id = ?`,
+		sessionID,
+	).Scan(&seconds, &usage.TranscriptionCost, &usage.LLMInputTokens, &usage.LLMOutputTokens, &usage.LLMCost, &usage.SummaryLatencyMs)
+	if err != nil {
+		return SessionUsage{}, fmt.Errorf("query usage for session %s: %w", sessionID, err)
+	}
+
+	usage.TranscriptionMinutes = seconds / 60
+	usage.TotalCost = usage.TranscriptionCost + usage.LLMCost
+	return usage, nil
+}
+
+// MonthlySpend is the USD spent on sessions started within a calendar month.
+type MonthlySpend struct {
+	TranscriptionCost float64 `json:"transcription_cost_usd"`
+	LLMCost           float64 `json:"llm_cost_usd"`
+}
+
+// GetMonthlySpend sums the usage of every session started in the UTC
+// calendar month containing month.
+func (s *SQLiteStore) GetMonthlySpend(month time.Time) (MonthlySpend, error) {
+	var spend MonthlySpend
+	err := s.db.QueryRow(
+		`SELECT COALESCE(SUM(u.transcription_cost), 0), COALESCE(SUM(u.llm_cost), 0)
+		 FROM session_usage u JOIN sessions s ON s.id = u.session_id
+		 WHERE substr(s.started_at, 1, 7) = ?`,
+		month.UTC().Format("2006-01"),
+	).Scan(&spend.TranscriptionCost, &spend.LLMCost)
+	if err != nil {
+		return MonthlySpend{}, fmt.Errorf("query monthly spend: %w", err)
+	}
+	return spend, nil
+}
@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSessionUsage(t *testing.T) {
+	store := newTestSQLiteStore(t)
+
+	startedAt := time.Date(2026, 2, 26, 10, 0, 0, 0, time.UTC)
+	sessionID := startedAt.Format("20060102150405")
+	if err := store.CreateSession(sessionID, startedAt); err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+
+	empty, err := store.GetSessionUsage(sessionID)
+	if err != nil {
+		t.Fatalf("GetSessionUsage failed: %v", err)
+	}
+	if empty != (SessionUsage{}) {
+		t.Fatalf("expected zero usage, got %+v", empty)
+	}
+
+	if err := store.AddLLMUsage(sessionID, 1000, 200, 0.01, 1500*time.Millisecond); err != nil {
+		t.Fatalf("AddLLMUsage failed: %v", err)
+	}
+	if err := store.RecordTranscriptionUsage(sessionID, 90*time.Second, 0.0087); err != nil {
+		t.Fatalf("RecordTranscriptionUsage failed: %v", err)
+	}
+	if err := store.AddLLMUsage(sessionID, 500, 100, 0.005, 800*time.Millisecond); err != nil {
+		t.Fatalf("AddLLMUsage failed: %v", err)
+	}
+
+	usage, err := store.GetSessionUsage(sessionID)
+	if err != nil {
+		t.Fatalf("GetSessionUsage failed: %v", err)
+	}
+	if usage.TranscriptionMinutes != 1.5 {
+		t.Fatalf("expected 1.5 transcription minutes, got %v", usage.TranscriptionMinutes)
+	}
+	if usage.LLMInputTokens != 1500 || usage.LLMOutputTokens != 300 {
+		t.Fatalf("expected accumulated tokens 1500/300, got %d/%d", usage.LLMInputTokens, usage.LLMOutputTokens)
+	}
+	if usage.SummaryLatencyMs != 800 {
+		t.Fatalf("expected latest latency 800ms, got %d", usage.SummaryLatencyMs)
+	}
+	// A failed run records its tokens but not its latency.
+	if err := store.AddLLMUsage(sessionID, 10, 0, 0, 0); err != nil {
+		t.Fatalf("AddLLMUsage failed: %v", err)
+	}
+	if failed, _ := store.GetSessionUsage(sessionID); failed.SummaryLatencyMs != 800 || failed.LLMInputTokens != 1510 {
+		t.Fatalf("expected the last good latency kept, got %+v", failed)
+	}
+	if diff := usage.TotalCost - 0.0237; diff > 1e-9 || diff < -1e-9 {
+		t.Fatalf("expected total cost 0.0237, got %v", usage.TotalCost)
+	}
+}
+
+func TestMonthlySpend(t *testing.T) {
+	store := newTestSQLiteStore(t)
+
+	for _, startedAt := range []time.Time{
+		time.Date(2026, 1, 31, 23, 0, 0, 0, time.UTC),
+		time.Date(2026, 2, 1, 9, 0, 0, 0, time.UTC),
+		time.Date(2026, 2, 26, 10, 0, 0, 0, time.UTC),
+	} {
+		sessionID := startedAt.Format("20060102150405")
+		if err := store.CreateSession(sessionID, startedAt); err != nil {
+			t.Fatalf("CreateSession failed: %v", err)
+		}
+		if err := store.RecordTranscriptionUsage(sessionID, time.Minute, 1); err != nil {
+			t.Fatalf("RecordTranscriptionUsage failed: %v", err)
+		}
+		if err := store.AddLLMUsage(sessionID, 10, 10, 0.5, time.Second); err != nil {
+			t.Fatalf("AddLLMUsage failed: %v", err)
+		}
+	}
+
+	spend, err := store.GetMonthlySpend(time.Date(2026, 2, 15, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("GetMonthlySpend failed: %v", err)
+	}
+	if spend.TranscriptionCost != 2 || spend.LLMCost != 1 {
+		t.Fatalf("expected February spend 2/1, got %+v", spend)
+	}
+}
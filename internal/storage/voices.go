@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+)
+
+// VoiceProfile is an enrolled voice: a speaker embedding learned from a
+// sample of someone talking, and the name their speakers get when it is
+// recognized in a session. Source says where the sample came from, and
+// SessionID is the session it was learned from, if any.
+type VoiceProfile struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Source    string    `json:"source"`
+	SessionID string    `json:"session_id,omitempty"`
+	Embedding []float64 `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AddVoiceProfile stores an enrolled voice and returns it with its ID.
+func (s *SQLiteStore) AddVoiceProfile(p VoiceProfile) (VoiceProfile, error) {
+	embedding, err := json.Marshal(p.Embedding)
+	if err != nil {
+		return VoiceProfile{}, fmt.Errorf("encode voice embedding: %w", err)
+	}
+	p.CreatedAt = p.CreatedAt.UTC()
+	res, err := s.db.Exec(
+		`INSERT INTO voice_profiles(name, source, session_id, embedding, created_at) VALUES(?, ?, ?, ?, ?)`,
+		p.Name, p.Source, p.SessionID, string(embedding), p.CreatedAt.Format(time.RFC3339Nano),
+	)
+	if err != nil {
+		return VoiceProfile{}, fmt.Errorf("add voice profile %q: %w", p.Name, err)
+	}
+	if p.ID, err = res.LastInsertId(); err != nil {
+		return VoiceProfile{}, fmt.Errorf("add voice profile id: %w", err)
+	}
+	return p, nil
+}
+
+// ListVoiceProfiles returns every enrolled voice, by name.
+func (s *SQLiteStore) ListVoiceProfiles() ([]VoiceProfile, error) {
+	rows, err := s.db.Query(`SELECT id, name, source, session_id, embedding, created_at FROM voice_profiles ORDER BY name, id`)
+	if err != nil {
+		return nil, fmt.Errorf("query voice profiles: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	profiles := make([]VoiceProfile, 0, 4)
+	for rows.Next() {
+		var p VoiceProfile
+		var embedding, createdAt string
+		if err := rows.Scan(&p.ID, &p.Name, &p.Source, &p.SessionID, &embedding, &createdAt); err != nil {
+			return nil, fmt.Errorf("scan voice profile: %w", err)
+		}
+		if err := json.Unmarshal([]byte(embedding), &p.Embedding); err != nil {
+			return nil, fmt.Errorf("decode embedding of voice profile %d: %w", p.ID, err)
+		}
+		if p.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAt); err != nil {
+			return nil, fmt.Errorf("parse voice profile created_at: %w", err)
+		}
+		profiles = append(profiles, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate voice profiles: %w", err)
+	}
+	return profiles, nil
+}
+
+// DeleteVoiceProfile forgets an enrolled voice. Speakers already named
+// after it keep their names.
+func (s *SQLiteStore) DeleteVoiceProfile(id int64) error {
+	res, err := s.db.Exec(`DELETE FROM voice_profiles WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("delete voice profile %d: %w", id, err)
+	}
+	if rows, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("delete voice profile rows affected: %w", err)
+	} else if rows == 0 {
+		return fmt.Errorf("delete voice profile %d: %w", id, sql.ErrNoRows)
+	}
+	return nil
+}
+
+// AddVoiceProfile stores an enrolled voice and returns it with its ID.
+func (s *MemoryStore) AddVoiceProfile(p VoiceProfile) (VoiceProfile, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextVoiceID++
+	p.ID = s.nextVoiceID
+	p.CreatedAt = p.CreatedAt.UTC()
+	p.Embedding = slices.Clone(p.Embedding)
+	s.voices = append(s.voices, p)
+	return p, nil
+}
+
+// ListVoiceProfiles returns every enrolled voice, by name.
+func (s *MemoryStore) ListVoiceProfiles() ([]VoiceProfile, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	profiles := slices.Clone(s.voices)
+	sort.SliceStable(profiles, func(i, j int) bool { return profiles[i].Name < profiles[j].Name })
+	return profiles, nil
+}
+
+// DeleteVoiceProfile forgets an enrolled voice.
+func (s *MemoryStore) DeleteVoiceProfile(id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := slices.IndexFunc(s.voices, func(p VoiceProfile) bool { return p.ID == id })
+	if i < 0 {
+		return fmt.Errorf("delete voice profile %d: %w", id, sql.ErrNoRows)
+	}
+	s.voices = slices.Delete(s.voices, i, i+1)
+	return nil
+}
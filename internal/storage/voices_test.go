@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestVoiceProfiles(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		enrolled := time.Date(2026, 3, 4, 9, 0, 0, 0, time.UTC)
+		bob, err := store.AddVoiceProfile(VoiceProfile{Name: "Bob", Source: "recording", Embedding: []float64{0.1, -0.2, 0.3}, CreatedAt: enrolled})
+		if err != nil {
+			t.Fatalf("AddVoiceProfile failed: %v", err)
+		}
+		alice, err := store.AddVoiceProfile(VoiceProfile{Name: "Alice", Source: "session s1, speaker 0", SessionID: "s1", Embedding: []float64{1, 0, 0}, CreatedAt: enrolled})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bob.ID == 0 || alice.ID == bob.ID {
+			t.Fatalf("expected distinct IDs, got %d and %d", bob.ID, alice.ID)
+		}
+
+		profiles, err := store.ListVoiceProfiles()
+		if err != nil {
+			t.Fatalf("ListVoiceProfiles failed: %v", err)
+		}
+		if len(profiles) != 2 || profiles[0].Name != "Alice" || profiles[0].SessionID != "s1" || profiles[1].Name != "Bob" {
+			t.Fatalf("expected profiles by name, got %+v", profiles)
+		}
+		if !reflect.DeepEqual(profiles[1].Embedding, []float64{0.1, -0.2, 0.3}) || !profiles[1].CreatedAt.Equal(enrolled) || profiles[1].Source != "recording" {
+			t.Fatalf("expected Bob round-tripped, got %+v", profiles[1])
+		}
+
+		if err := store.DeleteVoiceProfile(bob.ID); err != nil {
+			t.Fatalf("DeleteVoiceProfile failed: %v", err)
+		}
+		if err := store.DeleteVoiceProfile(bob.ID); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("expected sql.ErrNoRows deleting twice, got %v", err)
+		}
+		if profiles, _ := store.ListVoiceProfiles(); len(profiles) != 1 || profiles[0].ID != alice.ID {
+			t.Fatalf("expected only Alice left, got %+v", profiles)
+		}
+	})
+}
+
+func TestVoiceProfileSessionsAreBackfilled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	store, err := NewSQLiteStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Voices enrolled before session_id only named their session in source.
+	if _, err := store.db.Exec(`INSERT INTO voice_profiles(name, source, embedding, created_at) VALUES
+		('Alice', 'session 2026-03-04-0900, speaker 1', '[1]', '2026-03-04T09:00:00Z'),
+		('Bob', 'recording', '[1]', '2026-03-04T09:00:00Z')`); err != nil {
+		t.Fatal(err)
+	}
+	_ = store.Close()
+
+	store, err = NewSQLiteStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = store.Close() }()
+	profiles, err := store.ListVoiceProfiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(profiles) != 2 || profiles[0].SessionID != "2026-03-04-0900" || profiles[1].SessionID != "" {
+		t.Fatalf("expected Alice's session recovered from her source, got %+v", profiles)
+	}
+}
@@ -0,0 +1,132 @@
+package summary
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/sjawhar/ghost-wispr/internal/llm"
+)
+
+const actionItemsPrompt = `Extract every action item from the following conversation transcript.
+An action item is a task someone committed to doing after the meeting — not a topic that was merely discussed.
+
+Reply with ONLY a JSON array, no prose and no code fences. Each element must be an object with:
+- "item": one sentence describing the task
+- "owner": the name (or speaker label) of the person responsible; empty if unknown
+- "done": true if the transcript shows the task is already complete, otherwise false
+
+Reply with [] if there are no action items.`
+
+const priorActionItemsPrompt = `
+
+These action items were still open after the previous meeting in this series. Include every one of them in your reply, unchanged, with "done" set to true only if the transcript shows it was completed:
+`
+
+// ActionItem is a single action item extracted from a transcript.
+type ActionItem struct {
+	Text  string `json:"item"`
+	Owner string `json:"owner"`
+	Done  bool   `json:"done"`
+}
+
+type priorActionItemsKey struct{}
+
+// WithPriorActionItems returns a context in which summaries are asked to
+// report the status of items, the open action items carried over from the
+// previous meeting in a series.
+func WithPriorActionItems(ctx context.Context, items []ActionItem) context.Context {
+	if len(items) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, priorActionItemsKey{}, items)
+}
+
+func priorActionItems(ctx context.Context) []ActionItem {
+	items, _ := ctx.Value(priorActionItemsKey{}).([]ActionItem)
+	return items
+}
+
+// carryOverSection renders the prior action items for a summary prompt, or ""
+// when there are none.
+func carryOverSection(items []ActionItem) string {
+	if len(items) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("Open action items from the previous meeting in this series. Report the status of each one in the summary:\n")
+	b.WriteString(formatActionItems(items))
+	return b.String()
+}
+
+func formatActionItems(items []ActionItem) string {
+	var b strings.Builder
+	for _, item := range items {
+		b.WriteString("- ")
+		b.WriteString(item.Text)
+		if item.Owner != "" {
+			fmt.Fprintf(&b, " (owner: %s)", item.Owner)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// ExtractActionItems asks the summarization model to list the action items
+// in the transcript. Items in prior are carried over: they come back in the
+// result, marked done if the transcript shows they were completed. Short
+// transcripts yield prior unchanged without an LLM call.
+func (s *Summarizer) ExtractActionItems(ctx context.Context, transcript string, prior []ActionItem) ([]ActionItem, error) {
+	if len(strings.Fields(transcript)) < 20 {
+		return prior, nil
+	}
+
+	provider, model, err := llm.ParseModel(resolveModel(ctx, s.cfg.Model))
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := s.factory(provider, model)
+	if err != nil {
+		return nil, fmt.Errorf("create llm client: %w", err)
+	}
+
+	system := actionItemsPrompt
+	if len(prior) > 0 {
+		system += priorActionItemsPrompt + formatActionItems(prior)
+	}
+	result, err := client.Complete(ctx, []llm.Message{
+		{Role: "system", Content: system},
+		{Role: "user", Content: transcript},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("extract action items: %w", err)
+	}
+
+	return parseActionItems(result)
+}
+
+func parseActionItems(raw string) ([]ActionItem, error) {
+	start := strings.Index(raw, "[")
+	end := strings.LastIndex(raw, "]")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("parse action items: no JSON array in response")
+	}
+
+	var items []ActionItem
+	if err := json.Unmarshal([]byte(raw[start:end+1]), &items); err != nil {
+		return nil, fmt.Errorf("parse action items: %w", err)
+	}
+
+	result := make([]ActionItem, 0, len(items))
+	for _, item := range items {
+		item.Text = strings.TrimSpace(item.Text)
+		if item.Text == "" {
+			continue
+		}
+		item.Owner = strings.TrimSpace(item.Owner)
+		result = append(result, item)
+	}
+	return result, nil
+}
@@ -0,0 +1,83 @@
+package summary
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sjawhar/ghost-wispr/internal/config"
+	"github.com/sjawhar/ghost-wispr/internal/llm"
+)
+
+func TestExtractActionItemsWithPrior(t *testing.T) {
+	client := &mockLLMClient{response: `[{"item": "Send the deck", "owner": "Alice", "done": true}, {"item": " Book the room "}, {"item": ""}]`}
+	s := New(config.Summarization{Model: "openai/gpt-4o-mini"}, func(_, _ string) (llm.Client, error) {
+		return client, nil
+	})
+
+	prior := []ActionItem{{Text: "Send the deck", Owner: "Alice"}}
+	items, err := s.ExtractActionItems(context.Background(), buildTranscript(25), prior)
+	if err != nil {
+		t.Fatalf("ExtractActionItems failed: %v", err)
+	}
+	if len(items) != 2 || !items[0].Done || items[1].Text != "Book the room" || items[1].Done {
+		t.Fatalf("unexpected items %#v", items)
+	}
+	if !strings.Contains(client.lastMessages[0].Content, "- Send the deck (owner: Alice)") {
+		t.Fatalf("expected prior items in the system prompt, got %q", client.lastMessages[0].Content)
+	}
+}
+
+func TestExtractActionItemsShortTranscriptKeepsPrior(t *testing.T) {
+	client := &mockLLMClient{response: "[]"}
+	s := New(config.Summarization{Model: "openai/gpt-4o-mini"}, func(_, _ string) (llm.Client, error) {
+		return client, nil
+	})
+
+	prior := []ActionItem{{Text: "Send the deck"}}
+	items, err := s.ExtractActionItems(context.Background(), "too short", prior)
+	if err != nil {
+		t.Fatalf("ExtractActionItems failed: %v", err)
+	}
+	if len(items) != 1 || client.calls != 0 {
+		t.Fatalf("expected prior items and no llm calls, got %#v after %d calls", items, client.calls)
+	}
+}
+
+func TestSummarizeReportsPriorActionItems(t *testing.T) {
+	client := &mockLLMClient{response: "summary"}
+	cfg := config.Summarization{
+		Model: "openai/gpt-4o-mini",
+		Presets: map[string]config.Preset{
+			"default": {SystemPrompt: "Summarize.", UserTemplate: "{{transcript}}"},
+			"inline":  {SystemPrompt: "Summarize.", UserTemplate: "Follow up on:\n{{prior_action_items}}\n{{transcript}}"},
+		},
+	}
+	s := New(cfg, func(_, _ string) (llm.Client, error) {
+		return client, nil
+	})
+	ctx := WithPriorActionItems(context.Background(), []ActionItem{{Text: "Send the deck", Owner: "Alice"}})
+
+	if _, err := s.SummarizeWithPreset(ctx, "s1", buildTranscript(25), "default"); err != nil {
+		t.Fatal(err)
+	}
+	user := client.lastMessages[1].Content
+	if !strings.HasSuffix(user, "- Send the deck (owner: Alice)\n") {
+		t.Fatalf("expected prior items appended to the prompt, got %q", user)
+	}
+
+	if _, err := s.SummarizeWithPreset(ctx, "s1", buildTranscript(25), "inline"); err != nil {
+		t.Fatal(err)
+	}
+	user = client.lastMessages[1].Content
+	if !strings.HasPrefix(user, "Follow up on:\nOpen action items") || strings.Count(user, "Send the deck") != 1 {
+		t.Fatalf("expected prior items at the placeholder, got %q", user)
+	}
+
+	if _, err := s.SummarizeWithPreset(context.Background(), "s1", buildTranscript(25), "inline"); err != nil {
+		t.Fatal(err)
+	}
+	if user := client.lastMessages[1].Content; strings.Contains(user, "{{prior_action_items}}") {
+		t.Fatalf("expected placeholder removed without prior items, got %q", user)
+	}
+}
@@ -0,0 +1,37 @@
+package summary
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sjawhar/ghost-wispr/internal/llm"
+)
+
+const briefingPrompt = `You prepare short pre-meeting briefs in markdown.
+You are given an upcoming meeting and notes from related past conversations.
+Write a brief the attendees can read in two minutes: what was discussed before with these people or on this topic, decisions already made, open action items and questions worth raising.
+Only use the notes provided; if they are thin, say so rather than inventing context.`
+
+// WriteBrief asks the summarization model for a pre-meeting brief. meeting
+// describes the upcoming event and history holds notes from related past
+// sessions.
+func (s *Summarizer) WriteBrief(ctx context.Context, meeting, history string) (string, error) {
+	provider, model, err := llm.ParseModel(resolveModel(ctx, s.cfg.Model))
+	if err != nil {
+		return "", err
+	}
+
+	client, err := s.factory(provider, model)
+	if err != nil {
+		return "", fmt.Errorf("create llm client: %w", err)
+	}
+
+	brief, err := client.Complete(ctx, []llm.Message{
+		{Role: "system", Content: briefingPrompt},
+		{Role: "user", Content: "Upcoming meeting:\n" + meeting + "\n\nRelated past sessions:\n" + history},
+	})
+	if err != nil {
+		return "", fmt.Errorf("write brief: %w", err)
+	}
+	return brief, nil
+}
@@ -0,0 +1,195 @@
+package summary
+
+import (
+	"math"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/sjawhar/ghost-wispr/internal/transcribe"
+)
+
+// Citation links one summary bullet to the run of transcript segments that
+// best supports it.
+type Citation struct {
+	// Bullet counts the summary's bullets from zero; Line is the bullet's
+	// zero-based line in the summary.
+	Bullet int
+	Line   int
+	Text   string
+	// FirstSegmentID and LastSegmentID are the IDs of the first and last
+	// segments of the span.
+	FirstSegmentID int64
+	LastSegmentID  int64
+	StartTime      float64
+	EndTime        float64
+	// Score is the weighted share of the bullet's words found in the span.
+	Score float64
+}
+
+// minCitationScore is the share of a bullet's words, weighted by rarity, a
+// span must contain to be cited. Below it a bullet is left uncited rather
+// than linked to a misleading spot.
+const minCitationScore = 0.35
+
+var (
+	bulletLine   = regexp.MustCompile(`^\s*(?:[-*+]|\d+[.)])\s+(.+)$`)
+	markdownLink = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	emphasis     = strings.NewReplacer("**", "", "__", "", "`", "")
+)
+
+// Cite finds supporting transcript spans for the bullets of summary by
+// matching their words against segments. Words that appear throughout the
+// transcript count for less than distinctive ones, and a span grows into a
+// neighboring segment only when that adds matching words. Bullets without a
+// good enough match are omitted.
+func Cite(summary string, segments []transcribe.Segment) []Citation {
+	segWords := make([]map[string]bool, len(segments))
+	df := make(map[string]int)
+	for i, seg := range segments {
+		segWords[i] = make(map[string]bool)
+		for _, w := range citationWords(seg.Text) {
+			if !segWords[i][w] {
+				segWords[i][w] = true
+				df[w]++
+			}
+		}
+	}
+	weight := func(w string) float64 {
+		return math.Log(1 + float64(len(segments))/float64(df[w]+1))
+	}
+
+	var citations []Citation
+	bullet := 0
+	for line, text := range strings.Split(summary, "\n") {
+		m := bulletLine.FindStringSubmatch(text)
+		if m == nil {
+			continue
+		}
+		item := strings.TrimSpace(emphasis.Replace(markdownLink.ReplaceAllString(m[1], "$1")))
+		index := bullet
+		bullet++
+
+		words := unique(citationWords(item))
+		var total float64
+		for _, w := range words {
+			total += weight(w)
+		}
+		if total == 0 {
+			continue
+		}
+		matched := func(first, last int) float64 {
+			var score float64
+			for _, w := range words {
+				for i := first; i <= last; i++ {
+					if segWords[i][w] {
+						score += weight(w)
+						break
+					}
+				}
+			}
+			return score
+		}
+
+		best, bestScore := -1, 0.0
+		for i := range segments {
+			if score := matched(i, i); score > bestScore {
+				best, bestScore = i, score
+			}
+		}
+		if best < 0 {
+			continue
+		}
+		first, last := best, best
+		for {
+			grew := false
+			if first > 0 {
+				if score := matched(first-1, last); score > bestScore {
+					first, bestScore, grew = first-1, score, true
+				}
+			}
+			if last < len(segments)-1 {
+				if score := matched(first, last+1); score > bestScore {
+					last, bestScore, grew = last+1, score, true
+				}
+			}
+			if !grew {
+				break
+			}
+		}
+
+		if score := bestScore / total; score >= minCitationScore {
+			citations = append(citations, Citation{
+				Bullet:         index,
+				Line:           line,
+				Text:           item,
+				FirstSegmentID: segments[first].ID,
+				LastSegmentID:  segments[last].ID,
+				StartTime:      segments[first].StartTime,
+				EndTime:        segments[last].EndTime,
+				Score:          math.Round(score*100) / 100,
+			})
+		}
+	}
+	return citations
+}
+
+// citationWords splits text into lowercase content words with common
+// inflections stripped, so "shipped" in a summary matches "shipping" in the
+// transcript.
+func citationWords(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	words := fields[:0]
+	for _, w := range fields {
+		if len([]rune(w)) < 3 || citationStopwords[w] {
+			continue
+		}
+		words = append(words, stem(w))
+	}
+	return words
+}
+
+func stem(w string) string {
+	for _, suffix := range []string{"ing", "ed", "s"} {
+		if strings.HasSuffix(w, "ss") {
+			break
+		}
+		if base, ok := strings.CutSuffix(w, suffix); ok && len(base) >= 3 {
+			w = base
+			break
+		}
+	}
+	if base, ok := strings.CutSuffix(w, "e"); ok && len(base) >= 3 {
+		w = base
+	}
+	if n := len(w); n >= 4 && w[n-1] == w[n-2] {
+		w = w[:n-1]
+	}
+	return w
+}
+
+func unique(words []string) []string {
+	seen := make(map[string]bool, len(words))
+	out := words[:0]
+	for _, w := range words {
+		if !seen[w] {
+			seen[w] = true
+			out = append(out, w)
+		}
+	}
+	return out
+}
+
+// citationStopwords are words too common to tie a bullet to a spot in the
+// transcript.
+var citationStopwords = map[string]bool{
+	"the": true, "and": true, "for": true, "that": true, "this": true, "with": true,
+	"was": true, "were": true, "are": true, "will": true, "would": true, "should": true,
+	"has": true, "have": true, "had": true, "from": true, "about": true, "they": true,
+	"their": true, "them": true, "there": true, "then": true, "than": true, "into": true,
+	"but": true, "not": true, "its": true, "our": true, "you": true, "your": true,
+	"discussed": true, "mentioned": true, "agreed": true, "team": true, "also": true,
+	"which": true, "who": true, "what": true, "when": true, "been": true, "being": true,
+}
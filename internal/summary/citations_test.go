@@ -0,0 +1,39 @@
+package summary
+
+import (
+	"testing"
+
+	"github.com/sjawhar/ghost-wispr/internal/transcribe"
+)
+
+func TestCite(t *testing.T) {
+	segments := []transcribe.Segment{
+		{ID: 10, Text: "Morning everyone, let's get started.", StartTime: 0, EndTime: 3},
+		{ID: 11, Text: "The billing migration slipped because the vendor API changed.", StartTime: 3, EndTime: 9},
+		{ID: 14, Text: "So we're moving the launch to March.", StartTime: 9, EndTime: 12},
+		{ID: 12, Text: "Priya will update the pricing page before then.", StartTime: 12, EndTime: 16},
+		{ID: 13, Text: "Okay, thanks everyone.", StartTime: 16, EndTime: 18},
+	}
+	summary := "## Summary\n\n" +
+		"- Billing migration slipped after a vendor API change; launch moved to March\n" +
+		"- **Priya** updates the [pricing page](https://example.com)\n" +
+		"- Quarterly hiring plan\n"
+
+	citations := Cite(summary, segments)
+	if len(citations) != 2 {
+		t.Fatalf("expected 2 citations, got %+v", citations)
+	}
+
+	first := citations[0]
+	if first.Bullet != 0 || first.Line != 2 || first.FirstSegmentID != 11 || first.LastSegmentID != 14 {
+		t.Fatalf("expected the first bullet to cite segments 11 to 14, got %+v", first)
+	}
+	if first.StartTime != 3 || first.EndTime != 12 {
+		t.Fatalf("unexpected span times %+v", first)
+	}
+
+	second := citations[1]
+	if second.Bullet != 1 || second.FirstSegmentID != 12 || second.LastSegmentID != 12 || second.Text != "Priya updates the pricing page" {
+		t.Fatalf("expected the second bullet to cite segment 3, got %+v", second)
+	}
+}
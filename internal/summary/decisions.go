@@ -0,0 +1,79 @@
+package summary
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/sjawhar/ghost-wispr/internal/llm"
+)
+
+const decisionsPrompt = `Extract every decision that was made in the following conversation transcript.
+A decision is a conclusion the participants agreed on or a choice someone committed to — not a topic that was merely discussed.
+
+Reply with ONLY a JSON array, no prose and no code fences. Each element must be an object with:
+- "decision": one sentence stating the decision
+- "participants": array of names (or speaker labels) of the people who made or agreed to it; empty if unknown
+
+Reply with [] if no decisions were made.`
+
+// Decision is a single decision extracted from a transcript.
+type Decision struct {
+	Text         string   `json:"decision"`
+	Participants []string `json:"participants"`
+}
+
+// ExtractDecisions asks the summarization model to list the decisions made in
+// the transcript. Short transcripts yield no decisions without an LLM call.
+func (s *Summarizer) ExtractDecisions(ctx context.Context, transcript string) ([]Decision, error) {
+	if len(strings.Fields(transcript)) < 20 {
+		return nil, nil
+	}
+
+	provider, model, err := llm.ParseModel(resolveModel(ctx, s.cfg.Model))
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := s.factory(provider, model)
+	if err != nil {
+		return nil, fmt.Errorf("create llm client: %w", err)
+	}
+
+	result, err := client.Complete(ctx, []llm.Message{
+		{Role: "system", Content: decisionsPrompt},
+		{Role: "user", Content: transcript},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("extract decisions: %w", err)
+	}
+
+	return parseDecisions(result)
+}
+
+func parseDecisions(raw string) ([]Decision, error) {
+	start := strings.Index(raw, "[")
+	end := strings.LastIndex(raw, "]")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("parse decisions: no JSON array in response")
+	}
+
+	var decisions []Decision
+	if err := json.Unmarshal([]byte(raw[start:end+1]), &decisions); err != nil {
+		return nil, fmt.Errorf("parse decisions: %w", err)
+	}
+
+	result := make([]Decision, 0, len(decisions))
+	for _, d := range decisions {
+		d.Text = strings.TrimSpace(d.Text)
+		if d.Text == "" {
+			continue
+		}
+		if d.Participants == nil {
+			d.Participants = []string{}
+		}
+		result = append(result, d)
+	}
+	return result, nil
+}
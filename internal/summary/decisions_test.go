@@ -0,0 +1,60 @@
+package summary
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sjawhar/ghost-wispr/internal/config"
+	"github.com/sjawhar/ghost-wispr/internal/llm"
+)
+
+func TestExtractDecisions(t *testing.T) {
+	client := &mockLLMClient{response: "```json\n[{\"decision\": \"Ship on Friday\", \"participants\": [\"Alice\", \"Bob\"]}, {\"decision\": \"  \"}]\n```"}
+	cfg := config.Summarization{Model: "openai/gpt-4o-mini"}
+
+	s := New(cfg, func(_, _ string) (llm.Client, error) {
+		return client, nil
+	})
+
+	decisions, err := s.ExtractDecisions(context.Background(), buildTranscript(25))
+	if err != nil {
+		t.Fatalf("ExtractDecisions failed: %v", err)
+	}
+	if len(decisions) != 1 {
+		t.Fatalf("expected 1 decision, got %#v", decisions)
+	}
+	if decisions[0].Text != "Ship on Friday" {
+		t.Fatalf("unexpected decision text %q", decisions[0].Text)
+	}
+	if len(decisions[0].Participants) != 2 || decisions[0].Participants[0] != "Alice" {
+		t.Fatalf("unexpected participants %#v", decisions[0].Participants)
+	}
+	if client.lastMessages[0].Role != "system" {
+		t.Fatalf("expected system prompt first, got %#v", client.lastMessages)
+	}
+}
+
+func TestExtractDecisionsSkipsShortTranscript(t *testing.T) {
+	client := &mockLLMClient{response: "[]"}
+	s := New(config.Summarization{Model: "openai/gpt-4o-mini"}, func(_, _ string) (llm.Client, error) {
+		return client, nil
+	})
+
+	decisions, err := s.ExtractDecisions(context.Background(), "too short")
+	if err != nil {
+		t.Fatalf("ExtractDecisions failed: %v", err)
+	}
+	if decisions != nil || client.calls != 0 {
+		t.Fatalf("expected no decisions and no llm calls, got %#v after %d calls", decisions, client.calls)
+	}
+}
+
+func TestExtractDecisionsInvalidResponse(t *testing.T) {
+	s := New(config.Summarization{Model: "openai/gpt-4o-mini"}, func(_, _ string) (llm.Client, error) {
+		return &mockLLMClient{response: "No decisions were made."}, nil
+	})
+
+	if _, err := s.ExtractDecisions(context.Background(), buildTranscript(25)); err == nil {
+		t.Fatal("expected parse error for non-JSON response")
+	}
+}
@@ -0,0 +1,133 @@
+package summary
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/config"
+	"github.com/sjawhar/ghost-wispr/internal/i18n"
+)
+
+// Document is what a summary is rendered into: the session it belongs to and
+// where its recording and transcript can be found.
+type Document struct {
+	SessionID    string
+	Preset       string
+	StartedAt    time.Time
+	EndedAt      *time.Time
+	Participants []string
+}
+
+// DocumentRenderer gives stored summaries a consistent structure regardless
+// of how the model formatted them: it tidies the markdown and wraps it in the
+// configured document template.
+//
+// Templates use the same {{placeholder}} syntax as preset prompts:
+// {{summary}}, {{title}}, {{date}}, {{time}}, {{duration}}, {{participants}},
+// {{session_id}}, {{preset}}, {{audio_url}} and {{transcript_url}}.
+type DocumentRenderer struct {
+	// Localizer renders dates, durations and fallback titles; nil renders
+	// English.
+	Localizer *i18n.Localizer
+
+	template string
+	presets  map[string]string
+	baseURL  string
+}
+
+// NewDocumentRenderer renders with cfg's document template, overridden per
+// preset. Links point at cfg.LinkBaseURL.
+func NewDocumentRenderer(cfg config.Summarization) *DocumentRenderer {
+	presets := make(map[string]string)
+	for name, p := range cfg.Presets {
+		if p.DocumentTemplate != "" {
+			presets[name] = p.DocumentTemplate
+		}
+	}
+	return &DocumentRenderer{
+		template: cfg.DocumentTemplate,
+		presets:  presets,
+		baseURL:  strings.TrimRight(cfg.LinkBaseURL, "/"),
+	}
+}
+
+// Render returns summary as it should be stored. An empty summary stays
+// empty, and without a template only the markdown is tidied.
+func (r *DocumentRenderer) Render(summary string, doc Document) string {
+	summary = Normalize(summary)
+	if summary == "" {
+		return ""
+	}
+	tmpl := r.template
+	if t, ok := r.presets[doc.Preset]; ok {
+		tmpl = t
+	}
+	if strings.TrimSpace(tmpl) == "" {
+		return summary
+	}
+
+	l := r.Localizer
+	start := doc.StartedAt.Local()
+	duration := ""
+	if doc.EndedAt != nil {
+		duration = formatDuration(doc.EndedAt.Sub(doc.StartedAt), l)
+	}
+	participants := strings.Join(doc.Participants, ", ")
+	if participants == "" {
+		participants = l.T(i18n.DocNoParticipants)
+	}
+
+	replacer := strings.NewReplacer(
+		"{{summary}}", summary,
+		"{{title}}", Title(summary, start, l),
+		"{{date}}", l.Date(start),
+		"{{time}}", start.Format("15:04"),
+		"{{duration}}", duration,
+		"{{participants}}", participants,
+		"{{session_id}}", doc.SessionID,
+		"{{preset}}", doc.Preset,
+		"{{audio_url}}", r.baseURL+"/api/sessions/"+doc.SessionID+"/audio",
+		"{{transcript_url}}", r.baseURL+"/api/sessions/"+doc.SessionID,
+	)
+	return Normalize(replacer.Replace(tmpl))
+}
+
+// Title is the summary's first heading, or a dated fallback in l's
+// language.
+func Title(summary string, start time.Time, l *i18n.Localizer) string {
+	for _, line := range strings.Split(summary, "\n") {
+		if strings.HasPrefix(line, "#") {
+			if t := strings.TrimSpace(strings.TrimLeft(line, "#")); t != "" {
+				return t
+			}
+		}
+	}
+	return l.T(i18n.DocSessionTitle, start.Format("2006-01-02 15:04"))
+}
+
+func formatDuration(d time.Duration, l *i18n.Localizer) string {
+	minutes := max(int(d.Round(time.Minute).Minutes()), 1)
+	if minutes < 60 {
+		return l.T(i18n.DocMinutes, minutes)
+	}
+	return l.T(i18n.DocHoursMinutes, minutes/60, minutes%60)
+}
+
+var (
+	fencedDocument = regexp.MustCompile("(?s)^```(?:markdown|md)?[ \t]*\n(.*)\n```$")
+	headingSpace   = regexp.MustCompile(`(?m)^(#{2,6})([^#\s])`)
+	blankRuns      = regexp.MustCompile(`\n{3,}`)
+)
+
+// Normalize irons out common model formatting quirks: the whole answer
+// wrapped in a markdown code fence, Windows line endings, "##Heading" without
+// a space, and runs of blank lines.
+func Normalize(md string) string {
+	md = strings.TrimSpace(strings.ReplaceAll(md, "\r\n", "\n"))
+	if m := fencedDocument.FindStringSubmatch(md); m != nil {
+		md = strings.TrimSpace(m[1])
+	}
+	md = headingSpace.ReplaceAllString(md, "$1 $2")
+	return blankRuns.ReplaceAllString(md, "\n\n")
+}
@@ -0,0 +1,63 @@
+package summary
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/config"
+	"github.com/sjawhar/ghost-wispr/internal/i18n"
+)
+
+func TestNormalize(t *testing.T) {
+	in := "```markdown\r\n##Summary\r\n\r\n\r\n\r\n- shipped it\r\n#1 priority\r\n```\r\n"
+	want := "## Summary\n\n- shipped it\n#1 priority"
+	if got := Normalize(in); got != want {
+		t.Fatalf("Normalize() = %q, want %q", got, want)
+	}
+}
+
+func TestDocumentRendererWrapsSummary(t *testing.T) {
+	started := time.Date(2026, 3, 20, 9, 30, 0, 0, time.Local)
+	ended := started.Add(95 * time.Minute)
+	r := NewDocumentRenderer(config.Summarization{
+		DocumentTemplate: "# {{title}}\n{{date}} {{time}} ({{duration}})\nWith {{participants}}\n\n{{summary}}\n\n---\n[Audio]({{audio_url}}) · [Transcript]({{transcript_url}})",
+		LinkBaseURL:      "https://wispr.example.com/",
+		Presets: map[string]config.Preset{
+			"standup": {DocumentTemplate: "Standup {{session_id}}\n\n{{summary}}"},
+		},
+	})
+	doc := Document{SessionID: "s1", Preset: "default", StartedAt: started, EndedAt: &ended, Participants: []string{"Speaker 0", "Speaker 1"}}
+
+	got := r.Render("```\n## Roadmap\n\n- ship it\n```", doc)
+	for _, want := range []string{
+		"# Roadmap\nFriday, March 20, 2026 09:30 (1h 35m)\nWith Speaker 0, Speaker 1\n\n## Roadmap",
+		"[Audio](https://wispr.example.com/api/sessions/s1/audio)",
+		"[Transcript](https://wispr.example.com/api/sessions/s1)",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("rendered document missing %q:\n%s", want, got)
+		}
+	}
+
+	doc.Preset = "standup"
+	if got := r.Render("- done", doc); got != "Standup s1\n\n- done" {
+		t.Fatalf("expected the preset template, got %q", got)
+	}
+	if got := r.Render("  ", doc); got != "" {
+		t.Fatalf("expected an empty summary to stay empty, got %q", got)
+	}
+}
+
+func TestDocumentRendererInLocale(t *testing.T) {
+	started := time.Date(2026, 3, 20, 9, 30, 0, 0, time.Local)
+	ended := started.Add(95 * time.Minute)
+	r := NewDocumentRenderer(config.Summarization{DocumentTemplate: "# {{title}}\n{{date}} ({{duration}})\n{{participants}}\n\n{{summary}}"})
+	r.Localizer, _ = i18n.New("de")
+
+	got := r.Render("- erledigt", Document{SessionID: "s1", StartedAt: started, EndedAt: &ended})
+	want := "# Sitzung 2026-03-20 09:30\nFreitag, 20. März 2026 (1 Std. 35 Min.)\nUnbekannt\n\n- erledigt"
+	if got != want {
+		t.Fatalf("Render() = %q, want %q", got, want)
+	}
+}
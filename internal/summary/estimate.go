@@ -0,0 +1,115 @@
+package summary
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrPresetRequired is returned by EstimateSummary when no preset is named
+// and the router would pick one, which can't be known without calling it.
+var ErrPresetRequired = errors.New("preset is required when several are configured")
+
+const (
+	// CharsPerToken is the usual ratio of English text to tokens, close
+	// enough across providers for a cost estimate or a context budget.
+	CharsPerToken = 4
+	// Summaries run to about a tenth of their transcript, within these
+	// bounds.
+	minSummaryTokens = 150
+	maxSummaryTokens = 2000
+)
+
+// Estimate is the projected size and cost of summarizing a transcript with
+// one model.
+type Estimate struct {
+	Model        string  `json:"model"`
+	InputTokens  int     `json:"input_tokens"`
+	OutputTokens int     `json:"output_tokens"`
+	CostUSD      float64 `json:"cost_usd"`
+	// Priced is false when the model has no configured pricing, so CostUSD
+	// is unknown rather than free.
+	Priced bool `json:"priced"`
+	// Selected marks the model the preset summarizes with.
+	Selected bool `json:"selected"`
+}
+
+// EstimateSummary projects the tokens and cost of summarizing transcript
+// with preset, without calling any model: first with the preset's own
+// model, then with each other model that has pricing configured. It
+// returns the preset used, resolving an empty one to the only configured
+// preset or to "default". Decision and action item extraction, which run
+// after a summary, aren't included.
+func (s *Summarizer) EstimateSummary(ctx context.Context, transcript, presetName string) (string, []Estimate, error) {
+	presetName, err := s.estimatePreset(presetName)
+	if err != nil {
+		return "", nil, err
+	}
+	preset, ok := s.cfg.Presets[presetName]
+	if !ok {
+		return "", nil, fmt.Errorf("%w %q", ErrUnknownPreset, presetName)
+	}
+
+	input := 0
+	for _, msg := range summaryMessages(ctx, preset, transcript, summaryLanguage(ctx, s.cfg, preset, transcript)) {
+		input += estimateTokens(msg.Content)
+	}
+	output := min(max(estimateTokens(transcript)/10, minSummaryTokens), maxSummaryTokens)
+	// Short transcripts aren't sent to a model at all.
+	if len(strings.Fields(transcript)) < 20 {
+		input, output = 0, 0
+	}
+
+	selected := resolveModel(ctx, s.presetModel(preset))
+	models := []string{selected}
+	others := make([]string, 0, len(s.cfg.Pricing))
+	for model := range s.cfg.Pricing {
+		if model != selected {
+			others = append(others, model)
+		}
+	}
+	slices.Sort(others)
+	models = append(models, others...)
+
+	estimates := make([]Estimate, 0, len(models))
+	for _, model := range models {
+		pricing, priced := s.cfg.Pricing[model]
+		estimates = append(estimates, Estimate{
+			Model:        model,
+			InputTokens:  input,
+			OutputTokens: output,
+			CostUSD:      pricing.Cost(input, output),
+			Priced:       priced,
+			Selected:     model == selected,
+		})
+	}
+	return presetName, estimates, nil
+}
+
+func (s *Summarizer) estimatePreset(name string) (string, error) {
+	if name != "" {
+		return name, nil
+	}
+	if len(s.cfg.Presets) == 1 {
+		for name := range s.cfg.Presets {
+			return name, nil
+		}
+	}
+	if _, ok := s.cfg.Presets["default"]; ok {
+		return "default", nil
+	}
+	names := make([]string, 0, len(s.cfg.Presets))
+	for name := range s.cfg.Presets {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return "", fmt.Errorf("%w: one of %s", ErrPresetRequired, strings.Join(names, ", "))
+}
+
+// estimateTokens approximates how many tokens text takes.
+func estimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + CharsPerToken - 1) / CharsPerToken
+}
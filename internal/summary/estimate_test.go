@@ -0,0 +1,78 @@
+package summary
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sjawhar/ghost-wispr/internal/config"
+	"github.com/sjawhar/ghost-wispr/internal/llm"
+)
+
+func TestEstimateSummary(t *testing.T) {
+	cfg := config.Summarization{
+		Model: "openai/gpt-4o-mini",
+		Presets: map[string]config.Preset{
+			"default": {SystemPrompt: "system", UserTemplate: "{{transcript}}"},
+			"standup": {SystemPrompt: "system", UserTemplate: "{{transcript}}", Model: "anthropic/claude-sonnet"},
+		},
+		Pricing: map[string]config.ModelPricing{
+			"openai/gpt-4o-mini":      {InputPerMillion: 1, OutputPerMillion: 2},
+			"anthropic/claude-sonnet": {InputPerMillion: 3, OutputPerMillion: 15},
+		},
+	}
+	s := New(cfg, func(provider, model string) (llm.Client, error) {
+		t.Fatal("estimating must not call a model")
+		return nil, nil
+	})
+	transcript := buildTranscript(4000) // 20,000 characters
+
+	preset, estimates, err := s.EstimateSummary(context.Background(), transcript, "standup")
+	if err != nil {
+		t.Fatalf("EstimateSummary failed: %v", err)
+	}
+	if preset != "standup" {
+		t.Fatalf("expected preset standup, got %q", preset)
+	}
+	if len(estimates) != 2 || estimates[0].Model != "anthropic/claude-sonnet" || !estimates[0].Selected || estimates[1].Selected {
+		t.Fatalf("expected the preset's model first and selected, got %+v", estimates)
+	}
+	// 20,000 characters plus the prompt come to about 5,000 tokens in, and
+	// a tenth of the transcript out.
+	e := estimates[0]
+	if e.InputTokens < 5000 || e.InputTokens > 5010 || e.OutputTokens != 500 {
+		t.Fatalf("unexpected token estimate %+v", e)
+	}
+	if want := cfg.Pricing[e.Model].Cost(e.InputTokens, e.OutputTokens); e.CostUSD != want || !e.Priced {
+		t.Fatalf("expected priced cost %v, got %+v", want, e)
+	}
+
+	preset, estimates, err = s.EstimateSummary(context.Background(), transcript, "")
+	if err != nil || preset != "default" || estimates[0].Model != "openai/gpt-4o-mini" {
+		t.Fatalf("expected an empty preset to mean default, got %q %+v %v", preset, estimates, err)
+	}
+
+	if _, _, err := s.EstimateSummary(context.Background(), transcript, "missing"); !errors.Is(err, ErrUnknownPreset) {
+		t.Fatalf("expected ErrUnknownPreset, got %v", err)
+	}
+
+	delete(cfg.Presets, "default")
+	cfg.Presets["retro"] = config.Preset{UserTemplate: "{{transcript}}"}
+	if _, _, err := New(cfg, nil).EstimateSummary(context.Background(), transcript, ""); !errors.Is(err, ErrPresetRequired) {
+		t.Fatalf("expected ErrPresetRequired without a default preset, got %v", err)
+	}
+}
+
+func TestEstimateSummaryUnpricedModel(t *testing.T) {
+	cfg := config.Summarization{
+		Model:   "ollama/llama3",
+		Presets: map[string]config.Preset{"default": {UserTemplate: "{{transcript}}"}},
+	}
+	_, estimates, err := New(cfg, nil).EstimateSummary(context.Background(), buildTranscript(30), "")
+	if err != nil {
+		t.Fatalf("EstimateSummary failed: %v", err)
+	}
+	if len(estimates) != 1 || estimates[0].Priced || estimates[0].OutputTokens != minSummaryTokens {
+		t.Fatalf("expected one unpriced estimate with the minimum output, got %+v", estimates)
+	}
+}
@@ -0,0 +1,154 @@
+package summary
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/sjawhar/ghost-wispr/internal/config"
+)
+
+// LanguageShare is a language's share of the recognisable words in a
+// transcript.
+type LanguageShare struct {
+	Language string  `json:"language"`
+	Share    float64 `json:"share"`
+}
+
+// languageNames are the languages DetectLanguages knows, by ISO 639-1 code.
+var languageNames = map[string]string{
+	"de": "German",
+	"en": "English",
+	"es": "Spanish",
+	"fr": "French",
+	"it": "Italian",
+	"nl": "Dutch",
+	"pt": "Portuguese",
+}
+
+// stopwords are frequent function words that rarely appear in the other
+// languages' lists, which is enough to tell meeting speech apart.
+var stopwords = map[string][]string{
+	"de": {"und", "ich", "nicht", "das", "ist", "wir", "ein", "eine", "auch", "mit", "auf", "aber", "noch", "haben", "sie", "dass", "oder", "wenn", "schon", "jetzt"},
+	"en": {"the", "and", "is", "that", "you", "it", "we", "this", "with", "have", "for", "not", "are", "was", "they", "what", "so", "just", "about", "would"},
+	"es": {"el", "los", "las", "que", "y", "es", "una", "por", "con", "para", "pero", "está", "como", "más", "también", "muy", "hay", "eso", "sí", "entonces"},
+	"fr": {"le", "les", "et", "est", "une", "des", "que", "pas", "je", "nous", "vous", "avec", "pour", "mais", "c'est", "dans", "très", "aussi", "oui", "donc"},
+	"it": {"il", "gli", "che", "è", "sono", "una", "per", "con", "non", "ma", "anche", "molto", "questo", "allora", "perché", "della", "sì", "noi", "cosa", "come"},
+	"nl": {"het", "een", "en", "is", "dat", "niet", "ik", "wij", "met", "voor", "maar", "ook", "nog", "zijn", "heb", "wel", "dus", "ja", "gaan", "van"},
+	"pt": {"o", "os", "que", "não", "uma", "com", "para", "mas", "também", "muito", "isso", "está", "você", "nós", "então", "sim", "mais", "são", "tem", "do"},
+}
+
+// stopwordLanguages maps each stopword to the languages that use it.
+var stopwordLanguages = func() map[string][]string {
+	m := map[string][]string{}
+	for lang, words := range stopwords {
+		for _, w := range words {
+			m[w] = append(m[w], lang)
+		}
+	}
+	return m
+}()
+
+const (
+	// minLanguageEvidence is how many recognisable words a transcript needs
+	// before its language is trusted.
+	minLanguageEvidence = 10
+	// dominantShare is the share above which one language is the
+	// transcript's; below it the meeting counts as mixed.
+	dominantShare = 0.75
+)
+
+// DetectLanguages estimates which languages a transcript is in from its
+// function words, most used first. It returns nil when there is too little
+// text to tell.
+func DetectLanguages(transcript string) []LanguageShare {
+	counts := map[string]float64{}
+	total := 0.0
+	words := strings.FieldsFunc(strings.ToLower(transcript), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	})
+	for _, w := range words {
+		langs := stopwordLanguages[w]
+		// A word several languages share counts a little towards each.
+		for _, lang := range langs {
+			counts[lang] += 1 / float64(len(langs))
+		}
+		if len(langs) > 0 {
+			total++
+		}
+	}
+	if total < minLanguageEvidence {
+		return nil
+	}
+
+	shares := make([]LanguageShare, 0, len(counts))
+	for lang, n := range counts {
+		shares = append(shares, LanguageShare{Language: lang, Share: n / total})
+	}
+	slices.SortFunc(shares, func(a, b LanguageShare) int {
+		return cmp.Or(cmp.Compare(b.Share, a.Share), cmp.Compare(a.Language, b.Language))
+	})
+	return shares
+}
+
+type transcriptLanguagesKey struct{}
+
+// WithTranscriptLanguages returns a context in which summaries take the
+// transcript's languages from shares, as detected during transcription,
+// rather than guessing them from its words.
+func WithTranscriptLanguages(ctx context.Context, shares []LanguageShare) context.Context {
+	return context.WithValue(ctx, transcriptLanguagesKey{}, shares)
+}
+
+func transcriptLanguages(ctx context.Context) []LanguageShare {
+	shares, _ := ctx.Value(transcriptLanguagesKey{}).([]LanguageShare)
+	return shares
+}
+
+// summaryLanguage is the language a summary of transcript is written in:
+// the preset's pinned language, else the transcript's own, as detected
+// during transcription or else from its words. A meeting with no dominant
+// language is summarized in the configured primary language when it was
+// spoken at all, falling back to the most used one. An empty result leaves
+// the language to the model.
+func summaryLanguage(ctx context.Context, cfg config.Summarization, preset config.Preset, transcript string) string {
+	if pinned := strings.TrimSpace(preset.Language); pinned != "" && pinned != config.SummaryLanguageAuto {
+		return languageName(pinned)
+	}
+	shares := transcriptLanguages(ctx)
+	if len(shares) == 0 {
+		shares = DetectLanguages(transcript)
+	}
+	if len(shares) == 0 {
+		return ""
+	}
+	if shares[0].Share < dominantShare && cfg.PrimaryLanguage != "" {
+		primary := baseLanguage(cfg.PrimaryLanguage)
+		if slices.ContainsFunc(shares, func(s LanguageShare) bool { return baseLanguage(s.Language) == primary }) {
+			return languageName(primary)
+		}
+	}
+	return languageName(shares[0].Language)
+}
+
+// languageName spells out a language code, regional or not, for the
+// prompt; anything else is taken to be a name already.
+func languageName(lang string) string {
+	if name, ok := languageNames[baseLanguage(lang)]; ok {
+		return name
+	}
+	return lang
+}
+
+// baseLanguage drops the region from a code such as "en-US".
+func baseLanguage(lang string) string {
+	base, _, _ := strings.Cut(strings.ToLower(lang), "-")
+	return base
+}
+
+// languageInstruction asks for the summary in language.
+func languageInstruction(language string) string {
+	return "Write the summary in " + language + ", whatever language the instructions above are in."
+}
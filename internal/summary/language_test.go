@@ -0,0 +1,82 @@
+package summary
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sjawhar/ghost-wispr/internal/config"
+	"github.com/sjawhar/ghost-wispr/internal/llm"
+)
+
+const (
+	englishSpeech = "Speaker 0: So we have the numbers for this quarter and they are not what we expected, but that is just how it was.\n"
+	spanishSpeech = "Speaker 1: Entonces el equipo está listo para la entrega, pero hay que revisar las cifras con el cliente y también los plazos.\n"
+)
+
+func TestDetectLanguages(t *testing.T) {
+	if got := DetectLanguages(spanishSpeech + spanishSpeech); len(got) == 0 || got[0].Language != "es" {
+		t.Fatalf("expected Spanish, got %+v", got)
+	}
+	if got := DetectLanguages(englishSpeech); len(got) == 0 || got[0].Language != "en" || got[0].Share < dominantShare {
+		t.Fatalf("expected clearly English, got %+v", got)
+	}
+	if got := DetectLanguages("Speaker 0: okay thanks"); got != nil {
+		t.Fatalf("expected too little text to tell, got %+v", got)
+	}
+}
+
+func TestSummaryLanguage(t *testing.T) {
+	mixed := englishSpeech + spanishSpeech
+	tests := []struct {
+		name       string
+		cfg        config.Summarization
+		preset     config.Preset
+		transcript string
+		want       string
+	}{
+		{name: "matches transcript", transcript: spanishSpeech + spanishSpeech, want: "Spanish"},
+		{name: "auto matches transcript", preset: config.Preset{Language: config.SummaryLanguageAuto}, transcript: spanishSpeech + spanishSpeech, want: "Spanish"},
+		{name: "preset pins code", preset: config.Preset{Language: "fr"}, transcript: spanishSpeech, want: "French"},
+		{name: "preset pins name", preset: config.Preset{Language: "Klingon"}, transcript: englishSpeech, want: "Klingon"},
+		{name: "mixed prefers primary", cfg: config.Summarization{PrimaryLanguage: "es"}, transcript: mixed, want: "Spanish"},
+		{name: "mixed without primary", transcript: mixed + englishSpeech, want: "English"},
+		{name: "primary not spoken", cfg: config.Summarization{PrimaryLanguage: "de"}, transcript: mixed + englishSpeech, want: "English"},
+		{name: "too short", transcript: "hola", want: ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := summaryLanguage(context.Background(), tc.cfg, tc.preset, tc.transcript); got != tc.want {
+				t.Fatalf("expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestSummaryLanguagePrefersTranscribedLanguages(t *testing.T) {
+	ctx := WithTranscriptLanguages(context.Background(), []LanguageShare{{Language: "fr-CA", Share: 0.6}, {Language: "en", Share: 0.4}})
+	if got := summaryLanguage(ctx, config.Summarization{}, config.Preset{}, englishSpeech+englishSpeech); got != "French" {
+		t.Fatalf("expected the transcribed language to win, got %q", got)
+	}
+	if got := summaryLanguage(ctx, config.Summarization{PrimaryLanguage: "en"}, config.Preset{}, ""); got != "English" {
+		t.Fatalf("expected the spoken primary language for a mixed meeting, got %q", got)
+	}
+}
+
+func TestSummarizeAsksForTranscriptLanguage(t *testing.T) {
+	client := &mockLLMClient{response: "resumen"}
+	cfg := config.Summarization{
+		Model:   "openai/gpt-4o-mini",
+		Presets: map[string]config.Preset{"default": {SystemPrompt: "Summarize.", UserTemplate: "{{transcript}}"}},
+	}
+	s := New(cfg, func(_, _ string) (llm.Client, error) {
+		return client, nil
+	})
+
+	if _, err := s.SummarizeWithPreset(context.Background(), "s1", spanishSpeech+spanishSpeech, "default"); err != nil {
+		t.Fatal(err)
+	}
+	if user := client.lastMessages[1].Content; !strings.Contains(user, languageInstruction("Spanish")) {
+		t.Fatalf("expected the summary asked for in Spanish, got %q", user)
+	}
+}
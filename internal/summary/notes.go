@@ -0,0 +1,24 @@
+package summary
+
+import "context"
+
+// NoteMarker starts the transcript lines that hold notes typed during the
+// meeting rather than speech.
+const NoteMarker = "[note]"
+
+// notesGuidance tells the model how to read NoteMarker lines.
+const notesGuidance = "Lines starting with " + NoteMarker + " are notes a participant typed during the meeting, not speech. " +
+	"Treat them as their annotations of what mattered: reflect them in the summary and prefer them where they clarify the transcript."
+
+type notesKey struct{}
+
+// WithNotes returns a context in which summaries are told that the
+// transcript has typed notes interleaved with it.
+func WithNotes(ctx context.Context) context.Context {
+	return context.WithValue(ctx, notesKey{}, true)
+}
+
+func hasNotes(ctx context.Context) bool {
+	on, _ := ctx.Value(notesKey{}).(bool)
+	return on
+}
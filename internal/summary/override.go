@@ -0,0 +1,22 @@
+package summary
+
+import "context"
+
+type modelOverrideKey struct{}
+
+// WithModelOverride returns a context in which every summarization, routing
+// and decision-extraction call uses model ("provider/model") instead of the
+// configured one. An empty model leaves the configuration in effect.
+func WithModelOverride(ctx context.Context, model string) context.Context {
+	if model == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, modelOverrideKey{}, model)
+}
+
+func resolveModel(ctx context.Context, configured string) string {
+	if model, ok := ctx.Value(modelOverrideKey{}).(string); ok {
+		return model
+	}
+	return configured
+}
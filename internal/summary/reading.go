@@ -0,0 +1,76 @@
+package summary
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/sjawhar/ghost-wispr/internal/config"
+	"github.com/sjawhar/ghost-wispr/internal/llm"
+)
+
+// ErrUnknownReadingLevel is returned for a reading level that isn't one of
+// config.ReadingLevels.
+var ErrUnknownReadingLevel = errors.New("unknown reading level")
+
+// readingLevelInstructions tell the model who a summary is for.
+var readingLevelInstructions = map[string]string{
+	config.ReadingLevelPlain: "Write for a general audience in plain language: short sentences, everyday words, " +
+		"and any jargon, acronym or product name explained the first time it appears.",
+	config.ReadingLevelExecutive: "Write for a busy executive: lead with the outcome, decisions and risks in a few bullets, " +
+		"then owners and deadlines. Leave out how the discussion went.",
+	config.ReadingLevelTechnical: "Write for specialists who were not in the meeting: keep technical terms, figures, " +
+		"trade-offs considered and open questions, in full detail.",
+}
+
+// rewritePrompt asks for an existing summary in another register.
+const rewritePrompt = "You rewrite meeting summaries for a different audience. Keep every decision, action item, owner, " +
+	"date and figure, add nothing that isn't in the summary, and keep it in markdown and in the summary's language."
+
+type readingLevelKey struct{}
+
+// WithReadingLevel returns a context in which summaries are written at
+// level rather than their preset's. An empty level leaves the preset's.
+func WithReadingLevel(ctx context.Context, level string) context.Context {
+	if level == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, readingLevelKey{}, level)
+}
+
+// ValidReadingLevel reports whether level is empty or one of
+// config.ReadingLevels.
+func ValidReadingLevel(level string) bool {
+	return level == "" || slices.Contains(config.ReadingLevels, level)
+}
+
+func readingLevel(ctx context.Context, preset config.Preset) string {
+	if level, ok := ctx.Value(readingLevelKey{}).(string); ok {
+		return level
+	}
+	return preset.ReadingLevel
+}
+
+// RewriteSummary rewrites a finished summary at level, from the summary
+// alone rather than the transcript, with the model of the preset it was
+// written with.
+func (s *Summarizer) RewriteSummary(ctx context.Context, summary, presetName, level string) (string, error) {
+	instruction, ok := readingLevelInstructions[level]
+	if !ok {
+		return "", fmt.Errorf("%w %q", ErrUnknownReadingLevel, level)
+	}
+	if strings.TrimSpace(summary) == "" {
+		return "", errors.New("no summary to rewrite")
+	}
+	messages := []llm.Message{
+		{Role: "system", Content: rewritePrompt},
+		{Role: "user", Content: instruction + "\n\nSummary:\n\n" + summary},
+	}
+	result, err := s.complete(ctx, s.presetModel(s.cfg.Presets[presetName]), messages)
+	if err != nil {
+		return "", fmt.Errorf("rewrite summary failed after retries: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,71 @@
+package summary
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/config"
+	"github.com/sjawhar/ghost-wispr/internal/llm"
+)
+
+func TestSummarizeAtReadingLevel(t *testing.T) {
+	client := &mockLLMClient{response: "## Summary"}
+	cfg := config.Summarization{
+		Model: "openai/gpt-4o-mini",
+		Presets: map[string]config.Preset{
+			"default": {SystemPrompt: "system", UserTemplate: "{{transcript}}", ReadingLevel: config.ReadingLevelExecutive},
+		},
+	}
+	s := New(cfg, func(string, string) (llm.Client, error) { return client, nil })
+	s.sleep = func(time.Duration) {}
+
+	if _, err := s.SummarizeWithPreset(context.Background(), "s1", buildTranscript(25), "default"); err != nil {
+		t.Fatal(err)
+	}
+	if prompt := client.lastMessages[1].Content; !strings.Contains(prompt, readingLevelInstructions[config.ReadingLevelExecutive]) {
+		t.Fatalf("expected the preset's reading level in the prompt, got %q", prompt)
+	}
+
+	ctx := WithReadingLevel(context.Background(), config.ReadingLevelPlain)
+	if _, err := s.SummarizeWithPreset(ctx, "s1", buildTranscript(25), "default"); err != nil {
+		t.Fatal(err)
+	}
+	prompt := client.lastMessages[1].Content
+	if !strings.Contains(prompt, readingLevelInstructions[config.ReadingLevelPlain]) || strings.Contains(prompt, readingLevelInstructions[config.ReadingLevelExecutive]) {
+		t.Fatalf("expected the requested reading level to replace the preset's, got %q", prompt)
+	}
+}
+
+func TestRewriteSummary(t *testing.T) {
+	client := &mockLLMClient{response: "## In short\n\n- We ship Friday."}
+	var usedModel string
+	cfg := config.Summarization{
+		Model: "openai/gpt-4o-mini",
+		Presets: map[string]config.Preset{
+			"standup": {Model: "anthropic/claude-3-5-haiku-latest"},
+		},
+	}
+	s := New(cfg, func(provider, model string) (llm.Client, error) {
+		usedModel = provider + "/" + model
+		return client, nil
+	})
+	s.sleep = func(time.Duration) {}
+
+	got, err := s.RewriteSummary(context.Background(), "## Summary\n\n- Release moved to Friday after QA sign-off.", "standup", config.ReadingLevelPlain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != client.response || usedModel != "anthropic/claude-3-5-haiku-latest" {
+		t.Fatalf("expected the rewrite from the preset's model, got %q from %s", got, usedModel)
+	}
+	if prompt := client.lastMessages[1].Content; !strings.Contains(prompt, "Release moved to Friday") || !strings.Contains(prompt, readingLevelInstructions[config.ReadingLevelPlain]) {
+		t.Fatalf("expected the summary and level in the prompt, got %q", prompt)
+	}
+
+	if _, err := s.RewriteSummary(context.Background(), "## Summary", "standup", "legalese"); !errors.Is(err, ErrUnknownReadingLevel) {
+		t.Fatalf("expected ErrUnknownReadingLevel, got %v", err)
+	}
+}
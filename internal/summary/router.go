@@ -53,7 +53,7 @@ Available presets:
 %s
 Reply with ONLY the preset name, nothing else.`, sampled, presetList.String())
 
-	provider, model, err := llm.ParseModel(r.cfg.Model)
+	provider, model, err := llm.ParseModel(resolveModel(ctx, r.cfg.Model))
 	if err != nil {
 		slog.Warn("router: falling back to default preset", "reason", "parse model failed", "error", err)
 		return r.fallbackPreset(), nil
@@ -2,7 +2,9 @@ package summary
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"strings"
 	"time"
 
@@ -10,13 +12,23 @@ import (
 	"github.com/sjawhar/ghost-wispr/internal/llm"
 )
 
+// ErrUnknownPreset is returned for a preset name that isn't configured.
+var ErrUnknownPreset = errors.New("unknown preset")
+
 type ClientFactory func(provider, model string) (llm.Client, error)
 
+// PresetSelector picks a preset for a transcript from presets, which maps
+// preset names to descriptions.
+type PresetSelector interface {
+	SelectPreset(ctx context.Context, transcript string, presets map[string]string) (string, error)
+}
+
 type Summarizer struct {
-	cfg     config.Summarization
-	factory ClientFactory
-	router  *Router
-	sleep   func(time.Duration)
+	cfg      config.Summarization
+	factory  ClientFactory
+	router   *Router
+	selector PresetSelector
+	sleep    func(time.Duration)
 }
 
 func New(cfg config.Summarization, factory ClientFactory) *Summarizer {
@@ -48,33 +60,30 @@ func (s *Summarizer) SummarizeWithPreset(ctx context.Context, _ string, transcri
 
 	preset, ok := s.cfg.Presets[presetName]
 	if !ok {
-		return "", fmt.Errorf("unknown preset %q", presetName)
+		return "", fmt.Errorf("%w %q", ErrUnknownPreset, presetName)
 	}
 
-	modelStr := preset.Model
-	if modelStr == "" {
-		modelStr = s.cfg.Model
+	messages := summaryMessages(ctx, preset, transcript, summaryLanguage(ctx, s.cfg, preset, transcript))
+	result, err := s.complete(ctx, s.presetModel(preset), messages)
+	if err != nil {
+		return "", fmt.Errorf("summarize failed after retries: %w", err)
 	}
+	return result, nil
+}
 
-	provider, model, err := llm.ParseModel(modelStr)
+// complete sends messages to model, or the context's override, retrying
+// with backoff.
+func (s *Summarizer) complete(ctx context.Context, model string, messages []llm.Message) (string, error) {
+	provider, name, err := llm.ParseModel(resolveModel(ctx, model))
 	if err != nil {
 		return "", err
 	}
 
-	client, err := s.factory(provider, model)
+	client, err := s.factory(provider, name)
 	if err != nil {
 		return "", fmt.Errorf("create llm client: %w", err)
 	}
 
-	date := time.Now().UTC().Format("2006-01-02")
-	userContent := strings.ReplaceAll(preset.UserTemplate, "{{transcript}}", transcript)
-	userContent = strings.ReplaceAll(userContent, "{{date}}", date)
-
-	messages := []llm.Message{
-		{Role: "system", Content: preset.SystemPrompt},
-		{Role: "user", Content: userContent},
-	}
-
 	backoff := []time.Duration{1 * time.Second, 4 * time.Second, 16 * time.Second}
 	var lastErr error
 	for attempt := range backoff {
@@ -87,10 +96,71 @@ func (s *Summarizer) SummarizeWithPreset(ctx context.Context, _ string, transcri
 			s.sleep(backoff[attempt])
 		}
 	}
-	return "", fmt.Errorf("summarize failed after retries: %w", lastErr)
+	return "", lastErr
+}
+
+// presetModel is the "provider/model" a preset summarizes with.
+func (s *Summarizer) presetModel(preset config.Preset) string {
+	if preset.Model != "" {
+		return preset.Model
+	}
+	return s.cfg.Model
+}
+
+// summaryMessages builds the prompt that summarizes transcript with preset,
+// asking for the summary in language unless it is empty.
+func summaryMessages(ctx context.Context, preset config.Preset, transcript, language string) []llm.Message {
+	date := time.Now().UTC().Format("2006-01-02")
+	userContent := strings.ReplaceAll(preset.UserTemplate, "{{transcript}}", transcript)
+	userContent = strings.ReplaceAll(userContent, "{{date}}", date)
+	if carryOver := carryOverSection(priorActionItems(ctx)); carryOver != "" {
+		if strings.Contains(userContent, "{{prior_action_items}}") {
+			userContent = strings.ReplaceAll(userContent, "{{prior_action_items}}", carryOver)
+		} else {
+			userContent += "\n\n" + carryOver
+		}
+	}
+	userContent = strings.ReplaceAll(userContent, "{{prior_action_items}}", "")
+	if language != "" {
+		userContent += "\n\n" + languageInstruction(language)
+	}
+	if level := readingLevel(ctx, preset); level != "" {
+		userContent += "\n\n" + readingLevelInstructions[level]
+	}
+	if hasNotes(ctx) {
+		userContent += "\n\n" + notesGuidance
+	}
+
+	return []llm.Message{
+		{Role: "system", Content: preset.SystemPrompt},
+		{Role: "user", Content: userContent},
+	}
+}
+
+// SetPresetSelector routes sessions with sel ahead of the LLM router. When sel
+// fails or names an unknown preset, routing falls back as if it were unset.
+func (s *Summarizer) SetPresetSelector(sel PresetSelector) {
+	s.selector = sel
 }
 
 func (s *Summarizer) selectPreset(ctx context.Context, transcript string) (string, error) {
+	if s.selector != nil {
+		descriptions := make(map[string]string, len(s.cfg.Presets))
+		for name, p := range s.cfg.Presets {
+			descriptions[name] = p.Description
+		}
+		chosen, err := s.selector.SelectPreset(ctx, transcript, descriptions)
+		switch {
+		case err != nil:
+			slog.Warn("preset selector failed, falling back", "error", err)
+		case chosen == "":
+		default:
+			if _, ok := s.cfg.Presets[chosen]; ok {
+				return chosen, nil
+			}
+			slog.Warn("preset selector chose unknown preset, falling back", "chosen", chosen)
+		}
+	}
 	if s.router == nil {
 		for name := range s.cfg.Presets {
 			return name, nil
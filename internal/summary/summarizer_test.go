@@ -253,3 +253,114 @@ func buildTranscript(wordCount int) string {
 	}
 	return strings.Join(words, " ")
 }
+
+func TestSummarizeWithModelOverride(t *testing.T) {
+	client := &mockLLMClient{response: "cheap-summary"}
+
+	cfg := config.Summarization{
+		Model: "anthropic/claude-3-5-sonnet-latest",
+		Presets: map[string]config.Preset{
+			"default": {SystemPrompt: "system", UserTemplate: "{{transcript}}", Model: "anthropic/claude-3-opus-latest"},
+		},
+	}
+
+	var gotProvider, gotModel string
+	s := New(cfg, func(provider, model string) (llm.Client, error) {
+		gotProvider, gotModel = provider, model
+		return client, nil
+	})
+
+	ctx := WithModelOverride(context.Background(), "openai/gpt-4o-mini")
+	if _, err := s.SummarizeWithPreset(ctx, "session-1", buildTranscript(25), "default"); err != nil {
+		t.Fatalf("SummarizeWithPreset failed: %v", err)
+	}
+	if gotProvider != "openai" || gotModel != "gpt-4o-mini" {
+		t.Fatalf("expected override model openai/gpt-4o-mini, got %s/%s", gotProvider, gotModel)
+	}
+}
+
+type selectorFunc func(transcript string, presets map[string]string) (string, error)
+
+func (f selectorFunc) SelectPreset(_ context.Context, transcript string, presets map[string]string) (string, error) {
+	return f(transcript, presets)
+}
+
+func TestSummarizeUsesPresetSelector(t *testing.T) {
+	cfg := config.Summarization{
+		Model: "openai/gpt-4o-mini",
+		Presets: map[string]config.Preset{
+			"default": {Description: "general", SystemPrompt: "default-system", UserTemplate: "{{transcript}}"},
+			"standup": {Description: "daily standup", SystemPrompt: "standup-system", UserTemplate: "{{transcript}}"},
+		},
+	}
+
+	cases := []struct {
+		name      string
+		selector  selectorFunc
+		wantRoute bool
+		want      string
+	}{
+		{"chosen", func(_ string, presets map[string]string) (string, error) {
+			if presets["standup"] != "daily standup" {
+				t.Fatalf("expected preset descriptions, got %v", presets)
+			}
+			return "standup", nil
+		}, false, "standup"},
+		{"unknown falls back to router", func(string, map[string]string) (string, error) { return "retro", nil }, true, "default"},
+		{"error falls back to router", func(string, map[string]string) (string, error) { return "", errors.New("boom") }, true, "default"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			summaryClient := &mockLLMClient{response: "## Summary"}
+			routerClient := &mockLLMClient{response: "default"}
+			factoryCalls := 0
+			s := New(cfg, func(string, string) (llm.Client, error) {
+				factoryCalls++
+				if tc.wantRoute && factoryCalls == 1 {
+					return routerClient, nil
+				}
+				return summaryClient, nil
+			})
+			s.sleep = func(time.Duration) {}
+			s.SetPresetSelector(tc.selector)
+
+			_, preset, err := s.Summarize(context.Background(), "session-1", buildTranscript(25))
+			if err != nil {
+				t.Fatalf("Summarize failed: %v", err)
+			}
+			if preset != tc.want {
+				t.Fatalf("expected preset %q, got %q", tc.want, preset)
+			}
+			if routed := routerClient.calls > 0; routed != tc.wantRoute {
+				t.Fatalf("expected LLM routing=%v, got %v", tc.wantRoute, routed)
+			}
+		})
+	}
+}
+
+func TestSummarizeExplainsNotes(t *testing.T) {
+	client := &mockLLMClient{response: "summary"}
+	cfg := config.Summarization{
+		Model:   "openai/gpt-4o-mini",
+		Presets: map[string]config.Preset{"default": {SystemPrompt: "Summarize.", UserTemplate: "{{transcript}}"}},
+	}
+	s := New(cfg, func(_, _ string) (llm.Client, error) {
+		return client, nil
+	})
+
+	transcript := buildTranscript(25) + NoteMarker + " ship date is firm\n"
+	if _, err := s.SummarizeWithPreset(WithNotes(context.Background()), "s1", transcript, "default"); err != nil {
+		t.Fatal(err)
+	}
+	if user := client.lastMessages[1].Content; !strings.HasSuffix(user, notesGuidance) {
+		t.Fatalf("expected the prompt to explain notes, got %q", user)
+	}
+
+	if _, err := s.SummarizeWithPreset(context.Background(), "s1", buildTranscript(25), "default"); err != nil {
+		t.Fatal(err)
+	}
+	if user := client.lastMessages[1].Content; strings.Contains(user, NoteMarker) {
+		t.Fatalf("expected no notes guidance without notes, got %q", user)
+	}
+}
@@ -0,0 +1,205 @@
+package transcribe
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	api "github.com/deepgram/deepgram-go-sdk/v3/pkg/api/listen/v1/websocket/interfaces"
+	"github.com/gorilla/websocket"
+)
+
+// AssemblyAIStreamingURL is AssemblyAI's streaming speech-to-text endpoint.
+const AssemblyAIStreamingURL = "wss://streaming.assemblyai.com/v3/ws"
+
+// assemblyAICheckURL lists a key's most recent transcript, which is enough
+// to tell whether AssemblyAI accepts it.
+var assemblyAICheckURL = "https://api.assemblyai.com/v2/transcript?limit=1"
+
+const (
+	// AssemblyAI takes audio in messages of 50ms to 1s; writes are batched
+	// up to assemblyAIFrame.
+	assemblyAIFrame = 100 * time.Millisecond
+	// assemblyAIStopWait is how long Stop waits for the session to close
+	// after asking for it.
+	assemblyAIStopWait = 2 * time.Second
+)
+
+// AssemblyAI streams 16-bit mono audio to AssemblyAI's streaming API. It
+// connects with the key APIKey returns at the time, so a rotated key is
+// picked up by the next connection.
+type AssemblyAI struct {
+	URL        string
+	APIKey     func() string
+	SampleRate int
+}
+
+// AssemblyAIStream is a connection to AssemblyAI. Its turns are delivered
+// as Deepgram-shaped messages: interim while a turn is in progress, final
+// once AssemblyAI has formatted it.
+type AssemblyAIStream struct {
+	conn       *websocket.Conn
+	handler    Handler
+	frameBytes int
+
+	mu      sync.Mutex
+	pending []byte
+	closed  bool
+	done    chan struct{}
+}
+
+// assemblyAIMessage is any message AssemblyAI sends; Type tells which.
+type assemblyAIMessage struct {
+	Type            string `json:"type"`
+	Transcript      string `json:"transcript"`
+	EndOfTurn       bool   `json:"end_of_turn"`
+	TurnIsFormatted bool   `json:"turn_is_formatted"`
+	Words           []struct {
+		Text       string  `json:"text"`
+		Start      int64   `json:"start"`
+		End        int64   `json:"end"`
+		Confidence float64 `json:"confidence"`
+	} `json:"words"`
+	Error string `json:"error"`
+}
+
+// Check verifies the key with AssemblyAI's REST API.
+func (a AssemblyAI) Check(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, assemblyAICheckURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", a.APIKey())
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("assemblyai key: %w", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("assemblyai key: HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Connect opens a streaming session reporting to handler.
+func (a AssemblyAI) Connect(ctx context.Context, handler Handler) (*AssemblyAIStream, error) {
+	u, err := url.Parse(a.URL)
+	if err != nil {
+		return nil, fmt.Errorf("parse assemblyai url: %w", err)
+	}
+	q := u.Query()
+	q.Set("sample_rate", strconv.Itoa(a.SampleRate))
+	q.Set("encoding", "pcm_s16le")
+	q.Set("format_turns", "true")
+	u.RawQuery = q.Encode()
+
+	conn, resp, err := websocket.DefaultDialer.DialContext(ctx, u.String(), http.Header{"Authorization": {a.APIKey()}})
+	if err != nil {
+		if resp != nil {
+			return nil, fmt.Errorf("connect to assemblyai: %w (HTTP %d)", err, resp.StatusCode)
+		}
+		return nil, fmt.Errorf("connect to assemblyai: %w", err)
+	}
+	s := &AssemblyAIStream{
+		conn:       conn,
+		handler:    handler,
+		frameBytes: int(assemblyAIFrame.Seconds()*float64(a.SampleRate)) * 2,
+		done:       make(chan struct{}),
+	}
+	go s.read()
+	return s, nil
+}
+
+func (s *AssemblyAIStream) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return 0, ErrStreamStopped
+	}
+	s.pending = append(s.pending, p...)
+	if len(s.pending) < s.frameBytes {
+		return len(p), nil
+	}
+	if err := s.conn.WriteMessage(websocket.BinaryMessage, s.pending); err != nil {
+		s.closed = true
+		return 0, fmt.Errorf("send audio to assemblyai: %w", err)
+	}
+	s.pending = s.pending[:0]
+	return len(p), nil
+}
+
+// Stop asks AssemblyAI to finish the session, giving it a moment to return
+// the last turn, then closes the connection.
+func (s *AssemblyAIStream) Stop() {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		if len(s.pending) > 0 {
+			_ = s.conn.WriteMessage(websocket.BinaryMessage, s.pending)
+		}
+		_ = s.conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"Terminate"}`))
+	}
+	s.mu.Unlock()
+
+	select {
+	case <-s.done:
+	case <-time.After(assemblyAIStopWait):
+	}
+	_ = s.conn.Close()
+}
+
+func (s *AssemblyAIStream) read() {
+	defer close(s.done)
+	for {
+		_, data, err := s.conn.ReadMessage()
+		if err != nil {
+			s.mu.Lock()
+			stopping := s.closed
+			s.closed = true
+			s.mu.Unlock()
+			if !stopping && !websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+				streamError(s.handler, "assemblyai", err)
+			}
+			return
+		}
+		var msg assemblyAIMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			slog.Warn("unreadable assemblyai message", "error", err)
+			continue
+		}
+		switch {
+		case msg.Error != "":
+			streamError(s.handler, "assemblyai", errors.New(msg.Error))
+		case msg.Type == "Turn":
+			// With formatting on, a turn ends twice: raw, then formatted.
+			if msg.EndOfTurn && !msg.TurnIsFormatted {
+				continue
+			}
+			if err := s.handler.Message(assemblyAITurn(msg)); err != nil {
+				slog.Warn("handling assemblyai transcript failed", "error", err)
+			}
+		case msg.Type == "Termination":
+			return
+		}
+	}
+}
+
+// assemblyAITurn converts a turn, timed in milliseconds, to a message.
+func assemblyAITurn(msg assemblyAIMessage) *api.MessageResponse {
+	words := make([]Word, 0, len(msg.Words))
+	for _, w := range msg.Words {
+		words = append(words, Word{PunctuatedWord: w.Text, Start: float64(w.Start) / 1000, End: float64(w.End) / 1000, Confidence: w.Confidence})
+	}
+	mr := transcriptMessage(words, msg.EndOfTurn)
+	if msg.Transcript != "" {
+		mr.Channel.Alternatives[0].Transcript = msg.Transcript
+	}
+	return mr
+}
@@ -0,0 +1,30 @@
+package transcribe
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAssemblyAICheckSendsCurrentKey(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "good" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer srv.Close()
+	old := assemblyAICheckURL
+	assemblyAICheckURL = srv.URL
+	defer func() { assemblyAICheckURL = old }()
+
+	key := "bad"
+	a := AssemblyAI{APIKey: func() string { return key }}
+	if err := a.Check(context.Background()); err == nil {
+		t.Fatal("expected a rejected key to fail the check")
+	}
+	key = "good"
+	if err := a.Check(context.Background()); err != nil {
+		t.Fatalf("check with rotated key: %v", err)
+	}
+}
@@ -0,0 +1,161 @@
+package transcribe
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+)
+
+// ErrStreamStopped is returned by writes to a stopped stream.
+var ErrStreamStopped = errors.New("stream stopped")
+
+const (
+	// Chunks are cut at the first pause after minChunk, or at maxChunk
+	// whatever is being said.
+	minChunk = 5 * time.Second
+	maxChunk = 20 * time.Second
+	// A pause is pauseLength of audio quieter than pauseDBFS.
+	pauseLength = 300 * time.Millisecond
+	pauseDBFS   = -40.0
+	// chunkTimeout bounds the transcription of one chunk.
+	chunkTimeout = time.Minute
+)
+
+// ChunkedStream streams to a batch transcriber, such as a Whisper server,
+// as if it were live: 16-bit mono audio is cut into chunks at pauses and
+// each chunk's words are delivered as a final transcript once it has been
+// transcribed. Transcripts lag by a chunk, so it suits a fallback rather
+// than everyday use.
+type ChunkedStream struct {
+	batch      BatchTranscriber
+	handler    Handler
+	name       string
+	sampleRate int
+
+	mu      sync.Mutex
+	buf     []byte
+	start   float64
+	stopped bool
+	chunks  chan chunk
+}
+
+type chunk struct {
+	pcm   []byte
+	start float64
+}
+
+// NewChunkedStream streams sampleRate audio to batch, reporting transcripts
+// and errors to handler; name identifies the provider in errors.
+func NewChunkedStream(name string, batch BatchTranscriber, sampleRate int, handler Handler) *ChunkedStream {
+	s := &ChunkedStream{
+		batch:      batch,
+		handler:    handler,
+		name:       name,
+		sampleRate: sampleRate,
+		chunks:     make(chan chunk, 8),
+	}
+	go s.transcribe()
+	return s
+}
+
+func (s *ChunkedStream) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped {
+		return 0, ErrStreamStopped
+	}
+	s.buf = append(s.buf, p...)
+	if length := s.duration(len(s.buf)); length >= maxChunk || (length >= minChunk && s.pausing()) {
+		s.cut()
+	}
+	return len(p), nil
+}
+
+// Finalize transcribes the audio received so far without waiting for a
+// pause.
+func (s *ChunkedStream) Finalize() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped {
+		return ErrStreamStopped
+	}
+	s.cut()
+	return nil
+}
+
+// Stop transcribes the audio received so far and stops accepting more.
+// Chunks already cut are still delivered.
+func (s *ChunkedStream) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped {
+		return
+	}
+	s.cut()
+	s.stopped = true
+	close(s.chunks)
+}
+
+// cut hands the buffered audio on for transcription. s.mu must be held.
+func (s *ChunkedStream) cut() {
+	if len(s.buf) == 0 {
+		return
+	}
+	c := chunk{pcm: s.buf, start: s.start}
+	s.start += s.duration(len(s.buf)).Seconds()
+	s.buf = nil
+	select {
+	case s.chunks <- c:
+	default:
+		// The transcriber has fallen a few chunks behind; dropping audio
+		// beats holding up capture.
+		slog.Warn("chunked transcription backlog full, dropping audio", "provider", s.name, "seconds", s.duration(len(c.pcm)).Seconds())
+	}
+}
+
+func (s *ChunkedStream) transcribe() {
+	for c := range s.chunks {
+		ctx, cancel := context.WithTimeout(context.Background(), chunkTimeout)
+		words, err := s.batch.TranscribePCM(ctx, c.pcm, s.sampleRate)
+		cancel()
+		if err != nil {
+			streamError(s.handler, s.name, err)
+			continue
+		}
+		if len(words) == 0 {
+			continue
+		}
+		for i := range words {
+			words[i].Start += c.start
+			words[i].End += c.start
+		}
+		if err := s.handler.Message(transcriptMessage(words, true)); err != nil {
+			slog.Warn("handling chunked transcript failed", "provider", s.name, "error", err)
+		}
+	}
+}
+
+// pausing reports whether the buffered audio ends quietly. s.mu must be
+// held.
+func (s *ChunkedStream) pausing() bool {
+	n := int(pauseLength.Seconds()*float64(s.sampleRate)) * 2
+	if len(s.buf) < n {
+		return false
+	}
+	tail := s.buf[len(s.buf)-n:]
+	var sum float64
+	for i := 0; i+1 < len(tail); i += 2 {
+		v := float64(int16(binary.LittleEndian.Uint16(tail[i:]))) / math.MaxInt16
+		sum += v * v
+	}
+	rms := math.Sqrt(sum / float64(len(tail)/2))
+	return rms == 0 || 20*math.Log10(rms) < pauseDBFS
+}
+
+func (s *ChunkedStream) duration(bytes int) time.Duration {
+	return time.Duration(bytes/2) * time.Second / time.Duration(s.sampleRate)
+}
@@ -0,0 +1,84 @@
+package transcribe
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	api "github.com/deepgram/deepgram-go-sdk/v3/pkg/api/listen/v1/websocket/interfaces"
+)
+
+type batchStub struct{}
+
+// TranscribePCM returns one word spanning the chunk.
+func (batchStub) TranscribePCM(_ context.Context, pcm []byte, sampleRate int) ([]Word, error) {
+	return []Word{{PunctuatedWord: "hello", Start: 0, End: float64(len(pcm)/2) / float64(sampleRate)}}, nil
+}
+
+type handlerStub struct {
+	mu       sync.Mutex
+	messages []*api.MessageResponse
+}
+
+func (h *handlerStub) Message(mr *api.MessageResponse) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.messages = append(h.messages, mr)
+	return nil
+}
+
+func (h *handlerStub) Error(*api.ErrorResponse) error { return nil }
+
+func (h *handlerStub) wait(t *testing.T, n int) []*api.MessageResponse {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for {
+		h.mu.Lock()
+		got := append([]*api.MessageResponse(nil), h.messages...)
+		h.mu.Unlock()
+		if len(got) >= n {
+			return got
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d messages, got %d", n, len(got))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestChunkedStreamCutsAtPauses(t *testing.T) {
+	h := &handlerStub{}
+	s := NewChunkedStream("whisper", batchStub{}, 1000, h)
+
+	loud := make([]byte, 2*1000) // 1s of full-scale square wave at 1kHz
+	for i := 0; i < len(loud); i += 4 {
+		loud[i], loud[i+1] = 0xff, 0x7f
+	}
+	for range 6 {
+		if _, err := s.Write(loud); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	// Past minChunk, so the next pause cuts a chunk.
+	if _, err := s.Write(make([]byte, 2*400)); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	first := h.wait(t, 1)[0]
+	if !first.IsFinal || first.Channel.Alternatives[0].Transcript != "hello" {
+		t.Fatalf("expected a final transcript, got %+v", first)
+	}
+
+	if _, err := s.Write(loud); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	s.Stop()
+	second := h.wait(t, 2)[1]
+	word := second.Channel.Alternatives[0].Words[0]
+	if word.Start != 6.4 || word.End != 7.4 {
+		t.Fatalf("expected the second chunk timed from 6.4s, got %v-%v", word.Start, word.End)
+	}
+	if _, err := s.Write(loud); err != ErrStreamStopped {
+		t.Fatalf("expected ErrStreamStopped after Stop, got %v", err)
+	}
+}
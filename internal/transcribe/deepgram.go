@@ -0,0 +1,105 @@
+package transcribe
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+
+	prerecorded "github.com/deepgram/deepgram-go-sdk/v3/pkg/api/listen/v1/rest"
+	manage "github.com/deepgram/deepgram-go-sdk/v3/pkg/api/manage/v1"
+	interfaces "github.com/deepgram/deepgram-go-sdk/v3/pkg/client/interfaces"
+	client "github.com/deepgram/deepgram-go-sdk/v3/pkg/client/listen"
+	manageclient "github.com/deepgram/deepgram-go-sdk/v3/pkg/client/manage"
+)
+
+// DeepgramBatch transcribes recorded audio with Deepgram's pre-recorded API.
+type DeepgramBatch struct {
+	apiKey   func() string
+	model    string
+	language string
+
+	mu              sync.Mutex
+	keywords        []string
+	redact          []string
+	profanityFilter bool
+}
+
+// NewDeepgramBatch transcribes with model in language, which may be
+// LanguageAuto, using the key apiKey returns at the time.
+func NewDeepgramBatch(apiKey func() string, model, language string) *DeepgramBatch {
+	return &DeepgramBatch{apiKey: apiKey, model: model, language: language}
+}
+
+// SetKeywords changes the keywords boosted from the next transcription on.
+func (d *DeepgramBatch) SetKeywords(keywords []string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.keywords = keywords
+}
+
+// Keywords returns the keywords boosted.
+func (d *DeepgramBatch) Keywords() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.keywords
+}
+
+// SetRedaction has Deepgram redact the entity classes in redact, such as
+// "pci" or "pii", and mask profanity if profanityFilter is set.
+func (d *DeepgramBatch) SetRedaction(redact []string, profanityFilter bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.redact, d.profanityFilter = redact, profanityFilter
+}
+
+// Check verifies the key by listing the projects it belongs to.
+func (d *DeepgramBatch) Check(ctx context.Context) error {
+	dg := manage.New(manageclient.New(d.apiKey(), &interfaces.ClientOptions{}))
+	if _, err := dg.ListProjects(ctx); err != nil {
+		return fmt.Errorf("deepgram key: %w", err)
+	}
+	return nil
+}
+
+func (d *DeepgramBatch) TranscribePCM(ctx context.Context, pcm []byte, sampleRate int) ([]Word, error) {
+	d.mu.Lock()
+	keywords, redact, profanityFilter := d.keywords, d.redact, d.profanityFilter
+	d.mu.Unlock()
+	dg := prerecorded.New(client.NewREST(d.apiKey(), &interfaces.ClientOptions{}))
+	resp, err := dg.FromStream(ctx, bytes.NewReader(pcm), &interfaces.PreRecordedTranscriptionOptions{
+		Model:           d.model,
+		Language:        DeepgramLanguage(d.language),
+		Diarize:         true,
+		Punctuate:       true,
+		SmartFormat:     true,
+		Encoding:        "linear16",
+		SampleRate:      sampleRate,
+		Channels:        1,
+		Keywords:        keywords,
+		ProfanityFilter: profanityFilter,
+		Redact:          redact,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("deepgram batch transcription: %w", err)
+	}
+	if resp.Results == nil || len(resp.Results.Channels) == 0 || len(resp.Results.Channels[0].Alternatives) == 0 {
+		return nil, nil
+	}
+
+	channel := resp.Results.Channels[0]
+	alt := channel.Alternatives[0]
+	words := make([]Word, 0, len(alt.Words))
+	for _, w := range alt.Words {
+		text := w.PunctuatedWord
+		if text == "" {
+			text = w.Word
+		}
+		language := w.Language
+		if language == "" {
+			language = channel.DetectedLanguage
+		}
+		words = append(words, Word{Speaker: w.Speaker, PunctuatedWord: text, Start: w.Start, End: w.End, Language: language, Confidence: w.Confidence})
+	}
+	return words, nil
+}
@@ -0,0 +1,279 @@
+package transcribe
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Provider is a live transcription service in a failover chain.
+type Provider struct {
+	Name  string
+	Model string
+	// Connect opens a stream. A new connection's timestamps start from zero,
+	// so it is passed the seconds of audio streamed before it, to add to them.
+	Connect func(offset float64) (Stream, error)
+}
+
+// ErrNoProvider is returned by writes while no provider in the chain is
+// connected.
+var ErrNoProvider = errors.New("no transcription provider connected")
+
+const (
+	// failoverErrors errors reported within failoverWindow move the chain to
+	// its next provider.
+	failoverErrors = 3
+	failoverWindow = time.Minute
+	// failbackInterval is how often providers preferred to the active one
+	// are tried again.
+	failbackInterval = 5 * time.Minute
+)
+
+// Failover streams audio to the first provider of a chain that connects.
+// When the active provider keeps failing it fails over to the next one,
+// and it moves back up the chain once a preferred provider connects again,
+// so a Deepgram outage falls back to, say, a local Whisper server without
+// ending the session. A dropped connection is reconnected by Run.
+type Failover struct {
+	swap      *SwapWriter
+	providers []Provider
+	onSwitch  func(Provider)
+	dropped   chan error
+
+	// OnStatus, if set before Run, is told whenever the connection drops,
+	// a reconnect attempt fails, or a provider connects.
+	OnStatus func(Status)
+
+	mu sync.Mutex
+	// active indexes providers; -1 while none is connected.
+	active     int
+	switching  bool
+	recovering bool
+	errors     []time.Time
+}
+
+// NewFailover connects the first provider in providers, most preferred
+// first, that it can. onSwitch, if set, is called with each provider that
+// becomes active, starting with that one. If none connects, writes fail
+// with ErrNoProvider until Reconnect succeeds.
+func NewFailover(providers []Provider, sampleRate, channels int, onSwitch func(Provider)) *Failover {
+	f := &Failover{providers: providers, onSwitch: onSwitch, active: -1, dropped: make(chan error, 1)}
+	var stream Stream = downStream{}
+	for i, p := range providers {
+		s, err := p.Connect(0)
+		if err != nil {
+			slog.Warn("transcription provider unavailable", "provider", p.Name, "error", err)
+			continue
+		}
+		stream, f.active = s, i
+		break
+	}
+	f.swap = NewSwapWriter(stream, sampleRate, channels, time.Minute)
+	if f.active >= 0 && onSwitch != nil {
+		onSwitch(providers[f.active])
+	}
+	return f
+}
+
+// Active returns the provider audio is streamed to, and false if none is
+// connected.
+func (f *Failover) Active() (Provider, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.active < 0 {
+		return Provider{}, false
+	}
+	return f.providers[f.active], true
+}
+
+// Current returns the stream audio is sent to.
+func (f *Failover) Current() Stream {
+	return f.swap.Current()
+}
+
+// Write sends audio to the active provider. A failed write means the
+// connection has dropped, and has Run reconnect it; until it does, writes
+// fail with ErrReconnecting so a FallbackWriter in front buffers the audio.
+func (f *Failover) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	recovering := f.recovering
+	f.mu.Unlock()
+	if recovering {
+		f.swap.Skip(len(p))
+		return 0, ErrReconnecting
+	}
+
+	n, err := f.swap.Write(p)
+	if err != nil {
+		select {
+		case f.dropped <- err:
+		default:
+		}
+	}
+	return n, err
+}
+
+// ReportError counts an error the active provider reported; enough of them
+// close together fail over to the next provider.
+func (f *Failover) ReportError(err error) {
+	now := time.Now()
+	f.mu.Lock()
+	kept := f.errors[:0]
+	for _, at := range f.errors {
+		if now.Sub(at) < failoverWindow {
+			kept = append(kept, at)
+		}
+	}
+	f.errors = append(kept, now)
+	persistent := len(f.errors) >= failoverErrors && !f.switching
+	if persistent {
+		f.errors = nil
+	}
+	f.mu.Unlock()
+
+	if persistent {
+		slog.Warn("live transcription provider keeps failing, failing over", "error", err)
+		go f.failover()
+	}
+}
+
+// Reconnect connects the most preferred provider that it can, including
+// the active one afresh, and reports whether one connected, as after a
+// resume from suspend leaves the stream dead.
+func (f *Failover) Reconnect() bool {
+	candidates := make([]int, len(f.providers))
+	for i := range candidates {
+		candidates[i] = i
+	}
+	return f.switchTo(candidates) == nil
+}
+
+// Refresh reconnects the active provider, say to pick up a new API key, if
+// it is the one named; the old connection carries on if the new one fails.
+// Any other provider picks up its settings when it next connects.
+func (f *Failover) Refresh(name string) error {
+	f.mu.Lock()
+	active := f.active
+	f.mu.Unlock()
+	if active < 0 || f.providers[active].Name != name {
+		return nil
+	}
+	return f.switchTo([]int{active})
+}
+
+// Run supervises the chain until ctx is done: it reconnects dropped
+// connections, and every so often tries the providers preferred to the
+// active one, moving back to the first that connects.
+func (f *Failover) Run(ctx context.Context) {
+	if p, ok := f.Active(); ok {
+		f.report(Status{State: StatusConnected, Provider: p.Name})
+	} else {
+		f.recover(ctx, ErrNoProvider)
+	}
+
+	ticker := time.NewTicker(failbackInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case err := <-f.dropped:
+			f.recover(ctx, err)
+		case <-ticker.C:
+			f.failback()
+		}
+	}
+}
+
+func (f *Failover) failback() {
+	f.mu.Lock()
+	active := f.active
+	f.mu.Unlock()
+	if active == 0 {
+		return
+	}
+	// With nothing connected, every provider is preferred.
+	if active < 0 {
+		active = len(f.providers)
+	}
+	candidates := make([]int, active)
+	for i := range candidates {
+		candidates[i] = i
+	}
+	f.switchTo(candidates)
+}
+
+// failover moves to the providers after the active one, wrapping around to
+// those before it, and finally reconnects the active one if none of them
+// connects.
+func (f *Failover) failover() {
+	f.mu.Lock()
+	active := f.active
+	f.mu.Unlock()
+
+	n := len(f.providers)
+	candidates := make([]int, 0, n)
+	for i := 1; i <= n; i++ {
+		candidates = append(candidates, (active+i+n)%n)
+	}
+	f.switchTo(candidates)
+}
+
+// switchTo swaps in a connection to the first of candidates that connects.
+func (f *Failover) switchTo(candidates []int) error {
+	f.mu.Lock()
+	if f.switching {
+		f.mu.Unlock()
+		return ErrSwapInProgress
+	}
+	f.switching = true
+	f.mu.Unlock()
+
+	connected := -1
+	err := f.swap.Swap(func(offset float64) (Stream, error) {
+		var errs []error
+		for _, i := range candidates {
+			p := f.providers[i]
+			stream, err := p.Connect(offset)
+			if err != nil {
+				slog.Warn("transcription provider unavailable", "provider", p.Name, "error", err)
+				errs = append(errs, err)
+				continue
+			}
+			connected = i
+			return stream, nil
+		}
+		return nil, errors.Join(append(errs, ErrNoProvider)...)
+	})
+
+	f.mu.Lock()
+	f.switching = false
+	changed := err == nil && connected != f.active
+	if err == nil {
+		f.active = connected
+		f.errors = nil
+	}
+	f.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+	p := f.providers[connected]
+	if changed {
+		slog.Info("live transcription switched provider", "provider", p.Name)
+		if f.onSwitch != nil {
+			f.onSwitch(p)
+		}
+	}
+	f.report(Status{State: StatusConnected, Provider: p.Name})
+	return nil
+}
+
+// downStream stands in for a connection while no provider is connected.
+type downStream struct{}
+
+func (downStream) Write([]byte) (int, error) { return 0, ErrNoProvider }
+
+func (downStream) Stop() {}
@@ -0,0 +1,154 @@
+package transcribe
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type providerStub struct {
+	name   string
+	down   bool
+	stream *streamStub
+}
+
+func (p *providerStub) provider() Provider {
+	return Provider{Name: p.name, Connect: func(float64) (Stream, error) {
+		if p.down {
+			return nil, errors.New(p.name + " unavailable")
+		}
+		p.stream = &streamStub{}
+		return p.stream, nil
+	}}
+}
+
+func TestFailoverStartsWithFirstProviderThatConnects(t *testing.T) {
+	deepgram := &providerStub{name: "deepgram", down: true}
+	whisper := &providerStub{name: "whisper"}
+	var switched []string
+	f := NewFailover([]Provider{deepgram.provider(), whisper.provider()}, 16000, 1, func(p Provider) {
+		switched = append(switched, p.Name)
+	})
+
+	if p, ok := f.Active(); !ok || p.Name != "whisper" {
+		t.Fatalf("expected whisper active, got %+v ok=%v", p, ok)
+	}
+	if _, err := f.Write(make([]byte, 320)); err != nil || whisper.stream.Len() != 320 {
+		t.Fatalf("expected audio sent to whisper, got err=%v", err)
+	}
+
+	// Once the preferred provider is back, the chain moves back to it.
+	deepgram.down = false
+	f.failback()
+	if p, _ := f.Active(); p.Name != "deepgram" {
+		t.Fatalf("expected failback to deepgram, got %q", p.Name)
+	}
+	if !whisper.stream.stopped {
+		t.Fatal("expected the whisper stream stopped")
+	}
+	if len(switched) != 2 || switched[0] != "whisper" || switched[1] != "deepgram" {
+		t.Fatalf("expected switches to whisper then deepgram, got %v", switched)
+	}
+}
+
+func TestFailoverMovesOnAfterPersistentErrors(t *testing.T) {
+	deepgram := &providerStub{name: "deepgram"}
+	assemblyAI := &providerStub{name: "assemblyai"}
+	f := NewFailover([]Provider{deepgram.provider(), assemblyAI.provider()}, 16000, 1, nil)
+
+	for range failoverErrors - 1 {
+		f.ReportError(errors.New("net0001"))
+	}
+	if p, _ := f.Active(); p.Name != "deepgram" {
+		t.Fatalf("expected a couple of errors tolerated, got %q active", p.Name)
+	}
+
+	f.ReportError(errors.New("net0001"))
+	deadline := time.Now().Add(time.Second)
+	for {
+		if p, _ := f.Active(); p.Name == "assemblyai" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected failover to assemblyai")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestFailoverWithNoProviderConnected(t *testing.T) {
+	deepgram := &providerStub{name: "deepgram", down: true}
+	f := NewFailover([]Provider{deepgram.provider()}, 16000, 1, nil)
+
+	if _, ok := f.Active(); ok {
+		t.Fatal("expected no provider active")
+	}
+	if _, err := f.Write(make([]byte, 320)); !errors.Is(err, ErrNoProvider) {
+		t.Fatalf("expected ErrNoProvider, got %v", err)
+	}
+	if f.Reconnect() {
+		t.Fatal("expected reconnect to fail while the provider is down")
+	}
+	deepgram.down = false
+	if !f.Reconnect() {
+		t.Fatal("expected reconnect once the provider is back")
+	}
+	if p, ok := f.Active(); !ok || p.Name != "deepgram" {
+		t.Fatalf("expected deepgram active, got %+v ok=%v", p, ok)
+	}
+}
+
+// droppedStream rejects every write, as a connection that has gone away.
+type droppedStream struct{ streamStub }
+
+func (*droppedStream) Write([]byte) (int, error) { return 0, errors.New("connection is not valid") }
+
+func TestFailoverReconnectsDroppedStreamWithBackoff(t *testing.T) {
+	var connects atomic.Int32
+	next := &streamStub{}
+	deepgram := Provider{Name: "deepgram", Connect: func(float64) (Stream, error) {
+		switch connects.Add(1) {
+		case 1:
+			return &droppedStream{}, nil
+		case 2:
+			return nil, errors.New("dial tcp: no route to host")
+		default:
+			return next, nil
+		}
+	}}
+	f := NewFailover([]Provider{deepgram}, 16000, 1, nil)
+	statuses := make(chan Status, 8)
+	f.OnStatus = func(s Status) { statuses <- s }
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go f.Run(ctx)
+
+	if s := <-statuses; s.State != StatusConnected || s.Provider != "deepgram" {
+		t.Fatalf("expected connected status first, got %+v", s)
+	}
+	if _, err := f.Write(make([]byte, 320)); err == nil {
+		t.Fatal("expected the dropped stream's write to fail")
+	}
+	if s := <-statuses; s.State != StatusReconnecting || s.Attempt != 0 {
+		t.Fatalf("expected reconnecting status, got %+v", s)
+	}
+	if s := <-statuses; s.State != StatusReconnecting || s.Attempt != 1 || s.RetryIn != reconnectMin || s.Err == nil {
+		t.Fatalf("expected a failed attempt retried after %v, got %+v", reconnectMin, s)
+	}
+	if _, err := f.Write(make([]byte, 320)); !errors.Is(err, ErrReconnecting) {
+		t.Fatalf("expected ErrReconnecting while reconnecting, got %v", err)
+	}
+	select {
+	case s := <-statuses:
+		if s.State != StatusConnected {
+			t.Fatalf("expected connected status, got %+v", s)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("expected the stream reconnected")
+	}
+	if f.Current() != next {
+		t.Fatal("expected audio to go to the new connection")
+	}
+}
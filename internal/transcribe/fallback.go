@@ -0,0 +1,122 @@
+package transcribe
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Gap is audio captured while live transcription was unavailable.
+type Gap struct {
+	SessionID  string
+	Start      time.Time
+	PCM        []byte
+	SampleRate int
+}
+
+// Duration returns the length of the buffered 16-bit mono audio.
+func (g Gap) Duration() time.Duration {
+	if g.SampleRate <= 0 {
+		return 0
+	}
+	return time.Duration(len(g.PCM)/2) * time.Second / time.Duration(g.SampleRate)
+}
+
+// FallbackWriter forwards PCM to a live transcription stream. When the stream
+// rejects a write, audio is buffered instead; once live writes succeed again,
+// the stream having reconnected itself as a Failover does, the buffered span
+// is handed to onGap. Write never fails, so recording carries on while
+// offline.
+type FallbackWriter struct {
+	live       io.Writer
+	sampleRate int
+	maxBytes   int
+	session    func() string
+	onGap      func(Gap)
+
+	mu   sync.Mutex
+	down bool
+	gap  *Gap
+}
+
+// NewFallbackWriter wraps live. session reports the session active when a gap
+// begins; at most maxDuration of audio is buffered per gap, and a longer
+// outage is handed to onGap in pieces of that length as it goes.
+func NewFallbackWriter(live io.Writer, sampleRate int, maxDuration time.Duration, session func() string, onGap func(Gap)) *FallbackWriter {
+	return &FallbackWriter{
+		live:       live,
+		sampleRate: sampleRate,
+		maxBytes:   int(maxDuration.Seconds() * float64(sampleRate) * 2),
+		session:    session,
+		onGap:      onGap,
+	}
+}
+
+func (w *FallbackWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	down := w.down
+	w.mu.Unlock()
+
+	if _, err := w.live.Write(p); err == nil {
+		w.recovered()
+		return len(p), nil
+	} else if !down {
+		slog.Warn("live transcription unavailable, buffering audio", "error", err)
+	}
+
+	w.buffer(p)
+	return len(p), nil
+}
+
+func (w *FallbackWriter) buffer(p []byte) {
+	w.mu.Lock()
+
+	if !w.down {
+		w.down = true
+		w.gap = w.newGap()
+	}
+
+	// A full buffer is handed on as a gap of its own, so a long outage is
+	// transcribed in pieces rather than cut short.
+	var full *Gap
+	if w.maxBytes > 0 && len(w.gap.PCM) > 0 && len(w.gap.PCM)+len(p) > w.maxBytes {
+		full = w.gap
+		w.gap = w.newGap()
+	}
+	w.gap.PCM = append(w.gap.PCM, p...)
+	w.mu.Unlock()
+
+	if full != nil && w.onGap != nil {
+		slog.Info("offline audio buffer full, handing it on", "gap", full.Duration())
+		w.onGap(*full)
+	}
+}
+
+// newGap starts buffering for the session active now.
+func (w *FallbackWriter) newGap() *Gap {
+	sessionID := ""
+	if w.session != nil {
+		sessionID = w.session()
+	}
+	return &Gap{SessionID: sessionID, Start: time.Now().UTC(), SampleRate: w.sampleRate}
+}
+
+func (w *FallbackWriter) recovered() {
+	w.mu.Lock()
+	gap := w.gap
+	w.down = false
+	w.gap = nil
+	w.mu.Unlock()
+
+	if gap != nil && len(gap.PCM) > 0 && w.onGap != nil {
+		slog.Info("live transcription restored", "gap", gap.Duration())
+		w.onGap(*gap)
+	}
+}
+
+// BatchTranscriber transcribes a span of recorded 16-bit mono PCM.
+type BatchTranscriber interface {
+	TranscribePCM(ctx context.Context, pcm []byte, sampleRate int) ([]Word, error)
+}
@@ -0,0 +1,89 @@
+package transcribe
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type switchableWriter struct {
+	down    bool
+	written int
+}
+
+func (w *switchableWriter) Write(p []byte) (int, error) {
+	if w.down {
+		return 0, errors.New("connection is not valid")
+	}
+	w.written += len(p)
+	return len(p), nil
+}
+
+func TestFallbackWriterBuffersGap(t *testing.T) {
+	live := &switchableWriter{}
+	var gaps []Gap
+	w := NewFallbackWriter(live, 16000, time.Minute, func() string { return "s1" }, func(g Gap) { gaps = append(gaps, g) })
+
+	chunk := make([]byte, 3200) // 100ms at 16kHz
+	if _, err := w.Write(chunk); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	live.down = true
+	for range 10 {
+		if n, err := w.Write(chunk); err != nil || n != len(chunk) {
+			t.Fatalf("expected write to succeed while offline, got n=%d err=%v", n, err)
+		}
+	}
+	if len(gaps) != 0 {
+		t.Fatalf("expected no gap before reconnect, got %d", len(gaps))
+	}
+
+	live.down = false
+	if _, err := w.Write(chunk); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	if len(gaps) != 1 {
+		t.Fatalf("expected one gap, got %d", len(gaps))
+	}
+	if gaps[0].SessionID != "s1" {
+		t.Fatalf("expected gap session s1, got %q", gaps[0].SessionID)
+	}
+	if gaps[0].Duration() != time.Second {
+		t.Fatalf("expected 1s gap, got %v", gaps[0].Duration())
+	}
+	if live.written != 2*len(chunk) {
+		t.Fatalf("expected only online chunks written live, got %d bytes", live.written)
+	}
+}
+
+func TestFallbackWriterSplitsLongGap(t *testing.T) {
+	live := &switchableWriter{down: true}
+	var gaps []Gap
+	w := NewFallbackWriter(live, 16000, 500*time.Millisecond, nil, func(g Gap) { gaps = append(gaps, g) })
+
+	chunk := make([]byte, 3200)
+	for range 12 {
+		_, _ = w.Write(chunk)
+	}
+	if len(gaps) != 2 {
+		t.Fatalf("expected full buffers handed on while offline, got %d gaps", len(gaps))
+	}
+	live.down = false
+	_, _ = w.Write(chunk)
+
+	if len(gaps) != 3 {
+		t.Fatalf("expected the rest handed on at reconnect, got %d gaps", len(gaps))
+	}
+	var total time.Duration
+	for _, g := range gaps {
+		if g.Duration() > 500*time.Millisecond {
+			t.Fatalf("expected gaps of at most 500ms, got %v", g.Duration())
+		}
+		total += g.Duration()
+	}
+	if total != 1200*time.Millisecond {
+		t.Fatalf("expected no audio dropped, got %v", total)
+	}
+}
@@ -0,0 +1,55 @@
+package transcribe
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MaxKeywords caps the boosted keywords. Each goes in the stream's URL, and
+// Deepgram's accuracy drops as the list grows.
+const MaxKeywords = 100
+
+// ErrInvalidKeyword is returned for keywords Deepgram would reject.
+var ErrInvalidKeyword = errors.New("invalid keyword")
+
+// NormalizeKeywords tidies keywords to boost: names, product terms and
+// acronyms, each optionally followed by ":intensifier" (say "Kubernetes:2",
+// or a negative number to suppress a word). Blanks and repeats are dropped.
+// It returns the keywords that are usable along with an error describing
+// any that aren't.
+func NormalizeKeywords(keywords []string) ([]string, error) {
+	var kept []string
+	var errs []error
+	seen := make(map[string]bool, len(keywords))
+	for _, raw := range keywords {
+		kw := strings.TrimSpace(raw)
+		if kw == "" {
+			continue
+		}
+		term := kw
+		if i := strings.LastIndexByte(kw, ':'); i >= 0 {
+			term = strings.TrimSpace(kw[:i])
+			boost := strings.TrimSpace(kw[i+1:])
+			if _, err := strconv.ParseFloat(boost, 64); err != nil {
+				errs = append(errs, fmt.Errorf("%w %q: intensifier must be a number", ErrInvalidKeyword, kw))
+				continue
+			}
+			kw = term + ":" + boost
+		}
+		if term == "" {
+			errs = append(errs, fmt.Errorf("%w %q: missing term", ErrInvalidKeyword, kw))
+			continue
+		}
+		if key := strings.ToLower(term); !seen[key] {
+			seen[key] = true
+			kept = append(kept, kw)
+		}
+	}
+	if len(kept) > MaxKeywords {
+		errs = append(errs, fmt.Errorf("%w: at most %d keywords, got %d", ErrInvalidKeyword, MaxKeywords, len(kept)))
+		kept = kept[:MaxKeywords]
+	}
+	return kept, errors.Join(errs...)
+}
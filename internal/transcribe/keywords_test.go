@@ -0,0 +1,38 @@
+package transcribe
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeKeywords(t *testing.T) {
+	got, err := NormalizeKeywords([]string{" Kubernetes ", "", "OKR : 2", "okr", "Sjawhar:-1.5", "Ghost Wispr"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"Kubernetes", "OKR:2", "Sjawhar:-1.5", "Ghost Wispr"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestNormalizeKeywordsReportsInvalid(t *testing.T) {
+	got, err := NormalizeKeywords([]string{"Kubernetes", "OKR:lots", ":2"})
+	if !errors.Is(err, ErrInvalidKeyword) {
+		t.Fatalf("expected ErrInvalidKeyword, got %v", err)
+	}
+	if !reflect.DeepEqual(got, []string{"Kubernetes"}) {
+		t.Fatalf("expected the valid keyword kept, got %q", got)
+	}
+
+	many := make([]string, MaxKeywords+1)
+	for i := range many {
+		many[i] = fmt.Sprintf("term%d", i)
+	}
+	got, err = NormalizeKeywords(many)
+	if !errors.Is(err, ErrInvalidKeyword) || len(got) != MaxKeywords {
+		t.Fatalf("expected the list capped at %d with an error, got %d, %v", MaxKeywords, len(got), err)
+	}
+}
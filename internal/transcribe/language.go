@@ -0,0 +1,16 @@
+package transcribe
+
+import "strings"
+
+// LanguageAuto configures transcription to detect the language spoken,
+// word by word, for meetings that switch between languages.
+const LanguageAuto = "auto"
+
+// DeepgramLanguage returns the language parameter Deepgram expects for a
+// configured language: "multi" enables its code-switching model.
+func DeepgramLanguage(language string) string {
+	if strings.EqualFold(language, LanguageAuto) {
+		return "multi"
+	}
+	return language
+}
@@ -0,0 +1,155 @@
+package transcribe
+
+import (
+	"io"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// captureClockWindow is how far back a CaptureClock remembers when audio
+	// was captured. Transcripts arrive seconds after their audio, so older
+	// marks are never looked up.
+	captureClockWindow = 5 * time.Minute
+	// maxTranscriptLatency bounds what counts as a real measurement. After a
+	// reconnect a provider's clock restarts at zero, and its first results
+	// would otherwise read as the audio having been captured long ago.
+	maxTranscriptLatency = 2 * time.Minute
+	// latencySamples is how many recent measurements percentiles are taken
+	// over.
+	latencySamples = 1000
+)
+
+// CaptureClock stamps audio with the wall time it was captured as it is
+// passed on to a transcriber, so a time on the transcriber's stream can be
+// mapped back to when it was spoken. It sits where audio enters the
+// transcription path, before anything that buffers or drops audio.
+type CaptureClock struct {
+	dst       io.Writer
+	frameRate float64
+	frameSize int
+
+	mu     sync.Mutex
+	frames int64
+	marks  []captureMark
+}
+
+type captureMark struct {
+	frame int64
+	at    time.Time
+}
+
+// NewCaptureClock passes PCM16-LE at sampleRate with the given number of
+// interleaved channels on to dst.
+func NewCaptureClock(dst io.Writer, sampleRate, channels int) *CaptureClock {
+	return &CaptureClock{dst: dst, frameRate: float64(sampleRate), frameSize: max(channels, 1) * 2}
+}
+
+func (c *CaptureClock) Write(p []byte) (int, error) {
+	c.mark(len(p), time.Now())
+	return c.dst.Write(p)
+}
+
+func (c *CaptureClock) mark(n int, at time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.marks = append(c.marks, captureMark{frame: c.frames, at: at})
+	c.frames += int64(n / c.frameSize)
+	if old := c.marks[0]; at.Sub(old.at) > 2*captureClockWindow {
+		cut := sort.Search(len(c.marks), func(i int) bool { return at.Sub(c.marks[i].at) <= captureClockWindow })
+		c.marks = slices.Delete(c.marks, 0, cut)
+	}
+}
+
+// CapturedAt returns when the audio t seconds into the stream was captured.
+// It reports false for times not yet written or too long ago to remember.
+func (c *CaptureClock) CapturedAt(t float64) (time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	frame := int64(t * c.frameRate)
+	if len(c.marks) == 0 || frame < c.marks[0].frame || frame > c.frames {
+		return time.Time{}, false
+	}
+	i := sort.Search(len(c.marks), func(i int) bool { return c.marks[i].frame > frame }) - 1
+	mark := c.marks[i]
+	return mark.at.Add(time.Duration(float64(frame-mark.frame) / c.frameRate * float64(time.Second))), true
+}
+
+// LatencyStats summarize how long a provider takes to finalize transcripts.
+type LatencyStats struct {
+	Provider string `json:"provider"`
+	// Count and Sum cover every measurement since startup; the
+	// percentiles only the most recent ones.
+	Count int64   `json:"count"`
+	Sum   float64 `json:"sum_seconds"`
+	P50   float64 `json:"p50_ms"`
+	P90   float64 `json:"p90_ms"`
+	P99   float64 `json:"p99_ms"`
+	Max   float64 `json:"max_ms"`
+}
+
+// LatencyTracker measures the delay from capturing audio to receiving its
+// final transcript from a streaming provider.
+type LatencyTracker struct {
+	provider string
+	clock    *CaptureClock
+
+	mu     sync.Mutex
+	recent []time.Duration
+	next   int
+	count  int64
+	sum    time.Duration
+}
+
+// NewLatencyTracker measures provider's transcripts against the capture
+// times clock recorded.
+func NewLatencyTracker(provider string, clock *CaptureClock) *LatencyTracker {
+	return &LatencyTracker{provider: provider, clock: clock}
+}
+
+// ObserveFinal records a final transcript that ends end seconds into the
+// stream, received now.
+func (l *LatencyTracker) ObserveFinal(end float64) {
+	captured, ok := l.clock.CapturedAt(end)
+	if !ok {
+		return
+	}
+	l.Record(time.Since(captured))
+}
+
+// Record adds one measurement, ignoring ones that can't be real.
+func (l *LatencyTracker) Record(d time.Duration) {
+	if d < 0 || d > maxTranscriptLatency {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.count++
+	l.sum += d
+	if len(l.recent) < latencySamples {
+		l.recent = append(l.recent, d)
+		return
+	}
+	l.recent[l.next] = d
+	l.next = (l.next + 1) % latencySamples
+}
+
+// Stats returns the measurements so far.
+func (l *LatencyTracker) Stats() LatencyStats {
+	l.mu.Lock()
+	sorted := slices.Clone(l.recent)
+	stats := LatencyStats{Provider: l.provider, Count: l.count, Sum: l.sum.Seconds()}
+	l.mu.Unlock()
+
+	if len(sorted) == 0 {
+		return stats
+	}
+	slices.Sort(sorted)
+	ms := func(q float64) float64 {
+		return float64(sorted[int(q*float64(len(sorted)-1)+0.5)]) / float64(time.Millisecond)
+	}
+	stats.P50, stats.P90, stats.P99, stats.Max = ms(0.5), ms(0.9), ms(0.99), ms(1)
+	return stats
+}
@@ -0,0 +1,76 @@
+package transcribe
+
+import (
+	"io"
+	"testing"
+	"time"
+)
+
+func TestCaptureClockMapsStreamTime(t *testing.T) {
+	clock := NewCaptureClock(io.Discard, 100, 1)
+	start := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	// Two one-second writes of 100 two-byte samples, the second arriving
+	// 700ms after the first.
+	clock.mark(200, start)
+	clock.mark(200, start.Add(700*time.Millisecond))
+
+	for _, tc := range []struct {
+		t    float64
+		want time.Time
+	}{
+		{0, start},
+		{0.25, start.Add(250 * time.Millisecond)},
+		{1.5, start.Add(1200 * time.Millisecond)},
+		{2, start.Add(1700 * time.Millisecond)},
+	} {
+		got, ok := clock.CapturedAt(tc.t)
+		if !ok || !got.Equal(tc.want) {
+			t.Fatalf("CapturedAt(%v) = %v, %v; want %v", tc.t, got, ok, tc.want)
+		}
+	}
+	if _, ok := clock.CapturedAt(2.5); ok {
+		t.Fatal("expected audio not yet written to be unknown")
+	}
+
+	// Marks older than the window are forgotten.
+	clock.mark(200, start.Add(3*captureClockWindow))
+	if _, ok := clock.CapturedAt(0.5); ok {
+		t.Fatal("expected old audio to be forgotten")
+	}
+	if _, ok := clock.CapturedAt(2.5); !ok {
+		t.Fatal("expected recent audio to be remembered")
+	}
+}
+
+func TestLatencyTrackerPercentiles(t *testing.T) {
+	tracker := NewLatencyTracker("deepgram", NewCaptureClock(io.Discard, 16000, 1))
+	for i := 1; i <= 100; i++ {
+		tracker.Record(time.Duration(i) * 10 * time.Millisecond)
+	}
+	tracker.Record(-time.Second)
+	tracker.Record(time.Hour)
+
+	stats := tracker.Stats()
+	if stats.Provider != "deepgram" || stats.Count != 100 {
+		t.Fatalf("expected 100 plausible measurements, got %+v", stats)
+	}
+	if stats.P50 != 510 || stats.P90 != 900 || stats.P99 != 990 || stats.Max != 1000 {
+		t.Fatalf("unexpected percentiles %+v", stats)
+	}
+	if stats.Sum < 50.4 || stats.Sum > 50.6 {
+		t.Fatalf("expected 50.5s in total, got %v", stats.Sum)
+	}
+}
+
+func TestLatencyTrackerObserveFinal(t *testing.T) {
+	clock := NewCaptureClock(io.Discard, 100, 1)
+	clock.mark(200, time.Now().Add(-800*time.Millisecond))
+	tracker := NewLatencyTracker("deepgram", clock)
+
+	tracker.ObserveFinal(0.5)
+	tracker.ObserveFinal(30) // never captured
+	stats := tracker.Stats()
+	if stats.Count != 1 || stats.P50 < 300 || stats.P50 > 400 {
+		t.Fatalf("expected one ~300ms measurement, got %+v", stats)
+	}
+}
@@ -0,0 +1,53 @@
+package transcribe
+
+import (
+	"strings"
+
+	api "github.com/deepgram/deepgram-go-sdk/v3/pkg/api/listen/v1/websocket/interfaces"
+)
+
+// Handler receives a live stream's transcripts and errors. Every provider
+// delivers transcripts shaped like Deepgram's live messages, so sessions
+// are built from them the same way whichever one is active.
+type Handler interface {
+	Message(*api.MessageResponse) error
+	Error(*api.ErrorResponse) error
+}
+
+// transcriptMessage builds a live message for words. A final message also
+// ends the utterance, as providers without Deepgram's finer-grained events
+// only report finished turns.
+func transcriptMessage(words []Word, final bool) *api.MessageResponse {
+	alt := api.Alternative{Words: make([]api.Word, 0, len(words))}
+	texts := make([]string, 0, len(words))
+	for _, w := range words {
+		alt.Words = append(alt.Words, api.Word{
+			Word:           w.PunctuatedWord,
+			PunctuatedWord: w.PunctuatedWord,
+			Start:          w.Start,
+			End:            w.End,
+			Speaker:        w.Speaker,
+			Language:       w.Language,
+			Confidence:     w.Confidence,
+		})
+		texts = append(texts, w.PunctuatedWord)
+	}
+	alt.Transcript = strings.Join(texts, " ")
+
+	mr := &api.MessageResponse{
+		Type:        "Results",
+		Channel:     api.Channel{Alternatives: []api.Alternative{alt}},
+		IsFinal:     final,
+		SpeechFinal: final,
+	}
+	if len(words) > 0 {
+		mr.Start = words[0].Start
+		mr.Duration = words[len(words)-1].End - words[0].Start
+	}
+	return mr
+}
+
+// streamError reports err to h in the shape of a Deepgram error.
+func streamError(h Handler, provider string, err error) {
+	_ = h.Error(&api.ErrorResponse{Type: "Error", ErrCode: provider, Description: err.Error()})
+}
@@ -0,0 +1,92 @@
+package transcribe
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// ErrReconnecting is returned by writes while a dropped live connection is
+// being reconnected.
+var ErrReconnecting = errors.New("live transcription reconnecting")
+
+// Live transcription connection states.
+const (
+	StatusConnected    = "connected"
+	StatusReconnecting = "reconnecting"
+)
+
+// Status is the state of the live transcription connection. While
+// reconnecting, Attempt counts the failed attempts so far, the next coming
+// RetryIn later, and Err is why the last one failed.
+type Status struct {
+	State    string
+	Provider string
+	Attempt  int
+	RetryIn  time.Duration
+	Err      error
+}
+
+// Reconnect attempts back off exponentially from reconnectMin to
+// reconnectMax.
+const (
+	reconnectMin = time.Second
+	reconnectMax = time.Minute
+)
+
+// recover reconnects after the connection dropped with cause, retrying
+// with backoff until a provider connects or ctx is done. The active
+// provider is tried first, with its current settings, then the rest of the
+// chain.
+func (f *Failover) recover(ctx context.Context, cause error) {
+	f.mu.Lock()
+	f.recovering = true
+	active := f.active
+	f.mu.Unlock()
+	defer func() {
+		f.mu.Lock()
+		f.recovering = false
+		f.mu.Unlock()
+		// Writes that failed before recovery began said so already.
+		select {
+		case <-f.dropped:
+		default:
+		}
+	}()
+
+	provider := ""
+	if active >= 0 {
+		provider = f.providers[active].Name
+	}
+	slog.Warn("live transcription dropped, reconnecting", "provider", provider, "error", cause)
+	f.report(Status{State: StatusReconnecting, Provider: provider, Err: cause})
+
+	n := len(f.providers)
+	candidates := make([]int, 0, n)
+	for i := range n {
+		candidates = append(candidates, (max(active, 0)+i)%n)
+	}
+	delay := reconnectMin
+	for attempt := 1; ; attempt++ {
+		err := f.switchTo(candidates)
+		if err == nil {
+			slog.Info("live transcription reconnected", "attempts", attempt)
+			return
+		}
+		slog.Warn("live transcription reconnect failed", "attempt", attempt, "retry_in", delay, "error", err)
+		f.report(Status{State: StatusReconnecting, Provider: provider, Attempt: attempt, RetryIn: delay, Err: err})
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, reconnectMax)
+	}
+}
+
+func (f *Failover) report(s Status) {
+	if f.OnStatus != nil {
+		f.OnStatus(s)
+	}
+}
@@ -11,14 +11,76 @@ type Word struct {
 	PunctuatedWord string
 	Start          float64
 	End            float64
+	// Language is the BCP-47 code Deepgram detected for the word when
+	// transcribing multilingual audio, and "" otherwise.
+	Language string
+	// Confidence is how sure the provider was of the word, from 0 to 1, or
+	// 0 when it didn't say.
+	Confidence float64
 }
 
 type Segment struct {
+	// ID identifies a stored segment, and is set when segments are read
+	// back from the store. Unlike its position, it doesn't change as
+	// segments are inserted before it.
+	ID        int64     `json:"id,omitempty"`
 	Speaker   int       `json:"speaker"`
 	Text      string    `json:"text"`
 	StartTime float64   `json:"start_time"`
 	EndTime   float64   `json:"end_time"`
 	Timestamp time.Time `json:"timestamp"`
+	// Language is the language most of the segment's words were spoken in,
+	// when it was detected.
+	Language string `json:"language,omitempty"`
+	// Confidence is the mean confidence of the segment's words, or 0 when
+	// the provider reported none.
+	Confidence float64 `json:"confidence,omitempty"`
+	// Words are the segment's words with their own timings, for following
+	// along during playback and for captions. Segments imported from
+	// documents, or whose text was rewritten, have none.
+	Words []SegmentWord `json:"words,omitempty"`
+}
+
+// SegmentWord is one word of a segment, timed like the segment.
+type SegmentWord struct {
+	Word       string  `json:"word"`
+	Start      float64 `json:"start"`
+	End        float64 `json:"end"`
+	Confidence float64 `json:"confidence,omitempty"`
+}
+
+// WordsText is the text words spell, as a segment of them reads.
+func WordsText(words []SegmentWord) string {
+	texts := make([]string, len(words))
+	for i, w := range words {
+		texts[i] = w.Word
+	}
+	return strings.Join(texts, " ")
+}
+
+// DropStaleWords forgets the segment's words once its text has been
+// rewritten so that they no longer spell it, rather than keep timings for
+// words that aren't there, or that were redacted.
+func (s *Segment) DropStaleWords() {
+	if len(s.Words) > 0 && WordsText(s.Words) != strings.TrimSpace(s.Text) {
+		s.Words = nil
+	}
+}
+
+// DropLowConfidence removes the words the provider was less than floor
+// sure of. Words without a confidence are kept, and a floor of 0 keeps
+// everything.
+func DropLowConfidence(words []Word, floor float64) []Word {
+	if floor <= 0 {
+		return words
+	}
+	kept := make([]Word, 0, len(words))
+	for _, w := range words {
+		if w.Confidence == 0 || w.Confidence >= floor {
+			kept = append(kept, w)
+		}
+	}
+	return kept
 }
 
 func GroupWordsBySpeaker(words []Word) []Segment {
@@ -28,6 +90,8 @@ func GroupWordsBySpeaker(words []Word) []Segment {
 
 	var segments []Segment
 	var current Segment
+	var languages map[string]int
+	var confidence confidenceMean
 	started := false
 
 	for _, w := range words {
@@ -36,6 +100,7 @@ func GroupWordsBySpeaker(words []Word) []Segment {
 			speaker = *w.Speaker
 		}
 
+		word := SegmentWord{Word: w.PunctuatedWord, Start: w.Start, End: w.End, Confidence: w.Confidence}
 		if !started {
 			current = Segment{
 				Speaker:   speaker,
@@ -43,7 +108,10 @@ func GroupWordsBySpeaker(words []Word) []Segment {
 				StartTime: w.Start,
 				EndTime:   w.End,
 				Timestamp: time.Now(),
+				Words:     []SegmentWord{word},
 			}
+			languages = countLanguage(nil, w.Language)
+			confidence = confidenceMean{}.add(w.Confidence)
 			started = true
 			continue
 		}
@@ -51,22 +119,76 @@ func GroupWordsBySpeaker(words []Word) []Segment {
 		if speaker == current.Speaker {
 			current.Text += " " + w.PunctuatedWord
 			current.EndTime = w.End
+			current.Words = append(current.Words, word)
+			languages = countLanguage(languages, w.Language)
+			confidence = confidence.add(w.Confidence)
 		} else {
+			current.Language = mostCommon(languages)
+			current.Confidence = confidence.mean()
 			segments = append(segments, current)
+			languages = countLanguage(nil, w.Language)
+			confidence = confidenceMean{}.add(w.Confidence)
 			current = Segment{
 				Speaker:   speaker,
 				Text:      w.PunctuatedWord,
 				StartTime: w.Start,
 				EndTime:   w.End,
 				Timestamp: time.Now(),
+				Words:     []SegmentWord{word},
 			}
 		}
 	}
 
+	current.Language = mostCommon(languages)
+	current.Confidence = confidence.mean()
 	segments = append(segments, current)
 	return segments
 }
 
+// confidenceMean averages the confidences words were reported with.
+type confidenceMean struct {
+	sum float64
+	n   int
+}
+
+func (c confidenceMean) add(confidence float64) confidenceMean {
+	if confidence > 0 {
+		c.sum += confidence
+		c.n++
+	}
+	return c
+}
+
+func (c confidenceMean) mean() float64 {
+	if c.n == 0 {
+		return 0
+	}
+	return c.sum / float64(c.n)
+}
+
+func countLanguage(counts map[string]int, language string) map[string]int {
+	if language == "" {
+		return counts
+	}
+	if counts == nil {
+		counts = make(map[string]int)
+	}
+	counts[language]++
+	return counts
+}
+
+// mostCommon returns the language counted most, breaking ties
+// alphabetically so the result doesn't depend on map order.
+func mostCommon(counts map[string]int) string {
+	best := ""
+	for language, n := range counts {
+		if n > counts[best] || (n == counts[best] && language < best) {
+			best = language
+		}
+	}
+	return best
+}
+
 func (s Segment) FormatMarkdown() string {
 	ts := s.Timestamp.Format("15:04:05")
 	return fmt.Sprintf("**[%s] Speaker %d:** %s", ts, s.Speaker, strings.TrimSpace(s.Text))
@@ -34,6 +34,34 @@ func TestGroupWordsBySpeker(t *testing.T) {
 	}
 }
 
+func TestGroupWordsKeepsWordTimings(t *testing.T) {
+	segments := GroupWordsBySpeaker([]Word{
+		{Speaker: intPtr(0), PunctuatedWord: "Hello", Start: 0.1, End: 0.5, Confidence: 0.9},
+		{Speaker: intPtr(0), PunctuatedWord: "world.", Start: 0.6, End: 1.0},
+		{Speaker: intPtr(1), PunctuatedWord: "Hi.", Start: 1.2, End: 1.5},
+	})
+	if len(segments) != 2 || len(segments[0].Words) != 2 || len(segments[1].Words) != 1 {
+		t.Fatalf("expected each segment to keep its words, got %+v", segments)
+	}
+	if w := segments[0].Words[0]; w != (SegmentWord{Word: "Hello", Start: 0.1, End: 0.5, Confidence: 0.9}) {
+		t.Fatalf("unexpected first word %+v", w)
+	}
+	if got := WordsText(segments[0].Words); got != segments[0].Text {
+		t.Fatalf("expected words to spell %q, got %q", segments[0].Text, got)
+	}
+
+	seg := segments[0]
+	seg.DropStaleWords()
+	if len(seg.Words) != 2 {
+		t.Fatal("expected words kept while they spell the text")
+	}
+	seg.Text = "Hello, world."
+	seg.DropStaleWords()
+	if seg.Words != nil {
+		t.Fatalf("expected words dropped once the text was rewritten, got %+v", seg.Words)
+	}
+}
+
 func TestGroupWordsNilSpeaker(t *testing.T) {
 	words := []Word{
 		{Speaker: nil, PunctuatedWord: "Hello", Start: 0.0, End: 0.5},
@@ -47,6 +75,50 @@ func TestGroupWordsNilSpeaker(t *testing.T) {
 	}
 }
 
+func TestGroupWordsTakesMostCommonLanguage(t *testing.T) {
+	words := []Word{
+		{Speaker: intPtr(0), PunctuatedWord: "Bonjour", Language: "fr"},
+		{Speaker: intPtr(0), PunctuatedWord: "tout", Language: "fr"},
+		{Speaker: intPtr(0), PunctuatedWord: "OK", Language: "en"},
+		{Speaker: intPtr(1), PunctuatedWord: "Thanks."},
+	}
+	segments := GroupWordsBySpeaker(words)
+	if len(segments) != 2 {
+		t.Fatalf("expected 2 segments, got %d", len(segments))
+	}
+	if segments[0].Language != "fr" || segments[1].Language != "" {
+		t.Fatalf("expected languages fr and none, got %q and %q", segments[0].Language, segments[1].Language)
+	}
+}
+
+func TestSegmentConfidenceAndFloor(t *testing.T) {
+	words := []Word{
+		{Speaker: intPtr(0), PunctuatedWord: "Ship", Confidence: 0.9},
+		{Speaker: intPtr(0), PunctuatedWord: "uh", Confidence: 0.2},
+		{Speaker: intPtr(0), PunctuatedWord: "it.", Confidence: 0.7},
+		{Speaker: intPtr(1), PunctuatedWord: "Sure."},
+	}
+
+	segments := GroupWordsBySpeaker(words)
+	if len(segments) != 2 {
+		t.Fatalf("expected 2 segments, got %d", len(segments))
+	}
+	if got := segments[0].Confidence; got < 0.599 || got > 0.601 {
+		t.Fatalf("expected mean confidence 0.6, got %v", got)
+	}
+	if segments[1].Confidence != 0 {
+		t.Fatalf("expected no confidence for unscored words, got %v", segments[1].Confidence)
+	}
+
+	kept := GroupWordsBySpeaker(DropLowConfidence(words, 0.5))
+	if kept[0].Text != "Ship it." || kept[1].Text != "Sure." {
+		t.Fatalf("expected low-confidence word dropped, got %q and %q", kept[0].Text, kept[1].Text)
+	}
+	if len(DropLowConfidence(words, 0)) != len(words) {
+		t.Fatal("expected a zero floor to keep every word")
+	}
+}
+
 func TestFormatSegmentMarkdown(t *testing.T) {
 	seg := Segment{
 		Speaker:   0,
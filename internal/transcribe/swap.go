@@ -0,0 +1,139 @@
+package transcribe
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Stream is a live transcription connection.
+type Stream interface {
+	io.Writer
+	Stop()
+}
+
+// ErrSwapInProgress is returned by Swap while an earlier swap is still
+// connecting.
+var ErrSwapInProgress = errors.New("stream swap already in progress")
+
+// finalizeWait is how long a replaced stream that can be finalized is kept
+// open for the transcripts of the audio it already has.
+const finalizeWait = 3 * time.Second
+
+// SwapWriter forwards PCM to a live transcription stream that can be
+// replaced while audio flows, e.g. to pick up a rotated API key. Audio
+// written while the replacement connects is held and sent to it first, so
+// the session stays open and the transcript barely notices.
+type SwapWriter struct {
+	bytesPerSecond float64
+	maxBytes       int
+
+	mu        sync.Mutex
+	live      Stream
+	streamed  int64
+	swapping  bool
+	pending   []byte
+	truncated bool
+}
+
+// NewSwapWriter wraps live, a stream of 16-bit PCM. At most maxDuration of
+// audio is held during a swap.
+func NewSwapWriter(live Stream, sampleRate, channels int, maxDuration time.Duration) *SwapWriter {
+	bytesPerSecond := float64(sampleRate * channels * 2)
+	return &SwapWriter{
+		live:           live,
+		bytesPerSecond: bytesPerSecond,
+		maxBytes:       int(maxDuration.Seconds() * bytesPerSecond),
+	}
+}
+
+func (w *SwapWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.swapping {
+		if len(w.pending)+len(p) > w.maxBytes {
+			if !w.truncated {
+				slog.Warn("stream swap buffer full, dropping audio until connected")
+				w.truncated = true
+			}
+			return len(p), nil
+		}
+		w.pending = append(w.pending, p...)
+		return len(p), nil
+	}
+	n, err := w.live.Write(p)
+	// Audio a dropped stream rejected still moves the timeline on, so the
+	// next connection's timestamps line up.
+	w.streamed += int64(len(p))
+	return n, err
+}
+
+// Skip moves the timeline on by n bytes of audio that were never written,
+// as while a dropped stream is reconnected.
+func (w *SwapWriter) Skip(n int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.streamed += int64(n)
+}
+
+// Current returns the stream audio is sent to.
+func (w *SwapWriter) Current() Stream {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.live
+}
+
+// Swap connects a replacement stream, moves audio over to it and retires
+// the old one. A new connection's timestamps start from zero, so connect is
+// passed the seconds of audio streamed before it, to add to them. If
+// connect fails, the old stream carries on with the held audio.
+func (w *SwapWriter) Swap(connect func(offset float64) (Stream, error)) error {
+	w.mu.Lock()
+	if w.swapping {
+		w.mu.Unlock()
+		return ErrSwapInProgress
+	}
+	w.swapping = true
+	w.truncated = false
+	offset := float64(w.streamed) / w.bytesPerSecond
+	w.mu.Unlock()
+
+	next, err := connect(offset)
+
+	w.mu.Lock()
+	old := w.live
+	if err == nil {
+		w.live = next
+	}
+	pending := w.pending
+	w.pending = nil
+	w.swapping = false
+	if len(pending) > 0 {
+		_, werr := w.live.Write(pending)
+		w.streamed += int64(len(pending))
+		if werr != nil {
+			slog.Warn("sending audio held during stream swap failed", "error", werr)
+		}
+	}
+	w.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+	retire(old)
+	return nil
+}
+
+// retire stops a replaced stream, first letting one that can be finalized
+// return the transcripts of the audio it already has.
+func retire(s Stream) {
+	f, ok := s.(interface{ Finalize() error })
+	if !ok || f.Finalize() != nil {
+		s.Stop()
+		return
+	}
+	time.AfterFunc(finalizeWait, s.Stop)
+}
@@ -0,0 +1,71 @@
+package transcribe
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+type streamStub struct {
+	bytes.Buffer
+	stopped bool
+}
+
+func (s *streamStub) Stop() { s.stopped = true }
+
+func TestSwapWriterHoldsAudioDuringSwap(t *testing.T) {
+	old := &streamStub{}
+	w := NewSwapWriter(old, 16000, 1, time.Minute)
+
+	chunk := make([]byte, 3200) // 100ms at 16kHz
+	for range 10 {
+		if _, err := w.Write(chunk); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+
+	next := &streamStub{}
+	var gotOffset float64
+	err := w.Swap(func(offset float64) (Stream, error) {
+		gotOffset = offset
+		// Audio captured while connecting is held for the new stream.
+		if _, err := w.Write(bytes.Repeat([]byte{1}, 3200)); err != nil {
+			t.Fatalf("Write during swap failed: %v", err)
+		}
+		return next, nil
+	})
+	if err != nil {
+		t.Fatalf("Swap failed: %v", err)
+	}
+	if gotOffset != 1 {
+		t.Fatalf("expected the new stream to start 1s in, got %v", gotOffset)
+	}
+	if old.Len() != 10*3200 || !old.stopped {
+		t.Fatalf("expected the old stream to get only earlier audio and stop, got %d bytes stopped=%v", old.Len(), old.stopped)
+	}
+	if next.Len() != 3200 || next.Bytes()[0] != 1 {
+		t.Fatalf("expected the held audio sent to the new stream, got %d bytes", next.Len())
+	}
+	if w.Current() != next {
+		t.Fatal("expected audio to go to the new stream")
+	}
+}
+
+func TestSwapWriterKeepsOldStreamWhenConnectFails(t *testing.T) {
+	old := &streamStub{}
+	w := NewSwapWriter(old, 16000, 1, time.Minute)
+
+	err := w.Swap(func(float64) (Stream, error) {
+		if _, err := w.Write(make([]byte, 320)); err != nil {
+			t.Fatalf("Write during swap failed: %v", err)
+		}
+		return nil, errors.New("401 unauthorized")
+	})
+	if err == nil {
+		t.Fatal("expected the connect error")
+	}
+	if w.Current() != old || old.stopped || old.Len() != 320 {
+		t.Fatalf("expected the old stream to carry on with the held audio, got %d bytes stopped=%v", old.Len(), old.stopped)
+	}
+}
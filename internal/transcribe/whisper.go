@@ -0,0 +1,82 @@
+package transcribe
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// Whisper transcribes recorded audio with an OpenAI-compatible
+// transcription API: OpenAI's own, or a local Whisper server such as
+// faster-whisper-server or whisper.cpp's.
+type Whisper struct {
+	apiKey   func() string
+	baseURL  string
+	model    string
+	language string
+}
+
+// NewWhisper transcribes with model in language, which may be LanguageAuto,
+// using the key apiKey returns at the time, so a rotated key is picked up
+// by the next chunk. An empty baseURL uses OpenAI's API.
+func NewWhisper(apiKey func() string, baseURL, model, language string) *Whisper {
+	return &Whisper{apiKey: apiKey, baseURL: baseURL, model: model, language: language}
+}
+
+func (w *Whisper) TranscribePCM(ctx context.Context, pcm []byte, sampleRate int) ([]Word, error) {
+	config := openai.DefaultConfig(w.apiKey())
+	if w.baseURL != "" {
+		config.BaseURL = w.baseURL
+	}
+	resp, err := openai.NewClientWithConfig(config).CreateTranscription(ctx, openai.AudioRequest{
+		Model:                  w.model,
+		FilePath:               "audio.wav",
+		Reader:                 bytes.NewReader(wavFile(pcm, sampleRate)),
+		Language:               whisperLanguage(w.language),
+		Format:                 openai.AudioResponseFormatVerboseJSON,
+		TimestampGranularities: []openai.TranscriptionTimestampGranularity{openai.TranscriptionTimestampGranularityWord},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("whisper transcription: %w", err)
+	}
+	words := make([]Word, 0, len(resp.Words))
+	for _, w := range resp.Words {
+		if text := strings.TrimSpace(w.Word); text != "" {
+			words = append(words, Word{PunctuatedWord: text, Start: w.Start, End: w.End})
+		}
+	}
+	return words, nil
+}
+
+// whisperLanguage returns the ISO 639-1 code Whisper takes for a
+// configured language, or "" to have it detect the language.
+func whisperLanguage(language string) string {
+	if strings.EqualFold(language, LanguageAuto) {
+		return ""
+	}
+	base, _, _ := strings.Cut(strings.ToLower(language), "-")
+	return base
+}
+
+// wavFile wraps 16-bit mono PCM in a WAV header.
+func wavFile(pcm []byte, sampleRate int) []byte {
+	var b bytes.Buffer
+	b.Grow(44 + len(pcm))
+	b.WriteString("RIFF")
+	_ = binary.Write(&b, binary.LittleEndian, uint32(36+len(pcm)))
+	b.WriteString("WAVEfmt ")
+	_ = binary.Write(&b, binary.LittleEndian, struct {
+		Size                 uint32
+		Format, Channels     uint16
+		SampleRate, ByteRate uint32
+		BlockAlign, Bits     uint16
+	}{16, 1, 1, uint32(sampleRate), uint32(sampleRate * 2), 2, 16})
+	b.WriteString("data")
+	_ = binary.Write(&b, binary.LittleEndian, uint32(len(pcm)))
+	b.Write(pcm)
+	return b.Bytes()
+}
@@ -0,0 +1,207 @@
+// Package tts renders summaries to speech, through OpenAI's speech API or a
+// local piper install.
+package tts
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// Synthesizer writes spoken audio for a markdown summary to path. Ext is the
+// file extension of what it writes, including the dot.
+type Synthesizer interface {
+	Synthesize(ctx context.Context, text, path string) error
+	Ext() string
+}
+
+// maxSpeechInput is the most characters the OpenAI speech endpoint reads
+// in one request.
+const maxSpeechInput = 4096
+
+// OpenAI synthesizes MP3 with the OpenAI speech endpoint. Text longer than
+// one request takes is read a piece at a time and the MP3s joined.
+type OpenAI struct {
+	apiKey  func() string
+	baseURL string
+	model   string
+	voice   string
+}
+
+// NewOpenAI synthesizes with the key apiKey returns at the time, so a
+// rotated key is picked up by the next summary. An empty baseURL uses
+// OpenAI's.
+func NewOpenAI(apiKey func() string, baseURL, model, voice string) *OpenAI {
+	return &OpenAI{apiKey: apiKey, baseURL: baseURL, model: model, voice: voice}
+}
+
+func (o *OpenAI) Ext() string { return ".mp3" }
+
+func (o *OpenAI) Synthesize(ctx context.Context, text, path string) error {
+	config := openai.DefaultConfig(o.apiKey())
+	if o.baseURL != "" {
+		config.BaseURL = o.baseURL
+	}
+	client := openai.NewClientWithConfig(config)
+	return writeAtomic(path, func(w io.Writer) error {
+		for i, chunk := range splitText(SpeakableText(text), maxSpeechInput) {
+			resp, err := client.CreateSpeech(ctx, openai.CreateSpeechRequest{
+				Model:          openai.SpeechModel(o.model),
+				Voice:          openai.SpeechVoice(o.voice),
+				Input:          chunk,
+				ResponseFormat: openai.SpeechResponseFormatMp3,
+			})
+			if err != nil {
+				return fmt.Errorf("openai speech: %w", err)
+			}
+			audio, err := io.ReadAll(resp)
+			_ = resp.Close()
+			if err != nil {
+				return fmt.Errorf("openai speech: %w", err)
+			}
+			// MP3 frames play back to back; a tag is only wanted up front.
+			if i > 0 {
+				audio = stripID3(audio)
+			}
+			if _, err := w.Write(audio); err != nil {
+				return fmt.Errorf("write %s: %w", path, err)
+			}
+		}
+		return nil
+	})
+}
+
+// stripID3 drops the ID3v2 tag at the start of an MP3, if it has one.
+func stripID3(mp3 []byte) []byte {
+	if len(mp3) < 10 || string(mp3[:3]) != "ID3" {
+		return mp3
+	}
+	// The size is syncsafe: 7 bits a byte, not counting the header or footer.
+	size := 10 + (int(mp3[6]&0x7f)<<21 | int(mp3[7]&0x7f)<<14 | int(mp3[8]&0x7f)<<7 | int(mp3[9]&0x7f))
+	if mp3[5]&0x10 != 0 {
+		size += 10
+	}
+	return mp3[min(size, len(mp3)):]
+}
+
+// splitText breaks text into pieces of at most limit characters, between
+// lines where it can, then sentences, then words.
+func splitText(text string, limit int) []string {
+	return splitAt(text, limit, []string{"\n", ". ", " "})
+}
+
+func splitAt(text string, limit int, seps []string) []string {
+	text = strings.TrimSpace(text)
+	if utf8.RuneCountInString(text) <= limit {
+		if text == "" {
+			return nil
+		}
+		return []string{text}
+	}
+	if len(seps) == 0 {
+		runes := []rune(text)
+		return append([]string{string(runes[:limit])}, splitAt(string(runes[limit:]), limit, nil)...)
+	}
+
+	var chunks []string
+	var cur string
+	for _, part := range strings.SplitAfter(text, seps[0]) {
+		if utf8.RuneCountInString(cur+part) <= limit {
+			cur += part
+			continue
+		}
+		chunks = append(chunks, splitAt(cur, limit, nil)...)
+		cur = ""
+		if utf8.RuneCountInString(part) > limit {
+			chunks = append(chunks, splitAt(part, limit, seps[1:])...)
+		} else {
+			cur = part
+		}
+	}
+	return append(chunks, splitAt(cur, limit, nil)...)
+}
+
+// Piper synthesizes WAV by running the piper CLI with a local voice model.
+type Piper struct {
+	Command string
+	Model   string
+}
+
+func (p Piper) Ext() string { return ".wav" }
+
+func (p Piper) Synthesize(ctx context.Context, text, path string) error {
+	tmp := path + ".tmp"
+	cmd := exec.CommandContext(ctx, p.Command, "--model", p.Model, "--output_file", tmp)
+	cmd.Stdin = strings.NewReader(SpeakableText(text))
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("piper: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("piper: %w", err)
+	}
+	return nil
+}
+
+// writeAtomic writes path with write, leaving no file if it fails.
+func writeAtomic(path string, write func(w io.Writer) error) error {
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", tmp, err)
+	}
+	if err := write(f); err != nil {
+		_ = f.Close()
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("close %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("rename %s: %w", tmp, err)
+	}
+	return nil
+}
+
+var (
+	mdLink     = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	mdHeading  = regexp.MustCompile(`(?m)^\s{0,3}#{1,6}\s*`)
+	mdBullet   = regexp.MustCompile(`(?m)^\s*(?:[-*+]|\d+[.)])\s+(?:\[[ xX]\]\s+)?`)
+	mdEmphasis = regexp.MustCompile("[*_`~]+")
+)
+
+// SpeakableText strips markdown from a summary so a voice doesn't read out
+// the formatting. Headings and list items become their own sentences.
+func SpeakableText(markdown string) string {
+	text := mdLink.ReplaceAllString(markdown, "$1")
+	text = mdHeading.ReplaceAllString(text, "")
+	text = mdBullet.ReplaceAllString(text, "")
+	text = mdEmphasis.ReplaceAllString(text, "")
+
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if !strings.ContainsAny(line[len(line)-1:], ".!?:;") {
+			line += "."
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
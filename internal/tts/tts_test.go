@@ -0,0 +1,116 @@
+package tts
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSpeakableText(t *testing.T) {
+	md := "## Key topics\n\n- **Budget** review for [Q3](https://example.test)\n- [ ] Ship `v2`\n1. Hiring plan\n\nAll done!"
+	want := "Key topics.\nBudget review for Q3.\nShip v2.\nHiring plan.\nAll done!"
+	if got := SpeakableText(md); got != want {
+		t.Fatalf("unexpected speakable text:\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestSplitText(t *testing.T) {
+	line := strings.Repeat("word ", 30) + "end."
+	text := strings.Repeat(line+"\n", 40) + strings.Repeat("x", 250)
+	chunks := splitText(text, 100)
+	var joined []string
+	for _, c := range chunks {
+		if n := utf8.RuneCountInString(c); n > 100 || n == 0 {
+			t.Fatalf("expected chunks of 1 to 100 characters, got %d: %q", n, c)
+		}
+		joined = append(joined, strings.Fields(c)...)
+	}
+	if strings.Join(joined, "") != strings.Join(strings.Fields(text), "") {
+		t.Fatal("expected the chunks to hold all of the text, in order")
+	}
+	if got := splitText("short.", 100); len(got) != 1 || got[0] != "short." {
+		t.Fatalf("expected short text in one piece, got %q", got)
+	}
+}
+
+func TestOpenAISynthesizesLongTextInPieces(t *testing.T) {
+	var inputs []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/audio/speech" || r.Header.Get("Authorization") != "Bearer key" {
+			t.Errorf("unexpected request %s with %q", r.URL.Path, r.Header.Get("Authorization"))
+		}
+		var req struct {
+			Input string `json:"input"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		inputs = append(inputs, req.Input)
+		// Each piece comes back tagged; only the first tag is kept.
+		_, _ = w.Write([]byte{'I', 'D', '3', 4, 0, 0, 0, 0, 0, 2, 't', 'g'})
+		_, _ = w.Write([]byte("frames"))
+	}))
+	defer srv.Close()
+
+	o := NewOpenAI(func() string { return "key" }, srv.URL+"/v1", "tts-1", "alloy")
+	path := filepath.Join(t.TempDir(), "summary.mp3")
+	text := strings.Repeat("The budget review went well and the plan stands.\n", 200)
+	if err := o.Synthesize(context.Background(), text, path); err != nil {
+		t.Fatal(err)
+	}
+	if len(inputs) < 3 {
+		t.Fatalf("expected the text read in pieces, got %d requests", len(inputs))
+	}
+	for _, in := range inputs {
+		if utf8.RuneCountInString(in) > maxSpeechInput {
+			t.Fatalf("expected pieces within the %d character limit, got %d", maxSpeechInput, utf8.RuneCountInString(in))
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "ID3\x04\x00\x00\x00\x00\x00\x02tgframes" + strings.Repeat("frames", len(inputs)-1)
+	if string(data) != want {
+		t.Fatalf("expected the pieces joined with one tag, got %q", data)
+	}
+}
+
+func TestPiperSynthesize(t *testing.T) {
+	dir := t.TempDir()
+	fake := filepath.Join(dir, "piper")
+	script := "#!/bin/sh\n# --model m --output_file path\ncat > \"$4\"\n"
+	if err := os.WriteFile(fake, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	out := filepath.Join(dir, "summary.wav")
+	p := Piper{Command: fake, Model: "en_US-amy-medium.onnx"}
+	if err := p.Synthesize(context.Background(), "hello there", out); err != nil {
+		t.Fatalf("synthesize: %v", err)
+	}
+	got, err := os.ReadFile(out)
+	if err != nil || string(got) != "hello there." {
+		t.Fatalf("expected text piped to piper output, got %q (%v)", got, err)
+	}
+	if p.Ext() != ".wav" {
+		t.Fatalf("unexpected ext %q", p.Ext())
+	}
+}
+
+func TestPiperFailureLeavesNoFile(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "summary.wav")
+	p := Piper{Command: "sh", Model: "missing"}
+	err := p.Synthesize(context.Background(), "hello", out)
+	if err == nil || !strings.Contains(err.Error(), "piper") {
+		t.Fatalf("expected piper error, got %v", err)
+	}
+	if _, err := os.Stat(out); !os.IsNotExist(err) {
+		t.Fatal("expected no output file after failure")
+	}
+}
@@ -0,0 +1,433 @@
+// Package update polls GitHub releases for newer Ghost Wispr builds and can
+// stage a downloaded binary in place of the running one, so the next restart
+// (systemd Restart=always, launchd KeepAlive) picks it up.
+//
+// A binary is only staged once it matches its entry in the release's
+// checksums.txt, in sha256sum format, and that manifest carries a valid
+// Ed25519 signature, checksums.txt.sig, by the release key.
+package update
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	ChannelStable     = "stable"
+	ChannelPrerelease = "prerelease"
+
+	defaultReleasesURL = "https://api.github.com/repos/sjawhar/ghost-wispr/releases"
+
+	manifestAsset  = "checksums.txt"
+	signatureAsset = "checksums.txt.sig"
+	// maxManifestSize caps the manifest and signature downloads.
+	maxManifestSize = 1 << 20
+)
+
+// ReleaseKey is the base64 Ed25519 public key releases are signed with,
+// set when building a release with
+// -ldflags "-X github.com/sjawhar/ghost-wispr/internal/update.ReleaseKey=...".
+var ReleaseKey string
+
+// ParsePublicKey decodes a base64 Ed25519 public key.
+func ParsePublicKey(s string) (ed25519.PublicKey, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("decode release key: %w", err)
+	}
+	if len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("release key is %d bytes, want %d", len(key), ed25519.PublicKeySize)
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// IsRelease reports whether version is a release version, as opposed to a
+// development build such as "dev", which has nothing to compare releases
+// against.
+func IsRelease(version string) bool {
+	_, _, ok := parseVersion(version)
+	return ok
+}
+
+// Release is a published version newer than the running one.
+type Release struct {
+	Version     string `json:"version"`
+	URL         string `json:"url"`
+	PublishedAt string `json:"published_at"`
+	Staged      bool   `json:"staged"`
+
+	assetURL     string
+	manifestURL  string
+	signatureURL string
+}
+
+type githubRelease struct {
+	TagName     string `json:"tag_name"`
+	HTMLURL     string `json:"html_url"`
+	PublishedAt string `json:"published_at"`
+	Draft       bool   `json:"draft"`
+	Prerelease  bool   `json:"prerelease"`
+	Assets      []struct {
+		Name               string `json:"name"`
+		BrowserDownloadURL string `json:"browser_download_url"`
+	} `json:"assets"`
+}
+
+// Checker compares the running version against the newest release on its
+// channel. The zero Executable disables staging, and releases are only
+// staged when signed by PublicKey.
+type Checker struct {
+	Current     string
+	Channel     string
+	Executable  string
+	PublicKey   ed25519.PublicKey
+	ReleasesURL string
+	Client      *http.Client
+
+	mu        sync.RWMutex
+	available *Release
+}
+
+func NewChecker(current, channel, executable string) *Checker {
+	return &Checker{
+		Current:     current,
+		Channel:     channel,
+		Executable:  executable,
+		ReleasesURL: defaultReleasesURL,
+		Client:      &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Available returns the newest known release, or nil when up to date.
+func (c *Checker) Available() *Release {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.available == nil {
+		return nil
+	}
+	r := *c.available
+	return &r
+}
+
+// Check fetches releases and records the newest one on the channel that is
+// newer than Current. It returns nil when there is nothing newer.
+func (c *Checker) Check(ctx context.Context) (*Release, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.ReleasesURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch releases: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch releases: unexpected status %s", resp.Status)
+	}
+
+	var releases []githubRelease
+	if err := json.NewDecoder(resp.Body).Decode(&releases); err != nil {
+		return nil, fmt.Errorf("decode releases: %w", err)
+	}
+
+	var newest *githubRelease
+	for i := range releases {
+		r := &releases[i]
+		if r.Draft || (r.Prerelease && c.Channel != ChannelPrerelease) {
+			continue
+		}
+		if newest == nil || compareVersions(r.TagName, newest.TagName) > 0 {
+			newest = r
+		}
+	}
+	if newest == nil || compareVersions(newest.TagName, c.Current) <= 0 {
+		return nil, nil
+	}
+
+	release := &Release{
+		Version:     newest.TagName,
+		URL:         newest.HTMLURL,
+		PublishedAt: newest.PublishedAt,
+	}
+	want := assetName()
+	for _, a := range newest.Assets {
+		switch a.Name {
+		case want:
+			release.assetURL = a.BrowserDownloadURL
+		case manifestAsset:
+			release.manifestURL = a.BrowserDownloadURL
+		case signatureAsset:
+			release.signatureURL = a.BrowserDownloadURL
+		}
+	}
+
+	c.mu.Lock()
+	if c.available != nil && c.available.Version == release.Version {
+		release.Staged = c.available.Staged
+	}
+	c.available = release
+	c.mu.Unlock()
+
+	r := *release
+	return &r, nil
+}
+
+// Stage downloads the release binary for this platform, checks it against
+// the release's signed checksums and swaps it in for Executable, keeping the
+// previous binary alongside with an .old suffix. The running process is
+// unaffected until it restarts.
+func (c *Checker) Stage(ctx context.Context, release *Release) error {
+	if c.Executable == "" {
+		return errors.New("no executable path to stage over")
+	}
+	if len(c.PublicKey) != ed25519.PublicKeySize {
+		return errors.New("no release key to verify updates with")
+	}
+	if release.assetURL == "" {
+		return fmt.Errorf("release %s has no %s asset", release.Version, assetName())
+	}
+	if release.manifestURL == "" || release.signatureURL == "" {
+		return fmt.Errorf("release %s has no signed %s", release.Version, manifestAsset)
+	}
+	want, err := c.verifiedChecksum(ctx, release)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.get(ctx, release.assetURL)
+	if err != nil {
+		return fmt.Errorf("download %s: %w", release.Version, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	dir := filepath.Dir(c.Executable)
+	tmp, err := os.CreateTemp(dir, ".ghost-wispr-update-*")
+	if err != nil {
+		return fmt.Errorf("create staging file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer func() { _ = os.Remove(tmpPath) }()
+
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tmp, hash), resp.Body); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("download %s: %w", release.Version, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write staging file: %w", err)
+	}
+	if got := hash.Sum(nil); !bytes.Equal(got, want) {
+		return fmt.Errorf("download %s: checksum %x does not match the signed %x", release.Version, got, want)
+	}
+	if err := os.Chmod(tmpPath, 0o755); err != nil {
+		return fmt.Errorf("chmod staging file: %w", err)
+	}
+
+	backup := c.Executable + ".old"
+	_ = os.Remove(backup)
+	if err := os.Rename(c.Executable, backup); err != nil {
+		return fmt.Errorf("back up current binary: %w", err)
+	}
+	if err := os.Rename(tmpPath, c.Executable); err != nil {
+		_ = os.Rename(backup, c.Executable)
+		return fmt.Errorf("install new binary: %w", err)
+	}
+
+	c.mu.Lock()
+	if c.available != nil && c.available.Version == release.Version {
+		c.available.Staged = true
+	}
+	c.mu.Unlock()
+	release.Staged = true
+	return nil
+}
+
+// verifiedChecksum fetches the release's checksum manifest and signature
+// and returns the SHA-256 the manifest lists for this platform's binary,
+// once the signature checks out.
+func (c *Checker) verifiedChecksum(ctx context.Context, release *Release) ([]byte, error) {
+	manifest, err := c.fetch(ctx, release.manifestURL)
+	if err != nil {
+		return nil, fmt.Errorf("download %s: %w", manifestAsset, err)
+	}
+	signature, err := c.fetch(ctx, release.signatureURL)
+	if err != nil {
+		return nil, fmt.Errorf("download %s: %w", signatureAsset, err)
+	}
+	// Signatures are published raw or base64-encoded.
+	if len(signature) != ed25519.SignatureSize {
+		if decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature))); err == nil {
+			signature = decoded
+		}
+	}
+	if !ed25519.Verify(c.PublicKey, manifest, signature) {
+		return nil, fmt.Errorf("release %s: %s signature does not verify", release.Version, manifestAsset)
+	}
+
+	want := assetName()
+	for line := range strings.Lines(string(manifest)) {
+		sum, name, ok := strings.Cut(strings.TrimSpace(line), " ")
+		if !ok || strings.TrimLeft(strings.TrimSpace(name), "*") != want {
+			continue
+		}
+		checksum, err := hex.DecodeString(sum)
+		if err != nil || len(checksum) != sha256.Size {
+			return nil, fmt.Errorf("release %s: malformed checksum for %s", release.Version, want)
+		}
+		return checksum, nil
+	}
+	return nil, fmt.Errorf("release %s: %s lists no checksum for %s", release.Version, manifestAsset, want)
+}
+
+// get requests url, failing on anything but 200 OK.
+func (c *Checker) get(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return resp, nil
+}
+
+// fetch reads a small file at url.
+func (c *Checker) fetch(ctx context.Context, url string) ([]byte, error) {
+	resp, err := c.get(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxManifestSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxManifestSize {
+		return nil, errors.New("file too large")
+	}
+	return data, nil
+}
+
+// Run checks immediately and then every interval until ctx is cancelled.
+// onAvailable fires once per newly discovered (or newly staged) release.
+func (c *Checker) Run(ctx context.Context, interval time.Duration, stage bool, onAvailable func(Release)) {
+	var announced Release
+	check := func() {
+		release, err := c.Check(ctx)
+		if err != nil {
+			slog.Warn("update check failed", "error", err)
+			return
+		}
+		if release == nil {
+			return
+		}
+		if stage && !release.Staged {
+			if err := c.Stage(ctx, release); err != nil {
+				slog.Warn("update staging failed", "version", release.Version, "error", err)
+			}
+		}
+		if release.Version == announced.Version && release.Staged == announced.Staged {
+			return
+		}
+		announced = *release
+		if onAvailable != nil {
+			onAvailable(*release)
+		}
+	}
+
+	check()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			check()
+		}
+	}
+}
+
+func assetName() string {
+	name := fmt.Sprintf("ghost-wispr_%s_%s", runtime.GOOS, runtime.GOARCH)
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// compareVersions orders "v1.2.3" style tags numerically. A tag with a
+// pre-release suffix sorts before the same version without one; unparseable
+// versions (such as "dev") sort before everything.
+func compareVersions(a, b string) int {
+	pa, preA, okA := parseVersion(a)
+	pb, preB, okB := parseVersion(b)
+	switch {
+	case !okA && !okB:
+		return 0
+	case !okA:
+		return -1
+	case !okB:
+		return 1
+	}
+	for i := range pa {
+		if pa[i] != pb[i] {
+			if pa[i] < pb[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case preA == preB:
+		return 0
+	case preA == "":
+		return 1
+	case preB == "":
+		return -1
+	}
+	return strings.Compare(preA, preB)
+}
+
+func parseVersion(v string) ([3]int, string, bool) {
+	var parts [3]int
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	core, pre, _ := strings.Cut(v, "-")
+	core, _, _ = strings.Cut(core, "+")
+	fields := strings.Split(core, ".")
+	if len(fields) == 0 || len(fields) > 3 {
+		return parts, "", false
+	}
+	for i, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil || n < 0 {
+			return parts, "", false
+		}
+		parts[i] = n
+	}
+	return parts, pre, true
+}
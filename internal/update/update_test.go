@@ -0,0 +1,192 @@
+package update
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCompareVersions(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"v1.2.0", "v1.10.0", -1},
+		{"v1.2.0", "1.2.0", 0},
+		{"v2.0.0", "v1.9.9", 1},
+		{"v1.2.0-rc.1", "v1.2.0", -1},
+		{"v1.2", "v1.2.0", 0},
+		{"dev", "v0.0.1", -1},
+	}
+	for _, tc := range cases {
+		if got := compareVersions(tc.a, tc.b); got != tc.want {
+			t.Fatalf("compareVersions(%q, %q) = %d, want %d", tc.a, tc.b, got, tc.want)
+		}
+	}
+	if IsRelease("dev") || !IsRelease("v1.2.0") {
+		t.Fatal("expected only versions to count as releases")
+	}
+}
+
+// release is what releasesServer publishes for v1.2.0.
+type release struct {
+	binary    []byte
+	manifest  []byte
+	signature []byte
+}
+
+// signedRelease publishes binary with its checksum signed by key.
+func signedRelease(key ed25519.PrivateKey, binary []byte) release {
+	manifest := fmt.Appendf(nil, "%x  other-asset\n%x  %s\n", sha256.Sum256(nil), sha256.Sum256(binary), assetName())
+	return release{binary: binary, manifest: manifest, signature: ed25519.Sign(key, manifest)}
+}
+
+func releasesServer(t *testing.T, rel release) *httptest.Server {
+	t.Helper()
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/asset":
+			_, _ = w.Write(rel.binary)
+			return
+		case "/manifest":
+			_, _ = w.Write(rel.manifest)
+			return
+		case "/signature":
+			_, _ = w.Write(rel.signature)
+			return
+		}
+		_ = json.NewEncoder(w).Encode([]map[string]any{
+			{"tag_name": "v1.3.0-beta.1", "prerelease": true, "html_url": "https://example.test/beta"},
+			{"tag_name": "v1.2.0", "html_url": "https://example.test/1.2.0", "assets": []map[string]string{
+				{"name": assetName(), "browser_download_url": srv.URL + "/asset"},
+				{"name": manifestAsset, "browser_download_url": srv.URL + "/manifest"},
+				{"name": signatureAsset, "browser_download_url": srv.URL + "/signature"},
+			}},
+			{"tag_name": "v9.0.0", "draft": true},
+		})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestCheckRespectsChannel(t *testing.T) {
+	srv := releasesServer(t, release{})
+
+	stable := NewChecker("v1.1.0", ChannelStable, "")
+	stable.ReleasesURL = srv.URL
+	release, err := stable.Check(context.Background())
+	if err != nil {
+		t.Fatalf("check: %v", err)
+	}
+	if release == nil || release.Version != "v1.2.0" {
+		t.Fatalf("expected stable v1.2.0, got %+v", release)
+	}
+	if got := stable.Available(); got == nil || got.Version != "v1.2.0" {
+		t.Fatalf("expected available v1.2.0, got %+v", got)
+	}
+
+	pre := NewChecker("v1.1.0", ChannelPrerelease, "")
+	pre.ReleasesURL = srv.URL
+	release, err = pre.Check(context.Background())
+	if err != nil {
+		t.Fatalf("check: %v", err)
+	}
+	if release == nil || release.Version != "v1.3.0-beta.1" {
+		t.Fatalf("expected prerelease, got %+v", release)
+	}
+}
+
+func TestCheckUpToDate(t *testing.T) {
+	srv := releasesServer(t, release{})
+
+	c := NewChecker("v1.2.0", ChannelStable, "")
+	c.ReleasesURL = srv.URL
+	release, err := c.Check(context.Background())
+	if err != nil {
+		t.Fatalf("check: %v", err)
+	}
+	if release != nil || c.Available() != nil {
+		t.Fatalf("expected no update, got %+v", release)
+	}
+}
+
+func TestStageSwapsBinary(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	srv := releasesServer(t, signedRelease(priv, []byte("new binary")))
+	exe := filepath.Join(t.TempDir(), "ghost-wispr")
+	if err := os.WriteFile(exe, []byte("old binary"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewChecker("v1.1.0", ChannelStable, exe)
+	c.ReleasesURL = srv.URL
+	c.PublicKey = pub
+	release, err := c.Check(context.Background())
+	if err != nil {
+		t.Fatalf("check: %v", err)
+	}
+	if err := c.Stage(context.Background(), release); err != nil {
+		t.Fatalf("stage: %v", err)
+	}
+
+	if got, _ := os.ReadFile(exe); string(got) != "new binary" {
+		t.Fatalf("expected new binary in place, got %q", got)
+	}
+	if got, _ := os.ReadFile(exe + ".old"); string(got) != "old binary" {
+		t.Fatalf("expected backup of old binary, got %q", got)
+	}
+	if !c.Available().Staged {
+		t.Fatal("expected release to be marked staged")
+	}
+}
+
+func TestStageRefusesUnverifiedBinaries(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	_, other, _ := ed25519.GenerateKey(nil)
+
+	tampered := signedRelease(priv, []byte("new binary"))
+	tampered.binary = []byte("evil binary")
+	cases := map[string]struct {
+		rel release
+		key ed25519.PublicKey
+	}{
+		"no key":          {signedRelease(priv, []byte("new binary")), nil},
+		"other signer":    {signedRelease(other, []byte("new binary")), pub},
+		"tampered binary": {tampered, pub},
+		"unsigned":        {release{binary: []byte("new binary"), manifest: signedRelease(priv, []byte("new binary")).manifest}, pub},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := releasesServer(t, tc.rel)
+			exe := filepath.Join(t.TempDir(), "ghost-wispr")
+			if err := os.WriteFile(exe, []byte("old binary"), 0o755); err != nil {
+				t.Fatal(err)
+			}
+
+			c := NewChecker("v1.1.0", ChannelStable, exe)
+			c.ReleasesURL = srv.URL
+			c.PublicKey = tc.key
+			release, err := c.Check(context.Background())
+			if err != nil {
+				t.Fatalf("check: %v", err)
+			}
+			if err := c.Stage(context.Background(), release); err == nil {
+				t.Fatal("expected staging refused")
+			}
+			if got, _ := os.ReadFile(exe); string(got) != "old binary" {
+				t.Fatalf("expected old binary left in place, got %q", got)
+			}
+			if c.Available().Staged {
+				t.Fatal("expected release not marked staged")
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package voiceprint
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// Speaker models are trained on Kaldi-style filterbank features: 25ms
+// frames every 10ms of 16kHz audio, each reduced to the log energy in 80
+// mel-spaced bands. Matching Kaldi's recipe closely is what lets a model
+// exported from WeSpeaker or 3D-Speaker be used as it is.
+const (
+	// SampleRate is the rate audio is embedded at.
+	SampleRate = 16000
+
+	fbankBins   = 80
+	frameLength = SampleRate * 25 / 1000
+	frameShift  = SampleRate * 10 / 1000
+	fftSize     = 512
+	preemphasis = 0.97
+	lowFreq     = 20.0
+	// energyFloor keeps silent bands off log(0); Kaldi floors at float32
+	// epsilon.
+	energyFloor = 1.1920929e-07
+)
+
+// melBank is one band's triangular weights over the FFT bins, as the first
+// bin it covers and its weights from there.
+type melBank struct {
+	first   int
+	weights []float64
+}
+
+var melBanks = newMelBanks()
+
+func mel(hz float64) float64 { return 1127 * math.Log(1+hz/700) }
+
+func newMelBanks() []melBank {
+	low, high := mel(lowFreq), mel(SampleRate/2)
+	delta := (high - low) / (fbankBins + 1)
+	binWidth := float64(SampleRate) / fftSize
+	banks := make([]melBank, fbankBins)
+	for b := range banks {
+		left := low + float64(b)*delta
+		center, right := left+delta, left+2*delta
+		bank := melBank{first: -1}
+		for i := range fftSize / 2 {
+			m := mel(binWidth * float64(i))
+			if m <= left || m >= right {
+				continue
+			}
+			if bank.first < 0 {
+				bank.first = i
+			}
+			w := (right - m) / (right - center)
+			if m <= center {
+				w = (m - left) / (center - left)
+			}
+			bank.weights = append(bank.weights, w)
+		}
+		banks[b] = bank
+	}
+	return banks
+}
+
+// povey is Kaldi's default analysis window, a Hann window raised to 0.85.
+var povey = func() []float64 {
+	w := make([]float64, frameLength)
+	for i := range w {
+		w[i] = math.Pow(0.5-0.5*math.Cos(2*math.Pi*float64(i)/float64(frameLength-1)), 0.85)
+	}
+	return w
+}()
+
+// Fbank computes the features of samples at SampleRate, on the scale of
+// 16-bit PCM, as frames of fbankBins values with each band's mean over the
+// whole span subtracted. It returns the features flattened and the number
+// of frames, which is 0 for less than one frame of audio.
+func Fbank(samples []float32) ([]float32, int) {
+	if len(samples) < frameLength {
+		return nil, 0
+	}
+	frames := 1 + (len(samples)-frameLength)/frameShift
+	feats := make([]float32, frames*fbankBins)
+	mean := make([]float64, fbankBins)
+	frame := make([]float64, frameLength)
+	spectrum := make([]complex128, fftSize)
+	for f := range frames {
+		var dc float64
+		for i := range frame {
+			frame[i] = float64(samples[f*frameShift+i])
+			dc += frame[i]
+		}
+		dc /= frameLength
+		for i := range frame {
+			frame[i] -= dc
+		}
+		for i := frameLength - 1; i > 0; i-- {
+			frame[i] -= preemphasis * frame[i-1]
+		}
+		frame[0] -= preemphasis * frame[0]
+
+		for i := range spectrum {
+			spectrum[i] = 0
+		}
+		for i, v := range frame {
+			spectrum[i] = complex(v*povey[i], 0)
+		}
+		fft(spectrum)
+
+		for b, bank := range melBanks {
+			var energy float64
+			for i, w := range bank.weights {
+				p := cmplx.Abs(spectrum[bank.first+i])
+				energy += w * p * p
+			}
+			v := math.Log(max(energy, energyFloor))
+			feats[f*fbankBins+b] = float32(v)
+			mean[b] += v
+		}
+	}
+	for b := range mean {
+		mean[b] /= float64(frames)
+	}
+	for i := range feats {
+		feats[i] -= float32(mean[i%fbankBins])
+	}
+	return feats, frames
+}
+
+// fft transforms x in place; len(x) must be a power of two.
+func fft(x []complex128) {
+	n := len(x)
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j |= bit
+		if i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+	for size := 2; size <= n; size <<= 1 {
+		step := cmplx.Exp(complex(0, -2*math.Pi/float64(size)))
+		for start := 0; start < n; start += size {
+			w := complex(1, 0)
+			for k := range size / 2 {
+				a, b := x[start+k], w*x[start+k+size/2]
+				x[start+k], x[start+k+size/2] = a+b, a-b
+				w *= step
+			}
+		}
+	}
+}
@@ -0,0 +1,252 @@
+package voiceprint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+	"github.com/sjawhar/ghost-wispr/internal/transcribe"
+)
+
+const (
+	// sampleSeconds is how much of a speaker's speech, longest segments
+	// first, is embedded to recognize or enroll them.
+	sampleSeconds = 30.0
+	// minSpanSeconds skips segments too short to say much about a voice.
+	minSpanSeconds = 1.0
+)
+
+// ErrNoName is returned when enrolling a voice with no name to give it.
+var ErrNoName = errors.New("voice needs a name")
+
+// ErrNoSpeech is returned when enrolling a speaker with too little speech
+// in the session to learn their voice from.
+var ErrNoSpeech = errors.New("speaker has too little speech to learn their voice")
+
+// Store is the storage identification reads and names speakers in.
+type Store interface {
+	GetSession(id string) (storage.Session, error)
+	GetSegments(sessionID string) ([]transcribe.Segment, error)
+	GetSpeakerNames(sessionID string) (map[int]string, error)
+	SetSpeakerName(sessionID string, speaker int, name string) error
+	AddVoiceProfile(p storage.VoiceProfile) (storage.VoiceProfile, error)
+	ListVoiceProfiles() ([]storage.VoiceProfile, error)
+}
+
+// Identifier enrolls voices and recognizes them in sessions. A speaker is
+// recognized as the enrolled voice most similar to theirs, if at least
+// threshold similar.
+type Identifier struct {
+	store     Store
+	embedder  Embedder
+	threshold float64
+}
+
+func NewIdentifier(store Store, embedder Embedder, threshold float64) *Identifier {
+	return &Identifier{store: store, embedder: embedder, threshold: threshold}
+}
+
+// IdentifySession names the session's unnamed speakers whose voices are
+// recognized, each enrolled name going to one speaker at most, and returns
+// the names it gave. Speakers named by hand are left alone.
+func (i *Identifier) IdentifySession(ctx context.Context, sessionID, audioPath string) (map[int]string, error) {
+	profiles, err := i.store.ListVoiceProfiles()
+	if err != nil {
+		return nil, err
+	}
+	if len(profiles) == 0 {
+		return nil, nil
+	}
+	sess, err := i.store.GetSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	segments, err := i.store.GetSegments(sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("get segments: %w", err)
+	}
+	named, err := i.store.GetSpeakerNames(sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("get speaker names: %w", err)
+	}
+
+	var speakers []int
+	var spans []Span
+	var counts []int
+	for _, speaker := range sessionSpeakers(segments) {
+		if named[speaker] != "" {
+			continue
+		}
+		sample := speakerSample(segments, speaker, sess.AudioOffset)
+		if len(sample) == 0 {
+			continue
+		}
+		speakers = append(speakers, speaker)
+		spans = append(spans, sample...)
+		counts = append(counts, len(sample))
+	}
+	if len(spans) == 0 {
+		return nil, nil
+	}
+	embeddings, err := i.embedder.Embed(ctx, audioPath, spans)
+	if err != nil {
+		return nil, err
+	}
+
+	type candidate struct {
+		speaker    int
+		name       string
+		similarity float64
+	}
+	var candidates []candidate
+	for n, speaker := range speakers {
+		voice := Average(embeddings[:counts[n]])
+		embeddings = embeddings[counts[n]:]
+		for _, p := range profiles {
+			if sim := Similarity(voice, p.Embedding); sim >= i.threshold {
+				candidates = append(candidates, candidate{speaker, p.Name, sim})
+			}
+		}
+	}
+	sort.SliceStable(candidates, func(a, b int) bool { return candidates[a].similarity > candidates[b].similarity })
+
+	taken := make(map[string]bool)
+	for _, name := range named {
+		taken[name] = true
+	}
+	given := make(map[int]string)
+	for _, c := range candidates {
+		if taken[c.name] || given[c.speaker] != "" {
+			continue
+		}
+		if err := i.store.SetSpeakerName(sessionID, c.speaker, c.name); err != nil {
+			return given, fmt.Errorf("name speaker %d: %w", c.speaker, err)
+		}
+		slog.Info("recognized voice", "session", sessionID, "speaker", c.speaker, "name", c.name, "similarity", c.similarity)
+		taken[c.name] = true
+		given[c.speaker] = c.name
+	}
+	return given, nil
+}
+
+// Enroll learns a voice from a recording of it, read from r, and stores it
+// under name. filename is the recording's name, whose extension tells the
+// embedder its format. The recording itself is not kept.
+func (i *Identifier) Enroll(ctx context.Context, name, filename string, r io.Reader) (storage.VoiceProfile, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return storage.VoiceProfile{}, ErrNoName
+	}
+	f, err := os.CreateTemp("", "voice-*"+strings.ToLower(filepath.Ext(filename)))
+	if err != nil {
+		return storage.VoiceProfile{}, fmt.Errorf("create voice sample: %w", err)
+	}
+	defer func() { _ = os.Remove(f.Name()) }()
+	_, err = io.Copy(f, r)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return storage.VoiceProfile{}, fmt.Errorf("save voice sample: %w", err)
+	}
+
+	embeddings, err := i.embedder.Embed(ctx, f.Name(), nil)
+	if err != nil {
+		return storage.VoiceProfile{}, err
+	}
+	return i.store.AddVoiceProfile(storage.VoiceProfile{
+		Name:      name,
+		Source:    "recording",
+		Embedding: embeddings[0],
+		CreatedAt: time.Now(),
+	})
+}
+
+// EnrollSpeaker learns the voice of a speaker in a session, under name or,
+// when it is empty, the name the speaker was given there.
+func (i *Identifier) EnrollSpeaker(ctx context.Context, sessionID string, speaker int, name string) (storage.VoiceProfile, error) {
+	sess, err := i.store.GetSession(sessionID)
+	if err != nil {
+		return storage.VoiceProfile{}, err
+	}
+	if name = strings.TrimSpace(name); name == "" {
+		names, err := i.store.GetSpeakerNames(sessionID)
+		if err != nil {
+			return storage.VoiceProfile{}, fmt.Errorf("get speaker names: %w", err)
+		}
+		if name = names[speaker]; name == "" {
+			return storage.VoiceProfile{}, ErrNoName
+		}
+	}
+	if sess.AudioPath == "" || sess.AudioStatus == storage.AudioEncoding || sess.AudioStatus == storage.AudioFailed {
+		return storage.VoiceProfile{}, fmt.Errorf("session %s has no audio to learn from", sessionID)
+	}
+	segments, err := i.store.GetSegments(sessionID)
+	if err != nil {
+		return storage.VoiceProfile{}, fmt.Errorf("get segments: %w", err)
+	}
+	sample := speakerSample(segments, speaker, sess.AudioOffset)
+	if len(sample) == 0 {
+		return storage.VoiceProfile{}, ErrNoSpeech
+	}
+
+	embeddings, err := i.embedder.Embed(ctx, sess.AudioPath, sample)
+	if err != nil {
+		return storage.VoiceProfile{}, err
+	}
+	return i.store.AddVoiceProfile(storage.VoiceProfile{
+		Name:      name,
+		Source:    fmt.Sprintf("session %s, speaker %d", sessionID, speaker),
+		SessionID: sessionID,
+		Embedding: Average(embeddings),
+		CreatedAt: time.Now(),
+	})
+}
+
+// sessionSpeakers lists the speakers who said something, in order of
+// speaker number.
+func sessionSpeakers(segments []transcribe.Segment) []int {
+	seen := make(map[int]bool)
+	var speakers []int
+	for _, seg := range segments {
+		if !seen[seg.Speaker] && strings.TrimSpace(seg.Text) != "" {
+			seen[seg.Speaker] = true
+			speakers = append(speakers, seg.Speaker)
+		}
+	}
+	sort.Ints(speakers)
+	return speakers
+}
+
+// speakerSample picks the speaker's longest segments, up to sampleSeconds
+// of them, in the order they were said. Segment times are on the capture
+// timeline, and the session's audio starts offset seconds into it, so the
+// spans are moved back by offset to fall on the recording.
+func speakerSample(segments []transcribe.Segment, speaker int, offset float64) []Span {
+	var spans []Span
+	for _, seg := range segments {
+		start, end := max(seg.StartTime-offset, 0), seg.EndTime-offset
+		if seg.Speaker == speaker && strings.TrimSpace(seg.Text) != "" && end-start >= minSpanSeconds {
+			spans = append(spans, Span{Start: start, End: end})
+		}
+	}
+	sort.SliceStable(spans, func(a, b int) bool { return spans[a].End-spans[a].Start > spans[b].End-spans[b].Start })
+	total := 0.0
+	for n, s := range spans {
+		if total >= sampleSeconds {
+			spans = spans[:n]
+			break
+		}
+		total += s.End - s.Start
+	}
+	sort.Slice(spans, func(a, b int) bool { return spans[a].Start < spans[b].Start })
+	return spans
+}
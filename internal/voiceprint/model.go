@@ -0,0 +1,145 @@
+package voiceprint
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	ort "github.com/yalue/onnxruntime_go"
+
+	"github.com/sjawhar/ghost-wispr/internal/audio"
+)
+
+// ortMu guards starting ONNX Runtime, which is done once per process.
+var ortMu sync.Mutex
+
+// Model embeds voices in process with a speaker-verification model in ONNX
+// format, such as the WeSpeaker ResNet34 or 3D-Speaker CAM++ models
+// exported for sherpa-onnx. The model takes Fbank features as
+// [1, frames, 80] and returns one embedding as [1, dims].
+type Model struct {
+	timeout time.Duration
+	decode  func(path string, sampleRate int) ([]byte, error)
+
+	mu      sync.Mutex
+	session *ort.DynamicAdvancedSession
+	// infer embeds one span's features; the session's Run but for tests.
+	infer func(feats []float32, frames int) ([]float64, error)
+}
+
+// LoadModel loads the model at path with ONNX Runtime, from the shared
+// library at runtimePath or, when it is empty, the one the system finds.
+// Embedding a session gives up after timeout.
+func LoadModel(path, runtimePath string, timeout time.Duration) (*Model, error) {
+	ortMu.Lock()
+	if !ort.IsInitialized() {
+		if runtimePath != "" {
+			ort.SetSharedLibraryPath(runtimePath)
+		}
+		if err := ort.InitializeEnvironment(); err != nil {
+			ortMu.Unlock()
+			return nil, fmt.Errorf("start onnx runtime: %w", err)
+		}
+	}
+	ortMu.Unlock()
+
+	inputs, outputs, err := ort.GetInputOutputInfo(path)
+	if err != nil {
+		return nil, fmt.Errorf("read voice model: %w", err)
+	}
+	if len(inputs) != 1 || len(outputs) < 1 {
+		return nil, fmt.Errorf("voice model %s takes %d inputs and gives %d outputs, want Fbank features in and an embedding out", path, len(inputs), len(outputs))
+	}
+	session, err := ort.NewDynamicAdvancedSession(path, []string{inputs[0].Name}, []string{outputs[0].Name}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("load voice model: %w", err)
+	}
+	m := &Model{timeout: timeout, decode: audio.DecodeFile, session: session}
+	m.infer = m.run
+	return m, nil
+}
+
+// Close releases the model.
+func (m *Model) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.session == nil {
+		return nil
+	}
+	err := m.session.Destroy()
+	m.session = nil
+	return err
+}
+
+func (m *Model) Embed(ctx context.Context, audioPath string, spans []Span) ([][]float64, error) {
+	if m.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.timeout)
+		defer cancel()
+	}
+	pcm, err := m.decode(audioPath, SampleRate)
+	if err != nil {
+		return nil, fmt.Errorf("decode voice audio: %w", err)
+	}
+	samples := make([]float32, len(pcm)/2)
+	for i := range samples {
+		samples[i] = float32(int16(binary.LittleEndian.Uint16(pcm[2*i:])))
+	}
+	length := float64(len(samples)) / SampleRate
+	if len(spans) == 0 {
+		spans = []Span{{Start: 0, End: length}}
+	}
+
+	embeddings := make([][]float64, 0, len(spans))
+	for _, s := range spans {
+		if err := ctx.Err(); err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				return nil, fmt.Errorf("voice embedding timed out after %s", m.timeout)
+			}
+			return nil, err
+		}
+		start := min(max(int(s.Start*SampleRate), 0), len(samples))
+		end := min(max(int(s.End*SampleRate), start), len(samples))
+		feats, frames := Fbank(samples[start:end])
+		if frames == 0 {
+			return nil, fmt.Errorf("span %.1f-%.1fs is outside the %.1fs recording", s.Start, s.End, length)
+		}
+		embedding, err := m.infer(feats, frames)
+		if err != nil {
+			return nil, err
+		}
+		embeddings = append(embeddings, embedding)
+	}
+	return embeddings, nil
+}
+
+func (m *Model) run(feats []float32, frames int) ([]float64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.session == nil {
+		return nil, errors.New("voice model is closed")
+	}
+	input, err := ort.NewTensor(ort.NewShape(1, int64(frames), fbankBins), feats)
+	if err != nil {
+		return nil, fmt.Errorf("voice model input: %w", err)
+	}
+	defer func() { _ = input.Destroy() }()
+	outputs := []ort.Value{nil}
+	if err := m.session.Run([]ort.Value{input}, outputs); err != nil {
+		return nil, fmt.Errorf("run voice model: %w", err)
+	}
+	defer func() { _ = outputs[0].Destroy() }()
+	out, ok := outputs[0].(*ort.Tensor[float32])
+	if !ok {
+		return nil, errors.New("voice model does not return float32 embeddings")
+	}
+	data := out.GetData()
+	embedding := make([]float64, len(data))
+	for i, v := range data {
+		embedding[i] = float64(v)
+	}
+	return embedding, nil
+}
@@ -0,0 +1,77 @@
+// Package voiceprint recognizes enrolled voices in session audio with a
+// local speaker-embedding model, so that speakers are named after the
+// people they are rather than numbered.
+package voiceprint
+
+import (
+	"context"
+	"math"
+)
+
+// Span is a stretch of audio, in seconds from its start.
+type Span struct {
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+}
+
+// Embedder computes speaker embeddings of spans of an audio file, one per
+// span; with no spans, one for the whole file.
+type Embedder interface {
+	Embed(ctx context.Context, audioPath string, spans []Span) ([][]float64, error)
+}
+
+// Similarity is the cosine similarity of two embeddings: 1 for the same
+// direction, 0 for unrelated ones. Embeddings of different lengths, from
+// different models, are unrelated.
+func Similarity(a, b []float64) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += a[i] * b[i]
+		na += a[i] * a[i]
+		nb += b[i] * b[i]
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(na*nb)
+}
+
+// Average is the normalized mean of embeddings of the same length, which
+// stands for a voice heard over several spans.
+func Average(embeddings [][]float64) []float64 {
+	if len(embeddings) == 0 {
+		return nil
+	}
+	sum := make([]float64, len(embeddings[0]))
+	for _, e := range embeddings {
+		if len(e) != len(sum) {
+			continue
+		}
+		var norm float64
+		for _, v := range e {
+			norm += v * v
+		}
+		if norm == 0 {
+			continue
+		}
+		norm = math.Sqrt(norm)
+		for i, v := range e {
+			sum[i] += v / norm
+		}
+	}
+	var norm float64
+	for _, v := range sum {
+		norm += v * v
+	}
+	if norm == 0 {
+		return nil
+	}
+	norm = math.Sqrt(norm)
+	for i := range sum {
+		sum[i] /= norm
+	}
+	return sum
+}
@@ -0,0 +1,223 @@
+package voiceprint
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+	"github.com/sjawhar/ghost-wispr/internal/transcribe"
+)
+
+func TestModelEmbed(t *testing.T) {
+	// Two seconds of silence; each embedding is its span's frame count.
+	pcm := make([]byte, 2*2*SampleRate)
+	m := &Model{
+		decode: func(path string, rate int) ([]byte, error) {
+			if path != "s1.flac" || rate != SampleRate {
+				t.Fatalf("unexpected decode of %s at %d", path, rate)
+			}
+			return pcm, nil
+		},
+		infer: func(feats []float32, frames int) ([]float64, error) {
+			if len(feats) != frames*fbankBins {
+				t.Fatalf("expected %d features, got %d", frames*fbankBins, len(feats))
+			}
+			return []float64{float64(frames)}, nil
+		},
+	}
+
+	embeddings, err := m.Embed(context.Background(), "s1.flac", []Span{{0, 0.5}, {1.5, 5}})
+	if err != nil {
+		t.Fatalf("embed: %v", err)
+	}
+	// Half a second is 48 frames; the second span is cut off at the end of
+	// the recording.
+	if len(embeddings) != 2 || embeddings[0][0] != 48 || embeddings[1][0] != 48 {
+		t.Fatalf("expected an embedding per span, got %v", embeddings)
+	}
+	whole, err := m.Embed(context.Background(), "s1.flac", nil)
+	if err != nil || len(whole) != 1 || whole[0][0] != 198 {
+		t.Fatalf("expected one embedding of the whole file, got %v (%v)", whole, err)
+	}
+	if _, err := m.Embed(context.Background(), "s1.flac", []Span{{3, 4}}); err == nil || !strings.Contains(err.Error(), "outside") {
+		t.Fatalf("expected a span past the recording refused, got %v", err)
+	}
+}
+
+func TestFbank(t *testing.T) {
+	// A second of a 1kHz tone that gets louder halfway through.
+	samples := make([]float32, SampleRate)
+	for i := range samples {
+		amp := 1000.0
+		if i >= SampleRate/2 {
+			amp = 8000
+		}
+		samples[i] = float32(amp * math.Sin(2*math.Pi*1000*float64(i)/SampleRate))
+	}
+	feats, frames := Fbank(samples)
+	if frames != 98 || len(feats) != frames*fbankBins {
+		t.Fatalf("expected 98 frames of %d bands, got %d frames and %d values", fbankBins, frames, len(feats))
+	}
+	if _, frames := Fbank(samples[:frameLength-1]); frames != 0 {
+		t.Fatalf("expected no frames from less than one frame of audio, got %d", frames)
+	}
+
+	// The tone's band is the loudest, and the loud half is above the mean
+	// and the quiet half below it.
+	first := feats[:fbankBins]
+	peak := 0
+	for b := range first {
+		if first[b] > first[peak] {
+			peak = b
+		}
+	}
+	low, high := mel(lowFreq), mel(SampleRate/2)
+	center := low + (high-low)/(fbankBins+1)*float64(peak+1)
+	if math.Abs(center-mel(1000)) > (high-low)/(fbankBins+1) {
+		t.Fatalf("expected the 1kHz band loudest, got band %d", peak)
+	}
+	last := feats[(frames-1)*fbankBins:]
+	if first[peak] >= 0 || last[peak] <= 0 {
+		t.Fatalf("expected features relative to their mean, got %v then %v", first[peak], last[peak])
+	}
+}
+
+func TestSimilarityAndAverage(t *testing.T) {
+	if got := Similarity([]float64{1, 0}, []float64{2, 0}); math.Abs(got-1) > 1e-9 {
+		t.Fatalf("expected parallel vectors similar, got %v", got)
+	}
+	if got := Similarity([]float64{1, 0}, []float64{0, 1}); got != 0 {
+		t.Fatalf("expected orthogonal vectors unrelated, got %v", got)
+	}
+	if got := Similarity([]float64{1, 0}, []float64{1, 0, 0}); got != 0 {
+		t.Fatalf("expected embeddings of different models unrelated, got %v", got)
+	}
+	avg := Average([][]float64{{2, 0}, {0, 1}})
+	if math.Abs(avg[0]-avg[1]) > 1e-9 || math.Abs(avg[0]*avg[0]+avg[1]*avg[1]-1) > 1e-9 {
+		t.Fatalf("expected the normalized mean of normalized embeddings, got %v", avg)
+	}
+}
+
+// voiceEmbedder gives every span the embedding of whoever the test says is
+// speaking at its start.
+type voiceEmbedder struct {
+	voices map[float64][]float64
+	calls  int
+}
+
+func (e *voiceEmbedder) Embed(_ context.Context, _ string, spans []Span) ([][]float64, error) {
+	e.calls++
+	if len(spans) == 0 {
+		return [][]float64{{1, 0, 0}}, nil
+	}
+	out := make([][]float64, len(spans))
+	for i, s := range spans {
+		out[i] = e.voices[s.Start]
+	}
+	return out, nil
+}
+
+func identifyTestStore(t *testing.T) *storage.MemoryStore {
+	t.Helper()
+	store := storage.NewMemoryStore()
+	if err := store.CreateSession("s1", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	for i, speaker := range []int{0, 1, 2, 0} {
+		seg := transcribe.Segment{Speaker: speaker, Text: "words", StartTime: float64(i * 10), EndTime: float64(i*10 + 5)}
+		if err := store.AppendSegment("s1", seg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.SetAudioStatus("s1", storage.AudioReady, "s1.mp3"); err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func TestIdentifySessionNamesRecognizedSpeakers(t *testing.T) {
+	store := identifyTestStore(t)
+	for name, embedding := range map[string][]float64{"Alice": {1, 0, 0}, "Bob": {0, 1, 0}, "Carol": {0, 0, 1}} {
+		if _, err := store.AddVoiceProfile(storage.VoiceProfile{Name: name, Embedding: embedding}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.SetSpeakerName("s1", 2, "Carol"); err != nil {
+		t.Fatal(err)
+	}
+
+	embedder := &voiceEmbedder{voices: map[float64][]float64{
+		0:  {0.9, 0.1, 0},  // Alice
+		30: {0.95, 0, 0.1}, // Alice again
+		10: {0.5, 0.5, 0.5},
+		20: {0, 0, 1},
+	}}
+	given, err := NewIdentifier(store, embedder, 0.8).IdentifySession(context.Background(), "s1", "s1.mp3")
+	if err != nil {
+		t.Fatalf("IdentifySession failed: %v", err)
+	}
+	if len(given) != 1 || given[0] != "Alice" {
+		t.Fatalf("expected only speaker 0 recognized, as Alice, got %v", given)
+	}
+	names, _ := store.GetSpeakerNames("s1")
+	if names[0] != "Alice" || names[1] != "" || names[2] != "Carol" {
+		t.Fatalf("expected Alice named and Carol left alone, got %v", names)
+	}
+	if embedder.calls != 1 {
+		t.Fatalf("expected one embedder run for the session, got %d", embedder.calls)
+	}
+}
+
+func TestEnroll(t *testing.T) {
+	store := identifyTestStore(t)
+	if err := store.SetSpeakerName("s1", 1, "Bob"); err != nil {
+		t.Fatal(err)
+	}
+	embedder := &voiceEmbedder{voices: map[float64][]float64{10: {0, 1, 0}}}
+	identifier := NewIdentifier(store, embedder, 0.8)
+
+	profile, err := identifier.Enroll(context.Background(), " Alice ", "sample.webm", strings.NewReader("audio"))
+	if err != nil {
+		t.Fatalf("Enroll failed: %v", err)
+	}
+	if profile.ID == 0 || profile.Name != "Alice" || len(profile.Embedding) != 3 {
+		t.Fatalf("unexpected profile %+v", profile)
+	}
+	if _, err := identifier.Enroll(context.Background(), "", "sample.webm", strings.NewReader("audio")); !errors.Is(err, ErrNoName) {
+		t.Fatalf("expected ErrNoName, got %v", err)
+	}
+
+	profile, err = identifier.EnrollSpeaker(context.Background(), "s1", 1, "")
+	if err != nil {
+		t.Fatalf("EnrollSpeaker failed: %v", err)
+	}
+	if profile.Name != "Bob" || profile.Embedding[1] != 1 || !strings.Contains(profile.Source, "speaker 1") {
+		t.Fatalf("expected Bob enrolled from the session, got %+v", profile)
+	}
+	if _, err := identifier.EnrollSpeaker(context.Background(), "s1", 2, ""); !errors.Is(err, ErrNoName) {
+		t.Fatalf("expected ErrNoName for an unnamed speaker, got %v", err)
+	}
+	if _, err := identifier.EnrollSpeaker(context.Background(), "s1", 7, "Dan"); !errors.Is(err, ErrNoSpeech) {
+		t.Fatalf("expected ErrNoSpeech for a silent speaker, got %v", err)
+	}
+
+	// Segment times are on the capture timeline; the recording started 4s
+	// into it, so speaker 1 is heard 6s into the file.
+	if err := store.SetAudioOffset("s1", 4); err != nil {
+		t.Fatal(err)
+	}
+	embedder.voices = map[float64][]float64{6: {0, 0, 1}}
+	profile, err = identifier.EnrollSpeaker(context.Background(), "s1", 1, "Bobby")
+	if err != nil || profile.Embedding[2] != 1 {
+		t.Fatalf("expected Bobby enrolled from the span moved onto the recording, got %+v (%v)", profile, err)
+	}
+
+	profiles, _ := store.ListVoiceProfiles()
+	if len(profiles) != 3 || profiles[0].Name != "Alice" || profiles[1].Name != "Bob" {
+		t.Fatalf("expected Alice, Bob and Bobby enrolled, got %+v", profiles)
+	}
+}
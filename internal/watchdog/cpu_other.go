@@ -0,0 +1,8 @@
+//go:build !unix
+
+package watchdog
+
+import "time"
+
+// processCPUTime is unavailable here, so CPU use always reads 0%.
+func processCPUTime() time.Duration { return 0 }
@@ -0,0 +1,200 @@
+// Package watchdog samples the process's memory and CPU use against fixed
+// limits, so an always-on appliance can report and shed pressure before the
+// kernel's OOM killer or thermal throttling gets involved. It also reports
+// how large the database's indexes have grown.
+package watchdog
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/i18n"
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+)
+
+// indexSampleInterval is how often index sizes are measured; they grow
+// slowly and measuring them reads the whole database.
+const indexSampleInterval = 10 * time.Minute
+
+// Limits are the thresholds a Watchdog checks against. Zero disables a check.
+type Limits struct {
+	MemoryMB   int
+	CPUPercent float64
+}
+
+// Stats is the most recent sample, as reported in /api/status.
+type Stats struct {
+	MemoryMB        float64 `json:"memory_mb"`
+	MemoryLimitMB   int     `json:"memory_limit_mb"`
+	CPUPercent      float64 `json:"cpu_percent"`
+	CPULimitPercent float64 `json:"cpu_limit_percent"`
+	Goroutines      int     `json:"goroutines"`
+	OverMemory      bool    `json:"over_memory"`
+	OverCPU         bool    `json:"over_cpu"`
+	// Shedding is whether deferrable work is being held back, from the
+	// first sample over a limit to the first back under both.
+	Shedding bool `json:"shedding"`
+	// Indexes are the database's indexes by size, largest first.
+	Indexes   []storage.IndexSize `json:"indexes,omitempty"`
+	CheckedAt time.Time           `json:"checked_at"`
+}
+
+type Watchdog struct {
+	limits Limits
+
+	// Swappable for tests.
+	memory  func() uint64
+	cpuTime func() time.Duration
+	now     func() time.Time
+	release func()
+
+	// Localizer renders Warnings; nil renders English.
+	Localizer *i18n.Localizer
+	// Shed, when set, is called with true when a sample goes over a limit
+	// and false once one is back under both, to hold deferrable work back.
+	Shed func(shed bool)
+	// IndexSizes, when set, measures the database's indexes for Stats.
+	IndexSizes func() ([]storage.IndexSize, error)
+
+	mu        sync.Mutex
+	stats     Stats
+	lastCPU   time.Duration
+	lastTime  time.Time
+	shedding  bool
+	indexes   []storage.IndexSize
+	indexedAt time.Time
+}
+
+// New returns a Watchdog for limits. A memory limit is also installed as the
+// Go runtime's soft limit so the collector works harder as it approaches.
+func New(limits Limits) *Watchdog {
+	if limits.MemoryMB > 0 {
+		debug.SetMemoryLimit(int64(limits.MemoryMB) << 20)
+	}
+	return &Watchdog{
+		limits:  limits,
+		memory:  processMemory,
+		cpuTime: processCPUTime,
+		now:     time.Now,
+		release: debug.FreeOSMemory,
+	}
+}
+
+// Check takes a sample. CPU use is averaged since the previous Check, so the
+// first sample always reports 0%. When memory is over the limit, freed heap
+// is returned to the OS immediately.
+func (w *Watchdog) Check() Stats {
+	mem := w.memory()
+	cpu := w.cpuTime()
+	now := w.now()
+	indexes, measured := w.measureIndexes(now)
+
+	w.mu.Lock()
+
+	var cpuPercent float64
+	if !w.lastTime.IsZero() {
+		if wall := now.Sub(w.lastTime); wall > 0 {
+			cpuPercent = float64(cpu-w.lastCPU) / float64(wall) * 100
+		}
+	}
+	w.lastCPU = cpu
+	w.lastTime = now
+
+	memoryMB := float64(mem) / (1 << 20)
+	w.stats = Stats{
+		MemoryMB:        memoryMB,
+		MemoryLimitMB:   w.limits.MemoryMB,
+		CPUPercent:      cpuPercent,
+		CPULimitPercent: w.limits.CPUPercent,
+		Goroutines:      runtime.NumGoroutine(),
+		OverMemory:      w.limits.MemoryMB > 0 && memoryMB > float64(w.limits.MemoryMB),
+		OverCPU:         w.limits.CPUPercent > 0 && cpuPercent > w.limits.CPUPercent,
+		CheckedAt:       now.UTC(),
+	}
+	if measured {
+		w.indexes, w.indexedAt = indexes, now
+	}
+	w.stats.Indexes = w.indexes
+	if w.stats.OverMemory {
+		w.release()
+	}
+	shedding := w.stats.OverMemory || w.stats.OverCPU
+	changed := shedding != w.shedding
+	w.shedding, w.stats.Shedding = shedding, shedding
+	stats := w.stats
+	w.mu.Unlock()
+
+	if changed && w.Shed != nil {
+		w.Shed(shedding)
+	}
+	return stats
+}
+
+// measureIndexes measures index sizes if it is time to, reporting whether
+// it tried. A failed measurement keeps the last sizes.
+func (w *Watchdog) measureIndexes(now time.Time) ([]storage.IndexSize, bool) {
+	if w.IndexSizes == nil {
+		return nil, false
+	}
+	w.mu.Lock()
+	due := w.indexedAt.IsZero() || now.Sub(w.indexedAt) >= indexSampleInterval
+	last := w.indexes
+	w.mu.Unlock()
+	if !due {
+		return nil, false
+	}
+	sizes, err := w.IndexSizes()
+	if err != nil {
+		slog.Warn("measuring index sizes failed", "error", err)
+		return last, true
+	}
+	return sizes, true
+}
+
+// Stats returns the most recent sample without taking a new one.
+func (w *Watchdog) Stats() Stats {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.stats
+}
+
+// Warnings describes any limit the latest sample exceeded.
+func (w *Watchdog) Warnings() []string {
+	s := w.Stats()
+	var warnings []string
+	if s.OverMemory {
+		warnings = append(warnings, w.Localizer.T(i18n.WatchdogMemoryOver, s.MemoryMB, s.MemoryLimitMB))
+	}
+	if s.OverCPU {
+		warnings = append(warnings, w.Localizer.T(i18n.WatchdogCPUOver, s.CPUPercent, s.CPULimitPercent))
+	}
+	return warnings
+}
+
+// Run samples every interval until ctx is cancelled.
+func (w *Watchdog) Run(ctx context.Context, interval time.Duration) {
+	w.Check()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.Check()
+		}
+	}
+}
+
+// processMemory approximates resident memory as what the Go runtime holds
+// from the OS minus what it has already handed back.
+func processMemory() uint64 {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return m.Sys - m.HeapReleased
+}
@@ -0,0 +1,124 @@
+package watchdog
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sjawhar/ghost-wispr/internal/storage"
+)
+
+func TestCheckReportsUsageAgainstLimits(t *testing.T) {
+	start := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	now := start
+	cpu := time.Duration(0)
+	released := 0
+
+	w := &Watchdog{
+		limits:  Limits{MemoryMB: 100, CPUPercent: 50},
+		memory:  func() uint64 { return 150 << 20 },
+		cpuTime: func() time.Duration { return cpu },
+		now:     func() time.Time { return now },
+		release: func() { released++ },
+	}
+
+	first := w.Check()
+	if first.CPUPercent != 0 {
+		t.Fatalf("expected first sample to report 0%% CPU, got %v", first.CPUPercent)
+	}
+	if !first.OverMemory || released != 1 {
+		t.Fatalf("expected memory over limit to release heap, got %+v (released %d)", first, released)
+	}
+
+	now = start.Add(10 * time.Second)
+	cpu = 8 * time.Second
+	second := w.Check()
+	if second.CPUPercent != 80 || !second.OverCPU {
+		t.Fatalf("expected 80%% CPU over limit, got %+v", second)
+	}
+	if !reflect.DeepEqual(w.Stats(), second) {
+		t.Fatalf("expected Stats to return latest sample")
+	}
+
+	warnings := w.Warnings()
+	if len(warnings) != 2 || !strings.Contains(warnings[0], "150 MB") || !strings.Contains(warnings[1], "80%") {
+		t.Fatalf("unexpected warnings: %v", warnings)
+	}
+}
+
+func TestZeroLimitsNeverTrip(t *testing.T) {
+	w := &Watchdog{
+		memory:  func() uint64 { return 1 << 40 },
+		cpuTime: func() time.Duration { return time.Hour },
+		now:     time.Now,
+		release: func() { t.Fatal("unexpected release") },
+	}
+	if s := w.Check(); s.OverMemory || s.OverCPU {
+		t.Fatalf("expected no limits tripped, got %+v", s)
+	}
+}
+
+func TestCheckShedsLoadOnlyOnTransitions(t *testing.T) {
+	mem := uint64(150 << 20)
+	var calls []bool
+	w := &Watchdog{
+		limits:  Limits{MemoryMB: 100},
+		memory:  func() uint64 { return mem },
+		cpuTime: func() time.Duration { return 0 },
+		now:     time.Now,
+		release: func() {},
+		Shed:    func(shed bool) { calls = append(calls, shed) },
+	}
+
+	if s := w.Check(); !s.Shedding {
+		t.Fatalf("expected shedding over the memory limit, got %+v", s)
+	}
+	w.Check()
+	mem = 50 << 20
+	if s := w.Check(); s.Shedding {
+		t.Fatalf("expected shedding to stop under the limit, got %+v", s)
+	}
+	w.Check()
+	if !reflect.DeepEqual(calls, []bool{true, false}) {
+		t.Fatalf("expected Shed(true) then Shed(false), got %v", calls)
+	}
+}
+
+func TestCheckSamplesIndexSizesPeriodically(t *testing.T) {
+	start := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	now := start
+	measured := 0
+	var fail error
+	w := &Watchdog{
+		memory:  func() uint64 { return 0 },
+		cpuTime: func() time.Duration { return 0 },
+		now:     func() time.Time { return now },
+		release: func() {},
+		IndexSizes: func() ([]storage.IndexSize, error) {
+			measured++
+			if fail != nil {
+				return nil, fail
+			}
+			return []storage.IndexSize{{Name: "idx_segments_session", Bytes: int64(measured) * 4096}}, nil
+		},
+	}
+
+	if s := w.Check(); len(s.Indexes) != 1 || s.Indexes[0].Bytes != 4096 {
+		t.Fatalf("expected index sizes on the first check, got %+v", s.Indexes)
+	}
+	now = start.Add(time.Minute)
+	if s := w.Check(); measured != 1 || len(s.Indexes) != 1 {
+		t.Fatalf("expected the earlier sample to be reused, got %d measurements, %+v", measured, s.Indexes)
+	}
+	now = start.Add(indexSampleInterval)
+	fail = errors.New("dbstat unavailable")
+	if s := w.Check(); measured != 2 || len(s.Indexes) != 1 || s.Indexes[0].Bytes != 4096 {
+		t.Fatalf("expected a failed sample to keep the last sizes, got %d measurements, %+v", measured, s.Indexes)
+	}
+	now = start.Add(indexSampleInterval + time.Minute)
+	if w.Check(); measured != 2 {
+		t.Fatalf("expected a failed sample to wait for the next interval, got %d measurements", measured)
+	}
+}
@@ -41,4 +41,61 @@ describe('AudioPlayer', () => {
     await fireEvent.click(screen.getByRole('button', { name: /Hello/i }))
     expect(appState.activeAudioSessionId).toBe('s1')
   })
+
+  it('highlights the playing word of the active line', async () => {
+    const { container } = render(AudioPlayer, {
+      sessionId: 's1',
+      segments: [
+        {
+          speaker: 0,
+          text: 'Hello there friend',
+          start_time: 5,
+          end_time: 8,
+          timestamp: new Date().toISOString(),
+          words: [
+            { word: 'Hello', start: 5, end: 5.5 },
+            { word: 'there', start: 5.6, end: 6.4 },
+            { word: 'friend', start: 6.5, end: 8 },
+          ],
+        },
+      ],
+    })
+
+    const audio = container.querySelector('audio') as HTMLAudioElement
+    Object.defineProperty(audio, 'currentTime', { configurable: true, value: 6 })
+    await fireEvent.timeUpdate(audio)
+
+    expect(container.querySelector('.line-word.current')?.textContent).toBe('there')
+    expect(container.querySelector('.line-word.spoken')?.textContent).toBe('Hello')
+  })
+
+  it('lines segments up with a recording that starts partway into the timeline', async () => {
+    const { container } = render(AudioPlayer, {
+      sessionId: 's2',
+      audioOffset: 100,
+      segments: [
+        {
+          speaker: 0,
+          text: 'Hello there friend',
+          start_time: 105,
+          end_time: 108,
+          timestamp: new Date().toISOString(),
+          words: [
+            { word: 'Hello', start: 105, end: 105.5 },
+            { word: 'there', start: 105.6, end: 106.4 },
+            { word: 'friend', start: 106.5, end: 108 },
+          ],
+        },
+      ],
+    })
+
+    expect(container.querySelector('.line-time')?.textContent).toBe('00:05')
+    const audio = container.querySelector('audio') as HTMLAudioElement
+    Object.defineProperty(audio, 'currentTime', { configurable: true, writable: true, value: 6 })
+    await fireEvent.timeUpdate(audio)
+    expect(container.querySelector('.line-word.current')?.textContent).toBe('there')
+
+    await fireEvent.click(screen.getByRole('button', { name: /Hello/i }))
+    expect(audio.currentTime).toBe(5)
+  })
 })
@@ -4,6 +4,7 @@ import {
   fetchSession,
   fetchSessions,
   fetchStatus,
+  markSessionViewed,
   pauseRecording,
   resumeRecording,
   resummarize,
@@ -53,6 +54,15 @@ describe('api client', () => {
     await expect(fetchSession('s1')).resolves.toEqual({ session: { id: 's1' }, segments: [] })
   })
 
+  it('marks a session viewed', async () => {
+    const fetchMock = vi.fn().mockResolvedValue({ ok: true, status: 204, json: async () => ({}) })
+    vi.stubGlobal('fetch', fetchMock)
+
+    await markSessionViewed('s1')
+
+    expect(fetchMock).toHaveBeenCalledWith('/api/sessions/s1/viewed', { method: 'POST' })
+  })
+
   it('fetches status', async () => {
     vi.stubGlobal(
       'fetch',
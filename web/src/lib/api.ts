@@ -1,4 +1,15 @@
-import type { PresetMap, SessionDetailResponse, SessionSummary, StatusResponse } from './types'
+import type {
+  Note,
+  PresetMap,
+  SessionDetailResponse,
+  SessionSearchResponse,
+  SessionSummary,
+  StatusResponse,
+  SummaryEstimateResponse,
+  SummaryJob,
+  VoiceProfile,
+  Waveform,
+} from './types'
 
 async function request<T>(input: RequestInfo | URL, init?: RequestInit): Promise<T> {
   const response = await fetch(input, init)
@@ -26,6 +37,21 @@ export function fetchSession(id: string): Promise<SessionDetailResponse> {
   return request<SessionDetailResponse>(`/api/sessions/${encodeURIComponent(id)}`)
 }
 
+/** Records that a session was opened, taking it out of the unreviewed inbox. */
+export function markSessionViewed(id: string): Promise<void> {
+  return request<void>(`/api/sessions/${encodeURIComponent(id)}/viewed`, { method: 'POST' })
+}
+
+export function fetchWaveform(id: string): Promise<Waveform> {
+  return request<Waveform>(`/api/sessions/${encodeURIComponent(id)}/waveform`)
+}
+
+export function searchSession(id: string, query: string): Promise<SessionSearchResponse> {
+  return request<SessionSearchResponse>(
+    `/api/sessions/${encodeURIComponent(id)}/search?q=${encodeURIComponent(query)}`,
+  )
+}
+
 export function fetchStatus(): Promise<StatusResponse> {
   return request<StatusResponse>('/api/status')
 }
@@ -34,6 +60,14 @@ export function fetchPresets(): Promise<PresetMap> {
   return request<PresetMap>('/api/presets')
 }
 
+export function addNote(text: string): Promise<Note> {
+  return request<Note>('/api/session/current/notes', {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify({ text, at: new Date().toISOString() }),
+  })
+}
+
 export function pauseRecording(): Promise<void> {
   return request<void>('/api/pause', { method: 'POST' })
 }
@@ -53,6 +87,38 @@ export async function resummarize(sessionId: string, preset?: string): Promise<v
   }
 }
 
+export function estimateSummary(sessionId: string, preset?: string): Promise<SummaryEstimateResponse> {
+  const query = preset ? `?preset=${encodeURIComponent(preset)}` : ''
+  return request<SummaryEstimateResponse>(
+    `/api/sessions/${encodeURIComponent(sessionId)}/summarize/estimate${query}`,
+  )
+}
+
+export async function fetchSummaryQueue(): Promise<SummaryJob[]> {
+  const { jobs } = await request<{ jobs: SummaryJob[] }>('/api/summaries/queue')
+  return jobs
+}
+
+export function cancelSummary(sessionId: string): Promise<void> {
+  return request<void>(`/api/summaries/${encodeURIComponent(sessionId)}/cancel`, { method: 'POST' })
+}
+
 export function endSession(): Promise<void> {
   return request<void>('/api/session/end', { method: 'POST' })
 }
+
+/** Learns a voice from a short recording, so it is recognized in later sessions. */
+export function enrollVoice(name: string, recording: Blob): Promise<VoiceProfile> {
+  const form = new FormData()
+  form.append('file', recording, 'sample.webm')
+  return request<VoiceProfile>(`/api/voices?name=${encodeURIComponent(name)}`, { method: 'POST', body: form })
+}
+
+export async function fetchVoices(): Promise<VoiceProfile[]> {
+  const response = await request<{ voices: VoiceProfile[] }>('/api/voices')
+  return response.voices
+}
+
+export function deleteVoice(id: number): Promise<void> {
+  return request<void>(`/api/voices/${id}`, { method: 'DELETE' })
+}
@@ -0,0 +1,86 @@
+// Streams this browser's microphone to /ws/mic as mono PCM16-LE, for servers
+// with no audio device of their own.
+
+const tapSource = `
+class PCMTap extends AudioWorkletProcessor {
+  process(inputs) {
+    const channel = inputs[0][0]
+    if (channel) this.port.postMessage(channel.slice(0))
+    return true
+  }
+}
+registerProcessor('pcm-tap', PCMTap)
+`
+
+let socket: WebSocket | null = null
+let context: AudioContext | null = null
+let stream: MediaStream | null = null
+
+function micURL(sampleRate: number): string {
+  const protocol = window.location.protocol === 'https:' ? 'wss:' : 'ws:'
+  return `${protocol}//${window.location.host}/ws/mic?sample_rate=${sampleRate}`
+}
+
+function toPCM16(samples: Float32Array): ArrayBuffer {
+  const out = new Int16Array(samples.length)
+  for (let i = 0; i < samples.length; i++) {
+    const s = Math.max(-1, Math.min(1, samples[i]))
+    out[i] = s < 0 ? s * 0x8000 : s * 0x7fff
+  }
+  return out.buffer
+}
+
+export function browserMicActive(): boolean {
+  return socket !== null
+}
+
+/**
+ * Starts sending the microphone. onStop is called if the server hangs up,
+ * e.g. because another browser is already sending.
+ */
+export async function startBrowserMic(onStop: () => void): Promise<void> {
+  if (socket) {
+    return
+  }
+
+  // Processing meant for calls would distort the recording.
+  stream = await navigator.mediaDevices.getUserMedia({
+    audio: { channelCount: 1, echoCancellation: false, noiseSuppression: false, autoGainControl: false },
+  })
+  context = new AudioContext()
+  const tapURL = URL.createObjectURL(new Blob([tapSource], { type: 'text/javascript' }))
+  try {
+    await context.audioWorklet.addModule(tapURL)
+  } finally {
+    URL.revokeObjectURL(tapURL)
+  }
+
+  const ws = new WebSocket(micURL(context.sampleRate))
+  ws.binaryType = 'arraybuffer'
+  socket = ws
+
+  const tap = new AudioWorkletNode(context, 'pcm-tap')
+  tap.port.onmessage = (event: MessageEvent<Float32Array>) => {
+    if (ws.readyState === WebSocket.OPEN) {
+      ws.send(toPCM16(event.data))
+    }
+  }
+  context.createMediaStreamSource(stream).connect(tap)
+
+  ws.addEventListener('close', () => {
+    if (socket === ws) {
+      stopBrowserMic()
+      onStop()
+    }
+  })
+}
+
+export function stopBrowserMic(): void {
+  const ws = socket
+  socket = null
+  ws?.close()
+  stream?.getTracks().forEach((track) => track.stop())
+  stream = null
+  void context?.close()
+  context = null
+}
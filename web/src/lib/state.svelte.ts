@@ -1,9 +1,14 @@
 import type {
+  AudioReadyEvent,
+  BrowserMicStatus,
   LiveTranscriptEvent,
+  MicHealth,
   PresetMap,
   SessionDetailResponse,
   SessionSummary,
   SummaryReadyEvent,
+  TranscriptionStatus,
+  UpdateInfo,
   WebSocketEvent,
 } from './types'
 
@@ -15,12 +20,20 @@ type AppState = {
   sessionDetails: Map<string, SessionDetailResponse>
   dates: string[]
   warnings: string[]
+  update: UpdateInfo | null
   presets: PresetMap
   activeSessionId: string
   activeSessionStartedAt: number
   activeAudioSessionId: string
   interimText: string
   interimSpeaker: number
+  viewerCount: number
+  micHealthy: boolean
+  micError: string
+  transcriptionReconnecting: boolean
+  transcriptionError: string
+  browserMicEnabled: boolean
+  browserMicFeeding: boolean
 }
 
 export const appState = $state<AppState>({
@@ -31,12 +44,20 @@ export const appState = $state<AppState>({
   sessionDetails: new Map(),
   dates: [],
   warnings: [],
+  update: null,
   presets: {},
   activeSessionId: '',
   activeSessionStartedAt: 0,
   activeAudioSessionId: '',
   interimText: '',
   interimSpeaker: -1,
+  viewerCount: 0,
+  micHealthy: true,
+  micError: '',
+  transcriptionReconnecting: false,
+  transcriptionError: '',
+  browserMicEnabled: false,
+  browserMicFeeding: false,
 })
 
 export function getTodaysSessions(): SessionSummary[] {
@@ -52,6 +73,25 @@ export function setPaused(paused: boolean): void {
   appState.paused = paused
 }
 
+export function setViewerCount(count: number): void {
+  appState.viewerCount = count
+}
+
+export function setMicHealth(health: MicHealth | undefined): void {
+  appState.micHealthy = health?.healthy ?? true
+  appState.micError = health?.error ?? ''
+}
+
+export function setTranscriptionStatus(status: TranscriptionStatus | undefined): void {
+  appState.transcriptionReconnecting = status?.state === 'reconnecting'
+  appState.transcriptionError = status?.error ?? ''
+}
+
+export function setBrowserMic(status: BrowserMicStatus | undefined): void {
+  appState.browserMicEnabled = status?.enabled ?? false
+  appState.browserMicFeeding = status?.feeding ?? false
+}
+
 export function setDates(dates: string[]): void {
   appState.dates = dates
 }
@@ -60,6 +100,10 @@ export function setWarnings(warnings: string[]): void {
   appState.warnings = warnings
 }
 
+export function setUpdate(update: UpdateInfo | null): void {
+  appState.update = update
+}
+
 export function setPresets(presets: PresetMap): void {
   appState.presets = presets
 }
@@ -76,6 +120,19 @@ export function setSessionDetail(detail: SessionDetailResponse): void {
   appState.sessionDetails = next
 }
 
+export function removeSession(sessionId: string): void {
+  const nextSessions = new Map<string, SessionSummary[]>()
+  for (const [date, sessions] of appState.sessionsByDate) {
+    nextSessions.set(date, sessions.filter((session) => session.id !== sessionId))
+  }
+  appState.sessionsByDate = nextSessions
+  if (appState.sessionDetails.has(sessionId)) {
+    const nextDetails = new Map(appState.sessionDetails)
+    nextDetails.delete(sessionId)
+    appState.sessionDetails = nextDetails
+  }
+}
+
 export function setActiveAudioSession(sessionId: string): void {
   appState.activeAudioSessionId = sessionId
 }
@@ -128,6 +185,30 @@ export function applySummaryUpdate(event: SummaryReadyEvent): void {
   }
 }
 
+export function applyAudioUpdate(event: AudioReadyEvent): void {
+  const update = { audio_status: event.status, audio_path: event.audio_path ?? '' }
+
+  const nextByDate = new Map(appState.sessionsByDate)
+  for (const date of appState.dates) {
+    const sessions = nextByDate.get(date)
+    if (!sessions) {
+      continue
+    }
+    nextByDate.set(
+      date,
+      sessions.map((session) => (session.id === event.session_id ? { ...session, ...update } : session)),
+    )
+  }
+  appState.sessionsByDate = nextByDate
+
+  const detail = appState.sessionDetails.get(event.session_id)
+  if (detail) {
+    const nextDetails = new Map(appState.sessionDetails)
+    nextDetails.set(event.session_id, { ...detail, session: { ...detail.session, ...update } })
+    appState.sessionDetails = nextDetails
+  }
+}
+
 export function applyEvent(event: WebSocketEvent): void {
   switch (event.type) {
     case 'connection':
@@ -136,6 +217,26 @@ export function applyEvent(event: WebSocketEvent): void {
     case 'status_changed':
       setPaused(event.paused)
       return
+    case 'presence':
+      setViewerCount(event.viewer_count)
+      return
+    case 'mic_status':
+      setMicHealth(event)
+      return
+    case 'transcription_status':
+      setTranscriptionStatus(event)
+      return
+    case 'warnings':
+      setWarnings(event.warnings)
+      return
+    case 'update_available':
+      setUpdate({
+        version: event.new_version,
+        url: event.url,
+        published_at: '',
+        staged: event.staged,
+      })
+      return
     case 'session_started':
       appState.activeSessionId = event.session_id
       appState.activeSessionStartedAt = Date.parse(event.timestamp)
@@ -152,6 +253,36 @@ export function applyEvent(event: WebSocketEvent): void {
     case 'summary_ready':
       applySummaryUpdate(event)
       return
+    case 'audio_ready':
+      applyAudioUpdate(event)
+      return
+    case 'session_deleted':
+      removeSession(event.session_id)
+      return
+    case 'bookmark_added': {
+      const detail = appState.sessionDetails.get(event.bookmark.session_id)
+      if (detail) {
+        const nextDetails = new Map(appState.sessionDetails)
+        nextDetails.set(event.bookmark.session_id, {
+          ...detail,
+          bookmarks: [...(detail.bookmarks ?? []), event.bookmark],
+        })
+        appState.sessionDetails = nextDetails
+      }
+      return
+    }
+    case 'note_added': {
+      const detail = appState.sessionDetails.get(event.note.session_id)
+      if (detail) {
+        const nextDetails = new Map(appState.sessionDetails)
+        nextDetails.set(event.note.session_id, {
+          ...detail,
+          notes: [...(detail.notes ?? []), event.note],
+        })
+        appState.sessionDetails = nextDetails
+      }
+      return
+    }
     case 'live_transcript_interim':
       appState.interimText = event.text
       appState.interimSpeaker = event.speaker
@@ -177,7 +308,9 @@ export function resetState(): void {
   appState.activeSessionStartedAt = 0
   appState.activeAudioSessionId = ''
   appState.warnings = []
+  appState.update = null
   appState.presets = {}
   appState.interimText = ''
   appState.interimSpeaker = -1
+  appState.viewerCount = 0
 }
@@ -34,15 +34,203 @@ export interface SummaryReadyEvent extends BaseEvent {
   type: 'summary_ready'
   session_id: string
   summary: string
-  status: 'pending' | 'running' | 'completed' | 'failed'
+  status: 'pending' | 'running' | 'completed' | 'failed' | 'queued_offline' | 'cancelled' | 'skipped_budget'
   summary_preset?: string
 }
 
+export interface AudioReadyEvent extends BaseEvent {
+  type: 'audio_ready'
+  session_id: string
+  status: 'ready' | 'failed'
+  audio_path?: string
+}
+
 export interface StatusChangedEvent extends BaseEvent {
   type: 'status_changed'
   paused: boolean
 }
 
+export interface UpdateAvailableEvent extends BaseEvent {
+  type: 'update_available'
+  new_version: string
+  url: string
+  staged: boolean
+}
+
+export interface Bookmark {
+  id: number
+  session_id: string
+  offset: number
+  note: string
+  created_at: string
+}
+
+export interface Note {
+  id: number
+  session_id: string
+  /** Seconds on the timeline segment start_time and end_time are on. */
+  offset: number
+  text: string
+  created_at: string
+}
+
+export interface Annotation {
+  session_id: string
+  external_id: string
+  kind: 'comment' | 'reply' | 'suggestion'
+  author?: string
+  text: string
+  quote?: string
+  resolved?: boolean
+  created_at: string
+}
+
+export interface ActionItem {
+  id: number
+  session_id: string
+  item: string
+  owner: string
+  done: boolean
+  timestamp: string
+}
+
+export interface Citation {
+  bullet: number
+  line: number
+  text: string
+  first_segment_id: number
+  last_segment_id: number
+  start_time: number
+  end_time: number
+  score: number
+}
+
+export interface AudioFile {
+  index: number
+  kind: 'recording' | 'recovered' | 'gap' | 'imported' | 'clip'
+  path: string
+  created_at: string
+}
+
+export interface BookmarkAddedEvent extends BaseEvent {
+  type: 'bookmark_added'
+  bookmark: Bookmark
+}
+
+export interface NoteAddedEvent extends BaseEvent {
+  type: 'note_added'
+  note: Note
+}
+
+export interface AudioLevelEvent extends BaseEvent {
+  type: 'audio_level'
+  rms: number
+  peak: number
+  dbfs: number
+  clipping: boolean
+  clipped_samples: number
+}
+
+export interface MicHealth {
+  healthy: boolean
+  device: string
+  error?: string
+  since: string
+}
+
+export interface MicStatusEvent extends BaseEvent, MicHealth {
+  type: 'mic_status'
+}
+
+export interface TranscriptionStatus {
+  state: 'connected' | 'reconnecting'
+  provider?: string
+  attempt?: number
+  retry_in_seconds?: number
+  error?: string
+  since: string
+}
+
+export interface TranscriptionStatusEvent extends BaseEvent, TranscriptionStatus {
+  type: 'transcription_status'
+}
+
+export interface WarningsEvent extends BaseEvent {
+  type: 'warnings'
+  warnings: string[]
+}
+
+export interface Briefing {
+  id: string
+  title: string
+  attendees: string[]
+  starts_at: string
+  ends_at?: string
+  brief: string
+  session_ids: string[]
+  created_at: string
+  delivered_at?: string
+}
+
+export interface BriefingReadyEvent extends BaseEvent {
+  type: 'briefing_ready'
+  briefing: Briefing
+}
+
+export interface Report {
+  id: string
+  period_start: string
+  period_end: string
+  created_at: string
+  database_bytes: number
+  database_growth_bytes: number
+  audio_bytes_added: number
+  sessions: number
+  transcribed_minutes: number
+  transcription_cost_usd: number
+  llm_input_tokens: number
+  llm_output_tokens: number
+  llm_cost_usd: number
+  summary_failures: number
+  audio_failures: number
+  export_failures: number
+  text: string
+}
+
+export interface ReportReadyEvent extends BaseEvent {
+  type: 'report_ready'
+  report: Report
+}
+
+export interface SessionDeletedEvent extends BaseEvent {
+  type: 'session_deleted'
+  session_id: string
+}
+
+export interface CommandAckEvent extends BaseEvent {
+  type: 'ack'
+  id?: string
+  command: string
+  ok: boolean
+  error?: string
+  data?: unknown
+}
+
+export interface Viewer {
+  id: string
+  user?: string
+  user_agent: string
+  connected_at: string
+}
+
+export interface PresenceEvent extends BaseEvent {
+  type: 'presence'
+  change: 'joined' | 'left'
+  viewer: Viewer
+  viewer_count: number
+  viewers: Viewer[]
+}
+
 export interface ConnectionEvent extends BaseEvent {
   type: 'connection'
   connected: boolean
@@ -54,15 +242,38 @@ export type WebSocketEvent =
   | SessionStartedEvent
   | SessionEndedEvent
   | SummaryReadyEvent
+  | AudioReadyEvent
   | StatusChangedEvent
+  | UpdateAvailableEvent
+  | BookmarkAddedEvent
+  | NoteAddedEvent
+  | BriefingReadyEvent
+  | ReportReadyEvent
+  | SessionDeletedEvent
+  | AudioLevelEvent
+  | MicStatusEvent
+  | TranscriptionStatusEvent
+  | WarningsEvent
+  | CommandAckEvent
+  | PresenceEvent
   | ConnectionEvent
 
+export interface SegmentWord {
+  word: string
+  start: number
+  end: number
+  confidence?: number
+}
+
 export interface Segment {
   speaker: number
   text: string
   start_time: number
   end_time: number
   timestamp: string
+  language?: string
+  confidence?: number
+  words?: SegmentWord[]
 }
 
 export interface SessionSummary {
@@ -71,19 +282,124 @@ export interface SessionSummary {
   ended_at?: string
   status: string
   summary: string
-  summary_status: 'pending' | 'running' | 'completed' | 'failed'
+  summary_status: 'pending' | 'running' | 'completed' | 'failed' | 'queued_offline' | 'cancelled' | 'skipped_budget'
   summary_preset: string
   audio_path: string
+  audio_status?: 'encoding' | 'ready' | 'failed'
+  /** Where the recording starts on the timeline segments are timed on, in seconds. */
+  audio_offset?: number
+  summary_audio_path?: string
+  segments_version?: number
+  series_id?: string
+  kind?: 'memo'
+  recovered?: boolean
+  transcription_model?: string
+  transcription_language?: string
+  transcription_providers?: string[]
+  summary_model?: string
+  keep_wav?: boolean
+  wav_path?: string
+  gdoc_id?: string
+  journal_doc_id?: string
+  channel_speakers?: boolean
+  refine_failures?: number
+  last_viewed_at?: string
+  locked?: boolean
 }
 
 export interface SessionDetailResponse {
   session: SessionSummary
   segments: Segment[]
+  bookmarks?: Bookmark[]
+  notes?: Note[]
+  annotations?: Annotation[]
+  action_items?: ActionItem[]
+  citations?: Citation[]
+  audio_files?: AudioFile[]
+  speaker_names?: Record<number, string>
+}
+
+export interface VoiceProfile {
+  id: number
+  name: string
+  source: string
+  session_id?: string
+  created_at: string
+}
+
+export interface UpdateInfo {
+  version: string
+  url: string
+  published_at: string
+  staged: boolean
+}
+
+export interface BrowserMicStatus {
+  enabled: boolean
+  feeding: boolean
 }
 
 export interface StatusResponse {
   paused: boolean
   warnings: string[]
+  update: UpdateInfo | null
+  viewers?: number
+  mic?: MicHealth
+  browser_mic?: BrowserMicStatus
+  transcription?: TranscriptionStatus
 }
 
 export type PresetMap = Record<string, string>
+
+export interface SummaryJob {
+  session_id: string
+  preset?: string
+  status: 'pending' | 'running'
+  attempt: number
+  queued_at: string
+  started_at?: string
+  eta: string
+}
+
+export interface Waveform {
+  seconds_per_peak: number
+  duration: number
+  peaks: number[]
+}
+
+export interface TextRange {
+  start: number
+  end: number
+}
+
+export interface SearchMatch {
+  segment: number
+  speaker: number
+  start_time: number
+  end_time: number
+  text: string
+  /** UTF-16 offsets into text, as used by String.prototype.slice. */
+  highlights: TextRange[]
+  before?: { speaker: number; text: string }
+  after?: { speaker: number; text: string }
+}
+
+export interface SessionSearchResponse {
+  query: string
+  matches: SearchMatch[]
+  truncated: boolean
+}
+
+export interface SummaryEstimate {
+  model: string
+  input_tokens: number
+  output_tokens: number
+  cost_usd: number
+  priced: boolean
+  selected: boolean
+}
+
+export interface SummaryEstimateResponse {
+  preset: string
+  estimates: SummaryEstimate[]
+}
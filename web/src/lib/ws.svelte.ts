@@ -1,13 +1,24 @@
 import { applyEvent, setConnected } from './state.svelte'
-import type { WebSocketEvent } from './types'
+import type { CommandAckEvent, WebSocketEvent } from './types'
 
 let socket: WebSocket | null = null
+let nextCommandId = 0
+const pendingCommands = new Map<
+  string,
+  { resolve: (data: unknown) => void; reject: (error: Error) => void }
+>()
 let reconnectTimer: ReturnType<typeof setTimeout> | null = null
 let shouldReconnect = true
 
+// Phones get interim transcripts at most four times a second, sparing the
+// radio and battery; finals still arrive at once.
+const mobileInterimMs = 250
+
 function wsURL(): string {
   const protocol = window.location.protocol === 'https:' ? 'wss:' : 'ws:'
-  return `${protocol}//${window.location.host}/ws`
+  const mobile = window.matchMedia?.('(pointer: coarse)').matches
+  const query = mobile ? `?interim_ms=${mobileInterimMs}` : ''
+  return `${protocol}//${window.location.host}/ws${query}`
 }
 
 function scheduleReconnect(): void {
@@ -40,6 +51,10 @@ export function connect(): void {
   socket.addEventListener('message', (event) => {
     try {
       const payload = JSON.parse(event.data) as WebSocketEvent
+      if (payload.type === 'ack') {
+        settleCommand(payload)
+        return
+      }
       applyEvent(payload)
     } catch (error) {
       void error
@@ -48,6 +63,7 @@ export function connect(): void {
 
   socket.addEventListener('close', () => {
     setConnected(false)
+    rejectPendingCommands()
     scheduleReconnect()
   })
 
@@ -56,6 +72,60 @@ export function connect(): void {
   })
 }
 
+function settleCommand(ack: CommandAckEvent): void {
+  const pending = ack.id ? pendingCommands.get(ack.id) : undefined
+  if (!pending || !ack.id) {
+    return
+  }
+  pendingCommands.delete(ack.id)
+  if (ack.ok) {
+    pending.resolve(ack.data)
+  } else {
+    pending.reject(new Error(ack.error || `${ack.command} failed`))
+  }
+}
+
+function rejectPendingCommands(): void {
+  for (const pending of pendingCommands.values()) {
+    pending.reject(new Error('websocket closed'))
+  }
+  pendingCommands.clear()
+}
+
+// sendCommand runs a command over the open socket and resolves with the
+// ack's data. It rejects when the socket is not open, so callers can fall
+// back to the REST API.
+export function sendCommand(
+  command: string,
+  fields: Record<string, unknown> = {},
+  timeoutMs = 5000,
+): Promise<unknown> {
+  if (!socket || socket.readyState !== WebSocket.OPEN) {
+    return Promise.reject(new Error('websocket not connected'))
+  }
+
+  nextCommandId += 1
+  const id = String(nextCommandId)
+  const ws = socket
+  return new Promise((resolve, reject) => {
+    const timer = setTimeout(() => {
+      pendingCommands.delete(id)
+      reject(new Error(`${command} timed out`))
+    }, timeoutMs)
+    pendingCommands.set(id, {
+      resolve: (data) => {
+        clearTimeout(timer)
+        resolve(data)
+      },
+      reject: (error) => {
+        clearTimeout(timer)
+        reject(error)
+      },
+    })
+    ws.send(JSON.stringify({ ...fields, id, command }))
+  })
+}
+
 export function disconnect(): void {
   shouldReconnect = false
   if (reconnectTimer) {